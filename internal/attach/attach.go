@@ -0,0 +1,224 @@
+// Package attach links files (crash logs, screenshots, test artifacts) to
+// beads.
+//
+// File contents live in a blob store (see package blobstore), addressed by
+// their SHA-256 digest so attaching the same file twice stores it once.
+// The link lives on the bead: its description gets an "attachments"
+// section with one JSON-encoded Attachment per line.
+package attach
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/blobstore"
+)
+
+// Section is the bead description section listing attachments.
+const Section = "attachments"
+
+// digestPrefix marks the hash algorithm in digests.
+const digestPrefix = "sha256:"
+
+// Attachment is a file linked to a bead.
+type Attachment struct {
+	Digest string    `json:"digest"` // "sha256:<hex>"
+	Name   string    `json:"name"`   // Base name of the attached file
+	Size   int64     `json:"size"`
+	Type   string    `json:"type,omitempty"` // MIME type
+	By     string    `json:"by,omitempty"`
+	At     time.Time `json:"at"`
+}
+
+// Key returns the attachment's blob store key.
+func (a Attachment) Key() string {
+	return blobKey(a.Digest)
+}
+
+// ShortDigest is the first 12 hex digits of the digest, for display.
+func (a Attachment) ShortDigest() string {
+	hex := strings.TrimPrefix(a.Digest, digestPrefix)
+	if len(hex) > 12 {
+		hex = hex[:12]
+	}
+	return hex
+}
+
+// blobKey spreads blobs over 256 directories by the digest's first byte.
+func blobKey(digest string) string {
+	hex := strings.TrimPrefix(digest, digestPrefix)
+	if len(hex) < 2 {
+		return "sha256/" + hex
+	}
+	return "sha256/" + hex[:2] + "/" + hex
+}
+
+// Store saves and retrieves attachment contents.
+type Store struct {
+	blobs blobstore.Store
+}
+
+// New returns a store over blobs.
+func New(blobs blobstore.Store) *Store {
+	return &Store{blobs: blobs}
+}
+
+// OpenTown returns the town's attachment store, as configured by the
+// attachments (or blob_store) section of the town settings.
+func OpenTown(townRoot string) (*Store, error) {
+	blobs, err := blobstore.OpenTownClass(townRoot, blobstore.ClassAttachments)
+	if err != nil {
+		return nil, fmt.Errorf("attachment store: %w", err)
+	}
+	return New(blobs), nil
+}
+
+// Blobs returns the underlying blob store.
+func (s *Store) Blobs() blobstore.Store {
+	return s.blobs
+}
+
+// LocalPath returns the file holding an attachment when the store is a
+// local directory, or "" otherwise.
+func (s *Store) LocalPath(a Attachment) string {
+	l, ok := s.blobs.(*blobstore.Local)
+	if !ok {
+		return ""
+	}
+	p, err := l.Path(a.Key())
+	if err != nil {
+		return ""
+	}
+	return p
+}
+
+// Add stores the file at path (unless identical content is already stored)
+// and returns its attachment record.
+func (s *Store) Add(ctx context.Context, path, by string) (*Attachment, error) {
+	f, err := os.Open(path) //nolint:gosec // G304: the user names the file to attach
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if !fi.Mode().IsRegular() {
+		return nil, fmt.Errorf("%s is not a regular file", path)
+	}
+
+	h := sha256.New()
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(f, head)
+	h.Write(head[:n])
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	a := &Attachment{
+		Digest: digestPrefix + hex.EncodeToString(h.Sum(nil)),
+		Name:   filepath.Base(path),
+		Size:   fi.Size(),
+		Type:   detectType(path, head[:n]),
+		By:     by,
+		At:     time.Now().UTC().Truncate(time.Second),
+	}
+
+	if _, err := s.blobs.Stat(ctx, a.Key()); err == nil {
+		return a, nil
+	} else if !errors.Is(err, blobstore.ErrNotFound) {
+		return nil, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if err := s.blobs.Put(ctx, a.Key(), f, a.Size); err != nil {
+		return nil, fmt.Errorf("storing %s: %w", a.Name, err)
+	}
+	return a, nil
+}
+
+// Open returns the contents of an attachment.
+func (s *Store) Open(ctx context.Context, a Attachment) (io.ReadCloser, error) {
+	return s.blobs.Get(ctx, a.Key())
+}
+
+// detectType guesses a MIME type from the extension, then the content.
+func detectType(path string, head []byte) string {
+	if t := mime.TypeByExtension(filepath.Ext(path)); t != "" {
+		return t
+	}
+	if len(head) == 0 {
+		return ""
+	}
+	return http.DetectContentType(head)
+}
+
+// Parse reads the attachments listed in a bead description. Lines that
+// don't decode are skipped.
+func Parse(description string) []Attachment {
+	var list []Attachment
+	for _, line := range strings.Split(beads.GetDescriptionSection(description, Section), "\n") {
+		var a Attachment
+		if line = strings.TrimSpace(line); line == "" || json.Unmarshal([]byte(line), &a) != nil {
+			continue
+		}
+		list = append(list, a)
+	}
+	return list
+}
+
+// Link returns description with a listed. The same content under the same
+// name is listed once; the description is returned unchanged if so.
+func Link(description string, a Attachment) (string, error) {
+	list := Parse(description)
+	for _, existing := range list {
+		if existing.Digest == a.Digest && existing.Name == a.Name {
+			return description, nil
+		}
+	}
+	list = append(list, a)
+	lines := make([]string, 0, len(list))
+	for _, item := range list {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, string(data))
+	}
+	return beads.SetDescriptionSection(description, Section, strings.Join(lines, "\n")), nil
+}
+
+// Find returns the attachment matching ref: a file name, or a digest or
+// its prefix (with or without "sha256:"). Ambiguous refs are an error.
+func Find(list []Attachment, ref string) (*Attachment, error) {
+	hexRef := strings.TrimPrefix(ref, digestPrefix)
+	var found []Attachment
+	for _, a := range list {
+		if a.Name == ref {
+			return &a, nil
+		}
+		if len(hexRef) >= 4 && strings.HasPrefix(strings.TrimPrefix(a.Digest, digestPrefix), hexRef) {
+			found = append(found, a)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("no attachment matching %q", ref)
+	case 1:
+		return &found[0], nil
+	}
+	return nil, fmt.Errorf("%q matches %d attachments; use more of the digest", ref, len(found))
+}
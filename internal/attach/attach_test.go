@@ -0,0 +1,119 @@
+package attach
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/blobstore"
+)
+
+func TestAdd(t *testing.T) {
+	dir := t.TempDir()
+	store := New(blobstore.NewLocal(filepath.Join(dir, "attachments")))
+	log := filepath.Join(dir, "crash.log")
+	if err := os.WriteFile(log, []byte("panic: boom\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	a, err := store.Add(ctx, log, "gastown/polecats/nux")
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if !strings.HasPrefix(a.Digest, "sha256:") || a.Name != "crash.log" || a.Size != 12 || a.By != "gastown/polecats/nux" {
+		t.Errorf("attachment = %+v", a)
+	}
+	if !strings.HasPrefix(a.Type, "text/") {
+		t.Errorf("type = %q, want text/*", a.Type)
+	}
+
+	// Same content under another name is stored once.
+	copyPath := filepath.Join(dir, "again.txt")
+	if err := os.WriteFile(copyPath, []byte("panic: boom\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	b, err := store.Add(ctx, copyPath, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Digest != a.Digest {
+		t.Errorf("digests differ for identical content: %s vs %s", a.Digest, b.Digest)
+	}
+	infos, _ := store.Blobs().List(ctx, "")
+	if len(infos) != 1 {
+		t.Errorf("stored %d blobs, want 1", len(infos))
+	}
+
+	rc, err := store.Open(ctx, *a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(rc)
+	_ = rc.Close()
+	if string(data) != "panic: boom\n" {
+		t.Errorf("Open = %q", data)
+	}
+	if p := store.LocalPath(*a); !strings.HasSuffix(p, filepath.FromSlash(a.Key())) {
+		t.Errorf("LocalPath = %q", p)
+	}
+
+	if _, err := store.Add(ctx, dir, ""); err == nil {
+		t.Error("Add(directory): expected error")
+	}
+}
+
+func TestLinkAndParse(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	a := Attachment{Digest: "sha256:abcdef0123", Name: "crash.log", Size: 12, At: at}
+	b := Attachment{Digest: "sha256:abcd99", Name: "shot.png", Size: 3, At: at}
+
+	desc, err := Link("Login fails.", a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if desc, err = Link(desc, b); err != nil {
+		t.Fatal(err)
+	}
+	again, err := Link(desc, a)
+	if err != nil || again != desc {
+		t.Errorf("relinking changed the description:\n%s", again)
+	}
+	if !strings.HasPrefix(desc, "Login fails.") {
+		t.Errorf("prose lost:\n%s", desc)
+	}
+
+	list := Parse(desc)
+	if len(list) != 2 || list[0].Name != "crash.log" || list[1].Name != "shot.png" || !list[0].At.Equal(at) {
+		t.Fatalf("Parse = %+v", list)
+	}
+
+	tests := []struct {
+		ref     string
+		want    string
+		wantErr bool
+	}{
+		{"shot.png", "shot.png", false},
+		{"abcdef", "crash.log", false},
+		{"sha256:abcd99", "shot.png", false},
+		{"abcd", "", true}, // Ambiguous
+		{"ab", "", true},   // Too short to match digests
+		{"missing.txt", "", true},
+	}
+	for _, tt := range tests {
+		got, err := Find(list, tt.ref)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Find(%q) = %s, want error", tt.ref, got.Name)
+			}
+			continue
+		}
+		if err != nil || got.Name != tt.want {
+			t.Errorf("Find(%q) = %v, %v; want %s", tt.ref, got, err, tt.want)
+		}
+	}
+}
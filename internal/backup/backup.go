@@ -0,0 +1,343 @@
+// Package backup snapshots a town's state into its backup blob store and
+// restores it, so a town on an ephemeral machine can be rebuilt elsewhere.
+//
+// A backup is a tar.gz of the files that aren't recoverable from git: town
+// and rig configuration, beads databases (including the Dolt data
+// directory), memos, and the event log. Rig clones and worktrees are left
+// out; 'gt rig add' re-creates them from their remotes.
+//
+// Backups are stored under "<town name>/<UTC timestamp>.tar.gz", so towns
+// can share a bucket.
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/blobstore"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Latest names the newest backup in Find.
+const Latest = "latest"
+
+// timeFormat stamps backup keys; it sorts chronologically.
+const timeFormat = "20060102-150405"
+
+// suffix ends every backup key.
+const suffix = ".tar.gz"
+
+// Paths are the town-relative globs a backup covers. Directories are
+// included recursively.
+var Paths = []string{
+	"mayor/*.json",
+	"settings",
+	".beads",
+	".dolt-data",
+	"memos",
+	".events.jsonl",
+	"*/config.json",
+	"*/settings",
+	"*/.beads",
+	"*/mayor/rig/.beads",
+}
+
+// skip reports whether a file is runtime state that mustn't be restored:
+// locks, sockets, and PID files.
+func skip(name string) bool {
+	return name == "LOCK" || strings.HasSuffix(name, ".lock") ||
+		strings.HasSuffix(name, ".sock") || strings.HasSuffix(name, ".pid")
+}
+
+// Key returns the store key for a town's backup taken at t.
+func Key(town string, t time.Time) string {
+	return town + "/" + t.UTC().Format(timeFormat) + suffix
+}
+
+// Create writes a tar.gz of the town's state to w and returns the number of
+// files in it. Only regular files are archived; symlinks, sockets, and
+// runtime locks are skipped.
+func Create(townRoot string, w io.Writer) (int, error) {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	seen := make(map[string]bool)
+	count := 0
+
+	add := func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || !d.Type().IsRegular() || skip(d.Name()) {
+			return nil
+		}
+		rel, err := filepath.Rel(townRoot, p)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if seen[name] {
+			return nil
+		}
+		seen[name] = true
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if err := addFile(tw, p, name, info); err != nil {
+			return fmt.Errorf("archiving %s: %w", name, err)
+		}
+		count++
+		return nil
+	}
+
+	for _, glob := range Paths {
+		matches, err := filepath.Glob(filepath.Join(townRoot, glob))
+		if err != nil {
+			return count, err
+		}
+		sort.Strings(matches)
+		for _, m := range matches {
+			if err := filepath.WalkDir(m, add); err != nil {
+				return count, err
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return count, err
+	}
+	return count, gz.Close()
+}
+
+// addFile copies one file into the archive. The file is read whole first,
+// so a live file (the event log) changing mid-copy can't make the header's
+// size disagree with the data.
+func addFile(tw *tar.Writer, p, name string, info fs.FileInfo) error {
+	data, err := os.ReadFile(p) //nolint:gosec // G304: p comes from walking the town's state paths
+	if err != nil {
+		return err
+	}
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    int64(info.Mode().Perm()),
+		Size:    int64(len(data)),
+		ModTime: info.ModTime(),
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err = tw.Write(data)
+	return err
+}
+
+// Restore extracts a backup into dest. Files are first extracted to a
+// staging directory inside dest, so a corrupt archive or a refused
+// overwrite leaves dest untouched. Existing files are only replaced when
+// overwrite is set. It returns the restored paths, relative to dest.
+func Restore(r io.Reader, dest string, overwrite bool) ([]string, error) {
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return nil, err
+	}
+	staging, err := os.MkdirTemp(dest, ".gt-restore-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(staging)
+
+	names, err := extract(r, staging)
+	if err != nil {
+		return nil, err
+	}
+
+	var conflicts []string
+	for _, name := range names {
+		if _, err := os.Lstat(filepath.Join(dest, filepath.FromSlash(name))); err == nil {
+			conflicts = append(conflicts, name)
+		}
+	}
+	if len(conflicts) > 0 && !overwrite {
+		shown := conflicts
+		if len(shown) > 5 {
+			shown = append(shown[:5:5], "...")
+		}
+		return nil, fmt.Errorf("%d file(s) already exist in %s (%s); restore with --force to replace them",
+			len(conflicts), dest, strings.Join(shown, ", "))
+	}
+
+	for _, name := range names {
+		from := filepath.Join(staging, filepath.FromSlash(name))
+		to := filepath.Join(dest, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(to), 0755); err != nil {
+			return nil, err
+		}
+		if err := os.Rename(from, to); err != nil {
+			return nil, fmt.Errorf("restoring %s: %w", name, err)
+		}
+	}
+	return names, nil
+}
+
+// extract unpacks the archive's regular files under dir, rejecting entries
+// that would land outside it.
+func extract(r io.Reader, dir string) ([]string, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading backup: %w", err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+
+	var names []string
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading backup: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := path.Clean(hdr.Name)
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return nil, fmt.Errorf("backup entry %q escapes the destination", hdr.Name)
+		}
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return nil, err
+		}
+		f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, fs.FileMode(hdr.Mode).Perm()|0600) //nolint:gosec // G304: target is checked to stay under dir
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.Copy(f, tr); err != nil { //nolint:gosec // G110: backups are our own archives
+			_ = f.Close()
+			return nil, err
+		}
+		if err := f.Close(); err != nil {
+			return nil, err
+		}
+		_ = os.Chtimes(target, hdr.ModTime, hdr.ModTime)
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// Run backs up a town to its backup store and returns the stored object.
+func Run(ctx context.Context, townRoot string) (*blobstore.Info, error) {
+	store, err := blobstore.OpenTownClass(townRoot, blobstore.ClassBackups)
+	if err != nil {
+		return nil, fmt.Errorf("backup store: %w", err)
+	}
+	town, err := workspace.GetTownName(townRoot)
+	if err != nil {
+		return nil, err
+	}
+	return Upload(ctx, townRoot, store, Key(town, time.Now()))
+}
+
+// Upload archives the town to a temp file, then stores it at key. The temp
+// file lets remote stores get the size up front.
+func Upload(ctx context.Context, townRoot string, store blobstore.Store, key string) (*blobstore.Info, error) {
+	tmp, err := os.CreateTemp("", "gt-backup-*"+suffix)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if _, err := Create(townRoot, tmp); err != nil {
+		return nil, err
+	}
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if err := store.Put(ctx, key, tmp, size); err != nil {
+		return nil, fmt.Errorf("storing backup: %w", err)
+	}
+	return store.Stat(ctx, key)
+}
+
+// List returns the backups in a store, newest first. An empty town lists
+// every town's backups.
+func List(ctx context.Context, store blobstore.Store, town string) ([]blobstore.Info, error) {
+	prefix := ""
+	if town != "" {
+		prefix = town + "/"
+	}
+	infos, err := store.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	var backups []blobstore.Info
+	for _, info := range infos {
+		if strings.HasSuffix(info.Key, suffix) && strings.Contains(info.Key, "/") {
+			backups = append(backups, info)
+		}
+	}
+	// Keys embed their timestamp, so key order is age order within a town.
+	sort.SliceStable(backups, func(i, j int) bool {
+		return Stamp(backups[i].Key) > Stamp(backups[j].Key)
+	})
+	return backups, nil
+}
+
+// Stamp returns the timestamp part of a backup key.
+func Stamp(key string) string {
+	return strings.TrimSuffix(path.Base(key), suffix)
+}
+
+// Find resolves ref to a backup: Latest, a full key, or a timestamp (with
+// or without the .tar.gz suffix). Without a town, the match must be
+// unambiguous across towns.
+func Find(ctx context.Context, store blobstore.Store, town, ref string) (*blobstore.Info, error) {
+	backups, err := List(ctx, store, town)
+	if err != nil {
+		return nil, err
+	}
+	if len(backups) == 0 {
+		return nil, fmt.Errorf("no backups in %s", store)
+	}
+
+	var found []blobstore.Info
+	if ref == Latest {
+		towns := make(map[string]bool)
+		for _, b := range backups {
+			if t := path.Dir(b.Key); !towns[t] {
+				towns[t] = true
+				found = append(found, b)
+			}
+		}
+	} else {
+		for _, b := range backups {
+			if b.Key == ref || Stamp(b.Key) == strings.TrimSuffix(ref, suffix) {
+				found = append(found, b)
+			}
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("no backup matching %q in %s", ref, store)
+	case 1:
+		return &found[0], nil
+	}
+	return nil, fmt.Errorf("%q matches backups of %d towns; name the town", ref, len(found))
+}
@@ -0,0 +1,169 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/blobstore"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newTown lays out town state plus files a backup must leave out.
+func newTown(t *testing.T) string {
+	t.Helper()
+	town := t.TempDir()
+	writeFile(t, filepath.Join(town, "mayor", "town.json"), `{"type":"town","version":2,"name":"home"}`)
+	writeFile(t, filepath.Join(town, "settings", "config.json"), `{}`)
+	writeFile(t, filepath.Join(town, ".beads", "beads.db"), "db")
+	writeFile(t, filepath.Join(town, ".beads", "daemon.lock"), "lock")
+	writeFile(t, filepath.Join(town, ".dolt-data", "hq", ".dolt", "noms", "LOCK"), "")
+	writeFile(t, filepath.Join(town, ".dolt-data", "hq", ".dolt", "noms", "manifest"), "m")
+	writeFile(t, filepath.Join(town, ".events.jsonl"), "{}\n")
+	writeFile(t, filepath.Join(town, "gastown", "config.json"), `{"type":"rig"}`)
+	writeFile(t, filepath.Join(town, "gastown", "mayor", "rig", ".beads", "issues.jsonl"), "{}\n")
+	writeFile(t, filepath.Join(town, "gastown", "mayor", "rig", "main.go"), "package main")
+	writeFile(t, filepath.Join(town, "logs", "town.log"), "log")
+	return town
+}
+
+var wantFiles = []string{
+	".beads/beads.db",
+	".dolt-data/hq/.dolt/noms/manifest",
+	".events.jsonl",
+	"gastown/config.json",
+	"gastown/mayor/rig/.beads/issues.jsonl",
+	"mayor/town.json",
+	"settings/config.json",
+}
+
+func TestCreateRestore(t *testing.T) {
+	town := newTown(t)
+	var buf bytes.Buffer
+	n, err := Create(town, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != len(wantFiles) {
+		t.Errorf("Create archived %d files, want %d", n, len(wantFiles))
+	}
+
+	dest := t.TempDir()
+	restored, err := Restore(bytes.NewReader(buf.Bytes()), dest, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(restored)
+	if strings.Join(restored, "\n") != strings.Join(wantFiles, "\n") {
+		t.Errorf("restored:\n%s\nwant:\n%s", strings.Join(restored, "\n"), strings.Join(wantFiles, "\n"))
+	}
+	if data, _ := os.ReadFile(filepath.Join(dest, "gastown", "config.json")); string(data) != `{"type":"rig"}` {
+		t.Errorf("restored rig config = %q", data)
+	}
+	entries, _ := os.ReadDir(dest)
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".gt-restore-") {
+			t.Errorf("staging directory %s left behind", e.Name())
+		}
+	}
+
+	// A second restore conflicts with every file and changes nothing.
+	writeFile(t, filepath.Join(dest, "settings", "config.json"), `{"local":true}`)
+	if _, err := Restore(bytes.NewReader(buf.Bytes()), dest, false); err == nil {
+		t.Fatal("restore over existing files: expected error")
+	}
+	if data, _ := os.ReadFile(filepath.Join(dest, "settings", "config.json")); string(data) != `{"local":true}` {
+		t.Errorf("refused restore modified a file: %q", data)
+	}
+	if _, err := Restore(bytes.NewReader(buf.Bytes()), dest, true); err != nil {
+		t.Fatalf("restore with overwrite: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dest, "settings", "config.json")); string(data) != `{}` {
+		t.Errorf("overwrite restore left %q", data)
+	}
+}
+
+func TestRestore_RejectsEscapingEntries(t *testing.T) {
+	for _, name := range []string{"../evil", "/etc/evil", "a/../../evil"} {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gz)
+		_ = tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: 1, Typeflag: tar.TypeReg})
+		_, _ = tw.Write([]byte("x"))
+		_ = tw.Close()
+		_ = gz.Close()
+
+		dest := filepath.Join(t.TempDir(), "town")
+		if _, err := Restore(&buf, dest, true); err == nil {
+			t.Errorf("Restore accepted entry %q", name)
+		}
+	}
+}
+
+func TestUploadListFind(t *testing.T) {
+	town := newTown(t)
+	store := blobstore.NewLocal(filepath.Join(t.TempDir(), "backups"))
+	ctx := context.Background()
+	t0 := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	for _, key := range []string{Key("home", t0), Key("home", t0.Add(time.Hour)), Key("other", t0)} {
+		if _, err := Upload(ctx, town, store, key); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backups, err := List(ctx, store, "home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 || backups[0].Key != "home/20261015-130000.tar.gz" {
+		t.Fatalf("List = %+v, want 2 home backups, newest first", backups)
+	}
+
+	tests := []struct {
+		town, ref, want string
+	}{
+		{"home", Latest, "home/20261015-130000.tar.gz"},
+		{"home", "20261015-120000", "home/20261015-120000.tar.gz"},
+		{"", "other/20261015-120000.tar.gz", "other/20261015-120000.tar.gz"},
+		{"", Latest, ""},            // ambiguous across towns
+		{"", "20261015-120000", ""}, // ambiguous across towns
+		{"home", "20200101-000000", ""},
+	}
+	for _, tt := range tests {
+		info, err := Find(ctx, store, tt.town, tt.ref)
+		switch {
+		case tt.want == "" && err == nil:
+			t.Errorf("Find(%q, %q) = %s, want error", tt.town, tt.ref, info.Key)
+		case tt.want != "" && err != nil:
+			t.Errorf("Find(%q, %q): %v", tt.town, tt.ref, err)
+		case tt.want != "" && info.Key != tt.want:
+			t.Errorf("Find(%q, %q) = %s, want %s", tt.town, tt.ref, info.Key, tt.want)
+		}
+	}
+
+	rc, err := store.Get(ctx, backups[0].Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	restored, err := Restore(rc, t.TempDir(), false)
+	if err != nil || len(restored) != len(wantFiles) {
+		t.Errorf("restoring an uploaded backup: %d files, %v", len(restored), err)
+	}
+}
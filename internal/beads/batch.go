@@ -0,0 +1,121 @@
+package beads
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Batch answers a run of read queries against one database with as few bd
+// invocations as it can, for commands that would otherwise start bd once
+// per query: identical lists share one invocation, and the issues queued
+// with Want are fetched together by a single bd show.
+//
+// A Batch keeps what it reads, so use one for a single command run and
+// not across writes. A Batch is safe for concurrent use.
+type Batch struct {
+	list func(ListOptions) ([]*Issue, error)
+	show func([]string) (map[string]*Issue, error)
+
+	mu     sync.Mutex
+	lists  map[ListOptions]*batchList
+	wanted []string
+	shown  map[string]*Issue
+	tried  map[string]bool // IDs a bd show has asked for, found or not
+}
+
+type batchList struct {
+	once   sync.Once
+	issues []*Issue
+	err    error
+}
+
+// Batch returns a batch of queries against b's database.
+func (b *Beads) Batch() *Batch {
+	return newBatch(b.List, b.ShowMultiple)
+}
+
+func newBatch(list func(ListOptions) ([]*Issue, error), show func([]string) (map[string]*Issue, error)) *Batch {
+	return &Batch{
+		list:  list,
+		show:  show,
+		lists: make(map[ListOptions]*batchList),
+		shown: make(map[string]*Issue),
+		tried: make(map[string]bool),
+	}
+}
+
+// List returns the issues matching opts, running bd once however many
+// times the same opts are asked for.
+func (bt *Batch) List(opts ListOptions) ([]*Issue, error) {
+	bt.mu.Lock()
+	l, ok := bt.lists[opts]
+	if !ok {
+		l = &batchList{}
+		bt.lists[opts] = l
+	}
+	bt.mu.Unlock()
+
+	l.once.Do(func() { l.issues, l.err = bt.list(opts) })
+	return l.issues, l.err
+}
+
+// Want queues issues for the next Show, which fetches them all at once.
+func (bt *Batch) Want(ids ...string) {
+	bt.mu.Lock()
+	defer bt.mu.Unlock()
+	for _, id := range ids {
+		if !bt.tried[id] {
+			bt.wanted = append(bt.wanted, id)
+		}
+	}
+}
+
+// Show returns the issue with id. Unless it was already fetched, one bd
+// show fetches it along with every issue queued by Want. A missing issue
+// is ErrNotFound.
+func (bt *Batch) Show(id string) (*Issue, error) {
+	bt.mu.Lock()
+	defer bt.mu.Unlock()
+
+	if !bt.tried[id] {
+		ids := []string{id}
+		for _, w := range bt.wanted {
+			if w != id && !bt.tried[w] {
+				ids = append(ids, w)
+			}
+		}
+		bt.wanted = nil
+		found, err := bt.show(ids)
+		if err != nil && len(ids) > 1 {
+			// One bad ID can fail the whole show; fall back to this one
+			// and leave the rest for their own Show
+			ids = ids[:1]
+			found, err = bt.show(ids)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			bt.tried[id] = true
+			if issue, ok := found[id]; ok {
+				bt.shown[id] = issue
+			}
+		}
+	}
+
+	if issue, ok := bt.shown[id]; ok {
+		return issue, nil
+	}
+	return nil, ErrNotFound
+}
+
+// FindHandoffBead is Beads.FindHandoffBead from the batch's list of pinned
+// issues, so finding the handoff beads of every agent in a rig runs bd
+// once.
+func (bt *Batch) FindHandoffBead(role string) (*Issue, error) {
+	issues, err := bt.List(ListOptions{Status: StatusPinned, Priority: -1})
+	if err != nil {
+		return nil, fmt.Errorf("listing pinned issues: %w", err)
+	}
+	return findHandoff(issues, role), nil
+}
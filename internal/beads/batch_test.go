@@ -0,0 +1,101 @@
+package beads
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestBatchList(t *testing.T) {
+	calls := 0
+	var mu sync.Mutex
+	list := func(opts ListOptions) ([]*Issue, error) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		return []*Issue{
+			{ID: "gt-1", Title: HandoffBeadTitle("witness")},
+			{ID: "gt-2", Title: HandoffBeadTitle("refinery")},
+		}, nil
+	}
+	bt := newBatch(list, nil)
+
+	var wg sync.WaitGroup
+	for _, role := range []string{"witness", "refinery", "Toast", "witness"} {
+		wg.Add(1)
+		go func(role string) {
+			defer wg.Done()
+			if _, err := bt.FindHandoffBead(role); err != nil {
+				t.Error(err)
+			}
+		}(role)
+	}
+	wg.Wait()
+	if calls != 1 {
+		t.Errorf("bd list ran %d times, want 1", calls)
+	}
+
+	if h, _ := bt.FindHandoffBead("refinery"); h == nil || h.ID != "gt-2" {
+		t.Errorf("FindHandoffBead(refinery) = %v, want gt-2", h)
+	}
+	if _, err := bt.List(ListOptions{Status: "open"}); err != nil || calls != 2 {
+		t.Errorf("different opts: err %v after %d calls, want a second call", err, calls)
+	}
+}
+
+func TestBatchShow(t *testing.T) {
+	db := map[string]*Issue{"gt-1": {ID: "gt-1"}, "gt-2": {ID: "gt-2"}, "gt-3": {ID: "gt-3"}}
+	var calls [][]string
+	show := func(ids []string) (map[string]*Issue, error) {
+		calls = append(calls, append([]string(nil), ids...))
+		found := make(map[string]*Issue)
+		for _, id := range ids {
+			if id == "gt-bad" {
+				return nil, errors.New("bd show: invalid id")
+			}
+			if issue, ok := db[id]; ok {
+				found[id] = issue
+			}
+		}
+		return found, nil
+	}
+
+	t.Run("queued ids share one show", func(t *testing.T) {
+		calls = nil
+		bt := newBatch(nil, show)
+		bt.Want("gt-1", "gt-2", "gt-9")
+		for _, id := range []string{"gt-2", "gt-1"} {
+			if issue, err := bt.Show(id); err != nil || issue.ID != id {
+				t.Errorf("Show(%s) = %v, %v", id, issue, err)
+			}
+		}
+		if _, err := bt.Show("gt-9"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Show(gt-9) = %v, want ErrNotFound", err)
+		}
+		if len(calls) != 1 {
+			t.Fatalf("bd show ran %d times, want 1: %v", len(calls), calls)
+		}
+		got := append([]string(nil), calls[0]...)
+		sort.Strings(got)
+		if want := []string{"gt-1", "gt-2", "gt-9"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("bd show asked for %v, want %v", got, want)
+		}
+	})
+
+	t.Run("bad id falls back", func(t *testing.T) {
+		calls = nil
+		bt := newBatch(nil, show)
+		bt.Want("gt-3", "gt-bad")
+		if issue, err := bt.Show("gt-3"); err != nil || issue.ID != "gt-3" {
+			t.Errorf("Show(gt-3) = %v, %v", issue, err)
+		}
+		if _, err := bt.Show("gt-bad"); err == nil {
+			t.Error("Show(gt-bad) succeeded, want an error")
+		}
+		if len(calls) != 3 {
+			t.Errorf("bd show ran %d times, want 3 (batch, fallback, bad id): %v", len(calls), calls)
+		}
+	})
+}
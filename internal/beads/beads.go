@@ -3,6 +3,7 @@ package beads
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -12,6 +13,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/steveyegge/gastown/internal/replay"
 	"github.com/steveyegge/gastown/internal/runtime"
 )
 
@@ -35,6 +37,7 @@ type Issue struct {
 	CreatedBy   string   `json:"created_by,omitempty"`
 	UpdatedAt   string   `json:"updated_at"`
 	ClosedAt    string   `json:"closed_at,omitempty"`
+	CloseReason string   `json:"close_reason,omitempty"`
 	Parent      string   `json:"parent,omitempty"`
 	Assignee    string   `json:"assignee,omitempty"`
 	Children    []string `json:"children,omitempty"`
@@ -57,6 +60,9 @@ type Issue struct {
 	// Detailed dependency info from show output
 	Dependencies []IssueDep `json:"dependencies,omitempty"`
 	Dependents   []IssueDep `json:"dependents,omitempty"`
+
+	// Custom field values, decoded from field labels by FillCustomFields
+	Fields map[string]string `json:"fields,omitempty"`
 }
 
 // HasLabel checks if an issue has a specific label.
@@ -88,6 +94,7 @@ type ListOptions struct {
 	Parent     string // filter by parent ID
 	Assignee   string // filter by assignee (e.g., "gastown/Toast")
 	NoAssignee bool   // filter for issues with no assignee
+	Limit      int    // at most this many issues (bd --limit); 0 for bd's default
 }
 
 // CreateOptions specifies options for creating an issue.
@@ -97,8 +104,9 @@ type CreateOptions struct {
 	Priority    int    // 0-4
 	Description string
 	Parent      string
-	Actor       string // Who is creating this issue (populates created_by)
-	Ephemeral   bool   // Create as ephemeral (wisp) - not exported to JSONL
+	Actor       string   // Who is creating this issue (populates created_by)
+	Ephemeral   bool     // Create as ephemeral (wisp) - not exported to JSONL
+	Labels      []string // Additional labels (appended after the gt:<type> label)
 }
 
 // UpdateOptions specifies options for updating an issue.
@@ -124,8 +132,9 @@ type SyncStatus struct {
 // Beads wraps bd CLI operations for a working directory.
 type Beads struct {
 	workDir  string
-	beadsDir string // Optional BEADS_DIR override for cross-database access
-	isolated bool   // If true, suppress inherited beads env vars (for test isolation)
+	beadsDir string   // Optional BEADS_DIR override for cross-database access
+	isolated bool     // If true, suppress inherited beads env vars (for test isolation)
+	breaker  *Breaker // Optional: skip calls once this database's bd keeps failing
 
 	// Lazy-cached town root for routing resolution.
 	// Populated on first call to getTownRoot() to avoid filesystem walk on every operation.
@@ -190,6 +199,43 @@ func (b *Beads) Init(prefix string) error {
 
 // run executes a bd command and returns stdout.
 func (b *Beads) run(args ...string) ([]byte, error) {
+	if err := b.breaker.Allow(b); err != nil {
+		return nil, err
+	}
+	out, err := b.runOnce(args...)
+	b.breaker.Record(b, err)
+	return out, err
+}
+
+func (b *Beads) runOnce(args ...string) ([]byte, error) {
+	cmd := b.command(args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := replay.Run(cmd)
+	if err != nil {
+		return nil, b.wrapError(err, stderr.String(), args)
+	}
+
+	// Handle bd --no-daemon exit code 0 bug: when issue not found,
+	// --no-daemon exits 0 but writes error to stderr with empty stdout.
+	// Detect this case and treat as error to avoid JSON parse failures.
+	if stdout.Len() == 0 && stderr.Len() > 0 {
+		return nil, b.wrapError(fmt.Errorf("command produced no output"), stderr.String(), args)
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// command builds the exec.Cmd for a bd invocation, with the flags and
+// environment every call uses.
+func (b *Beads) command(args ...string) *exec.Cmd {
+	return b.commandContext(context.Background(), args...)
+}
+
+// commandContext is command for a call that ctx can cut short.
+func (b *Beads) commandContext(ctx context.Context, args ...string) *exec.Cmd {
 	// Use --no-daemon for faster read operations (avoids daemon IPC overhead)
 	// The daemon is primarily useful for write coalescing, not reads.
 	// Use --allow-stale to prevent failures when db is out of sync with JSONL
@@ -199,10 +245,7 @@ func (b *Beads) run(args ...string) ([]byte, error) {
 	// Always explicitly set BEADS_DIR to prevent inherited env vars from
 	// causing prefix mismatches. Use explicit beadsDir if set, otherwise
 	// resolve from working directory.
-	beadsDir := b.beadsDir
-	if beadsDir == "" {
-		beadsDir = ResolveBeadsDir(b.workDir)
-	}
+	beadsDir := b.resolvedBeadsDir()
 
 	// In isolated mode, use --db flag to force specific database path
 	// This bypasses bd's routing logic that can redirect to .beads-planning
@@ -213,7 +256,7 @@ func (b *Beads) run(args ...string) ([]byte, error) {
 		fullArgs = append([]string{"--db", beadsDB}, fullArgs...)
 	}
 
-	cmd := exec.Command("bd", fullArgs...) //nolint:gosec // G204: bd is a trusted internal tool
+	cmd := exec.CommandContext(ctx, "bd", fullArgs...) //nolint:gosec // G204: bd is a trusted internal tool
 	cmd.Dir = b.workDir
 
 	// Build environment: filter beads env vars when in isolated mode (tests)
@@ -225,24 +268,16 @@ func (b *Beads) run(args ...string) ([]byte, error) {
 		env = os.Environ()
 	}
 	cmd.Env = append(env, "BEADS_DIR="+beadsDir)
+	return cmd
+}
 
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	err := cmd.Run()
-	if err != nil {
-		return nil, b.wrapError(err, stderr.String(), args)
-	}
-
-	// Handle bd --no-daemon exit code 0 bug: when issue not found,
-	// --no-daemon exits 0 but writes error to stderr with empty stdout.
-	// Detect this case and treat as error to avoid JSON parse failures.
-	if stdout.Len() == 0 && stderr.Len() > 0 {
-		return nil, b.wrapError(fmt.Errorf("command produced no output"), stderr.String(), args)
+// resolvedBeadsDir is the BEADS_DIR calls run with: the explicit override,
+// or the one the working directory resolves to.
+func (b *Beads) resolvedBeadsDir() string {
+	if b.beadsDir != "" {
+		return b.beadsDir
 	}
-
-	return stdout.Bytes(), nil
+	return ResolveBeadsDir(b.workDir)
 }
 
 // Run executes a bd command and returns stdout.
@@ -324,6 +359,9 @@ func (b *Beads) List(opts ListOptions) ([]*Issue, error) {
 	if opts.NoAssignee {
 		args = append(args, "--no-assignee")
 	}
+	if opts.Limit > 0 {
+		args = append(args, fmt.Sprintf("--limit=%d", opts.Limit))
+	}
 
 	out, err := b.run(args...)
 	if err != nil {
@@ -348,6 +386,21 @@ func (b *Beads) ListByAssignee(assignee string) ([]*Issue, error) {
 	})
 }
 
+// ListActive lists the issues matching opts that are still to be done:
+// open, in progress, or hooked. opts.Status is ignored.
+func (b *Beads) ListActive(opts ListOptions) ([]*Issue, error) {
+	var active []*Issue
+	for _, status := range []string{"open", "in_progress", StatusHooked} {
+		opts.Status = status
+		issues, err := b.List(opts)
+		if err != nil {
+			return nil, err
+		}
+		active = append(active, issues...)
+	}
+	return active, nil
+}
+
 // GetAssignedIssue returns the first open or hooked issue assigned to the given assignee.
 // Returns nil if no open or hooked issue is assigned.
 func (b *Beads) GetAssignedIssue(assignee string) (*Issue, error) {
@@ -393,7 +446,14 @@ func (b *Beads) GetAssignedIssue(assignee string) (*Issue, error) {
 
 // Ready returns issues that are ready to work (not blocked).
 func (b *Beads) Ready() ([]*Issue, error) {
-	out, err := b.run("ready", "--json")
+	return b.ReadyLimit(0)
+}
+
+// ReadyLimit returns the first limit ready issues in priority order, so
+// bd stops early instead of returning the whole backlog. A zero limit
+// returns them all, in bd's default order.
+func (b *Beads) ReadyLimit(limit int) ([]*Issue, error) {
+	out, err := b.run(readyArgs(limit)...)
 	if err != nil {
 		return nil, err
 	}
@@ -494,9 +554,8 @@ func (b *Beads) Create(opts CreateOptions) (*Issue, error) {
 	if opts.Title != "" {
 		args = append(args, "--title="+opts.Title)
 	}
-	// Type is deprecated: convert to gt:<type> label
-	if opts.Type != "" {
-		args = append(args, "--labels=gt:"+opts.Type)
+	if labels := createLabels(opts); labels != "" {
+		args = append(args, "--labels="+labels)
 	}
 	if opts.Priority >= 0 {
 		args = append(args, fmt.Sprintf("--priority=%d", opts.Priority))
@@ -533,6 +592,18 @@ func (b *Beads) Create(opts CreateOptions) (*Issue, error) {
 	return &issue, nil
 }
 
+// createLabels builds the comma-separated --labels value for create.
+// Type is deprecated and converted to a gt:<type> label, followed by any
+// explicit labels.
+func createLabels(opts CreateOptions) string {
+	var labels []string
+	if opts.Type != "" {
+		labels = append(labels, "gt:"+opts.Type)
+	}
+	labels = append(labels, opts.Labels...)
+	return strings.Join(labels, ",")
+}
+
 // CreateWithID creates an issue with a specific ID.
 // This is useful for agent beads, role beads, and other beads that need
 // deterministic IDs rather than auto-generated ones.
@@ -545,9 +616,8 @@ func (b *Beads) CreateWithID(id string, opts CreateOptions) (*Issue, error) {
 	if opts.Title != "" {
 		args = append(args, "--title="+opts.Title)
 	}
-	// Type is deprecated: convert to gt:<type> label
-	if opts.Type != "" {
-		args = append(args, "--labels=gt:"+opts.Type)
+	if labels := createLabels(opts); labels != "" {
+		args = append(args, "--labels="+labels)
 	}
 	if opts.Priority >= 0 {
 		args = append(args, fmt.Sprintf("--priority=%d", opts.Priority))
@@ -722,6 +792,18 @@ func (b *Beads) SyncFromMain() error {
 	return err
 }
 
+// FlushJSONL exports the database to its JSONL file without syncing.
+func (b *Beads) FlushJSONL() error {
+	_, err := b.run("sync", "--flush-only")
+	return err
+}
+
+// ImportJSONL imports the JSONL file into the database without syncing.
+func (b *Beads) ImportJSONL() error {
+	_, err := b.run("sync", "--import-only")
+	return err
+}
+
 // GetSyncStatus returns the sync status without performing a sync.
 func (b *Beads) GetSyncStatus() (*SyncStatus, error) {
 	out, err := b.run("sync", "--status", "--json")
@@ -750,6 +832,38 @@ func (b *Beads) Stats() (string, error) {
 	return string(out), nil
 }
 
+// IssueCounts are bd's own counts of a database's issues.
+type IssueCounts struct {
+	Ready   int `json:"ready_issues"`
+	Blocked int `json:"blocked_issues"`
+}
+
+// Counts returns bd's counts of ready and blocked issues, so a total can
+// be had without reading every issue.
+func (b *Beads) Counts() (*IssueCounts, error) {
+	out, err := b.run("stats", "--json")
+	if err != nil {
+		return nil, err
+	}
+	return parseIssueCounts(out)
+}
+
+// parseIssueCounts reads bd stats --json, which newer bd versions nest
+// under "summary".
+func parseIssueCounts(out []byte) (*IssueCounts, error) {
+	var stats struct {
+		IssueCounts
+		Summary *IssueCounts `json:"summary"`
+	}
+	if err := json.Unmarshal(out, &stats); err != nil {
+		return nil, fmt.Errorf("parsing bd stats output: %w", err)
+	}
+	if stats.Summary != nil {
+		return stats.Summary, nil
+	}
+	return &stats.IssueCounts, nil
+}
+
 // IsBeadsRepo checks if the working directory is a beads repository.
 // ZFC: Check file existence directly instead of parsing bd errors.
 func (b *Beads) IsBeadsRepo() bool {
@@ -0,0 +1,29 @@
+// Package beads provides issue comment operations.
+package beads
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Comment is a comment on an issue.
+type Comment struct {
+	ID        int    `json:"id"`
+	IssueID   string `json:"issue_id"`
+	Author    string `json:"author"`
+	Text      string `json:"text"`
+	CreatedAt string `json:"created_at"`
+}
+
+// Comments returns an issue's comments, oldest first.
+func (b *Beads) Comments(id string) ([]*Comment, error) {
+	out, err := b.run("comments", id, "--json")
+	if err != nil {
+		return nil, err
+	}
+	var comments []*Comment
+	if err := json.Unmarshal(out, &comments); err != nil {
+		return nil, fmt.Errorf("parsing bd comments output: %w", err)
+	}
+	return comments, nil
+}
@@ -0,0 +1,84 @@
+package beads
+
+import (
+	"sort"
+	"strings"
+)
+
+// CustomFieldLabelPrefix prefixes the labels that carry custom field values,
+// as "field:<name>=<value>". Labels keep the values filterable with
+// 'bd list --label'.
+const CustomFieldLabelPrefix = "field:"
+
+// CustomFieldLabel returns the label carrying a custom field value.
+func CustomFieldLabel(name, value string) string {
+	return CustomFieldLabelPrefix + name + "=" + value
+}
+
+// CustomFields decodes the custom field values in labels.
+func CustomFields(labels []string) map[string]string {
+	var fields map[string]string
+	for _, l := range labels {
+		rest, ok := strings.CutPrefix(l, CustomFieldLabelPrefix)
+		if !ok {
+			continue
+		}
+		name, value, ok := strings.Cut(rest, "=")
+		if !ok || name == "" {
+			continue
+		}
+		if fields == nil {
+			fields = make(map[string]string)
+		}
+		fields[name] = value
+	}
+	return fields
+}
+
+// customFieldLabels returns the labels carrying values for the named field.
+func customFieldLabels(labels []string, name string) []string {
+	var matched []string
+	for _, l := range labels {
+		if strings.HasPrefix(l, CustomFieldLabelPrefix+name+"=") {
+			matched = append(matched, l)
+		}
+	}
+	return matched
+}
+
+// FillCustomFields sets each issue's Fields from its labels.
+func FillCustomFields(issues []*Issue) {
+	for _, issue := range issues {
+		issue.Fields = CustomFields(issue.Labels)
+	}
+}
+
+// UpdateCustomFields sets and clears custom fields on an issue in one
+// update. Set values replace any the issue had and should already be
+// checked against the rig's schema.
+func (b *Beads) UpdateCustomFields(id string, set map[string]string, unset []string) error {
+	issue, err := b.Show(id)
+	if err != nil {
+		return err
+	}
+	var opts UpdateOptions
+	for name, value := range set {
+		label := CustomFieldLabel(name, value)
+		if !HasLabel(issue, label) {
+			opts.AddLabels = append(opts.AddLabels, label)
+		}
+		for _, l := range customFieldLabels(issue.Labels, name) {
+			if l != label {
+				opts.RemoveLabels = append(opts.RemoveLabels, l)
+			}
+		}
+	}
+	for _, name := range unset {
+		opts.RemoveLabels = append(opts.RemoveLabels, customFieldLabels(issue.Labels, name)...)
+	}
+	if len(opts.AddLabels) == 0 && len(opts.RemoveLabels) == 0 {
+		return nil
+	}
+	sort.Strings(opts.AddLabels)
+	return b.Update(id, opts)
+}
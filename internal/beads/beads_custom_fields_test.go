@@ -0,0 +1,26 @@
+package beads
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCustomFields(t *testing.T) {
+	labels := []string{
+		"gt:task",
+		CustomFieldLabel("customer", "acme"),
+		CustomFieldLabel("docs", "https://example.com/a=b"),
+		"field:=orphan",
+		"field:noequals",
+	}
+	want := map[string]string{"customer": "acme", "docs": "https://example.com/a=b"}
+	if got := CustomFields(labels); !reflect.DeepEqual(got, want) {
+		t.Errorf("CustomFields = %v, want %v", got, want)
+	}
+	if got := CustomFields([]string{"gt:task"}); got != nil {
+		t.Errorf("no field labels: got %v, want nil", got)
+	}
+	if got := customFieldLabels(labels, "customer"); len(got) != 1 || got[0] != "field:customer=acme" {
+		t.Errorf("customFieldLabels = %v", got)
+	}
+}
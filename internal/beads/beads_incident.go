@@ -0,0 +1,261 @@
+// Package beads provides incident bead management.
+package beads
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// IncidentLabel marks incident beads.
+const IncidentLabel = "gt:incident"
+
+// HotfixLabel marks work allowed to merge while a rig's queue is frozen.
+const HotfixLabel = "hotfix"
+
+// incidentTimelineSection is the description section holding the timeline.
+const incidentTimelineSection = "timeline"
+
+// IncidentFields holds structured fields for incident beads.
+// These are stored as "key: value" lines in the description, followed by
+// the timeline section.
+type IncidentFields struct {
+	Severity    string // critical, high, medium, low
+	Rig         string // Affected rig
+	DeclaredBy  string // Agent or human that opened the incident
+	DeclaredAt  string // ISO 8601 timestamp
+	Responder   string // Agent assigned to respond (e.g., "gastown/crew/max")
+	FreezeQueue bool   // Whether the rig's merge queue is frozen (hotfixes excepted)
+	ResolvedBy  string // Who resolved it (empty while open)
+	ResolvedAt  string // When resolved (empty while open)
+	Resolution  string // Resolution summary (empty while open)
+
+	Timeline []IncidentEvent
+}
+
+// IncidentEvent is one entry in an incident timeline.
+type IncidentEvent struct {
+	At    time.Time `json:"at"`
+	Actor string    `json:"actor,omitempty"`
+	Text  string    `json:"text"`
+}
+
+// String formats an event as a timeline line.
+func (e IncidentEvent) String() string {
+	actor := e.Actor
+	if actor == "" {
+		actor = "unknown"
+	}
+	return fmt.Sprintf("- %s %s: %s", e.At.UTC().Format(time.RFC3339), actor, e.Text)
+}
+
+// parseIncidentEvent parses a timeline line written by IncidentEvent.String.
+func parseIncidentEvent(line string) (IncidentEvent, bool) {
+	line = strings.TrimPrefix(strings.TrimSpace(line), "- ")
+	stamp, rest, ok := strings.Cut(line, " ")
+	if !ok {
+		return IncidentEvent{}, false
+	}
+	at, err := time.Parse(time.RFC3339, stamp)
+	if err != nil {
+		return IncidentEvent{}, false
+	}
+	actor, text, ok := strings.Cut(rest, ": ")
+	if !ok {
+		return IncidentEvent{}, false
+	}
+	return IncidentEvent{At: at, Actor: actor, Text: text}, true
+}
+
+// FormatIncidentDescription creates a description string from incident fields.
+func FormatIncidentDescription(summary string, fields *IncidentFields) string {
+	var lines []string
+	if summary != "" {
+		lines = append(lines, summary, "")
+	}
+	add := func(key, value string) {
+		if value != "" {
+			lines = append(lines, fmt.Sprintf("%s: %s", key, value))
+		}
+	}
+	severity := fields.Severity
+	if severity == "" {
+		severity = "medium"
+	}
+	lines = append(lines, "severity: "+severity)
+	add("rig", fields.Rig)
+	add("declared_by", fields.DeclaredBy)
+	add("declared_at", fields.DeclaredAt)
+	add("responder", fields.Responder)
+	lines = append(lines, fmt.Sprintf("freeze_queue: %t", fields.FreezeQueue))
+	add("resolved_by", fields.ResolvedBy)
+	add("resolved_at", fields.ResolvedAt)
+	add("resolution", fields.Resolution)
+
+	description := strings.Join(lines, "\n")
+	if len(fields.Timeline) > 0 {
+		events := make([]string, 0, len(fields.Timeline))
+		for _, e := range fields.Timeline {
+			events = append(events, e.String())
+		}
+		description = SetDescriptionSection(description, incidentTimelineSection, strings.Join(events, "\n"))
+	}
+	return description
+}
+
+// ParseIncidentFields extracts incident fields and the timeline from a description.
+func ParseIncidentFields(description string) *IncidentFields {
+	fields := &IncidentFields{}
+
+	body := strings.TrimPrefix(description, incidentSummary(description))
+	for _, line := range strings.Split(SetDescriptionSection(body, incidentTimelineSection, ""), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "severity":
+			fields.Severity = value
+		case "rig":
+			fields.Rig = value
+		case "declared_by":
+			fields.DeclaredBy = value
+		case "declared_at":
+			fields.DeclaredAt = value
+		case "responder":
+			fields.Responder = value
+		case "freeze_queue":
+			fields.FreezeQueue = value == "true"
+		case "resolved_by":
+			fields.ResolvedBy = value
+		case "resolved_at":
+			fields.ResolvedAt = value
+		case "resolution":
+			fields.Resolution = value
+		}
+	}
+
+	for _, line := range strings.Split(GetDescriptionSection(description, incidentTimelineSection), "\n") {
+		if e, ok := parseIncidentEvent(line); ok {
+			fields.Timeline = append(fields.Timeline, e)
+		}
+	}
+
+	return fields
+}
+
+// incidentSummary returns the free-text summary preceding the fields,
+// which always start with the severity line.
+func incidentSummary(description string) string {
+	if i := strings.Index(description, "\n\nseverity: "); i != -1 {
+		return description[:i]
+	}
+	return ""
+}
+
+// CreateIncidentBead creates an incident bead. The first timeline event
+// records the declaration.
+func (b *Beads) CreateIncidentBead(title, summary string, fields *IncidentFields) (*Issue, error) {
+	if fields.DeclaredAt == "" {
+		fields.DeclaredAt = time.Now().UTC().Format(time.RFC3339)
+	}
+	if len(fields.Timeline) == 0 {
+		at, _ := time.Parse(time.RFC3339, fields.DeclaredAt)
+		fields.Timeline = append(fields.Timeline, IncidentEvent{At: at, Actor: fields.DeclaredBy, Text: "Incident declared: " + title})
+	}
+
+	labels := []string{IncidentLabel}
+	if fields.Severity != "" {
+		labels = append(labels, "severity:"+fields.Severity)
+	}
+	return b.Create(CreateOptions{
+		Title:       title,
+		Priority:    incidentPriority(fields.Severity),
+		Description: FormatIncidentDescription(summary, fields),
+		Labels:      labels,
+		Actor:       fields.DeclaredBy,
+	})
+}
+
+// incidentPriority maps severity to bead priority.
+func incidentPriority(severity string) int {
+	switch severity {
+	case "critical":
+		return 0
+	case "high":
+		return 1
+	case "low":
+		return 3
+	default:
+		return 2
+	}
+}
+
+// GetIncidentBead retrieves an incident bead and its fields.
+func (b *Beads) GetIncidentBead(id string) (*Issue, *IncidentFields, error) {
+	issue, err := b.Show(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !HasLabel(issue, IncidentLabel) {
+		return nil, nil, fmt.Errorf("issue %s is not an incident bead (missing %s label)", id, IncidentLabel)
+	}
+	return issue, ParseIncidentFields(issue.Description), nil
+}
+
+// UpdateIncident applies fn to an incident's fields and appends a timeline
+// event, then writes the description back.
+func (b *Beads) UpdateIncident(id, actor, event string, fn func(*IncidentFields)) (*IncidentFields, error) {
+	issue, fields, err := b.GetIncidentBead(id)
+	if err != nil {
+		return nil, err
+	}
+	if fn != nil {
+		fn(fields)
+	}
+	if event != "" {
+		fields.Timeline = append(fields.Timeline, IncidentEvent{At: time.Now().UTC(), Actor: actor, Text: event})
+	}
+	description := FormatIncidentDescription(incidentSummary(issue.Description), fields)
+	if err := b.Update(id, UpdateOptions{Description: &description}); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// ResolveIncident records the resolution, unfreezes the queue, and closes
+// the incident bead.
+func (b *Beads) ResolveIncident(id, resolvedBy, resolution string) error {
+	_, err := b.UpdateIncident(id, resolvedBy, "Resolved: "+resolution, func(f *IncidentFields) {
+		f.ResolvedBy = resolvedBy
+		f.ResolvedAt = time.Now().UTC().Format(time.RFC3339)
+		f.Resolution = resolution
+		f.FreezeQueue = false
+	})
+	if err != nil {
+		return err
+	}
+	return b.CloseWithReason(resolution, id)
+}
+
+// ListOpenIncidents returns open incident beads.
+func (b *Beads) ListOpenIncidents() ([]*Issue, error) {
+	return b.List(ListOptions{Status: "open", Label: IncidentLabel, Priority: -1})
+}
+
+// QueueFreezingIncidents returns the open incidents that freeze the merge queue.
+func (b *Beads) QueueFreezingIncidents() ([]*Issue, error) {
+	issues, err := b.ListOpenIncidents()
+	if err != nil {
+		return nil, err
+	}
+	var freezing []*Issue
+	for _, issue := range issues {
+		if ParseIncidentFields(issue.Description).FreezeQueue {
+			freezing = append(freezing, issue)
+		}
+	}
+	return freezing, nil
+}
@@ -0,0 +1,61 @@
+package beads
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestIncidentFieldsRoundTrip(t *testing.T) {
+	at := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	fields := &IncidentFields{
+		Severity:    "critical",
+		Rig:         "gastown",
+		DeclaredBy:  "mayor",
+		DeclaredAt:  at.Format(time.RFC3339),
+		Responder:   "gastown/crew/max",
+		FreezeQueue: true,
+		Timeline: []IncidentEvent{
+			{At: at, Actor: "mayor", Text: "Incident declared: prod down"},
+			{At: at.Add(5 * time.Minute), Actor: "gastown/crew/max", Text: "Rolled back: deploy 1.4.1"},
+		},
+	}
+
+	summary := "Login fails for all users.\n\nrig: not a field, just prose."
+	description := FormatIncidentDescription(summary, fields)
+
+	got := ParseIncidentFields(description)
+	if !reflect.DeepEqual(got, fields) {
+		t.Errorf("round trip mismatch:\ngot  %+v\nwant %+v", got, fields)
+	}
+	if s := incidentSummary(description); s != summary {
+		t.Errorf("incidentSummary = %q, want %q", s, summary)
+	}
+
+	resolved := *fields
+	resolved.FreezeQueue = false
+	resolved.Resolution = "Reverted gt-mr-123"
+	got = ParseIncidentFields(FormatIncidentDescription("", &resolved))
+	if got.FreezeQueue || got.Resolution != "Reverted gt-mr-123" {
+		t.Errorf("resolved fields = %+v", got)
+	}
+}
+
+func TestParseIncidentEvent(t *testing.T) {
+	tests := []struct {
+		line string
+		ok   bool
+		want IncidentEvent
+	}{
+		{"- 2026-05-01T12:00:00Z mayor: Paged: mail:mayor", true,
+			IncidentEvent{At: time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC), Actor: "mayor", Text: "Paged: mail:mayor"}},
+		{"- yesterday mayor: nope", false, IncidentEvent{}},
+		{"- 2026-05-01T12:00:00Z no separator", false, IncidentEvent{}},
+	}
+	for _, tt := range tests {
+		got, ok := parseIncidentEvent(tt.line)
+		if ok != tt.ok || (ok && !reflect.DeepEqual(got, tt.want)) {
+			t.Errorf("parseIncidentEvent(%q) = %+v, %v; want %+v, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
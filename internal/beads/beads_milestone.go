@@ -0,0 +1,127 @@
+// Package beads provides milestone bead management.
+package beads
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MilestoneLabel marks milestone beads.
+const MilestoneLabel = "gt:milestone"
+
+// MilestoneDateFormat is the format of milestone start and target dates.
+const MilestoneDateFormat = time.DateOnly
+
+// MilestoneFields holds structured fields for milestone beads.
+// These are stored as "key: value" lines in the description, after an
+// optional free-text summary. The issues a milestone groups are tracked
+// with "tracks" dependencies, as convoys track theirs, so they can live in
+// any rig.
+type MilestoneFields struct {
+	Start  string // YYYY-MM-DD the milestone's work started
+	Target string // YYYY-MM-DD the work is due
+	Owner  string // Who answers for the milestone (e.g., "mayor/")
+}
+
+// StartDate parses the start date; zero if unset or invalid.
+func (f *MilestoneFields) StartDate() time.Time {
+	t, _ := time.ParseInLocation(MilestoneDateFormat, f.Start, time.Local)
+	return t
+}
+
+// TargetDate parses the target date; zero if unset or invalid.
+func (f *MilestoneFields) TargetDate() time.Time {
+	t, _ := time.ParseInLocation(MilestoneDateFormat, f.Target, time.Local)
+	return t
+}
+
+// FormatMilestoneDescription creates a description string from milestone fields.
+func FormatMilestoneDescription(summary string, fields *MilestoneFields) string {
+	var lines []string
+	if summary != "" {
+		lines = append(lines, summary, "")
+	}
+	lines = append(lines, "target: "+fields.Target)
+	if fields.Start != "" {
+		lines = append(lines, "start: "+fields.Start)
+	}
+	if fields.Owner != "" {
+		lines = append(lines, "owner: "+fields.Owner)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ParseMilestoneFields extracts milestone fields from a description.
+func ParseMilestoneFields(description string) *MilestoneFields {
+	fields := &MilestoneFields{}
+	body := strings.TrimPrefix(description, milestoneSummary(description))
+	for _, line := range strings.Split(body, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "target":
+			fields.Target = value
+		case "start":
+			fields.Start = value
+		case "owner":
+			fields.Owner = value
+		}
+	}
+	return fields
+}
+
+// milestoneSummary returns the free-text summary preceding the fields,
+// which always start with the target line. The last such line starts
+// them, so a summary may mention a target of its own.
+func milestoneSummary(description string) string {
+	if i := strings.LastIndex(description, "\n\ntarget: "); i != -1 {
+		return description[:i]
+	}
+	return ""
+}
+
+// CreateMilestoneBead creates a milestone bead. The start date defaults to
+// today.
+func (b *Beads) CreateMilestoneBead(title, summary string, fields *MilestoneFields) (*Issue, error) {
+	if _, err := time.Parse(MilestoneDateFormat, fields.Target); err != nil {
+		return nil, fmt.Errorf("invalid target date %q: want YYYY-MM-DD", fields.Target)
+	}
+	if fields.Start == "" {
+		fields.Start = time.Now().Format(MilestoneDateFormat)
+	} else if _, err := time.Parse(MilestoneDateFormat, fields.Start); err != nil {
+		return nil, fmt.Errorf("invalid start date %q: want YYYY-MM-DD", fields.Start)
+	}
+	if fields.Start > fields.Target {
+		return nil, fmt.Errorf("start date %s is after target date %s", fields.Start, fields.Target)
+	}
+	return b.Create(CreateOptions{
+		Title:       title,
+		Priority:    2,
+		Description: FormatMilestoneDescription(summary, fields),
+		Labels:      []string{MilestoneLabel},
+		Actor:       fields.Owner,
+	})
+}
+
+// GetMilestoneBead retrieves a milestone bead and its fields.
+func (b *Beads) GetMilestoneBead(id string) (*Issue, *MilestoneFields, error) {
+	issue, err := b.Show(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !HasLabel(issue, MilestoneLabel) {
+		return nil, nil, fmt.Errorf("issue %s is not a milestone bead (missing %s label)", id, MilestoneLabel)
+	}
+	return issue, ParseMilestoneFields(issue.Description), nil
+}
+
+// ListMilestones returns milestone beads with the given status ("open",
+// "closed", or "all").
+func (b *Beads) ListMilestones(status string) ([]*Issue, error) {
+	return b.List(ListOptions{Status: status, Label: MilestoneLabel, Priority: -1})
+}
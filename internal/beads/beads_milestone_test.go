@@ -0,0 +1,30 @@
+package beads
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMilestoneFieldsRoundTrip(t *testing.T) {
+	fields := &MilestoneFields{Start: "2026-03-02", Target: "2026-03-27", Owner: "mayor/"}
+
+	summary := "Ship v2 onboarding.\n\ntarget: not a field, just prose."
+	description := FormatMilestoneDescription(summary, fields)
+	if got := ParseMilestoneFields(description); !reflect.DeepEqual(got, fields) {
+		t.Errorf("round trip mismatch:\ngot  %+v\nwant %+v", got, fields)
+	}
+	if s := milestoneSummary(description); s != summary {
+		t.Errorf("milestoneSummary = %q, want %q", s, summary)
+	}
+
+	bare := &MilestoneFields{Target: "2026-04-01"}
+	if got := ParseMilestoneFields(FormatMilestoneDescription("", bare)); !reflect.DeepEqual(got, bare) {
+		t.Errorf("bare round trip = %+v", got)
+	}
+	if d := fields.TargetDate(); d.Day() != 27 || fields.StartDate().Day() != 2 {
+		t.Errorf("dates = %v %v", fields.StartDate(), d)
+	}
+	if !(&MilestoneFields{Target: "soon"}).TargetDate().IsZero() {
+		t.Error("invalid target date should parse as zero")
+	}
+}
@@ -568,6 +568,38 @@ func TestMRFieldsRoundTrip(t *testing.T) {
 		Rig:         "gastown",
 		MergeCommit: "abc123def789",
 		CloseReason: "merged",
+		TestTriage:  "real",
+		FailedTests: "pkg/a.TestBad",
+		FlakyTests:  "pkg/b.TestNet,pkg/b.TestDNS",
+
+		DiffFiles:     4,
+		LinesAdded:    120,
+		LinesDeleted:  0,
+		Coverage:      "78.4",
+		CoverageDelta: "-0.6",
+
+		APIChanges:      3,
+		BreakingChanges: 1,
+
+		PostMerge: "failed",
+
+		Review:   "changes_requested",
+		Reviewer: "gastown/crew/rev",
+
+		Owners:    "gastown/crew, gastown/crew/ops",
+		Approvals: "gastown/crew/rev",
+
+		Boost:    2,
+		FrontAt:  "2026-03-01T09:00:00Z",
+		BumpedBy: "mayor",
+
+		DependsOn: "gt-mr-parent, gt-abc",
+
+		Signer:     "gastown refinery <refinery@gastown.gastown.local>",
+		SigningKey: "SHA256:sZW4jnXhrtl+nhybjhFpi+b3Xix97RkxvF84SIO4C24",
+
+		LicenseScan:  "blocked",
+		SecurityScan: "deferred",
 	}
 
 	// Format to string
@@ -586,6 +618,42 @@ func TestMRFieldsRoundTrip(t *testing.T) {
 	}
 }
 
+func TestDescriptionSection(t *testing.T) {
+	desc := "branch: polecat/Nux/gt-xyz\ntarget: main\n\nSome notes."
+
+	// Append
+	desc = SetDescriptionSection(desc, "change-summary", "3 files\n- M a.go")
+	if got := GetDescriptionSection(desc, "change-summary"); got != "3 files\n- M a.go" {
+		t.Errorf("GetDescriptionSection = %q", got)
+	}
+
+	// Replace keeps fields and notes intact
+	desc = SetDescriptionSection(desc, "change-summary", "1 file")
+	if got := GetDescriptionSection(desc, "change-summary"); got != "1 file" {
+		t.Errorf("after replace, GetDescriptionSection = %q", got)
+	}
+	if strings.Count(desc, "<!-- gt:change-summary -->") != 1 {
+		t.Errorf("expected one section, got:\n%s", desc)
+	}
+	fields := ParseMRFields(&Issue{Description: desc})
+	if fields == nil || fields.Branch != "polecat/Nux/gt-xyz" || fields.Target != "main" {
+		t.Errorf("MR fields lost: %+v", fields)
+	}
+	if !strings.Contains(desc, "Some notes.") {
+		t.Errorf("notes lost:\n%s", desc)
+	}
+
+	// Remove
+	desc = SetDescriptionSection(desc, "change-summary", "")
+	want := "branch: polecat/Nux/gt-xyz\ntarget: main\n\nSome notes."
+	if desc != want {
+		t.Errorf("after remove = %q, want %q", desc, want)
+	}
+	if got := GetDescriptionSection(desc, "change-summary"); got != "" {
+		t.Errorf("removed section still present: %q", got)
+	}
+}
+
 // TestParseMRFieldsFromDesignDoc tests the example from the design doc.
 func TestParseMRFieldsFromDesignDoc(t *testing.T) {
 	// Example from docs/merge-queue-design.md
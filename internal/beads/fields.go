@@ -202,6 +202,62 @@ type MRFields struct {
 	// GitHub PR tracking
 	PRNumber int    // GitHub PR number (0 = no PR created)
 	PRURL    string // Full GitHub PR URL
+
+	// Test triage (set by the refinery when a test run fails or flakes)
+	TestTriage  string // Triage class: real, flaky
+	FailedTests string // Comma-separated tests that failed on every attempt
+	FlakyTests  string // Comma-separated tests that failed only on some attempts
+
+	// Diff and coverage metrics (set by the refinery when an MR is measured)
+	DiffFiles     int    // Files changed relative to the target branch
+	LinesAdded    int    // Lines added relative to the target branch
+	LinesDeleted  int    // Lines deleted relative to the target branch
+	Coverage      string // Total coverage percentage with the MR applied (e.g., "78.4")
+	CoverageDelta string // Coverage change against the target baseline (e.g., "-0.6")
+
+	// Change summary counts (the summary itself is the "change-summary" section)
+	APIChanges      int // Exported symbols added, removed, or with changed signatures
+	BreakingChanges int // API removals and signature changes
+
+	// Post-merge verification (set after the landing is verified)
+	PostMerge string // monitoring, passed, or failed
+
+	// Review state (set when review is requested and when a verdict is posted)
+	Review   string // requested, approved, or changes_requested
+	Reviewer string // Who posted the latest verdict
+
+	// Code owners (set when the MR touches owned paths): the review holds
+	// until every owner is satisfied by an approval
+	Owners    string // Comma-separated owners whose approval is required
+	Approvals string // Comma-separated reviewers who approved
+
+	// Queue position overrides (set by gt mq bump)
+	Boost    int    // Priority levels the MR is boosted above its own
+	FrontAt  string // When the MR was moved to the front of the queue
+	BumpedBy string // Who last bumped the MR
+
+	// Dependencies (set by gt mq submit --depends-on): the MR can't land
+	// before these MRs, or the MRs for these issues
+	DependsOn string // Comma-separated MR or issue IDs
+
+	// Linked MRs (set by gt mq link): MRs across rigs that land together
+	LinkGroup string // Link group ID
+
+	// Queue time tracking (set by the daemon's queue SLO check)
+	QueueState string // awaiting_tests, awaiting_review, or awaiting_merge
+	QueueSince string // When the MR entered QueueState (RFC 3339)
+	QueueTimes string // Time spent in earlier states (e.g., "awaiting_review=2h0m0s")
+	SLOAlerted string // The state whose SLO breach was already reported
+
+	// Provenance (set by gt mq sign when the refinery signs the landing)
+	Signer     string // Committer identity the landed commits are signed as
+	SigningKey string // Fingerprint of the signing key
+
+	// License gate (set by gt mq license; details are the "license-scan" section)
+	LicenseScan string // passed, warned, or blocked
+
+	// Security gate (set by gt mq scan; details are the "security-scan" section)
+	SecurityScan string // passed, deferred, or blocked
 }
 
 // ParseMRFields extracts structured merge-request fields from an issue's description.
@@ -284,6 +340,98 @@ func ParseMRFields(issue *Issue) *MRFields {
 		case "pr_url", "pr-url", "prurl":
 			fields.PRURL = value
 			hasFields = true
+		case "test_triage", "test-triage", "testtriage":
+			fields.TestTriage = value
+			hasFields = true
+		case "failed_tests", "failed-tests", "failedtests":
+			fields.FailedTests = value
+			hasFields = true
+		case "flaky_tests", "flaky-tests", "flakytests":
+			fields.FlakyTests = value
+			hasFields = true
+		case "diff_files", "diff-files", "difffiles":
+			if n, err := parseIntField(value); err == nil {
+				fields.DiffFiles = n
+				hasFields = true
+			}
+		case "lines_added", "lines-added", "linesadded":
+			if n, err := parseIntField(value); err == nil {
+				fields.LinesAdded = n
+				hasFields = true
+			}
+		case "lines_deleted", "lines-deleted", "linesdeleted":
+			if n, err := parseIntField(value); err == nil {
+				fields.LinesDeleted = n
+				hasFields = true
+			}
+		case "coverage":
+			fields.Coverage = value
+			hasFields = true
+		case "coverage_delta", "coverage-delta", "coveragedelta":
+			fields.CoverageDelta = value
+			hasFields = true
+		case "api_changes", "api-changes", "apichanges":
+			if n, err := parseIntField(value); err == nil {
+				fields.APIChanges = n
+				hasFields = true
+			}
+		case "breaking_changes", "breaking-changes", "breakingchanges":
+			if n, err := parseIntField(value); err == nil {
+				fields.BreakingChanges = n
+				hasFields = true
+			}
+		case "post_merge", "post-merge", "postmerge":
+			fields.PostMerge = value
+			hasFields = true
+		case "review":
+			fields.Review = value
+			hasFields = true
+		case "reviewer":
+			fields.Reviewer = value
+		case "owners":
+			fields.Owners = value
+		case "approvals":
+			fields.Approvals = value
+			hasFields = true
+		case "boost":
+			if n, err := parseIntField(value); err == nil {
+				fields.Boost = n
+			}
+			hasFields = true
+		case "front_at", "front-at", "frontat":
+			fields.FrontAt = value
+			hasFields = true
+		case "bumped_by", "bumped-by", "bumpedby":
+			fields.BumpedBy = value
+		case "depends_on", "depends-on", "dependson":
+			fields.DependsOn = value
+			hasFields = true
+		case "link_group", "link-group", "linkgroup":
+			fields.LinkGroup = value
+			hasFields = true
+		case "queue_state", "queue-state", "queuestate":
+			fields.QueueState = value
+			hasFields = true
+		case "queue_since", "queue-since", "queuesince":
+			fields.QueueSince = value
+			hasFields = true
+		case "queue_times", "queue-times", "queuetimes":
+			fields.QueueTimes = value
+			hasFields = true
+		case "slo_alerted", "slo-alerted", "sloalerted":
+			fields.SLOAlerted = value
+			hasFields = true
+		case "signer":
+			fields.Signer = value
+			hasFields = true
+		case "signing_key", "signing-key", "signingkey":
+			fields.SigningKey = value
+			hasFields = true
+		case "license_scan", "license-scan", "licensescan":
+			fields.LicenseScan = value
+		case "security_scan", "security-scan", "securityscan":
+			fields.SecurityScan = value
+			hasFields = true
 		}
 	}
 
@@ -354,6 +502,86 @@ func FormatMRFields(fields *MRFields) string {
 	if fields.PRURL != "" {
 		lines = append(lines, "pr_url: "+fields.PRURL)
 	}
+	if fields.TestTriage != "" {
+		lines = append(lines, "test_triage: "+fields.TestTriage)
+	}
+	if fields.FailedTests != "" {
+		lines = append(lines, "failed_tests: "+fields.FailedTests)
+	}
+	if fields.FlakyTests != "" {
+		lines = append(lines, "flaky_tests: "+fields.FlakyTests)
+	}
+	if fields.DiffFiles > 0 {
+		lines = append(lines, fmt.Sprintf("diff_files: %d", fields.DiffFiles))
+		lines = append(lines, fmt.Sprintf("lines_added: %d", fields.LinesAdded))
+		lines = append(lines, fmt.Sprintf("lines_deleted: %d", fields.LinesDeleted))
+	}
+	if fields.Coverage != "" {
+		lines = append(lines, "coverage: "+fields.Coverage)
+	}
+	if fields.CoverageDelta != "" {
+		lines = append(lines, "coverage_delta: "+fields.CoverageDelta)
+	}
+	if fields.APIChanges > 0 {
+		lines = append(lines, fmt.Sprintf("api_changes: %d", fields.APIChanges))
+	}
+	if fields.BreakingChanges > 0 {
+		lines = append(lines, fmt.Sprintf("breaking_changes: %d", fields.BreakingChanges))
+	}
+	if fields.PostMerge != "" {
+		lines = append(lines, "post_merge: "+fields.PostMerge)
+	}
+	if fields.Review != "" {
+		lines = append(lines, "review: "+fields.Review)
+	}
+	if fields.Reviewer != "" {
+		lines = append(lines, "reviewer: "+fields.Reviewer)
+	}
+	if fields.Owners != "" {
+		lines = append(lines, "owners: "+fields.Owners)
+	}
+	if fields.Approvals != "" {
+		lines = append(lines, "approvals: "+fields.Approvals)
+	}
+	if fields.Boost > 0 {
+		lines = append(lines, fmt.Sprintf("boost: %d", fields.Boost))
+	}
+	if fields.FrontAt != "" {
+		lines = append(lines, "front_at: "+fields.FrontAt)
+	}
+	if fields.BumpedBy != "" {
+		lines = append(lines, "bumped_by: "+fields.BumpedBy)
+	}
+	if fields.DependsOn != "" {
+		lines = append(lines, "depends_on: "+fields.DependsOn)
+	}
+	if fields.LinkGroup != "" {
+		lines = append(lines, "link_group: "+fields.LinkGroup)
+	}
+	if fields.QueueState != "" {
+		lines = append(lines, "queue_state: "+fields.QueueState)
+	}
+	if fields.QueueSince != "" {
+		lines = append(lines, "queue_since: "+fields.QueueSince)
+	}
+	if fields.QueueTimes != "" {
+		lines = append(lines, "queue_times: "+fields.QueueTimes)
+	}
+	if fields.SLOAlerted != "" {
+		lines = append(lines, "slo_alerted: "+fields.SLOAlerted)
+	}
+	if fields.Signer != "" {
+		lines = append(lines, "signer: "+fields.Signer)
+	}
+	if fields.SigningKey != "" {
+		lines = append(lines, "signing_key: "+fields.SigningKey)
+	}
+	if fields.LicenseScan != "" {
+		lines = append(lines, "license_scan: "+fields.LicenseScan)
+	}
+	if fields.SecurityScan != "" {
+		lines = append(lines, "security_scan: "+fields.SecurityScan)
+	}
 
 	return strings.Join(lines, "\n")
 }
@@ -406,6 +634,71 @@ func SetMRFields(issue *Issue, fields *MRFields) string {
 		"pr_url":             true,
 		"pr-url":             true,
 		"prurl":              true,
+		"test_triage":        true,
+		"test-triage":        true,
+		"testtriage":         true,
+		"failed_tests":       true,
+		"failed-tests":       true,
+		"failedtests":        true,
+		"flaky_tests":        true,
+		"flaky-tests":        true,
+		"flakytests":         true,
+		"diff_files":         true,
+		"diff-files":         true,
+		"difffiles":          true,
+		"lines_added":        true,
+		"lines-added":        true,
+		"linesadded":         true,
+		"lines_deleted":      true,
+		"lines-deleted":      true,
+		"linesdeleted":       true,
+		"coverage":           true,
+		"coverage_delta":     true,
+		"coverage-delta":     true,
+		"coveragedelta":      true,
+		"api_changes":        true,
+		"api-changes":        true,
+		"apichanges":         true,
+		"breaking_changes":   true,
+		"breaking-changes":   true,
+		"breakingchanges":    true,
+		"post_merge":         true,
+		"post-merge":         true,
+		"postmerge":          true,
+		"review":             true,
+		"reviewer":           true,
+		"owners":             true,
+		"approvals":          true,
+		"boost":              true,
+		"front_at":           true,
+		"front-at":           true,
+		"frontat":            true,
+		"bumped_by":          true,
+		"bumped-by":          true,
+		"bumpedby":           true,
+		"depends_on":         true,
+		"depends-on":         true,
+		"dependson":          true,
+		"queue_state":        true,
+		"queue-state":        true,
+		"queuestate":         true,
+		"queue_since":        true,
+		"queue-since":        true,
+		"queuesince":         true,
+		"queue_times":        true,
+		"queue-times":        true,
+		"queuetimes":         true,
+		"slo_alerted":        true,
+		"slo-alerted":        true,
+		"sloalerted":         true,
+		"signer":             true,
+		"signing_key":        true,
+		"signing-key":        true,
+		"signingkey":         true,
+		"license_scan":       true,
+		"security_scan":      true,
+		"license-scan":       true,
+		"licensescan":        true,
 	}
 
 	// Collect non-MR lines from existing description
@@ -456,6 +749,62 @@ func SetMRFields(issue *Issue, fields *MRFields) string {
 	return formatted + "\n\n" + strings.Join(otherLines, "\n")
 }
 
+// Description sections are multi-line blocks of generated content kept in a
+// bead's description between HTML comment markers, so they can be replaced
+// on regeneration without touching fields or prose:
+//
+//	<!-- gt:change-summary -->
+//	...
+//	<!-- /gt:change-summary -->
+
+func sectionMarkers(name string) (string, string) {
+	return "<!-- gt:" + name + " -->", "<!-- /gt:" + name + " -->"
+}
+
+// GetDescriptionSection returns the body of a named section, or "" if the
+// description has none.
+func GetDescriptionSection(description, name string) string {
+	start, end := sectionMarkers(name)
+	i := strings.Index(description, start)
+	if i == -1 {
+		return ""
+	}
+	rest := description[i+len(start):]
+	j := strings.Index(rest, end)
+	if j == -1 {
+		return ""
+	}
+	return strings.Trim(rest[:j], "\n")
+}
+
+// SetDescriptionSection replaces (or appends) a named section in a
+// description. An empty body removes the section.
+func SetDescriptionSection(description, name, body string) string {
+	start, end := sectionMarkers(name)
+	if i := strings.Index(description, start); i != -1 {
+		if j := strings.Index(description[i:], end); j != -1 {
+			before := strings.TrimRight(description[:i], "\n")
+			after := strings.TrimLeft(description[i+j+len(end):], "\n")
+			description = before
+			if after != "" {
+				if description != "" {
+					description += "\n\n"
+				}
+				description += after
+			}
+		}
+	}
+	if body == "" {
+		return description
+	}
+
+	section := start + "\n" + strings.Trim(body, "\n") + "\n" + end
+	if description == "" {
+		return section
+	}
+	return strings.TrimRight(description, "\n") + "\n\n" + section
+}
+
 // SynthesisFields holds structured fields for synthesis beads.
 // These fields track the synthesis step in a convoy workflow.
 type SynthesisFields struct {
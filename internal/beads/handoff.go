@@ -26,15 +26,18 @@ func (b *Beads) FindHandoffBead(role string) (*Issue, error) {
 	if err != nil {
 		return nil, fmt.Errorf("listing pinned issues: %w", err)
 	}
+	return findHandoff(issues, role), nil
+}
 
+// findHandoff picks a role's handoff bead out of the pinned issues.
+func findHandoff(pinned []*Issue, role string) *Issue {
 	targetTitle := HandoffBeadTitle(role)
-	for _, issue := range issues {
+	for _, issue := range pinned {
 		if issue.Title == targetTitle {
-			return issue, nil
+			return issue
 		}
 	}
-
-	return nil, nil
+	return nil
 }
 
 // GetOrCreateHandoffBead returns the handoff bead for a role, creating it if needed.
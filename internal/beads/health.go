@@ -0,0 +1,170 @@
+package beads
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/replay"
+)
+
+// PingTimeout is how long a health probe waits for bd before calling the
+// database unhealthy. A healthy bd answers a one-issue list well within it.
+const PingTimeout = 5 * time.Second
+
+// DefaultBreakerThreshold is how many failed calls in a row trip a Breaker.
+const DefaultBreakerThreshold = 2
+
+var (
+	// ErrUnresponsive reports a bd that didn't answer a Ping in time.
+	ErrUnresponsive = errors.New("bd did not answer")
+
+	// ErrUnhealthy reports a call skipped because the database's bd failed
+	// its probe or kept failing earlier in the run.
+	ErrUnhealthy = errors.New("bd unhealthy, skipped")
+)
+
+// Ping checks that bd can read this database, giving up after timeout. It
+// reads a single issue, so it costs about what bd's startup costs.
+func (b *Beads) Ping(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	args := []string{"list", "--json", "--limit=1"}
+	cmd := b.commandContext(ctx, args...)
+	var stderr bytes.Buffer
+	cmd.Stdout = io.Discard
+	cmd.Stderr = &stderr
+	if err := replay.Run(cmd); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("%w within %s", ErrUnresponsive, timeout)
+		}
+		return b.wrapError(err, stderr.String(), args)
+	}
+	return nil
+}
+
+// WithBreaker makes calls on b go through br, and returns b.
+func (b *Beads) WithBreaker(br *Breaker) *Beads {
+	b.breaker = br
+	return b
+}
+
+// Breaker is a circuit breaker over beads databases for one command run.
+// Once a database's bd fails its probe, or fails Threshold calls in a row,
+// further calls to it return ErrUnhealthy at once instead of waiting out
+// the same failure again. A breaker never closes again: the next command
+// run starts with a fresh one.
+//
+// A nil *Breaker allows every call. A Breaker is safe for concurrent use.
+type Breaker struct {
+	Threshold int
+
+	mu  sync.Mutex
+	dbs map[string]*breakerState
+}
+
+type breakerState struct {
+	failures int
+	lastErr  error
+	probed   bool
+}
+
+// NewBreaker returns a breaker that trips after threshold failures in a row.
+func NewBreaker(threshold int) *Breaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+	return &Breaker{Threshold: threshold, dbs: make(map[string]*breakerState)}
+}
+
+// Probe pings b's database the first time the run touches it, tripping
+// the breaker if bd doesn't answer. Later probes of the same database
+// return the first answer without running bd again.
+func (br *Breaker) Probe(b *Beads, timeout time.Duration) error {
+	if br == nil {
+		return b.Ping(timeout)
+	}
+	return br.probe(b.resolvedBeadsDir(), func() error { return b.Ping(timeout) })
+}
+
+func (br *Breaker) probe(key string, ping func() error) error {
+	if err := br.allow(key); err != nil {
+		return err
+	}
+	br.mu.Lock()
+	probed := br.state(key).probed
+	br.mu.Unlock()
+	if probed {
+		return nil
+	}
+
+	err := ping()
+
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	s := br.state(key)
+	s.probed = true
+	if err != nil {
+		s.failures = br.Threshold
+		s.lastErr = err
+		return fmt.Errorf("%w: %v", ErrUnhealthy, err)
+	}
+	return nil
+}
+
+// Allow returns ErrUnhealthy if the breaker has tripped for b's database.
+func (br *Breaker) Allow(b *Beads) error {
+	if br == nil {
+		return nil
+	}
+	return br.allow(b.resolvedBeadsDir())
+}
+
+func (br *Breaker) allow(key string) error {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	if s := br.state(key); s.failures >= br.Threshold {
+		return fmt.Errorf("%w: %v", ErrUnhealthy, s.lastErr)
+	}
+	return nil
+}
+
+// Record counts the outcome of a call to b's database. ErrNotFound is an
+// answer, not a failure, and a call the breaker itself skipped counts as
+// nothing.
+func (br *Breaker) Record(b *Beads, err error) {
+	if br == nil {
+		return
+	}
+	br.record(b.resolvedBeadsDir(), err)
+}
+
+func (br *Breaker) record(key string, err error) {
+	if errors.Is(err, ErrUnhealthy) {
+		return
+	}
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	s := br.state(key)
+	if err == nil || errors.Is(err, ErrNotFound) {
+		s.failures = 0
+		return
+	}
+	s.failures++
+	s.lastErr = err
+}
+
+// state returns key's state, creating it. Callers hold br.mu.
+func (br *Breaker) state(key string) *breakerState {
+	s, ok := br.dbs[key]
+	if !ok {
+		s = &breakerState{}
+		br.dbs[key] = s
+	}
+	return s
+}
@@ -0,0 +1,74 @@
+package beads
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBreakerRecord(t *testing.T) {
+	failed := errors.New("bd: database locked")
+	tests := []struct {
+		name     string
+		outcomes []error
+		wantOpen bool
+	}{
+		{"healthy", []error{nil, nil}, false},
+		{"one failure", []error{failed}, false},
+		{"trips", []error{failed, failed}, true},
+		{"success resets", []error{failed, nil, failed}, false},
+		{"not found is an answer", []error{failed, ErrNotFound, failed}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			br := NewBreaker(2)
+			for _, err := range tt.outcomes {
+				br.record("rig", err)
+			}
+			err := br.allow("rig")
+			if (err != nil) != tt.wantOpen {
+				t.Fatalf("allow = %v, want open %v", err, tt.wantOpen)
+			}
+			if err != nil && !errors.Is(err, ErrUnhealthy) {
+				t.Errorf("allow = %v, want ErrUnhealthy", err)
+			}
+			if err := br.allow("other"); err != nil {
+				t.Errorf("allow(other) = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestBreakerProbe(t *testing.T) {
+	br := NewBreaker(DefaultBreakerThreshold)
+	pings := 0
+	ping := func(err error) func() error {
+		return func() error { pings++; return err }
+	}
+
+	if err := br.probe("good", ping(nil)); err != nil {
+		t.Fatalf("probe(good) = %v", err)
+	}
+	if err := br.probe("good", ping(nil)); err != nil || pings != 1 {
+		t.Errorf("second probe(good) = %v after %d pings, want nil after 1", err, pings)
+	}
+
+	if err := br.probe("bad", ping(ErrUnresponsive)); !errors.Is(err, ErrUnhealthy) {
+		t.Fatalf("probe(bad) = %v, want ErrUnhealthy", err)
+	}
+	// A failed probe trips at once
+	if err := br.allow("bad"); !errors.Is(err, ErrUnhealthy) {
+		t.Errorf("allow(bad) = %v, want ErrUnhealthy", err)
+	}
+	if err := br.probe("bad", ping(nil)); !errors.Is(err, ErrUnhealthy) || pings != 2 {
+		t.Errorf("second probe(bad) = %v after %d pings, want ErrUnhealthy after 2", err, pings)
+	}
+}
+
+func TestNilBreaker(t *testing.T) {
+	var br *Breaker
+	b := New(t.TempDir())
+	if err := br.Allow(b); err != nil {
+		t.Errorf("nil Allow = %v", err)
+	}
+	br.Record(b, errors.New("ignored"))
+}
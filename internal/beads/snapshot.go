@@ -0,0 +1,87 @@
+package beads
+
+import (
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/timefmt"
+)
+
+// Snapshot cuts reads across several beads databases off at one point in
+// time, so an aggregate over them agrees with itself while agents keep
+// working. Each database is read separately and at a slightly different
+// moment; Cut drops the issues created after the cutoff, and counts the
+// ones updated after it, whose state may already be past the snapshot.
+//
+// Create the snapshot before starting any read. A Snapshot is safe for
+// concurrent use.
+type Snapshot struct {
+	at time.Time
+
+	mu      sync.Mutex
+	dropped int
+	changed int
+}
+
+// SnapshotInfo describes a Snapshot in command output.
+type SnapshotInfo struct {
+	At      time.Time `json:"at"`      // The cutoff
+	Dropped int       `json:"dropped"` // Issues left out because they were created after At
+	Changed int       `json:"changed"` // Issues shown that were updated after At
+}
+
+// NewSnapshot starts a snapshot at the current time.
+func NewSnapshot() *Snapshot {
+	return &Snapshot{at: time.Now().UTC()}
+}
+
+// At returns the snapshot's cutoff.
+func (s *Snapshot) At() time.Time {
+	return s.at
+}
+
+// Cut returns the issues that existed at the cutoff. Issues with no
+// readable creation time are kept.
+func (s *Snapshot) Cut(issues []*Issue) []*Issue {
+	kept := issues[:0:0]
+	var dropped, changed int
+	for _, issue := range issues {
+		if after(issue.CreatedAt, s.at) {
+			dropped++
+			continue
+		}
+		if after(issue.UpdatedAt, s.at) {
+			changed++
+		}
+		kept = append(kept, issue)
+	}
+	s.mu.Lock()
+	s.dropped += dropped
+	s.changed += changed
+	s.mu.Unlock()
+	return kept
+}
+
+// Info returns the cutoff and what Cut has seen so far.
+func (s *Snapshot) Info() SnapshotInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return SnapshotInfo{At: s.at, Dropped: s.dropped, Changed: s.changed}
+}
+
+// after reports whether the stored timestamp ts is later than t. A
+// timestamp stored to the second only counts once it's in a later second
+// than t.
+func after(ts string, t time.Time) bool {
+	if ts == "" {
+		return false
+	}
+	parsed, err := timefmt.Parse(ts)
+	if err != nil {
+		return false
+	}
+	if parsed.Nanosecond() == 0 {
+		t = t.Truncate(time.Second)
+	}
+	return parsed.After(t)
+}
@@ -0,0 +1,43 @@
+package beads
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSnapshotCut(t *testing.T) {
+	s := &Snapshot{at: time.Date(2026, 3, 20, 12, 0, 0, 500_000_000, time.UTC)}
+	issues := []*Issue{
+		{ID: "gt-old", CreatedAt: "2026-03-20T11:00:00Z", UpdatedAt: "2026-03-20T11:30:00Z"},
+		{ID: "gt-same-second", CreatedAt: "2026-03-20T12:00:00Z", UpdatedAt: "2026-03-20T12:00:00Z"},
+		{ID: "gt-touched", CreatedAt: "2026-03-20T10:00:00Z", UpdatedAt: "2026-03-20T12:00:01Z"},
+		{ID: "gt-new", CreatedAt: "2026-03-20T12:00:01Z", UpdatedAt: "2026-03-20T12:00:01Z"},
+		{ID: "gt-new-nanos", CreatedAt: "2026-03-20T12:00:00.7Z"},
+		{ID: "gt-undated"},
+	}
+
+	kept := s.Cut(issues)
+	var ids []string
+	for _, issue := range kept {
+		ids = append(ids, issue.ID)
+	}
+	want := []string{"gt-old", "gt-same-second", "gt-touched", "gt-undated"}
+	if len(ids) != len(want) {
+		t.Fatalf("Cut kept %v, want %v", ids, want)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Errorf("Cut kept %v, want %v", ids, want)
+			break
+		}
+	}
+	if len(issues) != 6 || issues[3].ID != "gt-new" {
+		t.Error("Cut modified its input")
+	}
+
+	s.Cut([]*Issue{{ID: "gt-other", CreatedAt: "2026-03-20T09:00:00Z", UpdatedAt: "2026-03-20T13:00:00Z"}})
+	info := s.Info()
+	if info.Dropped != 2 || info.Changed != 2 || !info.At.Equal(s.At()) {
+		t.Errorf("Info() = %+v, want 2 dropped, 2 changed", info)
+	}
+}
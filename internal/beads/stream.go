@@ -0,0 +1,108 @@
+package beads
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/steveyegge/gastown/internal/replay"
+)
+
+// errNoOutput reports a bd command that printed nothing at all.
+var errNoOutput = errors.New("command produced no output")
+
+// ReadyEach calls fn with each ready issue as bd's output is decoded, so
+// a large backlog is never held in memory at once. A positive limit
+// returns the first limit issues in priority order, as ReadyLimit does.
+func (b *Beads) ReadyEach(limit int, fn func(*Issue)) error {
+	return b.each(fn, readyArgs(limit)...)
+}
+
+// readyArgs are the bd ready arguments for ReadyLimit and ReadyEach.
+func readyArgs(limit int) []string {
+	args := []string{"ready", "--json"}
+	if limit > 0 {
+		args = append(args, "-n", strconv.Itoa(limit), "--sort", "priority")
+	}
+	return args
+}
+
+// BlockedEach calls fn with each blocked issue as bd's output is decoded.
+func (b *Beads) BlockedEach(fn func(*Issue)) error {
+	return b.each(fn, "blocked", "--json")
+}
+
+// each runs a bd command that prints a JSON array of issues, decoding
+// the issues from its output as it arrives.
+func (b *Beads) each(fn func(*Issue), args ...string) error {
+	if err := b.breaker.Allow(b); err != nil {
+		return err
+	}
+	err := b.eachOnce(fn, args...)
+	b.breaker.Record(b, err)
+	return err
+}
+
+func (b *Beads) eachOnce(fn func(*Issue), args ...string) error {
+	cmd := b.command(args...)
+	pr, pw := io.Pipe()
+	var stderr bytes.Buffer
+	cmd.Stdout = pw
+	cmd.Stderr = &stderr
+
+	done := make(chan error, 1)
+	go func() {
+		err := replay.Run(cmd)
+		_ = pw.Close()
+		done <- err
+	}()
+	decodeErr := decodeIssues(pr, fn)
+	// Drain what's left so bd doesn't block on a full pipe
+	_, _ = io.Copy(io.Discard, pr)
+
+	if err := <-done; err != nil {
+		return b.wrapError(err, stderr.String(), args)
+	}
+	// Same bd --no-daemon exit 0 bug run handles: an error on stderr and
+	// nothing on stdout
+	if errors.Is(decodeErr, errNoOutput) {
+		if stderr.Len() > 0 {
+			return b.wrapError(decodeErr, stderr.String(), args)
+		}
+		return nil
+	}
+	if decodeErr != nil {
+		return fmt.Errorf("parsing bd %s output: %w", args[0], decodeErr)
+	}
+	return nil
+}
+
+// decodeIssues decodes a JSON array of issues from r one at a time. A
+// null array has no issues; empty input is errNoOutput.
+func decodeIssues(r io.Reader, fn func(*Issue)) error {
+	dec := json.NewDecoder(r)
+	tok, err := dec.Token()
+	if err == io.EOF {
+		return errNoOutput
+	} else if err != nil {
+		return err
+	}
+	if tok == nil {
+		return nil
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("expected an array, got %v", tok)
+	}
+	for dec.More() {
+		var issue Issue
+		if err := dec.Decode(&issue); err != nil {
+			return err
+		}
+		fn(&issue)
+	}
+	_, err = dec.Token()
+	return err
+}
@@ -0,0 +1,58 @@
+package beads
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeIssues(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"array", `[{"id":"gt-1"},{"id":"gt-2"}]`, "gt-1 gt-2", false},
+		{"empty array", `[]`, "", false},
+		{"null", `null`, "", false},
+		{"no output", ``, "", true},
+		{"not an array", `{"id":"gt-1"}`, "", true},
+		{"truncated", `[{"id":"gt-1"},{"id":`, "gt-1", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ids []string
+			err := decodeIssues(strings.NewReader(tt.in), func(issue *Issue) { ids = append(ids, issue.ID) })
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := strings.Join(ids, " "); got != tt.want {
+				t.Errorf("decoded %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseIssueCounts(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    IssueCounts
+		wantErr bool
+	}{
+		{"flat", `{"total_issues":9,"ready_issues":4,"blocked_issues":2}`, IssueCounts{Ready: 4, Blocked: 2}, false},
+		{"summary", `{"summary":{"ready_issues":7,"blocked_issues":1},"recent_activity":{}}`, IssueCounts{Ready: 7, Blocked: 1}, false},
+		{"not json", `Total: 9`, IssueCounts{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseIssueCounts([]byte(tt.in))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && *got != tt.want {
+				t.Errorf("counts = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
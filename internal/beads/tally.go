@@ -0,0 +1,89 @@
+package beads
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// Tally folds a stream of issues into counts and the top issues by
+// priority, so an aggregate over a large town holds a few issues per
+// source instead of all of them. Issues of equal priority keep the order
+// they arrived in.
+type Tally struct {
+	// Top is how many issues to keep; zero keeps them all.
+	Top int
+
+	Total      int
+	ByPriority [5]int // P0-P4; other priorities count toward Total only
+
+	kept tallyHeap
+	seq  int
+}
+
+// NewTally returns a tally keeping the top issues.
+func NewTally(top int) *Tally {
+	return &Tally{Top: top}
+}
+
+// Add counts an issue, keeping it if it's among the top.
+func (t *Tally) Add(issue *Issue) {
+	t.Total++
+	if issue.Priority >= 0 && issue.Priority < len(t.ByPriority) {
+		t.ByPriority[issue.Priority]++
+	}
+	e := tallyEntry{issue: issue, seq: t.seq}
+	t.seq++
+	switch {
+	case t.Top <= 0:
+		t.kept = append(t.kept, e)
+	case len(t.kept) < t.Top:
+		heap.Push(&t.kept, e)
+	case e.before(t.kept[0]):
+		t.kept[0] = e
+		heap.Fix(&t.kept, 0)
+	}
+}
+
+// Issues returns the kept issues, highest priority first.
+func (t *Tally) Issues() []*Issue {
+	entries := append(tallyHeap(nil), t.kept...)
+	sort.Slice(entries, func(i, j int) bool { return entries[i].before(entries[j]) })
+	issues := make([]*Issue, len(entries))
+	for i, e := range entries {
+		issues[i] = e.issue
+	}
+	return issues
+}
+
+// Dropped is how many issues were counted but not kept.
+func (t *Tally) Dropped() int {
+	return t.Total - len(t.kept)
+}
+
+type tallyEntry struct {
+	issue *Issue
+	seq   int
+}
+
+// before orders entries by priority (lower number first), then arrival.
+func (e tallyEntry) before(o tallyEntry) bool {
+	if e.issue.Priority != o.issue.Priority {
+		return e.issue.Priority < o.issue.Priority
+	}
+	return e.seq < o.seq
+}
+
+// tallyHeap keeps the entry that sorts last at the root, so it's the
+// one replaced when a better issue arrives.
+type tallyHeap []tallyEntry
+
+func (h tallyHeap) Len() int           { return len(h) }
+func (h tallyHeap) Less(i, j int) bool { return h[j].before(h[i]) }
+func (h tallyHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *tallyHeap) Push(x any)        { *h = append(*h, x.(tallyEntry)) }
+func (h *tallyHeap) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return e
+}
@@ -0,0 +1,39 @@
+package beads
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTally(t *testing.T) {
+	priorities := []int{3, 1, 4, 1, 0, 2, 1, 9}
+	tests := []struct {
+		top  int
+		want string
+	}{
+		{0, "gt-4 gt-1 gt-3 gt-6 gt-5 gt-0 gt-2 gt-7"},
+		{3, "gt-4 gt-1 gt-3"},
+		{5, "gt-4 gt-1 gt-3 gt-6 gt-5"},
+		{20, "gt-4 gt-1 gt-3 gt-6 gt-5 gt-0 gt-2 gt-7"},
+	}
+	for _, tt := range tests {
+		tally := NewTally(tt.top)
+		for i, p := range priorities {
+			tally.Add(&Issue{ID: fmt.Sprintf("gt-%d", i), Priority: p})
+		}
+		var ids []string
+		for _, issue := range tally.Issues() {
+			ids = append(ids, issue.ID)
+		}
+		if got := strings.Join(ids, " "); got != tt.want {
+			t.Errorf("top %d: Issues() = %s, want %s", tt.top, got, tt.want)
+		}
+		if tally.Total != len(priorities) || tally.ByPriority != [5]int{1, 3, 1, 1, 1} {
+			t.Errorf("top %d: Total %d ByPriority %v, want every issue counted", tt.top, tally.Total, tally.ByPriority)
+		}
+		if want := len(priorities) - len(ids); tally.Dropped() != want {
+			t.Errorf("top %d: Dropped() = %d, want %d", tt.top, tally.Dropped(), want)
+		}
+	}
+}
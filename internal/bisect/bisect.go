@@ -0,0 +1,211 @@
+// Package bisect finds the merge-queue landing that broke a check.
+//
+// Bisection runs over landings (the commits each merged MR put on the
+// target branch) rather than individual commits, so the culprit is an MR
+// with a source issue and a worker to hand the breakage back to.
+package bisect
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+// Errors returned when the endpoints don't bracket a breakage.
+var (
+	ErrNotBroken     = errors.New("check passes at the bad commit")
+	ErrAlreadyBroken = errors.New("check fails at the good commit")
+	ErrNoLandings    = errors.New("no landings between good and bad")
+)
+
+// Landing is a commit that an MR put on the target branch.
+type Landing struct {
+	Commit      string `json:"commit"`
+	MR          string `json:"mr,omitempty"`
+	SourceIssue string `json:"source_issue,omitempty"`
+	Worker      string `json:"worker,omitempty"`
+	Title       string `json:"title,omitempty"`
+}
+
+// Label describes the landing for display.
+func (l Landing) Label() string {
+	if l.MR == "" {
+		return shortSHA(l.Commit)
+	}
+	return fmt.Sprintf("%s (%s)", l.MR, shortSHA(l.Commit))
+}
+
+// Step is one run of the check.
+type Step struct {
+	Commit   string        `json:"commit"`
+	Passed   bool          `json:"passed"`
+	Duration time.Duration `json:"duration"`
+}
+
+// CheckFunc runs the check at a commit. A non-nil error means the check
+// couldn't run at all (as opposed to failing).
+type CheckFunc func(commit string) (passed bool, output string, err error)
+
+// Result is the outcome of a bisection.
+type Result struct {
+	Culprit  Landing `json:"culprit"`
+	LastGood string  `json:"last_good"`
+	// Output is the check's output at the culprit.
+	Output string `json:"output,omitempty"`
+	Steps  []Step `json:"steps"`
+}
+
+// Landings returns the landings among commits (oldest first) by matching
+// them to merged MR beads. When no commit matches an MR, every commit is
+// treated as a landing so bisection still narrows to a commit.
+func Landings(commits []string, mrs []*beads.Issue) []Landing {
+	type mrInfo struct {
+		issue  *beads.Issue
+		fields *beads.MRFields
+	}
+	var merged []mrInfo
+	for _, mr := range mrs {
+		if fields := beads.ParseMRFields(mr); fields != nil && fields.MergeCommit != "" {
+			merged = append(merged, mrInfo{mr, fields})
+		}
+	}
+
+	var landings []Landing
+	for _, commit := range commits {
+		for _, m := range merged {
+			if sameCommit(commit, m.fields.MergeCommit) {
+				landings = append(landings, Landing{
+					Commit:      commit,
+					MR:          m.issue.ID,
+					SourceIssue: m.fields.SourceIssue,
+					Worker:      m.fields.Worker,
+					Title:       m.issue.Title,
+				})
+				break
+			}
+		}
+	}
+	if len(landings) > 0 {
+		return landings
+	}
+	for _, commit := range commits {
+		landings = append(landings, Landing{Commit: commit})
+	}
+	return landings
+}
+
+// sameCommit compares a full SHA with a possibly abbreviated one.
+func sameCommit(a, b string) bool {
+	if len(a) < 7 || len(b) < 7 {
+		return a == b
+	}
+	return strings.HasPrefix(a, b) || strings.HasPrefix(b, a)
+}
+
+// Search finds the first landing at which check fails. It first confirms
+// that check passes at base and fails at the last landing.
+func Search(base string, landings []Landing, check CheckFunc) (*Result, error) {
+	if len(landings) == 0 {
+		return nil, ErrNoLandings
+	}
+	result := &Result{}
+	outputs := make(map[int]string)
+	run := func(commit string) (bool, string, error) {
+		start := time.Now()
+		passed, output, err := check(commit)
+		if err != nil {
+			return false, "", err
+		}
+		result.Steps = append(result.Steps, Step{Commit: commit, Passed: passed, Duration: time.Since(start)})
+		return passed, output, nil
+	}
+
+	last := len(landings) - 1
+	passed, output, err := run(landings[last].Commit)
+	if err != nil {
+		return nil, err
+	}
+	if passed {
+		return nil, ErrNotBroken
+	}
+	outputs[last] = output
+
+	if passed, _, err = run(base); err != nil {
+		return nil, err
+	} else if !passed {
+		return nil, ErrAlreadyBroken
+	}
+
+	lo, hi := 0, last
+	for lo < hi {
+		mid := (lo + hi) / 2
+		passed, output, err := run(landings[mid].Commit)
+		if err != nil {
+			return nil, err
+		}
+		if passed {
+			lo = mid + 1
+		} else {
+			hi = mid
+			outputs[mid] = output
+		}
+	}
+
+	result.Culprit = landings[lo]
+	result.Output = outputs[lo]
+	result.LastGood = base
+	if lo > 0 {
+		result.LastGood = landings[lo-1].Commit
+	}
+	return result, nil
+}
+
+// Tail returns the last n lines of output.
+func Tail(output string, n int) string {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// FormatEvidence formats a bisection as a bead description.
+func FormatEvidence(check string, r *Result) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Bisection found the landing that broke `%s`.\n\n", check)
+	fmt.Fprintf(&sb, "culprit_commit: %s\n", r.Culprit.Commit)
+	if r.Culprit.MR != "" {
+		fmt.Fprintf(&sb, "culprit_mr: %s\n", r.Culprit.MR)
+	}
+	if r.Culprit.SourceIssue != "" {
+		fmt.Fprintf(&sb, "culprit_issue: %s\n", r.Culprit.SourceIssue)
+	}
+	if r.Culprit.Worker != "" {
+		fmt.Fprintf(&sb, "culprit_worker: %s\n", r.Culprit.Worker)
+	}
+	fmt.Fprintf(&sb, "last_good: %s\n", r.LastGood)
+	fmt.Fprintf(&sb, "check: %s\n", check)
+
+	sb.WriteString("\n## Steps\n\n")
+	for _, s := range r.Steps {
+		verdict := "fail"
+		if s.Passed {
+			verdict = "pass"
+		}
+		fmt.Fprintf(&sb, "- %s %s (%s)\n", shortSHA(s.Commit), verdict, s.Duration.Round(time.Second))
+	}
+	if r.Output != "" {
+		fmt.Fprintf(&sb, "\n## Failing output\n\n```\n%s\n```\n", Tail(r.Output, 40))
+	}
+	return sb.String()
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
@@ -0,0 +1,98 @@
+package bisect
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+func TestLandings(t *testing.T) {
+	commits := []string{"aaaaaaaa11", "bbbbbbbb22", "cccccccc33"}
+	mrs := []*beads.Issue{
+		{ID: "gt-mr1", Title: "Merge: gt-1", Description: "branch: polecat/nux\nsource_issue: gt-1\nworker: nux\nmerge_commit: aaaaaaaa"},
+		{ID: "gt-mr3", Description: "branch: polecat/ace\nsource_issue: gt-3\nmerge_commit: cccccccc33"},
+		{ID: "gt-mr9", Description: "branch: polecat/old\nmerge_commit: 99999999"},
+	}
+
+	got := Landings(commits, mrs)
+	if len(got) != 2 || got[0].MR != "gt-mr1" || got[0].Worker != "nux" || got[1].SourceIssue != "gt-3" {
+		t.Errorf("Landings = %+v", got)
+	}
+
+	// No MR matches: fall back to every commit
+	if got := Landings(commits, nil); len(got) != 3 || got[1].Commit != "bbbbbbbb22" {
+		t.Errorf("fallback Landings = %+v", got)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	landings := make([]Landing, 10)
+	for i := range landings {
+		landings[i] = Landing{Commit: fmt.Sprintf("c%d", i)}
+	}
+	checkFrom := func(firstBad int) CheckFunc {
+		return func(commit string) (bool, string, error) {
+			if commit == "base" {
+				return true, "", nil
+			}
+			var i int
+			fmt.Sscanf(commit, "c%d", &i)
+			if i >= firstBad {
+				return false, "FAIL at " + commit, nil
+			}
+			return true, "ok", nil
+		}
+	}
+
+	for _, firstBad := range []int{0, 3, 9} {
+		r, err := Search("base", landings, checkFrom(firstBad))
+		if err != nil {
+			t.Fatalf("firstBad=%d: %v", firstBad, err)
+		}
+		if r.Culprit.Commit != landings[firstBad].Commit {
+			t.Errorf("firstBad=%d: culprit = %s", firstBad, r.Culprit.Commit)
+		}
+		if r.Output != "FAIL at "+landings[firstBad].Commit {
+			t.Errorf("firstBad=%d: output = %q", firstBad, r.Output)
+		}
+		wantGood := "base"
+		if firstBad > 0 {
+			wantGood = landings[firstBad-1].Commit
+		}
+		if r.LastGood != wantGood {
+			t.Errorf("firstBad=%d: last good = %s, want %s", firstBad, r.LastGood, wantGood)
+		}
+		if len(r.Steps) > 6 {
+			t.Errorf("firstBad=%d: %d steps, want at most 6", firstBad, len(r.Steps))
+		}
+	}
+
+	if _, err := Search("base", landings, checkFrom(100)); !errors.Is(err, ErrNotBroken) {
+		t.Errorf("never broken: err = %v", err)
+	}
+	alwaysBad := func(string) (bool, string, error) { return false, "", nil }
+	if _, err := Search("base", landings, alwaysBad); !errors.Is(err, ErrAlreadyBroken) {
+		t.Errorf("always broken: err = %v", err)
+	}
+	if _, err := Search("base", nil, alwaysBad); !errors.Is(err, ErrNoLandings) {
+		t.Errorf("no landings: err = %v", err)
+	}
+}
+
+func TestFormatEvidence(t *testing.T) {
+	r := &Result{
+		Culprit:  Landing{Commit: "cccccccc33", MR: "gt-mr3", SourceIssue: "gt-3", Worker: "ace"},
+		LastGood: "bbbbbbbb22",
+		Output:   "line1\nFAIL TestLogin",
+		Steps:    []Step{{Commit: "cccccccc33"}, {Commit: "bbbbbbbb22", Passed: true}},
+	}
+	got := FormatEvidence("go test ./...", r)
+	for _, want := range []string{"culprit_mr: gt-mr3", "culprit_issue: gt-3", "last_good: bbbbbbbb22", "- bbbbbbbb pass", "FAIL TestLogin"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("evidence missing %q:\n%s", want, got)
+		}
+	}
+}
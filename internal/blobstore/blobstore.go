@@ -0,0 +1,171 @@
+// Package blobstore stores opaque files (attachments, backups) by key in a
+// configurable backend: a local directory, an S3-compatible bucket, or a
+// Google Cloud Storage bucket.
+//
+// Keys are slash-separated relative paths like "sha256/ab/ab12...". Writes
+// are whole-object: Put replaces any existing object at the key.
+//
+// A town configures a default backend (blob_store in the town settings)
+// and may override it per class of blob (attachments, backups); see
+// OpenClass. Prune applies a retention policy to a store.
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// ErrNotFound is returned when no object exists at a key.
+var ErrNotFound = errors.New("blob not found")
+
+// Info describes a stored object.
+type Info struct {
+	Key      string    `json:"key"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+}
+
+// Store is a blob storage backend.
+type Store interface {
+	// Put stores size bytes from r at key.
+	Put(ctx context.Context, key string, r io.Reader, size int64) error
+	// Get opens the object at key. The caller closes it.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Stat describes the object at key.
+	Stat(ctx context.Context, key string) (*Info, error)
+	// Delete removes the object at key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+	// List describes the objects whose keys start with prefix, sorted by key.
+	List(ctx context.Context, prefix string) ([]Info, error)
+	// String names the backend and location for messages.
+	String() string
+}
+
+// Open returns the store cfg describes. A nil cfg, or one without a
+// backend, is a local store in defaultDir; relative directories are
+// resolved against townRoot.
+func Open(townRoot string, cfg *config.BlobStoreConfig, defaultDir string) (Store, error) {
+	if cfg == nil {
+		cfg = &config.BlobStoreConfig{}
+	}
+	switch cfg.Backend {
+	case "", "local":
+		dir := cfg.Dir
+		if dir == "" {
+			dir = defaultDir
+		}
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(townRoot, dir)
+		}
+		return NewLocal(dir), nil
+	case "s3":
+		if cfg.S3 == nil {
+			return nil, fmt.Errorf("blob store backend s3 needs an s3 section")
+		}
+		return NewS3(cfg.S3)
+	case "gcs":
+		if cfg.GCS == nil {
+			return nil, fmt.Errorf("blob store backend gcs needs a gcs section")
+		}
+		return NewGCS(cfg.GCS)
+	}
+	return nil, fmt.Errorf("unknown blob store backend %q (want local, s3, or gcs)", cfg.Backend)
+}
+
+// Classes of blob a town stores, each in its own store.
+const (
+	ClassAttachments = "attachments"
+	ClassBackups     = "backups"
+)
+
+// OpenClass returns the town's store for a class of blob. The class's own
+// setting (attachments, backups) wins; otherwise the town-wide blob_store
+// is used with the class as a subdirectory or key prefix; otherwise the
+// class gets a local directory under the town root.
+func OpenClass(townRoot string, settings *config.TownSettings, class string) (Store, error) {
+	var own *config.BlobStoreConfig
+	switch class {
+	case ClassAttachments:
+		own = settings.Attachments
+	case ClassBackups:
+		own = settings.Backups
+	default:
+		return nil, fmt.Errorf("unknown blob class %q", class)
+	}
+	if own != nil {
+		return Open(townRoot, own, class)
+	}
+	if settings.BlobStore == nil {
+		return Open(townRoot, nil, class)
+	}
+
+	cfg := *settings.BlobStore
+	if cfg.Dir != "" {
+		cfg.Dir = filepath.Join(cfg.Dir, class)
+	}
+	if cfg.S3 != nil {
+		s3 := *cfg.S3
+		s3.Prefix += class + "/"
+		cfg.S3 = &s3
+	}
+	if cfg.GCS != nil {
+		gcs := *cfg.GCS
+		gcs.Prefix += class + "/"
+		cfg.GCS = &gcs
+	}
+	return Open(townRoot, &cfg, class)
+}
+
+// OpenTownClass loads the town settings and opens a class's store.
+func OpenTownClass(townRoot, class string) (Store, error) {
+	settings, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(townRoot))
+	if err != nil {
+		return nil, fmt.Errorf("loading town settings: %w", err)
+	}
+	return OpenClass(townRoot, settings, class)
+}
+
+// ParseURL opens a store named by URL: "s3://bucket/prefix",
+// "gs://bucket/prefix", or a local directory path. S3 endpoints and
+// regions come from AWS_ENDPOINT_URL and AWS_REGION. It is for reaching a
+// store without a town, e.g. to restore a backup onto a fresh machine.
+func ParseURL(raw string) (Store, error) {
+	scheme, rest, ok := strings.Cut(raw, "://")
+	if !ok {
+		return NewLocal(raw), nil
+	}
+	if scheme == "file" {
+		return NewLocal(rest), nil
+	}
+	bucket, prefix, _ := strings.Cut(rest, "/")
+	if bucket == "" {
+		return nil, fmt.Errorf("blob store URL %q has no bucket", raw)
+	}
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	switch scheme {
+	case "s3":
+		return NewS3(&config.S3Config{Bucket: bucket, Prefix: prefix, Region: os.Getenv("AWS_REGION"), Endpoint: os.Getenv("AWS_ENDPOINT_URL")})
+	case "gs":
+		return NewGCS(&config.GCSConfig{Bucket: bucket, Prefix: prefix})
+	}
+	return nil, fmt.Errorf("unsupported blob store URL scheme %q (want s3, gs, or a path)", scheme)
+}
+
+// checkKey rejects keys that could escape the store.
+func checkKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || path.Clean(key) != key || key == ".." || strings.HasPrefix(key, "../") {
+		return fmt.Errorf("invalid blob key %q", key)
+	}
+	return nil
+}
@@ -0,0 +1,398 @@
+package blobstore
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// GCS endpoints and OAuth settings.
+const (
+	gcsEndpoint      = "https://storage.googleapis.com"
+	gcsScope         = "https://www.googleapis.com/auth/devstorage.read_write"
+	gcsMetadataHost  = "metadata.google.internal"
+	gcsTokenLifetime = time.Hour
+)
+
+// GCS stores blobs in a Google Cloud Storage bucket through the JSON API.
+type GCS struct {
+	endpoint string
+	bucket   string
+	prefix   string
+	tokens   gcsTokenSource
+	client   *http.Client
+}
+
+// NewGCS returns a store for the bucket cfg describes. Credentials are
+// resolved on first use (see config.GCSConfig).
+func NewGCS(cfg *config.GCSConfig) (*GCS, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("gcs: bucket is required")
+	}
+	g := &GCS{
+		endpoint: gcsEndpoint,
+		bucket:   cfg.Bucket,
+		prefix:   cfg.Prefix,
+		client:   &http.Client{Timeout: 5 * time.Minute},
+	}
+	if host := os.Getenv("STORAGE_EMULATOR_HOST"); host != "" {
+		if !strings.Contains(host, "://") {
+			host = "http://" + host
+		}
+		g.endpoint = strings.TrimRight(host, "/")
+		g.tokens = staticToken("")
+		return g, nil
+	}
+
+	if tok := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); tok != "" {
+		g.tokens = staticToken(tok)
+		return g, nil
+	}
+	keyFile := cfg.CredentialsFile
+	if keyFile == "" {
+		keyFile = os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	}
+	if keyFile != "" {
+		src, err := newServiceAccountTokens(keyFile, g.client)
+		if err != nil {
+			return nil, err
+		}
+		g.tokens = src
+		return g, nil
+	}
+	g.tokens = newMetadataTokens(g.client)
+	return g, nil
+}
+
+// gcsObject is an object resource in JSON API responses.
+type gcsObject struct {
+	Name    string    `json:"name"`
+	Size    string    `json:"size"` // int64 as a string
+	Updated time.Time `json:"updated"`
+}
+
+func (o gcsObject) info(prefix string) Info {
+	size, _ := strconv.ParseInt(o.Size, 10, 64)
+	return Info{Key: strings.TrimPrefix(o.Name, prefix), Size: size, Modified: o.Updated}
+}
+
+func (g *GCS) objectURL(key string) string {
+	return g.endpoint + "/storage/v1/b/" + url.PathEscape(g.bucket) + "/o/" + url.PathEscape(g.prefix+key)
+}
+
+// Put uploads the object with a simple media upload.
+func (g *GCS) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	u := g.endpoint + "/upload/storage/v1/b/" + url.PathEscape(g.bucket) + "/o?" +
+		url.Values{"uploadType": {"media"}, "name": {g.prefix + key}}.Encode()
+	resp, err := g.do(ctx, http.MethodPost, u, r, size)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// Get downloads the object's contents.
+func (g *GCS) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	resp, err := g.do(ctx, http.MethodGet, g.objectURL(key)+"?alt=media", nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Stat fetches the object's metadata.
+func (g *GCS) Stat(ctx context.Context, key string) (*Info, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	resp, err := g.do(ctx, http.MethodGet, g.objectURL(key), nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var obj gcsObject
+	if err := json.NewDecoder(resp.Body).Decode(&obj); err != nil {
+		return nil, fmt.Errorf("gcs: decoding object metadata: %w", err)
+	}
+	info := obj.info(g.prefix)
+	return &info, nil
+}
+
+// Delete removes the object; a missing object is not an error.
+func (g *GCS) Delete(ctx context.Context, key string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	resp, err := g.do(ctx, http.MethodDelete, g.objectURL(key), nil, 0)
+	if err == ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// List pages through the bucket's objects under prefix.
+func (g *GCS) List(ctx context.Context, prefix string) ([]Info, error) {
+	var infos []Info
+	token := ""
+	for {
+		q := url.Values{"prefix": {g.prefix + prefix}, "fields": {"items(name,size,updated),nextPageToken"}}
+		if token != "" {
+			q.Set("pageToken", token)
+		}
+		resp, err := g.do(ctx, http.MethodGet, g.endpoint+"/storage/v1/b/"+url.PathEscape(g.bucket)+"/o?"+q.Encode(), nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Items         []gcsObject `json:"items"`
+			NextPageToken string      `json:"nextPageToken"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("gcs: decoding list response: %w", err)
+		}
+		for _, obj := range page.Items {
+			infos = append(infos, obj.info(g.prefix))
+		}
+		if page.NextPageToken == "" {
+			break
+		}
+		token = page.NextPageToken
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Key < infos[j].Key })
+	return infos, nil
+}
+
+func (g *GCS) String() string {
+	return "gs://" + g.bucket + "/" + g.prefix
+}
+
+// do sends an authorized request. Non-2xx responses become errors; 404 is
+// ErrNotFound.
+func (g *GCS) do(ctx context.Context, method, u string, body io.Reader, size int64) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+	tok, err := g.tokens.token(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gcs: credentials: %w", err)
+	}
+	if tok != "" {
+		req.Header.Set("Authorization", "Bearer "+tok)
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("gcs: %w", err)
+	}
+	if resp.StatusCode/100 == 2 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	var e struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if json.Unmarshal(data, &e) != nil || e.Error.Message == "" {
+		e.Error.Message = strings.TrimSpace(string(data))
+	}
+	return nil, fmt.Errorf("gcs: %s %s: %s: %s", method, g.bucket, resp.Status, e.Error.Message)
+}
+
+// gcsTokenSource yields OAuth access tokens.
+type gcsTokenSource interface {
+	token(ctx context.Context) (string, error)
+}
+
+type staticToken string
+
+func (t staticToken) token(context.Context) (string, error) { return string(t), nil }
+
+// cachedTokens reuses a fetched token until shortly before it expires.
+type cachedTokens struct {
+	mu      sync.Mutex
+	value   string
+	expires time.Time
+	fetch   func(ctx context.Context) (string, time.Duration, error)
+}
+
+func (c *cachedTokens) token(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.value != "" && time.Until(c.expires) > time.Minute {
+		return c.value, nil
+	}
+	tok, ttl, err := c.fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+	c.value, c.expires = tok, time.Now().Add(ttl)
+	return tok, nil
+}
+
+// tokenResponse is an OAuth token endpoint (or metadata server) response.
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+func decodeTokenResponse(resp *http.Response) (string, time.Duration, error) {
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return "", 0, fmt.Errorf("token request: %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	var t tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&t); err != nil || t.AccessToken == "" {
+		return "", 0, fmt.Errorf("token request: malformed response")
+	}
+	return t.AccessToken, time.Duration(t.ExpiresIn) * time.Second, nil
+}
+
+// newMetadataTokens gets tokens for the VM's default service account from
+// the GCE metadata server (GCE_METADATA_HOST overrides its address).
+func newMetadataTokens(client *http.Client) *cachedTokens {
+	host := os.Getenv("GCE_METADATA_HOST")
+	if host == "" {
+		host = gcsMetadataHost
+	}
+	return &cachedTokens{fetch: func(ctx context.Context) (string, time.Duration, error) {
+		u := "http://" + host + "/computeMetadata/v1/instance/service-accounts/default/token"
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return "", 0, err
+		}
+		req.Header.Set("Metadata-Flavor", "Google")
+		resp, err := client.Do(req)
+		if err != nil {
+			return "", 0, fmt.Errorf("no GCS credentials: set GOOGLE_APPLICATION_CREDENTIALS or run on GCE (%v)", err)
+		}
+		return decodeTokenResponse(resp)
+	}}
+}
+
+// serviceAccountKey is the subset of a service account JSON key we use.
+type serviceAccountKey struct {
+	Type        string `json:"type"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// newServiceAccountTokens exchanges self-signed JWTs from a service account
+// key for access tokens (the OAuth 2.0 JWT bearer grant).
+func newServiceAccountTokens(keyFile string, client *http.Client) (*cachedTokens, error) {
+	data, err := os.ReadFile(keyFile) //nolint:gosec // G304: configured credentials path
+	if err != nil {
+		return nil, fmt.Errorf("gcs: reading credentials: %w", err)
+	}
+	var key serviceAccountKey
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, fmt.Errorf("gcs: parsing credentials %s: %w", keyFile, err)
+	}
+	if key.Type != "service_account" || key.ClientEmail == "" {
+		return nil, fmt.Errorf("gcs: %s is not a service account key", keyFile)
+	}
+	signer, err := parseRSAKey(key.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("gcs: %s: %w", keyFile, err)
+	}
+	if key.TokenURI == "" {
+		key.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+
+	return &cachedTokens{fetch: func(ctx context.Context) (string, time.Duration, error) {
+		assertion, err := signJWT(signer, key.ClientEmail, key.TokenURI, time.Now())
+		if err != nil {
+			return "", 0, err
+		}
+		form := url.Values{
+			"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+			"assertion":  {assertion},
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, key.TokenURI, strings.NewReader(form.Encode()))
+		if err != nil {
+			return "", 0, err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		resp, err := client.Do(req)
+		if err != nil {
+			return "", 0, err
+		}
+		return decodeTokenResponse(resp)
+	}}, nil
+}
+
+func parseRSAKey(pemKey string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil {
+		return nil, fmt.Errorf("private_key is not PEM")
+	}
+	if k, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		if rk, ok := k.(*rsa.PrivateKey); ok {
+			return rk, nil
+		}
+		return nil, fmt.Errorf("private_key is not RSA")
+	}
+	return x509.ParsePKCS1PrivateKey(block.Bytes)
+}
+
+// signJWT builds the RS256 assertion for the JWT bearer grant.
+func signJWT(key *rsa.PrivateKey, email, aud string, now time.Time) (string, error) {
+	enc := base64.RawURLEncoding
+	header := enc.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	claims, err := json.Marshal(map[string]interface{}{
+		"iss":   email,
+		"scope": gcsScope,
+		"aud":   aud,
+		"iat":   now.Unix(),
+		"exp":   now.Add(gcsTokenLifetime).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	signing := header + "." + enc.EncodeToString(claims)
+	sum := sha256.Sum256([]byte(signing))
+	sig, err := rsa.SignPKCS1v15(nil, key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", err
+	}
+	return signing + "." + enc.EncodeToString(sig), nil
+}
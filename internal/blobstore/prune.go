@@ -0,0 +1,91 @@
+package blobstore
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/retention"
+)
+
+// Prune removes objects under prefix that fall outside a retention policy:
+// those older than MaxAge, beyond the newest Keep, or that would push the
+// total size of the kept objects past MaxSize. It returns what it removed (or would
+// remove, in a dry run), oldest first.
+func Prune(ctx context.Context, s Store, prefix string, p retention.Policy, now time.Time, dryRun bool) ([]Info, error) {
+	infos, err := s.List(ctx, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("listing %s: %w", s, err)
+	}
+	sort.SliceStable(infos, func(i, j int) bool { return infos[i].Modified.After(infos[j].Modified) })
+
+	var removed []Info
+	var kept int64
+	for i, info := range infos {
+		expired := p.MaxAge > 0 && now.Sub(info.Modified) > p.MaxAge
+		excess := p.Keep > 0 && i >= p.Keep
+		oversize := p.MaxSize > 0 && kept+info.Size > p.MaxSize
+		if !expired && !excess && !oversize {
+			kept += info.Size
+			continue
+		}
+		if !dryRun {
+			if err := s.Delete(ctx, info.Key); err != nil {
+				return removed, fmt.Errorf("removing %s: %w", info.Key, err)
+			}
+		}
+		removed = append(removed, info)
+	}
+	for i, j := 0, len(removed)-1; i < j; i, j = i+1, j-1 {
+		removed[i], removed[j] = removed[j], removed[i]
+	}
+	return removed, nil
+}
+
+// PruneTown applies the town's backups and attachments retention policies
+// to their stores. Backups are pruned per town (under "<townName>/") so
+// towns sharing a bucket don't count each other's. Classes whose policy is
+// unbounded are skipped without listing their store. It keeps going past
+// errors and returns them joined with what it removed.
+func PruneTown(ctx context.Context, townRoot, townName string, dryRun bool) ([]retention.Action, error) {
+	settings, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(townRoot))
+	if err != nil {
+		return nil, fmt.Errorf("loading town settings: %w", err)
+	}
+	policies, err := retention.Policies(settings.Retention)
+	if err != nil {
+		return nil, err
+	}
+	classes := []struct{ class, prefix string }{
+		{ClassBackups, townName + "/"},
+		{ClassAttachments, ""},
+	}
+	now := time.Now()
+	var actions []retention.Action
+	var errs []string
+	for _, c := range classes {
+		p := policies[c.class]
+		if p.MaxAge == 0 && p.MaxSize == 0 && p.Keep == 0 {
+			continue
+		}
+		store, err := OpenClass(townRoot, settings, c.class)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		removed, err := Prune(ctx, store, c.prefix, p, now, dryRun)
+		for _, info := range removed {
+			actions = append(actions, retention.Action{Class: c.class, Op: "remove", Path: strings.TrimSuffix(store.String(), "/") + "/" + info.Key, Bytes: info.Size})
+		}
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return actions, fmt.Errorf("blob retention: %s", strings.Join(errs, "; "))
+	}
+	return actions, nil
+}
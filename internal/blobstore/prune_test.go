@@ -0,0 +1,109 @@
+package blobstore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/retention"
+)
+
+func TestPrune(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name   string
+		policy retention.Policy
+		want   string // removed keys, oldest first
+	}{
+		{"unbounded", retention.Policy{}, ""},
+		{"max age", retention.Policy{MaxAge: 3 * day}, "d5,d4"},
+		{"keep", retention.Policy{Keep: 2}, "d5,d4,d2"},
+		{"max size", retention.Policy{MaxSize: 25}, "d5,d4,d2"},
+		{"combined", retention.Policy{MaxAge: 10 * day, Keep: 3}, "d5,d4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			s := NewLocal(dir)
+			for _, age := range []int{0, 1, 2, 4, 5} {
+				key := "town/d" + string(rune('0'+age))
+				if err := s.Put(context.Background(), key, strings.NewReader("0123456789"), 10); err != nil {
+					t.Fatal(err)
+				}
+				mtime := now.Add(-time.Duration(age) * day)
+				if err := os.Chtimes(filepath.Join(dir, "town", "d"+string(rune('0'+age))), mtime, mtime); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			dry, err := Prune(context.Background(), s, "town/", tt.policy, now, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			removed, err := Prune(context.Background(), s, "town/", tt.policy, now, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := prunedKeys(removed); got != tt.want {
+				t.Errorf("removed %q, want %q", got, tt.want)
+			}
+			if got := prunedKeys(dry); got != tt.want {
+				t.Errorf("dry run reported %q, want %q", got, tt.want)
+			}
+			left, _ := s.List(context.Background(), "")
+			if len(left)+len(removed) != 5 {
+				t.Errorf("%d objects left after removing %d of 5", len(left), len(removed))
+			}
+		})
+	}
+}
+
+func prunedKeys(infos []Info) string {
+	var keys []string
+	for _, i := range infos {
+		keys = append(keys, strings.TrimPrefix(i.Key, "town/"))
+	}
+	return strings.Join(keys, ",")
+}
+
+func TestPruneTown(t *testing.T) {
+	townRoot := t.TempDir()
+	settings := config.NewTownSettings()
+	settings.Retention = &config.RetentionConfig{Backups: &config.RetentionPolicy{Keep: 1}}
+	if err := config.SaveTownSettings(config.TownSettingsPath(townRoot), settings); err != nil {
+		t.Fatal(err)
+	}
+	backups := NewLocal(filepath.Join(townRoot, ClassBackups))
+	attachments := NewLocal(filepath.Join(townRoot, ClassAttachments))
+	ctx := context.Background()
+	old := time.Now().Add(-time.Hour)
+	for _, key := range []string{"home/old.tar.gz", "home/new.tar.gz", "other/old.tar.gz"} {
+		if err := backups.Put(ctx, key, strings.NewReader("x"), 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = os.Chtimes(filepath.Join(townRoot, ClassBackups, "home", "old.tar.gz"), old, old)
+	if err := attachments.Put(ctx, "sha256/ab/abc", strings.NewReader("x"), 1); err != nil {
+		t.Fatal(err)
+	}
+
+	actions, err := PruneTown(ctx, townRoot, "home", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actions) != 1 || actions[0].Class != ClassBackups || !strings.HasSuffix(actions[0].Path, "/home/old.tar.gz") {
+		t.Fatalf("actions = %+v, want only home/old.tar.gz removed", actions)
+	}
+	if _, err := backups.Stat(ctx, "other/old.tar.gz"); err != nil {
+		t.Errorf("another town's backup was pruned: %v", err)
+	}
+	if _, err := attachments.Stat(ctx, "sha256/ab/abc"); err != nil {
+		t.Errorf("attachment pruned under an unbounded policy: %v", err)
+	}
+}
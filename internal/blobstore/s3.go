@@ -0,0 +1,323 @@
+package blobstore
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// unsignedPayload is the x-amz-content-sha256 value for bodies that aren't
+// hashed up front, so uploads can stream.
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+// S3 stores blobs in an S3-compatible bucket using path-style requests
+// signed with AWS Signature Version 4.
+type S3 struct {
+	endpoint *url.URL
+	bucket   string
+	region   string
+	prefix   string
+	creds    s3Credentials
+	client   *http.Client
+	now      func() time.Time
+}
+
+type s3Credentials struct {
+	accessKey, secretKey, sessionToken string
+}
+
+// NewS3 returns a store for the bucket cfg describes, with credentials from
+// the standard AWS environment variables.
+func NewS3(cfg *config.S3Config) (*S3, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("s3: bucket is required")
+	}
+	region := cfg.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = "https://s3." + region + ".amazonaws.com"
+	}
+	u, err := url.Parse(strings.TrimRight(endpoint, "/"))
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("s3: invalid endpoint %q", endpoint)
+	}
+	creds := s3Credentials{
+		accessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		sessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if creds.accessKey == "" || creds.secretKey == "" {
+		return nil, fmt.Errorf("s3: AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set")
+	}
+	return &S3{
+		endpoint: u,
+		bucket:   cfg.Bucket,
+		region:   region,
+		prefix:   cfg.Prefix,
+		creds:    creds,
+		client:   &http.Client{Timeout: 5 * time.Minute},
+		now:      time.Now,
+	}, nil
+}
+
+// Put uploads the object.
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	resp, err := s.do(ctx, http.MethodPut, s.prefix+key, nil, r, size)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// Get downloads the object.
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	resp, err := s.do(ctx, http.MethodGet, s.prefix+key, nil, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Stat issues a HEAD for the object.
+func (s *S3) Stat(ctx context.Context, key string) (*Info, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	resp, err := s.do(ctx, http.MethodHead, s.prefix+key, nil, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	_ = resp.Body.Close()
+	info := &Info{Key: key, Size: resp.ContentLength}
+	info.Modified, _ = http.ParseTime(resp.Header.Get("Last-Modified"))
+	return info, nil
+}
+
+// Delete removes the object. S3 reports success for missing keys.
+func (s *S3) Delete(ctx context.Context, key string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	resp, err := s.do(ctx, http.MethodDelete, s.prefix+key, nil, nil, 0)
+	if err == ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// listBucketResult is the ListObjectsV2 response.
+type listBucketResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List pages through ListObjectsV2.
+func (s *S3) List(ctx context.Context, prefix string) ([]Info, error) {
+	var infos []Info
+	token := ""
+	for {
+		q := url.Values{"list-type": {"2"}, "prefix": {s.prefix + prefix}}
+		if token != "" {
+			q.Set("continuation-token", token)
+		}
+		resp, err := s.do(ctx, http.MethodGet, "", q, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		var page listBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&page)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("s3: decoding list response: %w", err)
+		}
+		for _, c := range page.Contents {
+			infos = append(infos, Info{Key: strings.TrimPrefix(c.Key, s.prefix), Size: c.Size, Modified: c.LastModified})
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			break
+		}
+		token = page.NextContinuationToken
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Key < infos[j].Key })
+	return infos, nil
+}
+
+func (s *S3) String() string {
+	return "s3://" + s.bucket + "/" + s.prefix
+}
+
+// do sends a signed request for an object (or the bucket, with an empty
+// key). Non-2xx responses become errors; 404 is ErrNotFound.
+func (s *S3) do(ctx context.Context, method, key string, query url.Values, body io.Reader, size int64) (*http.Response, error) {
+	u := *s.endpoint
+	u.Path = u.Path + "/" + s.bucket
+	if key != "" {
+		u.Path += "/" + key
+	}
+	u.RawPath = uriEncode(u.Path, false)
+	u.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	req.Header.Set("x-amz-content-sha256", unsignedPayload)
+	signV4(req, s.creds, s.region, s.now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("s3: %w", err)
+	}
+	if resp.StatusCode/100 == 2 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	var e struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if xml.Unmarshal(data, &e) != nil || e.Code == "" {
+		e.Code = resp.Status
+	}
+	return nil, fmt.Errorf("s3: %s %s: %s %s", method, s.bucket+"/"+key, e.Code, e.Message)
+}
+
+// signV4 adds AWS Signature Version 4 headers to req for S3, signing the
+// host and every header already set. The payload hash must already be in
+// x-amz-content-sha256.
+func signV4(req *http.Request, creds s3Credentials, region string, now time.Time) {
+	signRequest(req, creds, region, "s3", req.Header.Get("x-amz-content-sha256"), now)
+}
+
+// signRequest is SigV4 for any service: S3 sends the payload hash in a
+// header, other services only sign it.
+func signRequest(req *http.Request, creds s3Credentials, region, service, payloadHash string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	req.Header.Set("x-amz-date", amzDate)
+	if creds.sessionToken != "" {
+		req.Header.Set("x-amz-security-token", creds.sessionToken)
+	}
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uriEncode(req.URL.Path, false),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := day + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hexSHA256(canonicalRequest)
+
+	key := hmacSHA256([]byte("AWS4"+creds.secretKey), day)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.accessKey, scope, signedHeaders, signature))
+}
+
+// canonicalQuery encodes query parameters sorted by key, as SigV4 requires.
+func canonicalQuery(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		for _, v := range q[k] {
+			parts = append(parts, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode percent-encodes everything but RFC 3986 unreserved characters,
+// and slashes unless encodeSlash is set.
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hexSHA256(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
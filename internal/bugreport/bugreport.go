@@ -0,0 +1,196 @@
+// Package bugreport collects a redacted diagnostic bundle for attaching to
+// issues: town and rig configs, recent logs, tool versions, and a doctor
+// report, written as a tar.gz archive.
+package bugreport
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultLogLines is how much of each log the bundle keeps.
+const DefaultLogLines = 500
+
+// configGlobs are the town-relative config files collected, redacted.
+var configGlobs = []string{
+	"mayor/*.json",
+	"settings/*.json",
+	".beads/routes.jsonl",
+	".beads/config.yaml",
+	".beads/metadata.json",
+	"*/config.json",
+	"*/settings/*.json",
+	"*/mayor/rig/.beads/config.yaml",
+	"*/mayor/rig/.beads/metadata.json",
+}
+
+// logFiles are the town-relative logs whose tails are collected.
+var logFiles = []string{
+	".events.jsonl",
+	"logs/town.log",
+	"daemon/daemon.log",
+	"daemon/dolt.log",
+}
+
+// versionCommands report the tools gt depends on.
+var versionCommands = [][]string{
+	{"bd", "--version"},
+	{"git", "--version"},
+	{"tmux", "-V"},
+	{"dolt", "version"},
+}
+
+// File is one entry in the bundle.
+type File struct {
+	Name string
+	Data []byte
+}
+
+// Bundle is a diagnostic bundle being assembled.
+type Bundle struct {
+	Files []File
+}
+
+// Add adds a file, redacting its contents.
+func (b *Bundle) Add(name string, data []byte) {
+	b.Files = append(b.Files, File{Name: name, Data: Redact(data)})
+}
+
+// CollectConfigs adds the town's and rigs' config files.
+func (b *Bundle) CollectConfigs(townRoot string) {
+	var names []string
+	for _, glob := range configGlobs {
+		matches, _ := filepath.Glob(filepath.Join(townRoot, glob))
+		for _, m := range matches {
+			if rel, err := filepath.Rel(townRoot, m); err == nil {
+				names = append(names, filepath.ToSlash(rel))
+			}
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if data, err := os.ReadFile(filepath.Join(townRoot, filepath.FromSlash(name))); err == nil {
+			b.Add("config/"+name, data)
+		}
+	}
+}
+
+// CollectLogs adds the last lines of the event log, town log, and daemon
+// logs.
+func (b *Bundle) CollectLogs(townRoot string, lines int) {
+	for _, name := range logFiles {
+		data, err := os.ReadFile(filepath.Join(townRoot, name))
+		if err != nil {
+			continue
+		}
+		b.Add("logs/"+strings.TrimPrefix(filepath.Base(name), "."), Tail(data, lines))
+	}
+}
+
+// CollectVersions adds versions.txt with gt's version, the platform, and
+// the version of each tool gt runs.
+func (b *Bundle) CollectVersions(gtVersion string) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "gt: %s\n", gtVersion)
+	fmt.Fprintf(&buf, "platform: %s/%s (%s)\n", runtime.GOOS, runtime.GOARCH, runtime.Version())
+	for _, args := range versionCommands {
+		out, err := exec.Command(args[0], args[1:]...).CombinedOutput() //nolint:gosec // G204: fixed commands
+		version := strings.TrimSpace(string(out))
+		if err != nil && version == "" {
+			version = "not available (" + err.Error() + ")"
+		}
+		fmt.Fprintf(&buf, "%s: %s\n", args[0], version)
+	}
+	b.Add("versions.txt", buf.Bytes())
+}
+
+// Write writes the bundle to path as a tar.gz under a top-level directory
+// named after the archive.
+func (b *Bundle) Write(path string) error {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	dir := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(path), ".gz"), ".tar")
+	now := time.Now()
+	for _, f := range b.Files {
+		hdr := &tar.Header{Name: dir + "/" + f.Name, Mode: 0600, Size: int64(len(f.Data)), ModTime: now}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(f.Data); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0600)
+}
+
+// Tail returns the last n lines of data.
+func Tail(data []byte, n int) []byte {
+	data = bytes.TrimRight(data, "\n")
+	lines := bytes.Split(data, []byte("\n"))
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return append(bytes.Join(lines, []byte("\n")), '\n')
+}
+
+const redacted = "[REDACTED]"
+
+var (
+	// secretKey matches "key": "value", key: value, and KEY=value where
+	// the key names a credential.
+	secretKey = regexp.MustCompile(`(?i)("?[\w.-]*(?:token|secret|password|passwd|api[_-]?key|credential|private[_-]?key|auth)[\w.-]*"?\s*[:=]\s*)("[^"]*"|'[^']*'|[^\s,}]+)`)
+
+	// secretValue matches well-known credential formats wherever they
+	// appear.
+	secretValue = regexp.MustCompile(`sk-ant-[\w-]{8,}|sk-[A-Za-z0-9]{20,}|gh[pousr]_[A-Za-z0-9]{20,}|github_pat_\w{20,}|xox[abprs]-[\w-]{10,}|AKIA[0-9A-Z]{16}|-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)
+
+	// urlCredentials matches user:password@ in URLs.
+	urlCredentials = regexp.MustCompile(`(://)[^/\s:@]+:[^/\s@]+@`)
+
+	email = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+
+	ansi = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+)
+
+// Redact removes credentials, email addresses, terminal escapes, and the
+// user's home directory from data.
+func Redact(data []byte) []byte {
+	data = ansi.ReplaceAll(data, nil)
+	data = secretValue.ReplaceAll(data, []byte(redacted))
+	data = secretKey.ReplaceAllFunc(data, func(m []byte) []byte {
+		sub := secretKey.FindSubmatch(m)
+		out := append([]byte{}, sub[1]...)
+		if q := sub[2][0]; q == '"' || q == '\'' {
+			return append(append(append(out, q), redacted...), q)
+		}
+		return append(out, redacted...)
+	})
+	data = urlCredentials.ReplaceAll(data, []byte("${1}"+redacted+"@"))
+	data = email.ReplaceAllFunc(data, func(m []byte) []byte {
+		if bytes.HasPrefix(m, []byte("git@")) {
+			return m // SSH remote, not an address
+		}
+		return []byte("[EMAIL]")
+	})
+	if home, err := os.UserHomeDir(); err == nil && len(home) > 1 {
+		data = bytes.ReplaceAll(data, []byte(home), []byte("~"))
+	}
+	return data
+}
@@ -0,0 +1,160 @@
+// Package capacity estimates how long each rig's ready queue will take to
+// drain and how many agents each rig needs to drain it in time.
+package capacity
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Rate sources: where a rig's per-agent throughput came from.
+const (
+	RateRig  = "rig"  // the rig's own completions
+	RateTown = "town" // the town-wide average; the rig had no history
+)
+
+// Advice for a rig's agent allocation.
+const (
+	AdviceHold   = "hold"   // current concurrency drains the queue in time
+	AdviceAdd    = "add"    // the queue won't drain by the target
+	AdviceRemove = "remove" // agents would sit idle; free them for other rigs
+	AdviceNoData = "no-data"
+)
+
+// Input is what the planner knows about a rig.
+type Input struct {
+	Rig       string
+	Ready     int // issues ready to work now
+	Completed int // issues completed during the history window
+	Agents    int // distinct agents that completed them
+	MaxAgents int // configured concurrency (max_polecats)
+}
+
+// RigPlan is the capacity estimate for one rig.
+type RigPlan struct {
+	Rig       string `json:"rig"`
+	Ready     int    `json:"ready"`
+	Completed int    `json:"completed"`
+	Agents    int    `json:"agents"`
+	MaxAgents int    `json:"max_agents"`
+	// Throughput is issues completed per day over the window.
+	Throughput float64 `json:"throughput_per_day"`
+	// PerAgent is issues per agent per day, from RateSource.
+	PerAgent   float64 `json:"per_agent_per_day"`
+	RateSource string  `json:"rate_source,omitempty"`
+	// DrainHours is how long the ready queue takes at MaxAgents; nil when
+	// there's no throughput to estimate from.
+	DrainHours  *float64 `json:"drain_hours,omitempty"`
+	Recommended int      `json:"recommended_agents"`
+	Change      int      `json:"change"`
+	Advice      string   `json:"advice"`
+}
+
+// Report is a town's capacity plan.
+type Report struct {
+	WindowDays  float64   `json:"window_days"`
+	TargetHours float64   `json:"target_hours"`
+	Rigs        []RigPlan `json:"rigs"`
+	Ready       int       `json:"ready"`
+	MaxAgents   int       `json:"max_agents"`
+	Recommended int       `json:"recommended_agents"`
+}
+
+// Build plans each rig's capacity from its ready queue, its completions
+// over window, and its configured concurrency, recommending the agents
+// needed to drain the queue within target.
+//
+// Agents are assumed to keep up the per-agent rate seen over the window.
+// A rig without completions borrows the town-wide rate. To avoid churn a
+// rig is only told to shed agents when its queue would drain in under half
+// the target, and never below one agent while it has ready work.
+func Build(inputs []Input, window, target time.Duration) *Report {
+	days := window.Hours() / 24
+	r := &Report{WindowDays: days, TargetHours: target.Hours()}
+
+	var townCompleted, townAgents int
+	for _, in := range inputs {
+		townCompleted += in.Completed
+		townAgents += in.Agents
+	}
+	townRate := 0.0
+	if townAgents > 0 && days > 0 {
+		townRate = float64(townCompleted) / float64(townAgents) / days
+	}
+
+	for _, in := range inputs {
+		p := RigPlan{
+			Rig:       in.Rig,
+			Ready:     in.Ready,
+			Completed: in.Completed,
+			Agents:    in.Agents,
+			MaxAgents: in.MaxAgents,
+		}
+		if days > 0 {
+			p.Throughput = float64(in.Completed) / days
+		}
+		switch {
+		case in.Agents > 0 && in.Completed > 0:
+			p.PerAgent, p.RateSource = p.Throughput/float64(in.Agents), RateRig
+		case townRate > 0:
+			p.PerAgent, p.RateSource = townRate, RateTown
+		}
+		plan(&p, target)
+		r.Rigs = append(r.Rigs, p)
+		r.Ready += p.Ready
+		r.MaxAgents += p.MaxAgents
+		r.Recommended += p.Recommended
+	}
+	SortByDrain(r.Rigs)
+	return r
+}
+
+// plan fills in a rig's drain time and recommendation.
+func plan(p *RigPlan, target time.Duration) {
+	p.Recommended, p.Advice = p.MaxAgents, AdviceHold
+	if p.Ready == 0 {
+		hours := 0.0
+		p.DrainHours = &hours
+		if p.MaxAgents > 1 {
+			p.Recommended, p.Advice = 1, AdviceRemove
+		}
+		p.Change = p.Recommended - p.MaxAgents
+		return
+	}
+	if p.PerAgent <= 0 {
+		p.Advice = AdviceNoData
+		return
+	}
+	if p.MaxAgents > 0 {
+		hours := float64(p.Ready) / (p.PerAgent * float64(p.MaxAgents)) * 24
+		p.DrainHours = &hours
+	}
+
+	need := int(math.Ceil(float64(p.Ready) / (p.PerAgent * target.Hours() / 24)))
+	if need < 1 {
+		need = 1
+	}
+	switch {
+	case need > p.MaxAgents:
+		p.Recommended, p.Advice = need, AdviceAdd
+	case p.DrainHours != nil && *p.DrainHours < target.Hours()/2 && need < p.MaxAgents:
+		p.Recommended, p.Advice = need, AdviceRemove
+	}
+	p.Change = p.Recommended - p.MaxAgents
+}
+
+// SortByDrain orders rigs by drain time, longest first, then by name;
+// rigs without an estimate go last.
+func SortByDrain(rigs []RigPlan) {
+	sort.SliceStable(rigs, func(i, j int) bool {
+		a, b := rigs[i], rigs[j]
+		if (a.DrainHours == nil) != (b.DrainHours == nil) {
+			return b.DrainHours == nil
+		}
+		if a.DrainHours != nil && *a.DrainHours != *b.DrainHours {
+			return *a.DrainHours > *b.DrainHours
+		}
+		return a.Rig < b.Rig
+	})
+}
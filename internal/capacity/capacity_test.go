@@ -0,0 +1,66 @@
+package capacity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuild(t *testing.T) {
+	day := 24 * time.Hour
+	inputs := []Input{
+		// 2 issues/agent/day; 40 ready at 2 agents is 10 days.
+		{Rig: "backed-up", Ready: 40, Completed: 28, Agents: 2, MaxAgents: 2},
+		// 1 ready at 4 agents drains in 3h: free all but one.
+		{Rig: "idle", Ready: 1, Completed: 14, Agents: 1, MaxAgents: 4},
+		// 6 ready at 1 agent/day, 2 agents: 3 days, on target.
+		{Rig: "steady", Ready: 6, Completed: 7, Agents: 1, MaxAgents: 2},
+		// No history: borrows the town rate.
+		{Rig: "new", Ready: 10, MaxAgents: 1},
+		{Rig: "empty", MaxAgents: 3},
+	}
+	r := Build(inputs, 7*day, 3*day)
+
+	got := make(map[string]RigPlan)
+	for _, p := range r.Rigs {
+		got[p.Rig] = p
+	}
+	tests := []struct {
+		rig         string
+		advice      string
+		recommended int
+		source      string
+	}{
+		{"backed-up", AdviceAdd, 7, RateRig},
+		{"idle", AdviceRemove, 1, RateRig},
+		{"steady", AdviceHold, 2, RateRig},
+		{"new", AdviceAdd, 2, RateTown}, // 1.75/agent/day town-wide
+		{"empty", AdviceRemove, 1, RateTown},
+	}
+	for _, tt := range tests {
+		p := got[tt.rig]
+		if p.Advice != tt.advice || p.Recommended != tt.recommended || p.RateSource != tt.source {
+			t.Errorf("%s: advice=%s recommended=%d source=%q, want %s %d %q",
+				tt.rig, p.Advice, p.Recommended, p.RateSource, tt.advice, tt.recommended, tt.source)
+		}
+		if p.Change != p.Recommended-p.MaxAgents {
+			t.Errorf("%s: change = %d", tt.rig, p.Change)
+		}
+	}
+	if d := got["backed-up"].DrainHours; d == nil || *d != 240 {
+		t.Errorf("backed-up drain = %v, want 240h", d)
+	}
+	if r.Rigs[0].Rig != "backed-up" {
+		t.Errorf("first rig = %s, want the longest drain", r.Rigs[0].Rig)
+	}
+	if r.Ready != 57 || r.MaxAgents != 12 || r.Recommended != 13 {
+		t.Errorf("totals = ready %d, agents %d -> %d", r.Ready, r.MaxAgents, r.Recommended)
+	}
+}
+
+func TestBuild_NoHistory(t *testing.T) {
+	r := Build([]Input{{Rig: "a", Ready: 5, MaxAgents: 2}}, 7*24*time.Hour, 24*time.Hour)
+	p := r.Rigs[0]
+	if p.Advice != AdviceNoData || p.DrainHours != nil || p.Recommended != 2 {
+		t.Errorf("plan = %+v, want no-data holding at 2", p)
+	}
+}
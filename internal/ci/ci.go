@@ -0,0 +1,304 @@
+// Package ci connects Gas Town to GitHub CI: it publishes merge-queue and
+// convoy status as commit statuses, and reads the statuses and check runs
+// that GitHub Actions (or any other CI) leaves on a commit.
+//
+// Requests go through the GitHub CLI ('gh api'), so they use whatever
+// credentials gh is logged in with.
+package ci
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Status contexts Gas Town publishes. Branch protection can require
+// ContextMergeQueue so GitHub only merges what the refinery has passed.
+const (
+	ContextMergeQueue = "gastown/merge-queue"
+	ContextConvoy     = "gastown/convoy"
+
+	// OwnPrefix marks Gas Town's own contexts, which 'gt ci wait' skips so
+	// the refinery never waits on itself.
+	OwnPrefix = "gastown/"
+)
+
+// State is a commit status state.
+type State string
+
+const (
+	Pending State = "pending"
+	Success State = "success"
+	Failure State = "failure"
+	Error   State = "error"
+)
+
+// maxDescription is GitHub's limit on a status description.
+const maxDescription = 140
+
+// Status is a commit status to publish.
+type Status struct {
+	Context     string
+	State       State
+	Description string
+	TargetURL   string
+}
+
+// ConvoyStatus describes a convoy's progress as a commit status: pending
+// until every tracked issue has landed.
+func ConvoyStatus(convoyID string, done, total int, closed bool) Status {
+	s := Status{
+		Context:     ContextConvoy,
+		State:       Pending,
+		Description: fmt.Sprintf("Convoy %s: %d/%d issues landed", convoyID, done, total),
+	}
+	if closed || (total > 0 && done == total) {
+		s.State = Success
+	}
+	return s
+}
+
+// Check is one CI result on a commit: a commit status or a check run.
+type Check struct {
+	Name  string `json:"name"`
+	State State  `json:"state"`
+	// Kind is "status" or "check_run".
+	Kind string `json:"kind"`
+	// Detail is the status description or the check run's conclusion.
+	Detail string `json:"detail,omitempty"`
+	URL    string `json:"url,omitempty"`
+}
+
+// Repo is a GitHub repository.
+type Repo struct {
+	Owner string
+	Name  string
+}
+
+func (r Repo) String() string {
+	return r.Owner + "/" + r.Name
+}
+
+// ParseRepo extracts the GitHub repository from a git URL in https, ssh,
+// or scp-like form.
+func ParseRepo(gitURL string) (Repo, error) {
+	u := strings.TrimSpace(gitURL)
+	if i := strings.Index(u, "://"); i >= 0 {
+		u = u[i+3:]
+	} else if at := strings.Index(u, "@"); at >= 0 {
+		// scp-like: git@github.com:owner/repo
+		u = strings.Replace(u[at+1:], ":", "/", 1)
+	}
+	if at := strings.Index(u, "@"); at >= 0 && at < strings.Index(u+"/", "/") {
+		u = u[at+1:]
+	}
+	u = strings.TrimSuffix(strings.TrimSuffix(u, "/"), ".git")
+	parts := strings.Split(u, "/")
+	host := strings.ToLower(strings.Split(parts[0], ":")[0])
+	if host != "github.com" || len(parts) != 3 || parts[1] == "" || parts[2] == "" {
+		return Repo{}, fmt.Errorf("%q is not a GitHub repository", gitURL)
+	}
+	return Repo{Owner: parts[1], Name: parts[2]}, nil
+}
+
+// Runner runs gh with args and returns its stdout.
+type Runner func(ctx context.Context, args ...string) ([]byte, error)
+
+// Client talks to GitHub through gh.
+type Client struct {
+	// Run runs gh. Default: the gh binary on PATH.
+	Run Runner
+}
+
+// NewClient returns a client that runs gh from PATH.
+func NewClient() *Client {
+	return &Client{Run: runGH}
+}
+
+func runGH(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "gh", args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("gh %s: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("gh %s: %w", args[0], err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// Publish sets a commit status on sha.
+func (c *Client) Publish(ctx context.Context, repo Repo, sha string, s Status) error {
+	desc := s.Description
+	if r := []rune(desc); len(r) > maxDescription {
+		desc = string(r[:maxDescription-1]) + "…"
+	}
+	args := []string{"api", "--method", "POST", fmt.Sprintf("repos/%s/statuses/%s", repo, sha),
+		"-f", "state=" + string(s.State),
+		"-f", "context=" + s.Context,
+		"-f", "description=" + desc,
+	}
+	if s.TargetURL != "" {
+		args = append(args, "-f", "target_url="+s.TargetURL)
+	}
+	if _, err := c.Run(ctx, args...); err != nil {
+		return fmt.Errorf("publishing %s status on %s: %w", s.Context, shortSHA(sha), err)
+	}
+	return nil
+}
+
+// Checks returns the commit statuses and check runs on sha, sorted by name.
+func (c *Client) Checks(ctx context.Context, repo Repo, sha string) ([]Check, error) {
+	out, err := c.Run(ctx, "api", fmt.Sprintf("repos/%s/commits/%s/status?per_page=100", repo, sha))
+	if err != nil {
+		return nil, err
+	}
+	var combined struct {
+		Statuses []struct {
+			Context     string `json:"context"`
+			State       string `json:"state"`
+			Description string `json:"description"`
+			TargetURL   string `json:"target_url"`
+		} `json:"statuses"`
+	}
+	if err := json.Unmarshal(out, &combined); err != nil {
+		return nil, fmt.Errorf("parsing commit status: %w", err)
+	}
+
+	out, err = c.Run(ctx, "api", fmt.Sprintf("repos/%s/commits/%s/check-runs?per_page=100", repo, sha))
+	if err != nil {
+		return nil, err
+	}
+	var runs struct {
+		CheckRuns []struct {
+			Name       string `json:"name"`
+			Status     string `json:"status"`
+			Conclusion string `json:"conclusion"`
+			HTMLURL    string `json:"html_url"`
+		} `json:"check_runs"`
+	}
+	if err := json.Unmarshal(out, &runs); err != nil {
+		return nil, fmt.Errorf("parsing check runs: %w", err)
+	}
+
+	var checks []Check
+	for _, s := range combined.Statuses {
+		state := State(s.State)
+		if state == Error {
+			state = Failure
+		}
+		checks = append(checks, Check{Name: s.Context, State: state, Kind: "status", Detail: s.Description, URL: s.TargetURL})
+	}
+	for _, r := range runs.CheckRuns {
+		checks = append(checks, Check{Name: r.Name, State: checkRunState(r.Status, r.Conclusion), Kind: "check_run", Detail: r.Conclusion, URL: r.HTMLURL})
+	}
+	sort.Slice(checks, func(i, j int) bool { return checks[i].Name < checks[j].Name })
+	return checks, nil
+}
+
+// checkRunState maps a check run's status and conclusion to a State.
+// Neutral and skipped runs don't block.
+func checkRunState(status, conclusion string) State {
+	if status != "completed" {
+		return Pending
+	}
+	switch conclusion {
+	case "success", "neutral", "skipped":
+		return Success
+	default:
+		return Failure
+	}
+}
+
+// Overall combines checks into one state: failure if any failed, pending
+// if any are still running, success otherwise. No checks is pending:
+// CI may not have started yet.
+func Overall(checks []Check) State {
+	if len(checks) == 0 {
+		return Pending
+	}
+	state := Success
+	for _, c := range checks {
+		switch c.State {
+		case Failure, Error:
+			return Failure
+		case Pending:
+			state = Pending
+		}
+	}
+	return state
+}
+
+// Filter drops checks whose names start with any of the prefixes.
+func Filter(checks []Check, prefixes []string) []Check {
+	var out []Check
+	for _, c := range checks {
+		skip := false
+		for _, p := range prefixes {
+			if p != "" && strings.HasPrefix(c.Name, p) {
+				skip = true
+				break
+			}
+		}
+		if !skip {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// WaitOptions configures Wait.
+type WaitOptions struct {
+	// Interval between polls. Default: 15s.
+	Interval time.Duration
+	// Ignore lists check name prefixes that don't count, e.g. OwnPrefix.
+	Ignore []string
+	// OnPoll, if set, is called with the checks after each poll.
+	OnPoll func([]Check)
+}
+
+// Wait polls sha's checks until none are pending, then returns them and
+// their overall state. It gives up when ctx is done, returning the last
+// checks seen with ctx's error.
+func (c *Client) Wait(ctx context.Context, repo Repo, sha string, opts WaitOptions) ([]Check, State, error) {
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = 15 * time.Second
+	}
+	var checks []Check
+	for {
+		all, err := c.Checks(ctx, repo, sha)
+		if err != nil {
+			if ctx.Err() != nil {
+				return checks, Pending, ctx.Err()
+			}
+			return nil, "", err
+		}
+		checks = Filter(all, opts.Ignore)
+		if opts.OnPoll != nil {
+			opts.OnPoll(checks)
+		}
+		if state := Overall(checks); state != Pending {
+			return checks, state, nil
+		}
+		select {
+		case <-ctx.Done():
+			return checks, Pending, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
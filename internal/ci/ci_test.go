@@ -0,0 +1,185 @@
+package ci
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRepo(t *testing.T) {
+	tests := []struct {
+		url     string
+		want    string
+		wantErr bool
+	}{
+		{"https://github.com/acme/widgets.git", "acme/widgets", false},
+		{"https://github.com/acme/widgets", "acme/widgets", false},
+		{"git@github.com:acme/widgets.git", "acme/widgets", false},
+		{"ssh://git@github.com/acme/widgets.git", "acme/widgets", false},
+		{"https://token@github.com/acme/widgets/", "acme/widgets", false},
+		{"https://gitlab.com/acme/widgets.git", "", true},
+		{"https://github.com/acme", "", true},
+		{"/srv/git/widgets.git", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRepo(tt.url)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRepo(%q) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got.String() != tt.want {
+			t.Errorf("ParseRepo(%q) = %s, want %s", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestPublish(t *testing.T) {
+	var got []string
+	c := &Client{Run: func(ctx context.Context, args ...string) ([]byte, error) {
+		got = args
+		return []byte("{}"), nil
+	}}
+	err := c.Publish(context.Background(), Repo{"acme", "widgets"}, "abc123", Status{
+		Context:     ContextMergeQueue,
+		State:       Success,
+		Description: strings.Repeat("x", 200),
+		TargetURL:   "https://github.com/acme/widgets/pull/7",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	joined := strings.Join(got, " ")
+	for _, want := range []string{"repos/acme/widgets/statuses/abc123", "state=success", "context=gastown/merge-queue", "target_url=https://github.com/acme/widgets/pull/7"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("gh args missing %q: %v", want, got)
+		}
+	}
+	for _, arg := range got {
+		if strings.HasPrefix(arg, "description=") && len([]rune(strings.TrimPrefix(arg, "description="))) != maxDescription {
+			t.Errorf("description not truncated to %d runes: %q", maxDescription, arg)
+		}
+	}
+}
+
+// fakeGitHub answers the status and check-runs endpoints from canned
+// responses, advancing through them on each poll.
+type fakeGitHub struct {
+	statuses []string
+	runs     []string
+	polls    int
+}
+
+func (f *fakeGitHub) run(ctx context.Context, args ...string) ([]byte, error) {
+	path := args[len(args)-1]
+	i := f.polls
+	if strings.Contains(path, "/check-runs") {
+		f.polls++
+		return []byte(f.runs[min(i, len(f.runs)-1)]), nil
+	}
+	return []byte(f.statuses[min(i, len(f.statuses)-1)]), nil
+}
+
+func TestChecks(t *testing.T) {
+	f := &fakeGitHub{
+		statuses: []string{`{"statuses":[{"context":"gastown/merge-queue","state":"pending"},{"context":"ci/legacy","state":"error","description":"boom"}]}`},
+		runs:     []string{`{"check_runs":[{"name":"build","status":"completed","conclusion":"success"},{"name":"lint","status":"in_progress"},{"name":"docs","status":"completed","conclusion":"skipped"}]}`},
+	}
+	checks, err := (&Client{Run: f.run}).Checks(context.Background(), Repo{"acme", "widgets"}, "abc123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]State{
+		"build":               Success,
+		"ci/legacy":           Failure,
+		"docs":                Success,
+		"gastown/merge-queue": Pending,
+		"lint":                Pending,
+	}
+	if len(checks) != len(want) {
+		t.Fatalf("checks = %+v", checks)
+	}
+	for _, c := range checks {
+		if c.State != want[c.Name] {
+			t.Errorf("%s: state %s, want %s", c.Name, c.State, want[c.Name])
+		}
+	}
+	if got := Overall(checks); got != Failure {
+		t.Errorf("Overall = %s, want failure", got)
+	}
+	if got := Overall(Filter(checks, []string{"ci/", OwnPrefix})); got != Pending {
+		t.Errorf("Overall without ci/ and own = %s, want pending", got)
+	}
+}
+
+func TestOverall(t *testing.T) {
+	tests := []struct {
+		name   string
+		checks []Check
+		want   State
+	}{
+		{"no checks yet", nil, Pending},
+		{"all passed", []Check{{State: Success}, {State: Success}}, Success},
+		{"one running", []Check{{State: Success}, {State: Pending}}, Pending},
+		{"failure wins", []Check{{State: Pending}, {State: Failure}}, Failure},
+	}
+	for _, tt := range tests {
+		if got := Overall(tt.checks); got != tt.want {
+			t.Errorf("%s: Overall = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWait(t *testing.T) {
+	f := &fakeGitHub{
+		statuses: []string{`{"statuses":[{"context":"gastown/merge-queue","state":"pending"}]}`},
+		runs: []string{
+			`{"check_runs":[]}`,
+			`{"check_runs":[{"name":"build","status":"queued"}]}`,
+			`{"check_runs":[{"name":"build","status":"completed","conclusion":"success"}]}`,
+		},
+	}
+	polls := 0
+	checks, state, err := (&Client{Run: f.run}).Wait(context.Background(), Repo{"acme", "widgets"}, "abc123", WaitOptions{
+		Interval: time.Millisecond,
+		Ignore:   []string{OwnPrefix},
+		OnPoll:   func([]Check) { polls++ },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state != Success || len(checks) != 1 || polls != 3 {
+		t.Errorf("Wait = %s with %+v after %d polls, want success with build after 3", state, checks, polls)
+	}
+
+	f = &fakeGitHub{
+		statuses: []string{`{"statuses":[]}`},
+		runs:     []string{`{"check_runs":[{"name":"build","status":"in_progress"}]}`},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	checks, state, err = (&Client{Run: f.run}).Wait(ctx, Repo{"acme", "widgets"}, "abc123", WaitOptions{Interval: time.Millisecond})
+	if !errors.Is(err, context.DeadlineExceeded) || state != Pending || len(checks) != 1 {
+		t.Errorf("timed-out Wait = %s, %+v, %v", state, checks, err)
+	}
+}
+
+func TestConvoyStatus(t *testing.T) {
+	tests := []struct {
+		done, total int
+		closed      bool
+		want        State
+	}{
+		{1, 3, false, Pending},
+		{3, 3, false, Success},
+		{0, 0, false, Pending},
+		{2, 3, true, Success},
+	}
+	for _, tt := range tests {
+		s := ConvoyStatus("hq-cv-1", tt.done, tt.total, tt.closed)
+		if s.State != tt.want || s.Context != ContextConvoy {
+			t.Errorf("ConvoyStatus(%d/%d, closed=%v) = %+v, want %s", tt.done, tt.total, tt.closed, s, tt.want)
+		}
+	}
+}
@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"sort"
@@ -132,9 +131,7 @@ func runAccountList(cmd *cobra.Command, args []string) error {
 	})
 
 	if accountJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(items)
+		return outputJSON(items)
 	}
 
 	// Text output
@@ -3,9 +3,12 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/paging"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
@@ -23,18 +26,47 @@ var (
 	activityIssue     string
 	activityTo        string
 	activityCount     int
+
+	// Activity feed flags
+	activityFeedActor string
+	activityFeedRig   string
+	activityFeedSince string
+	activityFeedLimit int
+	activityFeedSkip  int
+	activityFeedAll   bool
+	activityFeedJSON  bool
 )
 
 var activityCmd = &cobra.Command{
 	Use:     "activity",
 	GroupID: GroupDiag,
 	Short:   "Emit and view activity events",
-	Long: `Emit and view activity events for the Gas Town activity feed.
+	Long: `Show what happened across the town as a readable feed, or emit an
+activity event.
+
+Without a subcommand, prints the event log as sentences, oldest first:
+
+  14:02  gastown/polecats/furiosa  submitted gt-mr-abc for gt-123
+  14:09  gastown/refinery          merged gt-mr-abc (polecat/furiosa/gt-123)
 
-Events are written to ~/gt/.events.jsonl and can be viewed with 'gt feed'.
+--actor matches an agent's full address or its last part, so "furiosa"
+matches gastown/polecats/furiosa; an address ending in / matches
+everything under it. --rig keeps events about a rig, from its payload or
+the actor's address. Audit-only events are left out unless --all is given.
+
+Events are written to ~/gt/.events.jsonl; 'gt feed' shows them live.
+
+Examples:
+  gt activity --since 2h
+  gt activity --actor furiosa
+  gt activity --rig gastown --since 30m
+  gt activity --actor gastown/ --json
+  gt activity --limit 50 --offset 50   # The 50 events before the latest 50
 
 Subcommands:
   emit    Emit an activity event`,
+	Args: cobra.NoArgs,
+	RunE: runActivity,
 }
 
 var activityEmitCmd = &cobra.Command{
@@ -83,6 +115,15 @@ func init() {
 	activityEmitCmd.Flags().StringVar(&activityTo, "to", "", "Escalation target (for escalation_sent: mayor, deacon)")
 	activityEmitCmd.Flags().IntVar(&activityCount, "count", 0, "Polecat count (for patrol events)")
 
+	// Feed flags
+	activityCmd.Flags().StringVar(&activityFeedActor, "actor", "", "Only events by this agent (full address, last part, or prefix ending in /)")
+	activityCmd.Flags().StringVar(&activityFeedRig, "rig", "", "Only events about this rig")
+	activityCmd.Flags().StringVar(&activityFeedSince, "since", "", "Only events in this window (e.g., 30m, 2h, 24h)")
+	activityCmd.Flags().IntVarP(&activityFeedLimit, "limit", "n", 50, "Maximum events to show, most recent kept (0 for all)")
+	activityCmd.Flags().IntVar(&activityFeedSkip, "offset", 0, "Skip this many of the most recent events first, to page back")
+	activityCmd.Flags().BoolVar(&activityFeedAll, "all", false, "Include audit-only events")
+	activityCmd.Flags().BoolVar(&activityFeedJSON, "json", false, "Output as JSON")
+
 	activityCmd.AddCommand(activityEmitCmd)
 	rootCmd.AddCommand(activityCmd)
 }
@@ -195,4 +236,118 @@ func runActivityEmit(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// activityEntry is one line of the activity feed.
+type activityEntry struct {
+	Time  time.Time `json:"time"`
+	Actor string    `json:"actor,omitempty"`
+	Type  string    `json:"type"`
+	Rig   string    `json:"rig,omitempty"`
+	Text  string    `json:"text"`
+}
+
+func runActivity(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	var since time.Time
+	if activityFeedSince != "" {
+		d, err := time.ParseDuration(activityFeedSince)
+		if err != nil {
+			return fmt.Errorf("invalid --since duration: %w", err)
+		}
+		since = time.Now().Add(-d)
+	}
+
+	page := paging.Page{Limit: activityFeedLimit, Offset: activityFeedSkip}
+	if err := page.Validate(); err != nil {
+		return err
+	}
+	evts, pageInfo, err := events.ReadTail(townRoot, func(e events.Event) bool {
+		return (activityFeedAll || e.Visibility != events.VisibilityAudit) &&
+			!e.Time().Before(since) &&
+			activityActorMatches(e.Actor, activityFeedActor) &&
+			(activityFeedRig == "" || activityEventRig(e) == activityFeedRig)
+	}, page)
+	if err != nil {
+		return fmt.Errorf("reading event log: %w", err)
+	}
+
+	entries := make([]activityEntry, 0, len(evts))
+	for _, e := range evts {
+		entries = append(entries, activityEntry{
+			Time:  e.Time(),
+			Actor: e.Actor,
+			Type:  e.Type,
+			Rig:   activityEventRig(e),
+			Text:  events.Describe(e),
+		})
+	}
+
+	if activityFeedJSON {
+		return outputJSON(entries)
+	}
+	if len(entries) == 0 {
+		if activityFeedSkip > 0 {
+			printPageHint(&pageInfo, 0)
+		} else {
+			fmt.Printf("%s No activity\n", style.Dim.Render("○"))
+		}
+		return nil
+	}
+	now := time.Now()
+	stamps := make([]string, len(entries))
+	stampWidth, actorWidth := 0, 0
+	for i, e := range entries {
+		stamps[i] = activityStamp(e.Time, now)
+		stampWidth = max(stampWidth, len(stamps[i]))
+		actorWidth = max(actorWidth, len(e.Actor))
+	}
+	for i, e := range entries {
+		fmt.Printf("%s  %-*s  %s\n", style.Dim.Render(fmt.Sprintf("%-*s", stampWidth, stamps[i])), actorWidth, e.Actor, e.Text)
+	}
+	if activityFeedSkip > 0 {
+		printPageHint(&pageInfo, len(entries))
+	}
+	return nil
+}
+
+// activityActorMatches reports whether actor matches the --actor filter:
+// the full address, its last part ("furiosa" for
+// gastown/polecats/furiosa), or a prefix ending in "/".
+func activityActorMatches(actor, filter string) bool {
+	switch {
+	case filter == "":
+		return true
+	case strings.HasSuffix(filter, "/"):
+		return strings.HasPrefix(actor, filter)
+	default:
+		return actor == filter || strings.HasSuffix(actor, "/"+filter)
+	}
+}
+
+// activityEventRig returns the rig an event is about: its payload's rig,
+// or else the first part of the actor's address for rig-level agents.
+func activityEventRig(e events.Event) string {
+	if rig := e.PayloadString("rig"); rig != "" {
+		return rig
+	}
+	rig, _, ok := strings.Cut(e.Actor, "/")
+	if !ok || rig == "mayor" || rig == "deacon" {
+		return ""
+	}
+	return rig
+}
+
+// activityStamp formats an event time: the clock time for today's events,
+// with the date for older ones.
+func activityStamp(t, now time.Time) string {
+	t = t.Local()
+	if y, m, d := t.Date(); y == now.Year() && m == now.Month() && d == now.Day() {
+		return t.Format("15:04")
+	}
+	return t.Format("Jan 02 15:04")
+}
+
 // Note: detectActor is defined in sling.go and reused here
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+func TestActivityActorMatches(t *testing.T) {
+	tests := []struct {
+		actor, filter string
+		want          bool
+	}{
+		{"gastown/polecats/furiosa", "", true},
+		{"gastown/polecats/furiosa", "furiosa", true},
+		{"gastown/polecats/furiosa", "gastown/polecats/furiosa", true},
+		{"gastown/polecats/furiosa", "gastown/", true},
+		{"gastown/polecats/furiosa", "beads/", false},
+		{"gastown/polecats/furiosa2", "furiosa", false},
+		{"gastown/refinery", "refinery", true},
+		{"mayor", "refinery", false},
+	}
+	for _, tt := range tests {
+		if got := activityActorMatches(tt.actor, tt.filter); got != tt.want {
+			t.Errorf("activityActorMatches(%q, %q) = %v, want %v", tt.actor, tt.filter, got, tt.want)
+		}
+	}
+}
+
+func TestActivityEventRig(t *testing.T) {
+	tests := []struct {
+		name string
+		e    events.Event
+		want string
+	}{
+		{"payload", events.Event{Actor: "mayor", Payload: map[string]interface{}{"rig": "beads"}}, "beads"},
+		{"actor", events.Event{Actor: "gastown/refinery"}, "gastown"},
+		{"town agent", events.Event{Actor: "deacon/boot"}, ""},
+		{"bare actor", events.Event{Actor: "mayor"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := activityEventRig(tt.e); got != tt.want {
+				t.Errorf("activityEventRig() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestActivityStamp(t *testing.T) {
+	now := time.Date(2026, 10, 15, 13, 0, 0, 0, time.Local)
+	if got := activityStamp(now.Add(-time.Hour), now); got != "12:00" {
+		t.Errorf("today = %q", got)
+	}
+	if got := activityStamp(now.Add(-24*time.Hour), now); got != "Oct 14 13:00" {
+		t.Errorf("yesterday = %q", got)
+	}
+}
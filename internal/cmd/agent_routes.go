@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Agent routing command flags
+var (
+	agentRoutesRig  string
+	agentRoutesJSON bool
+
+	agentRateLimitedClear bool
+)
+
+// routedRoles are the roles shown by 'gt agents routes'.
+var routedRoles = []string{"mayor", "deacon", "witness", "refinery", "polecat", "crew", "reviewer"}
+
+var agentsRoutesCmd = &cobra.Command{
+	Use:   "routes",
+	Short: "Show which agent each role's next session would use",
+	Long: `Show model routing: each role's agent, its fallbacks, and which agent
+the next session would start on.
+
+Routing is configured in settings/config.json (town) or
+<rig>/settings/config.json (rig):
+
+  "role_agents":    {"reviewer": "claude-haiku", "polecat": "claude-opus"},
+  "role_fallbacks": {"polecat": ["claude-sonnet", "codex"]},
+  "agent_limits":   {"claude-opus": {"max_starts_per_hour": 20, "cooldown": "15m"}}
+
+A session start skips agents that are cooling down after a provider rate
+limit (429) or have used their hourly start budget. Sessions that end on a
+rate limit put their agent in cooldown automatically ('gt costs record');
+'gt agents rate-limited' does it by hand.
+
+Which model handled which issue is recorded in the cost log; see
+'gt costs --by-model'.`,
+	RunE: runAgentsRoutes,
+}
+
+var agentsRateLimitedCmd = &cobra.Command{
+	Use:   "rate-limited <agent>",
+	Short: "Mark an agent as rate limited so sessions route to fallbacks",
+	Long: `Put an agent in its rate-limit cooldown (agent_limits.<agent>.cooldown,
+default 10m). Until it ends, role sessions start on their fallbacks.
+
+Examples:
+  gt agents rate-limited claude-opus
+  gt agents rate-limited claude-opus --clear`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAgentsRateLimited,
+}
+
+func init() {
+	agentsRoutesCmd.Flags().StringVar(&agentRoutesRig, "rig", "", "Show routes for a rig's roles")
+	agentsRoutesCmd.Flags().BoolVar(&agentRoutesJSON, "json", false, "Output as JSON")
+	agentsRateLimitedCmd.Flags().BoolVar(&agentRateLimitedClear, "clear", false, "End the cooldown early")
+
+	agentsCmd.AddCommand(agentsRoutesCmd)
+	agentsCmd.AddCommand(agentsRateLimitedCmd)
+}
+
+func runAgentsRoutes(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	var rigPath string
+	if agentRoutesRig != "" {
+		rigPath = filepath.Join(townRoot, agentRoutesRig)
+	}
+
+	now := time.Now()
+	var routes []*config.AgentRoute
+	for _, role := range routedRoles {
+		route := config.RouteRoleAgent(role, townRoot, rigPath, now)
+		if route == nil {
+			name, _ := config.ResolveRoleAgentName(role, townRoot, rigPath)
+			route = &config.AgentRoute{Role: role, Agent: name, Chain: []string{name}}
+		}
+		routes = append(routes, route)
+	}
+
+	if agentRoutesJSON {
+		return outputJSON(routes)
+	}
+
+	table := style.NewTable(
+		style.Column{Name: "ROLE", Width: 10},
+		style.Column{Name: "NEXT", Width: 18},
+		style.Column{Name: "CHAIN", Width: 36},
+		style.Column{Name: "NOTES", Width: 40},
+	)
+	for _, r := range routes {
+		next := r.Agent
+		if r.Fallback {
+			next = style.Warning.Render(next)
+		}
+		var notes []string
+		for _, s := range r.Skipped {
+			notes = append(notes, s.Agent+": "+s.Reason)
+		}
+		table.AddRow(r.Role, next, strings.Join(r.Chain, " → "), style.Dim.Render(strings.Join(notes, "; ")))
+	}
+	fmt.Print(table.Render())
+	return nil
+}
+
+func runAgentsRateLimited(cmd *cobra.Command, args []string) error {
+	agent := args[0]
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	if agentRateLimitedClear {
+		if err := config.ClearAgentRateLimit(townRoot, agent); err != nil {
+			return err
+		}
+		fmt.Printf("%s Cleared rate limit on %s\n", style.Success.Render("✓"), agent)
+		return nil
+	}
+	until, err := config.MarkAgentRateLimited(townRoot, agent, time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s %s rate limited until %s; sessions route to fallbacks\n",
+		style.Warning.Render("⚠"), agent, until.Format("15:04"))
+	return nil
+}
@@ -0,0 +1,289 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/ctxbundle"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/session"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/templates"
+	"github.com/steveyegge/gastown/internal/tmux"
+	"github.com/steveyegge/gastown/internal/ui"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Agent spawn command flags
+var (
+	agentSpawnRig     string
+	agentSpawnName    string
+	agentSpawnIssue   string
+	agentSpawnAgent   string
+	agentSpawnPrompt  string
+	agentSpawnPrint   bool
+	agentSpawnDryRun  bool
+	agentSpawnWait    bool
+	agentSpawnTimeout time.Duration
+)
+
+var agentsSpawnCmd = &cobra.Command{
+	Use:   "spawn <role>",
+	Short: "Start an agent runtime with its rendered role prompt",
+	Long: `Render a role's prompt template and start the agent runtime in this
+terminal with it as the system prompt.
+
+Templates resolve from the town settings, most specific first:
+
+  settings/roles/<rig>/<role>.md.tmpl   Per-rig override
+  settings/roles/<role>.md.tmpl         Town-wide override
+  (built in)                            mayor, deacon, witness, refinery,
+                                        polecat, crew, reviewer
+
+Keeping overrides in the town means every machine renders the same prompt.
+With --issue, the issue and its context bundle (see 'gt context') are
+rendered into the prompt.
+
+The prompt is passed with the runtime's system_prompt_flag
+(--append-system-prompt for claude).
+
+With --wait the runtime starts in a detached tmux session (the role's
+usual session name) instead of this terminal, and the command returns
+once the agent is up: its ready prompt shows, or, for runtimes without a
+prompt to detect, its process is running. Exit codes: 0 ready, 2 timed
+out, 3 the runtime exited first.
+
+Examples:
+  gt agent spawn reviewer --rig gastown
+  gt agent spawn polecat --rig gastown --name toast --issue gt-abc
+  gt agent spawn witness --print        # Show the rendered prompt
+  gt agent spawn mayor --dry-run        # Show the command
+  gt agent spawn reviewer --rig gastown --wait --timeout 2m`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAgentsSpawn,
+}
+
+func init() {
+	agentsSpawnCmd.Flags().StringVar(&agentSpawnRig, "rig", "", "Rig to render for (default: inferred from cwd)")
+	agentsSpawnCmd.Flags().StringVar(&agentSpawnName, "name", "", "Agent name (polecat or crew member)")
+	agentsSpawnCmd.Flags().StringVar(&agentSpawnIssue, "issue", "", "Render this issue's context into the prompt")
+	agentsSpawnCmd.Flags().StringVar(&agentSpawnAgent, "agent", "", "Agent alias to run (overrides the role's configured agent)")
+	agentsSpawnCmd.Flags().StringVar(&agentSpawnPrompt, "prompt", "", "Initial message to send after startup")
+	agentsSpawnCmd.Flags().BoolVar(&agentSpawnPrint, "print", false, "Print the rendered prompt instead of starting the runtime")
+	agentsSpawnCmd.Flags().BoolVar(&agentSpawnDryRun, "dry-run", false, "Print the runtime command instead of running it")
+	agentsSpawnCmd.Flags().BoolVar(&agentSpawnWait, "wait", false, "Start in a detached tmux session and wait until the agent is up")
+	agentsSpawnCmd.Flags().DurationVar(&agentSpawnTimeout, "timeout", 5*time.Minute, "With --wait, give up after this long (0 = never)")
+
+	agentsCmd.AddCommand(agentsSpawnCmd)
+}
+
+func runAgentsSpawn(cmd *cobra.Command, args []string) error {
+	role := args[0]
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	rigName := agentSpawnRig
+	if rigName == "" && role != "mayor" && role != "deacon" {
+		rigName, _ = inferRigFromCwd(townRoot)
+	}
+	var rigPath string
+	if rigName != "" {
+		_, r, err := getRig(rigName)
+		if err != nil {
+			return err
+		}
+		rigPath = r.Path
+	}
+
+	tmpl, err := templates.New()
+	if err != nil {
+		return err
+	}
+	if err := tmpl.LoadOverrides(townRoot, rigName); err != nil {
+		return err
+	}
+	if !tmpl.HasRole(role) {
+		return fmt.Errorf("no template for role %q (built in: %s)", role, strings.Join(tmpl.RoleNames(), ", "))
+	}
+
+	data, err := spawnRoleData(role, townRoot, rigName, rigPath, agentSpawnName, agentSpawnIssue)
+	if err != nil {
+		return err
+	}
+	systemPrompt, err := tmpl.RenderRole(role, data)
+	if err != nil {
+		return err
+	}
+	if agentSpawnPrint {
+		fmt.Print(systemPrompt)
+		return nil
+	}
+
+	var rc *config.RuntimeConfig
+	if agentSpawnAgent != "" {
+		rc, _, err = config.ResolveAgentConfigWithOverride(townRoot, rigPath, agentSpawnAgent)
+		if err != nil {
+			return err
+		}
+	} else {
+		rc = config.ResolveRoleAgentConfig(role, townRoot, rigPath)
+	}
+	argv, err := rc.BuildArgsWithSystemPrompt(systemPrompt, agentSpawnPrompt)
+	if err != nil {
+		return err
+	}
+
+	env := config.AgentEnv(config.AgentEnvConfig{
+		Role:      role,
+		Rig:       rigName,
+		AgentName: agentSpawnName,
+		TownRoot:  townRoot,
+	})
+
+	if agentSpawnDryRun {
+		fmt.Printf("%s %s template: %s\n", style.Bold.Render("→"), role, tmpl.RoleSource(role))
+		quoted := make([]string, len(argv))
+		for i, a := range argv {
+			if a == systemPrompt {
+				// Elide the prompt itself; --print shows it
+				quoted[i] = style.Dim.Render(fmt.Sprintf("<%d-byte prompt>", len(a)))
+				continue
+			}
+			quoted[i] = config.ShellQuote(a)
+		}
+		fmt.Printf("%s%s\n", config.ExportPrefix(env), strings.Join(quoted, " "))
+		return nil
+	}
+
+	binPath, err := exec.LookPath(argv[0])
+	if err != nil {
+		return fmt.Errorf("%s not found: %w", argv[0], err)
+	}
+	if agentSpawnWait {
+		id := session.AgentIdentity{Role: session.Role(role), Rig: rigName, Name: agentSpawnName}
+		return spawnDetachedAndWait(townRoot, id.SessionName(), argv, env, rc)
+	}
+	ui.StopASCIIFilter() // Flush filtered output before the exec replaces us
+	return syscall.Exec(binPath, argv, config.EnvForExecCommand(env))
+}
+
+// spawnDetachedAndWait starts argv in a new detached tmux session and
+// waits until the runtime is ready. The command goes through a launcher
+// script because rendered prompts can exceed tmux's command length limit.
+func spawnDetachedAndWait(townRoot, sess string, argv []string, env map[string]string, rc *config.RuntimeConfig) error {
+	if sess == "" {
+		return fmt.Errorf("--wait needs a role with a tmux session (mayor, deacon, witness, refinery, crew, polecat)")
+	}
+	t := tmux.NewTmux()
+	if running, _ := t.HasSession(sess); running {
+		return fmt.Errorf("session %s already exists", sess)
+	}
+
+	quoted := make([]string, len(argv))
+	for i, a := range argv {
+		quoted[i] = config.ShellQuote(a)
+	}
+	launcher := filepath.Join(townRoot, constants.DirRuntime, "spawn", sess+".sh")
+	if err := os.MkdirAll(filepath.Dir(launcher), 0755); err != nil {
+		return err
+	}
+	script := "#!/bin/sh\n" + config.ExportPrefix(env) + "exec " + strings.Join(quoted, " ") + "\n"
+	if err := os.WriteFile(launcher, []byte(script), 0600); err != nil {
+		return fmt.Errorf("writing launcher: %w", err)
+	}
+
+	cwd, _ := os.Getwd()
+	if err := t.NewSessionWithCommand(sess, cwd, "sh "+config.ShellQuote(launcher)); err != nil {
+		return fmt.Errorf("starting session %s: %w", sess, err)
+	}
+	fmt.Printf("%s Started %s, waiting for the agent...\n", style.Dim.Render("◌"), sess)
+
+	start := time.Now()
+	err := pollUntil(time.Second, agentSpawnTimeout, func() (bool, error) {
+		if running, _ := t.HasSession(sess); !running {
+			return false, &waitFailedError{reason: "runtime exited before it was ready"}
+		}
+		return agentRuntimeReady(t, sess, rc), nil
+	})
+	if err != nil {
+		return waitExit(err, sess, agentSpawnTimeout)
+	}
+	fmt.Printf("%s %s is up (after %s)\n", style.Bold.Render("✓"), sess, time.Since(start).Round(time.Second))
+	fmt.Printf("  Attach: %s\n", style.Dim.Render("tmux attach -t "+sess))
+	return nil
+}
+
+// agentRuntimeReady reports whether the runtime in sess is up: its ready
+// prompt is showing or, without one to detect, its process is running.
+func agentRuntimeReady(t *tmux.Tmux, sess string, rc *config.RuntimeConfig) bool {
+	if rc.Tmux == nil {
+		return t.IsAgentRunning(sess)
+	}
+	prefix := strings.TrimSpace(rc.Tmux.ReadyPromptPrefix)
+	if prefix == "" {
+		return t.IsRuntimeRunning(sess, rc.Tmux.ProcessNames)
+	}
+	lines, err := t.CapturePaneLines(sess, 10)
+	if err != nil {
+		return false
+	}
+	for _, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// spawnRoleData builds the template data for role in rigName. name is the
+// polecat's name; with issueID, the issue's context bundle is rendered in.
+func spawnRoleData(role, townRoot, rigName, rigPath, name, issueID string) (templates.RoleData, error) {
+	townName, _ := workspace.GetTownName(townRoot)
+	cwd, _ := os.Getwd()
+	data := templates.RoleData{
+		Role:          role,
+		RigName:       rigName,
+		TownRoot:      townRoot,
+		TownName:      townName,
+		WorkDir:       cwd,
+		DefaultBranch: "main",
+		MayorSession:  session.MayorSessionName(),
+		DeaconSession: session.DeaconSessionName(),
+	}
+	if role == "polecat" {
+		data.Polecat = name
+	}
+	if rigPath != "" {
+		if rigCfg, err := rig.LoadRigConfig(rigPath); err == nil && rigCfg.DefaultBranch != "" {
+			data.DefaultBranch = rigCfg.DefaultBranch
+		}
+	}
+
+	if issueID != "" {
+		bd := beads.New(resolveBeadDir(issueID))
+		issue, err := bd.Show(issueID)
+		if err != nil {
+			return data, fmt.Errorf("fetching %s: %w", issueID, err)
+		}
+		bundle := ctxbundle.New(issue, time.Now())
+		if comments, err := bd.Comments(issueID); err == nil {
+			bundle.Comments = comments
+		}
+		bundle.Decisions = relevantDecisions(townRoot, rigName, bundle.Issue)
+		data.Issue = issue.ID
+		data.IssueTitle = issue.Title
+		data.IssueContext = bundle.Markdown()
+	}
+	return data, nil
+}
@@ -130,9 +130,7 @@ func queryAgentState(agentBead, beadsDir string) error {
 	}
 
 	if agentStateJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(result)
+		return outputJSON(result)
 	}
 
 	// Human-readable output
@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/scorecard"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// Agent stats command flags
+var (
+	agentStatsSince    string
+	agentStatsRig      string
+	agentStatsBy       string
+	agentStatsJSON     bool
+	agentStatsMarkdown bool
+)
+
+var agentsStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show per-agent performance scorecards",
+	Long: `Show a scorecard for each agent over a window of history:
+
+  Completed      Issues finished ('gt done')
+  MRs            Merge requests submitted
+  Accepted       Merged / (merged + rejected); superseded MRs don't count
+  Review rounds  Average review verdicts per reviewed MR
+  Reverted       Merged MRs later reverted ('gt mq revert')
+  Cost/issue     Session costs / issues completed
+
+Completions, reviews, and reverts come from the event log, MRs from each
+rig's merge-request beads, and costs from the cost log and daily digests
+(see 'gt costs'). --by role or --by model rolls agents up, to compare how
+well each role or model handles the work routed to it; an agent counts
+toward the model it spent the most on.
+
+Examples:
+  gt agents stats                     # Last 30 days, per agent
+  gt agents stats --since 7d --rig gastown
+  gt agents stats --by model
+  gt agents stats --markdown          # Table for a report
+  gt agents stats --json`,
+	Args: cobra.NoArgs,
+	RunE: runAgentsStats,
+}
+
+func init() {
+	agentsStatsCmd.Flags().StringVar(&agentStatsSince, "since", "30d", "History window (e.g., 7d, 30d, 12h)")
+	agentsStatsCmd.Flags().StringVar(&agentStatsRig, "rig", "", "Only agents and MRs in this rig")
+	agentsStatsCmd.Flags().StringVar(&agentStatsBy, "by", scorecard.ByAgent, "Group by agent, role, or model")
+	agentsStatsCmd.Flags().BoolVar(&agentStatsJSON, "json", false, "Output as JSON")
+	agentsStatsCmd.Flags().BoolVar(&agentStatsMarkdown, "markdown", false, "Output as a Markdown table")
+
+	agentsCmd.AddCommand(agentsStatsCmd)
+}
+
+func runAgentsStats(cmd *cobra.Command, args []string) error {
+	switch agentStatsBy {
+	case scorecard.ByAgent, scorecard.ByRole, scorecard.ByModel:
+	default:
+		return fmt.Errorf("invalid --by %q: want agent, role, or model", agentStatsBy)
+	}
+	window, err := parseDuration(agentStatsSince)
+	if err != nil {
+		return fmt.Errorf("invalid --since: %w", err)
+	}
+	since := time.Now().Add(-window)
+
+	rigs, townRoot, err := getAllRigs()
+	if err != nil {
+		return err
+	}
+	inRig := func(agent string) bool {
+		return agentStatsRig == "" || strings.HasPrefix(agent, agentStatsRig+"/")
+	}
+
+	evts, err := events.Read(townRoot, func(e events.Event) bool {
+		return !e.Time().Before(since)
+	})
+	if err != nil {
+		return fmt.Errorf("reading event log: %w", err)
+	}
+
+	var completions []scorecard.Completion
+	submitter := make(map[string]string) // MR ID -> agent, from done events
+	reviews := make(map[string]int)
+	reverted := make(map[string]bool)
+	for _, e := range evts {
+		switch e.Type {
+		case events.TypeDone:
+			if inRig(e.Actor) {
+				completions = append(completions, scorecard.Completion{Agent: e.Actor, Issue: e.PayloadString("bead")})
+			}
+			if mr := e.PayloadString("mr"); mr != "" {
+				submitter[mr] = e.Actor
+			}
+		case events.TypeMRReviewed:
+			reviews[e.PayloadString("mr")]++
+		case events.TypeMRReverted:
+			reverted[e.PayloadString("mr")] = true
+		}
+	}
+
+	var mrs []scorecard.MergeRequest
+	for _, r := range rigs {
+		if agentStatsRig != "" && r.Name != agentStatsRig {
+			continue
+		}
+		mrs = append(mrs, agentStatsMergeRequests(r, since, submitter, reviews, reverted)...)
+	}
+
+	var costs []scorecard.Cost
+	entries, _ := queryDigestBeads(int(window/(24*time.Hour)) + 1)
+	today, _ := querySessionCostEntries(time.Now())
+	for _, e := range append(entries, today...) {
+		if !e.EndedAt.IsZero() && e.EndedAt.Before(since) {
+			continue
+		}
+		agent := costAgentAddress(e)
+		if inRig(agent) {
+			costs = append(costs, scorecard.Cost{Agent: agent, Role: e.Role, Model: modelKey(e), USD: e.CostUSD})
+		}
+	}
+
+	cards := scorecard.Rollup(scorecard.Build(completions, mrs, costs), agentStatsBy)
+	switch {
+	case agentStatsJSON:
+		return outputJSON(cards)
+	case agentStatsMarkdown:
+		fmt.Print(scorecard.Markdown(cards, agentStatsBy))
+		return nil
+	}
+
+	if len(cards) == 0 {
+		fmt.Printf("%s No agent activity since %s\n", style.Dim.Render("○"), since.Local().Format(time.DateTime))
+		return nil
+	}
+	table := style.NewTable(
+		style.Column{Name: strings.ToUpper(agentStatsBy), Width: 28},
+		style.Column{Name: "DONE", Width: 5, Align: style.AlignRight},
+		style.Column{Name: "MRS", Width: 5, Align: style.AlignRight},
+		style.Column{Name: "ACCEPTED", Width: 9, Align: style.AlignRight},
+		style.Column{Name: "REVIEWS", Width: 8, Align: style.AlignRight},
+		style.Column{Name: "REVERTED", Width: 9, Align: style.AlignRight},
+		style.Column{Name: "COST/ISSUE", Width: 11, Align: style.AlignRight},
+	)
+	for _, c := range cards {
+		table.AddRow(c.Name, fmt.Sprint(c.Completed), fmt.Sprint(c.MRs),
+			scorecard.Percent(c.AcceptanceRate, c.Merged+c.Rejected),
+			scorecard.Rounds(c.ReviewIterations, c.Reviewed),
+			scorecard.Percent(c.RevertRate, c.Merged),
+			scorecard.Dollars(c.CostPerIssue, c.Completed))
+	}
+	fmt.Print(table.Render())
+	return nil
+}
+
+// agentStatsMergeRequests returns the MRs filed in a rig since the given
+// time, attributed to the agent that submitted them: the done event that
+// filed the MR, or else the polecat named in the MR.
+func agentStatsMergeRequests(r *rig.Rig, since time.Time, submitter map[string]string, reviews map[string]int, reverted map[string]bool) []scorecard.MergeRequest {
+	bd := beads.New(r.BeadsPath())
+	var out []scorecard.MergeRequest
+	for _, status := range []string{"open", "closed"} {
+		list, err := bd.List(beads.ListOptions{Status: status, Type: "merge-request", Priority: -1})
+		if err != nil {
+			continue
+		}
+		for _, mr := range list {
+			fields := beads.ParseMRFields(mr)
+			if fields == nil || parseRetroTime(mr.CreatedAt).Before(since) {
+				continue
+			}
+			agent := submitter[mr.ID]
+			if agent == "" && fields.Worker != "" {
+				agent = r.Name + "/polecats/" + fields.Worker
+			}
+			closeReason := mr.CloseReason
+			if closeReason == "" {
+				closeReason = fields.CloseReason
+			}
+			merged := mr.Status == "closed" && fields.MergeCommit != ""
+			out = append(out, scorecard.MergeRequest{
+				ID:       mr.ID,
+				Agent:    agent,
+				Merged:   merged,
+				Rejected: mr.Status == "closed" && !merged && closeReason != "superseded",
+				Reviews:  reviews[mr.ID],
+				Reverted: reverted[mr.ID],
+			})
+		}
+	}
+	return out
+}
+
+// costAgentAddress returns the address of the agent a cost entry's session
+// ran: gastown/polecats/toast, gastown/crew/joe, gastown/witness, mayor.
+func costAgentAddress(e CostEntry) string {
+	switch {
+	case e.Rig == "":
+		return e.Role
+	case e.Role == "polecat" && e.Worker != "":
+		return e.Rig + "/polecats/" + e.Worker
+	case e.Role == "crew" && e.Worker != "":
+		return e.Rig + "/crew/" + e.Worker
+	default:
+		return e.Rig + "/" + e.Role
+	}
+}
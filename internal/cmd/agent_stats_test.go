@@ -0,0 +1,20 @@
+package cmd
+
+import "testing"
+
+func TestCostAgentAddress(t *testing.T) {
+	tests := []struct {
+		entry CostEntry
+		want  string
+	}{
+		{CostEntry{Role: "polecat", Rig: "gastown", Worker: "toast"}, "gastown/polecats/toast"},
+		{CostEntry{Role: "crew", Rig: "gastown", Worker: "joe"}, "gastown/crew/joe"},
+		{CostEntry{Role: "witness", Rig: "gastown"}, "gastown/witness"},
+		{CostEntry{Role: "mayor", Worker: "mayor"}, "mayor"},
+	}
+	for _, tt := range tests {
+		if got := costAgentAddress(tt.entry); got != tt.want {
+			t.Errorf("costAgentAddress(%+v) = %q, want %q", tt.entry, got, tt.want)
+		}
+	}
+}
@@ -1,17 +1,20 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/i18n"
 	"github.com/steveyegge/gastown/internal/lock"
+	"github.com/steveyegge/gastown/internal/ratelimit"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -29,6 +32,16 @@ const (
 	AgentPolecat
 )
 
+// agentTypeRoles maps agent types to the role names used in porcelain output.
+var agentTypeRoles = map[AgentType]string{
+	AgentMayor:    "mayor",
+	AgentDeacon:   "deacon",
+	AgentWitness:  "witness",
+	AgentRefinery: "refinery",
+	AgentCrew:     "crew",
+	AgentPolecat:  "polecat",
+}
+
 // AgentSession represents a categorized tmux session.
 type AgentSession struct {
 	Name      string
@@ -60,7 +73,7 @@ var AgentTypeIcons = map[AgentType]string{
 
 var agentsCmd = &cobra.Command{
 	Use:     "agents",
-	Aliases: []string{"ag"},
+	Aliases: []string{"ag", "agent"},
 	GroupID: GroupAgents,
 	Short:   "Switch between Gas Town agent sessions",
 	Long: `Display a popup menu of core Gas Town agent sessions.
@@ -75,8 +88,11 @@ The menu appears as a tmux popup for quick session switching.`,
 var agentsListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List agent sessions (no popup)",
-	Long:  `List all agent sessions to stdout without the popup menu.`,
-	RunE:  runAgentsList,
+	Long: `List all agent sessions to stdout without the popup menu.
+
+With --porcelain, prints one stable tab-separated record per session:
+  agent	<session>	<role>	<rig>	<name>`,
+	RunE: runAgentsList,
 }
 
 var agentsCheckCmd = &cobra.Command{
@@ -111,13 +127,15 @@ For collisions with live processes, you must manually:
 }
 
 var (
-	agentsAllFlag   bool
-	agentsCheckJSON bool
+	agentsAllFlag       bool
+	agentsCheckJSON     bool
+	agentsListPorcelain string
 )
 
 func init() {
 	agentsCmd.PersistentFlags().BoolVarP(&agentsAllFlag, "all", "a", false, "Include polecats in the menu")
 	agentsCheckCmd.Flags().BoolVar(&agentsCheckJSON, "json", false, "Output as JSON")
+	addPorcelainFlag(agentsListCmd, &agentsListPorcelain)
 
 	agentsCmd.AddCommand(agentsListCmd)
 	agentsCmd.AddCommand(agentsCheckCmd)
@@ -291,7 +309,7 @@ func runAgents(cmd *cobra.Command, args []string) error {
 	}
 
 	if len(agents) == 0 {
-		fmt.Println("No agent sessions running.")
+		fmt.Println(i18n.T("No agent sessions running."))
 		fmt.Println("\nStart agents with:")
 		fmt.Println("  gt mayor start")
 		fmt.Println("  gt deacon start")
@@ -344,16 +362,43 @@ func runAgents(cmd *cobra.Command, args []string) error {
 }
 
 func runAgentsList(cmd *cobra.Command, args []string) error {
+	if agentsListPorcelain != "" {
+		if err := checkPorcelainVersion(agentsListPorcelain); err != nil {
+			return err
+		}
+	}
 	agents, err := getAgentSessions(agentsAllFlag)
 	if err != nil {
 		return fmt.Errorf("listing sessions: %w", err)
 	}
 
+	if agentsListPorcelain != "" {
+		p := porcelainWriter{w: os.Stdout}
+		for _, agent := range agents {
+			p.record("agent", agent.Name, agentTypeRoles[agent.Type], agent.Rig, agent.AgentName)
+		}
+		return nil
+	}
+
 	if len(agents) == 0 {
-		fmt.Println("No agent sessions running.")
+		if !quietFlag {
+			fmt.Println(i18n.T("No agent sessions running."))
+		}
 		return nil
 	}
 
+	// Shared rate limiter usage, when the town has limits configured
+	var usage map[string]ratelimit.AgentUsage
+	var limits []rateLimitProviderStatus
+	if townRoot, err := workspace.FindFromCwd(); err == nil && townRoot != "" {
+		if settings, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(townRoot)); err == nil && len(settings.RateLimits) > 0 {
+			if state, err := ratelimit.Load(townRoot); err == nil {
+				usage = state.AgentTotals()
+				limits = rateLimitStatuses(settings, state, time.Now())
+			}
+		}
+	}
+
 	var currentRig string
 	for _, agent := range agents {
 		// Print rig header
@@ -366,25 +411,50 @@ func runAgentsList(cmd *cobra.Command, args []string) error {
 		}
 
 		icon := AgentTypeIcons[agent.Type]
+		var label string
 		switch agent.Type {
 		case AgentMayor:
-			fmt.Printf("  %s Mayor\n", icon)
+			label = "Mayor"
 		case AgentDeacon:
-			fmt.Printf("  %s Deacon\n", icon)
+			label = "Deacon"
 		case AgentWitness:
-			fmt.Printf("  %s witness\n", icon)
+			label = "witness"
 		case AgentRefinery:
-			fmt.Printf("  %s refinery\n", icon)
+			label = "refinery"
 		case AgentCrew:
-			fmt.Printf("  %s crew/%s\n", icon, agent.AgentName)
+			label = "crew/" + agent.AgentName
 		case AgentPolecat:
-			fmt.Printf("  %s %s\n", icon, agent.AgentName)
+			label = agent.AgentName
+		default:
+			continue
+		}
+		fmt.Printf("  %s %s%s\n", icon, label, formatAgentUsage(usage, agent.Name))
+	}
+
+	if len(limits) > 0 {
+		fmt.Println()
+		for _, st := range limits {
+			fmt.Printf("%s %s: %.0f/%d per min available, %s\n", style.Dim.Render("Rate limit"), st.Provider,
+				st.Available, st.RequestsPerMinute, formatThrottled(st.Throttled, st.WaitedMs))
 		}
 	}
 
 	return nil
 }
 
+// formatAgentUsage renders an agent's rate limiter usage for 'gt agents list'.
+func formatAgentUsage(usage map[string]ratelimit.AgentUsage, session string) string {
+	u, ok := usage[session]
+	if !ok {
+		return ""
+	}
+	out := fmt.Sprintf("  %d req", u.Granted)
+	if u.Throttled > 0 {
+		return out + " " + style.Warning.Render(fmt.Sprintf("(%d throttled)", u.Throttled))
+	}
+	return style.Dim.Render(out)
+}
+
 // CollisionReport holds the results of a collision check.
 type CollisionReport struct {
 	TotalSessions int                    `json:"total_sessions"`
@@ -416,9 +486,7 @@ func runAgentsCheck(cmd *cobra.Command, args []string) error {
 	}
 
 	if agentsCheckJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(report)
+		return outputJSON(report)
 	}
 
 	// Text output
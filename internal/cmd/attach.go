@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/attach"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/diskusage"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Attach command flags
+var (
+	attachBy   string
+	attachJSON bool
+)
+
+var attachCmd = &cobra.Command{
+	Use:     "attach <issue> <file>...",
+	GroupID: GroupWork,
+	Short:   "Attach files (logs, screenshots, artifacts) to a bead",
+	Long: `Store files and link them to a bead, so crash logs and test artifacts
+stay with the issue they belong to.
+
+Files are stored once per content (by SHA-256) in the town's attachment
+store: <town>/attachments by default, or an S3-compatible or GCS bucket
+when the town settings configure one, either for attachments alone or as
+the town-wide "blob_store":
+
+  "attachments": {"backend": "s3", "s3": {"bucket": "gt-town", "region": "eu-west-1"}}
+
+The bead's description lists its attachments. Retrieve them with
+'gt show <issue> --attachments' (list) and 'gt show <issue> --attachment
+<name>' (contents); 'gt context' includes them.
+
+Examples:
+  gt attach gt-abc crash.log
+  gt attach gt-abc screenshot.png junit.xml
+  gt attach gt-abc /tmp/trace.out --json`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runAttach,
+}
+
+func init() {
+	attachCmd.Flags().StringVar(&attachBy, "by", "", "Who attached the files (default: detected)")
+	attachCmd.Flags().BoolVar(&attachJSON, "json", false, "Output the attachments as JSON")
+
+	rootCmd.AddCommand(attachCmd)
+}
+
+func runAttach(cmd *cobra.Command, args []string) error {
+	issueID, files := args[0], args[1:]
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	store, err := attach.OpenTown(townRoot)
+	if err != nil {
+		return err
+	}
+	bd := beads.New(resolveBeadDir(issueID))
+	issue, err := bd.Show(issueID)
+	if err != nil {
+		return fmt.Errorf("fetching %s: %w", issueID, err)
+	}
+
+	by := attachBy
+	if by == "" {
+		by = detectSender()
+	}
+	description := issue.Description
+	var added []*attach.Attachment
+	for _, path := range files {
+		a, err := store.Add(context.Background(), path, by)
+		if err != nil {
+			return fmt.Errorf("attaching %s: %w", path, err)
+		}
+		if description, err = attach.Link(description, *a); err != nil {
+			return err
+		}
+		added = append(added, a)
+	}
+	if description != issue.Description {
+		if err := bd.Update(issueID, beads.UpdateOptions{Description: &description}); err != nil {
+			return fmt.Errorf("updating %s: %w", issueID, err)
+		}
+	}
+
+	if attachJSON {
+		return outputJSON(added)
+	}
+	for _, a := range added {
+		fmt.Printf("%s Attached %s to %s %s\n", style.Success.Render("✓"), a.Name, issueID,
+			style.Dim.Render(fmt.Sprintf("(%s, %s)", diskusage.FormatSize(a.Size), a.ShortDigest())))
+	}
+	return nil
+}
+
+// printAttachments lists a bead's attachments for gt show.
+func printAttachments(store *attach.Store, list []attach.Attachment) {
+	if len(list) == 0 {
+		fmt.Printf("  %s\n", style.Dim.Render("(none)"))
+		return
+	}
+	for _, a := range list {
+		fmt.Printf("  %s %s", a.Name, style.Dim.Render(fmt.Sprintf("%s  %s  %s", diskusage.FormatSize(a.Size), a.ShortDigest(), a.Type)))
+		if a.By != "" {
+			fmt.Printf(" %s", style.Dim.Render("by "+a.By))
+		}
+		fmt.Println()
+		if p := store.LocalPath(a); p != "" {
+			fmt.Printf("    %s\n", p)
+		}
+	}
+}
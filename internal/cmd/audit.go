@@ -481,9 +481,7 @@ func formatFeedSummary(e events.Event) string {
 }
 
 func outputAuditJSON(entries []AuditEntry) error {
-	enc := json.NewEncoder(os.Stdout)
-	enc.SetIndent("", "  ")
-	return enc.Encode(entries)
+	return outputJSON(entries)
 }
 
 func outputAuditText(entries []AuditEntry) error {
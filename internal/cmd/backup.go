@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/backup"
+	"github.com/steveyegge/gastown/internal/blobstore"
+	"github.com/steveyegge/gastown/internal/diskusage"
+	"github.com/steveyegge/gastown/internal/retention"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Backup command flags
+var (
+	backupJSON   bool
+	backupFrom   string
+	backupTown   string
+	backupTo     string
+	backupForce  bool
+	backupDryRun bool
+)
+
+var backupCmd = &cobra.Command{
+	Use:     "backup",
+	GroupID: GroupWorkspace,
+	Short:   "Back up and restore town state to a blob store",
+	Long: `Snapshot the town's state so it survives the machine it runs on.
+
+A backup holds what git can't give back: town and rig configuration,
+beads databases (including the Dolt data directory), memos, and the event
+log. Rig clones are left out; re-add them from their remotes.
+
+Backups go to the town's backup store: <town>/backups by default, or a
+bucket configured in settings/config.json, either for backups alone or as
+the town-wide default for backups and attachments:
+
+  "blob_store": {"backend": "s3", "s3": {"bucket": "gt-towns", "region": "eu-west-1"}}
+  "backups": {"backend": "gcs", "gcs": {"bucket": "gt-backups"}}
+
+Set "backup_interval" (e.g. "6h") to have the daemon back up on a
+schedule. Old backups are pruned per "retention.backups" (default: 30 days,
+newest 14), by the daemon and by 'gt gc'.
+
+Examples:
+  gt backup create
+  gt backup list
+  gt backup restore latest --force
+  gt backup restore latest --from s3://gt-towns/backups --to ~/gt   # fresh machine
+  gt backup prune --dry-run`,
+	RunE: requireSubcommand,
+}
+
+var backupCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Back up the town now",
+	Args:  cobra.NoArgs,
+	RunE:  runBackupCreate,
+}
+
+var backupListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List backups, newest first",
+	Args:  cobra.NoArgs,
+	RunE:  runBackupList,
+}
+
+var backupRestoreCmd = &cobra.Command{
+	Use:   "restore <backup>",
+	Short: "Restore a backup into a town directory",
+	Long: `Restore a backup ("latest", a timestamp, or a full key) into a town.
+
+Inside a town, the backup comes from the town's backup store and is
+restored into the town. On a fresh machine, name the store with --from
+(s3://bucket/prefix, gs://bucket/prefix, or a directory) and the target
+with --to. Stop the daemon and agents first: restoring replaces beads
+databases in place. Existing files are only replaced with --force.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBackupRestore,
+}
+
+var backupPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove backups (and attachments) past their retention policy",
+	Args:  cobra.NoArgs,
+	RunE:  runBackupPrune,
+}
+
+func init() {
+	backupCreateCmd.Flags().BoolVar(&backupJSON, "json", false, "Output as JSON")
+
+	backupListCmd.Flags().StringVar(&backupFrom, "from", "", "Store URL to list instead of the town's backup store")
+	backupListCmd.Flags().StringVar(&backupTown, "town", "", "Town whose backups to list (default: this town; all towns with --from)")
+	backupListCmd.Flags().BoolVar(&backupJSON, "json", false, "Output as JSON")
+
+	backupRestoreCmd.Flags().StringVar(&backupFrom, "from", "", "Store URL to restore from instead of the town's backup store")
+	backupRestoreCmd.Flags().StringVar(&backupTown, "town", "", "Town whose backup to restore (default: this town)")
+	backupRestoreCmd.Flags().StringVar(&backupTo, "to", "", "Directory to restore into (default: this town)")
+	backupRestoreCmd.Flags().BoolVarP(&backupForce, "force", "f", false, "Replace existing files")
+	backupRestoreCmd.Flags().BoolVar(&backupJSON, "json", false, "Output as JSON")
+
+	backupPruneCmd.Flags().BoolVarP(&backupDryRun, "dry-run", "n", false, "Show what would be removed without removing it")
+	backupPruneCmd.Flags().BoolVar(&backupJSON, "json", false, "Output as JSON")
+
+	backupCmd.AddCommand(backupCreateCmd)
+	backupCmd.AddCommand(backupListCmd)
+	backupCmd.AddCommand(backupRestoreCmd)
+	backupCmd.AddCommand(backupPruneCmd)
+	rootCmd.AddCommand(backupCmd)
+}
+
+func runBackupCreate(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	info, err := backup.Run(context.Background(), townRoot)
+	if err != nil {
+		return err
+	}
+	if backupJSON {
+		return outputJSON(info)
+	}
+	fmt.Printf("%s Backed up town to %s %s\n", style.Success.Render("✓"), info.Key,
+		style.Dim.Render("("+diskusage.FormatSize(info.Size)+")"))
+	return nil
+}
+
+// backupSource resolves the store and town that list and restore read:
+// --from and --town when given, else the current town's backup store.
+func backupSource() (blobstore.Store, string, error) {
+	if backupFrom != "" {
+		store, err := blobstore.ParseURL(backupFrom)
+		return store, backupTown, err
+	}
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return nil, "", fmt.Errorf("not in a Gas Town workspace (use --from): %w", err)
+	}
+	store, err := blobstore.OpenTownClass(townRoot, blobstore.ClassBackups)
+	if err != nil {
+		return nil, "", fmt.Errorf("backup store: %w", err)
+	}
+	town := backupTown
+	if town == "" {
+		if town, err = workspace.GetTownName(townRoot); err != nil {
+			return nil, "", err
+		}
+	}
+	return store, town, nil
+}
+
+func runBackupList(cmd *cobra.Command, args []string) error {
+	store, town, err := backupSource()
+	if err != nil {
+		return err
+	}
+	backups, err := backup.List(context.Background(), store, town)
+	if err != nil {
+		return err
+	}
+	if backupJSON {
+		return outputJSON(backups)
+	}
+	if len(backups) == 0 {
+		fmt.Printf("%s No backups in %s\n", style.Dim.Render("ℹ"), store)
+		return nil
+	}
+	for _, b := range backups {
+		fmt.Printf("  %-20s %-16s %9s  %s\n", path.Dir(b.Key), backup.Stamp(b.Key),
+			diskusage.FormatSize(b.Size), style.Dim.Render(b.Modified.Local().Format("2006-01-02 15:04")))
+	}
+	return nil
+}
+
+func runBackupRestore(cmd *cobra.Command, args []string) error {
+	store, town, err := backupSource()
+	if err != nil {
+		return err
+	}
+	dest := backupTo
+	if dest == "" {
+		if dest, err = workspace.FindFromCwdOrError(); err != nil {
+			return fmt.Errorf("not in a Gas Town workspace (use --to): %w", err)
+		}
+	}
+
+	ctx := context.Background()
+	info, err := backup.Find(ctx, store, town, args[0])
+	if err != nil {
+		return err
+	}
+	r, err := store.Get(ctx, info.Key)
+	if err != nil {
+		return fmt.Errorf("fetching %s: %w", info.Key, err)
+	}
+	defer r.Close()
+	restored, err := backup.Restore(r, dest, backupForce)
+	if err != nil {
+		return err
+	}
+
+	if backupJSON {
+		return outputJSON(struct {
+			Backup string   `json:"backup"`
+			Dest   string   `json:"dest"`
+			Files  []string `json:"files"`
+		}{info.Key, dest, restored})
+	}
+	fmt.Printf("%s Restored %s into %s %s\n", style.Success.Render("✓"), info.Key, dest,
+		style.Dim.Render(fmt.Sprintf("(%d files)", len(restored))))
+	return nil
+}
+
+func runBackupPrune(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	actions, pruneErr := pruneBlobStores(townRoot, backupDryRun)
+	if backupJSON {
+		if err := outputJSON(actions); err != nil {
+			return err
+		}
+		return pruneErr
+	}
+	for _, a := range actions {
+		fmt.Printf("  %-10s %-11s %9s  %s\n", a.Op, a.Class, diskusage.FormatSize(a.Bytes), a.Path)
+	}
+	freed := retention.Freed(actions)
+	switch {
+	case len(actions) == 0:
+		fmt.Printf("%s Nothing to prune\n", style.Success.Render("✓"))
+	case backupDryRun:
+		fmt.Printf("%s Dry run: would remove %d object(s), %s\n", style.Dim.Render("ℹ"), len(actions), diskusage.FormatSize(freed))
+	default:
+		fmt.Printf("%s Removed %d object(s), %s\n", style.Success.Render("✓"), len(actions), diskusage.FormatSize(freed))
+	}
+	return pruneErr
+}
@@ -0,0 +1,20 @@
+package cmd
+
+import "github.com/steveyegge/gastown/internal/beads"
+
+// beadsHealth is the circuit breaker for the beads databases this command
+// run touches, so a rig whose bd is broken costs the run its failure
+// latency once instead of on every call.
+var beadsHealth = beads.NewBreaker(beads.DefaultBreakerThreshold)
+
+// healthyBeads returns a client for the database at beadsPath whose calls
+// go through beadsHealth, probing the database the first time the run
+// touches it. A database that fails the probe comes back as an error
+// wrapping beads.ErrUnhealthy right away, for the caller to skip.
+func healthyBeads(beadsPath string) (*beads.Beads, error) {
+	b := beads.New(beadsPath).WithBreaker(beadsHealth)
+	if err := beadsHealth.Probe(b, beads.PingTimeout); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/bisect"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// Bisect command flags
+var (
+	bisectCheck   string
+	bisectGood    string
+	bisectBad     string
+	bisectLast    int
+	bisectTimeout time.Duration
+	bisectNoFile  bool
+	bisectJSON    bool
+)
+
+var bisectCmd = &cobra.Command{
+	Use:     "bisect <rig>",
+	GroupID: GroupDiag,
+	Short:   "Find the merged MR that broke a check",
+	Long: `Bisect a rig's merge history to find the MR that broke a check.
+
+The check is a shell command that exits 0 when things are good. It runs in a
+scratch worktree of the rig's clone, checked out at each candidate. Bisection
+steps over landings (commits that merged MRs put on the branch) so the
+culprit is an MR, not an arbitrary commit. If no merged MR beads match the
+history, it falls back to individual commits.
+
+Once found, a bug bead labeled bisect is filed in the rig with the evidence
+(culprit commit and MR, last good commit, each step, and the failing output)
+and assigned to whoever worked the culprit's source issue.
+
+By default the search covers the last 50 commits on the rig's default
+branch; use --good to start from a known-good ref instead.
+
+Examples:
+  gt bisect gastown --check "go test ./internal/auth/..."
+  gt bisect gastown --check "make lint" --good v1.3.0
+  gt bisect gastown --check ./scripts/smoke.sh --last 20 --no-file`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBisect,
+}
+
+func init() {
+	bisectCmd.Flags().StringVar(&bisectCheck, "check", "", "Command that exits 0 when the check passes (required)")
+	bisectCmd.Flags().StringVar(&bisectGood, "good", "", "Known-good ref (default: --last commits before --bad)")
+	bisectCmd.Flags().StringVar(&bisectBad, "bad", "", "Known-bad ref (default: origin/<default branch>)")
+	bisectCmd.Flags().IntVar(&bisectLast, "last", 50, "How far back to search when --good isn't given")
+	bisectCmd.Flags().DurationVar(&bisectTimeout, "timeout", 10*time.Minute, "Timeout for each check run")
+	bisectCmd.Flags().BoolVar(&bisectNoFile, "no-file", false, "Report the culprit without filing a bead")
+	bisectCmd.Flags().BoolVar(&bisectJSON, "json", false, "Output as JSON")
+	_ = bisectCmd.MarkFlagRequired("check")
+
+	rootCmd.AddCommand(bisectCmd)
+}
+
+// BisectOutput is the JSON output of gt bisect.
+type BisectOutput struct {
+	Rig   string `json:"rig"`
+	Check string `json:"check"`
+	*bisect.Result
+	Bead string `json:"bead,omitempty"`
+}
+
+func runBisect(cmd *cobra.Command, args []string) error {
+	_, r, err := getRig(args[0])
+	if err != nil {
+		return err
+	}
+
+	g := git.NewGit(constants.RigMayorPath(r.Path))
+	if err := g.Fetch("origin"); err != nil {
+		return fmt.Errorf("fetching origin: %w", err)
+	}
+
+	bad := bisectBad
+	if bad == "" {
+		bad = "origin/" + r.DefaultBranch()
+	}
+	good := bisectGood
+	if good == "" {
+		good = fmt.Sprintf("%s~%d", bad, bisectLast)
+	}
+	badSHA, err := g.Rev(bad + "^{commit}")
+	if err != nil {
+		return fmt.Errorf("resolving bad ref %s: %w", bad, err)
+	}
+	goodSHA, err := g.Rev(good + "^{commit}")
+	if err != nil {
+		return fmt.Errorf("resolving good ref %s: %w", good, err)
+	}
+
+	commits, err := g.FirstParentCommits(goodSHA, badSHA)
+	if err != nil {
+		return fmt.Errorf("listing history %s..%s: %w", good, bad, err)
+	}
+	bd := beads.New(r.BeadsPath())
+	mrs, err := bd.List(beads.ListOptions{Status: "closed", Type: "merge-request", Priority: -1})
+	if err != nil {
+		return fmt.Errorf("listing merged MRs: %w", err)
+	}
+	landings := bisect.Landings(commits, mrs)
+	// The bad ref is known bad whether or not an MR landed it last
+	if n := len(landings); n == 0 || landings[n-1].Commit != badSHA {
+		landings = append(landings, bisect.Landing{Commit: badSHA})
+	}
+
+	tmp, err := os.MkdirTemp("", "gt-bisect-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	worktree := filepath.Join(tmp, "worktree")
+	if err := g.WorktreeAddDetached(worktree, badSHA); err != nil {
+		return fmt.Errorf("creating bisect worktree: %w", err)
+	}
+	defer func() { _ = g.WorktreeRemove(worktree, true) }()
+	wt := git.NewGit(worktree)
+
+	if !bisectJSON {
+		fmt.Printf("%s Bisecting %d landing(s) on %s (%s..%s)\n", style.Bold.Render("🔍"),
+			len(landings), r.Name, shortSHA(goodSHA), shortSHA(badSHA))
+	}
+	check := func(commit string) (bool, string, error) {
+		if err := wt.CheckoutDetached(commit); err != nil {
+			return false, "", fmt.Errorf("checking out %s: %w", shortSHA(commit), err)
+		}
+		passed, output := runBisectCheck(worktree, bisectCheck, bisectTimeout)
+		if !bisectJSON {
+			verdict := style.Success.Render("pass")
+			if !passed {
+				verdict = style.Error.Render("fail")
+			}
+			fmt.Printf("  %s %s\n", shortSHA(commit), verdict)
+		}
+		return passed, output, nil
+	}
+
+	result, err := bisect.Search(goodSHA, landings, check)
+	if err != nil {
+		if errors.Is(err, bisect.ErrNotBroken) || errors.Is(err, bisect.ErrAlreadyBroken) {
+			return fmt.Errorf("%w; nothing to bisect between %s and %s", err, good, bad)
+		}
+		return err
+	}
+
+	out := BisectOutput{Rig: r.Name, Check: bisectCheck, Result: result}
+	if !bisectNoFile {
+		out.Bead, err = fileBisectBead(bd, r.Name, result)
+		if err != nil {
+			return err
+		}
+	}
+
+	if bisectJSON {
+		return outputJSON(out)
+	}
+	fmt.Println()
+	fmt.Printf("%s Culprit: %s\n", style.Bold.Render("✗"), result.Culprit.Label())
+	if result.Culprit.Title != "" {
+		fmt.Printf("  %s\n", result.Culprit.Title)
+	}
+	if result.Culprit.SourceIssue != "" {
+		fmt.Printf("  Issue:     %s\n", result.Culprit.SourceIssue)
+	}
+	fmt.Printf("  Last good: %s\n", shortSHA(result.LastGood))
+	if out.Bead != "" {
+		fmt.Printf("  Filed:     %s\n", style.Bold.Render(out.Bead))
+	}
+	return nil
+}
+
+// runBisectCheck runs the check command in dir. A run that can't start or
+// times out counts as a failure.
+func runBisectCheck(dir, command string, timeout time.Duration) (bool, string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	c := exec.CommandContext(ctx, "sh", "-c", command) //nolint:gosec // G204: check command is supplied by the operator
+	c.Dir = dir
+	var output bytes.Buffer
+	c.Stdout = &output
+	c.Stderr = &output
+	err := c.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		output.WriteString(fmt.Sprintf("\n(check timed out after %s)", timeout))
+	}
+	return err == nil, output.String()
+}
+
+// fileBisectBead files the culprit as a bug, assigned to whoever worked the
+// culprit's source issue.
+func fileBisectBead(bd *beads.Beads, rigName string, r *bisect.Result) (string, error) {
+	title := fmt.Sprintf("Bisect: %s broke %s", r.Culprit.Label(), bisectCheck)
+	issue, err := bd.Create(beads.CreateOptions{
+		Title:       truncateString(title, 120),
+		Type:        "bug",
+		Priority:    1,
+		Description: bisect.FormatEvidence(bisectCheck, r),
+		Labels:      []string{"bisect"},
+		Actor:       detectSender(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("filing bisect bead: %w", err)
+	}
+
+	assignee := ""
+	if r.Culprit.SourceIssue != "" {
+		if source, err := bd.Show(r.Culprit.SourceIssue); err == nil {
+			assignee = source.Assignee
+		}
+	}
+	if assignee == "" && r.Culprit.Worker != "" {
+		assignee = fmt.Sprintf("%s/polecats/%s", rigName, r.Culprit.Worker)
+	}
+	if assignee != "" {
+		if err := bd.Update(issue.ID, beads.UpdateOptions{Assignee: &assignee}); err != nil {
+			style.PrintWarning("could not assign %s to %s: %v", issue.ID, assignee, err)
+		}
+	}
+	return issue.ID, nil
+}
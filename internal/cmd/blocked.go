@@ -1,7 +1,7 @@
 package cmd
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"sort"
@@ -12,14 +12,27 @@ import (
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/customfield"
 	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/i18n"
+	"github.com/steveyegge/gastown/internal/paging"
 	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
 var blockedJSON bool
-var blockedRig string
+var (
+	blockedRig       string
+	blockedGroup     string
+	blockedFormat    string
+	blockedPorcelain string
+	blockedFields    []string
+	blockedQuery     string
+	blockedLimit     int
+	blockedOffset    int
+	blockedTop       int
+)
 
 var blockedCmd = &cobra.Command{
 	Use:     "blocked",
@@ -34,31 +47,59 @@ Aggregates blocked issues from:
 Blocked items have unresolved dependencies preventing them from being worked.
 Results are sorted by priority (highest first) then by source.
 
+Every source is read as of the same cutoff: issues created after it are
+left out, and the total notes how many shown changed while reading. The
+cutoff is the snapshot field of --json output.
+
 Examples:
   gt blocked              # Show all blocked work
   gt blocked --json       # Output as JSON
-  gt blocked --rig=gastown  # Show only one rig`,
+  gt blocked --format=ndjson  # One issue per line, streamed as each rig answers
+  gt blocked --porcelain   # Stable tab-separated records for scripts
+  gt blocked --rig=gastown  # Show only one rig
+  gt blocked --group=infra  # Show the rigs in a group
+  gt blocked --field severity=S1  # Only issues with a custom field value
+  gt blocked --query 'priority<=1 and label:infra'  # See 'gt help query'
+  gt blocked --limit 50 --offset 50  # The second page of 50
+  gt blocked --top 5  # Each source's 5 highest-priority issues, with full counts
+
+Each source's bd is probed first. A rig whose bd doesn't answer is shown
+as skipped rather than holding up the whole report.`,
 	RunE: runBlocked,
 }
 
 func init() {
 	blockedCmd.Flags().BoolVar(&blockedJSON, "json", false, "Output as JSON")
 	blockedCmd.Flags().StringVar(&blockedRig, "rig", "", "Filter to a specific rig")
+	blockedCmd.Flags().StringArrayVar(&blockedFields, "field", nil, "Only issues with this custom field value, as name=value (repeatable)")
+	blockedCmd.Flags().StringVar(&blockedQuery, "query", "", queryFlagHelp)
+	blockedCmd.Flags().StringVar(&blockedGroup, "group", "", "Filter to the rigs in a group")
+	blockedCmd.Flags().IntVar(&blockedLimit, "limit", 0, "Show at most this many issues (0 for all)")
+	blockedCmd.Flags().IntVar(&blockedOffset, "offset", 0, "Skip this many issues first, for the next page")
+	blockedCmd.Flags().IntVar(&blockedTop, "top", 0, "List only each source's N highest-priority issues; counts still cover all")
+	blockedCmd.MarkFlagsMutuallyExclusive("top", "limit")
+	blockedCmd.MarkFlagsMutuallyExclusive("top", "offset")
+	blockedCmd.Flags().StringVar(&blockedFormat, "format", "", "Output format: text, json, or ndjson (streamed, one issue per line)")
+	addPorcelainFlag(blockedCmd, &blockedPorcelain)
 	rootCmd.AddCommand(blockedCmd)
 }
 
 // BlockedSource represents blocked items from a single source (town or rig).
 type BlockedSource struct {
-	Name   string         `json:"name"`
-	Issues []*beads.Issue `json:"issues"`
-	Error  string         `json:"error,omitempty"`
+	Name    string         `json:"name"`
+	Issues  []*beads.Issue `json:"issues"`
+	Error   string         `json:"error,omitempty"`
+	Skipped bool           `json:"skipped,omitempty"` // bd failed its health probe, so wasn't read
 }
 
 // BlockedResult is the aggregated result of gt blocked.
 type BlockedResult struct {
-	Sources  []BlockedSource `json:"sources"`
-	Summary  BlockedSummary  `json:"summary"`
-	TownRoot string          `json:"town_root,omitempty"`
+	Sources  []BlockedSource    `json:"sources"`
+	Summary  BlockedSummary     `json:"summary"`
+	TownRoot string             `json:"town_root,omitempty"`
+	Snapshot beads.SnapshotInfo `json:"snapshot"`       // Cutoff the sources were read at
+	Page     *paging.Info       `json:"page,omitempty"` // With --limit or --offset
+	Top      int                `json:"top,omitempty"`  // With --top: most issues listed per source
 }
 
 // BlockedSummary provides counts for the blocked report.
@@ -73,6 +114,36 @@ type BlockedSummary struct {
 }
 
 func runBlocked(cmd *cobra.Command, args []string) error {
+	format, err := resolveFormat(blockedFormat, blockedJSON)
+	if err != nil {
+		return err
+	}
+	fieldFilters, err := customfield.ParseFilters(blockedFields)
+	if err != nil {
+		return err
+	}
+	q, err := parseQueryFlag(blockedQuery)
+	if err != nil {
+		return err
+	}
+	if blockedPorcelain != "" {
+		if err := checkPorcelainVersion(blockedPorcelain); err != nil {
+			return err
+		}
+		format = formatPorcelain
+	}
+	page, err := issuePage(blockedLimit, blockedOffset, format)
+	if err != nil {
+		return err
+	}
+	if blockedTop < 0 {
+		return fmt.Errorf("--top must not be negative")
+	}
+	var stream *ndjsonWriter
+	if format == formatNDJSON {
+		stream = newNDJSONWriter(os.Stdout)
+	}
+
 	townRoot, err := workspace.FindFromCwdOrError()
 	if err != nil {
 		return fmt.Errorf("not in a Gas Town workspace: %w", err)
@@ -86,7 +157,9 @@ func runBlocked(cmd *cobra.Command, args []string) error {
 
 	g := git.NewGit(townRoot)
 	mgr := rig.NewManager(townRoot, rigsConfig, g)
+	progress := startTextProgress(format, i18n.T("Discovering rigs"), 0)
 	rigs, err := mgr.DiscoverRigs()
+	progress.Stop()
 	if err != nil {
 		return fmt.Errorf("discovering rigs: %w", err)
 	}
@@ -104,30 +177,63 @@ func runBlocked(cmd *cobra.Command, args []string) error {
 		}
 		rigs = filtered
 	}
+	if rigs, err = filterRigsByGroup(rigs, rigsConfig, blockedGroup); err != nil {
+		return err
+	}
+	rigs = filterRigsByQuery(rigs, q)
+	includeTown := blockedRig == "" && blockedGroup == "" && q.Covers("town")
+
+	// Cut every source off at the same moment so the totals agree
+	snap := beads.NewSnapshot()
+
+	total := len(rigs)
+	if includeTown {
+		total++ // town beads
+	}
+	progress = startTextProgress(format, i18n.T("Checking blocked work"), total)
+
+	// blockedFilter drops what bd blocked returns that isn't work here
+	blockedFilter := func(name, beadsPath string) func([]*beads.Issue) []*beads.Issue {
+		formulaNames, wispIDs := getFormulaNames(beadsPath), getWispIDs(beadsPath)
+		return func(issues []*beads.Issue) []*beads.Issue {
+			filtered := filterFormulaScaffolds(issues, formulaNames)
+			filtered = filterWisps(filtered, wispIDs)
+			filtered = filterWispsByID(filtered)
+			return q.Filter(name, filterByCustomFields(filtered, fieldFilters))
+		}
+	}
 
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 	sources := make([]BlockedSource, 0, len(rigs)+1)
+	tallies := make(map[string]*beads.Tally, len(rigs)+1)
 
-	if blockedRig == "" {
+	if includeTown {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
+			progress.Start("town")
 			townBeadsPath := beads.GetTownBeadsPath(townRoot)
-			townBeads := beads.New(townBeadsPath)
-			issues, err := townBeads.Blocked()
+			var tally *beads.Tally
+			townBeads, err := healthyBeads(townBeadsPath)
+			if err == nil {
+				tally, err = readSource(blockedEach(townBeads), nil, blockedFilter("town", townBeadsPath), snap, paging.Page{}, blockedTop)
+			}
+			progress.Done("town")
 
 			mu.Lock()
 			defer mu.Unlock()
 			src := BlockedSource{Name: "town"}
 			if err != nil {
 				src.Error = err.Error()
+				src.Skipped = errors.Is(err, beads.ErrUnhealthy)
+				tally = beads.NewTally(0)
 			} else {
-				formulaNames := getFormulaNames(townBeadsPath)
-				filtered := filterFormulaScaffolds(issues, formulaNames)
-				wispIDs := getWispIDs(townBeadsPath)
-				filtered = filterWisps(filtered, wispIDs)
-				src.Issues = filterWispsByID(filtered)
+				src.Issues = tally.Issues()
+			}
+			tallies[src.Name] = tally
+			if stream != nil {
+				stream.writeSource(src.Name, src.Issues, src.Error)
 			}
 			sources = append(sources, src)
 		}()
@@ -137,26 +243,37 @@ func runBlocked(cmd *cobra.Command, args []string) error {
 		wg.Add(1)
 		go func(r *rig.Rig) {
 			defer wg.Done()
-			rigBeads := beads.New(r.BeadsPath())
-			issues, err := rigBeads.Blocked()
+			progress.Start(r.Name)
+			var tally *beads.Tally
+			rigBeads, err := healthyBeads(r.BeadsPath())
+			if err == nil {
+				tally, err = readSource(blockedEach(rigBeads), nil, blockedFilter(r.Name, r.BeadsPath()), snap, paging.Page{}, blockedTop)
+			}
+			progress.Done(r.Name)
 
 			mu.Lock()
 			defer mu.Unlock()
 			src := BlockedSource{Name: r.Name}
 			if err != nil {
 				src.Error = err.Error()
+				src.Skipped = errors.Is(err, beads.ErrUnhealthy)
+				tally = beads.NewTally(0)
 			} else {
-				formulaNames := getFormulaNames(r.BeadsPath())
-				filtered := filterFormulaScaffolds(issues, formulaNames)
-				wispIDs := getWispIDs(r.BeadsPath())
-				filtered = filterWisps(filtered, wispIDs)
-				src.Issues = filterWispsByID(filtered)
+				src.Issues = tally.Issues()
+			}
+			tallies[src.Name] = tally
+			if stream != nil {
+				stream.writeSource(src.Name, src.Issues, src.Error)
 			}
 			sources = append(sources, src)
 		}(r)
 	}
 
 	wg.Wait()
+	progress.Stop()
+	if stream != nil {
+		return nil
+	}
 
 	sort.Slice(sources, func(i, j int) bool {
 		if sources[i].Name == "town" {
@@ -168,74 +285,88 @@ func runBlocked(cmd *cobra.Command, args []string) error {
 		return sources[i].Name < sources[j].Name
 	})
 
-	for i := range sources {
-		sort.Slice(sources[i].Issues, func(a, b int) bool {
-			return sources[i].Issues[a].Priority < sources[i].Issues[b].Priority
-		})
+	// bd blocked has no limit, so the page is cut from everything blocked
+	var pageInfo *paging.Info
+	if page.Paged() {
+		lists := make([][]*beads.Issue, len(sources))
+		for i := range sources {
+			lists[i] = sources[i].Issues
+		}
+		lists, info := pageIssueSources(lists, page)
+		for i := range sources {
+			sources[i].Issues = lists[i]
+		}
+		pageInfo = &info
 	}
 
 	summary := BlockedSummary{
 		BySource: make(map[string]int),
 	}
 	for _, src := range sources {
-		count := len(src.Issues)
-		summary.Total += count
-		summary.BySource[src.Name] = count
-		for _, issue := range src.Issues {
-			switch issue.Priority {
-			case 0:
-				summary.P0Count++
-			case 1:
-				summary.P1Count++
-			case 2:
-				summary.P2Count++
-			case 3:
-				summary.P3Count++
-			case 4:
-				summary.P4Count++
-			}
-		}
+		t := tallies[src.Name]
+		summary.Total += t.Total
+		summary.BySource[src.Name] = t.Total
+		summary.P0Count += t.ByPriority[0]
+		summary.P1Count += t.ByPriority[1]
+		summary.P2Count += t.ByPriority[2]
+		summary.P3Count += t.ByPriority[3]
+		summary.P4Count += t.ByPriority[4]
 	}
 
 	result := BlockedResult{
 		Sources:  sources,
 		Summary:  summary,
 		TownRoot: townRoot,
+		Snapshot: snap.Info(),
+		Page:     pageInfo,
+		Top:      blockedTop,
 	}
 
-	if blockedJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(result)
+	if format == formatJSON {
+		return outputJSON(result)
+	}
+	if format == formatPorcelain {
+		p := porcelainWriter{w: os.Stdout}
+		for _, src := range result.Sources {
+			writeIssueSourcePorcelain(p, "blocked", src.Name, src.Issues, src.Error)
+		}
+		return nil
 	}
 
 	return printBlockedHuman(result)
 }
 
 func printBlockedHuman(result BlockedResult) error {
-	if result.Summary.Total == 0 {
-		fmt.Println("No blocked work across town.")
+	shown := 0
+	for _, src := range result.Sources {
+		shown += len(src.Issues)
+	}
+	if shown == 0 {
+		if result.Page != nil && result.Page.Offset > 0 {
+			printPageHint(result.Page, 0)
+		} else if !quietFlag {
+			fmt.Println(i18n.T("No blocked work across town."))
+		}
 		return nil
 	}
 
-	fmt.Printf("%s Blocked work across town:\n\n", style.Bold.Render("\U0001F6AB"))
+	if !quietFlag {
+		fmt.Printf("%s %s\n\n", style.Bold.Render("\U0001F6AB"), i18n.T("Blocked work across town:"))
+	}
 
 	for _, src := range result.Sources {
 		if src.Error != "" {
-			fmt.Printf("%s %s\n", style.Dim.Render(src.Name+"/"), style.Warning.Render("(error: "+src.Error+")"))
+			fmt.Printf("%s %s\n", style.Dim.Render(src.Name+"/"), style.Warning.Render(i18n.Sprintf("(error: %s)", src.Error)))
 			continue
 		}
 
-		count := len(src.Issues)
-		if count == 0 {
+		// With --top or a page the source can hold more than it lists
+		count := result.Summary.BySource[src.Name]
+		if len(src.Issues) == 0 {
 			continue
 		}
 
-		itemWord := "items"
-		if count == 1 {
-			itemWord = "item"
-		}
-		fmt.Printf("%s (%d %s)\n", style.Bold.Render(src.Name+"/"), count, itemWord)
+		fmt.Printf("%s %s\n", style.Bold.Render(src.Name+"/"), i18n.Plural(count, "(%d item)", "(%d items)"))
 		for _, issue := range src.Issues {
 			priorityStr := fmt.Sprintf("P%d", issue.Priority)
 			var priorityStyled string
@@ -250,19 +381,26 @@ func printBlockedHuman(result BlockedResult) error {
 				priorityStyled = style.Dim.Render(priorityStr)
 			}
 
-			title := issue.Title
-			if len(title) > 60 {
-				title = title[:57] + "..."
-			}
-
 			blockedByStr := ""
 			if len(issue.BlockedBy) > 0 {
-				blockedByStr = " " + style.Dim.Render("(blocked by: "+strings.Join(issue.BlockedBy, ", ")+")")
+				blockedByStr = " " + style.Dim.Render(i18n.Sprintf("(blocked by: %s)", strings.Join(issue.BlockedBy, ", ")))
 			}
 
-			fmt.Printf("  [%s] %s %s%s\n", priorityStyled, style.Dim.Render(issue.ID), title, blockedByStr)
+			// The title gets what's left of the line around it
+			line := fmt.Sprintf("  [%s] %s ", priorityStyled, style.Dim.Render(issue.ID))
+			title := style.FitTitle(issue.Title, style.Width(line)+style.Width(blockedByStr))
+			fmt.Printf("%s%s%s\n", line, title, blockedByStr)
+		}
+		if result.Page == nil {
+			printMoreLine(count - len(src.Issues))
+		}
+		if !quietFlag {
+			fmt.Println()
 		}
-		fmt.Println()
+	}
+
+	if quietFlag {
+		return nil
 	}
 
 	parts := []string{}
@@ -283,11 +421,13 @@ func printBlockedHuman(result BlockedResult) error {
 	}
 
 	if len(parts) > 0 {
-		fmt.Printf("Total: %d items blocked (%s)\n", result.Summary.Total, strings.Join(parts, ", "))
+		fmt.Println(i18n.Plural(result.Summary.Total, "Total: %d item blocked (%s)", "Total: %d items blocked (%s)", strings.Join(parts, ", ")))
 	} else {
-		fmt.Printf("Total: %d items blocked\n", result.Summary.Total)
+		fmt.Println(i18n.Plural(result.Summary.Total, "Total: %d item blocked", "Total: %d items blocked"))
 	}
 
+	printPageHint(result.Page, shown)
+	printSnapshotLine(result.Snapshot)
 	return nil
 }
 
@@ -302,3 +442,11 @@ func filterWispsByID(issues []*beads.Issue) []*beads.Issue {
 	}
 	return filtered
 }
+
+// blockedEach adapts BlockedEach to readSource. bd blocked has no limit,
+// so the whole list is always read.
+func blockedEach(b *beads.Beads) func(int, func(*beads.Issue)) error {
+	return func(_ int, fn func(*beads.Issue)) error {
+		return b.BlockedEach(fn)
+	}
+}
@@ -3,7 +3,6 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
-	"os"
 	"os/exec"
 	"time"
 
@@ -128,9 +127,7 @@ func runBootStatus(cmd *cobra.Command, args []string) error {
 			"boot_dir":      b.Dir(),
 			"last_status":   status,
 		}
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(output)
+		return outputJSON(output)
 	}
 
 	// Pretty print
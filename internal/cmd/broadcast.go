@@ -3,9 +3,11 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -13,12 +15,14 @@ import (
 
 var (
 	broadcastRig    string
+	broadcastGroup  string
 	broadcastAll    bool
 	broadcastDryRun bool
 )
 
 func init() {
 	broadcastCmd.Flags().StringVar(&broadcastRig, "rig", "", "Only broadcast to workers in this rig")
+	broadcastCmd.Flags().StringVar(&broadcastGroup, "group", "", "Only broadcast to workers in the rigs of this group")
 	broadcastCmd.Flags().BoolVar(&broadcastAll, "all", false, "Include all agents (mayor, witness, etc.), not just workers")
 	broadcastCmd.Flags().BoolVar(&broadcastDryRun, "dry-run", false, "Show what would be sent without sending")
 	rootCmd.AddCommand(broadcastCmd)
@@ -38,6 +42,7 @@ The message is sent as a nudge to each worker's Claude Code session.
 Examples:
   gt broadcast "Check your mail"
   gt broadcast --rig greenplace "New priority work available"
+  gt broadcast --group client_a "Freeze merges until 3pm"
   gt broadcast --all "System maintenance in 5 minutes"
   gt broadcast --dry-run "Test message"`,
 	Args: cobra.ExactArgs(1),
@@ -51,6 +56,21 @@ func runBroadcast(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("message cannot be empty")
 	}
 
+	var groupRigs map[string]bool
+	if broadcastGroup != "" {
+		townRoot, err := workspace.FindFromCwdOrError()
+		if err != nil {
+			return fmt.Errorf("not in a Gas Town workspace: %w", err)
+		}
+		rigsConfig, err := config.LoadRigsConfig(filepath.Join(townRoot, "mayor", "rigs.json"))
+		if err != nil {
+			return fmt.Errorf("loading rigs config: %w", err)
+		}
+		if groupRigs, err = groupRigSet(rigsConfig, broadcastGroup); err != nil {
+			return err
+		}
+	}
+
 	// Get all agent sessions (including polecats)
 	agents, err := getAgentSessions(true)
 	if err != nil {
@@ -67,6 +87,9 @@ func runBroadcast(cmd *cobra.Command, args []string) error {
 		if broadcastRig != "" && agent.Rig != broadcastRig {
 			continue
 		}
+		if groupRigs != nil && !groupRigs[agent.Rig] {
+			continue
+		}
 
 		// Unless --all, only include workers (crew + polecats)
 		if !broadcastAll {
@@ -88,6 +111,9 @@ func runBroadcast(cmd *cobra.Command, args []string) error {
 		if broadcastRig != "" {
 			fmt.Printf("  (filtered by rig: %s)\n", broadcastRig)
 		}
+		if broadcastGroup != "" {
+			fmt.Printf("  (filtered by group: %s)\n", broadcastGroup)
+		}
 		return nil
 	}
 
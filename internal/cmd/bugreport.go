@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/bugreport"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var bugreportCmd = &cobra.Command{
+	Use:     "bugreport",
+	GroupID: GroupDiag,
+	Short:   "Collect a redacted diagnostic bundle for an issue report",
+	Long: `Collect a diagnostic bundle to attach to an issue report.
+
+The bundle is a tar.gz containing:
+  - Town and rig configs (mayor/, settings/, rig config.json, beads config)
+  - The last lines of the event log, town log, and daemon/Dolt logs
+  - gt, bd, git, tmux, and dolt versions and the platform
+  - A gt doctor report
+
+Everything is redacted before it is written: credential-looking keys and
+values, URL passwords, email addresses, and your home directory. gt keeps
+no shell history; the event log records what commands did. Review the
+bundle before attaching it.
+
+Issue contents are left out unless --include-beads-snapshot is given,
+which adds every issue in the town and rig beads (also redacted).
+
+Examples:
+  gt bugreport
+  gt bugreport -o /tmp/report.tar.gz --log-lines 2000
+  gt bugreport --include-beads-snapshot`,
+	RunE: runBugreport,
+}
+
+var (
+	bugreportOutput        string
+	bugreportBeadsSnapshot bool
+	bugreportNoDoctor      bool
+	bugreportLogLines      int
+)
+
+func init() {
+	bugreportCmd.Flags().StringVarP(&bugreportOutput, "output", "o", "", "Archive path (default gt-bugreport-<time>.tar.gz in the current directory)")
+	bugreportCmd.Flags().BoolVar(&bugreportBeadsSnapshot, "include-beads-snapshot", false, "Include every issue in the town and rig beads")
+	bugreportCmd.Flags().BoolVar(&bugreportNoDoctor, "no-doctor", false, "Skip the doctor report")
+	bugreportCmd.Flags().IntVar(&bugreportLogLines, "log-lines", bugreport.DefaultLogLines, "Lines to keep from each log")
+	rootCmd.AddCommand(bugreportCmd)
+}
+
+func runBugreport(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	out := bugreportOutput
+	if out == "" {
+		out = fmt.Sprintf("gt-bugreport-%s.tar.gz", time.Now().Format("20060102-150405"))
+	}
+
+	var b bugreport.Bundle
+	b.CollectVersions(Version)
+	b.CollectConfigs(townRoot)
+	b.CollectLogs(townRoot, bugreportLogLines)
+	if !bugreportNoDoctor {
+		fmt.Println("Running gt doctor...")
+		b.Add("doctor.txt", doctorReport())
+	}
+	if bugreportBeadsSnapshot {
+		n := collectBeadsSnapshot(&b, townRoot)
+		fmt.Printf("Included %d issue(s) from beads\n", n)
+	}
+
+	if err := b.Write(out); err != nil {
+		return fmt.Errorf("writing bundle: %w", err)
+	}
+	abs, _ := filepath.Abs(out)
+	fmt.Printf("%s Wrote %s (%d files)\n", style.SuccessPrefix, abs, len(b.Files))
+	fmt.Printf("  %s\n", style.Dim.Render("Contents are redacted; review before attaching: tar -tzf "+out))
+	return nil
+}
+
+// doctorReport runs gt doctor and returns its output, including failures.
+func doctorReport() []byte {
+	exe, err := os.Executable()
+	if err != nil {
+		return []byte(err.Error())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	c := exec.CommandContext(ctx, exe, "doctor", "--verbose") //nolint:gosec // G204: re-running gt
+	c.Env = append(os.Environ(), "NO_COLOR=1")
+	out, err := c.CombinedOutput()
+	if err != nil {
+		out = append(out, fmt.Sprintf("\n(gt doctor: %v)\n", err)...)
+	}
+	return out
+}
+
+// collectBeadsSnapshot adds every issue in the town and rig beads and
+// returns how many there were.
+func collectBeadsSnapshot(b *bugreport.Bundle, townRoot string) int {
+	dirs := map[string]string{"hq": beads.GetTownBeadsPath(townRoot)}
+	for _, rigName := range discoverRigs(townRoot) {
+		dirs[rigName] = filepath.Join(townRoot, rigName, "mayor", "rig")
+	}
+	total := 0
+	for name, dir := range dirs {
+		issues, err := beads.New(dir).List(beads.ListOptions{Status: "all", Priority: -1})
+		if err != nil {
+			b.Add("beads/"+name+".error.txt", []byte(err.Error()))
+			continue
+		}
+		data, err := json.MarshalIndent(issues, "", "  ")
+		if err != nil {
+			continue
+		}
+		b.Add("beads/"+name+".json", data)
+		total += len(issues)
+	}
+	return total
+}
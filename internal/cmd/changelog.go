@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/release"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// Changelog command flags
+var (
+	changelogSince   string
+	changelogHeading string
+	changelogJSON    bool
+)
+
+var changelogCmd = &cobra.Command{
+	Use:     "changelog <rig>",
+	GroupID: GroupWork,
+	Short:   "Generate a changelog from merge-queue history",
+	Long: `Generate a changelog from the MRs merged into a rig.
+
+Entries come from merged MR beads, titled by their source issue. Multiple
+MRs for the same issue are folded into one entry. Entries are grouped by
+the source issue's type and labels:
+
+  Breaking Changes   MR recorded breaking API changes, or "breaking" label
+  Features           feature issues, or "feature"/"enhancement" labels
+  Bug Fixes          bug issues, or "bug"/"fix" labels
+  Documentation      "docs"/"documentation" labels
+  Maintenance        chores, or "chore"/"refactor"/"ci"/"deps" labels
+  Other Changes      everything else
+
+--since takes a tag or a date (YYYY-MM-DD or RFC3339). By default the
+changelog covers everything since the last release tag (see 'gt rig release').
+
+Examples:
+  gt changelog gastown                     # Since the last release
+  gt changelog gastown --since v1.2.0
+  gt changelog gastown --since 2026-01-01 --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runChangelog,
+}
+
+func init() {
+	changelogCmd.Flags().StringVar(&changelogSince, "since", "", "Tag or date to start from (default: last release tag)")
+	changelogCmd.Flags().StringVar(&changelogHeading, "heading", "Unreleased", "Heading for the Markdown output")
+	changelogCmd.Flags().BoolVar(&changelogJSON, "json", false, "Output as JSON")
+
+	rootCmd.AddCommand(changelogCmd)
+}
+
+// ChangelogOutput is the JSON output of gt changelog.
+type ChangelogOutput struct {
+	Rig      string            `json:"rig"`
+	Since    string            `json:"since,omitempty"`
+	SinceAt  *time.Time        `json:"since_at,omitempty"`
+	Count    int               `json:"count"`
+	Sections []release.Section `json:"sections"`
+}
+
+func runChangelog(cmd *cobra.Command, args []string) error {
+	_, r, err := getRig(args[0])
+	if err != nil {
+		return err
+	}
+
+	g := git.NewGit(constants.RigMayorPath(r.Path))
+	sinceLabel, since, err := resolveChangelogSince(g, r, changelogSince)
+	if err != nil {
+		return err
+	}
+
+	entries, err := collectMergedEntries(beads.New(r.BeadsPath()), since)
+	if err != nil {
+		return err
+	}
+	entries = release.Dedupe(entries)
+	sections := release.Group(entries)
+
+	if changelogJSON {
+		out := ChangelogOutput{Rig: r.Name, Since: sinceLabel, Count: len(entries), Sections: sections}
+		if out.Sections == nil {
+			out.Sections = []release.Section{}
+		}
+		if !since.IsZero() {
+			out.SinceAt = &since
+		}
+		return outputJSON(out)
+	}
+
+	fmt.Print(release.Markdown(changelogHeading, sections))
+	return nil
+}
+
+// resolveChangelogSince turns --since into a cutoff time. A date is used
+// as-is; anything else is resolved as a tag. Empty means the latest release
+// tag, or all history if the rig has never been released.
+func resolveChangelogSince(g *git.Git, r *rig.Rig, since string) (string, time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, since); err == nil {
+			return since, t, nil
+		}
+	}
+
+	tag := since
+	if tag == "" {
+		prefix := config.DefaultReleaseTagPrefix
+		if settings, err := config.LoadRigSettings(config.RigSettingsPath(r.Path)); err == nil &&
+			settings.Release != nil && settings.Release.TagPrefix != nil {
+			prefix = *settings.Release.TagPrefix
+		}
+		tags, err := g.Tags(prefix + "*")
+		if err != nil {
+			return "", time.Time{}, fmt.Errorf("listing tags: %w", err)
+		}
+		latest, _, ok := release.Latest(tags, prefix)
+		if !ok {
+			return "", time.Time{}, nil
+		}
+		tag = latest
+	}
+
+	t, err := g.CommitTime(tag)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("resolving --since %q (want a tag or YYYY-MM-DD): %w", tag, err)
+	}
+	return tag, t, nil
+}
+
+// collectMergedEntries lists a rig's MRs merged after since, titled by
+// their source issues.
+func collectMergedEntries(bd *beads.Beads, since time.Time) ([]release.Entry, error) {
+	mrs, err := bd.List(beads.ListOptions{Status: "closed", Type: "merge-request", Priority: -1})
+	if err != nil {
+		return nil, fmt.Errorf("listing merged MRs: %w", err)
+	}
+	return release.Collect(mrs, since, func(id string) *beads.Issue {
+		issue, err := bd.Show(id)
+		if err != nil {
+			return nil
+		}
+		return issue
+	}), nil
+}
@@ -0,0 +1,257 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/ci"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// CI command flags
+var (
+	ciJSON       bool
+	ciTimeout    time.Duration
+	ciInterval   time.Duration
+	ciIncludeOwn bool
+	ciIgnore     []string
+)
+
+var ciCmd = &cobra.Command{
+	Use:     "ci",
+	GroupID: GroupWork,
+	Short:   "Read and publish GitHub CI status",
+	Long: `Connect the merge queue and GitHub Actions in both directions.
+
+Outbound: with merge_queue.publish_ci_status set in a rig's config.json,
+the merge queue publishes each MR's state as a "gastown/merge-queue"
+commit status on its branch head (pending when submitted, success when
+merged, failure when rejected), and convoy progress as "gastown/convoy".
+Require "gastown/merge-queue" in branch protection to let GitHub merge
+only what the refinery has passed.
+
+Inbound: 'gt ci wait' blocks until a commit's external checks finish, so
+the refinery (or a formula step) can gate on GitHub Actions.
+
+Requests go through 'gh api' and use gh's credentials.`,
+	RunE: requireSubcommand,
+}
+
+var ciStatusCmd = &cobra.Command{
+	Use:   "status <rig> <sha-or-ref>",
+	Short: "Show the CI checks on a commit",
+	Long: `Show the commit statuses and check runs on a commit in a rig's
+GitHub repository, with their combined state.
+
+Exits 1 if any check failed.
+
+Examples:
+  gt ci status gastown 3f2c1ab
+  gt ci status gastown polecat/nux/gt-abc --json`,
+	Args: cobra.ExactArgs(2),
+	RunE: runCIStatus,
+}
+
+var ciWaitCmd = &cobra.Command{
+	Use:   "wait <rig> <sha-or-ref>",
+	Short: "Wait for a commit's CI checks to finish",
+	Long: `Block until every CI check on a commit has finished.
+
+Exits 0 when all checks passed and 1 when any failed or the wait timed
+out. Gas Town's own statuses (gastown/*) are ignored unless
+--include-own is given, so the refinery never waits on itself. A commit
+with no checks yet counts as pending: CI may not have started.
+
+Examples:
+  gt ci wait gastown 3f2c1ab
+  gt ci wait gastown polecat/nux/gt-abc --timeout 1h
+  gt ci wait gastown 3f2c1ab --ignore codecov/`,
+	Args: cobra.ExactArgs(2),
+	RunE: runCIWait,
+}
+
+func init() {
+	ciStatusCmd.Flags().BoolVar(&ciJSON, "json", false, "Output as JSON")
+
+	ciWaitCmd.Flags().DurationVar(&ciTimeout, "timeout", 30*time.Minute, "Give up after this long (0 = never)")
+	ciWaitCmd.Flags().DurationVar(&ciInterval, "interval", 15*time.Second, "How often to poll GitHub")
+	ciWaitCmd.Flags().BoolVar(&ciIncludeOwn, "include-own", false, "Also wait on Gas Town's own gastown/* statuses")
+	ciWaitCmd.Flags().StringSliceVar(&ciIgnore, "ignore", nil, "Ignore checks whose names start with this prefix (repeatable)")
+	ciWaitCmd.Flags().BoolVar(&ciJSON, "json", false, "Output the final checks as JSON")
+
+	ciCmd.AddCommand(ciStatusCmd)
+	ciCmd.AddCommand(ciWaitCmd)
+	rootCmd.AddCommand(ciCmd)
+}
+
+// ciTarget resolves a rig's GitHub repository and a commit in it.
+func ciTarget(rigName, ref string) (ci.Repo, string, error) {
+	eng, err := loadRigEngineer(rigName)
+	if err != nil {
+		return ci.Repo{}, "", err
+	}
+	repo, err := eng.GitHubRepo()
+	if err != nil {
+		return ci.Repo{}, "", err
+	}
+	sha, err := eng.ResolveCommit(ref)
+	if err != nil {
+		return ci.Repo{}, "", err
+	}
+	return repo, sha, nil
+}
+
+func runCIStatus(cmd *cobra.Command, args []string) error {
+	repo, sha, err := ciTarget(args[0], args[1])
+	if err != nil {
+		return err
+	}
+	checks, err := ci.NewClient().Checks(context.Background(), repo, sha)
+	if err != nil {
+		return err
+	}
+	state := ci.Overall(checks)
+
+	if ciJSON {
+		if err := outputJSON(map[string]interface{}{
+			"repo":   repo.String(),
+			"sha":    sha,
+			"state":  state,
+			"checks": checks,
+		}); err != nil {
+			return err
+		}
+	} else {
+		printCIChecks(repo, sha, checks, state)
+	}
+	if state == ci.Failure {
+		return NewSilentExit(1)
+	}
+	return nil
+}
+
+func runCIWait(cmd *cobra.Command, args []string) error {
+	repo, sha, err := ciTarget(args[0], args[1])
+	if err != nil {
+		return err
+	}
+	ignore := ciIgnore
+	if !ciIncludeOwn {
+		ignore = append(ignore, ci.OwnPrefix)
+	}
+
+	ctx := context.Background()
+	if ciTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ciTimeout)
+		defer cancel()
+	}
+
+	if !ciJSON {
+		fmt.Printf("Waiting for CI on %s@%s...\n", repo, sha[:8])
+	}
+	last := ""
+	checks, state, err := ci.NewClient().Wait(ctx, repo, sha, ci.WaitOptions{
+		Interval: ciInterval,
+		Ignore:   ignore,
+		OnPoll: func(checks []ci.Check) {
+			if ciJSON {
+				return
+			}
+			if line := ciProgress(checks); line != last {
+				fmt.Printf("  %s\n", style.Dim.Render(line))
+				last = line
+			}
+		},
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s: %s", ciTimeout, ciProgress(checks))
+	}
+	if err != nil {
+		return err
+	}
+
+	if ciJSON {
+		if err := outputJSON(map[string]interface{}{
+			"repo":   repo.String(),
+			"sha":    sha,
+			"state":  state,
+			"checks": checks,
+		}); err != nil {
+			return err
+		}
+	} else {
+		printCIChecks(repo, sha, checks, state)
+	}
+	if state != ci.Success {
+		return NewSilentExit(1)
+	}
+	return nil
+}
+
+// ciProgress summarizes checks as "N passed, N failed, N pending".
+func ciProgress(checks []ci.Check) string {
+	if len(checks) == 0 {
+		return "no checks yet"
+	}
+	counts := make(map[ci.State]int)
+	for _, c := range checks {
+		counts[c.State]++
+	}
+	var parts []string
+	for _, s := range []struct {
+		state ci.State
+		label string
+	}{{ci.Success, "passed"}, {ci.Failure, "failed"}, {ci.Pending, "pending"}} {
+		if counts[s.state] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[s.state], s.label))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// printCIChecks lists checks with their combined state.
+func printCIChecks(repo ci.Repo, sha string, checks []ci.Check, state ci.State) {
+	fmt.Printf("%s %s@%s: %s\n", ciStateIcon(state), repo, sha[:8], style.Bold.Render(string(state)))
+	if len(checks) == 0 {
+		fmt.Printf("  %s\n", style.Dim.Render("(no checks)"))
+		return
+	}
+	for _, c := range checks {
+		fmt.Printf("  %s %s", ciStateIcon(c.State), c.Name)
+		if c.Detail != "" {
+			fmt.Printf("  %s", style.Dim.Render(c.Detail))
+		}
+		fmt.Println()
+	}
+}
+
+func ciStateIcon(s ci.State) string {
+	switch s {
+	case ci.Success:
+		return style.Success.Render("✓")
+	case ci.Failure, ci.Error:
+		return style.Error.Render("✗")
+	default:
+		return style.Warning.Render("●")
+	}
+}
+
+// publishMRStatus reports an MR's merge queue state to GitHub if the rig
+// publishes CI status. Failures are warnings: the queue doesn't depend on
+// GitHub being reachable.
+func publishMRStatus(rigName, mrID, sha string, state ci.State, description string) {
+	eng, err := loadRigEngineer(rigName)
+	if err != nil || !eng.Config().PublishCIStatus {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := eng.PublishCIStatus(ctx, mrID, sha, state, description); err != nil {
+		style.PrintWarning("could not publish CI status: %v", err)
+	}
+}
@@ -275,9 +275,7 @@ func runCompact(cmd *cobra.Command, args []string) error {
 
 	// Output results
 	if compactJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(result)
+		return outputJSON(result)
 	}
 
 	printCompactSummary(result)
@@ -402,13 +400,7 @@ func printCompactSummary(result *compactResult) {
 
 // compactTruncate shortens a string to maxLen, adding "..." if truncated.
 func compactTruncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
-	}
-	if maxLen <= 3 {
-		return s[:maxLen]
-	}
-	return s[:maxLen-3] + "..."
+	return style.Truncate(s, maxLen)
 }
 
 // hasComments checks the comment_count on the compactIssue.
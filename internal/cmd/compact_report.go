@@ -138,9 +138,7 @@ func runDailyDigest() error {
 	report.Anomalies = detectAnomalies(report)
 
 	if compactReportJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(report)
+		return outputJSON(report)
 	}
 
 	// Format as markdown
@@ -381,9 +379,7 @@ func runWeeklyRollup() error {
 	}
 
 	if compactReportJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(rollup)
+		return outputJSON(rollup)
 	}
 
 	markdown := formatWeeklyRollup(rollup)
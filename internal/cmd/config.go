@@ -2,9 +2,7 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
 	"sort"
 	"strings"
 
@@ -29,7 +27,8 @@ Commands:
   gt config agent get <name>         Show agent configuration
   gt config agent set <name> <cmd>   Set custom agent command
   gt config agent remove <name>      Remove custom agent
-  gt config default-agent [name]     Get or set default agent`,
+  gt config default-agent [name]     Get or set default agent
+  gt config defaults                 Show per-command flag defaults`,
 }
 
 // Agent subcommands
@@ -216,9 +215,7 @@ func runConfigAgentList(cmd *cobra.Command, args []string) error {
 	})
 
 	if configAgentListJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(items)
+		return outputJSON(items)
 	}
 
 	// Text output
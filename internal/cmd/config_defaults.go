@@ -0,0 +1,254 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var configDefaultsCmd = &cobra.Command{
+	Use:   "defaults",
+	Short: "Show per-command flag defaults",
+	Long: `Show the flag defaults applied to commands.
+
+Defaults save retyping the same filters: "ready and blocked default to
+--group mine". They come from two places, the later winning:
+
+  settings/config.json  command_defaults, shared by the town (--town)
+  ~/.config/gastown/defaults.json   your own
+
+A flag given on the command line always wins. The command "*" sets a
+default for every command that has the flag. Set GT_NO_DEFAULTS=1 to
+ignore all defaults, e.g. in scripts.
+
+Examples:
+  gt config defaults
+  gt config defaults set ready group=mine
+  gt config defaults set blocked group=mine rig=
+  gt config defaults set --town "*" json=true
+  gt config defaults unset ready group
+  gt config defaults unset ready`,
+	Args: cobra.NoArgs,
+	RunE: runConfigDefaults,
+}
+
+var configDefaultsSetCmd = &cobra.Command{
+	Use:   "set <command> <flag>=<value>...",
+	Short: "Set flag defaults for a command",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  runConfigDefaultsSet,
+}
+
+var configDefaultsUnsetCmd = &cobra.Command{
+	Use:   "unset <command> [flag...]",
+	Short: "Remove flag defaults for a command",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runConfigDefaultsUnset,
+}
+
+var (
+	configDefaultsTown bool
+	configDefaultsJSON bool
+)
+
+func init() {
+	configCmd.AddCommand(configDefaultsCmd)
+	configDefaultsCmd.AddCommand(configDefaultsSetCmd)
+	configDefaultsCmd.AddCommand(configDefaultsUnsetCmd)
+
+	configDefaultsCmd.Flags().BoolVar(&configDefaultsJSON, "json", false, "Output as JSON")
+	configDefaultsSetCmd.Flags().BoolVar(&configDefaultsTown, "town", false, "Set the town's defaults instead of yours")
+	configDefaultsUnsetCmd.Flags().BoolVar(&configDefaultsTown, "town", false, "Unset the town's defaults instead of yours")
+}
+
+// commandPath returns the command's path without the root command name,
+// the key command defaults use ("blocked", "rig list").
+func commandPath(cmd *cobra.Command) string {
+	return strings.TrimPrefix(strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()), " ")
+}
+
+// loadCommandDefaults returns the town defaults (nil outside a town) and
+// the user's.
+func loadCommandDefaults() (town, user config.CommandDefaults, err error) {
+	if townRoot, err := workspace.FindFromCwd(); err == nil && townRoot != "" {
+		if settings, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(townRoot)); err == nil {
+			town = settings.CommandDefaults
+		}
+	}
+	user, err = config.LoadCommandDefaults(config.UserDefaultsPath())
+	return town, user, err
+}
+
+// applyCommandDefaults sets the configured defaults on each of cmd's flags
+// that wasn't given on the command line.
+func applyCommandDefaults(cmd *cobra.Command) {
+	if os.Getenv(config.NoDefaultsEnv) == "1" {
+		return
+	}
+	town, user, err := loadCommandDefaults()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s command defaults: %v\n", style.Warning.Render("⚠"), err)
+	}
+	merged := config.MergeCommandDefaults(town, user)
+	if len(merged) == 0 {
+		return
+	}
+	path := commandPath(cmd)
+	own := merged[path]
+	for name, value := range merged.For(path) {
+		f := cmd.Flags().Lookup(name)
+		if f == nil {
+			if _, explicit := own[name]; explicit {
+				fmt.Fprintf(os.Stderr, "%s default for '%s' names unknown flag --%s\n", style.Warning.Render("⚠"), path, name)
+			}
+			continue // "*" defaults only apply to commands with the flag
+		}
+		if f.Changed {
+			continue
+		}
+		if err := cmd.Flags().Set(name, value); err != nil {
+			fmt.Fprintf(os.Stderr, "%s default --%s=%s for '%s': %v\n", style.Warning.Render("⚠"), name, value, path, err)
+		}
+	}
+}
+
+func runConfigDefaults(cmd *cobra.Command, args []string) error {
+	town, user, err := loadCommandDefaults()
+	if err != nil {
+		return err
+	}
+	if configDefaultsJSON {
+		return outputJSON(map[string]config.CommandDefaults{"town": town, "user": user})
+	}
+
+	merged := config.MergeCommandDefaults(town, user)
+	if len(merged) == 0 {
+		fmt.Println("No command defaults.")
+		fmt.Printf("\nSet one with: %s\n", style.Dim.Render("gt config defaults set <command> <flag>=<value>"))
+		return nil
+	}
+	for _, command := range merged.Commands() {
+		fmt.Printf("  %s\n", style.Bold.Render(command))
+		flags := make([]string, 0, len(merged[command]))
+		for flag := range merged[command] {
+			flags = append(flags, flag)
+		}
+		sort.Strings(flags)
+		for _, flag := range flags {
+			source := "town"
+			if _, ok := user[command][flag]; ok {
+				source = "user"
+			}
+			fmt.Printf("    --%s=%s %s\n", flag, merged[command][flag], style.Dim.Render("("+source+")"))
+		}
+	}
+	return nil
+}
+
+func runConfigDefaultsSet(cmd *cobra.Command, args []string) error {
+	command := args[0]
+	target, err := lookupDefaultsCommand(command)
+	if err != nil {
+		return err
+	}
+	values := make(map[string]string)
+	for _, arg := range args[1:] {
+		flag, value, ok := strings.Cut(arg, "=")
+		flag = strings.TrimPrefix(flag, "--")
+		if !ok || flag == "" {
+			return fmt.Errorf("expected <flag>=<value>, got %q", arg)
+		}
+		if target != nil && target.Flags().Lookup(flag) == nil {
+			return fmt.Errorf("'%s' has no --%s flag", command, flag)
+		}
+		values[flag] = value
+	}
+
+	return updateCommandDefaults(func(d config.CommandDefaults) bool {
+		for flag, value := range values {
+			d.Set(command, flag, value)
+		}
+		return true
+	})
+}
+
+func runConfigDefaultsUnset(cmd *cobra.Command, args []string) error {
+	command, flags := args[0], args[1:]
+	return updateCommandDefaults(func(d config.CommandDefaults) bool {
+		if len(flags) == 0 {
+			return d.Unset(command, "")
+		}
+		changed := false
+		for _, flag := range flags {
+			if d.Unset(command, strings.TrimPrefix(flag, "--")) {
+				changed = true
+			}
+		}
+		return changed
+	})
+}
+
+// lookupDefaultsCommand resolves a command path to the command it names,
+// or nil for "*".
+func lookupDefaultsCommand(path string) (*cobra.Command, error) {
+	if path == config.AllCommands {
+		return nil, nil
+	}
+	target, rest, err := rootCmd.Find(strings.Fields(path))
+	if err != nil || len(rest) > 0 || target == rootCmd {
+		return nil, fmt.Errorf("unknown command %q", path)
+	}
+	if got := commandPath(target); got != path {
+		return nil, fmt.Errorf("use the full command name %q, not %q", got, path)
+	}
+	return target, nil
+}
+
+// updateCommandDefaults applies change to the user's or, with --town, the
+// town's defaults and saves them if it reports a change.
+func updateCommandDefaults(change func(config.CommandDefaults) bool) error {
+	if !configDefaultsTown {
+		path := config.UserDefaultsPath()
+		d, err := config.LoadCommandDefaults(path)
+		if err != nil {
+			return err
+		}
+		if !change(d) {
+			fmt.Printf("%s Nothing to change\n", style.Dim.Render("•"))
+			return nil
+		}
+		if err := config.SaveCommandDefaults(path, d); err != nil {
+			return err
+		}
+		fmt.Printf("%s Updated %s\n", style.SuccessPrefix, path)
+		return nil
+	}
+
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	path := config.TownSettingsPath(townRoot)
+	settings, err := config.LoadOrCreateTownSettings(path)
+	if err != nil {
+		return fmt.Errorf("loading settings: %w", err)
+	}
+	if settings.CommandDefaults == nil {
+		settings.CommandDefaults = config.CommandDefaults{}
+	}
+	if !change(settings.CommandDefaults) {
+		fmt.Printf("%s Nothing to change\n", style.Dim.Render("•"))
+		return nil
+	}
+	if err := config.SaveTownSettings(path, settings); err != nil {
+		return fmt.Errorf("saving settings: %w", err)
+	}
+	fmt.Printf("%s Updated %s\n", style.SuccessPrefix, path)
+	return nil
+}
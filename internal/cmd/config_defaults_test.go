@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func TestApplyCommandDefaults(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Chdir(t.TempDir()) // Outside any town: user defaults only
+	d := config.CommandDefaults{
+		"*":     {"json": "true"},
+		"ready": {"group": "mine", "rig": "api"},
+	}
+	if err := config.SaveCommandDefaults(config.UserDefaultsPath(), d); err != nil {
+		t.Fatal(err)
+	}
+
+	root := &cobra.Command{Use: "gt"}
+	var group, rigName string
+	var jsonOut bool
+	ready := &cobra.Command{Use: "ready", Run: func(*cobra.Command, []string) {}}
+	ready.Flags().StringVar(&group, "group", "", "")
+	ready.Flags().StringVar(&rigName, "rig", "", "")
+	ready.Flags().BoolVar(&jsonOut, "json", false, "")
+	root.AddCommand(ready)
+
+	if err := ready.Flags().Parse([]string{"--rig", "web"}); err != nil {
+		t.Fatal(err)
+	}
+	applyCommandDefaults(ready)
+	if group != "mine" || !jsonOut {
+		t.Errorf("defaults not applied: group=%q json=%v", group, jsonOut)
+	}
+	if rigName != "web" {
+		t.Errorf("default overrode explicit --rig: %q", rigName)
+	}
+
+	group = ""
+	t.Setenv(config.NoDefaultsEnv, "1")
+	ready.Flags().Lookup("group").Changed = false
+	applyCommandDefaults(ready)
+	if group != "" {
+		t.Errorf("%s=1 still applied defaults: group=%q", config.NoDefaultsEnv, group)
+	}
+}
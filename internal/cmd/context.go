@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/attach"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/ctxbundle"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/memo"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Context command flags
+var (
+	contextJSON    bool
+	contextCommits int
+	contextNoGit   bool
+)
+
+var contextCmd = &cobra.Command{
+	Use:     "context <issue>",
+	GroupID: GroupWork,
+	Short:   "Bundle an issue's context for an agent prompt",
+	Long: `Produce one structured bundle with everything needed to pick up an issue:
+
+  - The issue: title, status, priority, labels, description
+  - Comments
+  - Dependencies and dependents, and whether the issue is blocked
+  - Merge requests filed for it (open and closed)
+  - Relevant file paths: path:/file: labels, plus files its merged MRs touched
+  - Recent related commits: commits mentioning the issue, then commits
+    touching the relevant files
+  - Handoff notes in the assignee's mailbox that mention the issue
+  - Decisions from the memo log linked to the issue or matching its
+    labels and title (see 'gt memo')
+  - Files attached with 'gt attach'
+
+Output is Markdown, ready to inject into a prompt; --json emits the same
+bundle as structured data.
+
+Examples:
+  gt context gt-abc                 # Markdown
+  gt context gt-abc --json          # For runtime wrappers
+  gt context gt-abc --commits 20    # More history
+  gt context hq-xyz --no-git        # Skip repository lookups`,
+	Args: cobra.ExactArgs(1),
+	RunE: runContext,
+}
+
+func init() {
+	contextCmd.Flags().BoolVar(&contextJSON, "json", false, "Output as JSON")
+	contextCmd.Flags().IntVar(&contextCommits, "commits", 10, "Maximum related commits to include")
+	contextCmd.Flags().BoolVar(&contextNoGit, "no-git", false, "Skip files and commits from the rig's repository")
+
+	rootCmd.AddCommand(contextCmd)
+}
+
+func runContext(cmd *cobra.Command, args []string) error {
+	issueID := args[0]
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	bd := beads.New(resolveBeadDir(issueID))
+	issue, err := bd.Show(issueID)
+	if err != nil {
+		return fmt.Errorf("fetching %s: %w", issueID, err)
+	}
+	bundle := ctxbundle.New(issue, time.Now())
+
+	// Older bd versions lack comments; the rest of the bundle still stands
+	if comments, err := bd.Comments(issueID); err == nil {
+		bundle.Comments = comments
+	}
+
+	var mrs []*beads.Issue
+	for _, status := range []string{"open", "closed"} {
+		if list, err := bd.List(beads.ListOptions{Status: status, Type: "merge-request", Priority: -1}); err == nil {
+			mrs = append(mrs, list...)
+		}
+	}
+	bundle.MergeRequests = ctxbundle.MergeRequestsFor(issueID, mrs)
+
+	// Town-level beads have no rig or repository
+	var rigName string
+	if _, r, err := getRigForBead(issueID); err == nil {
+		rigName = r.Name
+		if !contextNoGit {
+			addContextFromGit(bundle, r)
+		}
+	}
+
+	if issue.Assignee != "" {
+		bundle.AddHandoffs(handoffNotes(issue.Assignee, townRoot))
+	}
+	bundle.Decisions = relevantDecisions(townRoot, rigName, bundle.Issue)
+	bundle.Attachments = contextAttachments(townRoot, issue.Description)
+	bundle.Issue.Description = beads.SetDescriptionSection(bundle.Issue.Description, attach.Section, "")
+
+	if contextJSON {
+		return outputJSON(bundle)
+	}
+	fmt.Print(bundle.Markdown())
+	return nil
+}
+
+// addContextFromGit adds the files the issue's merged MRs touched and the
+// commits related to the issue, from the rig's clone.
+func addContextFromGit(bundle *ctxbundle.Bundle, r *rig.Rig) {
+	g := git.NewGit(constants.RigMayorPath(r.Path))
+	ref := "origin/" + r.DefaultBranch()
+	if _, err := g.Rev(ref); err != nil {
+		ref = "HEAD"
+	}
+
+	for _, mr := range bundle.MergeRequests {
+		if !mr.Merged() {
+			continue
+		}
+		changes, err := g.ChangedFiles(mr.MergeCommit+"^1", mr.MergeCommit)
+		if err != nil {
+			continue
+		}
+		for _, c := range changes {
+			bundle.AddFiles(c.Path)
+		}
+	}
+
+	seen := make(map[string]bool)
+	add := func(commits []git.CommitInfo) {
+		for _, c := range commits {
+			if len(bundle.Commits) >= contextCommits || seen[c.SHA] {
+				continue
+			}
+			seen[c.SHA] = true
+			bundle.Commits = append(bundle.Commits, c)
+		}
+	}
+	if commits, err := g.Log(ref, contextCommits, bundle.Issue.ID); err == nil {
+		add(commits)
+	}
+	if len(bundle.Commits) < contextCommits && len(bundle.Files) > 0 {
+		if commits, err := g.Log(ref, contextCommits, "", bundle.Files...); err == nil {
+			add(commits)
+		}
+	}
+}
+
+// contextAttachments lists the files attached to an issue, with their
+// local paths when the attachment store is on disk.
+func contextAttachments(townRoot, description string) []ctxbundle.Attachment {
+	list := attach.Parse(description)
+	if len(list) == 0 {
+		return nil
+	}
+	store, _ := attach.OpenTown(townRoot)
+	var out []ctxbundle.Attachment
+	for _, a := range list {
+		item := ctxbundle.Attachment{Name: a.Name, Digest: a.Digest, Size: a.Size, Type: a.Type}
+		if store != nil {
+			item.Path = store.LocalPath(a)
+		}
+		out = append(out, item)
+	}
+	return out
+}
+
+// contextMaxDecisions caps the memos included in a context bundle.
+const contextMaxDecisions = 5
+
+// relevantDecisions returns the memos relevant to an issue: those linked to
+// it, then those whose topics match its labels or title.
+func relevantDecisions(townRoot, rigName string, issue ctxbundle.Issue) []ctxbundle.Decision {
+	memos, err := memo.NewStore(townRoot).Relevant(memo.Query{
+		Issue:  issue.ID,
+		Rig:    rigName,
+		Labels: issue.Labels,
+		Text:   issue.Title + "\n" + issue.Description,
+	}, contextMaxDecisions)
+	if err != nil {
+		return nil
+	}
+	var decisions []ctxbundle.Decision
+	for _, m := range memos {
+		decisions = append(decisions, ctxbundle.Decision{ID: m.ID, Title: m.Title, Topics: m.Topics, Body: m.Body})
+	}
+	return decisions
+}
+
+// handoffNotes returns the handoff messages in an agent's mailbox.
+func handoffNotes(address, townRoot string) []ctxbundle.Handoff {
+	messages, err := mail.NewMailboxFromAddress(address, townRoot).List()
+	if err != nil {
+		return nil
+	}
+	var notes []ctxbundle.Handoff
+	for _, msg := range messages {
+		if !containsHandoff(msg.Subject) {
+			continue
+		}
+		note := ctxbundle.Handoff{From: msg.From, Subject: msg.Subject, Body: msg.Body}
+		if !msg.Timestamp.IsZero() {
+			note.Date = msg.Timestamp.Format(time.RFC3339)
+		}
+		notes = append(notes, note)
+	}
+	return notes
+}
@@ -17,6 +17,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/goal"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/tui/convoy"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -74,7 +75,11 @@ var (
 	convoyCloseReason  string
 	convoyCloseNotify  string
 	convoyCloseForce   bool
+	convoyCloseNoRetro bool
 	convoyCheckDryRun  bool
+	convoyCreateWait   bool
+	convoyGoal         string
+	convoyWaitTimeout  time.Duration
 )
 
 var convoyCmd = &cobra.Command{
@@ -134,7 +139,11 @@ Examples:
   gt convoy create "Release prep" gt-abc --notify           # defaults to mayor/
   gt convoy create "Release prep" gt-abc --notify ops/      # notify ops/
   gt convoy create "Feature rollout" gt-a gt-b --owner mayor/ --notify ops/
-  gt convoy create "Feature rollout" gt-a gt-b gt-c --molecule mol-release`,
+  gt convoy create "Feature rollout" gt-a gt-b gt-c --molecule mol-release
+  gt convoy create "Hotfix" gt-abc --wait --timeout 4h
+
+--wait blocks until every tracked issue is closed. Exit codes: 0 complete,
+2 timed out, 3 the convoy was closed with issues still open.`,
 	Args: cobra.MinimumNArgs(1),
 	RunE: runConvoyCreate,
 }
@@ -244,6 +253,9 @@ func init() {
 	convoyCreateCmd.Flags().StringVar(&convoyOwner, "owner", "", "Owner who requested convoy (gets completion notification)")
 	convoyCreateCmd.Flags().StringVar(&convoyNotify, "notify", "", "Additional address to notify on completion (default: mayor/ if flag used without value)")
 	convoyCreateCmd.Flags().Lookup("notify").NoOptDefVal = "mayor/"
+	convoyCreateCmd.Flags().StringVar(&convoyGoal, "goal", "", "Link the convoy to a town goal (see 'gt goal')")
+	convoyCreateCmd.Flags().BoolVar(&convoyCreateWait, "wait", false, "Wait until every tracked issue is closed")
+	convoyCreateCmd.Flags().DurationVar(&convoyWaitTimeout, "timeout", 24*time.Hour, "With --wait, give up after this long (0 = never)")
 
 	// Status flags
 	convoyStatusCmd.Flags().BoolVar(&convoyStatusJSON, "json", false, "Output as JSON")
@@ -267,6 +279,7 @@ func init() {
 	convoyCloseCmd.Flags().StringVar(&convoyCloseReason, "reason", "", "Reason for closing the convoy")
 	convoyCloseCmd.Flags().StringVar(&convoyCloseNotify, "notify", "", "Agent to notify on close (e.g., mayor/)")
 	convoyCloseCmd.Flags().BoolVarP(&convoyCloseForce, "force", "f", false, "Close even if tracked issues are still open")
+	convoyCloseCmd.Flags().BoolVar(&convoyCloseNoRetro, "no-retro", false, "Don't save a retrospective memo")
 
 	// Add subcommands
 	convoyCmd.AddCommand(convoyCreateCmd)
@@ -348,6 +361,16 @@ func runConvoyCreate(cmd *cobra.Command, args []string) error {
 	if beads.NeedsForceForID(convoyID) {
 		createArgs = append(createArgs, "--force")
 	}
+	if convoyGoal != "" {
+		townRoot, err := workspace.FindFromCwdOrError()
+		if err != nil {
+			return fmt.Errorf("not in a Gas Town workspace: %w", err)
+		}
+		if err := requireGoal(townRoot, convoyGoal); err != nil {
+			return err
+		}
+		createArgs = append(createArgs, "--labels="+goal.Label(convoyGoal))
+	}
 
 	createCmd := exec.Command("bd", createArgs...)
 	createCmd.Dir = townBeads
@@ -399,12 +422,91 @@ func runConvoyCreate(cmd *cobra.Command, args []string) error {
 	if convoyMolecule != "" {
 		fmt.Printf("  Molecule: %s\n", convoyMolecule)
 	}
+	if convoyGoal != "" {
+		fmt.Printf("  Goal:     %s\n", convoyGoal)
+	}
 
 	fmt.Printf("\n  %s\n", style.Dim.Render("Convoy auto-closes when all tracked issues complete"))
 
+	if convoyCreateWait {
+		if trackedCount == 0 {
+			return fmt.Errorf("--wait: convoy %s tracks no issues", convoyID)
+		}
+		return waitForConvoy(townBeads, convoyID)
+	}
 	return nil
 }
 
+// waitForConvoy blocks until every issue the convoy tracks is closed,
+// printing progress as issues close.
+func waitForConvoy(townBeads, convoyID string) error {
+	fmt.Printf("\n%s Waiting for %s to complete...\n", style.Dim.Render("◌"), convoyID)
+	start := time.Now()
+	lastClosed := -1
+	err := pollUntil(30*time.Second, convoyWaitTimeout, func() (bool, error) {
+		tracked, err := getTrackedIssues(townBeads, convoyID)
+		if err != nil {
+			return false, err
+		}
+		status, err := convoyStatus(townBeads, convoyID)
+		if err != nil {
+			return false, err
+		}
+		done, failure, closed := convoyWaitOutcome(status, tracked)
+		if closed != lastClosed {
+			fmt.Printf("  %s %d/%d closed\n", style.Dim.Render(time.Now().Format("15:04:05")), closed, len(tracked))
+			lastClosed = closed
+		}
+		if failure != "" {
+			return false, &waitFailedError{reason: failure}
+		}
+		return done, nil
+	})
+	if err == nil {
+		fmt.Printf("%s Convoy %s complete (after %s)\n", style.Bold.Render("✓"), convoyID, time.Since(start).Round(time.Second))
+	}
+	return waitExit(err, convoyID, convoyWaitTimeout)
+}
+
+// convoyStatus returns the convoy's bead status.
+func convoyStatus(townBeads, convoyID string) (string, error) {
+	showCmd := exec.Command("bd", "show", convoyID, "--json")
+	showCmd.Dir = townBeads
+	out, err := showCmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("convoy '%s' not found", convoyID)
+	}
+	var convoys []struct {
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(out, &convoys); err != nil {
+		return "", fmt.Errorf("parsing convoy data: %w", err)
+	}
+	if len(convoys) == 0 {
+		return "", fmt.Errorf("convoy '%s' not found", convoyID)
+	}
+	return convoys[0].Status, nil
+}
+
+// convoyWaitOutcome reports whether a convoy being waited on is complete
+// and how many of its tracked issues are closed. A convoy closed while
+// issues are still open (abandoned with --force) has failed.
+func convoyWaitOutcome(status string, tracked []trackedIssueInfo) (done bool, failure string, closed int) {
+	for _, t := range tracked {
+		if t.Status == "closed" || t.Status == "tombstone" {
+			closed++
+		}
+	}
+	open := len(tracked) - closed
+	switch {
+	case open == 0:
+		return true, "", closed
+	case status == "closed":
+		return true, fmt.Sprintf("convoy closed with %d issue(s) still open", open), closed
+	}
+	return false, "", closed
+}
+
 func runConvoyAdd(cmd *cobra.Command, args []string) error {
 	convoyID := args[0]
 	issuesToAdd := args[1:]
@@ -751,6 +853,10 @@ func runConvoyClose(cmd *cobra.Command, args []string) error {
 		notifyConvoyCompletion(townBeads, convoyID, convoy.Title)
 	}
 
+	if !convoyCloseNoRetro {
+		recordRetro(convoyID)
+	}
+
 	return nil
 }
 
@@ -795,9 +901,7 @@ func runConvoyStranded(cmd *cobra.Command, args []string) error {
 	}
 
 	if convoyStrandedJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(stranded)
+		return outputJSON(stranded)
 	}
 
 	if len(stranded) == 0 {
@@ -1022,6 +1126,7 @@ func checkAndCloseCompletedConvoys(townBeads string, dryRun bool) ([]struct{ ID,
 
 			// Check if convoy has notify address and send notification
 			notifyConvoyCompletion(townBeads, convoy.ID, convoy.Title)
+			recordRetro(convoy.ID)
 		}
 	}
 
@@ -1167,9 +1272,7 @@ func runConvoyStatus(cmd *cobra.Command, args []string) error {
 			Completed: completed,
 			Total:     len(tracked),
 		}
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(out)
+		return outputJSON(out)
 	}
 
 	// Human-readable output
@@ -1245,9 +1348,7 @@ func showAllConvoyStatus(townBeads string) error {
 	}
 
 	if convoyStatusJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(convoys)
+		return outputJSON(convoys)
 	}
 
 	fmt.Printf("%s\n\n", style.Bold.Render("Active Convoys"))
@@ -1330,9 +1431,7 @@ func runConvoyList(cmd *cobra.Command, args []string) error {
 				Total:     len(tracked),
 			})
 		}
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(enriched)
+		return outputJSON(enriched)
 	}
 
 	if len(convoys) == 0 {
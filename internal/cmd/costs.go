@@ -3,6 +3,7 @@ package cmd
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io/fs"
@@ -28,6 +29,7 @@ var (
 	costsWeek    bool
 	costsByRole  bool
 	costsByRig   bool
+	costsByModel bool
 	costsVerbose bool
 
 	// Record subcommand flags
@@ -58,6 +60,7 @@ Examples:
   gt costs --week       # This week's costs from digest beads + today's log
   gt costs --by-role    # Breakdown by role (polecat, witness, etc.)
   gt costs --by-rig     # Breakdown by rig
+  gt costs --by-model   # Breakdown by model (and agent routing)
   gt costs --json       # Output as JSON
   gt costs -v           # Show debug output for failures
 
@@ -134,6 +137,7 @@ func init() {
 	costsCmd.Flags().BoolVar(&costsWeek, "week", false, "Show this week's total from session events")
 	costsCmd.Flags().BoolVar(&costsByRole, "by-role", false, "Show breakdown by role")
 	costsCmd.Flags().BoolVar(&costsByRig, "by-rig", false, "Show breakdown by rig")
+	costsCmd.Flags().BoolVar(&costsByModel, "by-model", false, "Show breakdown by model")
 	costsCmd.Flags().BoolVarP(&costsVerbose, "verbose", "v", false, "Show debug output for failures")
 
 	// Add record subcommand
@@ -172,6 +176,8 @@ type CostEntry struct {
 	StartedAt time.Time `json:"started_at"`
 	EndedAt   time.Time `json:"ended_at"`
 	WorkItem  string    `json:"work_item,omitempty"`
+	Model     string    `json:"model,omitempty"`
+	Agent     string    `json:"agent,omitempty"`
 }
 
 // CostsOutput is the JSON output structure.
@@ -180,6 +186,7 @@ type CostsOutput struct {
 	Total    float64            `json:"total_usd"`
 	ByRole   map[string]float64 `json:"by_role,omitempty"`
 	ByRig    map[string]float64 `json:"by_rig,omitempty"`
+	ByModel  map[string]float64 `json:"by_model,omitempty"`
 	Period   string             `json:"period,omitempty"`
 }
 
@@ -212,6 +219,7 @@ type TranscriptUsage struct {
 // TokenUsage aggregates token usage across a session.
 type TokenUsage struct {
 	Model                    string
+	RateLimited              bool // the session's last response was a provider rate limit
 	InputTokens              int
 	CacheCreationInputTokens int
 	CacheReadInputTokens     int
@@ -338,8 +346,8 @@ func runCostsFromLedger() error {
 		// Also include today's wisps (not yet digested)
 		todayEntries, _ := querySessionCostEntries(now)
 		entries = append(entries, todayEntries...)
-	} else if costsByRole || costsByRig {
-		// When using --by-role or --by-rig without time filter, default to today
+	} else if costsByRole || costsByRig || costsByModel {
+		// When using a breakdown flag without time filter, default to today
 		// (querying all historical events would be expensive and likely empty)
 		entries, err = querySessionCostEntries(now)
 		if err != nil {
@@ -360,6 +368,7 @@ func runCostsFromLedger() error {
 	var total float64
 	byRole := make(map[string]float64)
 	byRig := make(map[string]float64)
+	byModel := make(map[string]float64)
 
 	for _, entry := range entries {
 		total += entry.CostUSD
@@ -367,6 +376,7 @@ func runCostsFromLedger() error {
 		if entry.Rig != "" {
 			byRig[entry.Rig] += entry.CostUSD
 		}
+		byModel[modelKey(entry)] += entry.CostUSD
 	}
 
 	// Build output
@@ -380,6 +390,9 @@ func runCostsFromLedger() error {
 	if costsByRig {
 		output.ByRig = byRig
 	}
+	if costsByModel {
+		output.ByModel = byModel
+	}
 
 	// Set period label
 	if costsToday {
@@ -780,6 +793,11 @@ func parseTranscriptUsage(transcriptPath string) (*TokenUsage, error) {
 			continue // Skip malformed lines
 		}
 
+		// Track whether the latest response was a rate-limit error
+		if msg.Type == "assistant" {
+			usage.RateLimited = isRateLimitLine(line)
+		}
+
 		// Only process assistant messages with usage info
 		if msg.Type != "assistant" || msg.Message == nil || msg.Message.Usage == nil {
 			continue
@@ -826,25 +844,59 @@ func calculateCost(usage *TokenUsage) float64 {
 	return inputCost + cacheReadCost + cacheCreateCost + outputCost
 }
 
+// rateLimitMarkers identify provider rate-limit errors in transcript lines.
+var rateLimitMarkers = [][]byte{[]byte(`rate_limit_error`), []byte(`API Error: 429`)}
+
+// isRateLimitLine reports whether a transcript line records a rate-limit error.
+func isRateLimitLine(line []byte) bool {
+	for _, m := range rateLimitMarkers {
+		if bytes.Contains(line, m) {
+			return true
+		}
+	}
+	return false
+}
+
 // extractCostFromWorkDir extracts cost from Claude Code transcript for a working directory.
 // This reads the most recent transcript file and sums all token usage.
 func extractCostFromWorkDir(workDir string) (float64, error) {
+	usage, err := extractUsageFromWorkDir(workDir)
+	if err != nil {
+		return 0, err
+	}
+	return calculateCost(usage), nil
+}
+
+// extractUsageFromWorkDir reads token usage from the most recent Claude Code
+// transcript for a working directory.
+func extractUsageFromWorkDir(workDir string) (*TokenUsage, error) {
 	projectDir, err := getClaudeProjectDir(workDir)
 	if err != nil {
-		return 0, fmt.Errorf("getting project dir: %w", err)
+		return nil, fmt.Errorf("getting project dir: %w", err)
 	}
 
 	transcriptPath, err := findLatestTranscript(projectDir)
 	if err != nil {
-		return 0, fmt.Errorf("finding transcript: %w", err)
+		return nil, fmt.Errorf("finding transcript: %w", err)
 	}
 
 	usage, err := parseTranscriptUsage(transcriptPath)
 	if err != nil {
-		return 0, fmt.Errorf("parsing transcript: %w", err)
+		return nil, fmt.Errorf("parsing transcript: %w", err)
 	}
+	return usage, nil
+}
 
-	return calculateCost(usage), nil
+// modelKey labels an entry for the by-model breakdown.
+func modelKey(e CostEntry) string {
+	model := e.Model
+	if model == "" {
+		model = "unknown"
+	}
+	if e.Agent != "" {
+		model += " (" + e.Agent + ")"
+	}
+	return model
 }
 
 // getTmuxSessionWorkDir gets the current working directory of a tmux session.
@@ -858,9 +910,7 @@ func getTmuxSessionWorkDir(session string) (string, error) {
 }
 
 func outputCostsJSON(output CostsOutput) error {
-	enc := json.NewEncoder(os.Stdout)
-	enc.SetIndent("", "  ")
-	return enc.Encode(output)
+	return outputJSON(output)
 }
 
 func outputCostsHuman(costs []SessionCost, total float64) error {
@@ -935,6 +985,14 @@ func outputLedgerHuman(output CostsOutput, entries []CostEntry) error {
 		}
 	}
 
+	// By model breakdown
+	if len(output.ByModel) > 0 {
+		fmt.Printf("\n%s\n", style.Bold.Render("By Model:"))
+		for model, cost := range output.ByModel {
+			fmt.Printf("  %-32s $%.2f\n", model, cost)
+		}
+	}
+
 	// Session count
 	fmt.Printf("\n%s %d sessions\n", style.Dim.Render("Entries:"), len(entries))
 
@@ -950,6 +1008,8 @@ type CostLogEntry struct {
 	CostUSD   float64   `json:"cost_usd"`
 	EndedAt   time.Time `json:"ended_at"`
 	WorkItem  string    `json:"work_item,omitempty"`
+	Model     string    `json:"model,omitempty"`
+	Agent     string    `json:"agent,omitempty"`
 }
 
 // getCostsLogPath returns the path to the costs log file (~/.gt/costs.jsonl).
@@ -997,20 +1057,51 @@ func runCostsRecord(cmd *cobra.Command, args []string) error {
 
 	// Extract cost from Claude transcript
 	var cost float64
+	var usage *TokenUsage
 	if workDir != "" {
 		var err error
-		cost, err = extractCostFromWorkDir(workDir)
+		usage, err = extractUsageFromWorkDir(workDir)
 		if err != nil {
 			if costsVerbose {
 				fmt.Fprintf(os.Stderr, "[costs] could not extract cost from transcript: %v\n", err)
 			}
-			cost = 0.0
+		} else {
+			cost = calculateCost(usage)
 		}
 	}
 
 	// Parse session name
 	role, rig, worker := parseSessionName(session)
 
+	// Attribute the session to its issue for per-issue cost reports
+	workItem := recordWorkItem
+	if workItem == "" {
+		workItem = os.Getenv("GT_ISSUE")
+	}
+	if workItem == "" {
+		if issue, err := tmux.NewTmux().GetEnvironment(session, "GT_ISSUE"); err == nil {
+			workItem = issue
+		}
+	}
+
+	agent := os.Getenv("GT_AGENT_ROUTE")
+	if agent == "" {
+		agent = os.Getenv("GT_AGENT")
+	}
+	var model string
+	if usage != nil {
+		model = usage.Model
+		// Route the role's next sessions to fallbacks while this agent cools down
+		if usage.RateLimited && agent != "" {
+			if townRoot, err := workspace.FindFromCwdOrError(); err == nil {
+				if until, err := config.MarkAgentRateLimited(townRoot, agent, time.Now()); err == nil {
+					fmt.Printf("%s %s rate limited; routing to fallbacks until %s\n",
+						style.Warning.Render("⚠"), agent, until.Format("15:04"))
+				}
+			}
+		}
+	}
+
 	// Build log entry
 	entry := CostLogEntry{
 		SessionID: session,
@@ -1019,7 +1110,9 @@ func runCostsRecord(cmd *cobra.Command, args []string) error {
 		Worker:    worker,
 		CostUSD:   cost,
 		EndedAt:   time.Now(),
-		WorkItem:  recordWorkItem,
+		WorkItem:  workItem,
+		Model:     model,
+		Agent:     agent,
 	}
 
 	// Marshal to JSON
@@ -1052,10 +1145,10 @@ func runCostsRecord(cmd *cobra.Command, args []string) error {
 	}
 
 	// Output confirmation (silent if cost is zero and no work item)
-	if cost > 0 || recordWorkItem != "" {
+	if cost > 0 || workItem != "" {
 		fmt.Printf("%s Recorded $%.2f for %s", style.Success.Render("✓"), cost, session)
-		if recordWorkItem != "" {
-			fmt.Printf(" (work: %s)", recordWorkItem)
+		if workItem != "" {
+			fmt.Printf(" (work: %s)", workItem)
 		}
 		fmt.Println()
 	}
@@ -1131,6 +1224,7 @@ type CostDigest struct {
 	Sessions     []CostEntry        `json:"sessions"`
 	ByRole       map[string]float64 `json:"by_role"`
 	ByRig        map[string]float64 `json:"by_rig,omitempty"`
+	ByModel      map[string]float64 `json:"by_model,omitempty"`
 }
 
 // runCostsDigest aggregates session cost entries into a daily digest bead.
@@ -1169,6 +1263,7 @@ func runCostsDigest(cmd *cobra.Command, args []string) error {
 		Sessions: costEntries,
 		ByRole:   make(map[string]float64),
 		ByRig:    make(map[string]float64),
+		ByModel:  make(map[string]float64),
 	}
 
 	for _, e := range costEntries {
@@ -1178,6 +1273,7 @@ func runCostsDigest(cmd *cobra.Command, args []string) error {
 		if e.Rig != "" {
 			digest.ByRig[e.Rig] += e.CostUSD
 		}
+		digest.ByModel[modelKey(e)] += e.CostUSD
 	}
 
 	if digestDryRun {
@@ -1263,6 +1359,8 @@ func querySessionCostEntries(targetDate time.Time) ([]CostEntry, error) {
 			CostUSD:   logEntry.CostUSD,
 			EndedAt:   logEntry.EndedAt,
 			WorkItem:  logEntry.WorkItem,
+			Model:     logEntry.Model,
+			Agent:     logEntry.Agent,
 		})
 	}
 
@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -112,3 +114,47 @@ func TestDeriveSessionName(t *testing.T) {
 		})
 	}
 }
+
+func TestParseTranscriptUsage_RateLimited(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  bool
+	}{
+		{
+			name: "ends on rate limit",
+			lines: []string{
+				`{"type":"assistant","message":{"model":"claude-opus","usage":{"output_tokens":10}}}`,
+				`{"type":"assistant","isApiErrorMessage":true,"message":{"content":[{"type":"text","text":"API Error: 429 {\"type\":\"error\",\"error\":{\"type\":\"rate_limit_error\"}}"}]}}`,
+			},
+			want: true,
+		},
+		{
+			name: "recovered after rate limit",
+			lines: []string{
+				`{"type":"assistant","message":{"content":[{"type":"text","text":"API Error: 429"}]}}`,
+				`{"type":"assistant","message":{"model":"claude-opus","usage":{"output_tokens":10}}}`,
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "transcript.jsonl")
+			if err := os.WriteFile(path, []byte(strings.Join(tt.lines, "\n")+"\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			usage, err := parseTranscriptUsage(path)
+			if err != nil {
+				t.Fatalf("parseTranscriptUsage: %v", err)
+			}
+			if usage.RateLimited != tt.want {
+				t.Errorf("RateLimited = %v, want %v", usage.RateLimited, tt.want)
+			}
+			if usage.Model != "claude-opus" {
+				t.Errorf("Model = %q, want claude-opus", usage.Model)
+			}
+		})
+	}
+}
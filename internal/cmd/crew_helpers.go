@@ -14,6 +14,7 @@ import (
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/ui"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
@@ -167,6 +168,7 @@ func execAgent(cfg *config.RuntimeConfig, prompt string) error {
 	if prompt != "" {
 		args = append(args, prompt)
 	}
+	ui.StopASCIIFilter() // Flush filtered output before the exec replaces us
 	return syscall.Exec(agentPath, args, os.Environ())
 }
 
@@ -191,6 +193,7 @@ func execRuntime(prompt, rigPath, configDir string) error {
 		env = append(env, fmt.Sprintf("%s=%s", runtimeConfig.Session.ConfigDirEnv, configDir))
 	}
 
+	ui.StopASCIIFilter() // Flush filtered output before the exec replaces us
 	return syscall.Exec(binPath, args, env)
 }
 
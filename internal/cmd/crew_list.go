@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 
@@ -84,9 +83,7 @@ func runCrewList(cmd *cobra.Command, args []string) error {
 	}
 
 	if crewJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(items)
+		return outputJSON(items)
 	}
 
 	// Text output
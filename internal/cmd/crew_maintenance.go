@@ -1,10 +1,8 @@
 package cmd
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
-	"os"
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/crew"
@@ -108,9 +106,7 @@ func runCrewPristine(cmd *cobra.Command, args []string) error {
 	}
 
 	if crewJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(results)
+		return outputJSON(results)
 	}
 
 	// Text output
@@ -14,12 +14,15 @@ import (
 )
 
 var (
-	dashboardPort int
-	dashboardOpen bool
+	dashboardPort      int
+	dashboardOpen      bool
+	dashboardObserver  bool
+	dashboardPublicURL string
 )
 
 var dashboardCmd = &cobra.Command{
 	Use:     "dashboard",
+	Aliases: []string{"serve"},
 	GroupID: GroupDiag,
 	Short:   "Start the convoy tracking web dashboard",
 	Long: `Start a web server that displays the convoy tracking dashboard.
@@ -30,16 +33,28 @@ The dashboard shows real-time convoy status with:
 - Last activity indicator (green/yellow/red)
 - Auto-refresh every 30 seconds via htmx
 
+With --observer, the dashboard is read-only and requires the town's viewer
+token: commands, mail, and issue creation are hidden and their endpoints
+refused. Hand out links with 'gt share status'; 'gt share status --rotate'
+revokes them.
+
+Inside a town the server also exposes a typed JSON API under /api/v1/:
+work queries, merge queues, agent start/stop, and a streamed event feed
+(see docs/api.md). pkg/client is its Go client. Observers may only read.
+
 Example:
   gt dashboard              # Start on default port 8080
   gt dashboard --port 3000  # Start on port 3000
-  gt dashboard --open       # Start and open browser`,
+  gt dashboard --open       # Start and open browser
+  gt serve --observer --public-url https://gt.example.com`,
 	RunE: runDashboard,
 }
 
 func init() {
 	dashboardCmd.Flags().IntVar(&dashboardPort, "port", 8080, "HTTP port to listen on")
 	dashboardCmd.Flags().BoolVar(&dashboardOpen, "open", false, "Open browser automatically")
+	dashboardCmd.Flags().BoolVar(&dashboardObserver, "observer", false, "Serve a read-only view to viewer-token holders")
+	dashboardCmd.Flags().StringVar(&dashboardPublicURL, "public-url", "", "URL viewers reach this server at, for share links (observer mode)")
 	rootCmd.AddCommand(dashboardCmd)
 }
 
@@ -49,6 +64,9 @@ func runDashboard(cmd *cobra.Command, args []string) error {
 	var err error
 
 	townRoot, wsErr := workspace.FindFromCwdOrError()
+	if dashboardObserver && wsErr != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", wsErr)
+	}
 	if wsErr != nil {
 		// No workspace - run in setup mode
 		handler, err = web.NewSetupMux()
@@ -70,7 +88,13 @@ func runDashboard(cmd *cobra.Command, args []string) error {
 			fmt.Fprintf(cmd.ErrOrStderr(), "warning: loading town settings: %v (using defaults)\n", loadErr)
 		}
 
-		handler, err = web.NewDashboardMux(fetcher, webCfg)
+		if dashboardObserver {
+			handler, err = web.NewObserverMux(fetcher, webCfg, func() (string, error) {
+				return web.LoadOrCreateViewerToken(townRoot)
+			})
+		} else {
+			handler, err = web.NewDashboardMux(fetcher, webCfg)
+		}
 		if err != nil {
 			return fmt.Errorf("creating dashboard handler: %w", err)
 		}
@@ -78,6 +102,11 @@ func runDashboard(cmd *cobra.Command, args []string) error {
 
 	// Build the URL
 	url := fmt.Sprintf("http://localhost:%d", dashboardPort)
+	if dashboardObserver {
+		if url, err = startObserver(townRoot); err != nil {
+			return err
+		}
+	}
 
 	// Open browser if requested
 	if dashboardOpen {
@@ -107,7 +136,11 @@ func runDashboard(cmd *cobra.Command, args []string) error {
     \$$     \$$$$$$         \$$$$$$  \$$   \$$  \$$$$$$     \$$     \$$$$$$  \$$      \$$ \$$   \$$
 
 `)
-	fmt.Printf("  launching dashboard at %s  •  api: %s/api/  •  ctrl+c to stop\n", url, url)
+	if dashboardObserver {
+		fmt.Printf("  launching read-only dashboard  •  share: %s  •  ctrl+c to stop\n", url)
+	} else {
+		fmt.Printf("  launching dashboard at %s  •  api: %s/api/  •  ctrl+c to stop\n", url, url)
+	}
 
 	server := &http.Server{
 		Addr:              fmt.Sprintf(":%d", dashboardPort),
@@ -120,6 +153,23 @@ func runDashboard(cmd *cobra.Command, args []string) error {
 	return server.ListenAndServe()
 }
 
+// startObserver records the observer server for 'gt share status' and
+// returns a share link to it.
+func startObserver(townRoot string) (string, error) {
+	base := dashboardPublicURL
+	if base == "" {
+		base = defaultShareBase(dashboardPort)
+	}
+	token, err := web.LoadOrCreateViewerToken(townRoot)
+	if err != nil {
+		return "", fmt.Errorf("creating viewer token: %w", err)
+	}
+	if err := web.SaveObserverState(townRoot, web.ObserverState{URL: base, StartedAt: time.Now()}); err != nil {
+		return "", fmt.Errorf("recording observer server: %w", err)
+	}
+	return web.ShareURL(base, token), nil
+}
+
 // openBrowser opens the specified URL in the default browser.
 func openBrowser(url string) {
 	var cmd *exec.Cmd
@@ -0,0 +1,538 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/deploy"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/release"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Deploy command flags
+var (
+	deployRecordURL  string
+	deployRecordNote string
+	deployRecordAt   string
+	deployRecordBy   string
+	deployRecordJSON bool
+
+	deployListEnv   string
+	deployListLimit int
+	deployListJSON  bool
+
+	deployStatusJSON bool
+	deployCheckJSON  bool
+
+	deployWebhookPort   int
+	deployWebhookSecret string
+)
+
+var deployCmd = &cobra.Command{
+	Use:     "deploy",
+	GroupID: GroupWork,
+	Short:   "Track deployments of merged work to environments",
+	RunE:    requireSubcommand,
+	Long: `Track which commits of a rig are deployed to which environments.
+
+Deployments are recorded as closed beads labeled gt:deployment, either by
+'gt deploy record' (e.g., from a deploy script) or by CI/CD posting to
+'gt deploy webhook'. A merged MR is live in an environment when its merge
+commit is contained in the commit last deployed there.
+
+Examples:
+  gt deploy record gastown prod               # Deploy of origin/<default branch>
+  gt deploy record gastown staging abc1234 --url https://ci/run/42
+  gt deploy status gastown                    # Latest deploy per environment
+  gt deploy check gt-abc                      # Is this fix live?`,
+}
+
+var deployRecordCmd = &cobra.Command{
+	Use:   "record <rig> <environment> [commit]",
+	Short: "Record a deployment",
+	Long: `Record that a commit of a rig was deployed to an environment.
+
+The commit defaults to origin/<default branch>. Any ref resolvable in the
+rig's clone is accepted and stored as its full SHA.
+
+Examples:
+  gt deploy record gastown prod
+  gt deploy record gastown prod v1.4.0 --url https://ci.example.com/run/42
+  gt deploy record gastown staging HEAD~1 --at 2026-01-02T15:04:05Z`,
+	Args: cobra.RangeArgs(2, 3),
+	RunE: runDeployRecord,
+}
+
+var deployListCmd = &cobra.Command{
+	Use:   "list <rig>",
+	Short: "List recorded deployments",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runDeployList,
+}
+
+var deployStatusCmd = &cobra.Command{
+	Use:   "status <rig>",
+	Short: "Show the latest deployment per environment",
+	Long: `Show the latest deployment per environment and how many merged MRs
+are not yet live there.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runDeployStatus,
+}
+
+var deployCheckCmd = &cobra.Command{
+	Use:   "check <bead-id>",
+	Short: "Check whether an issue's fix or an MR is live",
+	Long: `Check whether a merged MR, or the MRs that closed an issue, are live.
+
+For an issue, all merged MRs with it as source issue are checked. Each MR's
+merge commit is compared against the latest deployment of every environment.
+
+Examples:
+  gt deploy check gt-abc        # Issue: is the fix live in prod?
+  gt deploy check gt-mr-xyz     # Merge request
+  gt deploy check gt-abc --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runDeployCheck,
+}
+
+var deployWebhookCmd = &cobra.Command{
+	Use:   "webhook",
+	Short: "Serve an HTTP endpoint that records deployments",
+	Long: `Serve an HTTP endpoint for CI/CD systems to report deployments.
+
+  POST / {"rig": "gastown", "environment": "prod", "commit": "<sha>",
+          "ref": "v1.2.0", "url": "...", "deployed_by": "ci", "note": "..."}
+
+Set --secret (or GT_DEPLOY_SECRET) to require "Authorization: Bearer <secret>".`,
+	Args: cobra.NoArgs,
+	RunE: runDeployWebhook,
+}
+
+func init() {
+	deployRecordCmd.Flags().StringVar(&deployRecordURL, "url", "", "Link to the deploy job or release")
+	deployRecordCmd.Flags().StringVar(&deployRecordNote, "note", "", "Free-form note")
+	deployRecordCmd.Flags().StringVar(&deployRecordAt, "at", "", "Deploy time (RFC3339, default: now)")
+	deployRecordCmd.Flags().StringVar(&deployRecordBy, "by", "", "Who or what deployed (default: current agent)")
+	deployRecordCmd.Flags().BoolVar(&deployRecordJSON, "json", false, "Output as JSON")
+
+	deployListCmd.Flags().StringVar(&deployListEnv, "env", "", "Only show this environment")
+	deployListCmd.Flags().IntVarP(&deployListLimit, "limit", "n", 20, "Maximum deployments to show (0 for all)")
+	deployListCmd.Flags().BoolVar(&deployListJSON, "json", false, "Output as JSON")
+
+	deployStatusCmd.Flags().BoolVar(&deployStatusJSON, "json", false, "Output as JSON")
+	deployCheckCmd.Flags().BoolVar(&deployCheckJSON, "json", false, "Output as JSON")
+
+	deployWebhookCmd.Flags().IntVar(&deployWebhookPort, "port", 8790, "Port to listen on")
+	deployWebhookCmd.Flags().StringVar(&deployWebhookSecret, "secret", "", "Shared secret (default: $GT_DEPLOY_SECRET)")
+
+	deployCmd.AddCommand(deployRecordCmd)
+	deployCmd.AddCommand(deployListCmd)
+	deployCmd.AddCommand(deployStatusCmd)
+	deployCmd.AddCommand(deployCheckCmd)
+	deployCmd.AddCommand(deployWebhookCmd)
+
+	rootCmd.AddCommand(deployCmd)
+}
+
+func runDeployRecord(cmd *cobra.Command, args []string) error {
+	_, r, err := getRig(args[0])
+	if err != nil {
+		return err
+	}
+
+	rec := &deploy.Record{
+		Environment: args[1],
+		URL:         deployRecordURL,
+		Note:        deployRecordNote,
+		DeployedBy:  deployRecordBy,
+		DeployedAt:  time.Now().UTC(),
+	}
+	if len(args) > 2 {
+		rec.Commit = args[2]
+	}
+	if deployRecordAt != "" {
+		if rec.DeployedAt, err = time.Parse(time.RFC3339, deployRecordAt); err != nil {
+			return fmt.Errorf("invalid --at %q: want RFC3339", deployRecordAt)
+		}
+	}
+
+	rec, err = recordDeployment(r, rec)
+	if err != nil {
+		return err
+	}
+
+	if deployRecordJSON {
+		return outputJSON(rec)
+	}
+	fmt.Printf("%s Recorded deploy of %s to %s (%s)\n", style.Success.Render("✓"), shortSHA(rec.Commit), rec.Environment, rec.ID)
+	return nil
+}
+
+// recordDeployment resolves the record's commit in the rig's clone and
+// stores it as a closed deployment bead.
+func recordDeployment(r *rig.Rig, rec *deploy.Record) (*deploy.Record, error) {
+	g := git.NewGit(constants.RigMayorPath(r.Path))
+	ref := rec.Commit
+	if ref == "" {
+		if err := g.Fetch("origin"); err != nil {
+			return nil, fmt.Errorf("fetching origin: %w", err)
+		}
+		ref = "origin/" + r.DefaultBranch()
+	}
+	sha, err := g.Rev(ref)
+	if err != nil {
+		// The commit may be newer than the clone
+		_ = g.Fetch("origin")
+		if sha, err = g.Rev(ref); err != nil {
+			return nil, fmt.Errorf("resolving %s in %s: %w", ref, r.Name, err)
+		}
+	}
+	if sha != ref && rec.Ref == "" {
+		rec.Ref = ref
+	}
+	rec.Commit = sha
+	if rec.DeployedBy == "" {
+		rec.DeployedBy = detectSender()
+	}
+	if err := rec.Validate(); err != nil {
+		return nil, err
+	}
+
+	bd := beads.New(r.BeadsPath())
+	issue, err := bd.Create(beads.CreateOptions{
+		Title:       rec.Title(),
+		Type:        "deployment",
+		Priority:    -1,
+		Description: deploy.FormatDescription(rec),
+		Labels:      []string{"env:" + rec.Environment},
+		Actor:       rec.DeployedBy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("recording deployment: %w", err)
+	}
+	rec.ID = issue.ID
+	if err := bd.CloseWithReason("deployed", issue.ID); err != nil {
+		return nil, fmt.Errorf("closing deployment bead %s: %w", issue.ID, err)
+	}
+	return rec, nil
+}
+
+// loadDeployments returns a rig's deployment records, newest first.
+func loadDeployments(r *rig.Rig) ([]*deploy.Record, error) {
+	issues, err := beads.New(r.BeadsPath()).List(beads.ListOptions{Status: "all", Label: deploy.Label, Priority: -1})
+	if err != nil {
+		return nil, fmt.Errorf("listing deployments: %w", err)
+	}
+	return deploy.ParseAll(issues), nil
+}
+
+func runDeployList(cmd *cobra.Command, args []string) error {
+	_, r, err := getRig(args[0])
+	if err != nil {
+		return err
+	}
+	records, err := loadDeployments(r)
+	if err != nil {
+		return err
+	}
+	if deployListEnv != "" {
+		var filtered []*deploy.Record
+		for _, rec := range records {
+			if rec.Environment == deployListEnv {
+				filtered = append(filtered, rec)
+			}
+		}
+		records = filtered
+	}
+	if deployListLimit > 0 && len(records) > deployListLimit {
+		records = records[:deployListLimit]
+	}
+
+	if deployListJSON {
+		if records == nil {
+			records = []*deploy.Record{}
+		}
+		return outputJSON(records)
+	}
+	if len(records) == 0 {
+		fmt.Printf("%s No deployments recorded for %s\n", style.Dim.Render("○"), r.Name)
+		return nil
+	}
+
+	table := style.NewTable(
+		style.Column{Name: "ENV", Width: 12},
+		style.Column{Name: "COMMIT", Width: 10},
+		style.Column{Name: "REF", Width: 14},
+		style.Column{Name: "WHEN", Width: 12},
+		style.Column{Name: "BY", Width: 20},
+		style.Column{Name: "ID", Width: 12},
+	)
+	for _, rec := range records {
+		table.AddRow(rec.Environment, shortSHA(rec.Commit), truncateString(rec.Ref, 14),
+			formatTimeAgo(rec.DeployedAt.Format(time.RFC3339)), truncateString(rec.DeployedBy, 20), rec.ID)
+	}
+	fmt.Print(table.Render())
+	return nil
+}
+
+// DeployEnvSummary is one environment in gt deploy status.
+type DeployEnvSummary struct {
+	*deploy.Record
+	Pending []string `json:"pending"`
+}
+
+func runDeployStatus(cmd *cobra.Command, args []string) error {
+	_, r, err := getRig(args[0])
+	if err != nil {
+		return err
+	}
+	records, err := loadDeployments(r)
+	if err != nil {
+		return err
+	}
+	latest := deploy.Latest(records)
+
+	// Merged MRs not yet in each environment. MRs merged before the
+	// deployed commit was made are assumed included.
+	mrs, err := beads.New(r.BeadsPath()).List(beads.ListOptions{Status: "closed", Type: "merge-request", Priority: -1})
+	if err != nil {
+		return fmt.Errorf("listing merged MRs: %w", err)
+	}
+	g := git.NewGit(constants.RigMayorPath(r.Path))
+	summaries := make([]DeployEnvSummary, 0, len(latest))
+	for _, rec := range latest {
+		summary := DeployEnvSummary{Record: rec, Pending: []string{}}
+		cutoff, _ := g.CommitTime(rec.Commit)
+		for _, mr := range mrs {
+			fields := beads.ParseMRFields(mr)
+			if fields == nil || fields.MergeCommit == "" || !release.IsMerged(mr) {
+				continue
+			}
+			if !cutoff.IsZero() && parseBeadsTimestamp(mr.ClosedAt).Before(cutoff) {
+				continue
+			}
+			if live, err := g.IsAncestor(fields.MergeCommit, rec.Commit); err == nil && !live {
+				summary.Pending = append(summary.Pending, mr.ID)
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+
+	if deployStatusJSON {
+		return outputJSON(summaries)
+	}
+	if len(summaries) == 0 {
+		fmt.Printf("%s No deployments recorded for %s\n", style.Dim.Render("○"), r.Name)
+		return nil
+	}
+
+	fmt.Printf("%s Deployments for %s\n\n", style.Bold.Render("🚀"), r.Name)
+	table := style.NewTable(
+		style.Column{Name: "ENV", Width: 12},
+		style.Column{Name: "COMMIT", Width: 10},
+		style.Column{Name: "REF", Width: 14},
+		style.Column{Name: "WHEN", Width: 12},
+		style.Column{Name: "PENDING", Width: 8, Align: style.AlignRight},
+	)
+	for _, s := range summaries {
+		pending := style.Success.Render("0")
+		if len(s.Pending) > 0 {
+			pending = style.Warning.Render(fmt.Sprintf("%d", len(s.Pending)))
+		}
+		table.AddRow(s.Environment, shortSHA(s.Commit), truncateString(s.Ref, 14),
+			formatTimeAgo(s.DeployedAt.Format(time.RFC3339)), pending)
+	}
+	fmt.Print(table.Render())
+	return nil
+}
+
+// MRDeployStatus is the deployment status of one merged MR.
+type MRDeployStatus struct {
+	MR           string             `json:"mr"`
+	MergeCommit  string             `json:"merge_commit,omitempty"`
+	Environments []deploy.EnvStatus `json:"environments"`
+}
+
+// BeadDeployStatus answers "is this live?" for an issue or MR.
+type BeadDeployStatus struct {
+	Bead string           `json:"bead"`
+	Rig  string           `json:"rig"`
+	MRs  []MRDeployStatus `json:"merge_requests"`
+}
+
+// Live lists the environments where every merged MR is live.
+func (s *BeadDeployStatus) Live() []string {
+	counts := make(map[string]int)
+	var order []string
+	for _, mr := range s.MRs {
+		for _, env := range mr.Environments {
+			if _, seen := counts[env.Environment]; !seen {
+				order = append(order, env.Environment)
+				counts[env.Environment] = 0
+			}
+			if env.Live {
+				counts[env.Environment]++
+			}
+		}
+	}
+	var live []string
+	for _, env := range order {
+		if len(s.MRs) > 0 && counts[env] == len(s.MRs) {
+			live = append(live, env)
+		}
+	}
+	return live
+}
+
+// beadDeployStatus finds the rig owning a bead, the merged MRs for it
+// (the bead itself if it is an MR, else MRs with it as source issue), and
+// checks each against the latest deployment per environment.
+func beadDeployStatus(beadID string) (*BeadDeployStatus, error) {
+	_, r, err := getRigForBead(beadID)
+	if err != nil {
+		return nil, fmt.Errorf("%w; deployments are tracked per rig", err)
+	}
+
+	bd := beads.New(r.BeadsPath())
+	issue, err := bd.Show(beadID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", beadID, err)
+	}
+
+	var mrs []*beads.Issue
+	if fields := beads.ParseMRFields(issue); fields != nil && fields.Branch != "" {
+		mrs = []*beads.Issue{issue}
+	} else {
+		closed, err := bd.List(beads.ListOptions{Status: "closed", Type: "merge-request", Priority: -1})
+		if err != nil {
+			return nil, fmt.Errorf("listing merged MRs: %w", err)
+		}
+		for _, mr := range closed {
+			if fields := beads.ParseMRFields(mr); fields != nil && fields.SourceIssue == beadID {
+				mrs = append(mrs, mr)
+			}
+		}
+	}
+
+	records, err := loadDeployments(r)
+	if err != nil {
+		return nil, err
+	}
+	latest := deploy.Latest(records)
+
+	g := git.NewGit(constants.RigMayorPath(r.Path))
+	fetched := false
+	contains := func(commit, deployed string) (bool, error) {
+		live, err := g.IsAncestor(commit, deployed)
+		if err != nil && !fetched {
+			// Either commit may be newer than the clone
+			fetched = true
+			_ = g.Fetch("origin")
+			live, err = g.IsAncestor(commit, deployed)
+		}
+		return live, err
+	}
+
+	status := &BeadDeployStatus{Bead: beadID, Rig: r.Name, MRs: []MRDeployStatus{}}
+	for _, mr := range mrs {
+		fields := beads.ParseMRFields(mr)
+		if mr.Status != "closed" || !release.IsMerged(mr) || fields.MergeCommit == "" {
+			continue
+		}
+		status.MRs = append(status.MRs, MRDeployStatus{
+			MR:           mr.ID,
+			MergeCommit:  fields.MergeCommit,
+			Environments: deploy.Status(fields.MergeCommit, latest, contains),
+		})
+	}
+	return status, nil
+}
+
+func runDeployCheck(cmd *cobra.Command, args []string) error {
+	status, err := beadDeployStatus(args[0])
+	if err != nil {
+		return err
+	}
+	if deployCheckJSON {
+		return outputJSON(status)
+	}
+	printBeadDeployStatus(status)
+	return nil
+}
+
+// printBeadDeployStatus prints per-MR, per-environment live status.
+func printBeadDeployStatus(status *BeadDeployStatus) {
+	if len(status.MRs) == 0 {
+		fmt.Printf("%s %s has no merged MRs with a merge commit; nothing to deploy\n", style.Dim.Render("○"), status.Bead)
+		return
+	}
+	for _, mr := range status.MRs {
+		fmt.Printf("%s %s (merge commit %s)\n", style.Bold.Render("🚀"), mr.MR, shortSHA(mr.MergeCommit))
+		if len(mr.Environments) == 0 {
+			fmt.Printf("   %s\n", style.Dim.Render("No deployments recorded for "+status.Rig))
+			continue
+		}
+		for _, env := range mr.Environments {
+			when := formatTimeAgo(env.Deployment.DeployedAt.Format(time.RFC3339))
+			switch {
+			case env.Error != "":
+				fmt.Printf("   %s %-12s %s\n", style.Warning.Render("?"), env.Environment, style.Dim.Render(env.Error))
+			case env.Live:
+				fmt.Printf("   %s %-12s live (deployed %s %s)\n", style.Success.Render("✓"), env.Environment, shortSHA(env.Deployment.Commit), when)
+			default:
+				fmt.Printf("   %s %-12s not live (deployed %s %s)\n", style.Error.Render("✗"), env.Environment, shortSHA(env.Deployment.Commit), when)
+			}
+		}
+	}
+	if live := status.Live(); len(live) > 0 {
+		fmt.Printf("\nLive in: %s\n", strings.Join(live, ", "))
+	}
+}
+
+func runDeployWebhook(cmd *cobra.Command, args []string) error {
+	if _, err := workspace.FindFromCwdOrError(); err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	secret := deployWebhookSecret
+	if secret == "" {
+		secret = os.Getenv("GT_DEPLOY_SECRET")
+	}
+	if secret == "" {
+		fmt.Printf("%s No secret set; any client that can reach the port can record deployments\n", style.Warning.Render("⚠"))
+	}
+
+	handler := &deploy.Handler{
+		Secret: secret,
+		Record: func(rigName string, rec *deploy.Record) (*deploy.Record, error) {
+			_, r, err := getRig(rigName)
+			if err != nil {
+				return nil, err
+			}
+			rec, err = recordDeployment(r, rec)
+			if err == nil {
+				fmt.Printf("%s %s: deploy of %s to %s (%s)\n", style.Success.Render("✓"), rigName, shortSHA(rec.Commit), rec.Environment, rec.ID)
+			}
+			return rec, err
+		},
+	}
+
+	fmt.Printf("Listening for deployments on :%d\n", deployWebhookPort)
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%d", deployWebhookPort),
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      60 * time.Second,
+		IdleTimeout:       120 * time.Second,
+	}
+	return server.ListenAndServe()
+}
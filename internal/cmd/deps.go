@@ -0,0 +1,261 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/depupdate"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// Deps command flags
+var (
+	depsCheckFile   bool
+	depsCheckSling  bool
+	depsCheckReport string
+	depsCheckJSON   bool
+)
+
+var depsCmd = &cobra.Command{
+	Use:     "deps",
+	GroupID: GroupWork,
+	Short:   "Dependency-update automation",
+	RunE:    requireSubcommand,
+	Long: `Detect outdated dependencies in a rig and turn them into work.
+
+Updates are read from a Renovate/Dependabot-style report (configured via
+dep_updates.report_path in rig settings) or detected directly for Go rigs
+using 'go list -m -u'.`,
+}
+
+var depsCheckCmd = &cobra.Command{
+	Use:   "check <rig>",
+	Short: "Check a rig for dependency updates",
+	Long: `Check a rig for available dependency updates.
+
+By default only reports what is outdated. With --file, a bead labeled
+gt:dep-update is filed for each dependency that doesn't already have a
+bead open, hooked, or in progress, so the command is safe to run
+repeatedly from a patrol or cron job. When a newer release comes out while
+a dependency's bead is still open, that bead's title and description are
+updated to the new version instead; a bead already being worked is left
+alone. A report listing several updates for one dependency files a single
+bead, for the newest.
+
+With --sling (or dep_updates.auto_sling in rig settings), newly filed beads
+are slung to the rig so polecats do the bump and submit it through the
+merge queue. At most dep_updates.max_auto_sling beads are slung per run.
+
+Configuration (settings/config.json):
+  "dep_updates": {
+    "report_path": "renovate-report.json",
+    "include_indirect": false,
+    "ignore": ["golang.org/x/*"],
+    "priority": 3,
+    "auto_sling": false,
+    "max_auto_sling": 3
+  }
+
+Examples:
+  gt deps check gastown                 # Report available updates
+  gt deps check gastown --file          # File beads for new updates
+  gt deps check gastown --file --sling  # File and dispatch polecats
+  gt deps check gastown --report=/tmp/renovate.json --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runDepsCheck,
+}
+
+func init() {
+	depsCheckCmd.Flags().BoolVar(&depsCheckFile, "file", false, "File beads for updates without an unfinished bead")
+	depsCheckCmd.Flags().BoolVar(&depsCheckSling, "sling", false, "Sling newly filed beads to the rig (implies --file)")
+	depsCheckCmd.Flags().StringVar(&depsCheckReport, "report", "", "Read updates from this report instead of the configured source")
+	depsCheckCmd.Flags().BoolVar(&depsCheckJSON, "json", false, "Output as JSON")
+
+	depsCmd.AddCommand(depsCheckCmd)
+	rootCmd.AddCommand(depsCmd)
+}
+
+// DepsCheckResult is the JSON output of gt deps check.
+type DepsCheckResult struct {
+	Rig     string             `json:"rig"`
+	Updates []depupdate.Update `json:"updates"`
+	Filed   []DepsFiledBead    `json:"filed,omitempty"`
+	Updated []DepsFiledBead    `json:"updated,omitempty"` // open beads moved to a newer release
+	Slung   []string           `json:"slung,omitempty"`
+	Skipped map[string]string  `json:"skipped,omitempty"` // title -> existing bead ID
+}
+
+// DepsFiledBead records a bead filed, or updated, for an update.
+type DepsFiledBead struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+func runDepsCheck(cmd *cobra.Command, args []string) error {
+	rigName := args[0]
+
+	_, r, err := getRig(rigName)
+	if err != nil {
+		return err
+	}
+
+	cfg := &config.DepUpdatesConfig{}
+	if settings, err := config.LoadRigSettings(config.RigSettingsPath(r.Path)); err == nil && settings.DepUpdates != nil {
+		cfg = settings.DepUpdates
+	}
+
+	reportPath := cfg.ReportPath
+	if depsCheckReport != "" {
+		reportPath = depsCheckReport
+	}
+
+	repoDir := constants.RigMayorPath(r.Path)
+	updates, err := depupdate.Detect(context.Background(), repoDir, reportPath, cfg.IncludeIndirect)
+	if err != nil {
+		return fmt.Errorf("checking %s: %w", rigName, err)
+	}
+	updates = depupdate.Filter(updates, cfg.Ignore)
+
+	result := DepsCheckResult{Rig: rigName, Updates: updates}
+
+	sling := depsCheckSling || cfg.AutoSling
+	if depsCheckFile || sling {
+		if err := fileDepUpdateBeads(r.BeadsPath(), rigName, cfg, &result); err != nil {
+			return err
+		}
+	}
+
+	if sling && len(result.Filed) > 0 {
+		max := cfg.MaxAutoSling
+		if max <= 0 {
+			max = config.DefaultMaxAutoSling
+		}
+		for i, filed := range result.Filed {
+			if i >= max {
+				break
+			}
+			if err := slingDepUpdate(filed.ID, rigName); err != nil {
+				if !depsCheckJSON {
+					fmt.Printf("%s Could not sling %s: %v\n", style.Warning.Render("⚠"), filed.ID, err)
+				}
+				continue
+			}
+			result.Slung = append(result.Slung, filed.ID)
+		}
+	}
+
+	if depsCheckJSON {
+		return outputJSON(result)
+	}
+
+	printDepsCheckResult(result)
+	return nil
+}
+
+// fileDepUpdateBeads files a bead for each update that has no bead yet,
+// open or already taken up. An open bead for the same dependency at
+// another version is updated to this one instead; one being worked is
+// left alone, and the newer version is filed once it's closed.
+func fileDepUpdateBeads(beadsPath, rigName string, cfg *config.DepUpdatesConfig, result *DepsCheckResult) error {
+	b := beads.New(beadsPath)
+
+	existing, err := b.ListActive(beads.ListOptions{
+		Label:    depupdate.Label,
+		Priority: -1,
+	})
+	if err != nil {
+		return fmt.Errorf("listing existing update beads: %w", err)
+	}
+	byKey := make(map[string]*beads.Issue, len(existing))
+	for _, issue := range existing {
+		if key := depupdate.KeyOf(issue.Description); key != "" {
+			byKey[key] = issue
+		}
+	}
+
+	priority := config.DefaultDepUpdatePriority
+	if cfg.Priority != nil {
+		priority = *cfg.Priority
+	}
+
+	for _, u := range result.Updates {
+		title := u.Title()
+		if open, ok := byKey[u.Key()]; ok {
+			// A report can lag the bead: never move it back to an older version
+			if open.Status != "open" || !u.Supersedes(open.Description) {
+				if result.Skipped == nil {
+					result.Skipped = make(map[string]string)
+				}
+				result.Skipped[title] = open.ID
+				continue
+			}
+			description := u.Description()
+			if err := b.Update(open.ID, beads.UpdateOptions{Title: &title, Description: &description}); err != nil {
+				return fmt.Errorf("updating bead %s for %s: %w", open.ID, u.Name, err)
+			}
+			open.Title, open.Description = title, description
+			result.Updated = append(result.Updated, DepsFiledBead{ID: open.ID, Title: title})
+			continue
+		}
+
+		issue, err := b.Create(beads.CreateOptions{
+			Title:       title,
+			Type:        "task",
+			Priority:    priority,
+			Description: u.Description(),
+			Labels:      []string{depupdate.Label},
+			Actor:       rigName + "/deps",
+		})
+		if err != nil {
+			return fmt.Errorf("filing bead for %s: %w", u.Name, err)
+		}
+		byKey[u.Key()] = issue
+		result.Filed = append(result.Filed, DepsFiledBead{ID: issue.ID, Title: title})
+	}
+
+	return nil
+}
+
+// slingDepUpdate dispatches an update bead to a fresh polecat in the rig.
+func slingDepUpdate(beadID, rigName string) error {
+	c := exec.Command("gt", "sling", beadID, rigName)
+	out, err := c.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func printDepsCheckResult(result DepsCheckResult) {
+	fmt.Printf("%s Dependency updates for '%s':\n\n", style.Bold.Render("📦"), result.Rig)
+
+	if len(result.Updates) == 0 {
+		fmt.Printf("  %s\n", style.Dim.Render("(all dependencies up to date)"))
+		return
+	}
+
+	for _, u := range result.Updates {
+		fmt.Printf("  %s %s → %s %s\n", u.Name, style.Dim.Render(u.Current), u.Latest,
+			style.Dim.Render("("+u.Ecosystem+")"))
+	}
+	fmt.Println()
+
+	for _, f := range result.Filed {
+		fmt.Printf("%s Filed %s: %s\n", style.Bold.Render("✓"), f.ID, f.Title)
+	}
+	for _, f := range result.Updated {
+		fmt.Printf("%s Updated %s: %s\n", style.Bold.Render("✓"), f.ID, f.Title)
+	}
+	if len(result.Skipped) > 0 {
+		fmt.Printf("  %s\n", style.Dim.Render(fmt.Sprintf("%d update(s) already have beads", len(result.Skipped))))
+	}
+	for _, id := range result.Slung {
+		fmt.Printf("%s Slung %s to %s\n", style.Bold.Render("🎯"), id, result.Rig)
+	}
+}
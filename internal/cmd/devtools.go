@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/testtown"
+)
+
+var devtoolsCmd = &cobra.Command{
+	Use:     "devtools",
+	GroupID: GroupDiag,
+	Short:   "Tools for Gas Town contributors and plugin authors",
+	RunE:    requireSubcommand,
+}
+
+var devtoolsMktownCmd = &cobra.Command{
+	Use:   "mktown <dir>",
+	Short: "Generate a synthetic town for testing",
+	Long: `Generate a synthetic town with fake rigs, beads, git history, and merge queues.
+
+Each rig gets a config and registry entry, a mayor/rig git repo with
+history on main and a branch per merge request, and beads fixtures in
+.beads/issues.jsonl: work issues (some hooked by polecats), agent beads,
+and open merge requests. The same options and seed always produce the
+same town, down to commit hashes.
+
+With --import, the fixtures are loaded into bd so gt commands can query
+them; without it no bd or Dolt server is needed.
+
+Examples:
+  gt devtools mktown /tmp/town
+  gt devtools mktown /tmp/big --rigs 6 --issues 50 --mrs 10 --seed 7
+  gt devtools mktown /tmp/town --import && cd /tmp/town && gt mq list alpha`,
+	Args: cobra.ExactArgs(1),
+	RunE: runDevtoolsMktown,
+}
+
+var (
+	mktownOpts   testtown.Options
+	mktownImport bool
+)
+
+func init() {
+	devtoolsMktownCmd.Flags().StringVar(&mktownOpts.Name, "name", "testtown", "Town name")
+	devtoolsMktownCmd.Flags().IntVar(&mktownOpts.Rigs, "rigs", 2, "Number of rigs (at most 8)")
+	devtoolsMktownCmd.Flags().IntVar(&mktownOpts.IssuesPerRig, "issues", 10, "Work issues per rig")
+	devtoolsMktownCmd.Flags().IntVar(&mktownOpts.CommitsPerRig, "commits", 5, "Commits on main per rig")
+	devtoolsMktownCmd.Flags().IntVar(&mktownOpts.MergeRequests, "mrs", 3, "Open merge requests per rig (negative for none)")
+	devtoolsMktownCmd.Flags().IntVar(&mktownOpts.PolecatsPerRig, "polecats", 2, "Polecats per rig (at most 8)")
+	devtoolsMktownCmd.Flags().Int64Var(&mktownOpts.Seed, "seed", 1, "Random seed")
+	devtoolsMktownCmd.Flags().BoolVar(&mktownImport, "import", false, "Load the beads fixtures into bd")
+
+	devtoolsCmd.AddCommand(devtoolsMktownCmd)
+	rootCmd.AddCommand(devtoolsCmd)
+}
+
+func runDevtoolsMktown(cmd *cobra.Command, args []string) error {
+	dir, err := filepath.Abs(args[0])
+	if err != nil {
+		return err
+	}
+	town, err := testtown.Generate(dir, mktownOpts)
+	if err != nil {
+		return fmt.Errorf("generating town: %w", err)
+	}
+
+	fmt.Printf("%s Generated town %s at %s\n", style.SuccessPrefix, style.Bold.Render(town.Name), town.Root)
+	for _, r := range town.Rigs {
+		fmt.Printf("  %-10s %s  %d issues, %d commits, %d MRs, polecats: %v\n",
+			r.Name, style.Dim.Render(r.Prefix+"-"), len(r.Issues), len(r.Commits), len(r.MergeRequests), r.Polecats)
+	}
+
+	if mktownImport {
+		if err := town.Import(); err != nil {
+			return fmt.Errorf("importing beads: %w", err)
+		}
+		fmt.Printf("%s Imported beads fixtures\n", style.SuccessPrefix)
+	}
+	return nil
+}
@@ -50,6 +50,9 @@ Cleanup checks (fixable):
   - orphan-processes         Detect orphaned Claude processes
   - wisp-gc                  Detect and clean abandoned wisps (>1h)
   - stale-beads-redirect     Detect stale files in .beads directories with redirects
+  - orphaned-state           Reconcile crash leftovers: agents marked running
+                             with no session, MRs and merge slots held by dead
+                             agents, stale identity locks
 
 Clone divergence checks:
   - persistent-role-branches Detect crew/witness/refinery not on main
@@ -155,6 +158,7 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 	d.Register(doctor.NewBeadsSyncWorktreeCheck())
 	d.Register(doctor.NewCloneDivergenceCheck())
 	d.Register(doctor.NewIdentityCollisionCheck())
+	d.Register(doctor.NewOrphanedStateCheck())
 	d.Register(doctor.NewLinkedPaneCheck())
 	d.Register(doctor.NewThemeCheck())
 	d.Register(doctor.NewCrashReportCheck())
@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -15,6 +14,7 @@ import (
 	"github.com/steveyegge/gastown/internal/mail"
 	"github.com/steveyegge/gastown/internal/plugin"
 	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/timefmt"
 	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
@@ -30,6 +30,7 @@ var (
 	// Dispatch flags
 	dogDispatchPlugin string
 	dogDispatchRig    string
+	dogDispatchGroup  string
 	dogDispatchCreate bool
 	dogDispatchDog    string
 	dogDispatchJSON   bool
@@ -239,6 +240,7 @@ func init() {
 	// Dispatch flags
 	dogDispatchCmd.Flags().StringVar(&dogDispatchPlugin, "plugin", "", "Plugin name to dispatch (required)")
 	dogDispatchCmd.Flags().StringVar(&dogDispatchRig, "rig", "", "Limit plugin search to specific rig")
+	dogDispatchCmd.Flags().StringVar(&dogDispatchGroup, "group", "", "Limit plugin search to the rigs in a group")
 	dogDispatchCmd.Flags().StringVar(&dogDispatchDog, "dog", "", "Dispatch to specific dog (default: any idle)")
 	dogDispatchCmd.Flags().BoolVar(&dogDispatchCreate, "create", false, "Create a dog if none idle")
 	dogDispatchCmd.Flags().BoolVar(&dogDispatchJSON, "json", false, "Output as JSON")
@@ -438,9 +440,7 @@ func runDogList(cmd *cobra.Command, args []string) error {
 			})
 		}
 
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(items)
+		return outputJSON(items)
 	}
 
 	// Pretty print
@@ -673,9 +673,7 @@ func showDogStatus(mgr *dog.Manager, name string) error {
 	}
 
 	if dogStatusJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(d)
+		return outputJSON(d)
 	}
 
 	fmt.Printf("Dog: %s\n\n", style.Bold.Render(d.Name))
@@ -744,9 +742,7 @@ func showPackStatus(mgr *dog.Manager) error {
 			}
 		}
 
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(status)
+		return outputJSON(status)
 	}
 
 	fmt.Println(style.Bold.Render("Pack Status"))
@@ -786,30 +782,7 @@ func dogFormatTimeAgo(t time.Time) string {
 	if t.IsZero() {
 		return "(unknown)"
 	}
-
-	d := time.Since(t)
-	switch {
-	case d < time.Minute:
-		return "just now"
-	case d < time.Hour:
-		mins := int(d.Minutes())
-		if mins == 1 {
-			return "1 minute ago"
-		}
-		return fmt.Sprintf("%d minutes ago", mins)
-	case d < 24*time.Hour:
-		hours := int(d.Hours())
-		if hours == 1 {
-			return "1 hour ago"
-		}
-		return fmt.Sprintf("%d hours ago", hours)
-	default:
-		days := int(d.Hours() / 24)
-		if days == 1 {
-			return "1 day ago"
-		}
-		return fmt.Sprintf("%d days ago", days)
-	}
+	return timefmt.Ago(t)
 }
 
 // runDogDispatch dispatches plugin execution to a dog worker.
@@ -834,6 +807,11 @@ func runDogDispatch(cmd *cobra.Command, args []string) error {
 	// If --rig specified, search only that rig
 	if dogDispatchRig != "" {
 		rigNames = []string{dogDispatchRig}
+	} else if dogDispatchGroup != "" {
+		if _, err := groupRigSet(rigsConfig, dogDispatchGroup); err != nil {
+			return err
+		}
+		rigNames = rigsConfig.GroupMembers(dogDispatchGroup)
 	}
 
 	// Find the plugin using scanner
@@ -912,7 +890,7 @@ func runDogDispatch(cmd *cobra.Command, args []string) error {
 	// Dry-run mode: show what would happen and exit
 	if dogDispatchDryRun {
 		if dogDispatchJSON {
-			return json.NewEncoder(os.Stdout).Encode(result)
+			return outputJSON(result)
 		}
 		fmt.Printf("Dry run - would dispatch:\n")
 		fmt.Printf("  Plugin: %s\n", p.Name)
@@ -959,7 +937,7 @@ func runDogDispatch(cmd *cobra.Command, args []string) error {
 
 	// Success - output result
 	if dogDispatchJSON {
-		return json.NewEncoder(os.Stdout).Encode(result)
+		return outputJSON(result)
 	}
 
 	fmt.Printf("%s Found plugin: %s\n", style.Bold.Render("✓"), p.Name)
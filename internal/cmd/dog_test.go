@@ -437,12 +437,12 @@ func TestDogFormatTimeAgo(t *testing.T) {
 		want   string
 	}{
 		{"just now", 30 * time.Second, "just now"},
-		{"1 minute ago", 1 * time.Minute, "1 minute ago"},
-		{"5 minutes ago", 5 * time.Minute, "5 minutes ago"},
-		{"1 hour ago", 1 * time.Hour, "1 hour ago"},
-		{"3 hours ago", 3 * time.Hour, "3 hours ago"},
-		{"1 day ago", 24 * time.Hour, "1 day ago"},
-		{"5 days ago", 5 * 24 * time.Hour, "5 days ago"},
+		{"1 minute ago", 1 * time.Minute, "1m ago"},
+		{"5 minutes ago", 5 * time.Minute, "5m ago"},
+		{"1 hour ago", 1 * time.Hour, "1h ago"},
+		{"3 hours ago", 3 * time.Hour, "3h ago"},
+		{"1 day ago", 24 * time.Hour, "1d ago"},
+		{"5 days ago", 5 * 24 * time.Hour, "5d ago"},
 	}
 
 	for _, tt := range tests {
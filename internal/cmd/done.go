@@ -495,6 +495,7 @@ func runDone(cmd *cobra.Command, args []string) error {
 			mrID = existingMR.ID
 			fmt.Printf("%s MR already exists (idempotent)\n", style.Bold.Render("✓"))
 			fmt.Printf("  MR ID: %s\n", style.Bold.Render(mrID))
+			rerequestReview(rigName, existingMR)
 		} else {
 			// Build MR bead title and description
 			title := fmt.Sprintf("Merge: %s", issueID)
@@ -518,6 +519,9 @@ func runDone(cmd *cobra.Command, args []string) error {
 				description += fmt.Sprintf("\npr_url: %s", prURL)
 			}
 
+			// Hold for review if the rig requires it
+			description += reviewFieldsForSubmit(rigName, target)
+
 			// Create MR bead (ephemeral wisp - will be cleaned up after merge)
 			mrIssue, err := bd.Create(beads.CreateOptions{
 				Title:       title,
@@ -662,7 +666,11 @@ notifyWitness:
 
 	// Log done event (townlog and activity feed)
 	_ = LogDone(townRoot, sender, issueID)
-	_ = events.LogFeed(events.TypeDone, sender, events.DonePayload(issueID, branch))
+	donePayload := events.DonePayload(issueID, branch)
+	if mrID != "" {
+		donePayload["mr"] = mrID
+	}
+	_ = events.LogFeed(events.TypeDone, sender, donePayload)
 
 	// Update agent bead state (ZFC: self-report completion)
 	updateAgentStateOnDone(cwd, townRoot, exitType, issueID)
@@ -19,6 +19,7 @@ import (
 	"github.com/steveyegge/gastown/internal/polecat"
 	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/session"
+	"github.com/steveyegge/gastown/internal/shutdown"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -46,6 +47,14 @@ Shutdown levels (progressively more aggressive):
   gt down --all              Full shutdown with orphan cleanup
   gt down --nuke             Also kill the tmux server (DESTRUCTIVE)
 
+Before host maintenance, use --checkpoint to keep in-flight work:
+  gt down --checkpoint       Ask every polecat and crew agent to write a
+                             checkpoint, capture one for any that don't,
+                             flush queued spawn triggers, stop the agents,
+                             and record a resume manifest (daemon/resume.json)
+  gt up --resume             Bring the town back and respawn those agents
+                             on the issues they had hooked
+
 Infrastructure agents stopped:
   • Refineries - Per-rig work processors
   • Witnesses  - Per-rig polecat managers
@@ -65,12 +74,14 @@ Use cases:
 }
 
 var (
-	downQuiet    bool
-	downForce    bool
-	downAll      bool
-	downNuke     bool
-	downDryRun   bool
-	downPolecats bool
+	downQuiet      bool
+	downForce      bool
+	downAll        bool
+	downNuke       bool
+	downDryRun     bool
+	downPolecats   bool
+	downCheckpoint bool
+	downDrain      time.Duration
 )
 
 func init() {
@@ -79,6 +90,8 @@ func init() {
 	downCmd.Flags().BoolVarP(&downPolecats, "polecats", "p", false, "Also stop all polecat sessions")
 	downCmd.Flags().BoolVarP(&downAll, "all", "a", false, "Full shutdown with orphan cleanup and verification")
 	downCmd.Flags().BoolVar(&downNuke, "nuke", false, "Kill entire tmux server (DESTRUCTIVE - kills non-GT sessions!)")
+	downCmd.Flags().BoolVarP(&downCheckpoint, "checkpoint", "c", false, "Checkpoint polecat and crew agents and record a resume manifest for gt up --resume (implies --polecats)")
+	downCmd.Flags().DurationVar(&downDrain, "drain", 30*time.Second, "With --checkpoint, how long agents get to write their own checkpoint")
 	downCmd.Flags().BoolVar(&downDryRun, "dry-run", false, "Preview what would be stopped without taking action")
 	rootCmd.AddCommand(downCmd)
 }
@@ -124,8 +137,19 @@ func runDown(cmd *cobra.Command, args []string) error {
 
 	rigs := discoverRigs(townRoot)
 
+	// Phase 0.25: Checkpoint workers and record the resume manifest (--checkpoint)
+	var manifest *shutdown.Manifest
+	if downCheckpoint {
+		manifest, err = checkpointTown(t, townRoot, rigs, downDrain, downDryRun)
+		if err != nil {
+			return err
+		}
+		fmt.Println()
+	}
+	stopPolecats := downPolecats || downCheckpoint
+
 	// Phase 0.5: Stop polecats if --polecats
-	if downPolecats {
+	if stopPolecats {
 		if downDryRun {
 			fmt.Println("Would stop polecats...")
 		} else {
@@ -148,6 +172,21 @@ func runDown(cmd *cobra.Command, args []string) error {
 		fmt.Println()
 	}
 
+	// Phase 0.75: Stop checkpointed crew (--checkpoint)
+	if manifest != nil {
+		for _, a := range manifest.Agents {
+			if a.Role != string(session.RoleCrew) {
+				continue
+			}
+			if _, err := stopSession(t, a.Session); err != nil {
+				printDownStatus(fmt.Sprintf("Crew (%s)", a.Address()), false, err.Error())
+				allOK = false
+			} else {
+				printDownStatus(fmt.Sprintf("Crew (%s)", a.Address()), true, "stopped")
+			}
+		}
+	}
+
 	// Phase 1: Stop refineries
 	for _, rigName := range rigs {
 		sessionName := fmt.Sprintf("gt-%s-refinery", rigName)
@@ -300,9 +339,12 @@ func runDown(cmd *cobra.Command, args []string) error {
 			stoppedServices = append(stoppedServices, fmt.Sprintf("%s/refinery", rigName))
 			stoppedServices = append(stoppedServices, fmt.Sprintf("%s/witness", rigName))
 		}
-		if downPolecats {
+		if stopPolecats {
 			stoppedServices = append(stoppedServices, "polecats")
 		}
+		if manifest != nil {
+			stoppedServices = append(stoppedServices, "crew")
+		}
 		if downAll {
 			stoppedServices = append(stoppedServices, "bd-processes")
 		}
@@ -310,6 +352,10 @@ func runDown(cmd *cobra.Command, args []string) error {
 			stoppedServices = append(stoppedServices, "tmux-server")
 		}
 		_ = events.LogFeed(events.TypeHalt, "gt", events.HaltPayload(stoppedServices))
+		if manifest != nil {
+			fmt.Printf("  Run %s to bring the town back with %d agent(s) on their issues\n",
+				style.Bold.Render("gt up --resume"), len(manifest.Agents))
+		}
 	} else {
 		fmt.Printf("%s Some services failed to stop\n", style.Bold.Render("✗"))
 		return fmt.Errorf("not all services stopped")
@@ -510,4 +556,3 @@ func findOrphanedClaudeProcesses(townRoot string) []int {
 
 	return orphaned
 }
-
@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/checkpoint"
+	"github.com/steveyegge/gastown/internal/crew"
+	"github.com/steveyegge/gastown/internal/daemon"
+	"github.com/steveyegge/gastown/internal/polecat"
+	"github.com/steveyegge/gastown/internal/session"
+	"github.com/steveyegge/gastown/internal/shutdown"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+// downCheckpointNudge asks a worker to save its context before gt down
+// stops it.
+const downCheckpointNudge = "Town is shutting down for maintenance. Commit or stash work in progress " +
+	"and run `gt checkpoint write --notes \"<where you are>\"` now. You'll be resumed on the same issue by gt up --resume."
+
+// checkpointTown checkpoints every running polecat and crew worker, flushes
+// the pending spawn queue, and writes the resume manifest. Workers are given
+// drain to write their own checkpoint before one is captured for them.
+// Returns nil in dry-run mode.
+func checkpointTown(t *tmux.Tmux, townRoot string, rigNames []string, drain time.Duration, dryRun bool) (*shutdown.Manifest, error) {
+	workers := runningWorkers(t, rigNames)
+	if dryRun {
+		printDownStatus("Checkpoint", true, fmt.Sprintf("would checkpoint %d agent(s) and write %s", len(workers), shutdown.ManifestFile))
+		return nil, nil
+	}
+
+	nudgedAt := time.Now()
+	if drain > 0 && len(workers) > 0 {
+		for _, w := range workers {
+			_ = t.NudgeSession(w.Session, "[from gt down] "+downCheckpointNudge)
+		}
+		if !downQuiet {
+			fmt.Printf("Waiting %v for %d agent(s) to checkpoint...\n", drain, len(workers))
+		}
+		time.Sleep(drain)
+	}
+
+	hooked := hookedIssues(townRoot, rigNames)
+	for i := range workers {
+		w := &workers[i]
+		w.Issue = hooked[w.Address()]
+		if workDir, err := t.GetPaneWorkDir(w.Session); err == nil && workDir != "" {
+			w.WorkDir = workDir
+		}
+		if w.WorkDir == "" {
+			printDownStatus(fmt.Sprintf("Checkpoint (%s)", w.Address()), false, "no working directory")
+			continue
+		}
+		cp, err := saveWorkerCheckpoint(w.WorkDir, w.Issue, nudgedAt)
+		if err != nil {
+			printDownStatus(fmt.Sprintf("Checkpoint (%s)", w.Address()), false, err.Error())
+			continue
+		}
+		w.Branch, w.Commit = cp.Branch, cp.LastCommit
+		detail := "checkpointed"
+		if w.Issue != "" {
+			detail += " on " + w.Issue
+		}
+		printDownStatus(fmt.Sprintf("Checkpoint (%s)", w.Address()), true, detail)
+	}
+
+	m := &shutdown.Manifest{
+		CreatedAt: time.Now(),
+		CreatedBy: detectSender(),
+		Rigs:      rigNames,
+		Agents:    workers,
+	}
+	m.Daemon, _, _ = daemon.IsRunning(townRoot)
+
+	// Spawn triggers queued for sessions that are about to stop would
+	// fire at nothing; the polecats themselves are in the manifest.
+	if n, err := polecat.PruneStalePending(townRoot, 0); err == nil {
+		m.PendingSpawns = n
+	}
+
+	if err := shutdown.Write(townRoot, m); err != nil {
+		return nil, fmt.Errorf("writing resume manifest: %w", err)
+	}
+	printDownStatus("Resume manifest", true, fmt.Sprintf("%d agent(s) → %s", len(workers), shutdown.ManifestFile))
+	return m, nil
+}
+
+// runningWorkers returns the polecat and crew sessions running in rigNames.
+func runningWorkers(t *tmux.Tmux, rigNames []string) []shutdown.Agent {
+	sessions, err := t.ListSessions()
+	if err != nil {
+		return nil
+	}
+	var workers []shutdown.Agent
+	for _, name := range sessions {
+		id, err := session.ParseSessionName(name)
+		if err != nil || !slices.Contains(rigNames, id.Rig) {
+			continue
+		}
+		if id.Role != session.RolePolecat && id.Role != session.RoleCrew {
+			continue
+		}
+		workers = append(workers, shutdown.Agent{Role: string(id.Role), Rig: id.Rig, Name: id.Name, Session: name})
+	}
+	return workers
+}
+
+// hookedIssues maps agent addresses to their hooked issue across the
+// town's and every rig's beads.
+func hookedIssues(townRoot string, rigNames []string) map[string]string {
+	dirs := []string{beads.GetTownBeadsPath(townRoot)}
+	for _, rigName := range rigNames {
+		dirs = append(dirs, filepath.Join(townRoot, rigName, "mayor", "rig"))
+	}
+	hooked := make(map[string]string)
+	for _, dir := range dirs {
+		issues, err := beads.New(dir).List(beads.ListOptions{Status: beads.StatusHooked, Priority: -1})
+		if err != nil {
+			continue
+		}
+		for _, issue := range issues {
+			if issue.Assignee != "" && hooked[issue.Assignee] == "" {
+				hooked[issue.Assignee] = issue.ID
+			}
+		}
+	}
+	return hooked
+}
+
+// saveWorkerCheckpoint keeps a checkpoint the worker wrote since since,
+// capturing one from its git state otherwise, and makes sure it names the
+// hooked issue.
+func saveWorkerCheckpoint(workDir, issue string, since time.Time) (*checkpoint.Checkpoint, error) {
+	cp, err := checkpoint.Read(workDir)
+	if err != nil || cp == nil || cp.Timestamp.Before(since) {
+		if cp, err = checkpoint.Capture(workDir); err != nil {
+			return nil, err
+		}
+		cp.WithNotes("Checkpointed by gt down; the town was shut down for maintenance.")
+	}
+	if cp.HookedBead == "" && issue != "" {
+		cp.WithHookedBead(issue)
+	}
+	if err := checkpoint.Write(workDir, cp); err != nil {
+		return nil, err
+	}
+	return cp, nil
+}
+
+// resumeWorkers restarts the workers in a resume manifest on the issues
+// they had hooked, and removes the manifest once all of them are back.
+func resumeWorkers(townRoot string, m *shutdown.Manifest) bool {
+	t := tmux.NewTmux()
+	allOK := true
+	for _, a := range m.Agents {
+		name := fmt.Sprintf("Polecat (%s)", a.Address())
+		if a.Role == string(session.RoleCrew) {
+			name = fmt.Sprintf("Crew (%s)", a.Address())
+		}
+		sessionName, err := resumeWorker(t, a)
+		if err != nil {
+			printStatus(name, false, err.Error())
+			allOK = false
+			continue
+		}
+		detail := sessionName
+		if a.Issue != "" {
+			detail += " on " + a.Issue
+		}
+		printStatus(name, true, detail)
+	}
+	if allOK {
+		if err := shutdown.Remove(townRoot); err != nil {
+			fmt.Fprintf(os.Stderr, "%s removing resume manifest: %v\n", style.Warning.Render("⚠"), err)
+		}
+	}
+	return allOK
+}
+
+// resumeWorker restarts one worker and returns its session name.
+func resumeWorker(t *tmux.Tmux, a shutdown.Agent) (string, error) {
+	switch a.Role {
+	case string(session.RoleCrew):
+		crewMgr, _, err := getCrewManager(a.Rig)
+		if err != nil {
+			return "", err
+		}
+		if err := crewMgr.Start(a.Name, crew.StartOptions{Topic: "restart"}); err != nil && !errors.Is(err, crew.ErrSessionRunning) {
+			return "", err
+		}
+		return crewMgr.SessionName(a.Name), nil
+	default:
+		_, r, err := getRig(a.Rig)
+		if err != nil {
+			return "", err
+		}
+		mgr := polecat.NewSessionManager(t, r)
+		err = mgr.Start(a.Name, polecat.SessionStartOptions{Issue: a.Issue})
+		if err != nil && !errors.Is(err, polecat.ErrSessionRunning) {
+			return "", err
+		}
+		return mgr.SessionName(a.Name), nil
+	}
+}
@@ -3,6 +3,9 @@ package cmd
 import (
 	"os"
 	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/checkpoint"
 )
 
 func TestIsProcessRunning_CurrentProcess(t *testing.T) {
@@ -22,3 +25,33 @@ func TestIsProcessRunning_MaxPID(t *testing.T) {
 		t.Error("max PID should not be running")
 	}
 }
+
+func TestSaveWorkerCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	nudged := time.Now()
+
+	// A checkpoint the agent wrote after the nudge is kept, gaining the hook
+	own := &checkpoint.Checkpoint{Notes: "halfway through the parser", Timestamp: nudged.Add(time.Second)}
+	if err := checkpoint.Write(dir, own); err != nil {
+		t.Fatal(err)
+	}
+	cp, err := saveWorkerCheckpoint(dir, "gt-abc", nudged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp.Notes != "halfway through the parser" || cp.HookedBead != "gt-abc" {
+		t.Errorf("agent checkpoint = %+v, want its notes kept and hook gt-abc", cp)
+	}
+
+	// One from before the nudge is stale and gets replaced
+	cp, err = saveWorkerCheckpoint(dir, "gt-abc", nudged.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp.Notes == "halfway through the parser" {
+		t.Error("stale checkpoint was kept")
+	}
+	if saved, _ := checkpoint.Read(dir); saved == nil || saved.HookedBead != "gt-abc" {
+		t.Errorf("saved checkpoint = %+v, want hook gt-abc", saved)
+	}
+}
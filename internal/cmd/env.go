@@ -0,0 +1,295 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Env command flags
+var (
+	envRig    string
+	envRole   string
+	envReveal bool
+	envJSON   bool
+)
+
+var envCmd = &cobra.Command{
+	Use:     "env",
+	GroupID: GroupConfig,
+	Short:   "Inspect a rig's managed agent environment",
+	Long: `Inspect the environment Gas Town gives agents and test runs in a rig.
+
+A rig's environment profile lives in its settings/config.json:
+
+  "env": {
+    "tools":   {"go": "1.22.3", "node": "20"},
+    "path":    ["bin", "node_modules/.bin"],
+    "vars":    {"GOFLAGS": "-mod=mod", "DATA_DIR": "{rig}/data"},
+    "secrets": {"NPM_TOKEN": "env:NPM_TOKEN", "API_KEY": "file:~/.config/app/key"},
+    "roles":   ["refinery", "polecat", "crew", "witness"]
+  }
+
+The profile is materialized every time gt starts an agent session for the
+rig and every time the refinery runs tests, so every agent sees the same
+tools, PATH, and variables. Tool pins are exported for mise and asdf.
+Secrets are references, resolved when the environment is materialized.
+
+If the rig's repo declares a reproducible environment (flake.nix,
+shell.nix, or a devcontainer.json), work runs inside it:
+
+  "dev_env": {"mode": "auto", "roles": ["refinery", "polecat", "crew"]}
+
+Mode auto (the default) uses a declared environment when its tool (nix,
+nix-shell, devcontainer) is installed; nix or devcontainer require it;
+none runs on the host. Refinery test runs execute inside the environment.
+Agent sessions start with a Nix environment's variables; with a
+devcontainer, agents run on the host and use 'gt env exec' to build and
+test inside it.`,
+	RunE: requireSubcommand,
+}
+
+var envExecCmd = &cobra.Command{
+	Use:   "exec -- <command>...",
+	Short: "Run a command inside a rig's managed environment",
+	Long: `Run a command the way the refinery runs tests: inside the rig's dev
+environment (Nix or devcontainer) when it declares one, with the rig's
+environment profile applied. Runs in the current git repository, or in
+the rig's canonical clone when outside one.
+
+Examples:
+  gt env exec -- go test ./...
+  gt env exec --rig gastown -- make lint`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runEnvExec,
+}
+
+var envShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the environment agents get in a rig",
+	Long: `Show the variables an agent session or test run gets in a rig, and
+where each comes from: tool pins, PATH additions, variables, secrets, and
+shared build caches. Secret values are masked unless --reveal is given.
+
+Examples:
+  gt env show --rig gastown
+  gt env show --rig gastown --role polecat
+  gt env show --json`,
+	Args: cobra.NoArgs,
+	RunE: runEnvShow,
+}
+
+func init() {
+	envShowCmd.Flags().StringVar(&envRig, "rig", "", "Rig to show (default: inferred from cwd)")
+	envShowCmd.Flags().StringVar(&envRole, "role", "polecat", "Role whose environment to show")
+	envShowCmd.Flags().BoolVar(&envReveal, "reveal", false, "Show secret values")
+	envShowCmd.Flags().BoolVar(&envJSON, "json", false, "Output as JSON")
+
+	envExecCmd.Flags().StringVar(&envRig, "rig", "", "Rig whose environment to use (default: inferred from cwd)")
+	envExecCmd.Flags().StringVar(&envRole, "role", "refinery", "Role whose environment to use")
+
+	envCmd.AddCommand(envShowCmd)
+	envCmd.AddCommand(envExecCmd)
+	rootCmd.AddCommand(envCmd)
+}
+
+// EnvVar is one materialized variable and where it came from.
+type EnvVar struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Source string `json:"source"` // tool, path, var, secret, cache
+}
+
+// EnvShowOutput is the JSON output of gt env show.
+type EnvShowOutput struct {
+	Rig     string   `json:"rig"`
+	Role    string   `json:"role"`
+	Applies bool     `json:"applies"`
+	Vars    []EnvVar `json:"vars"`
+	Errors  []string `json:"errors,omitempty"`
+
+	// DevEnv is the environment work runs inside; Declared lists what the
+	// rig's repo declares.
+	DevEnv   *config.DevEnv  `json:"dev_env,omitempty"`
+	Declared []config.DevEnv `json:"declared,omitempty"`
+}
+
+// envRigFromFlags resolves --rig, falling back to the rig containing cwd.
+func envRigFromFlags() (*rig.Rig, error) {
+	rigName := envRig
+	if rigName == "" {
+		townRoot, err := workspace.FindFromCwdOrError()
+		if err != nil {
+			return nil, fmt.Errorf("not in a Gas Town workspace: %w", err)
+		}
+		if rigName, err = inferRigFromCwd(townRoot); err != nil || rigName == "" {
+			return nil, fmt.Errorf("could not determine rig (use --rig)")
+		}
+	}
+	_, r, err := getRig(rigName)
+	return r, err
+}
+
+func runEnvShow(cmd *cobra.Command, args []string) error {
+	r, err := envRigFromFlags()
+	if err != nil {
+		return err
+	}
+	settings, err := config.LoadRigSettings(config.RigSettingsPath(r.Path))
+	if err != nil {
+		settings = &config.RigSettings{}
+	}
+
+	out := EnvShowOutput{Rig: r.Name, Role: envRole}
+	for k, v := range config.BuildCacheEnv(r.Path, envRole, settings.BuildCache) {
+		out.Vars = append(out.Vars, EnvVar{Name: k, Value: v, Source: "cache"})
+	}
+	if settings.Env != nil {
+		out.Applies = config.EnvProfileAppliesTo(settings.Env, envRole)
+		env, err := config.EnvProfileEnv(r.Path, envRole, settings.Env, os.Getenv("PATH"))
+		if err != nil {
+			out.Errors = append(out.Errors, err.Error())
+		}
+		for k, v := range env {
+			source := envVarSource(settings.Env, k)
+			if source == "secret" && !envReveal {
+				v = maskSecret(v)
+			}
+			out.Vars = append(out.Vars, EnvVar{Name: k, Value: v, Source: source})
+		}
+	}
+	sort.Slice(out.Vars, func(i, j int) bool { return out.Vars[i].Name < out.Vars[j].Name })
+
+	repoDir := config.RigRepoDir(r.Path)
+	out.Declared = config.DetectDevEnvs(repoDir)
+	if out.DevEnv, err = config.ResolveDevEnv(repoDir, envRole, settings.DevEnv); err != nil {
+		out.Errors = append(out.Errors, err.Error())
+	}
+
+	if envJSON {
+		return outputJSON(out)
+	}
+
+	fmt.Printf("%s Environment for %s in %s\n\n", style.Bold.Render("🌐"), envRole, style.Bold.Render(r.Name))
+	switch {
+	case settings.Env == nil:
+		fmt.Printf("  %s\n", style.Dim.Render("No env profile configured (settings/config.json \"env\")"))
+	case !out.Applies:
+		fmt.Printf("  %s\n", style.Dim.Render(fmt.Sprintf("The env profile doesn't apply to %s (roles: %s)", envRole, strings.Join(settings.Env.Roles, ", "))))
+	}
+	switch {
+	case out.DevEnv != nil:
+		where := "test runs and agent sessions"
+		if out.DevEnv.Kind == config.DevEnvDevcontainer {
+			where = "test runs and 'gt env exec' (agents run on the host)"
+		}
+		fmt.Printf("  Dev environment: %s (%s) for %s\n\n", style.Bold.Render(out.DevEnv.Kind), out.DevEnv.File, where)
+	case len(out.Declared) > 0:
+		fmt.Printf("  Dev environment: %s\n\n", style.Dim.Render(fmt.Sprintf("%s declared but not used (mode none, role not covered, or %s not installed)",
+			out.Declared[0].File, out.Declared[0].Tool())))
+	}
+	if len(out.Vars) > 0 {
+		width := 0
+		for _, v := range out.Vars {
+			width = max(width, len(v.Name))
+		}
+		for _, v := range out.Vars {
+			fmt.Printf("  %-*s = %s  %s\n", width, v.Name, v.Value, style.Dim.Render("("+v.Source+")"))
+		}
+	}
+	for _, e := range out.Errors {
+		fmt.Printf("\n  %s %s\n", style.Warning.Render("⚠"), e)
+	}
+	return nil
+}
+
+func runEnvExec(cmd *cobra.Command, args []string) error {
+	r, err := envRigFromFlags()
+	if err != nil {
+		return err
+	}
+	dir, err := getGitRoot()
+	if err != nil || dir == "" {
+		dir = config.RigRepoDir(r.Path)
+	}
+	settings, err := config.LoadRigSettings(config.RigSettingsPath(r.Path))
+	if err != nil {
+		settings = &config.RigSettings{}
+	}
+
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = config.ShellQuote(a)
+	}
+	command := strings.Join(quoted, " ")
+	d, err := config.ResolveDevEnv(dir, envRole, settings.DevEnv)
+	if err != nil {
+		return err
+	}
+	if d != nil {
+		command = d.WrapCommand(dir, command)
+	}
+
+	env := config.BuildCacheEnv(r.Path, envRole, settings.BuildCache)
+	if settings.Env != nil {
+		profile, err := config.EnvProfileEnv(r.Path, envRole, settings.Env, os.Getenv("PATH"))
+		if err != nil {
+			style.PrintWarning("%v", err)
+		}
+		if env == nil {
+			env = profile
+		} else {
+			for k, v := range profile {
+				env[k] = v
+			}
+		}
+	}
+
+	c := exec.Command("sh", "-c", command) //nolint:gosec // G204: the user's own command
+	c.Dir = dir
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	c.Env = os.Environ()
+	for k, v := range env {
+		c.Env = append(c.Env, k+"="+v)
+	}
+	if err := c.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return NewSilentExit(exitErr.ExitCode())
+		}
+		return err
+	}
+	return nil
+}
+
+// envVarSource returns which part of a profile set variable k.
+func envVarSource(cfg *config.EnvProfileConfig, k string) string {
+	switch {
+	case cfg.Secrets[k] != "":
+		return "secret"
+	case k == "PATH":
+		return "path"
+	case k == "GT_TOOLS" || strings.HasSuffix(k, "_VERSION") && (strings.HasPrefix(k, "MISE_") || strings.HasPrefix(k, "ASDF_")):
+		if _, isVar := cfg.Vars[k]; !isVar {
+			return "tool"
+		}
+	}
+	return "var"
+}
+
+// maskSecret hides a secret value; an empty one stays visibly empty.
+func maskSecret(v string) string {
+	if v == "" {
+		return ""
+	}
+	return "********"
+}
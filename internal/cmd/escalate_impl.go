@@ -14,6 +14,7 @@ import (
 	"github.com/steveyegge/gastown/internal/events"
 	"github.com/steveyegge/gastown/internal/mail"
 	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/timefmt"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
@@ -150,8 +151,9 @@ func runEscalate(cmd *cobra.Command, args []string) error {
 		if escalateSource != "" {
 			result["source"] = escalateSource
 		}
-		out, _ := json.MarshalIndent(result, "", "  ")
-		fmt.Println(string(out))
+		if err := outputJSON(result); err != nil {
+			return err
+		}
 	} else {
 		emoji := severityEmoji(severity)
 		fmt.Printf("%s Escalation created: %s\n", emoji, issue.ID)
@@ -191,9 +193,7 @@ func runEscalateList(cmd *cobra.Command, args []string) error {
 	}
 
 	if escalateListJSON {
-		out, _ := json.MarshalIndent(issues, "", "  ")
-		fmt.Println(string(out))
-		return nil
+		return outputJSON(issues)
 	}
 
 	if len(issues) == 0 {
@@ -406,9 +406,7 @@ func runEscalateStale(cmd *cobra.Command, args []string) error {
 
 	// Output results
 	if escalateStaleJSON {
-		out, _ := json.MarshalIndent(results, "", "  ")
-		fmt.Println(string(out))
-		return nil
+		return outputJSON(results)
 	}
 
 	reescalated := 0
@@ -504,9 +502,7 @@ func runEscalateShow(cmd *cobra.Command, args []string) error {
 			"closedReason": fields.ClosedReason,
 			"relatedBead": fields.RelatedBead,
 		}
-		out, _ := json.MarshalIndent(data, "", "  ")
-		fmt.Println(string(out))
-		return nil
+		return outputJSON(data)
 	}
 
 	emoji := severityEmoji(fields.Severity)
@@ -625,34 +621,10 @@ func severityEmoji(severity string) string {
 }
 
 func formatRelativeTime(timestamp string) string {
-	t, err := time.Parse(time.RFC3339, timestamp)
-	if err != nil {
-		return timestamp
-	}
-
-	duration := time.Since(t)
-	if duration < time.Minute {
-		return "just now"
-	}
-	if duration < time.Hour {
-		mins := int(duration.Minutes())
-		if mins == 1 {
-			return "1 minute ago"
-		}
-		return fmt.Sprintf("%d minutes ago", mins)
-	}
-	if duration < 24*time.Hour {
-		hours := int(duration.Hours())
-		if hours == 1 {
-			return "1 hour ago"
-		}
-		return fmt.Sprintf("%d hours ago", hours)
-	}
-	days := int(duration.Hours() / 24)
-	if days == 1 {
-		return "1 day ago"
+	if ago := timefmt.AgoString(timestamp); ago != "" {
+		return ago
 	}
-	return fmt.Sprintf("%d days ago", days)
+	return timestamp
 }
 
 // detectSender is defined in mail_send.go - we reuse it here
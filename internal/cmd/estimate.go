@@ -0,0 +1,440 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/estimate"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Estimate command flags
+var (
+	estimateClear  bool
+	estimateJSON   bool
+	estimateBy     string
+	estimateConvoy string
+	estimateRig    string
+	estimateSince  string
+)
+
+var estimateCmd = &cobra.Command{
+	Use:     "estimate <issue> [size|hours]",
+	GroupID: GroupWork,
+	Short:   "Set or show an issue's effort estimate",
+	Long: `Set or show an issue's effort estimate.
+
+An estimate is a t-shirt size or a number of hours:
+
+  XS  1h     S  2h     M  4h     L  8h     XL  16h
+  6h, 1.5h   Hours, for when a size is too coarse
+
+Estimates are kept in an estimate:<value> label on the bead. Setting a new
+estimate replaces the old one.
+
+Examples:
+  gt estimate gt-abc M
+  gt estimate gt-abc 6h
+  gt estimate gt-abc            # Show the estimate
+  gt estimate gt-abc --clear
+  gt estimate rollup --by convoy
+  gt estimate stats --since 30d`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runEstimate,
+}
+
+var estimateRollupCmd = &cobra.Command{
+	Use:   "rollup",
+	Short: "Total estimates per rig or convoy",
+	Long: `Total the estimates of open work per rig, or of each open convoy's
+tracked issues.
+
+  ISSUES     Issues in the rig or convoy
+  ESTIMATED  How many of them have an estimate
+  HOURS      Estimated hours in all
+  DONE       Estimated hours of the closed issues (convoys)
+  LEFT       Estimated hours still open
+
+Unestimated issues count toward ISSUES but not the hours, so low coverage
+means the hours undercount.
+
+Examples:
+  gt estimate rollup                      # Open work per rig
+  gt estimate rollup --rig gastown
+  gt estimate rollup --by convoy          # Every open convoy
+  gt estimate rollup --convoy hq-cv-abc`,
+	Args: cobra.NoArgs,
+	RunE: runEstimateRollup,
+}
+
+var estimateStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Compare estimates with actual cycle time",
+	Long: `Compare estimates with how long estimated work actually took.
+
+For issues closed in the window, cycle time runs from the issue first
+being slung or hooked (or created, if it never was) to it closing. Issues
+are grouped by size; an hours estimate counts toward the smallest size
+that covers it.
+
+  ESTIMATE  Mean estimate for the size
+  MEDIAN    Median cycle time
+  RATIO     Median / estimate; above 1, work of this size runs long
+
+Cycle time is wall-clock time, so it includes time spent waiting; a
+steady ratio is more telling than its absolute value.
+
+Examples:
+  gt estimate stats
+  gt estimate stats --since 90d --rig gastown --json`,
+	Args: cobra.NoArgs,
+	RunE: runEstimateStats,
+}
+
+func init() {
+	estimateCmd.Flags().BoolVar(&estimateClear, "clear", false, "Remove the estimate")
+	estimateCmd.Flags().BoolVar(&estimateJSON, "json", false, "Output as JSON")
+
+	estimateRollupCmd.Flags().StringVar(&estimateBy, "by", "rig", "Roll up by rig or convoy")
+	estimateRollupCmd.Flags().StringVar(&estimateConvoy, "convoy", "", "Only this convoy (implies --by convoy)")
+	estimateRollupCmd.Flags().StringVar(&estimateRig, "rig", "", "Only this rig")
+	estimateRollupCmd.Flags().BoolVar(&estimateJSON, "json", false, "Output as JSON")
+
+	estimateStatsCmd.Flags().StringVar(&estimateSince, "since", "30d", "Window of closed issues (e.g., 30d, 90d)")
+	estimateStatsCmd.Flags().StringVar(&estimateRig, "rig", "", "Only this rig")
+	estimateStatsCmd.Flags().BoolVar(&estimateJSON, "json", false, "Output as JSON")
+
+	estimateCmd.AddCommand(estimateRollupCmd)
+	estimateCmd.AddCommand(estimateStatsCmd)
+	rootCmd.AddCommand(estimateCmd)
+}
+
+func runEstimate(cmd *cobra.Command, args []string) error {
+	id := args[0]
+	bd := beads.New(resolveBeadDir(id))
+	issue, err := bd.Show(id)
+	if err != nil {
+		return fmt.Errorf("looking up %s: %w", id, err)
+	}
+	current, has := estimate.FromLabels(issue.Labels)
+
+	switch {
+	case estimateClear:
+		if len(args) > 1 {
+			return fmt.Errorf("--clear takes no estimate")
+		}
+		old := estimate.Labels(issue.Labels)
+		if len(old) == 0 {
+			fmt.Printf("%s %s has no estimate\n", style.Dim.Render("○"), id)
+			return nil
+		}
+		if err := bd.Update(id, beads.UpdateOptions{RemoveLabels: old}); err != nil {
+			return fmt.Errorf("clearing estimate: %w", err)
+		}
+		fmt.Printf("%s Cleared estimate on %s\n", style.Success.Render("✓"), id)
+		return nil
+
+	case len(args) == 1:
+		if estimateJSON {
+			if !has {
+				return outputJSON(nil)
+			}
+			return outputJSON(current)
+		}
+		if !has {
+			fmt.Printf("%s %s has no estimate\n", style.Dim.Render("○"), id)
+			return nil
+		}
+		fmt.Printf("%s: %s\n", id, formatEstimate(current))
+		return nil
+	}
+
+	est, err := estimate.Parse(args[1])
+	if err != nil {
+		return err
+	}
+	var remove []string
+	for _, l := range estimate.Labels(issue.Labels) {
+		if l != est.Label() {
+			remove = append(remove, l)
+		}
+	}
+	if err := bd.Update(id, beads.UpdateOptions{AddLabels: []string{est.Label()}, RemoveLabels: remove}); err != nil {
+		return fmt.Errorf("setting estimate: %w", err)
+	}
+	if has && current.Value != est.Value {
+		fmt.Printf("%s %s: %s (was %s)\n", style.Success.Render("✓"), id, formatEstimate(est), current.Value)
+	} else {
+		fmt.Printf("%s %s: %s\n", style.Success.Render("✓"), id, formatEstimate(est))
+	}
+	return nil
+}
+
+// formatEstimate formats an estimate as "M (4h)", or "6h" for hours.
+func formatEstimate(e estimate.Estimate) string {
+	if _, ok := estimate.SizeHours[e.Value]; ok {
+		return fmt.Sprintf("%s (%sh)", e.Value, formatEstimateHours(e.Hours))
+	}
+	return e.Value
+}
+
+// formatEstimateHours formats hours to a tenth, dropping a trailing ".0".
+func formatEstimateHours(h float64) string {
+	return strings.TrimSuffix(fmt.Sprintf("%.1f", h), ".0")
+}
+
+func runEstimateRollup(cmd *cobra.Command, args []string) error {
+	if estimateConvoy != "" {
+		estimateBy = "convoy"
+	}
+	var items []estimate.Item
+	switch estimateBy {
+	case "rig":
+		rigs, _, err := getAllRigs()
+		if err != nil {
+			return err
+		}
+		for _, r := range rigs {
+			if estimateRig == "" || r.Name == estimateRig {
+				items = append(items, estimateRigItems(r)...)
+			}
+		}
+	case "convoy":
+		var err error
+		if items, err = estimateConvoyItems(estimateConvoy, estimateRig); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("invalid --by %q: want rig or convoy", estimateBy)
+	}
+
+	rollups := estimate.Roll(items)
+	if estimateJSON {
+		return outputJSON(rollups)
+	}
+	if len(rollups) == 0 {
+		fmt.Printf("%s No open work to roll up\n", style.Dim.Render("○"))
+		return nil
+	}
+	table := style.NewTable(
+		style.Column{Name: "NAME", Width: 24},
+		style.Column{Name: "ISSUES", Width: 7, Align: style.AlignRight},
+		style.Column{Name: "ESTIMATED", Width: 10, Align: style.AlignRight},
+		style.Column{Name: "HOURS", Width: 7, Align: style.AlignRight},
+		style.Column{Name: "DONE", Width: 7, Align: style.AlignRight},
+		style.Column{Name: "LEFT", Width: 7, Align: style.AlignRight},
+	)
+	for _, r := range rollups {
+		coverage := fmt.Sprintf("%d (%.0f%%)", r.Estimated, r.Coverage()*100)
+		if r.Coverage() < 0.5 {
+			coverage = style.Warning.Render(coverage)
+		}
+		table.AddRow(r.Name, fmt.Sprint(r.Issues), coverage,
+			formatEstimateHours(r.Hours), formatEstimateHours(r.DoneHours), formatEstimateHours(r.RemainingHours))
+	}
+	fmt.Print(table.Render())
+	return nil
+}
+
+// estimateRigItems returns a rig's open work, less merge requests and
+// identity beads, for a rollup.
+func estimateRigItems(r *rig.Rig) []estimate.Item {
+	bd := beads.New(r.BeadsPath())
+	wisps := getWispIDs(r.BeadsPath())
+	var items []estimate.Item
+	for _, status := range []string{"open", "in_progress"} {
+		issues, err := bd.List(beads.ListOptions{Status: status, Priority: -1})
+		if err != nil {
+			continue
+		}
+		for _, issue := range filterWisps(filterIdentityBeads(issues), wisps) {
+			if beads.HasLabel(issue, "gt:merge-request") || issue.Type == "merge-request" {
+				continue
+			}
+			items = append(items, estimateItem(issue, r.Name))
+		}
+	}
+	return items
+}
+
+// estimateConvoyItems returns the tracked issues of one convoy, or of every
+// open convoy, grouped by convoy.
+func estimateConvoyItems(convoyID, rigName string) ([]estimate.Item, error) {
+	townBeads, err := getTownBeadsDir()
+	if err != nil {
+		return nil, err
+	}
+	convoys := []string{convoyID}
+	if convoyID == "" {
+		if convoys, err = listOpenConvoyIDs(townBeads); err != nil {
+			return nil, err
+		}
+	}
+	townRoot, _ := workspace.FindFromCwd()
+	var items []estimate.Item
+	for _, id := range convoys {
+		tracked, err := getTrackedIssues(townBeads, id)
+		if err != nil {
+			if convoyID != "" {
+				return nil, err
+			}
+			style.PrintWarning("skipping convoy %s: %v", id, err)
+			continue
+		}
+		ids := make([]string, 0, len(tracked))
+		for _, t := range tracked {
+			if rigName == "" || beads.GetRigNameForPrefix(townRoot, beads.ExtractPrefix(t.ID)) == rigName {
+				ids = append(ids, t.ID)
+			}
+		}
+		for _, issue := range showIssuesAcrossRigs(ids) {
+			items = append(items, estimateItem(issue, id))
+		}
+	}
+	return items, nil
+}
+
+// listOpenConvoyIDs lists the IDs of the town's open convoys.
+func listOpenConvoyIDs(townBeads string) ([]string, error) {
+	listCmd := exec.Command("bd", "list", "--type=convoy", "--status=open", "--json")
+	listCmd.Dir = townBeads
+	var stdout bytes.Buffer
+	listCmd.Stdout = &stdout
+	if err := listCmd.Run(); err != nil {
+		return nil, fmt.Errorf("listing convoys: %w", err)
+	}
+	var convoys []struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &convoys); err != nil {
+		return nil, fmt.Errorf("parsing convoy list: %w", err)
+	}
+	ids := make([]string, len(convoys))
+	for i, c := range convoys {
+		ids[i] = c.ID
+	}
+	return ids, nil
+}
+
+// showIssuesAcrossRigs fetches issues from whichever rig each lives in,
+// one bd call per rig. Issues that can't be found are left out.
+func showIssuesAcrossRigs(ids []string) []*beads.Issue {
+	byDir := make(map[string][]string)
+	for _, id := range ids {
+		dir := resolveBeadDir(id)
+		byDir[dir] = append(byDir[dir], id)
+	}
+	var out []*beads.Issue
+	for dir, dirIDs := range byDir {
+		found, err := beads.New(dir).ShowMultiple(dirIDs)
+		if err != nil {
+			continue
+		}
+		for _, id := range dirIDs {
+			if issue, ok := found[id]; ok {
+				out = append(out, issue)
+			}
+		}
+	}
+	return out
+}
+
+func estimateItem(issue *beads.Issue, group string) estimate.Item {
+	it := estimate.Item{ID: issue.ID, Group: group, Closed: issue.Status == "closed"}
+	if est, ok := estimate.FromLabels(issue.Labels); ok {
+		it.Estimate = &est
+	}
+	return it
+}
+
+func runEstimateStats(cmd *cobra.Command, args []string) error {
+	window, err := parseDuration(estimateSince)
+	if err != nil || window <= 0 {
+		return fmt.Errorf("invalid --since %q", estimateSince)
+	}
+	since := time.Now().Add(-window)
+
+	rigs, townRoot, err := getAllRigs()
+	if err != nil {
+		return err
+	}
+	var closed []*beads.Issue
+	for _, r := range rigs {
+		if estimateRig != "" && r.Name != estimateRig {
+			continue
+		}
+		issues, err := beads.New(r.BeadsPath()).List(beads.ListOptions{Status: "closed", Priority: -1})
+		if err != nil {
+			continue
+		}
+		for _, issue := range issues {
+			if _, ok := estimate.FromLabels(issue.Labels); ok && !parseRetroTime(issue.ClosedAt).Before(since) {
+				closed = append(closed, issue)
+			}
+		}
+	}
+
+	// Work starts when the issue is first slung or hooked.
+	wanted := make(map[string]bool, len(closed))
+	for _, issue := range closed {
+		wanted[issue.ID] = true
+	}
+	started := make(map[string]time.Time)
+	evts, _ := events.Read(townRoot, func(e events.Event) bool {
+		return (e.Type == events.TypeSling || e.Type == events.TypeHook) && wanted[e.PayloadString("bead")]
+	})
+	for _, e := range evts {
+		id := e.PayloadString("bead")
+		if t, ok := started[id]; !ok || e.Time().Before(t) {
+			started[id] = e.Time()
+		}
+	}
+
+	var items []estimate.Item
+	for _, issue := range closed {
+		it := estimateItem(issue, "")
+		start, ok := started[issue.ID]
+		if !ok {
+			start = parseRetroTime(issue.CreatedAt)
+		}
+		if end := parseRetroTime(issue.ClosedAt); !start.IsZero() && end.After(start) {
+			it.Cycle = end.Sub(start)
+		}
+		items = append(items, it)
+	}
+
+	accuracy := estimate.Compare(items)
+	if estimateJSON {
+		return outputJSON(accuracy)
+	}
+	if len(accuracy) == 0 {
+		fmt.Printf("%s No estimated issues closed since %s\n", style.Dim.Render("○"), since.Local().Format(time.DateOnly))
+		return nil
+	}
+	table := style.NewTable(
+		style.Column{Name: "SIZE", Width: 5},
+		style.Column{Name: "ISSUES", Width: 7, Align: style.AlignRight},
+		style.Column{Name: "ESTIMATE", Width: 9, Align: style.AlignRight},
+		style.Column{Name: "MEDIAN", Width: 8, Align: style.AlignRight},
+		style.Column{Name: "RATIO", Width: 6, Align: style.AlignRight},
+	)
+	for _, a := range accuracy {
+		ratio := fmt.Sprintf("%.1fx", a.Ratio)
+		if a.Ratio > 2 {
+			ratio = style.Warning.Render(ratio)
+		}
+		table.AddRow(a.Size, fmt.Sprint(a.Count), formatEstimateHours(a.EstimatedHours)+"h",
+			formatPlanHours(a.MedianHours), ratio)
+	}
+	fmt.Print(table.Render())
+	return nil
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/tui/feed"
+	"github.com/steveyegge/gastown/internal/ui"
 	"github.com/steveyegge/gastown/internal/workspace"
 	"golang.org/x/term"
 )
@@ -192,6 +193,7 @@ func runFeedDirect(workDir string, bdArgs []string) error {
 		return fmt.Errorf("changing to directory %s: %w", workDir, err)
 	}
 
+	ui.StopASCIIFilter() // Flush filtered output before the exec replaces us
 	return syscall.Exec(bdPath, fullArgs, os.Environ())
 }
 
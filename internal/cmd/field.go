@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/customfield"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// Field command flags
+var (
+	fieldJSON bool
+	fieldRig  string
+)
+
+var fieldCmd = &cobra.Command{
+	Use:     "field",
+	GroupID: GroupWork,
+	Short:   "Set and show custom fields on issues",
+	RunE:    requireSubcommand,
+	Long: `Set and show custom fields on issues.
+
+Each rig defines the fields its issues may carry in its settings
+(settings/config.json), with a type that values are checked against:
+
+  "fields": {
+    "severity": {"type": "enum", "values": ["S1", "S2", "S3"]},
+    "customer": {"type": "string", "description": "Who asked for it"},
+    "cost":     {"type": "number"},
+    "spec":     {"type": "url"}
+  }
+
+Values are kept in field:<name>=<value> labels on the bead, appear as
+"fields" in JSON output, and filter 'gt ready' and 'gt blocked' with
+--field name=value.
+
+COMMANDS:
+  set      Set fields on an issue
+  unset    Clear fields on an issue
+  show     Show an issue's fields
+  list     Show the fields each rig defines`,
+}
+
+var fieldSetCmd = &cobra.Command{
+	Use:   "set <issue> <name=value> [name=value...]",
+	Short: "Set custom fields on an issue",
+	Long: `Set custom fields on an issue, replacing any values they had. Every
+value is checked against the rig's schema before anything is written.
+
+Examples:
+  gt field set gt-abc severity=S2 customer=acme
+  gt field set gt-abc spec=https://example.com/specs/42`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runFieldSet,
+}
+
+var fieldUnsetCmd = &cobra.Command{
+	Use:   "unset <issue> <name> [name...]",
+	Short: "Clear custom fields on an issue",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  runFieldUnset,
+}
+
+var fieldShowCmd = &cobra.Command{
+	Use:   "show <issue>",
+	Short: "Show an issue's custom fields",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runFieldShow,
+}
+
+var fieldListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show the custom fields each rig defines",
+	Args:  cobra.NoArgs,
+	RunE:  runFieldList,
+}
+
+func init() {
+	fieldShowCmd.Flags().BoolVar(&fieldJSON, "json", false, "Output as JSON")
+	fieldListCmd.Flags().BoolVar(&fieldJSON, "json", false, "Output as JSON")
+	fieldListCmd.Flags().StringVar(&fieldRig, "rig", "", "Only this rig")
+
+	fieldCmd.AddCommand(fieldSetCmd)
+	fieldCmd.AddCommand(fieldUnsetCmd)
+	fieldCmd.AddCommand(fieldShowCmd)
+	fieldCmd.AddCommand(fieldListCmd)
+
+	rootCmd.AddCommand(fieldCmd)
+}
+
+// fieldSchemaForBead loads the custom field schema of the rig owning a bead.
+func fieldSchemaForBead(id string) (string, customfield.Schema, error) {
+	rigName, r, err := getRigForBead(id)
+	if err != nil {
+		return "", nil, fmt.Errorf("custom fields are defined per rig: %w", err)
+	}
+	schema, err := customfield.Load(r.Path)
+	if err != nil {
+		return "", nil, fmt.Errorf("loading %s fields: %w", rigName, err)
+	}
+	return rigName, schema, nil
+}
+
+func runFieldSet(cmd *cobra.Command, args []string) error {
+	id := args[0]
+	rigName, schema, err := fieldSchemaForBead(id)
+	if err != nil {
+		return err
+	}
+	set := make(map[string]string, len(args)-1)
+	var names []string
+	for _, arg := range args[1:] {
+		name, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return fmt.Errorf("invalid field %q: want name=value", arg)
+		}
+		name = strings.TrimSpace(name)
+		value, err := schema.Normalize(name, value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", rigName, err)
+		}
+		if _, dup := set[name]; !dup {
+			names = append(names, name)
+		}
+		set[name] = value
+	}
+	if err := beads.New(resolveBeadDir(id)).UpdateCustomFields(id, set, nil); err != nil {
+		return fmt.Errorf("setting fields on %s: %w", id, err)
+	}
+	for _, name := range names {
+		fmt.Printf("%s %s: %s = %s\n", style.Success.Render("✓"), id, name, set[name])
+	}
+	return nil
+}
+
+func runFieldUnset(cmd *cobra.Command, args []string) error {
+	id := args[0]
+	if err := beads.New(resolveBeadDir(id)).UpdateCustomFields(id, nil, args[1:]); err != nil {
+		return fmt.Errorf("clearing fields on %s: %w", id, err)
+	}
+	fmt.Printf("%s Cleared %s on %s\n", style.Success.Render("✓"), strings.Join(args[1:], ", "), id)
+	return nil
+}
+
+func runFieldShow(cmd *cobra.Command, args []string) error {
+	id := args[0]
+	issue, err := beads.New(resolveBeadDir(id)).Show(id)
+	if err != nil {
+		return fmt.Errorf("looking up %s: %w", id, err)
+	}
+	fields := beads.CustomFields(issue.Labels)
+	if fieldJSON {
+		if fields == nil {
+			fields = map[string]string{}
+		}
+		return outputJSON(fields)
+	}
+	if len(fields) == 0 {
+		fmt.Printf("%s %s has no custom fields\n", style.Dim.Render("○"), id)
+		return nil
+	}
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("  %-14s %s\n", name+":", fields[name])
+	}
+	return nil
+}
+
+// FieldDefinition is one rig's custom field, for 'gt field list --json'.
+type FieldDefinition struct {
+	Rig         string   `json:"rig"`
+	Name        string   `json:"name"`
+	Type        string   `json:"type"`
+	Values      []string `json:"values,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Error       string   `json:"error,omitempty"`
+}
+
+func runFieldList(cmd *cobra.Command, args []string) error {
+	rigs, _, err := getAllRigs()
+	if err != nil {
+		return err
+	}
+	var defs []FieldDefinition
+	for _, r := range rigs {
+		if fieldRig != "" && r.Name != fieldRig {
+			continue
+		}
+		defs = append(defs, rigFieldDefinitions(r)...)
+	}
+	if fieldJSON {
+		if defs == nil {
+			defs = []FieldDefinition{}
+		}
+		return outputJSON(defs)
+	}
+	if len(defs) == 0 {
+		fmt.Printf("%s No custom fields defined (add \"fields\" to a rig's settings/config.json)\n", style.Dim.Render("○"))
+		return nil
+	}
+	table := style.NewTable(
+		style.Column{Name: "RIG", Width: 14},
+		style.Column{Name: "FIELD", Width: 14},
+		style.Column{Name: "TYPE", Width: 7},
+		style.Column{Name: "VALUES / DESCRIPTION", Width: 44},
+	)
+	for _, d := range defs {
+		if d.Error != "" {
+			table.AddRow(d.Rig, "-", "-", style.Error.Render(d.Error))
+			continue
+		}
+		detail := d.Description
+		if len(d.Values) > 0 {
+			detail = strings.Join(d.Values, " | ")
+		}
+		table.AddRow(d.Rig, d.Name, d.Type, detail)
+	}
+	fmt.Print(table.Render())
+	return nil
+}
+
+// rigFieldDefinitions lists a rig's custom fields, or the error loading them.
+func rigFieldDefinitions(r *rig.Rig) []FieldDefinition {
+	schema, err := customfield.Load(r.Path)
+	if err != nil {
+		return []FieldDefinition{{Rig: r.Name, Error: err.Error()}}
+	}
+	defs := make([]FieldDefinition, 0, len(schema))
+	for _, name := range schema.Names() {
+		def := schema[name]
+		defs = append(defs, FieldDefinition{Rig: r.Name, Name: name, Type: def.Type, Values: def.Values, Description: def.Description})
+	}
+	return defs
+}
+
+// filterByCustomFields fills in the issues' custom fields and keeps those
+// matching every filter.
+func filterByCustomFields(issues []*beads.Issue, filters []customfield.Filter) []*beads.Issue {
+	beads.FillCustomFields(issues)
+	if len(filters) == 0 {
+		return issues
+	}
+	var matched []*beads.Issue
+	for _, issue := range issues {
+		if customfield.Match(issue.Fields, filters) {
+			matched = append(matched, issue)
+		}
+	}
+	return matched
+}
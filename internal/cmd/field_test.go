@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/customfield"
+)
+
+func TestFilterByCustomFields(t *testing.T) {
+	issues := []*beads.Issue{
+		{ID: "gt-1", Labels: []string{"field:severity=S1", "field:customer=acme"}},
+		{ID: "gt-2", Labels: []string{"field:severity=S2"}},
+		{ID: "gt-3"},
+	}
+
+	all := filterByCustomFields(issues, nil)
+	if len(all) != 3 || all[0].Fields["customer"] != "acme" || all[2].Fields != nil {
+		t.Fatalf("unfiltered = %+v", all)
+	}
+
+	matched := filterByCustomFields(issues, []customfield.Filter{{Name: "severity", Value: "s1"}})
+	if len(matched) != 1 || matched[0].ID != "gt-1" {
+		t.Errorf("severity=s1 matched %v", matched)
+	}
+	if got := filterByCustomFields(issues, []customfield.Filter{{Name: "customer", Value: "globex"}}); len(got) != 0 {
+		t.Errorf("customer=globex matched %v", got)
+	}
+}
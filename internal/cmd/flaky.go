@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/flaky"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// Flaky command flags
+var (
+	flakyListAll  bool
+	flakyListJSON bool
+
+	flakyAddReason   string
+	flakyAddEvidence []string
+	flakyAddTTL      string
+
+	flakyExpireAll bool
+)
+
+var flakyCmd = &cobra.Command{
+	Use:     "flaky",
+	GroupID: GroupWork,
+	Short:   "Manage the flaky-test quarantine registry",
+	RunE:    requireSubcommand,
+	Long: `Manage a rig's registry of known-flaky tests.
+
+Quarantined tests are stored in <rig>/settings/quarantine.json. When the
+refinery's test run fails, the rig's merge_queue.quarantine_policy decides
+what happens to failures of quarantined tests:
+
+  retry  Runs failing only on quarantined tests get quarantine_retries
+         attempts (default 3) instead of retry_flaky_tests (default)
+  skip   Quarantined failures are ignored; the MR is not blocked by them
+  off    The registry is ignored
+
+Tests are identified as package.Test (as shown by 'gt mq triage'), a bare
+test name matching any package, or a glob. Subtests inherit their parent's
+quarantine.`,
+}
+
+var flakyListCmd = &cobra.Command{
+	Use:   "list <rig>",
+	Short: "List quarantined tests",
+	Long: `List quarantined tests for a rig.
+
+Expired entries are hidden unless --all is given.
+
+Examples:
+  gt flaky list gastown
+  gt flaky list gastown --all --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runFlakyList,
+}
+
+var flakyAddCmd = &cobra.Command{
+	Use:   "add <rig> <test>",
+	Short: "Quarantine a flaky test",
+	Long: `Add a test to a rig's quarantine registry.
+
+Adding a test that is already quarantined updates its reason and expiry and
+appends any new evidence.
+
+Examples:
+  gt flaky add gastown example.com/net.TestDial --reason "DNS timeouts" --evidence gt-abc12
+  gt flaky add gastown 'example.com/e2e.Test*' --ttl 7d --evidence https://ci.example.com/run/123`,
+	Args: cobra.ExactArgs(2),
+	RunE: runFlakyAdd,
+}
+
+var flakyExpireCmd = &cobra.Command{
+	Use:   "expire <rig> [test...]",
+	Short: "Remove tests from quarantine",
+	Long: `Remove tests from a rig's quarantine registry.
+
+With test arguments, those tests are released from quarantine immediately.
+With --all, every entry is removed. With neither, only entries past their
+expiry are pruned (suitable for a periodic patrol).
+
+Examples:
+  gt flaky expire gastown                          # Prune expired entries
+  gt flaky expire gastown example.com/net.TestDial # Release one test
+  gt flaky expire gastown --all`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runFlakyExpire,
+}
+
+func init() {
+	flakyListCmd.Flags().BoolVar(&flakyListAll, "all", false, "Include expired entries")
+	flakyListCmd.Flags().BoolVar(&flakyListJSON, "json", false, "Output as JSON")
+
+	flakyAddCmd.Flags().StringVarP(&flakyAddReason, "reason", "r", "", "Why the test is quarantined")
+	flakyAddCmd.Flags().StringArrayVarP(&flakyAddEvidence, "evidence", "e", nil, "Evidence link or bead ID (repeatable)")
+	flakyAddCmd.Flags().StringVar(&flakyAddTTL, "ttl", "", "Expire the quarantine after this long (e.g., 14d, 72h)")
+
+	flakyExpireCmd.Flags().BoolVar(&flakyExpireAll, "all", false, "Remove all entries")
+
+	flakyCmd.AddCommand(flakyListCmd)
+	flakyCmd.AddCommand(flakyAddCmd)
+	flakyCmd.AddCommand(flakyExpireCmd)
+	rootCmd.AddCommand(flakyCmd)
+}
+
+func runFlakyList(cmd *cobra.Command, args []string) error {
+	_, r, err := getRig(args[0])
+	if err != nil {
+		return err
+	}
+
+	reg, err := flaky.Load(r.Path)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	entries := make([]*flaky.Entry, 0, len(reg.Tests))
+	for _, e := range reg.Tests {
+		if flakyListAll || !e.Expired(now) {
+			entries = append(entries, e)
+		}
+	}
+
+	if flakyListJSON {
+		return outputJSON(entries)
+	}
+
+	fmt.Printf("%s Quarantined tests for '%s':\n\n", style.Bold.Render("🧪"), r.Name)
+	if len(entries) == 0 {
+		fmt.Printf("  %s\n", style.Dim.Render("(none)"))
+		return nil
+	}
+
+	table := style.NewTable(
+		style.Column{Name: "TEST", Width: 40},
+		style.Column{Name: "EXPIRES", Width: 12},
+		style.Column{Name: "EVIDENCE", Width: 20},
+		style.Column{Name: "REASON", Width: 30},
+	)
+	for _, e := range entries {
+		expires := style.Dim.Render("never")
+		if e.ExpiresAt != nil {
+			if e.Expired(now) {
+				expires = style.Warning.Render("expired")
+			} else {
+				expires = e.ExpiresAt.Local().Format("2006-01-02")
+			}
+		}
+		table.AddRow(e.Test, expires, strings.Join(e.Evidence, ", "), e.Reason)
+	}
+	fmt.Print(table.Render())
+	return nil
+}
+
+func runFlakyAdd(cmd *cobra.Command, args []string) error {
+	_, r, err := getRig(args[0])
+	if err != nil {
+		return err
+	}
+	test := args[1]
+
+	entry := flaky.Entry{
+		Test:     test,
+		Reason:   flakyAddReason,
+		Evidence: flakyAddEvidence,
+		AddedBy:  detectSender(),
+		AddedAt:  time.Now().UTC(),
+	}
+	if flakyAddTTL != "" {
+		ttl, err := parseDuration(flakyAddTTL)
+		if err != nil {
+			return fmt.Errorf("invalid --ttl: %w", err)
+		}
+		expires := entry.AddedAt.Add(ttl)
+		entry.ExpiresAt = &expires
+	}
+
+	reg, err := flaky.Load(r.Path)
+	if err != nil {
+		return err
+	}
+	existed := reg.Get(test) != nil
+	reg.Add(entry)
+	if err := flaky.Save(r.Path, reg); err != nil {
+		return err
+	}
+
+	if existed {
+		fmt.Printf("%s Updated quarantine for %s\n", style.Success.Render("✓"), test)
+	} else {
+		fmt.Printf("%s Quarantined %s in %s\n", style.Success.Render("✓"), test, r.Name)
+	}
+	return nil
+}
+
+func runFlakyExpire(cmd *cobra.Command, args []string) error {
+	_, r, err := getRig(args[0])
+	if err != nil {
+		return err
+	}
+	tests := args[1:]
+
+	reg, err := flaky.Load(r.Path)
+	if err != nil {
+		return err
+	}
+
+	var removed []string
+	switch {
+	case flakyExpireAll:
+		for _, e := range reg.Tests {
+			removed = append(removed, e.Test)
+		}
+		reg.Tests = nil
+	case len(tests) > 0:
+		for _, test := range tests {
+			if !reg.Remove(test) {
+				return fmt.Errorf("%s is not quarantined in %s", test, r.Name)
+			}
+			removed = append(removed, test)
+		}
+	default:
+		for _, e := range reg.Prune(time.Now()) {
+			removed = append(removed, e.Test)
+		}
+	}
+
+	if len(removed) == 0 {
+		fmt.Printf("%s No quarantine entries to expire\n", style.Dim.Render("○"))
+		return nil
+	}
+	if err := flaky.Save(r.Path, reg); err != nil {
+		return err
+	}
+	for _, test := range removed {
+		fmt.Printf("%s Released %s from quarantine\n", style.Success.Render("✓"), test)
+	}
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"text/template"
@@ -30,7 +31,14 @@ var (
 	formulaRunPR      int
 	formulaRunRig     string
 	formulaRunDryRun  bool
+	formulaRunIssue   string
+	formulaRunRetry   bool
+	formulaRunDone    []string
+	formulaRunVars    []string
 	formulaCreateType string
+	formulaRenderVars []string
+	formulaRenderRig  string
+	formulaRenderJSON bool
 )
 
 var formulaCmd = &cobra.Command{
@@ -49,6 +57,9 @@ Commands:
   list    List available formulas from all search paths
   show    Display formula details (steps, variables, composition)
   run     Execute a formula (pour and dispatch)
+  render  Preview a formula with its variables expanded
+  status  Show formula runs against an issue
+  check   Lint a formula and preview a run against an issue
   create  Create a new formula template
 
 Search paths (in order):
@@ -60,6 +71,7 @@ Examples:
   gt formula list                    # List all formulas
   gt formula show shiny              # Show formula details
   gt formula run shiny --pr=123      # Run formula on PR #123
+  gt formula render shiny --var feature=auth
   gt formula create my-workflow      # Create new formula template`,
 }
 
@@ -109,6 +121,22 @@ This command:
 
 For PR-based workflows, use --pr to specify the GitHub PR number.
 
+With --issue, a workflow formula's steps are executed against the issue
+in dependency order: steps with a run command are run as shell commands,
+and the run pauses at agent steps (no run command). Each step's status is
+recorded on a wisp bead under the issue, so running the same command again
+resumes where it stopped. See 'gt formula status <issue>'.
+
+Ready steps with parallel = true run together, and a step that needs them
+waits for all of them. A step with a when condition runs only if it holds:
+
+  [[steps]]
+  id = "migrate"
+  needs = ["detect"]
+  run = "make migrate"
+  when = { step = "detect", output = "schema changed" }  # or label = "db",
+                                                         # or files = "*.sql"
+
 If no formula name is provided, uses the default formula configured in
 the rig's settings/config.json under workflow.default_formula.
 
@@ -116,17 +144,51 @@ Options:
   --pr=N      Run formula on GitHub PR #N
   --rig=NAME  Target specific rig (default: current or gastown)
   --dry-run   Show what would happen without executing
+  --issue=ID  Execute a workflow formula's steps against an issue
+  --retry     With --issue, rerun failed steps
+  --done=STEP With --issue, mark an agent step done and continue
 
 Examples:
   gt formula run shiny                    # Run formula in current rig
   gt formula run                          # Run default formula from rig config
   gt formula run shiny --pr=123           # Run on PR #123
   gt formula run security-audit --rig=beads  # Run in specific rig
-  gt formula run release --dry-run        # Preview execution`,
+  gt formula run release --dry-run        # Preview execution
+  gt formula run ship --issue gt-abc      # Execute steps against an issue
+  gt formula run ship --issue gt-abc --retry
+  gt formula run ship --issue gt-abc --done review`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runFormulaRun,
 }
 
+var formulaRenderCmd = &cobra.Command{
+	Use:   "render <name>",
+	Short: "Preview a formula with its variables expanded",
+	Long: `Show a formula as it will run: with the formulas it extends or includes
+merged in and its {{var}} placeholders expanded.
+
+Composition:
+  extends = ["shiny"]     Inherit a parent's vars and steps; steps with the
+                          same id replace the parent's, new steps are appended
+  includes = ["lint"]     Pull in another formula's steps and vars
+
+Var values come from, in order:
+  1. --var key=value
+  2. The rig's settings/config.json (workflow.formula_vars.<name>, then
+     workflow.vars)
+  3. The var's default in [vars]
+
+Vars with no value and no default are left as {{placeholders}} for bd to
+fill in. Missing required vars are an error.
+
+Examples:
+  gt formula render shiny --var feature=auth
+  gt formula render shiny-secure --var feature=auth --rig beads
+  gt formula render release --var version=1.2.0 --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runFormulaRender,
+}
+
 var formulaCreateCmd = &cobra.Command{
 	Use:   "create <name>",
 	Short: "Create a new formula template",
@@ -159,6 +221,15 @@ func init() {
 	formulaRunCmd.Flags().IntVar(&formulaRunPR, "pr", 0, "GitHub PR number to run formula on")
 	formulaRunCmd.Flags().StringVar(&formulaRunRig, "rig", "", "Target rig (default: current or gastown)")
 	formulaRunCmd.Flags().BoolVar(&formulaRunDryRun, "dry-run", false, "Preview execution without running")
+	formulaRunCmd.Flags().StringVar(&formulaRunIssue, "issue", "", "Run a workflow formula's steps against this issue")
+	formulaRunCmd.Flags().BoolVar(&formulaRunRetry, "retry", false, "With --issue: rerun failed steps")
+	formulaRunCmd.Flags().StringSliceVar(&formulaRunDone, "done", nil, "With --issue: mark agent steps done before resuming (repeatable)")
+	formulaRunCmd.Flags().StringArrayVar(&formulaRunVars, "var", nil, "With --issue: variable as key=value (repeatable)")
+
+	// Render flags
+	formulaRenderCmd.Flags().StringArrayVar(&formulaRenderVars, "var", nil, "Variable as key=value (repeatable)")
+	formulaRenderCmd.Flags().StringVar(&formulaRenderRig, "rig", "", "Apply this rig's var overrides (default: current rig)")
+	formulaRenderCmd.Flags().BoolVar(&formulaRenderJSON, "json", false, "Output as JSON")
 
 	// Create flags
 	formulaCreateCmd.Flags().StringVar(&formulaCreateType, "type", "task", "Formula type: task, workflow, or patrol")
@@ -167,6 +238,7 @@ func init() {
 	formulaCmd.AddCommand(formulaListCmd)
 	formulaCmd.AddCommand(formulaShowCmd)
 	formulaCmd.AddCommand(formulaRunCmd)
+	formulaCmd.AddCommand(formulaRenderCmd)
 	formulaCmd.AddCommand(formulaCreateCmd)
 
 	rootCmd.AddCommand(formulaCmd)
@@ -266,6 +338,10 @@ func runFormulaRun(cmd *cobra.Command, args []string) error {
 		return dryRunFormula(f, formulaName, targetRig)
 	}
 
+	if formulaRunIssue != "" {
+		return executeFormulaOnIssue(f, formulaName, rigPath)
+	}
+
 	// Currently only convoy formulas are supported for execution
 	if f.Type != formula.TypeConvoy {
 		fmt.Printf("%s Formula type '%s' not yet supported for execution.\n",
@@ -383,9 +459,7 @@ func executeConvoyFormula(f *formula.Formula, formulaName, targetRig string) err
 	// Step 1: Create convoy bead
 	convoyID := fmt.Sprintf("hq-cv-%s", generateFormulaShortID())
 	convoyTitle := fmt.Sprintf("%s: %s", formulaName, f.Description)
-	if len(convoyTitle) > 80 {
-		convoyTitle = convoyTitle[:77] + "..."
-	}
+	convoyTitle = style.Truncate(convoyTitle, 80)
 
 	// Build description with formula context
 	description := fmt.Sprintf("Formula convoy: %s\n\nLegs: %d\nRig: %s",
@@ -629,10 +703,141 @@ func executeConvoyFormula(f *formula.Formula, formulaName, targetRig string) err
 	return nil
 }
 
-// findFormulaFile searches for a formula file by name
-func findFormulaFile(name string) (string, error) {
-	// Search paths in order
-	searchPaths := []string{}
+// runFormulaRender prints a formula with composition resolved and vars expanded.
+func runFormulaRender(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	path, err := findFormulaFile(name)
+	if err != nil {
+		return err
+	}
+	f, err := parseFormulaFile(path)
+	if err != nil {
+		return fmt.Errorf("parsing formula: %w", err)
+	}
+
+	given, err := parseFormulaVars(formulaRenderVars)
+	if err != nil {
+		return err
+	}
+
+	var overrides map[string]string
+	if rigPath := formulaRigPath(formulaRenderRig); rigPath != "" {
+		overrides = config.GetFormulaVars(rigPath, name)
+	}
+	values, err := f.ResolveVars(given, overrides)
+	if err != nil {
+		return err
+	}
+	out := f.Render(values)
+
+	if formulaRenderJSON {
+		return outputJSON(map[string]interface{}{
+			"formula": out,
+			"vars":    values,
+		})
+	}
+
+	fmt.Printf("%s (%s)\n", style.Bold.Render(out.Name), out.Type)
+	if len(f.Extends) > 0 || len(f.Includes) > 0 {
+		var parts []string
+		if len(f.Extends) > 0 {
+			parts = append(parts, "extends "+strings.Join(f.Extends, ", "))
+		}
+		if len(f.Includes) > 0 {
+			parts = append(parts, "includes "+strings.Join(f.Includes, ", "))
+		}
+		fmt.Printf("  %s\n", style.Dim.Render(strings.Join(parts, "; ")))
+	}
+	if out.Description != "" {
+		fmt.Printf("\n%s\n", out.Description)
+	}
+
+	if len(f.Vars) > 0 {
+		fmt.Printf("\n%s\n", style.Bold.Render("Vars:"))
+		varNames := make([]string, 0, len(f.Vars))
+		for k := range f.Vars {
+			varNames = append(varNames, k)
+		}
+		sort.Strings(varNames)
+		for _, k := range varNames {
+			if v, ok := values[k]; ok {
+				fmt.Printf("  %s = %s\n", k, v)
+			} else {
+				fmt.Printf("  %s %s\n", k, style.Dim.Render("(unset)"))
+			}
+		}
+	}
+
+	printItem := func(id, title, description string, needs []string) {
+		fmt.Printf("  %s  %s\n", style.Bold.Render(id), title)
+		if len(needs) > 0 {
+			fmt.Printf("    %s\n", style.Dim.Render("needs: "+strings.Join(needs, ", ")))
+		}
+		if description != "" {
+			fmt.Printf("    %s\n", strings.ReplaceAll(strings.TrimSpace(description), "\n", "\n    "))
+		}
+	}
+	switch out.Type {
+	case formula.TypeWorkflow:
+		fmt.Printf("\n%s\n", style.Bold.Render("Steps:"))
+		for _, st := range out.Steps {
+			printItem(st.ID, st.Title, st.Description, st.Needs)
+		}
+	case formula.TypeExpansion:
+		fmt.Printf("\n%s\n", style.Bold.Render("Templates:"))
+		for _, t := range out.Template {
+			printItem(t.ID, t.Title, t.Description, t.Needs)
+		}
+	case formula.TypeConvoy:
+		fmt.Printf("\n%s\n", style.Bold.Render("Legs:"))
+		for _, l := range out.Legs {
+			printItem(l.ID, l.Title, l.Description, nil)
+		}
+		if out.Synthesis != nil {
+			fmt.Printf("\n%s\n", style.Bold.Render("Synthesis:"))
+			printItem("synthesis", out.Synthesis.Title, out.Synthesis.Description, out.Synthesis.DependsOn)
+		}
+	case formula.TypeAspect:
+		fmt.Printf("\n%s\n", style.Bold.Render("Aspects:"))
+		for _, a := range out.Aspects {
+			printItem(a.ID, a.Title, a.Description, nil)
+		}
+	}
+	return nil
+}
+
+// parseFormulaVars parses --var key=value flags.
+func parseFormulaVars(args []string) (map[string]string, error) {
+	vars := make(map[string]string, len(args))
+	for _, kv := range args {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid --var %q (want key=value)", kv)
+		}
+		vars[k] = v
+	}
+	return vars, nil
+}
+
+// formulaRigPath returns the rig whose var overrides apply: rigName, or
+// the rig the current directory is in. Returns "" outside a rig.
+func formulaRigPath(rigName string) string {
+	townRoot, err := workspace.FindFromCwd()
+	if err != nil || townRoot == "" {
+		return ""
+	}
+	if rigName != "" {
+		return filepath.Join(townRoot, rigName)
+	}
+	if _, r, err := findCurrentRig(townRoot); err == nil && r != nil {
+		return r.Path
+	}
+	return ""
+}
+
+// formulaSearchPaths returns the directories formulas are looked up in.
+func formulaSearchPaths() []string {
+	var searchPaths []string
 
 	// 1. Project .beads/formulas/
 	if cwd, err := os.Getwd(); err == nil {
@@ -649,23 +854,19 @@ func findFormulaFile(name string) (string, error) {
 		searchPaths = append(searchPaths, filepath.Join(home, ".beads", "formulas"))
 	}
 
-	// Try each path with common extensions
-	extensions := []string{".formula.toml", ".formula.json"}
-	for _, basePath := range searchPaths {
-		for _, ext := range extensions {
-			path := filepath.Join(basePath, name+ext)
-			if _, err := os.Stat(path); err == nil {
-				return path, nil
-			}
-		}
-	}
+	return searchPaths
+}
 
-	return "", fmt.Errorf("formula '%s' not found in search paths", name)
+// findFormulaFile searches for a formula file by name
+func findFormulaFile(name string) (string, error) {
+	return (&formula.Loader{Dirs: formulaSearchPaths()}).Find(name)
 }
 
-// parseFormulaFile parses a formula file using the formula package's TOML parser.
+// parseFormulaFile parses a formula file, resolving the formulas it extends
+// or includes from its own directory first, then the search paths.
 func parseFormulaFile(path string) (*formula.Formula, error) {
-	return formula.ParseFile(path)
+	dirs := append([]string{filepath.Dir(path)}, formulaSearchPaths()...)
+	return (&formula.Loader{Dirs: dirs}).ParseFile(path)
 }
 
 // renderTemplate renders a Go text/template with the given context map
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/formula"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// Formula check flags
+var (
+	formulaCheckAgainst string
+	formulaCheckVars    []string
+	formulaCheckRig     string
+	formulaCheckJSON    bool
+)
+
+var formulaCheckCmd = &cobra.Command{
+	Use:   "check <name>",
+	Short: "Lint a formula and preview a run against an issue",
+	Long: `Check a formula before changing what agents run.
+
+Always: parses the formula (with extends and includes resolved), validates
+it, resolves its vars, and lints for problems that only show up at run
+time: undefined {{variables}}, run commands left with unexpanded
+placeholders, and parallel flags with no parallel peer.
+
+With --against, also reports what 'gt formula run <name> --issue <issue>'
+would do, step by step, without running anything: which steps run (and in
+which parallel batch), which are skipped by their when conditions (using
+the issue's labels and the current branch's changed files), which wait for
+an agent, and which depend on output not produced yet. An earlier run's
+state on the issue is taken into account.
+
+Exits 1 if the formula has problems.
+
+Examples:
+  gt formula check ship
+  gt formula check ship --against gt-abc
+  gt formula check ship --against gt-abc --var target=./cmd/... --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runFormulaCheck,
+}
+
+func init() {
+	formulaCheckCmd.Flags().StringVar(&formulaCheckAgainst, "against", "", "Preview a run against this issue")
+	formulaCheckCmd.Flags().StringArrayVar(&formulaCheckVars, "var", nil, "Variable as key=value (repeatable)")
+	formulaCheckCmd.Flags().StringVar(&formulaCheckRig, "rig", "", "Apply this rig's var overrides (default: current rig)")
+	formulaCheckCmd.Flags().BoolVar(&formulaCheckJSON, "json", false, "Output as JSON")
+	formulaCmd.AddCommand(formulaCheckCmd)
+}
+
+// formulaCheckResult is the JSON form of a check.
+type formulaCheckResult struct {
+	Formula  string                `json:"formula"`
+	OK       bool                  `json:"ok"`
+	Problems []string              `json:"problems,omitempty"`
+	Vars     map[string]string     `json:"vars,omitempty"`
+	Issue    string                `json:"issue,omitempty"`
+	Plan     []formula.PlannedStep `json:"plan,omitempty"`
+}
+
+func runFormulaCheck(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	res := formulaCheckResult{Formula: name}
+
+	path, err := findFormulaFile(name)
+	if err != nil {
+		return err
+	}
+	f, err := parseFormulaFile(path)
+	if err != nil {
+		res.Problems = append(res.Problems, err.Error())
+		return reportFormulaCheck(res)
+	}
+
+	given, err := parseFormulaVars(formulaCheckVars)
+	if err != nil {
+		return err
+	}
+	var overrides map[string]string
+	if rigPath := formulaRigPath(formulaCheckRig); rigPath != "" {
+		overrides = config.GetFormulaVars(rigPath, name)
+	}
+	values, err := f.ResolveVars(given, overrides)
+	if err != nil {
+		res.Problems = append(res.Problems, err.Error())
+	}
+	res.Vars = values
+	res.Problems = append(res.Problems, f.Lint(values)...)
+
+	if formulaCheckAgainst != "" {
+		res.Issue = formulaCheckAgainst
+		exec, err := newFormulaExecutor(f, formulaCheckAgainst, values)
+		if err != nil {
+			return err
+		}
+		if res.Plan, err = exec.Plan(); err != nil {
+			res.Problems = append(res.Problems, err.Error())
+		}
+	}
+	return reportFormulaCheck(res)
+}
+
+// reportFormulaCheck prints a check result and sets the exit code.
+func reportFormulaCheck(res formulaCheckResult) error {
+	res.OK = len(res.Problems) == 0
+	if formulaCheckJSON {
+		if err := outputJSON(res); err != nil {
+			return err
+		}
+	} else {
+		printFormulaCheck(res)
+	}
+	if !res.OK {
+		return NewSilentExit(1)
+	}
+	return nil
+}
+
+func printFormulaCheck(res formulaCheckResult) {
+	if res.OK {
+		fmt.Printf("%s %s: no problems\n", style.Success.Render("✓"), style.Bold.Render(res.Formula))
+	} else {
+		fmt.Printf("%s %s: %d problem(s)\n", style.Error.Render("✗"), style.Bold.Render(res.Formula), len(res.Problems))
+		for _, p := range res.Problems {
+			fmt.Printf("  - %s\n", p)
+		}
+	}
+	if len(res.Plan) == 0 {
+		return
+	}
+
+	fmt.Printf("\n%s\n", style.Bold.Render("Against "+res.Issue+" (nothing is run):"))
+	for _, ps := range res.Plan {
+		batch := "  "
+		if ps.Group > 0 {
+			batch = fmt.Sprintf("%2d", ps.Group)
+		}
+		fmt.Printf("  %s %s %-8s %s", style.Dim.Render(batch), formulaPlanIcon(ps.Action), ps.Action, ps.ID)
+		if ps.Title != "" {
+			fmt.Printf("  %s", style.Dim.Render(ps.Title))
+		}
+		fmt.Println()
+		if ps.Reason != "" {
+			fmt.Printf("               %s\n", style.Dim.Render(ps.Reason))
+		}
+		if ps.Command != "" && (ps.Action == formula.PlanRun || ps.Action == formula.PlanMaybe || ps.Action == formula.PlanRetry) {
+			fmt.Printf("               $ %s\n", strings.ReplaceAll(strings.TrimSpace(ps.Command), "\n", "\n                 "))
+		}
+	}
+}
+
+func formulaPlanIcon(a formula.PlanAction) string {
+	switch a {
+	case formula.PlanRun:
+		return style.Success.Render("▶")
+	case formula.PlanDone:
+		return style.Success.Render("✓")
+	case formula.PlanSkip:
+		return style.Dim.Render("-")
+	case formula.PlanRetry:
+		return style.Error.Render("↻")
+	default:
+		return style.Warning.Render("●")
+	}
+}
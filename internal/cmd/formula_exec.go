@@ -0,0 +1,266 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/formula"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// formulaRunLabel marks the wisp beads that hold formula run state.
+const formulaRunLabel = "gt:formula-run"
+
+var formulaStatusJSON bool
+
+var formulaStatusCmd = &cobra.Command{
+	Use:   "status <issue>",
+	Short: "Show formula runs against an issue",
+	Long: `Show the state of each formula run against an issue, step by step.
+
+Runs are started with 'gt formula run <name> --issue <issue>'. Their state
+lives on a wisp bead under the issue, so a failed or interrupted run can be
+picked up where it stopped.
+
+Step states:
+  pending   Not started
+  running   Started; if the run was interrupted it starts over on resume
+  done      Finished
+  failed    Command failed (resume with --retry)
+  waiting   Agent step; resume with --done <step> once it's finished
+  skipped   The step's when condition didn't hold
+
+Examples:
+  gt formula status gt-abc
+  gt formula status gt-abc --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runFormulaStatus,
+}
+
+func init() {
+	formulaStatusCmd.Flags().BoolVar(&formulaStatusJSON, "json", false, "Output as JSON")
+	formulaCmd.AddCommand(formulaStatusCmd)
+}
+
+// beadsRunStore keeps formula run state on an ephemeral child bead of the
+// issue the formula runs against.
+type beadsRunStore struct {
+	bd *beads.Beads
+}
+
+func newBeadsRunStore(issue string) *beadsRunStore {
+	return &beadsRunStore{bd: beads.New(resolveBeadDir(issue))}
+}
+
+// runs returns every formula run recorded against issue.
+func (s *beadsRunStore) runs(issue string) ([]*formula.RunState, error) {
+	children, err := s.bd.List(beads.ListOptions{
+		Status:   "all",
+		Label:    formulaRunLabel,
+		Parent:   issue,
+		Priority: -1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var runs []*formula.RunState
+	for _, child := range children {
+		if st := formula.ParseRunState(child.Description); st != nil && st.Issue == issue {
+			st.ID = child.ID
+			runs = append(runs, st)
+		}
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].Started.Before(runs[j].Started) })
+	return runs, nil
+}
+
+// Load implements formula.StateStore.
+func (s *beadsRunStore) Load(name, issue string) (*formula.RunState, error) {
+	runs, err := s.runs(issue)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(runs) - 1; i >= 0; i-- {
+		if runs[i].Formula == name {
+			return runs[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// Save implements formula.StateStore. A completed run's bead is closed so
+// it drops out of ready and blocked lists.
+func (s *beadsRunStore) Save(st *formula.RunState) error {
+	desc := st.Format()
+	if st.ID == "" {
+		issue, err := s.bd.Create(beads.CreateOptions{
+			Title:       fmt.Sprintf("formula %s: %s", st.Formula, st.Issue),
+			Type:        "formula-run",
+			Priority:    -1,
+			Description: desc,
+			Parent:      st.Issue,
+			Ephemeral:   true,
+		})
+		if err != nil {
+			return err
+		}
+		st.ID = issue.ID
+	} else if err := s.bd.Update(st.ID, beads.UpdateOptions{Description: &desc}); err != nil {
+		return err
+	}
+	if st.Complete() {
+		return s.bd.CloseWithReason("formula complete", st.ID)
+	}
+	return nil
+}
+
+// executeFormulaOnIssue runs a workflow formula's steps against an issue,
+// resuming any earlier run of it.
+func executeFormulaOnIssue(f *formula.Formula, formulaName, rigPath string) error {
+	given, err := parseFormulaVars(formulaRunVars)
+	if err != nil {
+		return err
+	}
+	var overrides map[string]string
+	if rigPath != "" {
+		overrides = config.GetFormulaVars(rigPath, formulaName)
+	}
+	values, err := f.ResolveVars(given, overrides)
+	if err != nil {
+		return err
+	}
+
+	exec, err := newFormulaExecutor(f, formulaRunIssue, values)
+	if err != nil {
+		return err
+	}
+	exec.Output = os.Stdout
+	exec.Retry = formulaRunRetry
+	exec.Done = formulaRunDone
+	state, err := exec.Run(context.Background())
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	printFormulaRun(state)
+	switch state.Status() {
+	case string(formula.StepDone):
+		return nil
+	case string(formula.StepWaiting):
+		fmt.Printf("\n  Resume once done: gt formula run %s --issue %s --done <step>\n", formulaName, formulaRunIssue)
+		return nil
+	case string(formula.StepFailed):
+		fmt.Printf("\n  Retry: gt formula run %s --issue %s --retry\n", formulaName, formulaRunIssue)
+	}
+	return NewSilentExit(1)
+}
+
+// newFormulaExecutor sets up an executor for f against issue, with the
+// issue's labels and the current branch's changed files for conditions.
+func newFormulaExecutor(f *formula.Formula, issue string, values map[string]string) (*formula.Executor, error) {
+	// Steps run where the work is: the current directory, normally the
+	// polecat or crew worktree for the issue.
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("getting current directory: %w", err)
+	}
+	store := newBeadsRunStore(issue)
+	target, err := store.bd.Show(issue)
+	if err != nil {
+		return nil, fmt.Errorf("looking up %s: %w", issue, err)
+	}
+	return &formula.Executor{
+		Formula:      f,
+		Issue:        issue,
+		Vars:         values,
+		Store:        store,
+		Dir:          dir,
+		Labels:       target.Labels,
+		ChangedFiles: formulaChangedFiles(dir),
+	}, nil
+}
+
+// formulaChangedFiles lists the files the branch checked out in dir
+// changes relative to the remote default branch, for when.files
+// conditions. Returns nil outside a git repository.
+func formulaChangedFiles(dir string) []string {
+	g := git.NewGit(dir)
+	changes, err := g.ChangedFiles("origin/"+g.RemoteDefaultBranch(), "HEAD")
+	if err != nil {
+		return nil
+	}
+	files := make([]string, 0, len(changes))
+	for _, c := range changes {
+		files = append(files, c.Path)
+	}
+	return files
+}
+
+func runFormulaStatus(cmd *cobra.Command, args []string) error {
+	issue := args[0]
+	runs, err := newBeadsRunStore(issue).runs(issue)
+	if err != nil {
+		return fmt.Errorf("listing formula runs: %w", err)
+	}
+	if formulaStatusJSON {
+		return outputJSON(runs)
+	}
+	if len(runs) == 0 {
+		fmt.Printf("No formula runs on %s\n", issue)
+		return nil
+	}
+	for i, run := range runs {
+		if i > 0 {
+			fmt.Println()
+		}
+		printFormulaRun(run)
+	}
+	return nil
+}
+
+// printFormulaRun prints a run's overall state and its steps in order.
+func printFormulaRun(run *formula.RunState) {
+	fmt.Printf("%s %s on %s: %s", formulaStepIcon(formula.StepStatus(run.Status())),
+		style.Bold.Render(run.Formula), run.Issue, run.Status())
+	if run.ID != "" {
+		fmt.Printf("  %s", style.Dim.Render(run.ID))
+	}
+	fmt.Println()
+	for _, id := range run.Order {
+		st := run.Step(id)
+		fmt.Printf("  %s %-20s %s", formulaStepIcon(st.Status), id, st.Status)
+		if st.Attempts > 1 {
+			fmt.Printf(" %s", style.Dim.Render(fmt.Sprintf("(%d attempts)", st.Attempts)))
+		}
+		if !st.Updated.IsZero() {
+			fmt.Printf(" %s", style.Dim.Render(st.Updated.Local().Format(time.DateTime)))
+		}
+		fmt.Println()
+		if st.Error != "" {
+			fmt.Printf("      %s\n", style.Error.Render(st.Error))
+		}
+	}
+}
+
+func formulaStepIcon(s formula.StepStatus) string {
+	switch s {
+	case formula.StepDone:
+		return style.Success.Render("✓")
+	case formula.StepFailed:
+		return style.Error.Render("✗")
+	case formula.StepWaiting, formula.StepRunning:
+		return style.Warning.Render("●")
+	case formula.StepSkipped:
+		return style.Dim.Render("-")
+	default:
+		return style.Dim.Render("○")
+	}
+}
@@ -3,7 +3,6 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
-	"os"
 	"os/exec"
 
 	"github.com/spf13/cobra"
@@ -180,7 +179,5 @@ func runGateWake(cmd *cobra.Command, args []string) error {
 }
 
 func outputGateWakeResult(result GateWakeResult) error {
-	enc := json.NewEncoder(os.Stdout)
-	enc.SetIndent("", "  ")
-	return enc.Encode(result)
+	return outputJSON(result)
 }
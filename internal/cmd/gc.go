@@ -0,0 +1,470 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/blobstore"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/diskusage"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/polecat"
+	"github.com/steveyegge/gastown/internal/retention"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/tmux"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// GC command flags
+var (
+	gcRig          string
+	gcGroup        string
+	gcDryRun       bool
+	gcUsageOnly    bool
+	gcJSON         bool
+	gcLogAge       string
+	gcRecordingAge string
+	gcCacheMax     string
+	gcMinSize      string
+	gcSkip         []string
+)
+
+var gcCmd = &cobra.Command{
+	Use:     "gc",
+	GroupID: GroupWorkspace,
+	Short:   "Report disk usage and safely reclaim space",
+	Long: `Report the disk each rig consumes and reclaim what is safe to remove.
+
+Usage is broken down per rig into clones, worktrees, build caches, beads,
+logs, and agent session recordings (transcripts under ~/.claude/projects).
+
+gc then removes, unless skipped with --skip:
+
+  worktrees   Polecats with no session, no uncommitted work, and nothing
+              that isn't already on the default branch (nuked like
+              'gt polecat nuke': session, worktree, branch, agent bead)
+  retention   Applies the town's retention policies (settings/config.json
+              "retention"): rotates and compresses oversized logs, event
+              and audit logs, and removes rotated files and transcripts
+              past their max age (--log-age, --recording-age override),
+              and prunes the backup and attachment stores
+              ("retention.backups", "retention.attachments")
+  recordings  Transcripts of polecats that no longer exist
+  caches      Build caches larger than --cache-max (only when set)
+  objects     Dangling git objects and stale worktree entries
+              (git worktree prune, git gc) in each rig's shared repo,
+              when git counts loose objects or garbage to reclaim
+
+Live logs, crew workspaces, and anything with unsaved work are never
+touched. Candidates smaller than --min-size are left alone. Use --dry-run
+to see what would go.
+
+Examples:
+  gt gc --usage                 # Just report disk usage
+  gt gc --dry-run               # Report and list what would be removed
+  gt gc --rig gastown           # Clean one rig
+  gt gc --group experiments     # Clean the rigs in a group
+  gt gc --cache-max 20G --skip objects`,
+	Args: cobra.NoArgs,
+	RunE: runGC,
+}
+
+func init() {
+	gcCmd.Flags().StringVar(&gcRig, "rig", "", "Only this rig (default: all rigs)")
+	gcCmd.Flags().StringVar(&gcGroup, "group", "", "Only the rigs in this group")
+	gcCmd.Flags().BoolVarP(&gcDryRun, "dry-run", "n", false, "Show what would be removed without removing it")
+	gcCmd.Flags().BoolVar(&gcUsageOnly, "usage", false, "Only report disk usage")
+	gcCmd.Flags().BoolVar(&gcJSON, "json", false, "Output as JSON")
+	gcCmd.Flags().StringVar(&gcLogAge, "log-age", "", "Remove rotated logs older than this (default: retention.logs.max_age)")
+	gcCmd.Flags().StringVar(&gcRecordingAge, "recording-age", "", "Remove session transcripts older than this (default: retention.recordings.max_age)")
+	gcCmd.Flags().StringVar(&gcCacheMax, "cache-max", "", "Clear build caches larger than this (e.g. 20G)")
+	gcCmd.Flags().StringVar(&gcMinSize, "min-size", "0", "Leave candidates smaller than this alone (e.g. 1M)")
+	gcCmd.Flags().StringSliceVar(&gcSkip, "skip", nil, "Kinds to skip: worktrees, retention, recordings, caches, objects")
+
+	rootCmd.AddCommand(gcCmd)
+}
+
+// GCOutput is the JSON output of gt gc.
+type GCOutput struct {
+	Usage      []diskusage.Entry     `json:"usage"`
+	TotalBytes int64                 `json:"total_bytes"`
+	Candidates []diskusage.Candidate `json:"candidates,omitempty"`
+	Retention  []retention.Action    `json:"retention,omitempty"`
+	Reclaimed  int64                 `json:"reclaimed_bytes"`
+	DryRun     bool                  `json:"dry_run"`
+	Errors     []string              `json:"errors,omitempty"`
+}
+
+func runGC(cmd *cobra.Command, args []string) error {
+	minSize, err := diskusage.ParseSize(gcMinSize)
+	if err != nil {
+		return fmt.Errorf("--min-size: %w", err)
+	}
+	var cacheMax int64
+	if gcCacheMax != "" {
+		if cacheMax, err = diskusage.ParseSize(gcCacheMax); err != nil {
+			return fmt.Errorf("--cache-max: %w", err)
+		}
+	}
+	skip := make(map[string]bool)
+	for _, k := range gcSkip {
+		skip[strings.TrimSuffix(strings.TrimSpace(k), "s")] = true
+	}
+
+	rigs, townRoot, err := getAllRigs()
+	if err != nil {
+		return err
+	}
+	if gcRig != "" {
+		_, r, err := getRig(gcRig)
+		if err != nil {
+			return err
+		}
+		rigs = []*rig.Rig{r}
+	}
+	if gcGroup != "" {
+		rigsConfig, err := config.LoadRigsConfig(filepath.Join(townRoot, "mayor", "rigs.json"))
+		if err != nil {
+			return fmt.Errorf("loading rigs config: %w", err)
+		}
+		if rigs, err = filterRigsByGroup(rigs, rigsConfig, gcGroup); err != nil {
+			return err
+		}
+	}
+	sort.Slice(rigs, func(i, j int) bool { return rigs[i].Name < rigs[j].Name })
+
+	projectsDir := ""
+	if home, err := os.UserHomeDir(); err == nil {
+		projectsDir = filepath.Join(home, ".claude", "projects")
+	}
+
+	out := GCOutput{DryRun: gcDryRun || gcUsageOnly}
+	if gcRig == "" && gcGroup == "" {
+		out.Usage = append(out.Usage, diskusage.ScanTown(townRoot)...)
+	}
+	var progress *style.Progress
+	if !gcJSON {
+		progress = startProgress("Measuring disk usage", len(rigs))
+	}
+	cacheDirs := make(map[string]string)
+	for _, r := range rigs {
+		progress.Start(r.Name)
+		cacheDir := ""
+		if settings, err := config.LoadRigSettings(config.RigSettingsPath(r.Path)); err == nil && settings.BuildCache != nil {
+			cacheDir = config.BuildCacheDir(r.Path, settings.BuildCache)
+		}
+		cacheDirs[r.Name] = cacheDir
+		out.Usage = append(out.Usage, diskusage.ScanRig(r.Name, r.Path, cacheDir, projectsDir)...)
+		progress.Done(r.Name)
+	}
+	progress.Stop()
+	out.TotalBytes = diskusage.Sum(out.Usage)
+
+	if !gcUsageOnly {
+		if !gcJSON {
+			progress = startProgress("Finding reclaimable space", len(rigs))
+		}
+		for _, r := range rigs {
+			progress.Start(r.Name)
+			if !skip["recording"] && projectsDir != "" {
+				out.Candidates = append(out.Candidates, diskusage.OrphanRecordings(r.Name, projectsDir, filepath.Join(r.Path, "polecats"), minSize)...)
+			}
+			if !skip["cache"] && cacheMax > 0 && cacheDirs[r.Name] != "" {
+				if size := diskusage.DirSize(cacheDirs[r.Name]); size > cacheMax && size >= minSize {
+					out.Candidates = append(out.Candidates, diskusage.Candidate{
+						Rig: r.Name, Kind: "cache", Path: cacheDirs[r.Name], Bytes: size,
+						Reason: "larger than " + diskusage.FormatSize(cacheMax),
+					})
+				}
+			}
+			if !skip["worktree"] {
+				out.Candidates = append(out.Candidates, mergedPolecats(r, minSize)...)
+			}
+			if !skip["object"] {
+				if c := gitObjectsCandidate(r, minSize); c != nil {
+					out.Candidates = append(out.Candidates, *c)
+				}
+			}
+			progress.Done(r.Name)
+		}
+		progress.Stop()
+		diskusage.SortCandidates(out.Candidates)
+
+		if !gcDryRun {
+			for _, c := range out.Candidates {
+				freed, err := reclaim(c)
+				if err != nil {
+					out.Errors = append(out.Errors, fmt.Sprintf("%s: %v", c.Path, err))
+					continue
+				}
+				out.Reclaimed += freed
+			}
+		}
+
+		if !skip["retention"] {
+			cfg, err := gcRetentionConfig(townRoot)
+			if err != nil {
+				return err
+			}
+			opts := retention.Options{TownRoot: townRoot, ProjectsDir: projectsDir, RigsOnly: gcRig != "" || gcGroup != "", DryRun: gcDryRun}
+			for _, r := range rigs {
+				opts.RigPaths = append(opts.RigPaths, r.Path)
+			}
+			out.Retention, err = retention.Enforce(opts, cfg)
+			if err != nil {
+				out.Errors = append(out.Errors, err.Error())
+			}
+			if !opts.RigsOnly {
+				pruned, err := pruneBlobStores(townRoot, gcDryRun)
+				out.Retention = append(out.Retention, pruned...)
+				if err != nil {
+					out.Errors = append(out.Errors, err.Error())
+				}
+			}
+			if !gcDryRun {
+				out.Reclaimed += retention.Freed(out.Retention)
+			}
+		}
+	}
+
+	if gcJSON {
+		return outputJSON(out)
+	}
+	printGCUsage(out.Usage)
+	if gcUsageOnly {
+		return nil
+	}
+
+	fmt.Println()
+	if len(out.Candidates) == 0 && len(out.Retention) == 0 {
+		fmt.Printf("%s Nothing to reclaim\n", style.Success.Render("✓"))
+		return nil
+	}
+	would := retention.Freed(out.Retention)
+	for _, a := range out.Retention {
+		fmt.Printf("  %-10s %-10s %9s  %s\n", a.Op, a.Class, diskusage.FormatSize(a.Bytes), a.Path)
+	}
+	for _, c := range out.Candidates {
+		would += c.Bytes
+		where := c.Rig
+		if where == "" {
+			where = "town"
+		}
+		fmt.Printf("  %-10s %-10s %9s  %s  %s\n", c.Kind, where, diskusage.FormatSize(c.Bytes), c.Path, style.Dim.Render(c.Reason))
+	}
+	fmt.Println()
+	if gcDryRun {
+		fmt.Printf("%s Dry run: would reclaim about %s from %d item(s)\n", style.Dim.Render("ℹ"), diskusage.FormatSize(would), len(out.Candidates)+len(out.Retention))
+		return nil
+	}
+	for _, e := range out.Errors {
+		fmt.Printf("  %s %s\n", style.Warning.Render("⚠"), e)
+	}
+	fmt.Printf("%s Reclaimed %s\n", style.Success.Render("✓"), diskusage.FormatSize(out.Reclaimed))
+	return nil
+}
+
+// pruneBlobStores applies the backups and attachments retention policies
+// to the town's blob stores.
+func pruneBlobStores(townRoot string, dryRun bool) ([]retention.Action, error) {
+	town, err := workspace.GetTownName(townRoot)
+	if err != nil {
+		return nil, err
+	}
+	return blobstore.PruneTown(context.Background(), townRoot, town, dryRun)
+}
+
+// gcRetentionConfig returns the town's retention config with --log-age and
+// --recording-age applied.
+func gcRetentionConfig(townRoot string) (*config.RetentionConfig, error) {
+	cfg := retention.LoadConfig(townRoot)
+	if cfg == nil {
+		cfg = config.DefaultRetentionConfig()
+	}
+	override := func(flag, value string, p **config.RetentionPolicy) error {
+		if value == "" {
+			return nil
+		}
+		if _, err := retention.ParseAge(value); err != nil {
+			return fmt.Errorf("--%s: %w", flag, err)
+		}
+		policy := config.RetentionPolicy{}
+		if *p != nil {
+			policy = **p
+		}
+		policy.MaxAge = value
+		*p = &policy
+		return nil
+	}
+	if err := override("log-age", gcLogAge, &cfg.Logs); err != nil {
+		return nil, err
+	}
+	if err := override("recording-age", gcRecordingAge, &cfg.Recordings); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// printGCUsage prints usage per rig by category, largest rigs first.
+func printGCUsage(entries []diskusage.Entry) {
+	byRig := make(map[string][]diskusage.Entry)
+	for _, e := range entries {
+		byRig[e.Rig] = append(byRig[e.Rig], e)
+	}
+	names := make([]string, 0, len(byRig))
+	for name := range byRig {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return diskusage.Sum(byRig[names[i]]) > diskusage.Sum(byRig[names[j]]) })
+
+	fmt.Printf("%s Disk usage\n\n", style.Bold.Render("💾"))
+	header := fmt.Sprintf("  %-16s", "RIG")
+	for _, c := range diskusage.Categories {
+		header += fmt.Sprintf(" %10s", strings.ToUpper(c))
+	}
+	fmt.Println(style.Dim.Render(header + fmt.Sprintf(" %10s", "TOTAL")))
+	for _, name := range names {
+		totals := diskusage.Totals(byRig[name], func(e diskusage.Entry) string { return e.Category })
+		label := name
+		if label == "" {
+			label = "(town)"
+		}
+		line := fmt.Sprintf("  %-16s", label)
+		for _, c := range diskusage.Categories {
+			cell := "-"
+			if totals[c] > 0 {
+				cell = diskusage.FormatSize(totals[c])
+			}
+			line += fmt.Sprintf(" %10s", cell)
+		}
+		fmt.Println(line + fmt.Sprintf(" %10s", style.Bold.Render(diskusage.FormatSize(diskusage.Sum(byRig[name])))))
+	}
+	fmt.Printf("\n  Total: %s\n", style.Bold.Render(diskusage.FormatSize(diskusage.Sum(entries))))
+}
+
+// mergedPolecats returns polecats whose worktrees can go: no session, not
+// working or stuck, no uncommitted work, and HEAD already on the default branch.
+func mergedPolecats(r *rig.Rig, minSize int64) []diskusage.Candidate {
+	mgr, _, err := getPolecatManager(r.Name)
+	if err != nil {
+		return nil
+	}
+	polecats, err := mgr.List()
+	if err != nil {
+		return nil
+	}
+	sessMgr := polecat.NewSessionManager(tmux.NewTmux(), r)
+	target := "origin/" + r.DefaultBranch()
+	var out []diskusage.Candidate
+	for _, p := range polecats {
+		if p.State.IsActive() || p.State == polecat.StateStuck {
+			continue
+		}
+		if running, _ := sessMgr.IsRunning(p.Name); running {
+			continue
+		}
+		g := git.NewGit(p.ClonePath)
+		status, err := g.CheckUncommittedWork()
+		if err != nil {
+			continue
+		}
+		// Unpushed commits are fine if the default branch already has them
+		status.UnpushedCommits = 0
+		if !status.CleanExcludingBeads() {
+			continue
+		}
+		if merged, err := g.IsAncestor("HEAD", target); err != nil || !merged {
+			continue
+		}
+		dir := filepath.Join(r.Path, "polecats", p.Name)
+		size := diskusage.DirSize(dir)
+		if size < minSize {
+			continue
+		}
+		out = append(out, diskusage.Candidate{
+			Rig: r.Name, Kind: "worktree", Path: dir, Bytes: size,
+			Reason: fmt.Sprintf("polecat %s idle, work merged into %s", p.Name, target),
+		})
+	}
+	return out
+}
+
+// gitObjectsCandidate returns the rig's shared repo, whose dangling objects
+// and stale worktree entries git can prune, or nil when its loose objects
+// and garbage come to less than minSize (or nothing at all).
+func gitObjectsCandidate(r *rig.Rig, minSize int64) *diskusage.Candidate {
+	repo := filepath.Join(r.Path, ".repo.git")
+	if info, err := os.Stat(repo); err != nil || !info.IsDir() {
+		repo = filepath.Join(r.Path, "mayor", "rig")
+		if _, err := os.Stat(filepath.Join(repo, ".git")); err != nil {
+			return nil
+		}
+	}
+	garbage := gitGarbageBytes(repo)
+	if garbage == 0 || garbage < minSize {
+		return nil
+	}
+	return &diskusage.Candidate{
+		Rig: r.Name, Kind: "objects", Path: repo, Bytes: garbage,
+		Reason: "git worktree prune && git gc --prune=2.weeks.ago",
+	}
+}
+
+// gitGarbageBytes estimates what git gc frees: loose objects and garbage.
+func gitGarbageBytes(repo string) int64 {
+	out, err := exec.Command("git", "-C", repo, "count-objects", "-v").Output() //nolint:gosec // G204: repo is a rig path
+	if err != nil {
+		return 0
+	}
+	var total int64
+	for _, line := range strings.Split(string(out), "\n") {
+		key, value, ok := strings.Cut(line, ": ")
+		if !ok || (key != "size" && key != "size-garbage") {
+			continue
+		}
+		var kib int64
+		if _, err := fmt.Sscanf(value, "%d", &kib); err == nil {
+			total += kib * 1024
+		}
+	}
+	return total
+}
+
+// reclaim removes a candidate and returns the bytes freed.
+func reclaim(c diskusage.Candidate) (int64, error) {
+	switch c.Kind {
+	case "worktree":
+		_, r, err := getRig(c.Rig)
+		if err != nil {
+			return 0, err
+		}
+		mgr, _, err := getPolecatManager(c.Rig)
+		if err != nil {
+			return 0, err
+		}
+		if err := nukePolecatFull(filepath.Base(c.Path), c.Rig, mgr, r); err != nil {
+			return 0, err
+		}
+		return c.Bytes, nil
+	case "objects":
+		before := diskusage.DirSize(c.Path)
+		if err := git.NewGitWithDir(c.Path, "").WorktreePrune(); err != nil {
+			return 0, fmt.Errorf("git worktree prune: %w", err)
+		}
+		if out, err := exec.Command("git", "-C", c.Path, "gc", "--quiet", "--prune=2.weeks.ago").CombinedOutput(); err != nil { //nolint:gosec // G204: repo is a rig path
+			return 0, fmt.Errorf("git gc: %v: %s", err, strings.TrimSpace(string(out)))
+		}
+		return max(before-diskusage.DirSize(c.Path), 0), nil
+	}
+	if err := diskusage.Remove(c); err != nil {
+		return 0, err
+	}
+	return c.Bytes, nil
+}
@@ -0,0 +1,328 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/goal"
+	"github.com/steveyegge/gastown/internal/milestone"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var goalJSON bool
+
+var goalCmd = &cobra.Command{
+	Use:     "goal",
+	GroupID: GroupWork,
+	Short:   "Roll convoy and milestone progress up to town goals",
+	RunE:    requireSubcommand,
+	Long: `Roll convoy and milestone progress up to the town's named goals.
+
+Goals are defined in town settings (settings/config.json):
+
+  "goals": {
+    "faster-onboarding": {
+      "description": "Signup in under 2 minutes",
+      "owner": "mayor/",
+      "target": "2026-06-30"
+    }
+  }
+
+Convoys and milestones link to a goal with 'gt goal link', or with --goal
+when they're created. A goal's progress counts the distinct issues its
+convoys and milestones track. With a target date the goal is projected
+like a milestone; without one it takes the worst health of its open
+milestones.
+
+COMMANDS:
+  list      Every goal's progress and health
+  status    One goal's linked convoys and milestones
+  link      Link convoys or milestones to a goal
+  unlink    Remove links`,
+}
+
+var goalListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show progress toward each goal",
+	Args:  cobra.NoArgs,
+	RunE:  runGoalList,
+}
+
+var goalStatusCmd = &cobra.Command{
+	Use:   "status <goal>",
+	Short: "Show a goal's linked convoys and milestones",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runGoalStatus,
+}
+
+var goalLinkCmd = &cobra.Command{
+	Use:   "link <goal> <convoy-or-milestone-id> [id...]",
+	Short: "Link convoys or milestones to a goal",
+	Long: `Link convoys or milestones to a goal. A convoy or milestone may serve
+several goals.
+
+Examples:
+  gt goal link faster-onboarding hq-cv-abc hq-m12`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runGoalLink,
+}
+
+var goalUnlinkCmd = &cobra.Command{
+	Use:   "unlink <goal> <convoy-or-milestone-id> [id...]",
+	Short: "Remove convoys or milestones from a goal",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  runGoalUnlink,
+}
+
+func init() {
+	goalListCmd.Flags().BoolVar(&goalJSON, "json", false, "Output as JSON")
+	goalStatusCmd.Flags().BoolVar(&goalJSON, "json", false, "Output as JSON")
+
+	goalCmd.AddCommand(goalListCmd)
+	goalCmd.AddCommand(goalStatusCmd)
+	goalCmd.AddCommand(goalLinkCmd)
+	goalCmd.AddCommand(goalUnlinkCmd)
+
+	rootCmd.AddCommand(goalCmd)
+}
+
+// requireGoal checks that a goal is defined in town settings.
+func requireGoal(townRoot, name string) error {
+	goals, err := goal.Load(townRoot)
+	if err != nil {
+		return fmt.Errorf("loading goals: %w", err)
+	}
+	g, ok := goals[name]
+	if !ok {
+		if len(goals) == 0 {
+			return fmt.Errorf("unknown goal %q: no goals defined in settings/config.json", name)
+		}
+		return fmt.Errorf("unknown goal %q (have: %s)", name, strings.Join(goal.Names(goals), ", "))
+	}
+	return goal.Validate(name, g)
+}
+
+// linkGoal links a convoy or milestone to a defined goal.
+func linkGoal(townRoot, name, id string) error {
+	if err := requireGoal(townRoot, name); err != nil {
+		return err
+	}
+	bd := beads.New(townRoot)
+	issue, err := bd.Show(id)
+	if err != nil {
+		return fmt.Errorf("looking up %s: %w", id, err)
+	}
+	if goalLinkKind(issue) == "" {
+		return fmt.Errorf("%s is neither a convoy nor a milestone", id)
+	}
+	return bd.Update(id, beads.UpdateOptions{AddLabels: []string{goal.Label(name)}})
+}
+
+// goalLinkKind returns "convoy" or "milestone" for beads that can link to
+// goals, or "".
+func goalLinkKind(issue *beads.Issue) string {
+	switch {
+	case issue.Type == "convoy":
+		return "convoy"
+	case beads.HasLabel(issue, beads.MilestoneLabel):
+		return "milestone"
+	}
+	return ""
+}
+
+func runGoalLink(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	name := args[0]
+	if err := requireGoal(townRoot, name); err != nil {
+		return err
+	}
+	linked := 0
+	for _, id := range args[1:] {
+		if err := linkGoal(townRoot, name, id); err != nil {
+			style.PrintWarning("couldn't link %s: %v", id, err)
+			continue
+		}
+		linked++
+	}
+	fmt.Printf("%s Linked %d to goal %s\n", style.Bold.Render("✓"), linked, name)
+	return nil
+}
+
+func runGoalUnlink(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	name := args[0]
+	bd := beads.New(townRoot)
+	unlinked := 0
+	for _, id := range args[1:] {
+		if err := bd.Update(id, beads.UpdateOptions{RemoveLabels: []string{goal.Label(name)}}); err != nil {
+			style.PrintWarning("couldn't unlink %s: %v", id, err)
+			continue
+		}
+		unlinked++
+	}
+	fmt.Printf("%s Unlinked %d from goal %s\n", style.Bold.Render("✓"), unlinked, name)
+	return nil
+}
+
+// buildGoalStatus rolls up the convoys and milestones linked to a goal.
+func buildGoalStatus(townRoot, name string, now time.Time) (*goal.Status, error) {
+	goals, err := goal.Load(townRoot)
+	if err != nil {
+		return nil, fmt.Errorf("loading goals: %w", err)
+	}
+	linked, err := beads.New(townRoot).List(beads.ListOptions{Status: "all", Label: goal.Label(name), Priority: -1})
+	if err != nil {
+		return nil, fmt.Errorf("listing goal %s links: %w", name, err)
+	}
+
+	var links []goal.Link
+	var issues []milestone.Issue
+	var start time.Time
+	for _, issue := range linked {
+		kind := goalLinkKind(issue)
+		if kind == "" {
+			continue
+		}
+		tracked, err := trackedMilestoneIssues(townRoot, issue.ID)
+		if err != nil {
+			style.PrintWarning("skipping %s: %v", issue.ID, err)
+			continue
+		}
+		link := goal.Link{ID: issue.ID, Kind: kind, Title: issue.Title, Status: issue.Status, Total: len(tracked)}
+		for _, it := range tracked {
+			if it.IsClosed() {
+				link.Closed++
+			}
+		}
+		created := startOfDay(parseRetroTime(issue.CreatedAt))
+		if kind == "milestone" {
+			fields := beads.ParseMilestoneFields(issue.Description)
+			if s := fields.StartDate(); !s.IsZero() {
+				created = s
+			}
+			link.Health = milestone.Build(issue.ID, issue.Title, issue.Status, created, fields.TargetDate(), tracked, now).Health
+		}
+		if start.IsZero() || created.Before(start) {
+			start = created
+		}
+		links = append(links, link)
+		issues = append(issues, tracked...)
+	}
+	if start.IsZero() {
+		start = startOfDay(now)
+	}
+	return goal.Build(name, goals[name], links, issues, start, now), nil
+}
+
+func runGoalList(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	goals, err := goal.Load(townRoot)
+	if err != nil {
+		return fmt.Errorf("loading goals: %w", err)
+	}
+	now := time.Now()
+	statuses := make([]*goal.Status, 0, len(goals))
+	for _, name := range goal.Names(goals) {
+		s, err := buildGoalStatus(townRoot, name, now)
+		if err != nil {
+			style.PrintWarning("skipping goal %s: %v", name, err)
+			continue
+		}
+		statuses = append(statuses, s)
+	}
+
+	if goalJSON {
+		return outputJSON(statuses)
+	}
+	if len(statuses) == 0 {
+		fmt.Printf("%s No goals defined (add \"goals\" to settings/config.json)\n", style.Dim.Render("○"))
+		return nil
+	}
+	table := style.NewTable(
+		style.Column{Name: "GOAL", Width: 22},
+		style.Column{Name: "TARGET", Width: 10},
+		style.Column{Name: "LINKED", Width: 6, Align: style.AlignRight},
+		style.Column{Name: "PROGRESS", Width: 20},
+		style.Column{Name: "HEALTH", Width: 9},
+	)
+	for _, s := range statuses {
+		target := "-"
+		if !s.Target.IsZero() {
+			target = s.Target.Format(time.DateOnly)
+		}
+		table.AddRow(s.Name, target, fmt.Sprint(len(s.Links)),
+			fmt.Sprintf("%d/%d %s", s.Closed, s.Total, style.ProgressBar(s.Percent(), 6)),
+			renderMilestoneHealth(s.Health))
+	}
+	fmt.Print(table.Render())
+	return nil
+}
+
+func runGoalStatus(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	name := args[0]
+	if err := requireGoal(townRoot, name); err != nil {
+		return err
+	}
+	s, err := buildGoalStatus(townRoot, name, time.Now())
+	if err != nil {
+		return err
+	}
+	if goalJSON {
+		return outputJSON(s)
+	}
+
+	fmt.Printf("%s %s\n", style.Bold.Render("◎"), style.Bold.Render(s.Name))
+	if s.Description != "" {
+		fmt.Printf("  %s\n", s.Description)
+	}
+	fmt.Println()
+	if !s.Target.IsZero() {
+		fmt.Printf("  Target:    %s\n", s.Target.Format(time.DateOnly))
+	}
+	if s.Owner != "" {
+		fmt.Printf("  Owner:     %s\n", s.Owner)
+	}
+	fmt.Printf("  Health:    %s\n", renderMilestoneHealth(s.Health))
+	fmt.Printf("  Progress:  %d/%d issues closed %s\n", s.Closed, s.Total, style.ProgressBar(s.Percent(), 20))
+	if !s.Projected.IsZero() {
+		fmt.Printf("  Projected: %s\n", s.Projected.Format(time.DateOnly))
+	}
+
+	if len(s.Links) == 0 {
+		fmt.Printf("\n  %s\n", style.Dim.Render("Nothing linked yet: gt goal link "+s.Name+" <convoy-or-milestone-id>"))
+		return nil
+	}
+	fmt.Println()
+	for _, l := range s.Links {
+		icon := "🚚"
+		if l.Kind == "milestone" {
+			icon = "🎯"
+		}
+		line := fmt.Sprintf("  %s %s %s  %d/%d", icon, l.ID, l.Title, l.Closed, l.Total)
+		if l.Health != "" {
+			line += "  " + renderMilestoneHealth(l.Health)
+		}
+		if l.Status == "closed" {
+			line = style.Dim.Render(line + "  (closed)")
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
@@ -37,6 +36,7 @@ Examples:
   gt hook status                    # Same as above
   gt hook gt-abc                    # Attach issue gt-abc to your hook
   gt hook gt-abc -s "Fix the bug"   # With subject for handoff mail
+  gt hook gt-abc --files=internal/auth,docs/auth.md  # Soft-lock paths
 
 Related commands:
   gt sling <bead>    # Hook + start now (keep context)
@@ -116,6 +116,7 @@ var (
 	hookDryRun  bool
 	hookForce   bool
 	hookClear   bool
+	hookFiles   []string
 )
 
 func init() {
@@ -125,6 +126,7 @@ func init() {
 	hookCmd.Flags().BoolVarP(&hookDryRun, "dry-run", "n", false, "Show what would be done")
 	hookCmd.Flags().BoolVarP(&hookForce, "force", "f", false, "Replace existing incomplete hooked bead")
 	hookCmd.Flags().BoolVar(&hookClear, "clear", false, "Clear your hook (alias for 'gt unhook')")
+	hookCmd.Flags().StringSliceVar(&hookFiles, "files", nil, "Files/directories you'll change, soft-locked for the claim (see 'gt locks')")
 
 	// --json flag for status output (used when no args, i.e., gt hook --json)
 	hookCmd.Flags().BoolVar(&moleculeJSON, "json", false, "Output as JSON (for status)")
@@ -268,6 +270,9 @@ func runHook(_ *cobra.Command, args []string) error {
 		if hookMessage != "" {
 			fmt.Printf("  context (for handoff mail): %s\n", hookMessage)
 		}
+		if len(hookFiles) > 0 {
+			fmt.Printf("  would lock files: %s\n", strings.Join(hookFiles, ", "))
+		}
 		return nil
 	}
 
@@ -278,6 +283,13 @@ func runHook(_ *cobra.Command, args []string) error {
 	}
 	townBeadsDir := filepath.Join(townRoot, ".beads")
 
+	// Lock declared files first, so a queued claim doesn't take the work
+	if len(hookFiles) > 0 {
+		if err := claimFileLocks(townRoot, agentID, beadID, hookFiles); err != nil {
+			return err
+		}
+	}
+
 	// Hook the bead using bd update with retry logic (discovery-based approach).
 	// Run from town root so bd can find routes.jsonl for prefix-based routing.
 	// This is essential for hooking convoys (hq-* prefix) stored in town beads.
@@ -423,8 +435,7 @@ func runHookShow(cmd *cobra.Command, args []string) error {
 		} else {
 			info.Status = "empty"
 		}
-		enc := json.NewEncoder(os.Stdout)
-		return enc.Encode(info)
+		return outputJSON(info)
 	}
 
 	// Compact one-line output
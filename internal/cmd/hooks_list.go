@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
@@ -133,12 +132,7 @@ func outputListJSON(infos []listTargetInfo) error {
 		OverridesDir: hooks.OverridesDir(),
 	}
 
-	data, err := json.MarshalIndent(output, "", "  ")
-	if err != nil {
-		return err
-	}
-	fmt.Println(string(data))
-	return nil
+	return outputJSON(output)
 }
 
 func outputListHuman(infos []listTargetInfo) error {
@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -147,13 +146,7 @@ func outputHooksJSON(townRoot string, hookInfos []HookInfo) error {
 		Count:    len(hookInfos),
 	}
 
-	data, err := json.MarshalIndent(output, "", "  ")
-	if err != nil {
-		return err
-	}
-
-	fmt.Println(string(data))
-	return nil
+	return outputJSON(output)
 }
 
 func outputHooksHuman(townRoot string, hookInfos []HookInfo) error {
@@ -0,0 +1,558 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Incident command flags
+var (
+	incidentSeverity  string
+	incidentSummary   string
+	incidentResponder string
+	incidentNoFreeze  bool
+	incidentNoPage    bool
+	incidentOpenJSON  bool
+
+	incidentListAll  bool
+	incidentListJSON bool
+	incidentShowJSON bool
+
+	incidentResolution string
+	incidentNoRetro    bool
+)
+
+var incidentCmd = &cobra.Command{
+	Use:     "incident",
+	GroupID: GroupComm,
+	Short:   "Coordinate incident response for a rig",
+	RunE:    requireSubcommand,
+	Long: `Open and track incidents affecting a rig.
+
+Opening an incident:
+  1. Creates an incident bead (gt:incident) in the rig's beads
+  2. Freezes the rig's merge queue: only MRs labeled hotfix (or whose
+     source issue is labeled hotfix) are processed until resolved
+  3. Pages via the rig's incident.page actions, or the town escalation
+     route for the severity (settings/escalation.json)
+  4. Assigns a responder (--responder or incident.responder) and mails them
+
+Every step, note, and the resolution is recorded on the incident timeline.
+
+Examples:
+  gt incident open gastown "Login broken in prod" --severity critical
+  gt incident note gt-abc "Rolled back deploy 1.4.1"
+  gt incident show gt-abc
+  gt incident resolve gt-abc --resolution "Reverted gt-mr-123"`,
+}
+
+var incidentOpenCmd = &cobra.Command{
+	Use:   "open <rig> <title>",
+	Short: "Open an incident, freeze the queue, and page",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runIncidentOpen,
+}
+
+var incidentNoteCmd = &cobra.Command{
+	Use:   "note <incident-id> <text>",
+	Short: "Add an event to an incident timeline",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  runIncidentNote,
+}
+
+var incidentAssignCmd = &cobra.Command{
+	Use:   "assign <incident-id> <agent>",
+	Short: "Hand an incident to a different responder",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runIncidentAssign,
+}
+
+var incidentFreezeCmd = &cobra.Command{
+	Use:   "freeze <incident-id>",
+	Short: "Freeze the rig's merge queue for an incident",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setIncidentFreeze(args[0], true)
+	},
+}
+
+var incidentUnfreezeCmd = &cobra.Command{
+	Use:   "unfreeze <incident-id>",
+	Short: "Let the merge queue run while the incident stays open",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setIncidentFreeze(args[0], false)
+	},
+}
+
+var incidentListCmd = &cobra.Command{
+	Use:   "list [rig]",
+	Short: "List open incidents",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runIncidentList,
+}
+
+var incidentShowCmd = &cobra.Command{
+	Use:   "show <incident-id>",
+	Short: "Show an incident and its timeline",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runIncidentShow,
+}
+
+var incidentResolveCmd = &cobra.Command{
+	Use:   "resolve <incident-id>",
+	Short: "Resolve an incident and unfreeze the queue",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runIncidentResolve,
+}
+
+func init() {
+	incidentOpenCmd.Flags().StringVarP(&incidentSeverity, "severity", "s", config.SeverityHigh, "Severity: critical, high, medium, low")
+	incidentOpenCmd.Flags().StringVar(&incidentSummary, "summary", "", "What is happening and what is affected")
+	incidentOpenCmd.Flags().StringVar(&incidentResponder, "responder", "", "Agent to assign (default: incident.responder)")
+	incidentOpenCmd.Flags().BoolVar(&incidentNoFreeze, "no-freeze", false, "Don't freeze the merge queue")
+	incidentOpenCmd.Flags().BoolVar(&incidentNoPage, "no-page", false, "Don't page")
+	incidentOpenCmd.Flags().BoolVar(&incidentOpenJSON, "json", false, "Output as JSON")
+
+	incidentListCmd.Flags().BoolVar(&incidentListAll, "all", false, "Include resolved incidents")
+	incidentListCmd.Flags().BoolVar(&incidentListJSON, "json", false, "Output as JSON")
+	incidentShowCmd.Flags().BoolVar(&incidentShowJSON, "json", false, "Output as JSON")
+
+	incidentResolveCmd.Flags().StringVarP(&incidentResolution, "resolution", "r", "", "How the incident was resolved (required)")
+	_ = incidentResolveCmd.MarkFlagRequired("resolution")
+	incidentResolveCmd.Flags().BoolVar(&incidentNoRetro, "no-retro", false, "Don't save a retrospective memo")
+
+	incidentCmd.AddCommand(incidentOpenCmd)
+	incidentCmd.AddCommand(incidentNoteCmd)
+	incidentCmd.AddCommand(incidentAssignCmd)
+	incidentCmd.AddCommand(incidentFreezeCmd)
+	incidentCmd.AddCommand(incidentUnfreezeCmd)
+	incidentCmd.AddCommand(incidentListCmd)
+	incidentCmd.AddCommand(incidentShowCmd)
+	incidentCmd.AddCommand(incidentResolveCmd)
+
+	rootCmd.AddCommand(incidentCmd)
+}
+
+// IncidentInfo is the JSON form of an incident.
+type IncidentInfo struct {
+	ID          string                `json:"id"`
+	Title       string                `json:"title"`
+	Status      string                `json:"status"`
+	Rig         string                `json:"rig"`
+	Severity    string                `json:"severity"`
+	Responder   string                `json:"responder,omitempty"`
+	FreezeQueue bool                  `json:"freeze_queue"`
+	DeclaredBy  string                `json:"declared_by,omitempty"`
+	DeclaredAt  string                `json:"declared_at,omitempty"`
+	Resolution  string                `json:"resolution,omitempty"`
+	Paged       []string              `json:"paged,omitempty"`
+	Timeline    []beads.IncidentEvent `json:"timeline"`
+}
+
+func newIncidentInfo(issue *beads.Issue, fields *beads.IncidentFields) IncidentInfo {
+	info := IncidentInfo{
+		ID:          issue.ID,
+		Title:       issue.Title,
+		Status:      issue.Status,
+		Rig:         fields.Rig,
+		Severity:    fields.Severity,
+		Responder:   fields.Responder,
+		FreezeQueue: fields.FreezeQueue,
+		DeclaredBy:  fields.DeclaredBy,
+		DeclaredAt:  fields.DeclaredAt,
+		Resolution:  fields.Resolution,
+		Timeline:    fields.Timeline,
+	}
+	if info.Timeline == nil {
+		info.Timeline = []beads.IncidentEvent{}
+	}
+	return info
+}
+
+func runIncidentOpen(cmd *cobra.Command, args []string) error {
+	severity := strings.ToLower(incidentSeverity)
+	if !config.IsValidSeverity(severity) {
+		return fmt.Errorf("invalid severity '%s': must be critical, high, medium, or low", incidentSeverity)
+	}
+
+	townRoot, r, err := getRig(args[0])
+	if err != nil {
+		return err
+	}
+	title := args[1]
+
+	cfg := &config.IncidentConfig{}
+	if settings, err := config.LoadRigSettings(config.RigSettingsPath(r.Path)); err == nil && settings.Incident != nil {
+		cfg = settings.Incident
+	}
+	responder := incidentResponder
+	if responder == "" {
+		responder = cfg.Responder
+	}
+
+	actor := detectSender()
+	if actor == "" {
+		actor = "unknown"
+	}
+
+	bd := beads.New(r.BeadsPath())
+	issue, err := bd.CreateIncidentBead(title, incidentSummary, &beads.IncidentFields{
+		Severity:    severity,
+		Rig:         r.Name,
+		DeclaredBy:  actor,
+		Responder:   responder,
+		FreezeQueue: !incidentNoFreeze,
+	})
+	if err != nil {
+		return fmt.Errorf("creating incident bead: %w", err)
+	}
+
+	var timeline []string
+	if !incidentNoFreeze {
+		timeline = append(timeline, "Merge queue frozen (hotfixes only)")
+	}
+
+	// Page
+	var paged []string
+	if !incidentNoPage {
+		actions := cfg.Page
+		escalationConfig, err := config.LoadOrCreateEscalationConfig(config.EscalationConfigPath(townRoot))
+		if err != nil {
+			style.PrintWarning("loading escalation config: %v", err)
+			escalationConfig = nil
+		}
+		if len(actions) == 0 && escalationConfig != nil {
+			actions = escalationConfig.GetRouteForSeverity(severity)
+		}
+		subject := fmt.Sprintf("[INCIDENT %s] %s: %s", strings.ToUpper(severity), r.Name, title)
+		body := formatIncidentMailBody(issue.ID, r.Name, severity, incidentSummary, responder, !incidentNoFreeze)
+		for _, target := range extractMailTargetsFromActions(actions) {
+			if err := sendIncidentMail(townRoot, actor, target, subject, body); err != nil {
+				style.PrintWarning("failed to page %s: %v", target, err)
+				continue
+			}
+			paged = append(paged, target)
+		}
+		if escalationConfig != nil {
+			executeExternalActions(actions, escalationConfig, issue.ID, severity, title)
+		}
+		if len(paged) > 0 {
+			timeline = append(timeline, "Paged: "+strings.Join(paged, ", "))
+		}
+	}
+
+	// Assign the responder
+	if responder != "" {
+		if err := bd.Update(issue.ID, beads.UpdateOptions{Assignee: &responder}); err != nil {
+			style.PrintWarning("assigning %s: %v", responder, err)
+		} else {
+			subject := fmt.Sprintf("[INCIDENT] You are responder for %s: %s", issue.ID, title)
+			body := formatIncidentMailBody(issue.ID, r.Name, severity, incidentSummary, responder, !incidentNoFreeze)
+			if err := sendIncidentMail(townRoot, actor, responder, subject, body); err != nil {
+				style.PrintWarning("notifying responder %s: %v", responder, err)
+			}
+			timeline = append(timeline, "Responder assigned: "+responder)
+		}
+	}
+
+	for _, text := range timeline {
+		if _, err := bd.UpdateIncident(issue.ID, actor, text, nil); err != nil {
+			style.PrintWarning("recording timeline: %v", err)
+			break
+		}
+	}
+
+	_ = events.LogFeed(events.TypeIncidentOpened, actor, map[string]interface{}{
+		"incident":  issue.ID,
+		"rig":       r.Name,
+		"severity":  severity,
+		"title":     title,
+		"responder": responder,
+		"frozen":    !incidentNoFreeze,
+	})
+
+	if incidentOpenJSON {
+		current, fields, err := bd.GetIncidentBead(issue.ID)
+		if err != nil {
+			return err
+		}
+		info := newIncidentInfo(current, fields)
+		info.Paged = paged
+		return outputJSON(info)
+	}
+
+	fmt.Printf("%s Incident opened: %s\n", severityEmoji(severity), issue.ID)
+	fmt.Printf("  Rig:       %s\n", r.Name)
+	fmt.Printf("  Severity:  %s\n", severity)
+	if !incidentNoFreeze {
+		fmt.Printf("  Queue:     %s\n", style.Warning.Render("frozen (hotfixes only)"))
+	}
+	if len(paged) > 0 {
+		fmt.Printf("  Paged:     %s\n", strings.Join(paged, ", "))
+	}
+	if responder != "" {
+		fmt.Printf("  Responder: %s\n", responder)
+	} else {
+		fmt.Printf("  Responder: %s\n", style.Dim.Render("none (gt incident assign "+issue.ID+" <agent>)"))
+	}
+	return nil
+}
+
+func sendIncidentMail(townRoot, from, to, subject, body string) error {
+	return mail.NewRouter(townRoot).Send(&mail.Message{
+		From:     from,
+		To:       to,
+		Subject:  subject,
+		Body:     body,
+		Type:     mail.TypeTask,
+		Priority: mail.PriorityUrgent,
+	})
+}
+
+func formatIncidentMailBody(id, rigName, severity, summary, responder string, frozen bool) string {
+	var lines []string
+	lines = append(lines, fmt.Sprintf("Incident: %s", id))
+	lines = append(lines, fmt.Sprintf("Rig: %s", rigName))
+	lines = append(lines, fmt.Sprintf("Severity: %s", severity))
+	if responder != "" {
+		lines = append(lines, fmt.Sprintf("Responder: %s", responder))
+	}
+	if frozen {
+		lines = append(lines, "Merge queue: frozen (label MRs or issues 'hotfix' to merge)")
+	}
+	if summary != "" {
+		lines = append(lines, "", summary)
+	}
+	lines = append(lines, "", "---")
+	lines = append(lines, "Timeline: gt incident note "+id+" \"what happened\"")
+	lines = append(lines, "Resolve:  gt incident resolve "+id+" --resolution \"...\"")
+	return strings.Join(lines, "\n")
+}
+
+// incidentBeads returns the beads client for an incident's rig.
+func incidentBeads(id string) (*beads.Beads, error) {
+	_, r, err := getRigForBead(id)
+	if err != nil {
+		return nil, err
+	}
+	return beads.New(r.BeadsPath()), nil
+}
+
+func runIncidentNote(cmd *cobra.Command, args []string) error {
+	bd, err := incidentBeads(args[0])
+	if err != nil {
+		return err
+	}
+	text := strings.Join(args[1:], " ")
+	if _, err := bd.UpdateIncident(args[0], detectSender(), text, nil); err != nil {
+		return err
+	}
+	fmt.Printf("%s Added to %s timeline\n", style.Success.Render("✓"), args[0])
+	return nil
+}
+
+func runIncidentAssign(cmd *cobra.Command, args []string) error {
+	id, responder := args[0], args[1]
+	townRoot, r, err := getRigForBead(id)
+	if err != nil {
+		return err
+	}
+	bd := beads.New(r.BeadsPath())
+	actor := detectSender()
+	if _, err := bd.UpdateIncident(id, actor, "Responder assigned: "+responder, func(f *beads.IncidentFields) {
+		f.Responder = responder
+	}); err != nil {
+		return err
+	}
+	if err := bd.Update(id, beads.UpdateOptions{Assignee: &responder}); err != nil {
+		return fmt.Errorf("assigning %s: %w", id, err)
+	}
+	issue, fields, err := bd.GetIncidentBead(id)
+	if err == nil {
+		subject := fmt.Sprintf("[INCIDENT] You are responder for %s: %s", id, issue.Title)
+		if err := sendIncidentMail(townRoot, actor, responder, subject,
+			formatIncidentMailBody(id, r.Name, fields.Severity, "", responder, fields.FreezeQueue)); err != nil {
+			style.PrintWarning("notifying responder %s: %v", responder, err)
+		}
+	}
+	fmt.Printf("%s %s assigned to %s\n", style.Success.Render("✓"), id, responder)
+	return nil
+}
+
+func setIncidentFreeze(id string, freeze bool) error {
+	bd, err := incidentBeads(id)
+	if err != nil {
+		return err
+	}
+	event := "Merge queue unfrozen"
+	if freeze {
+		event = "Merge queue frozen (hotfixes only)"
+	}
+	if _, err := bd.UpdateIncident(id, detectSender(), event, func(f *beads.IncidentFields) {
+		f.FreezeQueue = freeze
+	}); err != nil {
+		return err
+	}
+	fmt.Printf("%s %s\n", style.Success.Render("✓"), event)
+	return nil
+}
+
+func runIncidentList(cmd *cobra.Command, args []string) error {
+	var rigs []string
+	if len(args) > 0 {
+		rigs = args
+	} else {
+		all, _, err := getAllRigs()
+		if err != nil {
+			return err
+		}
+		for _, r := range all {
+			rigs = append(rigs, r.Name)
+		}
+	}
+
+	status := "open"
+	if incidentListAll {
+		status = "all"
+	}
+	infos := []IncidentInfo{}
+	for _, name := range rigs {
+		_, r, err := getRig(name)
+		if err != nil {
+			return err
+		}
+		issues, err := beads.New(r.BeadsPath()).List(beads.ListOptions{Status: status, Label: beads.IncidentLabel, Priority: -1})
+		if err != nil {
+			style.PrintWarning("%s: listing incidents: %v", name, err)
+			continue
+		}
+		for _, issue := range issues {
+			infos = append(infos, newIncidentInfo(issue, beads.ParseIncidentFields(issue.Description)))
+		}
+	}
+
+	if incidentListJSON {
+		return outputJSON(infos)
+	}
+	if len(infos) == 0 {
+		fmt.Printf("%s No open incidents\n", style.Success.Render("✓"))
+		return nil
+	}
+
+	table := style.NewTable(
+		style.Column{Name: "ID", Width: 12},
+		style.Column{Name: "RIG", Width: 12},
+		style.Column{Name: "SEV", Width: 9},
+		style.Column{Name: "QUEUE", Width: 7},
+		style.Column{Name: "RESPONDER", Width: 20},
+		style.Column{Name: "AGE", Width: 9},
+		style.Column{Name: "TITLE", Width: 40},
+	)
+	for _, info := range infos {
+		queue := "open"
+		if info.FreezeQueue {
+			queue = style.Warning.Render("frozen")
+		}
+		if info.Status == "closed" {
+			queue = style.Dim.Render("-")
+		}
+		table.AddRow(info.ID, info.Rig, info.Severity, queue, truncateString(info.Responder, 20),
+			formatTimeAgo(info.DeclaredAt), truncateString(info.Title, 40))
+	}
+	fmt.Print(table.Render())
+	return nil
+}
+
+func runIncidentShow(cmd *cobra.Command, args []string) error {
+	bd, err := incidentBeads(args[0])
+	if err != nil {
+		return err
+	}
+	issue, fields, err := bd.GetIncidentBead(args[0])
+	if err != nil {
+		return err
+	}
+	info := newIncidentInfo(issue, fields)
+	if incidentShowJSON {
+		return outputJSON(info)
+	}
+
+	fmt.Printf("%s %s: %s\n", severityEmoji(info.Severity), info.ID, info.Title)
+	fmt.Printf("  Status:    %s\n", info.Status)
+	fmt.Printf("  Rig:       %s\n", info.Rig)
+	fmt.Printf("  Severity:  %s\n", info.Severity)
+	if info.Responder != "" {
+		fmt.Printf("  Responder: %s\n", info.Responder)
+	}
+	if info.FreezeQueue {
+		fmt.Printf("  Queue:     %s\n", style.Warning.Render("frozen (hotfixes only)"))
+	}
+	if info.Resolution != "" {
+		fmt.Printf("  Resolved:  %s\n", info.Resolution)
+	}
+
+	fmt.Printf("\n%s\n", style.Bold.Render("Timeline"))
+	for _, e := range info.Timeline {
+		fmt.Printf("  %s  %s  %s\n", style.Dim.Render(e.At.Local().Format("01-02 15:04")), e.Actor, e.Text)
+	}
+	if len(info.Timeline) > 1 {
+		start, end := info.Timeline[0].At, info.Timeline[len(info.Timeline)-1].At
+		fmt.Printf("  %s\n", style.Dim.Render("Duration so far: "+end.Sub(start).Round(time.Minute).String()))
+	}
+	return nil
+}
+
+func runIncidentResolve(cmd *cobra.Command, args []string) error {
+	id := args[0]
+	bd, err := incidentBeads(id)
+	if err != nil {
+		return err
+	}
+	issue, fields, err := bd.GetIncidentBead(id)
+	if err != nil {
+		return err
+	}
+	if issue.Status == "closed" {
+		return fmt.Errorf("incident %s is already resolved", id)
+	}
+
+	actor := detectSender()
+	if err := bd.ResolveIncident(id, actor, incidentResolution); err != nil {
+		return fmt.Errorf("resolving incident: %w", err)
+	}
+
+	_ = events.LogFeed(events.TypeIncidentResolved, actor, map[string]interface{}{
+		"incident":   id,
+		"rig":        fields.Rig,
+		"resolution": incidentResolution,
+	})
+
+	// Let the people who were paged know it's over
+	if fields.Responder != "" {
+		if townRoot, err := workspace.FindFromCwdOrError(); err == nil {
+			subject := fmt.Sprintf("[RESOLVED] %s: %s", id, issue.Title)
+			if err := sendIncidentMail(townRoot, actor, fields.Responder, subject, incidentResolution); err != nil {
+				style.PrintWarning("notifying responder %s: %v", fields.Responder, err)
+			}
+		}
+	}
+
+	fmt.Printf("%s Incident %s resolved\n", style.Success.Render("✓"), id)
+	if fields.FreezeQueue {
+		fmt.Printf("  Merge queue for %s unfrozen\n", fields.Rig)
+	}
+	if !incidentNoRetro {
+		recordRetro(id)
+	}
+	return nil
+}
@@ -1,9 +1,7 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -47,9 +45,7 @@ Examples:
 		}
 
 		if jsonFlag {
-			enc := json.NewEncoder(os.Stdout)
-			enc.SetIndent("", "  ")
-			_ = enc.Encode(info)
+			_ = outputJSON(info)
 			return
 		}
 
@@ -209,9 +205,7 @@ var versionChanges = []VersionChange{
 // showWhatsNew displays agent-relevant changes from recent versions
 func showWhatsNew(jsonOutput bool) {
 	if jsonOutput {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		_ = enc.Encode(map[string]interface{}{
+		_ = outputJSON(map[string]interface{}{
 			"current_version": Version,
 			"recent_changes":  versionChanges,
 		})
@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
@@ -164,12 +163,7 @@ func runKrcStats(cmd *cobra.Command, args []string) error {
 	}
 
 	if krcStatsJSON {
-		data, err := json.MarshalIndent(stats, "", "  ")
-		if err != nil {
-			return err
-		}
-		fmt.Println(string(data))
-		return nil
+		return outputJSON(stats)
 	}
 
 	// Human-readable output
@@ -518,12 +512,7 @@ func runKrcDecay(cmd *cobra.Command, args []string) error {
 	report := krc.GenerateDecayReport(stats, config)
 
 	if krcDecayJSON {
-		data, err := json.MarshalIndent(report, "", "  ")
-		if err != nil {
-			return err
-		}
-		fmt.Println(string(data))
-		return nil
+		return outputJSON(report)
 	}
 
 	// Human-readable output
@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/softlock"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/timefmt"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var (
+	locksRig  string
+	locksJSON bool
+)
+
+var locksCmd = &cobra.Command{
+	Use:     "locks",
+	GroupID: GroupWork,
+	Short:   "Show and release soft locks on files",
+	Long: `Show and release file-level soft locks.
+
+Agents declare the files and directories they intend to change when they
+claim an issue, with 'gt hook <bead> --files' or 'gt sling <bead> <target>
+--files'. A claim overlapping another's locked paths is warned, or queued
+until the paths are released, per the rig's file_locks.policy ("warn" or
+"queue") in settings/config.json.
+
+Locks are released when the issue's MR merges, and expire with the claim
+after file_locks.ttl (default 24h). Re-claiming refreshes them.`,
+	RunE: requireSubcommand,
+}
+
+var locksFilesCmd = &cobra.Command{
+	Use:   "files",
+	Short: "List a rig's soft locks on files",
+	Long: `List the files and directories locked by in-progress work in a rig, in
+the order they were claimed. Queued claims are waiting for the paths.
+
+Examples:
+  gt locks files --rig=gastown
+  gt locks files --rig=gastown --json`,
+	Args: cobra.NoArgs,
+	RunE: runLocksFiles,
+}
+
+var locksReleaseCmd = &cobra.Command{
+	Use:   "release <issue>",
+	Short: "Release an issue's soft locks",
+	Long: `Release the file locks an issue's claim took, e.g. when the work is
+abandoned. Locks are released automatically when the issue's MR merges.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runLocksRelease,
+}
+
+func init() {
+	for _, c := range []*cobra.Command{locksFilesCmd, locksReleaseCmd} {
+		c.Flags().StringVar(&locksRig, "rig", "", "Rig (default: inferred from cwd)")
+	}
+	locksFilesCmd.Flags().BoolVar(&locksJSON, "json", false, "Output as JSON")
+
+	locksCmd.AddCommand(locksFilesCmd)
+	locksCmd.AddCommand(locksReleaseCmd)
+	rootCmd.AddCommand(locksCmd)
+}
+
+// locksRigPath resolves the --rig flag, or the rig of the cwd, to its path.
+func locksRigPath() (string, string, error) {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return "", "", fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	rigName := locksRig
+	if rigName == "" {
+		if rigName, err = inferRigFromCwd(townRoot); err != nil || rigName == "" {
+			return "", "", fmt.Errorf("could not determine rig; use --rig")
+		}
+	}
+	return rigName, filepath.Join(townRoot, rigName), nil
+}
+
+func runLocksFiles(cmd *cobra.Command, args []string) error {
+	rigName, rigPath, err := locksRigPath()
+	if err != nil {
+		return err
+	}
+	locks, err := softlock.Load(rigPath, time.Now())
+	if err != nil {
+		return err
+	}
+	if locksJSON {
+		if locks == nil {
+			locks = []softlock.Lock{}
+		}
+		return outputJSON(locks)
+	}
+
+	fmt.Printf("%s File locks for '%s':\n\n", style.Bold.Render("🔒"), rigName)
+	if len(locks) == 0 {
+		fmt.Printf("  %s\n", style.Dim.Render("(none)"))
+		return nil
+	}
+	table := style.NewTable(
+		style.Column{Name: "ISSUE", Width: 12},
+		style.Column{Name: "HOLDER", Width: 28},
+		style.Column{Name: "STATE", Width: 7},
+		style.Column{Name: "EXPIRES", Width: 8, Align: style.AlignRight},
+		style.Column{Name: "PATHS", Width: 40},
+	)
+	now := time.Now()
+	for _, l := range locks {
+		state := style.Success.Render("held")
+		if l.Queued {
+			state = style.Warning.Render("queued")
+		}
+		table.AddRow(l.Issue, l.Holder, state, timefmt.Age(l.ExpiresAt.Sub(now)), strings.Join(l.Paths, ", "))
+	}
+	fmt.Print(table.Render())
+	return nil
+}
+
+func runLocksRelease(cmd *cobra.Command, args []string) error {
+	_, rigPath, err := locksRigPath()
+	if err != nil {
+		return err
+	}
+	released, err := softlock.Release(rigPath, args[0], time.Now())
+	if err != nil {
+		return err
+	}
+	if !released {
+		fmt.Printf("%s %s holds no file locks\n", style.Dim.Render("ℹ"), args[0])
+		return nil
+	}
+	fmt.Printf("%s Released file locks for %s\n", style.Bold.Render("✓"), args[0])
+	return nil
+}
+
+// claimFileLocks locks the paths holder declared for issue in holder's rig,
+// per the rig's file_locks policy. Overlaps with other claims are warned;
+// under the queue policy, it returns an error once the claim is queued so
+// the caller doesn't start the work.
+func claimFileLocks(townRoot, holder, issue string, paths []string) error {
+	rigName, _, _ := strings.Cut(holder, "/")
+	if rigName == "" || rigName == "mayor" || rigName == "deacon" {
+		return fmt.Errorf("--files needs a rig agent; %s has no rig to lock files in", holder)
+	}
+	rigPath := filepath.Join(townRoot, rigName)
+	cleaned, err := softlock.Clean(paths)
+	if err != nil {
+		return fmt.Errorf("--files: %w", err)
+	}
+	if len(cleaned) == 0 {
+		return nil
+	}
+
+	var cfg *config.FileLocksConfig
+	settings, err := config.LoadRigSettings(config.RigSettingsPath(rigPath))
+	if err == nil {
+		cfg = settings.FileLocks
+	} else if !errors.Is(err, config.ErrNotFound) {
+		return fmt.Errorf("loading rig settings: %w", err)
+	}
+	policy, ttl, err := softlock.Settings(cfg)
+	if err != nil {
+		return err
+	}
+
+	lock := softlock.Lock{Issue: issue, Holder: holder, Paths: cleaned}
+	conflicts, queued, err := softlock.Claim(rigPath, lock, policy, ttl, time.Now())
+	if err != nil {
+		return fmt.Errorf("locking files: %w", err)
+	}
+	var ahead []string
+	for _, c := range conflicts {
+		held := "holds"
+		if c.Lock.Queued {
+			held = "is queued for"
+		}
+		style.PrintWarning("%s (%s) %s a lock overlapping %s", c.Lock.Issue, c.Lock.Holder, held, strings.Join(c.Paths, ", "))
+		ahead = append(ahead, c.Lock.Issue)
+	}
+	if queued {
+		return fmt.Errorf("claim on %s queued behind %s; claim again once released (see 'gt locks files --rig=%s')",
+			issue, strings.Join(ahead, ", "), rigName)
+	}
+	fmt.Printf("%s Locked %d path(s) for %s\n", style.Bold.Render("🔒"), len(cleaned), issue)
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/timefmt"
 	"github.com/steveyegge/gastown/internal/townlog"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
@@ -20,6 +21,7 @@ var (
 	logAgent  string
 	logSince  string
 	logFollow bool
+	logFormat string
 
 	// log crash flags
 	crashAgent    string
@@ -48,7 +50,8 @@ Examples:
   gt log --type spawn        # Show only spawn events
   gt log --agent greenplace/    # Show events for gastown rig
   gt log --since 1h          # Show events from last hour
-  gt log -f                  # Follow log (like tail -f)`,
+  gt log -f                  # Follow log (like tail -f)
+  gt log -n 0 --format ndjson   # Every event, one JSON object per line`,
 	RunE: runLog,
 }
 
@@ -75,6 +78,7 @@ func init() {
 	logCmd.Flags().StringVarP(&logAgent, "agent", "a", "", "Filter by agent prefix (e.g., gastown/, greenplace/crew/max)")
 	logCmd.Flags().StringVar(&logSince, "since", "", "Show events since duration (e.g., 1h, 30m, 24h)")
 	logCmd.Flags().BoolVarP(&logFollow, "follow", "f", false, "Follow log output (like tail -f)")
+	logCmd.Flags().StringVar(&logFormat, "format", formatText, "Output format: text, json, or ndjson (one event per line)")
 
 	// crash subcommand flags
 	logCrashCmd.Flags().StringVar(&crashAgent, "agent", "", "Agent ID (e.g., greenplace/Toast)")
@@ -92,15 +96,26 @@ func runLog(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("not in a Gas Town workspace: %w", err)
 	}
 
+	format, err := resolveFormat(logFormat, false)
+	if err != nil {
+		return err
+	}
+
 	logPath := fmt.Sprintf("%s/logs/town.log", townRoot)
 
 	// If following, use tail -f
 	if logFollow {
+		if format != formatText {
+			return fmt.Errorf("--follow only supports --format text")
+		}
 		return followLog(logPath)
 	}
 
 	// Check if log file exists
 	if _, err := os.Stat(logPath); os.IsNotExist(err) {
+		if format != formatText {
+			return writeEvents(format, nil)
+		}
 		fmt.Printf("%s No log file yet (no events recorded)\n", style.Dim.Render("○"))
 		return nil
 	}
@@ -111,7 +126,7 @@ func runLog(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("reading events: %w", err)
 	}
 
-	if len(events) == 0 {
+	if len(events) == 0 && format == formatText {
 		fmt.Printf("%s No events in log\n", style.Dim.Render("○"))
 		return nil
 	}
@@ -143,6 +158,10 @@ func runLog(cmd *cobra.Command, args []string) error {
 		events = events[len(events)-logTail:]
 	}
 
+	if format != formatText {
+		return writeEvents(format, events)
+	}
+
 	if len(events) == 0 {
 		fmt.Printf("%s No events match filter\n", style.Dim.Render("○"))
 		return nil
@@ -156,6 +175,23 @@ func runLog(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// writeEvents prints events as a JSON array or as NDJSON, one per line.
+func writeEvents(format string, events []townlog.Event) error {
+	if format == formatJSON {
+		if events == nil {
+			events = []townlog.Event{}
+		}
+		return outputJSON(events)
+	}
+	stream := newNDJSONWriter(os.Stdout)
+	for _, e := range events {
+		if err := stream.Write(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // followLog uses tail -f to follow the log file.
 func followLog(logPath string) error {
 	// Check if log file exists, create empty if not
@@ -180,7 +216,7 @@ func followLog(logPath string) error {
 
 // printEvent prints a single event with styling.
 func printEvent(e townlog.Event) {
-	ts := e.Timestamp.Format("2006-01-02 15:04:05")
+	ts := timefmt.ExactSeconds(e.Timestamp)
 
 	// Color-code event types
 	var typeStr string
@@ -296,10 +332,7 @@ func formatEventDetail(e townlog.Event) string {
 }
 
 func truncateStr(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
-	}
-	return s[:maxLen-3] + "..."
+	return style.Truncate(s, maxLen)
 }
 
 // runLogCrash handles the "gt log crash" command from tmux pane-died hooks.
@@ -72,9 +72,7 @@ func listAnnounceChannels(cfg *config.MessagingConfig) error {
 		sort.Slice(channels, func(i, j int) bool {
 			return channels[i].Name < channels[j].Name
 		})
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(channels)
+		return outputJSON(channels)
 	}
 
 	// Human-readable output
@@ -124,9 +122,7 @@ func readAnnounceChannel(townRoot string, cfg *config.MessagingConfig, channelNa
 		if messages == nil {
 			messages = []announceMessage{}
 		}
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(messages)
+		return outputJSON(messages)
 	}
 
 	// Human-readable output
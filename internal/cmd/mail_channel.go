@@ -156,9 +156,7 @@ func runChannelList(cmd *cobra.Command, args []string) error {
 	}
 
 	if channelJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(channels)
+		return outputJSON(channels)
 	}
 
 	if len(channels) == 0 {
@@ -214,9 +212,7 @@ func runChannelShow(cmd *cobra.Command, args []string) error {
 		if messages == nil {
 			messages = []channelMessage{}
 		}
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(messages)
+		return outputJSON(messages)
 	}
 
 	fmt.Printf("%s Channel: %s (%d messages)\n",
@@ -445,9 +441,7 @@ func runChannelSubscribers(cmd *cobra.Command, args []string) error {
 		if subs == nil {
 			subs = []string{}
 		}
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(subs)
+		return outputJSON(subs)
 	}
 
 	if len(fields.Subscribers) == 0 {
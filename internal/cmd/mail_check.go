@@ -1,9 +1,7 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/mail"
@@ -55,9 +53,7 @@ func runMailCheck(cmd *cobra.Command, args []string) error {
 			"unread":  unread,
 			"has_new": unread > 0,
 		}
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(result)
+		return outputJSON(result)
 	}
 
 	// Inject mode: notify agent of mail with priority-appropriate framing.
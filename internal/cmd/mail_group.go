@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
@@ -127,9 +126,7 @@ func runGroupList(cmd *cobra.Command, args []string) error {
 	}
 
 	if groupJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(groups)
+		return outputJSON(groups)
 	}
 
 	if len(groups) == 0 {
@@ -168,9 +165,7 @@ func runGroupShow(cmd *cobra.Command, args []string) error {
 	}
 
 	if groupJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(fields)
+		return outputJSON(fields)
 	}
 
 	fmt.Printf("Group: %s\n", fields.Name)
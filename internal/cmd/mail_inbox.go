@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -68,9 +67,7 @@ func runMailInbox(cmd *cobra.Command, args []string) error {
 
 	// JSON output
 	if mailInboxJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(messages)
+		return outputJSON(messages)
 	}
 
 	// Human-readable output
@@ -159,9 +156,7 @@ func runMailRead(cmd *cobra.Command, args []string) error {
 
 	// JSON output
 	if mailReadJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(msg)
+		return outputJSON(msg)
 	}
 
 	// Human-readable output
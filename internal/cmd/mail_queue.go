@@ -626,12 +626,7 @@ func runMailQueueShow(cmd *cobra.Command, args []string) error {
 			"created_by":       fields.CreatedBy,
 			"created_at":       fields.CreatedAt,
 		}
-		jsonBytes, err := json.MarshalIndent(output, "", "  ")
-		if err != nil {
-			return fmt.Errorf("marshaling JSON: %w", err)
-		}
-		fmt.Println(string(jsonBytes))
-		return nil
+		return outputJSON(output)
 	}
 
 	// Human-readable output
@@ -687,12 +682,7 @@ func runMailQueueList(cmd *cobra.Command, args []string) error {
 				"status":        fields.Status,
 			})
 		}
-		jsonBytes, err := json.MarshalIndent(output, "", "  ")
-		if err != nil {
-			return fmt.Errorf("marshaling JSON: %w", err)
-		}
-		fmt.Println(string(jsonBytes))
-		return nil
+		return outputJSON(output)
 	}
 
 	// Human-readable output
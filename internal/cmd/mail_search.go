@@ -1,9 +1,7 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/mail"
@@ -46,9 +44,7 @@ func runMailSearch(cmd *cobra.Command, args []string) error {
 
 	// JSON output
 	if mailSearchJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(messages)
+		return outputJSON(messages)
 	}
 
 	// Human-readable output
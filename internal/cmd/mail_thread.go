@@ -1,9 +1,7 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -37,9 +35,7 @@ func runMailThread(cmd *cobra.Command, args []string) error {
 
 	// JSON output
 	if mailThreadJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(messages)
+		return outputJSON(messages)
 	}
 
 	// Human-readable output
@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/memo"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Memo command flags
+var (
+	memoTopics  []string
+	memoRig     string
+	memoTown    bool
+	memoIssues  []string
+	memoMessage string
+	memoFile    string
+	memoJSON    bool
+
+	memoListTopic string
+	memoListRig   string
+	memoListIssue string
+)
+
+var memoCmd = &cobra.Command{
+	Use:     "memo",
+	GroupID: GroupWork,
+	Short:   "Record and read decisions in the town's memo log",
+	Long: `Keep a log of architectural decisions and other knowledge that should
+outlive the session that produced it.
+
+Memos are Markdown files in <town>/memos/, indexed by topic and rig. A memo
+without a rig applies town-wide. 'gt context' includes the memos linked to
+an issue or matching its labels and title, so the next agent on related
+work sees the decision. Find memos with 'gt search'.
+
+Examples:
+  gt memo add "Sessions use cookies, not JWTs" --topic auth -m "Because..."
+  gt memo add "Retry policy" --topic network --file decision.md --town
+  gt memo list --topic auth
+  gt memo show memo-20261015-sessions-use-cookies`,
+	RunE: requireSubcommand,
+}
+
+var memoAddCmd = &cobra.Command{
+	Use:   "add <title>",
+	Short: "Record a decision",
+	Long: `Record a decision memo. The body comes from -m or --file (- for stdin).
+
+The memo belongs to the current rig (GT_RIG) unless --rig or --town says
+otherwise. --issue links it to the issues it was made for.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMemoAdd,
+}
+
+var memoListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List memos, newest first",
+	RunE:  runMemoList,
+}
+
+var memoShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show a memo (an unambiguous ID prefix is enough)",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runMemoShow,
+}
+
+func init() {
+	memoAddCmd.Flags().StringSliceVarP(&memoTopics, "topic", "t", nil, "Topic to index the memo under (repeatable)")
+	memoAddCmd.Flags().StringVar(&memoRig, "rig", "", "Rig the memo applies to (default: current rig)")
+	memoAddCmd.Flags().BoolVar(&memoTown, "town", false, "Make the memo town-wide")
+	memoAddCmd.Flags().StringSliceVar(&memoIssues, "issue", nil, "Issue the decision was made for (repeatable)")
+	memoAddCmd.Flags().StringVarP(&memoMessage, "message", "m", "", "Memo body")
+	memoAddCmd.Flags().StringVar(&memoFile, "file", "", "Read the memo body from a file (- for stdin)")
+	memoAddCmd.Flags().BoolVar(&memoJSON, "json", false, "Output as JSON")
+
+	memoListCmd.Flags().StringVarP(&memoListTopic, "topic", "t", "", "Only memos with this topic")
+	memoListCmd.Flags().StringVar(&memoListRig, "rig", "", "Only memos for this rig (and town-wide ones)")
+	memoListCmd.Flags().StringVar(&memoListIssue, "issue", "", "Only memos linked to this issue")
+	memoListCmd.Flags().BoolVar(&memoJSON, "json", false, "Output as JSON")
+
+	memoShowCmd.Flags().BoolVar(&memoJSON, "json", false, "Output as JSON")
+
+	memoCmd.AddCommand(memoAddCmd)
+	memoCmd.AddCommand(memoListCmd)
+	memoCmd.AddCommand(memoShowCmd)
+	rootCmd.AddCommand(memoCmd)
+}
+
+func runMemoAdd(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	body, err := memoBody()
+	if err != nil {
+		return err
+	}
+	rigName := memoRig
+	if rigName == "" && !memoTown {
+		rigName = os.Getenv("GT_RIG")
+	}
+	if memoTown {
+		if memoRig != "" {
+			return fmt.Errorf("--rig and --town are mutually exclusive")
+		}
+		rigName = ""
+	}
+
+	m := &memo.Memo{
+		Title:  args[0],
+		Topics: memoTopics,
+		Rig:    rigName,
+		Author: detectSender(),
+		Issues: memoIssues,
+		Body:   body,
+	}
+	if err := memo.NewStore(townRoot).Add(m, time.Now()); err != nil {
+		return err
+	}
+
+	if memoJSON {
+		return outputJSON(m)
+	}
+	scope := "town-wide"
+	if m.Rig != "" {
+		scope = m.Rig
+	}
+	fmt.Printf("%s Recorded %s (%s)\n", style.Success.Render("✓"), m.ID, scope)
+	return nil
+}
+
+// memoBody reads the memo body from -m or --file.
+func memoBody() (string, error) {
+	switch {
+	case memoMessage != "" && memoFile != "":
+		return "", fmt.Errorf("-m and --file are mutually exclusive")
+	case memoMessage != "":
+		return memoMessage, nil
+	case memoFile == "-":
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("reading stdin: %w", err)
+		}
+		return string(data), nil
+	case memoFile != "":
+		data, err := os.ReadFile(memoFile) //nolint:gosec // G304: user-specified input file
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+	return "", fmt.Errorf("memo body required: use -m or --file")
+}
+
+func runMemoList(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	memos, err := memo.NewStore(townRoot).List(memo.Filter{
+		Topic: memoListTopic,
+		Rig:   memoListRig,
+		Issue: memoListIssue,
+	})
+	if err != nil {
+		return err
+	}
+
+	if memoJSON {
+		if memos == nil {
+			memos = []*memo.Memo{}
+		}
+		return outputJSON(memos)
+	}
+	if len(memos) == 0 {
+		fmt.Println(style.Dim.Render("No memos"))
+		return nil
+	}
+	for _, m := range memos {
+		printMemoLine(m)
+	}
+	return nil
+}
+
+// printMemoLine prints a one-line memo summary.
+func printMemoLine(m *memo.Memo) {
+	var tags []string
+	if m.Rig != "" {
+		tags = append(tags, m.Rig)
+	}
+	tags = append(tags, m.Topics...)
+	fmt.Printf("%s  %s  %s\n", style.Bold.Render(m.ID), m.Title, style.Dim.Render(strings.Join(tags, " · ")))
+}
+
+func runMemoShow(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	m, err := memo.NewStore(townRoot).Get(args[0])
+	if err != nil {
+		return err
+	}
+
+	if memoJSON {
+		return outputJSON(m)
+	}
+	fmt.Printf("%s\n", style.Bold.Render(m.Title))
+	fmt.Printf("%s\n", style.Dim.Render(m.ID))
+	if m.Rig != "" {
+		fmt.Printf("Rig:     %s\n", m.Rig)
+	} else {
+		fmt.Printf("Rig:     %s\n", style.Dim.Render("town-wide"))
+	}
+	if len(m.Topics) > 0 {
+		fmt.Printf("Topics:  %s\n", strings.Join(m.Topics, ", "))
+	}
+	if len(m.Issues) > 0 {
+		fmt.Printf("Issues:  %s\n", strings.Join(m.Issues, ", "))
+	}
+	if m.Author != "" {
+		fmt.Printf("Author:  %s\n", m.Author)
+	}
+	fmt.Printf("Created: %s\n\n", m.Created.Local().Format("2006-01-02 15:04"))
+	fmt.Println(m.Body)
+	return nil
+}
@@ -0,0 +1,508 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/estimate"
+	"github.com/steveyegge/gastown/internal/goal"
+	"github.com/steveyegge/gastown/internal/milestone"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Milestone command flags
+var (
+	milestoneTarget      string
+	milestoneStart       string
+	milestoneSummary     string
+	milestoneOwner       string
+	milestoneGoal        string
+	milestoneListAll     bool
+	milestoneJSON        bool
+	milestoneBurndown    bool
+	milestoneMarkdown    bool
+	milestoneCloseReason string
+	milestoneCloseForce  bool
+	milestoneNoRetro     bool
+)
+
+var milestoneCmd = &cobra.Command{
+	Use:     "milestone",
+	GroupID: GroupWork,
+	Short:   "Track delivery targets across rigs",
+	RunE:    requireSubcommand,
+	Long: `Manage milestones: town-level delivery targets that group issues across
+rigs under a target date.
+
+A milestone is a town bead (hq-*) labeled gt:milestone. Like a convoy it
+tracks its issues with non-blocking 'tracks' relations, so an issue in any
+rig can belong to it. Unlike a convoy it has a start and target date, and
+its status shows whether the work is on track to land by the target.
+
+Progress counts issues closed; where issues carry estimates ('gt estimate')
+the estimated hours left are shown too. The projection assumes issues keep
+closing at the rate they have since the start date.
+
+COMMANDS:
+  create    Create a milestone with a target date
+  add       Add issues to a milestone
+  remove    Remove issues from a milestone
+  list      List milestones with progress and health
+  status    Show a milestone's progress, open issues, and burndown
+  close     Close a milestone (saves a retrospective memo)`,
+}
+
+var milestoneCreateCmd = &cobra.Command{
+	Use:   "create <name> [issues...]",
+	Short: "Create a milestone",
+	Long: `Create a milestone with a target date, optionally adding issues to it.
+
+Examples:
+  gt milestone create "v2 onboarding" --target 2026-03-27
+  gt milestone create "Q2 hardening" --target 2026-06-30 --start 2026-04-01 gt-abc bd-def
+  gt milestone create "Self-serve signup" --target 2026-05-15 --goal faster-onboarding`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runMilestoneCreate,
+}
+
+var milestoneAddCmd = &cobra.Command{
+	Use:   "add <milestone-id> <issue-id> [issue-id...]",
+	Short: "Add issues to a milestone",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  runMilestoneAdd,
+}
+
+var milestoneRemoveCmd = &cobra.Command{
+	Use:   "remove <milestone-id> <issue-id> [issue-id...]",
+	Short: "Remove issues from a milestone",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  runMilestoneRemove,
+}
+
+var milestoneListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List milestones",
+	Long: `List milestones by target date, with progress and health:
+
+  on-track  Projected to land by the target
+  at-risk   Projected to land after the target
+  late      Past the target with issues open
+  unknown   Nothing closed yet to project from
+  done      Every issue closed
+
+Examples:
+  gt milestone list
+  gt milestone list --all --json`,
+	Args: cobra.NoArgs,
+	RunE: runMilestoneList,
+}
+
+var milestoneStatusCmd = &cobra.Command{
+	Use:   "status <milestone-id>",
+	Short: "Show a milestone's progress",
+	Long: `Show a milestone's progress: issues closed per rig, estimated hours
+left, projected finish, and the open issues.
+
+--burndown adds a daily burndown chart: bars for the issues still open at
+the end of each day, │ for the ideal line down to the target. --markdown
+renders the status as a report section.
+
+Examples:
+  gt milestone status hq-abc
+  gt milestone status hq-abc --burndown
+  gt milestone status hq-abc --markdown >> weekly.md
+  gt milestone status hq-abc --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMilestoneStatus,
+}
+
+var milestoneCloseCmd = &cobra.Command{
+	Use:   "close <milestone-id>",
+	Short: "Close a milestone",
+	Long: `Close a milestone. By default every issue must be closed; --force closes
+it regardless, for a target that was dropped or moved to a new milestone.
+
+A retrospective memo is saved on close (see 'gt retro').
+
+Examples:
+  gt milestone close hq-abc
+  gt milestone close hq-abc --force --reason "moved to Q3"`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMilestoneClose,
+}
+
+func init() {
+	milestoneCreateCmd.Flags().StringVar(&milestoneTarget, "target", "", "Target date, YYYY-MM-DD (required)")
+	milestoneCreateCmd.Flags().StringVar(&milestoneStart, "start", "", "Start date, YYYY-MM-DD (default: today)")
+	milestoneCreateCmd.Flags().StringVarP(&milestoneSummary, "message", "m", "", "What the milestone delivers")
+	milestoneCreateCmd.Flags().StringVar(&milestoneOwner, "owner", "", "Who answers for the milestone (default: you)")
+	milestoneCreateCmd.Flags().StringVar(&milestoneGoal, "goal", "", "Link the milestone to a town goal (see 'gt goal')")
+	_ = milestoneCreateCmd.MarkFlagRequired("target")
+
+	milestoneListCmd.Flags().BoolVar(&milestoneListAll, "all", false, "Include closed milestones")
+	milestoneListCmd.Flags().BoolVar(&milestoneJSON, "json", false, "Output as JSON")
+
+	milestoneStatusCmd.Flags().BoolVar(&milestoneBurndown, "burndown", false, "Show the burndown chart")
+	milestoneStatusCmd.Flags().BoolVar(&milestoneMarkdown, "markdown", false, "Output as a Markdown report section")
+	milestoneStatusCmd.Flags().BoolVar(&milestoneJSON, "json", false, "Output as JSON")
+
+	milestoneCloseCmd.Flags().StringVar(&milestoneCloseReason, "reason", "", "Reason for closing")
+	milestoneCloseCmd.Flags().BoolVarP(&milestoneCloseForce, "force", "f", false, "Close even if issues are still open")
+	milestoneCloseCmd.Flags().BoolVar(&milestoneNoRetro, "no-retro", false, "Don't save a retrospective memo")
+
+	milestoneCmd.AddCommand(milestoneCreateCmd)
+	milestoneCmd.AddCommand(milestoneAddCmd)
+	milestoneCmd.AddCommand(milestoneRemoveCmd)
+	milestoneCmd.AddCommand(milestoneListCmd)
+	milestoneCmd.AddCommand(milestoneStatusCmd)
+	milestoneCmd.AddCommand(milestoneCloseCmd)
+
+	rootCmd.AddCommand(milestoneCmd)
+}
+
+func runMilestoneCreate(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	owner := milestoneOwner
+	if owner == "" {
+		owner = detectSender()
+	}
+	if milestoneGoal != "" {
+		if err := requireGoal(townRoot, milestoneGoal); err != nil {
+			return err
+		}
+	}
+	bd := beads.New(townRoot)
+	fields := &beads.MilestoneFields{Start: milestoneStart, Target: milestoneTarget, Owner: owner}
+	issue, err := bd.CreateMilestoneBead(args[0], milestoneSummary, fields)
+	if err != nil {
+		return fmt.Errorf("creating milestone: %w", err)
+	}
+	if milestoneGoal != "" {
+		if err := bd.Update(issue.ID, beads.UpdateOptions{AddLabels: []string{goal.Label(milestoneGoal)}}); err != nil {
+			style.PrintWarning("couldn't link %s to goal %s: %v", issue.ID, milestoneGoal, err)
+		}
+	}
+
+	added := trackMilestoneIssues(townRoot, issue.ID, args[1:])
+	fmt.Printf("%s Created milestone 🎯 %s\n\n", style.Bold.Render("✓"), issue.ID)
+	fmt.Printf("  Name:     %s\n", args[0])
+	fmt.Printf("  Dates:    %s → %s\n", fields.Start, fields.Target)
+	if len(args) > 1 {
+		fmt.Printf("  Tracking: %d issues\n", len(added))
+	}
+	if owner != "" {
+		fmt.Printf("  Owner:    %s\n", owner)
+	}
+	if milestoneGoal != "" {
+		fmt.Printf("  Goal:     %s\n", milestoneGoal)
+	}
+	return nil
+}
+
+func runMilestoneAdd(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	id := args[0]
+	if _, _, err := beads.New(townRoot).GetMilestoneBead(id); err != nil {
+		return err
+	}
+	added := trackMilestoneIssues(townRoot, id, args[1:])
+	fmt.Printf("%s Added %d issue(s) to milestone 🎯 %s\n", style.Bold.Render("✓"), len(added), id)
+	if len(added) > 0 {
+		fmt.Printf("  Issues: %s\n", strings.Join(added, ", "))
+	}
+	return nil
+}
+
+// trackMilestoneIssues adds 'tracks' relations from a milestone to each
+// issue, warning about the ones that fail. Returns the issues added.
+func trackMilestoneIssues(townRoot, milestoneID string, issueIDs []string) []string {
+	var added []string
+	for _, issueID := range issueIDs {
+		depCmd := exec.Command("bd", "dep", "add", milestoneID, issueID, "--type=tracks")
+		depCmd.Dir = townRoot
+		var stderr bytes.Buffer
+		depCmd.Stderr = &stderr
+		if err := depCmd.Run(); err != nil {
+			errMsg := strings.TrimSpace(stderr.String())
+			if errMsg == "" {
+				errMsg = err.Error()
+			}
+			style.PrintWarning("couldn't add %s: %s", issueID, errMsg)
+			continue
+		}
+		added = append(added, issueID)
+	}
+	return added
+}
+
+func runMilestoneRemove(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	id := args[0]
+	bd := beads.New(townRoot)
+	if _, _, err := bd.GetMilestoneBead(id); err != nil {
+		return err
+	}
+	removed := 0
+	for _, issueID := range args[1:] {
+		if err := bd.RemoveDependency(id, issueID); err != nil {
+			style.PrintWarning("couldn't remove %s: %v", issueID, err)
+			continue
+		}
+		removed++
+	}
+	fmt.Printf("%s Removed %d issue(s) from milestone 🎯 %s\n", style.Bold.Render("✓"), removed, id)
+	return nil
+}
+
+// buildMilestoneProgress gathers a milestone's tracked issues from their
+// rigs and computes its progress.
+func buildMilestoneProgress(townRoot string, issue *beads.Issue, fields *beads.MilestoneFields, now time.Time) (*milestone.Progress, error) {
+	issues, err := trackedMilestoneIssues(townRoot, issue.ID)
+	if err != nil {
+		return nil, fmt.Errorf("listing milestone issues: %w", err)
+	}
+	start := fields.StartDate()
+	if start.IsZero() {
+		start = startOfDay(parseRetroTime(issue.CreatedAt))
+	}
+	return milestone.Build(issue.ID, issue.Title, issue.Status, start, fields.TargetDate(), issues, now), nil
+}
+
+// trackedMilestoneIssues returns the issues a milestone or convoy tracks,
+// with their rig, close time, and estimate.
+func trackedMilestoneIssues(townRoot, id string) ([]milestone.Issue, error) {
+	tracked, err := getTrackedIssues(beads.GetTownBeadsPath(townRoot), id)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(tracked))
+	for _, t := range tracked {
+		ids = append(ids, t.ID)
+	}
+	full := make(map[string]*beads.Issue)
+	for _, ti := range showIssuesAcrossRigs(ids) {
+		full[ti.ID] = ti
+	}
+
+	issues := make([]milestone.Issue, 0, len(tracked))
+	for _, t := range tracked {
+		it := milestone.Issue{
+			ID:     t.ID,
+			Title:  t.Title,
+			Status: t.Status,
+			Rig:    beads.GetRigNameForPrefix(townRoot, beads.ExtractPrefix(t.ID)),
+		}
+		if ti := full[t.ID]; ti != nil {
+			it.Closed = parseRetroTime(ti.ClosedAt)
+			if est, ok := estimate.FromLabels(ti.Labels); ok {
+				it.Hours = est.Hours
+			}
+		}
+		issues = append(issues, it)
+	}
+	return issues, nil
+}
+
+// startOfDay returns local midnight on t's day.
+func startOfDay(t time.Time) time.Time {
+	t = t.Local()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+func runMilestoneList(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	status := "open"
+	if milestoneListAll {
+		status = "all"
+	}
+	bd := beads.New(townRoot)
+	list, err := bd.ListMilestones(status)
+	if err != nil {
+		return fmt.Errorf("listing milestones: %w", err)
+	}
+
+	now := time.Now()
+	progress := make([]*milestone.Progress, 0, len(list))
+	for _, issue := range list {
+		p, err := buildMilestoneProgress(townRoot, issue, beads.ParseMilestoneFields(issue.Description), now)
+		if err != nil {
+			style.PrintWarning("skipping milestone %s: %v", issue.ID, err)
+			continue
+		}
+		p.Issues, p.Burndown = nil, nil
+		progress = append(progress, p)
+	}
+	sortMilestones(progress)
+
+	if milestoneJSON {
+		return outputJSON(progress)
+	}
+	if len(progress) == 0 {
+		fmt.Printf("%s No milestones\n", style.Dim.Render("○"))
+		return nil
+	}
+	table := style.NewTable(
+		style.Column{Name: "ID", Width: 12},
+		style.Column{Name: "NAME", Width: 28},
+		style.Column{Name: "TARGET", Width: 10},
+		style.Column{Name: "PROGRESS", Width: 18},
+		style.Column{Name: "HEALTH", Width: 9},
+	)
+	for _, p := range progress {
+		table.AddRow(p.ID, p.Title, p.Target.Format(time.DateOnly),
+			fmt.Sprintf("%d/%d %s", p.Closed, p.Total, style.ProgressBar(p.Percent(), 6)),
+			renderMilestoneHealth(p.Health))
+	}
+	fmt.Print(table.Render())
+	return nil
+}
+
+// sortMilestones orders milestones by target date, soonest first.
+func sortMilestones(progress []*milestone.Progress) {
+	sort.SliceStable(progress, func(i, j int) bool { return progress[i].Target.Before(progress[j].Target) })
+}
+
+func renderMilestoneHealth(health string) string {
+	switch health {
+	case milestone.HealthDone, milestone.HealthOnTrack:
+		return style.Success.Render(health)
+	case milestone.HealthAtRisk:
+		return style.Warning.Render(health)
+	case milestone.HealthLate:
+		return style.Error.Render(health)
+	}
+	return style.Dim.Render(health)
+}
+
+func runMilestoneStatus(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	issue, fields, err := beads.New(townRoot).GetMilestoneBead(args[0])
+	if err != nil {
+		return err
+	}
+	p, err := buildMilestoneProgress(townRoot, issue, fields, time.Now())
+	if err != nil {
+		return err
+	}
+	switch {
+	case milestoneJSON:
+		return outputJSON(p)
+	case milestoneMarkdown:
+		fmt.Print(p.Markdown())
+		return nil
+	}
+
+	fmt.Printf("🎯 %s: %s\n\n", style.Bold.Render(p.ID), p.Title)
+	target := p.Target.Format(time.DateOnly)
+	switch {
+	case p.Status == "closed":
+		target += " (closed)"
+	case p.DaysLeft >= 0:
+		target += fmt.Sprintf(" (%d days left)", p.DaysLeft)
+	}
+	fmt.Printf("  Target:    %s  %s\n", target, renderMilestoneHealth(p.Health))
+	fmt.Printf("  Progress:  %d/%d closed %s\n", p.Closed, p.Total, style.ProgressBar(p.Percent(), 20))
+	if p.Hours > 0 {
+		fmt.Printf("  Hours:     %s left of %s estimated\n", formatEstimateHours(p.RemainingHours), formatEstimateHours(p.Hours))
+	}
+	if !p.Projected.IsZero() {
+		fmt.Printf("  Projected: %s at %.1f issues/day\n", p.Projected.Format(time.DateOnly), p.Velocity)
+	}
+	if fields.Owner != "" {
+		fmt.Printf("  Owner:     %s\n", fields.Owner)
+	}
+
+	if len(p.Rigs) > 1 {
+		fmt.Println()
+		for _, r := range p.Rigs {
+			name := r.Rig
+			if name == "" {
+				name = "town"
+			}
+			fmt.Printf("  %-16s %d/%d\n", name, r.Closed, r.Total)
+		}
+	}
+
+	var open []milestone.Issue
+	for _, it := range p.Issues {
+		if !it.IsClosed() {
+			open = append(open, it)
+		}
+	}
+	if len(open) > 0 {
+		fmt.Printf("\n  Open issues:\n")
+		for _, it := range open {
+			fmt.Printf("    %s %s %s %s\n", style.Dim.Render("○"), it.ID, it.Title, style.Dim.Render("["+it.Status+"]"))
+		}
+	}
+
+	if milestoneBurndown {
+		if chart := milestone.Chart(p.Burndown, 40); chart != "" {
+			fmt.Printf("\n  Burndown:\n")
+			for _, line := range strings.Split(strings.TrimRight(chart, "\n"), "\n") {
+				fmt.Printf("    %s\n", line)
+			}
+		}
+	}
+	return nil
+}
+
+func runMilestoneClose(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	id := args[0]
+	bd := beads.New(townRoot)
+	issue, fields, err := bd.GetMilestoneBead(id)
+	if err != nil {
+		return err
+	}
+	if issue.Status == "closed" {
+		fmt.Printf("%s Milestone %s is already closed\n", style.Dim.Render("○"), id)
+		return nil
+	}
+	if !milestoneCloseForce {
+		p, err := buildMilestoneProgress(townRoot, issue, fields, time.Now())
+		if err != nil {
+			return err
+		}
+		if open := p.Total - p.Closed; open > 0 {
+			return fmt.Errorf("milestone %s has %d open issue(s); use --force to close anyway", id, open)
+		}
+	}
+	reason := milestoneCloseReason
+	if reason == "" {
+		reason = "milestone complete"
+	}
+	if err := bd.CloseWithReason(reason, id); err != nil {
+		return fmt.Errorf("closing milestone: %w", err)
+	}
+	fmt.Printf("%s Closed milestone 🎯 %s: %s\n", style.Bold.Render("✓"), id, issue.Title)
+	if !milestoneNoRetro {
+		recordRetro(id)
+	}
+	return nil
+}
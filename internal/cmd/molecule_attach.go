@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 
@@ -167,9 +166,7 @@ func runMoleculeAttachment(cmd *cobra.Command, args []string) error {
 			out.AttachedMolecule = attachment.AttachedMolecule
 			out.AttachedAt = attachment.AttachedAt
 		}
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(out)
+		return outputJSON(out)
 	}
 
 	// Human-readable output
@@ -3,7 +3,6 @@ package cmd
 import (
 	"bufio"
 	"context"
-	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
@@ -216,9 +215,7 @@ func runMoleculeAwaitSignal(cmd *cobra.Command, args []string) error {
 
 	// Output result
 	if moleculeJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(result)
+		return outputJSON(result)
 	}
 
 	if !awaitSignalQuiet {
@@ -1,9 +1,7 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
 	"sort"
 	"strings"
 
@@ -104,9 +102,7 @@ func runMoleculeDag(cmd *cobra.Command, args []string) error {
 
 	// JSON output
 	if moleculeJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(dag)
+		return outputJSON(dag)
 	}
 
 	// Human-readable output
@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
@@ -103,9 +102,7 @@ func runMoleculeBurn(cmd *cobra.Command, args []string) error {
 			"handoff_id":      handoff.ID,
 			"children_closed": childrenClosed,
 		}
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(result)
+		return outputJSON(result)
 	}
 
 	fmt.Printf("%s Burned molecule %s from %s\n",
@@ -261,9 +258,7 @@ squashed_at: %s
 			"handoff_id":      handoff.ID,
 			"children_closed": childrenClosed,
 		}
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(result)
+		return outputJSON(result)
 	}
 
 	fmt.Printf("%s Squashed molecule %s → digest %s\n",
@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
@@ -255,9 +254,7 @@ func runMoleculeProgress(cmd *cobra.Command, args []string) error {
 
 	// JSON output
 	if moleculeJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(progress)
+		return outputJSON(progress)
 	}
 
 	// Human-readable output
@@ -489,9 +486,7 @@ func runMoleculeStatus(cmd *cobra.Command, args []string) error {
 
 	// JSON output
 	if moleculeJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(status)
+		return outputJSON(status)
 	}
 
 	// Human-readable output
@@ -936,9 +931,7 @@ func runMoleculeCurrent(cmd *cobra.Command, args []string) error {
 // outputMoleculeCurrent outputs the current info in the appropriate format.
 func outputMoleculeCurrent(info MoleculeCurrentInfo) error {
 	if moleculeJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(info)
+		return outputJSON(info)
 	}
 
 	// Human-readable output matching spec format
@@ -6,8 +6,10 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/ci"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/refinery"
@@ -23,6 +25,11 @@ var (
 	mqSubmitEpic      string
 	mqSubmitPriority  int
 	mqSubmitNoCleanup bool
+	mqSubmitHotfix    bool
+	mqSubmitWait      bool
+	mqSubmitTimeout   time.Duration
+	mqSubmitDependsOn []string
+	mqSubmitTarget    string
 
 	// Retry flags
 	mqRetryNow bool
@@ -33,11 +40,17 @@ var (
 	mqRejectStdin  bool // Read reason from stdin
 
 	// List command flags
-	mqListReady  bool
-	mqListStatus string
-	mqListWorker string
-	mqListEpic   string
-	mqListJSON   bool
+	mqListReady     bool
+	mqListStatus    string
+	mqListWorker    string
+	mqListEpic      string
+	mqListTarget    string
+	mqListJSON      bool
+	mqListPorcelain string
+	mqListSLO       bool
+	mqListConflicts bool
+	mqListLimit     int
+	mqListOffset    int
 
 	// Status command flags
 	mqStatusJSON bool
@@ -84,9 +97,16 @@ Auto-detection:
   - Priority: inherited from source issue
 
 Target branch auto-detection:
-  1. If --epic is specified: target integration/<epic>
-  2. If source issue has a parent epic with integration/<epic> branch: target it
-  3. Otherwise: target main
+  1. If --target is specified: target that branch (e.g. a release branch)
+  2. If --epic is specified: target integration/<epic>
+  3. If source issue has a parent epic with integration/<epic> branch: target it
+  4. Otherwise: target main
+
+Each target has its own queue; merge_queue.targets in the rig's config sets
+per-target policies (review, tests, merge method, freeze windows), keyed by
+branch name or pattern:
+
+  "targets": {"release-*": {"require_review": true, "pr_merge_method": "merge"}}
 
 This ensures batch work on epics automatically flows to integration branches.
 
@@ -98,12 +118,26 @@ Polecat auto-cleanup:
   Use --no-cleanup to disable this behavior (e.g., if you want to submit
   multiple MRs or continue working).
 
+Dependencies:
+  --depends-on names MRs or issues this MR must land after. The refinery
+  also orders MRs by their source issues' blocking dependencies. It merges
+  parents first and holds a child until its parents land; 'gt mq order'
+  shows what each MR waits on.
+
+Waiting:
+  --wait blocks until the refinery merges the MR or it fails (a merge
+  conflict, failing tests, or closed without merging), for scripts that
+  act on the result. Exit codes: 0 merged, 2 timed out, 3 failed.
+
 Examples:
   gt mq submit                           # Auto-detect everything + auto-cleanup
   gt mq submit --issue gp-abc            # Explicit issue
   gt mq submit --epic gt-xyz             # Target integration branch explicitly
+  gt mq submit --target release-1.2      # Target a release branch
   gt mq submit --priority 0              # Override priority (P0)
-  gt mq submit --no-cleanup              # Submit without auto-cleanup`,
+  gt mq submit --no-cleanup              # Submit without auto-cleanup
+  gt mq submit --wait --timeout 1h       # Block until merged or failed
+  gt mq submit --depends-on gt-mr-abc    # Land after another MR`,
 	RunE: runMqSubmit,
 }
 
@@ -140,7 +174,31 @@ Examples:
   gt mq list greenplace
   gt mq list greenplace --ready
   gt mq list greenplace --status=open
-  gt mq list greenplace --worker=Nux`,
+  gt mq list greenplace --worker=Nux
+  gt mq list greenplace --target=release-1.2
+  gt mq list greenplace --slo
+  gt mq list greenplace --conflicts
+  gt mq list greenplace --porcelain
+  gt mq list greenplace --status=closed --limit 50 --offset 50
+
+--limit and --offset page through the list. The queue is ordered over
+every open MR, so open MRs are paged after ordering. Closed MRs pile up
+without bound and aren't queued: with --status=closed or all and no
+other filter, bd returns only the MRs up to the page, in its own order.
+
+--target shows one target branch's queue: MRs into release or integration
+branches queue separately from the default branch, under that target's
+freeze windows and policies.
+
+--slo shows time in queue state instead: how long each open MR has been
+awaiting tests, review, or merge against the rig's merge_queue.slo
+thresholds, worst offenders first. The daemon tracks state changes and
+emits a queue_slo_breached event when an MR overstays its SLO.
+
+--conflicts predicts conflicts instead: it compares the files changed by
+open MRs and by polecat branches not yet submitted, and lists each pair
+into the same target that touches the same files, with which should land
+first. Sequencing overlapping work early saves rebasing it late.`,
 	Args: cobra.ExactArgs(1),
 	RunE: runMQList,
 }
@@ -276,8 +334,14 @@ func init() {
 	mqSubmitCmd.Flags().StringVar(&mqSubmitBranch, "branch", "", "Source branch (default: current branch)")
 	mqSubmitCmd.Flags().StringVar(&mqSubmitIssue, "issue", "", "Source issue ID (default: parse from branch name)")
 	mqSubmitCmd.Flags().StringVar(&mqSubmitEpic, "epic", "", "Target epic's integration branch instead of main")
+	mqSubmitCmd.Flags().StringVar(&mqSubmitTarget, "target", "", "Target branch instead of main (e.g. a release branch)")
+	mqSubmitCmd.MarkFlagsMutuallyExclusive("target", "epic")
 	mqSubmitCmd.Flags().IntVarP(&mqSubmitPriority, "priority", "p", -1, "Override priority (0-4, default: inherit from issue)")
 	mqSubmitCmd.Flags().BoolVar(&mqSubmitNoCleanup, "no-cleanup", false, "Don't auto-cleanup after submit (for polecats)")
+	mqSubmitCmd.Flags().BoolVar(&mqSubmitHotfix, "hotfix", false, "Label the MR hotfix so it merges through a queue freeze")
+	mqSubmitCmd.Flags().BoolVar(&mqSubmitWait, "wait", false, "Wait until the MR is merged or fails")
+	mqSubmitCmd.Flags().DurationVar(&mqSubmitTimeout, "timeout", 2*time.Hour, "With --wait, give up after this long (0 = never)")
+	mqSubmitCmd.Flags().StringSliceVar(&mqSubmitDependsOn, "depends-on", nil, "MRs or issues this MR must land after (repeatable)")
 
 	// Retry flags
 	mqRetryCmd.Flags().BoolVar(&mqRetryNow, "now", false, "Immediately process instead of waiting for refinery loop")
@@ -287,7 +351,14 @@ func init() {
 	mqListCmd.Flags().StringVar(&mqListStatus, "status", "", "Filter by status (open, in_progress, closed)")
 	mqListCmd.Flags().StringVar(&mqListWorker, "worker", "", "Filter by worker name")
 	mqListCmd.Flags().StringVar(&mqListEpic, "epic", "", "Show MRs targeting integration/<epic>")
+	mqListCmd.Flags().StringVar(&mqListTarget, "target", "", "Show the queue for one target branch (e.g. release-1.2)")
+	mqListCmd.MarkFlagsMutuallyExclusive("target", "epic")
 	mqListCmd.Flags().BoolVar(&mqListJSON, "json", false, "Output as JSON")
+	mqListCmd.Flags().BoolVar(&mqListSLO, "slo", false, "Show time in queue state against the rig's SLOs, worst first")
+	mqListCmd.Flags().BoolVar(&mqListConflicts, "conflicts", false, "Show in-flight MRs and agent branches that touch the same files")
+	mqListCmd.Flags().IntVar(&mqListLimit, "limit", 0, "Show at most this many MRs (0 for all)")
+	mqListCmd.Flags().IntVar(&mqListOffset, "offset", 0, "Skip this many MRs first, for the next page")
+	addPorcelainFlag(mqListCmd, &mqListPorcelain)
 
 	// Reject flags
 	mqRejectCmd.Flags().StringVarP(&mqRejectReason, "reason", "r", "", "Reason for rejection (required unless --stdin)")
@@ -441,6 +512,7 @@ func runMQReject(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("rejecting MR: %w", err)
 	}
+	publishMRStatus(rigName, result.ID, "", ci.Failure, "Rejected: "+mqRejectReason)
 
 	fmt.Printf("%s Rejected: %s\n", style.Bold.Render("✗"), result.Branch)
 	fmt.Printf("  Worker: %s\n", result.Worker)
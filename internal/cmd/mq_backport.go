@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// MQ backport command flags
+var (
+	mqBackportDryRun bool
+	mqBackportJSON   bool
+)
+
+var mqBackportCmd = &cobra.Command{
+	Use:   "backport <rig> <mr-id> <target>...",
+	Short: "Backport a merged merge request to release branches",
+	Long: `Backport a merged MR to one or more target branches through the queue.
+
+For each target:
+  1. Cherry-picks the MR's merge commit onto backport/<target>/<mr-id>,
+     cut from the target, and pushes it. A pick that only conflicts on
+     whitespace is picked again ignoring whitespace.
+  2. Files a backport bead (labeled backport) linked to the original
+     issue and MR
+  3. Submits the branch to the target's queue, under the target's
+     policies (see merge_queue.targets)
+
+A pick with real conflicts is not pushed: the backport bead records the
+conflicting files so the manual backport isn't forgotten. Targets that
+already contain the change are skipped. The source issue lists its
+backports.
+
+Examples:
+  gt mq backport gastown gt-mr-abc release-1.2
+  gt mq backport gastown gt-mr-abc release-1.1 release-1.2
+  gt mq backport gastown gt-mr-abc release-1.2 --dry-run`,
+	Args: cobra.MinimumNArgs(3),
+	RunE: runMQBackport,
+}
+
+func init() {
+	mqBackportCmd.Flags().BoolVarP(&mqBackportDryRun, "dry-run", "n", false, "Show what would be backported without changing anything")
+	mqBackportCmd.Flags().BoolVar(&mqBackportJSON, "json", false, "Output as JSON")
+
+	mqCmd.AddCommand(mqBackportCmd)
+}
+
+func runMQBackport(cmd *cobra.Command, args []string) error {
+	mrID, targets := args[1], args[2:]
+
+	mgr, _, rigName, err := getRefineryManager(args[0])
+	if err != nil {
+		return err
+	}
+
+	actor := detectSender()
+	results, err := mgr.BackportMR(mrID, targets, refinery.BackportOptions{Actor: actor, DryRun: mqBackportDryRun})
+	if err != nil && len(results) == 0 {
+		return err
+	}
+
+	var submitted []string
+	for _, r := range results {
+		if r.BackportMR != "" {
+			submitted = append(submitted, r.Target)
+			nudgeRefinery(rigName, fmt.Sprintf("Backport submitted: %s branch=%s", r.BackportMR, r.Branch))
+		}
+	}
+	if len(submitted) > 0 {
+		_ = events.LogFeed(events.TypeMRBackported, actor, map[string]interface{}{
+			"rig":     rigName,
+			"mr":      mrID,
+			"targets": strings.Join(submitted, ", "),
+		})
+	}
+
+	if mqBackportJSON {
+		if jerr := outputJSON(results); jerr != nil {
+			return jerr
+		}
+		return err
+	}
+
+	for _, r := range results {
+		switch {
+		case r.Outcome == refinery.BackportPresent:
+			fmt.Printf("%s %s is already on %s\n", style.Dim.Render("○"), r.MR, r.Target)
+		case r.DryRun:
+			fmt.Printf("%s Would backport %s (commit %s) to %s\n", style.Bold.Render("○"), r.MR, shortSHA(r.Commit), r.Target)
+			fmt.Printf("  Branch: %s\n", r.Branch)
+		case r.Outcome == refinery.BackportConflict:
+			fmt.Printf("%s %s conflicts on %s; needs a manual backport\n", style.Warning.Render("⚠"), r.MR, r.Target)
+			fmt.Printf("  Backport bead: %s\n", style.Bold.Render(r.BackportBead))
+			fmt.Printf("  Conflicts:     %s\n", strings.Join(r.Conflicts, ", "))
+		default:
+			fmt.Printf("%s Backporting %s to %s\n", style.Bold.Render("✓"), r.MR, r.Target)
+			fmt.Printf("  Backport bead: %s\n", style.Bold.Render(r.BackportBead))
+			fmt.Printf("  Backport MR:   %s\n", style.Bold.Render(r.BackportMR))
+			fmt.Printf("  Branch:        %s → %s\n", r.Branch, r.Target)
+			if r.Resolved != "" {
+				fmt.Printf("  %s\n", style.Dim.Render("Resolved: "+r.Resolved))
+			}
+		}
+	}
+	return err
+}
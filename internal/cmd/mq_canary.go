@@ -0,0 +1,239 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// MQ canary command flags
+var (
+	mqCanaryCommit        string
+	mqCanaryReason        string
+	mqCanaryJSON          bool
+	mqCanaryWebhookPort   int
+	mqCanaryWebhookSecret string
+)
+
+var mqCanaryCmd = &cobra.Command{
+	Use:   "canary",
+	Short: "Soak merge requests on a canary branch before promotion",
+	Long: `Canary merge mode merges each MR to a canary branch first and promotes
+it to the target branch only after verification passes.
+
+Enable it in the rig's config.json:
+
+  "merge_queue": {
+    "canary_branch": "canary",
+    "canary_verify_command": "./scripts/smoke-test.sh",
+    "canary_timeout": "30m"
+  }
+
+The verify command runs in the refinery worktree with GT_CANARY_BRANCH,
+GT_CANARY_COMMIT, and GT_MR_BRANCH set; exit 0 promotes. Without a verify
+command the refinery waits for a verdict from 'gt mq canary pass|fail' or
+the canary webhook. A failed or timed-out canary resets the canary branch
+to the target, and the MR goes back to its worker.`,
+	RunE: requireSubcommand,
+}
+
+var mqCanaryRunCmd = &cobra.Command{
+	Use:   "run <rig> <mr-id>",
+	Short: "Merge an MR to the canary branch and wait for a verdict",
+	Long: `Merge an MR to the rig's canary branch, push it, and wait for
+verification. Exits non-zero if the canary fails or times out, after
+reverting the canary branch. Promote the MR only if this succeeds.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runMQCanaryRun,
+}
+
+var mqCanaryStatusCmd = &cobra.Command{
+	Use:   "status <rig>",
+	Short: "Show the canary currently soaking",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runMQCanaryStatus,
+}
+
+var mqCanaryPassCmd = &cobra.Command{
+	Use:   "pass <rig>",
+	Short: "Promote the soaking canary",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return signalMQCanary(args[0], true)
+	},
+}
+
+var mqCanaryFailCmd = &cobra.Command{
+	Use:   "fail <rig>",
+	Short: "Fail the soaking canary and revert it",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return signalMQCanary(args[0], false)
+	},
+}
+
+var mqCanaryWebhookCmd = &cobra.Command{
+	Use:   "webhook",
+	Short: "Serve an HTTP endpoint for canary verdicts",
+	Long: `Accept canary verdicts from monitoring or CI:
+
+  curl -X POST -H "Authorization: Bearer $GT_CANARY_SECRET" \
+    -d '{"rig": "gastown", "passed": false, "reason": "error rate 4%"}' \
+    http://localhost:8791/
+
+A missing commit applies to the rig's soaking canary.`,
+	Args: cobra.NoArgs,
+	RunE: runMQCanaryWebhook,
+}
+
+func init() {
+	for _, c := range []*cobra.Command{mqCanaryPassCmd, mqCanaryFailCmd} {
+		c.Flags().StringVar(&mqCanaryCommit, "commit", "", "Canary commit (default: the soaking canary)")
+		c.Flags().StringVar(&mqCanaryReason, "reason", "", "Why")
+	}
+	mqCanaryStatusCmd.Flags().BoolVar(&mqCanaryJSON, "json", false, "Output as JSON")
+	mqCanaryWebhookCmd.Flags().IntVar(&mqCanaryWebhookPort, "port", 8791, "Port to listen on")
+	mqCanaryWebhookCmd.Flags().StringVar(&mqCanaryWebhookSecret, "secret", "", "Shared secret (default: $GT_CANARY_SECRET)")
+
+	mqCanaryCmd.AddCommand(mqCanaryRunCmd)
+	mqCanaryCmd.AddCommand(mqCanaryStatusCmd)
+	mqCanaryCmd.AddCommand(mqCanaryPassCmd)
+	mqCanaryCmd.AddCommand(mqCanaryFailCmd)
+	mqCanaryCmd.AddCommand(mqCanaryWebhookCmd)
+	mqCmd.AddCommand(mqCanaryCmd)
+}
+
+func runMQCanaryRun(cmd *cobra.Command, args []string) error {
+	rigName, mrID := args[0], args[1]
+
+	_, r, _, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+	eng := refinery.NewEngineer(r)
+	if err := eng.LoadConfig(); err != nil {
+		return fmt.Errorf("loading merge queue config: %w", err)
+	}
+	if eng.Config().CanaryBranch == "" {
+		return fmt.Errorf("canary mode is not enabled for %s (set merge_queue.canary_branch)", rigName)
+	}
+
+	issue, err := beads.New(r.BeadsPath()).Show(mrID)
+	if err != nil {
+		return fmt.Errorf("fetching merge request %s: %w", mrID, err)
+	}
+	fields := beads.ParseMRFields(issue)
+	if fields == nil || fields.Branch == "" {
+		return fmt.Errorf("%s has no branch field; is it a merge request?", mrID)
+	}
+	target := fields.Target
+	if target == "" {
+		target = eng.Config().TargetBranch
+	}
+
+	result := eng.RunCanary(context.Background(), mrID, fields.Branch, target)
+	if !result.Success {
+		return fmt.Errorf("%s", result.Error)
+	}
+	fmt.Printf("%s Canary passed; promote %s to %s\n", style.Bold.Render("✓"), mrID, target)
+	return nil
+}
+
+func runMQCanaryStatus(cmd *cobra.Command, args []string) error {
+	_, r, _, err := getRefineryManager(args[0])
+	if err != nil {
+		return err
+	}
+	canary, err := refinery.LoadCanary(r.Path)
+	if err != nil {
+		return err
+	}
+	if mqCanaryJSON {
+		return outputJSON(canary)
+	}
+	if canary == nil {
+		fmt.Printf("%s No canary soaking on '%s'\n", style.Dim.Render("○"), r.Name)
+		return nil
+	}
+	fmt.Printf("%s Canary soaking on '%s'\n", style.Bold.Render("🧪"), r.Name)
+	if canary.MR != "" {
+		fmt.Printf("  MR:       %s\n", canary.MR)
+	}
+	fmt.Printf("  Branch:   %s → %s\n", canary.Branch, canary.Canary)
+	fmt.Printf("  Commit:   %s\n", shortSHA(canary.Commit))
+	fmt.Printf("  Started:  %s\n", formatTimeAgo(canary.StartedAt.Format(time.RFC3339)))
+	fmt.Printf("  Deadline: %s\n", canary.Deadline.Local().Format("15:04:05"))
+	return nil
+}
+
+func signalMQCanary(rigName string, passed bool) error {
+	_, r, _, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+	commit := mqCanaryCommit
+	if commit == "" {
+		canary, err := refinery.LoadCanary(r.Path)
+		if err != nil {
+			return err
+		}
+		if canary == nil {
+			return fmt.Errorf("no canary soaking on %s; pass --commit", rigName)
+		}
+		commit = canary.Commit
+	}
+
+	sig := &refinery.CanarySignal{Commit: commit, Passed: passed, Reason: mqCanaryReason, By: detectSender()}
+	if err := refinery.SignalCanary(r.Path, sig); err != nil {
+		return err
+	}
+	if passed {
+		fmt.Printf("%s Canary %s passed; the refinery will promote it\n", style.Bold.Render("✓"), shortSHA(commit))
+	} else {
+		fmt.Printf("%s Canary %s failed; the refinery will revert it\n", style.Bold.Render("✗"), shortSHA(commit))
+	}
+	return nil
+}
+
+func runMQCanaryWebhook(cmd *cobra.Command, args []string) error {
+	if _, err := workspace.FindFromCwdOrError(); err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	secret := mqCanaryWebhookSecret
+	if secret == "" {
+		secret = os.Getenv("GT_CANARY_SECRET")
+	}
+	if secret == "" {
+		fmt.Printf("%s No secret set; any client that can reach the port can pass or fail canaries\n", style.Warning.Render("⚠"))
+	}
+
+	handler := &refinery.CanaryWebhook{
+		Secret: secret,
+		RigPath: func(rigName string) (string, error) {
+			_, r, err := getRig(rigName)
+			if err != nil {
+				return "", err
+			}
+			return r.Path, nil
+		},
+	}
+
+	fmt.Printf("Listening for canary verdicts on :%d\n", mqCanaryWebhookPort)
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%d", mqCanaryWebhookPort),
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      60 * time.Second,
+		IdleTimeout:       120 * time.Second,
+	}
+	return server.ListenAndServe()
+}
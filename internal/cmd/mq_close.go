@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/ci"
 	"github.com/steveyegge/gastown/internal/style"
 )
 
@@ -41,6 +42,11 @@ func runMQClose(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("closing MR: %w", err)
 	}
+	if mqCloseReason == "merged" {
+		publishMRStatus(rigName, result.ID, "", ci.Success, "Merged by the refinery")
+	} else {
+		publishMRStatus(rigName, result.ID, "", ci.Failure, "Closed: "+mqCloseReason)
+	}
 
 	fmt.Printf("%s Closed: %s\n", style.Bold.Render("✓"), result.ID)
 	fmt.Printf("  Branch: %s\n", result.Branch)
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/timefmt"
+)
+
+// MQ comment command flags
+var (
+	mqCommentMessage string
+	mqCommentFile    string
+	mqCommentLine    int
+	mqCommentReply   string
+	mqCommentAuthor  string
+	mqCommentJSON    bool
+
+	mqCommentsJSON bool
+)
+
+var mqCommentCmd = &cobra.Command{
+	Use:   "comment <rig> <mr-id>",
+	Short: "Comment on a merge request",
+	Long: `Add a comment to an MR's discussion.
+
+Comments are stored on the MR bead, so review feedback outlives the chat
+it came from. Anchor a comment to a file, or a line in it, with --file and
+--line. Reply to an existing thread with --reply <comment-id>; replies
+share the thread's anchor.
+
+The discussion is shown by 'gt mq comments', 'gt mq status', and
+'gt mq next-review'. If the rig sets merge_queue.forge_comment_command,
+each new comment is also mirrored to the forge through it.
+
+Examples:
+  gt mq comment gastown gt-mr-abc -m "Looks close; see auth.go"
+  gt mq comment gastown gt-mr-abc --file auth.go --line 42 -m "Token is never checked"
+  gt mq comment gastown gt-mr-abc --reply c2 -m "Fixed in 3f2a1c"`,
+	Args: cobra.ExactArgs(2),
+	RunE: runMQComment,
+}
+
+var mqCommentsCmd = &cobra.Command{
+	Use:   "comments <rig> <mr-id>",
+	Short: "Show a merge request's discussion",
+	Long: `Show the comment threads on an MR, oldest first, with replies
+indented under the comment they answer.
+
+Examples:
+  gt mq comments gastown gt-mr-abc
+  gt mq comments gastown gt-mr-abc --json`,
+	Args: cobra.ExactArgs(2),
+	RunE: runMQComments,
+}
+
+func init() {
+	mqCommentCmd.Flags().StringVarP(&mqCommentMessage, "message", "m", "", "Comment text (required)")
+	mqCommentCmd.Flags().StringVar(&mqCommentFile, "file", "", "Anchor the comment to this file")
+	mqCommentCmd.Flags().IntVar(&mqCommentLine, "line", 0, "Anchor the comment to this line of --file")
+	mqCommentCmd.Flags().StringVar(&mqCommentReply, "reply", "", "Reply to this comment ID")
+	mqCommentCmd.Flags().StringVar(&mqCommentAuthor, "author", "", "Comment author (default: detected)")
+	mqCommentCmd.Flags().BoolVar(&mqCommentJSON, "json", false, "Output the new comment as JSON")
+	_ = mqCommentCmd.MarkFlagRequired("message")
+
+	mqCommentsCmd.Flags().BoolVar(&mqCommentsJSON, "json", false, "Output threads as JSON")
+
+	mqCmd.AddCommand(mqCommentCmd)
+	mqCmd.AddCommand(mqCommentsCmd)
+}
+
+func runMQComment(cmd *cobra.Command, args []string) error {
+	rigName, mrID := args[0], args[1]
+	eng, err := loadRigEngineer(rigName)
+	if err != nil {
+		return err
+	}
+	eng.SetOutput(os.Stderr)
+
+	author := mqCommentAuthor
+	if author == "" {
+		author = detectSender()
+	}
+	c, err := eng.AddComment(mrID, refinery.MRComment{
+		ReplyTo: mqCommentReply,
+		Author:  author,
+		Path:    mqCommentFile,
+		Line:    mqCommentLine,
+		Body:    mqCommentMessage,
+	})
+	if err != nil {
+		return err
+	}
+	_ = events.LogFeed(events.TypeMRCommented, author, map[string]interface{}{
+		"rig":      rigName,
+		"mr":       mrID,
+		"comment":  c.ID,
+		"reply_to": c.ReplyTo,
+	})
+
+	if mqCommentJSON {
+		return outputJSON(c)
+	}
+	what := "Commented on " + mrID
+	if c.ReplyTo != "" {
+		what = fmt.Sprintf("Replied to %s on %s", c.ReplyTo, mrID)
+	}
+	fmt.Printf("%s %s %s\n", style.Success.Render("✓"), what, style.Dim.Render("("+c.ID+")"))
+	return nil
+}
+
+func runMQComments(cmd *cobra.Command, args []string) error {
+	rigName, mrID := args[0], args[1]
+	_, r, _, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+	issue, err := beads.New(r.Path).Show(mrID)
+	if err != nil {
+		return fmt.Errorf("fetching merge request %s: %w", mrID, err)
+	}
+
+	threads := refinery.CommentThreads(refinery.ParseMRComments(issue.Description))
+	if mqCommentsJSON {
+		if threads == nil {
+			threads = []refinery.CommentThread{}
+		}
+		return outputJSON(threads)
+	}
+	if len(threads) == 0 {
+		fmt.Printf("%s No comments on %s\n", style.Dim.Render("○"), mrID)
+		return nil
+	}
+	fmt.Printf("%s %s\n\n", style.Bold.Render(mrID), issue.Title)
+	printCommentThreads(threads, "")
+	return nil
+}
+
+// printCommentThreads prints discussion threads, replies indented under
+// their thread and a blank line between threads.
+func printCommentThreads(threads []refinery.CommentThread, indent string) {
+	for i, t := range threads {
+		if i > 0 {
+			fmt.Println()
+		}
+		printComment(t.MRComment, indent, true)
+		for _, reply := range t.Replies {
+			printComment(reply, indent+"  ", false)
+		}
+	}
+}
+
+// printComment prints one comment: a header line with ID, author, age, and
+// (for thread starters) the anchor, then the indented body.
+func printComment(c refinery.MRComment, indent string, showAnchor bool) {
+	header := fmt.Sprintf("%s %s", style.Dim.Render(c.ID), style.Bold.Render(c.Author))
+	if showAnchor && c.Location() != "" {
+		header += " on " + style.Info.Render(c.Location())
+	}
+	header += " " + style.Dim.Render(timefmt.Ago(c.At))
+	fmt.Printf("%s%s\n", indent, header)
+	for _, line := range strings.Split(c.Body, "\n") {
+		fmt.Printf("%s  %s\n", indent, line)
+	}
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/ui"
+)
+
+// MQ diff command flags
+var (
+	mqDiffNameOnly bool
+	mqDiffStat     bool
+	mqDiffMerged   bool
+	mqDiffNoPager  bool
+	mqDiffJSON     bool
+)
+
+var mqDiffCmd = &cobra.Command{
+	Use:   "diff <rig> <mr-id>",
+	Short: "Show a merge request's diff against its target",
+	Long: `Show what a merge request changes relative to its target branch.
+
+Origin is fetched first, so the diff is against the current target. By
+default it is the branch's changes since it diverged from the target, as a
+forge would show them. With --merged, the branch is trial-merged into the
+target in a temporary worktree and the diff is exactly what merging would
+land; conflicting files are listed instead if the merge would fail.
+
+Output is a stat summary and file list followed by the colored patch,
+paged when stdout is a terminal.
+
+Examples:
+  gt mq diff gastown gt-mr-abc
+  gt mq diff gastown gt-mr-abc --stat
+  gt mq diff gastown gt-mr-abc --name-only
+  gt mq diff gastown gt-mr-abc --merged
+  gt mq diff gastown gt-mr-abc --json`,
+	Args: cobra.ExactArgs(2),
+	RunE: runMQDiff,
+}
+
+func init() {
+	mqDiffCmd.Flags().BoolVar(&mqDiffNameOnly, "name-only", false, "Only list changed file paths")
+	mqDiffCmd.Flags().BoolVar(&mqDiffStat, "stat", false, "Only show the stat summary and file list")
+	mqDiffCmd.Flags().BoolVar(&mqDiffMerged, "merged", false, "Diff a trial merge into the current target")
+	mqDiffCmd.Flags().BoolVar(&mqDiffNoPager, "no-pager", false, "Don't page the output")
+	mqDiffCmd.Flags().BoolVar(&mqDiffJSON, "json", false, "Output the stat and file list as JSON")
+
+	mqCmd.AddCommand(mqDiffCmd)
+}
+
+func runMQDiff(cmd *cobra.Command, args []string) error {
+	rigName, mrID := args[0], args[1]
+
+	_, r, _, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+
+	eng := refinery.NewEngineer(r)
+	if err := eng.LoadConfig(); err != nil {
+		return fmt.Errorf("loading merge queue config: %w", err)
+	}
+	eng.SetOutput(os.Stderr)
+
+	issue, err := beads.New(r.Path).Show(mrID)
+	if err != nil {
+		return fmt.Errorf("fetching merge request %s: %w", mrID, err)
+	}
+
+	d, err := eng.DiffMR(issue, mqDiffMerged)
+	if err != nil {
+		return err
+	}
+
+	if mqDiffJSON {
+		return outputJSON(d)
+	}
+	if mqDiffNameOnly {
+		for _, f := range d.Files {
+			fmt.Println(f.Path)
+		}
+		return nil
+	}
+
+	var b strings.Builder
+	writeMRDiffSummary(&b, d)
+	if !mqDiffStat && d.Patch != "" {
+		b.WriteString("\n")
+		b.WriteString(colorizePatch(d.Patch))
+	}
+	if len(d.Conflicts) > 0 {
+		fmt.Print(b.String())
+		return NewSilentExit(1)
+	}
+	return ui.ToPager(b.String(), ui.PagerOptions{NoPager: mqDiffNoPager})
+}
+
+// writeMRDiffSummary writes the header, stat line, and file list.
+func writeMRDiffSummary(b *strings.Builder, d *refinery.MRDiff) {
+	mode := "diff"
+	if d.Merged {
+		mode = "trial merge"
+	}
+	fmt.Fprintf(b, "%s %s → %s %s\n", style.Bold.Render(d.MR), d.Branch, d.Target, style.Dim.Render("("+mode+")"))
+
+	if len(d.Conflicts) > 0 {
+		fmt.Fprintf(b, "%s Merge into %s conflicts in %d file(s):\n", style.ErrorPrefix, d.Target, len(d.Conflicts))
+		for _, path := range d.Conflicts {
+			fmt.Fprintf(b, "  %s\n", path)
+		}
+		return
+	}
+	if len(d.Files) == 0 {
+		fmt.Fprintf(b, "%s\n", style.Dim.Render("No changes"))
+		return
+	}
+
+	fmt.Fprintf(b, "%s\n", formatMRSize(d.Stat.Files, d.Stat.Added, d.Stat.Deleted))
+	for _, f := range d.Files {
+		fmt.Fprintf(b, "  %s %s\n", formatFileStatus(f.Status), formatFilePath(f))
+	}
+}
+
+// formatFileStatus colors a single-letter change status.
+func formatFileStatus(status string) string {
+	switch status {
+	case "A":
+		return style.Success.Render(status)
+	case "D":
+		return style.Error.Render(status)
+	case "R", "C":
+		return style.Info.Render(status)
+	}
+	return style.Warning.Render(status)
+}
+
+// formatFilePath renders a changed path, showing the old path of renames.
+func formatFilePath(f git.FileChange) string {
+	if f.OldPath != "" {
+		return f.OldPath + " → " + f.Path
+	}
+	return f.Path
+}
+
+// colorizePatch colors a unified diff the way git does: headers bold,
+// hunk markers blue, additions green, deletions red.
+func colorizePatch(patch string) string {
+	lines := strings.SplitAfter(patch, "\n")
+	var b strings.Builder
+	for _, line := range lines {
+		text := strings.TrimSuffix(line, "\n")
+		nl := line[len(text):]
+		switch {
+		case text == "":
+		case strings.HasPrefix(text, "diff --git"), strings.HasPrefix(text, "+++ "), strings.HasPrefix(text, "--- "):
+			text = style.Bold.Render(text)
+		case strings.HasPrefix(text, "@@"):
+			text = style.Info.Render(text)
+		case strings.HasPrefix(text, "+"):
+			text = style.Success.Render(text)
+		case strings.HasPrefix(text, "-"):
+			text = style.Error.Render(text)
+		}
+		b.WriteString(text + nl)
+	}
+	return b.String()
+}
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/mq"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// MQ freeze command flags
+var (
+	mqFreezeUntil  string
+	mqFreezeReason string
+	mqFreezeJSON   bool
+	mqUnfreezeJSON bool
+)
+
+var mqFreezeCmd = &cobra.Command{
+	Use:   "freeze <rig>",
+	Short: "Freeze a rig's merge queue",
+	Long: `Stop the refinery from merging into a rig until the queue is unfrozen.
+
+While frozen, only hotfix MRs (submitted with 'gt mq submit --hotfix' or
+whose source issue is labeled hotfix) are processed. Other MRs stay queued
+and 'gt mq submit' warns that they won't merge until the freeze lifts.
+
+--until accepts a duration (2h, 3d), a date (2026-01-05), a local time
+(2026-01-05 08:00), or an RFC 3339 timestamp. Without --until the queue
+stays frozen until 'gt mq unfreeze'.
+
+Recurring freezes are configured in the rig's config.json:
+
+  "merge_queue": {"freeze_windows": ["Fri 18:00-Mon 08:00", "22:00-06:00"]}
+
+Examples:
+  gt mq freeze gastown --reason "release 1.4 cut"
+  gt mq freeze gastown --until 2h
+  gt mq freeze gastown --until "2026-01-05 08:00"`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMQFreeze,
+}
+
+var mqUnfreezeCmd = &cobra.Command{
+	Use:   "unfreeze <rig>",
+	Short: "Lift a manual merge queue freeze",
+	Long: `Lift a freeze set with 'gt mq freeze'.
+
+Scheduled freeze windows and incidents opened with --freeze-queue keep
+freezing the queue; unfreeze reports them if any are still active.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMQUnfreeze,
+}
+
+func init() {
+	mqFreezeCmd.Flags().StringVar(&mqFreezeUntil, "until", "", "When the freeze lifts (duration, date, or timestamp)")
+	mqFreezeCmd.Flags().StringVar(&mqFreezeReason, "reason", "", "Why the queue is frozen")
+	mqFreezeCmd.Flags().BoolVar(&mqFreezeJSON, "json", false, "Output as JSON")
+	mqUnfreezeCmd.Flags().BoolVar(&mqUnfreezeJSON, "json", false, "Output as JSON")
+
+	mqCmd.AddCommand(mqFreezeCmd)
+	mqCmd.AddCommand(mqUnfreezeCmd)
+}
+
+func runMQFreeze(cmd *cobra.Command, args []string) error {
+	_, r, _, err := getRefineryManager(args[0])
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	freeze := &mq.Freeze{
+		Reason:   mqFreezeReason,
+		FrozenBy: detectSender(),
+		FrozenAt: now.UTC(),
+	}
+	if mqFreezeUntil != "" {
+		until, err := parseFreezeUntil(mqFreezeUntil, now)
+		if err != nil {
+			return err
+		}
+		if !until.After(now) {
+			return fmt.Errorf("--until %s is in the past", mqFreezeUntil)
+		}
+		freeze.Until = until.UTC()
+	}
+
+	if err := mq.SaveFreeze(r.Path, freeze); err != nil {
+		return err
+	}
+
+	state, err := rigQueueFreeze(r)
+	if err != nil {
+		return err
+	}
+	if mqFreezeJSON {
+		return outputJSON(state)
+	}
+
+	fmt.Printf("%s Merge queue for '%s' frozen\n", style.Bold.Render("❄"), r.Name)
+	fmt.Printf("  %s\n", state.Reason())
+	fmt.Printf("  %s\n", style.Dim.Render("Only hotfix MRs will merge. Lift with: gt mq unfreeze "+r.Name))
+	return nil
+}
+
+func runMQUnfreeze(cmd *cobra.Command, args []string) error {
+	_, r, _, err := getRefineryManager(args[0])
+	if err != nil {
+		return err
+	}
+
+	if err := mq.ClearFreeze(r.Path); err != nil {
+		return err
+	}
+
+	state, err := rigQueueFreeze(r)
+	if err != nil {
+		return err
+	}
+	if mqUnfreezeJSON {
+		return outputJSON(state)
+	}
+
+	if state.Frozen() {
+		fmt.Printf("%s Manual freeze lifted, but the queue for '%s' is still frozen\n", style.Bold.Render("⚠"), r.Name)
+		fmt.Printf("  %s\n", state.Reason())
+		return nil
+	}
+	fmt.Printf("%s Merge queue for '%s' unfrozen\n", style.Bold.Render("✓"), r.Name)
+	return nil
+}
+
+// rigQueueFreeze returns the rig's current queue freeze from the refinery's
+// point of view: manual freeze, configured windows, and incidents.
+func rigQueueFreeze(r *rig.Rig) (*refinery.QueueFreeze, error) {
+	eng := refinery.NewEngineer(r)
+	if err := eng.LoadConfig(); err != nil {
+		return nil, fmt.Errorf("loading merge queue config: %w", err)
+	}
+	return eng.QueueFreeze()
+}
+
+// rigTargetFreeze returns the queue freeze for MRs into target, including
+// the target's own freeze windows; an empty target is the whole rig's.
+func rigTargetFreeze(r *rig.Rig, target string) (*refinery.QueueFreeze, error) {
+	if target == "" {
+		return rigQueueFreeze(r)
+	}
+	eng := refinery.NewEngineer(r)
+	if err := eng.LoadConfig(); err != nil {
+		return nil, fmt.Errorf("loading merge queue config: %w", err)
+	}
+	return eng.TargetFreeze(target)
+}
+
+// parseFreezeUntil parses --until as a duration from now, an RFC 3339
+// timestamp, a local "2006-01-02 15:04" time, or a local date.
+func parseFreezeUntil(s string, now time.Time) (time.Time, error) {
+	if d, err := parseDuration(s); err == nil {
+		return now.Add(d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid --until %q: want a duration (2h, 3d), date, or timestamp", s)
+}
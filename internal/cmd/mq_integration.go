@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
@@ -731,9 +730,7 @@ func runMqIntegrationStatus(cmd *cobra.Command, args []string) error {
 
 	// JSON output
 	if mqIntegrationStatusJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(output)
+		return outputJSON(output)
 	}
 
 	// Human-readable output
@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/sbom"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// MQ license command flags
+var (
+	mqLicenseBaseline string
+	mqLicenseNoRecord bool
+	mqLicenseJSON     bool
+)
+
+var mqLicenseCmd = &cobra.Command{
+	Use:   "license <rig> [mr-id]",
+	Short: "Check the licenses of the dependencies a merge request adds",
+	Long: `Scan an MR's dependencies and check their licenses against the rig's policy.
+
+The rig's merge_queue.license_scan command is run in the refinery clone,
+which should have the MR's branch checked out, and must print a CycloneDX
+or SPDX JSON SBOM. Components the target branch's recorded SBOM doesn't
+have, or has under a different license, are checked against the policy:
+
+  "merge_queue": {
+    "license_scan": {
+      "command": "syft scan dir:. -o cyclonedx-json -q",
+      "allow": ["MIT", "Apache-2.0", "BSD-*", "ISC"],
+      "deny": ["AGPL-*", "SSPL-*"],
+      "unknown": "warn"
+    }
+  }
+
+A dependency is blocked if any license it lists is denied or, with an
+allow list, not allowed; SPDX expressions like "MIT OR GPL-3.0-only" pass
+when one alternative does. "unknown" says what to do with dependencies
+without license information: warn (default), block, or allow.
+
+The result is recorded on the MR bead as the license_scan field (passed,
+warned, or blocked) with the findings in a license-scan section. Exits 1
+when blocked. With license_scan configured, the refinery runs this gate
+itself before merging.
+
+With --baseline <branch> and no MR, the SBOM of the current checkout is
+recorded as that branch's baseline (run after merging to main).
+
+Examples:
+  gt mq license gastown gt-mr-abc
+  gt mq license gastown gt-mr-abc --no-record --json
+  gt mq license gastown --baseline main`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runMQLicense,
+}
+
+func init() {
+	mqLicenseCmd.Flags().StringVar(&mqLicenseBaseline, "baseline", "", "Record the SBOM of the current checkout as this branch's baseline")
+	mqLicenseCmd.Flags().BoolVar(&mqLicenseNoRecord, "no-record", false, "Don't update the MR bead")
+	mqLicenseCmd.Flags().BoolVar(&mqLicenseJSON, "json", false, "Output as JSON")
+
+	mqCmd.AddCommand(mqLicenseCmd)
+}
+
+func runMQLicense(cmd *cobra.Command, args []string) error {
+	rigName := args[0]
+
+	if (len(args) == 2) == (mqLicenseBaseline != "") {
+		return fmt.Errorf("specify either an MR ID or --baseline <branch>")
+	}
+
+	_, r, _, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+
+	eng := refinery.NewEngineer(r)
+	if err := eng.LoadConfig(); err != nil {
+		return fmt.Errorf("loading merge queue config: %w", err)
+	}
+	if mqLicenseJSON {
+		eng.SetOutput(os.Stderr)
+	}
+
+	if mqLicenseBaseline != "" {
+		return licenseBaseline(eng, mqLicenseBaseline)
+	}
+
+	mrID := args[1]
+	issue, err := beads.New(r.Path).Show(mrID)
+	if err != nil {
+		return fmt.Errorf("fetching merge request %s: %w", mrID, err)
+	}
+	target := eng.Config().TargetBranch
+	if fields := beads.ParseMRFields(issue); fields != nil && fields.Target != "" {
+		target = fields.Target
+	}
+
+	report, err := eng.ScanLicenses(context.Background(), target)
+	if err != nil {
+		return err
+	}
+
+	if !mqLicenseNoRecord {
+		if err := eng.RecordLicenseScan(mrID, report); err != nil {
+			return err
+		}
+	}
+
+	if mqLicenseJSON {
+		if err := outputJSON(report); err != nil {
+			return err
+		}
+	} else {
+		printLicenseReport(mrID, report)
+	}
+	if report.Blocked {
+		return NewSilentExit(1)
+	}
+	return nil
+}
+
+// printLicenseReport prints the problem findings of a license scan.
+func printLicenseReport(mrID string, report *sbom.Report) {
+	icon := style.Success.Render("✓")
+	switch report.Status() {
+	case "blocked":
+		icon = style.Error.Render("✗")
+	case "warned":
+		icon = style.Warning.Render("⚠")
+	}
+	fmt.Printf("%s %s: license scan %s (%d components, %d checked)\n",
+		icon, mrID, report.Status(), report.Scanned, len(report.Findings))
+	if !report.Baseline {
+		fmt.Printf("  %s\n", style.Dim.Render("No target baseline; every dependency was checked"))
+	}
+	for _, f := range report.Findings {
+		if f.Verdict == sbom.VerdictAllowed {
+			continue
+		}
+		name := f.Name
+		if f.Version != "" {
+			name += "@" + f.Version
+		}
+		fmt.Printf("  %s %s: %s\n", f.Verdict, name, f.Reason)
+	}
+}
+
+// licenseBaseline records the SBOM of the current checkout for a branch.
+func licenseBaseline(eng *refinery.Engineer, branch string) error {
+	components, err := eng.ScanSBOM(context.Background())
+	if err != nil {
+		return err
+	}
+	if err := eng.SaveSBOMBaseline(branch, components); err != nil {
+		return fmt.Errorf("saving baseline: %w", err)
+	}
+
+	if mqLicenseJSON {
+		return outputJSON(map[string]interface{}{"branch": branch, "components": len(components)})
+	}
+	fmt.Printf("%s SBOM baseline for %s: %d components\n", style.Success.Render("✓"), branch, len(components))
+	return nil
+}
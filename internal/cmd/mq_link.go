@@ -0,0 +1,287 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/mq"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// MQ link command flags
+var (
+	mqLinkSubmodules []string
+	mqLinkReason     string
+	mqLinkJSON       bool
+)
+
+var mqLinkCmd = &cobra.Command{
+	Use:   "link <mr-id> <mr-id>...",
+	Short: "Link MRs across rigs so they land together or not at all",
+	Long: `Link merge requests in different rigs into one change.
+
+Each rig's refinery still processes its own MR, but holds it once its
+checks pass. Linked MRs land only when all of them are ready, and then in
+dependency order. If any of them fails, the group aborts: held MRs are
+rejected and MRs that already landed are reverted through their queues.
+
+For a repo that pins another as a submodule, --submodule names the
+submodule path in the pinning MR's repo and the MR it tracks:
+
+  --submodule <mr-id>=<path>:<tracked-mr-id>
+
+The tracked MR lands first; the pinning MR's branch then gets its
+submodule pointer bumped to the tracked MR's merge commit, its checks
+re-run, and it lands.
+
+Examples:
+  gt mq link lib-mr-abc app-mr-def --submodule app-mr-def=vendor/lib:lib-mr-abc
+  gt mq link gt-mr-1 bd-mr-2
+  gt mq link status
+  gt mq link abort link-1a2b3c4d --reason "wrong approach"`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runMQLink,
+}
+
+var mqLinkStatusCmd = &cobra.Command{
+	Use:   "status [link-id]",
+	Short: "Show link groups and their members",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runMQLinkStatus,
+}
+
+var mqLinkAbortCmd = &cobra.Command{
+	Use:   "abort <link-id>",
+	Short: "Abort a link group, reverting members that already landed",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runMQLinkAbort,
+}
+
+func init() {
+	mqLinkCmd.Flags().StringArrayVar(&mqLinkSubmodules, "submodule", nil, "Submodule pin as <mr-id>=<path>:<tracked-mr-id> (repeatable)")
+	mqLinkCmd.Flags().BoolVar(&mqLinkJSON, "json", false, "Output as JSON")
+	mqLinkStatusCmd.Flags().BoolVar(&mqLinkJSON, "json", false, "Output as JSON")
+	mqLinkAbortCmd.Flags().StringVarP(&mqLinkReason, "reason", "r", "", "Why the group is being aborted")
+
+	mqLinkCmd.AddCommand(mqLinkStatusCmd)
+	mqLinkCmd.AddCommand(mqLinkAbortCmd)
+	mqCmd.AddCommand(mqLinkCmd)
+}
+
+// parseSubmoduleLink parses "<mr-id>=<path>:<tracked-mr-id>".
+func parseSubmoduleLink(spec string) (mr, path, tracks string, err error) {
+	mr, rest, ok := strings.Cut(spec, "=")
+	if ok {
+		if i := strings.LastIndex(rest, ":"); i > 0 {
+			path, tracks = rest[:i], rest[i+1:]
+		}
+	}
+	if mr == "" || path == "" || tracks == "" {
+		return "", "", "", fmt.Errorf("invalid --submodule %q: want <mr-id>=<path>:<tracked-mr-id>", spec)
+	}
+	return mr, strings.Trim(path, "/"), tracks, nil
+}
+
+func runMQLink(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	type linkedBead struct {
+		bd    *beads.Beads
+		issue *beads.Issue
+	}
+	var members []*mq.LinkMember
+	byMR := make(map[string]*mq.LinkMember)
+	linked := make(map[string]linkedBead)
+	for _, mrID := range args {
+		rigName, r, err := getRigForBead(mrID)
+		if err != nil {
+			return fmt.Errorf("%s: %w", mrID, err)
+		}
+		bd := beads.New(r.BeadsPath())
+		issue, err := bd.Show(mrID)
+		if err != nil {
+			return fmt.Errorf("fetching %s: %w", mrID, err)
+		}
+		fields := beads.ParseMRFields(issue)
+		if fields == nil || issue.Type != "merge-request" {
+			return fmt.Errorf("%s is not a merge request", mrID)
+		}
+		if issue.Status == "closed" {
+			return fmt.Errorf("%s is already closed", mrID)
+		}
+		if fields.LinkGroup != "" {
+			return fmt.Errorf("%s is already in link group %s", mrID, fields.LinkGroup)
+		}
+		m := &mq.LinkMember{Rig: rigName, MR: mrID, Branch: fields.Branch, Target: fields.Target}
+		members = append(members, m)
+		byMR[mrID] = m
+		linked[mrID] = linkedBead{bd: bd, issue: issue}
+	}
+	for _, spec := range mqLinkSubmodules {
+		mrID, path, tracks, err := parseSubmoduleLink(spec)
+		if err != nil {
+			return err
+		}
+		m := byMR[mrID]
+		if m == nil {
+			return fmt.Errorf("--submodule %q: %s is not one of the linked MRs", spec, mrID)
+		}
+		m.Submodule, m.Tracks = path, tracks
+	}
+
+	g, err := mq.NewLinkGroup(members, detectSender(), time.Now())
+	if err != nil {
+		return err
+	}
+	if err := mq.SaveLink(townRoot, g); err != nil {
+		return fmt.Errorf("saving link group: %w", err)
+	}
+
+	// Tag each MR so its refinery holds it for the group
+	for _, mrID := range args {
+		lb := linked[mrID]
+		fields := beads.ParseMRFields(lb.issue)
+		fields.LinkGroup = g.ID
+		desc := beads.SetMRFields(lb.issue, fields)
+		if err := lb.bd.Update(mrID, beads.UpdateOptions{Description: &desc}); err != nil {
+			return fmt.Errorf("tagging %s with link group %s: %w", mrID, g.ID, err)
+		}
+	}
+
+	if mqLinkJSON {
+		return outputJSON(g)
+	}
+	fmt.Printf("%s Linked %d MRs as %s\n", style.Success.Render("✓"), len(g.Members), style.Bold.Render(g.ID))
+	printLinkGroup(g)
+	return nil
+}
+
+func runMQLinkStatus(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	var groups []*mq.LinkGroup
+	if len(args) == 1 {
+		g, err := mq.LoadLink(townRoot, args[0])
+		if err != nil {
+			return err
+		}
+		groups = []*mq.LinkGroup{g}
+	} else if groups, err = mq.ListLinks(townRoot); err != nil {
+		return fmt.Errorf("listing link groups: %w", err)
+	}
+
+	if mqLinkJSON {
+		return outputJSON(groups)
+	}
+	if len(groups) == 0 {
+		fmt.Println("No link groups.")
+		return nil
+	}
+	for i, g := range groups {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s  %s\n", style.Bold.Render(g.ID), linkStateStyle(g.State))
+		if g.Reason != "" {
+			fmt.Printf("  %s\n", style.Dim.Render(g.Reason))
+		}
+		printLinkGroup(g)
+	}
+	return nil
+}
+
+func runMQLinkAbort(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	reason := mqLinkReason
+	if reason == "" {
+		reason = "aborted by " + detectSender()
+	}
+
+	var landed []*mq.LinkMember
+	g, err := mq.UpdateLink(townRoot, args[0], func(g *mq.LinkGroup) error {
+		if g.State != mq.LinkOpen {
+			return fmt.Errorf("link group %s is already %s", g.ID, g.State)
+		}
+		landed = g.Abort(reason, time.Now())
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s Aborted %s\n", style.Bold.Render("✓"), g.ID)
+
+	// Held members are rejected by their refineries; landed ones are reverted here
+	for _, m := range landed {
+		mgr, _, _, err := getRefineryManager(m.Rig)
+		if err != nil {
+			style.PrintWarning("cannot revert %s: %v", m.MR, err)
+			continue
+		}
+		result, err := mgr.RevertMR(m.MR, refinery.RevertOptions{
+			Reason:   "link group " + g.ID + " aborted: " + reason,
+			Actor:    detectSender(),
+			Commit:   m.MergeCommit,
+			Priority: -1,
+			Hotfix:   true,
+		})
+		if err != nil {
+			style.PrintWarning("reverting %s: %v", m.MR, err)
+			continue
+		}
+		if _, err := mq.UpdateLink(townRoot, g.ID, func(g *mq.LinkGroup) error {
+			g.MarkReverted(m.MR, time.Now())
+			return nil
+		}); err != nil {
+			style.PrintWarning("recording revert of %s: %v", m.MR, err)
+		}
+		fmt.Printf("  Reverting %s via %s\n", m.MR, style.Bold.Render(result.RevertMR))
+	}
+	return nil
+}
+
+// printLinkGroup prints a group's members in landing order.
+func printLinkGroup(g *mq.LinkGroup) {
+	order, err := g.Order()
+	if err != nil {
+		order = g.Members
+	}
+	for i, m := range order {
+		line := fmt.Sprintf("  %d. %-14s %-10s %s", i+1, m.MR, m.Rig, linkStateStyle(m.State))
+		if m.Submodule != "" {
+			line += style.Dim.Render(fmt.Sprintf("  pins %s to %s", m.Submodule, m.Tracks))
+		}
+		if m.MergeCommit != "" {
+			line += style.Dim.Render("  " + shortSHA(m.MergeCommit))
+		}
+		fmt.Println(line)
+		if m.Error != "" {
+			fmt.Printf("     %s\n", style.Dim.Render(m.Error))
+		}
+	}
+}
+
+func linkStateStyle(state string) string {
+	switch state {
+	case mq.LinkMerged, mq.LinkLanded:
+		return style.Success.Render(state)
+	case mq.LinkFailed, mq.LinkAborted:
+		return style.Error.Render(state)
+	case mq.LinkReady, mq.LinkReverted:
+		return style.Warning.Render(state)
+	}
+	return style.Dim.Render(state)
+}
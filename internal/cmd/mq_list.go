@@ -1,21 +1,35 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
-	"sort"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/i18n"
+	"github.com/steveyegge/gastown/internal/outputversion"
+	"github.com/steveyegge/gastown/internal/paging"
 	"github.com/steveyegge/gastown/internal/refinery"
 	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/timefmt"
 )
 
 func runMQList(cmd *cobra.Command, args []string) error {
 	rigName := args[0]
+	if mqListPorcelain != "" {
+		if err := checkPorcelainVersion(mqListPorcelain); err != nil {
+			return err
+		}
+	}
+
+	if mqListSLO {
+		return runMQListSLO(rigName)
+	}
+	if mqListConflicts {
+		return runMQListConflicts(rigName)
+	}
 
 	_, r, _, err := getRefineryManager(rigName)
 	if err != nil {
@@ -40,6 +54,17 @@ func runMQList(cmd *cobra.Command, args []string) error {
 		opts.Status = "open"
 	}
 
+	// Closed MR history is paged by bd, in its order (see mq list --help)
+	page := paging.Page{Limit: mqListLimit, Offset: mqListOffset}
+	if err := page.Validate(); err != nil {
+		return err
+	}
+	history := page.Paged() && !mqListReady && !strings.EqualFold(opts.Status, "open") &&
+		mqListWorker == "" && mqListEpic == "" && mqListTarget == ""
+	if history {
+		opts.Limit = page.Fetch()
+	}
+
 	var issues []*beads.Issue
 
 	if mqListReady {
@@ -90,6 +115,11 @@ func runMQList(cmd *cobra.Command, args []string) error {
 		// Parse MR fields
 		fields := beads.ParseMRFields(issue)
 
+		// MRs held for review aren't ready to merge
+		if mqListReady && refinery.ReviewHolds(fields) {
+			continue
+		}
+
 		// Filter by worker
 		if mqListWorker != "" {
 			worker := ""
@@ -113,15 +143,36 @@ func runMQList(cmd *cobra.Command, args []string) error {
 			}
 		}
 
-		// Calculate priority score
-		score := calculateMRScore(issue, fields, now)
-		scored = append(scored, scoredIssue{issue: issue, fields: fields, score: score})
+		// Filter by target branch (MRs without one target the default)
+		if mqListTarget != "" {
+			target := r.DefaultBranch()
+			if fields != nil && fields.Target != "" {
+				target = fields.Target
+			}
+			if target != mqListTarget {
+				continue
+			}
+		}
+
+		scored = append(scored, scoredIssue{issue: issue, fields: fields})
 	}
 
-	// Sort by score descending (highest priority first)
-	sort.Slice(scored, func(i, j int) bool {
-		return scored[i].score > scored[j].score
-	})
+	// Put them in queue order (see 'gt mq order'), highest priority first
+	if !history {
+		byID := make(map[string]scoredIssue, len(scored))
+		var queued []*beads.Issue
+		for _, s := range scored {
+			byID[s.issue.ID] = s
+			queued = append(queued, s.issue)
+		}
+		scored = scored[:0]
+		for _, entry := range refinery.OrderQueue(refinery.QueueCandidates(b, queued), now) {
+			s := byID[entry.ID]
+			s.score = entry.Score
+			scored = append(scored, s)
+		}
+	}
+	scored, pageInfo := paging.Apply(scored, page)
 
 	// Extract filtered issues for JSON output compatibility
 	var filtered []*beads.Issue
@@ -134,11 +185,39 @@ func runMQList(cmd *cobra.Command, args []string) error {
 		return outputJSON(filtered)
 	}
 
+	// Porcelain v1: mr <id> <status> <priority> <score> <branch> <target>
+	// <issue> <worker> <convoy> <created-at>, in queue order
+	if mqListPorcelain != "" {
+		p := porcelainWriter{w: os.Stdout}
+		for _, item := range scored {
+			f := item.fields
+			if f == nil {
+				f = &beads.MRFields{}
+			}
+			p.record("mr", item.issue.ID, mrDisplayStatus(item.issue, item.fields), porcelainInt(item.issue.Priority),
+				fmt.Sprintf("%.1f", item.score), f.Branch, f.Target, f.SourceIssue, f.Worker, f.ConvoyID, item.issue.CreatedAt)
+		}
+		return nil
+	}
+
 	// Human-readable output
-	fmt.Printf("%s Merge queue for '%s':\n\n", style.Bold.Render("📋"), rigName)
+	if !quietFlag {
+		fmt.Printf("%s %s\n\n", style.Bold.Render("📋"), i18n.Sprintf("Merge queue for '%s':", rigName))
+	}
+
+	if freeze, err := rigTargetFreeze(r, mqListTarget); err != nil {
+		style.PrintWarning("could not check queue freeze: %v", err)
+	} else if freeze.Frozen() {
+		fmt.Printf("  %s %s\n", style.Warning.Render("❄ FROZEN"), freeze.Reason())
+		fmt.Printf("  %s\n\n", style.Dim.Render(i18n.T("Only hotfix MRs will merge until the freeze lifts")))
+	}
 
 	if len(filtered) == 0 {
-		fmt.Printf("  %s\n", style.Dim.Render("(empty)"))
+		if page.Offset > 0 {
+			printPageHint(&pageInfo, 0)
+		} else if !quietFlag {
+			fmt.Printf("  %s\n", style.Dim.Render(i18n.T("(empty)")))
+		}
 		return nil
 	}
 
@@ -149,6 +228,7 @@ func runMQList(cmd *cobra.Command, args []string) error {
 		style.Column{Name: "PRI", Width: 4},
 		style.Column{Name: "CONVOY", Width: 12},
 		style.Column{Name: "BRANCH", Width: 24},
+		style.Column{Name: "DIFF", Width: 12},
 		style.Column{Name: "STATUS", Width: 10},
 		style.Column{Name: "AGE", Width: 6, Align: style.AlignRight},
 	)
@@ -158,15 +238,7 @@ func runMQList(cmd *cobra.Command, args []string) error {
 		issue := item.issue
 		fields := item.fields
 
-		// Determine display status
-		displayStatus := issue.Status
-		if issue.Status == "open" {
-			if len(issue.BlockedBy) > 0 || issue.BlockedByCount > 0 {
-				displayStatus = "blocked"
-			} else {
-				displayStatus = "ready"
-			}
-		}
+		displayStatus := mrDisplayStatus(issue, fields)
 
 		// Format status with styling
 		styledStatus := displayStatus
@@ -177,6 +249,10 @@ func runMQList(cmd *cobra.Command, args []string) error {
 			styledStatus = style.Warning.Render("active")
 		case "blocked":
 			styledStatus = style.Dim.Render("blocked")
+		case "review":
+			styledStatus = style.Warning.Render("review")
+		case "changes":
+			styledStatus = style.Error.Render("changes")
 		case "closed":
 			styledStatus = style.Dim.Render("closed")
 		}
@@ -184,9 +260,13 @@ func runMQList(cmd *cobra.Command, args []string) error {
 		// Get MR fields
 		branch := ""
 		convoyID := ""
+		diff := style.Dim.Render("-")
 		if fields != nil {
 			branch = fields.Branch
 			convoyID = fields.ConvoyID
+			if fields.DiffFiles > 0 {
+				diff = formatLineDelta(fields.LinesAdded, fields.LinesDeleted)
+			}
 		}
 
 		// Format convoy column
@@ -219,7 +299,7 @@ func runMQList(cmd *cobra.Command, args []string) error {
 			displayID = displayID[:12]
 		}
 
-		table.AddRow(displayID, scoreStr, priority, convoyDisplay, branch, styledStatus, style.Dim.Render(age))
+		table.AddRow(displayID, scoreStr, priority, convoyDisplay, branch, diff, styledStatus, style.Dim.Render(age))
 	}
 
 	fmt.Print(table.Render())
@@ -241,71 +321,149 @@ func runMQList(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	printPageHint(&pageInfo, len(scored))
 	return nil
 }
 
-// formatMRAge formats the age of an MR from its created_at timestamp.
-func formatMRAge(createdAt string) string {
-	t, err := time.Parse(time.RFC3339, createdAt)
+// runMQListSLO shows how long each open MR has been in its queue state,
+// against the rig's SLOs, worst first.
+func runMQListSLO(rigName string) error {
+	eng, err := loadRigEngineer(rigName)
 	if err != nil {
-		// Try other formats
-		t, err = time.Parse("2006-01-02T15:04:05Z", createdAt)
-		if err != nil {
-			return "?"
-		}
+		return err
+	}
+	dwells, err := eng.QueueDwells(time.Now())
+	if err != nil {
+		return err
+	}
+	if mqListJSON {
+		return outputJSON(dwells)
 	}
 
-	d := time.Since(t)
-
-	if d < time.Minute {
-		return fmt.Sprintf("%ds", int(d.Seconds()))
+	fmt.Printf("%s Queue SLOs for '%s':\n\n", style.Bold.Render("⏱"), rigName)
+	slos := eng.SLOs()
+	if len(slos) == 0 {
+		fmt.Printf("  %s\n\n", style.Dim.Render("No SLOs set (merge_queue.slo in the rig config)"))
+	}
+	if len(dwells) == 0 {
+		fmt.Printf("  %s\n", style.Dim.Render("(empty)"))
+		return nil
 	}
-	if d < time.Hour {
-		return fmt.Sprintf("%dm", int(d.Minutes()))
+	table := style.NewTable(
+		style.Column{Name: "ID", Width: 12},
+		style.Column{Name: "STATE", Width: 16},
+		style.Column{Name: "IN STATE", Width: 9, Align: style.AlignRight},
+		style.Column{Name: "SLO", Width: 6, Align: style.AlignRight},
+		style.Column{Name: "BRANCH", Width: 32},
+	)
+	breached := 0
+	for _, d := range dwells {
+		dwell, slo := timefmt.Age(d.Dwell), style.Dim.Render("-")
+		if d.SLO > 0 {
+			slo = timefmt.Age(d.SLO)
+		}
+		switch {
+		case d.Breached:
+			breached++
+			dwell = style.Error.Render(dwell)
+		case d.Load() >= 0.8:
+			dwell = style.Warning.Render(dwell)
+		}
+		table.AddRow(d.MR, strings.ReplaceAll(d.State, "_", " "), dwell, slo, d.Branch)
 	}
-	if d < 24*time.Hour {
-		return fmt.Sprintf("%dh", int(d.Hours()))
+	fmt.Print(table.Render())
+	if breached > 0 {
+		fmt.Printf("\n  %s\n", style.Error.Render(fmt.Sprintf("%d MR(s) over SLO", breached)))
 	}
-	return fmt.Sprintf("%dd", int(d.Hours()/24))
-}
-
-// outputJSON outputs data as JSON.
-func outputJSON(data interface{}) error {
-	enc := json.NewEncoder(os.Stdout)
-	enc.SetIndent("", "  ")
-	return enc.Encode(data)
+	return nil
 }
 
-// calculateMRScore computes the priority score for an MR using the refinery scoring function.
-// Higher scores mean higher priority (process first).
-func calculateMRScore(issue *beads.Issue, fields *beads.MRFields, now time.Time) float64 {
-	// Parse MR creation time
-	mrCreatedAt, err := time.Parse(time.RFC3339, issue.CreatedAt)
+// runMQListConflicts shows pairs of in-flight efforts that change the same
+// files, in queue order.
+func runMQListConflicts(rigName string) error {
+	eng, err := loadRigEngineer(rigName)
 	if err != nil {
-		mrCreatedAt, err = time.Parse("2006-01-02T15:04:05Z", issue.CreatedAt)
-		if err != nil {
-			mrCreatedAt = now // Fallback to now if parsing fails
+		return err
+	}
+	overlaps, err := eng.PredictConflicts()
+	if err != nil {
+		return err
+	}
+	if mqListJSON {
+		if overlaps == nil {
+			overlaps = []refinery.Overlap{}
 		}
+		return outputJSON(overlaps)
 	}
 
-	// Build score input
-	input := refinery.ScoreInput{
-		Priority:    issue.Priority,
-		MRCreatedAt: mrCreatedAt,
-		Now:         now,
+	fmt.Printf("%s Predicted conflicts for '%s':\n\n", style.Bold.Render("⚠"), rigName)
+	if len(overlaps) == 0 {
+		fmt.Printf("  %s\n", style.Dim.Render("(none: no in-flight work touches the same files)"))
+		return nil
 	}
+	for _, o := range overlaps {
+		fmt.Printf("  %s %s %s\n", style.Bold.Render(effortLabel(o.First)), style.Dim.Render("↔"), style.Bold.Render(effortLabel(o.Second)))
+		fmt.Printf("    %d shared file(s): %s\n", len(o.Files), summarizeFiles(o.Files, 5))
+		fmt.Printf("    %s\n\n", style.Dim.Render("Suggest: "+o.Suggestion()))
+	}
+	return nil
+}
 
-	// Add fields from MR metadata if available
-	if fields != nil {
-		input.RetryCount = fields.RetryCount
+// effortLabel names an in-flight effort with its worker, if known.
+func effortLabel(ef refinery.Effort) string {
+	if ef.Worker == "" {
+		return ef.Name()
+	}
+	return fmt.Sprintf("%s (%s)", ef.Name(), ef.Worker)
+}
 
-		// Parse convoy created at if available
-		if fields.ConvoyCreatedAt != "" {
-			if convoyTime, err := time.Parse(time.RFC3339, fields.ConvoyCreatedAt); err == nil {
-				input.ConvoyCreatedAt = &convoyTime
-			}
-		}
+// summarizeFiles lists up to max files, noting how many more there are.
+func summarizeFiles(files []string, max int) string {
+	if len(files) <= max {
+		return strings.Join(files, ", ")
+	}
+	return fmt.Sprintf("%s, and %d more", strings.Join(files[:max], ", "), len(files)-max)
+}
+
+// mrDisplayStatus refines an MR's status for listing: open MRs show as
+// blocked, review, changes, or ready.
+func mrDisplayStatus(issue *beads.Issue, fields *beads.MRFields) string {
+	if issue.Status != "open" {
+		return issue.Status
+	}
+	switch {
+	case len(issue.BlockedBy) > 0 || issue.BlockedByCount > 0:
+		return "blocked"
+	case fields != nil && fields.Review == refinery.ReviewRequested:
+		return "review"
+	case fields != nil && fields.Review == refinery.ReviewChangesRequested:
+		return "changes"
 	}
+	return "ready"
+}
 
-	return refinery.ScoreMRWithDefaults(input)
+// formatMRAge formats the age of an MR from its created_at timestamp.
+func formatMRAge(createdAt string) string {
+	t, err := timefmt.Parse(createdAt)
+	if err != nil {
+		return "?"
+	}
+	return timefmt.Age(time.Since(t))
+}
+
+// outputJSON outputs data as JSON.
+func outputJSON(data interface{}) error {
+	if err := setOutputVersion(); err != nil {
+		return err
+	}
+	out, deprecations, err := outputversion.Encode(data, outputCommand, outputversion.Requested())
+	if err != nil {
+		return err
+	}
+	// On stderr, so the JSON on stdout still parses.
+	for _, d := range deprecations {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", d)
+	}
+	_, err = os.Stdout.Write(out)
+	return err
 }
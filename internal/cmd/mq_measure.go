@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// MQ measure command flags
+var (
+	mqMeasureNoCoverage bool
+	mqMeasureBaseline   string
+	mqMeasureNoRecord   bool
+	mqMeasureJSON       bool
+)
+
+var mqMeasureCmd = &cobra.Command{
+	Use:   "measure <rig> [mr-id]",
+	Short: "Record diff size and coverage for a merge request",
+	Long: `Measure an MR's diff against its target and, if configured, its coverage.
+
+The diff (files changed, lines added and deleted) is computed between the
+MR's target and branch, using the origin/ refs when present. If the rig's
+merge_queue config sets coverage_command, it is run in the refinery clone,
+which should have the MR's branch checked out (e.g. after tests pass). The
+coverage delta is relative to the target's recorded baseline.
+
+The result is recorded on the MR bead as diff_files, lines_added,
+lines_deleted, coverage, and coverage_delta fields, and shown by
+'gt mq list' and 'gt mq status'.
+
+With --baseline <branch> and no MR, coverage of the current checkout is
+recorded as that branch's baseline (run after merging to main).
+
+Example coverage_command for Go:
+  go test -coverprofile=/tmp/cover.out ./... >/dev/null && go tool cover -func=/tmp/cover.out
+
+Examples:
+  gt mq measure gastown gt-mr-abc
+  gt mq measure gastown gt-mr-abc --no-coverage --json
+  gt mq measure gastown --baseline main`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runMQMeasure,
+}
+
+func init() {
+	mqMeasureCmd.Flags().BoolVar(&mqMeasureNoCoverage, "no-coverage", false, "Only measure the diff")
+	mqMeasureCmd.Flags().StringVar(&mqMeasureBaseline, "baseline", "", "Record coverage of the current checkout as this branch's baseline")
+	mqMeasureCmd.Flags().BoolVar(&mqMeasureNoRecord, "no-record", false, "Don't update the MR bead")
+	mqMeasureCmd.Flags().BoolVar(&mqMeasureJSON, "json", false, "Output as JSON")
+
+	mqCmd.AddCommand(mqMeasureCmd)
+}
+
+func runMQMeasure(cmd *cobra.Command, args []string) error {
+	rigName := args[0]
+
+	if (len(args) == 2) == (mqMeasureBaseline != "") {
+		return fmt.Errorf("specify either an MR ID or --baseline <branch>")
+	}
+
+	_, r, _, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+
+	eng := refinery.NewEngineer(r)
+	if err := eng.LoadConfig(); err != nil {
+		return fmt.Errorf("loading merge queue config: %w", err)
+	}
+	if mqMeasureJSON {
+		eng.SetOutput(os.Stderr)
+	}
+
+	if mqMeasureBaseline != "" {
+		return measureBaseline(eng, mqMeasureBaseline)
+	}
+
+	mrID := args[1]
+	issue, err := beads.New(r.Path).Show(mrID)
+	if err != nil {
+		return fmt.Errorf("fetching merge request %s: %w", mrID, err)
+	}
+	fields := beads.ParseMRFields(issue)
+	if fields == nil || fields.Branch == "" {
+		return fmt.Errorf("%s has no branch field; is it a merge request?", mrID)
+	}
+	target := fields.Target
+	if target == "" {
+		target = eng.Config().TargetBranch
+	}
+
+	metrics, err := eng.MeasureMR(context.Background(), fields.Branch, target, !mqMeasureNoCoverage)
+	if err != nil {
+		return err
+	}
+
+	if !mqMeasureNoRecord {
+		if err := eng.RecordMetrics(mrID, metrics); err != nil {
+			return err
+		}
+	}
+
+	if mqMeasureJSON {
+		return outputJSON(metrics)
+	}
+
+	fmt.Printf("%s %s: %s\n", style.Success.Render("✓"), mrID,
+		formatMRSize(metrics.Diff.Files, metrics.Diff.Added, metrics.Diff.Deleted))
+	if metrics.Coverage != nil {
+		delta := ""
+		if metrics.CoverageDelta != nil {
+			delta = fmt.Sprintf("%+.1f", *metrics.CoverageDelta)
+		}
+		fmt.Printf("  Coverage: %s\n", formatCoverage(fmt.Sprintf("%.1f", *metrics.Coverage), delta))
+	}
+	return nil
+}
+
+// measureBaseline records the coverage of the current checkout for a branch.
+func measureBaseline(eng *refinery.Engineer, branch string) error {
+	pct, err := eng.MeasureCoverage(context.Background())
+	if err != nil {
+		return err
+	}
+	if pct == nil {
+		return fmt.Errorf("no coverage_command configured in merge_queue")
+	}
+
+	if err := eng.SaveCoverageBaseline(branch, *pct, ""); err != nil {
+		return fmt.Errorf("saving baseline: %w", err)
+	}
+
+	if mqMeasureJSON {
+		return outputJSON(map[string]interface{}{"branch": branch, "coverage": *pct})
+	}
+	fmt.Printf("%s Coverage baseline for %s: %.1f%%\n", style.Success.Render("✓"), branch, *pct)
+	return nil
+}
+
+// formatMRSize renders diff stats as "3 files, +120/-30".
+func formatMRSize(files, added, deleted int) string {
+	noun := "files"
+	if files == 1 {
+		noun = "file"
+	}
+	return fmt.Sprintf("%d %s, %s", files, noun, formatLineDelta(added, deleted))
+}
+
+// formatLineDelta renders line counts as a colored "+120/-30".
+func formatLineDelta(added, deleted int) string {
+	return style.Success.Render(fmt.Sprintf("+%d", added)) + "/" + style.Error.Render(fmt.Sprintf("-%d", deleted))
+}
+
+// formatCoverage renders coverage with its delta, warning on drops.
+func formatCoverage(coverage, delta string) string {
+	out := coverage + "%"
+	switch {
+	case delta == "":
+	case strings.HasPrefix(delta, "-") && strings.Trim(delta, "-0.") != "":
+		out += " " + style.Warning.Render("("+delta+")")
+	default:
+		out += " " + style.Dim.Render("("+delta+")")
+	}
+	return out
+}
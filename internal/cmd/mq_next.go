@@ -7,6 +7,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/refinery"
 	"github.com/steveyegge/gastown/internal/style"
 )
 
@@ -24,10 +25,14 @@ var mqNextCmd = &cobra.Command{
 
 The priority scoring function considers:
   - Convoy age: Older convoys get higher priority (starvation prevention)
-  - Issue priority: P0 > P1 > P2 > P3 > P4
+  - Issue priority: P0 > P1 > P2 > P3 > P4 (the more urgent of the MR's
+    and its source issue's, raised by 'gt mq bump')
   - Retry count: MRs that fail repeatedly get deprioritized
   - MR age: FIFO tiebreaker for same priority/convoy
 
+MRs moved to the front with 'gt mq bump --to-front' come first. See
+'gt mq order' for the whole queue and why.
+
 Use --strategy=fifo for first-in-first-out ordering instead.
 
 Examples:
@@ -91,6 +96,7 @@ func runMQNext(cmd *cobra.Command, args []string) error {
 	}
 
 	now := time.Now()
+	scores := make(map[string]float64, len(ready))
 
 	// Sort based on strategy
 	if mqNextStrategy == "fifo" {
@@ -101,25 +107,14 @@ func runMQNext(cmd *cobra.Command, args []string) error {
 			return ti.Before(tj)
 		})
 	} else {
-		// Priority: highest score first
-		type scoredIssue struct {
-			issue *beads.Issue
-			score float64
+		// Priority: queue order (see 'gt mq order')
+		byID := make(map[string]*beads.Issue, len(ready))
+		for _, issue := range ready {
+			byID[issue.ID] = issue
 		}
-		scored := make([]scoredIssue, len(ready))
-		for i, issue := range ready {
-			fields := beads.ParseMRFields(issue)
-			score := calculateMRScore(issue, fields, now)
-			scored[i] = scoredIssue{issue: issue, score: score}
-		}
-
-		sort.Slice(scored, func(i, j int) bool {
-			return scored[i].score > scored[j].score
-		})
-
-		// Rebuild ready slice in sorted order
-		for i, s := range scored {
-			ready[i] = s.issue
+		for i, entry := range refinery.OrderQueue(refinery.QueueCandidates(b, ready), now) {
+			ready[i] = byID[entry.ID]
+			scores[entry.ID] = entry.Score
 		}
 	}
 
@@ -140,10 +135,10 @@ func runMQNext(cmd *cobra.Command, args []string) error {
 	// Human-readable output
 	fmt.Printf("%s Next MR to process:\n\n", style.Bold.Render("🎯"))
 
-	score := calculateMRScore(next, fields, now)
-
 	fmt.Printf("  ID:       %s\n", next.ID)
-	fmt.Printf("  Score:    %.1f\n", score)
+	if score, ok := scores[next.ID]; ok {
+		fmt.Printf("  Score:    %.1f\n", score)
+	}
 	fmt.Printf("  Priority: P%d\n", next.Priority)
 
 	if fields != nil {
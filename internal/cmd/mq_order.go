@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// MQ bump/order command flags
+var (
+	mqBumpToFront bool
+	mqBumpReset   bool
+	mqOrderJSON   bool
+	mqOrderTarget string
+)
+
+var mqBumpCmd = &cobra.Command{
+	Use:   "bump <rig> <mr-id-or-branch>",
+	Short: "Expedite a merge request",
+	Long: `Move a merge request up the merge queue.
+
+By default the MR is boosted one priority level (P3 → P2) above where it
+stands, and can be bumped again. --to-front moves it ahead of every other
+MR instead; the MR most recently moved to the front goes first. --reset
+clears earlier bumps.
+
+MRs also inherit their source issue's priority: escalating the issue to
+P0 expedites its MR without a bump. See 'gt mq order' for the result.
+
+Examples:
+  gt mq bump gastown gt-mr-abc
+  gt mq bump gastown polecat/nux/gt-xyz@k3x9 --to-front
+  gt mq bump gastown gt-mr-abc --reset`,
+	Args: cobra.ExactArgs(2),
+	RunE: runMQBump,
+}
+
+var mqOrderCmd = &cobra.Command{
+	Use:   "order <rig>",
+	Short: "Show the merge queue order and why",
+	Long: `Show every open MR in the order the refinery will process it, with why
+each MR is where it is.
+
+The order is deterministic:
+  1. MRs moved to the front with 'gt mq bump --to-front', latest first
+  2. Then by score: priority (the more urgent of the MR's and its source
+     issue's, raised by bumps), convoy age, retries, and time queued
+  3. Then oldest first, then by ID
+  4. Then each MR moves below any MR it depends on (declared with
+     'gt mq submit --depends-on', or through its source issue's
+     dependencies), so parents always land first
+
+MRs that are blocked, claimed, awaiting review, or waiting on a dependency
+keep their place but are skipped until released; the HELD column says why.
+Dependency cycles hold every MR in them.
+
+--target shows one target branch's queue, numbered within that target.
+
+Examples:
+  gt mq order gastown
+  gt mq order gastown --target=release-1.2
+  gt mq order gastown --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMQOrder,
+}
+
+func init() {
+	mqBumpCmd.Flags().BoolVar(&mqBumpToFront, "to-front", false, "Move the MR ahead of every other MR")
+	mqBumpCmd.Flags().BoolVar(&mqBumpReset, "reset", false, "Clear earlier bumps")
+	mqBumpCmd.MarkFlagsMutuallyExclusive("to-front", "reset")
+	mqOrderCmd.Flags().BoolVar(&mqOrderJSON, "json", false, "Output as JSON")
+	mqOrderCmd.Flags().StringVar(&mqOrderTarget, "target", "", "Show the queue for one target branch")
+
+	mqCmd.AddCommand(mqBumpCmd)
+	mqCmd.AddCommand(mqOrderCmd)
+}
+
+func runMQBump(cmd *cobra.Command, args []string) error {
+	mgr, r, _, err := getRefineryManager(args[0])
+	if err != nil {
+		return err
+	}
+	mr, err := mgr.FindMR(args[1])
+	if err != nil {
+		return fmt.Errorf("finding MR %s: %w", args[1], err)
+	}
+
+	eng := refinery.NewEngineer(r)
+	now := time.Now()
+	if err := eng.Bump(mr.ID, mqBumpToFront, mqBumpReset, detectSender(), now); err != nil {
+		return err
+	}
+
+	switch {
+	case mqBumpReset:
+		fmt.Printf("%s Cleared bumps on %s\n", style.Bold.Render("✓"), mr.ID)
+	case mqBumpToFront:
+		fmt.Printf("%s Moved %s to the front of the queue\n", style.Bold.Render("⏫"), mr.ID)
+	default:
+		fmt.Printf("%s Boosted %s\n", style.Bold.Render("⬆"), mr.ID)
+	}
+	entries, err := eng.QueueOrder(now)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.ID == mr.ID {
+			fmt.Printf("  Position: %d of %d (P%d)\n", e.Position, len(entries), e.Priority)
+			if e.Held != "" {
+				fmt.Printf("  %s\n", style.Dim.Render("Held: "+e.Held))
+			}
+		}
+	}
+	return nil
+}
+
+func runMQOrder(cmd *cobra.Command, args []string) error {
+	eng, err := loadRigEngineer(args[0])
+	if err != nil {
+		return err
+	}
+	entries, err := eng.QueueOrder(time.Now())
+	if err != nil {
+		return err
+	}
+	if mqOrderTarget != "" {
+		entries = targetQueue(entries, mqOrderTarget, eng.Config().TargetBranch)
+	}
+	if mqOrderJSON {
+		if entries == nil {
+			entries = []refinery.QueueEntry{}
+		}
+		return outputJSON(entries)
+	}
+
+	title := args[0]
+	if mqOrderTarget != "" {
+		title += " → " + mqOrderTarget
+	}
+	fmt.Printf("%s Merge queue order for '%s':\n\n", style.Bold.Render("📋"), title)
+	if len(entries) == 0 {
+		fmt.Printf("  %s\n", style.Dim.Render("(empty)"))
+		return nil
+	}
+	table := style.NewTable(
+		style.Column{Name: "#", Width: 3, Align: style.AlignRight},
+		style.Column{Name: "ID", Width: 12},
+		style.Column{Name: "PRI", Width: 4},
+		style.Column{Name: "SCORE", Width: 7, Align: style.AlignRight},
+		style.Column{Name: "HELD", Width: 20},
+		style.Column{Name: "WHY", Width: 60},
+	)
+	for _, e := range entries {
+		priority := fmt.Sprintf("P%d", e.Priority)
+		if e.Priority != e.BasePriority {
+			priority = style.Warning.Render(priority)
+		}
+		score := fmt.Sprintf("%.1f", e.Score)
+		if e.Front {
+			score = style.Warning.Render("front")
+		}
+		held := style.Dim.Render("-")
+		if e.Held != "" {
+			held = style.Dim.Render(e.Held)
+		}
+		table.AddRow(fmt.Sprintf("%d", e.Position), e.ID, priority, score, held, strings.Join(e.Reasons, "; "))
+	}
+	fmt.Print(table.Render())
+	return nil
+}
+
+// targetQueue returns the entries queued for target, renumbered within it.
+// MRs without a target queue for the default branch.
+func targetQueue(entries []refinery.QueueEntry, target, defaultBranch string) []refinery.QueueEntry {
+	var out []refinery.QueueEntry
+	for _, e := range entries {
+		t := e.Target
+		if t == "" {
+			t = defaultBranch
+		}
+		if t == target {
+			e.Position = len(out) + 1
+			out = append(out, e)
+		}
+	}
+	return out
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// MQ revert command flags
+var (
+	mqRevertReason   string
+	mqRevertCommit   string
+	mqRevertPriority int
+	mqRevertHotfix   bool
+	mqRevertDryRun   bool
+	mqRevertJSON     bool
+)
+
+var mqRevertCmd = &cobra.Command{
+	Use:   "revert <rig> <mr-id>",
+	Short: "Revert a merged merge request through the queue",
+	Long: `Revert a previously merged MR without losing track of the work.
+
+Reverting:
+  1. Commits the revert of the MR's merge commit on revert/<mr-id>,
+     cut from the MR's target branch, and pushes it
+  2. Files a revert bead (labeled reverted) that links the original
+     issue, MR, and commit
+  3. Submits the revert branch to the merge queue at elevated priority
+     (P1, or the source issue's priority if higher)
+  4. Reopens the source issue with a note pointing at the revert
+
+The revert merges like any other MR, so it is tested and recorded. Use
+--hotfix to let it through a queue freeze.
+
+Examples:
+  gt mq revert gastown gt-mr-abc --reason "broke login on Safari"
+  gt mq revert gastown gt-mr-abc --hotfix --priority 0
+  gt mq revert gastown gt-mr-abc --dry-run`,
+	Args: cobra.ExactArgs(2),
+	RunE: runMQRevert,
+}
+
+func init() {
+	mqRevertCmd.Flags().StringVarP(&mqRevertReason, "reason", "r", "", "Why the MR is being reverted")
+	mqRevertCmd.Flags().StringVar(&mqRevertCommit, "commit", "", "Commit to revert (default: the MR's merge commit)")
+	mqRevertCmd.Flags().IntVarP(&mqRevertPriority, "priority", "p", -1, "Revert priority (default: elevated above the source issue)")
+	mqRevertCmd.Flags().BoolVar(&mqRevertHotfix, "hotfix", false, "Label the revert hotfix so it merges through a queue freeze")
+	mqRevertCmd.Flags().BoolVarP(&mqRevertDryRun, "dry-run", "n", false, "Show what would be reverted without changing anything")
+	mqRevertCmd.Flags().BoolVar(&mqRevertJSON, "json", false, "Output as JSON")
+
+	mqCmd.AddCommand(mqRevertCmd)
+}
+
+func runMQRevert(cmd *cobra.Command, args []string) error {
+	rigName, mrID := args[0], args[1]
+
+	mgr, _, rigName, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+
+	actor := detectSender()
+	result, err := mgr.RevertMR(mrID, refinery.RevertOptions{
+		Reason:   mqRevertReason,
+		Actor:    actor,
+		Commit:   mqRevertCommit,
+		Priority: mqRevertPriority,
+		Hotfix:   mqRevertHotfix,
+		DryRun:   mqRevertDryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	if !result.DryRun {
+		nudgeRefinery(rigName, fmt.Sprintf("Revert submitted: %s branch=%s", result.RevertMR, result.Branch))
+		_ = events.LogFeed(events.TypeMRReverted, actor, map[string]interface{}{
+			"rig":         rigName,
+			"mr":          result.MR,
+			"revert_mr":   result.RevertMR,
+			"revert_bead": result.RevertBead,
+			"commit":      result.RevertedCommit,
+			"reason":      mqRevertReason,
+		})
+	}
+
+	if mqRevertJSON {
+		return outputJSON(result)
+	}
+
+	if result.DryRun {
+		fmt.Printf("%s Would revert %s (commit %s) on %s\n", style.Bold.Render("○"), result.MR, shortSHA(result.RevertedCommit), result.Target)
+		fmt.Printf("  Branch:   %s\n", result.Branch)
+		fmt.Printf("  Priority: P%d\n", result.Priority)
+		if result.SourceIssue != "" {
+			fmt.Printf("  Reopens:  %s\n", result.SourceIssue)
+		}
+		return nil
+	}
+
+	fmt.Printf("%s Reverting %s (commit %s)\n", style.Bold.Render("✓"), result.MR, shortSHA(result.RevertedCommit))
+	fmt.Printf("  Revert bead: %s\n", style.Bold.Render(result.RevertBead))
+	fmt.Printf("  Revert MR:   %s (P%d)\n", style.Bold.Render(result.RevertMR), result.Priority)
+	fmt.Printf("  Branch:      %s → %s\n", result.Branch, result.Target)
+	if result.SourceIssue != "" {
+		fmt.Printf("  Reopened:    %s\n", result.SourceIssue)
+	}
+	return nil
+}
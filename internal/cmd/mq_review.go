@@ -0,0 +1,343 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// MQ review command flags
+var (
+	mqNextReviewJSON     bool
+	mqNextReviewNoClaim  bool
+	mqNextReviewReviewer string
+	mqNextReviewMaxDiff  int
+
+	mqReviewVerdict  string
+	mqReviewSummary  string
+	mqReviewComments []string
+	mqReviewFrom     string
+	mqReviewReviewer string
+)
+
+var mqNextReviewCmd = &cobra.Command{
+	Use:   "next-review <rig>",
+	Short: "Pull the next merge request awaiting review",
+	Long: `Pull the next MR in the "review requested" state, with its diff and
+context: source issue, commits, changed files, diff stats, change summary,
+and any earlier review.
+
+MRs enter review when the rig sets merge_queue.require_review, or with
+'gt mq request-review'. The refinery holds them until a reviewer approves.
+
+When the rig sets code_owners, MRs touching owned paths (per its CODEOWNERS
+file) go only to reviewers matching an owner who hasn't yet approved, and
+are held until every owner has.
+
+The MR is claimed for the reviewer (default: your identity) so parallel
+reviewers don't pick the same one; --no-claim only peeks. Exits non-zero
+with no output under --json when nothing is waiting.
+
+Examples:
+  gt mq next-review gastown --json                  # For reviewer agents
+  gt mq next-review gastown --no-claim              # Peek
+  gt mq next-review gastown --json --max-diff 50000`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMQNextReview,
+}
+
+var mqReviewCmd = &cobra.Command{
+	Use:   "review <rig> <mr-id>",
+	Short: "Post a review verdict on a merge request",
+	Long: `Post a structured review verdict on an MR.
+
+Verdicts:
+  approve           Release the MR to the merge queue (once all code
+                    owners have approved)
+  request_changes   Hold the MR and mail the worker the review
+  comment           Record notes without changing the review state
+
+The verdict is stored in the MR bead's review section. Line comments use
+--comment "path:line: text" (repeatable). Agents can instead pass the
+whole verdict as JSON with --from (a file, or - for stdin):
+
+  {"verdict": "request_changes", "summary": "...",
+   "comments": [{"path": "auth.go", "line": 42, "body": "..."}]}
+
+Examples:
+  gt mq review gastown gt-mr-abc --verdict approve
+  gt mq review gastown gt-mr-abc --verdict request_changes \
+    --summary "Needs tests" --comment "auth.go:42: token is never checked"
+  gt mq review gastown gt-mr-abc --from verdict.json`,
+	Args: cobra.ExactArgs(2),
+	RunE: runMQReview,
+}
+
+var mqRequestReviewCmd = &cobra.Command{
+	Use:   "request-review <rig> <mr-id>",
+	Short: "Hold a merge request for review",
+	Long: `Put an MR in the "review requested" state. The refinery won't merge it
+until a reviewer approves it with 'gt mq review'.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runMQRequestReview,
+}
+
+func init() {
+	mqNextReviewCmd.Flags().BoolVar(&mqNextReviewJSON, "json", false, "Output the review packet as JSON")
+	mqNextReviewCmd.Flags().BoolVar(&mqNextReviewNoClaim, "no-claim", false, "Don't claim the MR")
+	mqNextReviewCmd.Flags().StringVar(&mqNextReviewReviewer, "reviewer", "", "Reviewer identity (default: detected)")
+	mqNextReviewCmd.Flags().IntVar(&mqNextReviewMaxDiff, "max-diff", 0, "Truncate the diff at this many bytes (default: 200KB)")
+
+	mqReviewCmd.Flags().StringVar(&mqReviewVerdict, "verdict", "", "approve, request_changes, or comment")
+	mqReviewCmd.Flags().StringVarP(&mqReviewSummary, "summary", "m", "", "Review summary")
+	mqReviewCmd.Flags().StringArrayVar(&mqReviewComments, "comment", nil, `Line comment as "path:line: text" (repeatable)`)
+	mqReviewCmd.Flags().StringVar(&mqReviewFrom, "from", "", "Read the verdict as JSON from a file (- for stdin)")
+	mqReviewCmd.Flags().StringVar(&mqReviewReviewer, "reviewer", "", "Reviewer identity (default: detected)")
+
+	mqCmd.AddCommand(mqNextReviewCmd)
+	mqCmd.AddCommand(mqReviewCmd)
+	mqCmd.AddCommand(mqRequestReviewCmd)
+}
+
+func runMQNextReview(cmd *cobra.Command, args []string) error {
+	eng, err := loadRigEngineer(args[0])
+	if err != nil {
+		return err
+	}
+	reviewer := mqNextReviewReviewer
+	if reviewer == "" {
+		reviewer = detectSender()
+	}
+
+	mr, err := eng.NextReview(reviewer, !mqNextReviewNoClaim)
+	if errors.Is(err, refinery.ErrNoReviewPending) {
+		if mqNextReviewJSON {
+			return NewSilentExit(1)
+		}
+		fmt.Printf("%s No merge requests awaiting review on '%s'\n", style.Dim.Render("○"), args[0])
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	packet, err := eng.ReviewPacket(mr, mqNextReviewMaxDiff)
+	if err != nil {
+		return err
+	}
+	if mqNextReviewJSON {
+		return outputJSON(packet)
+	}
+
+	fmt.Printf("%s %s: %s\n", style.Bold.Render("🔍"), packet.MR, packet.Title)
+	fmt.Printf("  Branch:   %s → %s\n", packet.Branch, packet.Target)
+	if packet.Worker != "" {
+		fmt.Printf("  Worker:   %s\n", packet.Worker)
+	}
+	if packet.SourceIssue != nil {
+		fmt.Printf("  Issue:    %s %s\n", packet.SourceIssue.ID, style.Dim.Render(packet.SourceIssue.Title))
+	}
+	if packet.DiffStat != nil {
+		fmt.Printf("  Diff:     %d file(s), %s\n", packet.DiffStat.Files, formatLineDelta(packet.DiffStat.Added, packet.DiffStat.Deleted))
+	}
+	for _, c := range packet.Commits {
+		fmt.Printf("    %s\n", style.Dim.Render(c))
+	}
+	if packet.PreviousReview != "" {
+		fmt.Printf("\n%s\n%s\n", style.Bold.Render("Previous review:"), packet.PreviousReview)
+	}
+	if len(packet.Discussion) > 0 {
+		fmt.Printf("\n%s\n", style.Bold.Render("Discussion:"))
+		printCommentThreads(packet.Discussion, "  ")
+	}
+	fmt.Println()
+	fmt.Println(packet.Diff)
+	if packet.DiffTruncated {
+		fmt.Println(style.Dim.Render("(diff truncated; raise --max-diff to see more)"))
+	}
+	return nil
+}
+
+func runMQReview(cmd *cobra.Command, args []string) error {
+	rigName, mrID := args[0], args[1]
+	verdict, err := buildReviewVerdict()
+	if err != nil {
+		return err
+	}
+	eng, err := loadRigEngineer(rigName)
+	if err != nil {
+		return err
+	}
+
+	state, err := eng.PostReview(mrID, verdict)
+	if err != nil {
+		return err
+	}
+	_ = events.LogFeed(events.TypeMRReviewed, verdict.Reviewer, map[string]interface{}{
+		"rig":      rigName,
+		"mr":       mrID,
+		"verdict":  verdict.Verdict,
+		"comments": len(verdict.Comments),
+	})
+
+	switch verdict.Verdict {
+	case refinery.VerdictApprove:
+		nudgeRefinery(rigName, fmt.Sprintf("MR approved: %s", mrID))
+		fmt.Printf("%s Approved %s; released to the merge queue\n", style.Success.Render("✓"), mrID)
+	case refinery.VerdictRequestChanges:
+		fmt.Printf("%s Requested changes on %s; the worker has been notified\n", style.Warning.Render("⚠"), mrID)
+	default:
+		fmt.Printf("%s Commented on %s", style.Bold.Render("✓"), mrID)
+		if state != "" {
+			fmt.Printf(" %s", style.Dim.Render("(review "+state+")"))
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// buildReviewVerdict assembles the verdict from --from or the flags.
+func buildReviewVerdict() (*refinery.ReviewVerdict, error) {
+	v := &refinery.ReviewVerdict{}
+	if mqReviewFrom != "" {
+		var data []byte
+		var err error
+		if mqReviewFrom == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(mqReviewFrom)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading verdict: %w", err)
+		}
+		if err := json.Unmarshal(data, v); err != nil {
+			return nil, fmt.Errorf("parsing verdict: %w", err)
+		}
+	}
+	if mqReviewVerdict != "" {
+		v.Verdict = mqReviewVerdict
+	}
+	if mqReviewSummary != "" {
+		v.Summary = mqReviewSummary
+	}
+	for _, c := range mqReviewComments {
+		v.Comments = append(v.Comments, parseReviewComment(c))
+	}
+	if mqReviewReviewer != "" {
+		v.Reviewer = mqReviewReviewer
+	}
+	if v.Reviewer == "" {
+		v.Reviewer = detectSender()
+	}
+	// Accept the hyphenated spelling too
+	v.Verdict = strings.ReplaceAll(v.Verdict, "-", "_")
+	if v.Verdict == "" {
+		return nil, fmt.Errorf("--verdict is required (approve, request_changes, or comment)")
+	}
+	return v, v.Validate()
+}
+
+// parseReviewComment parses "path:line: text", "path: text", or "text".
+func parseReviewComment(s string) refinery.ReviewComment {
+	parts := strings.SplitN(s, ":", 3)
+	if len(parts) == 3 {
+		if line, err := strconv.Atoi(strings.TrimSpace(parts[1])); err == nil {
+			return refinery.ReviewComment{Path: strings.TrimSpace(parts[0]), Line: line, Body: strings.TrimSpace(parts[2])}
+		}
+	}
+	if len(parts) >= 2 && !strings.ContainsAny(parts[0], " \t") {
+		return refinery.ReviewComment{Path: parts[0], Body: strings.TrimSpace(strings.Join(parts[1:], ":"))}
+	}
+	return refinery.ReviewComment{Body: strings.TrimSpace(s)}
+}
+
+func runMQRequestReview(cmd *cobra.Command, args []string) error {
+	rigName, mrID := args[0], args[1]
+	eng, err := loadRigEngineer(rigName)
+	if err != nil {
+		return err
+	}
+	if err := eng.RequestReview(mrID); err != nil {
+		return err
+	}
+	fmt.Printf("%s %s is awaiting review\n", style.Bold.Render("✓"), mrID)
+	return nil
+}
+
+// loadRigEngineer returns a rig's refinery engineer with its merge queue
+// config loaded.
+func loadRigEngineer(rigName string) (*refinery.Engineer, error) {
+	_, r, _, err := getRefineryManager(rigName)
+	if err != nil {
+		return nil, err
+	}
+	eng := refinery.NewEngineer(r)
+	if err := eng.LoadConfig(); err != nil {
+		return nil, fmt.Errorf("loading merge queue config: %w", err)
+	}
+	return eng, nil
+}
+
+// reviewFieldsForSubmit returns the review line for a new MR's description,
+// or "" if the rig doesn't require review for MRs into target.
+func reviewFieldsForSubmit(rigName, target string) string {
+	eng, err := loadRigEngineer(rigName)
+	if err != nil || !eng.ConfigFor(target).RequireReview {
+		return ""
+	}
+	return "\nreview: " + refinery.ReviewRequested
+}
+
+// rerequestReview puts a resubmitted MR back in review after changes were
+// requested on it.
+func rerequestReview(rigName string, mr *beads.Issue) {
+	fields := beads.ParseMRFields(mr)
+	if fields == nil || fields.Review != refinery.ReviewChangesRequested {
+		return
+	}
+	eng, err := loadRigEngineer(rigName)
+	if err != nil {
+		return
+	}
+	if err := eng.RequestReview(mr.ID); err != nil {
+		style.PrintWarning("could not request review again: %v", err)
+		return
+	}
+	fmt.Printf("%s Review requested again\n", style.Bold.Render("✓"))
+}
+
+// routeToCodeOwners routes a submitted MR to the code owners of the paths it
+// touches, returning those owners. Failures are warnings: the refinery
+// checks ownership again before merging.
+func routeToCodeOwners(rigName string, bd *beads.Beads, g *git.Git, mrID, target, branch string) []string {
+	_, r, _, err := getRefineryManager(rigName)
+	if err != nil {
+		return nil
+	}
+	owners, err := refinery.RequiredOwners(g, r.Path, "origin/"+target, branch)
+	if err != nil {
+		style.PrintWarning("could not check code owners: %v", err)
+		return nil
+	}
+	if len(owners) == 0 {
+		return nil
+	}
+	mr, err := bd.Show(mrID)
+	if err == nil {
+		err = refinery.NewEngineer(r).RouteToOwners(mr, owners)
+	}
+	if err != nil {
+		style.PrintWarning("could not route to code owners: %v", err)
+	}
+	return owners
+}
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/refinery"
+)
+
+func TestParseReviewComment(t *testing.T) {
+	tests := []struct {
+		in   string
+		want refinery.ReviewComment
+	}{
+		{"auth.go:42: token is never checked", refinery.ReviewComment{Path: "auth.go", Line: 42, Body: "token is never checked"}},
+		{"README.md: typo", refinery.ReviewComment{Path: "README.md", Body: "typo"}},
+		{"needs a changelog entry", refinery.ReviewComment{Body: "needs a changelog entry"}},
+		{"please fix: it breaks", refinery.ReviewComment{Body: "please fix: it breaks"}},
+	}
+	for _, tt := range tests {
+		if got := parseReviewComment(tt.in); got != tt.want {
+			t.Errorf("parseReviewComment(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
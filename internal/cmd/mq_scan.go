@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/secscan"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// MQ scan command flags
+var (
+	mqScanBaseline string
+	mqScanNoRecord bool
+	mqScanNoFile   bool
+	mqScanJSON     bool
+)
+
+var mqScanCmd = &cobra.Command{
+	Use:   "scan <rig> [mr-id]",
+	Short: "Run static analysis and security scanners on a merge request",
+	Long: `Run the rig's scanners and judge the findings a merge request introduces.
+
+Each scanner in merge_queue.security_scan is run in the refinery clone,
+which should have the MR's branch checked out. Findings the target
+branch's recorded findings don't account for are judged against the
+severity thresholds:
+
+  "merge_queue": {
+    "security_scan": {
+      "scanners": [
+        {"name": "gosec"},
+        {"name": "semgrep", "fail_on": "critical"},
+        {"name": "codeql", "command": "./scripts/codeql.sh", "format": "sarif"}
+      ],
+      "fail_on": "high",
+      "file_on": "medium"
+    }
+  }
+
+gosec and semgrep have default commands; other scanners must write SARIF
+(or gosec/semgrep JSON) to stdout. A new finding at or above fail_on
+(default high) blocks the merge. Findings below it are deferred: they
+land unfixed, and those at or above file_on are filed as bug beads labeled
+security-finding, one per scanner, rule, and file.
+
+The result is recorded on the MR bead as the security_scan field (passed,
+deferred, or blocked) with the findings in a security-scan section. Exits
+1 when blocked. With security_scan configured, the refinery runs this gate
+itself before merging.
+
+With --baseline <branch> and no MR, the findings of the current checkout
+are recorded as that branch's baseline (run after merging to main).
+
+Examples:
+  gt mq scan gastown gt-mr-abc
+  gt mq scan gastown gt-mr-abc --no-record --no-file --json
+  gt mq scan gastown --baseline main`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runMQScan,
+}
+
+func init() {
+	mqScanCmd.Flags().StringVar(&mqScanBaseline, "baseline", "", "Record the findings of the current checkout as this branch's baseline")
+	mqScanCmd.Flags().BoolVar(&mqScanNoRecord, "no-record", false, "Don't update the MR bead")
+	mqScanCmd.Flags().BoolVar(&mqScanNoFile, "no-file", false, "Don't file beads for deferred findings")
+	mqScanCmd.Flags().BoolVar(&mqScanJSON, "json", false, "Output as JSON")
+
+	mqCmd.AddCommand(mqScanCmd)
+}
+
+func runMQScan(cmd *cobra.Command, args []string) error {
+	rigName := args[0]
+
+	if (len(args) == 2) == (mqScanBaseline != "") {
+		return fmt.Errorf("specify either an MR ID or --baseline <branch>")
+	}
+
+	_, r, _, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+
+	eng := refinery.NewEngineer(r)
+	if err := eng.LoadConfig(); err != nil {
+		return fmt.Errorf("loading merge queue config: %w", err)
+	}
+	if mqScanJSON {
+		eng.SetOutput(os.Stderr)
+	}
+
+	if mqScanBaseline != "" {
+		return securityBaseline(eng, mqScanBaseline)
+	}
+
+	mrID := args[1]
+	issue, err := beads.New(r.Path).Show(mrID)
+	if err != nil {
+		return fmt.Errorf("fetching merge request %s: %w", mrID, err)
+	}
+	target := eng.Config().TargetBranch
+	if fields := beads.ParseMRFields(issue); fields != nil && fields.Target != "" {
+		target = fields.Target
+	}
+
+	report, err := eng.ScanSecurity(context.Background(), target)
+	if err != nil {
+		return err
+	}
+
+	if !mqScanNoRecord {
+		if err := eng.RecordSecurityScan(mrID, report); err != nil {
+			return err
+		}
+	}
+	var filed []string
+	if !report.Blocked && !mqScanNoFile {
+		if filed, err = eng.FileDeferredFindings(mrID, report); err != nil {
+			return err
+		}
+	}
+
+	if mqScanJSON {
+		if err := outputJSON(report); err != nil {
+			return err
+		}
+	} else {
+		printSecurityReport(mrID, report, filed)
+	}
+	if report.Blocked {
+		return NewSilentExit(1)
+	}
+	return nil
+}
+
+// printSecurityReport prints the findings of a security scan.
+func printSecurityReport(mrID string, report *secscan.Report, filed []string) {
+	icon := style.Success.Render("✓")
+	switch report.Status() {
+	case "blocked":
+		icon = style.Error.Render("✗")
+	case "deferred":
+		icon = style.Warning.Render("⚠")
+	}
+	fmt.Printf("%s %s: security scan %s (%d new findings)\n", icon, mrID, report.Status(), len(report.Findings))
+	if !report.Baseline {
+		fmt.Printf("  %s\n", style.Dim.Render("No target baseline; every finding was judged"))
+	}
+	for _, f := range report.Findings {
+		line := fmt.Sprintf("%s %s/%s at %s: %s", f.Severity, f.Scanner, f.Rule, f.Location(), f.Message)
+		if f.Blocking {
+			fmt.Printf("  %s\n", style.Error.Render(line))
+		} else {
+			fmt.Printf("  %s\n", line)
+		}
+	}
+	for _, id := range filed {
+		fmt.Printf("  Filed %s for a deferred finding\n", id)
+	}
+}
+
+// securityBaseline records the findings of the current checkout for a
+// branch.
+func securityBaseline(eng *refinery.Engineer, branch string) error {
+	runs, findings, err := eng.RunScanners(context.Background())
+	if err != nil {
+		return err
+	}
+	if err := eng.SaveSecurityBaseline(branch, findings); err != nil {
+		return fmt.Errorf("saving baseline: %w", err)
+	}
+
+	if mqScanJSON {
+		return outputJSON(map[string]interface{}{"branch": branch, "scanners": runs, "findings": len(findings)})
+	}
+	fmt.Printf("%s Security baseline for %s: %d findings from %d scanners\n",
+		style.Success.Render("✓"), branch, len(findings), len(runs))
+	return nil
+}
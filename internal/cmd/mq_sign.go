@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+var mqSignCmd = &cobra.Command{
+	Use:   "sign <rig> <mr-id>",
+	Short: "Sign a merge request's commits as the refinery before landing",
+	Long: `Re-sign the commits the refinery is about to land, as the refinery.
+
+Run in the refinery clone with the MR's tested branch checked out, just
+before fast-forwarding the target. Every commit since the target is
+recreated with the refinery as committer and signed with its key; authors,
+messages, and trees are unchanged. The signer identity and key fingerprint
+are recorded on the MR bead as signer and signing_key.
+
+Configure signing in the rig's config.json:
+
+  "merge_queue": {
+    "signing": {
+      "format": "ssh",
+      "key": "/path/to/refinery_signing_key",
+      "email": "refinery@example.com"
+    }
+  }
+
+An empty "signing": {} uses the refinery's own ssh key; create it with
+'gt refinery signing-key <rig>'. Check landings with 'gt verify'.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runMQSign,
+}
+
+func init() {
+	mqCmd.AddCommand(mqSignCmd)
+}
+
+func runMQSign(cmd *cobra.Command, args []string) error {
+	rigName, mrID := args[0], args[1]
+	_, r, _, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+	eng := refinery.NewEngineer(r)
+	if err := eng.LoadConfig(); err != nil {
+		return fmt.Errorf("loading merge queue config: %w", err)
+	}
+
+	issue, err := beads.New(r.Path).Show(mrID)
+	if err != nil {
+		return fmt.Errorf("fetching merge request %s: %w", mrID, err)
+	}
+	target := eng.Config().TargetBranch
+	if fields := beads.ParseMRFields(issue); fields != nil && fields.Target != "" {
+		target = fields.Target
+	}
+
+	head, err := eng.SignLanding(mrID, target)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s Signed %s for %s as %s\n", style.Success.Render("✓"), mrID, target, head.Signer)
+	fmt.Printf("  Head: %s  Key: %s\n", shortSHA(head.SHA), style.Dim.Render(head.Key))
+	return nil
+}
@@ -1,15 +1,16 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
-	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/deploy"
+	"github.com/steveyegge/gastown/internal/refinery"
 	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/timefmt"
 )
 
 // MRStatusOutput is the JSON output structure for gt mq status.
@@ -34,6 +35,21 @@ type MRStatusOutput struct {
 	MergeCommit string `json:"merge_commit,omitempty"`
 	CloseReason string `json:"close_reason,omitempty"`
 
+	// Size and coverage (recorded by gt mq measure)
+	DiffFiles     int    `json:"diff_files,omitempty"`
+	LinesAdded    int    `json:"lines_added,omitempty"`
+	LinesDeleted  int    `json:"lines_deleted,omitempty"`
+	Coverage      string `json:"coverage,omitempty"`
+	CoverageDelta string `json:"coverage_delta,omitempty"`
+
+	// Change summary (recorded by gt mq summary)
+	APIChanges      int    `json:"api_changes,omitempty"`
+	BreakingChanges int    `json:"breaking_changes,omitempty"`
+	ChangeSummary   string `json:"change_summary,omitempty"`
+
+	// Environments the merged MR is live in (recorded by gt deploy)
+	Deployments []deploy.EnvStatus `json:"deployments,omitempty"`
+
 	// Dependencies
 	DependsOn []DependencyInfo `json:"depends_on,omitempty"`
 	Blocks    []DependencyInfo `json:"blocks,omitempty"`
@@ -95,6 +111,24 @@ func runMqStatus(cmd *cobra.Command, args []string) error {
 		output.Rig = mrFields.Rig
 		output.MergeCommit = mrFields.MergeCommit
 		output.CloseReason = mrFields.CloseReason
+		output.DiffFiles = mrFields.DiffFiles
+		output.LinesAdded = mrFields.LinesAdded
+		output.LinesDeleted = mrFields.LinesDeleted
+		output.Coverage = mrFields.Coverage
+		output.CoverageDelta = mrFields.CoverageDelta
+		output.APIChanges = mrFields.APIChanges
+		output.BreakingChanges = mrFields.BreakingChanges
+	}
+	output.ChangeSummary = beads.GetDescriptionSection(issue.Description, refinery.ChangeSummarySection)
+
+	// Deployment status is best effort: the MR may belong to a rig without
+	// deployment tracking, or we may not be in a workspace.
+	var deployStatus *BeadDeployStatus
+	if mrFields != nil && mrFields.MergeCommit != "" {
+		if s, err := beadDeployStatus(issue.ID); err == nil && len(s.MRs) > 0 {
+			deployStatus = s
+			output.Deployments = s.MRs[0].Environments
+		}
 	}
 
 	// Add dependency info from the issue's Dependencies field
@@ -121,17 +155,15 @@ func runMqStatus(cmd *cobra.Command, args []string) error {
 
 	// JSON output
 	if mqStatusJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(output)
+		return outputJSON(output)
 	}
 
 	// Human-readable output
-	return printMqStatus(issue, mrFields)
+	return printMqStatus(issue, mrFields, deployStatus)
 }
 
 // printMqStatus prints detailed MR status in human-readable format.
-func printMqStatus(issue *beads.Issue, mrFields *beads.MRFields) error {
+func printMqStatus(issue *beads.Issue, mrFields *beads.MRFields, deployStatus *BeadDeployStatus) error {
 	// Header
 	fmt.Printf("%s %s\n", style.Bold.Render("📋 Merge Request:"), issue.ID)
 	fmt.Printf("   %s\n\n", issue.Title)
@@ -184,6 +216,39 @@ func printMqStatus(issue *beads.Issue, mrFields *beads.MRFields) error {
 		if mrFields.CloseReason != "" {
 			fmt.Printf("   Close Reason: %s\n", mrFields.CloseReason)
 		}
+
+		if mrFields.DiffFiles > 0 || mrFields.Coverage != "" {
+			fmt.Printf("\n%s\n", style.Bold.Render("Size"))
+			if mrFields.DiffFiles > 0 {
+				fmt.Printf("   Diff:     %s\n", formatMRSize(mrFields.DiffFiles, mrFields.LinesAdded, mrFields.LinesDeleted))
+			}
+			if mrFields.Coverage != "" {
+				fmt.Printf("   Coverage: %s\n", formatCoverage(mrFields.Coverage, mrFields.CoverageDelta))
+			}
+		}
+	}
+
+	// Change summary (recorded by gt mq summary)
+	if summary := beads.GetDescriptionSection(issue.Description, refinery.ChangeSummarySection); summary != "" {
+		fmt.Printf("\n%s\n", style.Bold.Render("Change Summary"))
+		for _, line := range strings.Split(summary, "\n") {
+			fmt.Printf("   %s\n", line)
+		}
+	}
+
+	// Deployments (recorded by gt deploy)
+	if deployStatus != nil && len(deployStatus.MRs[0].Environments) > 0 {
+		fmt.Printf("\n%s\n", style.Bold.Render("Deployments"))
+		for _, env := range deployStatus.MRs[0].Environments {
+			switch {
+			case env.Error != "":
+				fmt.Printf("   %s %s\n", style.Warning.Render("?"), env.Environment)
+			case env.Live:
+				fmt.Printf("   %s %s\n", style.Success.Render("✓"), env.Environment)
+			default:
+				fmt.Printf("   %s %s %s\n", style.Error.Render("✗"), env.Environment, style.Dim.Render("(not yet deployed)"))
+			}
+		}
 	}
 
 	// Dependencies (what this MR is waiting on)
@@ -212,8 +277,15 @@ func printMqStatus(issue *beads.Issue, mrFields *beads.MRFields) error {
 		}
 	}
 
+	// Discussion threads from 'gt mq comment'
+	if threads := refinery.CommentThreads(refinery.ParseMRComments(issue.Description)); len(threads) > 0 {
+		fmt.Printf("\n%s\n", style.Bold.Render("Discussion"))
+		printCommentThreads(threads, "   ")
+	}
+
 	// Description (if present and not just MR fields)
-	desc := getDescriptionWithoutMRFields(issue.Description)
+	desc := beads.SetDescriptionSection(issue.Description, refinery.ChangeSummarySection, "")
+	desc = getDescriptionWithoutMRFields(beads.SetDescriptionSection(desc, refinery.CommentsSection, ""))
 	if desc != "" {
 		fmt.Printf("\n%s\n", style.Bold.Render("Notes"))
 		// Indent each line
@@ -255,55 +327,16 @@ func getStatusIcon(status string) string {
 
 // formatTimeAgo formats a timestamp as a relative time string.
 func formatTimeAgo(timestamp string) string {
-	// Try parsing common formats
-	formats := []string{
-		time.RFC3339,
-		"2006-01-02T15:04:05Z",
-		"2006-01-02T15:04:05",
-		"2006-01-02 15:04:05",
-		"2006-01-02",
-	}
-
-	var t time.Time
-	var err error
-	for _, format := range formats {
-		t, err = time.Parse(format, timestamp)
-		if err == nil {
-			break
-		}
-	}
-	if err != nil {
+	ago := timefmt.AgoString(timestamp)
+	if ago == "" {
 		return "" // Can't parse, return empty
 	}
-
-	d := time.Since(t)
-	if d < 0 {
-		return style.Dim.Render("(in the future)")
-	}
-
-	var ago string
-	if d < time.Minute {
-		ago = fmt.Sprintf("%ds ago", int(d.Seconds()))
-	} else if d < time.Hour {
-		ago = fmt.Sprintf("%dm ago", int(d.Minutes()))
-	} else if d < 24*time.Hour {
-		ago = fmt.Sprintf("%dh ago", int(d.Hours()))
-	} else {
-		ago = fmt.Sprintf("%dd ago", int(d.Hours()/24))
-	}
-
 	return style.Dim.Render("(" + ago + ")")
 }
 
 // truncateString truncates a string to maxLen, adding "..." if truncated.
 func truncateString(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
-	}
-	if maxLen <= 3 {
-		return s[:maxLen]
-	}
-	return s[:maxLen-3] + "..."
+	return style.Truncate(s, maxLen)
 }
 
 // getDescriptionWithoutMRFields returns the description with MR field lines removed.
@@ -328,6 +361,18 @@ func getDescriptionWithoutMRFields(description string) string {
 		"close-reason": true,
 		"closereason":  true,
 		"type":         true,
+
+		// Recorded by the refinery; shown in their own sections
+		"test_triage":      true,
+		"failed_tests":     true,
+		"flaky_tests":      true,
+		"diff_files":       true,
+		"lines_added":      true,
+		"lines_deleted":    true,
+		"coverage":         true,
+		"coverage_delta":   true,
+		"api_changes":      true,
+		"breaking_changes": true,
 	}
 
 	var lines []string
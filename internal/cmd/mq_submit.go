@@ -6,13 +6,16 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/ci"
 	"github.com/steveyegge/gastown/internal/constants"
 	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/refinery"
 	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -86,6 +89,9 @@ func runMqSubmit(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	if err := checkRigNotArchived(townRoot, rigName); err != nil {
+		return fmt.Errorf("merge queue closed: %w", err)
+	}
 
 	// Initialize git for the current directory
 	cwd, err := os.Getwd()
@@ -132,7 +138,20 @@ func runMqSubmit(cmd *cobra.Command, args []string) error {
 
 	// Determine target branch
 	target := defaultBranch
-	if mqSubmitEpic != "" {
+	if mqSubmitTarget != "" {
+		// Explicit target, e.g. a release branch for maintenance work
+		if mqSubmitTarget == branch {
+			return fmt.Errorf("cannot submit %s into itself", branch)
+		}
+		exists, err := g.RemoteBranchExists("origin", mqSubmitTarget)
+		if err != nil {
+			return fmt.Errorf("checking target branch: %w", err)
+		}
+		if !exists {
+			return fmt.Errorf("target branch %s does not exist on origin", mqSubmitTarget)
+		}
+		target = mqSubmitTarget
+	} else if mqSubmitEpic != "" {
 		// Explicit --epic flag takes precedence
 		target = "integration/" + mqSubmitEpic
 	} else {
@@ -146,14 +165,17 @@ func runMqSubmit(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	warnForeignFiles(townRoot, rigName, g, target, branch)
+	warnOverlappingWork(rigName, g, target, branch, worker)
+
 	// Get source issue for priority inheritance
+	sourceIssue, sourceErr := bd.Show(issueID)
 	var priority int
 	if mqSubmitPriority >= 0 {
 		priority = mqSubmitPriority
 	} else {
 		// Try to inherit from source issue
-		sourceIssue, err := bd.Show(issueID)
-		if err != nil {
+		if sourceErr != nil {
 			// Issue not found, use default priority
 			priority = 2
 		} else {
@@ -161,6 +183,13 @@ func runMqSubmit(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Hotfixes merge through queue freezes; inherit the label from the issue
+	var labels []string
+	hotfix := mqSubmitHotfix || (sourceErr == nil && beads.HasLabel(sourceIssue, beads.HotfixLabel))
+	if hotfix {
+		labels = append(labels, beads.HotfixLabel)
+	}
+
 	// Build MR bead title and description
 	title := fmt.Sprintf("Merge: %s", issueID)
 	description := fmt.Sprintf("branch: %s\ntarget: %s\nsource_issue: %s\nrig: %s",
@@ -168,6 +197,15 @@ func runMqSubmit(cmd *cobra.Command, args []string) error {
 	if worker != "" {
 		description += fmt.Sprintf("\nworker: %s", worker)
 	}
+	description += reviewFieldsForSubmit(rigName, target)
+	if len(mqSubmitDependsOn) > 0 {
+		for _, dep := range mqSubmitDependsOn {
+			if _, err := bd.Show(dep); err != nil {
+				return fmt.Errorf("--depends-on %s: %w", dep, err)
+			}
+		}
+		description += "\ndepends_on: " + strings.Join(mqSubmitDependsOn, ", ")
+	}
 
 	// Check if MR bead already exists for this branch (idempotency)
 	var mrIssue *beads.Issue
@@ -178,6 +216,7 @@ func runMqSubmit(cmd *cobra.Command, args []string) error {
 	} else if existingMR != nil {
 		mrIssue = existingMR
 		fmt.Printf("%s MR already exists (idempotent)\n", style.Bold.Render("✓"))
+		rerequestReview(rigName, existingMR)
 	} else {
 		// Create MR bead (ephemeral wisp - will be cleaned up after merge)
 		mrIssue, err = bd.Create(beads.CreateOptions{
@@ -185,6 +224,7 @@ func runMqSubmit(cmd *cobra.Command, args []string) error {
 			Type:        "merge-request",
 			Priority:    priority,
 			Description: description,
+			Labels:      labels,
 			Ephemeral:   true,
 		})
 		if err != nil {
@@ -194,6 +234,10 @@ func runMqSubmit(cmd *cobra.Command, args []string) error {
 		// Nudge refinery to pick up the new MR
 		nudgeRefinery(rigName, fmt.Sprintf("MR submitted: %s branch=%s", mrIssue.ID, branch))
 	}
+	owners := routeToCodeOwners(rigName, bd, g, mrIssue.ID, target, branch)
+	if head, err := g.Rev(branch); err == nil {
+		publishMRStatus(rigName, mrIssue.ID, head, ci.Pending, "Queued in the merge queue as "+mrIssue.ID)
+	}
 
 	// Success output
 	fmt.Printf("%s Submitted to merge queue\n", style.Bold.Render("✓"))
@@ -205,6 +249,22 @@ func runMqSubmit(cmd *cobra.Command, args []string) error {
 		fmt.Printf("  Worker: %s\n", worker)
 	}
 	fmt.Printf("  Priority: P%d\n", priority)
+	if len(owners) > 0 {
+		fmt.Printf("  Owners: %s\n", strings.Join(owners, ", "))
+	}
+	if len(mqSubmitDependsOn) > 0 {
+		fmt.Printf("  Depends on: %s\n", strings.Join(mqSubmitDependsOn, ", "))
+	}
+
+	if !hotfix && !beads.HasLabel(mrIssue, beads.HotfixLabel) {
+		warnIfQueueFrozen(rigName)
+	}
+
+	if mqSubmitWait {
+		if err := waitForMR(bd, mrIssue); err != nil {
+			return err
+		}
+	}
 
 	// Auto-cleanup for polecats: if this is a polecat branch and cleanup not disabled,
 	// send lifecycle request and wait for termination
@@ -223,6 +283,45 @@ func runMqSubmit(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// waitForMR blocks until the refinery merges the MR or it fails, polling
+// its bead.
+func waitForMR(bd *beads.Beads, mr *beads.Issue) error {
+	fmt.Printf("\n%s Waiting for %s to merge...\n", style.Dim.Render("◌"), mr.ID)
+	baseline := beads.ParseMRFields(mr)
+	start := time.Now()
+	err := pollUntil(15*time.Second, mqSubmitTimeout, func() (bool, error) {
+		issue, err := bd.Show(mr.ID)
+		if err != nil {
+			return false, fmt.Errorf("checking %s: %w", mr.ID, err)
+		}
+		done, failure := mrWaitOutcome(issue, baseline)
+		if failure != "" {
+			return false, &waitFailedError{reason: failure}
+		}
+		return done, nil
+	})
+	if err == nil {
+		fmt.Printf("%s Merged %s (after %s)\n", style.Bold.Render("✓"), mr.ID, time.Since(start).Round(time.Second))
+	}
+	return waitExit(err, mr.ID, mqSubmitTimeout)
+}
+
+// warnIfQueueFrozen warns that a newly submitted MR won't merge while the
+// rig's queue is frozen.
+func warnIfQueueFrozen(rigName string) {
+	_, r, err := getRig(rigName)
+	if err != nil {
+		return
+	}
+	freeze, err := rigQueueFreeze(r)
+	if err != nil || !freeze.Frozen() {
+		return
+	}
+	fmt.Println()
+	style.PrintWarning("merge queue is frozen: %s", freeze.Reason())
+	fmt.Printf("  %s\n", style.Dim.Render("This MR will wait until the freeze lifts (use --hotfix for urgent fixes)"))
+}
+
 // detectIntegrationBranch checks if an issue is a descendant of an epic that has an integration branch.
 // Traverses up the parent chain until it finds an epic or runs out of parents.
 // Returns the integration branch target (e.g., "integration/gt-epic") if found, or "" if not.
@@ -332,3 +431,69 @@ Please verify state and execute lifecycle action.
 		}
 	}
 }
+
+// warnForeignFiles warns when a monorepo sub-rig's branch changes files that
+// other sub-rigs own, since the refinery will reject the MR.
+func warnForeignFiles(townRoot, rigName string, g *git.Git, target, branch string) {
+	mgr, r, err := loadRigManager(townRoot, rigName)
+	if err != nil {
+		return
+	}
+	siblings := mgr.Siblings(r)
+	if len(siblings) < 2 {
+		return
+	}
+	changes, err := g.ChangedFiles("origin/"+target, branch)
+	if err != nil {
+		return
+	}
+	var files []string
+	for _, c := range changes {
+		files = append(files, c.Path)
+	}
+	foreign := rig.ForeignFiles(siblings, r, files)
+	if len(foreign) == 0 {
+		return
+	}
+	owners := make(map[string]int)
+	for _, f := range foreign {
+		owners[f.Owner]++
+	}
+	var parts []string
+	for owner, n := range owners {
+		parts = append(parts, fmt.Sprintf("%d owned by %s", n, owner))
+	}
+	sort.Strings(parts)
+	style.PrintWarning("%d changed file(s) are outside rig %s's subtree (%s); the refinery will reject this MR. See 'gt rig owner'.",
+		len(foreign), rigName, strings.Join(parts, ", "))
+}
+
+// warnOverlappingWork warns when the branch changes files that other open
+// MRs or agent branches into the same target also change, suggesting how
+// to sequence them.
+func warnOverlappingWork(rigName string, g *git.Git, target, branch, worker string) {
+	changes, err := g.ChangedFiles("origin/"+target, branch)
+	if err != nil || len(changes) == 0 {
+		return
+	}
+	eng, err := loadRigEngineer(rigName)
+	if err != nil {
+		return
+	}
+	ef := refinery.Effort{Branch: branch, Target: target, Worker: worker}
+	for _, c := range changes {
+		ef.Files = append(ef.Files, c.Path)
+	}
+	overlaps, err := eng.ConflictsFor(ef)
+	if err != nil {
+		style.PrintWarning("could not check for overlapping work: %v", err)
+		return
+	}
+	for _, o := range overlaps {
+		other := o.First
+		if other.Branch == branch {
+			other = o.Second
+		}
+		style.PrintWarning("%s also changes %s; %s", effortLabel(other), summarizeFiles(o.Files, 3), o.Suggestion())
+	}
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// MQ summary command flags
+var (
+	mqSummaryNoRecord bool
+	mqSummaryJSON     bool
+)
+
+var mqSummaryCmd = &cobra.Command{
+	Use:   "summary <rig> <mr-id>",
+	Short: "Attach a semantic change summary to a merge request",
+	Long: `Summarize what a merge request changes instead of reading the raw diff.
+
+The summary lists the files changed, the top-level Go declarations added,
+removed, or modified, and the package API changes: exported symbols that
+were added, removed, or had their signature changed. Removals and signature
+changes are counted as breaking. Body-only and formatting-only edits are not
+API changes.
+
+The summary is recorded in the MR bead's description (shown by 'gt mq
+status') along with api_changes and breaking_changes fields. Set
+merge_queue.semantic_summary in the rig's config.json to have the refinery
+attach summaries automatically.
+
+Examples:
+  gt mq summary gastown gt-mr-abc
+  gt mq summary gastown gt-mr-abc --json --no-record`,
+	Args: cobra.ExactArgs(2),
+	RunE: runMQSummary,
+}
+
+func init() {
+	mqSummaryCmd.Flags().BoolVar(&mqSummaryNoRecord, "no-record", false, "Don't update the MR bead")
+	mqSummaryCmd.Flags().BoolVar(&mqSummaryJSON, "json", false, "Output as JSON")
+
+	mqCmd.AddCommand(mqSummaryCmd)
+}
+
+func runMQSummary(cmd *cobra.Command, args []string) error {
+	rigName, mrID := args[0], args[1]
+
+	_, r, _, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+
+	eng := refinery.NewEngineer(r)
+	if err := eng.LoadConfig(); err != nil {
+		return fmt.Errorf("loading merge queue config: %w", err)
+	}
+
+	issue, err := beads.New(r.Path).Show(mrID)
+	if err != nil {
+		return fmt.Errorf("fetching merge request %s: %w", mrID, err)
+	}
+	fields := beads.ParseMRFields(issue)
+	if fields == nil || fields.Branch == "" {
+		return fmt.Errorf("%s has no branch field; is it a merge request?", mrID)
+	}
+	target := fields.Target
+	if target == "" {
+		target = eng.Config().TargetBranch
+	}
+
+	summary, err := eng.SummarizeMR(fields.Branch, target)
+	if err != nil {
+		return err
+	}
+
+	if !mqSummaryNoRecord {
+		if err := eng.RecordSummary(mrID, summary); err != nil {
+			return err
+		}
+	}
+
+	if mqSummaryJSON {
+		return outputJSON(summary)
+	}
+
+	fmt.Printf("%s Change summary for %s (%s...%s)\n\n", style.Bold.Render("📋"), mrID, summary.Base, summary.Branch)
+	fmt.Println(summary.Markdown(0))
+	return nil
+}
@@ -755,7 +755,7 @@ func TestMRFilteringByLabel(t *testing.T) {
 			issue: &beads.Issue{
 				ID:     "mr-1",
 				Title:  "Merge: test-branch",
-				Type:   "task", // Wrong type (default from bd create)
+				Type:   "task",                       // Wrong type (default from bd create)
 				Labels: []string{"gt:merge-request"}, // Correct label
 			},
 			wantIsMR: true,
@@ -801,3 +801,29 @@ func TestMRFilteringByLabel(t *testing.T) {
 		})
 	}
 }
+
+func TestParseSubmoduleLink(t *testing.T) {
+	tests := []struct {
+		spec                         string
+		wantMR, wantPath, wantTracks string
+		wantErr                      bool
+	}{
+		{spec: "app-mr-1=vendor/lib:lib-mr-2", wantMR: "app-mr-1", wantPath: "vendor/lib", wantTracks: "lib-mr-2"},
+		{spec: "app-mr-1=vendor/lib/:lib-mr-2", wantMR: "app-mr-1", wantPath: "vendor/lib", wantTracks: "lib-mr-2"},
+		{spec: "app-mr-1=vendor/lib", wantErr: true},
+		{spec: "vendor/lib:lib-mr-2", wantErr: true},
+		{spec: "=vendor/lib:lib-mr-2", wantErr: true},
+		{spec: "app-mr-1=:lib-mr-2", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			mr, path, tracks, err := parseSubmoduleLink(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if mr != tt.wantMR || path != tt.wantPath || tracks != tt.wantTracks {
+				t.Errorf("got (%q, %q, %q), want (%q, %q, %q)", mr, path, tracks, tt.wantMR, tt.wantPath, tt.wantTracks)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/testtriage"
+)
+
+// MQ triage command flags
+var (
+	mqTriageOutputs  []string
+	mqTriagePassed   bool
+	mqTriageRun      bool
+	mqTriageNoRecord bool
+	mqTriageJSON     bool
+)
+
+var mqTriageCmd = &cobra.Command{
+	Use:   "triage <rig> <mr-id>",
+	Short: "Triage test failures for a merge request",
+	Long: `Parse test output, classify failures, and attach them to an MR bead.
+
+Each --output file is one failed test attempt (command output, or a JUnit
+XML report when test_output_format is "junit"). Pass --passed if a later
+retry succeeded. Tests failing on every attempt are classified as real;
+tests failing on only some attempts are flaky. Tests in the rig's quarantine
+registry (see 'gt flaky') are handled per quarantine_policy.
+
+With --run, the rig's test_command is run directly (with retry_flaky_tests
+attempts) in the refinery clone instead of reading output files.
+
+The result is recorded on the MR bead as test_triage, failed_tests, and
+flaky_tests fields. If file_flake_beads is enabled in the rig's merge_queue
+config, a bug bead labeled gt:flaky-test is filed for each new flaky test.
+
+Examples:
+  go test ./... 2>&1 | tee /tmp/run1.log
+  gt mq triage gastown gt-mr-abc --output /tmp/run1.log
+  gt mq triage gastown gt-mr-abc --output run1.log --output run2.log --passed
+  gt mq triage gastown gt-mr-abc --run --json`,
+	Args: cobra.ExactArgs(2),
+	RunE: runMQTriage,
+}
+
+func init() {
+	mqTriageCmd.Flags().StringArrayVar(&mqTriageOutputs, "output", nil, "Test output file for a failed attempt (repeatable)")
+	mqTriageCmd.Flags().BoolVar(&mqTriagePassed, "passed", false, "A retry after the given attempts passed")
+	mqTriageCmd.Flags().BoolVar(&mqTriageRun, "run", false, "Run the configured test command instead of reading output files")
+	mqTriageCmd.Flags().BoolVar(&mqTriageNoRecord, "no-record", false, "Don't update the MR bead or file flaky-test beads")
+	mqTriageCmd.Flags().BoolVar(&mqTriageJSON, "json", false, "Output as JSON")
+
+	mqCmd.AddCommand(mqTriageCmd)
+}
+
+func runMQTriage(cmd *cobra.Command, args []string) error {
+	rigName, mrID := args[0], args[1]
+
+	if mqTriageRun == (len(mqTriageOutputs) > 0) {
+		return fmt.Errorf("specify either --run or at least one --output file")
+	}
+
+	_, r, _, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+
+	eng := refinery.NewEngineer(r)
+	if err := eng.LoadConfig(); err != nil {
+		return fmt.Errorf("loading merge queue config: %w", err)
+	}
+	if mqTriageJSON {
+		eng.SetOutput(os.Stderr)
+	}
+
+	var report *testtriage.Report
+	if mqTriageRun {
+		result := eng.RunTests(context.Background())
+		if result.Triage == nil {
+			return fmt.Errorf("running tests: %s", result.Error)
+		}
+		report = result.Triage
+	} else {
+		reg := eng.LoadQuarantine()
+		var attempts []testtriage.Attempt
+		var quarantined []testtriage.Failure
+		seen := make(map[string]bool)
+		for _, path := range mqTriageOutputs {
+			data, err := os.ReadFile(path) //nolint:gosec // G304: path is user-provided CLI argument
+			if err != nil {
+				return fmt.Errorf("reading test output: %w", err)
+			}
+			failures, err := eng.ParseTestFailures(data)
+			if err != nil {
+				return fmt.Errorf("parsing %s: %w", path, err)
+			}
+			attempt, q := eng.QuarantineAttempt(reg, failures)
+			attempts = append(attempts, attempt)
+			for _, f := range q {
+				if !seen[f.ID()] {
+					seen[f.ID()] = true
+					quarantined = append(quarantined, f)
+				}
+			}
+		}
+		if mqTriagePassed {
+			attempts = append(attempts, testtriage.Attempt{Passed: true})
+		}
+		report = testtriage.Classify(attempts)
+		report.Quarantined = quarantined
+	}
+
+	if !mqTriageNoRecord {
+		if err := eng.RecordTriage(mrID, report); err != nil {
+			return err
+		}
+	}
+
+	if mqTriageJSON {
+		return outputJSON(report)
+	}
+
+	printTriageReport(mrID, report)
+	return nil
+}
+
+func printTriageReport(mrID string, report *testtriage.Report) {
+	switch report.Class {
+	case testtriage.ClassReal:
+		fmt.Printf("%s %s: %s\n", style.Error.Render("✗"), mrID, report.Summary())
+	case testtriage.ClassFlaky:
+		fmt.Printf("%s %s: %s\n", style.Warning.Render("⚠"), mrID, report.Summary())
+	default:
+		fmt.Printf("%s %s: %s\n", style.Success.Render("✓"), mrID, report.Summary())
+	}
+
+	printTriageFailures("Real failures", report.Real)
+	printTriageFailures("Flaky", report.Flaky)
+	printTriageFailures("Quarantined", report.Quarantined)
+}
+
+func printTriageFailures(heading string, failures []testtriage.Failure) {
+	if len(failures) == 0 {
+		return
+	}
+	fmt.Printf("\n%s\n", style.Bold.Render(heading+":"))
+	for _, f := range failures {
+		fmt.Printf("  %s\n", f.ID())
+		if f.Message != "" {
+			fmt.Printf("    %s\n", style.Dim.Render(strings.SplitN(f.Message, "\n", 2)[0]))
+		}
+	}
+}
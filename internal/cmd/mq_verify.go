@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+var mqVerifyJSON bool
+
+var mqVerifyCmd = &cobra.Command{
+	Use:   "verify <rig> [mr-id]",
+	Short: "Run post-merge verification on recent landings",
+	Long: `Verify recently merged MRs with the rig's post-merge checks.
+
+Configure checks in the rig's config.json:
+
+  "merge_queue": {
+    "post_merge_command": "./scripts/smoke.sh",
+    "post_merge_health_url": "https://staging.example.com/healthz",
+    "post_merge_window": "30m",
+    "post_merge_auto_revert": true
+  }
+
+The smoke command runs once per landing in a scratch worktree at the merge
+commit, with GT_MERGE_COMMIT and GT_MR set. The health URL is polled until
+the window closes; any non-2xx response fails the landing. A failed landing
+is labeled post-merge-failed, the queue is frozen, and with
+post_merge_auto_revert a revert MR is queued as a hotfix.
+
+The daemon runs this every heartbeat. Use this command to verify now, or to
+verify a specific MR regardless of the window.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runMQVerify,
+}
+
+func init() {
+	mqVerifyCmd.Flags().BoolVar(&mqVerifyJSON, "json", false, "Output as JSON")
+	mqCmd.AddCommand(mqVerifyCmd)
+}
+
+func runMQVerify(cmd *cobra.Command, args []string) error {
+	rigName := args[0]
+	_, r, _, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+	eng := refinery.NewEngineer(r)
+	if err := eng.LoadConfig(); err != nil {
+		return fmt.Errorf("loading merge queue config: %w", err)
+	}
+	if !eng.PostMergeEnabled() {
+		return fmt.Errorf("post-merge verification is not configured for %s (set merge_queue.post_merge_command or post_merge_health_url)", rigName)
+	}
+
+	var pending []*beads.Issue
+	if len(args) > 1 {
+		mr, err := beads.New(r.BeadsPath()).Show(args[1])
+		if err != nil {
+			return fmt.Errorf("fetching merge request %s: %w", args[1], err)
+		}
+		pending = append(pending, mr)
+	} else if pending, err = eng.PendingLandings(time.Now()); err != nil {
+		return err
+	}
+
+	checks := []*refinery.LandingCheck{}
+	for _, mr := range pending {
+		check, err := eng.VerifyLanding(context.Background(), mr, time.Now())
+		if err != nil {
+			return fmt.Errorf("verifying %s: %w", mr.ID, err)
+		}
+		checks = append(checks, check)
+		if check.Verdict == refinery.PostMergeFailed {
+			_ = events.LogFeed(events.TypeLandingFailed, detectSender(), map[string]interface{}{
+				"rig":       rigName,
+				"mr":        mr.ID,
+				"commit":    check.Commit,
+				"revert_mr": check.RevertMR,
+			})
+			break
+		}
+	}
+
+	if mqVerifyJSON {
+		return outputJSON(checks)
+	}
+	if len(checks) == 0 {
+		fmt.Printf("%s No landings awaiting verification on '%s'\n", style.Dim.Render("○"), rigName)
+		return nil
+	}
+	for _, c := range checks {
+		switch c.Verdict {
+		case refinery.PostMergeFailed:
+			fmt.Printf("%s %s (%s) failed post-merge verification\n", style.Error.Render("✗"), c.MR, shortSHA(c.Commit))
+			if c.Output != "" {
+				for _, line := range strings.Split(strings.TrimSpace(c.Output), "\n") {
+					fmt.Printf("    %s\n", style.Dim.Render(line))
+				}
+			}
+			fmt.Printf("  %s Queue frozen; run 'gt mq unfreeze %s' once resolved\n", style.Warning.Render("❄"), rigName)
+			if c.RevertMR != "" {
+				fmt.Printf("  Revert queued: %s\n", style.Bold.Render(c.RevertMR))
+			}
+		case refinery.PostMergePassed:
+			fmt.Printf("%s %s (%s) passed\n", style.Success.Render("✓"), c.MR, shortSHA(c.Commit))
+		default:
+			fmt.Printf("%s %s (%s) healthy; monitoring until the window closes\n", style.Dim.Render("○"), c.MR, shortSHA(c.Commit))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// MQ worktrees command flags
+var (
+	mqWorktreesWarm  bool
+	mqWorktreesPrune bool
+	mqWorktreesJSON  bool
+)
+
+var mqWorktreesCmd = &cobra.Command{
+	Use:   "worktrees <rig>",
+	Short: "Show or warm the refinery's scratch worktree pool",
+	Long: `Show the pool of scratch worktrees the refinery borrows for trial merges,
+test suite runs, post-merge checks, reverts, and backports.
+
+Instead of creating and removing a worktree per operation, each clone keeps
+up to merge_queue.worktree_pool worktrees (default 2) under
+<rig>/.runtime/worktrees. A borrower gets a clean checkout of the ref it
+asked for; ignored files such as build caches survive between borrows.
+When every worktree is busy, operations fall back to a throwaway worktree.
+
+  "merge_queue": {"worktree_pool": 4}
+
+--warm fetches origin and checks the default branch out in every idle
+worktree, creating missing ones. --prune removes idle worktrees, e.g. after
+shrinking the pool.
+
+Examples:
+  gt mq worktrees gastown
+  gt mq worktrees gastown --warm
+  gt mq worktrees gastown --prune`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMQWorktrees,
+}
+
+func init() {
+	mqWorktreesCmd.Flags().BoolVar(&mqWorktreesWarm, "warm", false, "Fetch and create or refresh idle worktrees")
+	mqWorktreesCmd.Flags().BoolVar(&mqWorktreesPrune, "prune", false, "Remove idle worktrees")
+	mqWorktreesCmd.Flags().BoolVar(&mqWorktreesJSON, "json", false, "Output as JSON")
+	mqWorktreesCmd.MarkFlagsMutuallyExclusive("warm", "prune")
+
+	mqCmd.AddCommand(mqWorktreesCmd)
+}
+
+// WorktreePoolStatus is the JSON output of gt mq worktrees, per pool.
+type WorktreePoolStatus struct {
+	Dir    string                  `json:"dir"`
+	Warmed int                     `json:"warmed,omitempty"`
+	Pruned int                     `json:"pruned,omitempty"`
+	Slots  []refinery.WorktreeSlot `json:"slots"`
+}
+
+func runMQWorktrees(cmd *cobra.Command, args []string) error {
+	mgr, r, _, err := getRefineryManager(args[0])
+	if err != nil {
+		return err
+	}
+	pools, err := mgr.WorktreePools()
+	if err != nil {
+		return err
+	}
+
+	var statuses []WorktreePoolStatus
+	for _, pool := range pools {
+		status := WorktreePoolStatus{Dir: pool.Dir()}
+		switch {
+		case mqWorktreesWarm:
+			if status.Warmed, err = pool.Warm("origin/" + r.DefaultBranch()); err != nil {
+				return fmt.Errorf("warming %s: %w", pool.Dir(), err)
+			}
+		case mqWorktreesPrune:
+			if status.Pruned, err = pool.Prune(); err != nil {
+				return fmt.Errorf("pruning %s: %w", pool.Dir(), err)
+			}
+		}
+		status.Slots = pool.Slots()
+		statuses = append(statuses, status)
+	}
+
+	if mqWorktreesJSON {
+		return outputJSON(statuses)
+	}
+
+	for _, s := range statuses {
+		fmt.Printf("%s %s\n", style.Bold.Render("Worktree pool:"), s.Dir)
+		switch {
+		case mqWorktreesWarm:
+			fmt.Printf("  %s Warmed %d worktree(s)\n", style.Bold.Render("✓"), s.Warmed)
+		case mqWorktreesPrune:
+			fmt.Printf("  %s Pruned %d worktree(s)\n", style.Bold.Render("✓"), s.Pruned)
+		}
+		if len(s.Slots) == 0 {
+			fmt.Printf("  %s\n", style.Dim.Render("Pooling disabled (worktree_pool: 0)"))
+			continue
+		}
+		for _, slot := range s.Slots {
+			state := style.Dim.Render("not created")
+			switch {
+			case slot.Busy:
+				state = style.Warning.Render("busy")
+			case slot.Exists:
+				state = "idle at " + shortSHA(slot.Commit)
+			}
+			fmt.Printf("  %-40s %s\n", slot.Path, state)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+// Output formats accepted by --format.
+const (
+	formatText   = "text"
+	formatJSON   = "json"
+	formatNDJSON = "ndjson"
+)
+
+// resolveFormat validates a --format value and folds in --json, the older
+// spelling of --format=json.
+func resolveFormat(format string, jsonFlag bool) (string, error) {
+	switch format {
+	case "", formatText:
+		if jsonFlag {
+			return formatJSON, nil
+		}
+		return formatText, nil
+	case formatJSON, formatNDJSON:
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown --format %q (want text, json, or ndjson)", format)
+}
+
+// ndjsonWriter writes one JSON value per line. It is safe for concurrent
+// use, so per-rig goroutines can stream results as they arrive instead of
+// waiting for the slowest rig.
+type ndjsonWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func newNDJSONWriter(w io.Writer) *ndjsonWriter {
+	return &ndjsonWriter{w: w}
+}
+
+// Write encodes v on its own line.
+func (n *ndjsonWriter) Write(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	_, err = n.w.Write(append(data, '\n'))
+	return err
+}
+
+// issueRecord is the NDJSON line for an issue from a multi-source command:
+// the issue's fields plus the source (town or rig) it came from.
+type issueRecord struct {
+	Source string `json:"source"`
+	*beads.Issue
+}
+
+// sourceErrorRecord is the NDJSON line for a source that couldn't be read.
+type sourceErrorRecord struct {
+	Source string `json:"source"`
+	Error  string `json:"error"`
+}
+
+// writeSource streams one source's issues, highest priority first, or its
+// error.
+func (n *ndjsonWriter) writeSource(source string, issues []*beads.Issue, errMsg string) {
+	if errMsg != "" {
+		_ = n.Write(sourceErrorRecord{Source: source, Error: errMsg})
+		return
+	}
+	sorted := append([]*beads.Issue(nil), issues...)
+	sort.SliceStable(sorted, func(a, b int) bool { return sorted[a].Priority < sorted[b].Priority })
+	for _, issue := range sorted {
+		_ = n.Write(issueRecord{Source: source, Issue: issue})
+	}
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+func TestResolveFormat(t *testing.T) {
+	tests := []struct {
+		format  string
+		json    bool
+		want    string
+		wantErr bool
+	}{
+		{"", false, formatText, false},
+		{"text", false, formatText, false},
+		{"text", true, formatJSON, false},
+		{"json", false, formatJSON, false},
+		{"ndjson", true, formatNDJSON, false},
+		{"yaml", false, "", true},
+	}
+	for _, tt := range tests {
+		got, err := resolveFormat(tt.format, tt.json)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("resolveFormat(%q, %v) = %q, %v; want %q", tt.format, tt.json, got, err, tt.want)
+		}
+	}
+}
+
+func TestNDJSONWriteSource(t *testing.T) {
+	var buf bytes.Buffer
+	w := newNDJSONWriter(&buf)
+	w.writeSource("gastown", []*beads.Issue{
+		{ID: "gt-2", Priority: 2},
+		{ID: "gt-1", Priority: 0},
+	}, "")
+	w.writeSource("beads", nil, "bd not found")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3:\n%s", len(lines), buf.String())
+	}
+	var first struct {
+		Source string `json:"source"`
+		ID     string `json:"id"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatal(err)
+	}
+	if first.Source != "gastown" || first.ID != "gt-1" {
+		t.Errorf("first line = %+v, want gastown/gt-1 (highest priority first)", first)
+	}
+	if lines[2] != `{"source":"beads","error":"bd not found"}` {
+		t.Errorf("error line = %s", lines[2])
+	}
+}
@@ -0,0 +1,375 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/conventions"
+	"github.com/steveyegge/gastown/internal/diskusage"
+	"github.com/steveyegge/gastown/internal/guardrails"
+	"github.com/steveyegge/gastown/internal/lock"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/templates"
+)
+
+// Orient command flags
+var (
+	orientJSON     bool
+	orientIssue    string
+	orientNoPrompt bool
+)
+
+var orientCmd = &cobra.Command{
+	Use:     "orient",
+	GroupID: GroupAgents,
+	Short:   "Print everything a freshly spawned agent needs, in one call",
+	Long: `Orient an agent on spawn: who it is, what it's working on, the rig's
+conventions, and how to hand work back.
+
+Sections:
+  - Identity: role, rig, name, and working directory
+  - Role prompt: the rendered role template (see 'gt agent spawn')
+  - Assignment: the issue from --issue, GT_ISSUE, or the agent's hook,
+    with its context bundle (see 'gt context')
+  - Claim: whether the issue is hooked to this agent and who holds the
+    worker's identity lock
+  - Conventions: default branch, branch naming, test/lint/build commands,
+    protected paths
+  - Submitting: how this role hands work back
+
+--json emits the same as one object, so a runtime wrapper can bootstrap any
+agent with a single call instead of a per-runtime script.
+
+Examples:
+  gt orient
+  gt orient --json
+  gt orient --issue gt-abc --no-prompt`,
+	RunE: runOrient,
+}
+
+func init() {
+	orientCmd.Flags().BoolVar(&orientJSON, "json", false, "Output as JSON")
+	orientCmd.Flags().StringVar(&orientIssue, "issue", "", "Issue to orient on (default: GT_ISSUE, then the hooked bead)")
+	orientCmd.Flags().BoolVar(&orientNoPrompt, "no-prompt", false, "Omit the role prompt")
+
+	rootCmd.AddCommand(orientCmd)
+}
+
+// Orientation is everything 'gt orient' reports.
+type Orientation struct {
+	Role         string            `json:"role"`
+	Rig          string            `json:"rig,omitempty"`
+	Name         string            `json:"name,omitempty"`
+	Identity     string            `json:"identity"`
+	WorkDir      string            `json:"work_dir"`
+	TownRoot     string            `json:"town_root"`
+	PromptSource string            `json:"prompt_source,omitempty"`
+	Prompt       string            `json:"prompt,omitempty"`
+	Issue        *OrientIssue      `json:"issue,omitempty"`
+	Claim        OrientClaim       `json:"claim"`
+	Conventions  OrientConventions `json:"conventions"`
+	Submit       []string          `json:"submit"`
+}
+
+// OrientIssue is the agent's assigned issue.
+type OrientIssue struct {
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	Status  string `json:"status"`
+	Source  string `json:"source"` // flag, env, or hook
+	Context string `json:"context,omitempty"`
+}
+
+// OrientClaim reports the agent's hold on its work and identity.
+type OrientClaim struct {
+	Hooked     []string `json:"hooked,omitempty"`      // beads hooked to this agent
+	HookedToMe bool     `json:"hooked_to_me"`          // the assigned issue is among them
+	Assignee   string   `json:"assignee,omitempty"`    // the issue's current assignee
+	LockHolder string   `json:"lock_holder,omitempty"` // session holding the worker's identity lock
+	LockStale  bool     `json:"lock_stale,omitempty"`
+}
+
+// OrientConventions are the rig conventions an agent must follow.
+type OrientConventions struct {
+	DefaultBranch  string   `json:"default_branch"`
+	Subtree        string   `json:"subtree,omitempty"` // monorepo subtree the rig owns
+	BranchPattern  string   `json:"branch_pattern,omitempty"`
+	BranchRule     string   `json:"branch_rule,omitempty"`  // regexp the refinery enforces
+	CommitStyle    string   `json:"commit_style,omitempty"` // e.g. "conventional"
+	CommitTypes    []string `json:"commit_types,omitempty"`
+	IssueTrailer   string   `json:"issue_trailer,omitempty"`
+	TestCommand    string   `json:"test_command,omitempty"`
+	LintCommand    string   `json:"lint_command,omitempty"`
+	BuildCommand   string   `json:"build_command,omitempty"`
+	ProtectedPaths []string `json:"protected_paths,omitempty"`
+	MergeQueue     bool     `json:"merge_queue"`
+
+	// Guardrails summarizes the file guardrails the refinery enforces.
+	Guardrails *OrientGuardrails `json:"guardrails,omitempty"`
+}
+
+// OrientGuardrails are the file guardrails an agent's MRs are checked against.
+type OrientGuardrails struct {
+	MaxFileSize    string   `json:"max_file_size"`
+	GeneratedPaths []string `json:"generated_paths,omitempty"` // where generated or vendored code may go
+}
+
+func runOrient(cmd *cobra.Command, args []string) error {
+	info, err := GetRole()
+	if err != nil {
+		return err
+	}
+	o, err := buildOrientation(info)
+	if err != nil {
+		return err
+	}
+	if orientJSON {
+		return outputJSON(o)
+	}
+	printOrientation(o)
+	return nil
+}
+
+// buildOrientation gathers the orientation for the agent described by info.
+func buildOrientation(info RoleInfo) (*Orientation, error) {
+	o := &Orientation{
+		Role:     string(info.Role),
+		Rig:      info.Rig,
+		Name:     info.Polecat,
+		Identity: getAgentIdentity(info),
+		WorkDir:  info.WorkDir,
+		TownRoot: info.TownRoot,
+	}
+	if o.Identity == "" {
+		o.Identity = o.Role
+	}
+
+	var r *rig.Rig
+	var rigPath string
+	if info.Rig != "" {
+		if _, found, err := getRig(info.Rig); err == nil {
+			r, rigPath = found, found.Path
+		}
+	}
+	o.Conventions = orientConventions(r)
+	o.Submit = submitSteps(info.Role)
+	o.Claim.Hooked = hookedBeadIDs(info.WorkDir, o.Identity)
+	if info.Role == RolePolecat || info.Role == RoleCrew {
+		if lockInfo, err := lock.New(info.WorkDir).Read(); err == nil {
+			o.Claim.LockHolder = lockInfo.SessionID
+			o.Claim.LockStale = lockInfo.IsStale()
+		}
+	}
+
+	issueID, source := orientIssue, "flag"
+	if issueID == "" {
+		issueID, source = os.Getenv("GT_ISSUE"), "env"
+	}
+	if issueID == "" && len(o.Claim.Hooked) > 0 {
+		issueID, source = o.Claim.Hooked[0], "hook"
+	}
+
+	role := o.Role
+	tmpl, err := templates.New()
+	if err != nil {
+		return nil, err
+	}
+	if err := tmpl.LoadOverrides(info.TownRoot, info.Rig); err != nil {
+		style.PrintWarning("%v (using built-in role templates)", err)
+		if tmpl, err = templates.New(); err != nil {
+			return nil, err
+		}
+	}
+	data, err := spawnRoleData(role, info.TownRoot, info.Rig, rigPath, info.Polecat, issueID)
+	if err != nil {
+		return nil, err
+	}
+	if issueID != "" {
+		o.Issue = &OrientIssue{ID: data.Issue, Title: data.IssueTitle, Source: source, Context: data.IssueContext}
+		if issue, err := beads.New(resolveBeadDir(issueID)).Show(issueID); err == nil {
+			o.Issue.Status = issue.Status
+			o.Claim.Assignee = issue.Assignee
+		}
+		for _, id := range o.Claim.Hooked {
+			o.Claim.HookedToMe = o.Claim.HookedToMe || id == issueID
+		}
+	}
+	if !orientNoPrompt && tmpl.HasRole(role) {
+		o.PromptSource = tmpl.RoleSource(role)
+		if o.Prompt, err = tmpl.RenderRole(role, data); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// hookedBeadIDs returns the beads hooked to identity, from the agent's beads.
+func hookedBeadIDs(workDir, identity string) []string {
+	hooked, err := beads.New(workDir).List(beads.ListOptions{
+		Status:   beads.StatusHooked,
+		Assignee: identity,
+		Priority: -1,
+	})
+	if err != nil {
+		return nil
+	}
+	ids := make([]string, 0, len(hooked))
+	for _, h := range hooked {
+		ids = append(ids, h.ID)
+	}
+	return ids
+}
+
+// orientConventions reads a rig's conventions. A nil rig gets the defaults.
+func orientConventions(r *rig.Rig) OrientConventions {
+	c := OrientConventions{DefaultBranch: "main", BranchPattern: "polecat/{name}/{issue}@{timestamp}"}
+	if r == nil {
+		return c
+	}
+	c.DefaultBranch = r.DefaultBranch()
+	c.Subtree = r.Subdir
+	if tmpl := r.GetStringConfig("polecat_branch_template"); tmpl != "" {
+		c.BranchPattern = tmpl
+	}
+	settings, err := config.LoadRigSettings(config.RigSettingsPath(r.Path))
+	if err != nil {
+		return c
+	}
+	c.ProtectedPaths = settings.ProtectedPaths
+	if conv := settings.Conventions; conv != nil {
+		if conv.BranchTemplate != "" {
+			c.BranchPattern = conv.BranchTemplate
+		}
+		c.BranchRule, c.CommitStyle, c.IssueTrailer = conv.BranchPattern, conv.CommitStyle, conv.IssueTrailer
+		if c.CommitStyle == conventions.CommitStyleConventional {
+			c.CommitTypes = conv.CommitTypes
+			if len(c.CommitTypes) == 0 {
+				c.CommitTypes = conventions.DefaultCommitTypes
+			}
+		}
+	}
+	if g := settings.Guardrails; g != nil {
+		c.Guardrails = &OrientGuardrails{MaxFileSize: g.MaxFileSize, GeneratedPaths: g.GeneratedPaths}
+		if c.Guardrails.MaxFileSize == "" {
+			c.Guardrails.MaxFileSize = diskusage.FormatSize(guardrails.DefaultMaxFileSize)
+		}
+	}
+	if mq := settings.MergeQueue; mq != nil {
+		c.MergeQueue = mq.Enabled
+		c.TestCommand, c.LintCommand, c.BuildCommand = mq.TestCommand, mq.LintCommand, mq.BuildCommand
+	}
+	return c
+}
+
+// submitSteps describes how a role hands its work back.
+func submitSteps(role Role) []string {
+	switch role {
+	case RolePolecat:
+		return []string{
+			"Commit on your branch; keep commits scoped to the issue and follow the commit conventions",
+			"Run the test command and make it pass",
+			"gt done — pushes the branch, files the merge request, and frees your slot",
+		}
+	case RoleCrew:
+		return []string{
+			"Commit and push to your branch",
+			"gt mq submit — file a merge request for the refinery",
+			"bd close <issue> once it lands",
+		}
+	case RoleRefinery:
+		return []string{"gt mq next — merge queue work comes from the queue, not a hook"}
+	default:
+		return []string{"bd close <issue> when done; gt mail send to report back"}
+	}
+}
+
+func printOrientation(o *Orientation) {
+	fmt.Printf("%s %s", style.Bold.Render("You are"), o.Identity)
+	if o.Rig != "" {
+		fmt.Printf(" in rig %s", o.Rig)
+	}
+	fmt.Printf("\n  %s\n", style.Dim.Render(o.WorkDir))
+
+	if o.Prompt != "" {
+		fmt.Printf("\n%s %s\n\n", style.Bold.Render("## Role prompt"), style.Dim.Render("("+o.PromptSource+")"))
+		fmt.Print(strings.TrimRight(o.Prompt, "\n") + "\n")
+	}
+
+	fmt.Printf("\n%s\n\n", style.Bold.Render("## Assignment"))
+	if o.Issue == nil {
+		fmt.Println(style.Dim.Render("No issue: nothing hooked and GT_ISSUE unset. Check 'gt mail inbox'."))
+	} else {
+		fmt.Printf("%s: %s [%s] (from %s)\n", o.Issue.ID, o.Issue.Title, o.Issue.Status, o.Issue.Source)
+	}
+
+	fmt.Printf("\n%s\n\n", style.Bold.Render("## Claim"))
+	switch {
+	case o.Issue != nil && o.Claim.HookedToMe:
+		fmt.Printf("%s %s is hooked to you\n", style.Success.Render("✓"), o.Issue.ID)
+	case o.Issue != nil && o.Claim.Assignee != "" && o.Claim.Assignee != o.Identity:
+		fmt.Printf("%s %s is assigned to %s, not you\n", style.Warning.Render("⚠"), o.Issue.ID, o.Claim.Assignee)
+	case o.Issue != nil:
+		fmt.Printf("%s %s is not hooked; claim it with: gt hook %s\n", style.Warning.Render("⚠"), o.Issue.ID, o.Issue.ID)
+	case len(o.Claim.Hooked) == 0:
+		fmt.Println("Nothing hooked")
+	}
+	if o.Claim.LockHolder != "" {
+		state := "held by " + o.Claim.LockHolder
+		if o.Claim.LockStale {
+			state += " (stale)"
+		}
+		fmt.Printf("Identity lock: %s\n", state)
+	}
+
+	c := o.Conventions
+	fmt.Printf("\n%s\n\n", style.Bold.Render("## Conventions"))
+	fmt.Printf("Default branch:  %s\n", c.DefaultBranch)
+	if c.Subtree != "" {
+		fmt.Printf("Subtree:         %s/ %s\n", c.Subtree, style.Dim.Render("(other paths belong to other rigs; see 'gt rig owner')"))
+	}
+	fmt.Printf("Branch naming:   %s\n", c.BranchPattern)
+	if c.BranchRule != "" {
+		fmt.Printf("Branch rule:     %s\n", c.BranchRule)
+	}
+	if c.CommitStyle != "" {
+		fmt.Printf("Commit style:    %s (%s)\n", c.CommitStyle, strings.Join(c.CommitTypes, ", "))
+	}
+	if c.IssueTrailer != "" {
+		fmt.Printf("Commit trailer:  %s: <issue>\n", c.IssueTrailer)
+	}
+	for _, kv := range [][2]string{{"Test command:", c.TestCommand}, {"Lint command:", c.LintCommand}, {"Build command:", c.BuildCommand}} {
+		if kv[1] != "" {
+			fmt.Printf("%-16s %s\n", kv[0], kv[1])
+		}
+	}
+	if len(c.ProtectedPaths) > 0 {
+		fmt.Printf("Protected paths: %s\n", strings.Join(c.ProtectedPaths, ", "))
+		fmt.Println(style.Dim.Render("  Don't change these without a human's sign-off"))
+	}
+	if g := c.Guardrails; g != nil {
+		fmt.Printf("Guardrails:      files up to %s; no .env files, keys, or secrets\n", g.MaxFileSize)
+		generated := "nowhere"
+		if len(g.GeneratedPaths) > 0 {
+			generated = strings.Join(g.GeneratedPaths, ", ")
+		}
+		fmt.Println(style.Dim.Render("  Generated or vendored code only in: " + generated))
+	}
+	if c.MergeQueue {
+		fmt.Println("Merges go through the refinery's merge queue")
+	}
+
+	fmt.Printf("\n%s\n\n", style.Bold.Render("## Submitting work"))
+	for i, step := range o.Submit {
+		fmt.Printf("%d. %s\n", i+1, step)
+	}
+
+	if o.Issue != nil && o.Issue.Context != "" {
+		fmt.Printf("\n%s\n\n", style.Bold.Render("## Issue context"))
+		fmt.Print(o.Issue.Context)
+	}
+}
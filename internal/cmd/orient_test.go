@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+func TestOrientConventions(t *testing.T) {
+	if c := orientConventions(nil); c.DefaultBranch != "main" || !strings.HasPrefix(c.BranchPattern, "polecat/") {
+		t.Errorf("defaults = %+v", c)
+	}
+
+	rigPath := t.TempDir()
+	if err := os.WriteFile(filepath.Join(rigPath, "config.json"), []byte(`{"type":"rig","version":1,"name":"gastown","default_branch":"develop"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(rigPath, "settings"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	settings := `{"type":"rig-settings","version":1,
+		"merge_queue":{"enabled":true,"test_command":"go test ./...","lint_command":"golangci-lint run"},
+		"protected_paths":["migrations/**"]}`
+	if err := os.WriteFile(filepath.Join(rigPath, "settings", "config.json"), []byte(settings), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := orientConventions(&rig.Rig{Name: "gastown", Path: rigPath})
+	if c.DefaultBranch != "develop" {
+		t.Errorf("DefaultBranch = %q, want develop", c.DefaultBranch)
+	}
+	if c.TestCommand != "go test ./..." || c.LintCommand != "golangci-lint run" || !c.MergeQueue {
+		t.Errorf("merge queue conventions = %+v", c)
+	}
+	if len(c.ProtectedPaths) != 1 || c.ProtectedPaths[0] != "migrations/**" {
+		t.Errorf("ProtectedPaths = %v", c.ProtectedPaths)
+	}
+}
+
+func TestSubmitSteps(t *testing.T) {
+	for _, role := range []Role{RolePolecat, RoleCrew, RoleRefinery, RoleMayor} {
+		if len(submitSteps(role)) == 0 {
+			t.Errorf("submitSteps(%s) is empty", role)
+		}
+	}
+	if !strings.Contains(strings.Join(submitSteps(RolePolecat), "\n"), "gt done") {
+		t.Error("polecats submit with gt done")
+	}
+}
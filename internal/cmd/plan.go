@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/capacity"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// Plan command flags
+var (
+	planSince  string
+	planTarget string
+	planRig    string
+	planJSON   bool
+)
+
+var planCmd = &cobra.Command{
+	Use:     "plan",
+	GroupID: GroupWork,
+	Short:   "Estimate backlog drain time and recommend agent allocation",
+	Long: `Estimate how long each rig's ready queue will take to drain, and how
+many agents each rig needs to drain it within a target time.
+
+For each rig this combines:
+  Ready        Issues ready to work now (as in 'gt ready', less MRs)
+  Throughput   Issues completed per day over --since, from the event log
+  Per agent    Throughput / agents that completed work in the window
+  Max agents   The rig's configured concurrency (max_polecats)
+
+Drain time assumes max_polecats agents each keep up the per-agent rate.
+A rig with no completions in the window borrows the town-wide rate. The
+recommendation is the agents needed to drain the queue within --target;
+a rig is only told to free agents when its queue would drain in under
+half the target.
+
+Advice:
+  add      The queue won't drain by the target; raise max_polecats
+  remove   Agents would sit idle; move them to a rig that needs them
+  hold     Current concurrency is about right
+  no-data  No throughput history to estimate from
+
+--json emits the plan for the dispatcher to act on.
+
+Examples:
+  gt plan
+  gt plan --since 7d --target 2d
+  gt plan --rig gastown --json`,
+	Args: cobra.NoArgs,
+	RunE: runPlan,
+}
+
+func init() {
+	planCmd.Flags().StringVar(&planSince, "since", "14d", "Throughput history window (e.g., 7d, 14d)")
+	planCmd.Flags().StringVar(&planTarget, "target", "3d", "Time to drain each ready queue within (e.g., 24h, 3d)")
+	planCmd.Flags().StringVar(&planRig, "rig", "", "Only plan this rig")
+	planCmd.Flags().BoolVar(&planJSON, "json", false, "Output as JSON")
+	rootCmd.AddCommand(planCmd)
+}
+
+func runPlan(cmd *cobra.Command, args []string) error {
+	window, err := parseDuration(planSince)
+	if err != nil || window <= 0 {
+		return fmt.Errorf("invalid --since %q", planSince)
+	}
+	target, err := parseDuration(planTarget)
+	if err != nil || target <= 0 {
+		return fmt.Errorf("invalid --target %q", planTarget)
+	}
+
+	rigs, townRoot, err := getAllRigs()
+	if err != nil {
+		return err
+	}
+	if planRig != "" {
+		var filtered []*rig.Rig
+		for _, r := range rigs {
+			if r.Name == planRig {
+				filtered = append(filtered, r)
+			}
+		}
+		if len(filtered) == 0 {
+			return fmt.Errorf("rig not found: %s", planRig)
+		}
+		rigs = filtered
+	}
+
+	since := time.Now().Add(-window)
+	evts, err := events.Read(townRoot, func(e events.Event) bool {
+		return e.Type == events.TypeDone && !e.Time().Before(since)
+	})
+	if err != nil {
+		return fmt.Errorf("reading event log: %w", err)
+	}
+	completed := make(map[string]int)
+	agents := make(map[string]map[string]bool)
+	for _, e := range evts {
+		rigName := activityEventRig(e)
+		if rigName == "" {
+			continue
+		}
+		completed[rigName]++
+		if agents[rigName] == nil {
+			agents[rigName] = make(map[string]bool)
+		}
+		agents[rigName][e.Actor] = true
+	}
+
+	inputs := make([]capacity.Input, len(rigs))
+	var wg sync.WaitGroup
+	for i, r := range rigs {
+		inputs[i] = capacity.Input{
+			Rig:       r.Name,
+			Completed: completed[r.Name],
+			Agents:    len(agents[r.Name]),
+			MaxAgents: r.GetIntConfig("max_polecats"),
+		}
+		wg.Add(1)
+		go func(in *capacity.Input, r *rig.Rig) {
+			defer wg.Done()
+			in.Ready = planReadyCount(r)
+		}(&inputs[i], r)
+	}
+	wg.Wait()
+
+	report := capacity.Build(inputs, window, target)
+	if planJSON {
+		return outputJSON(report)
+	}
+	printPlan(report)
+	return nil
+}
+
+// planReadyCount counts the rig's ready work, filtered as gt ready does,
+// less merge requests, which the refinery rather than polecats works.
+func planReadyCount(r *rig.Rig) int {
+	issues, err := beads.New(r.BeadsPath()).Ready()
+	if err != nil {
+		return 0
+	}
+	issues = filterFormulaScaffolds(issues, getFormulaNames(r.BeadsPath()))
+	issues = filterWisps(issues, getWispIDs(r.BeadsPath()))
+	n := 0
+	for _, issue := range filterIdentityBeads(issues) {
+		if issue.Type != "merge-request" && !beads.HasLabel(issue, "gt:merge-request") {
+			n++
+		}
+	}
+	return n
+}
+
+func printPlan(report *capacity.Report) {
+	fmt.Printf("%s Capacity plan: drain within %s, throughput over the last %s\n\n",
+		style.Bold.Render("●"), planTarget, planSince)
+	if len(report.Rigs) == 0 {
+		fmt.Printf("  %s\n", style.Dim.Render("No rigs"))
+		return
+	}
+	table := style.NewTable(
+		style.Column{Name: "RIG", Width: 20},
+		style.Column{Name: "READY", Width: 6, Align: style.AlignRight},
+		style.Column{Name: "DONE/DAY", Width: 9, Align: style.AlignRight},
+		style.Column{Name: "PER AGENT", Width: 10, Align: style.AlignRight},
+		style.Column{Name: "DRAIN", Width: 7, Align: style.AlignRight},
+		style.Column{Name: "AGENTS", Width: 7, Align: style.AlignRight},
+		style.Column{Name: "ADVICE", Width: 24},
+	)
+	for _, p := range report.Rigs {
+		perAgent := "-"
+		if p.PerAgent > 0 {
+			perAgent = fmt.Sprintf("%.1f", p.PerAgent)
+			if p.RateSource == capacity.RateTown {
+				perAgent += "*"
+			}
+		}
+		table.AddRow(p.Rig, fmt.Sprint(p.Ready), fmt.Sprintf("%.1f", p.Throughput), perAgent,
+			planDrain(p, report.TargetHours), fmt.Sprintf("%d→%d", p.MaxAgents, p.Recommended), planAdvice(p))
+	}
+	fmt.Print(table.Render())
+	fmt.Printf("\n  Ready: %d   Agents: %d → %d\n", report.Ready, report.MaxAgents, report.Recommended)
+	for _, p := range report.Rigs {
+		if p.RateSource == capacity.RateTown {
+			fmt.Printf("  %s\n", style.Dim.Render("* no completions in the window; town-wide rate"))
+			break
+		}
+	}
+}
+
+// planDrain formats a rig's drain time, red when it misses the target.
+func planDrain(p capacity.RigPlan, targetHours float64) string {
+	if p.DrainHours == nil {
+		return "-"
+	}
+	s := formatPlanHours(*p.DrainHours)
+	if *p.DrainHours > targetHours {
+		return style.Error.Render(s)
+	}
+	return s
+}
+
+func planAdvice(p capacity.RigPlan) string {
+	switch p.Advice {
+	case capacity.AdviceAdd:
+		return style.Warning.Render(fmt.Sprintf("add %d", p.Change))
+	case capacity.AdviceRemove:
+		return style.Success.Render(fmt.Sprintf("free %d", -p.Change))
+	case capacity.AdviceNoData:
+		return style.Dim.Render("no history")
+	}
+	return style.Dim.Render("hold")
+}
+
+// formatPlanHours formats a drain time in hours as "45m", "6h", or "2.5d".
+func formatPlanHours(h float64) string {
+	switch {
+	case h == 0:
+		return "0"
+	case h < 1:
+		return fmt.Sprintf("%.0fm", h*60)
+	case h < 48:
+		return fmt.Sprintf("%.0fh", h)
+	}
+	return fmt.Sprintf("%.1fd", h/24)
+}
@@ -0,0 +1,21 @@
+package cmd
+
+import "testing"
+
+func TestFormatPlanHours(t *testing.T) {
+	tests := []struct {
+		hours float64
+		want  string
+	}{
+		{0, "0"},
+		{0.5, "30m"},
+		{6, "6h"},
+		{47.6, "48h"},
+		{60, "2.5d"},
+	}
+	for _, tt := range tests {
+		if got := formatPlanHours(tt.hours); got != tt.want {
+			t.Errorf("formatPlanHours(%v) = %q, want %q", tt.hours, got, tt.want)
+		}
+	}
+}
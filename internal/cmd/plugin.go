@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"sort"
@@ -191,9 +190,7 @@ func outputPluginListJSON(plugins []*plugin.Plugin) error {
 		summaries[i] = p.Summary()
 	}
 
-	enc := json.NewEncoder(os.Stdout)
-	enc.SetIndent("", "  ")
-	return enc.Encode(summaries)
+	return outputJSON(summaries)
 }
 
 func outputPluginListText(plugins []*plugin.Plugin, townRoot string) error {
@@ -284,9 +281,7 @@ func runPluginShow(cmd *cobra.Command, args []string) error {
 }
 
 func outputPluginShowJSON(p *plugin.Plugin) error {
-	enc := json.NewEncoder(os.Stdout)
-	enc.SetIndent("", "  ")
-	return enc.Encode(p)
+	return outputJSON(p)
 }
 
 func outputPluginShowText(p *plugin.Plugin) error {
@@ -474,9 +469,7 @@ func runPluginHistory(cmd *cobra.Command, args []string) error {
 	}
 
 	if pluginHistoryJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(runs)
+		return outputJSON(runs)
 	}
 
 	if len(runs) == 0 {
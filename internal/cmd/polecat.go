@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -17,6 +16,7 @@ import (
 	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/runtime"
 	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/timefmt"
 	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/util"
 )
@@ -435,9 +435,7 @@ func runPolecatList(cmd *cobra.Command, args []string) error {
 
 	// Output
 	if polecatListJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(allPolecats)
+		return outputJSON(allPolecats)
 	}
 
 	if len(allPolecats) == 0 {
@@ -652,9 +650,7 @@ func runPolecatStatus(cmd *cobra.Command, args []string) error {
 		if !sessInfo.LastActivity.IsZero() {
 			status.LastActivity = sessInfo.LastActivity.Format("2006-01-02 15:04:05")
 		}
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(status)
+		return outputJSON(status)
 	}
 
 	// Human-readable output
@@ -723,17 +719,7 @@ func runPolecatStatus(cmd *cobra.Command, args []string) error {
 
 // formatActivityTime returns a human-readable relative time string.
 func formatActivityTime(t time.Time) string {
-	d := time.Since(t)
-	switch {
-	case d < time.Minute:
-		return fmt.Sprintf("%d seconds ago", int(d.Seconds()))
-	case d < time.Hour:
-		return fmt.Sprintf("%d minutes ago", int(d.Minutes()))
-	case d < 24*time.Hour:
-		return fmt.Sprintf("%d hours ago", int(d.Hours()))
-	default:
-		return fmt.Sprintf("%d days ago", int(d.Hours()/24))
-	}
+	return timefmt.Ago(t)
 }
 
 // GitState represents the git state of a polecat's worktree.
@@ -769,9 +755,7 @@ func runPolecatGitState(cmd *cobra.Command, args []string) error {
 
 	// JSON output
 	if polecatGitStateJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(state)
+		return outputJSON(state)
 	}
 
 	// Human-readable output
@@ -991,9 +975,7 @@ func runPolecatCheckRecovery(cmd *cobra.Command, args []string) error {
 
 	// JSON output
 	if polecatCheckRecoveryJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(status)
+		return outputJSON(status)
 	}
 
 	// Human-readable output
@@ -1311,7 +1293,7 @@ func runPolecatStale(cmd *cobra.Command, args []string) error {
 
 	// JSON output
 	if polecatStaleJSON {
-		return json.NewEncoder(os.Stdout).Encode(staleInfos)
+		return outputJSON(staleInfos)
 	}
 
 	// Summary counts
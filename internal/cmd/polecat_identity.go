@@ -9,7 +9,6 @@ import (
 	"sort"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/spf13/cobra"
@@ -17,6 +16,7 @@ import (
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/polecat"
 	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/timefmt"
 	"github.com/steveyegge/gastown/internal/tmux"
 )
 
@@ -321,9 +321,7 @@ func runPolecatIdentityList(cmd *cobra.Command, args []string) error {
 
 	// JSON output
 	if polecatIdentityListJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(identities)
+		return outputJSON(identities)
 	}
 
 	// Human-readable output
@@ -437,9 +435,7 @@ func runPolecatIdentityShow(cmd *cobra.Command, args []string) error {
 		if output.HookBead == "" {
 			output.HookBead = fields.HookBead
 		}
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(output)
+		return outputJSON(output)
 	}
 
 	// Human-readable output
@@ -534,10 +530,7 @@ func runPolecatIdentityShow(cmd *cobra.Command, args []string) error {
 			if work.Type != "" {
 				typeStr = work.Type + ": "
 			}
-			title := work.Title
-			if len(title) > 40 {
-				title = title[:37] + "..."
-			}
+			title := style.Truncate(work.Title, 40)
 			fmt.Printf("  %-10s %s%s  %s\n", work.ID, typeStr, title, style.Dim.Render(work.Ago))
 		}
 	}
@@ -938,57 +931,7 @@ func getLanguageStats(clonePath string) map[string]int {
 
 // formatRelativeTimeCV returns a human-readable relative time string for CV display.
 func formatRelativeTimeCV(timestamp string) string {
-	// Try RFC3339 format with timezone (ISO 8601)
-	t, err := time.Parse(time.RFC3339, timestamp)
-	if err != nil {
-		// Try RFC3339Nano
-		t, err = time.Parse(time.RFC3339Nano, timestamp)
-		if err != nil {
-			// Try without timezone
-			t, err = time.Parse("2006-01-02T15:04:05", timestamp)
-			if err != nil {
-				// Try alternative format
-				t, err = time.Parse("2006-01-02 15:04:05", timestamp)
-				if err != nil {
-					// Try date only
-					t, err = time.Parse("2006-01-02", timestamp)
-					if err != nil {
-						return ""
-					}
-				}
-			}
-		}
-	}
-
-	d := time.Since(t)
-	switch {
-	case d < time.Minute:
-		return "just now"
-	case d < time.Hour:
-		mins := int(d.Minutes())
-		if mins == 1 {
-			return "1m ago"
-		}
-		return fmt.Sprintf("%dm ago", mins)
-	case d < 24*time.Hour:
-		hours := int(d.Hours())
-		if hours == 1 {
-			return "1h ago"
-		}
-		return fmt.Sprintf("%dh ago", hours)
-	case d < 7*24*time.Hour:
-		days := int(d.Hours() / 24)
-		if days == 1 {
-			return "1d ago"
-		}
-		return fmt.Sprintf("%dd ago", days)
-	default:
-		weeks := int(d.Hours() / 24 / 7)
-		if weeks == 1 {
-			return "1w ago"
-		}
-		return fmt.Sprintf("%dw ago", weeks)
-	}
+	return timefmt.AgoString(timestamp)
 }
 
 // formatCountStyled formats a count with appropriate styling using lipgloss.Style.
@@ -77,6 +77,9 @@ func SpawnPolecatForSling(rigName string, opts SlingSpawnOptions) (*SpawnedPolec
 	if err != nil {
 		return nil, fmt.Errorf("rig '%s' not found", rigName)
 	}
+	if r.Archived {
+		return nil, checkRigNotArchived(townRoot, rigName)
+	}
 
 	// Get polecat manager (with tmux for session-aware allocation)
 	polecatGit := git.NewGit(r.Path)
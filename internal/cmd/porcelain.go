@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+// Porcelain output is the stable, line-oriented format for scripts, in the
+// spirit of git's --porcelain. Every line is a record: a type word followed
+// by tab-separated fields. Within a version, record types and field order
+// never change and fields are never removed; anything else needs a new
+// version, so a script that asks for --porcelain=v1 keeps working while
+// the human output is free to change. See docs/porcelain.md.
+const (
+	formatPorcelain = "porcelain"
+	porcelainV1     = "v1"
+)
+
+// addPorcelainFlag registers --porcelain[=version] on cmd. A bare
+// --porcelain means the latest version.
+func addPorcelainFlag(cmd *cobra.Command, version *string) {
+	cmd.Flags().StringVar(version, "porcelain", "", "Stable line-oriented output for scripts (v1); see docs/porcelain.md")
+	cmd.Flags().Lookup("porcelain").NoOptDefVal = porcelainV1
+}
+
+// checkPorcelainVersion validates a --porcelain value.
+func checkPorcelainVersion(version string) error {
+	if version != porcelainV1 {
+		return fmt.Errorf("unknown --porcelain version %q (supported: %s)", version, porcelainV1)
+	}
+	return nil
+}
+
+// porcelainWriter writes porcelain records.
+type porcelainWriter struct {
+	w io.Writer
+}
+
+// record writes one line: kind, then fields, tab-separated. Empty fields
+// are written as "-" and tabs or newlines inside a field become spaces, so
+// every line splits into the same number of fields.
+func (p porcelainWriter) record(kind string, fields ...string) {
+	parts := make([]string, 0, len(fields)+1)
+	parts = append(parts, kind)
+	for _, f := range fields {
+		f = strings.Map(func(r rune) rune {
+			if r == '\t' || r == '\n' || r == '\r' {
+				return ' '
+			}
+			return r
+		}, f)
+		if f == "" {
+			f = "-"
+		}
+		parts = append(parts, f)
+	}
+	fmt.Fprintln(p.w, strings.Join(parts, "\t"))
+}
+
+// porcelainList joins a list field with commas ("-" when empty).
+func porcelainList(items []string) string {
+	return strings.Join(items, ",")
+}
+
+// porcelainInt formats an integer field.
+func porcelainInt(n int) string {
+	return strconv.Itoa(n)
+}
+
+// writeIssueSourcePorcelain writes one source's issues for ready and
+// blocked. v1 records:
+//
+//	<kind>	<source>	<id>	<priority>	<status>	<type>	<assignee>	<blocked-by>	<title>
+//	error	<source>	<message>
+func writeIssueSourcePorcelain(p porcelainWriter, kind, source string, issues []*beads.Issue, errMsg string) {
+	if errMsg != "" {
+		p.record("error", source, errMsg)
+		return
+	}
+	for _, issue := range issues {
+		p.record(kind, source, issue.ID, porcelainInt(issue.Priority), issue.Status,
+			issue.Type, issue.Assignee, porcelainList(issue.BlockedBy), issue.Title)
+	}
+}
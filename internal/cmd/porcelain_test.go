@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+func TestPorcelainRecord(t *testing.T) {
+	var buf bytes.Buffer
+	p := porcelainWriter{w: &buf}
+	p.record("mr", "gt-1", "", "multi\tword\ntitle")
+	want := "mr\tgt-1\t-\tmulti word title\n"
+	if buf.String() != want {
+		t.Errorf("record = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWriteIssueSourcePorcelain(t *testing.T) {
+	var buf bytes.Buffer
+	p := porcelainWriter{w: &buf}
+	writeIssueSourcePorcelain(p, "blocked", "gastown", []*beads.Issue{
+		{ID: "gt-1", Priority: 1, Status: "open", Type: "task", BlockedBy: []string{"gt-2", "gt-3"}, Title: "Fix it"},
+	}, "")
+	writeIssueSourcePorcelain(p, "blocked", "beads", nil, "bd failed")
+
+	want := "blocked\tgastown\tgt-1\t1\topen\ttask\t-\tgt-2,gt-3\tFix it\n" +
+		"error\tbeads\tbd failed\n"
+	if buf.String() != want {
+		t.Errorf("output:\n%q\nwant:\n%q", buf.String(), want)
+	}
+}
+
+func TestCheckPorcelainVersion(t *testing.T) {
+	if err := checkPorcelainVersion("v1"); err != nil {
+		t.Errorf("v1: %v", err)
+	}
+	if err := checkPorcelainVersion("v2"); err == nil {
+		t.Error("v2: expected error")
+	}
+}
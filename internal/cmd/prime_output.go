@@ -26,6 +26,15 @@ func outputPrimeContext(ctx RoleContext) error {
 		// Fall back to hardcoded output if templates fail
 		return outputPrimeContextFallback(ctx)
 	}
+	if ctx.TownRoot != "" {
+		if err := tmpl.LoadOverrides(ctx.TownRoot, ctx.Rig); err != nil {
+			// A broken override shouldn't leave the agent without context
+			fmt.Fprintf(os.Stderr, "warning: %v (using built-in role templates)\n", err)
+			if tmpl, err = templates.New(); err != nil {
+				return outputPrimeContextFallback(ctx)
+			}
+		}
+	}
 
 	// Map role to template name
 	var roleName string
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/ui"
+	"golang.org/x/term"
+)
+
+// startProgress shows a progress line on stderr for work over total items
+// (0 if unknown). It returns nil, which every Progress method accepts, when
+// --quiet is set, in agent mode, or when stdout or stderr isn't a terminal,
+// so piped and scripted output never sees the redraw sequences.
+func startProgress(label string, total int) *style.Progress {
+	if quietFlag || ui.IsAgentMode() || !ui.IsTerminal() || !term.IsTerminal(int(os.Stderr.Fd())) {
+		return nil
+	}
+	return style.NewProgress(os.Stderr, label, total)
+}
+
+// startTextProgress is startProgress for commands with --format: progress
+// is only drawn for text output, never over JSON or streamed NDJSON.
+func startTextProgress(format, label string, total int) *style.Progress {
+	if format != formatText {
+		return nil
+	}
+	return startProgress(label, total)
+}
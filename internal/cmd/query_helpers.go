@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/query"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// queryFlagHelp describes the --query flag shared by issue-listing commands.
+const queryFlagHelp = `Only issues matching a query, e.g. "priority<=1 and label:infra and not blocked" (see 'gt help query')`
+
+// queryHelpCmd is a help topic ('gt help query'), not a runnable command.
+var queryHelpCmd = &cobra.Command{
+	Use:   "query",
+	Short: "The query language for selecting issues across rigs",
+	Long: `Select issues across rigs with a query, passed as --query to
+'gt ready', 'gt blocked', and 'gt view', or saved as a view's "query":
+
+  priority<=1 and rig in (gastown, greenplace) and label:infra and not blocked
+
+Combine conditions with and, or, not, and parentheses; and binds tighter
+than or.
+
+CONDITIONS:
+  field = value        Also !=, <, <=, >, >=, and ~ (contains)
+  field in (a, b)      Any of the values
+  field:value          Shorthand for field = value
+  blocked              Has open blockers
+  ready                Open with no blockers
+  assigned             Has an assignee
+  closed               Is closed
+
+FIELDS:
+  id, title, status, type, assignee    Text, compared case-insensitively
+  rig                                  Rig name, or "town" for town beads
+  label                                = has the label, != lacks it, ~ any contains
+  priority                             0-4 or P0-P4; lower is more urgent
+  created, updated                     YYYY-MM-DD, or a duration ago (7d, 2w, 12h)
+  anything else                        A custom field (see 'gt field')
+
+Quote values with spaces: title ~ "dark mode".
+
+Examples:
+  gt ready --query 'priority<=1 and not assigned'
+  gt blocked --query 'rig:gastown and updated<7d'
+  gt view triage --query 'customer=acme'`,
+}
+
+func init() {
+	rootCmd.AddCommand(queryHelpCmd)
+}
+
+// parseQueryFlag parses a --query value; an empty value gives a nil query,
+// which matches everything.
+func parseQueryFlag(src string) (*query.Query, error) {
+	if src == "" {
+		return nil, nil
+	}
+	q, err := query.Parse(src)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --query: %w", err)
+	}
+	return q, nil
+}
+
+// filterRigsByQuery drops the rigs a query's rig conditions exclude, so
+// they aren't queried at all.
+func filterRigsByQuery(rigs []*rig.Rig, q *query.Query) []*rig.Rig {
+	if q.Rigs() == nil {
+		return rigs
+	}
+	var kept []*rig.Rig
+	for _, r := range rigs {
+		if q.Covers(r.Name) {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
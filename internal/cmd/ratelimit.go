@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/ratelimit"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Rate limit command flags
+var (
+	rateLimitProvider string
+	rateLimitAgent    string
+	rateLimitJSON     bool
+)
+
+var rateLimitCmd = &cobra.Command{
+	Use:     "ratelimit",
+	GroupID: GroupServices,
+	Short:   "Town-wide provider rate limiter",
+	Long: `Share one request budget per provider across every agent in the town.
+
+Each provider gets a token bucket, configured in settings/config.json:
+
+  "rate_limits": {
+    "claude": {"requests_per_minute": 120, "burst": 30, "max_wait": "30s"}
+  }
+
+Agents draw a token per request through a PreToolUse hook that runs
+'gt ratelimit acquire'. When the bucket is empty the hook waits, slowing
+every agent down together instead of letting them collectively trip the
+provider's 429s. A request never waits longer than max_wait; it is let
+through and counted as throttled.
+
+Add the hook to the registry (hooks/registry.toml) and install it:
+
+  [hooks.rate-limit]
+  description = "Share the provider rate limit across agents"
+  event = "PreToolUse"
+  matchers = [""]
+  command = "gt ratelimit acquire"
+  roles = ["polecat", "crew", "witness", "refinery"]
+  enabled = true
+
+Usage and throttling show in 'gt ratelimit status' and 'gt agents list'.`,
+	RunE: requireSubcommand,
+}
+
+var rateLimitAcquireCmd = &cobra.Command{
+	Use:   "acquire",
+	Short: "Take a token for the current agent, waiting if the bucket is empty",
+	Long: `Take a token from the provider's shared bucket, waiting up to max_wait.
+
+Meant to run as a PreToolUse hook. The provider defaults to the current
+role's agent; the agent defaults to the current session. Always exits 0
+so a limiter problem never blocks an agent.`,
+	RunE: runRateLimitAcquire,
+}
+
+var rateLimitStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show limiter usage and throttling",
+	RunE:  runRateLimitStatus,
+}
+
+func init() {
+	rateLimitAcquireCmd.Flags().StringVar(&rateLimitProvider, "provider", "", "Provider bucket (default: the role's agent provider)")
+	rateLimitAcquireCmd.Flags().StringVar(&rateLimitAgent, "agent", "", "Agent to charge (default: current session)")
+	rateLimitStatusCmd.Flags().BoolVar(&rateLimitJSON, "json", false, "Output as JSON")
+
+	rateLimitCmd.AddCommand(rateLimitAcquireCmd)
+	rateLimitCmd.AddCommand(rateLimitStatusCmd)
+	rootCmd.AddCommand(rateLimitCmd)
+}
+
+func runRateLimitAcquire(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwd()
+	if err != nil || townRoot == "" {
+		return nil
+	}
+	settings, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(townRoot))
+	if err != nil || len(settings.RateLimits) == 0 {
+		return nil
+	}
+
+	provider := rateLimitProvider
+	if provider == "" {
+		provider = currentAgentProvider(townRoot)
+	}
+	agent := rateLimitAgent
+	if agent == "" {
+		agent = deriveSessionName()
+	}
+	if agent == "" {
+		agent = detectCurrentTmuxSession()
+	}
+
+	res, err := ratelimit.New(townRoot, settings.RateLimits).Acquire(context.Background(), provider, agent)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ratelimit: %v\n", err)
+		return nil
+	}
+	if res.TimedOut {
+		fmt.Fprintf(os.Stderr, "ratelimit: %s bucket empty after %s; proceeding\n", provider, res.Waited.Round(time.Millisecond))
+	}
+	return nil
+}
+
+// currentAgentProvider returns the runtime provider of the current role's agent.
+func currentAgentProvider(townRoot string) string {
+	role := config.ExtractSimpleRole(os.Getenv("GT_ROLE"))
+	var rigPath string
+	if rigName := os.Getenv("GT_RIG"); rigName != "" {
+		rigPath = filepath.Join(townRoot, rigName)
+	}
+	var rc *config.RuntimeConfig
+	if role != "" {
+		rc = config.ResolveRoleAgentConfig(role, townRoot, rigPath)
+	} else {
+		rc = config.ResolveAgentConfig(townRoot, rigPath)
+	}
+	if rc.Provider == "" {
+		return "claude"
+	}
+	return rc.Provider
+}
+
+// rateLimitProviderStatus is one provider's row in 'gt ratelimit status'.
+type rateLimitProviderStatus struct {
+	Provider          string                           `json:"provider"`
+	RequestsPerMinute int                              `json:"requests_per_minute"`
+	Available         float64                          `json:"available"`
+	Granted           int64                            `json:"granted"`
+	Throttled         int64                            `json:"throttled"`
+	WaitedMs          int64                            `json:"waited_ms"`
+	Agents            map[string]*ratelimit.AgentUsage `json:"agents,omitempty"`
+}
+
+func runRateLimitStatus(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	settings, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(townRoot))
+	if err != nil {
+		return err
+	}
+	state, err := ratelimit.Load(townRoot)
+	if err != nil {
+		return err
+	}
+	statuses := rateLimitStatuses(settings, state, time.Now())
+
+	if rateLimitJSON {
+		return outputJSON(statuses)
+	}
+	if len(statuses) == 0 {
+		fmt.Println(style.Dim.Render("No rate limits configured (rate_limits in settings/config.json)"))
+		return nil
+	}
+	for _, st := range statuses {
+		fmt.Printf("%s %s: %.0f token(s) available, %d/min\n", style.Bold.Render("●"), st.Provider, st.Available, st.RequestsPerMinute)
+		fmt.Printf("  %d granted, %s\n", st.Granted, formatThrottled(st.Throttled, st.WaitedMs))
+
+		agents := make([]string, 0, len(st.Agents))
+		for a := range st.Agents {
+			agents = append(agents, a)
+		}
+		sort.Slice(agents, func(i, j int) bool { return st.Agents[agents[i]].Granted > st.Agents[agents[j]].Granted })
+		for _, a := range agents {
+			u := st.Agents[a]
+			fmt.Printf("    %-30s %6d  %s\n", a, u.Granted, style.Dim.Render(formatThrottled(u.Throttled, u.WaitedMs)))
+		}
+	}
+	return nil
+}
+
+// rateLimitStatuses builds the per-provider status rows, sorted by provider.
+func rateLimitStatuses(settings *config.TownSettings, state *ratelimit.State, now time.Time) []rateLimitProviderStatus {
+	var statuses []rateLimitProviderStatus
+	for provider, cfg := range settings.RateLimits {
+		if cfg == nil || cfg.RequestsPerMinute <= 0 {
+			continue
+		}
+		st := rateLimitProviderStatus{Provider: provider, RequestsPerMinute: cfg.RequestsPerMinute}
+		b := state.Buckets[provider]
+		if b == nil {
+			b = &ratelimit.Bucket{}
+		}
+		st.Available = b.Available(cfg, now)
+		st.Granted, st.Throttled, st.WaitedMs, st.Agents = b.Granted, b.Throttled, b.WaitedMs, b.Agents
+		statuses = append(statuses, st)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Provider < statuses[j].Provider })
+	return statuses
+}
+
+// formatThrottled renders a throttle count and total wait.
+func formatThrottled(throttled, waitedMs int64) string {
+	if throttled == 0 {
+		return "no throttling"
+	}
+	return strconv.FormatInt(throttled, 10) + " throttled, waited " + (time.Duration(waitedMs) * time.Millisecond).Round(time.Second).String()
+}
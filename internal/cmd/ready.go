@@ -3,6 +3,7 @@ package cmd
 import (
 	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -14,14 +15,28 @@ import (
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/customfield"
 	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/i18n"
+	"github.com/steveyegge/gastown/internal/paging"
 	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/timefmt"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
 var readyJSON bool
-var readyRig string
+var (
+	readyRig       string
+	readyGroup     string
+	readyFormat    string
+	readyPorcelain string
+	readyFields    []string
+	readyQuery     string
+	readyLimit     int
+	readyOffset    int
+	readyTop       int
+)
 
 var readyCmd = &cobra.Command{
 	Use:     "ready",
@@ -36,31 +51,66 @@ Aggregates ready issues from:
 Ready items have no blockers and can be worked immediately.
 Results are sorted by priority (highest first) then by source.
 
+Every source is read as of the same cutoff: issues created after it are
+left out, and the total notes how many shown changed while reading. The
+cutoff is the snapshot field of --json output.
+
 Examples:
   gt ready              # Show all ready work
   gt ready --json       # Output as JSON
-  gt ready --rig=gastown  # Show only one rig`,
+  gt ready --format=ndjson  # One issue per line, streamed as each rig answers
+  gt ready --porcelain   # Stable tab-separated records for scripts
+  gt ready --rig=gastown  # Show only one rig
+  gt ready --group=infra  # Show the rigs in a group
+  gt ready --field severity=S1  # Only issues with a custom field value
+  gt ready --query 'priority<=1 and label:infra'  # See 'gt help query'
+  gt ready --limit 50 --offset 50  # The second page of 50
+  gt ready --top 5   # Each source's 5 highest-priority issues, with full counts
+
+Issues are streamed from bd and counted as they arrive. With --top, only
+each source's top issues are kept, so a town with a very large backlog
+can be summarized in little memory. With --limit, each source's bd is
+asked only for the issues up to the end of the page, and the totals are
+bd's own counts of ready issues. They include issues gt hides or
+--field and --query drop, and have no priority breakdown.
+
+Each source's bd is probed first. A rig whose bd doesn't answer is shown
+as skipped rather than holding up the whole report.`,
 	RunE: runReady,
 }
 
 func init() {
 	readyCmd.Flags().BoolVar(&readyJSON, "json", false, "Output as JSON")
 	readyCmd.Flags().StringVar(&readyRig, "rig", "", "Filter to a specific rig")
+	readyCmd.Flags().StringArrayVar(&readyFields, "field", nil, "Only issues with this custom field value, as name=value (repeatable)")
+	readyCmd.Flags().StringVar(&readyQuery, "query", "", queryFlagHelp)
+	readyCmd.Flags().StringVar(&readyGroup, "group", "", "Filter to the rigs in a group")
+	readyCmd.Flags().IntVar(&readyLimit, "limit", 0, "Show at most this many issues (0 for all)")
+	readyCmd.Flags().IntVar(&readyOffset, "offset", 0, "Skip this many issues first, for the next page")
+	readyCmd.Flags().IntVar(&readyTop, "top", 0, "List only each source's N highest-priority issues; counts still cover all")
+	readyCmd.MarkFlagsMutuallyExclusive("top", "limit")
+	readyCmd.MarkFlagsMutuallyExclusive("top", "offset")
+	readyCmd.Flags().StringVar(&readyFormat, "format", "", "Output format: text, json, or ndjson (streamed, one issue per line)")
+	addPorcelainFlag(readyCmd, &readyPorcelain)
 	rootCmd.AddCommand(readyCmd)
 }
 
 // ReadySource represents ready items from a single source (town or rig).
 type ReadySource struct {
-	Name   string         `json:"name"`   // "town" or rig name
-	Issues []*beads.Issue `json:"issues"` // Ready issues from this source
-	Error  string         `json:"error,omitempty"`
+	Name    string         `json:"name"`   // "town" or rig name
+	Issues  []*beads.Issue `json:"issues"` // Ready issues from this source
+	Error   string         `json:"error,omitempty"`
+	Skipped bool           `json:"skipped,omitempty"` // bd failed its health probe, so wasn't read
 }
 
 // ReadyResult is the aggregated result of gt ready.
 type ReadyResult struct {
-	Sources  []ReadySource `json:"sources"`
-	Summary  ReadySummary  `json:"summary"`
-	TownRoot string        `json:"town_root,omitempty"`
+	Sources  []ReadySource      `json:"sources"`
+	Summary  ReadySummary       `json:"summary"`
+	TownRoot string             `json:"town_root,omitempty"`
+	Snapshot beads.SnapshotInfo `json:"snapshot"`       // Cutoff the sources were read at
+	Page     *paging.Info       `json:"page,omitempty"` // With --limit or --offset
+	Top      int                `json:"top,omitempty"`  // With --top: most issues listed per source
 }
 
 // ReadySummary provides counts for the ready report.
@@ -72,9 +122,43 @@ type ReadySummary struct {
 	P2Count  int            `json:"p2_count"`
 	P3Count  int            `json:"p3_count"`
 	P4Count  int            `json:"p4_count"`
+	// BdCounts is set with --limit: the totals are bd's raw counts of
+	// ready issues, before gt's filters and snapshot, and the priority
+	// counts are zero rather than a breakdown.
+	BdCounts bool `json:"bd_counts,omitempty"`
 }
 
 func runReady(cmd *cobra.Command, args []string) error {
+	format, err := resolveFormat(readyFormat, readyJSON)
+	if err != nil {
+		return err
+	}
+	fieldFilters, err := customfield.ParseFilters(readyFields)
+	if err != nil {
+		return err
+	}
+	q, err := parseQueryFlag(readyQuery)
+	if err != nil {
+		return err
+	}
+	if readyPorcelain != "" {
+		if err := checkPorcelainVersion(readyPorcelain); err != nil {
+			return err
+		}
+		format = formatPorcelain
+	}
+	page, err := issuePage(readyLimit, readyOffset, format)
+	if err != nil {
+		return err
+	}
+	if readyTop < 0 {
+		return fmt.Errorf("--top must not be negative")
+	}
+	var stream *ndjsonWriter
+	if format == formatNDJSON {
+		stream = newNDJSONWriter(os.Stdout)
+	}
+
 	// Find town root
 	townRoot, err := workspace.FindFromCwdOrError()
 	if err != nil {
@@ -91,7 +175,9 @@ func runReady(cmd *cobra.Command, args []string) error {
 	// Create rig manager and discover rigs
 	g := git.NewGit(townRoot)
 	mgr := rig.NewManager(townRoot, rigsConfig, g)
+	progress := startTextProgress(format, i18n.T("Discovering rigs"), 0)
 	rigs, err := mgr.DiscoverRigs()
+	progress.Stop()
 	if err != nil {
 		return fmt.Errorf("discovering rigs: %w", err)
 	}
@@ -110,35 +196,68 @@ func runReady(cmd *cobra.Command, args []string) error {
 		}
 		rigs = filtered
 	}
+	if rigs, err = filterRigsByGroup(rigs, rigsConfig, readyGroup); err != nil {
+		return err
+	}
+	rigs = filterRigsByQuery(rigs, q)
+	includeTown := readyRig == "" && readyGroup == "" && q.Covers("town")
+
+	// Cut every source off at the same moment so the totals agree
+	snap := beads.NewSnapshot()
+
+	// readyFilter drops what bd ready returns that isn't ready work here
+	readyFilter := func(name, beadsPath string) func([]*beads.Issue) []*beads.Issue {
+		formulaNames, wispIDs := getFormulaNames(beadsPath), getWispIDs(beadsPath)
+		return func(issues []*beads.Issue) []*beads.Issue {
+			// Filter out formula scaffolds (gt-579)
+			filtered := filterFormulaScaffolds(issues, formulaNames)
+			// Defense-in-depth: also filter wisps that shouldn't appear in ready work
+			filtered = filterWisps(filtered, wispIDs)
+			// Filter identity beads (agents, roles, rigs) - not actionable work
+			filtered = filterIdentityBeads(filtered)
+			return q.Filter(name, filterByCustomFields(filtered, fieldFilters))
+		}
+	}
 
 	// Collect results from all sources in parallel
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 	sources := make([]ReadySource, 0, len(rigs)+1)
+	tallies := make(map[string]*beads.Tally, len(rigs)+1)
 
-	// Fetch town beads (only if not filtering to a specific rig)
-	if readyRig == "" {
+	total := len(rigs)
+	if includeTown {
+		total++ // town beads
+	}
+	progress = startTextProgress(format, i18n.T("Checking ready work"), total)
+
+	// Fetch town beads (only if not filtering to specific rigs)
+	if includeTown {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
+			progress.Start("town")
 			townBeadsPath := beads.GetTownBeadsPath(townRoot)
-			townBeads := beads.New(townBeadsPath)
-			issues, err := townBeads.Ready()
+			var tally *beads.Tally
+			townBeads, err := healthyBeads(townBeadsPath)
+			if err == nil {
+				tally, err = readSource(townBeads.ReadyEach, readyCount(townBeads), readyFilter("town", townBeadsPath), snap, page, readyTop)
+			}
+			progress.Done("town")
 
 			mu.Lock()
 			defer mu.Unlock()
 			src := ReadySource{Name: "town"}
 			if err != nil {
 				src.Error = err.Error()
+				src.Skipped = errors.Is(err, beads.ErrUnhealthy)
+				tally = beads.NewTally(0)
 			} else {
-				// Filter out formula scaffolds (gt-579)
-				formulaNames := getFormulaNames(townBeadsPath)
-				filtered := filterFormulaScaffolds(issues, formulaNames)
-				// Defense-in-depth: also filter wisps that shouldn't appear in ready work
-				wispIDs := getWispIDs(townBeadsPath)
-				filtered = filterWisps(filtered, wispIDs)
-				// Filter identity beads (agents, roles, rigs) - not actionable work
-				src.Issues = filterIdentityBeads(filtered)
+				src.Issues = tally.Issues()
+			}
+			tallies[src.Name] = tally
+			if stream != nil {
+				stream.writeSource(src.Name, src.Issues, src.Error)
 			}
 			sources = append(sources, src)
 		}()
@@ -151,29 +270,37 @@ func runReady(cmd *cobra.Command, args []string) error {
 			defer wg.Done()
 			// Use rig root path where rig-level beads are stored
 			// BeadsPath returns rig root; redirect system handles mayor/rig routing
-			rigBeads := beads.New(r.BeadsPath())
-			issues, err := rigBeads.Ready()
+			progress.Start(r.Name)
+			var tally *beads.Tally
+			rigBeads, err := healthyBeads(r.BeadsPath())
+			if err == nil {
+				tally, err = readSource(rigBeads.ReadyEach, readyCount(rigBeads), readyFilter(r.Name, r.BeadsPath()), snap, page, readyTop)
+			}
+			progress.Done(r.Name)
 
 			mu.Lock()
 			defer mu.Unlock()
 			src := ReadySource{Name: r.Name}
 			if err != nil {
 				src.Error = err.Error()
+				src.Skipped = errors.Is(err, beads.ErrUnhealthy)
+				tally = beads.NewTally(0)
 			} else {
-				// Filter out formula scaffolds (gt-579)
-				formulaNames := getFormulaNames(r.BeadsPath())
-				filtered := filterFormulaScaffolds(issues, formulaNames)
-				// Defense-in-depth: also filter wisps that shouldn't appear in ready work
-				wispIDs := getWispIDs(r.BeadsPath())
-				filtered = filterWisps(filtered, wispIDs)
-				// Filter identity beads (agents, roles, rigs) - not actionable work
-				src.Issues = filterIdentityBeads(filtered)
+				src.Issues = tally.Issues()
+			}
+			tallies[src.Name] = tally
+			if stream != nil {
+				stream.writeSource(src.Name, src.Issues, src.Error)
 			}
 			sources = append(sources, src)
 		}(r)
 	}
 
 	wg.Wait()
+	progress.Stop()
+	if stream != nil {
+		return nil
+	}
 
 	// Sort sources: town first, then rigs alphabetically
 	sort.Slice(sources, func(i, j int) bool {
@@ -186,74 +313,96 @@ func runReady(cmd *cobra.Command, args []string) error {
 		return sources[i].Name < sources[j].Name
 	})
 
-	// Sort issues within each source by priority (lower number = higher priority)
-	for i := range sources {
-		sort.Slice(sources[i].Issues, func(a, b int) bool {
-			return sources[i].Issues[a].Priority < sources[i].Issues[b].Priority
-		})
+	// Cut the page out of the sources, taken in order
+	var pageInfo *paging.Info
+	if page.Paged() {
+		lists := make([][]*beads.Issue, len(sources))
+		for i := range sources {
+			lists[i] = sources[i].Issues
+		}
+		lists, info := pageIssueSources(lists, page)
+		for i := range sources {
+			sources[i].Issues = lists[i]
+		}
+		pageInfo = &info
 	}
 
-	// Build summary
+	// Build summary from the tallies, which count issues --top and the page
+	// left out
 	summary := ReadySummary{
 		BySource: make(map[string]int),
+		BdCounts: page.Fetch() > 0,
 	}
 	for _, src := range sources {
-		count := len(src.Issues)
-		summary.Total += count
-		summary.BySource[src.Name] = count
-		for _, issue := range src.Issues {
-			switch issue.Priority {
-			case 0:
-				summary.P0Count++
-			case 1:
-				summary.P1Count++
-			case 2:
-				summary.P2Count++
-			case 3:
-				summary.P3Count++
-			case 4:
-				summary.P4Count++
-			}
-		}
+		t := tallies[src.Name]
+		summary.Total += t.Total
+		summary.BySource[src.Name] = t.Total
+		summary.P0Count += t.ByPriority[0]
+		summary.P1Count += t.ByPriority[1]
+		summary.P2Count += t.ByPriority[2]
+		summary.P3Count += t.ByPriority[3]
+		summary.P4Count += t.ByPriority[4]
 	}
 
 	result := ReadyResult{
 		Sources:  sources,
 		Summary:  summary,
 		TownRoot: townRoot,
+		Snapshot: snap.Info(),
+		Page:     pageInfo,
+		Top:      readyTop,
 	}
 
 	// Output
-	if readyJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(result)
+	if format == formatJSON {
+		return outputJSON(result)
+	}
+	if format == formatPorcelain {
+		p := porcelainWriter{w: os.Stdout}
+		for _, src := range result.Sources {
+			writeIssueSourcePorcelain(p, "ready", src.Name, src.Issues, src.Error)
+		}
+		return nil
 	}
 
 	return printReadyHuman(result)
 }
 
 func printReadyHuman(result ReadyResult) error {
-	if result.Summary.Total == 0 {
-		fmt.Println("No ready work across town.")
+	shown := 0
+	for _, src := range result.Sources {
+		shown += len(src.Issues)
+	}
+	if shown == 0 {
+		if result.Page != nil && result.Page.Offset > 0 {
+			printPageHint(result.Page, 0)
+		} else if !quietFlag {
+			fmt.Println(i18n.T("No ready work across town."))
+		}
 		return nil
 	}
 
-	fmt.Printf("%s Ready work across town:\n\n", style.Bold.Render("📋"))
+	if !quietFlag {
+		fmt.Printf("%s %s\n\n", style.Bold.Render("📋"), i18n.T("Ready work across town:"))
+	}
 
 	for _, src := range result.Sources {
 		if src.Error != "" {
-			fmt.Printf("%s %s\n", style.Dim.Render(src.Name+"/"), style.Warning.Render("(error: "+src.Error+")"))
+			fmt.Printf("%s %s\n", style.Dim.Render(src.Name+"/"), style.Warning.Render(i18n.Sprintf("(error: %s)", src.Error)))
 			continue
 		}
 
-		count := len(src.Issues)
-		if count == 0 {
-			fmt.Printf("%s %s\n", style.Dim.Render(src.Name+"/"), style.Dim.Render("(none)"))
+		// With --top or a page the source can hold more than it lists
+		count := result.Summary.BySource[src.Name]
+		if len(src.Issues) == 0 {
+			// Sources outside the page aren't empty, just not shown
+			if !quietFlag && result.Page == nil {
+				fmt.Printf("%s %s\n", style.Dim.Render(src.Name+"/"), style.Dim.Render(i18n.T("(none)")))
+			}
 			continue
 		}
 
-		fmt.Printf("%s (%d items)\n", style.Bold.Render(src.Name+"/"), count)
+		fmt.Printf("%s %s\n", style.Bold.Render(src.Name+"/"), i18n.Plural(count, "(%d item)", "(%d items)"))
 		for _, issue := range src.Issues {
 			priorityStr := fmt.Sprintf("P%d", issue.Priority)
 			var priorityStyled string
@@ -268,18 +417,23 @@ func printReadyHuman(result ReadyResult) error {
 				priorityStyled = style.Dim.Render(priorityStr)
 			}
 
-			// Truncate title if too long
-			title := issue.Title
-			if len(title) > 60 {
-				title = title[:57] + "..."
-			}
-
-			fmt.Printf("  [%s] %s %s\n", priorityStyled, style.Dim.Render(issue.ID), title)
+			// The title gets what's left of the line
+			line := fmt.Sprintf("  [%s] %s ", priorityStyled, style.Dim.Render(issue.ID))
+			fmt.Printf("%s%s\n", line, style.FitTitle(issue.Title, style.Width(line)))
+		}
+		if result.Page == nil {
+			printMoreLine(count - len(src.Issues))
+		}
+		if !quietFlag {
+			fmt.Println()
 		}
-		fmt.Println()
 	}
 
 	// Summary line
+	if quietFlag {
+		return nil
+	}
+
 	parts := []string{}
 	if result.Summary.P0Count > 0 {
 		parts = append(parts, fmt.Sprintf("%d P0", result.Summary.P0Count))
@@ -297,15 +451,153 @@ func printReadyHuman(result ReadyResult) error {
 		parts = append(parts, fmt.Sprintf("%d P4", result.Summary.P4Count))
 	}
 
-	if len(parts) > 0 {
-		fmt.Printf("Total: %d items ready (%s)\n", result.Summary.Total, strings.Join(parts, ", "))
+	if result.Summary.BdCounts {
+		fmt.Println(i18n.Plural(result.Summary.Total, "Total: %d item ready (bd's count, before filters)", "Total: %d items ready (bd's count, before filters)"))
+	} else if len(parts) > 0 {
+		fmt.Println(i18n.Plural(result.Summary.Total, "Total: %d item ready (%s)", "Total: %d items ready (%s)", strings.Join(parts, ", ")))
 	} else {
-		fmt.Printf("Total: %d items ready\n", result.Summary.Total)
+		fmt.Println(i18n.Plural(result.Summary.Total, "Total: %d item ready", "Total: %d items ready"))
 	}
 
+	printPageHint(result.Page, shown)
+	printSnapshotLine(result.Snapshot)
 	return nil
 }
 
+// issuePage validates --limit and --offset for an aggregate issue command.
+// Streamed output goes out as each source answers, so it can't be paged.
+func issuePage(limit, offset int, format string) (paging.Page, error) {
+	page := paging.Page{Limit: limit, Offset: offset}
+	if err := page.Validate(); err != nil {
+		return page, err
+	}
+	if page.Paged() && format == formatNDJSON {
+		return page, fmt.Errorf("--limit and --offset can't be used with --format ndjson")
+	}
+	return page, nil
+}
+
+// readSource reads one source into a tally keeping its top issues (all of
+// them for zero top), dropping what keep rejects and what snap cuts off.
+// Issues are folded in as bd's output is decoded. For a page, only the
+// leading issues the page needs are read (see fetchPage), and the total
+// is bd's raw count of the source, which keep and snap don't apply to,
+// with no priority breakdown. On error
+// the tally holds only what was read before the failure, so callers count
+// none of a failed source.
+func readSource(each func(limit int, fn func(*beads.Issue)) error, count func() (int, error), keep func([]*beads.Issue) []*beads.Issue, snap *beads.Snapshot, page paging.Page, top int) (*beads.Tally, error) {
+	tally := beads.NewTally(top)
+	if need := page.Fetch(); need > 0 {
+		fetch := func(limit int) ([]*beads.Issue, error) {
+			var issues []*beads.Issue
+			err := each(limit, func(issue *beads.Issue) { issues = append(issues, issue) })
+			return issues, err
+		}
+		issues, err := fetchPage(fetch, keep, need)
+		if err != nil {
+			return tally, err
+		}
+		for _, issue := range snap.Cut(issues) {
+			tally.Add(issue)
+		}
+		// The page's own priorities would pass for the source's
+		total, err := count()
+		if err != nil {
+			return tally, err
+		}
+		tally.Total, tally.ByPriority = total, [5]int{}
+		return tally, nil
+	}
+
+	one := make([]*beads.Issue, 1)
+	err := each(0, func(issue *beads.Issue) {
+		one[0] = issue
+		for _, kept := range snap.Cut(keep(one)) {
+			tally.Add(kept)
+		}
+	})
+	return tally, err
+}
+
+// fetchPage reads enough of a source for a page of an aggregate: at least
+// need issues that pass keep, when the source has that many. Need is
+// pushed down to bd as a limit, doubled while keep drops too many. A zero
+// need reads everything.
+func fetchPage(fetch func(limit int) ([]*beads.Issue, error), keep func([]*beads.Issue) []*beads.Issue, need int) ([]*beads.Issue, error) {
+	limit := need
+	for {
+		issues, err := fetch(limit)
+		if err != nil {
+			return nil, err
+		}
+		kept := keep(issues)
+		if need == 0 || len(kept) >= need || len(issues) < limit {
+			return kept, nil
+		}
+		limit *= 2
+	}
+}
+
+// readyCount adapts Counts to readSource, for the totals of a page.
+func readyCount(b *beads.Beads) func() (int, error) {
+	return func() (int, error) {
+		counts, err := b.Counts()
+		if err != nil {
+			return 0, err
+		}
+		return counts.Ready, nil
+	}
+}
+
+// pageIssueSources cuts a page out of sources' issues, taken in order as
+// one list, and returns each source's share of it.
+func pageIssueSources(lists [][]*beads.Issue, page paging.Page) ([][]*beads.Issue, paging.Info) {
+	type entry struct {
+		source int
+		issue  *beads.Issue
+	}
+	var all []entry
+	for i, issues := range lists {
+		for _, issue := range issues {
+			all = append(all, entry{i, issue})
+		}
+	}
+	cut, info := paging.Apply(all, page)
+	paged := make([][]*beads.Issue, len(lists))
+	for _, e := range cut {
+		paged[e.source] = append(paged[e.source], e.issue)
+	}
+	return paged, info
+}
+
+// printMoreLine tells how many of a source's issues --top left unlisted.
+func printMoreLine(more int) {
+	if more > 0 {
+		fmt.Printf("  %s\n", style.Dim.Render(i18n.Sprintf("… %d more", more)))
+	}
+}
+
+// printPageHint tells where a page of shown results sits, and how to get
+// the next one.
+func printPageHint(info *paging.Info, shown int) {
+	if info == nil || quietFlag {
+		return
+	}
+	if hint := info.Hint(shown); hint != "" {
+		fmt.Println(style.Dim.Render(hint))
+	}
+}
+
+// printSnapshotLine prints the cutoff an aggregate was read at, and how
+// many of the issues shown changed while it was being read.
+func printSnapshotLine(info beads.SnapshotInfo) {
+	line := i18n.Sprintf("As of %s", timefmt.ExactSeconds(info.At))
+	if info.Changed > 0 {
+		line += " " + i18n.Plural(info.Changed, "(%d item changed while reading)", "(%d items changed while reading)")
+	}
+	fmt.Println(style.Dim.Render(line))
+}
+
 // getFormulaNames reads the formulas directory and returns a set of formula names.
 // Formula names are derived from filenames by removing the ".formula.toml" suffix.
 func getFormulaNames(beadsPath string) map[string]bool {
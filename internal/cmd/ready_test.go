@@ -1,11 +1,13 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/paging"
 )
 
 func TestGetFormulaNames(t *testing.T) {
@@ -148,3 +150,139 @@ func TestFilterFormulaScaffolds_DotInNonScaffold(t *testing.T) {
 		t.Errorf("got %d issues, want 2 (non-formula dots should not filter)", len(filtered))
 	}
 }
+
+func TestFetchPage(t *testing.T) {
+	// bd returns up to limit issues; every third one is filtered out
+	var source []*beads.Issue
+	for i := 0; i < 20; i++ {
+		source = append(source, &beads.Issue{ID: fmt.Sprintf("gt-%d", i), Priority: i % 3})
+	}
+	var limits []int
+	fetch := func(limit int) ([]*beads.Issue, error) {
+		limits = append(limits, limit)
+		if limit == 0 || limit > len(source) {
+			return source, nil
+		}
+		return source[:limit], nil
+	}
+	keep := func(issues []*beads.Issue) []*beads.Issue {
+		var kept []*beads.Issue
+		for _, issue := range issues {
+			if issue.Priority != 2 {
+				kept = append(kept, issue)
+			}
+		}
+		return kept
+	}
+
+	tests := []struct {
+		need       int
+		wantLen    int
+		wantLimits []int
+	}{
+		{0, 14, []int{0}},
+		{4, 6, []int{4, 8}},     // 4 keep 3, so 8 keep 6
+		{12, 14, []int{12, 24}}, // 12 keep 8, then bd runs out
+		{30, 14, []int{30}},     // bd runs out first
+	}
+	for _, tt := range tests {
+		limits = nil
+		got, err := fetchPage(fetch, keep, tt.need)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != tt.wantLen {
+			t.Errorf("need %d: got %d issues, want %d", tt.need, len(got), tt.wantLen)
+		}
+		if fmt.Sprint(limits) != fmt.Sprint(tt.wantLimits) {
+			t.Errorf("need %d: fetched with limits %v, want %v", tt.need, limits, tt.wantLimits)
+		}
+	}
+}
+
+func TestPageIssueSources(t *testing.T) {
+	issue := func(id string) *beads.Issue { return &beads.Issue{ID: id} }
+	lists := [][]*beads.Issue{
+		{issue("hq-1"), issue("hq-2")},
+		nil,
+		{issue("gt-1"), issue("gt-2"), issue("gt-3")},
+	}
+	paged, info := pageIssueSources(lists, paging.Page{Limit: 2, Offset: 1})
+	got := make([]string, len(paged))
+	for i, issues := range paged {
+		for _, is := range issues {
+			got[i] += is.ID + " "
+		}
+	}
+	if want := []string{"hq-2 ", "", "gt-1 "}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("page = %q, want %q", got, want)
+	}
+	if !info.More || info.NextOffset != 3 {
+		t.Errorf("info = %+v, want more at offset 3", info)
+	}
+}
+
+func TestReadSource(t *testing.T) {
+	var source []*beads.Issue
+	for i := 0; i < 10; i++ {
+		source = append(source, &beads.Issue{ID: fmt.Sprintf("gt-%d", i), Priority: (10 - i) % 4})
+	}
+	var read int
+	each := func(limit int, fn func(*beads.Issue)) error {
+		for i, issue := range source {
+			if limit > 0 && i >= limit {
+				break
+			}
+			read++
+			fn(issue)
+		}
+		return nil
+	}
+	// Drop priority 3
+	keep := func(issues []*beads.Issue) []*beads.Issue {
+		var kept []*beads.Issue
+		for _, issue := range issues {
+			if issue.Priority != 3 {
+				kept = append(kept, issue)
+			}
+		}
+		return kept
+	}
+
+	// bd's count, which the page's total comes from: it ignores keep
+	count := func() (int, error) { return len(source), nil }
+
+	tests := []struct {
+		name     string
+		page     paging.Page
+		top      int
+		wantIDs  []string
+		total    int
+		wantRead int
+	}{
+		{"everything", paging.Page{}, 0, []string{"gt-2", "gt-6", "gt-1", "gt-5", "gt-9", "gt-0", "gt-4", "gt-8"}, 8, 10},
+		{"top", paging.Page{}, 3, []string{"gt-2", "gt-6", "gt-1"}, 8, 10},
+		// Only the page's issues and one more are read; bd counts the
+		// rest, unfiltered
+		{"page", paging.Page{Limit: 2}, 0, []string{"gt-2", "gt-1", "gt-0"}, 10, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			read = 0
+			tally, err := readSource(each, count, keep, beads.NewSnapshot(), tt.page, tt.top)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			for _, issue := range tally.Issues() {
+				ids = append(ids, issue.ID)
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(tt.wantIDs) || tally.Total != tt.total {
+				t.Errorf("readSource = %v (total %d), want %v (total %d)", ids, tally.Total, tt.wantIDs, tt.total)
+			}
+			if read != tt.wantRead {
+				t.Errorf("read %d issues from bd, want %d", read, tt.wantRead)
+			}
+		})
+	}
+}
@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 
@@ -378,9 +377,7 @@ func runRefineryStatus(cmd *cobra.Command, args []string) error {
 		if sessionInfo != nil {
 			output.Session = sessionInfo.Name
 		}
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(output)
+		return outputJSON(output)
 	}
 
 	// Human-readable output
@@ -418,9 +415,7 @@ func runRefineryQueue(cmd *cobra.Command, args []string) error {
 
 	// JSON output
 	if refineryQueueJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(queue)
+		return outputJSON(queue)
 	}
 
 	// Human-readable output
@@ -654,9 +649,7 @@ func runRefineryUnclaimed(cmd *cobra.Command, args []string) error {
 
 	// JSON output
 	if refineryUnclaimedJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(unclaimed)
+		return outputJSON(unclaimed)
 	}
 
 	// Human-readable output
@@ -698,13 +691,14 @@ func runRefineryReady(cmd *cobra.Command, args []string) error {
 
 	// JSON output
 	if refineryReadyJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(ready)
+		return outputJSON(ready)
 	}
 
 	// Human-readable output
 	fmt.Printf("%s Ready MRs for '%s':\n\n", style.Bold.Render("🚀"), rigName)
+	if freeze, err := eng.QueueFreeze(); err == nil && freeze.Frozen() {
+		fmt.Printf("  %s\n\n", style.Warning.Render("⚠ Queue frozen by "+freeze.Reason()+": hotfixes only"))
+	}
 
 	if len(ready) == 0 {
 		fmt.Printf("  %s\n", style.Dim.Render("(none ready)"))
@@ -742,9 +736,7 @@ func runRefineryBlocked(cmd *cobra.Command, args []string) error {
 
 	// JSON output
 	if refineryBlockedJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(blocked)
+		return outputJSON(blocked)
 	}
 
 	// Human-readable output
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// Refinery process command flags
+var (
+	refineryProcessLimit int
+	refineryProcessJSON  bool
+)
+
+var refineryProcessCmd = &cobra.Command{
+	Use:   "process [rig]",
+	Short: "Validate ready MRs in parallel and land them in order",
+	Long: `Claim the ready MRs and process them as a batch.
+
+Up to merge_queue.max_concurrent MRs per target are validated at once.
+Each runs its test suites against the predicted state of the target once
+the MRs ahead of it land: a speculative merge of the target and those MRs,
+in a pooled scratch worktree. Landings stay serial and in queue order,
+through the usual gates, PR checks, and merge.
+
+When an MR fails or is held, or the target moves somewhere the queue
+didn't predict, the MRs validated after it are validated again against
+the new target. Size merge_queue.worktree_pool to at least max_concurrent
+plus one so validations don't fall back to throwaway worktrees.
+
+  "merge_queue": {"max_concurrent": 4, "worktree_pool": 5}
+
+Examples:
+  gt refinery process
+  gt refinery process gastown --limit 8`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRefineryProcess,
+}
+
+func init() {
+	refineryProcessCmd.Flags().IntVar(&refineryProcessLimit, "limit", 0, "Process at most this many MRs (0 = all ready)")
+	refineryProcessCmd.Flags().BoolVar(&refineryProcessJSON, "json", false, "Output as JSON")
+
+	refineryCmd.AddCommand(refineryProcessCmd)
+}
+
+func runRefineryProcess(cmd *cobra.Command, args []string) error {
+	rigName := ""
+	if len(args) > 0 {
+		rigName = args[0]
+	}
+	_, r, rigName, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+	eng := refinery.NewEngineer(r)
+	if err := eng.LoadConfig(); err != nil {
+		return fmt.Errorf("loading merge queue config: %w", err)
+	}
+	if refineryProcessJSON {
+		eng.SetOutput(cmd.ErrOrStderr())
+	}
+
+	// Settle MRs a crashed run left mid-merge, then hold the processing
+	// lock so a second run can't claim the same MRs
+	reconciled, err := eng.ReconcileState(cmd.Context())
+	if errors.Is(err, refinery.ErrProcessing) {
+		return fmt.Errorf("refinery for '%s' is already processing", rigName)
+	} else if err != nil {
+		return fmt.Errorf("reconciling refinery state: %w", err)
+	}
+	for _, r := range reconciled {
+		style.PrintWarning("%s was left %s by an earlier run: %s", r.MR, r.Phase, r.Action)
+	}
+	lock, err := eng.State().LockProcessing()
+	if err != nil {
+		return fmt.Errorf("locking refinery state: %w", err)
+	}
+	if lock == nil {
+		return fmt.Errorf("refinery for '%s' is already processing", rigName)
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	ready, err := eng.ListReadyMRs()
+	if err != nil {
+		return fmt.Errorf("listing ready MRs: %w", err)
+	}
+	if refineryProcessLimit > 0 && len(ready) > refineryProcessLimit {
+		ready = ready[:refineryProcessLimit]
+	}
+	worker := rigName + "/refinery"
+	claimed := ready[:0]
+	for _, mr := range ready {
+		if err := eng.ClaimMR(mr.ID, worker); err != nil {
+			style.PrintWarning("could not claim %s: %v", mr.ID, err)
+			continue
+		}
+		claimed = append(claimed, mr)
+	}
+	if len(claimed) == 0 {
+		if refineryProcessJSON {
+			return outputJSON([]refinery.BatchResult{})
+		}
+		fmt.Printf("%s No ready MRs for '%s'\n", style.Dim.Render("○"), rigName)
+		return nil
+	}
+
+	results := eng.ProcessBatch(cmd.Context(), claimed)
+
+	// Release what the batch didn't get to, or held
+	done := make(map[string]bool, len(results))
+	for _, res := range results {
+		done[res.MR.ID] = !res.Result.Deferred
+	}
+	for _, mr := range claimed {
+		if !done[mr.ID] {
+			_ = eng.ReleaseMR(mr.ID)
+		}
+	}
+
+	if refineryProcessJSON {
+		return outputJSON(results)
+	}
+
+	fmt.Printf("\n%s Processed %d MR(s) for '%s':\n", style.Bold.Render("⚙"), len(results), rigName)
+	for _, res := range results {
+		var status string
+		switch {
+		case res.Result.Success:
+			status = style.Success.Render("merged") + " " + shortSHA(res.Result.MergeCommit)
+		case res.Result.Deferred:
+			status = style.Dim.Render("held") + ": " + res.Result.Error
+		default:
+			status = style.Error.Render("failed") + ": " + res.Result.Error
+		}
+		fmt.Printf("  %s %s\n", res.MR.ID, status)
+		if res.Validations > 1 {
+			fmt.Printf("    %s\n", style.Dim.Render(fmt.Sprintf("validated %d times", res.Validations)))
+		}
+	}
+	return nil
+}
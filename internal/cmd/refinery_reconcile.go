@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// Refinery reconcile command flags
+var (
+	refineryReconcileDryRun bool
+	refineryReconcileForce  bool
+	refineryReconcileJSON   bool
+)
+
+var refineryReconcileCmd = &cobra.Command{
+	Use:   "reconcile [rig]",
+	Short: "Settle MRs a crashed refinery left in flight",
+	Long: `Settle the MRs the refinery's state store still shows in flight.
+
+The refinery journals each MR it claims and merges under
+<rig>/.runtime/refinery before acting on it. After a crash, an MR can be
+left claimed, or merged on the forge but never closed. Reconciling checks
+each one against beads and the forge:
+
+  finished  It landed; the MR and its issue are closed as merged
+  released  It didn't land; it's back in the queue
+  dropped   Beads already shows it finished or deleted
+  kept      Its fate couldn't be determined; run reconcile again later
+
+gt refinery start reconciles automatically. Run this by hand after a
+crash while the refinery is down. It refuses while the refinery session
+is running unless --force is given.
+
+Examples:
+  gt refinery reconcile
+  gt refinery reconcile gastown --dry-run`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRefineryReconcile,
+}
+
+func init() {
+	refineryReconcileCmd.Flags().BoolVarP(&refineryReconcileDryRun, "dry-run", "n", false, "List the in-flight MRs without changing anything")
+	refineryReconcileCmd.Flags().BoolVarP(&refineryReconcileForce, "force", "f", false, "Reconcile even while the refinery session is running")
+	refineryReconcileCmd.Flags().BoolVar(&refineryReconcileJSON, "json", false, "Output as JSON")
+
+	refineryCmd.AddCommand(refineryReconcileCmd)
+}
+
+func runRefineryReconcile(cmd *cobra.Command, args []string) error {
+	rigName := ""
+	if len(args) > 0 {
+		rigName = args[0]
+	}
+	mgr, r, rigName, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+	eng := refinery.NewEngineer(r)
+
+	if refineryReconcileDryRun {
+		inflight, err := eng.State().List()
+		if err != nil {
+			return fmt.Errorf("reading refinery state: %w", err)
+		}
+		if refineryReconcileJSON {
+			return outputJSON(inflight)
+		}
+		if len(inflight) == 0 {
+			fmt.Printf("%s No MRs in flight for '%s'\n", style.Dim.Render("○"), rigName)
+			return nil
+		}
+		fmt.Printf("%s %d MR(s) in flight for '%s':\n", style.Bold.Render("⚙"), len(inflight), rigName)
+		for _, st := range inflight {
+			line := fmt.Sprintf("  %s %s", st.MR, style.Warning.Render(st.Phase))
+			if st.PR != 0 {
+				line += fmt.Sprintf(" (PR #%d)", st.PR)
+			}
+			fmt.Printf("%s %s\n", line, style.Dim.Render(st.At.Local().Format("2006-01-02 15:04")))
+		}
+		return nil
+	}
+
+	if running, _ := mgr.IsRunning(); running && !refineryReconcileForce {
+		return fmt.Errorf("refinery for '%s' is running; stop it first or use --force", rigName)
+	}
+	if err := eng.LoadConfig(); err != nil {
+		return fmt.Errorf("loading merge queue config: %w", err)
+	}
+	if refineryReconcileJSON {
+		eng.SetOutput(cmd.ErrOrStderr())
+	}
+	results, err := eng.ReconcileState(cmd.Context())
+	if errors.Is(err, refinery.ErrProcessing) {
+		return fmt.Errorf("refinery for '%s' is processing MRs; try again when it's done", rigName)
+	} else if err != nil {
+		return fmt.Errorf("reconciling refinery state: %w", err)
+	}
+
+	if refineryReconcileJSON {
+		if results == nil {
+			results = []refinery.Reconciled{}
+		}
+		return outputJSON(results)
+	}
+	if len(results) == 0 {
+		fmt.Printf("%s No MRs in flight for '%s'\n", style.Dim.Render("○"), rigName)
+		return nil
+	}
+	fmt.Printf("\n%s Reconciled %d MR(s) for '%s':\n", style.Bold.Render("⚙"), len(results), rigName)
+	for _, res := range results {
+		var action string
+		switch res.Action {
+		case refinery.ReconcileFinished:
+			action = style.Success.Render("finished") + " " + shortSHA(res.MergeCommit)
+		case refinery.ReconcileKept:
+			action = style.Warning.Render("kept")
+		default:
+			action = style.Dim.Render(res.Action)
+		}
+		fmt.Printf("  %s %s %s\n", res.MR, style.Dim.Render("("+res.Phase+")"), action)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+var refinerySigningKeyCmd = &cobra.Command{
+	Use:   "signing-key [rig]",
+	Short: "Create the refinery's own key for signing landings",
+	Long: `Create an ssh key the refinery signs landed commits with, and an
+allowed_signers file that vouches for it, in <rig>/refinery/.signing.
+
+The key is used when merge_queue.signing sets no key of its own. Register
+the printed public key with your forge as a signing key so landings show
+as verified there too. An existing key is kept.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRefinerySigningKey,
+}
+
+func init() {
+	refineryCmd.AddCommand(refinerySigningKeyCmd)
+}
+
+func runRefinerySigningKey(cmd *cobra.Command, args []string) error {
+	rigName := ""
+	if len(args) > 0 {
+		rigName = args[0]
+	}
+	_, r, _, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+	eng := refinery.NewEngineer(r)
+	if err := eng.LoadConfig(); err != nil {
+		return fmt.Errorf("loading merge queue config: %w", err)
+	}
+	cfg := eng.Config().Signing
+	if cfg == nil {
+		cfg = &config.SigningConfig{}
+	}
+	if cfg.Format == config.SigningFormatOpenPGP || cfg.Key != "" {
+		return fmt.Errorf("merge_queue.signing already names a key; the refinery's own key is only used when it doesn't")
+	}
+
+	s := refinery.ResolveSigning(r.Path, r.Name, cfg)
+	key, err := refinery.CreateSigningKey(r.Path, s)
+	if err != nil {
+		return err
+	}
+	pub, err := os.ReadFile(key + ".pub")
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s Refinery signing key for %s: %s\n", style.Success.Render("✓"), r.Name, key)
+	fmt.Printf("  Signs as: %s <%s>\n", s.Name, s.Email)
+	fmt.Printf("  Public key: %s\n", strings.TrimSpace(string(pub)))
+	fmt.Printf("  Allowed signers: %s\n", refinery.AllowedSignersPath(r.Path))
+	if eng.Config().Signing == nil {
+		fmt.Printf("\n  Enable signing with \"signing\": {} in merge_queue of %s/config.json\n", r.Name)
+	}
+	return nil
+}
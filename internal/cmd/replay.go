@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/replay"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+var replayCmd = &cobra.Command{
+	Use:     "replay <recording>",
+	GroupID: GroupDiag,
+	Short:   "Re-run a recorded command against its recorded bd and git output",
+	Long: `Re-run a command recorded with gt --record, answering every bd and git
+call from the recording instead of running it.
+
+A recording holds the command line, a snapshot of the town's registry
+files, and each bd/git invocation with its output and exit code. Replay
+rebuilds a stub town from the snapshot in a temp directory and runs the
+same gt command there, so a maintainer can reproduce a user's report
+without access to their town. A call that wasn't recorded fails with
+"replay: no recorded call", which usually means the code path changed.
+
+Recording:
+  gt --record /tmp/blocked.rec blocked
+  GT_RECORD=/tmp/blocked.rec gt blocked
+
+Replaying:
+  gt replay /tmp/blocked.rec          # Re-run the command
+  gt replay /tmp/blocked.rec --list   # Show the recorded calls`,
+	Args: cobra.ExactArgs(1),
+	RunE: runReplay,
+}
+
+var (
+	replayList bool
+	replayKeep bool
+)
+
+func init() {
+	replayCmd.Flags().BoolVar(&replayList, "list", false, "List the recorded calls instead of replaying")
+	replayCmd.Flags().BoolVar(&replayKeep, "keep", false, "Keep the stub town after replaying")
+	rootCmd.AddCommand(replayCmd)
+}
+
+func runReplay(cmd *cobra.Command, args []string) error {
+	path, err := filepath.Abs(args[0])
+	if err != nil {
+		return err
+	}
+	rec, err := replay.Load(path)
+	if err != nil {
+		return err
+	}
+
+	h := rec.Header
+	fmt.Fprintf(os.Stderr, "%s gt %s (recorded %s with gt %s, %d call(s))\n",
+		style.Dim.Render("replay:"), strings.Join(h.Args, " "), h.CreatedAt.Local().Format("2006-01-02 15:04"), h.GTVersion, len(rec.Calls))
+
+	if replayList {
+		for _, c := range rec.Calls {
+			status := style.Success.Render("0")
+			if c.ExitCode != 0 {
+				status = style.Error.Render(fmt.Sprint(c.ExitCode))
+			} else if c.Err != "" {
+				status = style.Error.Render("err")
+			}
+			fmt.Printf("%4d  %-3s %6dms  %s %s\n", c.Seq, status, c.Millis, c.Name, strings.Join(c.Args, " "))
+		}
+		return nil
+	}
+
+	stub, err := os.MkdirTemp("", "gt-replay-")
+	if err != nil {
+		return err
+	}
+	if replayKeep {
+		fmt.Fprintf(os.Stderr, "%s stub town at %s\n", style.Dim.Render("replay:"), stub)
+	} else {
+		defer os.RemoveAll(stub)
+	}
+	dir, err := replay.Stage(rec, stub)
+	if err != nil {
+		return fmt.Errorf("staging stub town: %w", err)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	c := exec.Command(exe, h.Args...) //nolint:gosec // G204: re-running gt with recorded args
+	c.Dir = dir
+	c.Env = append(os.Environ(), replay.ReplayEnv+"="+path)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return NewSilentExit(exitErr.ExitCode())
+		}
+		return err
+	}
+	return nil
+}
+
+// withoutFlag removes a string flag and its value from args, in either
+// "--flag value" or "--flag=value" form.
+func withoutFlag(args []string, flag string) []string {
+	out := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == flag:
+			i++ // Skip the value too
+		case strings.HasPrefix(args[i], flag+"="):
+		default:
+			out = append(out, args[i])
+		}
+	}
+	return out
+}
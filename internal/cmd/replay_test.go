@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestWithoutFlag(t *testing.T) {
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"--record", "/tmp/x.rec", "blocked"}, []string{"blocked"}},
+		{[]string{"blocked", "--record=/tmp/x.rec", "--json"}, []string{"blocked", "--json"}},
+		{[]string{"mq", "list"}, []string{"mq", "list"}},
+	}
+	for _, tt := range tests {
+		if got := withoutFlag(tt.args, "--record"); !slices.Equal(got, tt.want) {
+			t.Errorf("withoutFlag(%v) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
@@ -185,9 +185,7 @@ func runResume(cmd *cobra.Command, args []string) error {
 }
 
 func outputResumeStatus(status ResumeStatus) error {
-	enc := json.NewEncoder(os.Stdout)
-	enc.SetIndent("", "  ")
-	return enc.Encode(status)
+	return outputJSON(status)
 }
 
 func displayResumeStatus(status ResumeStatus, parked *ParkedWork) error {
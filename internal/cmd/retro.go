@@ -0,0 +1,292 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/memo"
+	"github.com/steveyegge/gastown/internal/retro"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// RetroLabel marks retrospective beads.
+const RetroLabel = "gt:retro"
+
+// Retro command flags
+var (
+	retroJSON bool
+	retroMemo bool
+	retroBead bool
+)
+
+var retroCmd = &cobra.Command{
+	Use:     "retro <convoy-milestone-or-incident-id>",
+	GroupID: GroupWork,
+	Short:   "Generate a retrospective skeleton for a convoy, milestone, or incident",
+	Long: `Assemble a retrospective skeleton for a convoy, milestone, or incident:
+
+  - Opened, closed, and how long it took
+  - The work involved: a convoy's or milestone's tracked issues, with
+    their durations
+  - Merge requests filed for that work, and how long they sat in the queue
+  - A timeline from the event log (and the incident's own timeline)
+  - Failures encountered: merge and landing failures, reverts, session
+    deaths, escalations
+
+The facts are filled in; what went well, what went wrong, and action items
+are left for you. Output is Markdown. --memo saves it to the decision log
+(topic "retro"), --bead files it as a bead.
+
+Closing a convoy ('gt convoy close') or milestone ('gt milestone close'), or
+resolving an incident ('gt incident resolve'), saves a retro memo
+automatically.
+
+Examples:
+  gt retro hq-cv-abc
+  gt retro gt-inc42 --memo
+  gt retro hq-cv-abc --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRetro,
+}
+
+func init() {
+	retroCmd.Flags().BoolVar(&retroJSON, "json", false, "Output as JSON")
+	retroCmd.Flags().BoolVar(&retroMemo, "memo", false, "Save the retrospective as a memo")
+	retroCmd.Flags().BoolVar(&retroBead, "bead", false, "File the retrospective as a bead in town beads")
+
+	rootCmd.AddCommand(retroCmd)
+}
+
+func runRetro(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	r, err := buildRetro(townRoot, args[0])
+	if err != nil {
+		return err
+	}
+
+	if retroMemo {
+		m, err := saveRetroMemo(townRoot, r)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s Saved retrospective as %s\n", style.Success.Render("✓"), m.ID)
+	}
+	if retroBead {
+		issue, err := beads.New(townRoot).Create(beads.CreateOptions{
+			Title:       "Retro: " + r.Subject.Title,
+			Type:        "task",
+			Priority:    3,
+			Description: r.Markdown(),
+			Actor:       detectSender(),
+			Labels:      []string{RetroLabel},
+		})
+		if err != nil {
+			return fmt.Errorf("filing retrospective bead: %w", err)
+		}
+		fmt.Printf("%s Filed retrospective as %s\n", style.Success.Render("✓"), issue.ID)
+	}
+	if retroMemo || retroBead {
+		return nil
+	}
+
+	if retroJSON {
+		return outputJSON(r)
+	}
+	fmt.Print(r.Markdown())
+	return nil
+}
+
+// buildRetro gathers a convoy's, milestone's, or incident's facts into a
+// retrospective.
+func buildRetro(townRoot, id string) (*retro.Retro, error) {
+	bd := beads.New(resolveBeadDir(id))
+	issue, err := bd.Show(id)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", id, err)
+	}
+
+	subject := retro.Subject{
+		ID:     issue.ID,
+		Title:  issue.Title,
+		Status: issue.Status,
+		Opened: parseRetroTime(issue.CreatedAt),
+		Closed: parseRetroTime(issue.ClosedAt),
+	}
+	var items []retro.Item
+	var notes []retro.Entry
+	issueIDs := map[string]bool{issue.ID: true}
+	rigs := make(map[string]bool)
+
+	switch {
+	case issue.Type == "convoy" || beads.HasLabel(issue, beads.MilestoneLabel):
+		subject.Kind = retro.KindConvoy
+		if issue.Type != "convoy" {
+			subject.Kind = retro.KindMilestone
+		}
+		subject.Resolution = issue.CloseReason
+		townBeads, err := getTownBeadsDir()
+		if err != nil {
+			return nil, err
+		}
+		tracked, err := getTrackedIssues(townBeads, id)
+		if err != nil {
+			return nil, fmt.Errorf("listing tracked issues: %w", err)
+		}
+		// One bd show per database instead of one per tracked issue
+		batches := make(map[string]*beads.Batch)
+		batchOf := make(map[string]*beads.Batch, len(tracked))
+		for _, t := range tracked {
+			dir := resolveBeadDir(t.ID)
+			if batches[dir] == nil {
+				batches[dir] = beads.New(dir).Batch()
+			}
+			batches[dir].Want(t.ID)
+			batchOf[t.ID] = batches[dir]
+		}
+		for _, t := range tracked {
+			item := retro.Item{ID: t.ID, Title: t.Title, Status: t.Status, Assignee: t.Assignee}
+			if ti, err := batchOf[t.ID].Show(t.ID); err == nil {
+				item.Created, item.Closed = parseRetroTime(ti.CreatedAt), parseRetroTime(ti.ClosedAt)
+				if item.Assignee == "" {
+					item.Assignee = ti.Assignee
+				}
+			}
+			items = append(items, item)
+			issueIDs[t.ID] = true
+			if rigName, _, err := getRigForBead(t.ID); err == nil {
+				rigs[rigName] = true
+			}
+		}
+
+	case beads.HasLabel(issue, beads.IncidentLabel):
+		subject.Kind = retro.KindIncident
+		fields := beads.ParseIncidentFields(issue.Description)
+		subject.Rig, subject.Resolution = fields.Rig, fields.Resolution
+		if t := parseRetroTime(fields.DeclaredAt); !t.IsZero() {
+			subject.Opened = t
+		}
+		if t := parseRetroTime(fields.ResolvedAt); !t.IsZero() {
+			subject.Closed = t
+		}
+		for _, e := range fields.Timeline {
+			notes = append(notes, retro.Entry{At: e.At, Actor: e.Actor, Type: "incident_note", Text: e.Text})
+		}
+		if fields.Rig != "" {
+			rigs[fields.Rig] = true
+		}
+
+	default:
+		return nil, fmt.Errorf("%s is not a convoy, milestone, or incident", id)
+	}
+
+	mrs := retroMergeRequests(subject, rigs, issueIDs)
+	evts, err := events.Read(townRoot, nil)
+	if err != nil {
+		return nil, fmt.Errorf("reading event log: %w", err)
+	}
+	return retro.Build(subject, items, mrs, notes, evts, time.Now()), nil
+}
+
+// retroMergeRequests returns the MRs in rigs filed for issueIDs. For an
+// incident it also includes hotfix MRs filed while the incident was open.
+func retroMergeRequests(subject retro.Subject, rigs, issueIDs map[string]bool) []retro.MergeRequest {
+	end := subject.Closed
+	if end.IsZero() {
+		end = time.Now()
+	}
+	var out []retro.MergeRequest
+	for rigName := range rigs {
+		_, r, err := getRig(rigName)
+		if err != nil {
+			continue
+		}
+		bd := beads.New(r.BeadsPath())
+		for _, status := range []string{"open", "closed"} {
+			list, err := bd.List(beads.ListOptions{Status: status, Type: "merge-request", Priority: -1})
+			if err != nil {
+				continue
+			}
+			for _, mr := range list {
+				fields := beads.ParseMRFields(mr)
+				if fields == nil {
+					continue
+				}
+				created := parseRetroTime(mr.CreatedAt)
+				hotfix := subject.Kind == retro.KindIncident && beads.HasLabel(mr, beads.HotfixLabel) &&
+					!created.Before(subject.Opened) && !created.After(end)
+				if !issueIDs[fields.SourceIssue] && !hotfix {
+					continue
+				}
+				closeReason := mr.CloseReason
+				if closeReason == "" {
+					closeReason = fields.CloseReason
+				}
+				out = append(out, retro.MergeRequest{
+					ID:          mr.ID,
+					Issue:       fields.SourceIssue,
+					Branch:      fields.Branch,
+					Status:      mr.Status,
+					Merged:      mr.Status == "closed" && fields.MergeCommit != "",
+					CloseReason: closeReason,
+					Created:     created,
+					Closed:      parseRetroTime(mr.ClosedAt),
+				})
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Created.Before(out[j].Created) })
+	return out
+}
+
+// saveRetroMemo records a retrospective in the decision log.
+func saveRetroMemo(townRoot string, r *retro.Retro) (*memo.Memo, error) {
+	issues := []string{r.Subject.ID}
+	for _, it := range r.Items {
+		issues = append(issues, it.ID)
+	}
+	m := &memo.Memo{
+		Title:  fmt.Sprintf("Retro: %s %s: %s", r.Subject.Kind, r.Subject.ID, r.Subject.Title),
+		Topics: []string{"retro", r.Subject.Kind},
+		Rig:    r.Subject.Rig,
+		Author: detectSender(),
+		Issues: issues,
+		Body:   r.Markdown(),
+	}
+	if err := memo.NewStore(townRoot).Add(m, time.Now()); err != nil {
+		return nil, fmt.Errorf("saving retrospective memo: %w", err)
+	}
+	return m, nil
+}
+
+// recordRetro saves a retro memo for a convoy, milestone, or incident that
+// just closed.
+// Best-effort: a failure is a warning, never a failed close.
+func recordRetro(id string) {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return
+	}
+	r, err := buildRetro(townRoot, id)
+	if err == nil {
+		var m *memo.Memo
+		if m, err = saveRetroMemo(townRoot, r); err == nil {
+			fmt.Printf("  Retrospective: %s (gt memo show %s)\n", style.Dim.Render(m.ID), m.ID)
+			return
+		}
+	}
+	style.PrintWarning("generating retrospective for %s: %v", id, err)
+}
+
+// parseRetroTime parses a bead timestamp, returning the zero time if empty or malformed.
+func parseRetroTime(s string) time.Time {
+	t, _ := time.Parse(time.RFC3339, s)
+	return t
+}
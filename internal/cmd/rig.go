@@ -64,6 +64,12 @@ The command also:
   - Creates ~/gt/plugins/ (town-level) if it doesn't exist
   - Creates <rig>/plugins/ (rig-level)
 
+Monorepos: several rigs can share one repository, each owning a subtree
+with its own beads, merge queue, and agents. --subdir scopes the new rig to
+a subtree; --subrig-of takes the repository (and shares git objects) from
+an existing rig, so the git URL can be omitted. The refinery rejects MRs
+that change files another sub-rig owns (see 'gt rig owner').
+
 Use --adopt to register an existing directory instead of creating new:
   - Reads existing config.json if present
   - Auto-detects git URL from origin remote (git-url argument not required)
@@ -72,7 +78,8 @@ Use --adopt to register an existing directory instead of creating new:
 Example:
   gt rig add gastown https://github.com/steveyegge/gastown
   gt rig add my-project git@github.com:user/repo.git --prefix mp
-  gt rig add existing-rig --adopt`,
+  gt rig add existing-rig --adopt
+  gt rig add api --subrig-of mono --subdir services/api`,
 	Args: cobra.RangeArgs(1, 2),
 	RunE: runRigAdd,
 }
@@ -269,6 +276,8 @@ var (
 	rigAddAdopt        bool
 	rigAddAdoptURL     string
 	rigAddAdoptForce   bool
+	rigAddSubdir       string
+	rigAddSubrigOf     string
 	rigResetHandoff    bool
 	rigResetMail       bool
 	rigResetStale      bool
@@ -282,6 +291,7 @@ var (
 	rigStopNuclear     bool
 	rigRestartForce    bool
 	rigRestartNuclear  bool
+	rigListGroup       string
 )
 
 func init() {
@@ -298,12 +308,16 @@ func init() {
 	rigCmd.AddCommand(rigStatusCmd)
 	rigCmd.AddCommand(rigStopCmd)
 
+	rigListCmd.Flags().StringVar(&rigListGroup, "group", "", "Only list the rigs in this group")
+
 	rigAddCmd.Flags().StringVar(&rigAddPrefix, "prefix", "", "Beads issue prefix (default: derived from name)")
 	rigAddCmd.Flags().StringVar(&rigAddLocalRepo, "local-repo", "", "Local repo path to share git objects (optional)")
 	rigAddCmd.Flags().StringVar(&rigAddBranch, "branch", "", "Default branch name (default: auto-detected from remote)")
 	rigAddCmd.Flags().BoolVar(&rigAddAdopt, "adopt", false, "Adopt an existing directory instead of creating new")
 	rigAddCmd.Flags().StringVar(&rigAddAdoptURL, "url", "", "Git remote URL for --adopt (default: auto-detected from origin)")
 	rigAddCmd.Flags().BoolVar(&rigAddAdoptForce, "force", false, "With --adopt, register even if git remote cannot be detected")
+	rigAddCmd.Flags().StringVar(&rigAddSubdir, "subdir", "", "Monorepo subtree this rig owns (e.g. services/api)")
+	rigAddCmd.Flags().StringVar(&rigAddSubrigOf, "subrig-of", "", "Existing rig whose repository this sub-rig shares")
 
 	rigResetCmd.Flags().BoolVar(&rigResetHandoff, "handoff", false, "Clear handoff content")
 	rigResetCmd.Flags().BoolVar(&rigResetMail, "mail", false, "Clear stale mail messages")
@@ -332,11 +346,32 @@ func runRigAdd(cmd *cobra.Command, args []string) error {
 		return runRigAdopt(cmd, args)
 	}
 
+	// A sub-rig takes its repository from its parent rig
+	var gitURL string
+	if rigAddSubrigOf != "" {
+		if rigAddSubdir == "" {
+			return fmt.Errorf("--subrig-of requires --subdir")
+		}
+		_, parent, err := getRig(rigAddSubrigOf)
+		if err != nil {
+			return fmt.Errorf("parent rig %s: %w", rigAddSubrigOf, err)
+		}
+		gitURL = parent.GitURL
+		if rigAddLocalRepo == "" {
+			rigAddLocalRepo = filepath.Join(parent.Path, "mayor", "rig")
+		}
+	}
+	if len(args) >= 2 {
+		if gitURL != "" && !rig.SameRepo(gitURL, args[1]) {
+			return fmt.Errorf("%s is not rig %s's repository (%s)", args[1], rigAddSubrigOf, gitURL)
+		}
+		gitURL = args[1]
+	}
+
 	// Normal add mode requires git URL
-	if len(args) < 2 {
+	if gitURL == "" {
 		return fmt.Errorf("git-url is required (or use --adopt to register an existing directory)")
 	}
-	gitURL := args[1]
 
 	if !isGitRemoteURL(gitURL) {
 		return fmt.Errorf("invalid git URL %q: expected a remote URL (https://, git@, ssh://, git://)\n\nTo register a local directory, use:\n  gt rig add %s --adopt", gitURL, name)
@@ -373,6 +408,9 @@ func runRigAdd(cmd *cobra.Command, args []string) error {
 	if rigAddLocalRepo != "" {
 		fmt.Printf("  Local repo: %s\n", rigAddLocalRepo)
 	}
+	if rigAddSubdir != "" {
+		fmt.Printf("  Subtree:    %s\n", rigAddSubdir)
+	}
 
 	startTime := time.Now()
 
@@ -383,11 +421,25 @@ func runRigAdd(cmd *cobra.Command, args []string) error {
 		BeadsPrefix:   rigAddPrefix,
 		LocalRepo:     rigAddLocalRepo,
 		DefaultBranch: rigAddBranch,
+		Subdir:        rigAddSubdir,
 	})
 	if err != nil {
 		return fmt.Errorf("adding rig: %w", err)
 	}
 
+	// Register under the rigs lock: concurrent gt rig add processes (gt rig
+	// import runs several) would otherwise overwrite each other's entries in
+	// rigs.json, daemon.json, and routes.jsonl.
+	unlock, err := lockRigsRegistry(townRoot)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if fresh, err := config.LoadRigsConfig(rigsPath); err == nil && fresh.Rigs != nil {
+		fresh.Rigs[name] = rigsConfig.Rigs[name]
+		rigsConfig = fresh
+	}
+
 	// Save updated rigs config
 	if err := config.SaveRigsConfig(rigsPath, rigsConfig); err != nil {
 		return fmt.Errorf("saving rigs config: %w", err)
@@ -425,6 +477,7 @@ func runRigAdd(cmd *cobra.Command, args []string) error {
 			fmt.Printf("  %s Could not update routes.jsonl: %v\n", style.Warning.Render("!"), err)
 		}
 	}
+	unlock()
 
 	// Create rig identity bead
 	if newRig.Config.Prefix != "" && beadsWorkDir != "" {
@@ -503,16 +556,39 @@ func runRigList(cmd *cobra.Command, args []string) error {
 
 	fmt.Printf("Rigs in %s:\n\n", townRoot)
 
+	groupRigs, err := groupRigSet(rigsConfig, rigListGroup)
+	if err != nil {
+		return err
+	}
+
 	for name := range rigsConfig.Rigs {
+		if groupRigs != nil && !groupRigs[name] {
+			continue
+		}
 		r, err := mgr.GetRig(name)
 		if err != nil {
 			fmt.Printf("  %s %s\n", style.Warning.Render("!"), name)
 			continue
 		}
 
+		if archive := rigsConfig.Rigs[name].Archived; archive != nil {
+			fmt.Printf("  %s %s\n", style.Bold.Render(name), style.Dim.Render("(archived "+archive.ArchivedAt.Format("2006-01-02")+")"))
+			if archive.Reason != "" {
+				fmt.Printf("    Reason: %s\n", archive.Reason)
+			}
+			fmt.Println()
+			continue
+		}
+
 		summary := r.Summary()
 		fmt.Printf("  %s\n", style.Bold.Render(name))
 		fmt.Printf("    Polecats: %d  Crew: %d\n", summary.PolecatCount, summary.CrewCount)
+		if r.Subdir != "" {
+			fmt.Printf("    Subtree: %s/\n", r.Subdir)
+		}
+		if groups := rigsConfig.Rigs[name].Groups; len(groups) > 0 {
+			fmt.Printf("    Groups: %s\n", strings.Join(groups, ", "))
+		}
 
 		agents := []string{}
 		if summary.HasRefinery {
@@ -1023,6 +1099,12 @@ func runRigStart(cmd *cobra.Command, args []string) error {
 			continue
 		}
 
+		if r.Archived {
+			fmt.Printf("%s Rig '%s' is archived - skipping (use 'gt rig restore' first)\n",
+				style.Warning.Render("⚠"), rigName)
+			continue
+		}
+
 		// Check if rig is parked or docked
 		cfg := wisp.NewConfig(townRoot, rigName)
 		status := cfg.GetString("status")
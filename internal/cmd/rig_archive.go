@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/polecat"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/tmux"
+	"github.com/steveyegge/gastown/internal/witness"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var rigArchiveCmd = &cobra.Command{
+	Use:   "archive <rig>",
+	Short: "Put a rig in cold storage (removes its checkouts)",
+	Long: `Archive a rig that is no longer worked on.
+
+Archiving a rig:
+  - Stops the witness, refinery, and polecat sessions
+  - Exports every issue to <rig>/archive/issues-<time>.jsonl
+  - Removes .repo.git, mayor/rig, refinery/rig, crew/, and polecats/
+  - Marks the rig archived in mayor/rigs.json
+
+An archived rig stays in 'gt rig list' but is left out of discovery, so
+aggregate commands and the daemon skip it. Its merge queue is closed and
+agents can't be started or slung work there. config.json, settings/, and
+the rig beads are kept.
+
+Archiving refuses if any clone has uncommitted changes, stashes, or
+unpushed commits; --force archives anyway and loses them.
+
+Use 'gt rig restore' to re-clone the repository and bring the rig back.
+
+Examples:
+  gt rig archive oldproject
+  gt rig archive oldproject --reason "sunset in Q3"`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRigArchive,
+}
+
+var rigRestoreCmd = &cobra.Command{
+	Use:   "restore <rig>",
+	Short: "Bring an archived rig back",
+	Long: `Restore an archived rig.
+
+Re-clones the repository into .repo.git and mayor/rig, recreates the
+refinery worktree and the crew/ and polecats/ directories, and clears the
+archived mark. Does NOT start agents (use 'gt rig start' for that); crew
+workspaces are re-added with 'gt crew add'.
+
+Examples:
+  gt rig restore oldproject`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRigRestore,
+}
+
+var (
+	rigArchiveReason string
+	rigArchiveForce  bool
+)
+
+func init() {
+	rigCmd.AddCommand(rigArchiveCmd)
+	rigCmd.AddCommand(rigRestoreCmd)
+
+	rigArchiveCmd.Flags().StringVar(&rigArchiveReason, "reason", "", "Why the rig is archived (shown in gt rig list)")
+	rigArchiveCmd.Flags().BoolVar(&rigArchiveForce, "force", false, "Archive despite unsaved work or a failed beads snapshot")
+}
+
+func runRigArchive(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	unlock, err := lockRigsRegistry(townRoot)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	rigsPath := filepath.Join(townRoot, "mayor", "rigs.json")
+	rigsConfig, err := config.LoadRigsConfig(rigsPath)
+	if err != nil {
+		return fmt.Errorf("loading rigs config: %w", err)
+	}
+	mgr := rig.NewManager(townRoot, rigsConfig, git.NewGit(townRoot))
+	r, err := mgr.GetRig(name)
+	if err != nil {
+		return fmt.Errorf("rig '%s' not found", name)
+	}
+	if r.Archived {
+		fmt.Printf("%s Rig %s is already archived\n", style.Dim.Render("•"), name)
+		return nil
+	}
+
+	fmt.Printf("Archiving rig %s...\n", style.Bold.Render(name))
+	stopRigAgents(r)
+
+	result, err := mgr.ArchiveRig(name, rig.ArchiveOptions{Reason: rigArchiveReason, Force: rigArchiveForce})
+	if err != nil {
+		return fmt.Errorf("archiving %s: %w", name, err)
+	}
+	if err := config.SaveRigsConfig(rigsPath, rigsConfig); err != nil {
+		return fmt.Errorf("saving rigs config: %w", err)
+	}
+
+	fmt.Printf("%s Rig %s archived\n", style.Success.Render("✓"), name)
+	if result.Snapshot != "" {
+		fmt.Printf("  Beads snapshot: %s (%d issues)\n", result.Snapshot, result.IssueCount)
+	} else {
+		fmt.Printf("  %s No beads snapshot (export failed)\n", style.Warning.Render("!"))
+	}
+	fmt.Printf("  Freed %.1f MB\n", float64(result.FreedBytes)/(1<<20))
+	fmt.Printf("  Use '%s' to bring it back\n", style.Dim.Render("gt rig restore "+name))
+	return nil
+}
+
+func runRigRestore(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	unlock, err := lockRigsRegistry(townRoot)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	rigsPath := filepath.Join(townRoot, "mayor", "rigs.json")
+	rigsConfig, err := config.LoadRigsConfig(rigsPath)
+	if err != nil {
+		return fmt.Errorf("loading rigs config: %w", err)
+	}
+	mgr := rig.NewManager(townRoot, rigsConfig, git.NewGit(townRoot))
+
+	fmt.Printf("Restoring rig %s...\n", style.Bold.Render(name))
+	if _, err := mgr.RestoreRig(name); err != nil {
+		if errors.Is(err, rig.ErrRigNotFound) {
+			return fmt.Errorf("rig '%s' not found", name)
+		}
+		return fmt.Errorf("restoring %s: %w", name, err)
+	}
+	if err := config.SaveRigsConfig(rigsPath, rigsConfig); err != nil {
+		return fmt.Errorf("saving rigs config: %w", err)
+	}
+
+	fmt.Printf("%s Rig %s restored\n", style.Success.Render("✓"), name)
+	fmt.Printf("  Use '%s' to start agents\n", style.Dim.Render("gt rig start "+name))
+	return nil
+}
+
+// stopRigAgents stops a rig's witness, refinery, and polecat sessions,
+// warning about any that fail to stop.
+func stopRigAgents(r *rig.Rig) {
+	t := tmux.NewTmux()
+
+	if running, _ := t.HasSession(fmt.Sprintf("gt-%s-witness", r.Name)); running {
+		fmt.Printf("  Stopping witness...\n")
+		if err := witness.NewManager(r).Stop(); err != nil {
+			fmt.Printf("  %s Failed to stop witness: %v\n", style.Warning.Render("!"), err)
+		}
+	}
+
+	if running, _ := t.HasSession(fmt.Sprintf("gt-%s-refinery", r.Name)); running {
+		fmt.Printf("  Stopping refinery...\n")
+		if err := refinery.NewManager(r).Stop(); err != nil {
+			fmt.Printf("  %s Failed to stop refinery: %v\n", style.Warning.Render("!"), err)
+		}
+	}
+
+	polecatMgr := polecat.NewSessionManager(t, r)
+	if infos, err := polecatMgr.List(); err == nil && len(infos) > 0 {
+		fmt.Printf("  Stopping %d polecat session(s)...\n", len(infos))
+		if err := polecatMgr.StopAll(false); err != nil {
+			fmt.Printf("  %s Failed to stop polecat sessions: %v\n", style.Warning.Render("!"), err)
+		}
+	}
+}
+
+// checkRigNotArchived returns an error if the rig is archived.
+func checkRigNotArchived(townRoot, rigName string) error {
+	rigsConfig, err := config.LoadRigsConfig(filepath.Join(townRoot, "mayor", "rigs.json"))
+	if err != nil {
+		return nil
+	}
+	if entry, ok := rigsConfig.Rigs[rigName]; ok && entry.Archived != nil {
+		return fmt.Errorf("rig '%s' is archived - use 'gt rig restore %s' first", rigName, rigName)
+	}
+	return nil
+}
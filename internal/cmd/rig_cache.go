@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+var rigCacheClean bool
+
+var rigCacheCmd = &cobra.Command{
+	Use:   "cache <rig>",
+	Short: "Show or clean a rig's shared build caches",
+	Long: `Show or clean the shared build caches for a rig.
+
+Build caches are configured in the rig's settings/config.json:
+
+  "build_cache": {
+    "caches": ["go", "npm"],
+    "dir": ".runtime/cache",
+    "env": {"CCACHE_DIR": "{cache_dir}/ccache"},
+    "roles": ["refinery", "polecat", "crew"]
+  }
+
+Each preset exports the variables its toolchain already honors (e.g. go sets
+GOCACHE and GOMODCACHE) to refinery test runs and to agent sessions started
+for the listed roles, so MR validation reuses a warm cache.
+
+Presets: ` + strings.Join(config.BuildCachePresets(), ", ") + `
+
+Examples:
+  gt rig cache gastown           # Show cache location, variables, and size
+  gt rig cache gastown --clean   # Delete cached artifacts`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRigCache,
+}
+
+func init() {
+	rigCacheCmd.Flags().BoolVar(&rigCacheClean, "clean", false, "Delete the cache directory")
+	rigCmd.AddCommand(rigCacheCmd)
+}
+
+func runRigCache(cmd *cobra.Command, args []string) error {
+	_, r, err := getRig(args[0])
+	if err != nil {
+		return err
+	}
+
+	settings, err := config.LoadRigSettings(config.RigSettingsPath(r.Path))
+	if err != nil || settings.BuildCache == nil {
+		fmt.Printf("%s No build caches configured for %s\n", style.Dim.Render("○"), r.Name)
+		fmt.Printf("  Enable with: gt rig settings set %s build_cache.caches '[\"go\"]'\n", r.Name)
+		return nil
+	}
+
+	cfg := settings.BuildCache
+	dir := config.BuildCacheDir(r.Path, cfg)
+
+	if rigCacheClean {
+		// The Go module cache is read-only; make it writable so it can be removed
+		_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err == nil && d.IsDir() {
+				_ = os.Chmod(path, 0755) //nolint:gosec // G302: cache dirs under the rig
+			}
+			return nil
+		})
+		if err := os.RemoveAll(dir); err != nil {
+			return fmt.Errorf("removing cache: %w", err)
+		}
+		fmt.Printf("%s Cleaned build cache for %s (%s)\n", style.Success.Render("✓"), r.Name, dir)
+		return nil
+	}
+
+	fmt.Printf("%s Build cache for '%s'\n\n", style.Bold.Render("📦"), r.Name)
+	fmt.Printf("  Dir:    %s (%s)\n", dir, dirSizeHuman(dir))
+	fmt.Printf("  Caches: %s\n", strings.Join(cfg.Caches, ", "))
+
+	env := config.BuildCacheEnv(r.Path, "", cfg)
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if len(keys) > 0 {
+		fmt.Printf("\n  %s\n", style.Bold.Render("Environment:"))
+		for _, k := range keys {
+			fmt.Printf("    %s=%s\n", k, env[k])
+		}
+	}
+	return nil
+}
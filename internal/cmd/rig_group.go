@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var rigGroupCmd = &cobra.Command{
+	Use:   "group",
+	Short: "Manage rig groups",
+	RunE:  requireSubcommand,
+	Long: `Manage named groups of rigs (client-a, infra, experiments).
+
+A rig can belong to any number of groups. Groups are stored on each rig's
+entry in mayor/rigs.json and select several rigs at once with --group on
+commands that take --rig: blocked, ready, gc, broadcast, session list, and
+dog dispatch.
+
+Examples:
+  gt rig group add infra dolt_ops monitoring
+  gt rig group remove infra monitoring
+  gt rig group list
+  gt ready --group infra`,
+}
+
+var rigGroupAddCmd = &cobra.Command{
+	Use:   "add <group> <rig>...",
+	Short: "Add rigs to a group",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  runRigGroupAdd,
+}
+
+var rigGroupRemoveCmd = &cobra.Command{
+	Use:   "remove <group> <rig>...",
+	Short: "Remove rigs from a group",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  runRigGroupRemove,
+}
+
+var rigGroupListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List groups and their rigs",
+	Args:  cobra.NoArgs,
+	RunE:  runRigGroupList,
+}
+
+var rigGroupListJSON bool
+
+func init() {
+	rigCmd.AddCommand(rigGroupCmd)
+	rigGroupCmd.AddCommand(rigGroupAddCmd)
+	rigGroupCmd.AddCommand(rigGroupRemoveCmd)
+	rigGroupCmd.AddCommand(rigGroupListCmd)
+
+	rigGroupListCmd.Flags().BoolVar(&rigGroupListJSON, "json", false, "Output as JSON")
+}
+
+func runRigGroupAdd(cmd *cobra.Command, args []string) error {
+	return updateRigGroup(args[0], args[1:], func(c *config.RigsConfig, rigName, group string) (bool, error) {
+		return c.AddToGroup(rigName, group)
+	}, "added to")
+}
+
+func runRigGroupRemove(cmd *cobra.Command, args []string) error {
+	return updateRigGroup(args[0], args[1:], func(c *config.RigsConfig, rigName, group string) (bool, error) {
+		return c.RemoveFromGroup(rigName, group)
+	}, "removed from")
+}
+
+// updateRigGroup applies change to each rig under the rigs lock and saves
+// rigs.json once.
+func updateRigGroup(group string, rigNames []string, change func(*config.RigsConfig, string, string) (bool, error), verb string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	unlock, err := lockRigsRegistry(townRoot)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	rigsPath := filepath.Join(townRoot, "mayor", "rigs.json")
+	rigsConfig, err := config.LoadRigsConfig(rigsPath)
+	if err != nil {
+		return fmt.Errorf("loading rigs config: %w", err)
+	}
+
+	var changed []string
+	for _, rigName := range rigNames {
+		ok, err := change(rigsConfig, rigName, group)
+		if err != nil {
+			return err
+		}
+		if ok {
+			changed = append(changed, rigName)
+		}
+	}
+	if len(changed) == 0 {
+		fmt.Printf("%s Nothing to change\n", style.Dim.Render("•"))
+		return nil
+	}
+	if err := config.SaveRigsConfig(rigsPath, rigsConfig); err != nil {
+		return fmt.Errorf("saving rigs config: %w", err)
+	}
+	fmt.Printf("%s %s %s group %s\n", style.SuccessPrefix, strings.Join(changed, ", "), verb, style.Bold.Render(group))
+	return nil
+}
+
+func runRigGroupList(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	rigsConfig, err := config.LoadRigsConfig(filepath.Join(townRoot, "mayor", "rigs.json"))
+	if err != nil {
+		return fmt.Errorf("loading rigs config: %w", err)
+	}
+	groups := rigsConfig.Groups()
+
+	if rigGroupListJSON {
+		return outputJSON(groups)
+	}
+	if len(groups) == 0 {
+		fmt.Println("No rig groups.")
+		fmt.Printf("\nCreate one with: %s\n", style.Dim.Render("gt rig group add <group> <rig>..."))
+		return nil
+	}
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("  %s  %s\n", style.Bold.Render(name), strings.Join(groups[name], ", "))
+	}
+	return nil
+}
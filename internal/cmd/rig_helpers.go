@@ -2,7 +2,11 @@ package cmd
 
 import (
 	"fmt"
+	"path/filepath"
+	"sync"
 
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
 	"github.com/steveyegge/gastown/internal/git"
@@ -10,15 +14,19 @@ import (
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
-// checkRigNotParkedOrDocked checks if a rig is parked or docked and returns
-// an error if so. This prevents starting agents on rigs that have been
-// intentionally taken offline.
+// checkRigNotParkedOrDocked checks if a rig is parked, docked, or archived
+// and returns an error if so. This prevents starting agents on rigs that
+// have been intentionally taken offline.
 func checkRigNotParkedOrDocked(rigName string) error {
 	townRoot, r, err := getRig(rigName)
 	if err != nil {
 		return err
 	}
 
+	if r.Archived {
+		return checkRigNotArchived(townRoot, rigName)
+	}
+
 	if IsRigParked(townRoot, rigName) {
 		return fmt.Errorf("rig '%s' is parked - use 'gt rig unpark %s' first", rigName, rigName)
 	}
@@ -35,6 +43,18 @@ func checkRigNotParkedOrDocked(rigName string) error {
 	return nil
 }
 
+// lockRigsRegistry takes the town's exclusive lock on the rig registry
+// files (rigs.json and the rig entries in daemon.json and routes.jsonl).
+// The returned function releases it and may be called more than once.
+func lockRigsRegistry(townRoot string) (func(), error) {
+	fl := flock.New(filepath.Join(townRoot, "mayor", ".rigs.lock"))
+	if err := fl.Lock(); err != nil {
+		return nil, fmt.Errorf("locking rig registry: %w", err)
+	}
+	var once sync.Once
+	return func() { once.Do(func() { _ = fl.Unlock() }) }, nil
+}
+
 // getRig finds the town root and retrieves the specified rig.
 // This is the common boilerplate extracted from get*Manager functions.
 // Returns the town root path and rig instance.
@@ -59,3 +79,49 @@ func getRig(rigName string) (string, *rig.Rig, error) {
 
 	return townRoot, r, nil
 }
+
+// getRigForBead finds the rig that owns a bead from its ID prefix.
+func getRigForBead(beadID string) (string, *rig.Rig, error) {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return "", nil, fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	rigName := beads.GetRigNameForPrefix(townRoot, beads.ExtractPrefix(beadID))
+	if rigName == "" {
+		return "", nil, fmt.Errorf("no rig owns %s", beadID)
+	}
+	return getRig(rigName)
+}
+
+// groupRigSet returns the names of the rigs in group for --group
+// filtering, or nil if group is empty. A group with no members is an
+// error; it's almost always a typo.
+func groupRigSet(rigsConfig *config.RigsConfig, group string) (map[string]bool, error) {
+	if group == "" {
+		return nil, nil
+	}
+	members := rigsConfig.GroupMembers(group)
+	if len(members) == 0 {
+		return nil, fmt.Errorf("no rigs in group %q (see 'gt rig group list')", group)
+	}
+	set := make(map[string]bool, len(members))
+	for _, name := range members {
+		set[name] = true
+	}
+	return set, nil
+}
+
+// filterRigsByGroup keeps the rigs in group; an empty group keeps them all.
+func filterRigsByGroup(rigs []*rig.Rig, rigsConfig *config.RigsConfig, group string) ([]*rig.Rig, error) {
+	set, err := groupRigSet(rigsConfig, group)
+	if err != nil || set == nil {
+		return rigs, err
+	}
+	var filtered []*rig.Rig
+	for _, r := range rigs {
+		if set[r.Name] {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered, nil
+}
@@ -0,0 +1,309 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var rigImportCmd = &cobra.Command{
+	Use:   "import [git-url...]",
+	Short: "Clone and register many repositories as rigs",
+	Long: `Import repositories in bulk, adding each as a rig in parallel.
+
+Repositories come from the arguments, a file of URLs (one per line, # for
+comments), or a GitHub org or user listed with the gh CLI. Each rig is
+named after its repository and gets a beads prefix that is unique in the
+town. Output of each 'gt rig add' goes to logs/rig-import/<name>.log.
+
+--template copies an existing rig's settings/ into every imported rig.
+
+Progress is saved to daemon/rig-import.json. Re-running the same import
+skips rigs that were added and retries those that failed, keeping the
+prefixes chosen the first time.
+
+Examples:
+  gt rig import https://github.com/acme/api.git https://github.com/acme/web.git
+  gt rig import --file repos.txt --parallel 8
+  gt rig import --org acme --template api
+  gt rig import --user alice --ssh --include-forks --dry-run`,
+	RunE: runRigImport,
+}
+
+var (
+	rigImportOrg             string
+	rigImportUser            string
+	rigImportFile            string
+	rigImportParallel        int
+	rigImportTemplate        string
+	rigImportSSH             bool
+	rigImportIncludeForks    bool
+	rigImportIncludeArchived bool
+	rigImportLimit           int
+	rigImportDryRun          bool
+)
+
+func init() {
+	rigCmd.AddCommand(rigImportCmd)
+	rigImportCmd.Flags().StringVar(&rigImportOrg, "org", "", "Import the repositories of a GitHub organization")
+	rigImportCmd.Flags().StringVar(&rigImportUser, "user", "", "Import the repositories of a GitHub user")
+	rigImportCmd.Flags().StringVar(&rigImportFile, "file", "", "Read repository URLs from a file, one per line")
+	rigImportCmd.Flags().IntVar(&rigImportParallel, "parallel", 4, "Rigs to add at once")
+	rigImportCmd.Flags().StringVar(&rigImportTemplate, "template", "", "Existing rig whose settings/ each new rig gets")
+	rigImportCmd.Flags().BoolVar(&rigImportSSH, "ssh", false, "With --org/--user, clone over SSH")
+	rigImportCmd.Flags().BoolVar(&rigImportIncludeForks, "include-forks", false, "With --org/--user, include forks")
+	rigImportCmd.Flags().BoolVar(&rigImportIncludeArchived, "include-archived", false, "With --org/--user, include archived repositories")
+	rigImportCmd.Flags().IntVar(&rigImportLimit, "limit", 200, "With --org/--user, maximum repositories to list")
+	rigImportCmd.Flags().BoolVar(&rigImportDryRun, "dry-run", false, "Show the plan without adding rigs")
+}
+
+func runRigImport(cmd *cobra.Command, args []string) error {
+	if rigImportOrg != "" && rigImportUser != "" {
+		return fmt.Errorf("--org and --user are mutually exclusive")
+	}
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	urls := append([]string{}, args...)
+	if rigImportFile != "" {
+		fileURLs, err := readImportFile(rigImportFile)
+		if err != nil {
+			return err
+		}
+		urls = append(urls, fileURLs...)
+	}
+	if owner := rigImportOrg + rigImportUser; owner != "" {
+		ghURLs, err := listGitHubRepos(owner)
+		if err != nil {
+			return err
+		}
+		urls = append(urls, ghURLs...)
+	}
+	if len(urls) == 0 {
+		return fmt.Errorf("no repositories given (pass URLs, --file, --org, or --user)")
+	}
+
+	var templatePath string
+	if rigImportTemplate != "" {
+		_, r, err := getRig(rigImportTemplate)
+		if err != nil {
+			return fmt.Errorf("template: %w", err)
+		}
+		templatePath = r.Path
+	}
+
+	rigsConfig, err := config.LoadRigsConfig(filepath.Join(townRoot, "mayor", "rigs.json"))
+	if err != nil {
+		return fmt.Errorf("loading rigs config: %w", err)
+	}
+	registered := make(map[string]string)
+	for name, entry := range rigsConfig.Rigs {
+		prefix := ""
+		if entry.BeadsConfig != nil {
+			prefix = entry.BeadsConfig.Prefix
+		}
+		registered[name] = prefix
+	}
+
+	// Resume: a previous run's prefixes are kept so a retried rig doesn't
+	// get a different one.
+	prev, err := rig.LoadImportState(townRoot)
+	if err != nil {
+		return err
+	}
+	items := rig.PlanImport(urls, registered, prev)
+
+	var pending []*rig.ImportItem
+	for _, it := range items {
+		if it.Status == rig.ImportPending {
+			pending = append(pending, it)
+		}
+	}
+
+	if rigImportDryRun {
+		for _, it := range items {
+			status := style.Dim.Render("add")
+			if it.Status == rig.ImportSkipped {
+				status = style.Dim.Render("skip (already a rig)")
+			}
+			fmt.Printf("  %-24s %-8s %s  %s\n", it.Name, it.Prefix, it.URL, status)
+		}
+		fmt.Printf("\n%d to add, %d already registered\n", len(pending), len(items)-len(pending))
+		return nil
+	}
+	if len(pending) == 0 {
+		fmt.Printf("%s All %d repositories are already rigs\n", style.SuccessPrefix, len(items))
+		return nil
+	}
+
+	state := &rig.ImportState{StartedAt: time.Now().UTC(), Template: rigImportTemplate, Items: items}
+	if err := rig.SaveImportState(townRoot, state); err != nil {
+		return fmt.Errorf("saving import state: %w", err)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	logDir := filepath.Join(townRoot, "logs", "rig-import")
+	if err := os.MkdirAll(logDir, 0755); err != nil {
+		return err
+	}
+
+	parallel := rigImportParallel
+	if parallel < 1 {
+		parallel = 1
+	}
+	fmt.Printf("Importing %d repositories (%d at a time)...\n", len(pending), parallel)
+
+	var mu sync.Mutex // Guards state, done, and stdout
+	done := 0
+	work := make(chan *rig.ImportItem)
+	var wg sync.WaitGroup
+	for i := 0; i < parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for it := range work {
+				start := time.Now()
+				err := importOne(exe, townRoot, logDir, templatePath, it)
+
+				mu.Lock()
+				done++
+				it.Log = filepath.Join("logs", "rig-import", it.Name+".log")
+				if err != nil {
+					it.Status, it.Error = rig.ImportFailed, err.Error()
+					fmt.Printf("[%d/%d] %s %s: %v\n", done, len(pending), style.Error.Render("✗"), it.Name, err)
+				} else {
+					it.Status, it.Error = rig.ImportAdded, ""
+					fmt.Printf("[%d/%d] %s %s (%.1fs)\n", done, len(pending), style.Success.Render("✓"), it.Name, time.Since(start).Seconds())
+				}
+				if err := rig.SaveImportState(townRoot, state); err != nil {
+					fmt.Fprintf(os.Stderr, "%s saving import state: %v\n", style.Warning.Render("⚠"), err)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, it := range pending {
+		work <- it
+	}
+	close(work)
+	wg.Wait()
+
+	failed := 0
+	for _, it := range pending {
+		if it.Status == rig.ImportFailed {
+			failed++
+		}
+	}
+	fmt.Println()
+	if failed > 0 {
+		fmt.Printf("%s %d added, %d failed (logs in %s)\n", style.Warning.Render("⚠"), len(pending)-failed, failed, logDir)
+		fmt.Printf("  Re-run the same command to retry the failures.\n")
+		return NewSilentExit(1)
+	}
+	fmt.Printf("%s Added %d rigs\n", style.SuccessPrefix, len(pending))
+	return nil
+}
+
+// importOne runs gt rig add for one item, logging its output, and applies
+// the template. A directory left behind by an earlier failed attempt is
+// removed first; the item is only pending if the rig isn't registered.
+func importOne(exe, townRoot, logDir, templatePath string, it *rig.ImportItem) error {
+	rigPath := filepath.Join(townRoot, it.Name)
+	if _, err := os.Stat(rigPath); err == nil {
+		if err := os.RemoveAll(rigPath); err != nil {
+			return fmt.Errorf("removing partial rig: %w", err)
+		}
+	}
+
+	logFile, err := os.Create(filepath.Join(logDir, it.Name+".log"))
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+
+	c := exec.Command(exe, "rig", "add", it.Name, it.URL, "--prefix", it.Prefix) //nolint:gosec // G204: re-running gt
+	c.Dir = townRoot
+	c.Env = append(os.Environ(), "NO_COLOR=1")
+	c.Stdout, c.Stderr = logFile, logFile
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("gt rig add failed: %w", err)
+	}
+
+	if templatePath != "" {
+		if err := rig.ApplyTemplate(templatePath, rigPath); err != nil {
+			return fmt.Errorf("applying template: %w", err)
+		}
+	}
+	return nil
+}
+
+// readImportFile reads repository URLs, one per line, skipping blanks and
+// # comments.
+func readImportFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var urls []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		urls = append(urls, line)
+	}
+	return urls, scanner.Err()
+}
+
+// listGitHubRepos lists an owner's repositories with the gh CLI.
+func listGitHubRepos(owner string) ([]string, error) {
+	out, err := exec.Command("gh", "repo", "list", owner, //nolint:gosec // G204: owner is a flag value
+		"--json", "name,url,sshUrl,isArchived,isFork", "--limit", fmt.Sprint(rigImportLimit)).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("gh repo list %s: %s", owner, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("gh repo list %s: %w (is the gh CLI installed?)", owner, err)
+	}
+	var repos []struct {
+		Name       string `json:"name"`
+		URL        string `json:"url"`
+		SSHURL     string `json:"sshUrl"`
+		IsArchived bool   `json:"isArchived"`
+		IsFork     bool   `json:"isFork"`
+	}
+	if err := json.Unmarshal(out, &repos); err != nil {
+		return nil, fmt.Errorf("parsing gh output: %w", err)
+	}
+	var urls []string
+	for _, r := range repos {
+		if (r.IsFork && !rigImportIncludeForks) || (r.IsArchived && !rigImportIncludeArchived) {
+			continue
+		}
+		if rigImportSSH {
+			urls = append(urls, r.SSHURL)
+		} else {
+			urls = append(urls, r.URL+".git")
+		}
+	}
+	return urls, nil
+}
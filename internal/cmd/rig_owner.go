@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Rig owner command flags
+var (
+	rigOwnerRig  string
+	rigOwnerJSON bool
+)
+
+var rigOwnerCmd = &cobra.Command{
+	Use:   "owner <path>...",
+	Short: "Show which rig owns paths in a shared monorepo",
+	Long: `Route paths to the rig that owns them.
+
+When several rigs share one repository (sub-rigs, see 'gt rig add
+--subdir'), each owns its subtree: the rig with the longest subdir
+containing a path owns it, else the rig registered without a subdir.
+File issues in the owning rig's beads, and keep each MR to one rig's
+subtree: the refinery rejects MRs that change another sub-rig's files.
+
+Paths are relative to the repository root. Inside a clone, paths relative
+to the current directory work too.
+
+Examples:
+  gt rig owner services/api/main.go web/src/app.ts
+  gt rig owner --rig mono services/ --json`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runRigOwner,
+}
+
+func init() {
+	rigOwnerCmd.Flags().StringVar(&rigOwnerRig, "rig", "", "Any rig sharing the repository (default: inferred from cwd)")
+	rigOwnerCmd.Flags().BoolVar(&rigOwnerJSON, "json", false, "Output as JSON")
+
+	rigCmd.AddCommand(rigOwnerCmd)
+}
+
+// PathOwner is one routed path.
+type PathOwner struct {
+	Path   string `json:"path"`
+	Rig    string `json:"rig,omitempty"` // empty when no rig owns the path
+	Prefix string `json:"prefix,omitempty"`
+	Subdir string `json:"subdir,omitempty"`
+}
+
+func runRigOwner(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	rigName := rigOwnerRig
+	if rigName == "" {
+		if rigName, err = inferRigFromCwd(townRoot); err != nil {
+			return fmt.Errorf("could not determine rig: %w\nUse --rig to name one", err)
+		}
+	}
+	mgr, r, err := loadRigManager(townRoot, rigName)
+	if err != nil {
+		return err
+	}
+	siblings := mgr.Siblings(r)
+	gitRoot, _ := getGitRoot()
+
+	owners := make([]PathOwner, 0, len(args))
+	for _, arg := range args {
+		p := PathOwner{Path: repoRelative(gitRoot, arg)}
+		if owner := rig.Owner(siblings, p.Path); owner != nil {
+			p.Rig, p.Subdir = owner.Name, owner.Subdir
+			if full, err := mgr.GetRig(owner.Name); err == nil && full.Config != nil {
+				p.Prefix = full.Config.Prefix
+			}
+		}
+		owners = append(owners, p)
+	}
+
+	if rigOwnerJSON {
+		return outputJSON(owners)
+	}
+	for _, p := range owners {
+		if p.Rig == "" {
+			fmt.Printf("%s  %s\n", p.Path, style.Dim.Render("(no owner: shared, unclaimed by any sub-rig)"))
+			continue
+		}
+		detail := "whole repository"
+		if p.Subdir != "" {
+			detail = p.Subdir + "/"
+		}
+		if p.Prefix != "" {
+			detail += ", issues " + p.Prefix + "-*"
+		}
+		fmt.Printf("%s  %s %s\n", p.Path, style.Bold.Render(p.Rig), style.Dim.Render("("+detail+")"))
+	}
+	return nil
+}
+
+// loadRigManager returns the town's rig manager and the named rig.
+func loadRigManager(townRoot, rigName string) (*rig.Manager, *rig.Rig, error) {
+	rigsConfig, err := config.LoadRigsConfig(constants.MayorRigsPath(townRoot))
+	if err != nil {
+		rigsConfig = &config.RigsConfig{Rigs: make(map[string]config.RigEntry)}
+	}
+	mgr := rig.NewManager(townRoot, rigsConfig, git.NewGit(townRoot))
+	r, err := mgr.GetRig(rigName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("rig '%s' not found", rigName)
+	}
+	return mgr, r, nil
+}
+
+// repoRelative converts a path given relative to the cwd into one relative
+// to the repository root at gitRoot. Paths outside it are returned as given.
+func repoRelative(gitRoot, p string) string {
+	if gitRoot == "" {
+		return filepath.ToSlash(filepath.Clean(p))
+	}
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return filepath.ToSlash(filepath.Clean(p))
+	}
+	rel, err := filepath.Rel(gitRoot, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+		return filepath.ToSlash(filepath.Clean(p))
+	}
+	return filepath.ToSlash(rel)
+}
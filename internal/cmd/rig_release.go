@@ -0,0 +1,312 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/release"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// Rig release command flags
+var (
+	rigReleaseBump       string
+	rigReleaseVersion    string
+	rigReleaseDryRun     bool
+	rigReleaseNoPush     bool
+	rigReleaseNoPipeline bool
+	rigReleaseForce      bool
+	rigReleaseJSON       bool
+)
+
+var rigReleaseCmd = &cobra.Command{
+	Use:   "release <rig>",
+	Short: "Cut a release from merged merge requests",
+	Long: `Cut a release for a rig: tag the release branch and record a release bead.
+
+The changelog is built from MR beads merged since the last release tag
+(grouped as in 'gt changelog'). The next version is inferred unless
+--bump or --version is given:
+
+  major  an MR recorded breaking API changes (minor while at 0.x)
+  minor  an MR closed a feature issue
+  patch  otherwise
+
+The tag is created at origin/<branch> in the rig's clone and pushed. If the
+rig's settings/config.json sets release.pipeline, that command is then run
+in the clone with GT_RELEASE_VERSION, GT_RELEASE_TAG, and GT_RELEASE_NOTES
+(a file with the release notes). The release is recorded as a closed bead
+labeled gt:release.
+
+  "release": {
+    "tag_prefix": "v",
+    "branch": "main",
+    "pipeline": "gh workflow run release.yml -f tag=$GT_RELEASE_TAG"
+  }
+
+Examples:
+  gt rig release gastown --dry-run     # Preview version and changelog
+  gt rig release gastown               # Tag, push, run pipeline, record bead
+  gt rig release gastown --bump minor
+  gt rig release gastown --version 2.0.0 --no-pipeline`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRigRelease,
+}
+
+func init() {
+	rigReleaseCmd.Flags().StringVar(&rigReleaseBump, "bump", "", "Version bump: major, minor, or patch (default: inferred)")
+	rigReleaseCmd.Flags().StringVar(&rigReleaseVersion, "version", "", "Release this exact version (e.g., 1.4.0)")
+	rigReleaseCmd.Flags().BoolVarP(&rigReleaseDryRun, "dry-run", "n", false, "Show the release without tagging")
+	rigReleaseCmd.Flags().BoolVar(&rigReleaseNoPush, "no-push", false, "Create the tag locally without pushing it")
+	rigReleaseCmd.Flags().BoolVar(&rigReleaseNoPipeline, "no-pipeline", false, "Don't run the configured release pipeline")
+	rigReleaseCmd.Flags().BoolVar(&rigReleaseForce, "force", false, "Release even if nothing was merged since the last tag")
+	rigReleaseCmd.Flags().BoolVar(&rigReleaseJSON, "json", false, "Output as JSON")
+
+	rigCmd.AddCommand(rigReleaseCmd)
+}
+
+// RigReleaseResult is the JSON output of gt rig release.
+type RigReleaseResult struct {
+	Rig      string          `json:"rig"`
+	Version  string          `json:"version"`
+	Tag      string          `json:"tag"`
+	Previous string          `json:"previous,omitempty"`
+	Bump     string          `json:"bump,omitempty"`
+	Commit   string          `json:"commit"`
+	Entries  []release.Entry `json:"entries"`
+	Notes    string          `json:"notes"`
+	Bead     string          `json:"bead,omitempty"`
+	Pushed   bool            `json:"pushed"`
+	Pipeline bool            `json:"pipeline"`
+	DryRun   bool            `json:"dry_run,omitempty"`
+}
+
+func runRigRelease(cmd *cobra.Command, args []string) error {
+	if rigReleaseBump != "" && rigReleaseVersion != "" {
+		return fmt.Errorf("--bump and --version are mutually exclusive")
+	}
+
+	_, r, err := getRig(args[0])
+	if err != nil {
+		return err
+	}
+
+	cfg := &config.ReleaseConfig{}
+	if settings, err := config.LoadRigSettings(config.RigSettingsPath(r.Path)); err == nil && settings.Release != nil {
+		cfg = settings.Release
+	}
+	prefix := config.DefaultReleaseTagPrefix
+	if cfg.TagPrefix != nil {
+		prefix = *cfg.TagPrefix
+	}
+	branch := cfg.Branch
+	if branch == "" {
+		branch = r.DefaultBranch()
+	}
+
+	repoDir := constants.RigMayorPath(r.Path)
+	g := git.NewGit(repoDir)
+	if err := g.Fetch("origin"); err != nil {
+		return fmt.Errorf("fetching origin: %w", err)
+	}
+	if err := g.FetchTags("origin"); err != nil {
+		return fmt.Errorf("fetching tags: %w", err)
+	}
+
+	// Find the last release
+	tags, err := g.Tags(prefix + "*")
+	if err != nil {
+		return fmt.Errorf("listing tags: %w", err)
+	}
+	prevTag, current, hasPrev := release.Latest(tags, prefix)
+	var since time.Time
+	if hasPrev {
+		if since, err = g.CommitTime(prevTag); err != nil {
+			return fmt.Errorf("reading %s date: %w", prevTag, err)
+		}
+	}
+
+	// Collect merged MRs since then
+	bd := beads.New(r.BeadsPath())
+	entries, err := collectMergedEntries(bd, since)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 && !rigReleaseForce {
+		fmt.Printf("%s Nothing merged in %s since %s\n", style.Dim.Render("○"), r.Name, releaseRef(prevTag))
+		return nil
+	}
+
+	// Pick the version
+	result := RigReleaseResult{Rig: r.Name, Previous: prevTag, Entries: entries, DryRun: rigReleaseDryRun}
+	var next release.Version
+	if rigReleaseVersion != "" {
+		if next, err = release.ParseVersion(strings.TrimPrefix(rigReleaseVersion, prefix), ""); err != nil {
+			return err
+		}
+	} else {
+		result.Bump = rigReleaseBump
+		if result.Bump == "" {
+			result.Bump = release.InferBump(current, entries)
+		}
+		if !hasPrev {
+			next = release.Version{Minor: 1}
+		} else if next, err = current.Bump(result.Bump); err != nil {
+			return err
+		}
+	}
+	if hasPrev && !current.Less(next) {
+		return fmt.Errorf("version %s is not newer than %s", next, prevTag)
+	}
+	result.Version = next.String()
+	result.Tag = prefix + result.Version
+	result.Notes = release.Notes(result.Tag, entries)
+
+	ref := "origin/" + branch
+	if result.Commit, err = g.Rev(ref); err != nil {
+		return fmt.Errorf("resolving %s: %w", ref, err)
+	}
+
+	if rigReleaseDryRun {
+		return printRigRelease(&result)
+	}
+
+	// Tag, push, and run the pipeline
+	if err := g.CreateTag(result.Tag, result.Commit, result.Notes); err != nil {
+		return fmt.Errorf("creating tag: %w", err)
+	}
+	if !rigReleaseNoPush {
+		if err := g.PushTag("origin", result.Tag); err != nil {
+			return fmt.Errorf("pushing tag (created locally as %s): %w", result.Tag, err)
+		}
+		result.Pushed = true
+	}
+	if cfg.Pipeline != "" && !rigReleaseNoPipeline {
+		if err := runReleasePipeline(cfg.Pipeline, repoDir, &result); err != nil {
+			return err
+		}
+		result.Pipeline = true
+	}
+
+	// Record the release
+	bead, err := bd.Create(beads.CreateOptions{
+		Title:       fmt.Sprintf("Release %s", result.Tag),
+		Type:        "release",
+		Priority:    -1,
+		Description: formatReleaseBead(&result),
+		Actor:       detectSender(),
+	})
+	if err != nil {
+		return fmt.Errorf("recording release bead: %w", err)
+	}
+	result.Bead = bead.ID
+	if err := bd.CloseWithReason("released", bead.ID); err != nil {
+		fmt.Printf("%s Could not close release bead %s: %v\n", style.Warning.Render("⚠"), bead.ID, err)
+	}
+
+	return printRigRelease(&result)
+}
+
+// runReleasePipeline runs the configured release command in the rig's clone.
+func runReleasePipeline(pipeline, dir string, result *RigReleaseResult) error {
+	notesFile, err := os.CreateTemp("", "gt-release-notes-*.md")
+	if err != nil {
+		return fmt.Errorf("writing release notes: %w", err)
+	}
+	defer func() { _ = os.Remove(notesFile.Name()) }()
+	if _, err := notesFile.WriteString(result.Notes); err != nil {
+		_ = notesFile.Close()
+		return fmt.Errorf("writing release notes: %w", err)
+	}
+	_ = notesFile.Close()
+
+	if !rigReleaseJSON {
+		fmt.Printf("%s Running release pipeline: %s\n", style.Bold.Render("🚀"), pipeline)
+	}
+	c := exec.Command("sh", "-c", pipeline) //nolint:gosec // G204: pipeline is from trusted rig settings
+	c.Dir = dir
+	c.Env = append(os.Environ(),
+		"GT_RELEASE_VERSION="+result.Version,
+		"GT_RELEASE_TAG="+result.Tag,
+		"GT_RELEASE_NOTES="+filepath.Clean(notesFile.Name()),
+	)
+	c.Stdout = os.Stderr
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("release pipeline failed (tag %s already pushed): %w", result.Tag, err)
+	}
+	return nil
+}
+
+// formatReleaseBead renders the release bead description: key fields
+// followed by the notes.
+func formatReleaseBead(result *RigReleaseResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "version: %s\n", result.Version)
+	fmt.Fprintf(&b, "tag: %s\n", result.Tag)
+	fmt.Fprintf(&b, "commit: %s\n", result.Commit)
+	if result.Previous != "" {
+		fmt.Fprintf(&b, "previous: %s\n", result.Previous)
+	}
+	mrs := make([]string, 0, len(result.Entries))
+	for _, e := range result.Entries {
+		mrs = append(mrs, e.MR)
+	}
+	if len(mrs) > 0 {
+		fmt.Fprintf(&b, "merge_requests: %s\n", strings.Join(mrs, ","))
+	}
+	b.WriteString("\n" + result.Notes)
+	return b.String()
+}
+
+func printRigRelease(result *RigReleaseResult) error {
+	if rigReleaseJSON {
+		return outputJSON(result)
+	}
+
+	if result.DryRun {
+		fmt.Printf("%s Would release %s %s (%s, %d MRs since %s)\n\n", style.Bold.Render("📦"),
+			result.Rig, result.Tag, bumpLabel(result.Bump), len(result.Entries), releaseRef(result.Previous))
+	} else {
+		fmt.Printf("%s Released %s %s at %s\n", style.Success.Render("✓"), result.Rig, result.Tag, shortSHA(result.Commit))
+		if !result.Pushed {
+			fmt.Printf("  %s\n", style.Dim.Render("Tag not pushed (--no-push)"))
+		}
+		if result.Bead != "" {
+			fmt.Printf("  Release bead: %s\n", result.Bead)
+		}
+		fmt.Println()
+	}
+	fmt.Print(result.Notes)
+	return nil
+}
+
+func releaseRef(tag string) string {
+	if tag == "" {
+		return "the beginning"
+	}
+	return tag
+}
+
+func bumpLabel(bump string) string {
+	if bump == "" {
+		return "explicit version"
+	}
+	return bump
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
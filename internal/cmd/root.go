@@ -10,7 +10,12 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/cli"
 	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/i18n"
+	"github.com/steveyegge/gastown/internal/outputversion"
+	"github.com/steveyegge/gastown/internal/replay"
+	"github.com/steveyegge/gastown/internal/sandbox"
 	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/timefmt"
 	"github.com/steveyegge/gastown/internal/ui"
 	"github.com/steveyegge/gastown/internal/version"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -32,8 +37,32 @@ func init() {
 
 It coordinates agent spawning, work distribution, and communication
 across distributed teams of AI agents working on shared codebases.`, cmdName)
+
+	rootCmd.PersistentFlags().BoolVar(&sandboxFlag, "sandbox", false,
+		"Run against a throwaway copy of the town (same as GT_SANDBOX=1)")
+	rootCmd.PersistentFlags().StringVar(&recordFlag, "record", "",
+		"Record bd and git calls to `file` for gt replay (same as GT_RECORD=file)")
+	rootCmd.PersistentFlags().BoolVarP(&quietFlag, "quiet", "q", false,
+		"Suppress progress indicators and decorative output")
+	rootCmd.PersistentFlags().BoolVar(&utcFlag, "utc", false,
+		"Show times in UTC instead of local time (same as GT_UTC=1)")
+	rootCmd.PersistentFlags().BoolVar(&asciiFlag, "ascii", false,
+		"Plain ASCII output: no emoji or box drawing (same as GT_ASCII=1)")
+	rootCmd.PersistentFlags().IntVar(&outputVersionFlag, "output-version", 0,
+		"Shape --json output as this output `version` (same as GT_OUTPUT_VERSION)")
 }
 
+var (
+	sandboxFlag bool   // gt --sandbox
+	recordFlag  string // gt --record <file>
+	quietFlag   bool   // gt --quiet, or a command's own --quiet
+	utcFlag     bool   // gt --utc
+	asciiFlag   bool   // gt --ascii
+
+	outputVersionFlag int    // gt --output-version
+	outputCommand     string // command path the JSON output belongs to, e.g. "mq status"
+)
+
 // Commands that don't require beads to be installed/checked.
 // NOTE: Gas Town has migrated to Dolt for beads storage. The bd version
 // check is obsolete. Exempt all common commands.
@@ -61,6 +90,10 @@ var beadsExemptCommands = map[string]bool{
 	"install":    true,
 	"tap":        true,
 	"dnd":        true,
+	"devtools":   true,
+	"bugreport":  true,
+	"replay":     true,
+	"watch":      true,
 	"krc":           true, // KRC doesn't require beads
 	"run-migration": true, // Migration orchestrator handles its own beads checks
 }
@@ -78,6 +111,27 @@ var branchCheckExemptCommands = map[string]bool{
 
 // persistentPreRun runs before every command.
 func persistentPreRun(cmd *cobra.Command, args []string) error {
+	// Set before anything resolves the town, and exported so agents and
+	// other gt processes started from here stay in the sandbox too.
+	if sandboxFlag {
+		_ = os.Setenv(sandbox.EnvVar, "1")
+	}
+	if recordFlag != "" {
+		_ = os.Setenv(replay.RecordEnv, recordFlag)
+	}
+	timefmt.SetUTC(utcFlag || os.Getenv(timefmt.EnvUTC) != "")
+	outputCommand = strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+	// Commands with their own --quiet shadow the global one; honor either.
+	if f := cmd.Flags().Lookup("quiet"); f != nil && f.Value.String() == "true" {
+		quietFlag = true
+	}
+	if os.Getenv(replay.RecordEnv) != "" || os.Getenv(replay.ReplayEnv) != "" {
+		townRoot, _ := workspace.FindFromCwd()
+		if err := replay.Init(townRoot, withoutFlag(os.Args[1:], "--record"), Version); err != nil {
+			return err
+		}
+	}
+
 	// Check if binary was built properly (via make build, not raw go build).
 	// Raw go build produces unsigned binaries that macOS may kill.
 	// Warning only - doesn't block execution.
@@ -89,8 +143,12 @@ func persistentPreRun(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Initialize CLI theme (dark/light mode support)
+	// Initialize CLI theme (dark/light mode support), locale, and ASCII mode
 	initCLITheme()
+	cmd.Root().SetErrPrefix(i18n.T("Error:"))
+
+	// Fill in configured flag defaults before the command reads its flags
+	applyCommandDefaults(cmd)
 
 	// Get the root command name being run
 	cmdName := cmd.Name()
@@ -118,20 +176,37 @@ func persistentPreRun(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-// initCLITheme initializes the CLI color theme based on settings and environment.
+// initCLITheme initializes the CLI color theme, message locale, ASCII
+// output mode, and title width based on settings and environment.
 func initCLITheme() {
-	// Try to load town settings for CLITheme config
+	// Try to load town settings for the CLI display config
 	var configTheme string
+	var configASCII bool
+	var configLocale string
+	var configTitleWidth int
 	if townRoot, err := workspace.FindFromCwd(); err == nil && townRoot != "" {
 		settingsPath := config.TownSettingsPath(townRoot)
 		if settings, err := config.LoadOrCreateTownSettings(settingsPath); err == nil {
 			configTheme = settings.CLITheme
+			configASCII = settings.CLIASCII
+			configLocale = settings.CLILocale
+			configTitleWidth = settings.CLITitleWidth
 		}
 	}
 
 	// Initialize theme with config value (env var takes precedence inside InitTheme)
 	ui.InitTheme(configTheme)
 	ui.ApplyThemeMode()
+
+	i18n.Init(configLocale)
+	style.InitTitleWidth(configTitleWidth)
+
+	ui.InitASCII(configASCII || asciiFlag)
+	if ui.IsASCII() {
+		if err := ui.StartASCIIFilter(); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: ASCII output unavailable: %v\n", err)
+		}
+	}
 }
 
 // warnIfTownRootOffMain prints a warning if the town root is not on main branch.
@@ -230,6 +305,8 @@ func checkStaleBinaryWarning() {
 // Execute runs the root command and returns an exit code.
 // The caller (main) should call os.Exit with this code.
 func Execute() int {
+	defer ui.StopASCIIFilter()
+	translateErrors(rootCmd)
 	if err := rootCmd.Execute(); err != nil {
 		// Check for silent exit (scripting commands that signal status via exit code)
 		if code, ok := IsSilentExit(err); ok {
@@ -241,6 +318,19 @@ func Execute() int {
 	return 0
 }
 
+// translateErrors wraps the RunE of cmd and its subcommands so returned
+// errors are shown in the user's language.
+func translateErrors(cmd *cobra.Command) {
+	if run := cmd.RunE; run != nil {
+		cmd.RunE = func(cmd *cobra.Command, args []string) error {
+			return i18n.Error(run(cmd, args))
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		translateErrors(sub)
+	}
+}
+
 // Command group IDs - used by subcommands to organize help output
 const (
 	GroupWork      = "work"
@@ -313,3 +403,18 @@ func checkHelpFlag(cmd *cobra.Command, args []string) (bool, error) {
 	}
 	return false, nil
 }
+
+// setOutputVersion selects the JSON output version from --output-version
+// or GT_OUTPUT_VERSION. It runs when JSON is written, so a bad version
+// doesn't fail commands printing text.
+func setOutputVersion() error {
+	v := outputVersionFlag
+	if v == 0 {
+		env, err := outputversion.FromEnv()
+		if err != nil {
+			return err
+		}
+		v = env
+	}
+	return outputversion.Set(v)
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/runbook"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Run command flags
+var (
+	runParams []string
+	runDryRun bool
+	runJSON   bool
+)
+
+var runCmd = &cobra.Command{
+	Use:     "run [runbook]",
+	GroupID: GroupServices,
+	Short:   "Run a runbook from town settings",
+	Long: `Run a named runbook: a sequence of gt and shell commands defined under
+"runbooks" in settings/config.json. Without a name, list the runbooks.
+
+Steps run in order from the town root. Parameters are substituted into
+commands as {{name}} (shell-quoted) and exported as GT_PARAM_<NAME>. A
+failing step stops the runbook (exit 1) unless it sets continue_on_error.
+Every run is logged step by step to logs/runbook-<name>.log.
+
+Runbooks with "every" set are also run by the daemon on that schedule, and
+'gt run <name>' works from agent hooks like any other command.
+
+Example settings:
+  "runbooks": {
+    "rotate-rig": {
+      "description": "Park a rig, pull, and bring it back",
+      "params": [{"name": "rig", "required": true}],
+      "steps": [
+        {"gt": "rig park {{rig}}"},
+        {"run": "git -C {{rig}}/mayor/rig pull --ff-only", "timeout": "5m"},
+        {"gt": "rig unpark {{rig}}"}
+      ]
+    }
+  }
+
+Examples:
+  gt run                                  # List runbooks
+  gt run rotate-rig --param rig=gastown
+  gt run rotate-rig -p rig=gastown --dry-run`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRunbook,
+}
+
+func init() {
+	runCmd.Flags().StringArrayVarP(&runParams, "param", "p", nil, "Runbook parameter as key=value (repeatable)")
+	runCmd.Flags().BoolVarP(&runDryRun, "dry-run", "n", false, "Print the commands without running them")
+	runCmd.Flags().BoolVar(&runJSON, "json", false, "Output as JSON")
+	rootCmd.AddCommand(runCmd)
+}
+
+func runRunbook(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	runbooks, err := runbook.Load(townRoot)
+	if err != nil {
+		return fmt.Errorf("loading town settings: %w", err)
+	}
+	if len(args) == 0 {
+		return listRunbooks(runbooks)
+	}
+
+	name := args[0]
+	rb, ok := runbooks[name]
+	if !ok {
+		return fmt.Errorf("no runbook %q (see 'gt run' for the list)", name)
+	}
+	given, err := runbook.ParseParams(runParams)
+	if err != nil {
+		return err
+	}
+	params, err := runbook.Params(name, rb, given)
+	if err != nil {
+		return err
+	}
+
+	runner := &runbook.Runner{TownRoot: townRoot, DryRun: runDryRun}
+	if !runJSON {
+		runner.Output = os.Stdout
+	}
+	res, err := runner.Run(context.Background(), name, rb, params)
+	if err != nil {
+		return err
+	}
+	if !runDryRun {
+		_ = events.LogFeed(events.TypeRunbook, detectSender(), events.RunbookPayload(name, res.OK, res.FailedStep()))
+	}
+
+	if runJSON {
+		if err := outputJSON(res); err != nil {
+			return err
+		}
+	} else if !runDryRun {
+		if res.OK {
+			fmt.Printf("%s Runbook %s finished in %s\n", style.Success.Render("✓"), name, time.Since(res.Started).Round(time.Second))
+		} else {
+			fmt.Printf("%s Runbook %s failed at: %s\n", style.Error.Render("✗"), name, res.FailedStep())
+			fmt.Printf("  %s\n", style.Dim.Render("Log: "+res.LogPath))
+		}
+	}
+	if !res.OK {
+		return NewSilentExit(1)
+	}
+	return nil
+}
+
+// listRunbooks prints each runbook with its parameters and schedule.
+func listRunbooks(runbooks map[string]*config.RunbookConfig) error {
+	if runJSON {
+		return outputJSON(runbooks)
+	}
+	if len(runbooks) == 0 {
+		fmt.Println(style.Dim.Render("No runbooks defined (add \"runbooks\" to settings/config.json)"))
+		return nil
+	}
+	for _, name := range runbook.Names(runbooks) {
+		rb := runbooks[name]
+		fmt.Printf("%s", style.Bold.Render(name))
+		if rb.Description != "" {
+			fmt.Printf("  %s", rb.Description)
+		}
+		fmt.Println()
+
+		var details []string
+		for _, p := range rb.Params {
+			switch {
+			case p.Required:
+				details = append(details, p.Name+"=<required>")
+			case p.Default != "":
+				details = append(details, p.Name+"="+p.Default)
+			default:
+				details = append(details, p.Name)
+			}
+		}
+		line := fmt.Sprintf("%d step(s)", len(rb.Steps))
+		if len(details) > 0 {
+			line += "  params: " + strings.Join(details, " ")
+		}
+		if rb.Every != "" {
+			line += "  every " + rb.Every
+		}
+		if err := runbook.Validate(name, rb); err != nil {
+			line += "  " + style.Error.Render("invalid: "+err.Error())
+		}
+		fmt.Printf("  %s\n", style.Dim.Render(line))
+	}
+	return nil
+}
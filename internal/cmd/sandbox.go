@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/doltserver"
+	"github.com/steveyegge/gastown/internal/sandbox"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var sandboxCmd = &cobra.Command{
+	Use:     "sandbox",
+	GroupID: GroupDiag,
+	Short:   "Manage the town's sandbox overlay",
+	Long: `Manage the throwaway overlay used by sandbox mode.
+
+With GT_SANDBOX=1 or gt --sandbox, every command runs against a copy of
+the town instead of the town itself:
+  - Town and rig config, registries, and beads (including .dolt-data) are
+    copied; the overlay's Dolt server listens on its own port
+  - Rig repos are re-cloned with origin pointing at a local bare mirror,
+    so git pushes land in the overlay
+  - Agents run on a separate tmux server (tmux -L gt-sandbox)
+
+The overlay is created on first use and kept until reset, so a formula
+or policy can be exercised end to end across several commands. It lives
+under the system temp dir, or $GT_SANDBOX_DIR if set.
+
+Examples:
+  gt --sandbox sling gt-abc gastown   # Try a sling without touching real beads
+  GT_SANDBOX=1 gt up                  # Bring up a sandboxed town
+  gt sandbox status                   # Where the overlay is and what it holds
+  gt sandbox reset                    # Discard it and start fresh next time`,
+}
+
+var sandboxStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the sandbox overlay for this town",
+	RunE:  runSandboxStatus,
+}
+
+var sandboxResetCmd = &cobra.Command{
+	Use:   "reset",
+	Short: "Stop sandboxed agents and discard the overlay",
+	RunE:  runSandboxReset,
+}
+
+var sandboxStatusJSON bool
+
+func init() {
+	sandboxStatusCmd.Flags().BoolVar(&sandboxStatusJSON, "json", false, "Output as JSON")
+
+	sandboxCmd.AddCommand(sandboxStatusCmd)
+	sandboxCmd.AddCommand(sandboxResetCmd)
+	rootCmd.AddCommand(sandboxCmd)
+}
+
+func runSandboxStatus(cmd *cobra.Command, args []string) error {
+	source, err := workspace.FindSourceFromCwd()
+	if err != nil {
+		return err
+	}
+	overlay := sandbox.Dir(source)
+	info, _ := sandbox.ReadInfo(overlay)
+
+	if sandboxStatusJSON {
+		return outputJSON(struct {
+			Enabled bool          `json:"enabled"`
+			Town    string        `json:"town"`
+			Overlay string        `json:"overlay"`
+			Info    *sandbox.Info `json:"info,omitempty"`
+		}{sandbox.Enabled(), source, overlay, info})
+	}
+
+	mode := style.Dim.Render("off")
+	if sandbox.Enabled() {
+		mode = style.Warning.Render("on")
+	}
+	fmt.Printf("Sandbox mode: %s\n", mode)
+	fmt.Printf("Town:         %s\n", source)
+	fmt.Printf("Overlay:      %s\n", overlay)
+	if info == nil {
+		fmt.Printf("              %s\n", style.Dim.Render("(not created yet)"))
+		return nil
+	}
+	fmt.Printf("Created:      %s (%s ago)\n", info.CreatedAt.Local().Format("2006-01-02 15:04"), time.Since(info.CreatedAt).Round(time.Minute))
+	if len(info.Rigs) > 0 {
+		fmt.Printf("Rigs:         %s\n", strings.Join(info.Rigs, ", "))
+	}
+	return nil
+}
+
+func runSandboxReset(cmd *cobra.Command, args []string) error {
+	source, err := workspace.FindSourceFromCwd()
+	if err != nil {
+		return err
+	}
+	overlay := sandbox.Dir(source)
+	if !sandbox.IsOverlay(overlay) {
+		fmt.Printf("%s No sandbox to reset\n", style.Dim.Render("○"))
+		return nil
+	}
+
+	// Agents and the Dolt server would otherwise keep running against
+	// deleted directories.
+	_ = exec.Command("tmux", "-L", sandbox.TmuxSocket, "kill-server").Run()
+	if running, _, _ := doltserver.IsRunning(overlay); running {
+		if err := doltserver.Stop(overlay); err != nil {
+			fmt.Fprintf(os.Stderr, "%s stopping sandbox Dolt server: %v\n", style.Warning.Render("⚠"), err)
+		}
+	}
+
+	if err := sandbox.Reset(source); err != nil {
+		return fmt.Errorf("removing sandbox: %w", err)
+	}
+	fmt.Printf("%s Discarded sandbox %s\n", style.SuccessPrefix, overlay)
+	return nil
+}
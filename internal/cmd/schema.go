@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/capacity"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/estimate"
+	"github.com/steveyegge/gastown/internal/goal"
+	"github.com/steveyegge/gastown/internal/jsonschema"
+	"github.com/steveyegge/gastown/internal/memo"
+	"github.com/steveyegge/gastown/internal/milestone"
+	"github.com/steveyegge/gastown/internal/outputversion"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/view"
+)
+
+// outputSchema ties a command to the Go type of its JSON output.
+type outputSchema struct {
+	cmd    *cobra.Command
+	sample any // a value of the output type
+}
+
+// outputSchemas are the commands whose JSON output has a published
+// schema, keyed by command path ("milestone status"). Adding a command
+// here gives it a --schema flag and a contract test.
+var outputSchemas = map[string]outputSchema{
+	"status":                {statusCmd, TownStatus{}},
+	"ready":                 {readyCmd, ReadyResult{}},
+	"blocked":               {blockedCmd, BlockedResult{}},
+	"stale":                 {staleCmd, StaleOutput{}},
+	"costs":                 {costsCmd, CostsOutput{}},
+	"gc":                    {gcCmd, GCOutput{}},
+	"search":                {searchCmd, []memo.Match{}},
+	"view":                  {viewCmd, []view.Row{}},
+	"plan":                  {planCmd, capacity.Report{}},
+	"changelog":             {changelogCmd, ChangelogOutput{}},
+	"bisect":                {bisectCmd, BisectOutput{}},
+	"deps check":            {depsCheckCmd, DepsCheckResult{}},
+	"env show":              {envShowCmd, EnvShowOutput{}},
+	"estimate rollup":       {estimateRollupCmd, []estimate.Rollup{}},
+	"estimate stats":        {estimateStatsCmd, []estimate.Accuracy{}},
+	"field list":            {fieldListCmd, []FieldDefinition{}},
+	"goal list":             {goalListCmd, []*goal.Status{}},
+	"goal status":           {goalStatusCmd, goal.Status{}},
+	"hooks scan":            {hooksScanCmd, HooksOutput{}},
+	"milestone list":        {milestoneListCmd, []*milestone.Progress{}},
+	"milestone status":      {milestoneStatusCmd, milestone.Progress{}},
+	"mq status":             {mqStatusCmd, MRStatusOutput{}},
+	"mq integration status": {mqIntegrationStatusCmd, IntegrationStatusOutput{}},
+	"polecat status":        {polecatStatusCmd, PolecatStatus{}},
+	"refinery status":       {refineryStatusCmd, RefineryStatusOutput{}},
+	"rig release":           {rigReleaseCmd, RigReleaseResult{}},
+	"verify":                {verifyCmd, VerifyOutput{}},
+	"witness status":        {witnessStatusCmd, WitnessStatusOutput{}},
+}
+
+// configSchema ties a config file to its Go type.
+type configSchema struct {
+	path   string // where the file lives, for the schema description
+	sample any
+}
+
+// configSchemas are the config files with a published schema, keyed by
+// the name 'gt schema config <name>' takes.
+var configSchemas = map[string]configSchema{
+	"town":          {"mayor/town.json", config.TownConfig{}},
+	"rigs":          {"mayor/rigs.json", config.RigsConfig{}},
+	"accounts":      {"mayor/accounts.json", config.AccountsConfig{}},
+	"town-settings": {"settings/config.json", config.TownSettings{}},
+	"escalation":    {"settings/escalation.json", config.EscalationConfig{}},
+	"messaging":     {"config/messaging.json", config.MessagingConfig{}},
+	"rig":           {"<rig>/config.json", config.RigConfig{}},
+	"rig-settings":  {"<rig>/settings/config.json", config.RigSettings{}},
+}
+
+var schemaOut string
+
+var schemaCmd = &cobra.Command{
+	Use:     "schema [command... | config <name>]",
+	GroupID: GroupConfig,
+	Short:   "Print JSON Schemas for command output and config files",
+	Long: `Print the JSON Schema (draft 2020-12) of a command's --json output or of
+a config file. With no arguments, list what has a schema.
+
+Schemas are generated from the types gt encodes, so they always match the
+build you're running. Objects are closed: a property the schema doesn't
+list is a contract change. Commands with a schema also take --schema.
+
+Examples:
+  gt schema                        # List schemas
+  gt schema ready                  # Same as: gt ready --schema
+  gt schema milestone status
+  gt schema config town-settings
+  gt schema --out ./schemas        # Write every schema to a directory`,
+	RunE: runSchema,
+}
+
+func init() {
+	schemaCmd.Flags().StringVar(&schemaOut, "out", "", "Write every schema to this directory, one file each")
+	rootCmd.AddCommand(schemaCmd)
+
+	for name, s := range outputSchemas {
+		addSchemaFlag(name, s)
+	}
+}
+
+// addSchemaFlag gives a command a --schema flag that prints the schema of
+// its JSON output instead of running.
+func addSchemaFlag(name string, s outputSchema) {
+	var show bool
+	s.cmd.Flags().BoolVar(&show, "schema", false, "Print the JSON Schema of the --json output and exit")
+	run := s.cmd.RunE
+	s.cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if show {
+			return printSchema(commandSchema(name))
+		}
+		return run(cmd, args)
+	}
+	// The schema needs none of the command's arguments.
+	args := s.cmd.Args
+	s.cmd.Args = func(cmd *cobra.Command, a []string) error {
+		if show || args == nil {
+			return nil
+		}
+		return args(cmd, a)
+	}
+}
+
+// commandSchema generates the schema of a registered command's output.
+func commandSchema(name string) *jsonschema.Schema {
+	s := jsonschema.Generate(outputSchemas[name].sample, "gt "+name+" --json")
+	s.Description = fmt.Sprintf("Output of 'gt %s --json', output version %d.", name, outputversion.Current)
+	// A top-level array carries the version in each of its objects
+	obj := s
+	if s.Items != nil {
+		obj = s.Items
+	}
+	if obj.Properties != nil {
+		obj.Properties[outputversion.Field] = &jsonschema.Schema{Type: "integer"}
+		obj.Required = append(obj.Required, outputversion.Field)
+		sort.Strings(obj.Required)
+	}
+	return s
+}
+
+// configFileSchema generates the schema of a registered config file.
+func configFileSchema(name string) *jsonschema.Schema {
+	c := configSchemas[name]
+	// Config files are written by hand and by older and newer gt versions,
+	// so unknown keys are tolerated rather than rejected.
+	s := jsonschema.Generate(c.sample, c.path).Open()
+	s.Description = fmt.Sprintf("Gas Town config file %s.", c.path)
+	return s
+}
+
+func printSchema(s *jsonschema.Schema) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+func runSchema(cmd *cobra.Command, args []string) error {
+	if schemaOut != "" {
+		if len(args) > 0 {
+			return fmt.Errorf("--out writes every schema; it takes no arguments")
+		}
+		return writeSchemas(schemaOut)
+	}
+	if len(args) == 0 {
+		listSchemas()
+		return nil
+	}
+	if args[0] == "config" {
+		if len(args) != 2 {
+			return fmt.Errorf("usage: gt schema config <name> (have: %s)", strings.Join(sortedKeys(configSchemas), ", "))
+		}
+		if _, ok := configSchemas[args[1]]; !ok {
+			return fmt.Errorf("no schema for config %q (have: %s)", args[1], strings.Join(sortedKeys(configSchemas), ", "))
+		}
+		return printSchema(configFileSchema(args[1]))
+	}
+	name := strings.Join(args, " ")
+	if _, ok := outputSchemas[name]; !ok {
+		return fmt.Errorf("no schema for 'gt %s' (see 'gt schema' for the list)", name)
+	}
+	return printSchema(commandSchema(name))
+}
+
+func listSchemas() {
+	fmt.Println(style.Bold.Render("Command output (--json):"))
+	for _, name := range sortedKeys(outputSchemas) {
+		fmt.Printf("  gt %s\n", name)
+	}
+	fmt.Println()
+	fmt.Println(style.Bold.Render("Config files:"))
+	for _, name := range sortedKeys(configSchemas) {
+		fmt.Printf("  %-14s %s\n", name, style.Dim.Render(configSchemas[name].path))
+	}
+}
+
+// writeSchemas writes every schema to dir: commands as
+// <command-path>.schema.json, configs as config-<name>.schema.json.
+func writeSchemas(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	write := func(file string, s *jsonschema.Schema) error {
+		data, err := json.MarshalIndent(s, "", "  ")
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dir, file), append(data, '\n'), 0644) //nolint:gosec // G306: schemas are public
+	}
+	count := 0
+	for _, name := range sortedKeys(outputSchemas) {
+		if err := write(strings.ReplaceAll(name, " ", "-")+".schema.json", commandSchema(name)); err != nil {
+			return err
+		}
+		count++
+	}
+	for _, name := range sortedKeys(configSchemas) {
+		if err := write("config-"+name+".schema.json", configFileSchema(name)); err != nil {
+			return err
+		}
+		count++
+	}
+	fmt.Printf("%s Wrote %d schemas to %s\n", style.Success.Render("✓"), count, dir)
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/capacity"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/goal"
+	"github.com/steveyegge/gastown/internal/jsonschema"
+	"github.com/steveyegge/gastown/internal/milestone"
+	"github.com/steveyegge/gastown/internal/outputversion"
+	"github.com/steveyegge/gastown/internal/view"
+)
+
+// validateAgainst checks v as gt would print it: command output through
+// outputversion, config files as plain JSON.
+func validateAgainst(t *testing.T, s *jsonschema.Schema, v any) {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if strings.HasPrefix(s.Title, "gt ") {
+		data, _, err = outputversion.Encode(v, "", outputversion.Current)
+	}
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if err := jsonschema.Validate(s, data); err != nil {
+		t.Errorf("%s: %v\n%s", s.Title, err, data)
+	}
+}
+
+func TestOutputSchemasRegistered(t *testing.T) {
+	for name, s := range outputSchemas {
+		found, _, err := rootCmd.Find(strings.Fields(name))
+		if err != nil || found != s.cmd {
+			t.Errorf("%q does not resolve to its command", name)
+			continue
+		}
+		if s.cmd.Flags().Lookup("json") == nil && s.cmd.Flags().Lookup("format") == nil {
+			t.Errorf("gt %s has a schema but no --json or --format flag", name)
+		}
+		if s.cmd.Flags().Lookup("schema") == nil {
+			t.Errorf("gt %s has no --schema flag", name)
+		}
+		// The zero value is what a command emits with nothing to report.
+		validateAgainst(t, commandSchema(name), s.sample)
+	}
+	for name, c := range configSchemas {
+		validateAgainst(t, configFileSchema(name), c.sample)
+	}
+}
+
+// TestOutputsMatchSchemas validates outputs built the way the commands
+// build them.
+func TestOutputsMatchSchemas(t *testing.T) {
+	now := time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+	issue := &beads.Issue{ID: "gt-1", Title: "Fix login", Status: "open", Priority: 1, Type: "bug",
+		Labels: []string{"field:customer=acme"}, Fields: map[string]string{"customer": "acme"}}
+
+	validateAgainst(t, commandSchema("ready"), ReadyResult{
+		Sources:  []ReadySource{{Name: "gastown", Issues: []*beads.Issue{issue}}, {Name: "beads", Error: "bd: timeout"}},
+		Summary:  ReadySummary{Total: 1, BySource: map[string]int{"gastown": 1}, P1Count: 1},
+		Snapshot: beads.SnapshotInfo{At: now, Changed: 1},
+	})
+	validateAgainst(t, commandSchema("view"), []view.Row{{Source: "gastown", Issue: issue}})
+
+	issues := []milestone.Issue{
+		{ID: "gt-1", Status: "closed", Rig: "gastown", Closed: now.Add(-48 * time.Hour), Hours: 4},
+		{ID: "gt-2", Status: "open", Rig: "gastown"},
+	}
+	start, target := now.AddDate(0, 0, -5), now.AddDate(0, 0, 5)
+	validateAgainst(t, commandSchema("milestone status"), milestone.Build("hq-m1", "v2", "open", start, target, issues, now))
+	validateAgainst(t, commandSchema("goal status"), goal.Build("onboarding", &config.GoalConfig{Target: "2026-04-01"},
+		[]goal.Link{{ID: "hq-m1", Kind: "milestone", Total: 2, Closed: 1, Health: milestone.HealthOnTrack}}, issues, start, now))
+
+	validateAgainst(t, commandSchema("plan"), capacity.Build([]capacity.Input{
+		{Rig: "gastown", Ready: 6, Completed: 14, Agents: 2, MaxAgents: 10},
+		{Rig: "idle", Ready: 0, Completed: 0, Agents: 0, MaxAgents: 10},
+	}, 14*24*time.Hour, 72*time.Hour))
+
+	validateAgainst(t, configFileSchema("town-settings"), config.NewTownSettings())
+}
+
+func TestSchemaRejectsDrift(t *testing.T) {
+	// A property the schema doesn't know is a contract change.
+	data := []byte(`{"output_version": 3, "sources": [], "summary": {"total": 0, "by_source": {}, "p0_count": 0, "p1_count": 0,
+		"p2_count": 0, "p3_count": 0, "p4_count": 0}, "renamed_field": true}`)
+	if err := jsonschema.Validate(commandSchema("ready"), data); err == nil {
+		t.Error("ready schema accepted an unknown property")
+	}
+}
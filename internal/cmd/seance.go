@@ -18,6 +18,7 @@ import (
 	"github.com/steveyegge/gastown/internal/constants"
 	"github.com/steveyegge/gastown/internal/events"
 	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/timefmt"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
@@ -128,9 +129,7 @@ func runSeanceList() error {
 	}
 
 	if seanceJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(filtered)
+		return outputJSON(filtered)
 	}
 
 	if len(filtered) == 0 {
@@ -301,11 +300,11 @@ func getPayloadString(payload map[string]interface{}, key string) string {
 }
 
 func formatEventTime(ts string) string {
-	t, err := time.Parse(time.RFC3339, ts)
+	t, err := timefmt.Parse(ts)
 	if err != nil {
 		return ts
 	}
-	return t.Local().Format("2006-01-02 15:04")
+	return timefmt.Exact(t)
 }
 
 // sessionsIndex represents the structure of sessions-index.json files.
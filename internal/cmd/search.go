@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/memo"
+	"github.com/steveyegge/gastown/internal/paging"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Search command flags
+var (
+	searchRig    string
+	searchTopic  string
+	searchJSON   bool
+	searchFormat string
+	searchLimit  int
+	searchOffset int
+)
+
+var searchCmd = &cobra.Command{
+	Use:     "search <query>",
+	GroupID: GroupWork,
+	Short:   "Search the town's decision memos",
+	Long: `Search the memo log for decisions. Every word of the query must appear in
+a memo's title, topics, or body; title matches rank highest.
+
+For mail, use 'gt mail search'; for issues, 'bd list'.
+
+Examples:
+  gt search "session cookies"
+  gt search retry --topic network
+  gt search schema --rig beads --json
+  gt search deploy --format ndjson | jq .memo.id
+  gt search deploy --limit 20 --offset 20   # The second page of 20`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runSearch,
+}
+
+func init() {
+	searchCmd.Flags().StringVar(&searchRig, "rig", "", "Only memos for this rig (and town-wide ones)")
+	searchCmd.Flags().StringVarP(&searchTopic, "topic", "t", "", "Only memos with this topic")
+	searchCmd.Flags().BoolVar(&searchJSON, "json", false, "Output as JSON")
+	searchCmd.Flags().StringVar(&searchFormat, "format", formatText, "Output format: text, json, or ndjson (one match per line)")
+	searchCmd.Flags().IntVar(&searchLimit, "limit", 0, "Show at most this many matches, best first (0 for all)")
+	searchCmd.Flags().IntVar(&searchOffset, "offset", 0, "Skip this many matches first, for the next page")
+
+	rootCmd.AddCommand(searchCmd)
+}
+
+func runSearch(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	format, err := resolveFormat(searchFormat, searchJSON)
+	if err != nil {
+		return err
+	}
+	page := paging.Page{Limit: searchLimit, Offset: searchOffset}
+	if err := page.Validate(); err != nil {
+		return err
+	}
+	query := strings.Join(args, " ")
+	matches, pageInfo, err := memo.NewStore(townRoot).SearchPage(query, memo.Filter{Rig: searchRig, Topic: searchTopic}, page)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case formatJSON:
+		if matches == nil {
+			matches = []memo.Match{}
+		}
+		return outputJSON(matches)
+	case formatNDJSON:
+		stream := newNDJSONWriter(os.Stdout)
+		for _, match := range matches {
+			if err := stream.Write(match); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if len(matches) == 0 {
+		if page.Offset > 0 {
+			printPageHint(&pageInfo, 0)
+		} else {
+			fmt.Printf("%s\n", style.Dim.Render(fmt.Sprintf("No memos match %q", query)))
+		}
+		return nil
+	}
+	for _, match := range matches {
+		printMemoLine(match.Memo)
+		if match.Snippet != "" {
+			fmt.Printf("    %s\n", style.Dim.Render(match.Snippet))
+		}
+	}
+	printPageHint(&pageInfo, len(matches))
+	return nil
+}
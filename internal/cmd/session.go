@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -29,6 +28,7 @@ var (
 	sessionMessage   string
 	sessionFile      string
 	sessionRigFilter string
+	sessionGroup     string
 	sessionListJSON  bool
 )
 
@@ -89,7 +89,8 @@ var sessionListCmd = &cobra.Command{
 	Short: "List all sessions",
 	Long: `List all running polecat sessions.
 
-Shows session status, rig, and polecat name. Use --rig to filter by rig.`,
+Shows session status, rig, and polecat name. Use --rig to filter by rig,
+or --group to filter to the rigs in a group.`,
 	RunE: runSessionList,
 }
 
@@ -175,6 +176,7 @@ func init() {
 
 	// List flags
 	sessionListCmd.Flags().StringVar(&sessionRigFilter, "rig", "", "Filter by rig name")
+	sessionListCmd.Flags().StringVar(&sessionGroup, "group", "", "Filter to the rigs in a group")
 	sessionListCmd.Flags().BoolVar(&sessionListJSON, "json", false, "Output as JSON")
 
 	// Capture flags
@@ -375,6 +377,9 @@ func runSessionList(cmd *cobra.Command, args []string) error {
 		}
 		rigs = filtered
 	}
+	if rigs, err = filterRigsByGroup(rigs, rigsConfig, sessionGroup); err != nil {
+		return err
+	}
 
 	// Collect sessions from all rigs
 	t := tmux.NewTmux()
@@ -399,9 +404,7 @@ func runSessionList(cmd *cobra.Command, args []string) error {
 
 	// Output
 	if sessionListJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(allSessions)
+		return outputJSON(allSessions)
 	}
 
 	if len(allSessions) == 0 {
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/web"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Share command flags
+var (
+	shareURL    string
+	shareRotate bool
+	shareJSON   bool
+)
+
+var shareCmd = &cobra.Command{
+	Use:     "share",
+	GroupID: GroupDiag,
+	Short:   "Share read-only views of the town",
+	RunE:    requireSubcommand,
+}
+
+var shareStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Print a link to the read-only town status page",
+	Long: `Print a link to the live town status page served by
+'gt serve --observer'.
+
+The link carries the town's viewer token, which grants read-only access:
+viewers see convoys, agents, and the merge queue, but cannot run commands,
+read mail, or create issues. --rotate replaces the token, revoking every
+link handed out before.
+
+The link points at the observer server's --public-url if it was given,
+otherwise at this machine's hostname.
+
+Examples:
+  gt share status
+  gt share status --rotate                      # Revoke old links
+  gt share status --url https://gt.example.com`,
+	Args: cobra.NoArgs,
+	RunE: runShareStatus,
+}
+
+func init() {
+	shareStatusCmd.Flags().StringVar(&shareURL, "url", "", "Base URL of the observer server (default: the running one)")
+	shareStatusCmd.Flags().BoolVar(&shareRotate, "rotate", false, "Replace the viewer token, revoking existing links")
+	shareStatusCmd.Flags().BoolVar(&shareJSON, "json", false, "Output as JSON")
+
+	shareCmd.AddCommand(shareStatusCmd)
+	rootCmd.AddCommand(shareCmd)
+}
+
+func runShareStatus(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	var token string
+	if shareRotate {
+		token, err = web.RotateViewerToken(townRoot)
+	} else {
+		token, err = web.LoadOrCreateViewerToken(townRoot)
+	}
+	if err != nil {
+		return fmt.Errorf("viewer token: %w", err)
+	}
+
+	base := shareURL
+	running := false
+	if base == "" {
+		state, err := web.LoadObserverState(townRoot)
+		if err != nil {
+			return err
+		}
+		if state != nil {
+			base, running = state.URL, true
+		} else {
+			base = defaultShareBase(8080)
+		}
+	}
+	link := web.ShareURL(base, token)
+
+	if shareJSON {
+		return outputJSON(map[string]interface{}{
+			"url":     link,
+			"rotated": shareRotate,
+		})
+	}
+	if shareRotate {
+		fmt.Printf("%s Viewer token rotated; earlier links no longer work\n", style.Success.Render("✓"))
+	}
+	fmt.Println(link)
+	if !running && shareURL == "" {
+		fmt.Printf("%s No observer server recorded; start one with: gt serve --observer\n", style.Dim.Render("ℹ"))
+	}
+	return nil
+}
+
+// defaultShareBase is the observer URL on this machine's hostname.
+func defaultShareBase(port int) string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "localhost"
+	}
+	return fmt.Sprintf("http://%s:%d", host, port)
+}
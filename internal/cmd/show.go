@@ -1,12 +1,20 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"strings"
 	"syscall"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/attach"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/ui"
+	"github.com/steveyegge/gastown/internal/workspace"
 )
 
 func init() {
@@ -23,12 +31,22 @@ Delegates to 'bd show' - all bd show flags are supported.
 Works with any bead prefix (gt-, bd-, hq-, etc.) and routes
 to the correct beads database automatically.
 
+--deployments (gt only) appends whether the bead's merged MRs are live in
+each environment (see 'gt deploy').
+
+--attachments (gt only) appends the files attached with 'gt attach'.
+--attachment <name|digest> prints one attachment's contents instead of
+the bead (or writes it to a file with -o <path>).
+
 Examples:
   gt show gt-abc123          # Show a gastown issue
   gt show hq-xyz789          # Show a town-level bead (convoy, mail, etc.)
   gt show bd-def456          # Show a beads issue
   gt show gt-abc123 --json   # Output as JSON
-  gt show gt-abc123 -v       # Verbose output`,
+  gt show gt-abc123 -v       # Verbose output
+  gt show gt-abc123 --deployments  # Is this fix live?
+  gt show gt-abc123 --attachments  # List attached files
+  gt show gt-abc123 --attachment crash.log -o /tmp/crash.log`,
 	DisableFlagParsing: true, // Pass all flags through to bd show
 	RunE:               runShow,
 }
@@ -43,7 +61,139 @@ func runShow(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("bead ID required\n\nUsage: gt show <bead-id> [flags]")
 	}
 
-	return execBdShow(args)
+	if ref, rest, ok := stripFlagValue(args, "--attachment"); ok {
+		out, rest, _ := stripFlagValue(rest, "-o")
+		if len(rest) != 1 {
+			return fmt.Errorf("usage: gt show <bead-id> --attachment <name|digest> [-o <path>]")
+		}
+		return showAttachment(rest[0], ref, out)
+	}
+
+	bdArgs, withDeployments := stripFlag(args, "--deployments")
+	bdArgs, withAttachments := stripFlag(bdArgs, "--attachments")
+	if !withDeployments && !withAttachments {
+		return execBdShow(bdArgs)
+	}
+	if len(bdArgs) == 0 {
+		return fmt.Errorf("bead ID required\n\nUsage: gt show <bead-id> --deployments|--attachments")
+	}
+
+	// Run bd show as a child so deployment status can follow it
+	c := exec.Command("bd", append([]string{"show"}, bdArgs...)...)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		return err
+	}
+
+	if withAttachments {
+		if err := showAttachmentList(bdArgs[0]); err != nil {
+			fmt.Printf("\n%s Attachments: %v\n", style.Warning.Render("⚠"), err)
+		}
+	}
+	if !withDeployments {
+		return nil
+	}
+	status, err := beadDeployStatus(bdArgs[0])
+	if err != nil {
+		fmt.Printf("\n%s Deployments: %v\n", style.Warning.Render("⚠"), err)
+		return nil
+	}
+	fmt.Printf("\n%s\n", style.Bold.Render("DEPLOYMENTS"))
+	printBeadDeployStatus(status)
+	return nil
+}
+
+// showAttachmentList prints the attachments section for gt show.
+func showAttachmentList(beadID string) error {
+	store, list, err := beadAttachments(beadID)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\n%s\n", style.Bold.Render("ATTACHMENTS"))
+	printAttachments(store, list)
+	return nil
+}
+
+// showAttachment writes one attachment's contents to out, or stdout.
+func showAttachment(beadID, ref, out string) error {
+	store, list, err := beadAttachments(beadID)
+	if err != nil {
+		return err
+	}
+	a, err := attach.Find(list, ref)
+	if err != nil {
+		return fmt.Errorf("%s: %w", beadID, err)
+	}
+	rc, err := store.Open(context.Background(), *a)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", a.Name, err)
+	}
+	defer rc.Close()
+
+	var w io.Writer = os.Stdout
+	if out != "" {
+		f, err := os.Create(out) //nolint:gosec // G304: the user names the output file
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	_, err = io.Copy(w, rc)
+	return err
+}
+
+// beadAttachments returns the town's attachment store and a bead's attachments.
+func beadAttachments(beadID string) (*attach.Store, []attach.Attachment, error) {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return nil, nil, fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	store, err := attach.OpenTown(townRoot)
+	if err != nil {
+		return nil, nil, err
+	}
+	issue, err := beads.New(resolveBeadDir(beadID)).Show(beadID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetching %s: %w", beadID, err)
+	}
+	return store, attach.Parse(issue.Description), nil
+}
+
+// stripFlag removes a boolean flag from args, reporting whether it was present.
+func stripFlag(args []string, flag string) ([]string, bool) {
+	out := make([]string, 0, len(args))
+	found := false
+	for _, a := range args {
+		if a == flag {
+			found = true
+			continue
+		}
+		out = append(out, a)
+	}
+	return out, found
+}
+
+// stripFlagValue removes a flag that takes a value ("--flag v" or
+// "--flag=v"), returning the value and whether the flag was present.
+func stripFlagValue(args []string, flag string) (string, []string, bool) {
+	out := make([]string, 0, len(args))
+	value, found := "", false
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		switch {
+		case a == flag && i+1 < len(args):
+			value, found = args[i+1], true
+			i++
+		case strings.HasPrefix(a, flag+"="):
+			value, found = strings.TrimPrefix(a, flag+"="), true
+		default:
+			out = append(out, a)
+		}
+	}
+	return value, out, found
 }
 
 // execBdShow replaces the current process with 'bd show'.
@@ -58,5 +208,6 @@ func execBdShow(args []string) error {
 	fullArgs := append([]string{"bd", "show"}, args...)
 
 	// Replace process with bd show
+	ui.StopASCIIFilter() // Flush filtered output before the exec replaces us
 	return syscall.Exec(bdPath, fullArgs, os.Environ())
 }
@@ -53,6 +53,12 @@ Spawning Options (when target is a rig):
   gt sling gp-abc greenplace --force                # Ignore unread mail
   gt sling gp-abc greenplace --account work         # Use specific Claude account
 
+File Locks:
+  gt sling gp-abc greenplace --files=internal/auth  # Soft-lock paths for the work
+
+  Overlapping locks are warned, or queue the claim, per the rig's
+  file_locks policy. See 'gt locks'.
+
 Natural Language Args:
   gt sling gt-abc --args "patch release"
   gt sling code-review --args "focus on security"
@@ -106,6 +112,7 @@ var (
 	slingArgs        string   // --args flag: natural language instructions for executor
 	slingStdin       bool     // --stdin: read --message and/or --args from stdin
 	slingHookRawBead bool     // --hook-raw-bead: hook raw bead without default formula (expert mode)
+	slingFiles       []string // --files: paths to soft-lock for the claim
 
 	// Flags migrated for polecat spawning (used by sling for work assignment)
 	slingCreate        bool   // --create: create polecat if it doesn't exist
@@ -134,6 +141,7 @@ func init() {
 	slingCmd.Flags().StringVar(&slingAgent, "agent", "", "Override agent/runtime for this sling (e.g., claude, gemini, codex, or custom alias)")
 	slingCmd.Flags().BoolVar(&slingNoConvoy, "no-convoy", false, "Skip auto-convoy creation for single-issue sling")
 	slingCmd.Flags().BoolVar(&slingHookRawBead, "hook-raw-bead", false, "Hook raw bead without default formula (expert mode)")
+	slingCmd.Flags().StringSliceVar(&slingFiles, "files", nil, "Files/directories the work will change, soft-locked for the target (see 'gt locks')")
 	slingCmd.Flags().BoolVar(&slingNoMerge, "no-merge", false, "Skip merge queue on completion (keep work on feature branch for review)")
 	slingCmd.Flags().BoolVar(&slingNoBoot, "no-boot", false, "Skip rig boot after polecat spawn (avoids witness/refinery lock contention)")
 	slingCmd.Flags().IntVar(&slingMaxConcurrent, "max-concurrent", 0, "Limit concurrent polecat spawns in batch mode (0 = no limit)")
@@ -512,10 +520,20 @@ func runSling(cmd *cobra.Command, args []string) error {
 		if slingArgs != "" {
 			fmt.Printf("  args (in nudge): %s\n", slingArgs)
 		}
+		if len(slingFiles) > 0 {
+			fmt.Printf("Would lock files for %s: %s\n", targetAgent, strings.Join(slingFiles, ", "))
+		}
 		fmt.Printf("Would inject start prompt to pane: %s\n", targetPane)
 		return nil
 	}
 
+	// Lock declared files first, so a queued claim doesn't take the work
+	if len(slingFiles) > 0 {
+		if err := claimFileLocks(townRoot, targetAgent, beadID, slingFiles); err != nil {
+			return err
+		}
+	}
+
 	// Formula-on-bead mode: instantiate formula and bond to original bead
 	if formulaName != "" {
 		fmt.Printf("  Instantiating formula %s...\n", formulaName)
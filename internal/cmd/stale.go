@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 
@@ -100,9 +99,7 @@ func runStale(cmd *cobra.Command, args []string) error {
 }
 
 func outputStaleJSON(output StaleOutput) error {
-	enc := json.NewEncoder(os.Stdout)
-	enc.SetIndent("", "  ")
-	return enc.Encode(output)
+	return outputJSON(output)
 }
 
 func outputStaleText(output StaleOutput) error {
@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"os/signal"
@@ -273,7 +272,10 @@ func runStatusOnce(_ *cobra.Command, _ []string) error {
 	beadsWg.Add(1)
 	go func() {
 		defer beadsWg.Done()
-		townBeadsClient := beads.New(townBeadsPath)
+		townBeadsClient, err := healthyBeads(townBeadsPath)
+		if err != nil {
+			return
+		}
 		townAgentBeads, _ := townBeadsClient.ListAgentBeads()
 		mergeAgentBeads(townAgentBeads)
 
@@ -303,7 +305,11 @@ func runStatusOnce(_ *cobra.Command, _ []string) error {
 		go func(r *rig.Rig) {
 			defer beadsWg.Done()
 			rigBeadsPath := filepath.Join(r.Path, "mayor", "rig")
-			rigBeads := beads.New(rigBeadsPath)
+			// A rig whose bd fails the probe shows without agent beads
+			rigBeads, err := healthyBeads(rigBeadsPath)
+			if err != nil {
+				return
+			}
 			rigAgentBeads, _ := rigBeads.ListAgentBeads()
 			if rigAgentBeads == nil {
 				return
@@ -449,9 +455,7 @@ func runStatusOnce(_ *cobra.Command, _ []string) error {
 }
 
 func outputStatusJSON(status TownStatus) error {
-	enc := json.NewEncoder(os.Stdout)
-	enc.SetIndent("", "  ")
-	return enc.Encode(status)
+	return outputJSON(status)
 }
 
 func outputStatusText(status TownStatus) error {
@@ -891,13 +895,7 @@ func formatHookInfo(hookBead, title string, maxLen int) string {
 
 // truncateWithEllipsis shortens a string to maxLen, adding "..." if truncated
 func truncateWithEllipsis(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
-	}
-	if maxLen < 4 {
-		return s[:maxLen]
-	}
-	return s[:maxLen-3] + "..."
+	return style.Truncate(s, maxLen)
 }
 
 // capitalizeFirst capitalizes the first letter of a string
@@ -913,8 +911,10 @@ func capitalizeFirst(s string) string {
 func discoverRigHooks(r *rig.Rig, crews []string) []AgentHookInfo {
 	var hooks []AgentHookInfo
 
-	// Create beads instance for the rig
-	b := beads.New(r.Path)
+	// Batch the rig's queries: every agent's handoff bead comes out of one
+	// list of pinned issues, so the rig costs one bd run however many
+	// agents it has
+	b := beads.New(r.Path).WithBreaker(beadsHealth).Batch()
 
 	// Check polecats
 	for _, name := range r.Polecats {
@@ -1173,7 +1173,7 @@ func getMQSummary(r *rig.Rig) *MQSummary {
 	}
 
 	// Create beads instance for the rig
-	b := beads.New(r.BeadsPath())
+	b := beads.New(r.BeadsPath()).WithBreaker(beadsHealth)
 
 	// Query for all open merge-request type issues
 	opts := beads.ListOptions{
@@ -1241,7 +1241,7 @@ func getMQSummary(r *rig.Rig) *MQSummary {
 }
 
 // getAgentHook retrieves hook status for a specific agent.
-func getAgentHook(b *beads.Beads, role, agentAddress, roleType string) AgentHookInfo {
+func getAgentHook(b *beads.Batch, role, agentAddress, roleType string) AgentHookInfo {
 	hook := AgentHookInfo{
 		Agent: agentAddress,
 		Role:  roleType,
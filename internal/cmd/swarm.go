@@ -677,9 +677,7 @@ func runSwarmList(cmd *cobra.Command, args []string) error {
 
 	// JSON output
 	if swarmListJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(allSwarms)
+		return outputJSON(allSwarms)
 	}
 
 	// Human-readable output
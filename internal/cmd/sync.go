@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/townsync"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Sync command flags
+var (
+	syncPeer     string
+	syncPrefer   string
+	syncDryRun   bool
+	syncJSON     bool
+	syncSSH      string
+	syncRemoteGT string
+	syncServeDir string
+)
+
+var syncCmd = &cobra.Command{
+	Use:     "sync --peer <host[:town]>",
+	GroupID: GroupWorkspace,
+	Short:   "Replicate town state with another machine",
+	Long: `Sync town-level state with the same town on another machine, for a
+warm standby or a town split between home and office.
+
+Synced: the town and rig registry (mayor/*.json), town settings, town
+beads (exported to .beads/issues.jsonl, then imported), bead routes, and
+the event log. Rig clones are not synced; they have git.
+
+Each file is compared with its state at the last sync with that peer:
+
+  changed here only    pushed to the peer
+  changed there only   pulled here
+  changed on both      the event log keeps both sides' new lines; beads
+                       merge issue by issue; anything else is a conflict
+
+Conflicts are left alone on both sides and reported (exit 1); the rest is
+synced. Re-run with --prefer local or --prefer remote to settle them.
+
+The peer is reached over ssh and must have gt on its PATH. Without a
+path, its town is assumed to be at the same path as this one.
+
+Examples:
+  gt sync --peer office                      # Same town path on office
+  gt sync --peer me@office:/srv/gt --dry-run
+  gt sync --peer office --prefer local       # Settle conflicts in our favor
+  gt sync --peer office --ssh "ssh -p 2222"`,
+	Args: cobra.NoArgs,
+	RunE: runSync,
+}
+
+var syncServeCmd = &cobra.Command{
+	Use:    "serve",
+	Short:  "Answer a sync request from a peer on stdin (used over ssh)",
+	Hidden: true,
+	Args:   cobra.NoArgs,
+	RunE:   runSyncServe,
+}
+
+func init() {
+	syncCmd.Flags().StringVar(&syncPeer, "peer", "", "Peer machine: ssh host, optionally with :<town path> (required)")
+	syncCmd.Flags().StringVar(&syncPrefer, "prefer", "", "Settle conflicts with this side's version: local or remote")
+	syncCmd.Flags().BoolVarP(&syncDryRun, "dry-run", "n", false, "Show what would change without changing anything")
+	syncCmd.Flags().BoolVar(&syncJSON, "json", false, "Output the plan as JSON")
+	syncCmd.Flags().StringVar(&syncSSH, "ssh", "ssh", "ssh command and options")
+	syncCmd.Flags().StringVar(&syncRemoteGT, "remote-gt", "gt", "gt binary on the peer")
+	_ = syncCmd.MarkFlagRequired("peer")
+
+	syncServeCmd.Flags().StringVar(&syncServeDir, "town", "", "Town root to serve (default: current town)")
+
+	syncCmd.AddCommand(syncServeCmd)
+	rootCmd.AddCommand(syncCmd)
+}
+
+func runSync(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	prefer := townsync.Side(syncPrefer)
+	switch prefer {
+	case townsync.PreferNone, townsync.PreferLocal, townsync.PreferRemote:
+	default:
+		return fmt.Errorf("--prefer must be local or remote, not %q", syncPrefer)
+	}
+	peer, err := townsync.ParsePeer(syncPeer, townRoot)
+	if err != nil {
+		return err
+	}
+	peer.Command = strings.Fields(syncSSH)
+	peer.GT = syncRemoteGT
+
+	local := &townsync.Dir{Root: townRoot, Beads: true}
+	plan, err := townsync.Sync(context.Background(), local, peer, townsync.Options{
+		BaseDir: townsync.BaseDir(townRoot, peer.String()),
+		Prefer:  prefer,
+		DryRun:  syncDryRun,
+	})
+	if err != nil {
+		return err
+	}
+	conflicts := plan.Conflicts()
+
+	if syncJSON {
+		if err := outputJSON(plan); err != nil {
+			return err
+		}
+	} else {
+		printSyncPlan(plan, peer.String())
+	}
+	if len(conflicts) > 0 {
+		return NewSilentExit(1)
+	}
+	return nil
+}
+
+// printSyncPlan lists a sync's changes and sums them up.
+func printSyncPlan(plan *townsync.Plan, peer string) {
+	if len(plan.Changes) == 0 {
+		fmt.Printf("%s In sync with %s\n", style.Success.Render("✓"), peer)
+		return
+	}
+	for _, c := range plan.Changes {
+		label := string(c.Action)
+		if c.Deleted {
+			label += " (delete)"
+		}
+		switch c.Action {
+		case townsync.Conflict:
+			label = style.Error.Render(label)
+		case townsync.Merge:
+			label = style.Warning.Render(label)
+		}
+		fmt.Printf("  %-18s %s", label, c.Path)
+		if c.Detail != "" {
+			fmt.Printf("  %s", style.Dim.Render(c.Detail))
+		}
+		fmt.Println()
+	}
+
+	conflicts := len(plan.Conflicts())
+	synced := len(plan.Changes) - conflicts
+	switch {
+	case syncDryRun:
+		fmt.Printf("%s Dry run: would sync %d file(s) with %s\n", style.Dim.Render("ℹ"), synced, peer)
+	case synced > 0:
+		fmt.Printf("%s Synced %d file(s) with %s\n", style.Success.Render("✓"), synced, peer)
+	}
+	if conflicts > 0 {
+		fmt.Printf("%s %d conflict(s) left unsynced; resolve with --prefer local or --prefer remote\n",
+			style.Warning.Render("⚠"), conflicts)
+	}
+}
+
+func runSyncServe(cmd *cobra.Command, args []string) error {
+	townRoot := syncServeDir
+	if townRoot == "" {
+		var err error
+		if townRoot, err = workspace.FindFromCwdOrError(); err != nil {
+			return fmt.Errorf("not in a Gas Town workspace: %w", err)
+		}
+	}
+	return townsync.Serve(context.Background(), &townsync.Dir{Root: townRoot, Beads: true}, os.Stdin, os.Stdout)
+}
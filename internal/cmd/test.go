@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/testsuite"
+)
+
+// Test command flags
+var (
+	testSuites []string
+	testAll    bool
+	testBase   string
+	testList   bool
+)
+
+var testCmd = &cobra.Command{
+	Use:     "test [rig]",
+	GroupID: GroupWork,
+	Short:   "Run the rig's test suites that cover your changes",
+	Long: `Run the rig's test suites the same way the refinery does for an MR.
+
+Rigs declare named suites with path selectors in the merge_queue section of
+their config.json:
+
+  "test_suites": [
+    {"name": "unit", "command": "go test ./...", "paths": ["**/*.go", "go.mod"]},
+    {"name": "web",  "command": "npm test --prefix web", "paths": ["web/"]},
+    {"name": "lint", "command": "make lint"}
+  ]
+
+A suite without paths runs for every change. By default, gt test selects the
+suites covering the files changed on your branch since it diverged from the
+rig's default branch, plus uncommitted changes. The refinery selects the
+same way from the MR's diff. A rig with only test_command has one "default"
+suite.
+
+Tests run from the root of the current git repository (your worktree).
+
+Examples:
+  gt test                        # Suites covering your changes
+  gt test gastown --suite unit   # Just the unit suite
+  gt test --all                  # Every suite
+  gt test --list                 # Show suites and which would run`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runTest,
+}
+
+func init() {
+	testCmd.Flags().StringSliceVar(&testSuites, "suite", nil, "Run these suites (repeatable or comma-separated)")
+	testCmd.Flags().BoolVar(&testAll, "all", false, "Run every suite")
+	testCmd.Flags().StringVar(&testBase, "base", "", "Base ref to diff against (default: origin/<default branch>)")
+	testCmd.Flags().BoolVar(&testList, "list", false, "List suites and which ones your changes select, without running")
+
+	rootCmd.AddCommand(testCmd)
+}
+
+func runTest(cmd *cobra.Command, args []string) error {
+	rigName := ""
+	if len(args) > 0 {
+		rigName = args[0]
+	}
+	_, r, _, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+	eng := refinery.NewEngineer(r)
+	if err := eng.LoadConfig(); err != nil {
+		return fmt.Errorf("loading merge queue config: %w", err)
+	}
+	all := eng.TestSuites()
+	if len(all) == 0 {
+		return fmt.Errorf("rig %s has no test_suites or test_command in its merge_queue config", r.Name)
+	}
+
+	root, err := getGitRoot()
+	if err != nil {
+		return fmt.Errorf("not in a git repository: %w", err)
+	}
+	eng.SetWorkDir(root)
+
+	var suites []testsuite.Suite
+	switch {
+	case len(testSuites) > 0:
+		if suites, err = testsuite.Find(all, testSuites); err != nil {
+			return err
+		}
+	case testAll:
+		suites = all
+	default:
+		base := testBase
+		if base == "" {
+			base = "origin/" + r.DefaultBranch()
+		}
+		files, err := changedFilesSince(git.NewGit(root), base)
+		if err != nil {
+			style.PrintWarning("reading changes against %s: %v (selecting all suites)", base, err)
+		}
+		suites = testsuite.Select(all, files)
+	}
+
+	if testList {
+		selected := make(map[string]bool)
+		for _, s := range suites {
+			selected[s.Name] = true
+		}
+		for _, s := range all {
+			marker := style.Dim.Render("·")
+			if selected[s.Name] {
+				marker = style.Success.Render("●")
+			}
+			paths := "all paths"
+			if len(s.Paths) > 0 {
+				paths = strings.Join(s.Paths, ", ")
+			}
+			fmt.Printf("%s %s  %s\n    %s\n", marker, style.Bold.Render(s.Name), s.Command, style.Dim.Render(paths))
+		}
+		return nil
+	}
+
+	if len(suites) == 0 {
+		fmt.Println(style.Dim.Render("No test suites cover your changes"))
+		return nil
+	}
+	eng.SetOutput(os.Stderr)
+	result := eng.RunSuites(context.Background(), suites)
+	if !result.Success {
+		fmt.Printf("%s %s\n", style.Error.Render("✗"), result.Error)
+		return NewSilentExit(1)
+	}
+	fmt.Printf("%s Passed: %s\n", style.Success.Render("✓"), strings.Join(testsuite.Names(suites), ", "))
+	return nil
+}
+
+// changedFilesSince lists files changed on HEAD since it diverged from base,
+// plus uncommitted changes. Renames count as changes to both paths.
+func changedFilesSince(g *git.Git, base string) ([]string, error) {
+	changes, err := g.ChangedFiles(base, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	files := []string{}
+	for _, c := range changes {
+		files = append(files, c.Path)
+		if c.OldPath != "" {
+			files = append(files, c.OldPath)
+		}
+	}
+	if status, err := g.Status(); err == nil {
+		for _, list := range [][]string{status.Modified, status.Added, status.Deleted, status.Untracked} {
+			files = append(files, list...)
+		}
+	}
+	return files, nil
+}
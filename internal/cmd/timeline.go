@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/timeline"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Timeline command flags
+var (
+	timelineJSON    bool
+	timelineNoGit   bool
+	timelineCommits int
+)
+
+var timelineCmd = &cobra.Command{
+	Use:     "timeline <issue-id>",
+	GroupID: GroupWork,
+	Short:   "Show everything that happened to an issue, in order",
+	Long: `Show an issue's history as one chronological view:
+
+  bead      Created and closed
+  comment   Comments on the issue
+  agent     Slings, hooks, and done from the event log
+  mr        Merge requests filed for the issue and what the queue did with them
+  git       Commits in the rig's repository that mention the issue
+  event     Anything else in the event log that mentions the issue or its MRs
+
+Examples:
+  gt timeline gt-abc
+  gt timeline gt-abc --json
+  gt timeline gt-abc --no-git`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTimeline,
+}
+
+func init() {
+	timelineCmd.Flags().BoolVar(&timelineJSON, "json", false, "Output as JSON")
+	timelineCmd.Flags().BoolVar(&timelineNoGit, "no-git", false, "Skip commits from the rig's repository")
+	timelineCmd.Flags().IntVar(&timelineCommits, "commits", 20, "Maximum commits to include")
+
+	rootCmd.AddCommand(timelineCmd)
+}
+
+func runTimeline(cmd *cobra.Command, args []string) error {
+	id := args[0]
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	bd := beads.New(resolveBeadDir(id))
+	issue, err := bd.Show(id)
+	if err != nil {
+		return fmt.Errorf("fetching %s: %w", id, err)
+	}
+	subject := timeline.Issue{
+		ID:          issue.ID,
+		Title:       issue.Title,
+		Status:      issue.Status,
+		Assignee:    issue.Assignee,
+		CreatedBy:   issue.CreatedBy,
+		CloseReason: issue.CloseReason,
+		Created:     parseRetroTime(issue.CreatedAt),
+		Closed:      parseRetroTime(issue.ClosedAt),
+	}
+
+	// Older bd versions lack comments; the rest of the timeline still stands
+	var comments []timeline.Comment
+	if list, err := bd.Comments(id); err == nil {
+		for _, c := range list {
+			comments = append(comments, timeline.Comment{At: parseRetroTime(c.CreatedAt), Author: c.Author, Text: c.Text})
+		}
+	}
+
+	var mrs []timeline.MergeRequest
+	var commits []timeline.Commit
+	// Town-level beads have no rig, so no merge queue or repository
+	if _, r, err := getRigForBead(id); err == nil {
+		mrs = timelineMergeRequests(beads.New(r.BeadsPath()), id)
+		if !timelineNoGit {
+			commits = timelineCommitsFor(git.NewGit(constants.RigMayorPath(r.Path)), "origin/"+r.DefaultBranch(), id)
+		}
+	}
+
+	evts, err := events.Read(townRoot, nil)
+	if err != nil {
+		return fmt.Errorf("reading event log: %w", err)
+	}
+	t := timeline.Build(subject, comments, mrs, commits, evts)
+
+	if timelineJSON {
+		return outputJSON(t)
+	}
+	printTimeline(t)
+	return nil
+}
+
+// timelineMergeRequests returns the MRs in a rig's beads filed for issueID.
+func timelineMergeRequests(bd *beads.Beads, issueID string) []timeline.MergeRequest {
+	var out []timeline.MergeRequest
+	for _, status := range []string{"open", "closed"} {
+		list, err := bd.List(beads.ListOptions{Status: status, Type: "merge-request", Priority: -1})
+		if err != nil {
+			continue
+		}
+		for _, mr := range list {
+			fields := beads.ParseMRFields(mr)
+			if fields == nil || fields.SourceIssue != issueID {
+				continue
+			}
+			closeReason := mr.CloseReason
+			if closeReason == "" {
+				closeReason = fields.CloseReason
+			}
+			out = append(out, timeline.MergeRequest{
+				ID:          mr.ID,
+				Branch:      fields.Branch,
+				Status:      mr.Status,
+				MergeCommit: fields.MergeCommit,
+				CloseReason: closeReason,
+				Created:     parseRetroTime(mr.CreatedAt),
+				Closed:      parseRetroTime(mr.ClosedAt),
+			})
+		}
+	}
+	return out
+}
+
+// timelineCommitsFor returns the commits on ref whose message mentions
+// issueID, falling back to HEAD when ref doesn't exist.
+func timelineCommitsFor(g *git.Git, ref, issueID string) []timeline.Commit {
+	if _, err := g.Rev(ref); err != nil {
+		ref = "HEAD"
+	}
+	log, err := g.Log(ref, timelineCommits, issueID)
+	if err != nil {
+		return nil
+	}
+	commits := make([]timeline.Commit, 0, len(log))
+	for _, c := range log {
+		commits = append(commits, timeline.Commit{SHA: c.SHA, Subject: c.Subject, Author: c.Author, At: parseRetroTime(c.Date)})
+	}
+	return commits
+}
+
+// printTimeline prints a timeline, one entry per line.
+func printTimeline(t *timeline.Timeline) {
+	fmt.Printf("%s %s [%s]", style.Bold.Render(t.Issue.ID), t.Issue.Title, t.Issue.Status)
+	if t.Issue.Assignee != "" {
+		fmt.Printf(" %s", style.Dim.Render(t.Issue.Assignee))
+	}
+	fmt.Println()
+	if len(t.Entries) == 0 {
+		fmt.Println(style.Dim.Render("  No history recorded"))
+		return
+	}
+	for _, e := range t.Entries {
+		actor := e.Actor
+		if actor == "" {
+			actor = "-"
+		}
+		fmt.Printf("  %s  %-7s  %-24s  %s\n",
+			style.Dim.Render(e.At.Local().Format(time.DateTime)), e.Source, actor, timelineText(e))
+	}
+}
+
+// timelineText styles an entry's text, highlighting failures.
+func timelineText(e timeline.Entry) string {
+	switch e.Type {
+	case events.TypeMergeFailed, events.TypeLandingFailed, events.TypeMRReverted, events.TypePRFailed, events.TypeSessionDeath:
+		return style.Error.Render(e.Text)
+	case events.TypeMerged, "closed":
+		return style.Success.Render(e.Text)
+	}
+	return e.Text
+}
@@ -0,0 +1,276 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/procstat"
+	"github.com/steveyegge/gastown/internal/session"
+	"github.com/steveyegge/gastown/internal/tmux"
+	"github.com/steveyegge/gastown/internal/tui/top"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Top command flags
+var (
+	topInterval time.Duration
+	topSort     string
+	topOnce     bool
+	topJSON     bool
+)
+
+var topCmd = &cobra.Command{
+	Use:     "top",
+	GroupID: GroupDiag,
+	Short:   "Live resource view of running agents",
+	Long: `Show every running agent with the resources its session consumes,
+refreshed live like top(1).
+
+For each agent session:
+  CPU%, MEM   Summed over the session's whole process tree (the agent
+              and everything it spawned: builds, tests, language servers)
+  TOKENS      Tokens used by the agent's current transcript, and the
+              rate per minute since the previous refresh
+  COST        Estimated cost of the current transcript
+  IDLE        Time since the session last saw activity
+  ISSUE       The bead hooked to (or in progress by) the agent
+
+Keys:
+  j/k, ↑/↓    Select an agent
+  s / r       Cycle the sort column (cpu, mem, tokens, cost, activity,
+              name) / reverse it
+  x           Kill the selected session and its processes (asks first)
+  n           Nudge the selected agent with a message
+  R           Refresh now
+  q           Quit
+
+Examples:
+  gt top                        # Live view, sorted by CPU
+  gt top --sort tokens -i 5s    # Sort by tokens, refresh every 5s
+  gt top --once                 # Print one snapshot and exit
+  gt top --json                 # Snapshot as JSON`,
+	Args: cobra.NoArgs,
+	RunE: runTop,
+}
+
+func init() {
+	topCmd.Flags().DurationVarP(&topInterval, "interval", "i", 2*time.Second, "Refresh interval")
+	topCmd.Flags().StringVar(&topSort, "sort", top.SortCPU, "Sort column: "+strings.Join(top.SortColumns, ", "))
+	topCmd.Flags().BoolVar(&topOnce, "once", false, "Print one snapshot and exit")
+	topCmd.Flags().BoolVar(&topJSON, "json", false, "Print one snapshot as JSON and exit")
+
+	rootCmd.AddCommand(topCmd)
+}
+
+func runTop(cmd *cobra.Command, args []string) error {
+	if !slices.Contains(top.SortColumns, topSort) {
+		return fmt.Errorf("--sort %q: want one of %s", topSort, strings.Join(top.SortColumns, ", "))
+	}
+	if topInterval < 500*time.Millisecond {
+		return fmt.Errorf("--interval must be at least 500ms")
+	}
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	t := tmux.NewTmux()
+	sampler := newTopSampler(townRoot, t)
+
+	if topOnce || topJSON {
+		agents, err := sampler.Sample()
+		if err != nil {
+			return err
+		}
+		top.Sort(agents, topSort, false)
+		if topJSON {
+			return outputJSON(agents)
+		}
+		if len(agents) == 0 {
+			fmt.Println("No agents running.")
+			return nil
+		}
+		fmt.Print(top.Table(agents, -1, 0, time.Now()))
+		return nil
+	}
+
+	actions := top.Actions{
+		Kill: t.KillSessionWithProcesses,
+		Nudge: func(sessionName, message string) error {
+			sender := detectSender()
+			if err := t.NudgeSession(sessionName, fmt.Sprintf("[from %s] %s", sender, message)); err != nil {
+				return err
+			}
+			_ = events.LogFeed(events.TypeNudge, sender, events.NudgePayload("", sessionName, message))
+			return nil
+		},
+	}
+	p := tea.NewProgram(top.New(sampler.Sample, actions, topInterval, topSort), tea.WithAltScreen())
+	_, err = p.Run()
+	return err
+}
+
+// topIssueTTL is how long hooked issue lookups are reused between refreshes;
+// they cost a bd call per rig.
+const topIssueTTL = 15 * time.Second
+
+// topSampler collects agents for gt top, caching what is expensive to
+// recompute every refresh.
+type topSampler struct {
+	townRoot string
+	tmux     *tmux.Tmux
+
+	mu          sync.Mutex
+	transcripts map[string]topTranscript // transcript path → parsed usage
+	issues      map[string]*beads.Issue  // agent address → current issue
+	issuesAt    time.Time
+}
+
+// topTranscript is a transcript's usage as of its size and mtime.
+type topTranscript struct {
+	size  int64
+	mod   time.Time
+	usage *TokenUsage
+}
+
+func newTopSampler(townRoot string, t *tmux.Tmux) *topSampler {
+	return &topSampler{
+		townRoot:    townRoot,
+		tmux:        t,
+		transcripts: make(map[string]topTranscript),
+	}
+}
+
+// Sample returns the running agent sessions.
+func (s *topSampler) Sample() ([]top.Agent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessions, err := s.tmux.ListSessions()
+	if err != nil {
+		return nil, fmt.Errorf("listing sessions: %w", err)
+	}
+	snap, err := procstat.Take()
+	if err != nil {
+		return nil, err
+	}
+	issues := s.currentIssues()
+
+	var agents []top.Agent
+	for _, name := range sessions {
+		id, err := session.ParseSessionName(name)
+		if err != nil {
+			continue // not a Gas Town session
+		}
+		a := top.Agent{
+			Session: name,
+			Address: id.Address(),
+			Role:    string(id.Role),
+			Rig:     id.Rig,
+		}
+		if a.Address == "" {
+			a.Address = name
+		}
+		if pidStr, err := s.tmux.GetPanePID(name); err == nil {
+			if pid, err := strconv.Atoi(strings.TrimSpace(pidStr)); err == nil {
+				u := snap.Tree(pid)
+				a.CPU, a.RSS, a.Procs = u.CPU, u.RSS, u.Procs
+			}
+		}
+		if workDir, err := getTmuxSessionWorkDir(name); err == nil {
+			if usage := s.usage(workDir); usage != nil {
+				a.Tokens = usage.InputTokens + usage.CacheCreationInputTokens + usage.CacheReadInputTokens + usage.OutputTokens
+				a.Cost = calculateCost(usage)
+			}
+		}
+		if info, err := s.tmux.GetSessionInfo(name); err == nil {
+			if unix, err := strconv.ParseInt(info.Activity, 10, 64); err == nil && unix > 0 {
+				a.Activity = time.Unix(unix, 0)
+			}
+		}
+		if issue := issues[id.Address()]; issue != nil {
+			a.Issue, a.Title = issue.ID, issue.Title
+		}
+		agents = append(agents, a)
+	}
+	return agents, nil
+}
+
+// usage returns the token usage of the latest transcript for workDir,
+// re-parsing it only when it has changed.
+func (s *topSampler) usage(workDir string) *TokenUsage {
+	projectDir, err := getClaudeProjectDir(workDir)
+	if err != nil {
+		return nil
+	}
+	path, err := findLatestTranscript(projectDir)
+	if err != nil {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+	if cached, ok := s.transcripts[path]; ok && cached.size == info.Size() && cached.mod.Equal(info.ModTime()) {
+		return cached.usage
+	}
+	usage, err := parseTranscriptUsage(path)
+	if err != nil {
+		return nil
+	}
+	s.transcripts[path] = topTranscript{size: info.Size(), mod: info.ModTime(), usage: usage}
+	return usage
+}
+
+// currentIssues maps agent addresses to the issue each has hooked or in
+// progress, across the town's and every rig's beads.
+func (s *topSampler) currentIssues() map[string]*beads.Issue {
+	if s.issues != nil && time.Since(s.issuesAt) < topIssueTTL {
+		return s.issues
+	}
+	dirs := []string{beads.GetTownBeadsPath(s.townRoot)}
+	if rigs, _, err := getAllRigs(); err == nil {
+		for _, r := range rigs {
+			dirs = append(dirs, filepath.Join(r.Path, "mayor", "rig"))
+		}
+	}
+
+	issues := make(map[string]*beads.Issue)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, dir := range dirs {
+		wg.Add(1)
+		go func(dir string) {
+			defer wg.Done()
+			b := beads.New(dir)
+			for _, status := range []string{beads.StatusHooked, "in_progress"} {
+				list, err := b.List(beads.ListOptions{Status: status, Priority: -1})
+				if err != nil {
+					continue
+				}
+				mu.Lock()
+				for _, issue := range list {
+					// A hooked issue wins over one merely in progress
+					if issue.Assignee != "" && issues[issue.Assignee] == nil {
+						issues[issue.Assignee] = issue
+					}
+				}
+				mu.Unlock()
+			}
+		}(dir)
+	}
+	wg.Wait()
+
+	s.issues, s.issuesAt = issues, time.Now()
+	return issues
+}
@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
@@ -11,6 +10,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/timefmt"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
@@ -191,9 +191,7 @@ func runTrailCommits(cmd *cobra.Command, args []string) error {
 	}
 
 	if trailJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(commits)
+		return outputJSON(commits)
 	}
 
 	// Text output
@@ -285,9 +283,7 @@ func runTrailBeads(cmd *cobra.Command, args []string) error {
 	}
 
 	if trailJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(beads)
+		return outputJSON(beads)
 	}
 
 	// Text output
@@ -359,31 +355,5 @@ func findBeadsDir() (string, error) {
 }
 
 func relativeTime(t time.Time) string {
-	if t.IsZero() {
-		return ""
-	}
-
-	diff := time.Since(t)
-	switch {
-	case diff < time.Minute:
-		return "just now"
-	case diff < time.Hour:
-		mins := int(diff.Minutes())
-		if mins == 1 {
-			return "1 minute ago"
-		}
-		return fmt.Sprintf("%d minutes ago", mins)
-	case diff < 24*time.Hour:
-		hours := int(diff.Hours())
-		if hours == 1 {
-			return "1 hour ago"
-		}
-		return fmt.Sprintf("%d hours ago", hours)
-	default:
-		days := int(diff.Hours() / 24)
-		if days == 1 {
-			return "1 day ago"
-		}
-		return fmt.Sprintf("%d days ago", days)
-	}
+	return timefmt.Ago(t)
 }
@@ -20,6 +20,7 @@ import (
 	"github.com/steveyegge/gastown/internal/polecat"
 	"github.com/steveyegge/gastown/internal/refinery"
 	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/shutdown"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/wisp"
@@ -59,6 +60,12 @@ Use --restore to also start:
   • Crew       - Per rig settings (settings/config.json crew.startup)
   • Polecats   - Those with pinned beads (work attached)
 
+Use --resume after 'gt down --checkpoint' to respawn the polecat and crew
+agents recorded in the resume manifest on the issues they had hooked. They
+pick up from their checkpoints. The manifest is removed once every agent
+is back. The daemon brings back scheduled work (patrols, plugin gates,
+retention) as it starts.
+
 Running 'gt up' multiple times is safe - it only starts services that
 aren't already running.`,
 	RunE: runUp,
@@ -67,11 +74,13 @@ aren't already running.`,
 var (
 	upQuiet   bool
 	upRestore bool
+	upResume  bool
 )
 
 func init() {
 	upCmd.Flags().BoolVarP(&upQuiet, "quiet", "q", false, "Only show errors")
 	upCmd.Flags().BoolVar(&upRestore, "restore", false, "Also restore crew (from settings) and polecats (from hooks)")
+	upCmd.Flags().BoolVar(&upResume, "resume", false, "Respawn agents checkpointed by gt down --checkpoint on their previous issues")
 	rootCmd.AddCommand(upCmd)
 }
 
@@ -210,7 +219,25 @@ func runUp(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// 8. Agents checkpointed by gt down --checkpoint (if --resume)
+	manifest, err := shutdown.Read(townRoot)
+	if err != nil {
+		printStatus("Resume manifest", false, err.Error())
+		allOK = false
+	} else if upResume {
+		if manifest == nil {
+			printStatus("Resume", true, "nothing to resume")
+		} else if !resumeWorkers(townRoot, manifest) {
+			allOK = false
+		}
+	}
+
 	fmt.Println()
+	if manifest != nil && !upResume {
+		fmt.Printf("%s %d agent(s) were checkpointed by gt down at %s; run %s to respawn them\n",
+			style.Warning.Render("⚠"), len(manifest.Agents), manifest.CreatedAt.Local().Format("Jan 2 15:04"),
+			style.Bold.Render("gt up --resume"))
+	}
 	if allOK {
 		fmt.Printf("%s All services running\n", style.Bold.Render("✓"))
 		// Log boot event with started services
@@ -484,8 +511,10 @@ func discoverRigs(townRoot string) []string {
 	// Try rigs.json first
 	rigsConfigPath := filepath.Join(townRoot, "mayor", "rigs.json")
 	if rigsConfig, err := config.LoadRigsConfig(rigsConfigPath); err == nil {
-		for name := range rigsConfig.Rigs {
-			rigs = append(rigs, name)
+		for name, entry := range rigsConfig.Rigs {
+			if entry.Archived == nil {
+				rigs = append(rigs, name)
+			}
 		}
 		return rigs
 	}
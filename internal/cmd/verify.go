@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// Verify command flags
+var (
+	verifyFirstParent bool
+	verifyJSON        bool
+)
+
+var verifyCmd = &cobra.Command{
+	Use:     "verify <rig> <range>",
+	GroupID: GroupDiag,
+	Short:   "Check that landings were made and signed by the refinery",
+	Long: `Check every commit in a range was landed by the refinery: committed as
+the refinery's identity and carrying a good signature from its key.
+
+Use it for supply-chain attestation of agent-written code: a commit that
+was pushed around the queue, or signed by any other key, is reported.
+The range is a git revision range in the rig's refinery clone, which is
+fetched first. Signing is configured by merge_queue.signing (see
+'gt mq sign').
+
+Exits 1 if any commit fails.
+
+Examples:
+  gt verify gastown v1.4.0..origin/main
+  gt verify gastown origin/main~50..origin/main --json
+  gt verify gastown v1.4.0..origin/main --first-parent   # Rigs landing merge commits`,
+	Args: cobra.ExactArgs(2),
+	RunE: runVerify,
+}
+
+func init() {
+	verifyCmd.Flags().BoolVar(&verifyFirstParent, "first-parent", false, "Only check the first-parent chain (for merge-commit landings)")
+	verifyCmd.Flags().BoolVar(&verifyJSON, "json", false, "Output as JSON")
+	rootCmd.AddCommand(verifyCmd)
+}
+
+// VerifyOutput is the output of 'gt verify --json'.
+type VerifyOutput struct {
+	Rig        string                      `json:"rig"`
+	Range      string                      `json:"range"`
+	Checked    int                         `json:"checked"`
+	Verified   bool                        `json:"verified"`
+	Violations []refinery.LandingViolation `json:"violations"`
+}
+
+func runVerify(cmd *cobra.Command, args []string) error {
+	rigName, revRange := args[0], args[1]
+	_, r, _, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+	eng := refinery.NewEngineer(r)
+	if err := eng.LoadConfig(); err != nil {
+		return fmt.Errorf("loading merge queue config: %w", err)
+	}
+
+	commits, bad, err := eng.VerifyLandings(revRange, verifyFirstParent)
+	if err != nil {
+		return err
+	}
+
+	if verifyJSON {
+		if bad == nil {
+			bad = []refinery.LandingViolation{}
+		}
+		if err := outputJSON(VerifyOutput{
+			Rig: rigName, Range: revRange, Checked: len(commits), Verified: len(bad) == 0, Violations: bad,
+		}); err != nil {
+			return err
+		}
+	} else {
+		printVerify(eng.Signing(), revRange, commits, bad)
+	}
+	if len(bad) > 0 {
+		return NewSilentExit(1)
+	}
+	return nil
+}
+
+func printVerify(s *git.Signing, revRange string, commits []git.CommitSignature, bad []refinery.LandingViolation) {
+	if len(commits) == 0 {
+		fmt.Printf("%s No commits in %s\n", style.Dim.Render("○"), revRange)
+		return
+	}
+	if len(bad) == 0 {
+		fmt.Printf("%s All %d commits in %s were landed and signed by %s <%s>\n",
+			style.Success.Render("✓"), len(commits), revRange, s.Name, s.Email)
+		return
+	}
+	fmt.Printf("%s %d of %d commits in %s were not landed by the refinery:\n\n",
+		style.Error.Render("✗"), len(bad), len(commits), revRange)
+	for _, v := range bad {
+		fmt.Printf("  %s %s\n", style.Bold.Render(shortSHA(v.Commit.SHA)), v.Commit.Subject)
+		fmt.Printf("    %s\n", style.Dim.Render(v.Reason))
+	}
+}
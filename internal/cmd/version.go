@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/outputversion"
 	"github.com/steveyegge/gastown/internal/version"
 )
 
@@ -23,6 +24,19 @@ var (
 	BuiltProperly = ""
 )
 
+var versionJSON bool
+
+// VersionInfo is the output of 'gt version --json'. Harnesses read the
+// output versions to check the shape they pin is still supported.
+type VersionInfo struct {
+	Version             string `json:"version"`
+	Build               string `json:"build"`
+	Commit              string `json:"commit,omitempty"`
+	Branch              string `json:"branch,omitempty"`
+	OutputVersion       int    `json:"current_output_version"`
+	OldestOutputVersion int    `json:"oldest_output_version"`
+}
+
 var versionCmd = &cobra.Command{
 	Use:     "version",
 	GroupID: GroupDiag,
@@ -31,6 +45,18 @@ var versionCmd = &cobra.Command{
 		commit := resolveCommitHash()
 		branch := resolveBranch()
 
+		if versionJSON {
+			_ = outputJSON(VersionInfo{
+				Version:             Version,
+				Build:               Build,
+				Commit:              commit,
+				Branch:              branch,
+				OutputVersion:       outputversion.Current,
+				OldestOutputVersion: outputversion.Oldest,
+			})
+			return
+		}
+
 		if commit != "" && branch != "" {
 			fmt.Printf("gt version %s (%s: %s@%s)\n", Version, Build, branch, version.ShortCommit(commit))
 		} else if commit != "" {
@@ -42,6 +68,7 @@ var versionCmd = &cobra.Command{
 }
 
 func init() {
+	versionCmd.Flags().BoolVar(&versionJSON, "json", false, "Output as JSON")
 	rootCmd.AddCommand(versionCmd)
 
 	// Pass the build-time commit to the version package for stale binary checks
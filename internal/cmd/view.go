@@ -0,0 +1,272 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/query"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/view"
+)
+
+// View command flags
+var (
+	viewJSON     bool
+	viewIDs      bool
+	viewMarkdown bool
+	viewQuery    string
+)
+
+var viewCmd = &cobra.Command{
+	Use:     "view [name]",
+	GroupID: GroupWork,
+	Short:   "Run a saved query over issues and merge requests",
+	Long: `Run a saved query ("view") over issues and merge requests across rigs.
+With no name, list the views.
+
+Views are defined in town settings (settings/config.json):
+
+  "views": {
+    "p0-open": {
+      "description": "Every open P0",
+      "max_priority": 0
+    },
+    "acme-bugs": {
+      "type": "bug",
+      "fields": {"customer": "acme"},
+      "rigs": ["gastown", "beads"],
+      "sort": "updated",
+      "limit": 20
+    },
+    "ready-mrs": {"kind": "mrs", "status": "ready"},
+    "stale-infra": {"query": "label:infra and updated<14d and not assigned"}
+  }
+
+Filters: rigs ("town" for town beads), kind (issues, mrs, all), status
+(open, in_progress, closed, all, ready, blocked), type, labels, assignee
+("none" for unassigned), max_priority, and custom fields. Sort by
+priority, created, or updated; limit caps the results. A "query" (see
+'gt help query') says what the fields can't; --query narrows a view
+further for one run.
+
+Views plug into other tools by their output:
+
+  gt sling $(gt view acme-bugs --ids) gastown   # Dispatch a view's work
+  gt view p0-open --markdown >> report.md       # Add it to a report
+  gt view ready-mrs --json                      # Feed a plugin or schedule
+
+Examples:
+  gt view                  # List views
+  gt view p0-open
+  gt view acme-bugs --json
+  gt view acme-bugs --query 'priority<=1'`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runView,
+}
+
+func init() {
+	viewCmd.Flags().BoolVar(&viewJSON, "json", false, "Output as JSON")
+	viewCmd.Flags().BoolVar(&viewIDs, "ids", false, "Print only the matching IDs, one per line")
+	viewCmd.Flags().BoolVar(&viewMarkdown, "markdown", false, "Output as a Markdown report section")
+	viewCmd.Flags().StringVar(&viewQuery, "query", "", "Narrow the view with a query (see 'gt help query')")
+
+	rootCmd.AddCommand(viewCmd)
+}
+
+func runView(cmd *cobra.Command, args []string) error {
+	rigs, townRoot, err := getAllRigs()
+	if err != nil {
+		return err
+	}
+	views, err := view.Load(townRoot)
+	if err != nil {
+		return fmt.Errorf("loading views: %w", err)
+	}
+	if len(args) == 0 {
+		return listViews(views)
+	}
+
+	name := args[0]
+	v, ok := views[name]
+	if !ok {
+		if len(views) == 0 {
+			return fmt.Errorf("unknown view %q: no views defined in settings/config.json", name)
+		}
+		return fmt.Errorf("unknown view %q (have: %s)", name, strings.Join(view.Names(views), ", "))
+	}
+	if err := view.Validate(name, v); err != nil {
+		return err
+	}
+	q, err := view.Query(v, viewQuery)
+	if err != nil {
+		return fmt.Errorf("invalid --query: %w", err)
+	}
+
+	snap := beads.NewSnapshot()
+	rows, errs := runViewQuery(v, q, townRoot, rigs, snap)
+	for _, e := range errs {
+		style.PrintWarning("%v", e)
+	}
+
+	switch {
+	case viewJSON:
+		if rows == nil {
+			rows = []view.Row{}
+		}
+		return outputJSON(rows)
+	case viewIDs:
+		for _, r := range rows {
+			fmt.Println(r.ID)
+		}
+		return nil
+	case viewMarkdown:
+		fmt.Print(viewMarkdownSection(name, v, rows))
+		return nil
+	}
+	printViewRows(name, v, rows)
+	fmt.Println()
+	printSnapshotLine(snap.Info())
+	return nil
+}
+
+// runViewQuery runs a view against the town and each rig it covers, in
+// parallel, cut off at snap. Sources that fail are reported and skipped.
+func runViewQuery(v *config.ViewConfig, q *query.Query, townRoot string, rigs []*rig.Rig, snap *beads.Snapshot) ([]view.Row, []error) {
+	type source struct{ name, path string }
+	var sources []source
+	if view.Covers(v, view.TownSource) && q.Covers(view.TownSource) {
+		sources = append(sources, source{view.TownSource, beads.GetTownBeadsPath(townRoot)})
+	}
+	for _, r := range rigs {
+		if view.Covers(v, r.Name) && q.Covers(r.Name) {
+			sources = append(sources, source{r.Name, r.BeadsPath()})
+		}
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		rows []view.Row
+		errs []error
+	)
+	for _, src := range sources {
+		wg.Add(1)
+		go func(src source) {
+			defer wg.Done()
+			issues, err := fetchViewIssues(v, beads.New(src.path))
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", src.name, err))
+				return
+			}
+			var matched []*beads.Issue
+			for _, issue := range filterIdentityBeads(issues) {
+				issue.Fields = beads.CustomFields(issue.Labels)
+				if view.Match(v, issue) && q.Match(query.Record{Rig: src.name, Issue: issue}) {
+					matched = append(matched, issue)
+				}
+			}
+			for _, issue := range snap.Cut(matched) {
+				rows = append(rows, view.Row{Source: src.name, Issue: issue})
+			}
+		}(src)
+	}
+	wg.Wait()
+	return view.Order(v, rows), errs
+}
+
+// fetchViewIssues runs the bd query for a view's status, narrowing
+// server-side where bd can.
+func fetchViewIssues(v *config.ViewConfig, bd *beads.Beads) ([]*beads.Issue, error) {
+	switch view.Status(v) {
+	case view.StatusReady:
+		return bd.Ready()
+	case view.StatusBlocked:
+		return bd.Blocked()
+	}
+	opts := beads.ListOptions{Status: view.Status(v), Priority: -1}
+	switch {
+	case v.Kind == view.KindMRs:
+		opts.Label = "gt:merge-request"
+	case len(v.Labels) > 0:
+		opts.Label = v.Labels[0]
+	}
+	switch v.Assignee {
+	case "":
+	case "none":
+		opts.NoAssignee = true
+	default:
+		opts.Assignee = v.Assignee
+	}
+	return bd.List(opts)
+}
+
+func listViews(views map[string]*config.ViewConfig) error {
+	if viewJSON {
+		if views == nil {
+			views = map[string]*config.ViewConfig{}
+		}
+		return outputJSON(views)
+	}
+	if len(views) == 0 {
+		fmt.Printf("%s No views defined (add \"views\" to settings/config.json)\n", style.Dim.Render("○"))
+		return nil
+	}
+	for _, name := range view.Names(views) {
+		v := views[name]
+		fmt.Printf("  %s  %s\n", style.Bold.Render(name), style.Dim.Render(view.Describe(v)))
+		if v.Description != "" {
+			fmt.Printf("    %s\n", v.Description)
+		}
+	}
+	return nil
+}
+
+func printViewRows(name string, v *config.ViewConfig, rows []view.Row) {
+	header := style.Bold.Render(name)
+	if v.Description != "" {
+		header += " " + style.Dim.Render("— "+v.Description)
+	}
+	fmt.Printf("%s (%d)\n\n", header, len(rows))
+	if len(rows) == 0 {
+		fmt.Printf("  %s\n", style.Dim.Render("Nothing matches"))
+		return
+	}
+	table := style.NewTable(
+		style.Column{Name: "ID", Width: 14},
+		style.Column{Name: "SOURCE", Width: 10},
+		style.Column{Name: "PRI", Width: 3},
+		style.Column{Name: "STATUS", Width: 11},
+		style.Column{Name: "TITLE", Width: 44},
+		style.Column{Name: "ASSIGNEE", Width: 20},
+	)
+	for _, r := range rows {
+		table.AddRow(r.ID, r.Source, fmt.Sprintf("P%d", r.Priority), r.Status, r.Title, r.Assignee)
+	}
+	fmt.Print(table.Render())
+}
+
+// viewMarkdownSection renders a view's results as a report section.
+func viewMarkdownSection(name string, v *config.ViewConfig, rows []view.Row) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "## %s\n\n", name)
+	if v.Description != "" {
+		fmt.Fprintf(&sb, "%s\n\n", v.Description)
+	}
+	if len(rows) == 0 {
+		sb.WriteString("Nothing matches.\n")
+		return sb.String()
+	}
+	sb.WriteString("| ID | Rig | Priority | Status | Title | Assignee |\n|---|---|---|---|---|---|\n")
+	for _, r := range rows {
+		title := strings.ReplaceAll(r.Title, "|", "\\|")
+		fmt.Fprintf(&sb, "| %s | %s | P%d | %s | %s | %s |\n", r.ID, r.Source, r.Priority, r.Status, title, r.Assignee)
+	}
+	return sb.String()
+}
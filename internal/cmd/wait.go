@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// Exit codes for commands that block on an asynchronous outcome (--wait,
+// gt watch). 1 stays "the command itself failed".
+const (
+	exitWaitTimeout = 2 // Gave up before the outcome was known
+	exitWaitFailed  = 3 // The operation finished, unsuccessfully
+)
+
+// errWaitTimeout is returned by pollUntil when the timeout elapses first.
+var errWaitTimeout = errors.New("timed out")
+
+// pollUntil calls check every interval until it reports done or fails, or
+// until timeout elapses (0 waits forever). The first check runs at once.
+func pollUntil(interval, timeout time.Duration, check func() (bool, error)) error {
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	for {
+		done, err := check()
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+		wait := interval
+		if !deadline.IsZero() {
+			left := time.Until(deadline)
+			if left <= 0 {
+				return errWaitTimeout
+			}
+			if wait > left {
+				wait = left
+			}
+		}
+		time.Sleep(wait)
+	}
+}
+
+// waitExit turns a pollUntil error into the command's result: timeouts and
+// failures exit with their codes after printing what happened.
+func waitExit(err error, what string, timeout time.Duration) error {
+	var failed *waitFailedError
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, errWaitTimeout):
+		fmt.Fprintf(os.Stderr, "%s Timed out after %s waiting for %s\n", style.Error.Render("✗"), timeout, what)
+		return NewSilentExit(exitWaitTimeout)
+	case errors.As(err, &failed):
+		fmt.Fprintf(os.Stderr, "%s %s: %s\n", style.Error.Render("✗"), what, failed.reason)
+		return NewSilentExit(exitWaitFailed)
+	}
+	return err
+}
+
+// waitFailedError ends a wait because the operation finished unsuccessfully.
+type waitFailedError struct{ reason string }
+
+func (e *waitFailedError) Error() string { return e.reason }
+
+// mrWaitOutcome reports whether an MR being waited on has finished, and if
+// it failed, why. baseline is the MR's fields when the wait started, so a
+// resubmitted MR's earlier failures don't count.
+func mrWaitOutcome(issue *beads.Issue, baseline *beads.MRFields) (done bool, failure string) {
+	fields := beads.ParseMRFields(issue)
+	if fields == nil {
+		fields = &beads.MRFields{}
+	}
+	if issue.Status == "closed" {
+		switch fields.CloseReason {
+		case "merged", "":
+			return true, ""
+		default:
+			return true, "closed without merging (" + fields.CloseReason + ")"
+		}
+	}
+
+	if baseline == nil {
+		baseline = &beads.MRFields{}
+	}
+	if fields.RetryCount > baseline.RetryCount ||
+		(fields.ConflictTaskID != "" && fields.ConflictTaskID != baseline.ConflictTaskID) {
+		msg := "merge conflict"
+		if fields.ConflictTaskID != "" {
+			msg += " (resolution task " + fields.ConflictTaskID + ")"
+		}
+		return true, msg
+	}
+	if fields.TestTriage == "real" && (baseline.TestTriage != "real" || fields.FailedTests != baseline.FailedTests) {
+		msg := "tests failed"
+		if fields.FailedTests != "" {
+			msg += ": " + strings.ReplaceAll(fields.FailedTests, ",", ", ")
+		}
+		return true, msg
+	}
+	return false, ""
+}
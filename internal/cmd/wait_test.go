@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+func TestPollUntil(t *testing.T) {
+	calls := 0
+	err := pollUntil(time.Millisecond, time.Second, func() (bool, error) {
+		calls++
+		return calls == 3, nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("pollUntil = %v after %d calls, want nil after 3", err, calls)
+	}
+
+	err = pollUntil(time.Millisecond, 20*time.Millisecond, func() (bool, error) { return false, nil })
+	if !errors.Is(err, errWaitTimeout) {
+		t.Errorf("pollUntil = %v, want errWaitTimeout", err)
+	}
+
+	boom := errors.New("boom")
+	err = pollUntil(time.Millisecond, 0, func() (bool, error) { return false, boom })
+	if err != boom {
+		t.Errorf("pollUntil = %v, want check's error", err)
+	}
+}
+
+func TestWaitExit(t *testing.T) {
+	if code, ok := IsSilentExit(waitExit(errWaitTimeout, "x", time.Second)); !ok || code != exitWaitTimeout {
+		t.Errorf("timeout exit = %d, %v", code, ok)
+	}
+	if code, ok := IsSilentExit(waitExit(&waitFailedError{reason: "no"}, "x", time.Second)); !ok || code != exitWaitFailed {
+		t.Errorf("failed exit = %d, %v", code, ok)
+	}
+	if err := waitExit(nil, "x", time.Second); err != nil {
+		t.Errorf("waitExit(nil) = %v", err)
+	}
+}
+
+func TestMRWaitOutcome(t *testing.T) {
+	mr := func(status string, fields ...string) *beads.Issue {
+		return &beads.Issue{ID: "gt-mr", Status: status, Description: strings.Join(append([]string{"branch: b"}, fields...), "\n")}
+	}
+	tests := []struct {
+		name        string
+		issue       *beads.Issue
+		baseline    *beads.MRFields
+		wantDone    bool
+		wantFailure string
+	}{
+		{"open", mr("open"), nil, false, ""},
+		{"merged", mr("closed", "close_reason: merged"), nil, true, ""},
+		{"rejected", mr("closed", "close_reason: rejected"), nil, true, "closed without merging (rejected)"},
+		{"conflict", mr("open", "retry_count: 1", "conflict_task_id: gt-c1"), nil, true, "merge conflict (resolution task gt-c1)"},
+		{"earlier conflict", mr("open", "retry_count: 1", "conflict_task_id: gt-c1"),
+			&beads.MRFields{RetryCount: 1, ConflictTaskID: "gt-c1"}, false, ""},
+		{"tests failed", mr("open", "test_triage: real", "failed_tests: TestA,TestB"), nil, true, "tests failed: TestA, TestB"},
+		{"flaky only", mr("open", "test_triage: flaky"), nil, false, ""},
+		{"earlier test failure", mr("open", "test_triage: real", "failed_tests: TestA"),
+			&beads.MRFields{TestTriage: "real", FailedTests: "TestA"}, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			done, failure := mrWaitOutcome(tt.issue, tt.baseline)
+			if done != tt.wantDone || failure != tt.wantFailure {
+				t.Errorf("mrWaitOutcome = (%v, %q), want (%v, %q)", done, failure, tt.wantDone, tt.wantFailure)
+			}
+		})
+	}
+}
+
+func TestConvoyWaitOutcome(t *testing.T) {
+	issues := func(statuses ...string) []trackedIssueInfo {
+		var out []trackedIssueInfo
+		for _, s := range statuses {
+			out = append(out, trackedIssueInfo{Status: s})
+		}
+		return out
+	}
+	tests := []struct {
+		name        string
+		status      string
+		tracked     []trackedIssueInfo
+		wantDone    bool
+		wantFailure bool
+		wantClosed  int
+	}{
+		{"in progress", "open", issues("closed", "open"), false, false, 1},
+		{"complete", "open", issues("closed", "tombstone"), true, false, 2},
+		{"auto-closed", "closed", issues("closed"), true, false, 1},
+		{"abandoned", "closed", issues("closed", "open"), true, true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			done, failure, closed := convoyWaitOutcome(tt.status, tt.tracked)
+			if done != tt.wantDone || (failure != "") != tt.wantFailure || closed != tt.wantClosed {
+				t.Errorf("convoyWaitOutcome = (%v, %q, %d)", done, failure, closed)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/watch"
+)
+
+var watchCmd = &cobra.Command{
+	Use:     "watch <expr> -- <gt command...>",
+	GroupID: GroupDiag,
+	Short:   "Wait until a condition over a command's JSON output holds",
+	Long: `Run a gt command with --json every --interval until a condition over its
+output is true, then exit. Replaces sleep-loop shell scripts.
+
+The condition is evaluated against the command's JSON output:
+
+  summary.total == 0                         field access and comparison
+  status == "merged" || status == "failed"   && || ! and parentheses
+  len(issues) == 0                           length of an array or object
+  issues[0].priority <= 1                    array indexes
+  .[0].id == "gt-abc"                        the document root is "."
+
+A path that doesn't exist is null. --json is appended to the command if
+it isn't there already.
+
+EXIT CODES:
+  0 - Condition held
+  1 - Bad expression or command
+  2 - Timed out
+  3 - The command failed --max-errors times in a row
+
+Examples:
+  gt watch 'summary.total == 0' -- blocked
+  gt watch 'status == "merged"' --timeout 2h -- mq status gt-abc
+  gt watch 'len(.) == 0' --interval 1m -- ready --rig gastown
+  gt watch 'summary.total == 0' --notify mayor/ -- blocked --group infra`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runWatch,
+}
+
+var (
+	watchInterval  time.Duration
+	watchTimeout   time.Duration
+	watchNotify    string
+	watchMaxErrors int
+	watchQuiet     bool
+)
+
+func init() {
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", 10*time.Second, "Time between checks")
+	watchCmd.Flags().DurationVar(&watchTimeout, "timeout", 30*time.Minute, "Give up after this long (0 = never)")
+	watchCmd.Flags().StringVar(&watchNotify, "notify", "", "Mail this address when the condition holds")
+	watchCmd.Flags().IntVar(&watchMaxErrors, "max-errors", 5, "Give up after this many consecutive command failures (0 = never)")
+	watchCmd.Flags().BoolVarP(&watchQuiet, "quiet", "q", false, "Only report the outcome")
+	rootCmd.AddCommand(watchCmd)
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	dash := cmd.ArgsLenAtDash()
+	if dash != 1 {
+		return fmt.Errorf("usage: gt watch <expr> -- <gt command...>")
+	}
+	if watchInterval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	expr, err := watch.Parse(args[0])
+	if err != nil {
+		return fmt.Errorf("parsing condition: %w", err)
+	}
+	cmdArgs := watchCommandArgs(args[1:])
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("finding gt executable: %w", err)
+	}
+
+	label := "gt " + strings.Join(cmdArgs, " ")
+	if !watchQuiet {
+		fmt.Printf("Watching %s until %s\n", style.Bold.Render(label), style.Bold.Render(expr.String()))
+	}
+
+	start := time.Now()
+	var deadline time.Time
+	if watchTimeout > 0 {
+		deadline = start.Add(watchTimeout)
+	}
+	failures := 0
+	for {
+		held, err := checkWatch(exe, cmdArgs, expr)
+		switch {
+		case err != nil:
+			failures++
+			fmt.Fprintf(os.Stderr, "%s %s: %v\n", style.Warning.Render("⚠"), label, err)
+			if watchMaxErrors > 0 && failures >= watchMaxErrors {
+				fmt.Fprintf(os.Stderr, "%s Giving up after %d consecutive failures\n", style.Error.Render("✗"), failures)
+				return NewSilentExit(exitWaitFailed)
+			}
+		case held:
+			elapsed := time.Since(start).Round(time.Second)
+			fmt.Printf("%s %s (after %s)\n", style.SuccessPrefix, expr.String(), elapsed)
+			if watchNotify != "" {
+				notifyWatch(exe, label, expr.String(), elapsed)
+			}
+			return nil
+		default:
+			failures = 0
+			if !watchQuiet {
+				fmt.Printf("  %s %s\n", style.Dim.Render(time.Now().Format("15:04:05")), style.Dim.Render("not yet"))
+			}
+		}
+
+		wait := watchInterval
+		if !deadline.IsZero() {
+			left := time.Until(deadline)
+			if left <= 0 {
+				fmt.Fprintf(os.Stderr, "%s Timed out after %s waiting for %s\n",
+					style.Error.Render("✗"), watchTimeout, expr.String())
+				return NewSilentExit(exitWaitTimeout)
+			}
+			if wait > left {
+				wait = left
+			}
+		}
+		time.Sleep(wait)
+	}
+}
+
+// watchCommandArgs returns the command to run, with --json added unless it
+// already asks for JSON.
+func watchCommandArgs(args []string) []string {
+	for _, a := range args {
+		if a == "--json" || strings.HasPrefix(a, "--json=") {
+			return args
+		}
+	}
+	return append(append([]string{}, args...), "--json")
+}
+
+// checkWatch runs the command once and evaluates the condition against its
+// output.
+func checkWatch(exe string, args []string, expr *watch.Expr) (bool, error) {
+	var stdout, stderr bytes.Buffer
+	c := exec.Command(exe, args...) //nolint:gosec // G204: re-running gt with the user's arguments
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+	runErr := c.Run()
+
+	// Some commands exit nonzero with a JSON answer (e.g. "nothing found");
+	// only a run without JSON output counts as a failure.
+	var doc interface{}
+	if err := json.Unmarshal(stdout.Bytes(), &doc); err != nil {
+		if runErr != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return false, fmt.Errorf("%w: %s", runErr, msg)
+			}
+			return false, runErr
+		}
+		return false, fmt.Errorf("output is not JSON: %w", err)
+	}
+
+	return expr.Eval(doc)
+}
+
+// notifyWatch mails the --notify address that the condition held.
+func notifyWatch(exe, label, condition string, elapsed time.Duration) {
+	subject := "watch: " + condition
+	body := fmt.Sprintf("%s\n\nCondition %s held after %s.", label, condition, elapsed)
+	out, err := exec.Command(exe, "mail", "send", watchNotify, "-s", subject, "-m", body).CombinedOutput() //nolint:gosec // G204: args are constructed internally
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s Could not notify %s: %v\n%s", style.Warning.Render("⚠"), watchNotify, err, out)
+	}
+}
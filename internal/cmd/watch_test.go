@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWatchCommandArgs(t *testing.T) {
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"blocked"}, []string{"blocked", "--json"}},
+		{[]string{"mq", "status", "gt-abc", "--json"}, []string{"mq", "status", "gt-abc", "--json"}},
+		{[]string{"ready", "--json=true"}, []string{"ready", "--json=true"}},
+	}
+	for _, tt := range tests {
+		if got := watchCommandArgs(tt.args); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("watchCommandArgs(%v) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
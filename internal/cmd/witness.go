@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
@@ -259,9 +258,7 @@ func runWitnessStatus(cmd *cobra.Command, args []string) error {
 		if sessionInfo != nil {
 			output.Session = sessionInfo.Name
 		}
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(output)
+		return outputJSON(output)
 	}
 
 	// Human-readable output
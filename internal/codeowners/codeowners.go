@@ -0,0 +1,181 @@
+// Package codeowners reads CODEOWNERS files and works out whose review an
+// MR needs.
+//
+// The syntax is GitHub's: each line is a path pattern followed by owners,
+// and the last matching line wins. Owners are used as gt reviewer
+// identities ("gastown/crew/alice") or roles ("gastown/crew"), optionally
+// mapped from the handles a repo's file already uses ("@acme/payments").
+package codeowners
+
+import (
+	"bufio"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// DefaultPaths are where an ownership file is looked for, in order. The
+// gt-specific file lets a rig route reviews without touching the one a
+// forge reads.
+var DefaultPaths = []string{".gt/CODEOWNERS", "CODEOWNERS", ".github/CODEOWNERS", "docs/CODEOWNERS"}
+
+// Rule is one line of an ownership file.
+type Rule struct {
+	Pattern string
+	Owners  []string // empty means the path is explicitly unowned
+	Line    int
+}
+
+// Parse reads an ownership file.
+func Parse(data string) ([]Rule, error) {
+	var rules []Rule
+	sc := bufio.NewScanner(strings.NewReader(data))
+	n := 0
+	for sc.Scan() {
+		n++
+		line := strings.TrimSpace(sc.Text())
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		pattern := fields[0]
+		if _, err := path.Match(strings.Trim(pattern, "/"), ""); err != nil {
+			return nil, fmt.Errorf("line %d: bad pattern %q: %w", n, pattern, err)
+		}
+		rules = append(rules, Rule{Pattern: pattern, Owners: fields[1:], Line: n})
+	}
+	return rules, sc.Err()
+}
+
+// Match reports whether a rule's pattern matches file, with GitHub's
+// semantics: a pattern with no slash (other than a trailing one) matches at
+// any depth, any other slash anchors it to the root, and a pattern naming
+// a directory matches everything under it.
+func Match(pattern, file string) bool {
+	file = strings.TrimPrefix(file, "/")
+	anchored := strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.Trim(pattern, "/")
+	if pattern == "*" && !dirOnly {
+		return true
+	}
+
+	pat := strings.Split(pattern, "/")
+	segs := strings.Split(file, "/")
+	if !anchored {
+		// Unanchored: match against any trailing run of segments.
+		pat = append([]string{"**"}, pat...)
+	}
+	if dirOnly {
+		// A directory pattern can't match the file itself, only what's in it.
+		return matchPrefix(pat, segs[:len(segs)-1])
+	}
+	if matchSegments(pat, segs) {
+		return true
+	}
+	// "docs" owns everything under docs/, but "docs/*" only its direct
+	// children, as on GitHub.
+	return !strings.ContainsAny(pat[len(pat)-1], "*?[") && matchPrefix(pat, segs[:len(segs)-1])
+}
+
+// matchPrefix reports whether pattern matches some leading directory of dirs.
+func matchPrefix(pattern, dirs []string) bool {
+	for i := len(dirs); i > 0; i-- {
+		if matchSegments(pattern, dirs[:i]) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSegments(pattern, file []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(file); i++ {
+				if matchSegments(pattern[1:], file[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(file) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], file[0]); err != nil || !ok {
+			return false
+		}
+		pattern, file = pattern[1:], file[1:]
+	}
+	return len(file) == 0
+}
+
+// OwnersOf returns the owners of file: those of the last rule matching it.
+func OwnersOf(rules []Rule, file string) []string {
+	for i := len(rules) - 1; i >= 0; i-- {
+		if Match(rules[i].Pattern, file) {
+			return rules[i].Owners
+		}
+	}
+	return nil
+}
+
+// Required returns the owners whose approval files need, with each owner
+// translated through aliases, sorted and deduplicated. Owners without an
+// alias are used as written.
+func Required(rules []Rule, files []string, aliases map[string]string) []string {
+	seen := make(map[string]bool)
+	var owners []string
+	for _, f := range files {
+		for _, o := range OwnersOf(rules, f) {
+			if a, ok := aliases[o]; ok {
+				o = a
+			}
+			if o != "" && !seen[o] {
+				seen[o] = true
+				owners = append(owners, o)
+			}
+		}
+	}
+	sort.Strings(owners)
+	return owners
+}
+
+// Satisfies reports whether a review by reviewer counts for owner: the
+// reviewer is the owner, or a member of the owner's role ("gastown/crew"
+// is satisfied by "gastown/crew/alice").
+func Satisfies(reviewer, owner string) bool {
+	if reviewer == "" {
+		return false
+	}
+	return strings.EqualFold(reviewer, owner) || strings.HasPrefix(strings.ToLower(reviewer), strings.ToLower(owner)+"/")
+}
+
+// Pending returns the owners none of approvers satisfies.
+func Pending(owners, approvers []string) []string {
+	var pending []string
+	for _, o := range owners {
+		ok := false
+		for _, a := range approvers {
+			ok = ok || Satisfies(a, o)
+		}
+		if !ok {
+			pending = append(pending, o)
+		}
+	}
+	return pending
+}
+
+// SplitList parses a comma-separated list as stored in an MR field.
+func SplitList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,91 @@
+package codeowners
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		file    string
+		want    bool
+	}{
+		{"*", "any/file.go", true},
+		{"*.js", "web/app.js", true},
+		{"*.js", "app.js", true},
+		{"*.js", "app.ts", false},
+		{"/build/logs/", "build/logs/today.log", true},
+		{"/build/logs/", "src/build/logs/today.log", false},
+		{"apps/", "apps/x.go", true},
+		{"apps/", "sub/apps/x.go", true},
+		{"apps/", "apps", false},
+		{"docs/*", "docs/intro.md", true},
+		{"docs/*", "docs/guides/intro.md", false},
+		{"docs/**", "docs/guides/intro.md", true},
+		{"/internal/refinery", "internal/refinery/engineer.go", true},
+		{"internal/refinery", "internal/refinery/engineer.go", true},
+		{"internal/refinery", "x/internal/refinery/engineer.go", false},
+		{"**/migrations", "db/pg/migrations/001.sql", true},
+		{"go.mod", "go.mod", true},
+		{"go.mod", "tools/go.mod", true},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.file); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestRequired(t *testing.T) {
+	rules, err := Parse(`# Default reviewers
+*                       @acme/core
+
+/internal/refinery/     gastown/crew/mq  @acme/infra  # merge queue
+*.md                    gastown/crew/docs
+/docs/generated/
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 4 || len(rules[3].Owners) != 0 {
+		t.Fatalf("Parse() = %+v", rules)
+	}
+
+	aliases := map[string]string{"@acme/core": "gastown/crew", "@acme/infra": "gastown/crew/ops"}
+	tests := []struct {
+		files []string
+		want  []string
+	}{
+		{[]string{"main.go"}, []string{"gastown/crew"}},
+		{[]string{"internal/refinery/engineer.go", "README.md"}, []string{"gastown/crew/docs", "gastown/crew/mq", "gastown/crew/ops"}},
+		{[]string{"docs/generated/cli.md"}, nil}, // explicitly unowned
+	}
+	for _, tt := range tests {
+		if got := Required(rules, tt.files, aliases); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Required(%v) = %v, want %v", tt.files, got, tt.want)
+		}
+	}
+
+	if _, err := Parse("src/[ owner"); err == nil {
+		t.Error("Parse accepted a bad pattern")
+	}
+}
+
+func TestPending(t *testing.T) {
+	owners := []string{"gastown/crew", "gastown/witness", "@acme/ops"}
+	tests := []struct {
+		approvers []string
+		want      []string
+	}{
+		{nil, owners},
+		{[]string{"gastown/crew/alice"}, []string{"gastown/witness", "@acme/ops"}},
+		{[]string{"gastown/crewmate"}, owners}, // not a member of gastown/crew
+		{[]string{"gastown/crew/alice", "gastown/witness", "@ACME/ops"}, nil},
+	}
+	for _, tt := range tests {
+		if got := Pending(owners, tt.approvers); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Pending(%v) = %v, want %v", tt.approvers, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// BuildCacheConfig configures shared build caches for a rig (settings/config.json).
+// Cache directories are exported to refinery test runs and agent sessions via
+// the environment variables each toolchain already honors, so every MR
+// validation and polecat reuses one warm cache instead of building cold.
+type BuildCacheConfig struct {
+	// Dir is the cache root. Relative paths are resolved against the rig.
+	// Default: <rig>/.runtime/cache
+	Dir string `json:"dir,omitempty"`
+
+	// Caches lists toolchain presets to enable: go, npm, yarn, pnpm, pip,
+	// cargo, gradle, maven.
+	Caches []string `json:"caches,omitempty"`
+
+	// Env sets extra cache variables. "{cache_dir}" in values expands to Dir.
+	// Example: {"CCACHE_DIR": "{cache_dir}/ccache"}
+	Env map[string]string `json:"env,omitempty"`
+
+	// Roles limits which agent roles get the cache environment.
+	// Default: refinery, polecat, crew.
+	Roles []string `json:"roles,omitempty"`
+}
+
+// buildCachePresets maps a toolchain preset to its cache variables.
+// Paths are relative to the cache root.
+var buildCachePresets = map[string]map[string]string{
+	"go":     {"GOCACHE": "go-build", "GOMODCACHE": "go-mod"},
+	"npm":    {"npm_config_cache": "npm"},
+	"yarn":   {"YARN_CACHE_FOLDER": "yarn"},
+	"pnpm":   {"npm_config_store_dir": "pnpm-store"},
+	"pip":    {"PIP_CACHE_DIR": "pip"},
+	"cargo":  {"CARGO_HOME": "cargo"},
+	"gradle": {"GRADLE_USER_HOME": "gradle"},
+	"maven":  {"MAVEN_OPTS": "-Dmaven.repo.local={cache_dir}/maven"},
+}
+
+// defaultBuildCacheRoles are the roles that build or test code.
+var defaultBuildCacheRoles = []string{"refinery", "polecat", "crew"}
+
+// BuildCachePresets returns the names of supported cache presets.
+func BuildCachePresets() []string {
+	names := make([]string, 0, len(buildCachePresets))
+	for name := range buildCachePresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BuildCacheDir returns the resolved cache root for a rig.
+func BuildCacheDir(rigPath string, cfg *BuildCacheConfig) string {
+	if cfg == nil || cfg.Dir == "" {
+		return filepath.Join(rigPath, ".runtime", "cache")
+	}
+	if filepath.IsAbs(cfg.Dir) {
+		return cfg.Dir
+	}
+	return filepath.Join(rigPath, cfg.Dir)
+}
+
+// BuildCacheEnv returns the cache environment variables for a role in a rig.
+// Returns nil if no caches are configured or the role is not covered.
+// Unknown presets are ignored.
+func BuildCacheEnv(rigPath, role string, cfg *BuildCacheConfig) map[string]string {
+	if cfg == nil || (len(cfg.Caches) == 0 && len(cfg.Env) == 0) {
+		return nil
+	}
+
+	roles := cfg.Roles
+	if len(roles) == 0 {
+		roles = defaultBuildCacheRoles
+	}
+	if role != "" && !containsString(roles, role) {
+		return nil
+	}
+
+	dir := BuildCacheDir(rigPath, cfg)
+	env := make(map[string]string)
+	for _, name := range cfg.Caches {
+		for k, v := range buildCachePresets[name] {
+			if strings.Contains(v, "{cache_dir}") {
+				env[k] = strings.ReplaceAll(v, "{cache_dir}", dir)
+			} else {
+				env[k] = filepath.Join(dir, v)
+			}
+		}
+	}
+	for k, v := range cfg.Env {
+		env[k] = strings.ReplaceAll(v, "{cache_dir}", dir)
+	}
+	return env
+}
+
+// LoadBuildCacheEnv loads a rig's settings and returns the cache environment
+// for a role. Missing or unreadable settings yield nil.
+func LoadBuildCacheEnv(rigPath, role string) map[string]string {
+	settings, err := LoadRigSettings(RigSettingsPath(rigPath))
+	if err != nil || settings.BuildCache == nil {
+		return nil
+	}
+	return BuildCacheEnv(rigPath, role, settings.BuildCache)
+}
+
+// EnsureBuildCacheDir creates the cache root for a rig if caching is configured.
+func EnsureBuildCacheDir(rigPath string, cfg *BuildCacheConfig) error {
+	if cfg == nil || (len(cfg.Caches) == 0 && len(cfg.Env) == 0) {
+		return nil
+	}
+	return os.MkdirAll(BuildCacheDir(rigPath, cfg), 0755)
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
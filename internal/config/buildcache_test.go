@@ -0,0 +1,45 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestBuildCacheEnv_Presets(t *testing.T) {
+	t.Parallel()
+	cfg := &BuildCacheConfig{
+		Caches: []string{"go", "npm", "unknown"},
+		Env:    map[string]string{"CCACHE_DIR": "{cache_dir}/ccache"},
+	}
+
+	env := BuildCacheEnv("/town/myrig", "refinery", cfg)
+
+	assertEnv(t, env, "GOCACHE", "/town/myrig/.runtime/cache/go-build")
+	assertEnv(t, env, "GOMODCACHE", "/town/myrig/.runtime/cache/go-mod")
+	assertEnv(t, env, "npm_config_cache", "/town/myrig/.runtime/cache/npm")
+	assertEnv(t, env, "CCACHE_DIR", "/town/myrig/.runtime/cache/ccache")
+	if len(env) != 4 {
+		t.Errorf("got %d vars, want 4: %v", len(env), env)
+	}
+}
+
+func TestBuildCacheEnv_DirAndRoles(t *testing.T) {
+	t.Parallel()
+	cfg := &BuildCacheConfig{
+		Dir:    "/shared/cache",
+		Caches: []string{"maven"},
+		Roles:  []string{"refinery"},
+	}
+
+	env := BuildCacheEnv("/town/myrig", "refinery", cfg)
+	assertEnv(t, env, "MAVEN_OPTS", "-Dmaven.repo.local=/shared/cache/maven")
+
+	if env := BuildCacheEnv("/town/myrig", "polecat", cfg); env != nil {
+		t.Errorf("expected no cache env for polecat, got %v", env)
+	}
+	if env := BuildCacheEnv("/town/myrig", "witness", &BuildCacheConfig{Caches: []string{"go"}}); env != nil {
+		t.Errorf("expected no cache env for witness by default, got %v", env)
+	}
+	if env := BuildCacheEnv("/town/myrig", "refinery", nil); env != nil {
+		t.Errorf("expected nil env for nil config, got %v", env)
+	}
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/steveyegge/gastown/internal/state"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// AllCommands is the CommandDefaults key whose flags apply to every
+// command that has them.
+const AllCommands = "*"
+
+// NoDefaultsEnv disables command defaults when set to 1, for scripts that
+// need the built-in behavior regardless of who runs them.
+const NoDefaultsEnv = "GT_NO_DEFAULTS"
+
+// CommandDefaults maps a command path ("blocked", "rig list", "*") to
+// flag names and their default values.
+type CommandDefaults map[string]map[string]string
+
+// UserDefaultsPath returns the per-user command defaults file,
+// ~/.config/gastown/defaults.json.
+func UserDefaultsPath() string {
+	return filepath.Join(state.ConfigDir(), "defaults.json")
+}
+
+// LoadCommandDefaults reads a command defaults file. A missing file is
+// empty defaults.
+func LoadCommandDefaults(path string) (CommandDefaults, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		if os.IsNotExist(err) {
+			return CommandDefaults{}, nil
+		}
+		return nil, err
+	}
+	var d CommandDefaults
+	if err := json.Unmarshal(data, &d); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if d == nil {
+		d = CommandDefaults{}
+	}
+	return d, nil
+}
+
+// SaveCommandDefaults writes a command defaults file.
+func SaveCommandDefaults(path string, d CommandDefaults) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating directory: %w", err)
+	}
+	return util.AtomicWriteJSON(path, d)
+}
+
+// Set sets a flag default for a command.
+func (d CommandDefaults) Set(command, flag, value string) {
+	if d[command] == nil {
+		d[command] = make(map[string]string)
+	}
+	d[command][flag] = value
+}
+
+// Unset removes a flag default, or every default for the command if flag
+// is empty. Returns false if there was nothing to remove.
+func (d CommandDefaults) Unset(command, flag string) bool {
+	flags, ok := d[command]
+	if !ok {
+		return false
+	}
+	if flag == "" {
+		delete(d, command)
+		return true
+	}
+	if _, ok := flags[flag]; !ok {
+		return false
+	}
+	delete(flags, flag)
+	if len(flags) == 0 {
+		delete(d, command)
+	}
+	return true
+}
+
+// For returns the flag defaults for a command: the "*" defaults
+// overridden by the command's own.
+func (d CommandDefaults) For(command string) map[string]string {
+	out := make(map[string]string)
+	for flag, value := range d[AllCommands] {
+		out[flag] = value
+	}
+	for flag, value := range d[command] {
+		out[flag] = value
+	}
+	return out
+}
+
+// Commands returns the commands with defaults, sorted.
+func (d CommandDefaults) Commands() []string {
+	names := make([]string, 0, len(d))
+	for name := range d {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// MergeCommandDefaults layers each set of defaults over the previous
+// ones, flag by flag.
+func MergeCommandDefaults(layers ...CommandDefaults) CommandDefaults {
+	out := CommandDefaults{}
+	for _, layer := range layers {
+		for command, flags := range layer {
+			for flag, value := range flags {
+				out.Set(command, flag, value)
+			}
+		}
+	}
+	return out
+}
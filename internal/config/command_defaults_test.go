@@ -0,0 +1,61 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCommandDefaultsFor(t *testing.T) {
+	town := CommandDefaults{
+		"*":     {"json": "true", "group": "all"},
+		"ready": {"group": "infra"},
+	}
+	user := CommandDefaults{
+		"ready":   {"group": "mine"},
+		"blocked": {"rig": "api"},
+	}
+	merged := MergeCommandDefaults(town, user)
+
+	tests := []struct {
+		command string
+		want    map[string]string
+	}{
+		{"ready", map[string]string{"json": "true", "group": "mine"}},
+		{"blocked", map[string]string{"json": "true", "group": "all", "rig": "api"}},
+		{"rig list", map[string]string{"json": "true", "group": "all"}},
+	}
+	for _, tt := range tests {
+		if got := merged.For(tt.command); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("For(%q) = %v, want %v", tt.command, got, tt.want)
+		}
+	}
+	if town["ready"]["group"] != "infra" {
+		t.Error("MergeCommandDefaults modified its input")
+	}
+}
+
+func TestCommandDefaultsSetUnsetRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "defaults.json")
+	d, err := LoadCommandDefaults(path)
+	if err != nil || len(d) != 0 {
+		t.Fatalf("LoadCommandDefaults(missing) = %v, %v", d, err)
+	}
+
+	d.Set("ready", "group", "mine")
+	d.Set("ready", "rig", "api")
+	if err := SaveCommandDefaults(path, d); err != nil {
+		t.Fatal(err)
+	}
+	d, err = LoadCommandDefaults(path)
+	if err != nil || d["ready"]["group"] != "mine" {
+		t.Fatalf("round trip = %v, %v", d, err)
+	}
+
+	if !d.Unset("ready", "rig") || d.Unset("ready", "rig") {
+		t.Error("Unset(flag) should remove once")
+	}
+	if !d.Unset("ready", "") || len(d) != 0 {
+		t.Errorf("Unset(command) left %v", d)
+	}
+}
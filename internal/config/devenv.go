@@ -0,0 +1,212 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Dev environment modes (settings/config.json "dev_env.mode").
+const (
+	DevEnvAuto         = "auto"         // use whatever the repo declares, if its tool is installed
+	DevEnvNone         = "none"         // always run on the host
+	DevEnvNix          = "nix"          // require flake.nix or shell.nix
+	DevEnvDevcontainer = "devcontainer" // require a devcontainer.json
+)
+
+// DevEnvConfig configures running a rig's work inside the reproducible
+// environment its repo declares: a Nix flake or shell, or a devcontainer.
+type DevEnvConfig struct {
+	// Mode is auto (default), nix, devcontainer, or none. In auto mode a
+	// declared environment is used only when its tool is installed; an
+	// explicit mode fails loudly instead.
+	Mode string `json:"mode,omitempty"`
+
+	// Roles limits which roles run inside the environment.
+	// Default: refinery, polecat, crew.
+	Roles []string `json:"roles,omitempty"`
+}
+
+// DevEnv is a reproducible environment declared by a repo.
+type DevEnv struct {
+	Kind string `json:"kind"` // nix or devcontainer
+	File string `json:"file"` // declaring file, relative to the repo
+}
+
+// devEnvFiles are the files that declare an environment, in order of preference.
+var devEnvFiles = []DevEnv{
+	{Kind: DevEnvNix, File: "flake.nix"},
+	{Kind: DevEnvNix, File: "shell.nix"},
+	{Kind: DevEnvDevcontainer, File: ".devcontainer/devcontainer.json"},
+	{Kind: DevEnvDevcontainer, File: ".devcontainer.json"},
+}
+
+// defaultDevEnvRoles are the roles that build or test code.
+var defaultDevEnvRoles = []string{"refinery", "polecat", "crew"}
+
+// DetectDevEnvs returns the environments repoDir declares, preferred first.
+func DetectDevEnvs(repoDir string) []DevEnv {
+	var found []DevEnv
+	for _, d := range devEnvFiles {
+		if _, err := os.Stat(filepath.Join(repoDir, d.File)); err == nil {
+			found = append(found, d)
+		}
+	}
+	return found
+}
+
+// ResolveDevEnv picks the environment role runs in for repoDir. It returns
+// nil when work runs on the host: the mode is none, the role isn't covered,
+// nothing is declared, or (in auto mode) the environment's tool is missing.
+// An explicit mode whose file or tool is missing is an error.
+func ResolveDevEnv(repoDir, role string, cfg *DevEnvConfig) (*DevEnv, error) {
+	mode := DevEnvAuto
+	var roles []string
+	if cfg != nil {
+		if cfg.Mode != "" {
+			mode = cfg.Mode
+		}
+		roles = cfg.Roles
+	}
+	if len(roles) == 0 {
+		roles = defaultDevEnvRoles
+	}
+	if mode == DevEnvNone || (role != "" && !containsString(roles, role)) {
+		return nil, nil
+	}
+	if mode != DevEnvAuto && mode != DevEnvNix && mode != DevEnvDevcontainer {
+		return nil, fmt.Errorf("dev_env.mode %q: want auto, nix, devcontainer, or none", mode)
+	}
+
+	for _, d := range DetectDevEnvs(repoDir) {
+		if mode != DevEnvAuto && d.Kind != mode {
+			continue
+		}
+		d := d
+		if _, err := exec.LookPath(d.Tool()); err != nil {
+			if mode == DevEnvAuto {
+				continue
+			}
+			return nil, fmt.Errorf("%s declares a %s environment but %s is not installed", d.File, d.Kind, d.Tool())
+		}
+		return &d, nil
+	}
+	if mode != DevEnvAuto {
+		return nil, fmt.Errorf("dev_env.mode is %s but %s declares no %s environment", mode, repoDir, mode)
+	}
+	return nil, nil
+}
+
+// LoadDevEnv loads a rig's settings and resolves the environment role runs in
+// for repoDir.
+func LoadDevEnv(rigPath, repoDir, role string) (*DevEnv, error) {
+	var cfg *DevEnvConfig
+	if settings, err := LoadRigSettings(RigSettingsPath(rigPath)); err == nil {
+		cfg = settings.DevEnv
+	}
+	return ResolveDevEnv(repoDir, role, cfg)
+}
+
+// Tool returns the command that enters the environment.
+func (d *DevEnv) Tool() string {
+	switch {
+	case d.Kind == DevEnvDevcontainer:
+		return "devcontainer"
+	case d.File == "shell.nix":
+		return "nix-shell"
+	}
+	return "nix"
+}
+
+// WrapCommand returns a shell command that runs command inside the
+// environment for the repo at dir. A devcontainer is started (or reused)
+// first.
+func (d *DevEnv) WrapCommand(dir, command string) string {
+	switch d.Tool() {
+	case "devcontainer":
+		ws := ShellQuote(dir)
+		return fmt.Sprintf("devcontainer up --workspace-folder %s >/dev/null && devcontainer exec --workspace-folder %s sh -c %s",
+			ws, ws, ShellQuote(command))
+	case "nix-shell":
+		return fmt.Sprintf("nix-shell %s --run %s", ShellQuote(filepath.Join(dir, d.File)), ShellQuote(command))
+	}
+	return fmt.Sprintf("nix develop %s --command sh -c %s", ShellQuote(dir), ShellQuote(command))
+}
+
+// hostOnlyVars are variables a captured environment must not carry over
+// into an agent session.
+var hostOnlyVars = map[string]bool{
+	"PWD": true, "OLDPWD": true, "SHLVL": true, "_": true, "HOME": true,
+	"USER": true, "LOGNAME": true, "TERM": true, "TMUX": true, "TMUX_PANE": true,
+	"SHELL": true, "TMPDIR": true, "TEMP": true, "TMP": true, "TEMPDIR": true,
+}
+
+// CaptureEnv builds a Nix environment for the repo at dir and returns the
+// variables it sets or changes. Agent sessions start with these variables
+// rather than under the nix wrapper, so the agent stays the session's
+// foreground process. Devcontainer environments can't be captured.
+func (d *DevEnv) CaptureEnv(ctx context.Context, dir string) (map[string]string, error) {
+	if d.Kind != DevEnvNix {
+		return nil, fmt.Errorf("a %s environment can't be captured", d.Kind)
+	}
+	cmd := exec.CommandContext(ctx, "sh", "-c", d.WrapCommand(dir, "env -0")) //nolint:gosec // G204: dir is a rig clone
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("building %s environment: %v: %s", d.File, err, strings.TrimSpace(stderr.String()))
+	}
+
+	env := make(map[string]string)
+	for _, kv := range strings.Split(string(out), "\x00") {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok || k == "" || hostOnlyVars[k] || os.Getenv(k) == v {
+			continue
+		}
+		env[k] = v
+	}
+	return env, nil
+}
+
+// RigRepoDir returns a rig's canonical clone: refinery/rig, falling back to
+// mayor/rig.
+func RigRepoDir(rigPath string) string {
+	dir := filepath.Join(rigPath, "refinery", "rig")
+	if _, err := os.Stat(dir); err != nil {
+		dir = filepath.Join(rigPath, "mayor", "rig")
+	}
+	return dir
+}
+
+// devEnvCaptureTimeout bounds building a Nix environment at session start.
+const devEnvCaptureTimeout = 10 * time.Minute
+
+// LoadDevEnvAgentEnv returns the variables an agent session in a rig starts
+// with to run inside the rig's Nix environment. Returns nil when the role
+// runs on the host or the rig uses a devcontainer (agents run on the host;
+// test runs go inside). Failures are warnings: the agent starts on the host.
+func LoadDevEnvAgentEnv(rigPath, role string) map[string]string {
+	repoDir := RigRepoDir(rigPath)
+	d, err := LoadDevEnv(rigPath, repoDir, role)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: dev environment: %v\n", err)
+		return nil
+	}
+	if d == nil || d.Kind != DevEnvNix {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), devEnvCaptureTimeout)
+	defer cancel()
+	env, err := d.CaptureEnv(ctx, repoDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: dev environment: %v (starting on the host)\n", err)
+		return nil
+	}
+	return env
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fakeTool puts an executable named name on PATH that runs script.
+func fakeTool(t *testing.T, name, script string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts")
+	}
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, name), []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func writeRepoFile(t *testing.T, repo, name string) {
+	t.Helper()
+	path := filepath.Join(repo, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDetectDevEnvs(t *testing.T) {
+	t.Parallel()
+	repo := t.TempDir()
+	if got := DetectDevEnvs(repo); len(got) != 0 {
+		t.Fatalf("empty repo declares nothing, got %v", got)
+	}
+	writeRepoFile(t, repo, ".devcontainer/devcontainer.json")
+	writeRepoFile(t, repo, "flake.nix")
+
+	got := DetectDevEnvs(repo)
+	if len(got) != 2 || got[0].File != "flake.nix" || got[1].Kind != DevEnvDevcontainer {
+		t.Errorf("DetectDevEnvs = %v, want flake.nix then devcontainer", got)
+	}
+}
+
+func TestResolveDevEnv(t *testing.T) {
+	fakeTool(t, "devcontainer", "exit 0")
+	t.Setenv("PATH", filepath.SplitList(os.Getenv("PATH"))[0]) // only the fake tool
+
+	repo := t.TempDir()
+	writeRepoFile(t, repo, "flake.nix")
+	writeRepoFile(t, repo, ".devcontainer.json")
+
+	// auto skips nix (not installed) and uses the devcontainer
+	d, err := ResolveDevEnv(repo, "refinery", nil)
+	if err != nil || d == nil || d.Kind != DevEnvDevcontainer {
+		t.Fatalf("auto = %v, %v; want devcontainer", d, err)
+	}
+	if d, _ := ResolveDevEnv(repo, "mayor", nil); d != nil {
+		t.Errorf("mayor is not covered by default, got %v", d)
+	}
+	if d, _ := ResolveDevEnv(repo, "refinery", &DevEnvConfig{Mode: DevEnvNone}); d != nil {
+		t.Errorf("mode none runs on the host, got %v", d)
+	}
+	if _, err := ResolveDevEnv(repo, "refinery", &DevEnvConfig{Mode: DevEnvNix}); err == nil || !strings.Contains(err.Error(), "not installed") {
+		t.Errorf("explicit nix without nix should fail, got %v", err)
+	}
+	if _, err := ResolveDevEnv(t.TempDir(), "refinery", &DevEnvConfig{Mode: DevEnvDevcontainer}); err == nil {
+		t.Error("explicit devcontainer without a devcontainer.json should fail")
+	}
+	if _, err := ResolveDevEnv(repo, "refinery", &DevEnvConfig{Mode: "docker"}); err == nil {
+		t.Error("unknown mode should fail")
+	}
+	if d, err := ResolveDevEnv(t.TempDir(), "refinery", nil); d != nil || err != nil {
+		t.Errorf("auto with nothing declared = %v, %v; want host", d, err)
+	}
+}
+
+func TestDevEnvWrapCommand(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		env  DevEnv
+		want string
+	}{
+		{DevEnv{Kind: DevEnvNix, File: "flake.nix"}, `nix develop /r --command sh -c 'go test ./...'`},
+		{DevEnv{Kind: DevEnvNix, File: "shell.nix"}, `nix-shell /r/shell.nix --run 'go test ./...'`},
+		{DevEnv{Kind: DevEnvDevcontainer, File: ".devcontainer.json"},
+			`devcontainer up --workspace-folder /r >/dev/null && devcontainer exec --workspace-folder /r sh -c 'go test ./...'`},
+	}
+	for _, tt := range tests {
+		if got := tt.env.WrapCommand("/r", "go test ./..."); got != tt.want {
+			t.Errorf("%s: WrapCommand =\n  %s\nwant\n  %s", tt.env.File, got, tt.want)
+		}
+	}
+}
+
+func TestDevEnvCaptureEnv(t *testing.T) {
+	// A fake nix that "enters" an environment setting GOROOT and runs the command
+	fakeTool(t, "nix", `shift 3; GT_TEST_NIX_GOROOT=/nix/store/go exec "$@"`)
+
+	d := &DevEnv{Kind: DevEnvNix, File: "flake.nix"}
+	env, err := d.CaptureEnv(context.Background(), t.TempDir())
+	if err != nil {
+		t.Fatalf("CaptureEnv: %v", err)
+	}
+	if env["GT_TEST_NIX_GOROOT"] != "/nix/store/go" {
+		t.Errorf("captured env should include the environment's variables, got %v", env)
+	}
+	if _, ok := env["HOME"]; ok {
+		t.Error("host-only variables should be dropped")
+	}
+	if _, ok := env["PATH"]; ok {
+		t.Error("unchanged variables should be dropped")
+	}
+
+	if _, err := (&DevEnv{Kind: DevEnvDevcontainer}).CaptureEnv(context.Background(), t.TempDir()); err == nil {
+		t.Error("devcontainer environments can't be captured")
+	}
+}
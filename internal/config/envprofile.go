@@ -0,0 +1,162 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// EnvProfileConfig is a rig's managed environment (settings/config.json "env").
+// Gas Town materializes it into every agent session it starts for the rig
+// and into refinery test runs, so agents don't drift on tool versions, PATH,
+// or variables set by hand in one session.
+type EnvProfileConfig struct {
+	// Tools pins tool versions, e.g. {"go": "1.22.3", "node": "20"}. Pins are
+	// exported as the variables mise and asdf read (MISE_GO_VERSION,
+	// ASDF_GO_VERSION) and listed in GT_TOOLS.
+	Tools map[string]string `json:"tools,omitempty"`
+
+	// Path lists directories prepended to PATH, in order. Relative entries
+	// are resolved against the rig.
+	Path []string `json:"path,omitempty"`
+
+	// Vars sets environment variables. "{rig}" in values expands to the rig path.
+	Vars map[string]string `json:"vars,omitempty"`
+
+	// Secrets maps variable names to secret references, resolved when the
+	// environment is materialized and never stored in settings:
+	//   "env:NAME"  - the variable NAME from gt's own environment
+	//   "file:PATH" - the contents of PATH (relative to the rig), trimmed
+	Secrets map[string]string `json:"secrets,omitempty"`
+
+	// Roles limits which agent roles get the profile.
+	// Default: refinery, polecat, crew, witness.
+	Roles []string `json:"roles,omitempty"`
+}
+
+// defaultEnvProfileRoles are the roles that work inside a rig.
+var defaultEnvProfileRoles = []string{"refinery", "polecat", "crew", "witness"}
+
+// EnvProfileAppliesTo reports whether a profile covers role. An empty role
+// matches any profile.
+func EnvProfileAppliesTo(cfg *EnvProfileConfig, role string) bool {
+	if cfg == nil {
+		return false
+	}
+	roles := cfg.Roles
+	if len(roles) == 0 {
+		roles = defaultEnvProfileRoles
+	}
+	return role == "" || containsString(roles, role)
+}
+
+// EnvProfileEnv materializes a rig's environment profile for role. basePath
+// is the PATH the profile's entries are prepended to. Secrets that can't be
+// resolved are left out and reported in the returned error; the rest of the
+// environment is still returned. Returns nil if the role isn't covered.
+func EnvProfileEnv(rigPath, role string, cfg *EnvProfileConfig, basePath string) (map[string]string, error) {
+	if !EnvProfileAppliesTo(cfg, role) {
+		return nil, nil
+	}
+	env := make(map[string]string)
+
+	if len(cfg.Tools) > 0 {
+		names := make([]string, 0, len(cfg.Tools))
+		for name, version := range cfg.Tools {
+			key := toolEnvKey(name)
+			env["MISE_"+key+"_VERSION"] = version
+			env["ASDF_"+key+"_VERSION"] = version
+			names = append(names, name+"@"+version)
+		}
+		sort.Strings(names)
+		env["GT_TOOLS"] = strings.Join(names, " ")
+	}
+
+	for k, v := range cfg.Vars {
+		env[k] = strings.ReplaceAll(v, "{rig}", rigPath)
+	}
+
+	if len(cfg.Path) > 0 {
+		dirs := make([]string, 0, len(cfg.Path)+1)
+		for _, p := range cfg.Path {
+			p = strings.ReplaceAll(p, "{rig}", rigPath)
+			if !filepath.IsAbs(p) {
+				p = filepath.Join(rigPath, p)
+			}
+			dirs = append(dirs, p)
+		}
+		if basePath != "" {
+			dirs = append(dirs, basePath)
+		}
+		env["PATH"] = strings.Join(dirs, string(os.PathListSeparator))
+	}
+
+	var missing []string
+	for k, ref := range cfg.Secrets {
+		v, err := ResolveSecret(rigPath, ref)
+		if err != nil {
+			missing = append(missing, fmt.Sprintf("%s: %v", k, err))
+			continue
+		}
+		env[k] = v
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return env, fmt.Errorf("unresolved secrets: %s", strings.Join(missing, "; "))
+	}
+	return env, nil
+}
+
+// ResolveSecret resolves a secret reference ("env:NAME" or "file:PATH").
+func ResolveSecret(rigPath, ref string) (string, error) {
+	kind, target, ok := strings.Cut(ref, ":")
+	if !ok || target == "" {
+		return "", fmt.Errorf("invalid secret reference %q (want env:NAME or file:PATH)", ref)
+	}
+	switch kind {
+	case "env":
+		v, ok := os.LookupEnv(target)
+		if !ok {
+			return "", fmt.Errorf("$%s is not set", target)
+		}
+		return v, nil
+	case "file":
+		if strings.HasPrefix(target, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				target = filepath.Join(home, target[2:])
+			}
+		} else if !filepath.IsAbs(target) {
+			target = filepath.Join(rigPath, target)
+		}
+		data, err := os.ReadFile(target) //nolint:gosec // G304: path is from operator-controlled rig settings
+		if err != nil {
+			return "", fmt.Errorf("reading %s: %w", target, err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	default:
+		return "", fmt.Errorf("unknown secret source %q (want env or file)", kind)
+	}
+}
+
+// LoadEnvProfileEnv loads a rig's settings and materializes its environment
+// profile for role on top of basePath (gt's own PATH if empty). Missing
+// settings yield nil; unresolved secrets are left out.
+func LoadEnvProfileEnv(rigPath, role, basePath string) map[string]string {
+	settings, err := LoadRigSettings(RigSettingsPath(rigPath))
+	if err != nil || settings.Env == nil {
+		return nil
+	}
+	if basePath == "" {
+		basePath = os.Getenv("PATH")
+	}
+	env, _ := EnvProfileEnv(rigPath, role, settings.Env, basePath)
+	return env
+}
+
+// toolEnvKey turns a tool name into the form version managers expect in
+// variable names: "golangci-lint" becomes "GOLANGCI_LINT".
+func toolEnvKey(name string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "_", ".", "_", "/", "_").Replace(name))
+}
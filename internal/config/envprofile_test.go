@@ -0,0 +1,86 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEnvProfileEnv(t *testing.T) {
+	rigPath := t.TempDir()
+	if err := os.WriteFile(filepath.Join(rigPath, "token"), []byte("s3cret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GT_TEST_NPM_TOKEN", "npm-abc")
+
+	cfg := &EnvProfileConfig{
+		Tools: map[string]string{"go": "1.22.3", "golangci-lint": "1.59"},
+		Path:  []string{"bin", "/opt/tools/bin"},
+		Vars:  map[string]string{"DATA_DIR": "{rig}/data", "GOFLAGS": "-mod=mod"},
+		Secrets: map[string]string{
+			"NPM_TOKEN": "env:GT_TEST_NPM_TOKEN",
+			"API_KEY":   "file:token",
+		},
+	}
+
+	env, err := EnvProfileEnv(rigPath, "polecat", cfg, "/usr/bin")
+	if err != nil {
+		t.Fatalf("EnvProfileEnv: %v", err)
+	}
+	assertEnv(t, env, "MISE_GO_VERSION", "1.22.3")
+	assertEnv(t, env, "ASDF_GOLANGCI_LINT_VERSION", "1.59")
+	assertEnv(t, env, "GT_TOOLS", "go@1.22.3 golangci-lint@1.59")
+	assertEnv(t, env, "PATH", strings.Join([]string{filepath.Join(rigPath, "bin"), "/opt/tools/bin", "/usr/bin"}, string(os.PathListSeparator)))
+	assertEnv(t, env, "DATA_DIR", rigPath+"/data")
+	assertEnv(t, env, "GOFLAGS", "-mod=mod")
+	assertEnv(t, env, "NPM_TOKEN", "npm-abc")
+	assertEnv(t, env, "API_KEY", "s3cret")
+}
+
+func TestEnvProfileEnv_Roles(t *testing.T) {
+	t.Parallel()
+	cfg := &EnvProfileConfig{Vars: map[string]string{"A": "1"}}
+	if env, _ := EnvProfileEnv("/town/myrig", "mayor", cfg, ""); env != nil {
+		t.Errorf("mayor is not covered by default, got %v", env)
+	}
+	if env, _ := EnvProfileEnv("/town/myrig", "witness", cfg, ""); env["A"] != "1" {
+		t.Errorf("witness is covered by default, got %v", env)
+	}
+
+	cfg.Roles = []string{"refinery"}
+	if env, _ := EnvProfileEnv("/town/myrig", "polecat", cfg, ""); env != nil {
+		t.Errorf("polecat excluded by roles, got %v", env)
+	}
+	if env, _ := EnvProfileEnv("/town/myrig", "", cfg, ""); env["A"] != "1" {
+		t.Errorf("empty role matches any profile, got %v", env)
+	}
+	if env, _ := EnvProfileEnv("/town/myrig", "refinery", nil, ""); env != nil {
+		t.Errorf("nil profile should yield nil, got %v", env)
+	}
+}
+
+func TestEnvProfileEnv_UnresolvedSecrets(t *testing.T) {
+	cfg := &EnvProfileConfig{
+		Vars: map[string]string{"A": "1"},
+		Secrets: map[string]string{
+			"MISSING": "env:GT_TEST_DEFINITELY_UNSET",
+			"BAD":     "vault:kv/x",
+		},
+	}
+	env, err := EnvProfileEnv(t.TempDir(), "refinery", cfg, "")
+	if err == nil {
+		t.Fatal("expected an error for unresolved secrets")
+	}
+	for _, want := range []string{"MISSING", "BAD", "unknown secret source"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q should mention %q", err, want)
+		}
+	}
+	if env["A"] != "1" {
+		t.Errorf("resolved variables should still be returned, got %v", env)
+	}
+	if _, ok := env["MISSING"]; ok {
+		t.Error("unresolved secrets should be left out")
+	}
+}
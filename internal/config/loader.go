@@ -6,12 +6,16 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/mq"
 )
 
 var (
@@ -218,6 +222,27 @@ func validateRigSettings(c *RigSettings) error {
 // ErrInvalidOnConflict indicates an invalid on_conflict strategy.
 var ErrInvalidOnConflict = errors.New("invalid on_conflict strategy")
 
+// ValidateTargets checks merge_queue.targets: each key is a branch name or
+// glob pattern (e.g. "release-*") with a valid policy.
+func ValidateTargets(targets map[string]TargetPolicy) error {
+	for pattern, t := range targets {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid targets pattern '%s': %w", pattern, err)
+		}
+		switch t.PRMergeMethod {
+		case "", "squash", "merge", "rebase":
+		default:
+			return fmt.Errorf("invalid targets.%s.pr_merge_method '%s': want 'squash', 'merge', or 'rebase'", pattern, t.PRMergeMethod)
+		}
+		for _, spec := range t.FreezeWindows {
+			if _, err := mq.ParseWindow(spec); err != nil {
+				return fmt.Errorf("targets.%s: %w", pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
 // validateMergeQueueConfig validates a MergeQueueConfig.
 func validateMergeQueueConfig(c *MergeQueueConfig) error {
 	// Validate on_conflict strategy
@@ -233,13 +258,93 @@ func validateMergeQueueConfig(c *MergeQueueConfig) error {
 		}
 	}
 
+	switch c.TestOutputFormat {
+	case "", "go", "junit":
+	case "regex":
+		if c.TestFailurePattern == "" {
+			return fmt.Errorf("%w: test_failure_pattern is required when test_output_format is 'regex'", ErrMissingField)
+		}
+	default:
+		return fmt.Errorf("invalid test_output_format '%s': want 'go', 'junit', or 'regex'", c.TestOutputFormat)
+	}
+
+	switch c.QuarantinePolicy {
+	case "", "retry", "skip", "off":
+	default:
+		return fmt.Errorf("invalid quarantine_policy '%s': want 'retry', 'skip', or 'off'", c.QuarantinePolicy)
+	}
+
+	if c.CoveragePattern != "" {
+		if _, err := regexp.Compile(c.CoveragePattern); err != nil {
+			return fmt.Errorf("invalid coverage_pattern: %w", err)
+		}
+	}
+
+	if c.PostMergeWindow != "" {
+		if _, err := time.ParseDuration(c.PostMergeWindow); err != nil {
+			return fmt.Errorf("invalid post_merge_window: %w", err)
+		}
+	}
+	if c.CanaryTimeout != "" {
+		if _, err := time.ParseDuration(c.CanaryTimeout); err != nil {
+			return fmt.Errorf("invalid canary_timeout: %w", err)
+		}
+	}
+	if c.CanaryBranch != "" && c.CanaryBranch == c.TargetBranch {
+		return fmt.Errorf("canary_branch must differ from target_branch (%s)", c.TargetBranch)
+	}
+
+	for state, limit := range c.SLO {
+		switch state {
+		case "awaiting_tests", "awaiting_review", "awaiting_merge":
+		default:
+			return fmt.Errorf("invalid slo state '%s': want 'awaiting_tests', 'awaiting_review', or 'awaiting_merge'", state)
+		}
+		if d, err := time.ParseDuration(limit); err != nil || d <= 0 {
+			return fmt.Errorf("invalid slo.%s '%s': want a positive duration like 4h", state, limit)
+		}
+	}
+
+	for _, spec := range c.FreezeWindows {
+		if _, err := mq.ParseWindow(spec); err != nil {
+			return err
+		}
+	}
+
+	switch c.PRMergeMethod {
+	case "", "squash", "merge", "rebase":
+	default:
+		return fmt.Errorf("invalid pr_merge_method '%s': want 'squash', 'merge', or 'rebase'", c.PRMergeMethod)
+	}
+	for name, text := range map[string]string{
+		"commit_subject_template": c.CommitSubject,
+		"commit_body_template":    c.CommitBody,
+	} {
+		if _, err := template.New(name).Parse(text); err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+
+	if err := ValidateTargets(c.Targets); err != nil {
+		return err
+	}
+
 	// Validate non-negative values
+	if c.QuarantineRetries < 0 {
+		return fmt.Errorf("%w: quarantine_retries must be non-negative", ErrMissingField)
+	}
+	if c.FlakeBeadThreshold < 0 {
+		return fmt.Errorf("%w: flake_bead_threshold must be non-negative", ErrMissingField)
+	}
 	if c.RetryFlakyTests < 0 {
 		return fmt.Errorf("%w: retry_flaky_tests must be non-negative", ErrMissingField)
 	}
 	if c.MaxConcurrent < 0 {
 		return fmt.Errorf("%w: max_concurrent must be non-negative", ErrMissingField)
 	}
+	if c.WorktreePool != nil && *c.WorktreePool < 0 {
+		return fmt.Errorf("%w: worktree_pool must be non-negative", ErrMissingField)
+	}
 
 	return nil
 }
@@ -1059,6 +1164,14 @@ func ResolveRoleAgentConfig(role, townRoot, rigPath string) *RuntimeConfig {
 		_ = LoadRigAgentRegistry(RigAgentRegistryPath(rigPath))
 	}
 
+	// Route around rate-limited or over-budget agents to the role's fallbacks
+	if route := routeRoleAgent(role, townRoot, rigPath, townSettings, rigSettings, time.Now()); route != nil && route.Fallback {
+		if rc := lookupCustomAgentConfig(route.Agent, townSettings, rigSettings); rc != nil {
+			return rc
+		}
+		return lookupAgentConfig(route.Agent, townSettings, rigSettings)
+	}
+
 	// Check rig's RoleAgents first
 	if rigSettings != nil && rigSettings.RoleAgents != nil {
 		if agentName, ok := rigSettings.RoleAgents[role]; ok && agentName != "" {
@@ -1484,6 +1597,25 @@ func BuildStartupCommand(envVars map[string]string, rigPath, prompt string) stri
 	if rc.Session != nil && rc.Session.SessionIDEnv != "" {
 		resolvedEnv["GT_SESSION_ID_ENV"] = rc.Session.SessionIDEnv
 	}
+	// Count the start against the routed agent's budget
+	if role != "" && townRoot != "" {
+		if agent := RecordRoleStart(role, townRoot, rigPath, time.Now()); agent != "" {
+			resolvedEnv["GT_AGENT_ROUTE"] = agent
+		}
+	}
+	// Enter the rig's reproducible environment, point toolchains at its shared
+	// build caches, and apply its managed environment
+	if rigPath != "" {
+		for k, v := range LoadDevEnvAgentEnv(rigPath, role) {
+			resolvedEnv[k] = v
+		}
+		for k, v := range LoadBuildCacheEnv(rigPath, role) {
+			resolvedEnv[k] = v
+		}
+		for k, v := range LoadEnvProfileEnv(rigPath, role, resolvedEnv["PATH"]) {
+			resolvedEnv[k] = v
+		}
+	}
 	// Merge agent-specific env vars (e.g., OPENCODE_PERMISSION for yolo mode)
 	for k, v := range rc.Env {
 		resolvedEnv[k] = v
@@ -1608,6 +1740,24 @@ func BuildStartupCommandWithAgentOverride(envVars map[string]string, rigPath, pr
 	// Record agent override so handoff can preserve it
 	if agentOverride != "" {
 		resolvedEnv["GT_AGENT"] = agentOverride
+	} else if role != "" && townRoot != "" {
+		// Count the start against the routed agent's budget
+		if agent := RecordRoleStart(role, townRoot, rigPath, time.Now()); agent != "" {
+			resolvedEnv["GT_AGENT_ROUTE"] = agent
+		}
+	}
+	// Enter the rig's reproducible environment, point toolchains at its shared
+	// build caches, and apply its managed environment
+	if rigPath != "" {
+		for k, v := range LoadDevEnvAgentEnv(rigPath, role) {
+			resolvedEnv[k] = v
+		}
+		for k, v := range LoadBuildCacheEnv(rigPath, role) {
+			resolvedEnv[k] = v
+		}
+		for k, v := range LoadEnvProfileEnv(rigPath, role, resolvedEnv["PATH"]) {
+			resolvedEnv[k] = v
+		}
 	}
 	// Merge agent-specific env vars (e.g., OPENCODE_PERMISSION for yolo mode)
 	for k, v := range rc.Env {
@@ -1752,6 +1902,28 @@ func GetDefaultFormula(rigPath string) string {
 	return settings.Workflow.DefaultFormula
 }
 
+// GetFormulaVars returns a rig's var overrides for a formula: the rig-wide
+// workflow.vars with workflow.formula_vars[name] on top.
+// Returns nil if the rig has no overrides.
+func GetFormulaVars(rigPath, name string) map[string]string {
+	settings, err := LoadRigSettings(RigSettingsPath(rigPath))
+	if err != nil || settings.Workflow == nil {
+		return nil
+	}
+	wf := settings.Workflow
+	if len(wf.Vars) == 0 && len(wf.FormulaVars[name]) == 0 {
+		return nil
+	}
+	vars := make(map[string]string, len(wf.Vars)+len(wf.FormulaVars[name]))
+	for k, v := range wf.Vars {
+		vars[k] = v
+	}
+	for k, v := range wf.FormulaVars[name] {
+		vars[k] = v
+	}
+	return vars
+}
+
 // GetRigPrefix returns the beads prefix for a rig from rigs.json.
 // Falls back to "gt" if the rig isn't found or has no prefix configured.
 // townRoot is the path to the town directory (e.g., ~/gt).
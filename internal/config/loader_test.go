@@ -2589,6 +2589,56 @@ func TestBuildCommandWithPromptRespectsPromptModeNone(t *testing.T) {
 	}
 }
 
+// TestBuildArgsWithSystemPrompt verifies the system prompt flag defaults and
+// placement ahead of the initial prompt.
+func TestBuildArgsWithSystemPrompt(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		rc      *RuntimeConfig
+		prompt  string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "claude default flag",
+			rc:     &RuntimeConfig{Provider: "claude", Command: "claude", Args: []string{}},
+			prompt: "go",
+			want:   []string{"claude", "--append-system-prompt", "SYSTEM", "go"},
+		},
+		{
+			name:   "custom flag, prompt mode none",
+			rc:     &RuntimeConfig{Provider: "generic", Command: "agent", Args: []string{"-q"}, PromptMode: "none", SystemPromptFlag: "--system"},
+			prompt: "ignored",
+			want:   []string{"agent", "-q", "--system", "SYSTEM"},
+		},
+		{
+			name:    "no flag",
+			rc:      &RuntimeConfig{Provider: "codex"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.rc.BuildArgsWithSystemPrompt("SYSTEM", tt.prompt)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BuildArgsWithSystemPrompt: %v", err)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 // TestRoleAgentConfigWithCustomAgent tests role-based agent resolution with
 // custom agents that have special settings like prompt_mode: "none".
 //
@@ -3751,3 +3801,21 @@ func TestBuildStartupCommandWithAgentOverride_NoGTAgentWhenNoOverride(t *testing
 		t.Errorf("expected no GT_AGENT in command when no override, got: %q", cmd)
 	}
 }
+
+func TestValidateTargets(t *testing.T) {
+	tests := []struct {
+		name    string
+		targets map[string]TargetPolicy
+		wantErr bool
+	}{
+		{"ok", map[string]TargetPolicy{"release-*": {PRMergeMethod: "merge", FreezeWindows: []string{"fri 16:00-mon 08:00"}}}, false},
+		{"bad pattern", map[string]TargetPolicy{"release-[": {}}, true},
+		{"bad method", map[string]TargetPolicy{"release-*": {PRMergeMethod: "octopus"}}, true},
+		{"bad window", map[string]TargetPolicy{"release-*": {FreezeWindows: []string{"someday"}}}, true},
+	}
+	for _, tt := range tests {
+		if err := ValidateTargets(tt.targets); (err != nil) != tt.wantErr {
+			t.Errorf("%s: ValidateTargets() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
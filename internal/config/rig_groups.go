@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+)
+
+var groupNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ValidateGroupName checks that a rig group name is lowercase letters,
+// digits, hyphens, and underscores.
+func ValidateGroupName(group string) error {
+	if !groupNamePattern.MatchString(group) {
+		return fmt.Errorf("invalid group name %q: use lowercase letters, digits, '-' and '_'", group)
+	}
+	return nil
+}
+
+// InGroup reports whether the rig belongs to group.
+func (e RigEntry) InGroup(group string) bool {
+	return slices.Contains(e.Groups, group)
+}
+
+// GroupMembers returns the sorted names of the rigs in group.
+func (c *RigsConfig) GroupMembers(group string) []string {
+	var names []string
+	for name, entry := range c.Rigs {
+		if entry.InGroup(group) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Groups returns every group with its sorted members.
+func (c *RigsConfig) Groups() map[string][]string {
+	groups := make(map[string][]string)
+	for name, entry := range c.Rigs {
+		for _, g := range entry.Groups {
+			groups[g] = append(groups[g], name)
+		}
+	}
+	for _, members := range groups {
+		sort.Strings(members)
+	}
+	return groups
+}
+
+// AddToGroup adds a registered rig to group. Returns false if it was
+// already a member.
+func (c *RigsConfig) AddToGroup(rigName, group string) (bool, error) {
+	if err := ValidateGroupName(group); err != nil {
+		return false, err
+	}
+	entry, ok := c.Rigs[rigName]
+	if !ok {
+		return false, fmt.Errorf("rig '%s' not found", rigName)
+	}
+	if entry.InGroup(group) {
+		return false, nil
+	}
+	entry.Groups = append(entry.Groups, group)
+	sort.Strings(entry.Groups)
+	c.Rigs[rigName] = entry
+	return true, nil
+}
+
+// RemoveFromGroup removes a rig from group. Returns false if it wasn't a
+// member.
+func (c *RigsConfig) RemoveFromGroup(rigName, group string) (bool, error) {
+	entry, ok := c.Rigs[rigName]
+	if !ok {
+		return false, fmt.Errorf("rig '%s' not found", rigName)
+	}
+	i := slices.Index(entry.Groups, group)
+	if i < 0 {
+		return false, nil
+	}
+	entry.Groups = slices.Delete(entry.Groups, i, i+1)
+	c.Rigs[rigName] = entry
+	return true, nil
+}
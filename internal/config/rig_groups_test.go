@@ -0,0 +1,45 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRigGroups(t *testing.T) {
+	c := &RigsConfig{Rigs: map[string]RigEntry{"api": {}, "web": {}, "ops": {}}}
+
+	for _, tc := range []struct{ rig, group string }{
+		{"web", "client_a"}, {"api", "client_a"}, {"ops", "infra"}, {"api", "infra"},
+	} {
+		if added, err := c.AddToGroup(tc.rig, tc.group); err != nil || !added {
+			t.Fatalf("AddToGroup(%s, %s) = %v, %v", tc.rig, tc.group, added, err)
+		}
+	}
+	if added, _ := c.AddToGroup("api", "infra"); added {
+		t.Error("AddToGroup reported adding an existing member")
+	}
+	if _, err := c.AddToGroup("nope", "infra"); err == nil {
+		t.Error("AddToGroup accepted an unknown rig")
+	}
+	if _, err := c.AddToGroup("api", "Bad Name"); err == nil {
+		t.Error("AddToGroup accepted an invalid group name")
+	}
+
+	if got := c.GroupMembers("client_a"); !reflect.DeepEqual(got, []string{"api", "web"}) {
+		t.Errorf("GroupMembers(client_a) = %v", got)
+	}
+	want := map[string][]string{"client_a": {"api", "web"}, "infra": {"api", "ops"}}
+	if got := c.Groups(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Groups() = %v, want %v", got, want)
+	}
+
+	if removed, _ := c.RemoveFromGroup("api", "infra"); !removed {
+		t.Error("RemoveFromGroup didn't remove a member")
+	}
+	if removed, _ := c.RemoveFromGroup("api", "infra"); removed {
+		t.Error("RemoveFromGroup removed a non-member")
+	}
+	if got := c.GroupMembers("infra"); !reflect.DeepEqual(got, []string{"ops"}) {
+		t.Errorf("GroupMembers(infra) after remove = %v", got)
+	}
+}
@@ -0,0 +1,306 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// DefaultRateLimitCooldown is how long an agent is skipped after a session
+// hits a provider rate limit, unless its AgentLimits set a cooldown.
+const DefaultRateLimitCooldown = 10 * time.Minute
+
+// RoutingState tracks per-agent rate limits and recent session starts so
+// session starts can route around exhausted agents.
+type RoutingState struct {
+	// LimitedUntil maps agent names to the end of their rate-limit cooldown.
+	LimitedUntil map[string]time.Time `json:"limited_until,omitempty"`
+
+	// Starts maps agent names to session start times within the last hour.
+	Starts map[string][]time.Time `json:"starts,omitempty"`
+}
+
+// AgentRoute is the agent chosen for a role's next session.
+type AgentRoute struct {
+	Role     string      `json:"role"`
+	Agent    string      `json:"agent"`
+	Chain    []string    `json:"chain"`
+	Fallback bool        `json:"fallback"`
+	Skipped  []RouteSkip `json:"skipped,omitempty"`
+}
+
+// RouteSkip records why an agent in the chain was passed over.
+type RouteSkip struct {
+	Agent  string `json:"agent"`
+	Reason string `json:"reason"`
+}
+
+// RoutingStatePath returns the path to the town's model routing state.
+func RoutingStatePath(townRoot string) string {
+	return filepath.Join(townRoot, ".runtime", "model-routing.json")
+}
+
+// LoadRoutingState loads the town's routing state. A missing file is an
+// empty state.
+func LoadRoutingState(townRoot string) (*RoutingState, error) {
+	state := &RoutingState{}
+	data, err := os.ReadFile(RoutingStatePath(townRoot)) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("parsing routing state: %w", err)
+	}
+	return state, nil
+}
+
+// SaveRoutingState writes the town's routing state.
+func SaveRoutingState(townRoot string, state *RoutingState) error {
+	return util.EnsureDirAndWriteJSON(RoutingStatePath(townRoot), state)
+}
+
+// updateRoutingState loads the routing state under the town's routing
+// lock, applies fn, and saves the result unless fn fails. Sessions start
+// concurrently, and an unlocked load and save drops their starts.
+func updateRoutingState(townRoot string, fn func(*RoutingState) error) error {
+	path := RoutingStatePath(townRoot)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	fl := flock.New(path + ".lock")
+	if err := fl.Lock(); err != nil {
+		return fmt.Errorf("locking routing state: %w", err)
+	}
+	defer func() { _ = fl.Unlock() }()
+
+	state, err := LoadRoutingState(townRoot)
+	if err != nil {
+		return err
+	}
+	if err := fn(state); err != nil {
+		return err
+	}
+	return SaveRoutingState(townRoot, state)
+}
+
+// prune drops expired cooldowns and starts older than an hour.
+func (s *RoutingState) prune(now time.Time) {
+	for agent, until := range s.LimitedUntil {
+		if !until.After(now) {
+			delete(s.LimitedUntil, agent)
+		}
+	}
+	for agent, starts := range s.Starts {
+		var recent []time.Time
+		for _, t := range starts {
+			if now.Sub(t) < time.Hour {
+				recent = append(recent, t)
+			}
+		}
+		if len(recent) == 0 {
+			delete(s.Starts, agent)
+		} else {
+			s.Starts[agent] = recent
+		}
+	}
+}
+
+// unavailable returns why agent can't take a new session, or "" if it can.
+func (s *RoutingState) unavailable(agent string, limit *AgentLimitConfig, now time.Time) string {
+	if until, ok := s.LimitedUntil[agent]; ok && until.After(now) {
+		return fmt.Sprintf("rate limited until %s", until.Format("15:04"))
+	}
+	if limit != nil && limit.MaxStartsPerHour > 0 {
+		var recent int
+		for _, t := range s.Starts[agent] {
+			if now.Sub(t) < time.Hour {
+				recent++
+			}
+		}
+		if recent >= limit.MaxStartsPerHour {
+			return fmt.Sprintf("%d/%d starts this hour", recent, limit.MaxStartsPerHour)
+		}
+	}
+	return ""
+}
+
+// roleAgentChain returns the role's agent followed by its fallbacks, without
+// duplicates. Rig fallbacks replace town fallbacks.
+func roleAgentChain(role, primary string, townSettings *TownSettings, rigSettings *RigSettings) []string {
+	fallbacks := townSettings.RoleFallbacks[role]
+	if rigSettings != nil {
+		if rigFallbacks, ok := rigSettings.RoleFallbacks[role]; ok {
+			fallbacks = rigFallbacks
+		}
+	}
+	chain := []string{primary}
+	seen := map[string]bool{primary: true}
+	for _, name := range fallbacks {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			chain = append(chain, name)
+		}
+	}
+	return chain
+}
+
+// routingConfigured reports whether any agent in chain has fallbacks or limits.
+func routingConfigured(chain []string, townSettings *TownSettings) bool {
+	if len(chain) > 1 {
+		return true
+	}
+	for _, name := range chain {
+		if townSettings.AgentLimits[name] != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// chooseAgent picks the first available agent in chain. If none is available
+// it keeps the first one: a throttled session beats no session.
+func chooseAgent(role string, chain []string, limits map[string]*AgentLimitConfig, state *RoutingState, valid func(string) error, now time.Time) *AgentRoute {
+	route := &AgentRoute{Role: role, Agent: chain[0], Chain: chain}
+	for i, name := range chain {
+		reason := state.unavailable(name, limits[name], now)
+		if reason == "" && i > 0 && valid != nil {
+			if err := valid(name); err != nil {
+				reason = err.Error()
+			}
+		}
+		if reason != "" {
+			route.Skipped = append(route.Skipped, RouteSkip{Agent: name, Reason: reason})
+			continue
+		}
+		route.Agent = name
+		route.Fallback = i > 0
+		return route
+	}
+	return route
+}
+
+// RouteRoleAgent returns the agent a role's next session should use, given
+// the role's fallbacks, the town's agent limits, and recent rate limits.
+// It returns nil when the role has no fallbacks or limits configured, in
+// which case ResolveRoleAgentConfig's plain resolution applies.
+func RouteRoleAgent(role, townRoot, rigPath string, now time.Time) *AgentRoute {
+	townSettings, rigSettings := loadRoutingSettings(townRoot, rigPath)
+	return routeRoleAgent(role, townRoot, rigPath, townSettings, rigSettings, now)
+}
+
+func routeRoleAgent(role, townRoot, rigPath string, townSettings *TownSettings, rigSettings *RigSettings, now time.Time) *AgentRoute {
+	return routeRoleAgentWith(role, townRoot, rigPath, townSettings, rigSettings, nil, now)
+}
+
+// routeRoleAgentWith routes against state, or the saved state when nil.
+func routeRoleAgentWith(role, townRoot, rigPath string, townSettings *TownSettings, rigSettings *RigSettings, state *RoutingState, now time.Time) *AgentRoute {
+	primary, _ := ResolveRoleAgentName(role, townRoot, rigPath)
+	chain := roleAgentChain(role, primary, townSettings, rigSettings)
+	if !routingConfigured(chain, townSettings) {
+		return nil
+	}
+	if state == nil {
+		var err error
+		if state, err = LoadRoutingState(townRoot); err != nil {
+			state = &RoutingState{}
+		}
+	}
+	valid := func(name string) error {
+		if lookupCustomAgentConfig(name, townSettings, rigSettings) != nil {
+			return nil
+		}
+		return ValidateAgentConfig(name, townSettings, rigSettings)
+	}
+	return chooseAgent(role, chain, townSettings.AgentLimits, state, valid, now)
+}
+
+func loadRoutingSettings(townRoot, rigPath string) (*TownSettings, *RigSettings) {
+	townSettings, err := LoadOrCreateTownSettings(TownSettingsPath(townRoot))
+	if err != nil {
+		townSettings = NewTownSettings()
+	}
+	var rigSettings *RigSettings
+	if rigPath != "" {
+		if rs, err := LoadRigSettings(RigSettingsPath(rigPath)); err == nil {
+			rigSettings = rs
+		}
+	}
+	return townSettings, rigSettings
+}
+
+// errUnrouted stops a routing state update for a role with no routing.
+var errUnrouted = errors.New("role has no routing configured")
+
+// RecordRoleStart routes a role's session start and records it against the
+// chosen agent's hourly budget. It returns the chosen agent, or "" when the
+// role has no routing configured. Routing and recording happen under one
+// lock, so concurrent starts count against each other's budgets.
+func RecordRoleStart(role, townRoot, rigPath string, now time.Time) string {
+	townSettings, rigSettings := loadRoutingSettings(townRoot, rigPath)
+	var route *AgentRoute
+	err := updateRoutingState(townRoot, func(state *RoutingState) error {
+		if route = routeRoleAgentWith(role, townRoot, rigPath, townSettings, rigSettings, state, now); route == nil {
+			return errUnrouted
+		}
+		state.prune(now)
+		if state.Starts == nil {
+			state.Starts = make(map[string][]time.Time)
+		}
+		state.Starts[route.Agent] = append(state.Starts[route.Agent], now)
+		return nil
+	})
+	if err != nil && !errors.Is(err, errUnrouted) && route == nil {
+		// The state can't be read: route without it, unrecorded
+		route = routeRoleAgentWith(role, townRoot, rigPath, townSettings, rigSettings, &RoutingState{}, now)
+	}
+	if route == nil {
+		return ""
+	}
+	return route.Agent
+}
+
+// MarkAgentRateLimited puts agent in its cooldown after a provider rate
+// limit, so session starts route to fallbacks until it ends. It returns the
+// end of the cooldown.
+func MarkAgentRateLimited(townRoot, agent string, now time.Time) (time.Time, error) {
+	cooldown := DefaultRateLimitCooldown
+	townSettings, _ := loadRoutingSettings(townRoot, "")
+	if limit := townSettings.AgentLimits[agent]; limit != nil && limit.Cooldown != "" {
+		d, err := time.ParseDuration(limit.Cooldown)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("agent_limits[%s].cooldown: %w", agent, err)
+		}
+		cooldown = d
+	}
+
+	until := now.Add(cooldown)
+	err := updateRoutingState(townRoot, func(state *RoutingState) error {
+		state.prune(now)
+		if state.LimitedUntil == nil {
+			state.LimitedUntil = make(map[string]time.Time)
+		}
+		state.LimitedUntil[agent] = until
+		return nil
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	return until, nil
+}
+
+// ClearAgentRateLimit ends agent's rate-limit cooldown early.
+func ClearAgentRateLimit(townRoot, agent string) error {
+	return updateRoutingState(townRoot, func(state *RoutingState) error {
+		delete(state.LimitedUntil, agent)
+		return nil
+	})
+}
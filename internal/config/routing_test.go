@@ -0,0 +1,190 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestChooseAgent(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	limits := map[string]*AgentLimitConfig{
+		"opus": {MaxStartsPerHour: 2},
+	}
+
+	tests := []struct {
+		name         string
+		state        *RoutingState
+		valid        func(string) error
+		wantAgent    string
+		wantFallback bool
+		wantSkipped  int
+	}{
+		{
+			name:      "primary available",
+			state:     &RoutingState{},
+			wantAgent: "opus",
+		},
+		{
+			name:         "primary rate limited",
+			state:        &RoutingState{LimitedUntil: map[string]time.Time{"opus": now.Add(time.Minute)}},
+			wantAgent:    "sonnet",
+			wantFallback: true,
+			wantSkipped:  1,
+		},
+		{
+			name:      "expired cooldown",
+			state:     &RoutingState{LimitedUntil: map[string]time.Time{"opus": now.Add(-time.Minute)}},
+			wantAgent: "opus",
+		},
+		{
+			name: "hourly budget spent",
+			state: &RoutingState{Starts: map[string][]time.Time{
+				"opus": {now.Add(-10 * time.Minute), now.Add(-5 * time.Minute)},
+			}},
+			wantAgent:    "sonnet",
+			wantFallback: true,
+			wantSkipped:  1,
+		},
+		{
+			name: "old starts don't count",
+			state: &RoutingState{Starts: map[string][]time.Time{
+				"opus": {now.Add(-2 * time.Hour), now.Add(-5 * time.Minute)},
+			}},
+			wantAgent: "opus",
+		},
+		{
+			name:         "invalid fallback skipped",
+			state:        &RoutingState{LimitedUntil: map[string]time.Time{"opus": now.Add(time.Minute)}},
+			valid:        func(name string) error { return map[string]error{"sonnet": os.ErrNotExist}[name] },
+			wantAgent:    "codex",
+			wantFallback: true,
+			wantSkipped:  2,
+		},
+		{
+			name: "everything exhausted keeps primary",
+			state: &RoutingState{LimitedUntil: map[string]time.Time{
+				"opus": now.Add(time.Minute), "sonnet": now.Add(time.Minute), "codex": now.Add(time.Minute),
+			}},
+			wantAgent:   "opus",
+			wantSkipped: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route := chooseAgent("polecat", []string{"opus", "sonnet", "codex"}, limits, tt.state, tt.valid, now)
+			if route.Agent != tt.wantAgent || route.Fallback != tt.wantFallback || len(route.Skipped) != tt.wantSkipped {
+				t.Errorf("got agent=%s fallback=%v skipped=%v, want agent=%s fallback=%v skipped=%d",
+					route.Agent, route.Fallback, route.Skipped, tt.wantAgent, tt.wantFallback, tt.wantSkipped)
+			}
+		})
+	}
+}
+
+func TestRoleAgentChain(t *testing.T) {
+	t.Parallel()
+
+	town := &TownSettings{RoleFallbacks: map[string][]string{"polecat": {"sonnet", "opus", "codex"}}}
+	if got := roleAgentChain("polecat", "opus", town, nil); len(got) != 3 || got[0] != "opus" || got[1] != "sonnet" || got[2] != "codex" {
+		t.Errorf("town chain = %v, want [opus sonnet codex]", got)
+	}
+
+	rig := &RigSettings{RoleFallbacks: map[string][]string{"polecat": {"haiku"}}}
+	if got := roleAgentChain("polecat", "opus", town, rig); len(got) != 2 || got[1] != "haiku" {
+		t.Errorf("rig chain = %v, want [opus haiku]", got)
+	}
+
+	if got := roleAgentChain("witness", "opus", town, rig); len(got) != 1 {
+		t.Errorf("unrouted chain = %v, want [opus]", got)
+	}
+}
+
+func TestMarkAgentRateLimitedAndRecordStart(t *testing.T) {
+	townRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(townRoot, "settings"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	settings := NewTownSettings()
+	settings.RoleAgents["polecat"] = "claude"
+	settings.RoleAgents["witness"] = "claude-alt"
+	settings.RoleFallbacks = map[string][]string{"polecat": {"claude-alt"}}
+	settings.Agents = map[string]*RuntimeConfig{"claude-alt": {Command: "claude", Args: []string{"--model", "sonnet"}}}
+	settings.AgentLimits = map[string]*AgentLimitConfig{"claude": {Cooldown: "30m"}}
+	if err := SaveTownSettings(TownSettingsPath(townRoot), settings); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	until, err := MarkAgentRateLimited(townRoot, "claude", now)
+	if err != nil {
+		t.Fatalf("MarkAgentRateLimited: %v", err)
+	}
+	if got := until.Sub(now); got != 30*time.Minute {
+		t.Errorf("cooldown = %v, want 30m", got)
+	}
+
+	if agent := RecordRoleStart("polecat", townRoot, "", now); agent != "claude-alt" {
+		t.Errorf("RecordRoleStart = %q, want claude-alt", agent)
+	}
+	state, err := LoadRoutingState(townRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Starts["claude-alt"]) != 1 {
+		t.Errorf("starts = %v, want one start on claude-alt", state.Starts)
+	}
+	if rc := ResolveRoleAgentConfig("polecat", townRoot, ""); len(rc.Args) != 2 || rc.Args[1] != "sonnet" {
+		t.Errorf("ResolveRoleAgentConfig args = %v, want the fallback's", rc.Args)
+	}
+
+	if err := ClearAgentRateLimit(townRoot, "claude"); err != nil {
+		t.Fatal(err)
+	}
+	if agent := RecordRoleStart("polecat", townRoot, "", now); agent != "claude" {
+		t.Errorf("after clear, RecordRoleStart = %q, want claude", agent)
+	}
+
+	// Roles whose agent has no fallbacks or limits aren't tracked
+	if agent := RecordRoleStart("witness", townRoot, "", now); agent != "" {
+		t.Errorf("unrouted RecordRoleStart = %q, want empty", agent)
+	}
+}
+
+func TestRecordRoleStart_Concurrent(t *testing.T) {
+	townRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(townRoot, "settings"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	settings := NewTownSettings()
+	settings.RoleAgents["polecat"] = "claude"
+	settings.RoleFallbacks = map[string][]string{"polecat": {"claude-alt"}}
+	settings.Agents = map[string]*RuntimeConfig{"claude-alt": {Command: "claude", Args: []string{"--model", "sonnet"}}}
+	settings.AgentLimits = map[string]*AgentLimitConfig{"claude": {MaxStartsPerHour: 5}}
+	if err := SaveTownSettings(TownSettingsPath(townRoot), settings); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every start is recorded, and none overshoots the budget
+	now := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 12; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			RecordRoleStart("polecat", townRoot, "", now)
+		}()
+	}
+	wg.Wait()
+	state, err := LoadRoutingState(townRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Starts["claude"]) != 5 || len(state.Starts["claude-alt"]) != 7 {
+		t.Errorf("starts = claude %d, claude-alt %d, want 5 and 7", len(state.Starts["claude"]), len(state.Starts["claude-alt"]))
+	}
+}
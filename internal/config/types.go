@@ -40,12 +40,35 @@ type TownSettings struct {
 	Type    string `json:"type"`    // "town-settings"
 	Version int    `json:"version"` // schema version
 
+	// CommandDefaults sets flag defaults per command for everyone in the
+	// town, keyed by command path ("blocked", "rig list", or "*" for
+	// every command with the flag) and then flag name. User defaults
+	// (see UserDefaultsPath) win over these; flags on the command line
+	// win over both.
+	// Example: {"ready": {"group": "mine"}, "*": {"json": "true"}}
+	CommandDefaults CommandDefaults `json:"command_defaults,omitempty"`
+
 	// CLITheme controls CLI output color scheme.
 	// Values: "dark", "light", "auto" (default).
 	// "auto" lets the terminal emulator's background color guide the choice.
 	// Can be overridden by GT_THEME environment variable.
 	CLITheme string `json:"cli_theme,omitempty"`
 
+	// CLIASCII replaces emoji and box-drawing characters in human output
+	// with plain ASCII, for terminals and CI logs that mangle them.
+	// Can also be enabled with --ascii or GT_ASCII=1.
+	CLIASCII bool `json:"cli_ascii,omitempty"`
+
+	// CLILocale selects the language of gt's messages, e.g. "de" or "es".
+	// Can be overridden by GT_LANG; when both are unset the system locale
+	// (LC_ALL, LC_MESSAGES, LANG) is used. Untranslated messages stay English.
+	CLILocale string `json:"cli_locale,omitempty"`
+
+	// CLITitleWidth caps issue titles in human list output, in terminal
+	// cells. 0 (default) fits titles to the terminal width; -1 never
+	// truncates them. Can be overridden by GT_TITLE_WIDTH.
+	CLITitleWidth int `json:"cli_title_width,omitempty"`
+
 	// DefaultAgent is the name of the agent preset to use by default.
 	// Can be a built-in preset ("claude", "gemini", "codex", "cursor", "auggie", "amp")
 	// or a custom agent name defined in settings/agents.json.
@@ -65,6 +88,21 @@ type TownSettings struct {
 	// Example: {"mayor": "claude-opus", "witness": "claude-haiku", "polecat": "claude-sonnet"}
 	RoleAgents map[string]string `json:"role_agents,omitempty"`
 
+	// RoleFallbacks maps role names to ordered fallback agent aliases.
+	// A session start uses the first agent in [role agent, fallbacks...] that
+	// isn't cooling down from a provider rate limit or over its AgentLimits.
+	// Example: {"polecat": ["claude-sonnet", "codex"]}
+	RoleFallbacks map[string][]string `json:"role_fallbacks,omitempty"`
+
+	// AgentLimits caps how often each agent alias is used, keyed by agent name.
+	// Example: {"claude-opus": {"max_starts_per_hour": 20, "cooldown": "15m"}}
+	AgentLimits map[string]*AgentLimitConfig `json:"agent_limits,omitempty"`
+
+	// RateLimits configures the town-wide request limiter shared by all
+	// agents, keyed by runtime provider ("claude", "codex", ...).
+	// Example: {"claude": {"requests_per_minute": 120, "burst": 30}}
+	RateLimits map[string]*RateLimitConfig `json:"rate_limits,omitempty"`
+
 	// AgentEmailDomain is the domain used for agent git identity emails.
 	// Agent addresses like "gastown/crew/jack" become "gastown.crew.jack@{domain}".
 	// Default: "gastown.local"
@@ -78,6 +116,42 @@ type TownSettings struct {
 
 	// FeedCurator configures event deduplication and aggregation windows.
 	FeedCurator *FeedCuratorConfig `json:"feed_curator,omitempty"`
+
+	// Retention bounds how long and how large logs, the event log, audit
+	// logs, and session recordings may grow. Enforced by the daemon and gt gc.
+	Retention *RetentionConfig `json:"retention,omitempty"`
+
+	// BlobStore is the town's default storage backend for attachments and
+	// backups. Each class gets its own subdirectory or key prefix ("attachments/",
+	// "backups/"). Default: local directories under the town root.
+	BlobStore *BlobStoreConfig `json:"blob_store,omitempty"`
+
+	// Attachments overrides BlobStore for files attached to beads with
+	// 'gt attach'. Default: content-addressed files under <town>/attachments.
+	Attachments *BlobStoreConfig `json:"attachments,omitempty"`
+
+	// Backups overrides BlobStore for town state backups ('gt backup').
+	// Default: <town>/backups.
+	Backups *BlobStoreConfig `json:"backups,omitempty"`
+
+	// BackupInterval, if set, has the daemon back up town state this often
+	// (e.g. "6h", "1d"). Old backups are pruned per retention.backups.
+	BackupInterval string `json:"backup_interval,omitempty"`
+
+	// Runbooks are named command sequences run with 'gt run <name>', from
+	// hooks, or on a schedule by the daemon, keyed by runbook name.
+	Runbooks map[string]*RunbookConfig `json:"runbooks,omitempty"`
+
+	// Goals are the town's named objectives, keyed by goal name. Convoys
+	// and milestones link to a goal with a goal:<name> label; 'gt goal'
+	// rolls up their progress.
+	// Example: {"faster-onboarding": {"description": "Signup in under 2 minutes", "target": "2026-06-30"}}
+	Goals map[string]*GoalConfig `json:"goals,omitempty"`
+
+	// Views are named queries over issues and merge requests across rigs,
+	// run with 'gt view <name>'.
+	// Example: {"p0-open": {"status": "open", "max_priority": 0}}
+	Views map[string]*ViewConfig `json:"views,omitempty"`
 }
 
 // NewTownSettings creates a new TownSettings with defaults.
@@ -91,6 +165,162 @@ func NewTownSettings() *TownSettings {
 	}
 }
 
+// GoalConfig is a named objective that convoys and milestones roll up to.
+type GoalConfig struct {
+	// Description states the objective; shown in rollups.
+	Description string `json:"description,omitempty"`
+
+	// Owner answers for the goal (e.g., "mayor/" or a person's name).
+	Owner string `json:"owner,omitempty"`
+
+	// Target is the date the goal is due, YYYY-MM-DD. Optional; without
+	// it a goal's health comes from its milestones.
+	Target string `json:"target,omitempty"`
+}
+
+// ViewConfig is a saved query over beads across rigs. Every filter that is
+// set must match.
+type ViewConfig struct {
+	// Description says what the view shows; shown by 'gt view'.
+	Description string `json:"description,omitempty"`
+
+	// Rigs limits the view to these rigs ("town" for town beads).
+	// Default: the town and every rig.
+	Rigs []string `json:"rigs,omitempty"`
+
+	// Kind is "issues" (default), "mrs" for merge requests, or "all".
+	Kind string `json:"kind,omitempty"`
+
+	// Status is "open" (default), "in_progress", "closed", "all", or one of
+	// the computed states "ready" and "blocked".
+	Status string `json:"status,omitempty"`
+
+	// Type is an issue type (e.g., "bug", "feature").
+	Type string `json:"type,omitempty"`
+
+	// Labels an issue must all carry.
+	Labels []string `json:"labels,omitempty"`
+
+	// Assignee matches the assignee exactly; "none" matches unassigned.
+	Assignee string `json:"assignee,omitempty"`
+
+	// MaxPriority keeps issues at this priority or more urgent (0 = P0).
+	MaxPriority *int `json:"max_priority,omitempty"`
+
+	// Fields are custom field values an issue must carry (see 'gt field').
+	Fields map[string]string `json:"fields,omitempty"`
+
+	// Query is a query language expression issues must also match, for
+	// what the fields above can't say (see 'gt help query').
+	// Example: "priority<=1 and (label:infra or label:security)"
+	Query string `json:"query,omitempty"`
+
+	// Sort is "priority" (default), "created", or "updated"; newest first
+	// for the dates.
+	Sort string `json:"sort,omitempty"`
+
+	// Limit caps the number of results. 0 means no limit.
+	Limit int `json:"limit,omitempty"`
+}
+
+// RunbookConfig is a named sequence of gt and shell commands with
+// parameters (see package runbook).
+type RunbookConfig struct {
+	// Description says what the runbook is for; shown by 'gt run'.
+	Description string `json:"description,omitempty"`
+
+	// Params are the runbook's parameters, referenced in steps as {{name}}.
+	Params []RunbookParam `json:"params,omitempty"`
+
+	// Steps run in order, from the town root. A failing step stops the
+	// runbook unless it sets continue_on_error.
+	Steps []RunbookStep `json:"steps"`
+
+	// Every, if set, has the daemon run the runbook this often (e.g. "1h",
+	// "1d") with default parameters.
+	Every string `json:"every,omitempty"`
+}
+
+// RunbookParam is a runbook parameter.
+type RunbookParam struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+
+	// Default is used when the parameter isn't given.
+	Default string `json:"default,omitempty"`
+
+	// Required parameters have no default and must be given.
+	Required bool `json:"required,omitempty"`
+}
+
+// RunbookStep is one command in a runbook. Exactly one of Run and GT is set.
+type RunbookStep struct {
+	// Name labels the step in output and logs. Default: the command.
+	Name string `json:"name,omitempty"`
+
+	// Run is a shell command.
+	Run string `json:"run,omitempty"`
+
+	// GT is a gt command line without the leading "gt", run with the same
+	// gt binary, e.g. "mq list {{rig}}".
+	GT string `json:"gt,omitempty"`
+
+	// Timeout bounds the step (e.g. "10m"). Default: "30m".
+	Timeout string `json:"timeout,omitempty"`
+
+	// ContinueOnError keeps the runbook going if this step fails.
+	ContinueOnError bool `json:"continue_on_error,omitempty"`
+}
+
+// BlobStoreConfig selects a backend for stored files (see package blobstore).
+type BlobStoreConfig struct {
+	// Backend is "local" (default), "s3", or "gcs".
+	Backend string `json:"backend,omitempty"`
+
+	// Dir is the local backend's directory. Relative paths are resolved
+	// against the town root. Default depends on what is stored.
+	Dir string `json:"dir,omitempty"`
+
+	// S3 configures the s3 backend.
+	S3 *S3Config `json:"s3,omitempty"`
+
+	// GCS configures the gcs backend.
+	GCS *GCSConfig `json:"gcs,omitempty"`
+}
+
+// S3Config configures an S3-compatible bucket (AWS S3, MinIO, R2, ...).
+// Credentials come from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, and
+// optionally AWS_SESSION_TOKEN.
+type S3Config struct {
+	// Bucket is the bucket name (required).
+	Bucket string `json:"bucket"`
+
+	// Region is the signing region. Default: "us-east-1".
+	Region string `json:"region,omitempty"`
+
+	// Endpoint is the service URL, e.g. "http://minio.local:9000".
+	// Default: AWS S3 for Region. Requests use path-style addressing.
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// Prefix is prepended to every object key, e.g. "towns/home/".
+	Prefix string `json:"prefix,omitempty"`
+}
+
+// GCSConfig configures a Google Cloud Storage bucket. Credentials come
+// from GOOGLE_OAUTH_ACCESS_TOKEN, a service account key file
+// (CredentialsFile or GOOGLE_APPLICATION_CREDENTIALS), or the GCE metadata
+// server, in that order. STORAGE_EMULATOR_HOST points at an emulator.
+type GCSConfig struct {
+	// Bucket is the bucket name (required).
+	Bucket string `json:"bucket"`
+
+	// Prefix is prepended to every object name, e.g. "towns/home/".
+	Prefix string `json:"prefix,omitempty"`
+
+	// CredentialsFile is a service account JSON key file.
+	CredentialsFile string `json:"credentials_file,omitempty"`
+}
+
 // WebTimeoutsConfig configures command execution timeouts for the web dashboard.
 type WebTimeoutsConfig struct {
 	// CmdTimeout is the timeout for bd (beads) commands. Default: "15s".
@@ -167,6 +397,53 @@ func DefaultFeedCuratorConfig() *FeedCuratorConfig {
 	}
 }
 
+// RetentionConfig sets retention per class of file. Unset classes use
+// DefaultRetentionConfig's policy.
+type RetentionConfig struct {
+	// Logs covers agent and daemon logs (logs/*.log, daemon/*.log, rig logs).
+	Logs *RetentionPolicy `json:"logs,omitempty"`
+	// Events covers the town event log (.events.jsonl, .feed.jsonl).
+	Events *RetentionPolicy `json:"events,omitempty"`
+	// Audit covers beads audit logs (.beads/audit.log).
+	Audit *RetentionPolicy `json:"audit,omitempty"`
+	// Recordings covers agent session transcripts for the town.
+	// MaxSize bounds each session directory; Keep and Compress don't apply.
+	Recordings *RetentionPolicy `json:"recordings,omitempty"`
+	// Backups covers town state backups in the backup store. MaxAge and
+	// Keep (newest kept) apply per backup; MaxSize caps their total size.
+	Backups *RetentionPolicy `json:"backups,omitempty"`
+	// Attachments covers the attachment store, like Backups. Pruned
+	// attachments stay listed on their beads but can't be retrieved.
+	Attachments *RetentionPolicy `json:"attachments,omitempty"`
+	// Interval is how often the daemon enforces retention. Default: "1h".
+	Interval string `json:"interval,omitempty"`
+}
+
+// RetentionPolicy bounds one class of file.
+type RetentionPolicy struct {
+	// MaxAge removes rotated files (and recordings) older than this, e.g. "14d".
+	MaxAge string `json:"max_age,omitempty"`
+	// MaxSize rotates a live file once it grows past this, e.g. "50M".
+	MaxSize string `json:"max_size,omitempty"`
+	// Keep is the most rotated files kept per log; 0 means no limit.
+	Keep int `json:"keep,omitempty"`
+	// Compress gzips rotated files. Default: true.
+	Compress *bool `json:"compress,omitempty"`
+}
+
+// DefaultRetentionConfig returns a RetentionConfig with sensible defaults.
+func DefaultRetentionConfig() *RetentionConfig {
+	return &RetentionConfig{
+		Logs:        &RetentionPolicy{MaxAge: "14d", MaxSize: "50M", Keep: 10},
+		Events:      &RetentionPolicy{MaxAge: "30d", MaxSize: "100M", Keep: 10},
+		Audit:       &RetentionPolicy{MaxAge: "90d", MaxSize: "20M", Keep: 10},
+		Recordings:  &RetentionPolicy{MaxAge: "30d", MaxSize: "2G"},
+		Backups:     &RetentionPolicy{MaxAge: "30d", Keep: 14},
+		Attachments: &RetentionPolicy{},
+		Interval:    "1h",
+	}
+}
+
 // ParseDurationOrDefault parses a Go duration string, returning fallback on error or empty input.
 func ParseDurationOrDefault(s string, fallback time.Duration) time.Duration {
 	if s == "" {
@@ -266,6 +543,25 @@ type RigEntry struct {
 	LocalRepo   string       `json:"local_repo,omitempty"`
 	AddedAt     time.Time    `json:"added_at"`
 	BeadsConfig *BeadsConfig `json:"beads,omitempty"`
+
+	// Subdir scopes a sub-rig to a subtree of a monorepo shared with other
+	// rigs (same git URL). Empty means the rig owns the whole repository.
+	Subdir string `json:"subdir,omitempty"`
+
+	// Archived is set while the rig is in cold storage (gt rig archive):
+	// its checkouts are removed and it is left out of discovery.
+	Archived *RigArchive `json:"archived,omitempty"`
+
+	// Groups are named sets the rig belongs to (client-a, infra), used to
+	// select several rigs at once with --group.
+	Groups []string `json:"groups,omitempty"`
+}
+
+// RigArchive records when and how a rig was archived.
+type RigArchive struct {
+	ArchivedAt time.Time `json:"archived_at"`
+	Snapshot   string    `json:"snapshot,omitempty"` // Beads export, relative to the rig
+	Reason     string    `json:"reason,omitempty"`
 }
 
 // BeadsConfig represents beads configuration for a rig.
@@ -304,6 +600,13 @@ type WorkflowConfig struct {
 	// DefaultFormula is the formula to use when `gt formula run` is called without arguments.
 	// If empty, no default is set and a formula name must be provided.
 	DefaultFormula string `json:"default_formula,omitempty"`
+
+	// Vars override formula var defaults for every formula run in this rig.
+	Vars map[string]string `json:"vars,omitempty"`
+
+	// FormulaVars override var defaults for individual formulas, keyed by
+	// formula name. They take precedence over Vars.
+	FormulaVars map[string]map[string]string `json:"formula_vars,omitempty"`
 }
 
 // RigSettings represents per-rig behavioral configuration (settings/config.json).
@@ -317,6 +620,25 @@ type RigSettings struct {
 	Workflow   *WorkflowConfig   `json:"workflow,omitempty"`    // workflow settings
 	Runtime    *RuntimeConfig    `json:"runtime,omitempty"`     // LLM runtime settings (deprecated: use Agent)
 
+	// DepUpdates configures dependency-update detection (gt deps check).
+	DepUpdates *DepUpdatesConfig `json:"dep_updates,omitempty"`
+
+	// BuildCache configures shared build caches for test runs and agents.
+	BuildCache *BuildCacheConfig `json:"build_cache,omitempty"`
+
+	// Env is the rig's managed environment profile for agents and test runs.
+	Env *EnvProfileConfig `json:"env,omitempty"`
+
+	// DevEnv runs agents and test runs inside the Nix or devcontainer
+	// environment the rig's repo declares.
+	DevEnv *DevEnvConfig `json:"dev_env,omitempty"`
+
+	// Release configures release cutting (gt rig release).
+	Release *ReleaseConfig `json:"release,omitempty"`
+
+	// Incident configures incident response (gt incident).
+	Incident *IncidentConfig `json:"incident,omitempty"`
+
 	// Agent selects which agent preset to use for this rig.
 	// Can be a built-in preset ("claude", "gemini", "codex", "cursor", "auggie", "amp")
 	// or a custom agent defined in settings/agents.json.
@@ -335,6 +657,152 @@ type RigSettings struct {
 	// Overrides TownSettings.RoleAgents for this specific rig.
 	// Example: {"witness": "claude-haiku", "polecat": "claude-sonnet"}
 	RoleAgents map[string]string `json:"role_agents,omitempty"`
+
+	// RoleFallbacks maps role names to ordered fallback agent aliases.
+	// Overrides TownSettings.RoleFallbacks for this specific rig.
+	RoleFallbacks map[string][]string `json:"role_fallbacks,omitempty"`
+
+	// ProtectedPaths lists path globs agents must not change without a
+	// human's sign-off (e.g. "migrations/**", ".github/workflows/*").
+	// Shown to agents by 'gt orient'.
+	ProtectedPaths []string `json:"protected_paths,omitempty"`
+
+	// Conventions configures branch-name and commit-message conventions,
+	// applied when branches are created and enforced by the refinery.
+	Conventions *ConventionsConfig `json:"conventions,omitempty"`
+
+	// Guardrails flags or rejects MRs that add oversized files, generated
+	// or vendored code outside allowed paths, or secrets. Enforced by the
+	// refinery.
+	Guardrails *GuardrailsConfig `json:"guardrails,omitempty"`
+
+	// CodeOwners routes MRs touching owned paths to their owners for
+	// review, and holds them until every owner approves.
+	CodeOwners *CodeOwnersConfig `json:"code_owners,omitempty"`
+
+	// FileLocks sets how overlapping soft locks on files are handled when
+	// agents declare the paths they'll change as they claim work.
+	FileLocks *FileLocksConfig `json:"file_locks,omitempty"`
+
+	// Fields defines the custom fields the rig's beads may carry, keyed by
+	// field name (e.g. "customer", "component", "severity"). Set with
+	// 'gt field set'.
+	Fields map[string]*CustomFieldConfig `json:"fields,omitempty"`
+}
+
+// CustomFieldConfig defines one custom field.
+type CustomFieldConfig struct {
+	// Type is "enum", "number", "string", or "url".
+	Type string `json:"type"`
+
+	// Values lists the allowed values of an enum field.
+	Values []string `json:"values,omitempty"`
+
+	// Description says what the field is for.
+	Description string `json:"description,omitempty"`
+}
+
+// ConventionsConfig holds a rig's branch and commit conventions.
+type ConventionsConfig struct {
+	// BranchTemplate names new polecat branches. Same variables as the
+	// polecat_branch_template rig config, which it overrides:
+	// {user}, {year}, {month}, {name}, {issue}, {description}, {timestamp}.
+	BranchTemplate string `json:"branch_template,omitempty"`
+
+	// BranchPattern is a regexp every MR branch must match. "{issue}" in it
+	// matches the MR's source issue, with or without its prefix.
+	// Example: "^polecat/[a-z0-9-]+/{issue}(@[a-z0-9]+)?$"
+	BranchPattern string `json:"branch_pattern,omitempty"`
+
+	// CommitStyle is "conventional" to require Conventional Commits
+	// subjects ("type(scope): description"), or empty for no subject rule.
+	CommitStyle string `json:"commit_style,omitempty"`
+
+	// CommitTypes are the allowed conventional commit types.
+	// Default: feat, fix, docs, style, refactor, perf, test, build, ci, chore, revert.
+	CommitTypes []string `json:"commit_types,omitempty"`
+
+	// IssueTrailer, when set, requires each commit to carry a trailer with
+	// this key naming the MR's source issue (e.g. "Issue" for "Issue: gt-abc").
+	IssueTrailer string `json:"issue_trailer,omitempty"`
+}
+
+// CodeOwnersConfig configures review routing by path ownership.
+type CodeOwnersConfig struct {
+	// File is the ownership file in the repository, in CODEOWNERS syntax.
+	// Default: the first of .gt/CODEOWNERS, CODEOWNERS, .github/CODEOWNERS,
+	// and docs/CODEOWNERS on the MR's target branch.
+	File string `json:"file,omitempty"`
+
+	// Reviewers maps owners as written in the file (e.g. "@acme/payments")
+	// to the gt reviewer or role that reviews for them (e.g.
+	// "gastown/crew/pay", or "gastown/crew" for any crew member). Owners
+	// without a mapping are used as written.
+	Reviewers map[string]string `json:"reviewers,omitempty"`
+}
+
+// FileLocksConfig configures file-level soft locks.
+type FileLocksConfig struct {
+	// Policy is what a claim overlapping another's locked paths does:
+	// "warn" (the default) takes the lock anyway and warns both sides,
+	// "queue" waits in line for the paths and refuses the claim until
+	// they're released.
+	Policy string `json:"policy,omitempty"`
+
+	// TTL is how long a claim's locks last before expiring unreleased, as
+	// a duration (e.g. "8h"). Re-claiming refreshes them. Default: 24h.
+	TTL string `json:"ttl,omitempty"`
+}
+
+// GuardrailsConfig holds a rig's file guardrails.
+type GuardrailsConfig struct {
+	// Actions maps a guardrail to what tripping it does: "reject" (the
+	// default) fails the MR, "warn" flags it on the MR bead, and "off"
+	// disables the check. Guardrails: large-file, generated-code,
+	// forbidden-file, secret.
+	Actions map[string]string `json:"actions,omitempty"`
+
+	// MaxFileSize is the largest file an MR may add or grow to, e.g.
+	// "5MB". Default: 10MB.
+	MaxFileSize string `json:"max_file_size,omitempty"`
+
+	// GeneratedPaths are globs where generated or vendored code may be
+	// committed (e.g. "vendor/**", "**/*.pb.go"). Default: none.
+	GeneratedPaths []string `json:"generated_paths,omitempty"`
+
+	// ForbiddenPaths are globs of files that must never be committed.
+	// Default: .env files, private keys, and keystores.
+	ForbiddenPaths []string `json:"forbidden_paths,omitempty"`
+
+	// SecretAllowPaths are globs excluded from secret scanning, such as
+	// test fixtures with fake credentials.
+	SecretAllowPaths []string `json:"secret_allow_paths,omitempty"`
+}
+
+// RateLimitConfig is a token bucket shared by every agent on one provider.
+type RateLimitConfig struct {
+	// RequestsPerMinute is the sustained rate the bucket refills at.
+	RequestsPerMinute int `json:"requests_per_minute"`
+
+	// Burst is the bucket size: requests allowed at once after idling.
+	// Default: RequestsPerMinute / 4 (at least 1).
+	Burst int `json:"burst,omitempty"`
+
+	// MaxWait caps how long one request waits for a token before it is let
+	// through anyway (and counted as throttled). Keep it under the runtime's
+	// hook timeout. Default: "30s".
+	MaxWait string `json:"max_wait,omitempty"`
+}
+
+// AgentLimitConfig caps use of one agent alias.
+type AgentLimitConfig struct {
+	// MaxStartsPerHour caps session starts on the agent in any rolling hour.
+	// Once reached, starts route to the role's fallbacks. 0 means unlimited.
+	MaxStartsPerHour int `json:"max_starts_per_hour,omitempty"`
+
+	// Cooldown is how long the agent is skipped after a session hits a
+	// provider rate limit (HTTP 429). Default: "10m".
+	Cooldown string `json:"cooldown,omitempty"`
 }
 
 // CrewConfig represents crew workspace settings for a rig.
@@ -383,6 +851,11 @@ type RuntimeConfig struct {
 	// Default: "arg" for claude/generic, "none" for codex.
 	PromptMode string `json:"prompt_mode,omitempty"`
 
+	// SystemPromptFlag is the flag that passes a rendered role prompt to the
+	// runtime as (part of) its system prompt.
+	// Default: "--append-system-prompt" for claude, empty (unsupported) otherwise.
+	SystemPromptFlag string `json:"system_prompt_flag,omitempty"`
+
 	// Session config controls environment integration for runtime session IDs.
 	Session *RuntimeSessionConfig `json:"session,omitempty"`
 
@@ -485,6 +958,27 @@ func (rc *RuntimeConfig) BuildCommandWithPrompt(prompt string) string {
 	return base + " " + quoteForShell(p)
 }
 
+// BuildArgsWithSystemPrompt returns the runtime command and args for exec,
+// passing system via SystemPromptFlag ahead of the initial prompt.
+// It fails if the runtime has no system prompt flag.
+func (rc *RuntimeConfig) BuildArgsWithSystemPrompt(system, prompt string) ([]string, error) {
+	resolved := normalizeRuntimeConfig(rc)
+	if resolved.SystemPromptFlag == "" {
+		return nil, fmt.Errorf("runtime %q has no system prompt flag (set system_prompt_flag in its agent config)", resolved.Command)
+	}
+	args := append([]string{resolved.Command}, resolved.Args...)
+	args = append(args, resolved.SystemPromptFlag, system)
+
+	p := prompt
+	if p == "" {
+		p = resolved.InitialPrompt
+	}
+	if p != "" && resolved.PromptMode != "none" {
+		args = append(args, p)
+	}
+	return args, nil
+}
+
 // BuildArgsWithPrompt returns the runtime command and args suitable for exec.
 func (rc *RuntimeConfig) BuildArgsWithPrompt(prompt string) []string {
 	resolved := normalizeRuntimeConfig(rc)
@@ -545,6 +1039,10 @@ func normalizeRuntimeConfig(rc *RuntimeConfig) *RuntimeConfig {
 		rc.PromptMode = defaultPromptMode(rc.Provider)
 	}
 
+	if rc.SystemPromptFlag == "" {
+		rc.SystemPromptFlag = defaultSystemPromptFlag(rc.Provider)
+	}
+
 	if rc.Session == nil {
 		rc.Session = &RuntimeSessionConfig{}
 	}
@@ -658,6 +1156,13 @@ func defaultPromptMode(provider string) string {
 	}
 }
 
+func defaultSystemPromptFlag(provider string) string {
+	if provider == "claude" {
+		return "--append-system-prompt"
+	}
+	return ""
+}
+
 func defaultSessionIDEnv(provider string) string {
 	if provider == "claude" {
 		return "CLAUDE_SESSION_ID"
@@ -845,19 +1350,244 @@ type MergeQueueConfig struct {
 	// RetryFlakyTests is the number of times to retry flaky tests.
 	RetryFlakyTests int `json:"retry_flaky_tests"`
 
+	// TestOutputFormat is how test failures are parsed for triage:
+	// "go" (default), "junit", or "regex".
+	TestOutputFormat string `json:"test_output_format,omitempty"`
+
+	// TestFailurePattern is the regex used when TestOutputFormat is "regex".
+	// It must have a named group (?P<test>...); package and message are optional.
+	TestFailurePattern string `json:"test_failure_pattern,omitempty"`
+
+	// TestReport is the JUnit XML report path (relative to the clone) read
+	// when TestOutputFormat is "junit".
+	TestReport string `json:"test_report,omitempty"`
+
+	// FileFlakeBeads files a bug bead for tests classified as flaky.
+	FileFlakeBeads bool `json:"file_flake_beads,omitempty"`
+
+	// FlakeBeadThreshold is how many MRs a test must be flaky on, within
+	// 30 days, before FileFlakeBeads files a bead for it. Default: 3.
+	FlakeBeadThreshold int `json:"flake_bead_threshold,omitempty"`
+
+	// QuarantinePolicy controls how failures of tests in the rig's quarantine
+	// registry (settings/quarantine.json) are handled: "retry" (default) gives
+	// runs failing only on quarantined tests extra attempts, "skip" ignores
+	// those failures, "off" disables the registry.
+	QuarantinePolicy string `json:"quarantine_policy,omitempty"`
+
+	// QuarantineRetries is the attempt budget under the "retry" policy. Default: 3.
+	QuarantineRetries int `json:"quarantine_retries,omitempty"`
+
+	// CoverageCommand, if set, is run after tests to measure total coverage.
+	// Its output must contain the total as a percentage, e.g. the last line of
+	// "go tool cover -func" ("total: (statements) 78.4%").
+	CoverageCommand string `json:"coverage_command,omitempty"`
+
+	// CoveragePattern is an optional regex whose first capture group is the
+	// total coverage percentage. Default: the last "NN.N%" in the output.
+	CoveragePattern string `json:"coverage_pattern,omitempty"`
+
+	// SemanticSummary attaches a structured change summary (files, symbols
+	// touched, Go API changes) to each MR bead for reviewers.
+	SemanticSummary bool `json:"semantic_summary,omitempty"`
+
+	// FreezeWindows are recurring windows when only hotfixes merge, e.g.
+	// "Fri 18:00-Mon 08:00" (weekly) or "22:00-06:00" (daily), in local time.
+	FreezeWindows []string `json:"freeze_windows,omitempty"`
+
+	// Targets are policies for MRs into branches other than the default,
+	// such as release branches, keyed by branch name or glob pattern
+	// (e.g. "release-*"). The most specific match applies.
+	Targets map[string]TargetPolicy `json:"targets,omitempty"`
+
+	// CanaryBranch enables canary merges: each MR is merged to this branch
+	// first and promoted to the target only after verification passes.
+	CanaryBranch string `json:"canary_branch,omitempty"`
+
+	// CanaryVerifyCommand verifies a canary merge (exit 0 promotes). It runs
+	// in the refinery worktree with GT_CANARY_BRANCH and GT_CANARY_COMMIT set.
+	// Empty waits for a pass/fail signal from 'gt mq canary' or its webhook.
+	CanaryVerifyCommand string `json:"canary_verify_command,omitempty"`
+
+	// CanaryTimeout bounds the soak; a canary without a verdict by then is
+	// reverted (e.g., "30m"). Default: "30m".
+	CanaryTimeout string `json:"canary_timeout,omitempty"`
+
+	// PostMergeCommand is a smoke check run against each landing (in a
+	// scratch worktree at the merge commit, with GT_MERGE_COMMIT and GT_MR
+	// set). The daemon runs it shortly after the merge.
+	PostMergeCommand string `json:"post_merge_command,omitempty"`
+
+	// PostMergeHealthURL is polled (GET, 2xx is healthy) throughout the
+	// post-merge window after each landing.
+	PostMergeHealthURL string `json:"post_merge_health_url,omitempty"`
+
+	// PostMergeWindow is how long after a merge failures are attributed to
+	// the landing (e.g., "30m"). Default: "30m".
+	PostMergeWindow string `json:"post_merge_window,omitempty"`
+
+	// PostMergeAutoRevert reverts a landing that fails post-merge
+	// verification through 'gt mq revert'. The queue is frozen either way.
+	PostMergeAutoRevert bool `json:"post_merge_auto_revert,omitempty"`
+
+	// RequireReview holds new MRs in the "review requested" state until a
+	// reviewer approves them with 'gt mq review'.
+	RequireReview bool `json:"require_review,omitempty"`
+
+	// ForgeCommentCommand mirrors MR discussion to a forge (e.g. a script
+	// calling 'gh pr comment'). It runs for each new 'gt mq comment' with
+	// the comment as JSON on stdin and GT_MR, GT_MR_BRANCH, and
+	// GT_COMMENT_ID set. Mirroring failures are reported but don't fail
+	// the comment.
+	ForgeCommentCommand string `json:"forge_comment_command,omitempty"`
+
+	// PublishCIStatus publishes each MR's progress through the queue as a
+	// "gastown/merge-queue" commit status on its head commit, and its
+	// convoy's progress as "gastown/convoy", via 'gh api'. Branch protection
+	// can then require the refinery's verdict.
+	PublishCIStatus bool `json:"publish_ci_status,omitempty"`
+
+	// SLO sets how long an MR may sit in each queue state before the daemon
+	// raises an alert: "awaiting_tests" (the refinery is testing it),
+	// "awaiting_review", and "awaiting_merge" (queued, ready to land), e.g.
+	// {"awaiting_review": "4h", "awaiting_merge": "1h"}. Unset states have
+	// no SLO.
+	SLO map[string]string `json:"slo,omitempty"`
+
 	// PollInterval is how often to poll for new merge requests (e.g., "30s").
 	PollInterval string `json:"poll_interval"`
 
-	// MaxConcurrent is the maximum number of concurrent merges.
+	// MaxConcurrent is how many MRs per target are validated at once,
+	// each against the predicted state of its target; landings stay serial.
 	MaxConcurrent int `json:"max_concurrent"`
 
+	// WorktreePool is how many scratch worktrees each clone keeps warm for
+	// trial merges, picks, and test runs (default 2); 0 creates and
+	// removes one per operation.
+	WorktreePool *int `json:"worktree_pool,omitempty"`
+
 	// PRChecksTimeout is max wait for CI checks (e.g., "15m"). Default: "15m".
 	PRChecksTimeout string `json:"pr_checks_timeout,omitempty"`
 
-	// PRMergeMethod is "squash" (default), "merge", or "rebase".
+	// PRMergeMethod is the landing strategy: "squash" (default) lands one
+	// commit per MR with a message generated from the source issue,
+	// "merge" lands a merge commit, and "rebase" rebases the branch's
+	// commits onto the target.
+	PRMergeMethod string `json:"pr_merge_method,omitempty"`
+
+	// CommitSubject and CommitBody are Go templates for the
+	// message of squash and merge landings, over the MR and its source
+	// issue: {{.MR}}, {{.Branch}}, {{.Target}}, {{.Worker}}, {{.PR}},
+	// {{.Issue}}, {{.IssueTitle}}, {{.IssueType}}, {{.IssueSummary}}, and
+	// {{.Strategy}}. Empty uses a message generated from the issue.
+	CommitSubject string `json:"commit_subject_template,omitempty"`
+	CommitBody    string `json:"commit_body_template,omitempty"`
+
+	// Signing makes the refinery sign the commits it lands ('gt mq sign')
+	// so 'gt verify' can prove every landing went through the queue.
+	Signing *SigningConfig `json:"signing,omitempty"`
+
+	// LicenseScan gates merges on the licenses of the dependencies an MR
+	// adds ('gt mq license').
+	LicenseScan *LicenseScanConfig `json:"license_scan,omitempty"`
+
+	// SecurityScan gates merges on the static analysis findings an MR
+	// introduces ('gt mq scan').
+	SecurityScan *SecurityScanConfig `json:"security_scan,omitempty"`
+}
+
+// LicenseScanConfig configures the merge queue's license gate.
+type LicenseScanConfig struct {
+	// Command prints an SBOM (CycloneDX or SPDX JSON) of the checkout it
+	// runs in, e.g. "syft dir:. -o cyclonedx-json".
+	Command string `json:"command"`
+
+	// Allow lists the SPDX license IDs dependencies may use; globs like
+	// "BSD-*" match. Empty allows anything not denied.
+	Allow []string `json:"allow,omitempty"`
+
+	// Deny lists licenses that block a merge, even if also allowed.
+	Deny []string `json:"deny,omitempty"`
+
+	// Unknown is what happens to a dependency with no license
+	// information: "warn" (default), "block", or "allow".
+	Unknown string `json:"unknown,omitempty"`
+}
+
+// SecurityScanConfig configures the merge queue's security scan gate.
+type SecurityScanConfig struct {
+	// Scanners run in order against the MR's checkout.
+	Scanners []SecurityScanner `json:"scanners"`
+
+	// FailOn is the severity at or above which a new finding blocks the
+	// merge: "low", "medium", "high" (default), or "critical".
+	FailOn string `json:"fail_on,omitempty"`
+
+	// FileOn is the severity at or above which a new finding that doesn't
+	// block is filed as a bead when the MR lands. Empty files none.
+	FileOn string `json:"file_on,omitempty"`
+}
+
+// SecurityScanner is one static analysis or security scanner.
+type SecurityScanner struct {
+	// Name identifies the scanner. "gosec" and "semgrep" have a default
+	// command and format.
+	Name string `json:"name"`
+
+	// Command prints the scanner's findings on stdout.
+	Command string `json:"command,omitempty"`
+
+	// Format is the output format: "gosec", "semgrep", or "sarif".
+	// Defaults to the name for known scanners, else "sarif".
+	Format string `json:"format,omitempty"`
+
+	// FailOn overrides the blocking severity for this scanner.
+	FailOn string `json:"fail_on,omitempty"`
+}
+
+// TargetPolicy overrides merge queue settings for MRs into matching target
+// branches. Unset fields keep the rig's settings.
+type TargetPolicy struct {
+	// RequireReview overrides require_review.
+	RequireReview *bool `json:"require_review,omitempty"`
+
+	// RunTests overrides run_tests.
+	RunTests *bool `json:"run_tests,omitempty"`
+
+	// PRMergeMethod overrides pr_merge_method.
 	PRMergeMethod string `json:"pr_merge_method,omitempty"`
+
+	// FreezeWindows are added to the rig's freeze windows for this target.
+	FreezeWindows []string `json:"freeze_windows,omitempty"`
 }
 
+// SigningConfig configures how the refinery signs landed commits.
+type SigningConfig struct {
+	// Format is the signature format: "ssh" (default) or "openpgp".
+	Format string `json:"format,omitempty"`
+
+	// Key is the signing key: a private key file for ssh, a key ID or
+	// fingerprint for openpgp. Empty uses the refinery's own ssh key,
+	// created by 'gt refinery signing-key'.
+	Key string `json:"key,omitempty"`
+
+	// Name and Email are the committer identity landings are signed as.
+	// Default: "<rig> refinery" and "refinery@<rig>.gastown.local".
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email,omitempty"`
+
+	// AllowedSigners is the ssh allowed_signers file signatures are
+	// verified against. Empty uses the file written with the refinery's
+	// own key.
+	AllowedSigners string `json:"allowed_signers,omitempty"`
+}
+
+// Signing format constants.
+const (
+	SigningFormatSSH     = "ssh"
+	SigningFormatOpenPGP = "openpgp"
+)
+
 // OnConflict strategy constants.
 const (
 	OnConflictAssignBack = "assign_back"
@@ -880,6 +1610,69 @@ func DefaultMergeQueueConfig() *MergeQueueConfig {
 	}
 }
 
+// DepUpdatesConfig configures dependency-update detection for a rig.
+// Updates come either from a Renovate/Dependabot-style report file or, for
+// Go rigs, from `go list -m -u` run against the rig's clone.
+type DepUpdatesConfig struct {
+	// ReportPath is a JSON update report relative to the rig's clone
+	// (e.g., a Renovate --report-type=file output). If empty, gt runs its
+	// own detector for supported ecosystems.
+	ReportPath string `json:"report_path,omitempty"`
+
+	// IncludeIndirect includes indirect Go module dependencies.
+	IncludeIndirect bool `json:"include_indirect,omitempty"`
+
+	// Ignore lists dependency name globs to skip (e.g., "golang.org/x/*").
+	Ignore []string `json:"ignore,omitempty"`
+
+	// Priority is the priority for filed update beads. Default: 3.
+	Priority *int `json:"priority,omitempty"`
+
+	// AutoSling slings newly filed update beads to the rig so polecats
+	// do the bump and submit it through the merge queue.
+	AutoSling bool `json:"auto_sling,omitempty"`
+
+	// MaxAutoSling caps how many beads are slung per run. Default: 3.
+	MaxAutoSling int `json:"max_auto_sling,omitempty"`
+}
+
+// DefaultDepUpdatePriority is the bead priority used when none is configured.
+const DefaultDepUpdatePriority = 3
+
+// DefaultMaxAutoSling is the per-run sling cap used when none is configured.
+const DefaultMaxAutoSling = 3
+
+// ReleaseConfig configures how releases are cut for a rig.
+type ReleaseConfig struct {
+	// TagPrefix is prepended to versions in tag names. Default: "v".
+	TagPrefix *string `json:"tag_prefix,omitempty"`
+
+	// Branch is the branch releases are tagged from. Default: the rig's
+	// default branch.
+	Branch string `json:"branch,omitempty"`
+
+	// Pipeline is a shell command run in the rig's clone after the tag is
+	// pushed (e.g., "gh workflow run release.yml -f tag=$GT_RELEASE_TAG").
+	// It receives GT_RELEASE_VERSION, GT_RELEASE_TAG, and GT_RELEASE_NOTES
+	// (path to a file with the release notes).
+	Pipeline string `json:"pipeline,omitempty"`
+}
+
+// DefaultReleaseTagPrefix is the tag prefix used when none is configured.
+const DefaultReleaseTagPrefix = "v"
+
+// IncidentConfig configures incident response for a rig.
+type IncidentConfig struct {
+	// Responder is the agent assigned to new incidents when none is given
+	// (e.g., "gastown/crew/max").
+	Responder string `json:"responder,omitempty"`
+
+	// Page lists the actions used to page on a new incident, in the format
+	// of escalation routes ("mail:mayor", "slack", ...). Default: the town's
+	// escalation route for the incident's severity.
+	Page []string `json:"page,omitempty"`
+}
+
 // NamepoolConfig represents namepool settings for themed polecat names.
 type NamepoolConfig struct {
 	// Style picks from a built-in theme (e.g., "mad-max", "minerals", "wasteland").
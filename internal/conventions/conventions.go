@@ -0,0 +1,202 @@
+// Package conventions checks MR branches and commits against a rig's
+// branch-name and commit-message conventions.
+//
+// Consistent naming is what links merge requests back to issues: the
+// refinery rejects branches and commits that break the rig's conventions,
+// with one precise message per violation so the author knows what to fix.
+package conventions
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+// CommitStyleConventional selects Conventional Commits subjects.
+const CommitStyleConventional = "conventional"
+
+// DefaultCommitTypes are the conventional commit types allowed by default.
+var DefaultCommitTypes = []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"}
+
+// conventionalRe splits a conventional subject into type, scope, and description.
+var conventionalRe = regexp.MustCompile(`^([a-zA-Z]+)(\([^()]+\))?(!)?: (\S.*)$`)
+
+// Rules a violation can break.
+const (
+	RuleBranch  = "branch-name"
+	RuleSubject = "commit-subject"
+	RuleTrailer = "issue-trailer"
+)
+
+// Violation is one broken convention.
+type Violation struct {
+	Rule    string `json:"rule"`
+	Commit  string `json:"commit,omitempty"` // empty for branch violations
+	Message string `json:"message"`
+}
+
+// String renders the violation for an MR failure message.
+func (v Violation) String() string {
+	if v.Commit == "" {
+		return v.Message
+	}
+	return shortSHA(v.Commit) + ": " + v.Message
+}
+
+// Enabled reports whether cfg has any rule to enforce.
+func Enabled(cfg *config.ConventionsConfig) bool {
+	return cfg != nil && (cfg.BranchPattern != "" || cfg.CommitStyle != "" || cfg.IssueTrailer != "")
+}
+
+// Check returns the violations of cfg by an MR's branch and commits.
+func Check(cfg *config.ConventionsConfig, branch, issue string, commits []git.CommitMessage) ([]Violation, error) {
+	if !Enabled(cfg) {
+		return nil, nil
+	}
+	var violations []Violation
+	v, err := CheckBranch(cfg, branch, issue)
+	if err != nil {
+		return nil, err
+	}
+	if v != nil {
+		violations = append(violations, *v)
+	}
+	for _, c := range commits {
+		violations = append(violations, CheckCommit(cfg, c, issue)...)
+	}
+	return violations, nil
+}
+
+// CheckBranch checks branch against the configured pattern. It returns an
+// error only if the pattern itself is invalid.
+func CheckBranch(cfg *config.ConventionsConfig, branch, issue string) (*Violation, error) {
+	if cfg == nil || cfg.BranchPattern == "" {
+		return nil, nil
+	}
+	re, err := BranchRegexp(cfg.BranchPattern, issue)
+	if err != nil {
+		return nil, err
+	}
+	if re.MatchString(branch) {
+		return nil, nil
+	}
+	return &Violation{
+		Rule:    RuleBranch,
+		Message: fmt.Sprintf("branch %q does not match the rig's pattern %q (issue %s)", branch, cfg.BranchPattern, issue),
+	}, nil
+}
+
+// BranchRegexp compiles a branch pattern for issue, expanding "{issue}" to
+// the issue ID with or without its prefix.
+func BranchRegexp(pattern, issue string) (*regexp.Regexp, error) {
+	expanded := pattern
+	if strings.Contains(pattern, "{issue}") {
+		alts := regexp.QuoteMeta(issue)
+		if _, num, ok := strings.Cut(issue, "-"); ok && num != "" {
+			alts += "|" + regexp.QuoteMeta(num)
+		}
+		if issue == "" {
+			alts = "[^/@]+"
+		}
+		expanded = strings.ReplaceAll(pattern, "{issue}", "(?:"+alts+")")
+	}
+	re, err := regexp.Compile(expanded)
+	if err != nil {
+		return nil, fmt.Errorf("conventions.branch_pattern %q: %w", pattern, err)
+	}
+	return re, nil
+}
+
+// CheckCommit checks one commit's message.
+func CheckCommit(cfg *config.ConventionsConfig, c git.CommitMessage, issue string) []Violation {
+	if cfg == nil {
+		return nil
+	}
+	var violations []Violation
+	subject, _, _ := strings.Cut(strings.TrimSpace(c.Message), "\n")
+	subject = strings.TrimSpace(subject)
+
+	if cfg.CommitStyle == CommitStyleConventional {
+		if msg := checkConventional(subject, commitTypes(cfg)); msg != "" {
+			violations = append(violations, Violation{Rule: RuleSubject, Commit: c.SHA, Message: msg})
+		}
+	}
+
+	if cfg.IssueTrailer != "" && issue != "" {
+		values := Trailers(c.Message)[strings.ToLower(cfg.IssueTrailer)]
+		found := false
+		for _, v := range values {
+			found = found || v == issue
+		}
+		if !found {
+			msg := fmt.Sprintf("%q is missing the trailer %q", subject, cfg.IssueTrailer+": "+issue)
+			if len(values) > 0 {
+				msg = fmt.Sprintf("%q has %s: %s, want %s", subject, cfg.IssueTrailer, strings.Join(values, ", "), issue)
+			}
+			violations = append(violations, Violation{Rule: RuleTrailer, Commit: c.SHA, Message: msg})
+		}
+	}
+	return violations
+}
+
+// checkConventional returns why subject isn't a conventional commit subject,
+// or "" if it is.
+func checkConventional(subject string, types []string) string {
+	m := conventionalRe.FindStringSubmatch(subject)
+	if m == nil {
+		return fmt.Sprintf("%q is not a Conventional Commits subject; want \"<type>(<scope>): <description>\" with type one of %s",
+			subject, strings.Join(types, ", "))
+	}
+	for _, t := range types {
+		if m[1] == t {
+			return ""
+		}
+	}
+	return fmt.Sprintf("%q has type %q; want one of %s", subject, m[1], strings.Join(types, ", "))
+}
+
+func commitTypes(cfg *config.ConventionsConfig) []string {
+	if len(cfg.CommitTypes) > 0 {
+		return cfg.CommitTypes
+	}
+	return DefaultCommitTypes
+}
+
+// Trailers parses the trailers in a commit message's last paragraph, keyed
+// by lowercased trailer key. A message with only a subject has none.
+func Trailers(message string) map[string][]string {
+	trailers := make(map[string][]string)
+	paragraphs := strings.Split(strings.TrimSpace(strings.ReplaceAll(message, "\r\n", "\n")), "\n\n")
+	if len(paragraphs) < 2 {
+		return trailers
+	}
+	for _, line := range strings.Split(paragraphs[len(paragraphs)-1], "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			continue
+		}
+		trailers[strings.ToLower(key)] = append(trailers[strings.ToLower(key)], strings.TrimSpace(value))
+	}
+	return trailers
+}
+
+// Format renders violations as one failure message, one per line.
+func Format(violations []Violation) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d convention violation(s):", len(violations))
+	for _, v := range violations {
+		sb.WriteString("\n  - ")
+		sb.WriteString(v.String())
+	}
+	return sb.String()
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
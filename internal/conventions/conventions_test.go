@@ -0,0 +1,97 @@
+package conventions
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+func TestCheckBranch(t *testing.T) {
+	cfg := &config.ConventionsConfig{BranchPattern: `^polecat/[a-z0-9-]+/{issue}(@[a-z0-9]+)?$`}
+	tests := []struct {
+		branch, issue string
+		ok            bool
+	}{
+		{"polecat/nux/gt-abc@k2x9", "gt-abc", true},
+		{"polecat/nux/abc", "gt-abc", true}, // prefix stripped, as {issue} in branch templates
+		{"polecat/nux/gt-xyz", "gt-abc", false},
+		{"feature/login", "gt-abc", false},
+		{"polecat/nux/anything", "", true},
+	}
+	for _, tt := range tests {
+		v, err := CheckBranch(cfg, tt.branch, tt.issue)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (v == nil) != tt.ok {
+			t.Errorf("CheckBranch(%q, %q) = %v, want ok=%v", tt.branch, tt.issue, v, tt.ok)
+		}
+	}
+
+	if _, err := CheckBranch(&config.ConventionsConfig{BranchPattern: "("}, "x", ""); err == nil {
+		t.Error("invalid pattern should error")
+	}
+}
+
+func TestCheckCommit(t *testing.T) {
+	cfg := &config.ConventionsConfig{CommitStyle: CommitStyleConventional, IssueTrailer: "Issue"}
+	tests := []struct {
+		name    string
+		message string
+		rules   string
+	}{
+		{"compliant", "feat(auth): add cookie sessions\n\nBody.\n\nIssue: gt-abc", ""},
+		{"breaking", "fix!: drop v1 tokens\n\nIssue: gt-abc\nSigned-off-by: nux", ""},
+		{"not conventional", "Add cookie sessions\n\nIssue: gt-abc", RuleSubject},
+		{"unknown type", "feature: add cookies\n\nIssue: gt-abc", RuleSubject},
+		{"no trailer", "feat: add cookies", RuleTrailer},
+		{"wrong issue", "feat: add cookies\n\nIssue: gt-xyz", RuleTrailer},
+		{"both", "wip", RuleSubject + "," + RuleTrailer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rules []string
+			for _, v := range CheckCommit(cfg, git.CommitMessage{SHA: "0123456789abcdef", Message: tt.message}, "gt-abc") {
+				rules = append(rules, v.Rule)
+			}
+			if got := strings.Join(rules, ","); got != tt.rules {
+				t.Errorf("rules = %q, want %q", got, tt.rules)
+			}
+		})
+	}
+
+	custom := &config.ConventionsConfig{CommitStyle: CommitStyleConventional, CommitTypes: []string{"feature"}}
+	if v := CheckCommit(custom, git.CommitMessage{Message: "feature: add cookies"}, ""); len(v) != 0 {
+		t.Errorf("custom type rejected: %v", v)
+	}
+}
+
+func TestCheckAndFormat(t *testing.T) {
+	if v, _ := Check(nil, "any", "gt-abc", []git.CommitMessage{{Message: "wip"}}); v != nil {
+		t.Errorf("no conventions should mean no violations, got %v", v)
+	}
+
+	cfg := &config.ConventionsConfig{BranchPattern: "^polecat/", CommitStyle: CommitStyleConventional}
+	v, err := Check(cfg, "feature/x", "gt-abc", []git.CommitMessage{{SHA: "0123456789abcdef", Message: "wip"}, {SHA: "fedcba9876543210", Message: "fix: ok"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := Format(v)
+	for _, want := range []string{"2 convention violation(s):", `branch "feature/x" does not match`, `01234567: "wip" is not a Conventional Commits subject`} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("Format missing %q:\n%s", want, msg)
+		}
+	}
+}
+
+func TestTrailers(t *testing.T) {
+	got := Trailers("subject\n\nbody line: not a trailer? yes it is\n\nIssue: gt-abc\nIssue: gt-def\nCo-authored-by: x")
+	if strings.Join(got["issue"], ",") != "gt-abc,gt-def" || len(got["co-authored-by"]) != 1 {
+		t.Errorf("Trailers = %v", got)
+	}
+	if len(Trailers("Issue: gt-abc")) != 0 {
+		t.Error("a subject alone has no trailers")
+	}
+}
@@ -114,6 +114,18 @@ func isConvoyClosed(townRoot, convoyID string) bool {
 	return results[0].Status == "closed"
 }
 
+// Progress reports how many of a convoy's tracked issues are closed, and
+// whether the convoy itself is.
+func Progress(townRoot, convoyID string) (done, total int, closed bool) {
+	for _, t := range getConvoyTrackedIssues(townRoot, convoyID) {
+		total++
+		if t.Status == "closed" {
+			done++
+		}
+	}
+	return done, total, isConvoyClosed(townRoot, convoyID)
+}
+
 // runConvoyCheck runs `gt convoy check <convoy-id>` to check a specific convoy.
 // This is idempotent and handles already-closed convoys gracefully.
 func runConvoyCheck(townRoot, convoyID string) error {
@@ -0,0 +1,338 @@
+// Package ctxbundle assembles everything an agent needs to pick up an issue
+// into one structured bundle, for injection into the agent's prompt.
+//
+// A bundle carries the issue itself, its comments, dependency status, the
+// merge requests filed for it, relevant file paths (from path: labels and
+// the files its merged MRs touched), recent related commits, and handoff
+// notes that mention it, and files attached to it.
+package ctxbundle
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/diskusage"
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+// MaxFiles caps the relevant file paths listed in a bundle.
+const MaxFiles = 50
+
+// pathLabelPrefixes mark labels that name relevant paths (e.g. "path:internal/auth").
+var pathLabelPrefixes = []string{"path:", "file:"}
+
+// Bundle is the context for one issue.
+type Bundle struct {
+	Issue         Issue            `json:"issue"`
+	Comments      []*beads.Comment `json:"comments,omitempty"`
+	Dependencies  []Link           `json:"dependencies,omitempty"`
+	Dependents    []Link           `json:"dependents,omitempty"`
+	Blocked       bool             `json:"blocked"`
+	MergeRequests []MergeRequest   `json:"merge_requests,omitempty"`
+	Files         []string         `json:"files,omitempty"`
+	Commits       []git.CommitInfo `json:"commits,omitempty"`
+	Handoffs      []Handoff        `json:"handoffs,omitempty"`
+	Decisions     []Decision       `json:"decisions,omitempty"`
+	Attachments   []Attachment     `json:"attachments,omitempty"`
+	GeneratedAt   time.Time        `json:"generated_at"`
+}
+
+// Issue is the subject of a bundle.
+type Issue struct {
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Type        string   `json:"type,omitempty"`
+	Status      string   `json:"status"`
+	Priority    int      `json:"priority"`
+	Assignee    string   `json:"assignee,omitempty"`
+	Parent      string   `json:"parent,omitempty"`
+	Labels      []string `json:"labels,omitempty"`
+	Description string   `json:"description,omitempty"`
+	CreatedAt   string   `json:"created_at,omitempty"`
+}
+
+// Link is a dependency or dependent of the issue.
+type Link struct {
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Status string `json:"status"`
+	Kind   string `json:"kind,omitempty"` // blocks, parent-child, related, ...
+}
+
+// MergeRequest is an MR filed for the issue.
+type MergeRequest struct {
+	ID          string `json:"id"`
+	Status      string `json:"status"`
+	Branch      string `json:"branch"`
+	Target      string `json:"target,omitempty"`
+	MergeCommit string `json:"merge_commit,omitempty"`
+	CloseReason string `json:"close_reason,omitempty"`
+	Review      string `json:"review,omitempty"`
+}
+
+// Merged reports whether the MR landed.
+func (m MergeRequest) Merged() bool {
+	return m.Status == "closed" && m.MergeCommit != ""
+}
+
+// Handoff is a handoff note that mentions the issue.
+type Handoff struct {
+	From    string `json:"from"`
+	Subject string `json:"subject"`
+	Date    string `json:"date,omitempty"`
+	Body    string `json:"body"`
+}
+
+// Decision is a memo from the town's decision log relevant to the issue.
+type Decision struct {
+	ID     string   `json:"id"`
+	Title  string   `json:"title"`
+	Topics []string `json:"topics,omitempty"`
+	Body   string   `json:"body"`
+}
+
+// Attachment is a file attached to the issue with 'gt attach'.
+type Attachment struct {
+	Name   string `json:"name"`
+	Digest string `json:"digest"`
+	Size   int64  `json:"size"`
+	Type   string `json:"type,omitempty"`
+	Path   string `json:"path,omitempty"` // Local file, when the store is on disk
+}
+
+// New starts a bundle for issue, with its dependency status filled in.
+func New(issue *beads.Issue, now time.Time) *Bundle {
+	b := &Bundle{
+		Issue: Issue{
+			ID:          issue.ID,
+			Title:       issue.Title,
+			Type:        issue.Type,
+			Status:      issue.Status,
+			Priority:    issue.Priority,
+			Assignee:    issue.Assignee,
+			Parent:      issue.Parent,
+			Labels:      issue.Labels,
+			Description: issue.Description,
+			CreatedAt:   issue.CreatedAt,
+		},
+		GeneratedAt: now.UTC(),
+	}
+	for _, d := range issue.Dependencies {
+		b.Dependencies = append(b.Dependencies, Link{ID: d.ID, Title: d.Title, Status: d.Status, Kind: d.DependencyType})
+		if blocking(d) {
+			b.Blocked = true
+		}
+	}
+	for _, d := range issue.Dependents {
+		b.Dependents = append(b.Dependents, Link{ID: d.ID, Title: d.Title, Status: d.Status, Kind: d.DependencyType})
+	}
+	b.AddFiles(PathsFromLabels(issue.Labels)...)
+	return b
+}
+
+// blocking reports whether a dependency still blocks work.
+func blocking(d beads.IssueDep) bool {
+	if d.Status == "closed" {
+		return false
+	}
+	return d.DependencyType == "" || d.DependencyType == "blocks"
+}
+
+// PathsFromLabels returns the paths named by path: and file: labels.
+func PathsFromLabels(labels []string) []string {
+	var paths []string
+	for _, l := range labels {
+		for _, prefix := range pathLabelPrefixes {
+			if p := strings.TrimPrefix(l, prefix); p != l && p != "" {
+				paths = append(paths, p)
+			}
+		}
+	}
+	return paths
+}
+
+// MergeRequestsFor returns the MRs among mrs whose source issue is issueID,
+// in the order given.
+func MergeRequestsFor(issueID string, mrs []*beads.Issue) []MergeRequest {
+	var out []MergeRequest
+	for _, mr := range mrs {
+		fields := beads.ParseMRFields(mr)
+		if fields == nil || fields.SourceIssue != issueID {
+			continue
+		}
+		closeReason := mr.CloseReason
+		if closeReason == "" {
+			closeReason = fields.CloseReason
+		}
+		out = append(out, MergeRequest{
+			ID:          mr.ID,
+			Status:      mr.Status,
+			Branch:      fields.Branch,
+			Target:      fields.Target,
+			MergeCommit: fields.MergeCommit,
+			CloseReason: closeReason,
+			Review:      fields.Review,
+		})
+	}
+	return out
+}
+
+// AddFiles adds relevant paths, skipping duplicates, up to MaxFiles.
+func (b *Bundle) AddFiles(paths ...string) {
+	seen := make(map[string]bool, len(b.Files))
+	for _, p := range b.Files {
+		seen[p] = true
+	}
+	for _, p := range paths {
+		if len(b.Files) >= MaxFiles {
+			return
+		}
+		if p == "" || seen[p] {
+			continue
+		}
+		seen[p] = true
+		b.Files = append(b.Files, p)
+	}
+}
+
+// AddHandoffs keeps the handoff notes that mention the issue.
+func (b *Bundle) AddHandoffs(notes []Handoff) {
+	for _, h := range notes {
+		if strings.Contains(h.Subject, b.Issue.ID) || strings.Contains(h.Body, b.Issue.ID) {
+			b.Handoffs = append(b.Handoffs, h)
+		}
+	}
+}
+
+// Markdown renders the bundle for a prompt.
+func (b *Bundle) Markdown() string {
+	var sb strings.Builder
+	is := b.Issue
+	fmt.Fprintf(&sb, "# %s: %s\n\n", is.ID, is.Title)
+	fmt.Fprintf(&sb, "- Status: %s (P%d", is.Status, is.Priority)
+	if is.Type != "" {
+		fmt.Fprintf(&sb, ", %s", is.Type)
+	}
+	sb.WriteString(")\n")
+	if is.Assignee != "" {
+		fmt.Fprintf(&sb, "- Assignee: %s\n", is.Assignee)
+	}
+	if is.Parent != "" {
+		fmt.Fprintf(&sb, "- Parent: %s\n", is.Parent)
+	}
+	if len(is.Labels) > 0 {
+		fmt.Fprintf(&sb, "- Labels: %s\n", strings.Join(is.Labels, ", "))
+	}
+	if b.Blocked {
+		sb.WriteString("- **Blocked** by an open dependency\n")
+	}
+	if is.Description != "" {
+		fmt.Fprintf(&sb, "\n## Description\n\n%s\n", strings.TrimSpace(is.Description))
+	}
+
+	if len(b.Dependencies) > 0 || len(b.Dependents) > 0 {
+		sb.WriteString("\n## Dependencies\n\n")
+		for _, d := range b.Dependencies {
+			fmt.Fprintf(&sb, "- depends on %s [%s] %s%s\n", d.ID, d.Status, d.Title, kindSuffix(d.Kind))
+		}
+		for _, d := range b.Dependents {
+			fmt.Fprintf(&sb, "- needed by %s [%s] %s%s\n", d.ID, d.Status, d.Title, kindSuffix(d.Kind))
+		}
+	}
+
+	if len(b.Comments) > 0 {
+		sb.WriteString("\n## Comments\n\n")
+		for _, c := range b.Comments {
+			fmt.Fprintf(&sb, "**%s** (%s):\n%s\n\n", c.Author, c.CreatedAt, strings.TrimSpace(c.Text))
+		}
+	}
+
+	if len(b.MergeRequests) > 0 {
+		sb.WriteString("\n## Merge requests\n\n")
+		for _, mr := range b.MergeRequests {
+			fmt.Fprintf(&sb, "- %s [%s] %s", mr.ID, mr.Status, mr.Branch)
+			if mr.Target != "" {
+				fmt.Fprintf(&sb, " → %s", mr.Target)
+			}
+			if mr.Merged() {
+				fmt.Fprintf(&sb, " (merged %s)", shortSHA(mr.MergeCommit))
+			} else if mr.CloseReason != "" {
+				fmt.Fprintf(&sb, " (%s)", mr.CloseReason)
+			}
+			if mr.Review != "" {
+				fmt.Fprintf(&sb, " review: %s", mr.Review)
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	if len(b.Files) > 0 {
+		sb.WriteString("\n## Relevant files\n\n")
+		for _, f := range b.Files {
+			fmt.Fprintf(&sb, "- %s\n", f)
+		}
+	}
+
+	if len(b.Commits) > 0 {
+		sb.WriteString("\n## Related commits\n\n")
+		for _, c := range b.Commits {
+			fmt.Fprintf(&sb, "- %s %s (%s)\n", shortSHA(c.SHA), c.Subject, c.Author)
+		}
+	}
+
+	if len(b.Handoffs) > 0 {
+		sb.WriteString("\n## Handoff notes\n\n")
+		for _, h := range b.Handoffs {
+			fmt.Fprintf(&sb, "### %s\nFrom %s", h.Subject, h.From)
+			if h.Date != "" {
+				fmt.Fprintf(&sb, ", %s", h.Date)
+			}
+			fmt.Fprintf(&sb, "\n\n%s\n\n", strings.TrimSpace(h.Body))
+		}
+	}
+
+	if len(b.Decisions) > 0 {
+		sb.WriteString("\n## Decisions\n\n")
+		for _, d := range b.Decisions {
+			fmt.Fprintf(&sb, "### %s\n%s", d.Title, d.ID)
+			if len(d.Topics) > 0 {
+				fmt.Fprintf(&sb, " (%s)", strings.Join(d.Topics, ", "))
+			}
+			fmt.Fprintf(&sb, "\n\n%s\n\n", strings.TrimSpace(d.Body))
+		}
+	}
+
+	if len(b.Attachments) > 0 {
+		sb.WriteString("\n## Attachments\n\n")
+		for _, a := range b.Attachments {
+			fmt.Fprintf(&sb, "- %s (%s", a.Name, diskusage.FormatSize(a.Size))
+			if a.Type != "" {
+				fmt.Fprintf(&sb, ", %s", a.Type)
+			}
+			sb.WriteString(")")
+			if a.Path != "" {
+				fmt.Fprintf(&sb, ": %s", a.Path)
+			} else {
+				fmt.Fprintf(&sb, ": `gt show %s --attachment %s`", b.Issue.ID, a.Name)
+			}
+			sb.WriteString("\n")
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n") + "\n"
+}
+
+func kindSuffix(kind string) string {
+	if kind == "" || kind == "blocks" {
+		return ""
+	}
+	return " (" + kind + ")"
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
@@ -0,0 +1,125 @@
+package ctxbundle
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+func TestNew(t *testing.T) {
+	issue := &beads.Issue{
+		ID:     "gt-abc",
+		Title:  "Fix login",
+		Status: "open",
+		Labels: []string{"bug", "path:internal/auth", "file:cmd/login.go"},
+		Dependencies: []beads.IssueDep{
+			{ID: "gt-dep1", Status: "closed", DependencyType: "blocks"},
+			{ID: "gt-epic", Status: "open", DependencyType: "parent-child"},
+		},
+	}
+	b := New(issue, time.Now())
+	if b.Blocked {
+		t.Error("Blocked = true with only a closed blocker and a parent")
+	}
+	if want := []string{"internal/auth", "cmd/login.go"}; strings.Join(b.Files, ",") != strings.Join(want, ",") {
+		t.Errorf("Files = %v, want %v", b.Files, want)
+	}
+
+	issue.Dependencies = append(issue.Dependencies, beads.IssueDep{ID: "gt-dep2", Status: "in_progress", DependencyType: "blocks"})
+	if !New(issue, time.Now()).Blocked {
+		t.Error("Blocked = false with an open blocker")
+	}
+}
+
+func TestMergeRequestsFor(t *testing.T) {
+	mrs := []*beads.Issue{
+		{ID: "gt-mr1", Status: "closed", CloseReason: "rejected: flaky", Description: "branch: polecat/nux/gt-abc\nsource_issue: gt-abc"},
+		{ID: "gt-mr2", Status: "closed", Description: "branch: polecat/nux/gt-abc\ntarget: main\nsource_issue: gt-abc\nmerge_commit: 0123456789abcdef"},
+		{ID: "gt-mr3", Status: "open", Description: "branch: polecat/ace/gt-other\nsource_issue: gt-other"},
+	}
+	got := MergeRequestsFor("gt-abc", mrs)
+	if len(got) != 2 {
+		t.Fatalf("MergeRequestsFor = %+v", got)
+	}
+	if got[0].Merged() || got[0].CloseReason != "rejected: flaky" {
+		t.Errorf("rejected MR = %+v", got[0])
+	}
+	if !got[1].Merged() || got[1].Target != "main" {
+		t.Errorf("merged MR = %+v", got[1])
+	}
+}
+
+func TestAddFiles(t *testing.T) {
+	b := &Bundle{}
+	b.AddFiles("a.go", "b.go", "a.go", "")
+	if len(b.Files) != 2 {
+		t.Errorf("Files = %v, want a.go and b.go", b.Files)
+	}
+	for i := 0; i < MaxFiles*2; i++ {
+		b.AddFiles(fmt.Sprintf("f%d.go", i))
+	}
+	if len(b.Files) != MaxFiles {
+		t.Errorf("len(Files) = %d, want %d", len(b.Files), MaxFiles)
+	}
+}
+
+func TestAddHandoffs(t *testing.T) {
+	b := &Bundle{Issue: Issue{ID: "gt-abc"}}
+	b.AddHandoffs([]Handoff{
+		{Subject: "🤝 HANDOFF: gt-abc half done", Body: "tests left"},
+		{Subject: "🤝 HANDOFF: Session cycling", Body: "Working on gt-abc; auth.go next"},
+		{Subject: "🤝 HANDOFF: Session cycling", Body: "Working on gt-xyz"},
+	})
+	if len(b.Handoffs) != 2 {
+		t.Errorf("Handoffs = %+v, want the two mentioning gt-abc", b.Handoffs)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	b := New(&beads.Issue{
+		ID:          "gt-abc",
+		Title:       "Fix login",
+		Status:      "open",
+		Priority:    1,
+		Description: "Login fails on Safari.",
+		Dependencies: []beads.IssueDep{
+			{ID: "gt-dep", Title: "Session store", Status: "open", DependencyType: "blocks"},
+		},
+	}, time.Now())
+	b.Comments = []*beads.Comment{{Author: "mayor", Text: "Repro on 17.2", CreatedAt: "2026-01-02"}}
+	b.MergeRequests = []MergeRequest{{ID: "gt-mr2", Status: "closed", Branch: "polecat/nux/gt-abc", Target: "main", MergeCommit: "0123456789abcdef"}}
+	b.Files = []string{"internal/auth/login.go"}
+	b.Commits = []git.CommitInfo{{SHA: "fedcba9876543210", Subject: "gt-abc: first pass", Author: "nux"}}
+	b.Handoffs = []Handoff{{From: "gastown/polecats/nux", Subject: "HANDOFF gt-abc", Body: "Cookie path next"}}
+	b.Decisions = []Decision{{ID: "memo-20260101-cookie-sessions", Title: "Cookie sessions", Topics: []string{"auth"}, Body: "No JWTs in localStorage."}}
+	b.Attachments = []Attachment{
+		{Name: "crash.log", Size: 2048, Type: "text/plain", Path: "/town/attachments/sha256/ab/abc"},
+		{Name: "shot.png", Size: 10},
+	}
+
+	got := b.Markdown()
+	for _, want := range []string{
+		"# gt-abc: Fix login",
+		"**Blocked**",
+		"Login fails on Safari.",
+		"- depends on gt-dep [open] Session store",
+		"Repro on 17.2",
+		"- gt-mr2 [closed] polecat/nux/gt-abc → main (merged 01234567)",
+		"- internal/auth/login.go",
+		"- fedcba98 gt-abc: first pass (nux)",
+		"Cookie path next",
+		"## Decisions",
+		"memo-20260101-cookie-sessions (auth)",
+		"No JWTs in localStorage.",
+		"- crash.log (2.0 KB, text/plain): /town/attachments/sha256/ab/abc",
+		"- shot.png (10 B): `gt show gt-abc --attachment shot.png`",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Markdown missing %q:\n%s", want, got)
+		}
+	}
+}
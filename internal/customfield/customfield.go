@@ -0,0 +1,175 @@
+// Package customfield checks custom field values against a rig's schema
+// (see config.CustomFieldConfig) and filters issues by them.
+package customfield
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// Field types.
+const (
+	TypeEnum   = "enum"
+	TypeNumber = "number"
+	TypeString = "string"
+	TypeURL    = "url"
+)
+
+// Schema is a rig's custom field definitions, keyed by name.
+type Schema map[string]*config.CustomFieldConfig
+
+// validName matches a field name; names become part of labels.
+var validName = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// Load returns the custom fields defined in a rig's settings, checked. A
+// rig with no settings has no fields.
+func Load(rigPath string) (Schema, error) {
+	settings, err := config.LoadRigSettings(config.RigSettingsPath(rigPath))
+	if err != nil {
+		if errors.Is(err, config.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	schema := Schema(settings.Fields)
+	if err := schema.Check(); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// Names returns the schema's field names, sorted.
+func (s Schema) Names() []string {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Check validates the field definitions themselves.
+func (s Schema) Check() error {
+	for _, name := range s.Names() {
+		def := s[name]
+		if !validName.MatchString(name) {
+			return fmt.Errorf("field %q: name must be lowercase letters, digits, '_' or '-'", name)
+		}
+		if def == nil {
+			return fmt.Errorf("field %q: no definition", name)
+		}
+		switch def.Type {
+		case TypeEnum:
+			if len(def.Values) == 0 {
+				return fmt.Errorf("field %q: enum needs values", name)
+			}
+			for _, v := range def.Values {
+				if err := checkText(v); err != nil {
+					return fmt.Errorf("field %q: value %q: %w", name, v, err)
+				}
+			}
+		case TypeNumber, TypeString, TypeURL:
+		default:
+			return fmt.Errorf("field %q: unknown type %q (want enum, number, string, or url)", name, def.Type)
+		}
+	}
+	return nil
+}
+
+// Normalize checks a value against the named field's definition and
+// returns it in canonical form (numbers without trailing zeros, enum
+// values as defined).
+func (s Schema) Normalize(name, value string) (string, error) {
+	def, ok := s[name]
+	if !ok || def == nil {
+		if len(s) == 0 {
+			return "", fmt.Errorf("unknown field %q: no custom fields defined", name)
+		}
+		return "", fmt.Errorf("unknown field %q (have: %s)", name, strings.Join(s.Names(), ", "))
+	}
+	value = strings.TrimSpace(value)
+	if err := checkText(value); err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	switch def.Type {
+	case TypeEnum:
+		for _, v := range def.Values {
+			if strings.EqualFold(v, value) {
+				return v, nil
+			}
+		}
+		return "", fmt.Errorf("%s: %q is not one of %s", name, value, strings.Join(def.Values, ", "))
+	case TypeNumber:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "", fmt.Errorf("%s: %q is not a number", name, value)
+		}
+		return strconv.FormatFloat(n, 'f', -1, 64), nil
+	case TypeURL:
+		u, err := url.Parse(value)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return "", fmt.Errorf("%s: %q is not an http(s) URL", name, value)
+		}
+		return value, nil
+	}
+	return value, nil
+}
+
+// checkText rejects values that can't live in a label.
+func checkText(v string) error {
+	switch {
+	case v == "":
+		return fmt.Errorf("empty value")
+	case strings.ContainsAny(v, ",\n"):
+		return fmt.Errorf("value can't contain commas or newlines")
+	}
+	return nil
+}
+
+// Filter matches issues whose field has a value.
+type Filter struct {
+	Name  string
+	Value string
+}
+
+// ParseFilters parses "name=value" filter arguments.
+func ParseFilters(args []string) ([]Filter, error) {
+	filters := make([]Filter, 0, len(args))
+	for _, arg := range args {
+		name, value, ok := strings.Cut(arg, "=")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !ok || name == "" || value == "" {
+			return nil, fmt.Errorf("invalid field filter %q: want name=value", arg)
+		}
+		filters = append(filters, Filter{Name: name, Value: value})
+	}
+	return filters, nil
+}
+
+// Match reports whether field values satisfy every filter. Numbers
+// compare numerically and enum values case-insensitively.
+func Match(fields map[string]string, filters []Filter) bool {
+	for _, f := range filters {
+		v, ok := fields[f.Name]
+		if !ok || !equal(v, f.Value) {
+			return false
+		}
+	}
+	return true
+}
+
+func equal(have, want string) bool {
+	if strings.EqualFold(have, want) {
+		return true
+	}
+	a, errA := strconv.ParseFloat(have, 64)
+	b, errB := strconv.ParseFloat(want, 64)
+	return errA == nil && errB == nil && a == b
+}
@@ -0,0 +1,89 @@
+package customfield
+
+import "testing"
+
+var schema = Schema{
+	"severity": {Type: TypeEnum, Values: []string{"S1", "S2", "S3"}},
+	"customer": {Type: TypeString},
+	"cost":     {Type: TypeNumber},
+	"docs":     {Type: TypeURL},
+}
+
+func TestCheck(t *testing.T) {
+	if err := schema.Check(); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	bad := []Schema{
+		{"Severity": {Type: TypeString}},
+		{"severity": {Type: TypeEnum}},
+		{"severity": {Type: "date"}},
+		{"severity": nil},
+		{"tags": {Type: TypeEnum, Values: []string{"a,b"}}},
+	}
+	for _, s := range bad {
+		if err := s.Check(); err == nil {
+			t.Errorf("Check(%v) = nil, want error", s.Names())
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name, value string
+		want        string
+		wantErr     bool
+	}{
+		{"severity", "s2", "S2", false},
+		{"severity", "S9", "", true},
+		{"customer", " acme ", "acme", false},
+		{"customer", "acme, inc", "", true},
+		{"customer", "", "", true},
+		{"cost", "12.50", "12.5", false},
+		{"cost", "lots", "", true},
+		{"docs", "https://example.com/spec", "https://example.com/spec", false},
+		{"docs", "example.com/spec", "", true},
+		{"owner", "max", "", true},
+	}
+	for _, tt := range tests {
+		got, err := schema.Normalize(tt.name, tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("Normalize(%s, %q) = %q, %v; want %q, err %v", tt.name, tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+	if _, err := Schema(nil).Normalize("customer", "acme"); err == nil {
+		t.Error("empty schema accepted a field")
+	}
+}
+
+func TestFilters(t *testing.T) {
+	filters, err := ParseFilters([]string{"severity=s1", "cost=3.0"})
+	if err != nil {
+		t.Fatalf("ParseFilters: %v", err)
+	}
+	tests := []struct {
+		fields map[string]string
+		want   bool
+	}{
+		{map[string]string{"severity": "S1", "cost": "3"}, true},
+		{map[string]string{"severity": "S2", "cost": "3"}, false},
+		{map[string]string{"severity": "S1"}, false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.fields, filters); got != tt.want {
+			t.Errorf("Match(%v) = %v, want %v", tt.fields, got, tt.want)
+		}
+	}
+	for _, arg := range []string{"severity", "=S1", "severity="} {
+		if _, err := ParseFilters([]string{arg}); err == nil {
+			t.Errorf("ParseFilters(%q) = nil error", arg)
+		}
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	s, err := Load(t.TempDir())
+	if err != nil || s != nil {
+		t.Errorf("Load(no settings) = %v, %v; want nil, nil", s, err)
+	}
+}
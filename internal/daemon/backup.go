@@ -0,0 +1,129 @@
+package daemon
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/backup"
+	"github.com/steveyegge/gastown/internal/blobstore"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/retention"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// backupCheckInterval is how often the scheduler re-reads backup_interval
+// and checks whether a backup is due.
+const backupCheckInterval = 10 * time.Minute
+
+// BackupScheduler backs up town state to the town's backup store every
+// backup_interval (town settings). It is idle while the interval is unset.
+// Pruning old backups is left to the retention enforcer.
+type BackupScheduler struct {
+	townRoot string
+	logger   func(format string, args ...interface{})
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+
+	// last is when the newest backup was taken; zero until looked up.
+	last time.Time
+}
+
+// NewBackupScheduler creates a new backup scheduler.
+func NewBackupScheduler(townRoot string, logger func(format string, args ...interface{})) *BackupScheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &BackupScheduler{
+		townRoot: townRoot,
+		logger:   logger,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Start begins the scheduler goroutine.
+func (s *BackupScheduler) Start() {
+	s.wg.Add(1)
+	go s.run()
+}
+
+// Stop gracefully stops the scheduler, waiting for a running backup.
+func (s *BackupScheduler) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+func (s *BackupScheduler) run() {
+	defer s.wg.Done()
+
+	for {
+		s.check()
+		timer := time.NewTimer(backupCheckInterval)
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// check backs up the town if backups are enabled and the newest one is
+// older than the interval. The newest backup's time comes from the store
+// the first time, so a daemon restart doesn't trigger an extra backup.
+func (s *BackupScheduler) check() {
+	interval := s.interval()
+	if interval <= 0 {
+		return
+	}
+	if s.last.IsZero() {
+		s.last = s.newest()
+	}
+	if time.Since(s.last) < interval {
+		return
+	}
+
+	info, err := backup.Run(s.ctx, s.townRoot)
+	if err != nil {
+		s.logger("Backup error: %v", err)
+		return
+	}
+	s.last = info.Modified
+	if s.last.IsZero() {
+		s.last = time.Now()
+	}
+	s.logger("Backup: stored %s (%d bytes)", info.Key, info.Size)
+}
+
+// interval returns the configured backup interval, or 0 if backups are
+// off or the setting is invalid.
+func (s *BackupScheduler) interval() time.Duration {
+	settings, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(s.townRoot))
+	if err != nil || settings.BackupInterval == "" {
+		return 0
+	}
+	d, err := retention.ParseAge(settings.BackupInterval)
+	if err != nil {
+		s.logger("Backup: invalid backup_interval: %v", err)
+		return 0
+	}
+	return d
+}
+
+// newest returns when the town's newest backup was stored, or the zero
+// time if it has none or the store can't be read.
+func (s *BackupScheduler) newest() time.Time {
+	store, err := blobstore.OpenTownClass(s.townRoot, blobstore.ClassBackups)
+	if err != nil {
+		return time.Time{}
+	}
+	town, err := workspace.GetTownName(s.townRoot)
+	if err != nil {
+		return time.Time{}
+	}
+	backups, err := backup.List(s.ctx, store, town)
+	if err != nil || len(backups) == 0 {
+		return time.Time{}
+	}
+	return backups[0].Modified
+}
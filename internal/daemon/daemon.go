@@ -49,6 +49,13 @@ type Daemon struct {
 	convoyWatcher *ConvoyWatcher
 	doltServer    *DoltServerManager
 	krcPruner     *KRCPruner
+	retention     *RetentionEnforcer
+	backups       *BackupScheduler
+	runbooks      *RunbookScheduler
+
+	// verifyMu keeps post-merge verification to one run at a time; smoke
+	// commands can outlast a heartbeat.
+	verifyMu sync.Mutex
 
 	// Mass death detection: track recent session deaths
 	deathsMu     sync.Mutex
@@ -65,6 +72,14 @@ type Daemon struct {
 	// Only accessed from heartbeat loop goroutine - no sync needed.
 	syncFailures map[string]int
 
+	// rateLimitThrottled holds each provider's throttle count at the last
+	// heartbeat, to log the change. Only accessed from heartbeat loop goroutine.
+	rateLimitThrottled map[string]int64
+
+	// idleNudges records when the idle reaper nudged each polecat session,
+	// starting its grace period. Only accessed from heartbeat loop goroutine.
+	idleNudges map[string]time.Time
+
 	// PATCH-006: Resolved binary paths to avoid PATH issues in subprocesses.
 	// The daemon may be started with a limited PATH, causing exec.Command("gt", ...)
 	// to fail with "executable file not found in $PATH".
@@ -233,6 +248,21 @@ func (d *Daemon) Run() error {
 		}
 	}
 
+	// Start retention enforcer for log rotation and cleanup
+	d.retention = NewRetentionEnforcer(d.config.TownRoot, d.getKnownRigs, d.logger.Printf)
+	d.retention.Start()
+	d.logger.Println("Retention enforcer started")
+
+	// Start backup scheduler (idle unless backup_interval is set)
+	d.backups = NewBackupScheduler(d.config.TownRoot, d.logger.Printf)
+	d.backups.Start()
+	d.logger.Println("Backup scheduler started")
+
+	// Start runbook scheduler (idle unless a runbook sets "every")
+	d.runbooks = NewRunbookScheduler(d.config.TownRoot, d.logger.Printf)
+	d.runbooks.Start()
+	d.logger.Println("Runbook scheduler started")
+
 	// Start dedicated Dolt health check ticker if Dolt server is configured.
 	// This runs at a much higher frequency (default 30s) than the general
 	// heartbeat (3 min) so Dolt crashes are detected quickly.
@@ -250,6 +280,10 @@ func (d *Daemon) Run() error {
 	// Global pane-died hooks don't fire reliably in tmux 3.2a, so we rely on the
 	// per-session approach which has been tested to work for continuous recovery.
 
+	// Repair state orphaned by a crash (of agents or of this daemon)
+	// before the first heartbeat acts on it.
+	d.reconcileOrphanedState()
+
 	// Initial heartbeat
 	d.heartbeat(state)
 
@@ -389,6 +423,21 @@ func (d *Daemon) heartbeat(state *State) {
 	// If they have local .beads with databases, bd uses the wrong database.
 	d.cleanupTownServiceBeads()
 
+	// 14. Verify recent landings for rigs with post-merge checks configured.
+	// Runs in the background so a slow smoke command doesn't stall the heartbeat.
+	go d.verifyLandings()
+
+	// 15. Maintain the shared provider rate limiter: prune idle agents and
+	// log throttling since the last heartbeat.
+	d.maintainRateLimits()
+
+	// 16. Nudge, then reap, polecats idle beyond the configured threshold
+	// (opt-in via patrols.idle_reaper in mayor/daemon.json).
+	d.reapIdleAgents()
+
+	// 17. Track merge queue state times and alert on queue SLO breaches.
+	d.checkQueueSLOs()
+
 	// Update state
 	state.LastHeartbeat = time.Now()
 	state.HeartbeatCount++
@@ -803,14 +852,19 @@ func (d *Daemon) getKnownRigs() []string {
 	}
 
 	var parsed struct {
-		Rigs map[string]interface{} `json:"rigs"`
+		Rigs map[string]struct {
+			Archived json.RawMessage `json:"archived"`
+		} `json:"rigs"`
 	}
 	if err := json.Unmarshal(data, &parsed); err != nil {
 		return nil
 	}
 
 	var rigs []string
-	for name := range parsed.Rigs {
+	for name, entry := range parsed.Rigs {
+		if len(entry.Archived) > 0 && string(entry.Archived) != "null" {
+			continue // Archived rigs have no checkouts to patrol
+		}
 		rigs = append(rigs, name)
 	}
 	return rigs
@@ -1125,6 +1179,24 @@ func (d *Daemon) shutdown(state *State) error { //nolint:unparam // error return
 		d.logger.Println("KRC pruner stopped")
 	}
 
+	// Stop runbook scheduler
+	if d.runbooks != nil {
+		d.runbooks.Stop()
+		d.logger.Println("Runbook scheduler stopped")
+	}
+
+	// Stop backup scheduler
+	if d.backups != nil {
+		d.backups.Stop()
+		d.logger.Println("Backup scheduler stopped")
+	}
+
+	// Stop retention enforcer
+	if d.retention != nil {
+		d.retention.Stop()
+		d.logger.Println("Retention enforcer stopped")
+	}
+
 	// Stop Dolt server if we're managing it
 	if d.doltServer != nil && d.doltServer.IsEnabled() && !d.doltServer.IsExternal() {
 		if err := d.doltServer.Stop(); err != nil {
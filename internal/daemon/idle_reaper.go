@@ -0,0 +1,310 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+// IdleReaperConfig configures the idle-agent reaper (patrols.idle_reaper in
+// mayor/daemon.json). The reaper is off unless enabled: it kills sessions.
+//
+//	"idle_reaper": {"enabled": true, "idle_after": "30m", "grace": "10m"}
+type IdleReaperConfig struct {
+	// Enabled turns the reaper on.
+	Enabled bool `json:"enabled"`
+
+	// IdleAfter is how long a polecat may go without output, commits, or
+	// bead updates before it counts as idle (default 30m).
+	IdleAfter string `json:"idle_after,omitempty"`
+
+	// Nudge sends an idle polecat a wake-up nudge before reaping it
+	// (default true).
+	Nudge *bool `json:"nudge,omitempty"`
+
+	// NudgeMessage replaces the default wake-up message.
+	NudgeMessage string `json:"nudge_message,omitempty"`
+
+	// Grace is how long a nudged polecat has to show progress before it is
+	// reaped. Without a nudge, polecats are reaped after IdleAfter+Grace
+	// (default 10m).
+	Grace string `json:"grace,omitempty"`
+
+	// Requeue releases a reaped polecat's hooked issue back to open so
+	// another polecat can claim it (default true).
+	Requeue *bool `json:"requeue,omitempty"`
+
+	// Rigs limits the reaper to specific rigs. If empty, all rigs are reaped.
+	Rigs []string `json:"rigs,omitempty"`
+}
+
+// Idle reaper defaults.
+const (
+	defaultIdleAfter = 30 * time.Minute
+	defaultIdleGrace = 10 * time.Minute
+
+	// idleNudgeEcho is how long after a nudge session output is still put
+	// down to the nudge itself (the typed message and a short reply)
+	// rather than to the agent getting back to work.
+	idleNudgeEcho = 2 * time.Minute
+)
+
+// idlePolicy is an IdleReaperConfig with defaults applied.
+type idlePolicy struct {
+	idleAfter    time.Duration
+	grace        time.Duration
+	nudge        bool
+	nudgeMessage string
+	requeue      bool
+	rigs         []string
+}
+
+// idleReaperPolicy returns the reaper policy, or false if the reaper is off.
+func idleReaperPolicy(cfg *DaemonPatrolConfig) (idlePolicy, bool) {
+	if cfg == nil || cfg.Patrols == nil || cfg.Patrols.IdleReaper == nil || !cfg.Patrols.IdleReaper.Enabled {
+		return idlePolicy{}, false
+	}
+	rc := cfg.Patrols.IdleReaper
+	p := idlePolicy{
+		idleAfter:    config.ParseDurationOrDefault(rc.IdleAfter, defaultIdleAfter),
+		grace:        config.ParseDurationOrDefault(rc.Grace, defaultIdleGrace),
+		nudge:        rc.Nudge == nil || *rc.Nudge,
+		nudgeMessage: rc.NudgeMessage,
+		requeue:      rc.Requeue == nil || *rc.Requeue,
+		rigs:         rc.Rigs,
+	}
+	if p.nudgeMessage == "" {
+		p.nudgeMessage = fmt.Sprintf("You've been idle for %v. Continue your hooked work, run gt done if it's finished, "+
+			"or escalate if you're blocked. This session will be stopped in %v if it stays idle.",
+			p.idleAfter, p.grace)
+	}
+	return p, true
+}
+
+// idleVerdict is what the reaper does about one polecat.
+type idleVerdict int
+
+const (
+	idleActive  idleVerdict = iota // progressing; forget any earlier nudge
+	idleWaiting                    // nudged, still within the grace period
+	idleNudge                      // idle: send the wake-up nudge
+	idleReap                       // idle past the grace period: reap
+)
+
+// decide judges a polecat whose last sign of progress was at last and who
+// was nudged at nudgedAt (zero if not nudged).
+func (p idlePolicy) decide(now, last, nudgedAt time.Time) idleVerdict {
+	if !nudgedAt.IsZero() && last.After(nudgedAt.Add(idleNudgeEcho)) {
+		nudgedAt = time.Time{} // got back to work after the nudge
+	}
+	if !nudgedAt.IsZero() {
+		if now.Sub(nudgedAt) >= p.grace {
+			return idleReap
+		}
+		return idleWaiting
+	}
+	idle := now.Sub(last)
+	switch {
+	case !p.nudge && idle >= p.idleAfter+p.grace:
+		return idleReap
+	case p.nudge && idle >= p.idleAfter:
+		return idleNudge
+	}
+	return idleActive
+}
+
+// latest returns the most recent of ts.
+func latest(ts ...time.Time) time.Time {
+	var last time.Time
+	for _, t := range ts {
+		if t.After(last) {
+			last = t
+		}
+	}
+	return last
+}
+
+// reapIdleAgents nudges, and after a grace period reaps, polecats that have
+// stopped making progress: no session output, no commits, and no updates to
+// their agent bead or hooked issue. Reaping kills the session, clears the
+// hook, and releases the issue back to open so it is picked up again.
+// Idle premium-model sessions otherwise burn money doing nothing.
+func (d *Daemon) reapIdleAgents() {
+	policy, ok := idleReaperPolicy(d.patrolConfig)
+	if !ok {
+		return
+	}
+
+	cmd := exec.Command(d.bdPath, "list", "--label=gt:agent", "--json")
+	cmd.Dir = d.config.TownRoot
+	cmd.Env = os.Environ() // Inherit PATH to find bd executable
+
+	output, err := cmd.Output()
+	if err != nil {
+		d.logger.Printf("Warning: bd list failed for idle reaper: %v", err)
+		return
+	}
+
+	var agents []struct {
+		ID        string `json:"id"`
+		UpdatedAt string `json:"updated_at"`
+		HookBead  string `json:"hook_bead"`
+	}
+	if err := json.Unmarshal(output, &agents); err != nil {
+		return
+	}
+
+	rigs := d.getKnownRigs()
+	if len(policy.rigs) > 0 {
+		rigs = policy.rigs
+	}
+
+	now := time.Now()
+	seen := make(map[string]bool)
+	for _, rigName := range rigs {
+		// Pattern: <prefix>-<rig>-polecat-<name>
+		prefix := config.GetRigPrefix(d.config.TownRoot, rigName) + "-" + rigName + "-polecat-"
+		for _, agent := range agents {
+			if !strings.HasPrefix(agent.ID, prefix) {
+				continue
+			}
+			polecatName := strings.TrimPrefix(agent.ID, prefix)
+			sessionName := fmt.Sprintf("gt-%s-%s", rigName, polecatName)
+			if !d.tmux.IsAgentAlive(sessionName) {
+				continue // Dead sessions are the orphaned-work check's business
+			}
+			seen[sessionName] = true
+
+			agentUpdated, _ := time.Parse(time.RFC3339, agent.UpdatedAt)
+			last := latest(agentUpdated, d.sessionProgress(sessionName))
+			if agent.HookBead != "" {
+				last = latest(last, d.beadUpdatedAt(agent.HookBead))
+			}
+
+			switch policy.decide(now, last, d.idleNudges[sessionName]) {
+			case idleActive:
+				delete(d.idleNudges, sessionName)
+			case idleNudge:
+				d.nudgeIdleAgent(rigName, sessionName, now.Sub(last), policy)
+			case idleReap:
+				d.reapIdleAgent(rigName, agent.ID, sessionName, agent.HookBead, now.Sub(last), policy)
+				delete(d.idleNudges, sessionName)
+			}
+		}
+	}
+
+	// Forget nudges for sessions that have gone away on their own
+	for sessionName := range d.idleNudges {
+		if !seen[sessionName] {
+			delete(d.idleNudges, sessionName)
+		}
+	}
+}
+
+// sessionProgress returns the latest of the session's start, its last
+// output, and the last commit in its working directory.
+func (d *Daemon) sessionProgress(sessionName string) time.Time {
+	var last time.Time
+	if info, err := d.tmux.GetSessionInfo(sessionName); err == nil {
+		if created, err := time.ParseInLocation("2006-01-02 15:04:05", info.Created, time.Local); err == nil {
+			last = latest(last, created)
+		}
+		if unix, err := strconv.ParseInt(info.Activity, 10, 64); err == nil && unix > 0 {
+			last = latest(last, time.Unix(unix, 0))
+		}
+	}
+	if workDir, err := d.tmux.GetPaneWorkDir(sessionName); err == nil && workDir != "" {
+		out, err := exec.Command("git", "-C", workDir, "log", "-1", "--format=%ct").Output()
+		if err == nil {
+			if unix, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64); err == nil {
+				last = latest(last, time.Unix(unix, 0))
+			}
+		}
+	}
+	return last
+}
+
+// beadUpdatedAt returns when a bead was last updated, or the zero time.
+func (d *Daemon) beadUpdatedAt(id string) time.Time {
+	cmd := exec.Command(d.bdPath, "show", id, "--json")
+	cmd.Dir = d.config.TownRoot
+	cmd.Env = os.Environ()
+
+	output, err := cmd.Output()
+	if err != nil {
+		return time.Time{}
+	}
+	var issues []struct {
+		UpdatedAt string `json:"updated_at"`
+	}
+	if err := json.Unmarshal(output, &issues); err != nil || len(issues) == 0 {
+		return time.Time{}
+	}
+	t, _ := time.Parse(time.RFC3339, issues[0].UpdatedAt)
+	return t
+}
+
+// nudgeIdleAgent sends an idle polecat the wake-up nudge and starts its
+// grace period.
+func (d *Daemon) nudgeIdleAgent(rigName, sessionName string, idle time.Duration, policy idlePolicy) {
+	if err := d.tmux.NudgeSession(sessionName, "[from daemon] "+policy.nudgeMessage); err != nil {
+		d.logger.Printf("Idle reaper: failed to nudge %s: %v", sessionName, err)
+		return
+	}
+	if d.idleNudges == nil {
+		d.idleNudges = make(map[string]time.Time)
+	}
+	d.idleNudges[sessionName] = time.Now()
+	d.logger.Printf("Idle reaper: nudged %s (idle %v, grace %v)", sessionName, idle.Round(time.Minute), policy.grace)
+	_ = events.LogFeed(events.TypeNudge, "daemon", events.NudgePayload(rigName, sessionName, "idle "+idle.Round(time.Minute).String()))
+}
+
+// reapIdleAgent kills an idle polecat's session, clears its hook, and
+// releases its issue, then tells the witness, which owns the worktree.
+func (d *Daemon) reapIdleAgent(rigName, agentID, sessionName, hookBead string, idle time.Duration, policy idlePolicy) {
+	reason := fmt.Sprintf("idle for %v", idle.Round(time.Minute))
+	if err := d.tmux.KillSessionWithProcesses(sessionName); err != nil {
+		d.logger.Printf("Idle reaper: failed to kill %s: %v", sessionName, err)
+		return
+	}
+	d.logger.Printf("Idle reaper: killed %s (%s)", sessionName, reason)
+	_ = events.LogFeed(events.TypeKill, "daemon", events.KillPayload(rigName, sessionName, "idle reaper: "+reason))
+
+	if hookBead != "" && policy.requeue {
+		bd := beads.New(d.config.TownRoot)
+		if err := bd.ClearHookBead(agentID); err != nil {
+			d.logger.Printf("Idle reaper: failed to clear hook on %s: %v", agentID, err)
+		}
+		if err := bd.ReleaseWithReason(hookBead, "idle reaper: "+agentID+" "+reason); err != nil {
+			d.logger.Printf("Idle reaper: failed to release %s: %v", hookBead, err)
+		} else {
+			d.logger.Printf("Idle reaper: released %s back to open", hookBead)
+		}
+	}
+
+	witnessAddr := rigName + "/witness"
+	subject := fmt.Sprintf("IDLE_REAPED: %s %s", agentID, reason)
+	body := fmt.Sprintf(`Agent %s made no progress (output, commits, bead updates) and was stopped.
+
+session: %s
+hook_bead: %s
+requeued: %v
+
+Action needed: Clean up the polecat's worktree.`,
+		agentID, sessionName, hookBead, hookBead != "" && policy.requeue)
+
+	cmd := exec.Command(d.gtPath, "mail", "send", witnessAddr, "-s", subject, "-m", body)
+	cmd.Dir = d.config.TownRoot
+	cmd.Env = os.Environ() // Inherit PATH to find gt executable
+	if err := cmd.Run(); err != nil {
+		d.logger.Printf("Warning: failed to notify witness of idle reap: %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package daemon
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIdleReaperPolicy(t *testing.T) {
+	if _, ok := idleReaperPolicy(nil); ok {
+		t.Error("reaper enabled without config")
+	}
+
+	var cfg DaemonPatrolConfig
+	if err := json.Unmarshal([]byte(`{"patrols": {"idle_reaper": {"enabled": true, "grace": "5m", "nudge": false}}}`), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	p, ok := idleReaperPolicy(&cfg)
+	if !ok {
+		t.Fatal("reaper disabled despite enabled: true")
+	}
+	if p.idleAfter != defaultIdleAfter || p.grace != 5*time.Minute {
+		t.Errorf("idleAfter, grace = %v, %v; want %v, 5m", p.idleAfter, p.grace, defaultIdleAfter)
+	}
+	if p.nudge || !p.requeue {
+		t.Errorf("nudge, requeue = %v, %v; want false, true", p.nudge, p.requeue)
+	}
+	if !strings.Contains(p.nudgeMessage, "5m0s") {
+		t.Errorf("default nudge message doesn't mention the grace period: %q", p.nudgeMessage)
+	}
+}
+
+func TestIdlePolicyDecide(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	ago := func(d time.Duration) time.Time { return now.Add(-d) }
+	nudging := idlePolicy{idleAfter: 30 * time.Minute, grace: 10 * time.Minute, nudge: true}
+	silent := idlePolicy{idleAfter: 30 * time.Minute, grace: 10 * time.Minute}
+
+	tests := []struct {
+		name     string
+		policy   idlePolicy
+		last     time.Time
+		nudgedAt time.Time
+		want     idleVerdict
+	}{
+		{"recent progress", nudging, ago(5 * time.Minute), time.Time{}, idleActive},
+		{"idle, nudge first", nudging, ago(31 * time.Minute), time.Time{}, idleNudge},
+		{"nudged, within grace", nudging, ago(35 * time.Minute), ago(4 * time.Minute), idleWaiting},
+		{"nudge echo isn't progress", nudging, ago(3 * time.Minute), ago(4 * time.Minute), idleWaiting},
+		{"nudged, grace expired", nudging, ago(45 * time.Minute), ago(11 * time.Minute), idleReap},
+		{"back to work after nudge", nudging, ago(1 * time.Minute), ago(9 * time.Minute), idleActive},
+		{"no nudge, within grace", silent, ago(35 * time.Minute), time.Time{}, idleActive},
+		{"no nudge, grace expired", silent, ago(41 * time.Minute), time.Time{}, idleReap},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.decide(now, tt.last, tt.nudgedAt); got != tt.want {
+				t.Errorf("decide() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
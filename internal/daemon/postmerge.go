@@ -0,0 +1,54 @@
+package daemon
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// verifyLandings runs post-merge verification for every rig that configures
+// a post_merge_command or post_merge_health_url. A failed landing is flagged,
+// the rig's queue is frozen, and the landing is reverted if the rig opts in.
+func (d *Daemon) verifyLandings() {
+	if !d.verifyMu.TryLock() {
+		return // Previous run still going
+	}
+	defer d.verifyMu.Unlock()
+
+	for _, rigName := range d.getKnownRigs() {
+		r := &rig.Rig{
+			Name: rigName,
+			Path: filepath.Join(d.config.TownRoot, rigName),
+		}
+		e := refinery.NewEngineer(r)
+		if err := e.LoadConfig(); err != nil || !e.PostMergeEnabled() {
+			continue
+		}
+		pending, err := e.PendingLandings(time.Now())
+		if err != nil {
+			d.logger.Printf("Post-merge: %s: %v", rigName, err)
+			continue
+		}
+		for _, mr := range pending {
+			check, err := e.VerifyLanding(d.ctx, mr, time.Now())
+			if err != nil {
+				d.logger.Printf("Post-merge: %s: verifying %s: %v", rigName, mr.ID, err)
+			}
+			if check == nil || check.Verdict != refinery.PostMergeFailed {
+				continue
+			}
+			d.logger.Printf("Post-merge: %s: landing %s failed verification; queue frozen", rigName, mr.ID)
+			_ = events.LogFeed(events.TypeLandingFailed, rigName+"/refinery", map[string]interface{}{
+				"rig":       rigName,
+				"mr":        mr.ID,
+				"commit":    check.Commit,
+				"revert_mr": check.RevertMR,
+			})
+			// The queue is frozen now; the rest can wait for the next run
+			break
+		}
+	}
+}
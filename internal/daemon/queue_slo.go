@@ -0,0 +1,42 @@
+package daemon
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// checkQueueSLOs tracks how long MRs sit in each queue state, for rigs
+// that set merge_queue.slo, and emits a queue_slo_breached event the first
+// time an MR overstays its state's SLO.
+func (d *Daemon) checkQueueSLOs() {
+	for _, rigName := range d.getKnownRigs() {
+		r := &rig.Rig{
+			Name: rigName,
+			Path: filepath.Join(d.config.TownRoot, rigName),
+		}
+		e := refinery.NewEngineer(r)
+		if err := e.LoadConfig(); err != nil || len(e.SLOs()) == 0 {
+			continue
+		}
+		breaches, err := e.TrackQueueTimes(time.Now())
+		if err != nil {
+			d.logger.Printf("Queue SLO: %s: %v", rigName, err)
+		}
+		for _, b := range breaches {
+			d.logger.Printf("Queue SLO: %s: %s %s for %s (SLO %s)", rigName, b.MR, b.State,
+				b.Dwell.Round(time.Minute), b.SLO)
+			_ = events.LogFeed(events.TypeQueueSLOBreached, rigName+"/refinery", map[string]interface{}{
+				"rig":    rigName,
+				"mr":     b.MR,
+				"branch": b.Branch,
+				"state":  b.State,
+				"dwell":  b.Dwell.Round(time.Minute).String(),
+				"slo":    b.SLO.String(),
+			})
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package daemon
+
+import (
+	"os"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/ratelimit"
+)
+
+// rateLimitIdleAge is how long an agent can go without drawing a token
+// before the daemon drops it from the limiter's usage table.
+const rateLimitIdleAge = time.Hour
+
+// maintainRateLimits prunes idle agents from the town's shared rate limiter
+// and logs throttling since the last heartbeat.
+func (d *Daemon) maintainRateLimits() {
+	if _, err := os.Stat(ratelimit.StatePath(d.config.TownRoot)); err != nil {
+		return // No agent has drawn a token yet
+	}
+
+	throttled := make(map[string]int64)
+	err := ratelimit.Update(d.config.TownRoot, func(s *ratelimit.State) error {
+		if pruned := s.Prune(time.Now(), rateLimitIdleAge); pruned > 0 {
+			d.logger.Printf("Rate limiter: pruned %d idle agent(s)", pruned)
+		}
+		for provider, b := range s.Buckets {
+			throttled[provider] = b.Throttled
+		}
+		return nil
+	})
+	if err != nil {
+		d.logger.Printf("Rate limiter: %v", err)
+		return
+	}
+
+	for provider, n := range throttled {
+		if delta := n - d.rateLimitThrottled[provider]; delta > 0 && d.rateLimitThrottled != nil {
+			d.logger.Printf("Rate limiter: %d %s request(s) throttled since last heartbeat", delta, provider)
+		}
+	}
+	d.rateLimitThrottled = throttled
+}
@@ -0,0 +1,28 @@
+package daemon
+
+import (
+	"github.com/steveyegge/gastown/internal/reconcile"
+)
+
+// reconcileOrphanedState repairs state left behind by a crash before the
+// first heartbeat: agents marked running with no session, MRs and merge
+// slots held by dead agents, and stale identity locks. Each repair is
+// recorded in the event log.
+func (d *Daemon) reconcileOrphanedState() {
+	repairs := reconcile.Run(reconcile.Options{
+		TownRoot: d.config.TownRoot,
+		Rigs:     d.getKnownRigs(),
+		Alive:    d.tmux.IsAgentAlive,
+	}, "daemon")
+
+	for _, r := range repairs {
+		if r.Err != "" {
+			d.logger.Printf("Reconcile: failed to %s: %s", r, r.Err)
+		} else {
+			d.logger.Printf("Reconcile: %s", r)
+		}
+	}
+	if len(repairs) == 0 {
+		d.logger.Println("Reconcile: no orphaned state found")
+	}
+}
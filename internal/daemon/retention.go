@@ -0,0 +1,97 @@
+package daemon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/blobstore"
+	"github.com/steveyegge/gastown/internal/retention"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// RetentionEnforcer periodically applies the town's retention policies:
+// rotating and compressing oversized logs and removing expired rotated
+// files, session recordings, and stored backups and attachments. It runs
+// as a background goroutine within the daemon.
+type RetentionEnforcer struct {
+	townRoot  string
+	knownRigs func() []string
+	logger    func(format string, args ...interface{})
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+// NewRetentionEnforcer creates a new retention enforcer. knownRigs lists
+// the town's rigs at each run, so rigs added later are covered.
+func NewRetentionEnforcer(townRoot string, knownRigs func() []string, logger func(format string, args ...interface{})) *RetentionEnforcer {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &RetentionEnforcer{
+		townRoot:  townRoot,
+		knownRigs: knownRigs,
+		logger:    logger,
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// Start begins the enforcer goroutine.
+func (e *RetentionEnforcer) Start() {
+	// Run once on startup
+	e.enforce()
+
+	e.wg.Add(1)
+	go e.run()
+}
+
+// Stop gracefully stops the enforcer.
+func (e *RetentionEnforcer) Stop() {
+	e.cancel()
+	e.wg.Wait()
+}
+
+// run is the main enforcer loop. The interval is re-read after each run so
+// config changes apply without a daemon restart.
+func (e *RetentionEnforcer) run() {
+	defer e.wg.Done()
+
+	for {
+		timer := time.NewTimer(retention.Interval(retention.LoadConfig(e.townRoot)))
+		select {
+		case <-e.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			e.enforce()
+		}
+	}
+}
+
+// enforce runs a single retention pass.
+func (e *RetentionEnforcer) enforce() {
+	opts := retention.Options{TownRoot: e.townRoot}
+	for _, name := range e.knownRigs() {
+		opts.RigPaths = append(opts.RigPaths, filepath.Join(e.townRoot, name))
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		opts.ProjectsDir = filepath.Join(home, ".claude", "projects")
+	}
+
+	actions, err := retention.Enforce(opts, retention.LoadConfig(e.townRoot))
+	if err != nil {
+		e.logger("Retention error: %v", err)
+	}
+	if town, err := workspace.GetTownName(e.townRoot); err == nil {
+		pruned, err := blobstore.PruneTown(e.ctx, e.townRoot, town, false)
+		if err != nil {
+			e.logger("Retention error: %v", err)
+		}
+		actions = append(actions, pruned...)
+	}
+	if len(actions) > 0 {
+		e.logger("Retention: %d action(s), freed %d bytes", len(actions), retention.Freed(actions))
+	}
+}
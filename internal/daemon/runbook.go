@@ -0,0 +1,100 @@
+package daemon
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/runbook"
+)
+
+// runbookCheckInterval is how often the scheduler re-reads the runbooks
+// and checks which are due.
+const runbookCheckInterval = time.Minute
+
+// RunbookScheduler runs runbooks that set "every" (town settings) on that
+// schedule, with default parameters. A runbook is due when its log is
+// older than its interval, so manual 'gt run's count too. Runbooks run
+// one at a time.
+type RunbookScheduler struct {
+	townRoot string
+	logger   func(format string, args ...interface{})
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// NewRunbookScheduler creates a new runbook scheduler.
+func NewRunbookScheduler(townRoot string, logger func(format string, args ...interface{})) *RunbookScheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &RunbookScheduler{
+		townRoot: townRoot,
+		logger:   logger,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Start begins the scheduler goroutine.
+func (s *RunbookScheduler) Start() {
+	s.wg.Add(1)
+	go s.run()
+}
+
+// Stop stops the scheduler, cancelling a running runbook.
+func (s *RunbookScheduler) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+func (s *RunbookScheduler) run() {
+	defer s.wg.Done()
+
+	for {
+		s.check()
+		timer := time.NewTimer(runbookCheckInterval)
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// check runs every scheduled runbook that is due.
+func (s *RunbookScheduler) check() {
+	runbooks, err := runbook.Load(s.townRoot)
+	if err != nil {
+		return
+	}
+	for _, name := range runbook.Names(runbooks) {
+		rb := runbooks[name]
+		interval := runbook.Interval(rb)
+		if interval <= 0 || s.ctx.Err() != nil {
+			continue
+		}
+		if info, err := os.Stat(runbook.LogPath(s.townRoot, name)); err == nil && time.Since(info.ModTime()) < interval {
+			continue
+		}
+		params, err := runbook.Params(name, rb, nil)
+		if err != nil {
+			s.logger("Runbook %s: %v", name, err)
+			continue
+		}
+		runner := &runbook.Runner{TownRoot: s.townRoot}
+		res, err := runner.Run(s.ctx, name, rb, params)
+		if err != nil {
+			s.logger("Runbook %s: %v", name, err)
+			continue
+		}
+		_ = events.LogFeed(events.TypeRunbook, "daemon", events.RunbookPayload(name, res.OK, res.FailedStep()))
+		if res.OK {
+			s.logger("Runbook %s: ok", name)
+		} else {
+			s.logger("Runbook %s: failed at %s (see %s)", name, res.FailedStep(), res.LogPath)
+		}
+	}
+}
@@ -117,6 +117,7 @@ type PatrolsConfig struct {
 	Witness    *PatrolConfig     `json:"witness,omitempty"`
 	Deacon     *PatrolConfig     `json:"deacon,omitempty"`
 	DoltServer *DoltServerConfig `json:"dolt_server,omitempty"`
+	IdleReaper *IdleReaperConfig `json:"idle_reaper,omitempty"`
 }
 
 // DaemonPatrolConfig is the structure of mayor/daemon.json.
@@ -0,0 +1,200 @@
+// Package deploy tracks which commits of a rig have been deployed to which
+// environments, so that merged work can be checked for being live.
+//
+// Each deployment is recorded as a closed bead labeled gt:deployment whose
+// description carries "key: value" fields (environment, commit, ...).
+package deploy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+// Label marks deployment beads.
+const Label = "gt:deployment"
+
+// Record is one deployment of a commit to an environment.
+type Record struct {
+	ID          string    `json:"id,omitempty"`
+	Environment string    `json:"environment"`
+	Commit      string    `json:"commit"`
+	Ref         string    `json:"ref,omitempty"`
+	URL         string    `json:"url,omitempty"`
+	DeployedBy  string    `json:"deployed_by,omitempty"`
+	DeployedAt  time.Time `json:"deployed_at"`
+	Note        string    `json:"note,omitempty"`
+}
+
+// Validate checks that a record has the required fields.
+func (r *Record) Validate() error {
+	if r.Environment == "" {
+		return fmt.Errorf("environment is required")
+	}
+	if strings.ContainsAny(r.Environment, " \t\n,:") {
+		return fmt.Errorf("invalid environment %q: no spaces, commas, or colons", r.Environment)
+	}
+	if r.Commit == "" {
+		return fmt.Errorf("commit is required")
+	}
+	return nil
+}
+
+// Title returns the bead title for a record.
+func (r *Record) Title() string {
+	return fmt.Sprintf("Deploy %s to %s", shortSHA(r.Commit), r.Environment)
+}
+
+// FormatDescription renders a record as a bead description.
+func FormatDescription(r *Record) string {
+	var lines []string
+	add := func(key, value string) {
+		if value != "" {
+			lines = append(lines, key+": "+value)
+		}
+	}
+	add("environment", r.Environment)
+	add("commit", r.Commit)
+	add("ref", r.Ref)
+	add("url", r.URL)
+	add("deployed_by", r.DeployedBy)
+	if !r.DeployedAt.IsZero() {
+		add("deployed_at", r.DeployedAt.UTC().Format(time.RFC3339))
+	}
+	out := strings.Join(lines, "\n")
+	if r.Note != "" {
+		out += "\n\n" + r.Note
+	}
+	return out
+}
+
+// Parse extracts a record from a deployment bead. Returns nil if the bead
+// has no environment or commit. DeployedAt falls back to the bead's
+// creation time.
+func Parse(issue *beads.Issue) *Record {
+	if issue == nil {
+		return nil
+	}
+	r := &Record{ID: issue.ID}
+	var note []string
+	inNote := false
+	for _, line := range strings.Split(issue.Description, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if inNote {
+			note = append(note, line)
+			continue
+		}
+		if trimmed == "" {
+			inNote = true
+			continue
+		}
+		key, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "environment", "env":
+			r.Environment = value
+		case "commit":
+			r.Commit = value
+		case "ref":
+			r.Ref = value
+		case "url":
+			r.URL = value
+		case "deployed_by", "deployed-by":
+			r.DeployedBy = value
+		case "deployed_at", "deployed-at":
+			r.DeployedAt = parseTime(value)
+		}
+	}
+	if r.Environment == "" || r.Commit == "" {
+		return nil
+	}
+	if r.DeployedAt.IsZero() {
+		r.DeployedAt = parseTime(issue.CreatedAt)
+	}
+	r.Note = strings.TrimSpace(strings.Join(note, "\n"))
+	return r
+}
+
+// ParseAll extracts records from deployment beads, newest first.
+func ParseAll(issues []*beads.Issue) []*Record {
+	var records []*Record
+	for _, issue := range issues {
+		if r := Parse(issue); r != nil {
+			records = append(records, r)
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].DeployedAt.After(records[j].DeployedAt)
+	})
+	return records
+}
+
+// Latest returns the most recent record per environment, sorted by
+// environment name.
+func Latest(records []*Record) []*Record {
+	byEnv := make(map[string]*Record)
+	for _, r := range records {
+		if cur, ok := byEnv[r.Environment]; !ok || r.DeployedAt.After(cur.DeployedAt) {
+			byEnv[r.Environment] = r
+		}
+	}
+	latest := make([]*Record, 0, len(byEnv))
+	for _, r := range byEnv {
+		latest = append(latest, r)
+	}
+	sort.Slice(latest, func(i, j int) bool {
+		return latest[i].Environment < latest[j].Environment
+	})
+	return latest
+}
+
+// EnvStatus says whether a commit is live in an environment.
+type EnvStatus struct {
+	Environment string  `json:"environment"`
+	Live        bool    `json:"live"`
+	Deployment  *Record `json:"deployment"`
+	Error       string  `json:"error,omitempty"`
+}
+
+// Contains reports whether commit is included in deployed (typically
+// git's IsAncestor).
+type Contains func(commit, deployed string) (bool, error)
+
+// Status reports, for each environment's latest deployment, whether commit
+// is live there.
+func Status(commit string, latest []*Record, contains Contains) []EnvStatus {
+	statuses := make([]EnvStatus, 0, len(latest))
+	for _, r := range latest {
+		s := EnvStatus{Environment: r.Environment, Deployment: r}
+		live, err := contains(commit, r.Commit)
+		if err != nil {
+			s.Error = err.Error()
+		}
+		s.Live = live
+		statuses = append(statuses, s)
+	}
+	return statuses
+}
+
+// parseTime parses a bead timestamp, returning zero time on error.
+func parseTime(s string) time.Time {
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
@@ -0,0 +1,126 @@
+package deploy
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+func TestFormatAndParse(t *testing.T) {
+	at := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	rec := &Record{
+		Environment: "prod",
+		Commit:      "0123456789abcdef",
+		Ref:         "v1.2.0",
+		URL:         "https://ci.example.com/run/42",
+		DeployedBy:  "ci",
+		DeployedAt:  at,
+		Note:        "Rolled out in two waves.\nNo incidents.",
+	}
+	issue := &beads.Issue{ID: "gt-d1", Description: FormatDescription(rec), CreatedAt: "2026-01-01T00:00:00Z"}
+
+	got := Parse(issue)
+	if got == nil {
+		t.Fatal("Parse returned nil")
+	}
+	rec.ID = "gt-d1"
+	if *got != *rec {
+		t.Errorf("round trip mismatch:\ngot  %+v\nwant %+v", got, rec)
+	}
+	if rec.Title() != "Deploy 01234567 to prod" {
+		t.Errorf("Title = %q", rec.Title())
+	}
+
+	if Parse(&beads.Issue{Description: "environment: prod"}) != nil {
+		t.Error("expected nil for a record without commit")
+	}
+	fallback := Parse(&beads.Issue{Description: "env: dev\ncommit: abc", CreatedAt: "2026-01-02T00:00:00Z"})
+	if fallback == nil || !fallback.DeployedAt.Equal(time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected DeployedAt to fall back to CreatedAt, got %+v", fallback)
+	}
+}
+
+func TestLatestAndStatus(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	records := ParseAll([]*beads.Issue{
+		{ID: "d1", Description: "environment: prod\ncommit: old\ndeployed_at: " + t0.Format(time.RFC3339)},
+		{ID: "d2", Description: "environment: staging\ncommit: new\ndeployed_at: " + t0.Add(2*time.Hour).Format(time.RFC3339)},
+		{ID: "d3", Description: "environment: prod\ncommit: mid\ndeployed_at: " + t0.Add(time.Hour).Format(time.RFC3339)},
+		{ID: "bogus", Description: "not a deployment"},
+	})
+	if len(records) != 3 || records[0].ID != "d2" {
+		t.Fatalf("ParseAll = %+v, want 3 records newest first", records)
+	}
+
+	latest := Latest(records)
+	if len(latest) != 2 || latest[0].Environment != "prod" || latest[0].Commit != "mid" || latest[1].Commit != "new" {
+		t.Fatalf("Latest = %+v", latest)
+	}
+
+	// History: fix < mid < new
+	order := map[string]int{"fix": 1, "mid": 2, "new": 3}
+	contains := func(commit, deployed string) (bool, error) {
+		if _, ok := order[deployed]; !ok {
+			return false, errors.New("unknown commit")
+		}
+		return order[commit] <= order[deployed], nil
+	}
+	statuses := Status("fix", latest, contains)
+	for _, s := range statuses {
+		if !s.Live {
+			t.Errorf("%s: expected fix to be live", s.Environment)
+		}
+	}
+	statuses = Status("new", latest, contains)
+	if statuses[0].Live || !statuses[1].Live {
+		t.Errorf("Status(new) = %+v, want live only in staging", statuses)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	var recorded *Record
+	h := &Handler{
+		Secret: "s3cret",
+		Record: func(rig string, r *Record) (*Record, error) {
+			if rig != "gastown" {
+				return nil, errors.New("unknown rig")
+			}
+			recorded = r
+			r.ID = "gt-d9"
+			return r, nil
+		},
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		auth   string
+		body   string
+		want   int
+	}{
+		{"wrong method", http.MethodGet, "Bearer s3cret", "", http.StatusMethodNotAllowed},
+		{"bad secret", http.MethodPost, "Bearer nope", `{"rig":"gastown","environment":"prod","commit":"abc"}`, http.StatusUnauthorized},
+		{"bad json", http.MethodPost, "Bearer s3cret", `{`, http.StatusBadRequest},
+		{"missing commit", http.MethodPost, "Bearer s3cret", `{"rig":"gastown","environment":"prod"}`, http.StatusBadRequest},
+		{"unknown rig", http.MethodPost, "Bearer s3cret", `{"rig":"nope","environment":"prod","commit":"abc"}`, http.StatusInternalServerError},
+		{"ok", http.MethodPost, "Bearer s3cret", `{"rig":"gastown","environment":"prod","commit":"abc"}`, http.StatusCreated},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "/", strings.NewReader(tt.body))
+		req.Header.Set("Authorization", tt.auth)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d (%s)", tt.name, rec.Code, tt.want, rec.Body.String())
+		}
+	}
+
+	if recorded == nil || recorded.Environment != "prod" || recorded.DeployedAt.IsZero() {
+		t.Errorf("recorded = %+v, want prod with DeployedAt defaulted", recorded)
+	}
+}
@@ -0,0 +1,72 @@
+package deploy
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxWebhookBody bounds webhook request bodies.
+const maxWebhookBody = 64 << 10
+
+// WebhookRequest is the JSON body accepted by the deploy webhook.
+type WebhookRequest struct {
+	Rig string `json:"rig"`
+	Record
+}
+
+// RecordFunc records a deployment for a rig and returns the stored record.
+type RecordFunc func(rig string, r *Record) (*Record, error)
+
+// Handler accepts deployment notifications from CI/CD systems:
+//
+//	POST / {"rig": "gastown", "environment": "prod", "commit": "abc123"}
+//
+// If Secret is set, requests must carry "Authorization: Bearer <secret>".
+type Handler struct {
+	Secret string
+	Record RecordFunc
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.Secret != "" {
+		token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(h.Secret)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	var body WebhookRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxWebhookBody)).Decode(&body); err != nil {
+		http.Error(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if body.Rig == "" {
+		http.Error(w, "rig is required", http.StatusBadRequest)
+		return
+	}
+	if err := body.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if body.DeployedAt.IsZero() {
+		body.DeployedAt = time.Now().UTC()
+	}
+
+	rec, err := h.Record(body.Rig, &body.Record)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(rec)
+}
@@ -0,0 +1,370 @@
+// Package depupdate detects outdated dependencies in a rig and describes
+// them as beads that polecats can pick up.
+//
+// Updates come from two kinds of sources:
+//   - A Renovate/Dependabot-style JSON report produced outside Gas Town
+//   - gt's own detector (currently `go list -m -u` for Go rigs)
+//
+// Filing is idempotent: an open bead is matched to an update by ecosystem
+// and dependency name, so a newer release updates that bead rather than
+// filing another.
+package depupdate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Label marks beads filed for dependency updates.
+const Label = "gt:dep-update"
+
+// Sources for detected updates.
+const (
+	SourceGoList = "go-list"
+	SourceReport = "report"
+)
+
+// ErrNoDetector is returned when a rig has no report configured and no
+// supported ecosystem was detected in its clone.
+var ErrNoDetector = errors.New("no dependency detector for this rig (configure dep_updates.report_path)")
+
+// Update is a single dependency that has a newer version available.
+type Update struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+	Current   string `json:"current"`
+	Latest    string `json:"latest"`
+	Source    string `json:"source"`
+}
+
+// Title returns the bead title for this update.
+func (u Update) Title() string {
+	return fmt.Sprintf("Bump %s from %s to %s", u.Name, u.Current, u.Latest)
+}
+
+// Key identifies the dependency an update is for, whatever its versions.
+// Beads are deduped on it.
+func (u Update) Key() string {
+	return u.Ecosystem + ":" + u.Name
+}
+
+// KeyOf returns the Key of the update a bead was filed for, read from the
+// bead's description (see Description), or "" if it names no dependency.
+func KeyOf(description string) string {
+	var ecosystem, name string
+	for _, line := range strings.Split(description, "\n") {
+		if v, ok := strings.CutPrefix(line, "ecosystem: "); ok {
+			ecosystem = v
+		} else if v, ok := strings.CutPrefix(line, "dependency: "); ok {
+			name = v
+		}
+	}
+	if name == "" {
+		return ""
+	}
+	return Update{Ecosystem: ecosystem, Name: name}.Key()
+}
+
+// Supersedes reports whether u goes to a newer version than the update a
+// bead was filed for, read from the bead's description. A bead that
+// records no version is superseded by any update.
+func (u Update) Supersedes(description string) bool {
+	for _, line := range strings.Split(description, "\n") {
+		if v, ok := strings.CutPrefix(line, "latest: "); ok {
+			return compareVersions(u.Latest, v) > 0
+		}
+	}
+	return true
+}
+
+// Description returns the bead description for this update.
+func (u Update) Description() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "ecosystem: %s\n", u.Ecosystem)
+	fmt.Fprintf(&sb, "dependency: %s\n", u.Name)
+	fmt.Fprintf(&sb, "current: %s\n", u.Current)
+	fmt.Fprintf(&sb, "latest: %s\n", u.Latest)
+	fmt.Fprintf(&sb, "source: %s\n", u.Source)
+	sb.WriteString("\nUpdate the dependency, fix any breakage, run the test suite, and submit via gt done.")
+	return sb.String()
+}
+
+// goListModule mirrors the fields of `go list -m -u -json` we care about.
+type goListModule struct {
+	Path     string        `json:"Path"`
+	Version  string        `json:"Version"`
+	Main     bool          `json:"Main"`
+	Indirect bool          `json:"Indirect"`
+	Update   *goListModule `json:"Update"`
+}
+
+// ParseGoList parses the concatenated JSON objects printed by
+// `go list -m -u -json all`. The main module and modules without an
+// available update are skipped.
+func ParseGoList(r io.Reader, includeIndirect bool) ([]Update, error) {
+	dec := json.NewDecoder(r)
+	var updates []Update
+	for {
+		var m goListModule
+		if err := dec.Decode(&m); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("parsing go list output: %w", err)
+		}
+		if m.Main || m.Update == nil || m.Update.Version == "" {
+			continue
+		}
+		if m.Indirect && !includeIndirect {
+			continue
+		}
+		updates = append(updates, Update{
+			Ecosystem: "go",
+			Name:      m.Path,
+			Current:   m.Version,
+			Latest:    m.Update.Version,
+			Source:    SourceGoList,
+		})
+	}
+	return updates, nil
+}
+
+// CheckGo runs `go list -m -u -json all` in dir and returns available updates.
+func CheckGo(ctx context.Context, dir string, includeIndirect bool) ([]Update, error) {
+	cmd := exec.CommandContext(ctx, "go", "list", "-m", "-u", "-json", "all")
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("go list -m -u: %s", strings.TrimSpace(stderr.String()))
+	}
+	return ParseGoList(&stdout, includeIndirect)
+}
+
+// reportEntry is the generic flat report format:
+//
+//	[{"ecosystem": "npm", "name": "left-pad", "current": "1.0.0", "latest": "1.3.0"}]
+type reportEntry struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+	Current   string `json:"current"`
+	Latest    string `json:"latest"`
+}
+
+// renovateReport is the subset of Renovate's --report-type=file output we read.
+type renovateReport struct {
+	Repositories map[string]struct {
+		PackageFiles map[string][]struct {
+			Deps []struct {
+				DepName      string `json:"depName"`
+				CurrentValue string `json:"currentValue"`
+				Updates      []struct {
+					NewVersion string `json:"newVersion"`
+					NewValue   string `json:"newValue"`
+				} `json:"updates"`
+			} `json:"deps"`
+		} `json:"packageFiles"`
+	} `json:"repositories"`
+}
+
+// ParseReport parses an update report. Two shapes are accepted: a flat JSON
+// array of {ecosystem, name, current, latest} entries (or an object with an
+// "updates" key holding one), and Renovate's file report.
+func ParseReport(data []byte) ([]Update, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, nil
+	}
+
+	if trimmed[0] == '[' {
+		var entries []reportEntry
+		if err := json.Unmarshal(trimmed, &entries); err != nil {
+			return nil, fmt.Errorf("parsing update report: %w", err)
+		}
+		return newestPerKey(fromEntries(entries)), nil
+	}
+
+	var probe struct {
+		Updates      []reportEntry   `json:"updates"`
+		Repositories json.RawMessage `json:"repositories"`
+	}
+	if err := json.Unmarshal(trimmed, &probe); err != nil {
+		return nil, fmt.Errorf("parsing update report: %w", err)
+	}
+	if probe.Repositories == nil {
+		return newestPerKey(fromEntries(probe.Updates)), nil
+	}
+
+	var rr renovateReport
+	if err := json.Unmarshal(trimmed, &rr); err != nil {
+		return nil, fmt.Errorf("parsing renovate report: %w", err)
+	}
+	var updates []Update
+	for _, repo := range rr.Repositories {
+		for manager, files := range repo.PackageFiles {
+			for _, file := range files {
+				for _, dep := range file.Deps {
+					for _, up := range dep.Updates {
+						latest := up.NewVersion
+						if latest == "" {
+							latest = up.NewValue
+						}
+						if dep.DepName == "" || latest == "" {
+							continue
+						}
+						updates = append(updates, Update{
+							Ecosystem: manager,
+							Name:      dep.DepName,
+							Current:   dep.CurrentValue,
+							Latest:    latest,
+							Source:    SourceReport,
+						})
+					}
+				}
+			}
+		}
+	}
+	return newestPerKey(updates), nil
+}
+
+func fromEntries(entries []reportEntry) []Update {
+	updates := make([]Update, 0, len(entries))
+	for _, e := range entries {
+		if e.Name == "" || e.Latest == "" {
+			continue
+		}
+		updates = append(updates, Update{
+			Ecosystem: e.Ecosystem,
+			Name:      e.Name,
+			Current:   e.Current,
+			Latest:    e.Latest,
+			Source:    SourceReport,
+		})
+	}
+	return updates
+}
+
+// Detect returns the available updates for a rig clone.
+// If reportPath is set it is read (relative to repoDir); otherwise the
+// built-in detector for the clone's ecosystem is used.
+func Detect(ctx context.Context, repoDir, reportPath string, includeIndirect bool) ([]Update, error) {
+	if reportPath != "" {
+		if !filepath.IsAbs(reportPath) {
+			reportPath = filepath.Join(repoDir, reportPath)
+		}
+		data, err := os.ReadFile(reportPath) //nolint:gosec // G304: path comes from operator-controlled rig settings
+		if err != nil {
+			return nil, fmt.Errorf("reading update report: %w", err)
+		}
+		return ParseReport(data)
+	}
+
+	if _, err := os.Stat(filepath.Join(repoDir, "go.mod")); err == nil {
+		return CheckGo(ctx, repoDir, includeIndirect)
+	}
+
+	return nil, ErrNoDetector
+}
+
+// Filter drops updates whose name matches any of the ignore globs.
+func Filter(updates []Update, ignore []string) []Update {
+	if len(ignore) == 0 {
+		return updates
+	}
+	kept := make([]Update, 0, len(updates))
+	for _, u := range updates {
+		if !matchesAny(u.Name, ignore) {
+			kept = append(kept, u)
+		}
+	}
+	return kept
+}
+
+func matchesAny(name string, globs []string) bool {
+	for _, g := range globs {
+		if ok, _ := path.Match(g, name); ok {
+			return true
+		}
+		// A trailing /* also covers nested paths (golang.org/x/* matches golang.org/x/net/v2)
+		if strings.HasSuffix(g, "/*") && strings.HasPrefix(name, strings.TrimSuffix(g, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// newestPerKey keeps one update per dependency, the one to its newest
+// version, sorted. Renovate lists each of a dependency's update branches
+// (minor, major) and each package file that uses it; a bead is filed for
+// the dependency once.
+func newestPerKey(updates []Update) []Update {
+	byKey := make(map[string]int, len(updates))
+	kept := updates[:0:0]
+	for _, u := range updates {
+		i, seen := byKey[u.Key()]
+		if !seen {
+			byKey[u.Key()] = len(kept)
+			kept = append(kept, u)
+		} else if compareVersions(u.Latest, kept[i].Latest) > 0 {
+			kept[i] = u
+		}
+	}
+	sortUpdates(kept)
+	return kept
+}
+
+// compareVersions orders two version strings by their dot-separated
+// numeric parts, ignoring a leading "v" and any pre-release or build
+// suffix. It returns -1, 0, or 1.
+func compareVersions(a, b string) int {
+	parts := func(v string) []int {
+		v = strings.TrimPrefix(v, "v")
+		if i := strings.IndexAny(v, "-+"); i >= 0 {
+			v = v[:i]
+		}
+		var nums []int
+		for _, p := range strings.Split(v, ".") {
+			n, _ := strconv.Atoi(p)
+			nums = append(nums, n)
+		}
+		return nums
+	}
+	pa, pb := parts(a), parts(b)
+	for i := 0; i < max(len(pa), len(pb)); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func sortUpdates(updates []Update) {
+	sort.Slice(updates, func(i, j int) bool {
+		if updates[i].Ecosystem != updates[j].Ecosystem {
+			return updates[i].Ecosystem < updates[j].Ecosystem
+		}
+		return updates[i].Name < updates[j].Name
+	})
+}
@@ -0,0 +1,218 @@
+package depupdate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseGoList(t *testing.T) {
+	input := `{
+	"Path": "example.com/app",
+	"Main": true
+}
+{
+	"Path": "github.com/spf13/cobra",
+	"Version": "v1.8.0",
+	"Update": {"Path": "github.com/spf13/cobra", "Version": "v1.10.2"}
+}
+{
+	"Path": "golang.org/x/sys",
+	"Version": "v0.10.0",
+	"Indirect": true,
+	"Update": {"Path": "golang.org/x/sys", "Version": "v0.20.0"}
+}
+{
+	"Path": "github.com/google/uuid",
+	"Version": "v1.6.0"
+}`
+
+	updates, err := ParseGoList(strings.NewReader(input), false)
+	if err != nil {
+		t.Fatalf("ParseGoList: %v", err)
+	}
+	if len(updates) != 1 {
+		t.Fatalf("got %d updates, want 1: %+v", len(updates), updates)
+	}
+	u := updates[0]
+	if u.Name != "github.com/spf13/cobra" || u.Current != "v1.8.0" || u.Latest != "v1.10.2" {
+		t.Errorf("unexpected update: %+v", u)
+	}
+	if u.Ecosystem != "go" || u.Source != SourceGoList {
+		t.Errorf("unexpected ecosystem/source: %+v", u)
+	}
+
+	withIndirect, err := ParseGoList(strings.NewReader(input), true)
+	if err != nil {
+		t.Fatalf("ParseGoList: %v", err)
+	}
+	if len(withIndirect) != 2 {
+		t.Errorf("got %d updates with indirect, want 2", len(withIndirect))
+	}
+}
+
+func TestParseReport_Flat(t *testing.T) {
+	data := []byte(`[
+		{"ecosystem": "npm", "name": "left-pad", "current": "1.0.0", "latest": "1.3.0"},
+		{"ecosystem": "npm", "name": "", "current": "1.0.0", "latest": "2.0.0"}
+	]`)
+	updates, err := ParseReport(data)
+	if err != nil {
+		t.Fatalf("ParseReport: %v", err)
+	}
+	if len(updates) != 1 || updates[0].Name != "left-pad" {
+		t.Fatalf("unexpected updates: %+v", updates)
+	}
+
+	wrapped := []byte(`{"updates": [{"ecosystem": "pip", "name": "requests", "current": "2.0", "latest": "2.31"}]}`)
+	updates, err = ParseReport(wrapped)
+	if err != nil {
+		t.Fatalf("ParseReport wrapped: %v", err)
+	}
+	if len(updates) != 1 || updates[0].Ecosystem != "pip" {
+		t.Fatalf("unexpected wrapped updates: %+v", updates)
+	}
+}
+
+func TestParseReport_Renovate(t *testing.T) {
+	data := []byte(`{
+		"repositories": {
+			"acme/app": {
+				"packageFiles": {
+					"npm": [{"deps": [
+						{"depName": "react", "currentValue": "17.0.2", "updates": [{"newVersion": "18.2.0"}]},
+						{"depName": "lodash", "currentValue": "4.17.21", "updates": []}
+					]}],
+					"gomod": [{"deps": [
+						{"depName": "github.com/pkg/errors", "currentValue": "v0.8.0", "updates": [{"newValue": "v0.9.1"}]}
+					]}]
+				}
+			}
+		}
+	}`)
+	updates, err := ParseReport(data)
+	if err != nil {
+		t.Fatalf("ParseReport: %v", err)
+	}
+	if len(updates) != 2 {
+		t.Fatalf("got %d updates, want 2: %+v", len(updates), updates)
+	}
+	if updates[0].Ecosystem != "gomod" || updates[0].Latest != "v0.9.1" {
+		t.Errorf("unexpected first update: %+v", updates[0])
+	}
+	if updates[1].Name != "react" || updates[1].Latest != "18.2.0" {
+		t.Errorf("unexpected second update: %+v", updates[1])
+	}
+}
+
+func TestParseReport_RenovateCollapsesRepeats(t *testing.T) {
+	// Minor and major branches of one dependency, used by two package files
+	data := []byte(`{
+		"repositories": {
+			"acme/app": {
+				"packageFiles": {
+					"npm": [
+						{"deps": [{"depName": "react", "currentValue": "17.0.2", "updates": [{"newVersion": "17.0.9"}, {"newVersion": "18.2.0"}]}]},
+						{"deps": [{"depName": "react", "currentValue": "17.0.2", "updates": [{"newVersion": "17.0.9"}]}]}
+					]
+				}
+			}
+		}
+	}`)
+	updates, err := ParseReport(data)
+	if err != nil {
+		t.Fatalf("ParseReport: %v", err)
+	}
+	if len(updates) != 1 || updates[0].Latest != "18.2.0" {
+		t.Errorf("updates = %+v, want react to 18.2.0 alone", updates)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"v1.10.0", "v1.9.9", 1},
+		{"18.2.0", "17.0.9", 1},
+		{"1.2", "1.2.1", -1},
+		{"2.0.0-rc.1", "1.9.0", 1},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSupersedes(t *testing.T) {
+	filed := Update{Ecosystem: "npm", Name: "react", Current: "17.0.2", Latest: "18.2.0"}.Description()
+	tests := []struct {
+		name   string
+		latest string
+		want   bool
+	}{
+		{"newer release", "18.3.1", true},
+		{"same release", "18.2.0", false},
+		// A stale report mustn't move the bead back
+		{"downgrade", "18.1.0", false},
+	}
+	for _, tt := range tests {
+		u := Update{Ecosystem: "npm", Name: "react", Current: "17.0.2", Latest: tt.latest}
+		if got := u.Supersedes(filed); got != tt.want {
+			t.Errorf("%s: Supersedes = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if !(Update{Latest: "1.0.0"}).Supersedes("dependency: react\n") {
+		t.Error("a bead with no recorded version should be superseded")
+	}
+}
+
+func TestFilter(t *testing.T) {
+	updates := []Update{
+		{Name: "golang.org/x/net"},
+		{Name: "golang.org/x/net/v2"},
+		{Name: "github.com/spf13/cobra"},
+	}
+	kept := Filter(updates, []string{"golang.org/x/*"})
+	if len(kept) != 1 || kept[0].Name != "github.com/spf13/cobra" {
+		t.Errorf("Filter kept %+v", kept)
+	}
+	if got := Filter(updates, nil); len(got) != 3 {
+		t.Errorf("Filter with no globs kept %d, want 3", len(got))
+	}
+}
+
+func TestUpdateTitle(t *testing.T) {
+	u := Update{Name: "github.com/spf13/cobra", Current: "v1.8.0", Latest: "v1.10.2"}
+	if got, want := u.Title(), "Bump github.com/spf13/cobra from v1.8.0 to v1.10.2"; got != want {
+		t.Errorf("Title() = %q, want %q", got, want)
+	}
+}
+
+func TestUpdateKey(t *testing.T) {
+	older := Update{Ecosystem: "go", Name: "github.com/spf13/cobra", Current: "v1.8.0", Latest: "v1.9.0"}
+	newer := Update{Ecosystem: "go", Name: "github.com/spf13/cobra", Current: "v1.8.0", Latest: "v1.10.2"}
+	if older.Key() != newer.Key() {
+		t.Errorf("keys differ across releases: %q, %q", older.Key(), newer.Key())
+	}
+	if other := (Update{Ecosystem: "npm", Name: "github.com/spf13/cobra"}); other.Key() == older.Key() {
+		t.Errorf("key %q doesn't tell ecosystems apart", other.Key())
+	}
+
+	tests := []struct {
+		name        string
+		description string
+		want        string
+	}{
+		{"filed bead", older.Description(), older.Key()},
+		{"no dependency", "Update things by hand", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := KeyOf(tt.description); got != tt.want {
+				t.Errorf("KeyOf() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,356 @@
+// Package diskusage accounts for the disk a town consumes and finds what
+// can safely be reclaimed.
+//
+// Usage is broken down per rig into clones, worktrees, build caches, beads,
+// logs, and agent session recordings (Claude Code transcripts under
+// ~/.claude/projects). Candidates are files and directories gc may remove:
+// recordings of agents that no longer exist and oversized caches. Worktrees
+// and git objects are reclaimed by their owners (polecat nuke, git gc), and
+// logs by the retention package, not here.
+package diskusage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Usage categories.
+const (
+	CategoryClones     = "clones"
+	CategoryWorktrees  = "worktrees"
+	CategoryCaches     = "caches"
+	CategoryBeads      = "beads"
+	CategoryLogs       = "logs"
+	CategoryRecordings = "recordings"
+	CategoryOther      = "other"
+)
+
+// Categories lists usage categories in display order.
+var Categories = []string{CategoryClones, CategoryWorktrees, CategoryCaches, CategoryBeads, CategoryLogs, CategoryRecordings, CategoryOther}
+
+// Entry is the disk used by one path.
+type Entry struct {
+	Rig      string `json:"rig,omitempty"` // empty for town-level entries
+	Category string `json:"category"`
+	Path     string `json:"path"`
+	Bytes    int64  `json:"bytes"`
+}
+
+// Candidate is something gc can remove.
+type Candidate struct {
+	Rig    string `json:"rig,omitempty"`
+	Kind   string `json:"kind"` // log, recording, cache, worktree, objects
+	Path   string `json:"path"`
+	Bytes  int64  `json:"bytes"`
+	Reason string `json:"reason"`
+}
+
+// DirSize returns the bytes used by regular files under path, without
+// following symlinks. Unreadable entries are skipped.
+func DirSize(path string) int64 {
+	var total int64
+	_ = filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			}
+		}
+		return nil
+	})
+	return total
+}
+
+// rigCategories maps a rig's top-level entries to usage categories.
+var rigCategories = map[string]string{
+	".repo.git": CategoryClones,
+	"mayor":     CategoryClones,
+	"refinery":  CategoryWorktrees,
+	"polecats":  CategoryWorktrees,
+	"crew":      CategoryWorktrees,
+	".beads":    CategoryBeads,
+	"logs":      CategoryLogs,
+}
+
+// ScanRig returns a rig's usage. cacheDir is the rig's build cache root
+// (counted as caches even when it lives inside the rig); projectsDir is the
+// agent transcript root, or "" to skip recordings.
+func ScanRig(rigName, rigPath, cacheDir, projectsDir string) []Entry {
+	var entries []Entry
+	cacheBytes := int64(0)
+	if cacheDir != "" {
+		cacheBytes = DirSize(cacheDir)
+		if cacheBytes > 0 {
+			entries = append(entries, Entry{Rig: rigName, Category: CategoryCaches, Path: cacheDir, Bytes: cacheBytes})
+		}
+	}
+
+	children, _ := os.ReadDir(rigPath)
+	for _, c := range children {
+		path := filepath.Join(rigPath, c.Name())
+		if !c.IsDir() {
+			if info, err := c.Info(); err == nil && info.Size() > 0 {
+				entries = append(entries, Entry{Rig: rigName, Category: CategoryOther, Path: path, Bytes: info.Size()})
+			}
+			continue
+		}
+		category, ok := rigCategories[c.Name()]
+		if !ok {
+			category = CategoryOther
+		}
+		size := DirSize(path)
+		if cacheDir != "" && isWithin(cacheDir, path) {
+			size -= cacheBytes
+		}
+		if size > 0 {
+			entries = append(entries, Entry{Rig: rigName, Category: category, Path: path, Bytes: size})
+		}
+	}
+
+	if projectsDir != "" {
+		for _, dir := range RecordingDirs(projectsDir, rigPath) {
+			if size := DirSize(dir); size > 0 {
+				entries = append(entries, Entry{Rig: rigName, Category: CategoryRecordings, Path: dir, Bytes: size})
+			}
+		}
+	}
+	return entries
+}
+
+// ScanTown returns usage outside the rigs: town logs and daemon state.
+func ScanTown(townRoot string) []Entry {
+	var entries []Entry
+	for _, d := range []struct{ path, category string }{
+		{filepath.Join(townRoot, "logs"), CategoryLogs},
+		{filepath.Join(townRoot, "daemon"), CategoryLogs},
+		{filepath.Join(townRoot, ".beads"), CategoryBeads},
+		{filepath.Join(townRoot, ".runtime"), CategoryOther},
+	} {
+		if size := DirSize(d.path); size > 0 {
+			entries = append(entries, Entry{Category: d.category, Path: d.path, Bytes: size})
+		}
+	}
+	return entries
+}
+
+// Totals sums entries by key.
+func Totals(entries []Entry, key func(Entry) string) map[string]int64 {
+	totals := make(map[string]int64)
+	for _, e := range entries {
+		totals[key(e)] += e.Bytes
+	}
+	return totals
+}
+
+// Sum returns the bytes of all entries.
+func Sum(entries []Entry) int64 {
+	var total int64
+	for _, e := range entries {
+		total += e.Bytes
+	}
+	return total
+}
+
+// projectKey normalizes a path the way agent runtimes name per-project
+// transcript directories: every character but letters and digits becomes "-".
+func projectKey(path string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '-'
+	}, filepath.Clean(path))
+}
+
+// RecordingDirs returns the transcript directories in projectsDir recorded
+// for sessions under root. Directory names are lossy (/x/gt-tools and
+// /x/gt/tools share one), so a directory belongs to root only when every
+// transcript in it records a working directory under root; anything else is
+// left to its owner.
+func RecordingDirs(projectsDir, root string) []string {
+	var dirs []string
+	for _, r := range recordings(projectsDir, root) {
+		dirs = append(dirs, r.dir)
+	}
+	return dirs
+}
+
+// recording is a transcript directory and the working directories its
+// sessions were started in.
+type recording struct {
+	dir  string
+	cwds []string
+}
+
+func recordings(projectsDir, root string) []recording {
+	prefix := projectKey(root)
+	entries, err := os.ReadDir(projectsDir)
+	if err != nil {
+		return nil
+	}
+	var out []recording
+	for _, e := range entries {
+		name := projectKey(e.Name())
+		if !e.IsDir() || (name != prefix && !strings.HasPrefix(name, prefix+"-")) {
+			continue
+		}
+		dir := filepath.Join(projectsDir, e.Name())
+		cwds := transcriptCwds(dir)
+		owned := len(cwds) > 0
+		for _, cwd := range cwds {
+			owned = owned && cwd != "" && isWithin(cwd, root)
+		}
+		if owned {
+			out = append(out, recording{dir: dir, cwds: cwds})
+		}
+	}
+	return out
+}
+
+// transcriptCwds returns the working directory each transcript in dir was
+// recorded in, "" for a transcript that records none.
+func transcriptCwds(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var cwds []string
+	for _, e := range entries {
+		if e.Type().IsRegular() && IsTranscript(e.Name()) {
+			cwds = append(cwds, transcriptCwd(filepath.Join(dir, e.Name())))
+		}
+	}
+	return cwds
+}
+
+// transcriptCwd returns the first cwd recorded in a transcript's leading
+// lines, or "" if there is none.
+func transcriptCwd(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	dec := json.NewDecoder(f)
+	for i := 0; i < 50; i++ {
+		var line struct {
+			Cwd string `json:"cwd"`
+		}
+		if err := dec.Decode(&line); err != nil {
+			return ""
+		}
+		if line.Cwd != "" && filepath.IsAbs(line.Cwd) {
+			return filepath.Clean(line.Cwd)
+		}
+	}
+	return ""
+}
+
+// OrphanRecordings returns transcript directories under polecatsDir's
+// polecats that no longer exist.
+func OrphanRecordings(rigName, projectsDir, polecatsDir string, bytesThreshold int64) []Candidate {
+	var out []Candidate
+	for _, r := range recordings(projectsDir, polecatsDir) {
+		alive := false
+		for _, cwd := range r.cwds {
+			rel, err := filepath.Rel(polecatsDir, cwd)
+			if err != nil || rel == "." {
+				alive = true
+				break
+			}
+			name, _, _ := strings.Cut(rel, string(filepath.Separator))
+			if info, err := os.Stat(filepath.Join(polecatsDir, name)); err == nil && info.IsDir() {
+				alive = true
+				break
+			}
+		}
+		if alive {
+			continue
+		}
+		if size := DirSize(r.dir); size >= bytesThreshold {
+			out = append(out, Candidate{Rig: rigName, Kind: "recording", Path: r.dir, Bytes: size, Reason: "polecat no longer exists"})
+		}
+	}
+	return out
+}
+
+// IsTranscript reports whether name is an agent session transcript.
+func IsTranscript(name string) bool {
+	return strings.HasSuffix(name, ".jsonl")
+}
+
+// Remove deletes a candidate's path.
+func Remove(c Candidate) error {
+	if c.Path == "" || c.Path == "/" {
+		return fmt.Errorf("refusing to remove %q", c.Path)
+	}
+	if c.Kind == "cache" {
+		// Module caches are read-only; make them writable so they can go
+		_ = filepath.WalkDir(c.Path, func(path string, d fs.DirEntry, err error) error {
+			if err == nil && d.IsDir() {
+				_ = os.Chmod(path, 0755) //nolint:gosec // G302: cache dirs under the rig
+			}
+			return nil
+		})
+	}
+	return os.RemoveAll(c.Path)
+}
+
+// SortCandidates orders candidates largest first.
+func SortCandidates(cs []Candidate) {
+	sort.SliceStable(cs, func(i, j int) bool { return cs[i].Bytes > cs[j].Bytes })
+}
+
+// ParseSize parses a size like "500M", "2G", "1.5GB", or "1024" (bytes).
+func ParseSize(size string) (int64, error) {
+	s := strings.TrimSpace(strings.ToUpper(size))
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "IB"), "B")
+	mult := int64(1)
+	if n := len(s); n > 0 {
+		switch s[n-1] {
+		case 'K':
+			mult = 1 << 10
+		case 'M':
+			mult = 1 << 20
+		case 'G':
+			mult = 1 << 30
+		case 'T':
+			mult = 1 << 40
+		}
+		if mult > 1 {
+			s = s[:n-1]
+		}
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || f < 0 {
+		return 0, fmt.Errorf("invalid size %q (want e.g. 500M, 2G)", size)
+	}
+	return int64(f * float64(mult)), nil
+}
+
+// FormatSize renders bytes in human-readable form.
+func FormatSize(b int64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(b)/float64(div), "KMGTPE"[exp])
+}
+
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
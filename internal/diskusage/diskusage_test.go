@@ -0,0 +1,154 @@
+package diskusage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path string, size int, mtime time.Time) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !mtime.IsZero() {
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// writeTranscript writes a transcript recorded in cwd, padded to size bytes.
+func writeTranscript(t *testing.T, path, cwd string, size int) {
+	t.Helper()
+	line := fmt.Sprintf(`{"type":"user","cwd":%q}`, cwd)
+	writeFile(t, path, 0, time.Time{})
+	if err := os.WriteFile(path, []byte(line+strings.Repeat(" ", max(size-len(line)-1, 0))+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+		err  bool
+	}{
+		{"1024", 1024, false},
+		{"500M", 500 << 20, false},
+		{"2G", 2 << 30, false},
+		{"1.5GB", 3 << 29, false},
+		{"4KiB", 4 << 10, false},
+		{"1t", 1 << 40, false},
+		{"", 0, true},
+		{"lots", 0, true},
+		{"-1M", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSize(tt.in)
+		if (err != nil) != tt.err {
+			t.Errorf("ParseSize(%q) error = %v, want error %v", tt.in, err, tt.err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseSize(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		in   int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1536, "1.5 KB"},
+		{5 << 30, "5.0 GB"},
+	}
+	for _, tt := range tests {
+		if got := FormatSize(tt.in); got != tt.want {
+			t.Errorf("FormatSize(%d) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestScanRig(t *testing.T) {
+	rigPath := filepath.Join(t.TempDir(), "gastown")
+	writeFile(t, filepath.Join(rigPath, ".repo.git", "objects", "pack"), 100, time.Time{})
+	writeFile(t, filepath.Join(rigPath, "polecats", "nux", "main.go"), 20, time.Time{})
+	writeFile(t, filepath.Join(rigPath, ".runtime", "cache", "go-build", "x"), 50, time.Time{})
+	writeFile(t, filepath.Join(rigPath, ".runtime", "state.json"), 5, time.Time{})
+	writeFile(t, filepath.Join(rigPath, ".beads", "issues.jsonl"), 7, time.Time{})
+
+	projects := t.TempDir()
+	nux := filepath.Join(rigPath, "polecats", "nux")
+	writeTranscript(t, filepath.Join(projects, projectKey(nux), "s.jsonl"), nux, 300)
+	writeTranscript(t, filepath.Join(projects, "-somewhere-else", "s.jsonl"), "/somewhere/else", 1000)
+
+	entries := ScanRig("gastown", rigPath, filepath.Join(rigPath, ".runtime", "cache"), projects)
+	totals := Totals(entries, func(e Entry) string { return e.Category })
+	want := map[string]int64{
+		CategoryClones:     100,
+		CategoryWorktrees:  20,
+		CategoryCaches:     50,
+		CategoryBeads:      7,
+		CategoryOther:      5,
+		CategoryRecordings: 300,
+	}
+	for category, bytes := range want {
+		if totals[category] != bytes {
+			t.Errorf("%s = %d, want %d", category, totals[category], bytes)
+		}
+	}
+	if got := Sum(entries); got != 482 {
+		t.Errorf("Sum = %d, want 482", got)
+	}
+}
+
+func TestOrphanRecordings(t *testing.T) {
+	polecats := filepath.Join(t.TempDir(), "gastown", "polecats")
+	if err := os.MkdirAll(filepath.Join(polecats, "nux"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	projects := t.TempDir()
+	live := filepath.Join(projects, projectKey(filepath.Join(polecats, "nux", "gastown")))
+	gone := filepath.Join(projects, projectKey(filepath.Join(polecats, "toast")))
+	writeTranscript(t, filepath.Join(live, "a.jsonl"), filepath.Join(polecats, "nux", "gastown"), 200)
+	writeTranscript(t, filepath.Join(gone, "b.jsonl"), filepath.Join(polecats, "toast"), 200)
+
+	got := OrphanRecordings("gastown", projects, polecats, 0)
+	if len(got) != 1 || got[0].Path != gone {
+		t.Fatalf("OrphanRecordings = %+v, want only %s", got, gone)
+	}
+	if got := OrphanRecordings("gastown", projects, polecats, 201); len(got) != 0 {
+		t.Errorf("OrphanRecordings below threshold = %+v, want none", got)
+	}
+}
+
+func TestRecordingDirs_SiblingProjects(t *testing.T) {
+	parent := t.TempDir()
+	town := filepath.Join(parent, "gt")
+	projects := t.TempDir()
+
+	own := filepath.Join(projects, projectKey(filepath.Join(town, "gastown")))
+	writeTranscript(t, filepath.Join(own, "a.jsonl"), filepath.Join(town, "gastown"), 10)
+	// Siblings whose keys share the town's prefix.
+	writeTranscript(t, filepath.Join(projects, projectKey(filepath.Join(parent, "gt-tools")), "b.jsonl"), filepath.Join(parent, "gt-tools"), 10)
+	writeTranscript(t, filepath.Join(projects, projectKey(filepath.Join(parent, "gt.old")), "c.jsonl"), filepath.Join(parent, "gt.old"), 10)
+	// A key the town shares with a sibling: gt/tools and gt-tools.
+	shared := filepath.Join(projects, projectKey(filepath.Join(town, "tools")))
+	writeTranscript(t, filepath.Join(shared, "d.jsonl"), filepath.Join(parent, "gt-tools"), 10)
+	// No recorded cwd: cannot be tied to the town.
+	writeFile(t, filepath.Join(projects, projectKey(filepath.Join(town, "mayor")), "e.jsonl"), 10, time.Time{})
+
+	if got := RecordingDirs(projects, town); len(got) != 1 || got[0] != own {
+		t.Errorf("RecordingDirs = %v, want only %s", got, own)
+	}
+}
@@ -0,0 +1,77 @@
+package doctor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/reconcile"
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+// OrphanedStateCheck detects state left behind by crashed agents and
+// processes: agent beads still marked running, MRs and merge slots held by
+// dead agents, and stale identity locks. The daemon reconciles the same
+// state when it starts.
+type OrphanedStateCheck struct {
+	FixableCheck
+}
+
+// NewOrphanedStateCheck creates a new orphaned state check.
+func NewOrphanedStateCheck() *OrphanedStateCheck {
+	return &OrphanedStateCheck{
+		FixableCheck: FixableCheck{
+			BaseCheck: BaseCheck{
+				CheckName:        "orphaned-state",
+				CheckDescription: "Detect agent, MR, merge slot, and lock state orphaned by crashes",
+				CheckCategory:    CategoryCleanup,
+			},
+		},
+	}
+}
+
+func (c *OrphanedStateCheck) options(ctx *CheckContext) reconcile.Options {
+	rigs, _ := discoverRigs(ctx.TownRoot)
+	return reconcile.Options{
+		TownRoot: ctx.TownRoot,
+		Rigs:     rigs,
+		Alive:    tmux.NewTmux().IsAgentAlive,
+	}
+}
+
+// Run scans for orphaned state.
+func (c *OrphanedStateCheck) Run(ctx *CheckContext) *CheckResult {
+	repairs := reconcile.Scan(c.options(ctx))
+	if len(repairs) == 0 {
+		return &CheckResult{
+			Name:    c.Name(),
+			Status:  StatusOK,
+			Message: "No orphaned state found",
+		}
+	}
+
+	details := make([]string, len(repairs))
+	for i, r := range repairs {
+		details[i] = r.String()
+	}
+	return &CheckResult{
+		Name:    c.Name(),
+		Status:  StatusWarning,
+		Message: fmt.Sprintf("Found %d piece(s) of state orphaned by crashes", len(repairs)),
+		Details: details,
+		FixHint: "Run 'gt doctor --fix' to reconcile",
+	}
+}
+
+// Fix rescans and repairs, so nothing that recovered since Run is touched.
+func (c *OrphanedStateCheck) Fix(ctx *CheckContext) error {
+	var failed []string
+	for _, r := range reconcile.Run(c.options(ctx), "gt doctor") {
+		if r.Err != "" {
+			failed = append(failed, fmt.Sprintf("%s: %s", r, r.Err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d repair(s) failed: %s", len(failed), strings.Join(failed, "; "))
+	}
+	return nil
+}
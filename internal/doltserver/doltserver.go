@@ -45,6 +45,7 @@ import (
 
 	"github.com/gofrs/flock"
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/sandbox"
 	"github.com/steveyegge/gastown/internal/util"
 )
 
@@ -94,11 +95,17 @@ type Config struct {
 }
 
 // DefaultConfig returns the default Dolt server configuration.
+// A sandbox overlay's server listens on sandbox.DoltPort so it can run
+// alongside the real town's.
 func DefaultConfig(townRoot string) *Config {
 	daemonDir := filepath.Join(townRoot, "daemon")
+	port := DefaultPort
+	if sandbox.IsOverlay(townRoot) {
+		port = sandbox.DoltPort
+	}
 	return &Config{
 		TownRoot:       townRoot,
-		Port:           DefaultPort,
+		Port:           port,
 		User:           DefaultUser,
 		DataDir:        filepath.Join(townRoot, ".dolt-data"),
 		LogFile:        filepath.Join(daemonDir, "dolt.log"),
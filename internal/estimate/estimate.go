@@ -0,0 +1,218 @@
+// Package estimate handles effort estimates on beads. An estimate is a
+// t-shirt size or a number of hours, kept in an estimate:<value> label so
+// it travels with the bead across rigs and tools.
+package estimate
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LabelPrefix prefixes the label that holds a bead's estimate.
+const LabelPrefix = "estimate:"
+
+// Sizes are the t-shirt sizes, smallest first.
+var Sizes = []string{"XS", "S", "M", "L", "XL"}
+
+// SizeHours is the effort each t-shirt size stands for.
+var SizeHours = map[string]float64{
+	"XS": 1,
+	"S":  2,
+	"M":  4,
+	"L":  8,
+	"XL": 16,
+}
+
+// Estimate is a bead's effort estimate.
+type Estimate struct {
+	Value string  `json:"value"` // as labeled: "M" or "6h"
+	Hours float64 `json:"hours"`
+}
+
+// Parse parses an estimate: a t-shirt size (XS, S, M, L, XL; any case)
+// or hours ("6h", "1.5h", or a bare number).
+func Parse(s string) (Estimate, error) {
+	s = strings.TrimSpace(s)
+	if h, ok := SizeHours[strings.ToUpper(s)]; ok {
+		return Estimate{Value: strings.ToUpper(s), Hours: h}, nil
+	}
+	h, err := strconv.ParseFloat(strings.TrimSuffix(strings.ToLower(s), "h"), 64)
+	if err != nil || h <= 0 || math.IsInf(h, 0) || math.IsNaN(h) {
+		return Estimate{}, fmt.Errorf("invalid estimate %q: want %s or hours (e.g. 6h)", s, strings.Join(Sizes, ", "))
+	}
+	return Estimate{Value: strconv.FormatFloat(h, 'f', -1, 64) + "h", Hours: h}, nil
+}
+
+// Label returns the label that records e.
+func (e Estimate) Label() string {
+	return LabelPrefix + e.Value
+}
+
+// Size returns e's t-shirt size; an hours estimate maps to the smallest
+// size that covers it.
+func (e Estimate) Size() string {
+	if _, ok := SizeHours[e.Value]; ok {
+		return e.Value
+	}
+	for _, size := range Sizes {
+		if e.Hours <= SizeHours[size] {
+			return size
+		}
+	}
+	return Sizes[len(Sizes)-1]
+}
+
+// FromLabels returns the estimate recorded in a bead's labels. If a bead
+// somehow carries several, the last valid one wins.
+func FromLabels(labels []string) (Estimate, bool) {
+	var est Estimate
+	found := false
+	for _, l := range labels {
+		if v, ok := strings.CutPrefix(l, LabelPrefix); ok {
+			if e, err := Parse(v); err == nil {
+				est, found = e, true
+			}
+		}
+	}
+	return est, found
+}
+
+// Labels returns the estimate labels among labels, for removal.
+func Labels(labels []string) []string {
+	var out []string
+	for _, l := range labels {
+		if strings.HasPrefix(l, LabelPrefix) {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// Item is one bead in a rollup or comparison.
+type Item struct {
+	ID       string
+	Group    string // convoy or rig the bead rolls up into
+	Estimate *Estimate
+	Closed   bool
+	// Cycle is the time from the bead being picked up to it closing; zero
+	// when not known.
+	Cycle time.Duration
+}
+
+// Rollup totals the estimates of a group of beads.
+type Rollup struct {
+	Name           string  `json:"name"`
+	Issues         int     `json:"issues"`
+	Closed         int     `json:"closed"`
+	Estimated      int     `json:"estimated"`
+	Hours          float64 `json:"hours"`
+	DoneHours      float64 `json:"done_hours"`
+	RemainingHours float64 `json:"remaining_hours"`
+}
+
+// Coverage is the fraction of the group's beads that have an estimate.
+func (r Rollup) Coverage() float64 {
+	if r.Issues == 0 {
+		return 0
+	}
+	return float64(r.Estimated) / float64(r.Issues)
+}
+
+// Roll totals items by group, in group order.
+func Roll(items []Item) []Rollup {
+	byGroup := make(map[string]*Rollup)
+	var order []string
+	for _, it := range items {
+		r := byGroup[it.Group]
+		if r == nil {
+			r = &Rollup{Name: it.Group}
+			byGroup[it.Group] = r
+			order = append(order, it.Group)
+		}
+		r.Issues++
+		if it.Closed {
+			r.Closed++
+		}
+		if it.Estimate == nil {
+			continue
+		}
+		r.Estimated++
+		r.Hours += it.Estimate.Hours
+		if it.Closed {
+			r.DoneHours += it.Estimate.Hours
+		} else {
+			r.RemainingHours += it.Estimate.Hours
+		}
+	}
+	sort.Strings(order)
+	out := make([]Rollup, 0, len(order))
+	for _, name := range order {
+		out = append(out, *byGroup[name])
+	}
+	return out
+}
+
+// Accuracy compares one size's estimate with the cycle times of the
+// closed beads estimated at that size.
+type Accuracy struct {
+	Size           string  `json:"size"`
+	Count          int     `json:"count"`
+	EstimatedHours float64 `json:"estimated_hours"` // mean estimate
+	MedianHours    float64 `json:"median_cycle_hours"`
+	// Ratio is median cycle time over mean estimate: above 1, work of
+	// this size takes longer than estimated.
+	Ratio float64 `json:"ratio"`
+}
+
+// Compare reports estimate accuracy per size, smallest first, from the
+// closed items with both an estimate and a cycle time.
+func Compare(items []Item) []Accuracy {
+	estimated := make(map[string][]float64)
+	cycles := make(map[string][]float64)
+	for _, it := range items {
+		if !it.Closed || it.Estimate == nil || it.Cycle <= 0 {
+			continue
+		}
+		size := it.Estimate.Size()
+		estimated[size] = append(estimated[size], it.Estimate.Hours)
+		cycles[size] = append(cycles[size], it.Cycle.Hours())
+	}
+	var out []Accuracy
+	for _, size := range Sizes {
+		if len(cycles[size]) == 0 {
+			continue
+		}
+		a := Accuracy{
+			Size:           size,
+			Count:          len(cycles[size]),
+			EstimatedHours: mean(estimated[size]),
+			MedianHours:    median(cycles[size]),
+		}
+		if a.EstimatedHours > 0 {
+			a.Ratio = a.MedianHours / a.EstimatedHours
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+func mean(xs []float64) float64 {
+	sum := 0.0
+	for _, x := range xs {
+		sum += x
+	}
+	return sum / float64(len(xs))
+}
+
+func median(xs []float64) float64 {
+	s := append([]float64(nil), xs...)
+	sort.Float64s(s)
+	if n := len(s); n%2 == 0 {
+		return (s[n/2-1] + s[n/2]) / 2
+	}
+	return s[len(s)/2]
+}
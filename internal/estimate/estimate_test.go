@@ -0,0 +1,100 @@
+package estimate
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		value   string
+		hours   float64
+		size    string
+		wantErr bool
+	}{
+		{"M", "M", 4, "M", false},
+		{"xl", "XL", 16, "XL", false},
+		{"6h", "6h", 6, "L", false},
+		{"1.5", "1.5h", 1.5, "S", false},
+		{"40h", "40h", 40, "XL", false},
+		{"XXL", "", 0, "", true},
+		{"0h", "", 0, "", true},
+		{"-2", "", 0, "", true},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Parse(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if got.Value != tt.value || got.Hours != tt.hours || got.Size() != tt.size {
+			t.Errorf("Parse(%q) = %+v size %s, want %s %v %s", tt.in, got, got.Size(), tt.value, tt.hours, tt.size)
+		}
+	}
+}
+
+func TestFromLabels(t *testing.T) {
+	if _, ok := FromLabels([]string{"bug", "estimate:bogus"}); ok {
+		t.Error("invalid estimate label accepted")
+	}
+	got, ok := FromLabels([]string{"estimate:S", "gt:task", "estimate:3h"})
+	if !ok || got.Value != "3h" {
+		t.Errorf("FromLabels = %+v, %v; want the last one, 3h", got, ok)
+	}
+	if l := got.Label(); l != "estimate:3h" {
+		t.Errorf("Label() = %q", l)
+	}
+	if old := Labels([]string{"estimate:S", "gt:task", "estimate:3h"}); len(old) != 2 {
+		t.Errorf("Labels() = %v", old)
+	}
+}
+
+func TestRoll(t *testing.T) {
+	m := Estimate{Value: "M", Hours: 4}
+	l := Estimate{Value: "L", Hours: 8}
+	rollups := Roll([]Item{
+		{ID: "b-1", Group: "beta", Estimate: &m, Closed: true},
+		{ID: "b-2", Group: "beta", Estimate: &l},
+		{ID: "b-3", Group: "beta"},
+		{ID: "a-1", Group: "alpha", Estimate: &m},
+	})
+	if len(rollups) != 2 || rollups[0].Name != "alpha" {
+		t.Fatalf("Roll = %+v", rollups)
+	}
+	b := rollups[1]
+	if b.Issues != 3 || b.Closed != 1 || b.Estimated != 2 || b.Hours != 12 || b.DoneHours != 4 || b.RemainingHours != 8 {
+		t.Errorf("beta = %+v", b)
+	}
+	if c := b.Coverage(); c < 0.66 || c > 0.67 {
+		t.Errorf("coverage = %v", c)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	s := Estimate{Value: "S", Hours: 2}
+	m := Estimate{Value: "M", Hours: 4}
+	threeH := Estimate{Value: "3h", Hours: 3}
+	acc := Compare([]Item{
+		{Estimate: &m, Closed: true, Cycle: 4 * time.Hour},
+		{Estimate: &threeH, Closed: true, Cycle: 8 * time.Hour},
+		{Estimate: &m, Closed: true, Cycle: 12 * time.Hour},
+		{Estimate: &s, Closed: true, Cycle: time.Hour},
+		{Estimate: &s, Closed: false, Cycle: time.Hour}, // still open
+		{Estimate: nil, Closed: true, Cycle: time.Hour}, // no estimate
+		{Estimate: &s, Closed: true},                    // no cycle time
+	})
+	if len(acc) != 2 || acc[0].Size != "S" || acc[1].Size != "M" {
+		t.Fatalf("Compare = %+v", acc)
+	}
+	if acc[0].Count != 1 || acc[0].Ratio != 0.5 {
+		t.Errorf("S = %+v", acc[0])
+	}
+	// M: estimates 4, 3, 4 (mean 3.67); cycles 4, 8, 12 (median 8).
+	if acc[1].Count != 3 || acc[1].MedianHours != 8 || acc[1].Ratio < 2.18 || acc[1].Ratio > 2.19 {
+		t.Errorf("M = %+v", acc[1])
+	}
+}
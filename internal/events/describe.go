@@ -0,0 +1,114 @@
+package events
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PayloadString returns the string payload value for key, or "" if it is
+// missing or not a string.
+func (e Event) PayloadString(key string) string {
+	if s, ok := e.Payload[key].(string); ok {
+		return s
+	}
+	return ""
+}
+
+// Mentions reports whether any string in the event's payload names one of
+// ids, either exactly or as a substring (an ID inside a branch name or a
+// mail subject).
+func (e Event) Mentions(ids map[string]bool) bool {
+	for _, v := range e.Payload {
+		switch v := v.(type) {
+		case string:
+			if ids[v] {
+				return true
+			}
+			for id := range ids {
+				if strings.Contains(v, id) {
+					return true
+				}
+			}
+		case []interface{}:
+			for _, x := range v {
+				if s, ok := x.(string); ok && ids[s] {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// Describe renders an event as a line of timeline text, such as
+// "slung gt-abc to gastown/Toast" or "merge failed: gt-mr1 (conflict)".
+// Types without a specific rendering fall back to the payload's message,
+// or else the type name.
+func Describe(e Event) string {
+	p := e.PayloadString
+	switch e.Type {
+	case TypeSling:
+		return fmt.Sprintf("slung %s to %s", p("bead"), p("target"))
+	case TypeHook:
+		return "hooked " + p("bead")
+	case TypeUnhook:
+		return "unhooked " + p("bead")
+	case TypeDone:
+		if p("mr") != "" {
+			return fmt.Sprintf("submitted %s for %s", p("mr"), p("bead"))
+		}
+		return withDetail("done with "+p("bead"), p("branch"))
+	case TypeHandoff:
+		return withDetail("handed off", p("subject"))
+	case TypeMail:
+		return fmt.Sprintf("mailed %s: %s", p("to"), p("subject"))
+	case TypeSpawn:
+		return fmt.Sprintf("spawned %s/%s", p("rig"), p("polecat"))
+	case TypeKill:
+		return withDetail("killed "+p("target"), p("reason"))
+	case TypeNudge:
+		return "nudged " + p("target")
+	case TypePolecatNudged:
+		return withDetail("nudged "+p("target"), p("reason"))
+	case TypePRCreated:
+		return withDetail("opened a PR for "+p("branch"), p("pr_url"))
+	case TypeMergeStarted:
+		return "merge started: " + p("mr")
+	case TypeMerged:
+		return withDetail("merged "+p("mr"), p("branch"))
+	case TypeMergeFailed:
+		return withDetail("merge failed: "+p("mr"), p("reason"))
+	case TypeMergeSkipped:
+		return withDetail("merge skipped: "+p("mr"), p("reason"))
+	case TypeQueueSLOBreached:
+		return fmt.Sprintf("queue SLO breached: %s %s for %s (SLO %s)", p("mr"), strings.ReplaceAll(p("state"), "_", " "), p("dwell"), p("slo"))
+	case TypeLandingFailed:
+		return withDetail("landing failed: "+p("mr"), p("reason"))
+	case TypeMRReverted:
+		return withDetail("reverted "+p("mr"), p("reason"))
+	case TypeMRBackported:
+		return withDetail("backported "+p("mr"), p("targets"))
+	case TypePRFailed:
+		return withDetail("PR failed for "+p("branch"), p("error"))
+	case TypeSessionDeath:
+		return withDetail("session "+p("session")+" died", p("reason"))
+	case TypeEscalationSent:
+		return withDetail(fmt.Sprintf("escalated %s to %s", p("target"), p("to")), p("reason"))
+	case TypeIncidentOpened:
+		return "incident opened: " + p("title")
+	case TypeIncidentResolved:
+		return withDetail("incident resolved", p("resolution"))
+	}
+	if msg := p("message"); msg != "" {
+		return msg
+	}
+	return strings.ReplaceAll(e.Type, "_", " ")
+}
+
+// withDetail appends detail to text when there is one.
+func withDetail(text, detail string) string {
+	if detail == "" {
+		return text
+	}
+	return text + " (" + detail + ")"
+}
@@ -5,6 +5,7 @@
 package events
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -12,6 +13,7 @@ import (
 	"time"
 
 	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/paging"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
@@ -68,10 +70,26 @@ const (
 	TypeMerged       = "merged"
 	TypeMergeFailed  = "merge_failed"
 	TypeMergeSkipped = "merge_skipped"
+	TypeMRReverted   = "mr_reverted"
+	TypeMRBackported = "mr_backported"
+	TypeLandingFailed = "landing_failed" // Post-merge verification failed
+	TypeMRReviewed   = "mr_reviewed"
+	TypeMRCommented  = "mr_commented"
+	TypeQueueSLOBreached = "queue_slo_breached" // MR overstayed a queue state's SLO
 
 	// GitHub PR events (emitted by gt done / refinery)
 	TypePRCreated = "pr_created"
 	TypePRFailed  = "pr_failed"
+
+	// Incident events (emitted by gt incident)
+	TypeIncidentOpened   = "incident_opened"
+	TypeIncidentResolved = "incident_resolved"
+
+	// Crash recovery (emitted by the daemon on start and gt doctor --fix)
+	TypeReconciled = "reconciled"
+
+	// Runbook runs (emitted by gt run and the daemon's runbook scheduler)
+	TypeRunbook = "runbook"
 )
 
 // EventsFile is the name of the raw events log.
@@ -142,6 +160,80 @@ func write(event Event) error {
 	return nil
 }
 
+// Read returns the events in the town's raw log for which keep returns
+// true, oldest first. A nil keep returns every event; a missing log has none.
+// Lines that don't parse are skipped.
+func Read(townRoot string, keep func(Event) bool) ([]Event, error) {
+	var out []Event
+	err := scan(townRoot, keep, func(e Event) { out = append(out, e) })
+	return out, err
+}
+
+// ReadTail returns a page of the events for which keep returns true,
+// counted back from the newest: page.Offset newest events are skipped and
+// the page.Limit before them returned, oldest first. Only the events in
+// and after the page are held while the log is read, so a long log can be
+// paged through without loading it.
+func ReadTail(townRoot string, keep func(Event) bool, page paging.Page) ([]Event, paging.Info, error) {
+	window := page.Fetch()
+	var tail []Event
+	err := scan(townRoot, keep, func(e Event) {
+		tail = append(tail, e)
+		if window > 0 && len(tail) >= 2*window {
+			tail = append(tail[:0:0], tail[len(tail)-window:]...)
+		}
+	})
+	if err != nil {
+		return nil, paging.Info{}, err
+	}
+	if window > 0 && len(tail) > window {
+		tail = tail[len(tail)-window:]
+	}
+
+	// Newest first, so the page can be cut from the front
+	for i, j := 0, len(tail)-1; i < j; i, j = i+1, j-1 {
+		tail[i], tail[j] = tail[j], tail[i]
+	}
+	evts, info := paging.Apply(tail, page)
+	out := make([]Event, len(evts))
+	for i, e := range evts {
+		out[len(evts)-1-i] = e
+	}
+	return out, info, nil
+}
+
+// scan calls fn with each event in the town's raw log that keep accepts.
+func scan(townRoot string, keep func(Event) bool, fn func(Event)) error {
+	f, err := os.Open(filepath.Join(townRoot, EventsFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		if keep == nil || keep(e) {
+			fn(e)
+		}
+	}
+	return scanner.Err()
+}
+
+// Time returns when the event happened, or the zero time if its timestamp
+// doesn't parse.
+func (e Event) Time() time.Time {
+	t, _ := time.Parse(time.RFC3339, e.Timestamp)
+	return t
+}
+
 // Payload helpers for common event structures.
 
 // SlingPayload creates a payload for sling events.
@@ -340,6 +432,20 @@ func MassDeathPayload(count int, window string, sessions []string, possibleCause
 	return p
 }
 
+// ReconcilePayload creates a payload for a repair of state orphaned by a
+// crash.
+func ReconcilePayload(kind, target, holder, action string) map[string]interface{} {
+	p := map[string]interface{}{
+		"kind":   kind,
+		"target": target,
+		"action": action,
+	}
+	if holder != "" {
+		p["holder"] = holder
+	}
+	return p
+}
+
 // SessionPayload creates a payload for session start/end events.
 // sessionID: Claude Code session UUID
 // role: Gas Town role (e.g., "gastown/crew/joe", "deacon")
@@ -359,3 +465,16 @@ func SessionPayload(sessionID, role, topic, cwd string) map[string]interface{} {
 	}
 	return p
 }
+
+// RunbookPayload creates a payload for a finished runbook run.
+// failedStep is the first step that failed, if any.
+func RunbookPayload(name string, ok bool, failedStep string) map[string]interface{} {
+	p := map[string]interface{}{
+		"runbook": name,
+		"ok":      ok,
+	}
+	if failedStep != "" {
+		p["failed_step"] = failedStep
+	}
+	return p
+}
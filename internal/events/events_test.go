@@ -0,0 +1,59 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/paging"
+)
+
+func TestReadTail(t *testing.T) {
+	townRoot := t.TempDir()
+	var lines []string
+	for i := 1; i <= 10; i++ {
+		data, err := json.Marshal(Event{Type: fmt.Sprintf("e%d", i), Actor: "gastown/witness"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, string(data))
+	}
+	lines = append(lines[:4], append([]string{"not json"}, lines[4:]...)...)
+	if err := os.WriteFile(filepath.Join(townRoot, EventsFile), []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	odd := func(e Event) bool { return e.Type != "e2" }
+
+	tests := []struct {
+		name string
+		page paging.Page
+		keep func(Event) bool
+		want string
+		info paging.Info
+	}{
+		{"all", paging.Page{}, nil, "e1 e2 e3 e4 e5 e6 e7 e8 e9 e10", paging.Info{}},
+		{"newest", paging.Page{Limit: 3}, nil, "e8 e9 e10", paging.Info{Limit: 3, More: true, NextOffset: 3}},
+		{"older page", paging.Page{Limit: 3, Offset: 3}, nil, "e5 e6 e7", paging.Info{Limit: 3, Offset: 3, More: true, NextOffset: 6}},
+		{"oldest page", paging.Page{Limit: 3, Offset: 9}, nil, "e1", paging.Info{Limit: 3, Offset: 9}},
+		{"filtered", paging.Page{Limit: 2, Offset: 7}, odd, "e1 e3", paging.Info{Limit: 2, Offset: 7}},
+		{"offset only", paging.Page{Offset: 8}, nil, "e1 e2", paging.Info{Offset: 8}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evts, info, err := ReadTail(townRoot, tt.keep, tt.page)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var types []string
+			for _, e := range evts {
+				types = append(types, e.Type)
+			}
+			if got := strings.Join(types, " "); got != tt.want || info != tt.info {
+				t.Errorf("ReadTail(%+v) = %q, %+v; want %q, %+v", tt.page, got, info, tt.want, tt.info)
+			}
+		})
+	}
+}
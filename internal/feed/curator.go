@@ -94,13 +94,14 @@ func (c *Curator) Start() error {
 	}
 
 	// Seek to end to only process new events
-	if _, err := file.Seek(0, io.SeekEnd); err != nil {
+	offset, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
 		_ = file.Close() //nolint:gosec // G104: best effort cleanup on error
 		return fmt.Errorf("seeking to end: %w", err)
 	}
 
 	c.wg.Add(1)
-	go c.run(file)
+	go c.run(file, offset)
 
 	return nil
 }
@@ -113,7 +114,7 @@ func (c *Curator) Stop() {
 
 // run is the main curator loop.
 // ZFC: No in-memory state to clean up - state is derived from the events file.
-func (c *Curator) run(file *os.File) {
+func (c *Curator) run(file *os.File, offset int64) {
 	defer c.wg.Done()
 	defer file.Close()
 
@@ -127,9 +128,18 @@ func (c *Curator) run(file *os.File) {
 			return
 
 		case <-ticker.C:
+			// Retention truncates the events file when rotating it; start
+			// over from the top
+			if info, err := file.Stat(); err == nil && info.Size() < offset {
+				_, _ = file.Seek(0, io.SeekStart)
+				reader.Reset(file)
+				offset = 0
+			}
+
 			// Read available lines
 			for {
 				line, err := reader.ReadString('\n')
+				offset += int64(len(line))
 				if err != nil {
 					break // No more data available
 				}
@@ -0,0 +1,196 @@
+// Package flaky maintains a per-rig quarantine registry of known-flaky tests.
+//
+// The registry lives at <rig>/settings/quarantine.json so it is versioned with
+// the rest of the rig's operator-managed settings. The refinery consults it
+// when tests fail: depending on the rig's quarantine_policy, quarantined tests
+// are retried more aggressively or skipped entirely.
+package flaky
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// Quarantine policies for the refinery.
+const (
+	// PolicyRetry retries runs whose only failures are quarantined tests.
+	PolicyRetry = "retry"
+	// PolicySkip ignores failures of quarantined tests.
+	PolicySkip = "skip"
+	// PolicyOff disables the registry for test runs.
+	PolicyOff = "off"
+)
+
+// DefaultQuarantineRetries is the attempt budget for runs that fail only on
+// quarantined tests under PolicyRetry.
+const DefaultQuarantineRetries = 3
+
+// CurrentVersion is the registry file format version.
+const CurrentVersion = 1
+
+// Entry is a quarantined test.
+type Entry struct {
+	// Test identifies the test as package.Test, a bare test name, or a glob
+	// (e.g., "example.com/net.TestDial*").
+	Test string `json:"test"`
+
+	// Reason explains why the test is quarantined.
+	Reason string `json:"reason,omitempty"`
+
+	// Evidence links to beads, CI runs, or MRs showing the flake.
+	Evidence []string `json:"evidence,omitempty"`
+
+	AddedBy   string     `json:"added_by,omitempty"`
+	AddedAt   time.Time  `json:"added_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// Expired reports whether the entry has expired as of now.
+func (e *Entry) Expired(now time.Time) bool {
+	return e.ExpiresAt != nil && !now.Before(*e.ExpiresAt)
+}
+
+// Matches reports whether the entry covers the test with the given package
+// and name. A bare test name matches that test in any package.
+func (e *Entry) Matches(pkg, test string) bool {
+	id := test
+	if pkg != "" {
+		id = pkg + "." + test
+	}
+	for _, candidate := range []string{id, test} {
+		if e.Test == candidate {
+			return true
+		}
+		if ok, _ := path.Match(e.Test, candidate); ok {
+			return true
+		}
+	}
+	// Subtests inherit their parent's quarantine.
+	return strings.HasPrefix(id, e.Test+"/") || strings.HasPrefix(test, e.Test+"/")
+}
+
+// Registry is the set of quarantined tests for a rig.
+type Registry struct {
+	Version int      `json:"version"`
+	Tests   []*Entry `json:"tests"`
+}
+
+// Path returns the registry path for a rig.
+func Path(rigPath string) string {
+	return filepath.Join(rigPath, "settings", "quarantine.json")
+}
+
+// Load reads a rig's registry. A missing file yields an empty registry.
+func Load(rigPath string) (*Registry, error) {
+	data, err := os.ReadFile(Path(rigPath)) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &Registry{Version: CurrentVersion}, nil
+		}
+		return nil, fmt.Errorf("reading quarantine registry: %w", err)
+	}
+
+	var reg Registry
+	if err := json.Unmarshal(data, &reg); err != nil {
+		return nil, fmt.Errorf("parsing quarantine registry: %w", err)
+	}
+	if reg.Version == 0 {
+		reg.Version = CurrentVersion
+	}
+	return &reg, nil
+}
+
+// Save writes a rig's registry atomically.
+func Save(rigPath string, reg *Registry) error {
+	if err := os.MkdirAll(filepath.Dir(Path(rigPath)), 0755); err != nil {
+		return fmt.Errorf("creating settings directory: %w", err)
+	}
+	return util.AtomicWriteJSON(Path(rigPath), reg)
+}
+
+// Get returns the entry for an exact test identifier, or nil.
+func (r *Registry) Get(test string) *Entry {
+	for _, e := range r.Tests {
+		if e.Test == test {
+			return e
+		}
+	}
+	return nil
+}
+
+// Add quarantines a test. If the test is already quarantined, the reason and
+// expiry are updated and new evidence is appended.
+func (r *Registry) Add(entry Entry) *Entry {
+	if existing := r.Get(entry.Test); existing != nil {
+		if entry.Reason != "" {
+			existing.Reason = entry.Reason
+		}
+		if entry.ExpiresAt != nil {
+			existing.ExpiresAt = entry.ExpiresAt
+		}
+		for _, ev := range entry.Evidence {
+			if !contains(existing.Evidence, ev) {
+				existing.Evidence = append(existing.Evidence, ev)
+			}
+		}
+		return existing
+	}
+
+	e := entry
+	r.Tests = append(r.Tests, &e)
+	sort.Slice(r.Tests, func(i, j int) bool { return r.Tests[i].Test < r.Tests[j].Test })
+	return &e
+}
+
+// Remove drops a test from the registry. Returns false if it wasn't present.
+func (r *Registry) Remove(test string) bool {
+	for i, e := range r.Tests {
+		if e.Test == test {
+			r.Tests = append(r.Tests[:i], r.Tests[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Prune removes expired entries and returns them.
+func (r *Registry) Prune(now time.Time) []*Entry {
+	var kept, expired []*Entry
+	for _, e := range r.Tests {
+		if e.Expired(now) {
+			expired = append(expired, e)
+		} else {
+			kept = append(kept, e)
+		}
+	}
+	r.Tests = kept
+	return expired
+}
+
+// Match returns the active (unexpired) entry covering a test, or nil.
+func (r *Registry) Match(pkg, test string, now time.Time) *Entry {
+	for _, e := range r.Tests {
+		if !e.Expired(now) && e.Matches(pkg, test) {
+			return e
+		}
+	}
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package flaky
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEntryMatches(t *testing.T) {
+	tests := []struct {
+		entry string
+		pkg   string
+		test  string
+		want  bool
+	}{
+		{"example.com/net.TestDial", "example.com/net", "TestDial", true},
+		{"TestDial", "example.com/net", "TestDial", true},
+		{"TestDial", "example.com/net", "TestDialTimeout", false},
+		{"example.com/net.TestDial*", "example.com/net", "TestDialTimeout", true},
+		{"example.com/net.TestTable", "example.com/net", "TestTable/zero", true},
+		{"example.com/net.TestDial", "example.com/other", "TestDial", false},
+	}
+
+	for _, tt := range tests {
+		e := &Entry{Test: tt.entry}
+		if got := e.Matches(tt.pkg, tt.test); got != tt.want {
+			t.Errorf("Entry{%q}.Matches(%q, %q) = %v, want %v", tt.entry, tt.pkg, tt.test, got, tt.want)
+		}
+	}
+}
+
+func TestRegistryAddMergesEvidence(t *testing.T) {
+	reg := &Registry{}
+	reg.Add(Entry{Test: "p.TestA", Reason: "network", Evidence: []string{"gt-1"}})
+	reg.Add(Entry{Test: "p.TestA", Evidence: []string{"gt-1", "gt-2"}})
+
+	if len(reg.Tests) != 1 {
+		t.Fatalf("got %d entries, want 1", len(reg.Tests))
+	}
+	e := reg.Get("p.TestA")
+	if e.Reason != "network" {
+		t.Errorf("Reason = %q, want network", e.Reason)
+	}
+	if len(e.Evidence) != 2 {
+		t.Errorf("Evidence = %v, want [gt-1 gt-2]", e.Evidence)
+	}
+}
+
+func TestRegistryPruneAndMatch(t *testing.T) {
+	now := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	reg := &Registry{}
+	reg.Add(Entry{Test: "p.TestOld", ExpiresAt: &past})
+	reg.Add(Entry{Test: "p.TestNew", ExpiresAt: &future})
+
+	if reg.Match("p", "TestOld", now) != nil {
+		t.Error("expired entry should not match")
+	}
+	if reg.Match("p", "TestNew", now) == nil {
+		t.Error("active entry should match")
+	}
+
+	expired := reg.Prune(now)
+	if len(expired) != 1 || expired[0].Test != "p.TestOld" {
+		t.Errorf("Prune returned %+v", expired)
+	}
+	if len(reg.Tests) != 1 {
+		t.Errorf("registry has %d entries after prune, want 1", len(reg.Tests))
+	}
+}
+
+func TestLoadSaveRoundTrip(t *testing.T) {
+	rigPath := t.TempDir()
+
+	reg, err := Load(rigPath)
+	if err != nil {
+		t.Fatalf("Load missing: %v", err)
+	}
+	if len(reg.Tests) != 0 {
+		t.Fatalf("expected empty registry, got %d entries", len(reg.Tests))
+	}
+
+	reg.Add(Entry{Test: "p.TestA", Reason: "timing", AddedAt: time.Now().UTC()})
+	if err := Save(rigPath, reg); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded, err := Load(rigPath)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if e := loaded.Get("p.TestA"); e == nil || e.Reason != "timing" {
+		t.Errorf("loaded entry = %+v", e)
+	}
+}
@@ -0,0 +1,208 @@
+package formula
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Extensions are the formula file suffixes, in lookup order.
+var Extensions = []string{".formula.toml", ".formula.json"}
+
+// Loader finds formulas by name in a list of directories and resolves
+// their extends and includes.
+//
+// A formula that extends a parent inherits everything the parent defines:
+// description, type, vars, inputs, prompts, and its steps, legs, templates,
+// and aspects. The child's own fields win; a child item with the same id
+// as a parent item replaces it in place, and new items are appended.
+// Multiple parents are applied in order.
+//
+// Includes pull in another formula's steps, legs, templates, aspects, and
+// vars without inheriting its identity. Items the formula already defines
+// win over included ones.
+type Loader struct {
+	// Dirs are searched in order; the first match wins.
+	Dirs []string
+}
+
+// Find returns the path of the named formula.
+func (l *Loader) Find(name string) (string, error) {
+	for _, dir := range l.Dirs {
+		for _, ext := range Extensions {
+			path := filepath.Join(dir, name+ext)
+			if _, err := os.Stat(path); err == nil {
+				return path, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("formula '%s' not found in search paths", name)
+}
+
+// Load finds, parses, and resolves the named formula.
+func (l *Loader) Load(name string) (*Formula, error) {
+	path, err := l.Find(name)
+	if err != nil {
+		return nil, err
+	}
+	return l.ParseFile(path)
+}
+
+// ParseFile parses and resolves the formula at path.
+func (l *Loader) ParseFile(path string) (*Formula, error) {
+	f, err := l.resolveFile(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.finish(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// resolveFile parses path and merges in what it extends and includes.
+// stack holds the formulas being resolved, to catch cycles.
+func (l *Loader) resolveFile(path string, stack []string) (*Formula, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is from trusted formula directory
+	if err != nil {
+		return nil, fmt.Errorf("reading formula file: %w", err)
+	}
+	f, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(f.Extends) == 0 && len(f.Includes) == 0 {
+		return f, nil
+	}
+
+	name := f.Name
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	for _, s := range stack {
+		if s == name {
+			return nil, fmt.Errorf("formula composition cycle: %s -> %s", strings.Join(stack, " -> "), name)
+		}
+	}
+	stack = append(stack, name)
+
+	load := func(ref string) (*Formula, error) {
+		p, err := l.Find(ref)
+		if err != nil {
+			return nil, fmt.Errorf("formula %q: %w", name, err)
+		}
+		return l.resolveFile(p, stack)
+	}
+
+	var base *Formula
+	for _, parent := range f.Extends {
+		p, err := load(parent)
+		if err != nil {
+			return nil, err
+		}
+		if base == nil {
+			base = p
+		} else {
+			base = inherit(base, p)
+		}
+	}
+	if base != nil {
+		f = inherit(base, f)
+	}
+	for _, inc := range f.Includes {
+		p, err := load(inc)
+		if err != nil {
+			return nil, err
+		}
+		include(f, p)
+	}
+	return f, nil
+}
+
+// inherit returns child laid over parent.
+func inherit(parent, child *Formula) *Formula {
+	out := *child
+	if out.Description == "" {
+		out.Description = parent.Description
+	}
+	if out.Type == "" {
+		out.Type = parent.Type
+	}
+	if out.Version == 0 {
+		out.Version = parent.Version
+	}
+	if out.Output == nil {
+		out.Output = parent.Output
+	}
+	if out.Synthesis == nil {
+		out.Synthesis = parent.Synthesis
+	}
+	out.Vars = mergeMap(parent.Vars, child.Vars)
+	out.Inputs = mergeMap(parent.Inputs, child.Inputs)
+	out.Prompts = mergeMap(parent.Prompts, child.Prompts)
+	out.Steps = overlay(parent.Steps, child.Steps, func(s Step) string { return s.ID })
+	out.Legs = overlay(parent.Legs, child.Legs, func(l Leg) string { return l.ID })
+	out.Template = overlay(parent.Template, child.Template, func(t Template) string { return t.ID })
+	out.Aspects = overlay(parent.Aspects, child.Aspects, func(a Aspect) string { return a.ID })
+	return &out
+}
+
+// include adds inc's items and vars to f where f doesn't define them.
+func include(f, inc *Formula) {
+	f.Vars = mergeMap(inc.Vars, f.Vars)
+	f.Inputs = mergeMap(inc.Inputs, f.Inputs)
+	f.Prompts = mergeMap(inc.Prompts, f.Prompts)
+	f.Steps = appendMissing(f.Steps, inc.Steps, func(s Step) string { return s.ID })
+	f.Legs = appendMissing(f.Legs, inc.Legs, func(l Leg) string { return l.ID })
+	f.Template = appendMissing(f.Template, inc.Template, func(t Template) string { return t.ID })
+	f.Aspects = appendMissing(f.Aspects, inc.Aspects, func(a Aspect) string { return a.ID })
+}
+
+// mergeMap returns base with over's entries laid on top.
+func mergeMap[V any](base, over map[string]V) map[string]V {
+	if len(base) == 0 && len(over) == 0 {
+		return over
+	}
+	out := make(map[string]V, len(base)+len(over))
+	for k, v := range base {
+		out[k] = v
+	}
+	for k, v := range over {
+		out[k] = v
+	}
+	return out
+}
+
+// overlay replaces base items with same-id items from over, in place, and
+// appends the rest of over.
+func overlay[T any](base, over []T, id func(T) string) []T {
+	out := make([]T, 0, len(base)+len(over))
+	index := make(map[string]int, len(base))
+	for _, item := range base {
+		index[id(item)] = len(out)
+		out = append(out, item)
+	}
+	for _, item := range over {
+		if i, ok := index[id(item)]; ok {
+			out[i] = item
+		} else {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// appendMissing appends the items of extra whose ids aren't in items.
+func appendMissing[T any](items, extra []T, id func(T) string) []T {
+	have := make(map[string]bool, len(items))
+	for _, item := range items {
+		have[id(item)] = true
+	}
+	for _, item := range extra {
+		if !have[id(item)] {
+			items = append(items, item)
+		}
+	}
+	return items
+}
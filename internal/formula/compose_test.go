@@ -0,0 +1,149 @@
+package formula
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFormula(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name+".formula.toml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+const baseFormula = `
+formula = "base"
+description = "Base workflow for {{feature}}"
+type = "workflow"
+
+[vars.feature]
+required = true
+[vars.test_cmd]
+default = "go test ./..."
+
+[[steps]]
+id = "implement"
+title = "Implement {{feature}}"
+
+[[steps]]
+id = "test"
+title = "Run {{test_cmd}}"
+needs = ["implement"]
+`
+
+func TestLoader_Extends(t *testing.T) {
+	dir := t.TempDir()
+	writeFormula(t, dir, "base", baseFormula)
+	writeFormula(t, dir, "child", `
+formula = "child"
+extends = ["base"]
+
+[vars.test_cmd]
+default = "make test"
+
+[[steps]]
+id = "test"
+title = "Test with {{test_cmd}}"
+needs = ["implement"]
+
+[[steps]]
+id = "submit"
+title = "Submit"
+needs = ["test"]
+`)
+
+	f, err := (&Loader{Dirs: []string{dir}}).Load("child")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if f.Name != "child" || f.Type != TypeWorkflow {
+		t.Errorf("name/type = %q/%q, want child/workflow", f.Name, f.Type)
+	}
+	if f.Description != "Base workflow for {{feature}}" {
+		t.Errorf("description not inherited: %q", f.Description)
+	}
+	var ids []string
+	for _, s := range f.Steps {
+		ids = append(ids, s.ID)
+	}
+	if got := strings.Join(ids, ","); got != "implement,test,submit" {
+		t.Errorf("steps = %s, want implement,test,submit", got)
+	}
+	if got := f.GetStep("test").Title; got != "Test with {{test_cmd}}" {
+		t.Errorf("overridden step title = %q", got)
+	}
+	if !f.Vars["feature"].Required || f.Vars["test_cmd"].Default != "make test" {
+		t.Errorf("vars not merged: %+v", f.Vars)
+	}
+}
+
+func TestLoader_Includes(t *testing.T) {
+	dir := t.TempDir()
+	writeFormula(t, dir, "lint", `
+formula = "lint"
+[vars.lint_cmd]
+default = "golangci-lint run"
+[[steps]]
+id = "lint"
+title = "Run {{lint_cmd}}"
+[[steps]]
+id = "implement"
+title = "Should not replace the including formula's step"
+`)
+	writeFormula(t, dir, "feature", `
+formula = "feature"
+includes = ["lint"]
+[[steps]]
+id = "implement"
+title = "Implement"
+`)
+
+	f, err := (&Loader{Dirs: []string{dir}}).Load("feature")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(f.Steps) != 2 || f.Steps[0].Title != "Implement" || f.Steps[1].ID != "lint" {
+		t.Errorf("steps = %+v", f.Steps)
+	}
+	if _, ok := f.Vars["lint_cmd"]; !ok {
+		t.Error("included var lint_cmd missing")
+	}
+}
+
+func TestLoader_Errors(t *testing.T) {
+	dir := t.TempDir()
+	writeFormula(t, dir, "a", "formula = \"a\"\nextends = [\"b\"]\n")
+	writeFormula(t, dir, "b", "formula = \"b\"\nextends = [\"a\"]\n")
+	writeFormula(t, dir, "orphan", "formula = \"orphan\"\nextends = [\"missing\"]\n")
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"a", "cycle"},
+		{"orphan", "not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := (&Loader{Dirs: []string{dir}}).Load(tt.name)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load(%q) error = %v, want %q", tt.name, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFile_EmbeddedExtends(t *testing.T) {
+	f, err := ParseFile("formulas/shiny-secure.formula.toml")
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	if f.Name != "shiny-secure" || f.GetStep("implement") == nil {
+		t.Errorf("shiny-secure did not inherit shiny's steps: %+v", f.Steps)
+	}
+}
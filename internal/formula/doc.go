@@ -114,6 +114,21 @@
 //	ready := f.ReadySteps(completed)
 //	// Returns: ["build"] (test is done, build can run)
 //
+// # Composition and Variables
+//
+// A formula can extend parents (extends = ["shiny"]) to inherit their vars
+// and steps, overriding steps by id, or include other formulas
+// (includes = ["lint"]) to pull in their steps and vars. ParseFile and
+// Loader resolve both:
+//
+//	f, err := (&formula.Loader{Dirs: dirs}).Load("shiny-secure")
+//
+// ResolveVars combines given values, per-rig overrides, and [vars]
+// defaults; Render expands {{var}} placeholders with the result:
+//
+//	values, err := f.ResolveVars(map[string]string{"feature": "auth"}, rigVars)
+//	rendered := f.Render(values)
+//
 // # Embedded Formulas
 //
 // The package includes embedded formula files that can be provisioned
@@ -0,0 +1,525 @@
+package formula
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultStepTimeout bounds a step's run command when the step sets no
+// timeout of its own.
+const DefaultStepTimeout = 30 * time.Minute
+
+// StepStatus is where a step stands in a formula run.
+type StepStatus string
+
+const (
+	StepPending StepStatus = "pending"
+	StepRunning StepStatus = "running"
+	StepDone    StepStatus = "done"
+	StepFailed  StepStatus = "failed"
+	// StepWaiting marks an agent step (no run command) that the run is
+	// paused on until it is marked done.
+	StepWaiting StepStatus = "waiting"
+	// StepSkipped marks a step whose when condition didn't hold. It
+	// satisfies the needs of later steps like a done step.
+	StepSkipped StepStatus = "skipped"
+)
+
+// maxStepOutput bounds how much of a step's output is kept for when
+// conditions: the tail, where results usually are.
+const maxStepOutput = 2048
+
+// Finished reports whether the step no longer blocks its dependents.
+func (s StepStatus) Finished() bool {
+	return s == StepDone || s == StepSkipped
+}
+
+// StepState records one step of a run.
+type StepState struct {
+	Status   StepStatus `json:"status"`
+	Attempts int        `json:"attempts,omitempty"`
+	Error    string     `json:"error,omitempty"`
+	Updated  time.Time  `json:"updated,omitempty"`
+	// Output is the tail of the step's command output.
+	Output string `json:"output,omitempty"`
+}
+
+// RunState is the persisted state of a formula run against an issue.
+type RunState struct {
+	// ID is the store's identifier for the run (the wisp bead ID).
+	ID      string                `json:"id,omitempty"`
+	Formula string                `json:"formula"`
+	Issue   string                `json:"issue"`
+	Started time.Time             `json:"started"`
+	Steps   map[string]*StepState `json:"steps"`
+	// Order is the formula's step order, for display.
+	Order []string `json:"order"`
+}
+
+// Step returns the state of step id, creating it as pending.
+func (s *RunState) Step(id string) *StepState {
+	if s.Steps == nil {
+		s.Steps = make(map[string]*StepState)
+	}
+	st, ok := s.Steps[id]
+	if !ok {
+		st = &StepState{Status: StepPending}
+		s.Steps[id] = st
+	}
+	return st
+}
+
+// Complete reports whether every step is done or skipped.
+func (s *RunState) Complete() bool {
+	for _, id := range s.Order {
+		if !s.Step(id).Status.Finished() {
+			return false
+		}
+	}
+	return len(s.Order) > 0
+}
+
+// Status summarizes the run: done, failed, waiting, or running.
+func (s *RunState) Status() string {
+	if s.Complete() {
+		return string(StepDone)
+	}
+	for _, want := range []StepStatus{StepFailed, StepWaiting} {
+		for _, id := range s.Order {
+			if s.Step(id).Status == want {
+				return string(want)
+			}
+		}
+	}
+	return string(StepRunning)
+}
+
+// Format renders the state as "key: value" lines for a bead description.
+func (s *RunState) Format() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "formula: %s\n", s.Formula)
+	fmt.Fprintf(&b, "issue: %s\n", s.Issue)
+	fmt.Fprintf(&b, "started: %s\n", s.Started.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "order: %s\n", strings.Join(s.Order, ","))
+	ids := make([]string, 0, len(s.Steps))
+	for id := range s.Steps {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		st := s.Steps[id]
+		fmt.Fprintf(&b, "step.%s: %s attempts=%d", id, st.Status, st.Attempts)
+		if !st.Updated.IsZero() {
+			fmt.Fprintf(&b, " at=%s", st.Updated.UTC().Format(time.RFC3339))
+		}
+		b.WriteString("\n")
+		if st.Error != "" {
+			fmt.Fprintf(&b, "step.%s.error: %s\n", id, strings.ReplaceAll(st.Error, "\n", " "))
+		}
+		if st.Output != "" {
+			fmt.Fprintf(&b, "step.%s.output: %s\n", id, strconv.Quote(st.Output))
+		}
+	}
+	return b.String()
+}
+
+// ParseRunState reads a state written by Format. Returns nil if the text
+// has no formula run fields.
+func ParseRunState(text string) *RunState {
+	s := &RunState{}
+	for _, line := range strings.Split(text, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch {
+		case key == "formula":
+			s.Formula = value
+		case key == "issue":
+			s.Issue = value
+		case key == "started":
+			s.Started, _ = time.Parse(time.RFC3339, value)
+		case key == "order":
+			if value != "" {
+				s.Order = strings.Split(value, ",")
+			}
+		case strings.HasPrefix(key, "step.") && strings.HasSuffix(key, ".error"):
+			s.Step(strings.TrimSuffix(strings.TrimPrefix(key, "step."), ".error")).Error = value
+		case strings.HasPrefix(key, "step.") && strings.HasSuffix(key, ".output"):
+			if out, err := strconv.Unquote(value); err == nil {
+				s.Step(strings.TrimSuffix(strings.TrimPrefix(key, "step."), ".output")).Output = out
+			}
+		case strings.HasPrefix(key, "step."):
+			st := s.Step(strings.TrimPrefix(key, "step."))
+			fields := strings.Fields(value)
+			if len(fields) > 0 {
+				st.Status = StepStatus(fields[0])
+			}
+			for _, f := range fields[1:] {
+				k, v, _ := strings.Cut(f, "=")
+				switch k {
+				case "attempts":
+					st.Attempts, _ = strconv.Atoi(v)
+				case "at":
+					st.Updated, _ = time.Parse(time.RFC3339, v)
+				}
+			}
+		}
+	}
+	if s.Formula == "" || s.Issue == "" {
+		return nil
+	}
+	return s
+}
+
+// StateStore persists run state between invocations.
+type StateStore interface {
+	// Load returns the run of formula against issue, or nil if none.
+	Load(formula, issue string) (*RunState, error)
+	// Save records state, assigning state.ID on first save.
+	Save(state *RunState) error
+}
+
+// Executor runs a workflow formula's steps against an issue, recording
+// each step's status so a failed or interrupted run can be resumed.
+//
+// Steps run in dependency order. A step with a run command is run with
+// sh -c from Dir, with {{var}} placeholders expanded and GT_ISSUE,
+// GT_FORMULA, and GT_VAR_<NAME> in its environment. A step without one is
+// an agent step: the run pauses there (StepWaiting) until it is listed in
+// Done on a later invocation.
+//
+// Ready steps marked parallel run together as a group; a step that needs
+// them all waits for the whole group (join). A step whose when condition
+// doesn't hold is skipped. After a failure no new steps start, but the
+// rest of its parallel group finishes.
+//
+// Running the same formula against the same issue again resumes: done
+// steps are skipped and steps left running by an interrupted run start
+// over. Failed steps are retried only when Retry is set.
+type Executor struct {
+	Formula *Formula
+	Issue   string
+	Vars    map[string]string
+	Store   StateStore
+	Dir     string
+
+	// Labels are the issue's labels, for when.label conditions.
+	Labels []string
+
+	// ChangedFiles are the files the issue's work touches, for when.files
+	// conditions. If nil, patterns are matched against files in Dir.
+	ChangedFiles []string
+
+	// Output receives step headers and command output.
+	Output io.Writer
+
+	// Retry reruns failed steps instead of stopping at them.
+	Retry bool
+
+	// Done marks agent steps as completed before the run continues.
+	Done []string
+
+	mu sync.Mutex // guards state and Store during parallel steps
+}
+
+// Run advances the run as far as it can and returns its state. The error
+// is for failures to run at all; a failed step is recorded in the state.
+func (e *Executor) Run(ctx context.Context) (*RunState, error) {
+	f := e.Formula
+	if f.Type != TypeWorkflow {
+		return nil, fmt.Errorf("formula %q is a %s formula; only workflow formulas can be executed", f.Name, f.Type)
+	}
+	order, err := f.TopologicalSort()
+	if err != nil {
+		return nil, err
+	}
+	out := e.Output
+	if out == nil {
+		out = io.Discard
+	}
+
+	state, err := e.Store.Load(f.Name, e.Issue)
+	if err != nil {
+		return nil, fmt.Errorf("loading run state: %w", err)
+	}
+	if state == nil {
+		state = &RunState{Formula: f.Name, Issue: e.Issue, Started: time.Now()}
+	}
+	state.Order = order
+
+	for _, id := range e.Done {
+		if f.GetStep(id) == nil {
+			return nil, fmt.Errorf("formula %q has no step %q", f.Name, id)
+		}
+		st := state.Step(id)
+		st.Status, st.Error, st.Updated = StepDone, "", time.Now()
+	}
+	for _, id := range order {
+		if st := state.Step(id); st.Status == StepRunning {
+			// Interrupted mid-step: start it over.
+			st.Status = StepPending
+		}
+	}
+	if err := e.Store.Save(state); err != nil {
+		return nil, fmt.Errorf("saving run state: %w", err)
+	}
+
+	vars := make(map[string]string, len(e.Vars)+1)
+	for k, v := range e.Vars {
+		vars[k] = v
+	}
+	if _, ok := vars["issue"]; !ok {
+		vars["issue"] = e.Issue
+	}
+	rendered := f.Render(vars)
+
+	attempted := make(map[string]bool)
+	for ctx.Err() == nil {
+		var parallel, sequential []*Step
+		halt, changed := false, false
+		for _, id := range order {
+			st := state.Step(id)
+			if st.Status.Finished() || st.Status == StepWaiting || attempted[id] {
+				continue
+			}
+			if st.Status == StepFailed && !e.Retry {
+				_, _ = fmt.Fprintf(out, "--- %s failed earlier (rerun with --retry)\n", id)
+				halt = true
+				break
+			}
+			step := rendered.GetStep(id)
+			if !needsMet(step, state) {
+				continue
+			}
+
+			ok, why, err := e.conditionHolds(step.When, state)
+			if err != nil {
+				return state, fmt.Errorf("step %q: %w", id, err)
+			}
+			if !ok {
+				st.Status, st.Error, st.Updated = StepSkipped, "", time.Now()
+				_, _ = fmt.Fprintf(out, "--- %s: skipped (%s)\n", id, why)
+				changed = true
+				continue
+			}
+			if step.Run == "" {
+				st.Status, st.Updated = StepWaiting, time.Now()
+				_, _ = fmt.Fprintf(out, "--- %s: waiting for an agent (%s)\n", id, step.Title)
+				attempted[id] = true
+				changed = true
+				continue
+			}
+			if step.Parallel {
+				parallel = append(parallel, step)
+			} else {
+				sequential = append(sequential, step)
+			}
+		}
+		if changed {
+			if err := e.Store.Save(state); err != nil {
+				return state, fmt.Errorf("saving run state: %w", err)
+			}
+		}
+		if halt {
+			break
+		}
+
+		wave := parallel
+		if len(wave) == 0 && len(sequential) > 0 {
+			wave = sequential[:1]
+		}
+		if len(wave) == 0 {
+			if changed {
+				continue // skips may have unblocked steps
+			}
+			break
+		}
+		failed, err := e.runWave(ctx, wave, state, vars, out)
+		if err != nil {
+			return state, err
+		}
+		for _, step := range wave {
+			attempted[step.ID] = true
+		}
+		if failed {
+			break
+		}
+	}
+	return state, nil
+}
+
+// needsMet reports whether every step that step needs is done or skipped.
+func needsMet(step *Step, state *RunState) bool {
+	for _, need := range step.Needs {
+		if !state.Step(need).Status.Finished() {
+			return false
+		}
+	}
+	return true
+}
+
+// conditionHolds evaluates a when clause against the issue and the run so
+// far. If it doesn't hold, why says which part failed.
+func (e *Executor) conditionHolds(c *Condition, state *RunState) (ok bool, why string, err error) {
+	if c == nil {
+		return true, "", nil
+	}
+	if c.Label != "" && !containsString(e.Labels, c.Label) {
+		return false, "issue lacks label " + c.Label, nil
+	}
+	if c.Files != "" {
+		matched, err := e.filesMatch(c.Files)
+		if err != nil {
+			return false, "", err
+		}
+		if !matched {
+			return false, "no files match " + c.Files, nil
+		}
+	}
+	if c.Step != "" {
+		re, err := regexp.Compile(c.Output)
+		if err != nil {
+			return false, "", err
+		}
+		if !re.MatchString(state.Step(c.Step).Output) {
+			return false, fmt.Sprintf("%s output doesn't match %q", c.Step, c.Output), nil
+		}
+	}
+	return true, "", nil
+}
+
+// filesMatch reports whether a changed file (or, without a change list, a
+// file in Dir) matches pattern by path or base name.
+func (e *Executor) filesMatch(pattern string) (bool, error) {
+	if e.ChangedFiles == nil {
+		matches, err := filepath.Glob(filepath.Join(e.Dir, pattern))
+		return len(matches) > 0, err
+	}
+	for _, file := range e.ChangedFiles {
+		if ok, _ := filepath.Match(pattern, file); ok {
+			return true, nil
+		}
+		if ok, _ := filepath.Match(pattern, filepath.Base(file)); ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// runWave runs steps concurrently, saving state as each starts and ends.
+// A lone step streams its output; a parallel group's output is buffered
+// per step so it doesn't interleave. Reports whether any step failed.
+func (e *Executor) runWave(ctx context.Context, wave []*Step, state *RunState, vars map[string]string, out io.Writer) (bool, error) {
+	e.mu.Lock()
+	for _, step := range wave {
+		st := state.Step(step.ID)
+		st.Status, st.Error, st.Output, st.Updated = StepRunning, "", "", time.Now()
+		st.Attempts++
+	}
+	err := e.Store.Save(state)
+	e.mu.Unlock()
+	if err != nil {
+		return false, fmt.Errorf("saving run state: %w", err)
+	}
+	if len(wave) > 1 {
+		ids := make([]string, len(wave))
+		for i, step := range wave {
+			ids[i] = step.ID
+		}
+		_, _ = fmt.Fprintf(out, "--- running in parallel: %s\n", strings.Join(ids, ", "))
+	}
+
+	var wg sync.WaitGroup
+	var saveErr error
+	failed := false
+	for _, step := range wave {
+		wg.Add(1)
+		go func(step *Step) {
+			defer wg.Done()
+			var buf bytes.Buffer
+			w := io.Writer(&buf)
+			if len(wave) == 1 {
+				_, _ = fmt.Fprintf(out, "--- [%s] %s\n", step.ID, step.Title)
+				w = io.MultiWriter(out, &buf)
+			}
+			runErr := e.runStep(ctx, step, vars, w)
+
+			e.mu.Lock()
+			defer e.mu.Unlock()
+			st := state.Step(step.ID)
+			st.Output = tail(buf.String(), maxStepOutput)
+			if len(wave) > 1 {
+				_, _ = fmt.Fprintf(out, "--- [%s] %s\n", step.ID, step.Title)
+				_, _ = out.Write(buf.Bytes())
+			}
+			if runErr != nil {
+				st.Status, st.Error = StepFailed, runErr.Error()
+				failed = true
+				_, _ = fmt.Fprintf(out, "--- %s failed: %s\n", step.ID, st.Error)
+			} else {
+				st.Status = StepDone
+				_, _ = fmt.Fprintf(out, "--- %s ok\n", step.ID)
+			}
+			st.Updated = time.Now()
+			if err := e.Store.Save(state); err != nil && saveErr == nil {
+				saveErr = fmt.Errorf("saving run state: %w", err)
+			}
+		}(step)
+	}
+	wg.Wait()
+	return failed, saveErr
+}
+
+// runStep runs a step's command with the run's environment.
+func (e *Executor) runStep(ctx context.Context, step *Step, vars map[string]string, out io.Writer) error {
+	timeout := DefaultStepTimeout
+	if step.Timeout != "" {
+		timeout, _ = time.ParseDuration(step.Timeout)
+	}
+	stepCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(stepCtx, "sh", "-c", step.Run) //nolint:gosec // G204: formulas are trusted town content
+	cmd.Dir = e.Dir
+	cmd.Env = append(os.Environ(), "GT_ISSUE="+e.Issue, "GT_FORMULA="+e.Formula.Name)
+	for k, v := range vars {
+		cmd.Env = append(cmd.Env, "GT_VAR_"+strings.ToUpper(k)+"="+v)
+	}
+	cmd.Stdout = out
+	cmd.Stderr = out
+	err := cmd.Run()
+	if stepCtx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timed out after %s", timeout)
+	}
+	return err
+}
+
+// tail returns the last n bytes of s.
+func tail(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[len(s)-n:]
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,291 @@
+package formula
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// memStore is an in-memory StateStore that round-trips through Format, as
+// the beads store does.
+type memStore struct {
+	saved map[string]string
+}
+
+func (m *memStore) Load(formula, issue string) (*RunState, error) {
+	text, ok := m.saved[formula+"/"+issue]
+	if !ok {
+		return nil, nil
+	}
+	s := ParseRunState(text)
+	s.ID = "wisp-1"
+	return s, nil
+}
+
+func (m *memStore) Save(s *RunState) error {
+	if m.saved == nil {
+		m.saved = make(map[string]string)
+	}
+	m.saved[s.Formula+"/"+s.Issue] = s.Format()
+	return nil
+}
+
+func execFormula(t *testing.T, steps string) *Formula {
+	t.Helper()
+	f, err := Parse([]byte("formula = \"ship\"\ntype = \"workflow\"\n" + steps))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestExecutor_RunsInOrderAndResumes(t *testing.T) {
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "attempts")
+	f := execFormula(t, `
+[[steps]]
+id = "build"
+title = "Build {{issue}}"
+run = "echo built $GT_ISSUE >> log"
+
+[[steps]]
+id = "flaky"
+run = "echo x >> attempts; test $(wc -l < attempts) -ge 2"
+needs = ["build"]
+
+[[steps]]
+id = "review"
+title = "Review"
+needs = ["flaky"]
+
+[[steps]]
+id = "land"
+run = "echo landed >> log"
+needs = ["review"]
+`)
+	store := &memStore{}
+	exec := func(retry bool, done ...string) *RunState {
+		t.Helper()
+		e := &Executor{Formula: f, Issue: "gt-1", Store: store, Dir: dir, Retry: retry, Done: done}
+		s, err := e.Run(context.Background())
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		return s
+	}
+
+	// First run: build passes, flaky fails and stops the run.
+	s := exec(false)
+	if s.Step("build").Status != StepDone || s.Step("flaky").Status != StepFailed || s.Status() != "failed" {
+		t.Fatalf("after first run: %s", s.Format())
+	}
+
+	// Without --retry the failed step is not rerun.
+	exec(false)
+	if data, _ := os.ReadFile(marker); strings.Count(string(data), "x") != 1 {
+		t.Errorf("failed step rerun without retry")
+	}
+
+	// Retry: flaky passes, the run pauses on the agent step.
+	s = exec(true)
+	if s.Step("flaky").Status != StepDone || s.Step("flaky").Attempts != 2 {
+		t.Errorf("flaky = %+v, want done after 2 attempts", s.Step("flaky"))
+	}
+	if s.Step("review").Status != StepWaiting || s.Status() != "waiting" {
+		t.Fatalf("review = %+v, want waiting", s.Step("review"))
+	}
+
+	// Marking the agent step done finishes the run; build isn't rerun.
+	s = exec(false, "review")
+	if !s.Complete() {
+		t.Fatalf("run not complete: %s", s.Format())
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "log"))
+	if got := string(data); got != "built gt-1\nlanded\n" {
+		t.Errorf("log = %q", got)
+	}
+}
+
+func TestExecutor_InterruptedStepStartsOver(t *testing.T) {
+	f := execFormula(t, "[[steps]]\nid = \"a\"\nrun = \"true\"\n")
+	store := &memStore{}
+	_ = store.Save(&RunState{Formula: "ship", Issue: "gt-1", Steps: map[string]*StepState{"a": {Status: StepRunning, Attempts: 1}}})
+
+	s, err := (&Executor{Formula: f, Issue: "gt-1", Store: store, Dir: t.TempDir()}).Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st := s.Step("a"); st.Status != StepDone || st.Attempts != 2 {
+		t.Errorf("a = %+v, want done on attempt 2", st)
+	}
+}
+
+func TestExecutor_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		f    *Formula
+		done []string
+		want string
+	}{
+		{"not a workflow", &Formula{Name: "c", Type: TypeConvoy}, nil, "only workflow"},
+		{"unknown done step", execFormula(t, "[[steps]]\nid = \"a\"\n"), []string{"zz"}, "no step"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := (&Executor{Formula: tt.f, Issue: "gt-1", Store: &memStore{}, Done: tt.done}).Run(context.Background())
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunState_FormatRoundTrip(t *testing.T) {
+	s := &RunState{Formula: "ship", Issue: "gt-1", Order: []string{"a", "b"}}
+	s.Step("a").Status = StepDone
+	s.Step("a").Attempts = 1
+	s.Step("b").Status = StepFailed
+	s.Step("b").Error = "exit status 1\nmore"
+
+	got := ParseRunState(s.Format())
+	if got == nil || got.Formula != "ship" || got.Issue != "gt-1" || len(got.Order) != 2 {
+		t.Fatalf("ParseRunState = %+v", got)
+	}
+	if got.Step("a").Status != StepDone || got.Step("a").Attempts != 1 {
+		t.Errorf("a = %+v", got.Step("a"))
+	}
+	if got.Step("b").Error != "exit status 1 more" {
+		t.Errorf("b error = %q", got.Step("b").Error)
+	}
+	if ParseRunState("just a description") != nil {
+		t.Error("ParseRunState should return nil without run fields")
+	}
+}
+
+func TestExecutor_ParallelGroupJoins(t *testing.T) {
+	dir := t.TempDir()
+	// Each parallel step waits for the other to start, so they only pass
+	// when run concurrently.
+	f := execFormula(t, `
+[[steps]]
+id = "lint"
+parallel = true
+timeout = "5s"
+run = "touch lint.started; while [ ! -f test.started ]; do sleep 0.05; done"
+
+[[steps]]
+id = "test"
+parallel = true
+timeout = "5s"
+run = "touch test.started; while [ ! -f lint.started ]; do sleep 0.05; done"
+
+[[steps]]
+id = "review"
+needs = ["lint", "test"]
+run = "test -f lint.started && test -f test.started"
+`)
+	s, err := (&Executor{Formula: f, Issue: "gt-1", Store: &memStore{}, Dir: dir}).Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.Complete() {
+		t.Fatalf("run not complete: %s", s.Format())
+	}
+}
+
+func TestExecutor_Conditions(t *testing.T) {
+	f := execFormula(t, `
+[[steps]]
+id = "detect"
+run = "echo 'schema changed: yes'"
+
+[[steps]]
+id = "migrate"
+needs = ["detect"]
+run = "echo migrated"
+when = { step = "detect", output = "schema changed: yes" }
+
+[[steps]]
+id = "docs"
+needs = ["detect"]
+run = "echo docs"
+when = { step = "detect", output = "docs changed" }
+
+[[steps]]
+id = "frontend"
+needs = ["detect"]
+run = "echo frontend"
+when = { files = "*.ts" }
+
+[[steps]]
+id = "security"
+needs = ["detect"]
+run = "echo security"
+when = { label = "security" }
+
+[[steps]]
+id = "land"
+needs = ["migrate", "docs", "frontend", "security"]
+run = "true"
+`)
+	e := &Executor{
+		Formula:      f,
+		Issue:        "gt-1",
+		Store:        &memStore{},
+		Dir:          t.TempDir(),
+		Labels:       []string{"security"},
+		ChangedFiles: []string{"internal/cmd/formula.go"},
+	}
+	s, err := e.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]StepStatus{
+		"detect":   StepDone,
+		"migrate":  StepDone,
+		"docs":     StepSkipped,
+		"frontend": StepSkipped,
+		"security": StepDone,
+		"land":     StepDone,
+	}
+	for id, status := range want {
+		if got := s.Step(id).Status; got != status {
+			t.Errorf("%s = %s, want %s", id, got, status)
+		}
+	}
+	if !s.Complete() {
+		t.Errorf("run with skipped steps should be complete: %s", s.Format())
+	}
+}
+
+func TestValidateCondition(t *testing.T) {
+	tests := []struct {
+		name string
+		when string
+		want string
+	}{
+		{"empty", "{}", "empty when"},
+		{"output without step", `{ output = "x" }`, "go together"},
+		{"bad regex", `{ step = "a", output = "(" }`, "invalid output pattern"},
+		{"step not a need", `{ step = "c", output = "x" }`, "must be among its needs"},
+		{"bad glob", `{ files = "[" }`, "invalid files pattern"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(`formula = "f"
+[[steps]]
+id = "a"
+[[steps]]
+id = "c"
+[[steps]]
+id = "b"
+needs = ["a"]
+when = ` + tt.when))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -2,36 +2,49 @@ package formula
 
 import (
 	"fmt"
-	"os"
+	"path/filepath"
+	"regexp"
 	"sort"
+	"time"
 
 	"github.com/BurntSushi/toml"
 )
 
-// ParseFile reads and parses a formula.toml file.
+// ParseFile reads and parses a formula.toml file. Formulas it extends or
+// includes are looked up in the same directory.
 func ParseFile(path string) (*Formula, error) {
-	data, err := os.ReadFile(path) //nolint:gosec // G304: path is from trusted formula directory
+	l := &Loader{Dirs: []string{filepath.Dir(path)}}
+	return l.ParseFile(path)
+}
+
+// Parse parses formula.toml content from bytes. It does not resolve
+// extends or includes; use ParseFile or Loader for composed formulas.
+func Parse(data []byte) (*Formula, error) {
+	f, err := decode(data)
 	if err != nil {
-		return nil, fmt.Errorf("reading formula file: %w", err)
+		return nil, err
+	}
+	if err := f.finish(); err != nil {
+		return nil, err
 	}
-	return Parse(data)
+	return f, nil
 }
 
-// Parse parses formula.toml content from bytes.
-func Parse(data []byte) (*Formula, error) {
+// decode parses formula.toml content without validating it.
+func decode(data []byte) (*Formula, error) {
 	var f Formula
 	if _, err := toml.Decode(string(data), &f); err != nil {
 		return nil, fmt.Errorf("parsing TOML: %w", err)
 	}
+	return &f, nil
+}
 
+// finish infers the type and validates a decoded (and resolved) formula.
+func (f *Formula) finish() error {
 	// Infer type from content if not explicitly set
 	f.inferType()
 
-	if err := f.Validate(); err != nil {
-		return nil, err
-	}
-
-	return &f, nil
+	return f.Validate()
 }
 
 // inferType sets the formula type based on content when not explicitly set.
@@ -122,6 +135,11 @@ func (f *Formula) validateWorkflow() error {
 			return fmt.Errorf("duplicate step id: %s", step.ID)
 		}
 		seen[step.ID] = true
+		if step.Timeout != "" {
+			if _, err := time.ParseDuration(step.Timeout); err != nil {
+				return fmt.Errorf("step %q has invalid timeout %q", step.ID, step.Timeout)
+			}
+		}
 	}
 
 	// Validate step needs references
@@ -138,9 +156,64 @@ func (f *Formula) validateWorkflow() error {
 		return err
 	}
 
+	for _, step := range f.Steps {
+		if err := f.validateCondition(step); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// validateCondition checks a step's when clause. A step output condition
+// must name a step that is guaranteed to have run first.
+func (f *Formula) validateCondition(step Step) error {
+	c := step.When
+	if c == nil {
+		return nil
+	}
+	if c.Label == "" && c.Files == "" && c.Step == "" && c.Output == "" {
+		return fmt.Errorf("step %q has an empty when condition", step.ID)
+	}
+	if c.Files != "" {
+		if _, err := filepath.Match(c.Files, ""); err != nil {
+			return fmt.Errorf("step %q: invalid files pattern %q", step.ID, c.Files)
+		}
+	}
+	if (c.Step == "") != (c.Output == "") {
+		return fmt.Errorf("step %q: when.step and when.output go together", step.ID)
+	}
+	if c.Step != "" {
+		if _, err := regexp.Compile(c.Output); err != nil {
+			return fmt.Errorf("step %q: invalid output pattern: %w", step.ID, err)
+		}
+		if !f.dependsOn(step.ID, c.Step) {
+			return fmt.Errorf("step %q: when.step %q must be among its needs", step.ID, c.Step)
+		}
+	}
+	return nil
+}
+
+// dependsOn reports whether step id needs dep, directly or transitively.
+func (f *Formula) dependsOn(id, dep string) bool {
+	seen := make(map[string]bool)
+	var walk func(string) bool
+	walk = func(id string) bool {
+		step := f.GetStep(id)
+		if step == nil || seen[id] {
+			return false
+		}
+		seen[id] = true
+		for _, need := range step.Needs {
+			if need == dep || walk(need) {
+				return true
+			}
+		}
+		return false
+	}
+	return walk(id)
+}
+
 func (f *Formula) validateExpansion() error {
 	if len(f.Template) == 0 {
 		return fmt.Errorf("expansion formula requires at least one template")
@@ -0,0 +1,218 @@
+package formula
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PlanAction is what the executor would do with a step.
+type PlanAction string
+
+const (
+	PlanRun   PlanAction = "run"   // run the step's command
+	PlanAgent PlanAction = "agent" // wait for an agent to do the step
+	PlanSkip  PlanAction = "skip"  // the when condition doesn't hold
+	PlanMaybe PlanAction = "maybe" // depends on output not produced yet
+	PlanDone  PlanAction = "done"  // already finished in an earlier run
+	PlanRetry PlanAction = "retry" // failed earlier; runs again with --retry
+)
+
+// PlannedStep is one step of a Plan.
+type PlannedStep struct {
+	ID      string     `json:"id"`
+	Title   string     `json:"title"`
+	Action  PlanAction `json:"action"`
+	Reason  string     `json:"reason,omitempty"`
+	Command string     `json:"command,omitempty"`
+	// Group numbers the batches steps start in; steps sharing a group run
+	// in parallel.
+	Group int `json:"group"`
+}
+
+// Plan reports what Run would do, step by step, without running anything
+// or saving state. Label and file conditions are evaluated against the
+// issue; a condition on the output of a step that hasn't run yet is
+// reported as PlanMaybe. The earlier run's state, if any, is taken into
+// account.
+func (e *Executor) Plan() ([]PlannedStep, error) {
+	f := e.Formula
+	if f.Type != TypeWorkflow {
+		return nil, fmt.Errorf("formula %q is a %s formula; only workflow formulas can be executed", f.Name, f.Type)
+	}
+	order, err := f.TopologicalSort()
+	if err != nil {
+		return nil, err
+	}
+	state, err := e.Store.Load(f.Name, e.Issue)
+	if err != nil {
+		return nil, fmt.Errorf("loading run state: %w", err)
+	}
+	if state == nil {
+		state = &RunState{Formula: f.Name, Issue: e.Issue}
+	}
+
+	vars := make(map[string]string, len(e.Vars)+1)
+	for k, v := range e.Vars {
+		vars[k] = v
+	}
+	if _, ok := vars["issue"]; !ok {
+		vars["issue"] = e.Issue
+	}
+	rendered := f.Render(vars)
+
+	// Simulate Run's scheduling, assuming every step succeeds and agent
+	// steps get done: each batch is the ready parallel steps, or else the
+	// first ready sequential step.
+	planned := make(map[string]PlannedStep, len(order))
+	for _, id := range order {
+		if state.Step(id).Status.Finished() {
+			planned[id] = PlannedStep{ID: id, Title: rendered.GetStep(id).Title, Action: PlanDone}
+		}
+	}
+	group := 0
+	for len(planned) < len(order) {
+		var parallel, sequential []PlannedStep
+		changed := false
+		for _, id := range order {
+			step := rendered.GetStep(id)
+			if _, ok := planned[id]; ok || !plannedNeeds(step, planned) {
+				continue
+			}
+			ps, err := e.planStep(step, state)
+			if err != nil {
+				return nil, err
+			}
+			switch {
+			case ps.Action == PlanSkip:
+				planned[id] = ps
+				changed = true
+			case step.Parallel && ps.Action != PlanAgent:
+				parallel = append(parallel, ps)
+			default:
+				sequential = append(sequential, ps)
+			}
+		}
+		batch := parallel
+		if len(batch) == 0 && len(sequential) > 0 {
+			batch = sequential[:1]
+		}
+		if len(batch) == 0 {
+			if changed {
+				continue
+			}
+			break
+		}
+		group++
+		for _, ps := range batch {
+			ps.Group = group
+			planned[ps.ID] = ps
+		}
+	}
+
+	plan := make([]PlannedStep, 0, len(order))
+	for _, id := range order {
+		plan = append(plan, planned[id])
+	}
+	return plan, nil
+}
+
+// planStep decides what Run would do with a ready step.
+func (e *Executor) planStep(step *Step, state *RunState) (PlannedStep, error) {
+	ps := PlannedStep{ID: step.ID, Title: step.Title, Command: step.Run, Action: PlanRun}
+	if step.Run == "" {
+		ps.Action = PlanAgent
+	}
+	if st := state.Step(step.ID); st.Status == StepFailed && !e.Retry {
+		ps.Action, ps.Reason = PlanRetry, "failed earlier: "+st.Error
+		return ps, nil
+	}
+	c := step.When
+	if c != nil && c.Step != "" && !state.Step(c.Step).Status.Finished() {
+		ps.Action, ps.Reason = PlanMaybe, fmt.Sprintf("runs if %s output matches %q", c.Step, c.Output)
+		c = &Condition{Label: c.Label, Files: c.Files}
+	}
+	ok, why, err := e.conditionHolds(c, state)
+	if err != nil {
+		return ps, fmt.Errorf("step %q: %w", step.ID, err)
+	}
+	if !ok {
+		ps.Action, ps.Reason = PlanSkip, why
+	}
+	return ps, nil
+}
+
+// plannedNeeds reports whether every step that step needs has been planned.
+func plannedNeeds(step *Step, planned map[string]PlannedStep) bool {
+	for _, need := range step.Needs {
+		if _, ok := planned[need]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// sameNeeds reports whether two steps need the same steps.
+func sameNeeds(a, b *Step) bool {
+	if len(a.Needs) != len(b.Needs) {
+		return false
+	}
+	x := append([]string(nil), a.Needs...)
+	y := append([]string(nil), b.Needs...)
+	sort.Strings(x)
+	sort.Strings(y)
+	return strings.Join(x, ",") == strings.Join(y, ",")
+}
+
+// Lint returns problems in a formula that parse and validation allow but
+// that will bite at run time: undefined variables, placeholders left in
+// run commands after rendering, and parallel flags with no effect.
+// {{issue}} counts as defined, since the executor supplies it.
+// values are the resolved vars (see ResolveVars); nil skips the rendering
+// check.
+func (f *Formula) Lint(values map[string]string) []string {
+	var problems []string
+	var undefined []string
+	for _, v := range f.GetUndefinedVariables() {
+		if v != "issue" { // supplied by the executor
+			undefined = append(undefined, v)
+		}
+	}
+	if len(undefined) > 0 {
+		problems = append(problems, "undefined template variables: "+strings.Join(undefined, ", "))
+	}
+	if f.Type != TypeWorkflow {
+		return problems
+	}
+
+	if values != nil {
+		known := make(map[string]string, len(values)+1)
+		for k, v := range values {
+			known[k] = v
+		}
+		known["issue"] = "issue"
+		for _, step := range f.Render(known).Steps {
+			if left := ExtractTemplateVariables(step.Run); len(left) > 0 {
+				problems = append(problems, fmt.Sprintf("step %q: run command has no value for %s",
+					step.ID, strings.Join(left, ", ")))
+			}
+		}
+	}
+
+	for _, step := range f.Steps {
+		if !step.Parallel {
+			continue
+		}
+		peers := 0
+		for i := range f.Steps {
+			other := &f.Steps[i]
+			if other.ID != step.ID && other.Parallel && sameNeeds(other, &step) {
+				peers++
+			}
+		}
+		if peers == 0 {
+			problems = append(problems, fmt.Sprintf("step %q is marked parallel but no other parallel step shares its needs", step.ID))
+		}
+	}
+	return problems
+}
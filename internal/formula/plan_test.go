@@ -0,0 +1,138 @@
+package formula
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExecutor_Plan(t *testing.T) {
+	f := execFormula(t, `
+[vars.target]
+default = "./..."
+
+[[steps]]
+id = "detect"
+run = "git diff --stat"
+
+[[steps]]
+id = "lint"
+parallel = true
+needs = ["detect"]
+run = "golangci-lint run {{target}}"
+
+[[steps]]
+id = "test"
+parallel = true
+needs = ["detect"]
+run = "go test {{target}}"
+
+[[steps]]
+id = "frontend"
+needs = ["detect"]
+run = "npm test"
+when = { files = "*.ts" }
+
+[[steps]]
+id = "migrate"
+needs = ["detect"]
+run = "make migrate"
+when = { step = "detect", output = "migrations/" }
+
+[[steps]]
+id = "review"
+needs = ["lint", "test", "frontend", "migrate"]
+title = "Review {{issue}}"
+`)
+	store := &memStore{}
+	e := &Executor{
+		Formula:      f,
+		Issue:        "gt-1",
+		Vars:         map[string]string{"target": "./internal/..."},
+		Store:        store,
+		ChangedFiles: []string{"main.go"},
+	}
+	plan, err := e.Plan()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]PlannedStep)
+	for _, ps := range plan {
+		got[ps.ID] = ps
+	}
+	tests := []struct {
+		id     string
+		action PlanAction
+		group  int
+	}{
+		{"detect", PlanRun, 1},
+		{"lint", PlanRun, 2},
+		{"test", PlanRun, 2},
+		{"frontend", PlanSkip, 0},
+		{"migrate", PlanMaybe, 3},
+		{"review", PlanAgent, 4},
+	}
+	for _, tt := range tests {
+		ps := got[tt.id]
+		if ps.Action != tt.action || ps.Group != tt.group {
+			t.Errorf("%s = %s in group %d, want %s in group %d (%s)", tt.id, ps.Action, ps.Group, tt.action, tt.group, ps.Reason)
+		}
+	}
+	if got["test"].Command != "go test ./internal/..." {
+		t.Errorf("test command = %q", got["test"].Command)
+	}
+	if got["review"].Title != "Review gt-1" {
+		t.Errorf("review title = %q", got["review"].Title)
+	}
+	if len(store.saved) != 0 {
+		t.Error("Plan saved state")
+	}
+
+	// With an earlier run on record, finished steps are done and the
+	// output condition is decided.
+	_ = store.Save(&RunState{Formula: "ship", Issue: "gt-1", Steps: map[string]*StepState{
+		"detect": {Status: StepDone, Output: "migrations/001.sql | 4 ++++"},
+		"lint":   {Status: StepFailed, Error: "exit status 1"},
+	}})
+	plan, err = e.Plan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ps := range plan {
+		got[ps.ID] = ps
+	}
+	if got["detect"].Action != PlanDone || got["lint"].Action != PlanRetry || got["migrate"].Action != PlanRun {
+		t.Errorf("resumed plan = %+v", plan)
+	}
+}
+
+func TestLint(t *testing.T) {
+	f := execFormula(t, `
+[vars.target]
+
+[[steps]]
+id = "a"
+title = "For {{issue}}"
+run = "go test {{target}}"
+parallel = true
+
+[[steps]]
+id = "b"
+title = "Uses {{undeclared}}"
+needs = ["a"]
+`)
+	problems := f.Lint(map[string]string{})
+	want := []string{
+		"undefined template variables: undeclared",
+		`step "a": run command has no value for target`,
+		`step "a" is marked parallel`,
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("Lint() = %q", problems)
+	}
+	for i, w := range want {
+		if !strings.Contains(problems[i], w) {
+			t.Errorf("problem %d = %q, want %q", i, problems[i], w)
+		}
+	}
+}
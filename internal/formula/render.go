@@ -0,0 +1,91 @@
+package formula
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ResolveVars computes the values for a formula's [vars]: given values
+// first, then overrides (per-rig settings), then each var's default.
+//
+// A var with no value and an empty default is left out, so its
+// {{placeholder}} survives rendering for bd to fill in at pour time.
+// Missing required vars and given values for undeclared vars are errors;
+// overrides for undeclared vars are ignored, since rig-wide overrides apply
+// to every formula.
+func (f *Formula) ResolveVars(given, overrides map[string]string) (map[string]string, error) {
+	var unknown []string
+	for k := range given {
+		if _, ok := f.Vars[k]; !ok {
+			unknown = append(unknown, k)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("formula %q has no var %s", f.Name, strings.Join(unknown, ", "))
+	}
+
+	values := make(map[string]string, len(f.Vars))
+	var missing []string
+	for name, v := range f.Vars {
+		if val, ok := given[name]; ok {
+			values[name] = val
+		} else if val, ok := overrides[name]; ok {
+			values[name] = val
+		} else if v.Default != "" {
+			values[name] = v.Default
+		} else if v.Required {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("formula %q needs --var %s=<value>", f.Name, strings.Join(missing, "=<value> --var "))
+	}
+	return values, nil
+}
+
+// Render returns a copy of f with {{var}} placeholders replaced by values.
+// Placeholders without a value are left as they are. Values are inserted
+// into step run commands verbatim; commands that take untrusted values
+// should read them from $GT_VAR_<NAME> instead.
+func (f *Formula) Render(values map[string]string) *Formula {
+	expand := func(s string) string {
+		return variablePattern.ReplaceAllStringFunc(s, func(m string) string {
+			if v, ok := values[variablePattern.FindStringSubmatch(m)[1]]; ok {
+				return v
+			}
+			return m
+		})
+	}
+
+	out := *f
+	out.Description = expand(f.Description)
+	out.Steps = make([]Step, len(f.Steps))
+	for i, s := range f.Steps {
+		s.Title, s.Description, s.Run = expand(s.Title), expand(s.Description), expand(s.Run)
+		out.Steps[i] = s
+	}
+	out.Legs = make([]Leg, len(f.Legs))
+	for i, l := range f.Legs {
+		l.Title, l.Description, l.Focus = expand(l.Title), expand(l.Description), expand(l.Focus)
+		out.Legs[i] = l
+	}
+	out.Template = make([]Template, len(f.Template))
+	for i, t := range f.Template {
+		t.Title, t.Description = expand(t.Title), expand(t.Description)
+		out.Template[i] = t
+	}
+	out.Aspects = make([]Aspect, len(f.Aspects))
+	for i, a := range f.Aspects {
+		a.Title, a.Description, a.Focus = expand(a.Title), expand(a.Description), expand(a.Focus)
+		out.Aspects[i] = a
+	}
+	if f.Synthesis != nil {
+		syn := *f.Synthesis
+		syn.Title, syn.Description = expand(syn.Title), expand(syn.Description)
+		out.Synthesis = &syn
+	}
+	return &out
+}
@@ -0,0 +1,92 @@
+package formula
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolveVars(t *testing.T) {
+	f, err := Parse([]byte(baseFormula))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Vars["computed"] = Var{}
+
+	tests := []struct {
+		name      string
+		given     map[string]string
+		overrides map[string]string
+		want      map[string]string
+		wantErr   string
+	}{
+		{
+			name:  "defaults",
+			given: map[string]string{"feature": "login"},
+			want:  map[string]string{"feature": "login", "test_cmd": "go test ./..."},
+		},
+		{
+			name:      "rig override beats default",
+			given:     map[string]string{"feature": "login"},
+			overrides: map[string]string{"test_cmd": "make test", "unrelated": "x"},
+			want:      map[string]string{"feature": "login", "test_cmd": "make test"},
+		},
+		{
+			name:      "given beats rig override",
+			given:     map[string]string{"feature": "login", "test_cmd": "just test"},
+			overrides: map[string]string{"test_cmd": "make test"},
+			want:      map[string]string{"feature": "login", "test_cmd": "just test"},
+		},
+		{
+			name:    "missing required",
+			wantErr: "needs --var feature",
+		},
+		{
+			name:    "unknown var",
+			given:   map[string]string{"feature": "x", "nope": "y"},
+			wantErr: "no var nope",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := f.ResolveVars(tt.given, tt.overrides)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("%s = %q, want %q", k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	f, err := Parse([]byte(baseFormula + "\n[vars.computed]\n[[steps]]\nid = \"report\"\ntitle = \"Report {{computed}}\"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := f.Render(map[string]string{"feature": "login", "test_cmd": "make test"})
+
+	if out.Description != "Base workflow for login" {
+		t.Errorf("description = %q", out.Description)
+	}
+	if got := out.GetStep("test").Title; got != "Run make test" {
+		t.Errorf("test title = %q", got)
+	}
+	if got := out.GetStep("report").Title; got != "Report {{computed}}" {
+		t.Errorf("unvalued placeholder should survive, got %q", got)
+	}
+	if f.GetStep("implement").Title != "Implement {{feature}}" {
+		t.Error("Render modified the original formula")
+	}
+}
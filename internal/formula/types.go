@@ -30,6 +30,12 @@ type Formula struct {
 	Type        FormulaType `toml:"type"`
 	Version     int         `toml:"version"`
 
+	// Composition: parents whose content this formula inherits and
+	// overrides, and formulas whose steps, legs, and vars it pulls in.
+	// Resolved by ParseFile and Loader; see compose.go.
+	Extends  []string `toml:"extends"`
+	Includes []string `toml:"includes"`
+
 	// Convoy-specific
 	Inputs    map[string]Input `toml:"inputs"`
 	Prompts   map[string]string `toml:"prompts"`
@@ -94,6 +100,30 @@ type Step struct {
 	Description string   `toml:"description"`
 	Needs       []string `toml:"needs"`
 	Parallel    bool     `toml:"parallel"` // If true, this step can run concurrently with other parallel steps that share the same needs
+
+	// Run is a shell command the executor runs for this step. Steps
+	// without one are agent steps: the executor waits for them to be
+	// marked done.
+	Run     string `toml:"run"`
+	Timeout string `toml:"timeout"` // e.g. "10m"; default DefaultStepTimeout
+
+	// When makes the step conditional: if it doesn't hold, the executor
+	// skips the step, and steps that need it proceed as if it were done.
+	When *Condition `toml:"when"`
+}
+
+// Condition gates a step. Every field that is set must match.
+type Condition struct {
+	// Label requires the issue to carry this label.
+	Label string `toml:"label"`
+	// Files requires a changed file to match this glob, by path or base
+	// name (e.g. "*.go", "web/*").
+	Files string `toml:"files"`
+	// Step and Output require an earlier step's output to match the
+	// Output regular expression. Step must be among the step's needs,
+	// directly or transitively.
+	Step   string `toml:"step"`
+	Output string `toml:"output"`
 }
 
 // Template represents a template step in an expansion formula.
@@ -74,6 +74,8 @@ func (f *Formula) ValidateTemplateVariables() error {
 		allText.WriteString("\n")
 		allText.WriteString(step.Description)
 		allText.WriteString("\n")
+		allText.WriteString(step.Run)
+		allText.WriteString("\n")
 	}
 
 	// Legs (convoy)
@@ -8,7 +8,11 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/replay"
 )
 
 // GitError contains raw output from a git command for agent observation.
@@ -98,7 +102,7 @@ func (g *Git) run(args ...string) (string, error) {
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	err := cmd.Run()
+	err := replay.Run(cmd)
 	if err != nil {
 		return "", g.wrapError(err, stdout.String(), stderr.String(), args)
 	}
@@ -341,6 +345,28 @@ func (g *Git) Checkout(ref string) error {
 	return err
 }
 
+// CheckoutDetached force-checks out ref with a detached HEAD, discarding
+// local modifications. Meant for scratch worktrees.
+func (g *Git) CheckoutDetached(ref string) error {
+	_, err := g.run("checkout", "--force", "--detach", ref)
+	return err
+}
+
+// ResetScratch returns a scratch worktree to a clean checkout of ref with a
+// detached HEAD: any in-progress merge, pick, or revert is dropped, and
+// modified and untracked files are discarded. Ignored files (build caches)
+// are kept.
+func (g *Git) ResetScratch(ref string) error {
+	if _, err := g.run("reset", "--hard", "--quiet"); err != nil {
+		return err
+	}
+	if err := g.CheckoutDetached(ref); err != nil {
+		return err
+	}
+	_, err := g.run("clean", "-ffd", "--quiet")
+	return err
+}
+
 // Fetch fetches from the remote.
 func (g *Git) Fetch(remote string) error {
 	_, err := g.run("fetch", remote)
@@ -615,6 +641,21 @@ func (g *Git) CheckConflicts(source, target string) ([]string, error) {
 	return nil, nil
 }
 
+// TrialMerge merges branch into HEAD without committing, leaving the result
+// staged for inspection (see StagedDiff). It returns the conflicting files
+// when the merge conflicts. The caller is responsible for cleanup, usually
+// by running it in a throwaway worktree.
+func (g *Git) TrialMerge(branch string) ([]string, error) {
+	_, mergeErr := g.runMergeCheck("merge", "--no-commit", "--no-ff", branch)
+	if mergeErr == nil {
+		return nil, nil
+	}
+	if conflicts, err := g.GetConflictingFiles(); err == nil && len(conflicts) > 0 {
+		return conflicts, nil
+	}
+	return nil, mergeErr
+}
+
 // runMergeCheck runs a git merge command and returns error info from both stdout and stderr.
 // ZFC: Returns GitError with raw output for agent observation.
 func (g *Git) runMergeCheck(args ...string) (string, error) {
@@ -1051,6 +1092,20 @@ func (g *Git) CommitsAhead(base, branch string) (int, error) {
 	return count, nil
 }
 
+// FirstParentCommits returns the commits on ref's first-parent history
+// after base, oldest first. These are the commits that landed on a branch
+// one merge at a time.
+func (g *Git) FirstParentCommits(base, ref string) ([]string, error) {
+	out, err := g.run("rev-list", "--first-parent", "--reverse", base+".."+ref)
+	if err != nil {
+		return nil, err
+	}
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
+}
+
 // CountCommitsBehind returns the number of commits that HEAD is behind the given ref.
 // For example, CountCommitsBehind("origin/main") returns how many commits
 // are on origin/main that are not on the current HEAD.
@@ -1069,6 +1124,410 @@ func (g *Git) CountCommitsBehind(ref string) (int, error) {
 	return count, nil
 }
 
+// DiffStat summarizes the changes a branch makes relative to a base.
+type DiffStat struct {
+	Files   int `json:"files"`
+	Added   int `json:"added"`
+	Deleted int `json:"deleted"`
+}
+
+// DiffStat returns the files and lines changed on branch since it diverged
+// from base (the three-dot diff, i.e. what merging branch would introduce).
+// Binary files count toward Files but not toward line totals.
+func (g *Git) DiffStat(base, branch string) (*DiffStat, error) {
+	out, err := g.run("diff", "--numstat", base+"..."+branch)
+	if err != nil {
+		return nil, err
+	}
+	return parseNumstat(out), nil
+}
+
+// parseNumstat parses `git diff --numstat` output.
+func parseNumstat(out string) *DiffStat {
+	stat := &DiffStat{}
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.SplitN(line, "\t", 3)
+		if len(parts) < 3 {
+			continue
+		}
+		stat.Files++
+		// Binary files report "-" for both counts
+		if n, err := strconv.Atoi(parts[0]); err == nil {
+			stat.Added += n
+		}
+		if n, err := strconv.Atoi(parts[1]); err == nil {
+			stat.Deleted += n
+		}
+	}
+	return stat
+}
+
+// FileChange is one file in a diff.
+type FileChange struct {
+	Status  string `json:"status"`             // A, M, D, R (renamed), C (copied), T (type changed)
+	Path    string `json:"path"`               // Path on the branch (for deletions, the removed path)
+	OldPath string `json:"old_path,omitempty"` // Path on the base, for renames and copies
+}
+
+// ChangedFiles lists the files changed on branch since it diverged from base,
+// with renames detected.
+func (g *Git) ChangedFiles(base, branch string) ([]FileChange, error) {
+	out, err := g.run("diff", "--name-status", "-M", base+"..."+branch)
+	if err != nil {
+		return nil, err
+	}
+	return parseNameStatus(out), nil
+}
+
+// Diff returns the unified diff that merging branch into base would
+// introduce (the three-dot diff).
+func (g *Git) Diff(base, branch string) (string, error) {
+	return g.run("diff", "-M", base+"..."+branch)
+}
+
+// FileSizes returns the size in bytes of each of paths at ref. Paths that
+// don't exist at ref, or aren't files, are omitted.
+func (g *Git) FileSizes(ref string, paths []string) (map[string]int64, error) {
+	sizes := make(map[string]int64, len(paths))
+	if len(paths) == 0 {
+		return sizes, nil
+	}
+	out, err := g.run(append([]string{"ls-tree", "-l", "-z", "--full-tree", ref, "--"}, paths...)...)
+	if err != nil {
+		return nil, err
+	}
+	// Each entry: "<mode> <type> <object> <size>\t<path>"
+	for _, entry := range strings.Split(out, "\x00") {
+		meta, path, ok := strings.Cut(entry, "\t")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(meta)
+		if len(fields) != 4 || fields[1] != "blob" {
+			continue
+		}
+		if n, err := strconv.ParseInt(fields[3], 10, 64); err == nil {
+			sizes[path] = n
+		}
+	}
+	return sizes, nil
+}
+
+// StagedDiff returns the unified diff, stats, and changed files of the
+// index against HEAD.
+func (g *Git) StagedDiff() (string, *DiffStat, []FileChange, error) {
+	patch, err := g.run("diff", "--cached", "-M")
+	if err != nil {
+		return "", nil, nil, err
+	}
+	numstat, err := g.run("diff", "--cached", "--numstat", "-M")
+	if err != nil {
+		return "", nil, nil, err
+	}
+	nameStatus, err := g.run("diff", "--cached", "--name-status", "-M")
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return patch, parseNumstat(numstat), parseNameStatus(nameStatus), nil
+}
+
+// CommitSubjects returns "<short sha> <subject>" for each commit on branch
+// since it diverged from base, oldest first.
+func (g *Git) CommitSubjects(base, branch string) ([]string, error) {
+	out, err := g.run("log", "--reverse", "--format=%h %s", base+".."+branch)
+	if err != nil {
+		return nil, err
+	}
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// CommitMessage is a commit's full message.
+type CommitMessage struct {
+	SHA     string
+	Message string
+}
+
+// CommitMessages returns the full message of each non-merge commit on
+// branch since it diverged from base, oldest first.
+func (g *Git) CommitMessages(base, branch string) ([]CommitMessage, error) {
+	out, err := g.run("log", "--reverse", "--no-merges", "--format=%H%x1f%B%x1e", base+".."+branch)
+	if err != nil {
+		return nil, err
+	}
+	var commits []CommitMessage
+	for _, record := range strings.Split(out, "\x1e") {
+		sha, msg, ok := strings.Cut(strings.TrimLeft(record, "\n"), "\x1f")
+		if !ok {
+			continue
+		}
+		commits = append(commits, CommitMessage{SHA: sha, Message: strings.TrimSpace(msg)})
+	}
+	return commits, nil
+}
+
+// Signing is the git configuration for signing and verifying commits. It
+// is passed as -c options, leaving the clone's own config untouched.
+type Signing struct {
+	Format         string // gpg.format: "ssh" or "openpgp"
+	Key            string // user.signingkey
+	Name           string // committer name
+	Email          string // committer email
+	AllowedSigners string // gpg.ssh.allowedSignersFile, to verify ssh signatures
+}
+
+func (s *Signing) configArgs() []string {
+	if s == nil {
+		return nil
+	}
+	var args []string
+	set := func(key, value string) {
+		if value != "" {
+			args = append(args, "-c", key+"="+value)
+		}
+	}
+	set("gpg.format", s.Format)
+	set("user.signingkey", s.Key)
+	set("user.name", s.Name)
+	set("user.email", s.Email)
+	set("gpg.ssh.allowedSignersFile", s.AllowedSigners)
+	return args
+}
+
+// Resign recreates the commits on the current branch since base, signed
+// with s and committed as its identity. Authors, messages, and trees are
+// kept.
+func (g *Git) Resign(base string, s *Signing) error {
+	args := append(s.configArgs(), "rebase", "--force-rebase", "--gpg-sign", base)
+	_, err := g.run(args...)
+	return err
+}
+
+// Signature statuses reported by CommitSignatures (git's %G?).
+const (
+	SignatureGood    = "G" // good, valid signature
+	SignatureNone    = "N" // unsigned
+	SignatureBad     = "B" // bad signature
+	SignatureUnknown = "U" // good signature, unknown validity
+)
+
+// CommitSignature is a commit's committer and signature as git verifies it.
+type CommitSignature struct {
+	SHA            string `json:"sha"`
+	Subject        string `json:"subject"`
+	Author         string `json:"author"`
+	CommitterName  string `json:"committer_name"`
+	CommitterEmail string `json:"committer_email"`
+	Status         string `json:"status"`           // one of git's %G? codes
+	Signer         string `json:"signer,omitempty"` // ssh principal or openpgp user ID
+	Key            string `json:"key,omitempty"`    // key fingerprint
+}
+
+// CommitSignatures verifies the signatures of the commits in revRange
+// (e.g., "v1.2..main"), newest first. With firstParent, only commits on the
+// first-parent chain are listed.
+func (g *Git) CommitSignatures(revRange string, firstParent bool, s *Signing) ([]CommitSignature, error) {
+	args := append(s.configArgs(), "log", "--format=%H%x1f%s%x1f%an%x1f%cn%x1f%ce%x1f%G?%x1f%GS%x1f%GK%x1e")
+	if firstParent {
+		args = append(args, "--first-parent")
+	}
+	out, err := g.run(append(args, revRange)...)
+	if err != nil {
+		return nil, err
+	}
+	var commits []CommitSignature
+	for _, record := range strings.Split(out, "\x1e") {
+		f := strings.Split(strings.TrimLeft(record, "\n"), "\x1f")
+		if len(f) != 8 {
+			continue
+		}
+		commits = append(commits, CommitSignature{
+			SHA: f[0], Subject: f[1], Author: f[2], CommitterName: f[3], CommitterEmail: f[4],
+			Status: f[5], Signer: f[6], Key: f[7],
+		})
+	}
+	return commits, nil
+}
+
+// CommitInfo is a commit as listed by Log.
+type CommitInfo struct {
+	SHA     string `json:"sha"`
+	Subject string `json:"subject"`
+	Author  string `json:"author"`
+	Date    string `json:"date"` // Committer date, ISO 8601
+}
+
+// Log lists up to limit commits reachable from ref, newest first. A non-empty
+// grep keeps commits whose message matches it; paths limit the history to
+// commits touching them.
+func (g *Git) Log(ref string, limit int, grep string, paths ...string) ([]CommitInfo, error) {
+	args := []string{"log", fmt.Sprintf("-n%d", limit), "--format=%H%x1f%s%x1f%an%x1f%cI"}
+	if grep != "" {
+		args = append(args, "--fixed-strings", "--grep="+grep)
+	}
+	args = append(args, ref, "--")
+	args = append(args, paths...)
+	out, err := g.run(args...)
+	if err != nil {
+		return nil, err
+	}
+	var commits []CommitInfo
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.Split(line, "\x1f")
+		if len(parts) != 4 {
+			continue
+		}
+		commits = append(commits, CommitInfo{SHA: parts[0], Subject: parts[1], Author: parts[2], Date: parts[3]})
+	}
+	return commits, nil
+}
+
+// parseNameStatus parses `git diff --name-status` output.
+func parseNameStatus(out string) []FileChange {
+	var changes []FileChange
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.Split(line, "\t")
+		if len(parts) < 2 || parts[0] == "" {
+			continue
+		}
+		// Renames and copies carry a similarity score (e.g., R087)
+		change := FileChange{Status: parts[0][:1], Path: parts[len(parts)-1]}
+		if len(parts) == 3 {
+			change.OldPath = parts[1]
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// MergeBase returns the best common ancestor of two refs.
+func (g *Git) MergeBase(a, b string) (string, error) {
+	return g.run("merge-base", a, b)
+}
+
+// ShowFile returns the content of a file at the given ref.
+func (g *Git) ShowFile(ref, path string) ([]byte, error) {
+	out, err := g.run("show", ref+":"+path)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(out), nil
+}
+
+// SubmoduleCommit returns the commit a submodule at path is pinned to in ref.
+func (g *Git) SubmoduleCommit(ref, path string) (string, error) {
+	out, err := g.run("ls-tree", ref, "--", path)
+	if err != nil {
+		return "", err
+	}
+	// <mode> SP <type> SP <object> TAB <path>
+	fields := strings.Fields(out)
+	if len(fields) < 3 || fields[1] != "commit" {
+		return "", fmt.Errorf("%s is not a submodule in %s", path, ref)
+	}
+	return fields[2], nil
+}
+
+// SetSubmoduleCommit stages the submodule at path pinned to commit, without
+// needing the submodule checked out.
+func (g *Git) SetSubmoduleCommit(path, commit string) error {
+	_, err := g.run("update-index", "--add", "--cacheinfo", "160000,"+commit+","+path)
+	return err
+}
+
+// Tags returns the tags matching a glob pattern (all tags if empty).
+func (g *Git) Tags(pattern string) ([]string, error) {
+	args := []string{"tag", "--list"}
+	if pattern != "" {
+		args = append(args, pattern)
+	}
+	out, err := g.run(args...)
+	if err != nil {
+		return nil, err
+	}
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// FetchTags fetches all tags from the remote.
+func (g *Git) FetchTags(remote string) error {
+	_, err := g.run("fetch", "--tags", remote)
+	return err
+}
+
+// CreateTag creates an annotated tag at ref.
+func (g *Git) CreateTag(name, ref, message string) error {
+	_, err := g.run("tag", "-a", name, ref, "-m", message)
+	return err
+}
+
+// PushTag pushes a tag to the remote.
+func (g *Git) PushTag(remote, tag string) error {
+	_, err := g.run("push", remote, "refs/tags/"+tag)
+	return err
+}
+
+// CommitTime returns the committer date of the commit ref points to.
+func (g *Git) CommitTime(ref string) (time.Time, error) {
+	out, err := g.run("log", "-1", "--format=%cI", ref+"^{commit}")
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339, out)
+}
+
+// Revert commits the inverse of a commit onto the current branch. Merge
+// commits are reverted relative to their first parent.
+func (g *Git) Revert(commit, message string) error {
+	args := []string{"revert", "--no-edit"}
+	parents, err := g.run("rev-list", "--parents", "-n", "1", commit)
+	if err != nil {
+		return err
+	}
+	if len(strings.Fields(parents)) > 2 {
+		args = append(args, "-m", "1")
+	}
+	if _, err := g.run(append(args, commit)...); err != nil {
+		_, _ = g.run("revert", "--abort")
+		return err
+	}
+	if message != "" {
+		_, err = g.run("commit", "--amend", "-m", message)
+	}
+	return err
+}
+
+// CherryPick applies a commit onto the current branch, noting the original
+// commit in the message (-x). Merge commits are picked relative to their
+// first parent. Strategy options (e.g. "ignore-space-change") are passed
+// with -X. On failure the pick is left in progress so the caller can
+// inspect it with GetConflictingFiles; call CherryPickAbort to give up.
+func (g *Git) CherryPick(commit string, strategyOptions ...string) error {
+	args := []string{"cherry-pick", "-x"}
+	parents, err := g.run("rev-list", "--parents", "-n", "1", commit)
+	if err != nil {
+		return err
+	}
+	if len(strings.Fields(parents)) > 2 {
+		args = append(args, "-m", "1")
+	}
+	for _, opt := range strategyOptions {
+		args = append(args, "-X", opt)
+	}
+	_, err = g.run(append(args, commit)...)
+	return err
+}
+
+// CherryPickAbort abandons an in-progress cherry-pick.
+func (g *Git) CherryPickAbort() error {
+	_, err := g.run("cherry-pick", "--abort")
+	return err
+}
+
 // StashCount returns the number of stashes in the repository.
 func (g *Git) StashCount() (int, error) {
 	out, err := g.run("stash", "list")
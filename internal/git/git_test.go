@@ -4,8 +4,10 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 func initTestRepo(t *testing.T) string {
@@ -190,6 +192,183 @@ func TestStatus(t *testing.T) {
 	}
 }
 
+func TestDiffStat(t *testing.T) {
+	dir := initTestRepo(t)
+	g := NewGit(dir)
+
+	base, err := g.CurrentBranch()
+	if err != nil {
+		t.Fatalf("CurrentBranch: %v", err)
+	}
+	if err := g.CreateBranch("feature"); err != nil {
+		t.Fatalf("CreateBranch: %v", err)
+	}
+	if err := g.Checkout("feature"); err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Changed\nline two\n"), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "blob.bin"), []byte{0, 1, 2, 0}, 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := g.Add("."); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := g.Commit("change"); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	stat, err := g.DiffStat(base, "feature")
+	if err != nil {
+		t.Fatalf("DiffStat: %v", err)
+	}
+	want := DiffStat{Files: 2, Added: 2, Deleted: 1}
+	if *stat != want {
+		t.Errorf("DiffStat = %+v, want %+v", *stat, want)
+	}
+
+	diff, err := g.Diff(base, "feature")
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	if !strings.Contains(diff, "+line two") || !strings.Contains(diff, "blob.bin") {
+		t.Errorf("Diff missing changes:\n%s", diff)
+	}
+	subjects, err := g.CommitSubjects(base, "feature")
+	if err != nil {
+		t.Fatalf("CommitSubjects: %v", err)
+	}
+	if len(subjects) != 1 || !strings.HasSuffix(subjects[0], " change") {
+		t.Errorf("CommitSubjects = %q", subjects)
+	}
+	messages, err := g.CommitMessages(base, "feature")
+	if err != nil {
+		t.Fatalf("CommitMessages: %v", err)
+	}
+	if len(messages) != 1 || messages[0].Message != "change" || len(messages[0].SHA) != 40 {
+		t.Errorf("CommitMessages = %+v", messages)
+	}
+}
+
+func TestChangedFilesAndShowFile(t *testing.T) {
+	dir := initTestRepo(t)
+	g := NewGit(dir)
+
+	base, err := g.CurrentBranch()
+	if err != nil {
+		t.Fatalf("CurrentBranch: %v", err)
+	}
+	if err := g.CreateBranch("feature"); err != nil {
+		t.Fatalf("CreateBranch: %v", err)
+	}
+	if err := g.Checkout("feature"); err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "new.txt"), []byte("hello\n"), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := g.Add("new.txt"); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := g.Commit("add new"); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	changes, err := g.ChangedFiles(base, "feature")
+	if err != nil {
+		t.Fatalf("ChangedFiles: %v", err)
+	}
+	if len(changes) != 1 || changes[0] != (FileChange{Status: "A", Path: "new.txt"}) {
+		t.Errorf("ChangedFiles = %+v", changes)
+	}
+
+	content, err := g.ShowFile(base, "README.md")
+	if err != nil {
+		t.Fatalf("ShowFile: %v", err)
+	}
+	if string(content) != "# Test" {
+		t.Errorf("ShowFile = %q, want %q", content, "# Test")
+	}
+}
+
+func TestFileSizes(t *testing.T) {
+	dir := initTestRepo(t)
+	g := NewGit(dir)
+
+	if err := os.MkdirAll(filepath.Join(dir, "sub dir"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sub dir", "big.bin"), make([]byte, 4096), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Add("sub dir/big.bin"); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := g.Commit("add big"); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	sizes, err := g.FileSizes("HEAD", []string{"README.md", "sub dir/big.bin", "missing.txt", "sub dir"})
+	if err != nil {
+		t.Fatalf("FileSizes: %v", err)
+	}
+	want := map[string]int64{"README.md": 7, "sub dir/big.bin": 4096}
+	if !reflect.DeepEqual(sizes, want) {
+		t.Errorf("FileSizes = %v, want %v", sizes, want)
+	}
+}
+
+func TestTagsAndCommitTime(t *testing.T) {
+	dir := initTestRepo(t)
+	g := NewGit(dir)
+
+	if tags, err := g.Tags("v*"); err != nil || len(tags) != 0 {
+		t.Fatalf("Tags on untagged repo = %v, %v", tags, err)
+	}
+	if err := g.CreateTag("v1.0.0", "HEAD", "Release v1.0.0"); err != nil {
+		t.Fatalf("CreateTag: %v", err)
+	}
+	if err := g.CreateTag("other", "HEAD", "not a release"); err != nil {
+		t.Fatalf("CreateTag: %v", err)
+	}
+
+	tags, err := g.Tags("v*")
+	if err != nil {
+		t.Fatalf("Tags: %v", err)
+	}
+	if len(tags) != 1 || tags[0] != "v1.0.0" {
+		t.Errorf("Tags(v*) = %v, want [v1.0.0]", tags)
+	}
+
+	ts, err := g.CommitTime("v1.0.0")
+	if err != nil {
+		t.Fatalf("CommitTime: %v", err)
+	}
+	if ts.IsZero() || time.Since(ts) > time.Hour {
+		t.Errorf("CommitTime = %v, want recent", ts)
+	}
+}
+
+func TestParseNameStatus(t *testing.T) {
+	out := "M\tgit.go\nR087\told.go\tnew.go\nD\tgone.go"
+	got := parseNameStatus(out)
+	want := []FileChange{
+		{Status: "M", Path: "git.go"},
+		{Status: "R", Path: "new.go", OldPath: "old.go"},
+		{Status: "D", Path: "gone.go"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d changes, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("change %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
 func TestAddAndCommit(t *testing.T) {
 	dir := initTestRepo(t)
 	g := NewGit(dir)
@@ -535,6 +714,52 @@ func TestCloneBareHasOriginRefs(t *testing.T) {
 	}
 }
 
+func TestRevert(t *testing.T) {
+	dir := initTestRepo(t)
+	g := NewGit(dir)
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	readme := filepath.Join(dir, "README.md")
+
+	// Plain commit
+	if err := os.WriteFile(readme, []byte("# Changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run("commit", "-am", "change readme")
+	if err := g.Revert("HEAD", "Revert readme change"); err != nil {
+		t.Fatalf("Revert: %v", err)
+	}
+	if data, _ := os.ReadFile(readme); string(data) != "# Test\n" {
+		t.Errorf("README after revert = %q", data)
+	}
+	if msg, _ := g.run("log", "-1", "--format=%s"); msg != "Revert readme change" {
+		t.Errorf("revert message = %q", msg)
+	}
+
+	// Merge commit
+	base, _ := g.CurrentBranch()
+	run("checkout", "-b", "feature")
+	if err := os.WriteFile(filepath.Join(dir, "feature.txt"), []byte("x\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run("add", ".")
+	run("commit", "-m", "add feature")
+	run("checkout", base)
+	run("merge", "--no-ff", "-m", "merge feature", "feature")
+	if err := g.Revert("HEAD", ""); err != nil {
+		t.Fatalf("Revert merge: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "feature.txt")); !os.IsNotExist(err) {
+		t.Errorf("feature.txt still present after reverting merge: %v", err)
+	}
+}
+
 func stringContains(s, substr string) bool {
 	for i := 0; i <= len(s)-len(substr); i++ {
 		if s[i:i+len(substr)] == substr {
@@ -543,3 +768,140 @@ func stringContains(s, substr string) bool {
 	}
 	return false
 }
+
+func TestLog(t *testing.T) {
+	dir := initTestRepo(t)
+	g := NewGit(dir)
+
+	for _, c := range []struct{ file, msg string }{
+		{"a.txt", "gt-abc: add a"},
+		{"b.txt", "unrelated"},
+		{"a.txt", "gt-abc: tweak a"},
+	} {
+		if err := os.WriteFile(filepath.Join(dir, c.file), []byte(c.msg+"\n"), 0644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+		if err := g.Add(c.file); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+		if err := g.Commit(c.msg); err != nil {
+			t.Fatalf("Commit: %v", err)
+		}
+	}
+
+	commits, err := g.Log("HEAD", 10, "gt-abc")
+	if err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+	if len(commits) != 2 || commits[0].Subject != "gt-abc: tweak a" || commits[0].SHA == "" || commits[0].Date == "" {
+		t.Errorf("Log(grep) = %+v", commits)
+	}
+
+	commits, err = g.Log("HEAD", 10, "", "b.txt")
+	if err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+	if len(commits) != 1 || commits[0].Subject != "unrelated" {
+		t.Errorf("Log(paths) = %+v", commits)
+	}
+
+	if commits, _ := g.Log("HEAD", 1, ""); len(commits) != 1 {
+		t.Errorf("Log(limit 1) returned %d commits", len(commits))
+	}
+}
+
+func TestResignAndCommitSignatures(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not installed")
+	}
+	dir := initTestRepo(t)
+	g := NewGit(dir)
+	base, err := g.CurrentBranch()
+	if err != nil {
+		t.Fatalf("CurrentBranch: %v", err)
+	}
+
+	keys := t.TempDir()
+	key := filepath.Join(keys, "key")
+	if out, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", "refinery@test", "-f", key).CombinedOutput(); err != nil {
+		t.Fatalf("ssh-keygen: %v: %s", err, out)
+	}
+	pub, _ := os.ReadFile(key + ".pub")
+	allowed := filepath.Join(keys, "allowed_signers")
+	if err := os.WriteFile(allowed, []byte(`refinery@test namespaces="git" `+string(pub)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := &Signing{Format: "ssh", Key: key, Name: "Refinery", Email: "refinery@test", AllowedSigners: allowed}
+
+	if err := g.CreateBranch("temp"); err != nil {
+		t.Fatalf("CreateBranch: %v", err)
+	}
+	if err := g.Checkout("temp"); err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	for _, msg := range []string{"work 1", "work 2"} {
+		if err := os.WriteFile(filepath.Join(dir, "f.txt"), []byte(msg), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := g.Add("f.txt"); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+		if err := g.Commit(msg); err != nil {
+			t.Fatalf("Commit: %v", err)
+		}
+	}
+
+	sigs, err := g.CommitSignatures(base+"..temp", false, s)
+	if err != nil {
+		t.Fatalf("CommitSignatures: %v", err)
+	}
+	if len(sigs) != 2 || sigs[0].Status != SignatureNone {
+		t.Fatalf("before Resign: %+v", sigs)
+	}
+
+	if err := g.Resign(base, s); err != nil {
+		t.Fatalf("Resign: %v", err)
+	}
+	sigs, err = g.CommitSignatures(base+"..temp", false, s)
+	if err != nil {
+		t.Fatalf("CommitSignatures: %v", err)
+	}
+	if len(sigs) != 2 {
+		t.Fatalf("got %d commits, want 2", len(sigs))
+	}
+	for _, c := range sigs {
+		if c.Status != SignatureGood || c.Signer != "refinery@test" || c.CommitterEmail != "refinery@test" || c.Author != "Test User" {
+			t.Errorf("after Resign: %+v", c)
+		}
+	}
+	if sigs[0].Subject != "work 2" {
+		t.Errorf("newest commit = %q, want work 2", sigs[0].Subject)
+	}
+}
+
+func TestSubmoduleCommit(t *testing.T) {
+	dir := initTestRepo(t)
+	g := NewGit(dir)
+	head, err := g.Rev("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := g.SetSubmoduleCommit("vendor/lib", head); err != nil {
+		t.Fatalf("SetSubmoduleCommit: %v", err)
+	}
+	if err := g.Commit("pin lib"); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	got, err := g.SubmoduleCommit("HEAD", "vendor/lib")
+	if err != nil {
+		t.Fatalf("SubmoduleCommit: %v", err)
+	}
+	if got != head {
+		t.Errorf("SubmoduleCommit = %s, want %s", got, head)
+	}
+
+	if _, err := g.SubmoduleCommit("HEAD", "README.md"); err == nil {
+		t.Error("a regular file is not a submodule")
+	}
+}
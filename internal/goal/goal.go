@@ -0,0 +1,170 @@
+// Package goal rolls the progress of convoys and milestones up to the
+// town's named goals, defined in town settings (see config.GoalConfig).
+//
+// A convoy or milestone links to a goal with a goal:<name> label on its
+// bead. A goal's progress counts the distinct issues its convoys and
+// milestones track; an issue tracked by several of them counts once.
+package goal
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/milestone"
+)
+
+// LabelPrefix prefixes the label linking a convoy or milestone to a goal.
+const LabelPrefix = "goal:"
+
+// HealthActive is the health of a goal with open work but neither a
+// target nor milestones to measure it against.
+const HealthActive = "active"
+
+// validName matches a goal name; names become labels.
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Load returns the goals defined in the town's settings.
+func Load(townRoot string) (map[string]*config.GoalConfig, error) {
+	settings, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(townRoot))
+	if err != nil {
+		return nil, err
+	}
+	return settings.Goals, nil
+}
+
+// Names returns the goal names, sorted.
+func Names(goals map[string]*config.GoalConfig) []string {
+	names := make([]string, 0, len(goals))
+	for name := range goals {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate checks a goal's name and target date.
+func Validate(name string, g *config.GoalConfig) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("invalid goal name %q (letters, digits, '.', '_', '-')", name)
+	}
+	if g != nil && g.Target != "" {
+		if _, err := time.Parse(time.DateOnly, g.Target); err != nil {
+			return fmt.Errorf("goal %q: invalid target %q: want YYYY-MM-DD", name, g.Target)
+		}
+	}
+	return nil
+}
+
+// Label returns the label linking a bead to the named goal.
+func Label(name string) string {
+	return LabelPrefix + name
+}
+
+// FromLabels returns the goals a bead's labels link it to.
+func FromLabels(labels []string) []string {
+	var names []string
+	for _, l := range labels {
+		if name, ok := strings.CutPrefix(l, LabelPrefix); ok && name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Link is a convoy or milestone linked to a goal.
+type Link struct {
+	ID     string `json:"id"`
+	Kind   string `json:"kind"` // "convoy" or "milestone"
+	Title  string `json:"title"`
+	Status string `json:"status"`
+	Total  int    `json:"total"`
+	Closed int    `json:"closed"`
+	Health string `json:"health,omitempty"` // milestones only
+}
+
+// Status is a goal's rolled-up progress.
+type Status struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Owner       string    `json:"owner,omitempty"`
+	Target      time.Time `json:"target,omitempty"`
+	Links       []Link    `json:"links"`
+	Total       int       `json:"total"`
+	Closed      int       `json:"closed"`
+	Health      string    `json:"health"`
+	Projected   time.Time `json:"projected,omitempty"`
+}
+
+// Percent is the share of the goal's issues closed, 0-100.
+func (s *Status) Percent() int {
+	if s.Total == 0 {
+		return 0
+	}
+	return s.Closed * 100 / s.Total
+}
+
+// healthRank orders milestone health from worst to best.
+var healthRank = map[string]int{
+	milestone.HealthLate:    0,
+	milestone.HealthAtRisk:  1,
+	milestone.HealthUnknown: 2,
+	milestone.HealthOnTrack: 3,
+	milestone.HealthDone:    4,
+}
+
+// Build rolls links and the issues they track up to a goal. start is when
+// work toward the goal began, normally the earliest link's creation.
+//
+// A goal with a target is projected like a milestone over its distinct
+// issues. Without a target it takes the worst health of its milestones,
+// or is done or active by whether work is still open.
+func Build(name string, g *config.GoalConfig, links []Link, issues []milestone.Issue, start, now time.Time) *Status {
+	if g == nil {
+		g = &config.GoalConfig{}
+	}
+	s := &Status{Name: name, Description: g.Description, Owner: g.Owner, Links: append([]Link(nil), links...)}
+	sort.SliceStable(s.Links, func(i, j int) bool { return s.Links[i].ID < s.Links[j].ID })
+
+	seen := make(map[string]bool, len(issues))
+	distinct := make([]milestone.Issue, 0, len(issues))
+	for _, it := range issues {
+		if !seen[it.ID] {
+			seen[it.ID] = true
+			distinct = append(distinct, it)
+		}
+	}
+
+	if target, err := time.ParseInLocation(time.DateOnly, g.Target, now.Location()); err == nil {
+		s.Target = target
+		p := milestone.Build(name, g.Description, "", start, target, distinct, now)
+		s.Total, s.Closed, s.Health, s.Projected = p.Total, p.Closed, p.Health, p.Projected
+		return s
+	}
+
+	for _, it := range distinct {
+		s.Total++
+		if it.IsClosed() {
+			s.Closed++
+		}
+	}
+	switch {
+	case s.Total > 0 && s.Closed == s.Total:
+		s.Health = milestone.HealthDone
+	case s.Total == 0:
+		s.Health = milestone.HealthUnknown
+	default:
+		s.Health = HealthActive
+	}
+	for _, l := range links {
+		if rank, ok := healthRank[l.Health]; ok && l.Kind == "milestone" && l.Status != "closed" {
+			if cur, ok := healthRank[s.Health]; !ok || rank < cur {
+				s.Health = l.Health
+			}
+		}
+	}
+	return s
+}
@@ -0,0 +1,87 @@
+package goal
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/milestone"
+)
+
+func day(d int) time.Time {
+	return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		goal    *config.GoalConfig
+		wantErr bool
+	}{
+		{"faster-onboarding", &config.GoalConfig{Target: "2026-06-30"}, false},
+		{"q2.reliability", nil, false},
+		{"has space", nil, true},
+		{"-leading", nil, true},
+		{"ok", &config.GoalConfig{Target: "June"}, true},
+	}
+	for _, tt := range tests {
+		if err := Validate(tt.name, tt.goal); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%q) err = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestFromLabels(t *testing.T) {
+	got := FromLabels([]string{"gt:milestone", Label("onboarding"), "goal:", Label("reliability")})
+	want := []string{"onboarding", "reliability"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FromLabels = %v, want %v", got, want)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	issues := []milestone.Issue{
+		{ID: "gt-1", Status: "closed", Closed: day(2)},
+		{ID: "gt-2", Status: "open"},
+		// Tracked by both the convoy and the milestone: counts once.
+		{ID: "gt-1", Status: "closed", Closed: day(2)},
+		{ID: "bd-1", Status: "open"},
+	}
+	links := []Link{
+		{ID: "hq-m1", Kind: "milestone", Status: "open", Total: 2, Closed: 1, Health: milestone.HealthAtRisk},
+		{ID: "hq-cv-a", Kind: "convoy", Status: "open", Total: 2, Closed: 1},
+	}
+
+	tests := []struct {
+		name   string
+		goal   *config.GoalConfig
+		links  []Link
+		issues []milestone.Issue
+		health string
+	}{
+		{"no target takes worst milestone", &config.GoalConfig{}, links, issues, milestone.HealthAtRisk},
+		{"no target, convoys only", nil, links[1:], issues, HealthActive},
+		{"no target, all closed", nil, links[1:], issues[:1], milestone.HealthDone},
+		{"no target, nothing linked", nil, nil, nil, milestone.HealthUnknown},
+		// 1 closed in 4 days leaves 2 open for 8 more days.
+		{"target on track", &config.GoalConfig{Target: "2026-03-20"}, links, issues, milestone.HealthOnTrack},
+		{"target at risk", &config.GoalConfig{Target: "2026-03-08"}, links, issues, milestone.HealthAtRisk},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Build("onboarding", tt.goal, tt.links, tt.issues, day(1), day(5))
+			if s.Health != tt.health {
+				t.Errorf("health = %s, want %s", s.Health, tt.health)
+			}
+		})
+	}
+
+	s := Build("onboarding", &config.GoalConfig{Owner: "mayor/"}, links, issues, day(1), day(5))
+	if s.Total != 3 || s.Closed != 1 || s.Percent() != 33 {
+		t.Errorf("progress = %d/%d (%d%%), want 1/3", s.Closed, s.Total, s.Percent())
+	}
+	if s.Links[0].ID != "hq-cv-a" || s.Owner != "mayor/" {
+		t.Errorf("status = %+v", s)
+	}
+}
@@ -0,0 +1,387 @@
+// Package guardrails checks the files an MR adds for things that should
+// never land: oversized files, generated or vendored code outside the
+// paths a rig allows it in, forbidden files like .env, and secrets.
+//
+// Agents occasionally commit build artifacts, dependency trees, or
+// credentials along with their work. The refinery runs these checks on
+// every MR and rejects or flags it, per the rig's configuration.
+package guardrails
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/diskusage"
+	"github.com/steveyegge/gastown/internal/testsuite"
+)
+
+// Guardrails a violation can trip.
+const (
+	RuleLargeFile = "large-file"
+	RuleGenerated = "generated-code"
+	RuleForbidden = "forbidden-file"
+	RuleSecret    = "secret"
+)
+
+// Rules lists every guardrail.
+var Rules = []string{RuleLargeFile, RuleGenerated, RuleForbidden, RuleSecret}
+
+// What tripping a guardrail does.
+const (
+	ActionReject = "reject"
+	ActionWarn   = "warn"
+	ActionOff    = "off"
+)
+
+// DefaultMaxFileSize is the largest file an MR may add by default.
+const DefaultMaxFileSize = 10 << 20
+
+// DefaultForbiddenPaths are files that must never be committed.
+var DefaultForbiddenPaths = []string{
+	"**/.env", "**/.env.*",
+	"**/*.pem", "**/*.key", "**/*.p12", "**/*.pfx", "**/*.jks", "**/*.keystore",
+	"**/id_rsa", "**/id_dsa", "**/id_ecdsa", "**/id_ed25519",
+	"**/.npmrc", "**/.pypirc", "**/.netrc",
+}
+
+// templateSuffixes mark example files that look forbidden but aren't,
+// like .env.example.
+var templateSuffixes = []string{".example", ".sample", ".template", ".dist"}
+
+// vendorDirs hold third-party code wherever they appear.
+var vendorDirs = []string{"vendor", "node_modules", "third_party", "bower_components"}
+
+// generatedMarkers are the conventional "this file is generated" comments.
+var generatedMarkers = []*regexp.Regexp{
+	regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`), // Go
+	regexp.MustCompile(`@generated\b`),                         // Meta/Phabricator, protobuf, many others
+	regexp.MustCompile(`(?i)\bauto-?generated\b.*\bdo not (edit|modify)\b`),
+}
+
+// markerLines is how far into a file a generated marker is looked for.
+const markerLines = 5
+
+// Violation is one tripped guardrail.
+type Violation struct {
+	Rule    string `json:"rule"`
+	Action  string `json:"action"` // reject or warn
+	Path    string `json:"path"`
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+// String renders the violation for an MR failure message.
+func (v Violation) String() string {
+	loc := v.Path
+	if v.Line > 0 {
+		loc = fmt.Sprintf("%s:%d", v.Path, v.Line)
+	}
+	return fmt.Sprintf("%s: %s (%s)", loc, v.Message, v.Rule)
+}
+
+// Line is a line an MR adds.
+type Line struct {
+	Num  int
+	Text string
+}
+
+// File is a file an MR adds or changes.
+type File struct {
+	Path     string
+	Status   string // A, M, R, C, or T, as from git diff --name-status
+	Size     int64  // on the branch
+	BaseSize int64  // on the target; 0 if the file is new
+	Binary   bool
+	Added    []Line // lines the MR adds, in order
+}
+
+// Validate checks a guardrails config.
+func Validate(cfg *config.GuardrailsConfig) error {
+	if cfg == nil {
+		return nil
+	}
+	for rule, action := range cfg.Actions {
+		if !isRule(rule) {
+			return fmt.Errorf("guardrails.actions: unknown guardrail %q (want one of %s)", rule, strings.Join(Rules, ", "))
+		}
+		switch action {
+		case ActionReject, ActionWarn, ActionOff:
+		default:
+			return fmt.Errorf("guardrails.actions.%s: unknown action %q (want %s, %s, or %s)", rule, action, ActionReject, ActionWarn, ActionOff)
+		}
+	}
+	if _, err := maxFileSize(cfg); err != nil {
+		return err
+	}
+	for _, globs := range [][]string{cfg.GeneratedPaths, cfg.ForbiddenPaths, cfg.SecretAllowPaths} {
+		for _, g := range globs {
+			if _, err := path.Match(g, g); err != nil {
+				return fmt.Errorf("guardrails: bad path %q: %w", g, err)
+			}
+		}
+	}
+	return nil
+}
+
+func isRule(rule string) bool {
+	for _, r := range Rules {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// Action returns what tripping rule does under cfg.
+func Action(cfg *config.GuardrailsConfig, rule string) string {
+	if cfg != nil {
+		if a, ok := cfg.Actions[rule]; ok {
+			return a
+		}
+	}
+	return ActionReject
+}
+
+func maxFileSize(cfg *config.GuardrailsConfig) (int64, error) {
+	if cfg.MaxFileSize == "" {
+		return DefaultMaxFileSize, nil
+	}
+	n, err := diskusage.ParseSize(cfg.MaxFileSize)
+	if err != nil || n == 0 {
+		return 0, fmt.Errorf("guardrails.max_file_size: invalid size %q (want e.g. 5MB)", cfg.MaxFileSize)
+	}
+	return n, nil
+}
+
+// Check returns the guardrails files trip, most severe action first. A nil
+// config checks nothing.
+func Check(cfg *config.GuardrailsConfig, files []File) ([]Violation, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	if err := Validate(cfg); err != nil {
+		return nil, err
+	}
+	limit, _ := maxFileSize(cfg)
+	forbidden := cfg.ForbiddenPaths
+	if len(forbidden) == 0 {
+		forbidden = DefaultForbiddenPaths
+	}
+
+	var violations []Violation
+	add := func(rule, path string, line int, msg string) {
+		if a := Action(cfg, rule); a != ActionOff {
+			violations = append(violations, Violation{Rule: rule, Action: a, Path: path, Line: line, Message: msg})
+		}
+	}
+	for _, f := range files {
+		// Files that were already over the limit aren't the MR's doing.
+		if f.Size > limit && f.BaseSize <= limit {
+			add(RuleLargeFile, f.Path, 0, fmt.Sprintf("%s exceeds the %s limit", diskusage.FormatSize(f.Size), diskusage.FormatSize(limit)))
+		}
+		if f.Status == "A" || f.Status == "R" || f.Status == "C" {
+			if matchAny(forbidden, f.Path) && !isTemplate(f.Path) {
+				add(RuleForbidden, f.Path, 0, "this file must not be committed")
+			}
+			if why := generatedReason(f); why != "" && !matchAny(cfg.GeneratedPaths, f.Path) {
+				add(RuleGenerated, f.Path, 0, why+" outside the rig's generated_paths")
+			}
+		}
+		if !f.Binary && !matchAny(cfg.SecretAllowPaths, f.Path) {
+			for _, l := range f.Added {
+				if kind := DetectSecret(l.Text); kind != "" {
+					add(RuleSecret, f.Path, l.Num, "possible "+kind)
+				}
+			}
+		}
+	}
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].Action == ActionReject && violations[j].Action != ActionReject
+	})
+	return violations, nil
+}
+
+// Rejected reports whether any violation rejects the MR.
+func Rejected(violations []Violation) bool {
+	for _, v := range violations {
+		if v.Action == ActionReject {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAny(globs []string, file string) bool {
+	for _, g := range globs {
+		if testsuite.Match(g, file) {
+			return true
+		}
+	}
+	return false
+}
+
+func isTemplate(file string) bool {
+	for _, s := range templateSuffixes {
+		if strings.HasSuffix(file, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// generatedReason says why a new file looks generated or vendored, or ""
+// if it doesn't.
+func generatedReason(f File) string {
+	segments := strings.Split(f.Path, "/")
+	for _, seg := range segments[:len(segments)-1] {
+		for _, d := range vendorDirs {
+			if seg == d {
+				return "vendored code (" + d + "/)"
+			}
+		}
+	}
+	base := segments[len(segments)-1]
+	if strings.HasSuffix(base, ".min.js") || strings.HasSuffix(base, ".min.css") {
+		return "minified code"
+	}
+	for i, l := range f.Added {
+		if i == markerLines {
+			break
+		}
+		for _, re := range generatedMarkers {
+			if re.MatchString(strings.TrimSpace(l.Text)) {
+				return "generated code"
+			}
+		}
+	}
+	return ""
+}
+
+// secretPatterns are credentials with a recognizable shape.
+var secretPatterns = []struct {
+	kind string
+	re   *regexp.Regexp
+}{
+	{"private key", regexp.MustCompile(`-----BEGIN ([A-Z]+ )?PRIVATE KEY( BLOCK)?-----`)},
+	{"AWS access key", regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"GitHub token", regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{40,})\b`)},
+	{"GitLab token", regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}\b`)},
+	{"Slack token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}\b`)},
+	{"Stripe key", regexp.MustCompile(`\b[rs]k_live_[A-Za-z0-9]{20,}\b`)},
+	{"Google API key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{"API key", regexp.MustCompile(`\bsk-(ant-|proj-)?[A-Za-z0-9_-]{32,}\b`)},
+}
+
+// assignmentRe finds values assigned to secret-sounding names, like
+// password = "..." or API_TOKEN: '...'.
+var assignmentRe = regexp.MustCompile(`(?i)(secret|token|passw(or)?d|api[_-]?key|access[_-]?key|credential|auth)[a-z0-9_-]*["']?\s*[:=]+\s*["']([^"'\s]{12,})["']`)
+
+// minSecretEntropy is the Shannon entropy, in bits per character, above
+// which an assigned value looks random enough to be a real credential
+// rather than a placeholder like "changeme-changeme".
+const minSecretEntropy = 3.5
+
+// DetectSecret returns what kind of secret a line seems to contain, or ""
+// if none.
+func DetectSecret(line string) string {
+	for _, p := range secretPatterns {
+		if p.re.MatchString(line) {
+			return p.kind
+		}
+	}
+	for _, m := range assignmentRe.FindAllStringSubmatch(line, -1) {
+		value := m[3]
+		if looksLikePlaceholder(value) {
+			continue
+		}
+		if entropy(value) >= minSecretEntropy {
+			return "hardcoded " + strings.ToLower(m[1])
+		}
+	}
+	return ""
+}
+
+func looksLikePlaceholder(v string) bool {
+	lower := strings.ToLower(v)
+	for _, p := range []string{"example", "placeholder", "changeme", "dummy", "xxxx", "${", "{{", "<"} {
+		if strings.Contains(lower, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// entropy returns the Shannon entropy of s in bits per character.
+func entropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	n := 0
+	for _, r := range s {
+		counts[r]++
+		n++
+	}
+	var h float64
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+// ParseDiff extracts the lines each file gains from a unified diff, and
+// which files are binary.
+func ParseDiff(diff string) map[string]*File {
+	files := make(map[string]*File)
+	var cur *File
+	next := 0
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			cur = nil
+			// "diff --git a/x b/x": the b/ path is the file on the branch.
+			if i := strings.LastIndex(line, " b/"); i >= 0 {
+				p := line[i+3:]
+				cur = &File{Path: p}
+				files[p] = cur
+			}
+		case cur == nil:
+		case strings.HasPrefix(line, "Binary files "):
+			cur.Binary = true
+		case strings.HasPrefix(line, "+++ "), strings.HasPrefix(line, "--- "):
+		case strings.HasPrefix(line, "@@ "):
+			// "@@ -a,b +c,d @@": added lines are numbered from c.
+			if _, after, ok := strings.Cut(line, " +"); ok {
+				num, _, _ := strings.Cut(after, " ")
+				num, _, _ = strings.Cut(num, ",")
+				_, _ = fmt.Sscanf(num, "%d", &next)
+			}
+		case strings.HasPrefix(line, "+"):
+			cur.Added = append(cur.Added, Line{Num: next, Text: line[1:]})
+			next++
+		case strings.HasPrefix(line, " "):
+			next++
+		}
+	}
+	return files
+}
+
+// Format renders violations as one failure message, one per line.
+func Format(violations []Violation) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d guardrail violation(s):", len(violations))
+	for _, v := range violations {
+		sb.WriteString("\n  - ")
+		if v.Action == ActionWarn {
+			sb.WriteString("(warning) ")
+		}
+		sb.WriteString(v.String())
+	}
+	return sb.String()
+}
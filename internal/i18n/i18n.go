@@ -0,0 +1,188 @@
+// Package i18n translates gt's user-facing messages.
+//
+// Messages are keyed by their English text, gettext style: code calls
+// T("No ready work across town.") and gets the English string back when
+// the active locale has no translation. Catalogs live in locales/<lang>.json
+// as {"English text": "translation"} and are embedded in the binary.
+//
+// Error messages are translated phrase by phrase (see Error), so common
+// fragments like "not in a Gas Town workspace" are translated wherever
+// they appear in a wrapped error chain.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// EnvLang selects the locale for gt's messages, overriding config and the
+// system locale (LC_ALL, LC_MESSAGES, LANG).
+const EnvLang = "GT_LANG"
+
+// DefaultLocale is the source language.
+const DefaultLocale = "en"
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+var (
+	mu      sync.RWMutex
+	locale  = DefaultLocale
+	catalog map[string]string
+	phrases []string // Catalog keys, longest first, for Error
+)
+
+// Init selects the locale. configLocale is TownSettings.CLILocale (may be
+// empty); GT_LANG wins over it, and it wins over the system locale.
+func Init(configLocale string) {
+	SetLocale(resolveLocale(configLocale))
+}
+
+// SetLocale switches to the given locale ("de", "es_ES.UTF-8", ...). Unknown
+// locales fall back to English.
+func SetLocale(tag string) {
+	lang := normalize(tag)
+	cat, err := loadCatalog(lang)
+	if err != nil {
+		lang, cat = DefaultLocale, nil
+	}
+	keys := make([]string, 0, len(cat))
+	for k := range cat {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return len(keys[i]) > len(keys[j]) })
+
+	mu.Lock()
+	defer mu.Unlock()
+	locale, catalog, phrases = lang, cat, keys
+}
+
+// Locale returns the active locale's language code.
+func Locale() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return locale
+}
+
+// Available lists the locales with a catalog, plus English.
+func Available() []string {
+	langs := []string{DefaultLocale}
+	entries, _ := localeFS.ReadDir("locales")
+	for _, e := range entries {
+		langs = append(langs, strings.TrimSuffix(e.Name(), ".json"))
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// T translates msg.
+func T(msg string) string {
+	mu.RLock()
+	defer mu.RUnlock()
+	if t, ok := catalog[msg]; ok && t != "" {
+		return t
+	}
+	return msg
+}
+
+// Sprintf translates format, then formats it.
+func Sprintf(format string, args ...interface{}) string {
+	return fmt.Sprintf(T(format), args...)
+}
+
+// Plural translates and formats one or other depending on n. Both are
+// format strings that take n as their first argument.
+func Plural(n int, one, other string, args ...interface{}) string {
+	format := other
+	if n == 1 {
+		format = one
+	}
+	return Sprintf(format, append([]interface{}{n}, args...)...)
+}
+
+// Error translates the known phrases in err's message. The result still
+// unwraps to err, so errors.Is and errors.As keep working.
+func Error(err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := err.Error()
+	translated := translatePhrases(msg)
+	if translated == msg {
+		return err
+	}
+	return &translatedError{msg: translated, err: err}
+}
+
+type translatedError struct {
+	msg string
+	err error
+}
+
+func (e *translatedError) Error() string { return e.msg }
+func (e *translatedError) Unwrap() error { return e.err }
+
+// translatePhrases replaces catalog keys found in msg, longest first so
+// that a longer phrase wins over a phrase it contains.
+func translatePhrases(msg string) string {
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, k := range phrases {
+		if strings.Contains(k, "%") || !strings.Contains(msg, k) {
+			continue
+		}
+		msg = strings.ReplaceAll(msg, k, catalog[k])
+	}
+	return msg
+}
+
+func loadCatalog(lang string) (map[string]string, error) {
+	if lang == DefaultLocale {
+		return nil, nil
+	}
+	data, err := localeFS.ReadFile(path.Join("locales", lang+".json"))
+	if err != nil {
+		return nil, err
+	}
+	var cat map[string]string
+	if err := json.Unmarshal(data, &cat); err != nil {
+		return nil, fmt.Errorf("locale %s: %w", lang, err)
+	}
+	return cat, nil
+}
+
+// resolveLocale picks the locale: GT_LANG, then config, then the POSIX
+// locale variables.
+func resolveLocale(configLocale string) string {
+	if v := os.Getenv(EnvLang); v != "" {
+		return v
+	}
+	if configLocale != "" {
+		return configLocale
+	}
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(env); v != "" {
+			return v
+		}
+	}
+	return DefaultLocale
+}
+
+// normalize reduces a locale tag like "de_DE.UTF-8" or "pt-BR" to its
+// language code. "C" and "POSIX" mean English.
+func normalize(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "_-.@"); i >= 0 {
+		tag = tag[:i]
+	}
+	if tag == "" || tag == "c" || tag == "posix" {
+		return DefaultLocale
+	}
+	return tag
+}
@@ -0,0 +1,121 @@
+package i18n
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"de_DE.UTF-8": "de",
+		"es-ES":       "es",
+		"pt_BR@euro":  "pt",
+		"C":           "en",
+		"POSIX":       "en",
+		"":            "en",
+		"FR":          "fr",
+	}
+	for in, want := range tests {
+		if got := normalize(in); got != want {
+			t.Errorf("normalize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestResolveLocale(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "es_ES.UTF-8")
+	t.Setenv(EnvLang, "")
+	if got := resolveLocale(""); got != "es_ES.UTF-8" {
+		t.Errorf("system locale: got %q", got)
+	}
+	if got := resolveLocale("de"); got != "de" {
+		t.Errorf("config wins over system: got %q", got)
+	}
+	t.Setenv(EnvLang, "en")
+	if got := resolveLocale("de"); got != "en" {
+		t.Errorf("GT_LANG wins over config: got %q", got)
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	defer SetLocale(DefaultLocale)
+
+	SetLocale("de_DE.UTF-8")
+	if Locale() != "de" {
+		t.Fatalf("Locale() = %q, want de", Locale())
+	}
+	if got := T("(none)"); got != "(keine)" {
+		t.Errorf("T = %q", got)
+	}
+	if got := T("Some untranslated message"); got != "Some untranslated message" {
+		t.Errorf("untranslated fallback = %q", got)
+	}
+	if got := Plural(1, "(%d item)", "(%d items)"); got != "(1 Eintrag)" {
+		t.Errorf("Plural(1) = %q", got)
+	}
+	if got := Plural(3, "(%d item)", "(%d items)"); got != "(3 Einträge)" {
+		t.Errorf("Plural(3) = %q", got)
+	}
+
+	SetLocale("xx")
+	if Locale() != DefaultLocale || T("(none)") != "(none)" {
+		t.Errorf("unknown locale should fall back to English, got %q", Locale())
+	}
+}
+
+func TestError(t *testing.T) {
+	defer SetLocale(DefaultLocale)
+	base := errors.New("not in a Gas Town workspace")
+	err := fmt.Errorf("loading: %w", base)
+
+	if got := Error(err); got != err {
+		t.Errorf("English Error should return err unchanged, got %v", got)
+	}
+
+	SetLocale("de")
+	got := Error(err)
+	if got.Error() != "loading: nicht in einem Gas-Town-Arbeitsbereich" {
+		t.Errorf("Error = %q", got)
+	}
+	if !errors.Is(got, base) {
+		t.Error("translated error should still unwrap to the original")
+	}
+	if Error(nil) != nil {
+		t.Error("Error(nil) should be nil")
+	}
+}
+
+var verbRe = regexp.MustCompile(`%[-+# 0]*[0-9]*(?:\.[0-9]+)?[a-zA-Z%]`)
+
+// TestCatalogs checks every catalog parses and keeps each message's format
+// verbs, in order, so translated Sprintf calls can't misformat.
+func TestCatalogs(t *testing.T) {
+	for _, lang := range Available() {
+		if lang == DefaultLocale {
+			continue
+		}
+		data, err := localeFS.ReadFile("locales/" + lang + ".json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var cat map[string]string
+		if err := json.Unmarshal(data, &cat); err != nil {
+			t.Fatalf("%s: %v", lang, err)
+		}
+		for k, v := range cat {
+			if strings.TrimSpace(v) == "" {
+				t.Errorf("%s: %q has an empty translation", lang, k)
+			}
+			want := strings.Join(verbRe.FindAllString(k, -1), " ")
+			if got := strings.Join(verbRe.FindAllString(v, -1), " "); got != want {
+				t.Errorf("%s: %q has verbs %q, translation %q has %q", lang, k, want, v, got)
+			}
+		}
+	}
+}
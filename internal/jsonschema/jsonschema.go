@@ -0,0 +1,302 @@
+// Package jsonschema generates JSON Schemas (draft 2020-12) from Go types
+// and validates JSON documents against them. It covers what encoding/json
+// produces: structs with json tags, maps, slices, pointers, and time.Time.
+// It is not a general-purpose validator; it checks the keywords Generate
+// emits.
+package jsonschema
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Draft is the JSON Schema dialect generated schemas declare.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema.
+type Schema struct {
+	Draft                string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 any                `json:"type,omitempty"` // a type name, or a list of them
+	Format               string             `json:"format,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties any                `json:"additionalProperties,omitempty"` // *Schema or false
+	Items                *Schema            `json:"items,omitempty"`
+}
+
+// Types returns the JSON types the schema allows; nil allows any.
+func (s *Schema) Types() []string {
+	switch t := s.Type.(type) {
+	case string:
+		return []string{t}
+	case []string:
+		return t
+	case []any:
+		types := make([]string, 0, len(t))
+		for _, v := range t {
+			if name, ok := v.(string); ok {
+				types = append(types, name)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	durationType      = reflect.TypeOf(time.Duration(0))
+	rawMessageType    = reflect.TypeOf(json.RawMessage(nil))
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Generate returns the schema of v's JSON encoding. Struct objects are
+// closed (additionalProperties: false) so a renamed or added field shows
+// up as a schema change.
+func Generate(v any, title string) *Schema {
+	g := &generator{seen: make(map[reflect.Type]bool)}
+	s := g.schema(reflect.TypeOf(v))
+	s.Draft = Draft
+	s.Title = title
+	return s
+}
+
+type generator struct {
+	seen map[reflect.Type]bool // struct types being generated, to stop on recursion
+}
+
+func nullable(s *Schema) *Schema {
+	if t, ok := s.Type.(string); ok {
+		s.Type = []string{t, "null"}
+	}
+	return s
+}
+
+func (g *generator) schema(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == durationType:
+		return &Schema{Type: "integer"}
+	case t == rawMessageType:
+		return &Schema{}
+	case t.Kind() != reflect.Pointer && (t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType)):
+		return &Schema{}
+	case t.Kind() != reflect.Pointer && (t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType)):
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Pointer:
+		return nullable(g.schema(t.Elem()))
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: []string{"string", "null"}} // base64
+		}
+		return nullable(&Schema{Type: "array", Items: g.schema(t.Elem())})
+	case reflect.Array:
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return nullable(&Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())})
+	case reflect.Struct:
+		return g.structSchema(t)
+	}
+	// Interfaces and anything else: any JSON value.
+	return &Schema{}
+}
+
+func (g *generator) structSchema(t reflect.Type) *Schema {
+	if g.seen[t] {
+		// Recursive type: accept any object rather than recurse forever.
+		return &Schema{Type: "object"}
+	}
+	g.seen[t] = true
+	defer delete(g.seen, t)
+
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema), AdditionalProperties: false}
+	g.addFields(s, t, true)
+	sort.Strings(s.Required)
+	return s
+}
+
+// addFields adds a struct's fields to s, flattening embedded structs as
+// encoding/json does. Fields of an embedded pointer are never required.
+func (g *generator) addFields(s *Schema, t reflect.Type, required bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		ft := f.Type
+		if f.Anonymous && name == "" {
+			embedded := ft
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.addFields(s, embedded, required && ft.Kind() != reflect.Pointer)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		prop := g.schema(ft)
+		if hasOpt(opts, "string") {
+			prop = &Schema{Type: "string"}
+		}
+		s.Properties[name] = prop
+		if required && !hasOpt(opts, "omitempty") && !hasOpt(opts, "omitzero") {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+func hasOpt(opts, want string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if o == want {
+			return true
+		}
+	}
+	return false
+}
+
+// Open relaxes every object in the schema to allow properties it doesn't
+// list, for documents like config files that other versions also write.
+func (s *Schema) Open() *Schema {
+	if s == nil {
+		return s
+	}
+	if s.AdditionalProperties == false {
+		s.AdditionalProperties = nil
+	}
+	if sub, ok := s.AdditionalProperties.(*Schema); ok {
+		sub.Open()
+	}
+	for _, prop := range s.Properties {
+		prop.Open()
+	}
+	s.Items.Open()
+	return s
+}
+
+// Validate checks a JSON document against a schema and returns the first
+// violation, located by a JSON path like "$.sources[0].issues".
+func Validate(s *Schema, data []byte) error {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	return validate(s, v, "$")
+}
+
+func validate(s *Schema, v any, path string) error {
+	if s == nil {
+		return nil
+	}
+	if types := s.Types(); types != nil {
+		got := jsonType(v)
+		ok := false
+		for _, t := range types {
+			if t == got || (t == "number" && got == "integer") {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return fmt.Errorf("%s: got %s, want %s", path, got, strings.Join(types, " or "))
+		}
+	}
+	switch v := v.(type) {
+	case string:
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, v); err != nil {
+				return fmt.Errorf("%s: %q is not a date-time", path, v)
+			}
+		}
+	case []any:
+		for i, item := range v {
+			if err := validate(s.Items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				return fmt.Errorf("%s: missing required property %q", path, name)
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			sub := path + "." + k
+			if prop, ok := s.Properties[k]; ok {
+				if err := validate(prop, v[k], sub); err != nil {
+					return err
+				}
+				continue
+			}
+			switch extra := s.AdditionalProperties.(type) {
+			case bool:
+				if !extra {
+					return fmt.Errorf("%s: unexpected property", sub)
+				}
+			case *Schema:
+				if err := validate(extra, v[k], sub); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func jsonType(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return "unknown"
+}
@@ -0,0 +1,147 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+type Base struct {
+	ID string `json:"id"`
+}
+
+type Meta struct {
+	Owner string `json:"owner"`
+}
+
+type node struct {
+	Name     string  `json:"name"`
+	Children []*node `json:"children,omitempty"`
+}
+
+type sample struct {
+	Base
+	*Meta
+	Count    int             `json:"count"`
+	Ratio    float64         `json:"ratio,omitempty"`
+	Done     bool            `json:"done"`
+	When     time.Time       `json:"when"`
+	Took     time.Duration   `json:"took"`
+	Tags     []string        `json:"tags"`
+	Extra    map[string]int  `json:"extra,omitempty"`
+	Next     *sample         `json:"next,omitempty"`
+	Any      any             `json:"any,omitempty"`
+	Raw      json.RawMessage `json:"raw,omitempty"`
+	Tree     node            `json:"tree"`
+	Quoted   int             `json:"quoted,string"`
+	Skip     string          `json:"-"`
+	unexport string          //nolint:unused
+	Untagged string
+	Labels   map[string]string `json:"labels"`
+}
+
+func TestGenerate(t *testing.T) {
+	s := Generate(sample{}, "sample")
+	if s.Draft != Draft || s.Title != "sample" || s.AdditionalProperties != false {
+		t.Errorf("root = %+v", s)
+	}
+	wantRequired := "Untagged count done id labels quoted tags took tree when"
+	if got := strings.Join(s.Required, " "); got != wantRequired {
+		t.Errorf("required = %s\nwant       %s", got, wantRequired)
+	}
+	checks := map[string]string{
+		"id":     `{"type":"string"}`,
+		"owner":  `{"type":"string"}`,
+		"count":  `{"type":"integer"}`,
+		"ratio":  `{"type":"number"}`,
+		"when":   `{"type":"string","format":"date-time"}`,
+		"took":   `{"type":"integer"}`,
+		"tags":   `{"type":["array","null"],"items":{"type":"string"}}`,
+		"extra":  `{"type":["object","null"],"additionalProperties":{"type":"integer"}}`,
+		"any":    `{}`,
+		"raw":    `{}`,
+		"quoted": `{"type":"string"}`,
+	}
+	for name, want := range checks {
+		got, _ := json.Marshal(s.Properties[name])
+		if string(got) != want {
+			t.Errorf("%s = %s, want %s", name, got, want)
+		}
+	}
+	for _, name := range []string{"Skip", "unexport", "-"} {
+		if _, ok := s.Properties[name]; ok {
+			t.Errorf("property %q should be skipped", name)
+		}
+	}
+	// Recursion stops at the repeated type.
+	if next := s.Properties["next"]; next.Properties != nil || strings.Join(next.Types(), ",") != "object,null" {
+		t.Errorf("recursive field not cut off: %+v", next)
+	}
+	if tree := s.Properties["tree"]; tree.Properties["children"].Items.Properties != nil {
+		t.Errorf("recursive children not cut off: %+v", tree.Properties["children"].Items)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	s := Generate(sample{}, "sample")
+	good, err := json.Marshal(sample{Base: Base{ID: "gt-1"}, Meta: &Meta{Owner: "mayor/"}, Tags: []string{"a"},
+		When: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), Next: &sample{}, Tree: node{Name: "root", Children: []*node{{Name: "leaf"}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Validate(s, good); err != nil {
+		t.Fatalf("valid document rejected: %v", err)
+	}
+	zero, _ := json.Marshal(sample{})
+	if err := Validate(s, zero); err != nil {
+		t.Fatalf("zero value rejected: %v", err)
+	}
+
+	tests := []struct {
+		name, mutate, wantErr string
+	}{
+		{"wrong type", `"count": "3"`, "$.count: got string, want integer"},
+		{"fraction for integer", `"count": 1.5`, "$.count: got number"},
+		{"bad date", `"when": "yesterday"`, "$.when"},
+		{"unexpected property", `"colour": "red"`, "$.colour: unexpected property"},
+		{"bad item", `"tags": [1]`, "$.tags[0]"},
+		{"bad map value", `"extra": {"a": "x"}`, "$.extra.a"},
+	}
+	for _, tt := range tests {
+		var doc map[string]any
+		_ = json.Unmarshal(zero, &doc)
+		var patch map[string]any
+		if err := json.Unmarshal([]byte("{"+tt.mutate+"}"), &patch); err != nil {
+			t.Fatal(err)
+		}
+		for k, v := range patch {
+			doc[k] = v
+		}
+		data, _ := json.Marshal(doc)
+		err := Validate(s, data)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: err = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+
+	var doc map[string]any
+	_ = json.Unmarshal(zero, &doc)
+	delete(doc, "count")
+	data, _ := json.Marshal(doc)
+	if err := Validate(s, data); err == nil || !strings.Contains(err.Error(), `missing required property "count"`) {
+		t.Errorf("missing property: err = %v", err)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	s := Generate(sample{}, "sample").Open()
+	doc := `{"count": 1, "colour": "red", "tree": {"name": "x", "leaves": 3}}`
+	s.Required = nil
+	if err := Validate(s, []byte(doc)); err != nil {
+		t.Errorf("open schema rejected unknown properties: %v", err)
+	}
+	if err := Validate(s, []byte(`{"count": "one"}`)); err == nil {
+		t.Error("open schema should still check known properties")
+	}
+}
@@ -0,0 +1,395 @@
+// Package memo provides the town's decision log: short Markdown memos that
+// record architectural decisions and other knowledge one agent needs to
+// pass to the next.
+//
+// Memos live in <town>/memos/<id>.md with TOML front matter between "+++"
+// lines, so they are readable and diffable as plain files:
+//
+//	+++
+//	id = "memo-20261015-use-refresh-tokens"
+//	title = "Use refresh tokens for agent auth"
+//	topics = ["auth", "security"]
+//	rig = "gastown"
+//	author = "gastown/crew/max"
+//	issues = ["gt-abc"]
+//	created = 2026-10-15T10:00:00Z
+//	+++
+//
+//	Body in Markdown.
+//
+// Memos are indexed by topic and rig; a memo without a rig applies town-wide.
+package memo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/steveyegge/gastown/internal/paging"
+)
+
+// frontMatterDelim separates a memo's TOML front matter from its body.
+const frontMatterDelim = "+++"
+
+// ErrNotFound is returned when no memo matches an ID.
+var ErrNotFound = errors.New("memo not found")
+
+// Memo is one recorded decision.
+type Memo struct {
+	ID      string    `toml:"id" json:"id"`
+	Title   string    `toml:"title" json:"title"`
+	Topics  []string  `toml:"topics,omitempty" json:"topics,omitempty"`
+	Rig     string    `toml:"rig,omitempty" json:"rig,omitempty"`
+	Author  string    `toml:"author,omitempty" json:"author,omitempty"`
+	Issues  []string  `toml:"issues,omitempty" json:"issues,omitempty"`
+	Created time.Time `toml:"created" json:"created"`
+	Body    string    `toml:"-" json:"body"`
+}
+
+// Filter narrows a listing. Empty fields match everything.
+type Filter struct {
+	Topic string
+	Rig   string // also matches town-wide memos
+	Issue string
+}
+
+// Match is a search hit.
+type Match struct {
+	Memo    *Memo  `json:"memo"`
+	Score   int    `json:"score"`
+	Snippet string `json:"snippet,omitempty"`
+}
+
+// Dir returns the town's memo directory.
+func Dir(townRoot string) string {
+	return filepath.Join(townRoot, "memos")
+}
+
+// Store reads and writes the memos in one directory.
+type Store struct {
+	dir string
+}
+
+// NewStore returns the store for townRoot.
+func NewStore(townRoot string) *Store {
+	return &Store{dir: Dir(townRoot)}
+}
+
+var slugRe = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slug turns a title into an ID-safe fragment.
+func slug(title string) string {
+	s := strings.Trim(slugRe.ReplaceAllString(strings.ToLower(title), "-"), "-")
+	if len(s) > 40 {
+		s = strings.TrimRight(s[:40], "-")
+	}
+	if s == "" {
+		s = "memo"
+	}
+	return s
+}
+
+// NormalizeTopics lowercases, trims, and dedupes topics, keeping their order.
+func NormalizeTopics(topics []string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, t := range topics {
+		for _, part := range strings.Split(t, ",") {
+			part = strings.ToLower(strings.TrimSpace(part))
+			if part != "" && !seen[part] {
+				seen[part] = true
+				out = append(out, part)
+			}
+		}
+	}
+	return out
+}
+
+// Add assigns m an ID (if it has none) and a creation time, and writes it.
+func (s *Store) Add(m *Memo, now time.Time) error {
+	if strings.TrimSpace(m.Title) == "" {
+		return fmt.Errorf("memo needs a title")
+	}
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("creating memo directory: %w", err)
+	}
+	m.Topics = NormalizeTopics(m.Topics)
+	if m.Created.IsZero() {
+		m.Created = now.UTC().Truncate(time.Second)
+	}
+	if m.ID == "" {
+		base := "memo-" + m.Created.Format("20060102") + "-" + slug(m.Title)
+		m.ID = base
+		for i := 2; ; i++ {
+			if _, err := os.Stat(s.path(m.ID)); os.IsNotExist(err) {
+				break
+			}
+			m.ID = fmt.Sprintf("%s-%d", base, i)
+		}
+	}
+
+	data, err := Format(m)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path(m.ID), data, 0644) //nolint:gosec // G306: memos are shared, non-secret documents
+}
+
+func (s *Store) path(id string) string {
+	return filepath.Join(s.dir, id+".md")
+}
+
+// Get returns the memo with id, or the only memo whose ID starts with id.
+func (s *Store) Get(id string) (*Memo, error) {
+	if m, err := readMemo(s.path(id)); err == nil {
+		return m, nil
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	all, err := s.List(Filter{})
+	if err != nil {
+		return nil, err
+	}
+	var found *Memo
+	for _, m := range all {
+		if strings.HasPrefix(m.ID, id) {
+			if found != nil {
+				return nil, fmt.Errorf("%q matches more than one memo", id)
+			}
+			found = m
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return found, nil
+}
+
+// List returns the memos matching f, newest first.
+func (s *Store) List(f Filter) ([]*Memo, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var memos []*Memo
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".md") {
+			continue
+		}
+		m, err := readMemo(filepath.Join(s.dir, e.Name()))
+		if err != nil {
+			continue // Skip hand-edited files that no longer parse
+		}
+		if f.matches(m) {
+			memos = append(memos, m)
+		}
+	}
+	sort.SliceStable(memos, func(i, j int) bool {
+		if !memos[i].Created.Equal(memos[j].Created) {
+			return memos[i].Created.After(memos[j].Created)
+		}
+		return memos[i].ID < memos[j].ID
+	})
+	return memos, nil
+}
+
+func (f Filter) matches(m *Memo) bool {
+	if f.Topic != "" && !contains(m.Topics, strings.ToLower(f.Topic)) {
+		return false
+	}
+	if f.Rig != "" && m.Rig != "" && m.Rig != f.Rig {
+		return false
+	}
+	if f.Issue != "" && !contains(m.Issues, f.Issue) {
+		return false
+	}
+	return true
+}
+
+// Search returns memos matching every term of query, best first. Title
+// hits weigh most, then topics, then the body.
+func (s *Store) Search(query string, f Filter) ([]Match, error) {
+	matches, _, err := s.SearchPage(query, f, paging.Page{})
+	return matches, err
+}
+
+// SearchPage is Search returning one page of the ranked matches. Ranking
+// needs every memo scored, so the page is cut after scoring; the snippets,
+// the costly part, are only built for the page.
+func (s *Store) SearchPage(query string, f Filter, page paging.Page) ([]Match, paging.Info, error) {
+	terms := strings.Fields(strings.ToLower(query))
+	memos, err := s.List(f)
+	if err != nil {
+		return nil, paging.Info{}, err
+	}
+	var matches []Match
+	for _, m := range memos {
+		title, body := strings.ToLower(m.Title), strings.ToLower(m.Body)
+		topics := strings.Join(m.Topics, " ")
+		score := 0
+		for _, term := range terms {
+			hit := 3*strings.Count(title, term) + 2*strings.Count(topics, term) + strings.Count(body, term)
+			if hit == 0 {
+				score = 0
+				break
+			}
+			score += hit
+		}
+		if score == 0 && len(terms) > 0 {
+			continue
+		}
+		matches = append(matches, Match{Memo: m, Score: score})
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	matches, info := paging.Apply(matches, page)
+	for i := range matches {
+		matches[i].Snippet = snippet(matches[i].Memo.Body, terms)
+	}
+	return matches, info, nil
+}
+
+// Query describes an issue for Relevant.
+type Query struct {
+	Issue  string
+	Rig    string
+	Labels []string
+	Text   string // title and description
+}
+
+// Relevant returns up to limit memos relevant to an issue: memos linked to
+// it first, then memos whose topics appear in its labels or text. Memos for
+// other rigs are skipped.
+func (s *Store) Relevant(q Query, limit int) ([]*Memo, error) {
+	memos, err := s.List(Filter{Rig: q.Rig})
+	if err != nil {
+		return nil, err
+	}
+	labels := make(map[string]bool, len(q.Labels))
+	for _, l := range q.Labels {
+		labels[strings.ToLower(l)] = true
+	}
+	words := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(strings.ToLower(q.Text), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_')
+	}) {
+		words[w] = true
+	}
+
+	type scored struct {
+		memo  *Memo
+		score int
+	}
+	var hits []scored
+	for _, m := range memos {
+		score := 0
+		if q.Issue != "" && contains(m.Issues, q.Issue) {
+			score += 10
+		}
+		for _, t := range m.Topics {
+			if labels[t] {
+				score += 5
+			} else if words[t] {
+				score += 2
+			}
+		}
+		if score > 0 {
+			hits = append(hits, scored{m, score})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].score > hits[j].score })
+
+	var out []*Memo
+	for _, h := range hits {
+		if limit > 0 && len(out) >= limit {
+			break
+		}
+		out = append(out, h.memo)
+	}
+	return out, nil
+}
+
+// Format renders a memo as a file: TOML front matter, then the body.
+func Format(m *Memo) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(frontMatterDelim + "\n")
+	if err := toml.NewEncoder(&buf).Encode(m); err != nil {
+		return nil, fmt.Errorf("encoding memo: %w", err)
+	}
+	buf.WriteString(frontMatterDelim + "\n\n")
+	buf.WriteString(strings.TrimSpace(m.Body))
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+// Parse reads a memo file's contents.
+func Parse(data []byte) (*Memo, error) {
+	text := strings.TrimLeft(string(data), "\ufeff \t\r\n")
+	if !strings.HasPrefix(text, frontMatterDelim) {
+		return nil, fmt.Errorf("missing %s front matter", frontMatterDelim)
+	}
+	rest := strings.TrimPrefix(text, frontMatterDelim)
+	end := strings.Index(rest, "\n"+frontMatterDelim)
+	if end < 0 {
+		return nil, fmt.Errorf("unterminated front matter")
+	}
+	m := &Memo{}
+	if _, err := toml.Decode(rest[:end], m); err != nil {
+		return nil, fmt.Errorf("parsing front matter: %w", err)
+	}
+	m.Body = strings.TrimSpace(rest[end+len("\n"+frontMatterDelim):])
+	return m, nil
+}
+
+func readMemo(path string) (*Memo, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is within the memo directory
+	if err != nil {
+		return nil, err
+	}
+	m, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if m.ID == "" {
+		m.ID = strings.TrimSuffix(filepath.Base(path), ".md")
+	}
+	return m, nil
+}
+
+// snippet returns the body line with the first search term, or the first line.
+func snippet(body string, terms []string) string {
+	lines := strings.Split(body, "\n")
+	for _, line := range lines {
+		lower := strings.ToLower(line)
+		for _, t := range terms {
+			if strings.Contains(lower, t) {
+				return strings.TrimSpace(line)
+			}
+		}
+	}
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			return strings.TrimSpace(line)
+		}
+	}
+	return ""
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
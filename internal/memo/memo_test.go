@@ -0,0 +1,132 @@
+package memo
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/paging"
+)
+
+func TestAddGetRoundTrip(t *testing.T) {
+	s := NewStore(t.TempDir())
+	now := time.Date(2026, 10, 15, 10, 0, 0, 0, time.UTC)
+
+	m := &Memo{
+		Title:  "Use refresh tokens for agent auth!",
+		Topics: []string{"Auth", "security, auth"},
+		Rig:    "gastown",
+		Author: "gastown/crew/max",
+		Issues: []string{"gt-abc"},
+		Body:   "\nLong-lived tokens leaked twice.\n\nRotate hourly.\n",
+	}
+	if err := s.Add(m, now); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if m.ID != "memo-20261015-use-refresh-tokens-for-agent-auth" {
+		t.Errorf("ID = %q", m.ID)
+	}
+	if strings.Join(m.Topics, ",") != "auth,security" {
+		t.Errorf("Topics = %v, want [auth security]", m.Topics)
+	}
+
+	// Same title the same day gets a suffix
+	dup := &Memo{Title: m.Title}
+	if err := s.Add(dup, now); err != nil {
+		t.Fatal(err)
+	}
+	if dup.ID != m.ID+"-2" {
+		t.Errorf("duplicate ID = %q, want %q", dup.ID, m.ID+"-2")
+	}
+
+	got, err := s.Get(m.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Title != m.Title || got.Rig != "gastown" || !got.Created.Equal(now) || got.Body != "Long-lived tokens leaked twice.\n\nRotate hourly." {
+		t.Errorf("Get = %+v", got)
+	}
+	if _, err := s.Get("memo-20261015-use"); err == nil {
+		t.Error("ambiguous prefix should fail")
+	}
+	if _, err := s.Get("memo-nope"); err == nil {
+		t.Error("unknown ID should fail")
+	}
+}
+
+func TestListSearchRelevant(t *testing.T) {
+	s := NewStore(t.TempDir())
+	base := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	memos := []*Memo{
+		{Title: "Postgres over SQLite", Topics: []string{"database"}, Rig: "gastown", Body: "We need concurrent writers."},
+		{Title: "Retry policy", Topics: []string{"network"}, Body: "Retry idempotent calls with backoff; the database client retries itself."},
+		{Title: "Beads schema", Topics: []string{"database"}, Rig: "beads", Body: "Schema migrations run at startup."},
+		{Title: "Token rotation", Topics: []string{"auth"}, Rig: "gastown", Issues: []string{"gt-42"}, Body: "Rotate hourly."},
+	}
+	for i, m := range memos {
+		if err := s.Add(m, base.Add(time.Duration(i)*time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ids := func(ms []*Memo) string {
+		var out []string
+		for _, m := range ms {
+			out = append(out, m.Title)
+		}
+		return strings.Join(out, "|")
+	}
+
+	listTests := []struct {
+		filter Filter
+		want   string
+	}{
+		{Filter{}, "Token rotation|Beads schema|Retry policy|Postgres over SQLite"},
+		{Filter{Topic: "Database"}, "Beads schema|Postgres over SQLite"},
+		{Filter{Rig: "gastown"}, "Token rotation|Retry policy|Postgres over SQLite"},
+		{Filter{Issue: "gt-42"}, "Token rotation"},
+	}
+	for _, tt := range listTests {
+		got, err := s.List(tt.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ids(got) != tt.want {
+			t.Errorf("List(%+v) = %s, want %s", tt.filter, ids(got), tt.want)
+		}
+	}
+
+	matches, err := s.Search("database", Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 3 || matches[2].Memo.Title != "Retry policy" {
+		t.Errorf("Search(database) ranked %v", matches)
+	}
+	if matches, _ := s.Search("rotate hourly", Filter{}); len(matches) != 1 || matches[0].Snippet != "Rotate hourly." {
+		t.Errorf("Search(rotate hourly) = %v", matches)
+	}
+	page, info, err := s.SearchPage("database", Filter{}, paging.Page{Limit: 1, Offset: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 1 || page[0].Memo.Title != "Retry policy" || page[0].Snippet == "" || info.More {
+		t.Errorf("SearchPage(database, 1 at 2) = %v, %+v; want the last match with a snippet", page, info)
+	}
+
+	relevant, err := s.Relevant(Query{Issue: "gt-42", Rig: "gastown", Labels: []string{"database"}, Text: "Fix auth"}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(relevant); got != "Token rotation|Postgres over SQLite" {
+		t.Errorf("Relevant = %s", got)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, in := range []string{"no front matter", "+++\ntitle = \"x\"\n", "+++\ntitle = \n+++\n"} {
+		if _, err := Parse([]byte(in)); err == nil {
+			t.Errorf("Parse(%q) should fail", in)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+// Package milestone computes progress and burndown for town-level
+// milestones: groups of issues across rigs due by a target date.
+package milestone
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Health of a milestone against its target.
+const (
+	HealthDone    = "done"     // every issue closed
+	HealthOnTrack = "on-track" // projected to finish by the target
+	HealthAtRisk  = "at-risk"  // projected to finish after the target
+	HealthLate    = "late"     // past the target with work open
+	HealthUnknown = "unknown"  // nothing closed yet to project from
+)
+
+// Issue is one issue a milestone groups.
+type Issue struct {
+	ID     string    `json:"id"`
+	Title  string    `json:"title"`
+	Status string    `json:"status"`
+	Rig    string    `json:"rig,omitempty"`
+	Closed time.Time `json:"closed,omitempty"`
+	Hours  float64   `json:"hours,omitempty"` // estimate; zero when unestimated
+}
+
+// IsClosed reports whether the issue is closed.
+func (i Issue) IsClosed() bool {
+	return i.Status == "closed"
+}
+
+// RigProgress is a milestone's progress within one rig.
+type RigProgress struct {
+	Rig    string `json:"rig"`
+	Total  int    `json:"total"`
+	Closed int    `json:"closed"`
+}
+
+// Point is one day of a burndown.
+type Point struct {
+	Date      time.Time `json:"date"`
+	Remaining *int      `json:"remaining,omitempty"` // nil for days still ahead
+	Ideal     float64   `json:"ideal"`
+}
+
+// Progress is a milestone's state as of a moment.
+type Progress struct {
+	ID             string        `json:"id"`
+	Title          string        `json:"title"`
+	Status         string        `json:"status"`
+	Start          time.Time     `json:"start"`
+	Target         time.Time     `json:"target"`
+	Total          int           `json:"total"`
+	Closed         int           `json:"closed"`
+	Hours          float64       `json:"hours,omitempty"`
+	RemainingHours float64       `json:"remaining_hours,omitempty"`
+	DaysLeft       int           `json:"days_left"`
+	Velocity       float64       `json:"velocity"` // issues closed per day since start
+	Projected      time.Time     `json:"projected,omitempty"`
+	Health         string        `json:"health"`
+	Rigs           []RigProgress `json:"rigs,omitempty"`
+	Issues         []Issue       `json:"issues,omitempty"`
+	Burndown       []Point       `json:"burndown,omitempty"`
+}
+
+// Percent is the share of issues closed, 0-100.
+func (p *Progress) Percent() int {
+	if p.Total == 0 {
+		return 0
+	}
+	return p.Closed * 100 / p.Total
+}
+
+// Build computes a milestone's progress as of now. start and target are
+// dates (midnight local time); the target day counts as a working day.
+// The projection assumes issues keep closing at the rate they have since
+// start.
+func Build(id, title, status string, start, target time.Time, issues []Issue, now time.Time) *Progress {
+	p := &Progress{ID: id, Title: title, Status: status, Start: start, Target: target, Issues: issues, Total: len(issues)}
+	rigs := make(map[string]*RigProgress)
+	for _, it := range issues {
+		rp := rigs[it.Rig]
+		if rp == nil {
+			rp = &RigProgress{Rig: it.Rig}
+			rigs[it.Rig] = rp
+		}
+		rp.Total++
+		p.Hours += it.Hours
+		if it.IsClosed() {
+			p.Closed++
+			rp.Closed++
+		} else {
+			p.RemainingHours += it.Hours
+		}
+	}
+	for _, rp := range rigs {
+		p.Rigs = append(p.Rigs, *rp)
+	}
+	sort.Slice(p.Rigs, func(i, j int) bool { return p.Rigs[i].Rig < p.Rigs[j].Rig })
+
+	due := target.AddDate(0, 0, 1)
+	p.DaysLeft = int(due.Sub(now).Hours() / 24)
+	if elapsed := now.Sub(start).Hours() / 24; elapsed > 0 {
+		p.Velocity = float64(p.Closed) / elapsed
+	}
+	open := p.Total - p.Closed
+	switch {
+	case open == 0 && p.Total > 0:
+		p.Health = HealthDone
+	case now.After(due):
+		p.Health = HealthLate
+	case p.Velocity <= 0:
+		p.Health = HealthUnknown
+	default:
+		days := float64(open) / p.Velocity
+		p.Projected = now.Add(time.Duration(days * 24 * float64(time.Hour)))
+		p.Health = HealthOnTrack
+		if p.Projected.After(due) {
+			p.Health = HealthAtRisk
+		}
+	}
+	p.Burndown = Burndown(issues, start, target, now)
+	return p
+}
+
+// Burndown returns one point per day from start to target: the issues
+// still open at the end of each day up to today, against an ideal line
+// from all of them at start to none at the end of the target day. Issues
+// count from the start, whenever they were added to the milestone.
+func Burndown(issues []Issue, start, target, now time.Time) []Point {
+	if target.Before(start) {
+		return nil
+	}
+	days := int(target.Sub(start).Hours()/24+0.5) + 1
+	var points []Point
+	for d := 0; d < days; d++ {
+		date := start.AddDate(0, 0, d)
+		pt := Point{Date: date, Ideal: float64(len(issues)) * float64(days-d-1) / float64(days)}
+		if end := date.AddDate(0, 0, 1); !date.After(now) {
+			remaining := 0
+			for _, it := range issues {
+				if !it.IsClosed() || it.Closed.IsZero() || !it.Closed.Before(end) {
+					remaining++
+				}
+			}
+			pt.Remaining = &remaining
+		}
+		points = append(points, pt)
+	}
+	return points
+}
+
+// Chart draws a burndown as text: one row per day, a bar for the issues
+// remaining and a marker where the ideal line is. Days still ahead show
+// only the marker.
+func Chart(points []Point, width int) string {
+	top := 0.0
+	for _, pt := range points {
+		if pt.Remaining != nil && float64(*pt.Remaining) > top {
+			top = float64(*pt.Remaining)
+		}
+		if pt.Ideal > top {
+			top = pt.Ideal
+		}
+	}
+	if top == 0 || width < 1 {
+		return ""
+	}
+	scale := func(v float64) int { return int(v/top*float64(width) + 0.5) }
+
+	var sb strings.Builder
+	for _, pt := range points {
+		row := []rune(strings.Repeat(" ", width+1))
+		count := ""
+		if pt.Remaining != nil {
+			for i := 0; i < scale(float64(*pt.Remaining)); i++ {
+				row[i] = '█'
+			}
+			count = fmt.Sprint(*pt.Remaining)
+		}
+		row[scale(pt.Ideal)] = '│'
+		fmt.Fprintf(&sb, "%s %s %s\n", pt.Date.Format("Jan 02"), strings.TrimRight(string(row), " "), count)
+	}
+	return sb.String()
+}
+
+// Markdown renders the progress as a report section.
+func (p *Progress) Markdown() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "## Milestone %s: %s\n\n", p.ID, p.Title)
+	fmt.Fprintf(&sb, "- Target: %s (%s)\n", p.Target.Format(time.DateOnly), p.Health)
+	fmt.Fprintf(&sb, "- Progress: %d/%d issues closed (%d%%)\n", p.Closed, p.Total, p.Percent())
+	if p.Hours > 0 {
+		fmt.Fprintf(&sb, "- Estimated hours left: %.1f of %.1f\n", p.RemainingHours, p.Hours)
+	}
+	if !p.Projected.IsZero() {
+		fmt.Fprintf(&sb, "- Projected finish: %s at %.1f issues/day\n", p.Projected.Format(time.DateOnly), p.Velocity)
+	}
+	if len(p.Rigs) > 0 {
+		sb.WriteString("\n| Rig | Closed | Total |\n|---|---:|---:|\n")
+		for _, r := range p.Rigs {
+			name := r.Rig
+			if name == "" {
+				name = "town"
+			}
+			fmt.Fprintf(&sb, "| %s | %d | %d |\n", name, r.Closed, r.Total)
+		}
+	}
+	var open []Issue
+	for _, it := range p.Issues {
+		if !it.IsClosed() {
+			open = append(open, it)
+		}
+	}
+	if len(open) > 0 {
+		sb.WriteString("\n### Open\n\n")
+		for _, it := range open {
+			fmt.Fprintf(&sb, "- %s %s (%s)\n", it.ID, it.Title, it.Status)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,95 @@
+package milestone
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func day(d int) time.Time {
+	return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestBuild(t *testing.T) {
+	issues := []Issue{
+		{ID: "gt-1", Status: "closed", Rig: "gastown", Closed: day(2).Add(10 * time.Hour), Hours: 4},
+		{ID: "gt-2", Status: "closed", Rig: "gastown", Closed: day(4).Add(10 * time.Hour)},
+		{ID: "bd-1", Status: "in_progress", Rig: "beads", Hours: 8},
+		{ID: "bd-2", Status: "open", Rig: "beads", Hours: 2},
+	}
+	tests := []struct {
+		name   string
+		target time.Time
+		now    time.Time
+		health string
+	}{
+		// 2 closed in 4 days: 0.5/day, 2 open take 4 more days.
+		{"on track", day(10), day(5), HealthOnTrack},
+		{"at risk", day(7), day(5), HealthAtRisk},
+		{"late", day(4), day(5).Add(time.Hour), HealthLate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Build("hq-m1", "v2", "open", day(1), tt.target, issues, tt.now)
+			if p.Health != tt.health {
+				t.Errorf("health = %s, want %s (projected %v)", p.Health, tt.health, p.Projected)
+			}
+			if p.Total != 4 || p.Closed != 2 || p.Percent() != 50 || p.Hours != 14 || p.RemainingHours != 10 {
+				t.Errorf("progress = %+v", p)
+			}
+			if len(p.Rigs) != 2 || p.Rigs[0].Rig != "beads" || p.Rigs[1].Closed != 2 {
+				t.Errorf("rigs = %+v", p.Rigs)
+			}
+		})
+	}
+
+	if p := Build("hq-m1", "v2", "open", day(1), day(10), issues[2:], day(3)); p.Health != HealthUnknown {
+		t.Errorf("nothing closed: health = %s", p.Health)
+	}
+	if p := Build("hq-m1", "v2", "open", day(1), day(3), issues[:2], day(5)); p.Health != HealthDone {
+		t.Errorf("all closed: health = %s", p.Health)
+	}
+}
+
+func TestBurndown(t *testing.T) {
+	issues := []Issue{
+		{ID: "a", Status: "closed", Closed: day(1).Add(12 * time.Hour)},
+		{ID: "b", Status: "closed", Closed: day(3).Add(time.Hour)},
+		{ID: "c", Status: "open"},
+		{ID: "d", Status: "open"},
+	}
+	points := Burndown(issues, day(1), day(4), day(3).Add(5*time.Hour))
+	if len(points) != 4 {
+		t.Fatalf("got %d points, want 4", len(points))
+	}
+	want := []int{3, 3, 2}
+	for i, w := range want {
+		if points[i].Remaining == nil || *points[i].Remaining != w {
+			t.Errorf("day %d remaining = %v, want %d", i+1, points[i].Remaining, w)
+		}
+	}
+	if points[3].Remaining != nil {
+		t.Errorf("future day has remaining %d", *points[3].Remaining)
+	}
+	if points[0].Ideal != 3 || points[3].Ideal != 0 {
+		t.Errorf("ideal = %v .. %v, want 3 .. 0", points[0].Ideal, points[3].Ideal)
+	}
+
+	chart := Chart(points, 8)
+	if lines := strings.Split(strings.TrimSpace(chart), "\n"); len(lines) != 4 || !strings.HasPrefix(lines[0], "Mar 01 ") || !strings.HasSuffix(lines[0], " 3") {
+		t.Errorf("chart =\n%s", chart)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	p := Build("hq-m1", "v2 onboarding", "open", day(1), day(10), []Issue{
+		{ID: "gt-1", Title: "Login", Status: "closed", Rig: "gastown", Closed: day(2)},
+		{ID: "gt-2", Title: "Signup", Status: "open", Rig: "gastown"},
+	}, day(5))
+	md := p.Markdown()
+	for _, want := range []string{"## Milestone hq-m1: v2 onboarding", "1/2 issues closed (50%)", "| gastown | 1 | 2 |", "- gt-2 Signup (open)"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package mq
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// Freeze is a manual freeze of a rig's merge queue (gt mq freeze). While
+// frozen, only hotfix MRs are processed.
+type Freeze struct {
+	Reason   string    `json:"reason,omitempty"`
+	FrozenBy string    `json:"frozen_by,omitempty"`
+	FrozenAt time.Time `json:"frozen_at"`
+	// Until is when the freeze lifts on its own; zero means until unfrozen.
+	Until time.Time `json:"until,omitempty"`
+}
+
+// Active reports whether the freeze is in effect at now.
+func (f *Freeze) Active(now time.Time) bool {
+	return f != nil && (f.Until.IsZero() || now.Before(f.Until))
+}
+
+// FreezePath returns the manual freeze file for a rig.
+func FreezePath(rigPath string) string {
+	return filepath.Join(rigPath, "settings", "freeze.json")
+}
+
+// LoadFreeze reads a rig's manual freeze. Returns nil if the queue isn't
+// manually frozen.
+func LoadFreeze(rigPath string) (*Freeze, error) {
+	data, err := os.ReadFile(FreezePath(rigPath)) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading freeze: %w", err)
+	}
+	var f Freeze
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parsing freeze: %w", err)
+	}
+	return &f, nil
+}
+
+// SaveFreeze freezes a rig's queue.
+func SaveFreeze(rigPath string, f *Freeze) error {
+	if err := os.MkdirAll(filepath.Dir(FreezePath(rigPath)), 0755); err != nil {
+		return fmt.Errorf("creating settings directory: %w", err)
+	}
+	return util.AtomicWriteJSON(FreezePath(rigPath), f)
+}
+
+// ClearFreeze unfreezes a rig's queue. Clearing an unfrozen queue is not an error.
+func ClearFreeze(rigPath string) error {
+	if err := os.Remove(FreezePath(rigPath)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("removing freeze: %w", err)
+	}
+	return nil
+}
+
+// Window is a recurring weekly freeze window, e.g. "Fri 18:00-Mon 08:00",
+// or a daily one, e.g. "22:00-06:00". Times are local.
+type Window struct {
+	Spec string
+	// Daily windows repeat every day; StartDay and EndDay are unused.
+	Daily    bool
+	StartDay time.Weekday
+	EndDay   time.Weekday
+	Start    int // Minutes after midnight
+	End      int // Minutes after midnight
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// ParseWindow parses a freeze window spec: "<day> HH:MM-<day> HH:MM" for
+// a weekly window or "HH:MM-HH:MM" for a daily one. Days are English names
+// or three-letter abbreviations. A window may wrap past the week's or the
+// day's end.
+func ParseWindow(spec string) (Window, error) {
+	w := Window{Spec: spec}
+	startSpec, endSpec, ok := strings.Cut(spec, "-")
+	if !ok {
+		return w, fmt.Errorf("invalid freeze window %q: want \"Fri 18:00-Mon 08:00\" or \"22:00-06:00\"", spec)
+	}
+	startFields, endFields := strings.Fields(startSpec), strings.Fields(endSpec)
+	if len(startFields) != len(endFields) || len(startFields) == 0 || len(startFields) > 2 {
+		return w, fmt.Errorf("invalid freeze window %q: give a day on both ends or neither", spec)
+	}
+
+	var err error
+	if len(startFields) == 1 {
+		w.Daily = true
+	} else {
+		if w.StartDay, err = parseWeekday(startFields[0]); err != nil {
+			return w, fmt.Errorf("invalid freeze window %q: %w", spec, err)
+		}
+		if w.EndDay, err = parseWeekday(endFields[0]); err != nil {
+			return w, fmt.Errorf("invalid freeze window %q: %w", spec, err)
+		}
+	}
+	if w.Start, err = parseClock(startFields[len(startFields)-1]); err != nil {
+		return w, fmt.Errorf("invalid freeze window %q: %w", spec, err)
+	}
+	if w.End, err = parseClock(endFields[len(endFields)-1]); err != nil {
+		return w, fmt.Errorf("invalid freeze window %q: %w", spec, err)
+	}
+	if w.span() == 0 {
+		return w, fmt.Errorf("invalid freeze window %q: empty window", spec)
+	}
+	return w, nil
+}
+
+func parseWeekday(s string) (time.Weekday, error) {
+	s = strings.ToLower(s)
+	if len(s) >= 3 {
+		if d, ok := weekdays[s[:3]]; ok && strings.HasPrefix(strings.ToLower(d.String()), s) {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown day %q", s)
+}
+
+func parseClock(s string) (int, error) {
+	h, m, ok := strings.Cut(s, ":")
+	hour, err1 := strconv.Atoi(h)
+	minute, err2 := strconv.Atoi(m)
+	if !ok || err1 != nil || err2 != nil || hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		return 0, fmt.Errorf("invalid time %q: want HH:MM", s)
+	}
+	return hour*60 + minute, nil
+}
+
+// period is the length of the window's cycle in minutes.
+func (w Window) period() int {
+	if w.Daily {
+		return 24 * 60
+	}
+	return 7 * 24 * 60
+}
+
+// startOffset is the window start in minutes from the cycle's origin.
+func (w Window) startOffset() int {
+	if w.Daily {
+		return w.Start
+	}
+	return int(w.StartDay)*24*60 + w.Start
+}
+
+// span is the window length in minutes.
+func (w Window) span() int {
+	end := w.End
+	if !w.Daily {
+		end += int(w.EndDay) * 24 * 60
+	}
+	return ((end-w.startOffset())%w.period() + w.period()) % w.period()
+}
+
+// position returns t's offset into the cycle in minutes.
+func (w Window) position(t time.Time) int {
+	pos := t.Hour()*60 + t.Minute()
+	if !w.Daily {
+		pos += int(t.Weekday()) * 24 * 60
+	}
+	return pos
+}
+
+// Contains reports whether t falls inside the window.
+func (w Window) Contains(t time.Time) bool {
+	into := ((w.position(t)-w.startOffset())%w.period() + w.period()) % w.period()
+	return into < w.span()
+}
+
+// EndAfter returns when the window containing t ends. Only meaningful when
+// Contains(t).
+func (w Window) EndAfter(t time.Time) time.Time {
+	into := ((w.position(t)-w.startOffset())%w.period() + w.period()) % w.period()
+	base := t.Truncate(time.Minute)
+	return base.Add(time.Duration(w.span()-into) * time.Minute)
+}
+
+// ActiveWindow returns the first window containing now, if any.
+func ActiveWindow(specs []string, now time.Time) (*Window, error) {
+	for _, spec := range specs {
+		w, err := ParseWindow(spec)
+		if err != nil {
+			return nil, err
+		}
+		if w.Contains(now) {
+			return &w, nil
+		}
+	}
+	return nil, nil
+}
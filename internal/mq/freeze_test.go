@@ -0,0 +1,97 @@
+package mq
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseWindow(t *testing.T) {
+	tests := []struct {
+		spec    string
+		wantErr bool
+	}{
+		{"Fri 18:00-Mon 08:00", false},
+		{"friday 18:00-monday 08:00", false},
+		{"22:00-06:00", false},
+		{"Sat 00:00-Sun 23:59", false},
+		{"Fri 18:00", true},
+		{"Fri 18:00-08:00", true},
+		{"Fry 18:00-Mon 08:00", true},
+		{"25:00-06:00", true},
+		{"10:00-10:00", true},
+	}
+	for _, tt := range tests {
+		_, err := ParseWindow(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseWindow(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+		}
+	}
+}
+
+func TestWindowContains(t *testing.T) {
+	// 2026-01-02 is a Friday
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 1, day, hour, minute, 0, 0, time.Local)
+	}
+
+	weekend, _ := ParseWindow("Fri 18:00-Mon 08:00")
+	nightly, _ := ParseWindow("22:00-06:00")
+
+	tests := []struct {
+		name string
+		w    Window
+		t    time.Time
+		want bool
+	}{
+		{"weekend: Fri before", weekend, at(2, 17, 59), false},
+		{"weekend: Fri start", weekend, at(2, 18, 0), true},
+		{"weekend: Sun", weekend, at(4, 12, 0), true},
+		{"weekend: Mon before end", weekend, at(5, 7, 59), true},
+		{"weekend: Mon end", weekend, at(5, 8, 0), false},
+		{"weekend: Wed", weekend, at(7, 12, 0), false},
+		{"nightly: late", nightly, at(7, 23, 0), true},
+		{"nightly: early", nightly, at(7, 5, 0), true},
+		{"nightly: day", nightly, at(7, 12, 0), false},
+	}
+	for _, tt := range tests {
+		if got := tt.w.Contains(tt.t); got != tt.want {
+			t.Errorf("%s: Contains(%s) = %v, want %v", tt.name, tt.t.Format("Mon 15:04"), got, tt.want)
+		}
+	}
+
+	if end := weekend.EndAfter(at(4, 12, 0)); !end.Equal(at(5, 8, 0)) {
+		t.Errorf("EndAfter = %v, want Mon 08:00", end)
+	}
+	if end := nightly.EndAfter(at(7, 23, 30)); !end.Equal(at(8, 6, 0)) {
+		t.Errorf("EndAfter = %v, want next day 06:00", end)
+	}
+}
+
+func TestFreezeFile(t *testing.T) {
+	dir := t.TempDir()
+	if f, err := LoadFreeze(dir); err != nil || f != nil {
+		t.Fatalf("LoadFreeze on empty rig = %v, %v; want nil, nil", f, err)
+	}
+
+	now := time.Now()
+	if err := SaveFreeze(dir, &Freeze{Reason: "release", FrozenAt: now, Until: now.Add(time.Hour)}); err != nil {
+		t.Fatalf("SaveFreeze: %v", err)
+	}
+	f, err := LoadFreeze(dir)
+	if err != nil || f == nil || f.Reason != "release" {
+		t.Fatalf("LoadFreeze = %+v, %v", f, err)
+	}
+	if !f.Active(now) || f.Active(now.Add(2*time.Hour)) {
+		t.Error("freeze should be active until Until only")
+	}
+	if !(&Freeze{}).Active(now) {
+		t.Error("freeze without Until should stay active")
+	}
+
+	if err := ClearFreeze(dir); err != nil {
+		t.Fatalf("ClearFreeze: %v", err)
+	}
+	if err := ClearFreeze(dir); err != nil {
+		t.Errorf("ClearFreeze twice: %v", err)
+	}
+}
@@ -0,0 +1,355 @@
+package mq
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// Link groups tie MRs in different rigs (a repo and its submodule, or
+// sibling repos) into one change that lands together or not at all.
+//
+// Each rig's refinery processes its own member as usual. Once a member's
+// checks pass it is marked ready and held; members land only when every
+// member is ready, in dependency order (a member pinning another's repo as a
+// submodule lands after it, with its submodule pointer bumped to the merged
+// commit). If any member fails, the group aborts: held members are rejected
+// and members that already landed are reverted.
+
+// Link member states.
+const (
+	LinkPending  = "pending"  // not yet through its checks
+	LinkReady    = "ready"    // checks passed; held for the group
+	LinkMerged   = "merged"   // landed
+	LinkFailed   = "failed"   // failed its checks or its merge
+	LinkReverted = "reverted" // landed, then reverted when the group aborted
+)
+
+// Link group states.
+const (
+	LinkOpen    = "open"
+	LinkLanded  = "landed"
+	LinkAborted = "aborted"
+)
+
+// ErrLinkNotFound is returned for an unknown link group.
+var ErrLinkNotFound = errors.New("link group not found")
+
+// LinkMember is one MR in a link group.
+type LinkMember struct {
+	Rig    string `json:"rig"`
+	MR     string `json:"mr"`
+	Branch string `json:"branch,omitempty"`
+	Target string `json:"target,omitempty"`
+
+	// Submodule is the path, in this member's repo, of a submodule pinned
+	// to the repo of the member named by Tracks. When that member lands,
+	// this member's pointer is bumped to its merge commit before landing.
+	Submodule string `json:"submodule,omitempty"`
+	Tracks    string `json:"tracks,omitempty"`
+
+	State       string `json:"state"`
+	MergeCommit string `json:"merge_commit,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// LinkGroup is a set of MRs that land atomically.
+type LinkGroup struct {
+	ID        string        `json:"id"`
+	State     string        `json:"state"`
+	Members   []*LinkMember `json:"members"`
+	Reason    string        `json:"reason,omitempty"` // why the group aborted
+	CreatedBy string        `json:"created_by,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// LinkAction is what a refinery should do with a ready member.
+type LinkAction int
+
+const (
+	// LinkWait holds the member: the group isn't ready, or the member's
+	// dependencies haven't landed yet.
+	LinkWait LinkAction = iota
+	// LinkProceed lands the member now.
+	LinkProceed
+	// LinkAbort rejects the member: the group aborted.
+	LinkAbort
+)
+
+// NewLinkGroup returns an open group of members, all pending. It checks
+// that MRs are unique and that Tracks names another member.
+func NewLinkGroup(members []*LinkMember, createdBy string, now time.Time) (*LinkGroup, error) {
+	if len(members) < 2 {
+		return nil, fmt.Errorf("a link group needs at least two MRs")
+	}
+	seen := make(map[string]bool)
+	for _, m := range members {
+		if seen[m.MR] {
+			return nil, fmt.Errorf("%s is listed twice", m.MR)
+		}
+		seen[m.MR] = true
+	}
+	for _, m := range members {
+		if (m.Submodule == "") != (m.Tracks == "") {
+			return nil, fmt.Errorf("%s: a submodule path and the MR it tracks go together", m.MR)
+		}
+		if m.Tracks != "" && (!seen[m.Tracks] || m.Tracks == m.MR) {
+			return nil, fmt.Errorf("%s tracks %s, which is not another member of the group", m.MR, m.Tracks)
+		}
+		m.State = LinkPending
+	}
+	g := &LinkGroup{
+		ID:        newLinkID(),
+		State:     LinkOpen,
+		Members:   members,
+		CreatedBy: createdBy,
+		CreatedAt: now.UTC(),
+		UpdatedAt: now.UTC(),
+	}
+	if _, err := g.Order(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func newLinkID() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return "link-" + hex.EncodeToString(b)
+}
+
+// Member returns the member for mrID, or nil.
+func (g *LinkGroup) Member(mrID string) *LinkMember {
+	for _, m := range g.Members {
+		if m.MR == mrID {
+			return m
+		}
+	}
+	return nil
+}
+
+// Order returns the members in landing order: a member lands after the
+// member it tracks. Ties keep the order members were given in.
+func (g *LinkGroup) Order() ([]*LinkMember, error) {
+	var order []*LinkMember
+	placed := make(map[string]bool)
+	for len(order) < len(g.Members) {
+		progress := false
+		for _, m := range g.Members {
+			if placed[m.MR] || (m.Tracks != "" && !placed[m.Tracks]) {
+				continue
+			}
+			order = append(order, m)
+			placed[m.MR] = true
+			progress = true
+		}
+		if !progress {
+			return nil, fmt.Errorf("link group %s has a submodule cycle", g.ID)
+		}
+	}
+	return order, nil
+}
+
+// MarkReady records that mrID passed its checks.
+func (g *LinkGroup) MarkReady(mrID string, now time.Time) {
+	if m := g.Member(mrID); m != nil && m.State == LinkPending {
+		m.State = LinkReady
+		g.UpdatedAt = now.UTC()
+	}
+}
+
+// Decide returns what to do with mrID once it is ready, and why.
+func (g *LinkGroup) Decide(mrID string) (LinkAction, string) {
+	self := g.Member(mrID)
+	if self == nil {
+		return LinkAbort, fmt.Sprintf("%s is not in link group %s", mrID, g.ID)
+	}
+	if g.State == LinkAborted {
+		return LinkAbort, fmt.Sprintf("link group %s aborted: %s", g.ID, g.Reason)
+	}
+	var waiting []string
+	for _, m := range g.Members {
+		if m.State == LinkPending {
+			waiting = append(waiting, m.MR+" ("+m.Rig+")")
+		}
+	}
+	if len(waiting) > 0 {
+		return LinkWait, "waiting for linked MRs to pass checks: " + strings.Join(waiting, ", ")
+	}
+	order, err := g.Order()
+	if err != nil {
+		return LinkAbort, err.Error()
+	}
+	for _, m := range order {
+		if m.MR == mrID {
+			return LinkProceed, ""
+		}
+		if m.State != LinkMerged {
+			return LinkWait, fmt.Sprintf("waiting for %s (%s) to land first", m.MR, m.Rig)
+		}
+	}
+	return LinkAbort, fmt.Sprintf("%s is not in link group %s", mrID, g.ID)
+}
+
+// SubmoduleBump returns the submodule path and commit mrID must pin before
+// landing, or "" if it pins nothing.
+func (g *LinkGroup) SubmoduleBump(mrID string) (path, commit string) {
+	self := g.Member(mrID)
+	if self == nil || self.Submodule == "" {
+		return "", ""
+	}
+	if tracked := g.Member(self.Tracks); tracked != nil && tracked.State == LinkMerged {
+		return self.Submodule, tracked.MergeCommit
+	}
+	return "", ""
+}
+
+// MarkMerged records that mrID landed. The group is landed once every
+// member has.
+func (g *LinkGroup) MarkMerged(mrID, commit string, now time.Time) {
+	m := g.Member(mrID)
+	if m == nil {
+		return
+	}
+	m.State, m.MergeCommit, m.Error = LinkMerged, commit, ""
+	g.UpdatedAt = now.UTC()
+	for _, other := range g.Members {
+		if other.State != LinkMerged {
+			return
+		}
+	}
+	g.State = LinkLanded
+}
+
+// MarkFailed records that mrID failed and aborts the group. It returns the
+// members that already landed and must be reverted; a group that had
+// already aborted returns none.
+func (g *LinkGroup) MarkFailed(mrID, reason string, now time.Time) []*LinkMember {
+	m := g.Member(mrID)
+	if m == nil || g.State == LinkLanded {
+		return nil
+	}
+	m.State, m.Error = LinkFailed, reason
+	g.UpdatedAt = now.UTC()
+	return g.Abort(fmt.Sprintf("%s (%s) failed: %s", m.MR, m.Rig, reason), now)
+}
+
+// Abort aborts an open group. It returns the members that already landed
+// and must be reverted; a group that isn't open returns none.
+func (g *LinkGroup) Abort(reason string, now time.Time) []*LinkMember {
+	if g.State != LinkOpen {
+		// Whoever aborted the group already took care of its landings
+		return nil
+	}
+	g.State, g.Reason = LinkAborted, reason
+	g.UpdatedAt = now.UTC()
+	var landed []*LinkMember
+	for _, other := range g.Members {
+		if other.State == LinkMerged {
+			landed = append(landed, other)
+		}
+	}
+	return landed
+}
+
+// MarkReverted records that a landed member was reverted after an abort.
+func (g *LinkGroup) MarkReverted(mrID string, now time.Time) {
+	if m := g.Member(mrID); m != nil && m.State == LinkMerged {
+		m.State = LinkReverted
+		g.UpdatedAt = now.UTC()
+	}
+}
+
+// LinksDir returns the directory holding the town's link groups.
+func LinksDir(townRoot string) string {
+	return filepath.Join(constants.TownRuntimePath(townRoot), "mr-links")
+}
+
+func linkPath(townRoot, id string) string {
+	return filepath.Join(LinksDir(townRoot), id+".json")
+}
+
+// LoadLink reads a link group.
+func LoadLink(townRoot, id string) (*LinkGroup, error) {
+	data, err := os.ReadFile(linkPath(townRoot, id)) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrLinkNotFound, id)
+		}
+		return nil, err
+	}
+	var g LinkGroup
+	if err := json.Unmarshal(data, &g); err != nil {
+		return nil, fmt.Errorf("parsing link group %s: %w", id, err)
+	}
+	return &g, nil
+}
+
+// SaveLink writes a new link group.
+func SaveLink(townRoot string, g *LinkGroup) error {
+	if err := os.MkdirAll(LinksDir(townRoot), 0755); err != nil {
+		return fmt.Errorf("creating links directory: %w", err)
+	}
+	return util.AtomicWriteJSON(linkPath(townRoot, g.ID), g)
+}
+
+// UpdateLink loads a link group under its lock, applies fn, and saves it.
+// Refineries in different rigs coordinate through this lock.
+func UpdateLink(townRoot, id string, fn func(*LinkGroup) error) (*LinkGroup, error) {
+	if err := os.MkdirAll(LinksDir(townRoot), 0755); err != nil {
+		return nil, fmt.Errorf("creating links directory: %w", err)
+	}
+	fl := flock.New(filepath.Join(LinksDir(townRoot), id+".lock"))
+	if err := fl.Lock(); err != nil {
+		return nil, fmt.Errorf("locking link group %s: %w", id, err)
+	}
+	defer func() { _ = fl.Unlock() }()
+
+	g, err := LoadLink(townRoot, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := fn(g); err != nil {
+		return nil, err
+	}
+	if err := util.AtomicWriteJSON(linkPath(townRoot, id), g); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// ListLinks returns the town's link groups, newest first.
+func ListLinks(townRoot string) ([]*LinkGroup, error) {
+	entries, err := os.ReadDir(LinksDir(townRoot))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var groups []*LinkGroup
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		g, err := LoadLink(townRoot, strings.TrimSuffix(e.Name(), ".json"))
+		if err != nil {
+			continue
+		}
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].CreatedAt.After(groups[j].CreatedAt) })
+	return groups, nil
+}
@@ -0,0 +1,126 @@
+package mq
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func testGroup(t *testing.T) *LinkGroup {
+	t.Helper()
+	g, err := NewLinkGroup([]*LinkMember{
+		{Rig: "app", MR: "app-mr-1", Submodule: "vendor/lib", Tracks: "lib-mr-1"},
+		{Rig: "lib", MR: "lib-mr-1"},
+	}, "mayor", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestNewLinkGroup_Validates(t *testing.T) {
+	now := time.Now()
+	bad := [][]*LinkMember{
+		{{MR: "a"}},
+		{{MR: "a"}, {MR: "a"}},
+		{{MR: "a", Submodule: "x"}, {MR: "b"}},
+		{{MR: "a", Submodule: "x", Tracks: "zzz"}, {MR: "b"}},
+		{{MR: "a", Submodule: "x", Tracks: "b"}, {MR: "b", Submodule: "y", Tracks: "a"}},
+	}
+	for i, members := range bad {
+		if _, err := NewLinkGroup(members, "", now); err == nil {
+			t.Errorf("case %d: expected an error", i)
+		}
+	}
+}
+
+func TestLinkGroup_LandsInOrder(t *testing.T) {
+	g := testGroup(t)
+	now := time.Now()
+
+	order, _ := g.Order()
+	if order[0].MR != "lib-mr-1" || order[1].MR != "app-mr-1" {
+		t.Fatalf("submodule should land first, got %s then %s", order[0].MR, order[1].MR)
+	}
+
+	// The app is ready first but must wait for the library
+	g.MarkReady("app-mr-1", now)
+	if action, _ := g.Decide("app-mr-1"); action != LinkWait {
+		t.Fatalf("app should wait for lib's checks, got %v", action)
+	}
+	g.MarkReady("lib-mr-1", now)
+	if action, reason := g.Decide("app-mr-1"); action != LinkWait {
+		t.Fatalf("app should wait for lib to land, got %v (%s)", action, reason)
+	}
+	if action, _ := g.Decide("lib-mr-1"); action != LinkProceed {
+		t.Fatalf("lib should proceed, got %v", action)
+	}
+	if path, _ := g.SubmoduleBump("app-mr-1"); path != "" {
+		t.Error("no bump before lib lands")
+	}
+
+	g.MarkMerged("lib-mr-1", "abc123", now)
+	if action, _ := g.Decide("app-mr-1"); action != LinkProceed {
+		t.Fatalf("app should proceed after lib landed, got %v", action)
+	}
+	if path, commit := g.SubmoduleBump("app-mr-1"); path != "vendor/lib" || commit != "abc123" {
+		t.Errorf("SubmoduleBump = %q %q", path, commit)
+	}
+	g.MarkMerged("app-mr-1", "def456", now)
+	if g.State != LinkLanded {
+		t.Errorf("State = %s, want landed", g.State)
+	}
+}
+
+func TestLinkGroup_AbortRevertsLanded(t *testing.T) {
+	g := testGroup(t)
+	now := time.Now()
+	g.MarkReady("lib-mr-1", now)
+	g.MarkReady("app-mr-1", now)
+	g.MarkMerged("lib-mr-1", "abc123", now)
+
+	landed := g.MarkFailed("app-mr-1", "CI failed after submodule bump", now)
+	if len(landed) != 1 || landed[0].MR != "lib-mr-1" {
+		t.Fatalf("landed = %+v, want lib-mr-1", landed)
+	}
+	if g.State != LinkAborted {
+		t.Errorf("State = %s, want aborted", g.State)
+	}
+	if again := g.MarkFailed("app-mr-1", "retried", now); len(again) != 0 {
+		t.Errorf("second failure should not revert again, got %+v", again)
+	}
+	if action, _ := g.Decide("app-mr-1"); action != LinkAbort {
+		t.Errorf("aborted group should reject members, got %v", action)
+	}
+	g.MarkReverted("lib-mr-1", now)
+	if g.Member("lib-mr-1").State != LinkReverted {
+		t.Error("lib should be marked reverted")
+	}
+}
+
+func TestLinkStore(t *testing.T) {
+	town := t.TempDir()
+	g := testGroup(t)
+	if err := SaveLink(town, g); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := UpdateLink(town, g.ID, func(g *LinkGroup) error {
+		g.MarkReady("lib-mr-1", time.Now())
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadLink(town, g.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Member("lib-mr-1").State != LinkReady {
+		t.Errorf("update not saved: %+v", loaded.Member("lib-mr-1"))
+	}
+	if list, _ := ListLinks(town); len(list) != 1 {
+		t.Errorf("ListLinks = %d groups", len(list))
+	}
+	if _, err := LoadLink(town, "link-nope"); !errors.Is(err, ErrLinkNotFound) {
+		t.Errorf("LoadLink(unknown) = %v", err)
+	}
+}
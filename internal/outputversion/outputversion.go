@@ -0,0 +1,196 @@
+// Package outputversion versions the shape of gt's JSON output.
+//
+// Every JSON object gt prints carries an "output_version" field: a
+// top-level object, or each object in a top-level array. Harnesses
+// that parse gt pin a version with --output-version (or GT_OUTPUT_VERSION)
+// and keep getting that shape after gt moves on: renamed fields are mapped
+// back to their old names, with a deprecation warning on stderr so the pin
+// gets updated before the old shape is dropped.
+//
+// Version history:
+//
+//	1  Output before versioning; no output_version field.
+//	2  Adds output_version to top-level objects.
+//	3  Adds output_version to the objects of top-level arrays.
+package outputversion
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"sync/atomic"
+)
+
+// EnvVar pins the output version, same as --output-version.
+const EnvVar = "GT_OUTPUT_VERSION"
+
+// Field is the property that carries the version in JSON output.
+const Field = "output_version"
+
+const (
+	// Current is the output version gt emits by default.
+	Current = 3
+	// Oldest is the oldest output version gt can still emit. Raise it to
+	// drop a deprecated shape.
+	Oldest = 1
+)
+
+// Rename records a JSON field renamed in an output version. Add one here
+// whenever a field of a command's --json output changes name, instead of
+// changing the shape under existing harnesses.
+type Rename struct {
+	Since   int    // the output version that introduced New
+	Command string // command path, e.g. "mq status"; "" for every command
+	Old     string // the field name before Since
+	New     string // the field name from Since on
+}
+
+// Renames are the field renames across output versions, oldest first.
+var Renames []Rename
+
+var requested atomic.Int32
+
+// Set selects the output version to emit; 0 selects Current.
+func Set(version int) error {
+	if version == 0 {
+		version = Current
+	}
+	if version < Oldest || version > Current {
+		return fmt.Errorf("output version %d not supported (this gt emits %d through %d)", version, Oldest, Current)
+	}
+	requested.Store(int32(version))
+	return nil
+}
+
+// FromEnv returns the version pinned by GT_OUTPUT_VERSION, or 0 if unset.
+func FromEnv() (int, error) {
+	s := os.Getenv(EnvVar)
+	if s == "" {
+		return 0, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%s=%q: not a version number", EnvVar, s)
+	}
+	return v, nil
+}
+
+// Requested returns the output version selected with Set.
+func Requested() int {
+	if v := requested.Load(); v != 0 {
+		return int(v)
+	}
+	return Current
+}
+
+// Deprecation is a renamed field mapped back to its old name for an
+// older output version.
+type Deprecation struct {
+	Rename
+	Version int // the version the output was shaped for
+}
+
+func (d Deprecation) String() string {
+	return fmt.Sprintf("field %q is deprecated: it is %q since output version %d (you requested %d)",
+		d.Old, d.New, d.Since, d.Version)
+}
+
+// Encode marshals v as indented JSON in the shape of the given output
+// version of a command's output, returning the deprecated fields it had
+// to map back. Output in the current version keeps its field order;
+// older versions with renames are re-encoded with sorted keys.
+func Encode(v any, command string, version int) ([]byte, []Deprecation, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var renames []Rename
+	for _, r := range Renames {
+		if r.Since > version && (r.Command == "" || r.Command == command) {
+			renames = append(renames, r)
+		}
+	}
+	var deprecations []Deprecation
+	if len(renames) > 0 {
+		var doc any
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, nil, err
+		}
+		// Newest first, so a field renamed twice walks back one step at a time.
+		for i := len(renames) - 1; i >= 0; i-- {
+			if renameKeys(doc, renames[i].New, renames[i].Old) {
+				deprecations = append(deprecations, Deprecation{Rename: renames[i], Version: version})
+			}
+		}
+		if data, err = json.Marshal(doc); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if version >= 2 {
+		if data, err = stamp(data, version); err != nil {
+			return nil, nil, err
+		}
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, data, "", "  "); err != nil {
+		return nil, nil, err
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), deprecations, nil
+}
+
+// stamp adds the version field first in a top-level object of compact
+// JSON, or, from version 3, in each object of a top-level array. Scalars
+// have nowhere to carry it and are left alone.
+func stamp(data []byte, version int) ([]byte, error) {
+	switch {
+	case len(data) > 0 && data[0] == '{':
+		return stampObject(data, version), nil
+	case len(data) > 0 && data[0] == '[' && version >= 3:
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, err
+		}
+		for i, item := range items {
+			if len(item) > 0 && item[0] == '{' {
+				items[i] = stampObject(item, version)
+			}
+		}
+		return json.Marshal(items)
+	}
+	return data, nil
+}
+
+func stampObject(obj []byte, version int) []byte {
+	field := fmt.Sprintf("%q:%d", Field, version)
+	if bytes.Equal(obj, []byte("{}")) {
+		return []byte("{" + field + "}")
+	}
+	return append([]byte("{"+field+","), obj[1:]...)
+}
+
+// renameKeys renames the key from to the key to in every object in doc,
+// reporting whether any was found.
+func renameKeys(doc any, from, to string) bool {
+	found := false
+	switch v := doc.(type) {
+	case map[string]any:
+		if val, ok := v[from]; ok {
+			delete(v, from)
+			v[to] = val
+			found = true
+		}
+		for _, val := range v {
+			found = renameKeys(val, from, to) || found
+		}
+	case []any:
+		for _, val := range v {
+			found = renameKeys(val, from, to) || found
+		}
+	}
+	return found
+}
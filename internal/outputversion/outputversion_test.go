@@ -0,0 +1,122 @@
+package outputversion
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestEncodeStamp(t *testing.T) {
+	type out struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+	tests := []struct {
+		name    string
+		v       any
+		version int
+		want    string
+	}{
+		{"object", out{"a", 1}, 2, "{\n  \"output_version\": 2,\n  \"name\": \"a\",\n  \"count\": 1\n}\n"},
+		{"empty object", struct{}{}, 2, "{\n  \"output_version\": 2\n}\n"},
+		{"array", []int{1, 2}, 3, "[\n  1,\n  2\n]\n"},
+		{"array of objects", []out{{"a", 1}}, 3, "[\n  {\n    \"output_version\": 3,\n    \"name\": \"a\",\n    \"count\": 1\n  }\n]\n"},
+		{"array of objects, version 2", []out{{"a", 1}}, 2, "[\n  {\n    \"name\": \"a\",\n    \"count\": 1\n  }\n]\n"},
+		{"version 1", out{"a", 1}, 1, "{\n  \"name\": \"a\",\n  \"count\": 1\n}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, deps, err := Encode(tt.v, "status", tt.version)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Encode() = %q, want %q", got, tt.want)
+			}
+			if len(deps) != 0 {
+				t.Errorf("deprecations = %v, want none", deps)
+			}
+		})
+	}
+}
+
+func TestEncodeRenames(t *testing.T) {
+	saved := Renames
+	defer func() { Renames = saved }()
+	Renames = []Rename{
+		{Since: 2, Command: "mq status", Old: "mr_id", New: "id"},
+		{Since: 2, Old: "total", New: "count"},
+	}
+
+	v := map[string]any{"id": "gt-1", "count": 3, "items": []any{map[string]any{"count": 1}}}
+
+	tests := []struct {
+		name    string
+		command string
+		version int
+		want    []string // keys expected at the top level
+		deps    int
+	}{
+		{"current shape", "mq status", 2, []string{Field, "id", "count"}, 0},
+		{"old shape", "mq status", 1, []string{"mr_id", "total"}, 2},
+		{"rename scoped to another command", "status", 1, []string{"id", "total"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, deps, err := Encode(v, tt.command, tt.version)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var doc map[string]any
+			if err := json.Unmarshal(got, &doc); err != nil {
+				t.Fatal(err)
+			}
+			for _, key := range tt.want {
+				if _, ok := doc[key]; !ok {
+					t.Errorf("missing key %q in %s", key, got)
+				}
+			}
+			if len(deps) != tt.deps {
+				t.Errorf("got %d deprecations, want %d: %v", len(deps), tt.deps, deps)
+			}
+			if tt.version == 1 && strings.Contains(string(got), `"count"`) {
+				t.Errorf("nested field not renamed: %s", got)
+			}
+		})
+	}
+	if _, ok := v["id"]; !ok {
+		t.Error("Encode modified its input")
+	}
+}
+
+func TestSet(t *testing.T) {
+	defer func() { _ = Set(0) }()
+	for _, v := range []int{Oldest, Current} {
+		if err := Set(v); err != nil || Requested() != v {
+			t.Errorf("Set(%d) = %v, Requested() = %d", v, err, Requested())
+		}
+	}
+	if err := Set(0); err != nil || Requested() != Current {
+		t.Errorf("Set(0) = %v, Requested() = %d, want current", err, Requested())
+	}
+	for _, v := range []int{-1, Current + 1} {
+		if err := Set(v); err == nil {
+			t.Errorf("Set(%d) accepted an unsupported version", v)
+		}
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv(EnvVar, "")
+	if v, err := FromEnv(); v != 0 || err != nil {
+		t.Errorf("unset: got %d, %v", v, err)
+	}
+	t.Setenv(EnvVar, "1")
+	if v, err := FromEnv(); v != 1 || err != nil {
+		t.Errorf("1: got %d, %v", v, err)
+	}
+	t.Setenv(EnvVar, "latest")
+	if _, err := FromEnv(); err == nil {
+		t.Error("accepted a non-numeric version")
+	}
+}
@@ -0,0 +1,82 @@
+// Package paging selects pages of large result sets.
+//
+// A Page is a window of a result set in its usual order: Limit results
+// after skipping Offset. Sources that can stop early (bd's --limit, a
+// bounded scan of the event log) are asked for Fetch results, enough for
+// the page and one more to tell whether another page follows; Apply cuts
+// the page out of what came back.
+package paging
+
+import "fmt"
+
+// Page is a window of a result set. A zero Limit means no limit.
+type Page struct {
+	Limit  int
+	Offset int
+}
+
+// Info describes the page a command returned, for JSON output. NextOffset
+// is the --offset of the next page, when there is one.
+type Info struct {
+	Limit      int  `json:"limit"`
+	Offset     int  `json:"offset"`
+	More       bool `json:"more"`
+	NextOffset int  `json:"next_offset,omitempty"`
+}
+
+// Validate rejects negative limits and offsets.
+func (p Page) Validate() error {
+	if p.Limit < 0 {
+		return fmt.Errorf("--limit must not be negative")
+	}
+	if p.Offset < 0 {
+		return fmt.Errorf("--offset must not be negative")
+	}
+	return nil
+}
+
+// Paged reports whether the page selects less than everything.
+func (p Page) Paged() bool {
+	return p.Limit > 0 || p.Offset > 0
+}
+
+// Fetch is how many results to ask a source for: the page, the results
+// before it, and one more. Zero means all of them.
+func (p Page) Fetch() int {
+	if p.Limit <= 0 {
+		return 0
+	}
+	return p.Offset + p.Limit + 1
+}
+
+// Apply cuts the page out of items, which start at the beginning of the
+// result set.
+func Apply[T any](items []T, p Page) ([]T, Info) {
+	info := Info{Limit: p.Limit, Offset: p.Offset}
+	if p.Offset >= len(items) {
+		return items[:0], info
+	}
+	items = items[p.Offset:]
+	if p.Limit > 0 && len(items) > p.Limit {
+		items = items[:p.Limit]
+		info.More = true
+		info.NextOffset = p.Offset + p.Limit
+	}
+	return items, info
+}
+
+// Hint is the line human output ends a page with, or "" when the page
+// is everything.
+func (i Info) Hint(shown int) string {
+	if !i.More && i.Offset == 0 {
+		return ""
+	}
+	if shown == 0 {
+		return fmt.Sprintf("Nothing past offset %d", i.Offset)
+	}
+	hint := fmt.Sprintf("Showing %d-%d", i.Offset+1, i.Offset+shown)
+	if i.More {
+		hint += fmt.Sprintf("; more with --offset %d", i.NextOffset)
+	}
+	return hint
+}
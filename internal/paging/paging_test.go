@@ -0,0 +1,68 @@
+package paging
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	tests := []struct {
+		name string
+		page Page
+		want []int
+		info Info
+	}{
+		{"everything", Page{}, []int{1, 2, 3, 4, 5}, Info{}},
+		{"first page", Page{Limit: 2}, []int{1, 2}, Info{Limit: 2, More: true, NextOffset: 2}},
+		{"middle page", Page{Limit: 2, Offset: 2}, []int{3, 4}, Info{Limit: 2, Offset: 2, More: true, NextOffset: 4}},
+		{"last page", Page{Limit: 2, Offset: 4}, []int{5}, Info{Limit: 2, Offset: 4}},
+		{"exact fit", Page{Limit: 5}, []int{1, 2, 3, 4, 5}, Info{Limit: 5}},
+		{"past the end", Page{Limit: 2, Offset: 9}, []int{}, Info{Limit: 2, Offset: 9}},
+		{"offset only", Page{Offset: 3}, []int{4, 5}, Info{Offset: 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, info := Apply(append([]int(nil), items...), tt.page)
+			if !reflect.DeepEqual(got, tt.want) || info != tt.info {
+				t.Errorf("Apply(%+v) = %v, %+v; want %v, %+v", tt.page, got, info, tt.want, tt.info)
+			}
+		})
+	}
+}
+
+func TestFetch(t *testing.T) {
+	tests := []struct {
+		page Page
+		want int
+	}{
+		{Page{}, 0},
+		{Page{Offset: 10}, 0},
+		{Page{Limit: 50}, 51},
+		{Page{Limit: 50, Offset: 100}, 151},
+	}
+	for _, tt := range tests {
+		if got := tt.page.Fetch(); got != tt.want {
+			t.Errorf("%+v.Fetch() = %d, want %d", tt.page, got, tt.want)
+		}
+	}
+}
+
+func TestHint(t *testing.T) {
+	tests := []struct {
+		info  Info
+		shown int
+		want  string
+	}{
+		{Info{}, 5, ""},
+		{Info{Limit: 10}, 3, ""},
+		{Info{Limit: 2, More: true, NextOffset: 2}, 2, "Showing 1-2; more with --offset 2"},
+		{Info{Limit: 2, Offset: 4}, 1, "Showing 5-5"},
+		{Info{Limit: 2, Offset: 9}, 0, "Nothing past offset 9"},
+	}
+	for _, tt := range tests {
+		if got := tt.info.Hint(tt.shown); got != tt.want {
+			t.Errorf("%+v.Hint(%d) = %q, want %q", tt.info, tt.shown, got, tt.want)
+		}
+	}
+}
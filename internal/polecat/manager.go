@@ -447,11 +447,18 @@ type AddOptions struct {
 // - {description}: sanitized issue title
 // - {timestamp}: unique timestamp
 //
+// The rig settings' conventions.branch_template takes precedence over the
+// polecat_branch_template rig config.
+//
 // If no template is configured or template is empty, uses default format:
 // - polecat/{name}/{issue}@{timestamp} when issue is available
 // - polecat/{name}-{timestamp} otherwise
 func (m *Manager) buildBranchName(name, issue string) string {
 	template := m.rig.GetStringConfig("polecat_branch_template")
+	if settings, err := config.LoadRigSettings(config.RigSettingsPath(m.rig.Path)); err == nil &&
+		settings.Conventions != nil && settings.Conventions.BranchTemplate != "" {
+		template = settings.Conventions.BranchTemplate
+	}
 
 	// No template configured - use default behavior for backward compatibility
 	if template == "" {
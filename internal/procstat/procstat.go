@@ -0,0 +1,103 @@
+// Package procstat samples CPU and memory use of process trees, so agent
+// sessions can be shown with the resources their processes consume.
+package procstat
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Proc is one process in a snapshot.
+type Proc struct {
+	PID     int
+	PPID    int
+	CPU     float64 // percent of one core, as ps reports it
+	RSS     int64   // resident memory in bytes
+	Command string
+}
+
+// Snapshot is the process table at one moment, keyed by PID.
+type Snapshot map[int]Proc
+
+// Usage is the summed resource use of a process tree.
+type Usage struct {
+	CPU   float64 `json:"cpu_percent"`
+	RSS   int64   `json:"rss_bytes"`
+	Procs int     `json:"procs"`
+}
+
+// psTimeout bounds a ps invocation.
+const psTimeout = 5 * time.Second
+
+// Take snapshots the process table with ps, which reports the same fields
+// on Linux and macOS.
+func Take() (Snapshot, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), psTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "ps", "-A", "-o", "pid=,ppid=,pcpu=,rss=,comm=").Output()
+	if err != nil {
+		return nil, fmt.Errorf("running ps: %w", err)
+	}
+	return Parse(string(out)), nil
+}
+
+// Parse parses ps output with pid, ppid, pcpu, rss (KiB), and comm columns.
+// Malformed lines are skipped.
+func Parse(out string) Snapshot {
+	snap := make(Snapshot)
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 {
+			continue
+		}
+		pid, err1 := strconv.Atoi(fields[0])
+		ppid, err2 := strconv.Atoi(fields[1])
+		cpu, err3 := strconv.ParseFloat(fields[2], 64)
+		rss, err4 := strconv.ParseInt(fields[3], 10, 64)
+		if err1 != nil || err2 != nil || err3 != nil || err4 != nil {
+			continue
+		}
+		snap[pid] = Proc{
+			PID:     pid,
+			PPID:    ppid,
+			CPU:     cpu,
+			RSS:     rss * 1024,
+			Command: strings.Join(fields[4:], " "),
+		}
+	}
+	return snap
+}
+
+// Tree sums the usage of root and all its descendants. A root that isn't
+// in the snapshot yields zero usage.
+func (s Snapshot) Tree(root int) Usage {
+	children := make(map[int][]int)
+	for pid, p := range s {
+		if pid != p.PPID {
+			children[p.PPID] = append(children[p.PPID], pid)
+		}
+	}
+	var u Usage
+	seen := make(map[int]bool)
+	stack := []int{root}
+	for len(stack) > 0 {
+		pid := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		p, ok := s[pid]
+		if !ok || seen[pid] {
+			continue
+		}
+		seen[pid] = true
+		u.CPU += p.CPU
+		u.RSS += p.RSS
+		u.Procs++
+		stack = append(stack, children[pid]...)
+	}
+	return u
+}
@@ -0,0 +1,42 @@
+package procstat
+
+import "testing"
+
+const psOutput = `    1     0   0.0  1024 init
+  100     1   2.5  2048 tmux: server
+  200   100  50.0 409600 claude
+  201   200  10.0 10240 node
+  202   201   1.5  1024 go test ./...
+  300   100   0.0  1024 bash
+  bad   line
+`
+
+func TestParse(t *testing.T) {
+	snap := Parse(psOutput)
+	if len(snap) != 6 {
+		t.Fatalf("Parse found %d processes, want 6", len(snap))
+	}
+	if p := snap[202]; p.PPID != 201 || p.CPU != 1.5 || p.RSS != 1024*1024 || p.Command != "go test ./..." {
+		t.Errorf("snap[202] = %+v", p)
+	}
+}
+
+func TestTree(t *testing.T) {
+	snap := Parse(psOutput)
+	tests := []struct {
+		root  int
+		cpu   float64
+		rss   int64
+		procs int
+	}{
+		{200, 61.5, (409600 + 10240 + 1024) * 1024, 3},
+		{300, 0, 1024 * 1024, 1},
+		{999, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		u := snap.Tree(tt.root)
+		if u.CPU != tt.cpu || u.RSS != tt.rss || u.Procs != tt.procs {
+			t.Errorf("Tree(%d) = %+v, want cpu %v rss %d procs %d", tt.root, u, tt.cpu, tt.rss, tt.procs)
+		}
+	}
+}
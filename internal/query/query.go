@@ -0,0 +1,654 @@
+// Package query parses and evaluates the issue query language used to
+// select beads across rigs:
+//
+//	priority<=1 and rig in (gastown, greenplace) and label:infra and not blocked
+//
+// A query combines conditions with and, or, not, and parentheses. A
+// condition compares a field with =, !=, <, <=, >, >=, or ~ (contains),
+// tests membership with in (...), or is a bare predicate. field:value is
+// shorthand for field=value.
+//
+// Fields: id, title, status, type, priority (0-4 or P0-P4), rig ("town"
+// for town beads), assignee, label, created, and updated. Any other name
+// is a custom field (see 'gt field'). Dates compare against YYYY-MM-DD or
+// a duration like 7d meaning that long ago, so "updated<7d" is stale work.
+//
+// Predicates: blocked, ready (open and unblocked), assigned, and closed.
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+// Record is what a query is evaluated against: an issue and the rig (or
+// "town") it came from.
+type Record struct {
+	Rig   string
+	Issue *beads.Issue
+}
+
+// Query is a parsed query.
+type Query struct {
+	src  string
+	root node
+}
+
+// Parse parses a query, resolving relative dates against the current time.
+func Parse(src string) (*Query, error) {
+	return ParseAt(src, time.Now())
+}
+
+// ParseAt parses a query, resolving relative dates against now.
+func ParseAt(src string, now time.Time) (*Query, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks, now: now}
+	if p.peek().kind == tokEOF {
+		return nil, fmt.Errorf("empty query")
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos+1)
+	}
+	return &Query{src: src, root: root}, nil
+}
+
+// String returns the query's source text.
+func (q *Query) String() string {
+	return q.src
+}
+
+// Match reports whether a record satisfies the query. A nil query
+// matches everything.
+func (q *Query) Match(r Record) bool {
+	if q == nil {
+		return true
+	}
+	return q.root.eval(r)
+}
+
+// Filter returns the issues from one rig that satisfy the query.
+func (q *Query) Filter(rig string, issues []*beads.Issue) []*beads.Issue {
+	if q == nil {
+		return issues
+	}
+	var matched []*beads.Issue
+	for _, issue := range issues {
+		if q.Match(Record{Rig: rig, Issue: issue}) {
+			matched = append(matched, issue)
+		}
+	}
+	return matched
+}
+
+// Rigs returns the rigs the query is limited to by rig conditions that
+// every match must satisfy, or nil if any rig may match. Callers use it
+// to skip querying rigs that can't match.
+func (q *Query) Rigs() []string {
+	if q == nil {
+		return nil
+	}
+	return requiredRigs(q.root)
+}
+
+// Covers reports whether records from a rig could match the query.
+func (q *Query) Covers(rig string) bool {
+	rigs := q.Rigs()
+	if rigs == nil {
+		return true
+	}
+	for _, r := range rigs {
+		if strings.EqualFold(r, rig) {
+			return true
+		}
+	}
+	return false
+}
+
+// requiredRigs walks the top-level conjunction for rig = or rig in.
+func requiredRigs(n node) []string {
+	switch n := n.(type) {
+	case *andNode:
+		if rigs := requiredRigs(n.left); rigs != nil {
+			return rigs
+		}
+		return requiredRigs(n.right)
+	case *cmpNode:
+		if n.field == "rig" && n.op == "=" {
+			return []string{n.values[0].text}
+		}
+		if n.field == "rig" && n.op == "in" {
+			rigs := make([]string, len(n.values))
+			for i, v := range n.values {
+				rigs[i] = v.text
+			}
+			return rigs
+		}
+	}
+	return nil
+}
+
+// Lexer
+
+type tokKind int
+
+const (
+	tokEOF tokKind = iota
+	tokWord
+	tokString
+	tokOp
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+type token struct {
+	kind tokKind
+	text string
+	pos  int
+}
+
+// isWordRune reports whether r can appear in a bare word. Colons and
+// slashes are allowed so labels ("gt:bug") and agents ("gastown/crew/max")
+// need no quotes.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_-.:/@#*+", r)
+}
+
+func lex(src string) ([]token, error) {
+	var toks []token
+	rs := []rune(src)
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			toks = append(toks, token{tokLParen, "(", i})
+			i++
+		case r == ')':
+			toks = append(toks, token{tokRParen, ")", i})
+			i++
+		case r == ',':
+			toks = append(toks, token{tokComma, ",", i})
+			i++
+		case r == '"' || r == '\'':
+			start := i
+			i++
+			var sb strings.Builder
+			for i < len(rs) && rs[i] != r {
+				if rs[i] == '\\' && i+1 < len(rs) {
+					i++
+				}
+				sb.WriteRune(rs[i])
+				i++
+			}
+			if i >= len(rs) {
+				return nil, fmt.Errorf("unterminated string at position %d", start+1)
+			}
+			i++
+			toks = append(toks, token{tokString, sb.String(), start})
+		case strings.ContainsRune("=!<>~", r):
+			start := i
+			op := string(r)
+			if i+1 < len(rs) && rs[i+1] == '=' && r != '=' && r != '~' {
+				op += "="
+			}
+			if op == "!" {
+				return nil, fmt.Errorf("unexpected '!' at position %d (use != or not)", start+1)
+			}
+			i += len(op)
+			toks = append(toks, token{tokOp, op, start})
+		case isWordRune(r):
+			start := i
+			for i < len(rs) && isWordRune(rs[i]) {
+				i++
+			}
+			toks = append(toks, token{tokWord, string(rs[start:i]), start})
+		default:
+			return nil, fmt.Errorf("unexpected %q at position %d", r, i+1)
+		}
+	}
+	return append(toks, token{tokEOF, "", len(rs)}), nil
+}
+
+// Parser
+
+type parser struct {
+	toks []token
+	i    int
+	now  time.Time
+}
+
+func (p *parser) peek() token { return p.toks[p.i] }
+
+func (p *parser) next() token {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+// keyword reports whether the next token is the given keyword.
+func (p *parser) keyword(kw string) bool {
+	t := p.peek()
+	return t.kind == tokWord && strings.EqualFold(t.text, kw)
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.keyword("not") {
+		p.next()
+		inner, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{inner}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokLParen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if c := p.next(); c.kind != tokRParen {
+			return nil, fmt.Errorf("expected ')' at position %d", c.pos+1)
+		}
+		return inner, nil
+	case tokWord:
+	case tokEOF:
+		return nil, fmt.Errorf("query ends early")
+	default:
+		return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos+1)
+	}
+
+	field := strings.ToLower(t.text)
+	switch n := p.peek(); {
+	case n.kind == tokOp:
+		p.next()
+		v := p.next()
+		if v.kind != tokWord && v.kind != tokString {
+			return nil, fmt.Errorf("expected a value after %s at position %d", n.text, v.pos+1)
+		}
+		return p.comparison(field, n.text, []token{v})
+	case n.kind == tokWord && strings.EqualFold(n.text, "in"):
+		p.next()
+		values, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		return p.comparison(field, "in", values)
+	}
+
+	// field:value shorthand, split at the first colon.
+	if name, value, ok := strings.Cut(t.text, ":"); ok && name != "" && value != "" {
+		return p.comparison(strings.ToLower(name), "=", []token{{tokWord, value, t.pos + len(name) + 1}})
+	}
+	switch field {
+	case "blocked", "ready", "assigned", "closed":
+		return &predNode{field}, nil
+	}
+	return nil, fmt.Errorf("unknown predicate %q at position %d (want blocked, ready, assigned, closed, or a comparison)", t.text, t.pos+1)
+}
+
+func (p *parser) parseList() ([]token, error) {
+	if t := p.next(); t.kind != tokLParen {
+		return nil, fmt.Errorf("expected '(' after in at position %d", t.pos+1)
+	}
+	var values []token
+	for {
+		v := p.next()
+		if v.kind != tokWord && v.kind != tokString {
+			return nil, fmt.Errorf("expected a value at position %d", v.pos+1)
+		}
+		values = append(values, v)
+		switch sep := p.next(); sep.kind {
+		case tokComma:
+			continue
+		case tokRParen:
+			return values, nil
+		default:
+			return nil, fmt.Errorf("expected ',' or ')' at position %d", sep.pos+1)
+		}
+	}
+}
+
+// Field kinds decide how values compare.
+var fieldKinds = map[string]string{
+	"id":       "string",
+	"title":    "string",
+	"status":   "string",
+	"type":     "string",
+	"rig":      "string",
+	"assignee": "string",
+	"label":    "label",
+	"priority": "number",
+	"created":  "date",
+	"updated":  "date",
+}
+
+func (p *parser) comparison(field, op string, toks []token) (node, error) {
+	kind, builtin := fieldKinds[field]
+	if !builtin {
+		kind = "custom"
+	}
+	n := &cmpNode{field: field, op: op, kind: kind}
+	ordered := op == "<" || op == "<=" || op == ">" || op == ">="
+	if ordered && (kind == "string" || kind == "label") {
+		return nil, fmt.Errorf("%s can't be compared with %s", field, op)
+	}
+	if op == "~" && (kind == "number" || kind == "date") {
+		return nil, fmt.Errorf("%s can't be compared with ~", field)
+	}
+	for _, t := range toks {
+		v := value{text: t.text}
+		switch kind {
+		case "number":
+			num, err := strconv.Atoi(strings.TrimPrefix(strings.ToUpper(t.text), "P"))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %q is not a priority", field, t.text)
+			}
+			v.num = float64(num)
+		case "date":
+			d, err := parseDate(t.text, p.now)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", field, err)
+			}
+			v.date = d
+		}
+		n.values = append(n.values, v)
+	}
+	return n, nil
+}
+
+// parseDate parses YYYY-MM-DD, an RFC 3339 time, or a duration ago
+// ("7d", "12h", "2w").
+func parseDate(s string, now time.Time) (time.Time, error) {
+	if t, err := time.ParseInLocation(time.DateOnly, s, now.Location()); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if len(s) > 1 {
+		if n, err := strconv.Atoi(s[:len(s)-1]); err == nil {
+			switch s[len(s)-1] {
+			case 'd':
+				return now.AddDate(0, 0, -n), nil
+			case 'w':
+				return now.AddDate(0, 0, -7*n), nil
+			}
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("%q is not a date (YYYY-MM-DD) or a duration ago (7d)", s)
+}
+
+// Evaluation
+
+type node interface {
+	eval(r Record) bool
+}
+
+type andNode struct{ left, right node }
+
+func (n *andNode) eval(r Record) bool { return n.left.eval(r) && n.right.eval(r) }
+
+type orNode struct{ left, right node }
+
+func (n *orNode) eval(r Record) bool { return n.left.eval(r) || n.right.eval(r) }
+
+type notNode struct{ inner node }
+
+func (n *notNode) eval(r Record) bool { return !n.inner.eval(r) }
+
+type predNode struct{ name string }
+
+func (n *predNode) eval(r Record) bool {
+	issue := r.Issue
+	switch n.name {
+	case "blocked":
+		return isBlocked(issue)
+	case "ready":
+		return issue.Status == "open" && !isBlocked(issue)
+	case "assigned":
+		return issue.Assignee != ""
+	case "closed":
+		return issue.Status == "closed"
+	}
+	return false
+}
+
+func isBlocked(issue *beads.Issue) bool {
+	return issue.Status == "blocked" || issue.BlockedByCount > 0 || len(issue.BlockedBy) > 0
+}
+
+type value struct {
+	text string
+	num  float64
+	date time.Time
+}
+
+type cmpNode struct {
+	field  string
+	op     string
+	kind   string
+	values []value
+}
+
+func (n *cmpNode) eval(r Record) bool {
+	if n.kind == "label" {
+		return n.evalLabel(r.Issue.Labels)
+	}
+	have, ok := n.fieldValue(r)
+	if n.op == "in" {
+		for _, v := range n.values {
+			if ok && n.equal(have, v) {
+				return true
+			}
+		}
+		return false
+	}
+	v := n.values[0]
+	if !ok {
+		return n.op == "!="
+	}
+	switch n.op {
+	case "=":
+		return n.equal(have, v)
+	case "!=":
+		return !n.equal(have, v)
+	case "~":
+		return strings.Contains(strings.ToLower(have.text), strings.ToLower(v.text))
+	}
+	c, ok := n.compare(have, v)
+	if !ok {
+		return false
+	}
+	switch n.op {
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	}
+	return false
+}
+
+// evalLabel handles label conditions: = and in test for a label, != for
+// its absence, and ~ for any label containing the text.
+func (n *cmpNode) evalLabel(labels []string) bool {
+	has := func(want string) bool {
+		for _, l := range labels {
+			if strings.EqualFold(l, want) {
+				return true
+			}
+		}
+		return false
+	}
+	switch n.op {
+	case "!=":
+		return !has(n.values[0].text)
+	case "~":
+		for _, l := range labels {
+			if strings.Contains(strings.ToLower(l), strings.ToLower(n.values[0].text)) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, v := range n.values {
+		if has(v.text) {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldValue extracts the record's value for the field; ok is false when
+// the record has none (an unset custom field or date).
+func (n *cmpNode) fieldValue(r Record) (value, bool) {
+	issue := r.Issue
+	switch n.field {
+	case "id":
+		return value{text: issue.ID}, true
+	case "title":
+		return value{text: issue.Title}, true
+	case "status":
+		return value{text: issue.Status}, true
+	case "type":
+		return value{text: issueType(issue)}, true
+	case "rig":
+		return value{text: r.Rig}, true
+	case "assignee":
+		return value{text: issue.Assignee}, true
+	case "priority":
+		return value{num: float64(issue.Priority)}, true
+	case "created", "updated":
+		s := issue.CreatedAt
+		if n.field == "updated" {
+			s = issue.UpdatedAt
+		}
+		t, err := time.Parse(time.RFC3339, s)
+		return value{date: t}, err == nil
+	}
+	fields := issue.Fields
+	if fields == nil {
+		fields = beads.CustomFields(issue.Labels)
+	}
+	text, ok := fields[n.field]
+	return value{text: text}, ok
+}
+
+// issueType prefers the issue's type, falling back to its gt:<type> label.
+func issueType(issue *beads.Issue) string {
+	if issue.Type != "" {
+		return issue.Type
+	}
+	for _, l := range issue.Labels {
+		if t, ok := strings.CutPrefix(l, "gt:"); ok {
+			return t
+		}
+	}
+	return ""
+}
+
+func (n *cmpNode) equal(have, want value) bool {
+	switch n.kind {
+	case "number":
+		return have.num == want.num
+	case "date":
+		y1, m1, d1 := have.date.Date()
+		y2, m2, d2 := want.date.In(have.date.Location()).Date()
+		return y1 == y2 && m1 == m2 && d1 == d2
+	}
+	if strings.EqualFold(have.text, want.text) {
+		return true
+	}
+	if n.kind == "custom" {
+		a, errA := strconv.ParseFloat(have.text, 64)
+		b, errB := strconv.ParseFloat(want.text, 64)
+		return errA == nil && errB == nil && a == b
+	}
+	return false
+}
+
+// compare orders two values; ok is false when they don't order (custom
+// fields that aren't both numbers).
+func (n *cmpNode) compare(have, want value) (int, bool) {
+	switch n.kind {
+	case "number":
+		return cmpFloat(have.num, want.num), true
+	case "date":
+		return have.date.Compare(want.date), true
+	}
+	a, errA := strconv.ParseFloat(have.text, 64)
+	b, errB := strconv.ParseFloat(want.text, 64)
+	if errA != nil || errB != nil {
+		return 0, false
+	}
+	return cmpFloat(a, b), true
+}
+
+func cmpFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
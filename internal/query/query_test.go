@@ -0,0 +1,130 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+var now = time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+
+var records = []Record{
+	{Rig: "gastown", Issue: &beads.Issue{ID: "gt-1", Title: "Fix login redirect", Status: "open", Type: "bug", Priority: 1,
+		Labels: []string{"gt:bug", "infra", "field:customer=acme", "field:cost=3"}, UpdatedAt: "2026-03-01T10:00:00Z", CreatedAt: "2026-02-20T10:00:00Z"}},
+	{Rig: "greenplace", Issue: &beads.Issue{ID: "gp-1", Title: "Add dark mode", Status: "open", Priority: 2, Labels: []string{"gt:feature", "infra"},
+		BlockedByCount: 1, UpdatedAt: "2026-03-19T10:00:00Z", CreatedAt: "2026-03-10T10:00:00Z"}},
+	{Rig: "beads", Issue: &beads.Issue{ID: "bd-1", Title: "Speed up sync", Status: "in_progress", Type: "task", Priority: 0,
+		Assignee: "beads/polecats/Toast", UpdatedAt: "2026-03-18T10:00:00Z", CreatedAt: "2026-03-01T10:00:00Z"}},
+	{Rig: "town", Issue: &beads.Issue{ID: "hq-1", Title: "Quarterly review", Status: "closed", Type: "task", Priority: 3}},
+}
+
+func matching(t *testing.T, src string) []string {
+	t.Helper()
+	q, err := ParseAt(src, now)
+	if err != nil {
+		t.Fatalf("ParseAt(%q): %v", src, err)
+	}
+	var ids []string
+	for _, r := range records {
+		if q.Match(r) {
+			ids = append(ids, r.Issue.ID)
+		}
+	}
+	return ids
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"priority<=1 and rig in (gastown, greenplace) and label:infra and not blocked", []string{"gt-1"}},
+		{"priority <= P1", []string{"gt-1", "bd-1"}},
+		{"priority>1", []string{"gp-1", "hq-1"}},
+		{"label:infra", []string{"gt-1", "gp-1"}},
+		{"label != infra", []string{"bd-1", "hq-1"}},
+		{"label ~ feat", []string{"gp-1"}},
+		{"type=bug or type:feature", []string{"gt-1", "gp-1"}},
+		{"blocked", []string{"gp-1"}},
+		{"ready", []string{"gt-1"}},
+		{"assigned", []string{"bd-1"}},
+		{"closed or status=in_progress", []string{"bd-1", "hq-1"}},
+		{"not (closed or assigned)", []string{"gt-1", "gp-1"}},
+		{`title ~ "dark"`, []string{"gp-1"}},
+		{"assignee=beads/polecats/Toast", []string{"bd-1"}},
+		{"rig=TOWN", []string{"hq-1"}},
+		{"customer=acme", []string{"gt-1"}},
+		{"customer!=acme", []string{"gp-1", "bd-1", "hq-1"}},
+		{"cost>=3.0", []string{"gt-1"}},
+		{"updated<7d", []string{"gt-1"}},
+		{"created>=2026-03-01 and created<2026-03-11", []string{"gp-1", "bd-1"}},
+		{"created=2026-03-10", []string{"gp-1"}},
+		{"status = 'open' and not label:infra", []string{}},
+	}
+	for _, tt := range tests {
+		got := matching(t, tt.query)
+		if len(got) == 0 && len(tt.want) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q matched %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	bad := []string{
+		"",
+		"priority<=",
+		"priority<=high",
+		"title<b",
+		"label>infra",
+		"priority~1",
+		"(blocked",
+		"blocked)",
+		"rig in gastown",
+		"rig in (gastown greenplace)",
+		"stale",
+		`title="unterminated`,
+		"!blocked",
+		"updated<soon",
+		"blocked and",
+	}
+	for _, src := range bad {
+		if _, err := ParseAt(src, now); err == nil {
+			t.Errorf("ParseAt(%q) = nil error", src)
+		}
+	}
+}
+
+func TestRigs(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"rig in (gastown, greenplace) and blocked", []string{"gastown", "greenplace"}},
+		{"priority<2 and rig:beads", []string{"beads"}},
+		{"rig=gastown or rig=beads", nil},
+		{"not rig=gastown", nil},
+		{"blocked", nil},
+	}
+	for _, tt := range tests {
+		q, err := ParseAt(tt.query, now)
+		if err != nil {
+			t.Fatalf("ParseAt(%q): %v", tt.query, err)
+		}
+		if got := q.Rigs(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Rigs(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+	q, _ := ParseAt("rig:gastown", now)
+	if !q.Covers("GASTOWN") || q.Covers("beads") {
+		t.Error("Covers wrong for rig:gastown")
+	}
+	var none *Query
+	if !none.Covers("beads") || !none.Match(records[0]) || len(none.Filter("x", []*beads.Issue{records[0].Issue})) != 1 {
+		t.Error("nil query should match everything")
+	}
+}
@@ -0,0 +1,276 @@
+// Package ratelimit provides the town-wide token-bucket limiter that keeps
+// parallel agents from collectively exceeding a provider's rate limits.
+//
+// The buckets live in the daemon directory and are shared through a file
+// lock, so every agent in the town draws from the same budget. Agents take
+// a token per request (via a PreToolUse hook running 'gt ratelimit
+// acquire'); when the bucket is empty they wait, which is the backpressure.
+// The daemon prunes idle agents and logs throttling each heartbeat.
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// DefaultMaxWait is how long a request waits for a token when the provider's
+// config doesn't say.
+const DefaultMaxWait = 30 * time.Second
+
+// State is the shared limiter state, one bucket per provider.
+type State struct {
+	Buckets map[string]*Bucket `json:"buckets,omitempty"`
+}
+
+// Bucket is one provider's token bucket and its usage counters.
+type Bucket struct {
+	Tokens    float64                `json:"tokens"`
+	UpdatedAt time.Time              `json:"updated_at"`
+	Granted   int64                  `json:"granted"`
+	Throttled int64                  `json:"throttled"`
+	WaitedMs  int64                  `json:"waited_ms"`
+	Agents    map[string]*AgentUsage `json:"agents,omitempty"`
+}
+
+// AgentUsage counts one agent's draws on a bucket.
+type AgentUsage struct {
+	Granted   int64     `json:"granted"`
+	Throttled int64     `json:"throttled"`
+	WaitedMs  int64     `json:"waited_ms"`
+	LastAt    time.Time `json:"last_at"`
+}
+
+// Result describes one Acquire.
+type Result struct {
+	Limited  bool          `json:"limited"`   // the provider has a limit configured
+	Waited   time.Duration `json:"waited"`    // time spent waiting for a token
+	TimedOut bool          `json:"timed_out"` // gave up waiting and let the request through
+}
+
+// StatePath returns the path to the town's limiter state.
+func StatePath(townRoot string) string {
+	return filepath.Join(townRoot, "daemon", "ratelimit.json")
+}
+
+func lockPath(townRoot string) string {
+	return filepath.Join(townRoot, "daemon", "ratelimit.lock")
+}
+
+// Load reads the limiter state. A missing file is an empty state.
+func Load(townRoot string) (*State, error) {
+	state := &State{}
+	data, err := os.ReadFile(StatePath(townRoot)) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("parsing limiter state: %w", err)
+	}
+	return state, nil
+}
+
+// Update loads the state under the town's limiter lock, applies fn, and
+// saves the result.
+func Update(townRoot string, fn func(*State) error) error {
+	if err := os.MkdirAll(filepath.Dir(lockPath(townRoot)), 0755); err != nil {
+		return err
+	}
+	fl := flock.New(lockPath(townRoot))
+	if err := fl.Lock(); err != nil {
+		return fmt.Errorf("locking limiter state: %w", err)
+	}
+	defer func() { _ = fl.Unlock() }()
+
+	state, err := Load(townRoot)
+	if err != nil {
+		return err
+	}
+	if err := fn(state); err != nil {
+		return err
+	}
+	return util.AtomicWriteJSON(StatePath(townRoot), state)
+}
+
+// burst returns the bucket size for cfg.
+func burst(cfg *config.RateLimitConfig) float64 {
+	if cfg.Burst > 0 {
+		return float64(cfg.Burst)
+	}
+	return math.Max(1, float64(cfg.RequestsPerMinute/4))
+}
+
+// maxWait returns how long a request may wait under cfg.
+func maxWait(cfg *config.RateLimitConfig) time.Duration {
+	if cfg.MaxWait != "" {
+		if d, err := time.ParseDuration(cfg.MaxWait); err == nil {
+			return d
+		}
+	}
+	return DefaultMaxWait
+}
+
+// refill adds the tokens earned since the bucket was last updated.
+func (b *Bucket) refill(cfg *config.RateLimitConfig, now time.Time) {
+	size := burst(cfg)
+	if b.UpdatedAt.IsZero() {
+		b.Tokens = size
+	} else if elapsed := now.Sub(b.UpdatedAt); elapsed > 0 {
+		b.Tokens = math.Min(size, b.Tokens+elapsed.Minutes()*float64(cfg.RequestsPerMinute))
+	}
+	b.UpdatedAt = now
+}
+
+// take removes a token if one is available. Otherwise it returns how long
+// until the next token.
+func (b *Bucket) take(cfg *config.RateLimitConfig, now time.Time) (bool, time.Duration) {
+	b.refill(cfg, now)
+	if b.Tokens >= 1 {
+		b.Tokens--
+		return true, 0
+	}
+	perToken := time.Duration(float64(time.Minute) / float64(cfg.RequestsPerMinute))
+	return false, time.Duration((1 - b.Tokens) * float64(perToken))
+}
+
+// record counts a finished Acquire against the bucket and agent.
+func (b *Bucket) record(agent string, waited time.Duration, throttled bool, now time.Time) {
+	b.Granted++
+	b.WaitedMs += waited.Milliseconds()
+	if throttled {
+		b.Throttled++
+	}
+	if agent == "" {
+		return
+	}
+	if b.Agents == nil {
+		b.Agents = make(map[string]*AgentUsage)
+	}
+	u := b.Agents[agent]
+	if u == nil {
+		u = &AgentUsage{}
+		b.Agents[agent] = u
+	}
+	u.Granted++
+	u.WaitedMs += waited.Milliseconds()
+	if throttled {
+		u.Throttled++
+	}
+	u.LastAt = now
+}
+
+// Prune drops agents idle longer than maxAge. It returns how many it dropped.
+func (s *State) Prune(now time.Time, maxAge time.Duration) int {
+	var pruned int
+	for _, b := range s.Buckets {
+		for agent, u := range b.Agents {
+			if now.Sub(u.LastAt) > maxAge {
+				delete(b.Agents, agent)
+				pruned++
+			}
+		}
+	}
+	return pruned
+}
+
+// Limiter draws tokens from the town's shared buckets.
+type Limiter struct {
+	townRoot string
+	limits   map[string]*config.RateLimitConfig
+	now      func() time.Time
+}
+
+// New returns a limiter for townRoot with the given per-provider limits.
+func New(townRoot string, limits map[string]*config.RateLimitConfig) *Limiter {
+	return &Limiter{townRoot: townRoot, limits: limits, now: time.Now}
+}
+
+// Acquire takes a token from provider's bucket on behalf of agent, waiting
+// while the bucket is empty. It never blocks past the provider's max wait:
+// the request is let through and counted as throttled instead, so a stuck
+// limiter can't wedge an agent. Providers without a limit return at once.
+func (l *Limiter) Acquire(ctx context.Context, provider, agent string) (Result, error) {
+	cfg := l.limits[provider]
+	if cfg == nil || cfg.RequestsPerMinute <= 0 {
+		return Result{}, nil
+	}
+	res := Result{Limited: true}
+	start := l.now()
+	deadline := start.Add(maxWait(cfg))
+
+	for waited := false; ; waited = true {
+		var ok bool
+		var wait time.Duration
+		err := Update(l.townRoot, func(s *State) error {
+			if s.Buckets == nil {
+				s.Buckets = make(map[string]*Bucket)
+			}
+			b := s.Buckets[provider]
+			if b == nil {
+				b = &Bucket{}
+				s.Buckets[provider] = b
+			}
+			now := l.now()
+			ok, wait = b.take(cfg, now)
+			if !ok && !now.Before(deadline) {
+				// Out of patience: let it through and record the throttle
+				ok, res.TimedOut = true, true
+			}
+			if ok {
+				if waited {
+					res.Waited = now.Sub(start)
+				}
+				b.record(agent, res.Waited, waited, now)
+			}
+			return nil
+		})
+		if err != nil || ok {
+			return res, err
+		}
+		if remaining := deadline.Sub(l.now()); wait > remaining {
+			wait = max(remaining, 0)
+		}
+
+		select {
+		case <-ctx.Done():
+			return res, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// Available returns the tokens in the bucket as of now, without taking one.
+func (b *Bucket) Available(cfg *config.RateLimitConfig, now time.Time) float64 {
+	view := *b
+	view.refill(cfg, now)
+	return view.Tokens
+}
+
+// AgentTotals sums each agent's usage across providers.
+func (s *State) AgentTotals() map[string]AgentUsage {
+	totals := make(map[string]AgentUsage)
+	for _, b := range s.Buckets {
+		for agent, u := range b.Agents {
+			t := totals[agent]
+			t.Granted += u.Granted
+			t.Throttled += u.Throttled
+			t.WaitedMs += u.WaitedMs
+			if u.LastAt.After(t.LastAt) {
+				t.LastAt = u.LastAt
+			}
+			totals[agent] = t
+		}
+	}
+	return totals
+}
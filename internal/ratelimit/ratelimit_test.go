@@ -0,0 +1,116 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func TestBucketTake(t *testing.T) {
+	cfg := &config.RateLimitConfig{RequestsPerMinute: 60, Burst: 2}
+	start := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	b := &Bucket{}
+
+	tests := []struct {
+		name     string
+		at       time.Duration
+		wantOK   bool
+		wantWait time.Duration
+	}{
+		{"full bucket", 0, true, 0},
+		{"second of burst", 0, true, 0},
+		{"empty", 0, false, time.Second},
+		{"half refilled", 500 * time.Millisecond, false, 500 * time.Millisecond},
+		{"refilled", time.Second, true, 0},
+		{"refill capped at burst", time.Hour, true, 0},
+		{"burst left after cap", time.Hour, true, 0},
+		{"empty again", time.Hour, false, time.Second},
+	}
+
+	for _, tt := range tests {
+		ok, wait := b.take(cfg, start.Add(tt.at))
+		if ok != tt.wantOK || wait.Round(time.Millisecond) != tt.wantWait {
+			t.Errorf("%s: take() = %v, %v; want %v, %v", tt.name, ok, wait, tt.wantOK, tt.wantWait)
+		}
+	}
+}
+
+func TestBurstDefault(t *testing.T) {
+	tests := []struct {
+		cfg  config.RateLimitConfig
+		want float64
+	}{
+		{config.RateLimitConfig{RequestsPerMinute: 120}, 30},
+		{config.RateLimitConfig{RequestsPerMinute: 2}, 1},
+		{config.RateLimitConfig{RequestsPerMinute: 120, Burst: 5}, 5},
+	}
+	for _, tt := range tests {
+		if got := burst(&tt.cfg); got != tt.want {
+			t.Errorf("burst(%+v) = %v, want %v", tt.cfg, got, tt.want)
+		}
+	}
+}
+
+func TestAcquire(t *testing.T) {
+	townRoot := t.TempDir()
+	limits := map[string]*config.RateLimitConfig{
+		// 10ms per token keeps the waits short
+		"claude": {RequestsPerMinute: 6000, Burst: 1, MaxWait: "1s"},
+		"codex":  {RequestsPerMinute: 1, Burst: 1, MaxWait: "20ms"},
+	}
+	l := New(townRoot, limits)
+	ctx := context.Background()
+
+	res, err := l.Acquire(ctx, "claude", "gt-a")
+	if err != nil || !res.Limited || res.Waited != 0 {
+		t.Fatalf("first Acquire = %+v, %v; want an immediate grant", res, err)
+	}
+	res, err = l.Acquire(ctx, "claude", "gt-b")
+	if err != nil || res.Waited == 0 || res.TimedOut {
+		t.Fatalf("second Acquire = %+v, %v; want a short wait", res, err)
+	}
+
+	// codex refills once a minute: the second request gives up at max_wait
+	if _, err := l.Acquire(ctx, "codex", "gt-a"); err != nil {
+		t.Fatal(err)
+	}
+	res, err = l.Acquire(ctx, "codex", "gt-a")
+	if err != nil || !res.TimedOut {
+		t.Fatalf("codex Acquire = %+v, %v; want a timeout", res, err)
+	}
+
+	// Unlimited providers pass straight through
+	if res, err := l.Acquire(ctx, "gemini", "gt-a"); err != nil || res.Limited {
+		t.Errorf("unlimited Acquire = %+v, %v", res, err)
+	}
+
+	state, err := Load(townRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := state.Buckets["claude"]; b.Granted != 2 || b.Throttled != 1 || b.Agents["gt-b"].Throttled != 1 {
+		t.Errorf("claude bucket = %+v", b)
+	}
+	totals := state.AgentTotals()
+	if got := totals["gt-a"]; got.Granted != 3 || got.Throttled != 1 {
+		t.Errorf("gt-a totals = %+v, want 3 granted, 1 throttled", got)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	now := time.Now()
+	s := &State{Buckets: map[string]*Bucket{
+		"claude": {Agents: map[string]*AgentUsage{
+			"active": {LastAt: now.Add(-time.Minute)},
+			"idle":   {LastAt: now.Add(-2 * time.Hour)},
+		}},
+	}}
+	if pruned := s.Prune(now, time.Hour); pruned != 1 {
+		t.Errorf("Prune() = %d, want 1", pruned)
+	}
+	if _, ok := s.Buckets["claude"].Agents["active"]; !ok {
+		t.Error("active agent was pruned")
+	}
+}
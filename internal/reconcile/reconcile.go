@@ -0,0 +1,224 @@
+// Package reconcile repairs state left behind when agents or processes
+// crash: agent beads still marked running, merge requests still claimed by a
+// dead refinery, merge slots held by dead agents, and identity locks whose
+// owner is gone. The daemon runs it on start and gt doctor --fix runs it on
+// demand; every repair is recorded in the event log.
+package reconcile
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/lock"
+	"github.com/steveyegge/gastown/internal/session"
+)
+
+// Kinds of orphaned state.
+const (
+	KindAgentState = "agent_state" // Agent bead marked running, session gone
+	KindMRClaim    = "mr_claim"    // MR claimed by an agent that is gone
+	KindMergeSlot  = "merge_slot"  // Merge slot held by an agent that is gone
+	KindLock       = "stale_lock"  // Identity lock whose process and session are gone
+)
+
+// runningStates are agent_state values that claim a live session.
+var runningStates = map[string]bool{
+	"spawning": true,
+	"working":  true,
+	"running":  true,
+}
+
+// Repair is one piece of orphaned state and what reconciling it does.
+type Repair struct {
+	Kind   string `json:"kind"`
+	Target string `json:"target"`           // Bead ID or lock path
+	Holder string `json:"holder,omitempty"` // Dead agent the state belonged to
+	Dir    string `json:"-"`                // Beads or worker directory to act in
+	Action string `json:"action"`
+	Err    string `json:"error,omitempty"`
+}
+
+func (r Repair) String() string {
+	s := fmt.Sprintf("%s %s: %s", r.Kind, r.Target, r.Action)
+	if r.Holder != "" {
+		s += " (held by " + r.Holder + ")"
+	}
+	return s
+}
+
+// Options configures a reconciliation.
+type Options struct {
+	TownRoot string
+	Rigs     []string
+
+	// Alive reports whether an agent's tmux session is running its agent.
+	Alive func(session string) bool
+}
+
+// Scan finds orphaned state without changing anything.
+func Scan(opts Options) []Repair {
+	var repairs []Repair
+
+	dirs := []string{beads.GetTownBeadsPath(opts.TownRoot)}
+	for _, rigName := range opts.Rigs {
+		dirs = append(dirs, filepath.Join(opts.TownRoot, rigName, "mayor", "rig"))
+	}
+	for i, dir := range dirs {
+		b := beads.New(dir)
+		if agents, err := b.List(beads.ListOptions{Label: "gt:agent", Priority: -1}); err == nil {
+			repairs = append(repairs, deadAgents(dir, agents, opts.Alive)...)
+		}
+		if i == 0 {
+			continue // Merge queues live in rig beads
+		}
+		for _, status := range []string{"open", "in_progress"} {
+			if mrs, err := b.List(beads.ListOptions{Status: status, Label: "gt:merge-request", Priority: -1}); err == nil {
+				repairs = append(repairs, deadMRClaims(dir, mrs, opts.Alive)...)
+			}
+		}
+		if slot, err := b.MergeSlotCheck(); err == nil {
+			if r, ok := deadSlotHolder(dir, slot, opts.Alive); ok {
+				repairs = append(repairs, r)
+			}
+		}
+	}
+
+	if locks, err := lock.FindAllLocks(opts.TownRoot); err == nil {
+		repairs = append(repairs, staleLocks(locks, opts.Alive)...)
+	}
+	return repairs
+}
+
+// Apply makes each repair, recording it in the event log, and returns the
+// repairs with any failures filled in.
+func Apply(repairs []Repair, actor string) []Repair {
+	for i := range repairs {
+		r := &repairs[i]
+		if err := apply(*r); err != nil {
+			r.Err = err.Error()
+			continue
+		}
+		_ = events.LogFeed(events.TypeReconciled, actor, events.ReconcilePayload(r.Kind, r.Target, r.Holder, r.Action))
+	}
+	return repairs
+}
+
+// Run scans for orphaned state and repairs it.
+func Run(opts Options, actor string) []Repair {
+	return Apply(Scan(opts), actor)
+}
+
+func apply(r Repair) error {
+	switch r.Kind {
+	case KindAgentState:
+		return beads.New(r.Dir).UpdateAgentState(r.Target, "dead", nil)
+	case KindMRClaim:
+		open, none := "open", ""
+		return beads.New(r.Dir).Update(r.Target, beads.UpdateOptions{Status: &open, Assignee: &none})
+	case KindMergeSlot:
+		return beads.New(r.Dir).MergeSlotRelease(r.Holder)
+	case KindLock:
+		return lock.New(r.Dir).Release()
+	}
+	return fmt.Errorf("unknown repair kind %q", r.Kind)
+}
+
+// sessionFor returns the tmux session of the agent at address, or "" if
+// the address isn't an agent with a session.
+func sessionFor(address string) string {
+	id, err := session.ParseAddress(address)
+	if err != nil {
+		return ""
+	}
+	return id.SessionName()
+}
+
+// deadAgents finds agent beads marked running whose session is gone.
+func deadAgents(dir string, agents []*beads.Issue, alive func(string) bool) []Repair {
+	var repairs []Repair
+	for _, a := range agents {
+		if !runningStates[a.AgentState] {
+			continue
+		}
+		rig, role, name, ok := beads.ParseAgentBeadID(a.ID)
+		if !ok {
+			continue
+		}
+		id := session.AgentIdentity{Role: session.Role(role), Rig: rig, Name: name}
+		sessionName := id.SessionName()
+		if sessionName == "" || alive(sessionName) {
+			continue
+		}
+		repairs = append(repairs, Repair{
+			Kind:   KindAgentState,
+			Target: a.ID,
+			Dir:    dir,
+			Action: fmt.Sprintf("mark dead (was %s, %s not running)", a.AgentState, sessionName),
+		})
+	}
+	return repairs
+}
+
+// deadMRClaims finds merge requests still claimed by an agent that is gone,
+// typically a refinery that crashed mid-merge.
+func deadMRClaims(dir string, mrs []*beads.Issue, alive func(string) bool) []Repair {
+	var repairs []Repair
+	for _, mr := range mrs {
+		if mr.Assignee == "" {
+			continue
+		}
+		sessionName := sessionFor(mr.Assignee)
+		if sessionName == "" || alive(sessionName) {
+			continue
+		}
+		repairs = append(repairs, Repair{
+			Kind:   KindMRClaim,
+			Target: mr.ID,
+			Holder: mr.Assignee,
+			Dir:    dir,
+			Action: "release back to the queue",
+		})
+	}
+	return repairs
+}
+
+// deadSlotHolder reports a merge slot held by an agent that is gone.
+func deadSlotHolder(dir string, slot *beads.MergeSlotStatus, alive func(string) bool) (Repair, bool) {
+	if slot == nil || slot.Available || slot.Holder == "" {
+		return Repair{}, false
+	}
+	sessionName := sessionFor(slot.Holder)
+	if sessionName == "" || alive(sessionName) {
+		return Repair{}, false
+	}
+	return Repair{
+		Kind:   KindMergeSlot,
+		Target: slot.ID,
+		Holder: slot.Holder,
+		Dir:    dir,
+		Action: "release",
+	}, true
+}
+
+// staleLocks finds identity locks whose process is dead and whose session
+// isn't running.
+func staleLocks(locks map[string]*lock.LockInfo, alive func(string) bool) []Repair {
+	var repairs []Repair
+	for workerDir, info := range locks {
+		if !info.IsStale() || (info.SessionID != "" && alive(info.SessionID)) {
+			continue
+		}
+		repairs = append(repairs, Repair{
+			Kind:   KindLock,
+			Target: filepath.Join(workerDir, ".runtime", "agent.lock"),
+			Holder: info.SessionID,
+			Dir:    workerDir,
+			Action: fmt.Sprintf("remove (PID %d is gone)", info.PID),
+		})
+	}
+	sort.Slice(repairs, func(i, j int) bool { return repairs[i].Target < repairs[j].Target })
+	return repairs
+}
@@ -0,0 +1,90 @@
+package reconcile
+
+import (
+	"os"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/lock"
+)
+
+// aliveOnly returns an Alive func reporting only the given sessions alive.
+func aliveOnly(sessions ...string) func(string) bool {
+	set := make(map[string]bool)
+	for _, s := range sessions {
+		set[s] = true
+	}
+	return func(s string) bool { return set[s] }
+}
+
+func TestDeadAgents(t *testing.T) {
+	agents := []*beads.Issue{
+		{ID: "gt-gastown-polecat-Toast", AgentState: "working"}, // session gone
+		{ID: "gt-gastown-polecat-Nux", AgentState: "working"},   // alive
+		{ID: "gt-gastown-polecat-Slit", AgentState: "done"},     // not claiming a session
+		{ID: "gt-gastown-witness", AgentState: "running"},       // session gone
+		{ID: "hq-mayor", AgentState: "spawning"},                // alive
+		{ID: "not-an-agent-id-at-all-really", AgentState: "working"},
+	}
+	got := deadAgents("/beads", agents, aliveOnly("gt-gastown-Nux", "hq-mayor"))
+
+	want := map[string]bool{"gt-gastown-polecat-Toast": true, "gt-gastown-witness": true}
+	if len(got) != len(want) {
+		t.Fatalf("deadAgents() = %v, want repairs for %v", got, want)
+	}
+	for _, r := range got {
+		if !want[r.Target] || r.Kind != KindAgentState || r.Dir != "/beads" {
+			t.Errorf("unexpected repair %+v", r)
+		}
+	}
+}
+
+func TestDeadMRClaims(t *testing.T) {
+	mrs := []*beads.Issue{
+		{ID: "gt-mr1", Assignee: "gastown/refinery"},
+		{ID: "gt-mr2"},
+		{ID: "gt-mr3", Assignee: "beads/refinery"},
+	}
+	got := deadMRClaims("/beads", mrs, aliveOnly("gt-beads-refinery"))
+	if len(got) != 1 || got[0].Target != "gt-mr1" || got[0].Holder != "gastown/refinery" {
+		t.Errorf("deadMRClaims() = %+v, want only gt-mr1 held by gastown/refinery", got)
+	}
+}
+
+func TestDeadSlotHolder(t *testing.T) {
+	tests := []struct {
+		name string
+		slot *beads.MergeSlotStatus
+		want bool
+	}{
+		{"available", &beads.MergeSlotStatus{ID: "gt-slot", Available: true}, false},
+		{"held by live refinery", &beads.MergeSlotStatus{ID: "gt-slot", Holder: "gastown/refinery"}, false},
+		{"held by dead polecat", &beads.MergeSlotStatus{ID: "gt-slot", Holder: "gastown/polecats/Toast"}, true},
+		{"no slot", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, ok := deadSlotHolder("/beads", tt.slot, aliveOnly("gt-gastown-refinery"))
+			if ok != tt.want {
+				t.Fatalf("deadSlotHolder() ok = %v, want %v", ok, tt.want)
+			}
+			if ok && (r.Kind != KindMergeSlot || r.Holder != tt.slot.Holder) {
+				t.Errorf("repair = %+v", r)
+			}
+		})
+	}
+}
+
+func TestStaleLocks(t *testing.T) {
+	live := os.Getpid()
+	dead := 99999999
+	locks := map[string]*lock.LockInfo{
+		"/town/gastown/polecats/Toast": {PID: dead, SessionID: "gt-gastown-Toast"},
+		"/town/gastown/polecats/Nux":   {PID: dead, SessionID: "gt-gastown-Nux"}, // session still up
+		"/town/gastown/crew/max":       {PID: live, SessionID: "gt-gastown-crew-max"},
+	}
+	got := staleLocks(locks, aliveOnly("gt-gastown-Nux"))
+	if len(got) != 1 || got[0].Dir != "/town/gastown/polecats/Toast" {
+		t.Errorf("staleLocks() = %+v, want only Toast's lock", got)
+	}
+}
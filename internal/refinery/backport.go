@@ -0,0 +1,266 @@
+// Package refinery provides the merge queue processing agent.
+// This file backports merged MRs to release branches through the queue.
+
+package refinery
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+// BackportLabel marks backport beads and MRs.
+const BackportLabel = "backport"
+
+// Backport outcomes, per target.
+const (
+	BackportSubmitted = "submitted" // Picked cleanly and queued
+	BackportConflict  = "conflict"  // Needs a manual backport; a bead tracks it
+	BackportPresent   = "present"   // The change is already on the target
+)
+
+// errAlreadyPresent is returned when a pick changes nothing on the target.
+var errAlreadyPresent = errors.New("change is already on the target")
+
+// BackportOptions configures BackportMR.
+type BackportOptions struct {
+	Actor  string
+	DryRun bool
+}
+
+// BackportResult describes a backport of an MR to one target branch.
+type BackportResult struct {
+	MR             string   `json:"mr"`
+	SourceIssue    string   `json:"source_issue,omitempty"`
+	Target         string   `json:"target"`
+	Outcome        string   `json:"outcome,omitempty"`
+	Commit         string   `json:"commit"`
+	Branch         string   `json:"branch"`
+	BackportCommit string   `json:"backport_commit,omitempty"`
+	BackportBead   string   `json:"backport_bead,omitempty"`
+	BackportMR     string   `json:"backport_mr,omitempty"`
+	Conflicts      []string `json:"conflicts,omitempty"`
+	Resolved       string   `json:"resolved,omitempty"` // How trivial conflicts were resolved
+	DryRun         bool     `json:"dry_run,omitempty"`
+}
+
+// BackportMR cherry-picks a merged MR onto each target branch and files the
+// picks as MRs in those targets' queues. Each backport gets a bead linked
+// to the original issue and MR, which the backport MR closes when it lands.
+// Whitespace-only conflicts are resolved by picking again ignoring
+// whitespace; other conflicts file the bead without an MR, for a manual
+// backport.
+func (m *Manager) BackportMR(mrID string, targets []string, opts BackportOptions) ([]BackportResult, error) {
+	b := beads.New(m.rig.BeadsPath())
+	mr, err := b.Show(mrID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching merge request %s: %w", mrID, err)
+	}
+	fields := beads.ParseMRFields(mr)
+	if fields == nil {
+		return nil, fmt.Errorf("%s has no MR fields; is it a merge request?", mrID)
+	}
+	if mr.Status != "closed" || fields.MergeCommit == "" {
+		return nil, fmt.Errorf("%w: %s has no merge commit", ErrMRNotMerged, mrID)
+	}
+	var source *beads.Issue
+	if fields.SourceIssue != "" {
+		source, _ = b.Show(fields.SourceIssue)
+	}
+
+	g := git.NewGit(constants.RigMayorPath(m.rig.Path))
+	if err := g.Fetch("origin"); err != nil {
+		return nil, fmt.Errorf("fetching origin: %w", err)
+	}
+	commit, err := g.Rev(fields.MergeCommit + "^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("unknown merge commit %s: %w", fields.MergeCommit, err)
+	}
+	for _, target := range targets {
+		if target == fields.Target {
+			return nil, fmt.Errorf("%s already merged into %s", mrID, target)
+		}
+		if _, err := g.Rev("origin/" + target); err != nil {
+			return nil, fmt.Errorf("target branch %s does not exist on origin", target)
+		}
+	}
+
+	eng := NewEngineer(m.rig)
+	if err := eng.LoadConfig(); err != nil {
+		return nil, fmt.Errorf("loading merge queue config: %w", err)
+	}
+
+	var results []BackportResult
+	var notes []string
+	for _, target := range targets {
+		r := BackportResult{
+			MR:          mrID,
+			SourceIssue: fields.SourceIssue,
+			Target:      target,
+			Commit:      commit,
+			Branch:      "backport/" + target + "/" + mrID,
+			DryRun:      opts.DryRun,
+		}
+		if present, _ := g.IsAncestor(commit, "origin/"+target); present {
+			r.Outcome = BackportPresent
+			results = append(results, r)
+			continue
+		}
+		if opts.DryRun {
+			results = append(results, r)
+			continue
+		}
+
+		r.BackportCommit, r.Resolved, r.Conflicts, err = m.commitBackport(g, r.Branch, target, commit)
+		switch {
+		case errors.Is(err, errAlreadyPresent):
+			r.Outcome = BackportPresent
+			results = append(results, r)
+			continue
+		case err != nil:
+			return results, err
+		case len(r.Conflicts) > 0:
+			r.Outcome = BackportConflict
+		default:
+			r.Outcome = BackportSubmitted
+		}
+
+		if err := m.fileBackport(b, eng, mr, source, &r, opts.Actor); err != nil {
+			return results, err
+		}
+		results = append(results, r)
+		if r.BackportMR != "" {
+			notes = append(notes, fmt.Sprintf("Backported to %s in %s (MR %s).", target, r.BackportBead, r.BackportMR))
+		} else {
+			notes = append(notes, fmt.Sprintf("Backport to %s needs a manual pick: %s.", target, r.BackportBead))
+		}
+	}
+
+	// Link the original work to its backports
+	if source != nil && len(notes) > 0 {
+		note := beads.GetDescriptionSection(source.Description, "backports")
+		if note != "" {
+			note += "\n"
+		}
+		description := beads.SetDescriptionSection(source.Description, "backports", note+strings.Join(notes, "\n"))
+		if err := b.Update(source.ID, beads.UpdateOptions{Description: &description}); err != nil {
+			_, _ = fmt.Fprintf(m.output, "Warning: failed to link backports to %s: %v\n", source.ID, err)
+		}
+	}
+	return results, nil
+}
+
+// fileBackport files the backport bead and, for a clean pick, the MR that
+// lands it in the target's queue.
+func (m *Manager) fileBackport(b *beads.Beads, eng *Engineer, mr, source *beads.Issue, r *BackportResult, actor string) error {
+	title := fmt.Sprintf("Backport %s to %s", r.MR, r.Target)
+	priority := mr.Priority
+	if source != nil {
+		title = fmt.Sprintf("Backport %s to %s: %s", source.ID, r.Target, source.Title)
+		priority = source.Priority
+	}
+	bead, err := b.Create(beads.CreateOptions{
+		Title:       title,
+		Type:        "task",
+		Priority:    priority,
+		Description: formatBackportDescription(r),
+		Labels:      []string{BackportLabel},
+		Actor:       actor,
+	})
+	if err != nil {
+		return fmt.Errorf("creating backport bead for %s: %w", r.Target, err)
+	}
+	r.BackportBead = bead.ID
+	if r.Outcome != BackportSubmitted {
+		return nil
+	}
+
+	description := fmt.Sprintf("branch: %s\ntarget: %s\nsource_issue: %s\nrig: %s",
+		r.Branch, r.Target, bead.ID, m.rig.Name)
+	if eng.ConfigFor(r.Target).RequireReview {
+		description += "\nreview: " + ReviewRequested
+	}
+	backportMR, err := b.Create(beads.CreateOptions{
+		Title:       "Merge: " + bead.ID,
+		Type:        "merge-request",
+		Priority:    priority,
+		Description: description,
+		Labels:      []string{BackportLabel},
+		Actor:       actor,
+		Ephemeral:   true,
+	})
+	if err != nil {
+		return fmt.Errorf("submitting backport MR for %s: %w", r.Target, err)
+	}
+	r.BackportMR = backportMR.ID
+	return nil
+}
+
+// formatBackportDescription links a backport bead to what it backports.
+func formatBackportDescription(r *BackportResult) string {
+	summary := fmt.Sprintf("Backports %s (merged as %s) to %s.", r.MR, shortCommit(r.Commit), r.Target)
+	if len(r.Conflicts) > 0 {
+		summary += fmt.Sprintf("\n\nThe pick conflicts; cherry-pick %s onto %s by hand and submit it with 'gt mq submit --target %s'.",
+			shortCommit(r.Commit), r.Target, r.Target)
+	}
+	lines := []string{summary, "", "backports_mr: " + r.MR}
+	if r.SourceIssue != "" {
+		lines = append(lines, "backports_issue: "+r.SourceIssue)
+	}
+	lines = append(lines, "backported_commit: "+r.Commit, "target: "+r.Target)
+	if len(r.Conflicts) > 0 {
+		lines = append(lines, "conflicts: "+strings.Join(r.Conflicts, ", "))
+	} else {
+		lines = append(lines, "branch: "+r.Branch)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// commitBackport creates branch from origin/target in a pooled worktree,
+// cherry-picks commit, and pushes it. If the pick conflicts it tries again
+// ignoring whitespace; if that conflicts too, nothing is pushed and the
+// conflicting files are returned. Returns the backport commit and how any
+// conflicts were resolved.
+func (m *Manager) commitBackport(g *git.Git, branch, target, commit string) (string, string, []string, error) {
+	scratch, err := m.worktrees(g).BorrowBranch(branch, "origin/"+target)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("creating backport branch %s: %w", branch, err)
+	}
+	defer func() {
+		scratch.Return()
+		_ = g.DeleteBranch(branch, true)
+	}()
+
+	wt := scratch.Git
+	resolved := ""
+	if err := wt.CherryPick(commit); err != nil {
+		conflicts, _ := wt.GetConflictingFiles()
+		status, serr := wt.Status()
+		_ = wt.CherryPickAbort()
+		if len(conflicts) == 0 {
+			// Nothing conflicted, so the pick was empty or failed outright
+			if serr == nil && status.Clean {
+				return "", "", nil, errAlreadyPresent
+			}
+			return "", "", nil, fmt.Errorf("cherry-picking %s onto %s: %w", shortCommit(commit), target, err)
+		}
+		if err := wt.CherryPick(commit, "ignore-space-change"); err != nil {
+			_ = wt.CherryPickAbort()
+			return "", "", conflicts, nil
+		}
+		resolved = "ignored whitespace in " + strings.Join(conflicts, ", ")
+	}
+
+	sha, err := wt.Rev("HEAD")
+	if err != nil {
+		return "", "", nil, err
+	}
+	if err := wt.Push("origin", branch, false); err != nil {
+		return "", "", nil, fmt.Errorf("pushing %s: %w", branch, err)
+	}
+	return sha, resolved, nil, nil
+}
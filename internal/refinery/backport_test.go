@@ -0,0 +1,119 @@
+package refinery
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+func TestCommitBackport(t *testing.T) {
+	tmp := t.TempDir()
+	origin := filepath.Join(tmp, "origin.git")
+	clone := filepath.Join(tmp, "clone")
+	run := func(dir string, args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@test.com",
+			"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@test.com")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(clone, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	commit := func(msg string) string {
+		run(clone, "add", ".")
+		run(clone, "commit", "-m", msg)
+		return run(clone, "rev-parse", "HEAD")
+	}
+
+	run(tmp, "init", "--bare", "-b", "main", origin)
+	run(tmp, "clone", origin, clone)
+	run(clone, "config", "user.email", "test@test.com")
+	run(clone, "config", "user.name", "Test")
+	run(clone, "checkout", "-b", "main")
+	write("fix.txt", "broken\n")
+	write("style.txt", "if x {\n\treturn\n}\n")
+	write("app.txt", "v1\n")
+	commit("initial")
+	run(clone, "branch", "release-1.2")
+
+	fix := func() string { write("fix.txt", "fixed\n"); return commit("fix") }()
+	// Main reindents style.txt; the release branch keeps the old indent.
+	write("style.txt", "if x {\n    return\n}\n")
+	commit("reindent")
+	write("style.txt", "if x {\n    return nil\n}\n")
+	styleFix := commit("return nil")
+	write("app.txt", "v3\n")
+	appChange := commit("app v3")
+	run(clone, "push", "origin", "main", "release-1.2")
+
+	run(clone, "checkout", "release-1.2")
+	write("app.txt", "v2\n")
+	commit("release app v2")
+	run(clone, "push", "origin", "release-1.2")
+	run(clone, "checkout", "main")
+	run(clone, "fetch", "origin")
+
+	m := &Manager{rig: &rig.Rig{Name: "test-rig", Path: tmp}, output: io.Discard}
+	g := git.NewGit(clone)
+
+	sha, resolved, conflicts, err := m.commitBackport(g, "backport/release-1.2/gt-mr1", "release-1.2", fix)
+	if err != nil || conflicts != nil || resolved != "" {
+		t.Fatalf("clean pick: %q %v %v", resolved, conflicts, err)
+	}
+	if got := run(origin, "rev-parse", "backport/release-1.2/gt-mr1"); got != sha {
+		t.Errorf("origin backport branch = %s, want %s", got, sha)
+	}
+	if got := run(origin, "show", sha+":fix.txt"); got != "fixed" {
+		t.Errorf("fix.txt on backport = %q, want fixed", got)
+	}
+	if msg := run(origin, "log", "-1", "--format=%B", sha); !strings.Contains(msg, "cherry picked from commit "+fix) {
+		t.Errorf("backport message doesn't record the original commit:\n%s", msg)
+	}
+
+	// Conflicts only on whitespace: resolved by ignoring it
+	if _, resolved, conflicts, err = m.commitBackport(g, "backport/release-1.2/gt-mr2", "release-1.2", styleFix); err != nil || conflicts != nil || resolved == "" {
+		t.Errorf("whitespace pick: %q %v %v", resolved, conflicts, err)
+	}
+
+	// Real conflicts: nothing is pushed
+	_, _, conflicts, err = m.commitBackport(g, "backport/release-1.2/gt-mr3", "release-1.2", appChange)
+	if err != nil || !reflect.DeepEqual(conflicts, []string{"app.txt"}) {
+		t.Errorf("conflicting pick: %v %v", conflicts, err)
+	}
+	if out := run(origin, "branch", "--list", "backport/release-1.2/gt-mr3"); out != "" {
+		t.Errorf("conflicting backport was pushed: %q", out)
+	}
+
+	// Already on the target
+	run(clone, "fetch", "origin")
+	if _, _, _, err := m.commitBackport(g, "backport/release-1.2/gt-mr4", "backport/release-1.2/gt-mr1", fix); err != errAlreadyPresent {
+		t.Errorf("repeat pick error = %v, want errAlreadyPresent", err)
+	}
+}
+
+func TestFormatBackportDescription(t *testing.T) {
+	r := &BackportResult{MR: "gt-mr1", SourceIssue: "gt-42", Target: "release-1.2", Commit: "0123456789abcdef", Conflicts: []string{"app.txt"}}
+	got := formatBackportDescription(r)
+	for _, want := range []string{"backports_mr: gt-mr1", "backports_issue: gt-42", "backported_commit: 0123456789abcdef", "conflicts: app.txt", "gt mq submit --target release-1.2"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("description missing %q:\n%s", want, got)
+		}
+	}
+}
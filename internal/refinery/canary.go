@@ -0,0 +1,372 @@
+// Package refinery provides the merge queue processing agent.
+// This file implements canary merges: an MR soaks on a canary branch and is
+// promoted to the target only once verification passes.
+
+package refinery
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// canaryPollInterval is how often a canary waiting on a signal checks for one.
+const canaryPollInterval = 10 * time.Second
+
+// Canary is the canary merge currently soaking on a rig.
+type Canary struct {
+	MR        string    `json:"mr,omitempty"`
+	Branch    string    `json:"branch"`        // Source branch being verified
+	Canary    string    `json:"canary_branch"` // Branch the MR was merged to
+	Commit    string    `json:"commit"`        // Canary merge commit
+	StartedAt time.Time `json:"started_at"`
+	Deadline  time.Time `json:"deadline"` // Reverted if unverified by then
+}
+
+// CanarySignal is an external verdict on a canary commit.
+type CanarySignal struct {
+	Commit string    `json:"commit"`
+	Passed bool      `json:"passed"`
+	Reason string    `json:"reason,omitempty"`
+	By     string    `json:"by,omitempty"`
+	At     time.Time `json:"at"`
+}
+
+func canaryDir(rigPath string) string {
+	return filepath.Join(rigPath, ".runtime", "canary")
+}
+
+func canaryStatePath(rigPath string) string {
+	return filepath.Join(canaryDir(rigPath), "current.json")
+}
+
+func canarySignalPath(rigPath, commit string) string {
+	return filepath.Join(canaryDir(rigPath), "signal-"+commit+".json")
+}
+
+// LoadCanary returns the rig's soaking canary, or nil if none.
+func LoadCanary(rigPath string) (*Canary, error) {
+	data, err := os.ReadFile(canaryStatePath(rigPath)) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var c Canary
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("parsing canary state: %w", err)
+	}
+	return &c, nil
+}
+
+func saveCanary(rigPath string, c *Canary) error {
+	if err := os.MkdirAll(canaryDir(rigPath), 0755); err != nil {
+		return err
+	}
+	return util.AtomicWriteJSON(canaryStatePath(rigPath), c)
+}
+
+// SignalCanary records a verdict for a canary commit. The refinery picks it
+// up on its next poll.
+func SignalCanary(rigPath string, sig *CanarySignal) error {
+	if sig.Commit == "" {
+		return errors.New("canary signal requires a commit")
+	}
+	if sig.At.IsZero() {
+		sig.At = time.Now().UTC()
+	}
+	if err := os.MkdirAll(canaryDir(rigPath), 0755); err != nil {
+		return err
+	}
+	return util.AtomicWriteJSON(canarySignalPath(rigPath, sig.Commit), sig)
+}
+
+// LoadCanarySignal returns the verdict for a canary commit, or nil if none
+// has arrived.
+func LoadCanarySignal(rigPath, commit string) (*CanarySignal, error) {
+	data, err := os.ReadFile(canarySignalPath(rigPath, commit)) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var sig CanarySignal
+	if err := json.Unmarshal(data, &sig); err != nil {
+		return nil, fmt.Errorf("parsing canary signal: %w", err)
+	}
+	return &sig, nil
+}
+
+// clearCanary removes the soaking canary and its signal.
+func clearCanary(rigPath, commit string) {
+	_ = os.Remove(canaryStatePath(rigPath))
+	if commit != "" {
+		_ = os.Remove(canarySignalPath(rigPath, commit))
+	}
+}
+
+// canaryTimeout returns the configured soak limit.
+func (e *Engineer) canaryTimeout() time.Duration {
+	d, err := time.ParseDuration(e.config.CanaryTimeout)
+	if err != nil || d <= 0 {
+		return 30 * time.Minute
+	}
+	return d
+}
+
+// RunCanary merges branch onto target in the canary branch, pushes it, and
+// waits for a verdict. On failure or timeout the canary branch is reset to
+// target. A successful result means the MR may be promoted.
+func (e *Engineer) RunCanary(ctx context.Context, mrID, branch, target string) ProcessResult {
+	canary := e.config.CanaryBranch
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Canary: merging %s to %s\n", branch, canary)
+
+	if err := e.git.Fetch("origin"); err != nil {
+		return ProcessResult{Error: fmt.Sprintf("canary: fetching origin: %v", err)}
+	}
+	commit, err := e.mergeToCanary(branch, target)
+	if err != nil {
+		if isConflictError(err) {
+			return ProcessResult{Conflict: true, Error: err.Error()}
+		}
+		return ProcessResult{Error: fmt.Sprintf("canary merge failed: %v", err)}
+	}
+
+	now := time.Now().UTC()
+	state := &Canary{
+		MR:        mrID,
+		Branch:    branch,
+		Canary:    canary,
+		Commit:    commit,
+		StartedAt: now,
+		Deadline:  now.Add(e.canaryTimeout()),
+	}
+	if err := saveCanary(e.rig.Path, state); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to save canary state: %v\n", err)
+	}
+	defer clearCanary(e.rig.Path, commit)
+
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Canary %s pushed to %s; verifying (timeout %s)\n", shortCommit(commit), canary, e.canaryTimeout())
+
+	passed, reason := e.verifyCanary(ctx, state)
+	if passed {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Canary %s passed; promoting to %s\n", shortCommit(commit), target)
+		return ProcessResult{Success: true}
+	}
+
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Canary %s failed: %s; reverting %s\n", shortCommit(commit), reason, canary)
+	if err := e.revertCanary(target); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to revert canary branch %s: %v\n", canary, err)
+	}
+	return ProcessResult{
+		CanaryFailed: true,
+		Error:        "canary verification failed: " + reason,
+	}
+}
+
+// mergeToCanary resets the canary branch to the target, merges the source
+// branch into it, and force-pushes. Returns the canary commit.
+func (e *Engineer) mergeToCanary(branch, target string) (string, error) {
+	canary := e.config.CanaryBranch
+	previous, _ := e.git.CurrentBranch()
+	if previous == canary {
+		// A leftover canary checkout would block the reset below
+		if err := e.git.Checkout(target); err != nil {
+			return "", err
+		}
+		previous = target
+	}
+	defer func() {
+		if previous != "" {
+			_ = e.git.Checkout(previous)
+		}
+	}()
+
+	if err := e.git.ResetBranch(canary, "origin/"+target); err != nil {
+		return "", fmt.Errorf("resetting %s to origin/%s: %w", canary, target, err)
+	}
+	if err := e.git.Checkout(canary); err != nil {
+		return "", err
+	}
+	if err := e.git.MergeNoFF("origin/"+branch, fmt.Sprintf("Canary: merge %s into %s", branch, target)); err != nil {
+		conflicts, _ := e.git.GetConflictingFiles()
+		_ = e.git.AbortMerge()
+		if len(conflicts) > 0 {
+			return "", fmt.Errorf("conflict merging %s onto %s: %s", branch, target, strings.Join(conflicts, ", "))
+		}
+		return "", err
+	}
+	commit, err := e.git.Rev("HEAD")
+	if err != nil {
+		return "", err
+	}
+	if err := e.git.Push("origin", canary, true); err != nil {
+		return "", fmt.Errorf("pushing %s: %w", canary, err)
+	}
+	return commit, nil
+}
+
+// revertCanary points the canary branch back at the target so the failed
+// change stops soaking.
+func (e *Engineer) revertCanary(target string) error {
+	canary := e.config.CanaryBranch
+	if current, _ := e.git.CurrentBranch(); current == canary {
+		if err := e.git.Checkout(target); err != nil {
+			return err
+		}
+	}
+	if err := e.git.ResetBranch(canary, "origin/"+target); err != nil {
+		return err
+	}
+	return e.git.Push("origin", canary, true)
+}
+
+// verifyCanary runs the verify command, or waits for a signal when none is
+// configured. Returns whether the canary passed and, if not, why.
+func (e *Engineer) verifyCanary(ctx context.Context, c *Canary) (bool, string) {
+	ctx, cancel := context.WithDeadline(ctx, c.Deadline)
+	defer cancel()
+
+	if e.config.CanaryVerifyCommand != "" {
+		return e.runCanaryVerify(ctx, c)
+	}
+	return e.waitForCanarySignal(ctx, c)
+}
+
+// runCanaryVerify runs CanaryVerifyCommand against the canary commit.
+func (e *Engineer) runCanaryVerify(ctx context.Context, c *Canary) (bool, string) {
+	// Trust boundary: CanaryVerifyCommand comes from the rig's config.json.
+	cmd := exec.CommandContext(ctx, "sh", "-c", e.config.CanaryVerifyCommand) //nolint:gosec // G204: from trusted rig config
+	cmd.Dir = e.workDir
+	cmd.Env = append(os.Environ(),
+		"GT_CANARY_BRANCH="+c.Canary,
+		"GT_CANARY_COMMIT="+c.Commit,
+		"GT_MR_BRANCH="+c.Branch,
+	)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return false, "verification timed out"
+		}
+		return false, fmt.Sprintf("%v\n%s", err, strings.TrimSpace(output.String()))
+	}
+	return true, ""
+}
+
+// waitForCanarySignal polls for a pass/fail signal until the deadline.
+func (e *Engineer) waitForCanarySignal(ctx context.Context, c *Canary) (bool, string) {
+	ticker := time.NewTicker(canaryPollInterval)
+	defer ticker.Stop()
+	for {
+		sig, err := LoadCanarySignal(e.rig.Path, c.Commit)
+		if err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: reading canary signal: %v\n", err)
+		} else if sig != nil {
+			if sig.Passed {
+				return true, ""
+			}
+			reason := sig.Reason
+			if reason == "" {
+				reason = "failed by " + sig.By
+			}
+			return false, reason
+		}
+
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return false, "no verdict before timeout"
+			}
+			return false, "canceled"
+		case <-e.stopCh:
+			return false, "refinery stopping"
+		case <-ticker.C:
+		}
+	}
+}
+
+// shortCommit abbreviates a commit SHA for log output.
+func shortCommit(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
+
+// CanaryWebhook accepts canary verdicts from external systems:
+//
+//	POST / {"rig": "gastown", "commit": "abc123", "passed": true}
+//
+// A missing commit applies to the rig's soaking canary. If Secret is set,
+// requests must carry "Authorization: Bearer <secret>".
+type CanaryWebhook struct {
+	Secret string
+	// RigPath resolves a rig name to its path.
+	RigPath func(rig string) (string, error)
+}
+
+func (h *CanaryWebhook) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.Secret != "" {
+		token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(h.Secret)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	var body struct {
+		Rig string `json:"rig"`
+		CanarySignal
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, 64<<10)).Decode(&body); err != nil {
+		http.Error(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if body.Rig == "" {
+		http.Error(w, "rig is required", http.StatusBadRequest)
+		return
+	}
+	rigPath, err := h.RigPath(body.Rig)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if body.Commit == "" {
+		current, err := LoadCanary(rigPath)
+		if err != nil || current == nil {
+			http.Error(w, "no canary is soaking on "+body.Rig, http.StatusConflict)
+			return
+		}
+		body.Commit = current.Commit
+	}
+	if body.By == "" {
+		body.By = "webhook"
+	}
+	if err := SignalCanary(rigPath, &body.CanarySignal); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(body.CanarySignal)
+}
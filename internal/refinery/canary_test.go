@@ -0,0 +1,116 @@
+package refinery
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+func newCanaryEngineer(t *testing.T, verify string) *Engineer {
+	t.Helper()
+	dir := t.TempDir()
+	cfg := DefaultMergeQueueConfig()
+	cfg.CanaryBranch = "canary"
+	cfg.CanaryVerifyCommand = verify
+	return &Engineer{
+		rig:     &rig.Rig{Name: "test-rig", Path: dir},
+		config:  cfg,
+		workDir: dir,
+		output:  io.Discard,
+		stopCh:  make(chan struct{}),
+	}
+}
+
+func TestVerifyCanary_Command(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		timeout time.Duration
+		want    bool
+		reason  string
+	}{
+		{"pass", `test "$GT_CANARY_COMMIT" = abc123`, time.Minute, true, ""},
+		{"fail", "echo boom; exit 3", time.Minute, false, "boom"},
+		{"timeout", "exec sleep 5", 50 * time.Millisecond, false, "timed out"},
+	}
+	for _, tt := range tests {
+		e := newCanaryEngineer(t, tt.command)
+		c := &Canary{Commit: "abc123", Canary: "canary", Deadline: time.Now().Add(tt.timeout)}
+		passed, reason := e.verifyCanary(context.Background(), c)
+		if passed != tt.want || !strings.Contains(reason, tt.reason) {
+			t.Errorf("%s: verifyCanary = %v %q, want %v containing %q", tt.name, passed, reason, tt.want, tt.reason)
+		}
+	}
+}
+
+func TestVerifyCanary_Signal(t *testing.T) {
+	e := newCanaryEngineer(t, "")
+	c := &Canary{Commit: "abc123", Deadline: time.Now().Add(50 * time.Millisecond)}
+
+	if passed, reason := e.verifyCanary(context.Background(), c); passed || reason != "no verdict before timeout" {
+		t.Errorf("without signal: %v %q", passed, reason)
+	}
+
+	if err := SignalCanary(e.rig.Path, &CanarySignal{Commit: "abc123", Reason: "error rate 4%"}); err != nil {
+		t.Fatal(err)
+	}
+	c.Deadline = time.Now().Add(time.Minute)
+	if passed, reason := e.verifyCanary(context.Background(), c); passed || reason != "error rate 4%" {
+		t.Errorf("with fail signal: %v %q", passed, reason)
+	}
+
+	if err := SignalCanary(e.rig.Path, &CanarySignal{Commit: "abc123", Passed: true}); err != nil {
+		t.Fatal(err)
+	}
+	if passed, _ := e.verifyCanary(context.Background(), c); !passed {
+		t.Error("with pass signal: expected pass")
+	}
+}
+
+func TestCanaryWebhook(t *testing.T) {
+	dir := t.TempDir()
+	if err := saveCanary(dir, &Canary{Commit: "abc123"}); err != nil {
+		t.Fatal(err)
+	}
+	h := &CanaryWebhook{
+		Secret: "s3cret",
+		RigPath: func(name string) (string, error) {
+			if name != "gastown" {
+				return "", io.EOF
+			}
+			return dir, nil
+		},
+	}
+
+	tests := []struct {
+		name string
+		auth string
+		body string
+		want int
+	}{
+		{"bad secret", "Bearer nope", `{"rig":"gastown","passed":true}`, http.StatusUnauthorized},
+		{"missing rig", "Bearer s3cret", `{"passed":true}`, http.StatusBadRequest},
+		{"unknown rig", "Bearer s3cret", `{"rig":"nope","passed":true}`, http.StatusNotFound},
+		{"soaking canary", "Bearer s3cret", `{"rig":"gastown","passed":true}`, http.StatusAccepted},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+		req.Header.Set("Authorization", tt.auth)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d (%s)", tt.name, rec.Code, tt.want, rec.Body.String())
+		}
+	}
+
+	sig, err := LoadCanarySignal(dir, "abc123")
+	if err != nil || sig == nil || !sig.Passed || sig.By != "webhook" {
+		t.Errorf("signal = %+v, %v", sig, err)
+	}
+}
@@ -0,0 +1,80 @@
+// Package refinery provides the merge queue processing agent.
+// This file publishes merge queue state to GitHub as commit statuses.
+
+package refinery
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/ci"
+	"github.com/steveyegge/gastown/internal/convoy"
+)
+
+// PublishCIStatus publishes an MR's merge queue state as a
+// "gastown/merge-queue" commit status on sha (default: the MR branch's
+// head) and, for convoy work, the convoy's progress as "gastown/convoy".
+// It does nothing unless the rig sets merge_queue.publish_ci_status.
+func (e *Engineer) PublishCIStatus(ctx context.Context, mrID, sha string, state ci.State, description string) error {
+	if !e.config.PublishCIStatus {
+		return nil
+	}
+	repo, err := ci.ParseRepo(e.rig.GitURL)
+	if err != nil {
+		return err
+	}
+	mr, err := e.beads.Show(mrID)
+	if err != nil {
+		return fmt.Errorf("fetching MR %s: %w", mrID, err)
+	}
+	fields := beads.ParseMRFields(mr)
+	if fields == nil || fields.Branch == "" {
+		return fmt.Errorf("MR %s has no branch", mrID)
+	}
+	if sha == "" {
+		if sha, err = e.ResolveCommit(fields.Branch); err != nil {
+			return err
+		}
+	}
+
+	client := e.ciClient()
+	if err := client.Publish(ctx, repo, sha, ci.Status{
+		Context:     ci.ContextMergeQueue,
+		State:       state,
+		Description: description,
+		TargetURL:   fields.PRURL,
+	}); err != nil {
+		return err
+	}
+	if fields.ConvoyID != "" {
+		done, total, closed := convoy.Progress(e.rig.Path, fields.ConvoyID)
+		if err := client.Publish(ctx, repo, sha, ci.ConvoyStatus(fields.ConvoyID, done, total, closed)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResolveCommit resolves a commit SHA, branch, or other ref in the
+// refinery's clone, trying the remote-tracking branch first.
+func (e *Engineer) ResolveCommit(ref string) (string, error) {
+	for _, candidate := range []string{"origin/" + ref, ref} {
+		if sha, err := e.git.Rev(candidate + "^{commit}"); err == nil {
+			return sha, nil
+		}
+	}
+	return "", fmt.Errorf("cannot resolve %q to a commit in %s", ref, e.workDir)
+}
+
+// GitHubRepo returns the rig's GitHub repository.
+func (e *Engineer) GitHubRepo() (ci.Repo, error) {
+	return ci.ParseRepo(e.rig.GitURL)
+}
+
+func (e *Engineer) ciClient() *ci.Client {
+	if e.ci == nil {
+		e.ci = ci.NewClient()
+	}
+	return e.ci
+}
@@ -0,0 +1,36 @@
+package refinery
+
+import (
+	"context"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/ci"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+func TestPublishCIStatus(t *testing.T) {
+	var calls int
+	fake := &ci.Client{Run: func(ctx context.Context, args ...string) ([]byte, error) {
+		calls++
+		return []byte("{}"), nil
+	}}
+
+	e := NewEngineer(&rig.Rig{Name: "test-rig", Path: t.TempDir(), GitURL: "https://github.com/acme/widgets.git"})
+	e.ci = fake
+	if err := e.PublishCIStatus(context.Background(), "gt-mr-1", "abc123", ci.Pending, "queued"); err != nil {
+		t.Errorf("disabled: unexpected error %v", err)
+	}
+	if calls != 0 {
+		t.Errorf("disabled: gh called %d times", calls)
+	}
+
+	e = NewEngineer(&rig.Rig{Name: "test-rig", Path: t.TempDir(), GitURL: "https://gitlab.com/acme/widgets.git"})
+	e.ci = fake
+	e.config.PublishCIStatus = true
+	if err := e.PublishCIStatus(context.Background(), "gt-mr-1", "abc123", ci.Pending, "queued"); err == nil {
+		t.Error("non-GitHub rig: expected error")
+	}
+	if calls != 0 {
+		t.Errorf("non-GitHub rig: gh called %d times", calls)
+	}
+}
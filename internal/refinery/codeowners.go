@@ -0,0 +1,176 @@
+// Package refinery provides the merge queue processing agent.
+// This file routes MRs touching owned paths to their code owners and holds
+// them until every owner approves.
+
+package refinery
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/codeowners"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/mail"
+)
+
+// RequiredOwners returns the code owners whose approval an MR from head
+// into base needs under the rig's code_owners settings, or nil if the rig
+// routes no reviews by ownership. The ownership file is read from base, so
+// an MR can't change who reviews it.
+func RequiredOwners(g *git.Git, rigPath, base, head string) ([]string, error) {
+	settings, err := config.LoadRigSettings(config.RigSettingsPath(rigPath))
+	if errors.Is(err, config.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading rig settings: %w", err)
+	}
+	if settings.CodeOwners == nil {
+		return nil, nil
+	}
+	cfg := settings.CodeOwners
+	paths := codeowners.DefaultPaths
+	if cfg.File != "" {
+		paths = []string{cfg.File}
+	}
+	var data []byte
+	for _, p := range paths {
+		if data, err = g.ShowFile(base, p); err == nil {
+			break
+		}
+	}
+	if data == nil {
+		if cfg.File != "" {
+			return nil, fmt.Errorf("code owners file %s not found on %s", cfg.File, base)
+		}
+		return nil, nil
+	}
+	rules, err := codeowners.Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("parsing code owners file: %w", err)
+	}
+
+	changes, err := g.ChangedFiles(base, head)
+	if err != nil {
+		return nil, fmt.Errorf("reading diff of %s: %w", head, err)
+	}
+	files := make([]string, 0, len(changes))
+	for _, c := range changes {
+		files = append(files, c.Path)
+		if c.OldPath != "" {
+			files = append(files, c.OldPath)
+		}
+	}
+	return codeowners.Required(rules, files, cfg.Reviewers), nil
+}
+
+// PendingOwners returns the MR's code owners that no approval satisfies yet.
+func PendingOwners(fields *beads.MRFields) []string {
+	if fields == nil {
+		return nil
+	}
+	return codeowners.Pending(codeowners.SplitList(fields.Owners), codeowners.SplitList(fields.Approvals))
+}
+
+// RouteToOwners records the MR's code owners and, while any of them has
+// yet to approve, holds the MR for review and mails the owners it waits on.
+func (e *Engineer) RouteToOwners(mr *beads.Issue, owners []string) error {
+	fields := beads.ParseMRFields(mr)
+	if fields == nil {
+		return fmt.Errorf("%s has no MR fields; is it a merge request?", mr.ID)
+	}
+	fields.Owners = strings.Join(owners, ", ")
+	pending := PendingOwners(fields)
+	if len(pending) > 0 && fields.Review != ReviewChangesRequested {
+		fields.Review = ReviewRequested
+	}
+	if err := e.updateMRFields(mr, fields, ""); err != nil {
+		return err
+	}
+	for _, owner := range pending {
+		e.notifyOwner(mr, fields, owner)
+	}
+	return nil
+}
+
+// ownerAddress returns the mail address that reaches an owner, or "" if
+// the owner isn't a gt identity. Crew and polecat roles reach every member.
+func ownerAddress(owner string) string {
+	if strings.HasPrefix(owner, "@") {
+		return "" // a forge handle with no reviewers mapping
+	}
+	if rig, role, ok := strings.Cut(owner, "/"); ok && (role == "crew" || role == "polecats") {
+		return "@" + role + "/" + rig
+	}
+	return owner
+}
+
+// notifyOwner mails an owner that an MR awaits their review.
+func (e *Engineer) notifyOwner(mr *beads.Issue, fields *beads.MRFields, owner string) {
+	to := ownerAddress(owner)
+	if to == "" || e.router == nil {
+		return
+	}
+	msg := &mail.Message{
+		From:    fmt.Sprintf("%s/refinery", e.rig.Name),
+		To:      to,
+		Subject: fmt.Sprintf("Review requested: %s", mr.ID),
+		Body: fmt.Sprintf(`A merge request touches paths owned by %s and needs your approval.
+
+Branch: %s
+Issue: %s
+Owners: %s
+
+Review it with 'gt mq next-review %s', then approve or request changes
+with 'gt mq review %s %s'.`,
+			owner, fields.Branch, fields.SourceIssue, fields.Owners, e.rig.Name, e.rig.Name, mr.ID),
+		Timestamp: time.Now(),
+	}
+	if err := e.router.Send(msg); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to notify owner %s: %v\n", owner, err)
+	}
+}
+
+// enforceCodeOwners holds an MR whose changes need approval from a code
+// owner who hasn't given it, re-routing it if its changes now touch paths
+// with new owners. It returns nil when the MR may proceed to merge. An MR
+// whose owners can't be worked out is held too, not merged without them.
+func (e *Engineer) enforceCodeOwners(mrID, branch, target string) *ProcessResult {
+	if e.rig == nil || mrID == "" {
+		return nil
+	}
+	owners, err := RequiredOwners(e.git, e.rig.Path, e.resolveRef(target), e.resolveRef(branch))
+	if err != nil {
+		return e.holdForOwners(err)
+	}
+	if len(owners) == 0 {
+		return nil
+	}
+	mr, err := e.beads.Show(mrID)
+	if err != nil {
+		return e.holdForOwners(err)
+	}
+	fields := beads.ParseMRFields(mr)
+	if fields == nil {
+		return nil
+	}
+	fields.Owners = strings.Join(owners, ", ")
+	pending := PendingOwners(fields)
+	if len(pending) == 0 {
+		return nil
+	}
+	if err := e.RouteToOwners(mr, owners); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: %v\n", err)
+	}
+	return &ProcessResult{Deferred: true, Error: "awaiting approval from code owners: " + strings.Join(pending, ", ")}
+}
+
+// holdForOwners holds an MR whose code owner check failed with err.
+func (e *Engineer) holdForOwners(err error) *ProcessResult {
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Code owner check failed, holding MR: %v\n", err)
+	return &ProcessResult{Deferred: true, Error: fmt.Sprintf("code owner check failed: %v", err)}
+}
@@ -0,0 +1,141 @@
+package refinery
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+func TestRequiredOwners(t *testing.T) {
+	rigPath := t.TempDir()
+	clone := filepath.Join(rigPath, "refinery", "rig")
+	if err := os.MkdirAll(clone, 0755); err != nil {
+		t.Fatal(err)
+	}
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = clone
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@test.com",
+			"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@test.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		p := filepath.Join(clone, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run("init", "-b", "main")
+	write("CODEOWNERS", "*.md @docs\ninternal/billing/ gastown/crew/alice\n")
+	write("README.md", "# app\n")
+	write("internal/billing/pay.go", "package billing\n")
+	run("add", ".")
+	run("commit", "-m", "initial")
+
+	run("checkout", "-b", "polecat/nux")
+	write("internal/billing/pay.go", "package billing\n\nvar rate = 2\n")
+	write("README.md", "# app\n\nUpdated.\n")
+	// Handing billing to the MR's author doesn't take effect until merged.
+	write("CODEOWNERS", "*.md @docs\ninternal/billing/ gastown/polecats/nux\n")
+	run("add", ".")
+	run("commit", "-m", "feature")
+
+	g := git.NewGit(clone)
+
+	// No code_owners settings: nothing is routed.
+	if owners, err := RequiredOwners(g, rigPath, "main", "polecat/nux"); err != nil || owners != nil {
+		t.Fatalf("unconfigured: %v, %v", owners, err)
+	}
+
+	settings := config.NewRigSettings()
+	settings.CodeOwners = &config.CodeOwnersConfig{Reviewers: map[string]string{"@docs": "gastown/crew"}}
+	if err := config.SaveRigSettings(config.RigSettingsPath(rigPath), settings); err != nil {
+		t.Fatal(err)
+	}
+	owners, err := RequiredOwners(g, rigPath, "main", "polecat/nux")
+	if err != nil {
+		t.Fatalf("RequiredOwners: %v", err)
+	}
+	if want := []string{"gastown/crew", "gastown/crew/alice"}; !reflect.DeepEqual(owners, want) {
+		t.Errorf("RequiredOwners() = %v, want %v", owners, want)
+	}
+
+	settings.CodeOwners.File = ".gt/CODEOWNERS"
+	if err := config.SaveRigSettings(config.RigSettingsPath(rigPath), settings); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RequiredOwners(g, rigPath, "main", "polecat/nux"); err == nil {
+		t.Error("expected an error for a missing configured file")
+	}
+
+	// Owners that can't be worked out hold the MR rather than waive review
+	e := &Engineer{
+		rig:     &rig.Rig{Name: "gastown", Path: rigPath},
+		config:  DefaultMergeQueueConfig(),
+		workDir: clone,
+		git:     g,
+		output:  io.Discard,
+	}
+	if held := e.enforceCodeOwners("gt-mr-1", "polecat/nux", "main"); held == nil || !held.Deferred {
+		t.Errorf("enforceCodeOwners(missing file) = %+v, want the MR held", held)
+	}
+	if err := os.WriteFile(config.RigSettingsPath(rigPath), []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RequiredOwners(g, rigPath, "main", "polecat/nux"); err == nil {
+		t.Error("expected an error for unreadable settings")
+	}
+}
+
+func TestPendingOwners(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields *beads.MRFields
+		want   []string
+	}{
+		{"nil", nil, nil},
+		{"no owners", &beads.MRFields{Approvals: "gastown/crew/alice"}, nil},
+		{"none approved", &beads.MRFields{Owners: "gastown/crew, gastown/witness"}, []string{"gastown/crew", "gastown/witness"}},
+		{"role member approved", &beads.MRFields{Owners: "gastown/crew, gastown/witness", Approvals: "gastown/crew/bob"}, []string{"gastown/witness"}},
+		{"all approved", &beads.MRFields{Owners: "gastown/crew", Approvals: "gastown/crew/bob, gastown/witness"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PendingOwners(tt.fields); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PendingOwners() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOwnerAddress(t *testing.T) {
+	tests := map[string]string{
+		"gastown/crew/alice": "gastown/crew/alice",
+		"gastown/crew":       "@crew/gastown",
+		"gastown/polecats":   "@polecats/gastown",
+		"gastown/witness":    "gastown/witness",
+		"@acme/payments":     "",
+	}
+	for owner, want := range tests {
+		if got := ownerAddress(owner); got != want {
+			t.Errorf("ownerAddress(%q) = %q, want %q", owner, got, want)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+// Package refinery provides the merge queue processing agent.
+// This file implements discussion threads on merge requests.
+
+package refinery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+// CommentsSection is the MR bead description section holding the
+// discussion, one JSON-encoded comment per line in the order posted.
+const CommentsSection = "comments"
+
+// forgeCommentTimeout bounds ForgeCommentCommand.
+const forgeCommentTimeout = 30 * time.Second
+
+// MRComment is one comment in an MR's discussion. Replies point at the
+// first comment of their thread and share its anchor.
+type MRComment struct {
+	ID      string    `json:"id"`
+	ReplyTo string    `json:"reply_to,omitempty"`
+	Author  string    `json:"author"`
+	At      time.Time `json:"at"`
+	Path    string    `json:"path,omitempty"`
+	Line    int       `json:"line,omitempty"`
+	Body    string    `json:"body"`
+}
+
+// Location renders the comment's anchor as "path:line", "path", or "".
+func (c MRComment) Location() string {
+	return strings.TrimSuffix(ReviewComment{Path: c.Path, Line: c.Line}.location(), ": ")
+}
+
+// CommentThread is a top-level comment and its replies, oldest first.
+type CommentThread struct {
+	MRComment
+	Replies []MRComment `json:"replies,omitempty"`
+}
+
+// ParseMRComments reads the discussion from an MR description. Lines that
+// don't decode are skipped so a hand-edited section can't hide the rest.
+func ParseMRComments(description string) []MRComment {
+	var comments []MRComment
+	for _, line := range strings.Split(beads.GetDescriptionSection(description, CommentsSection), "\n") {
+		var c MRComment
+		if line = strings.TrimSpace(line); line == "" || json.Unmarshal([]byte(line), &c) != nil {
+			continue
+		}
+		comments = append(comments, c)
+	}
+	return comments
+}
+
+// formatMRComments encodes comments for the description section.
+func formatMRComments(comments []MRComment) (string, error) {
+	lines := make([]string, 0, len(comments))
+	for _, c := range comments {
+		data, err := json.Marshal(c)
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, string(data))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// CommentThreads groups comments into threads in the order they started.
+// Replies to a comment that no longer exists start their own thread.
+func CommentThreads(comments []MRComment) []CommentThread {
+	var threads []CommentThread
+	index := make(map[string]int)
+	for _, c := range comments {
+		if i, ok := index[c.ReplyTo]; ok && c.ReplyTo != "" {
+			threads[i].Replies = append(threads[i].Replies, c)
+			continue
+		}
+		index[c.ID] = len(threads)
+		threads = append(threads, CommentThread{MRComment: c})
+	}
+	return threads
+}
+
+// AddComment appends c to an MR's discussion and mirrors it to the forge
+// when ForgeCommentCommand is set. The ID and timestamp are assigned here.
+// A reply to a reply joins the root comment's thread.
+func (e *Engineer) AddComment(mrID string, c MRComment) (*MRComment, error) {
+	if strings.TrimSpace(c.Body) == "" {
+		return nil, fmt.Errorf("comment has no body")
+	}
+	if c.Line > 0 && c.Path == "" {
+		return nil, fmt.Errorf("a line anchor needs a file")
+	}
+	mr, err := e.beads.Show(mrID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching merge request %s: %w", mrID, err)
+	}
+	fields := beads.ParseMRFields(mr)
+	if fields == nil {
+		return nil, fmt.Errorf("%s has no MR fields; is it a merge request?", mrID)
+	}
+
+	comments := ParseMRComments(mr.Description)
+	if c.ReplyTo != "" {
+		parent := findComment(comments, c.ReplyTo)
+		if parent == nil {
+			return nil, fmt.Errorf("no comment %s on %s", c.ReplyTo, mrID)
+		}
+		if parent.ReplyTo != "" {
+			parent = findComment(comments, parent.ReplyTo)
+		}
+		if parent != nil {
+			c.ReplyTo, c.Path, c.Line = parent.ID, parent.Path, parent.Line
+		}
+	}
+	c.ID = fmt.Sprintf("c%d", len(comments)+1)
+	c.At = time.Now().UTC().Truncate(time.Second)
+	c.Body = strings.TrimSpace(c.Body)
+
+	section, err := formatMRComments(append(comments, c))
+	if err != nil {
+		return nil, err
+	}
+	description := beads.SetDescriptionSection(mr.Description, CommentsSection, section)
+	if err := e.beads.Update(mr.ID, beads.UpdateOptions{Description: &description}); err != nil {
+		return nil, fmt.Errorf("updating merge request %s: %w", mr.ID, err)
+	}
+
+	if e.config.ForgeCommentCommand != "" {
+		if err := e.mirrorComment(mr.ID, fields.Branch, c); err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: mirroring comment %s to forge: %v\n", c.ID, err)
+		}
+	}
+	return &c, nil
+}
+
+func findComment(comments []MRComment, id string) *MRComment {
+	for i := range comments {
+		if comments[i].ID == id {
+			return &comments[i]
+		}
+	}
+	return nil
+}
+
+// mirrorComment runs ForgeCommentCommand with the comment on stdin.
+func (e *Engineer) mirrorComment(mrID, branch string, c MRComment) error {
+	payload, err := json.Marshal(struct {
+		MR     string `json:"mr"`
+		Branch string `json:"branch"`
+		MRComment
+	}{mrID, branch, c})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), forgeCommentTimeout)
+	defer cancel()
+	// Trust boundary: ForgeCommentCommand comes from the rig's config.json.
+	cmd := exec.CommandContext(ctx, "sh", "-c", e.config.ForgeCommentCommand) //nolint:gosec // G204: from trusted rig config
+	cmd.Dir = e.workDir
+	cmd.Env = append(os.Environ(),
+		"GT_MR="+mrID,
+		"GT_MR_BRANCH="+branch,
+		"GT_COMMENT_ID="+c.ID,
+	)
+	cmd.Stdin = bytes.NewReader(payload)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%v\n%s", err, strings.TrimSpace(output.String()))
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package refinery
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+func TestParseMRComments_RoundTrip(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	comments := []MRComment{
+		{ID: "c1", Author: "gastown/crew/max", At: at, Path: "auth.go", Line: 42, Body: "Token is never checked.\nSee RFC."},
+		{ID: "c2", ReplyTo: "c1", Author: "gastown/polecats/nux", At: at, Path: "auth.go", Line: 42, Body: "Fixed"},
+	}
+	section, err := formatMRComments(comments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	desc := beads.SetDescriptionSection("branch: feature\ntarget: main", CommentsSection, section)
+	desc += "\n\nprose after"
+
+	got := ParseMRComments(desc)
+	if len(got) != 2 {
+		t.Fatalf("got %d comments, want 2: %+v", len(got), got)
+	}
+	if got[0].Body != comments[0].Body || got[0].Line != 42 || !got[0].At.Equal(at) {
+		t.Errorf("comment 1 = %+v", got[0])
+	}
+	if got[1].ReplyTo != "c1" {
+		t.Errorf("comment 2 reply_to = %q", got[1].ReplyTo)
+	}
+	if f := beads.ParseMRFields(&beads.Issue{Description: desc}); f == nil || f.Branch != "feature" {
+		t.Errorf("MR fields lost: %+v", f)
+	}
+}
+
+func TestParseMRComments_SkipsBadLines(t *testing.T) {
+	desc := beads.SetDescriptionSection("", CommentsSection, "not json\n{\"id\":\"c1\",\"body\":\"ok\"}")
+	if got := ParseMRComments(desc); len(got) != 1 || got[0].ID != "c1" {
+		t.Errorf("ParseMRComments = %+v", got)
+	}
+	if got := ParseMRComments("no section"); got != nil {
+		t.Errorf("ParseMRComments(no section) = %+v", got)
+	}
+}
+
+func TestCommentThreads(t *testing.T) {
+	threads := CommentThreads([]MRComment{
+		{ID: "c1", Body: "a"},
+		{ID: "c2", Body: "b"},
+		{ID: "c3", ReplyTo: "c1", Body: "re a"},
+		{ID: "c4", ReplyTo: "gone", Body: "orphan"},
+		{ID: "c5", ReplyTo: "c1", Body: "re a again"},
+	})
+	var got [][]string
+	for _, th := range threads {
+		ids := []string{th.ID}
+		for _, r := range th.Replies {
+			ids = append(ids, r.ID)
+		}
+		got = append(got, ids)
+	}
+	want := [][]string{{"c1", "c3", "c5"}, {"c2"}, {"c4"}}
+	if len(got) != len(want) {
+		t.Fatalf("threads = %v, want %v", got, want)
+	}
+	for i := range want {
+		if len(got[i]) != len(want[i]) {
+			t.Fatalf("threads = %v, want %v", got, want)
+		}
+		for j := range want[i] {
+			if got[i][j] != want[i][j] {
+				t.Errorf("threads = %v, want %v", got, want)
+			}
+		}
+	}
+}
+
+func TestMRCommentLocation(t *testing.T) {
+	tests := []struct {
+		c    MRComment
+		want string
+	}{
+		{MRComment{Path: "auth.go", Line: 42}, "auth.go:42"},
+		{MRComment{Path: "auth.go"}, "auth.go"},
+		{MRComment{}, ""},
+	}
+	for _, tt := range tests {
+		if got := tt.c.Location(); got != tt.want {
+			t.Errorf("Location(%+v) = %q, want %q", tt.c, got, tt.want)
+		}
+	}
+}
+
+func TestMirrorComment(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "mirrored.json")
+	cfg := DefaultMergeQueueConfig()
+	cfg.ForgeCommentCommand = `cat > "` + out + `" && test "$GT_MR" = gt-mr1 && test "$GT_COMMENT_ID" = c3`
+	e := &Engineer{
+		rig:     &rig.Rig{Name: "test-rig", Path: dir},
+		config:  cfg,
+		workDir: dir,
+		output:  io.Discard,
+	}
+
+	c := MRComment{ID: "c3", Author: "max", Path: "auth.go", Line: 7, Body: "nit"}
+	if err := e.mirrorComment("gt-mr1", "feature", c); err != nil {
+		t.Fatalf("mirrorComment: %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		t.Fatalf("payload %q: %v", data, err)
+	}
+	if payload["mr"] != "gt-mr1" || payload["branch"] != "feature" || payload["body"] != "nit" || payload["line"] != float64(7) {
+		t.Errorf("payload = %v", payload)
+	}
+
+	e.config.ForgeCommentCommand = "echo forge down >&2; exit 1"
+	if err := e.mirrorComment("gt-mr1", "feature", c); err == nil {
+		t.Error("expected error from failing command")
+	}
+}
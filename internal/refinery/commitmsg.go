@@ -0,0 +1,146 @@
+// Package refinery provides the merge queue processing agent.
+// This file generates the commit messages MRs land with.
+
+package refinery
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+// Landing strategies (pr_merge_method).
+const (
+	StrategySquash = "squash" // One commit per MR, message generated from the issue
+	StrategyMerge  = "merge"  // A merge commit, keeping the branch's commits
+	StrategyRebase = "rebase" // The branch's commits rebased onto the target
+)
+
+// Default commit message templates. Rebase landings keep the branch's own
+// commit messages.
+const (
+	DefaultSquashSubject = "{{.IssueTitle}} ({{.Issue}})"
+	DefaultMergeSubject  = "Merge {{.Branch}}: {{.IssueTitle}} ({{.Issue}})"
+	DefaultCommitBody    = "{{with .IssueSummary}}{{.}}\n\n{{end}}Merge-Request: {{.MR}}{{with .Worker}}\nWorker: {{.}}{{end}}"
+)
+
+// CommitMessageData is what commit message templates can refer to.
+type CommitMessageData struct {
+	MR           string // MR bead ID
+	Branch       string // Source branch
+	Target       string // Target branch
+	Worker       string // Agent that did the work
+	PR           int    // Pull request number
+	Issue        string // Source issue ID (the MR ID if unknown)
+	IssueTitle   string // Source issue title (the branch if unknown)
+	IssueType    string // Source issue type (bug, feature, task, ...)
+	IssueSummary string // First paragraph of the source issue's description
+	Strategy     string // squash, merge, or rebase
+}
+
+// ValidateStrategy checks a pr_merge_method.
+func ValidateStrategy(strategy string) error {
+	switch strategy {
+	case "", StrategySquash, StrategyMerge, StrategyRebase:
+		return nil
+	}
+	return fmt.Errorf("pr_merge_method must be squash, merge, or rebase, not %q", strategy)
+}
+
+// ValidateCommitTemplate checks that a commit message template parses and
+// renders.
+func ValidateCommitTemplate(name, text string) error {
+	sample := CommitMessageData{MR: "gt-mr-1", Branch: "polecat/nux/gt-1", Target: "main", Worker: "nux",
+		PR: 1, Issue: "gt-1", IssueTitle: "Fix login", IssueType: "bug", IssueSummary: "Login fails.", Strategy: StrategySquash}
+	if _, err := renderTemplate(name, text, sample); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// RenderCommitMessage renders the subject and body an MR lands with under
+// a strategy; empty templates use the defaults. Rebase landings keep the
+// branch's commit messages, so both are empty.
+func RenderCommitMessage(strategy, subjectTmpl, bodyTmpl string, data CommitMessageData) (string, string, error) {
+	if strategy == "" {
+		strategy = StrategySquash
+	}
+	if strategy == StrategyRebase {
+		return "", "", nil
+	}
+	data.Strategy = strategy
+	if subjectTmpl == "" {
+		subjectTmpl = DefaultSquashSubject
+		if strategy == StrategyMerge {
+			subjectTmpl = DefaultMergeSubject
+		}
+	}
+	if bodyTmpl == "" {
+		bodyTmpl = DefaultCommitBody
+	}
+	subject, err := renderTemplate("commit_subject_template", subjectTmpl, data)
+	if err != nil {
+		return "", "", err
+	}
+	body, err := renderTemplate("commit_body_template", bodyTmpl, data)
+	if err != nil {
+		return "", "", err
+	}
+	// A subject is one line
+	subject, _, _ = strings.Cut(strings.TrimSpace(subject), "\n")
+	return subject, strings.TrimSpace(body), nil
+}
+
+func renderTemplate(name, text string, data CommitMessageData) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// issueSummary returns the first paragraph of an issue description.
+func issueSummary(description string) string {
+	para, _, _ := strings.Cut(strings.TrimSpace(description), "\n\n")
+	return strings.TrimSpace(para)
+}
+
+// commitMessage builds the subject and body an MR lands with, from the MR
+// and its source issue, under the target's strategy and templates. A
+// template that fails to render falls back to the defaults.
+func (e *Engineer) commitMessage(strategy, mrID, branch, target, sourceIssue string, prNumber int) (string, string) {
+	data := CommitMessageData{MR: mrID, Branch: branch, Target: target, PR: prNumber, Issue: sourceIssue}
+	if mrID != "" {
+		if mr, err := e.beads.Show(mrID); err == nil {
+			if fields := beads.ParseMRFields(mr); fields != nil {
+				data.Worker = fields.Worker
+			}
+		}
+	}
+	if sourceIssue != "" {
+		if issue, err := e.beads.Show(sourceIssue); err == nil {
+			data.IssueTitle, data.IssueType = issue.Title, issue.Type
+			data.IssueSummary = issueSummary(issue.Description)
+		}
+	}
+	if data.Issue == "" {
+		data.Issue = mrID
+	}
+	if data.IssueTitle == "" {
+		data.IssueTitle = branch
+	}
+
+	subject, body, err := RenderCommitMessage(strategy, e.config.CommitSubject, e.config.CommitBody, data)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: commit message template: %v (using the default)\n", err)
+		subject, body, _ = RenderCommitMessage(strategy, "", "", data)
+	}
+	return subject, body
+}
@@ -0,0 +1,47 @@
+package refinery
+
+import "testing"
+
+func TestRenderCommitMessage(t *testing.T) {
+	data := CommitMessageData{MR: "gt-mr-1", Branch: "polecat/nux/gt-42", Target: "main", Worker: "gastown/polecats/nux",
+		PR: 7, Issue: "gt-42", IssueTitle: "Fix login redirect", IssueType: "bug", IssueSummary: "Login loops on Safari."}
+	tests := []struct {
+		name, strategy, subjectTmpl, bodyTmpl string
+		wantSubject, wantBody                 string
+		wantErr                               bool
+	}{
+		{"squash default", "", "", "", "Fix login redirect (gt-42)",
+			"Login loops on Safari.\n\nMerge-Request: gt-mr-1\nWorker: gastown/polecats/nux", false},
+		{"merge default", StrategyMerge, "", "", "Merge polecat/nux/gt-42: Fix login redirect (gt-42)",
+			"Login loops on Safari.\n\nMerge-Request: gt-mr-1\nWorker: gastown/polecats/nux", false},
+		{"rebase keeps branch messages", StrategyRebase, "{{.IssueTitle}}", "", "", "", false},
+		{"custom", StrategySquash, "{{.IssueType}}: {{.IssueTitle}}\nextra line", "Closes {{.Issue}} (#{{.PR}})",
+			"bug: Fix login redirect", "Closes gt-42 (#7)", false},
+		{"unknown field", StrategySquash, "{{.Nope}}", "", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subject, body, err := RenderCommitMessage(tt.strategy, tt.subjectTmpl, tt.bodyTmpl, data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if subject != tt.wantSubject || body != tt.wantBody {
+				t.Errorf("got %q / %q, want %q / %q", subject, body, tt.wantSubject, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestValidateCommitTemplate(t *testing.T) {
+	if err := ValidateCommitTemplate("commit_subject_template", "{{.IssueTitle}} ({{.Issue}})"); err != nil {
+		t.Errorf("valid template: %v", err)
+	}
+	for _, bad := range []string{"{{.IssueTitle", "{{.Missing}}"} {
+		if err := ValidateCommitTemplate("commit_subject_template", bad); err == nil {
+			t.Errorf("ValidateCommitTemplate(%q) should fail", bad)
+		}
+	}
+	if err := ValidateStrategy("octopus"); err == nil {
+		t.Error("ValidateStrategy(octopus) should fail")
+	}
+}
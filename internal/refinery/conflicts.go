@@ -0,0 +1,190 @@
+// Package refinery provides the merge queue processing agent.
+// This file predicts conflicts between in-flight MRs and agent branches
+// from the files they change.
+
+package refinery
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+// Effort is a line of work in flight in a rig: an open MR, or an agent
+// branch that hasn't been submitted yet.
+type Effort struct {
+	MR     string   `json:"mr,omitempty"`
+	Branch string   `json:"branch"`
+	Target string   `json:"target"`
+	Worker string   `json:"worker,omitempty"`
+	Files  []string `json:"-"`
+}
+
+// Name returns how to refer to the effort: its MR ID, or its branch.
+func (ef Effort) Name() string {
+	if ef.MR != "" {
+		return ef.MR
+	}
+	return ef.Branch
+}
+
+// Overlap is a pair of efforts into the same target that change the same
+// files. First is ahead in the queue and should land first; Second should
+// rebase onto it rather than race it.
+type Overlap struct {
+	First  Effort   `json:"first"`
+	Second Effort   `json:"second"`
+	Files  []string `json:"files"`
+}
+
+// Suggestion returns the sequencing advice for an overlap.
+func (o Overlap) Suggestion() string {
+	return fmt.Sprintf("land %s first, then rebase %s onto %s", o.First.Name(), o.Second.Name(), o.First.Target)
+}
+
+// FindOverlaps returns every pair of efforts into the same target that
+// change a common file, given efforts in queue order.
+func FindOverlaps(efforts []Effort) []Overlap {
+	var overlaps []Overlap
+	for i := range efforts {
+		files := make(map[string]bool, len(efforts[i].Files))
+		for _, f := range efforts[i].Files {
+			files[f] = true
+		}
+		for j := i + 1; j < len(efforts); j++ {
+			if efforts[i].Target != efforts[j].Target {
+				continue
+			}
+			var shared []string
+			for _, f := range efforts[j].Files {
+				if files[f] {
+					shared = append(shared, f)
+				}
+			}
+			if len(shared) > 0 {
+				sort.Strings(shared)
+				overlaps = append(overlaps, Overlap{First: efforts[i], Second: efforts[j], Files: shared})
+			}
+		}
+	}
+	return overlaps
+}
+
+// OverlapsWith returns the overlaps involving branch.
+func OverlapsWith(overlaps []Overlap, branch string) []Overlap {
+	var out []Overlap
+	for _, o := range overlaps {
+		if o.First.Branch == branch || o.Second.Branch == branch {
+			out = append(out, o)
+		}
+	}
+	return out
+}
+
+// InFlightEfforts returns the rig's open MRs in queue order — the one
+// merging first, then by priority and age — followed by polecat branches
+// with unsubmitted changes, each with the files it changes against its
+// target.
+func (e *Engineer) InFlightEfforts() ([]Effort, error) {
+	var mrs []*beads.Issue
+	for _, status := range []string{"in_progress", "open"} {
+		issues, err := e.beads.List(beads.ListOptions{Status: status, Type: "merge-request", Priority: -1})
+		if err != nil {
+			return nil, fmt.Errorf("listing %s MRs: %w", status, err)
+		}
+		sort.SliceStable(issues, func(i, j int) bool {
+			if issues[i].Priority != issues[j].Priority {
+				return issues[i].Priority < issues[j].Priority
+			}
+			return issues[i].CreatedAt < issues[j].CreatedAt
+		})
+		mrs = append(mrs, issues...)
+	}
+
+	var efforts []Effort
+	submitted := make(map[string]bool)
+	for _, mr := range mrs {
+		fields := beads.ParseMRFields(mr)
+		if fields == nil || fields.Branch == "" {
+			continue
+		}
+		submitted[fields.Branch] = true
+		target := fields.Target
+		if target == "" {
+			target = e.config.TargetBranch
+		}
+		efforts = append(efforts, Effort{MR: mr.ID, Branch: fields.Branch, Target: target, Worker: fields.Worker})
+	}
+
+	branches, err := e.git.ListBranches("polecat/*")
+	if err != nil {
+		return nil, fmt.Errorf("listing polecat branches: %w", err)
+	}
+	for _, branch := range branches {
+		if !submitted[branch] {
+			efforts = append(efforts, Effort{Branch: branch, Target: e.config.TargetBranch, Worker: branchWorker(branch)})
+		}
+	}
+
+	inFlight := efforts[:0]
+	for _, ef := range efforts {
+		changes, err := e.git.ChangedFiles(e.resolveRef(ef.Target), e.resolveRef(ef.Branch))
+		if err != nil {
+			// Branches deleted since their MR merged, or never pushed.
+			continue
+		}
+		for _, c := range changes {
+			ef.Files = append(ef.Files, c.Path)
+			if c.OldPath != "" {
+				ef.Files = append(ef.Files, c.OldPath)
+			}
+		}
+		if len(ef.Files) > 0 {
+			inFlight = append(inFlight, ef)
+		}
+	}
+	return inFlight, nil
+}
+
+// PredictConflicts returns the overlaps between the rig's in-flight efforts.
+func (e *Engineer) PredictConflicts() ([]Overlap, error) {
+	efforts, err := e.InFlightEfforts()
+	if err != nil {
+		return nil, err
+	}
+	return FindOverlaps(efforts), nil
+}
+
+// ConflictsFor returns the overlaps between ef, about to join the back of
+// the queue, and the rig's other in-flight efforts.
+func (e *Engineer) ConflictsFor(ef Effort) ([]Overlap, error) {
+	efforts, err := e.InFlightEfforts()
+	if err != nil {
+		return nil, err
+	}
+	others := efforts[:0]
+	for _, o := range efforts {
+		if o.Branch != ef.Branch {
+			others = append(others, o)
+		}
+	}
+	return OverlapsWith(FindOverlaps(append(others, ef)), ef.Branch), nil
+}
+
+// branchWorker returns the polecat name from a default polecat branch name:
+// "polecat/<name>/<issue>@<ts>" or "polecat/<name>-<ts>".
+func branchWorker(branch string) string {
+	parts := strings.Split(branch, "/")
+	switch {
+	case len(parts) < 2 || parts[0] != "polecat":
+		return ""
+	case len(parts) > 2:
+		return parts[1]
+	}
+	if i := strings.LastIndex(parts[1], "-"); i > 0 {
+		return parts[1][:i]
+	}
+	return parts[1]
+}
@@ -0,0 +1,57 @@
+package refinery
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindOverlaps(t *testing.T) {
+	efforts := []Effort{
+		{MR: "gt-mr-1", Branch: "polecat/nux/gt-1@a", Target: "main", Files: []string{"auth.go", "auth_test.go", "README.md"}},
+		{MR: "gt-mr-2", Branch: "polecat/toast/gt-2@b", Target: "main", Files: []string{"api.go"}},
+		{MR: "gt-mr-3", Branch: "polecat/ace/gt-3@c", Target: "integration/gt-epic", Files: []string{"auth.go"}},
+		{Branch: "polecat/slit-k3x9", Target: "main", Files: []string{"auth_test.go", "auth.go", "api.go"}},
+	}
+	got := FindOverlaps(efforts)
+	want := []struct {
+		first, second string
+		files         []string
+	}{
+		{"gt-mr-1", "polecat/slit-k3x9", []string{"auth.go", "auth_test.go"}},
+		{"gt-mr-2", "polecat/slit-k3x9", []string{"api.go"}},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("FindOverlaps() = %+v, want %d overlaps", got, len(want))
+	}
+	for i, w := range want {
+		if got[i].First.Name() != w.first || got[i].Second.Name() != w.second || !reflect.DeepEqual(got[i].Files, w.files) {
+			t.Errorf("overlap %d = %s/%s %v, want %s/%s %v", i,
+				got[i].First.Name(), got[i].Second.Name(), got[i].Files, w.first, w.second, w.files)
+		}
+	}
+	if s := got[0].Suggestion(); s != "land gt-mr-1 first, then rebase polecat/slit-k3x9 onto main" {
+		t.Errorf("Suggestion() = %q", s)
+	}
+
+	if n := len(OverlapsWith(got, "polecat/toast/gt-2@b")); n != 1 {
+		t.Errorf("OverlapsWith(toast) = %d overlaps, want 1", n)
+	}
+	if n := len(OverlapsWith(got, "polecat/ace/gt-3@c")); n != 0 {
+		t.Errorf("OverlapsWith(ace) = %d overlaps, want 0 (different target)", n)
+	}
+}
+
+func TestBranchWorker(t *testing.T) {
+	tests := map[string]string{
+		"polecat/nux/gt-abc@k3x9": "nux",
+		"polecat/nux-k3x9":        "nux",
+		"polecat/nux":             "nux",
+		"feature/login":           "",
+		"main":                    "",
+	}
+	for branch, want := range tests {
+		if got := branchWorker(branch); got != want {
+			t.Errorf("branchWorker(%q) = %q, want %q", branch, got, want)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+// Package refinery provides the merge queue processing agent.
+// This file enforces the rig's branch and commit conventions on MRs.
+
+package refinery
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/conventions"
+)
+
+// CheckConventions checks an MR's branch name and commit messages against
+// the rig's conventions. It returns nil when the rig has none configured or
+// the MR complies, and an error when the rig settings can't be read, since
+// they may configure conventions.
+func (e *Engineer) CheckConventions(branch, target, sourceIssue string) ([]conventions.Violation, error) {
+	if e.rig == nil {
+		return nil, nil
+	}
+	settings, err := config.LoadRigSettings(config.RigSettingsPath(e.rig.Path))
+	if errors.Is(err, config.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading rig settings: %w", err)
+	}
+	if !conventions.Enabled(settings.Conventions) {
+		return nil, nil
+	}
+	commits, err := e.git.CommitMessages(e.resolveRef(target), e.resolveRef(branch))
+	if err != nil {
+		return nil, fmt.Errorf("reading commits on %s: %w", branch, err)
+	}
+	return conventions.Check(settings.Conventions, branch, sourceIssue, commits)
+}
+
+// enforceConventions rejects an MR that breaks the rig's conventions. It
+// returns nil when the MR may proceed to merge. Like the guardrails, it
+// fails closed: an MR whose check can't run is failed rather than merged.
+func (e *Engineer) enforceConventions(branch, target, sourceIssue string) *ProcessResult {
+	violations, err := e.CheckConventions(branch, target, sourceIssue)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Convention check failed: %v\n", err)
+		return &ProcessResult{
+			Success: false,
+			Error:   fmt.Sprintf("convention check failed: %v", err),
+		}
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Rejected: %d convention violation(s)\n", len(violations))
+	return &ProcessResult{
+		Success:             false,
+		ConventionViolation: true,
+		Error:               conventions.Format(violations),
+	}
+}
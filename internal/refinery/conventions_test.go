@@ -0,0 +1,70 @@
+package refinery
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+func TestEnforceConventions(t *testing.T) {
+	rigPath := t.TempDir()
+	clone := filepath.Join(rigPath, "refinery", "rig")
+	if err := os.MkdirAll(clone, 0755); err != nil {
+		t.Fatal(err)
+	}
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = clone
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@test.com",
+			"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@test.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	run("init", "-b", "main")
+	run("commit", "--allow-empty", "-m", "initial")
+	run("checkout", "-b", "polecat/nux")
+	run("commit", "--allow-empty", "-m", "feat: add widget")
+
+	e := &Engineer{
+		rig:     &rig.Rig{Name: "test-rig", Path: rigPath},
+		config:  DefaultMergeQueueConfig(),
+		workDir: clone,
+		git:     git.NewGit(clone),
+		output:  io.Discard,
+	}
+
+	// No settings file: nothing is checked.
+	if result := e.enforceConventions("polecat/nux", "main", ""); result != nil {
+		t.Errorf("enforceConventions(unconfigured) = %+v, want nil", result)
+	}
+
+	settings := config.NewRigSettings()
+	settings.Conventions = &config.ConventionsConfig{CommitStyle: "conventional"}
+	if err := config.SaveRigSettings(config.RigSettingsPath(rigPath), settings); err != nil {
+		t.Fatal(err)
+	}
+	if result := e.enforceConventions("polecat/nux", "main", ""); result != nil {
+		t.Errorf("enforceConventions(compliant) = %+v, want nil", result)
+	}
+
+	// A check that can't run fails the MR instead of letting it through
+	if failed := e.enforceConventions("polecat/missing", "main", ""); failed == nil || failed.Success || !strings.Contains(failed.Error, "convention check failed") {
+		t.Errorf("enforceConventions(missing branch) = %+v, want a failure", failed)
+	}
+	if err := os.WriteFile(config.RigSettingsPath(rigPath), []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if failed := e.enforceConventions("polecat/nux", "main", ""); failed == nil || failed.Success || !strings.Contains(failed.Error, "loading rig settings") {
+		t.Errorf("enforceConventions(broken settings) = %+v, want a failure", failed)
+	}
+}
@@ -0,0 +1,325 @@
+// Package refinery provides the merge queue processing agent.
+// This file orders merges by the dependencies between MRs.
+
+package refinery
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/codeowners"
+)
+
+// Dependencies is what each open MR must land after.
+type Dependencies struct {
+	// Parents maps an MR ID to the open MRs it must land after.
+	Parents map[string][]string
+	// Waiting maps an MR ID to why it can't land yet; MRs absent from it
+	// are free to merge.
+	Waiting map[string][]string
+}
+
+// DeclaredDependencies returns the MR or issue IDs an MR was submitted
+// with --depends-on.
+func DeclaredDependencies(fields *beads.MRFields) []string {
+	if fields == nil {
+		return nil
+	}
+	return codeowners.SplitList(fields.DependsOn)
+}
+
+// sourceDependencies returns the open issues a source issue depends on.
+// Parent-child and related links don't order work, so only blocking
+// dependencies count.
+func sourceDependencies(src *beads.Issue) []string {
+	if len(src.Dependencies) == 0 {
+		return src.DependsOn
+	}
+	var ids []string
+	for _, dep := range src.Dependencies {
+		if dep.Status == "closed" {
+			continue
+		}
+		if dep.DependencyType == "" || dep.DependencyType == "blocks" {
+			ids = append(ids, dep.ID)
+		}
+	}
+	return ids
+}
+
+// ResolveDependencies works out what each candidate must land after. An MR
+// depends on the MRs and issues it declared, and on the issues its source
+// issue depends on; an issue dependency is satisfied by the issue's open MR
+// landing, or by the issue being closed. Lookup holds the issues and closed
+// MRs referenced that aren't candidates. Declared references that can't be
+// found hold the MR; source issue dependencies that can't be found (e.g.
+// in another rig's beads) don't.
+func ResolveDependencies(candidates []QueueCandidate, lookup map[string]*beads.Issue) Dependencies {
+	deps := Dependencies{Parents: make(map[string][]string), Waiting: make(map[string][]string)}
+	open := make(map[string]QueueCandidate, len(candidates))
+	bySource := make(map[string]string)
+	for _, c := range candidates {
+		open[c.Issue.ID] = c
+		if c.Fields != nil && c.Fields.SourceIssue != "" {
+			bySource[c.Fields.SourceIssue] = c.Issue.ID
+		}
+	}
+
+	for _, c := range candidates {
+		id := c.Issue.ID
+		var parents, waiting []string
+		resolve := func(ref string, declared bool) {
+			if ref == id || (c.Fields != nil && ref == c.Fields.SourceIssue) {
+				return
+			}
+			if _, ok := open[ref]; ok {
+				parents = appendUnique(parents, ref)
+				return
+			}
+			if mrID, ok := bySource[ref]; ok {
+				parents = appendUnique(parents, mrID)
+				return
+			}
+			issue := lookup[ref]
+			switch {
+			case issue == nil:
+				if declared {
+					waiting = append(waiting, fmt.Sprintf("depends on %s, which can't be found", ref))
+				}
+			case beads.HasLabel(issue, "gt:merge-request") || issue.Type == "merge-request":
+				if issue.Status != "closed" {
+					waiting = append(waiting, fmt.Sprintf("waiting for MR %s (%s) to land", ref, issue.Status))
+				} else if f := beads.ParseMRFields(issue); f == nil || f.CloseReason != string(CloseReasonMerged) {
+					waiting = append(waiting, fmt.Sprintf("depends on MR %s, which was closed without merging", ref))
+				}
+			case issue.Status != "closed":
+				waiting = append(waiting, fmt.Sprintf("depends on %s (%s), which has no MR in the queue", ref, issue.Status))
+			}
+		}
+		for _, ref := range DeclaredDependencies(c.Fields) {
+			resolve(ref, true)
+		}
+		for _, ref := range c.SourceDeps {
+			resolve(ref, false)
+		}
+
+		sort.Strings(parents)
+		if len(parents) > 0 {
+			deps.Parents[id] = parents
+		}
+		for _, p := range parents {
+			waiting = append(waiting, fmt.Sprintf("waiting for parent MR %s%s to land", p, branchOf(open[p])))
+		}
+		if len(waiting) > 0 {
+			deps.Waiting[id] = waiting
+		}
+	}
+
+	for _, cycle := range findCycles(deps.Parents) {
+		msg := "dependency cycle: " + strings.Join(append(cycle, cycle[0]), " → ")
+		for _, id := range cycle {
+			deps.Waiting[id] = append([]string{msg}, deps.Waiting[id]...)
+		}
+	}
+	return deps
+}
+
+// ApplyDependencies reorders a queue so no MR comes before an MR it
+// depends on, moving children down only as far as needed, and holds MRs
+// that are waiting on a dependency. MRs in a cycle go last.
+func ApplyDependencies(entries []QueueEntry, deps Dependencies) []QueueEntry {
+	ordered := make([]QueueEntry, 0, len(entries))
+	placed := make(map[string]bool, len(entries))
+	queued := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		queued[e.ID] = true
+	}
+	ready := func(id string) bool {
+		for _, p := range deps.Parents[id] {
+			if queued[p] && !placed[p] {
+				return false
+			}
+		}
+		return true
+	}
+
+	remaining := entries
+	for len(remaining) > 0 {
+		next := -1
+		for i, e := range remaining {
+			if ready(e.ID) {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			// Only cycles are left
+			ordered = append(ordered, remaining...)
+			break
+		}
+		ordered = append(ordered, remaining[next])
+		placed[remaining[next].ID] = true
+		remaining = append(remaining[:next:next], remaining[next+1:]...)
+	}
+
+	for i := range ordered {
+		e := &ordered[i]
+		e.Position = i + 1
+		if parents := deps.Parents[e.ID]; len(parents) > 0 {
+			e.Reasons = append([]string{"lands after " + strings.Join(parents, ", ")}, e.Reasons...)
+		}
+		if waiting := deps.Waiting[e.ID]; len(waiting) > 0 && e.Held == "" {
+			e.Held = waiting[0]
+		}
+	}
+	return ordered
+}
+
+// findCycles returns each dependency cycle once, as the MR IDs around it
+// starting from the smallest.
+func findCycles(parents map[string][]string) [][]string {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int)
+	var stack []string
+	var cycles [][]string
+	var visit func(id string)
+	visit = func(id string) {
+		state[id] = visiting
+		stack = append(stack, id)
+		for _, p := range parents[id] {
+			switch state[p] {
+			case unvisited:
+				visit(p)
+			case visiting:
+				for i := len(stack) - 1; i >= 0; i-- {
+					if stack[i] == p {
+						cycles = append(cycles, rotateToMin(append([]string(nil), stack[i:]...)))
+						break
+					}
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[id] = done
+	}
+
+	ids := make([]string, 0, len(parents))
+	for id := range parents {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if state[id] == unvisited {
+			visit(id)
+		}
+	}
+	return cycles
+}
+
+func rotateToMin(ids []string) []string {
+	first := 0
+	for i, id := range ids {
+		if id < ids[first] {
+			first = i
+		}
+	}
+	return append(ids[first:], ids[:first]...)
+}
+
+func branchOf(c QueueCandidate) string {
+	if c.Fields == nil || c.Fields.Branch == "" {
+		return ""
+	}
+	return " (" + c.Fields.Branch + ")"
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, x := range list {
+		if x == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+// MergeDependencies resolves the dependencies of every open MR, plus the
+// given MRs (e.g. ones claimed for processing that are no longer open).
+func (e *Engineer) MergeDependencies(extra ...*beads.Issue) (Dependencies, error) {
+	mrs, err := e.beads.List(beads.ListOptions{Type: "merge-request", Status: "open", Priority: -1})
+	if err != nil {
+		return Dependencies{}, fmt.Errorf("listing open MRs: %w", err)
+	}
+	open := make([]*beads.Issue, 0, len(mrs)+len(extra))
+	seen := make(map[string]bool)
+	for _, mr := range append(mrs, extra...) {
+		if mr == nil || seen[mr.ID] || mr.Status == "closed" {
+			continue
+		}
+		seen[mr.ID] = true
+		open = append(open, mr)
+	}
+	return e.resolveDependencies(QueueCandidates(e.beads, open))
+}
+
+// resolveDependencies looks up the issues and closed MRs the candidates
+// refer to, then resolves their dependencies.
+func (e *Engineer) resolveDependencies(candidates []QueueCandidate) (Dependencies, error) {
+	known := make(map[string]bool)
+	for _, c := range candidates {
+		known[c.Issue.ID] = true
+		if c.Fields != nil && c.Fields.SourceIssue != "" {
+			known[c.Fields.SourceIssue] = true
+		}
+	}
+	var refs []string
+	for _, c := range candidates {
+		for _, ref := range append(DeclaredDependencies(c.Fields), c.SourceDeps...) {
+			if !known[ref] {
+				known[ref] = true
+				refs = append(refs, ref)
+			}
+		}
+	}
+	lookup := map[string]*beads.Issue{}
+	if len(refs) > 0 {
+		var err error
+		if lookup, err = e.beads.ShowMultiple(refs); err != nil {
+			return Dependencies{}, fmt.Errorf("looking up MR dependencies: %w", err)
+		}
+	}
+	return ResolveDependencies(candidates, lookup), nil
+}
+
+// enforceDependencies holds an MR until every MR and issue it depends on
+// has landed. An MR whose dependencies can't be looked up is held too,
+// rather than merged ahead of them.
+func (e *Engineer) enforceDependencies(mrID string) *ProcessResult {
+	if mrID == "" {
+		return nil
+	}
+	mr, err := e.beads.Show(mrID)
+	if err != nil {
+		return e.holdForDependencies(err)
+	}
+	deps, err := e.MergeDependencies(mr)
+	if err != nil {
+		return e.holdForDependencies(err)
+	}
+	waiting := deps.Waiting[mrID]
+	if len(waiting) == 0 {
+		return nil
+	}
+	return &ProcessResult{Deferred: true, Error: "not merging before its dependencies: " + strings.Join(waiting, "; ")}
+}
+
+// holdForDependencies defers an MR whose dependency check failed.
+func (e *Engineer) holdForDependencies(err error) *ProcessResult {
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Dependency check failed, holding MR: %v\n", err)
+	return &ProcessResult{Deferred: true, Error: fmt.Sprintf("dependency check failed: %v", err)}
+}
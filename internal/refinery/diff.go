@@ -0,0 +1,103 @@
+// Package refinery provides the merge queue processing agent.
+// This file computes an MR's diff against its target for reviewers.
+
+package refinery
+
+import (
+	"fmt"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+// MRDiff is what a merge request changes relative to its target.
+type MRDiff struct {
+	MR     string `json:"mr"`
+	Branch string `json:"branch"`
+	Target string `json:"target"`
+	Base   string `json:"base"` // Target ref the diff was taken against
+	Head   string `json:"head"` // Branch ref
+
+	// Merged is set when the diff is of a trial merge into the current
+	// target rather than the branch's changes since it diverged.
+	Merged    bool     `json:"merged"`
+	Conflicts []string `json:"conflicts,omitempty"` // Files the trial merge conflicted on
+
+	Stat  *git.DiffStat    `json:"stat"`
+	Files []git.FileChange `json:"files"`
+	Patch string           `json:"-"`
+}
+
+// DiffMR computes mr's diff against its target after fetching origin.
+//
+// By default it is the three-dot diff: the branch's changes since it
+// diverged from the target, as a forge shows them. With merged set, the
+// branch is trial-merged into the current target in a throwaway worktree
+// and the diff is exactly what merging would land, including any
+// conflicts (in which case Patch and Files are left empty).
+func (e *Engineer) DiffMR(mr *beads.Issue, merged bool) (*MRDiff, error) {
+	fields := beads.ParseMRFields(mr)
+	if fields == nil || fields.Branch == "" {
+		return nil, fmt.Errorf("%s has no branch field; is it a merge request?", mr.ID)
+	}
+	target := fields.Target
+	if target == "" {
+		target = e.config.TargetBranch
+	}
+
+	_ = e.git.Fetch("origin")
+	d := &MRDiff{
+		MR:     mr.ID,
+		Branch: fields.Branch,
+		Target: target,
+		Base:   e.resolveRef(target),
+		Head:   e.resolveRef(fields.Branch),
+		Merged: merged,
+		Files:  []git.FileChange{},
+	}
+	if merged {
+		return d, e.trialMergeDiff(d)
+	}
+
+	var err error
+	if d.Patch, err = e.git.Diff(d.Base, d.Head); err != nil {
+		return nil, fmt.Errorf("diffing %s against %s: %w", d.Branch, target, err)
+	}
+	if d.Stat, err = e.git.DiffStat(d.Base, d.Head); err != nil {
+		return nil, err
+	}
+	if d.Files, err = e.git.ChangedFiles(d.Base, d.Head); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// trialMergeDiff fills d from a no-commit merge of d.Head into d.Base in a
+// scratch worktree borrowed from the pool.
+func (e *Engineer) trialMergeDiff(d *MRDiff) error {
+	scratch, err := e.Worktrees().Borrow(d.Base)
+	if err != nil {
+		return fmt.Errorf("creating trial merge worktree: %w", err)
+	}
+	defer scratch.Return()
+
+	wt := scratch.Git
+	conflicts, err := wt.TrialMerge(d.Head)
+	if err != nil {
+		return fmt.Errorf("trial merging %s into %s: %w", d.Branch, d.Target, err)
+	}
+	if len(conflicts) > 0 {
+		d.Conflicts = conflicts
+		d.Stat = &git.DiffStat{}
+		return nil
+	}
+	patch, stat, files, err := wt.StagedDiff()
+	if err != nil {
+		return err
+	}
+	d.Patch, d.Stat, d.Files = patch, stat, files
+	if d.Files == nil {
+		d.Files = []git.FileChange{}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package refinery
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+func TestDiffMR(t *testing.T) {
+	tmp := t.TempDir()
+	origin := filepath.Join(tmp, "origin.git")
+	clone := filepath.Join(tmp, "clone")
+	run := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@test.com",
+			"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@test.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(clone, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run(tmp, "init", "--bare", "-b", "main", origin)
+	run(tmp, "clone", origin, clone)
+	run(clone, "config", "user.email", "test@test.com")
+	run(clone, "config", "user.name", "Test")
+	run(clone, "checkout", "-b", "main")
+	write("app.txt", "v1\n")
+	write("old.txt", "keep\n")
+	run(clone, "add", ".")
+	run(clone, "commit", "-m", "initial")
+	run(clone, "push", "origin", "main")
+
+	// feature adds a file, renames one, and edits app.txt.
+	run(clone, "checkout", "-b", "feature")
+	write("new.txt", "hello\n")
+	write("app.txt", "v2\n")
+	run(clone, "mv", "old.txt", "renamed.txt")
+	run(clone, "add", ".")
+	run(clone, "commit", "-m", "feature")
+	run(clone, "push", "origin", "feature")
+
+	// main moves on with an unrelated file, so a plain two-dot diff would
+	// wrongly show it as deleted.
+	run(clone, "checkout", "main")
+	write("main.txt", "later\n")
+	run(clone, "add", ".")
+	run(clone, "commit", "-m", "main moves")
+	run(clone, "push", "origin", "main")
+
+	e := &Engineer{
+		rig:     &rig.Rig{Name: "test-rig", Path: tmp},
+		config:  DefaultMergeQueueConfig(),
+		workDir: clone,
+		git:     git.NewGit(clone),
+		output:  io.Discard,
+	}
+	mr := &beads.Issue{ID: "gt-mr1", Description: "branch: feature\ntarget: main"}
+
+	for _, merged := range []bool{false, true} {
+		d, err := e.DiffMR(mr, merged)
+		if err != nil {
+			t.Fatalf("DiffMR(merged=%v): %v", merged, err)
+		}
+		got := map[string]string{}
+		for _, f := range d.Files {
+			got[f.Path] = f.Status
+		}
+		want := map[string]string{"app.txt": "M", "new.txt": "A", "renamed.txt": "R"}
+		if len(got) != len(want) {
+			t.Errorf("merged=%v: files = %v, want %v", merged, got, want)
+		}
+		for path, status := range want {
+			if got[path] != status {
+				t.Errorf("merged=%v: %s status = %q, want %q", merged, path, got[path], status)
+			}
+		}
+		if d.Stat == nil || d.Stat.Files != 3 {
+			t.Errorf("merged=%v: stat = %+v, want 3 files", merged, d.Stat)
+		}
+		if !strings.Contains(d.Patch, "+v2") {
+			t.Errorf("merged=%v: patch missing +v2:\n%s", merged, d.Patch)
+		}
+	}
+
+	// A conflicting change on main makes the trial merge report conflicts.
+	write("app.txt", "v3\n")
+	run(clone, "commit", "-am", "conflict")
+	run(clone, "push", "origin", "main")
+	d, err := e.DiffMR(mr, true)
+	if err != nil {
+		t.Fatalf("DiffMR(conflict): %v", err)
+	}
+	if len(d.Conflicts) != 1 || d.Conflicts[0] != "app.txt" {
+		t.Errorf("conflicts = %v, want [app.txt]", d.Conflicts)
+	}
+	// Only the clone and its pooled scratch worktree remain
+	if out, _ := exec.Command("git", "-C", clone, "worktree", "list").Output(); strings.Count(string(out), "\n") != 2 ||
+		!strings.Contains(string(out), e.Worktrees().Dir()) {
+		t.Errorf("trial merge worktree left behind:\n%s", out)
+	}
+}
@@ -53,7 +53,7 @@ func TestValidateTestCommand(t *testing.T) {
 }
 
 func TestRunTests_EmptyCommand(t *testing.T) {
-	// Verify that runTests returns a failure when TestCommand is empty,
+	// Verify that RunTests returns a failure when TestCommand is empty,
 	// rather than silently succeeding or executing a blank shell command.
 	e := &Engineer{
 		config: &MergeQueueConfig{
@@ -61,7 +61,7 @@ func TestRunTests_EmptyCommand(t *testing.T) {
 		},
 	}
 
-	result := e.runTests(nil)
+	result := e.RunTests(nil)
 	if result.Success {
 		t.Error("expected failure for empty test command, got success")
 	}
@@ -77,7 +77,7 @@ func TestRunTests_WhitespaceCommand(t *testing.T) {
 		},
 	}
 
-	result := e.runTests(nil)
+	result := e.RunTests(nil)
 	if result.Success {
 		t.Error("expected failure for whitespace-only test command, got success")
 	}
@@ -0,0 +1,118 @@
+// Package refinery provides the merge queue processing agent.
+// This file gates the merge queue while a rig is frozen.
+
+package refinery
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/mq"
+)
+
+// QueueFreeze describes why a rig's merge queue is frozen. While frozen,
+// only hotfix MRs are ready for processing.
+type QueueFreeze struct {
+	Incidents  []string   `json:"incidents,omitempty"`   // Open incidents freezing the queue
+	Manual     *mq.Freeze `json:"manual,omitempty"`      // Active gt mq freeze
+	Window     string     `json:"window,omitempty"`      // Active scheduled freeze window
+	WindowEnds time.Time  `json:"window_ends,omitempty"` // When the active window ends
+}
+
+// Frozen reports whether anything is freezing the queue.
+func (f *QueueFreeze) Frozen() bool {
+	return f != nil && (len(f.Incidents) > 0 || f.Manual != nil || f.Window != "")
+}
+
+// Reason describes the freeze for display.
+func (f *QueueFreeze) Reason() string {
+	if !f.Frozen() {
+		return ""
+	}
+	var reasons []string
+	if f.Manual != nil {
+		r := "frozen"
+		if f.Manual.FrozenBy != "" {
+			r += " by " + f.Manual.FrozenBy
+		}
+		if f.Manual.Reason != "" {
+			r += ": " + f.Manual.Reason
+		}
+		if !f.Manual.Until.IsZero() {
+			r += " (until " + f.Manual.Until.Local().Format("Mon Jan 2 15:04") + ")"
+		}
+		reasons = append(reasons, r)
+	}
+	if f.Window != "" {
+		reasons = append(reasons, fmt.Sprintf("freeze window %s (ends %s)", f.Window, f.WindowEnds.Local().Format("Mon Jan 2 15:04")))
+	}
+	if len(f.Incidents) > 0 {
+		reasons = append(reasons, "incident "+strings.Join(f.Incidents, ", "))
+	}
+	return strings.Join(reasons, "; ")
+}
+
+// QueueFreeze returns the rig's current queue freeze: a manual freeze that
+// hasn't expired, an active scheduled window, or open incidents.
+func (e *Engineer) QueueFreeze() (*QueueFreeze, error) {
+	return e.queueFreeze(e.config.FreezeWindows)
+}
+
+func (e *Engineer) queueFreeze(windows []string) (*QueueFreeze, error) {
+	now := time.Now()
+	freeze := &QueueFreeze{}
+
+	manual, err := mq.LoadFreeze(e.rig.Path)
+	if err != nil {
+		return nil, err
+	}
+	if manual.Active(now) {
+		freeze.Manual = manual
+	}
+
+	window, err := mq.ActiveWindow(windows, now)
+	if err != nil {
+		return nil, err
+	}
+	if window != nil {
+		freeze.Window = window.Spec
+		freeze.WindowEnds = window.EndAfter(now)
+	}
+
+	incidents, err := e.beads.QueueFreezingIncidents()
+	if err != nil {
+		return nil, fmt.Errorf("checking incidents: %w", err)
+	}
+	for _, issue := range incidents {
+		freeze.Incidents = append(freeze.Incidents, issue.ID)
+	}
+	return freeze, nil
+}
+
+// IsHotfix reports whether an MR may merge through a freeze: the MR bead or
+// its source issue is labeled hotfix. lookup resolves the source issue and
+// may return nil.
+func IsHotfix(mr *beads.Issue, lookup func(id string) *beads.Issue) bool {
+	if beads.HasLabel(mr, beads.HotfixLabel) {
+		return true
+	}
+	fields := beads.ParseMRFields(mr)
+	if fields == nil || fields.SourceIssue == "" || lookup == nil {
+		return false
+	}
+	source := lookup(fields.SourceIssue)
+	return source != nil && beads.HasLabel(source, beads.HotfixLabel)
+}
+
+// isHotfix is IsHotfix with source issues resolved from the rig's beads.
+func (e *Engineer) isHotfix(mr *beads.Issue) bool {
+	return IsHotfix(mr, func(id string) *beads.Issue {
+		issue, err := e.beads.Show(id)
+		if err != nil {
+			return nil
+		}
+		return issue
+	})
+}
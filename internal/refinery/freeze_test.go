@@ -0,0 +1,49 @@
+package refinery
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/mq"
+)
+
+func TestIsHotfix(t *testing.T) {
+	issues := map[string]*beads.Issue{
+		"gt-hot":  {ID: "gt-hot", Labels: []string{"hotfix"}},
+		"gt-cold": {ID: "gt-cold", Labels: []string{"bug"}},
+	}
+	lookup := func(id string) *beads.Issue { return issues[id] }
+
+	tests := []struct {
+		name string
+		mr   *beads.Issue
+		want bool
+	}{
+		{"labeled MR", &beads.Issue{Labels: []string{"gt:merge-request", "hotfix"}}, true},
+		{"hotfix source issue", &beads.Issue{Description: "branch: polecat/a\nsource_issue: gt-hot"}, true},
+		{"regular source issue", &beads.Issue{Description: "branch: polecat/b\nsource_issue: gt-cold"}, false},
+		{"unknown source issue", &beads.Issue{Description: "branch: polecat/c\nsource_issue: gt-gone"}, false},
+		{"no fields", &beads.Issue{}, false},
+	}
+	for _, tt := range tests {
+		if got := IsHotfix(tt.mr, lookup); got != tt.want {
+			t.Errorf("%s: IsHotfix = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	var none *QueueFreeze
+	if none.Frozen() || (&QueueFreeze{}).Frozen() {
+		t.Error("empty freeze reported as frozen")
+	}
+	if f := (&QueueFreeze{Incidents: []string{"gt-inc1"}}); !f.Frozen() || f.Reason() != "incident gt-inc1" {
+		t.Errorf("incident freeze = %v %q", f.Frozen(), f.Reason())
+	}
+	manual := &QueueFreeze{Manual: &mq.Freeze{FrozenBy: "mayor", Reason: "release"}, Incidents: []string{"gt-inc1"}}
+	if !manual.Frozen() || manual.Reason() != "frozen by mayor: release; incident gt-inc1" {
+		t.Errorf("manual freeze = %v %q", manual.Frozen(), manual.Reason())
+	}
+	if f := (&QueueFreeze{Window: "22:00-06:00"}); !f.Frozen() || !strings.HasPrefix(f.Reason(), "freeze window 22:00-06:00") {
+		t.Errorf("window freeze = %v %q", f.Frozen(), f.Reason())
+	}
+}
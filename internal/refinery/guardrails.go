@@ -0,0 +1,124 @@
+// Package refinery provides the merge queue processing agent.
+// This file enforces the rig's file guardrails on MRs.
+
+package refinery
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/guardrails"
+)
+
+// GuardrailsSection is the MR bead description section listing guardrail
+// warnings.
+const GuardrailsSection = "guardrails"
+
+// CheckGuardrails checks the files an MR adds or changes against the rig's
+// guardrails. It returns nil when the rig has none configured or the MR
+// trips none, and an error when the rig settings can't be read, since
+// they may configure guardrails.
+func (e *Engineer) CheckGuardrails(branch, target string) ([]guardrails.Violation, error) {
+	if e.rig == nil {
+		return nil, nil
+	}
+	settings, err := config.LoadRigSettings(config.RigSettingsPath(e.rig.Path))
+	if errors.Is(err, config.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading rig settings: %w", err)
+	}
+	if settings.Guardrails == nil {
+		return nil, nil
+	}
+	base, head := e.resolveRef(target), e.resolveRef(branch)
+	changes, err := e.git.ChangedFiles(base, head)
+	if err != nil {
+		return nil, fmt.Errorf("reading diff of %s: %w", branch, err)
+	}
+	var paths []string
+	for _, c := range changes {
+		if c.Status != "D" {
+			paths = append(paths, c.Path)
+		}
+	}
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	sizes, err := e.git.FileSizes(head, paths)
+	if err != nil {
+		return nil, fmt.Errorf("reading file sizes on %s: %w", branch, err)
+	}
+	baseSizes, err := e.git.FileSizes(base, paths)
+	if err != nil {
+		return nil, fmt.Errorf("reading file sizes on %s: %w", target, err)
+	}
+	diff, err := e.git.Diff(base, head)
+	if err != nil {
+		return nil, fmt.Errorf("reading diff of %s: %w", branch, err)
+	}
+	parsed := guardrails.ParseDiff(diff)
+
+	files := make([]guardrails.File, 0, len(paths))
+	for _, c := range changes {
+		if c.Status == "D" {
+			continue
+		}
+		f := guardrails.File{Path: c.Path, Status: c.Status, Size: sizes[c.Path], BaseSize: baseSizes[c.Path]}
+		if d := parsed[c.Path]; d != nil {
+			f.Binary, f.Added = d.Binary, d.Added
+		}
+		files = append(files, f)
+	}
+	return guardrails.Check(settings.Guardrails, files)
+}
+
+// enforceGuardrails rejects an MR that trips a rejecting guardrail and
+// flags warnings on the MR bead. It returns nil when the MR may proceed to
+// merge. Like the license gate, it fails closed: an MR whose check can't
+// run is failed rather than merged unchecked.
+func (e *Engineer) enforceGuardrails(mrID, branch, target string) *ProcessResult {
+	violations, err := e.CheckGuardrails(branch, target)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Guardrail check failed: %v\n", err)
+		return &ProcessResult{
+			Success: false,
+			Error:   fmt.Sprintf("guardrail check failed: %v", err),
+		}
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	if !guardrails.Rejected(violations) {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] %d guardrail warning(s)\n", len(violations))
+		if mrID != "" {
+			if err := e.recordGuardrailWarnings(mrID, violations); err != nil {
+				_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: %v\n", err)
+			}
+		}
+		return nil
+	}
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Rejected: %d guardrail violation(s)\n", len(violations))
+	return &ProcessResult{
+		Success:            false,
+		GuardrailViolation: true,
+		Error:              guardrails.Format(violations),
+	}
+}
+
+// recordGuardrailWarnings lists guardrail warnings in the MR bead's
+// guardrails section.
+func (e *Engineer) recordGuardrailWarnings(mrID string, violations []guardrails.Violation) error {
+	mrBead, err := e.beads.Show(mrID)
+	if err != nil {
+		return fmt.Errorf("fetching MR bead %s: %w", mrID, err)
+	}
+	newDesc := beads.SetDescriptionSection(mrBead.Description, GuardrailsSection, guardrails.Format(violations))
+	if err := e.beads.Update(mrID, beads.UpdateOptions{Description: &newDesc}); err != nil {
+		return fmt.Errorf("updating MR %s with guardrail warnings: %w", mrID, err)
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+// Package refinery provides the merge queue processing agent.
+// This file gates merges on the licenses of the dependencies an MR adds.
+
+package refinery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/sbom"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// LicenseScanSection is the MR bead description section holding the
+// license scan.
+const LicenseScanSection = "license-scan"
+
+// licenseScanMaxItems caps the components listed on the bead.
+const licenseScanMaxItems = 30
+
+// ValidateLicenseScan checks a merge_queue.license_scan config.
+func ValidateLicenseScan(cfg *config.LicenseScanConfig) error {
+	if cfg.Command == "" {
+		return fmt.Errorf("command is required")
+	}
+	switch cfg.Unknown {
+	case "", sbom.UnknownWarn, sbom.UnknownBlock, sbom.UnknownAllow:
+	default:
+		return fmt.Errorf("unknown %q: want %s, %s, or %s", cfg.Unknown, sbom.UnknownWarn, sbom.UnknownBlock, sbom.UnknownAllow)
+	}
+	return ValidateTestCommand(cfg.Command)
+}
+
+// licensePolicy returns the rig's license policy.
+func (e *Engineer) licensePolicy() sbom.Policy {
+	cfg := e.config.LicenseScan
+	p := sbom.Policy{Allow: cfg.Allow, Deny: cfg.Deny, Unknown: cfg.Unknown}
+	if p.Unknown == "" {
+		p.Unknown = sbom.UnknownWarn
+	}
+	return p
+}
+
+// ScanSBOM runs the license_scan command in the work dir and parses the
+// SBOM it prints.
+func (e *Engineer) ScanSBOM(ctx context.Context) ([]sbom.Component, error) {
+	if e.config.LicenseScan == nil {
+		return nil, fmt.Errorf("license scanning is not configured (set merge_queue.license_scan)")
+	}
+	command := e.config.LicenseScan.Command
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Executing license scan: %s\n", command)
+	cmd, err := e.shellCommand(ctx, command)
+	if err != nil {
+		return nil, err
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("license scan failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return sbom.Parse(stdout.Bytes())
+}
+
+// ScanLicenses scans the current checkout and checks the dependencies it
+// adds or relicenses, relative to target's recorded SBOM, against the
+// rig's policy. Without a recorded SBOM every dependency is checked.
+func (e *Engineer) ScanLicenses(ctx context.Context, target string) (*sbom.Report, error) {
+	head, err := e.ScanSBOM(ctx)
+	if err != nil {
+		return nil, err
+	}
+	base, err := e.LoadSBOMBaseline(target)
+	if err != nil {
+		return nil, err
+	}
+	return sbom.Evaluate(base, head, e.licensePolicy()), nil
+}
+
+// RunLicenseGate checks out an MR's branch, scans it, records the result on
+// the MR bead, and rejects the MR if the policy blocks it.
+func (e *Engineer) RunLicenseGate(ctx context.Context, mrID, branch, target string) ProcessResult {
+	previous, _ := e.git.CurrentBranch()
+	if err := e.git.Checkout(e.resolveRef(branch)); err != nil {
+		return ProcessResult{Error: fmt.Sprintf("checking out %s for license scan: %v", branch, err)}
+	}
+	report, err := e.ScanLicenses(ctx, target)
+	if previous != "" {
+		if err := e.git.Checkout(previous); err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: restoring checkout of %s: %v\n", previous, err)
+		}
+	}
+	if err != nil {
+		return ProcessResult{Error: err.Error()}
+	}
+	if mrID != "" {
+		if err := e.RecordLicenseScan(mrID, report); err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: %v\n", err)
+		}
+	}
+	if report.Blocked {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Rejected: dependencies with disallowed licenses\n")
+		return ProcessResult{LicenseViolation: true, Error: report.Markdown(licenseScanMaxItems)}
+	}
+	_, _ = fmt.Fprintf(e.output, "[Engineer] License scan %s\n", report.Status())
+	return ProcessResult{Success: true}
+}
+
+// RecordLicenseScan stores a license scan on the MR bead: the status as the
+// license_scan field and the findings as the license-scan section.
+func (e *Engineer) RecordLicenseScan(mrID string, report *sbom.Report) error {
+	mrBead, err := e.beads.Show(mrID)
+	if err != nil {
+		return fmt.Errorf("fetching MR bead %s: %w", mrID, err)
+	}
+	mrFields := beads.ParseMRFields(mrBead)
+	if mrFields == nil {
+		mrFields = &beads.MRFields{}
+	}
+	mrFields.LicenseScan = report.Status()
+
+	newDesc := beads.SetMRFields(mrBead, mrFields)
+	newDesc = beads.SetDescriptionSection(newDesc, LicenseScanSection, report.Markdown(licenseScanMaxItems))
+	if err := e.beads.Update(mrID, beads.UpdateOptions{Description: &newDesc}); err != nil {
+		return fmt.Errorf("updating MR %s with license scan: %w", mrID, err)
+	}
+	return nil
+}
+
+// sbomBaselinePath returns where a target branch's SBOM is recorded.
+func sbomBaselinePath(rigPath, target string) string {
+	return filepath.Join(rigPath, ".runtime", "sbom", strings.ReplaceAll(target, "/", "_")+".json")
+}
+
+// SaveSBOMBaseline records the SBOM of a target branch, so MRs against it
+// are judged only on what they change.
+func (e *Engineer) SaveSBOMBaseline(target string, components []sbom.Component) error {
+	path := sbomBaselinePath(e.rig.Path, target)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if components == nil {
+		components = []sbom.Component{}
+	}
+	return util.AtomicWriteJSON(path, components)
+}
+
+// LoadSBOMBaseline returns the recorded SBOM of a target branch, or nil if
+// none was recorded.
+func (e *Engineer) LoadSBOMBaseline(target string) ([]sbom.Component, error) {
+	data, err := os.ReadFile(sbomBaselinePath(e.rig.Path, target))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var components []sbom.Component
+	if err := json.Unmarshal(data, &components); err != nil {
+		return nil, fmt.Errorf("parsing SBOM baseline for %s: %w", target, err)
+	}
+	if components == nil {
+		components = []sbom.Component{}
+	}
+	return components, nil
+}
@@ -0,0 +1,90 @@
+package refinery
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/sbom"
+)
+
+func TestValidateLicenseScan(t *testing.T) {
+	tests := []struct {
+		cfg     config.LicenseScanConfig
+		wantErr bool
+	}{
+		{config.LicenseScanConfig{Command: "syft -o cyclonedx-json ."}, false},
+		{config.LicenseScanConfig{Command: "syft .", Unknown: "block"}, false},
+		{config.LicenseScanConfig{}, true},
+		{config.LicenseScanConfig{Command: "syft .", Unknown: "ignore"}, true},
+	}
+	for _, tt := range tests {
+		if err := ValidateLicenseScan(&tt.cfg); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateLicenseScan(%+v) = %v, wantErr %v", tt.cfg, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSBOMBaseline_RoundTrip(t *testing.T) {
+	e := &Engineer{rig: &rig.Rig{Name: "test-rig", Path: t.TempDir()}}
+
+	base, err := e.LoadSBOMBaseline("integration/epic")
+	if err != nil || base != nil {
+		t.Fatalf("expected no baseline, got %+v, %v", base, err)
+	}
+
+	want := []sbom.Component{{Name: "cobra", Version: "v1.8.0", Licenses: []string{"Apache-2.0"}}}
+	if err := e.SaveSBOMBaseline("integration/epic", want); err != nil {
+		t.Fatalf("SaveSBOMBaseline: %v", err)
+	}
+	got, err := e.LoadSBOMBaseline("integration/epic")
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadSBOMBaseline = %+v, %v; want %+v", got, err, want)
+	}
+
+	// An empty SBOM is still a baseline, unlike a missing one.
+	if err := e.SaveSBOMBaseline("main", nil); err != nil {
+		t.Fatalf("SaveSBOMBaseline: %v", err)
+	}
+	if got, err := e.LoadSBOMBaseline("main"); err != nil || got == nil {
+		t.Errorf("empty baseline loaded as %v, %v", got, err)
+	}
+}
+
+func TestScanLicenses(t *testing.T) {
+	doc := `{"bomFormat":"CycloneDX","components":[` +
+		`{"name":"ok","purl":"pkg:npm/ok@1","licenses":[{"license":{"id":"MIT"}}]},` +
+		`{"name":"viral","purl":"pkg:npm/viral@1","licenses":[{"license":{"id":"AGPL-3.0-only"}}]}]}`
+	e := &Engineer{
+		rig: &rig.Rig{Name: "test-rig", Path: t.TempDir()},
+		config: &MergeQueueConfig{LicenseScan: &config.LicenseScanConfig{
+			Command: "printf '%s' '" + doc + "'",
+			Deny:    []string{"AGPL-*"},
+		}},
+		workDir: t.TempDir(),
+		output:  io.Discard,
+	}
+
+	report, err := e.ScanLicenses(context.Background(), "main")
+	if err != nil {
+		t.Fatalf("ScanLicenses: %v", err)
+	}
+	if !report.Blocked || report.Baseline || report.Scanned != 2 {
+		t.Errorf("without a baseline: %+v", report)
+	}
+
+	// Once the target already has the dependency, the MR isn't blamed for it.
+	if err := e.SaveSBOMBaseline("main", []sbom.Component{{Name: "viral", PURL: "pkg:npm/viral@0.9", Licenses: []string{"AGPL-3.0-only"}}}); err != nil {
+		t.Fatal(err)
+	}
+	report, err = e.ScanLicenses(context.Background(), "main")
+	if err != nil {
+		t.Fatalf("ScanLicenses: %v", err)
+	}
+	if report.Blocked || report.Status() != "passed" || len(report.Findings) != 1 {
+		t.Errorf("with a baseline: %+v", report)
+	}
+}
@@ -0,0 +1,222 @@
+// Package refinery provides the merge queue processing agent.
+// This file lands linked MRs across rigs together (see mq.LinkGroup).
+
+package refinery
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/mq"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// linkGroupOf returns the link group of an MR, or "" if it isn't linked.
+func (e *Engineer) linkGroupOf(mrID string) string {
+	if mrID == "" || e.beads == nil {
+		return ""
+	}
+	mr, err := e.beads.Show(mrID)
+	if err != nil {
+		return ""
+	}
+	if fields := beads.ParseMRFields(mr); fields != nil {
+		return fields.LinkGroup
+	}
+	return ""
+}
+
+// linkTownRoot returns the town root shared by every rig in a link group.
+func (e *Engineer) linkTownRoot() (string, error) {
+	if e.rig == nil {
+		return "", fmt.Errorf("no rig")
+	}
+	townRoot, err := workspace.Find(e.rig.Path)
+	if err != nil || townRoot == "" {
+		return "", fmt.Errorf("finding town root for %s", e.rig.Path)
+	}
+	return townRoot, nil
+}
+
+// holdLinked decides whether a linked MR whose checks passed may land now.
+// It returns nil to land, or a result that holds or rejects the MR. A member
+// pinning another member's repo as a submodule gets its pointer bumped to
+// that member's merge commit, and its checks re-run, before it lands.
+func (e *Engineer) holdLinked(mrID, branch string, prNumber int) *ProcessResult {
+	group := e.linkGroupOf(mrID)
+	if group == "" {
+		return nil
+	}
+	townRoot, err := e.linkTownRoot()
+	if err != nil {
+		return &ProcessResult{Error: fmt.Sprintf("linked MR: %v", err)}
+	}
+
+	var action mq.LinkAction
+	var reason, subPath, subCommit string
+	if _, err := mq.UpdateLink(townRoot, group, func(g *mq.LinkGroup) error {
+		g.MarkReady(mrID, time.Now())
+		action, reason = g.Decide(mrID)
+		if action == mq.LinkProceed {
+			subPath, subCommit = g.SubmoduleBump(mrID)
+		}
+		return nil
+	}); err != nil {
+		return &ProcessResult{Error: fmt.Sprintf("linked MR: %v", err)}
+	}
+
+	switch action {
+	case mq.LinkWait:
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Holding %s for link group %s: %s\n", mrID, group, reason)
+		return &ProcessResult{Deferred: true, Error: reason}
+	case mq.LinkAbort:
+		return &ProcessResult{LinkAborted: true, Error: reason}
+	}
+
+	if subPath != "" {
+		bumped, err := e.bumpSubmodule(branch, subPath, subCommit)
+		if err != nil {
+			return &ProcessResult{Error: fmt.Sprintf("bumping submodule %s to %s: %v", subPath, shortCommit(subCommit), err)}
+		}
+		if bumped {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Bumped submodule %s to %s; re-running PR checks\n", subPath, shortCommit(subCommit))
+			passed, details, err := e.waitForPRChecks(prNumber)
+			if err != nil {
+				return &ProcessResult{Error: fmt.Sprintf("error waiting for PR checks after submodule bump: %v", err)}
+			}
+			if !passed {
+				return &ProcessResult{TestsFailed: true, Error: "after submodule bump: " + details}
+			}
+		}
+	}
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Link group %s: landing %s\n", group, mrID)
+	return nil
+}
+
+// bumpSubmodule pins the submodule at path to commit on the MR's branch and
+// pushes it. Reports whether the branch changed.
+func (e *Engineer) bumpSubmodule(branch, path, commit string) (bool, error) {
+	if err := e.git.FetchBranch("origin", branch); err != nil {
+		return false, err
+	}
+	if current, err := e.git.SubmoduleCommit("origin/"+branch, path); err != nil {
+		return false, err
+	} else if current == commit {
+		return false, nil
+	}
+
+	previous, _ := e.git.CurrentBranch()
+	if err := e.git.CheckoutDetached("origin/" + branch); err != nil {
+		return false, err
+	}
+	defer func() {
+		if previous != "" {
+			_ = e.git.Checkout(previous)
+		}
+	}()
+	if err := e.git.SetSubmoduleCommit(path, commit); err != nil {
+		return false, err
+	}
+	if err := e.git.Commit(fmt.Sprintf("Update %s submodule to %s", path, shortCommit(commit))); err != nil {
+		return false, err
+	}
+	if err := e.git.Push("origin", "HEAD:refs/heads/"+branch, false); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// recordLinkedMerge marks a linked MR landed. If its group aborted while it
+// was landing, the landing is reverted.
+func (e *Engineer) recordLinkedMerge(mrID, commit string) {
+	group := e.linkGroupOf(mrID)
+	if group == "" {
+		return
+	}
+	townRoot, err := e.linkTownRoot()
+	if err != nil {
+		return
+	}
+	var aborted bool
+	g, err := mq.UpdateLink(townRoot, group, func(g *mq.LinkGroup) error {
+		g.MarkMerged(mrID, commit, time.Now())
+		aborted = g.State == mq.LinkAborted
+		return nil
+	})
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to record landing in link group %s: %v\n", group, err)
+		return
+	}
+	if aborted {
+		e.revertLinked(townRoot, g, g.Member(mrID))
+	}
+}
+
+// failLinked aborts a failed MR's link group and reverts members that had
+// already landed.
+func (e *Engineer) failLinked(mrID, reason string) {
+	group := e.linkGroupOf(mrID)
+	if group == "" {
+		return
+	}
+	townRoot, err := e.linkTownRoot()
+	if err != nil {
+		return
+	}
+	var landed []*mq.LinkMember
+	g, err := mq.UpdateLink(townRoot, group, func(g *mq.LinkGroup) error {
+		landed = g.MarkFailed(mrID, reason, time.Now())
+		return nil
+	})
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to abort link group %s: %v\n", group, err)
+		return
+	}
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Link group %s aborted\n", group)
+	for _, m := range landed {
+		e.revertLinked(townRoot, g, m)
+	}
+}
+
+// revertLinked reverts a landed member of an aborted group through its own
+// rig's queue.
+func (e *Engineer) revertLinked(townRoot string, g *mq.LinkGroup, m *mq.LinkMember) {
+	if m == nil || m.State != mq.LinkMerged {
+		return
+	}
+	rigsConfig, err := config.LoadRigsConfig(constants.MayorRigsPath(townRoot))
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: cannot revert %s: %v\n", m.MR, err)
+		return
+	}
+	r, err := rig.NewManager(townRoot, rigsConfig, git.NewGit(townRoot)).GetRig(m.Rig)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: cannot revert %s: rig %s: %v\n", m.MR, m.Rig, err)
+		return
+	}
+	mgr := NewManager(r)
+	mgr.SetOutput(e.output)
+	result, err := mgr.RevertMR(m.MR, RevertOptions{
+		Reason:   "link group " + g.ID + " aborted: " + g.Reason,
+		Actor:    e.rig.Name + "/refinery",
+		Commit:   m.MergeCommit,
+		Priority: -1,
+		Hotfix:   true,
+	})
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to revert linked MR %s: %v\n", m.MR, err)
+		return
+	}
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Reverting linked MR %s via %s\n", m.MR, result.RevertMR)
+	if _, err := mq.UpdateLink(townRoot, g.ID, func(g *mq.LinkGroup) error {
+		g.MarkReverted(m.MR, time.Now())
+		return nil
+	}); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to record revert in link group %s: %v\n", g.ID, err)
+	}
+}
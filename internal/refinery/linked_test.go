@@ -0,0 +1,30 @@
+package refinery
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+func TestHandleMRInfoFailure_DeferredIsNotAFailure(t *testing.T) {
+	e := NewEngineer(&rig.Rig{Name: "test-rig", Path: t.TempDir()})
+	var out bytes.Buffer
+	e.output = &out
+
+	e.HandleMRInfoFailure(&MRInfo{ID: "gt-mr-1", Branch: "polecat/nux"}, ProcessResult{
+		Deferred: true,
+		Error:    "waiting for linked MRs to pass checks: bd-mr-2 (beads)",
+	})
+	if got := out.String(); !strings.Contains(got, "Held: gt-mr-1") || strings.Contains(got, "Failed") {
+		t.Errorf("deferred MR should be reported held, got:\n%s", got)
+	}
+}
+
+func TestHoldLinked_UnlinkedMRLands(t *testing.T) {
+	e := NewEngineer(&rig.Rig{Name: "test-rig", Path: t.TempDir()})
+	if hold := e.holdLinked("", "polecat/nux", 1); hold != nil {
+		t.Errorf("unlinked MR should not be held, got %+v", hold)
+	}
+}
@@ -1,12 +1,12 @@
 package refinery
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
 	"time"
 
@@ -17,6 +17,7 @@ import (
 	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/runtime"
 	"github.com/steveyegge/gastown/internal/session"
+	"github.com/steveyegge/gastown/internal/timefmt"
 	"github.com/steveyegge/gastown/internal/tmux"
 )
 
@@ -108,6 +109,10 @@ func (m *Manager) Start(foreground bool, agentOverride string) error {
 
 	// Note: No PID check per ZFC - tmux session is the source of truth
 
+	// Settle MRs a previous refinery left mid-merge before a new one
+	// starts pulling from the queue
+	m.reconcile()
+
 	// Background mode: spawn a Claude agent in a tmux session
 	// The Claude agent handles MR processing using git commands and beads
 
@@ -223,27 +228,17 @@ func (m *Manager) Queue() ([]QueueItem, error) {
 		return nil, fmt.Errorf("querying merge queue from beads: %w", err)
 	}
 
-	// Score and sort issues by priority score (highest first)
-	now := time.Now()
-	type scoredIssue struct {
-		issue *beads.Issue
-		score float64
-	}
-	scored := make([]scoredIssue, 0, len(issues))
+	// Order issues as the refinery processes them
+	ordered := make(map[string]*beads.Issue, len(issues))
 	for _, issue := range issues {
-		score := m.calculateIssueScore(issue, now)
-		scored = append(scored, scoredIssue{issue: issue, score: score})
+		ordered[issue.ID] = issue
 	}
 
-	sort.Slice(scored, func(i, j int) bool {
-		return scored[i].score > scored[j].score
-	})
-
-	// Convert scored issues to queue items
+	// Convert ordered issues to queue items
 	var items []QueueItem
 	pos := 1
-	for _, s := range scored {
-		mr := m.issueToMR(s.issue)
+	for _, entry := range OrderQueue(QueueCandidates(b, issues), time.Now()) {
+		mr := m.issueToMR(ordered[entry.ID])
 		if mr != nil {
 			items = append(items, QueueItem{
 				Position: pos,
@@ -257,39 +252,6 @@ func (m *Manager) Queue() ([]QueueItem, error) {
 	return items, nil
 }
 
-// calculateIssueScore computes the priority score for an MR issue.
-// Higher scores mean higher priority (process first).
-func (m *Manager) calculateIssueScore(issue *beads.Issue, now time.Time) float64 {
-	fields := beads.ParseMRFields(issue)
-
-	// Parse MR creation time
-	mrCreatedAt := parseTime(issue.CreatedAt)
-	if mrCreatedAt.IsZero() {
-		mrCreatedAt = now // Fallback
-	}
-
-	// Build score input
-	input := ScoreInput{
-		Priority:    issue.Priority,
-		MRCreatedAt: mrCreatedAt,
-		Now:         now,
-	}
-
-	// Add fields from MR metadata if available
-	if fields != nil {
-		input.RetryCount = fields.RetryCount
-
-		// Parse convoy created at if available
-		if fields.ConvoyCreatedAt != "" {
-			if convoyTime := parseTime(fields.ConvoyCreatedAt); !convoyTime.IsZero() {
-				input.ConvoyCreatedAt = &convoyTime
-			}
-		}
-	}
-
-	return ScoreMRWithDefaults(input)
-}
-
 // issueToMR converts a beads issue to a MergeRequest.
 func (m *Manager) issueToMR(issue *beads.Issue) *MergeRequest {
 	if issue == nil {
@@ -341,18 +303,7 @@ func parseTime(s string) time.Time {
 
 // formatAge formats a duration since the given time.
 func formatAge(t time.Time) string {
-	d := time.Since(t)
-
-	if d < time.Minute {
-		return fmt.Sprintf("%ds ago", int(d.Seconds()))
-	}
-	if d < time.Hour {
-		return fmt.Sprintf("%dm ago", int(d.Minutes()))
-	}
-	if d < 24*time.Hour {
-		return fmt.Sprintf("%dh ago", int(d.Hours()))
-	}
-	return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	return timefmt.Ago(t)
 }
 
 // Common errors for MR operations
@@ -526,3 +477,21 @@ func findTownRoot(startPath string) string {
 	}
 	return ""
 }
+
+// reconcile settles MRs a crashed refinery left in flight, reporting what
+// it did. Failures are warnings: the new refinery can still start.
+func (m *Manager) reconcile() {
+	eng := NewEngineer(m.rig)
+	eng.SetOutput(m.output)
+	if err := eng.LoadConfig(); err != nil {
+		_, _ = fmt.Fprintf(m.output, "Warning: loading merge queue config: %v\n", err)
+	}
+	results, err := eng.ReconcileState(context.Background())
+	if err != nil {
+		_, _ = fmt.Fprintf(m.output, "Warning: reconciling refinery state: %v\n", err)
+		return
+	}
+	for _, r := range results {
+		_, _ = fmt.Fprintf(m.output, "Reconciled %s (%s): %s\n", r.MR, r.Phase, r.Action)
+	}
+}
@@ -0,0 +1,242 @@
+// Package refinery provides the merge queue processing agent.
+// This file contains MR metrics: diff size and coverage delta, recorded on
+// MR beads so reviewers can see how big a change is and what it does to
+// coverage before it merges.
+
+package refinery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// MRMetrics holds the measured size and coverage of a merge request.
+type MRMetrics struct {
+	Diff *git.DiffStat `json:"diff,omitempty"`
+
+	// Coverage is the total coverage percentage with the MR applied.
+	// Nil when no coverage_command is configured.
+	Coverage *float64 `json:"coverage,omitempty"`
+
+	// CoverageDelta is Coverage minus the target's recorded baseline.
+	// Nil when there is no baseline yet.
+	CoverageDelta *float64 `json:"coverage_delta,omitempty"`
+}
+
+// CoverageBaseline is the last known coverage of a target branch.
+type CoverageBaseline struct {
+	Coverage  float64   `json:"coverage"`
+	Commit    string    `json:"commit,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// coverageTotalRe matches the last percentage in coverage output.
+var coverageTotalRe = regexp.MustCompile(`(\d+(?:\.\d+)?)%`)
+
+// ParseCoverage extracts the total coverage percentage from coverage command
+// output. If pattern is set, its first capture group is used; otherwise the
+// last "NN.N%" in the output is taken (the total line of go tool cover,
+// pytest-cov, and most other reporters comes last).
+func ParseCoverage(output []byte, pattern string) (float64, error) {
+	var value string
+	if pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return 0, fmt.Errorf("invalid coverage_pattern: %w", err)
+		}
+		matches := re.FindAllSubmatch(output, -1)
+		if len(matches) == 0 || len(matches[len(matches)-1]) < 2 {
+			return 0, errors.New("coverage_pattern did not match coverage output")
+		}
+		value = string(matches[len(matches)-1][1])
+	} else {
+		matches := coverageTotalRe.FindAllSubmatch(output, -1)
+		if len(matches) == 0 {
+			return 0, errors.New("no coverage percentage found in output")
+		}
+		value = string(matches[len(matches)-1][1])
+	}
+
+	pct, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parsing coverage %q: %w", value, err)
+	}
+	return pct, nil
+}
+
+// MeasureDiff returns the diff stats of branch against target, preferring
+// the remote-tracking refs the refinery fetches.
+func (e *Engineer) MeasureDiff(branch, target string) (*git.DiffStat, error) {
+	return e.git.DiffStat(e.resolveRef(target), e.resolveRef(branch))
+}
+
+// resolveRef returns origin/<name> if it exists, otherwise name.
+func (e *Engineer) resolveRef(name string) string {
+	if _, err := e.git.Rev("origin/" + name); err == nil {
+		return "origin/" + name
+	}
+	return name
+}
+
+// MeasureCoverage runs the configured coverage_command in the work dir and
+// returns the total coverage. Returns nil when coverage isn't configured.
+func (e *Engineer) MeasureCoverage(ctx context.Context) (*float64, error) {
+	if e.config.CoverageCommand == "" {
+		return nil, nil
+	}
+	if err := ValidateTestCommand(e.config.CoverageCommand); err != nil {
+		return nil, fmt.Errorf("invalid coverage command: %w", err)
+	}
+
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Executing coverage command: %s\n", e.config.CoverageCommand)
+	cmd, err := e.shellCommand(ctx, e.config.CoverageCommand)
+	if err != nil {
+		return nil, err
+	}
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("coverage command failed: %w", err)
+	}
+
+	pct, err := ParseCoverage(output.Bytes(), e.config.CoveragePattern)
+	if err != nil {
+		return nil, err
+	}
+	return &pct, nil
+}
+
+// MeasureMR measures the diff of an MR against its target and, if configured,
+// its coverage in the current work dir checkout. The coverage delta is
+// computed against the target's recorded baseline.
+func (e *Engineer) MeasureMR(ctx context.Context, branch, target string, withCoverage bool) (*MRMetrics, error) {
+	diff, err := e.MeasureDiff(branch, target)
+	if err != nil {
+		return nil, fmt.Errorf("measuring diff: %w", err)
+	}
+	metrics := &MRMetrics{Diff: diff}
+
+	if !withCoverage {
+		return metrics, nil
+	}
+	metrics.Coverage, err = e.MeasureCoverage(ctx)
+	if err != nil {
+		return metrics, err
+	}
+	if metrics.Coverage != nil {
+		if base, err := e.LoadCoverageBaseline(target); err == nil && base != nil {
+			delta := *metrics.Coverage - base.Coverage
+			metrics.CoverageDelta = &delta
+		}
+	}
+	return metrics, nil
+}
+
+// RecordMetrics stores MR metrics on the MR bead as MR fields.
+func (e *Engineer) RecordMetrics(mrID string, m *MRMetrics) error {
+	if m == nil {
+		return nil
+	}
+
+	mrBead, err := e.beads.Show(mrID)
+	if err != nil {
+		return fmt.Errorf("fetching MR bead %s: %w", mrID, err)
+	}
+	mrFields := beads.ParseMRFields(mrBead)
+	if mrFields == nil {
+		mrFields = &beads.MRFields{}
+	}
+
+	if m.Diff != nil {
+		mrFields.DiffFiles = m.Diff.Files
+		mrFields.LinesAdded = m.Diff.Added
+		mrFields.LinesDeleted = m.Diff.Deleted
+	}
+	if m.Coverage != nil {
+		mrFields.Coverage = strconv.FormatFloat(*m.Coverage, 'f', 1, 64)
+		mrFields.CoverageDelta = ""
+		if m.CoverageDelta != nil {
+			mrFields.CoverageDelta = fmt.Sprintf("%+.1f", *m.CoverageDelta)
+		}
+	}
+
+	newDesc := beads.SetMRFields(mrBead, mrFields)
+	if err := e.beads.Update(mrID, beads.UpdateOptions{Description: &newDesc}); err != nil {
+		return fmt.Errorf("updating MR %s with metrics: %w", mrID, err)
+	}
+	return nil
+}
+
+// coverageBaselinePath returns the path of the rig's coverage baselines.
+func coverageBaselinePath(rigPath string) string {
+	return filepath.Join(rigPath, ".runtime", "coverage.json")
+}
+
+// loadCoverageBaselines reads all recorded baselines, keyed by target branch.
+func (e *Engineer) loadCoverageBaselines() (map[string]*CoverageBaseline, error) {
+	baselines := make(map[string]*CoverageBaseline)
+	if e.rig == nil {
+		return baselines, nil
+	}
+	data, err := os.ReadFile(coverageBaselinePath(e.rig.Path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return baselines, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &baselines); err != nil {
+		return nil, fmt.Errorf("parsing coverage baselines: %w", err)
+	}
+	return baselines, nil
+}
+
+// LoadCoverageBaseline returns the recorded coverage baseline for a target
+// branch, or nil if none has been recorded.
+func (e *Engineer) LoadCoverageBaseline(target string) (*CoverageBaseline, error) {
+	baselines, err := e.loadCoverageBaselines()
+	if err != nil {
+		return nil, err
+	}
+	return baselines[target], nil
+}
+
+// SaveCoverageBaseline records the coverage of a target branch, used to
+// compute deltas for subsequent MRs. An empty commit defaults to the work
+// dir's HEAD.
+func (e *Engineer) SaveCoverageBaseline(target string, coverage float64, commit string) error {
+	if e.rig == nil {
+		return errors.New("no rig configured")
+	}
+	if commit == "" && e.git != nil {
+		commit, _ = e.git.Rev("HEAD")
+	}
+	baselines, err := e.loadCoverageBaselines()
+	if err != nil {
+		return err
+	}
+	baselines[target] = &CoverageBaseline{
+		Coverage:  coverage,
+		Commit:    commit,
+		UpdatedAt: time.Now().UTC(),
+	}
+
+	path := coverageBaselinePath(e.rig.Path)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return util.AtomicWriteJSON(path, baselines)
+}
@@ -0,0 +1,112 @@
+package refinery
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+func TestParseCoverage(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		pattern string
+		want    float64
+		wantErr bool
+	}{
+		{
+			name:   "go tool cover total",
+			output: "pkg/a.go:10:\tFoo\t100.0%\npkg/b.go:20:\tBar\t50.0%\ntotal:\t(statements)\t78.4%\n",
+			want:   78.4,
+		},
+		{
+			name:   "pytest-cov integer",
+			output: "src/app.py     120     30    75%\nTOTAL          200     40    80%\n",
+			want:   80,
+		},
+		{
+			name:    "custom pattern",
+			output:  "All files | 91.2 | 80.5 | 70.1 |\n",
+			pattern: `All files \| ([\d.]+)`,
+			want:    91.2,
+		},
+		{
+			name:    "no percentage",
+			output:  "ok  \texample.com/pkg\t0.01s\n",
+			wantErr: true,
+		},
+		{
+			name:    "pattern does not match",
+			output:  "total: 50%",
+			pattern: `coverage=(\d+)`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCoverage([]byte(tt.output), tt.pattern)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseCoverage: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseCoverage = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCoverageBaseline_RoundTrip(t *testing.T) {
+	e := &Engineer{rig: &rig.Rig{Name: "test-rig", Path: t.TempDir()}}
+
+	base, err := e.LoadCoverageBaseline("main")
+	if err != nil || base != nil {
+		t.Fatalf("expected no baseline, got %+v, %v", base, err)
+	}
+
+	if err := e.SaveCoverageBaseline("main", 78.4, "abc123"); err != nil {
+		t.Fatalf("SaveCoverageBaseline: %v", err)
+	}
+	if err := e.SaveCoverageBaseline("integration/epic", 60, ""); err != nil {
+		t.Fatalf("SaveCoverageBaseline: %v", err)
+	}
+
+	base, err = e.LoadCoverageBaseline("main")
+	if err != nil {
+		t.Fatalf("LoadCoverageBaseline: %v", err)
+	}
+	if base == nil || base.Coverage != 78.4 || base.Commit != "abc123" {
+		t.Errorf("baseline = %+v, want 78.4 at abc123", base)
+	}
+}
+
+func TestMeasureCoverage(t *testing.T) {
+	e := &Engineer{
+		config: &MergeQueueConfig{
+			CoverageCommand: `printf 'total:\t(statements)\t81.5%%\n'`,
+		},
+		workDir: t.TempDir(),
+		output:  io.Discard,
+	}
+
+	pct, err := e.MeasureCoverage(context.Background())
+	if err != nil {
+		t.Fatalf("MeasureCoverage: %v", err)
+	}
+	if pct == nil || *pct != 81.5 {
+		t.Errorf("coverage = %v, want 81.5", pct)
+	}
+
+	e.config.CoverageCommand = ""
+	if pct, err := e.MeasureCoverage(context.Background()); err != nil || pct != nil {
+		t.Errorf("expected nil coverage when unconfigured, got %v, %v", pct, err)
+	}
+}
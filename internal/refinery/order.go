@@ -0,0 +1,240 @@
+// Package refinery provides the merge queue processing agent.
+// This file orders the merge queue and explains each MR's place in it.
+
+package refinery
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/timefmt"
+)
+
+// MaxBoost caps how many priority levels gt mq bump can raise an MR.
+const MaxBoost = 4
+
+// QueueCandidate is an open MR to place in the queue.
+type QueueCandidate struct {
+	Issue  *beads.Issue
+	Fields *beads.MRFields // nil if the MR has none
+	// SourcePriority is the source issue's current priority, or -1 if unknown.
+	SourcePriority int
+	// SourceDeps are the open issues the source issue depends on.
+	SourceDeps []string
+}
+
+// QueueEntry is an MR's place in the merge queue and why it's there.
+type QueueEntry struct {
+	Position     int        `json:"position"`
+	ID           string     `json:"id"`
+	Branch       string     `json:"branch,omitempty"`
+	Target       string     `json:"target,omitempty"`
+	Priority     int        `json:"priority"`      // Effective priority the MR is queued at
+	BasePriority int        `json:"base_priority"` // The MR bead's own priority
+	Front        bool       `json:"front,omitempty"`
+	Score        float64    `json:"score"`
+	Parts        ScoreParts `json:"score_parts"`
+	Reasons      []string   `json:"reasons"`
+	// Held says why the MR won't be processed when it reaches the front
+	// (blocked, claimed, or awaiting review); empty if it will.
+	Held string `json:"held,omitempty"`
+
+	frontAt   time.Time
+	createdAt time.Time
+}
+
+// EffectivePriority returns the priority an MR is queued at: the more
+// urgent of its own and its source issue's current priority, raised by
+// any boost, and never above P0. Reasons explain any difference from the
+// MR's own priority.
+func EffectivePriority(c QueueCandidate) (int, []string) {
+	p := c.Issue.Priority
+	var reasons []string
+	if c.SourcePriority >= 0 && c.SourcePriority < p && c.Fields != nil {
+		reasons = append(reasons, fmt.Sprintf("inherits P%d from source issue %s (MR is P%d)", c.SourcePriority, c.Fields.SourceIssue, p))
+		p = c.SourcePriority
+	}
+	if c.Fields != nil && c.Fields.Boost > 0 {
+		boosted := max(p-c.Fields.Boost, 0)
+		reasons = append(reasons, fmt.Sprintf("boosted P%d → P%d%s", p, boosted, bumpedBy(c.Fields)))
+		p = boosted
+	}
+	return p, reasons
+}
+
+// OrderQueue orders candidates as the refinery processes them:
+//
+//  1. MRs moved to the front, most recently moved first
+//  2. then by score (see ScoreMR), at the effective priority
+//  3. then oldest first, then by ID, so the order is deterministic
+func OrderQueue(candidates []QueueCandidate, now time.Time) []QueueEntry {
+	entries := make([]QueueEntry, 0, len(candidates))
+	for _, c := range candidates {
+		priority, reasons := EffectivePriority(c)
+		input := ScoreInput{Priority: priority, MRCreatedAt: parseTime(c.Issue.CreatedAt), Now: now}
+		if input.MRCreatedAt.IsZero() {
+			input.MRCreatedAt = now
+		}
+		entry := QueueEntry{ID: c.Issue.ID, Priority: priority, BasePriority: c.Issue.Priority, createdAt: input.MRCreatedAt}
+		if f := c.Fields; f != nil {
+			entry.Branch, entry.Target = f.Branch, f.Target
+			input.RetryCount = f.RetryCount
+			if t := parseTime(f.ConvoyCreatedAt); !t.IsZero() {
+				input.ConvoyCreatedAt = &t
+			}
+			if t := parseTime(f.FrontAt); !t.IsZero() {
+				entry.Front, entry.frontAt = true, t
+				reasons = append([]string{fmt.Sprintf("moved to front at %s%s", t.Local().Format("Jan 2 15:04"), bumpedBy(f))}, reasons...)
+			}
+		}
+		entry.Parts = ScoreBreakdown(input, DefaultScoreConfig())
+		entry.Score = entry.Parts.Total()
+		entry.Reasons = append(reasons, scoreReasons(entry.Priority, entry.Parts, input, now)...)
+		entries = append(entries, entry)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		switch {
+		case a.Front != b.Front:
+			return a.Front
+		case !a.frontAt.Equal(b.frontAt):
+			return a.frontAt.After(b.frontAt)
+		case a.Score != b.Score:
+			return a.Score > b.Score
+		case !a.createdAt.Equal(b.createdAt):
+			return a.createdAt.Before(b.createdAt)
+		}
+		return a.ID < b.ID
+	})
+	for i := range entries {
+		entries[i].Position = i + 1
+	}
+	return entries
+}
+
+// scoreReasons describes the factors of a score that moved it.
+func scoreReasons(priority int, parts ScoreParts, input ScoreInput, now time.Time) []string {
+	reasons := []string{fmt.Sprintf("P%d: %+.0f", priority, parts.Priority)}
+	if parts.ConvoyAge > 0 {
+		reasons = append(reasons, fmt.Sprintf("convoy waiting %s: %+.0f", timefmt.Age(now.Sub(*input.ConvoyCreatedAt)), parts.ConvoyAge))
+	}
+	if parts.RetryPenalty > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d retries: %+.0f", input.RetryCount, -parts.RetryPenalty))
+	}
+	if parts.MRAge > 0 {
+		reasons = append(reasons, fmt.Sprintf("queued %s: %+.1f", timefmt.Age(now.Sub(input.MRCreatedAt)), parts.MRAge))
+	}
+	return reasons
+}
+
+func bumpedBy(f *beads.MRFields) string {
+	if f.BumpedBy == "" {
+		return ""
+	}
+	return " by " + f.BumpedBy
+}
+
+// QueueCandidates pairs MRs with their fields and their source issues'
+// current priorities and dependencies. Source issues that can't be read
+// don't pass on either.
+func QueueCandidates(b *beads.Beads, mrs []*beads.Issue) []QueueCandidate {
+	candidates := make([]QueueCandidate, 0, len(mrs))
+	var sources []string
+	for _, mr := range mrs {
+		c := QueueCandidate{Issue: mr, Fields: beads.ParseMRFields(mr), SourcePriority: -1}
+		if c.Fields != nil && c.Fields.SourceIssue != "" {
+			sources = append(sources, c.Fields.SourceIssue)
+		}
+		candidates = append(candidates, c)
+	}
+	if len(sources) == 0 || b == nil {
+		return candidates
+	}
+	issues, err := b.ShowMultiple(sources)
+	if err != nil {
+		return candidates
+	}
+	for i, c := range candidates {
+		if c.Fields == nil {
+			continue
+		}
+		if src := issues[c.Fields.SourceIssue]; src != nil && src.Status != "closed" {
+			candidates[i].SourcePriority = src.Priority
+			candidates[i].SourceDeps = sourceDependencies(src)
+		}
+	}
+	return candidates
+}
+
+// QueueOrder returns every open MR in the order the refinery will process
+// them, with why each is where it is and what holds it. MRs come after
+// the MRs they depend on.
+func (e *Engineer) QueueOrder(now time.Time) ([]QueueEntry, error) {
+	mrs, err := e.beads.List(beads.ListOptions{Type: "merge-request", Status: "open", Priority: -1})
+	if err != nil {
+		return nil, fmt.Errorf("listing open MRs: %w", err)
+	}
+	open := mrs[:0]
+	held := make(map[string]string)
+	for _, mr := range mrs {
+		if mr.Status != "open" {
+			continue
+		}
+		open = append(open, mr)
+		fields := beads.ParseMRFields(mr)
+		switch {
+		case len(mr.BlockedBy) > 0:
+			held[mr.ID] = "blocked by " + mr.BlockedBy[0]
+		case mr.BlockedByCount > 0:
+			held[mr.ID] = "blocked"
+		case mr.Assignee != "":
+			held[mr.ID] = "claimed by " + mr.Assignee
+		case ReviewHolds(fields):
+			held[mr.ID] = "awaiting review"
+			if len(PendingOwners(fields)) > 0 {
+				held[mr.ID] = "awaiting code owner review"
+			}
+		}
+	}
+	candidates := QueueCandidates(e.beads, open)
+	entries := OrderQueue(candidates, now)
+	for i := range entries {
+		entries[i].Held = held[entries[i].ID]
+	}
+	deps, err := e.resolveDependencies(candidates)
+	if err != nil {
+		return nil, err
+	}
+	return ApplyDependencies(entries, deps), nil
+}
+
+// Bump expedites an MR: by default it raises the MR one priority level
+// above where it stands; toFront moves it ahead of every other MR instead.
+// Reset clears earlier bumps.
+func (e *Engineer) Bump(mrID string, toFront, reset bool, by string, now time.Time) error {
+	mr, err := e.beads.Show(mrID)
+	if err != nil {
+		return fmt.Errorf("fetching merge request %s: %w", mrID, err)
+	}
+	fields := beads.ParseMRFields(mr)
+	if fields == nil {
+		return fmt.Errorf("%s has no MR fields; is it a merge request?", mrID)
+	}
+	switch {
+	case reset:
+		fields.Boost, fields.FrontAt, fields.BumpedBy = 0, "", ""
+	case toFront:
+		fields.FrontAt = now.UTC().Format(time.RFC3339)
+		fields.BumpedBy = by
+	default:
+		if fields.Boost >= MaxBoost {
+			return fmt.Errorf("%s is already boosted %d levels; use --to-front to expedite it further", mrID, fields.Boost)
+		}
+		fields.Boost++
+		fields.BumpedBy = by
+	}
+	return e.updateMRFields(mr, fields, "")
+}
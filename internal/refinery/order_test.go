@@ -0,0 +1,76 @@
+package refinery
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+func TestScoreBreakdown(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	convoy := now.Add(-10 * time.Hour)
+	input := ScoreInput{Priority: 1, MRCreatedAt: now.Add(-2 * time.Hour), ConvoyCreatedAt: &convoy, RetryCount: 8, Now: now}
+	parts := ScoreBreakdown(input, DefaultScoreConfig())
+	want := ScoreParts{Base: 1000, ConvoyAge: 100, Priority: 300, RetryPenalty: 300, MRAge: 2}
+	if parts != want {
+		t.Errorf("ScoreBreakdown() = %+v, want %+v", parts, want)
+	}
+	if got := ScoreMRWithDefaults(input); got != 1102 {
+		t.Errorf("ScoreMR() = %v, want 1102", got)
+	}
+}
+
+func TestOrderQueue(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	mr := func(id string, priority int, age time.Duration, fields string) QueueCandidate {
+		issue := &beads.Issue{ID: id, Priority: priority, CreatedAt: now.Add(-age).Format(time.RFC3339),
+			Description: "branch: polecat/" + id + "\nsource_issue: src-" + id + "\n" + fields}
+		return QueueCandidate{Issue: issue, Fields: beads.ParseMRFields(issue), SourcePriority: -1}
+	}
+
+	var candidates []QueueCandidate
+	for _, id := range []string{"p3-a", "p3-b", "p3-c"} {
+		candidates = append(candidates, mr(id, 3, 5*time.Hour, ""))
+	}
+	fix := mr("fix", 3, time.Hour, "")
+	fix.SourcePriority = 0 // source issue escalated to P0 after submit
+	boosted := mr("boosted", 3, time.Hour, "boost: 1\nbumped_by: mayor")
+	front := mr("front", 4, 0, "front_at: 2026-03-01T11:00:00Z")
+	fronter := mr("fronter", 4, 0, "front_at: 2026-03-01T11:30:00Z")
+	candidates = append(candidates, fix, boosted, front, fronter, mr("p1", 1, 0, ""))
+
+	entries := OrderQueue(candidates, now)
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.ID)
+	}
+	want := "fronter front fix p1 boosted p3-a p3-b p3-c"
+	if strings.Join(got, " ") != want {
+		t.Fatalf("OrderQueue() = %v, want %s", got, want)
+	}
+
+	byID := map[string]QueueEntry{}
+	for _, e := range entries {
+		byID[e.ID] = e
+	}
+	if e := byID["fix"]; e.Priority != 0 || e.BasePriority != 3 || !strings.Contains(e.Reasons[0], "inherits P0 from source issue src-fix") {
+		t.Errorf("fix = %+v", e)
+	}
+	if e := byID["boosted"]; e.Priority != 2 || e.Reasons[0] != "boosted P3 → P2 by mayor" {
+		t.Errorf("boosted = %+v", e)
+	}
+	if e := byID["front"]; !e.Front || !strings.HasPrefix(e.Reasons[0], "moved to front at ") {
+		t.Errorf("front = %+v", e)
+	}
+	if e := byID["p3-a"]; e.Position != 6 || e.Reasons[0] != "P3: +100" || e.Reasons[1] != "queued 5h: +5.0" {
+		t.Errorf("p3-a = %+v", e)
+	}
+
+	// Equal scores fall back to ID, so the order never depends on input order.
+	reversed := []QueueCandidate{candidates[2], candidates[1], candidates[0]}
+	if e := OrderQueue(reversed, now); e[0].ID != "p3-a" || e[2].ID != "p3-c" {
+		t.Errorf("tie order = %s %s %s", e[0].ID, e[1].ID, e[2].ID)
+	}
+}
@@ -0,0 +1,91 @@
+// Package refinery provides the merge queue processing agent.
+// This file keeps MRs on monorepo sub-rigs within the rig's own subtree.
+
+package refinery
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// maxForeignListed caps the files named in an ownership rejection.
+const maxForeignListed = 10
+
+// CheckOwnership returns the files an MR changes that a sibling sub-rig owns.
+// It returns nil unless the rig shares its repository with other rigs, and
+// an error when the town's rigs can't be read, since any may be a sibling.
+func (e *Engineer) CheckOwnership(branch, target string) ([]rig.ForeignFile, error) {
+	if e.rig == nil {
+		return nil, nil
+	}
+	townRoot, err := workspace.Find(e.rig.Path)
+	if err != nil {
+		return nil, fmt.Errorf("finding town root: %w", err)
+	}
+	if townRoot == "" {
+		return nil, nil
+	}
+	rigsConfig, err := config.LoadRigsConfig(constants.MayorRigsPath(townRoot))
+	if err != nil {
+		return nil, fmt.Errorf("loading rigs config: %w", err)
+	}
+	siblings := rig.NewManager(townRoot, rigsConfig, git.NewGit(townRoot)).Siblings(e.rig)
+	if len(siblings) < 2 {
+		return nil, nil
+	}
+	self := &rig.Rig{Name: e.rig.Name, GitURL: e.rig.GitURL, Subdir: e.rig.Subdir}
+	changes, err := e.git.ChangedFiles(e.resolveRef(target), e.resolveRef(branch))
+	if err != nil {
+		return nil, fmt.Errorf("reading diff of %s: %w", branch, err)
+	}
+	files := make([]string, 0, len(changes))
+	for _, c := range changes {
+		files = append(files, c.Path)
+		if c.OldPath != "" {
+			files = append(files, c.OldPath)
+		}
+	}
+	return rig.ForeignFiles(siblings, self, files), nil
+}
+
+// enforceOwnership rejects an MR that changes files other sub-rigs own. It
+// returns nil when the MR may proceed to merge. Like the guardrails, it
+// fails closed: an MR whose check can't run is failed rather than merged.
+func (e *Engineer) enforceOwnership(branch, target string) *ProcessResult {
+	foreign, err := e.CheckOwnership(branch, target)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Ownership check failed: %v\n", err)
+		return &ProcessResult{
+			Success: false,
+			Error:   fmt.Sprintf("ownership check failed: %v", err),
+		}
+	}
+	if len(foreign) == 0 {
+		return nil
+	}
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Rejected: %d file(s) owned by other rigs\n", len(foreign))
+	return &ProcessResult{
+		OwnershipViolation: true,
+		Error:              formatForeign(e.rig.Name, foreign),
+	}
+}
+
+// formatForeign renders an ownership rejection, grouped by owning rig.
+func formatForeign(rigName string, foreign []rig.ForeignFile) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d file(s) outside rig %s's subtree; split them into MRs for the owning rigs:", len(foreign), rigName)
+	for i, f := range foreign {
+		if i == maxForeignListed {
+			fmt.Fprintf(&sb, "\n  ... and %d more", len(foreign)-maxForeignListed)
+			break
+		}
+		fmt.Fprintf(&sb, "\n  - %s (owned by %s)", f.File, f.Owner)
+	}
+	return sb.String()
+}
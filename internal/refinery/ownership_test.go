@@ -0,0 +1,49 @@
+package refinery
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+func TestFormatForeign(t *testing.T) {
+	msg := formatForeign("api", []rig.ForeignFile{{File: "web/app.ts", Owner: "web"}, {File: "go.mod", Owner: "mono"}})
+	for _, want := range []string{"2 file(s) outside rig api's subtree", "web/app.ts (owned by web)", "go.mod (owned by mono)"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("missing %q in:\n%s", want, msg)
+		}
+	}
+
+	var many []rig.ForeignFile
+	for i := 0; i < maxForeignListed+3; i++ {
+		many = append(many, rig.ForeignFile{File: fmt.Sprintf("web/f%d.ts", i), Owner: "web"})
+	}
+	msg = formatForeign("api", many)
+	if !strings.Contains(msg, "... and 3 more") || strings.Contains(msg, "f12.ts") {
+		t.Errorf("long lists should be truncated:\n%s", msg)
+	}
+}
+
+func TestEnforceOwnership_FailsWhenRigsUnreadable(t *testing.T) {
+	town := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(town, "mayor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(town, "mayor", "town.json"), []byte(`{"name":"test"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(town, "mayor", "rigs.json"), []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	e := &Engineer{rig: &rig.Rig{Name: "api", Path: filepath.Join(town, "api")}, output: io.Discard}
+
+	failed := e.enforceOwnership("polecat/nux", "main")
+	if failed == nil || failed.Success || !strings.Contains(failed.Error, "ownership check failed") {
+		t.Errorf("enforceOwnership(broken rigs.json) = %+v, want a failure", failed)
+	}
+}
@@ -0,0 +1,285 @@
+// Package refinery provides the merge queue processing agent.
+// This file pools the scratch worktrees refinery operations run in.
+
+package refinery
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+// DefaultWorktreePool is how many scratch worktrees a clone keeps warm.
+const DefaultWorktreePool = 2
+
+// WorktreePool keeps scratch worktrees of one clone under the rig's
+// .runtime/worktrees, so trial merges, picks, and test runs reuse a warm
+// checkout instead of creating and removing a worktree each time. Slots are
+// claimed with file locks, so separate gt processes share the pool. When
+// every slot is busy, or the pool is disabled (size 0), Borrow falls back
+// to a throwaway worktree.
+type WorktreePool struct {
+	repo *git.Git
+	dir  string
+	size int
+}
+
+// NewWorktreePool returns the pool of size worktrees of the clone at
+// repoPath (e.g. <rig>/refinery/rig), named after the clone's role.
+func NewWorktreePool(rigPath, repoPath string, size int) *WorktreePool {
+	return &WorktreePool{
+		repo: git.NewGit(repoPath),
+		dir:  filepath.Join(constants.RigRuntimePath(rigPath), "worktrees", filepath.Base(filepath.Dir(repoPath))),
+		size: size,
+	}
+}
+
+// Dir returns the directory the pool's worktrees live in.
+func (p *WorktreePool) Dir() string {
+	return p.dir
+}
+
+// ScratchWorktree is a worktree borrowed from a pool. Return it when done.
+type ScratchWorktree struct {
+	Path string
+	Git  *git.Git
+
+	pool *WorktreePool
+	lock *flock.Flock // nil for a throwaway worktree
+	tmp  string
+}
+
+// Pooled reports whether the worktree came from the pool rather than
+// being created for this borrower.
+func (w *ScratchWorktree) Pooled() bool {
+	return w.lock != nil
+}
+
+// WorktreeSlot describes one slot of a pool.
+type WorktreeSlot struct {
+	Path   string `json:"path"`
+	Exists bool   `json:"exists"`
+	Busy   bool   `json:"busy"`
+	Commit string `json:"commit,omitempty"`
+}
+
+// Borrow returns a scratch worktree with ref checked out on a detached
+// HEAD. Anything a previous borrower left behind is discarded, except
+// ignored files such as build caches.
+func (p *WorktreePool) Borrow(ref string) (*ScratchWorktree, error) {
+	commit, err := p.repo.Rev(ref + "^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("resolving %s: %w", ref, err)
+	}
+	if p.size > 0 {
+		if err := os.MkdirAll(p.dir, 0755); err != nil {
+			return nil, fmt.Errorf("creating worktree pool: %w", err)
+		}
+		for i := 0; i < p.size; i++ {
+			path := p.slotPath(i)
+			fl := flock.New(path + ".lock")
+			if locked, err := fl.TryLock(); err != nil || !locked {
+				continue
+			}
+			if err := p.prepare(path, commit); err != nil {
+				_ = fl.Unlock()
+				return nil, err
+			}
+			return &ScratchWorktree{Path: path, Git: git.NewGit(path), pool: p, lock: fl}, nil
+		}
+	}
+
+	// Pool disabled or exhausted
+	tmp, err := os.MkdirTemp("", "gt-scratch-*")
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(tmp, "worktree")
+	if err := p.repo.WorktreeAddDetached(path, commit); err != nil {
+		_ = os.RemoveAll(tmp)
+		return nil, fmt.Errorf("creating scratch worktree: %w", err)
+	}
+	return &ScratchWorktree{Path: path, Git: git.NewGit(path), pool: p, tmp: tmp}, nil
+}
+
+// BorrowBranch borrows a worktree with a new branch cut from startPoint
+// checked out. The branch outlives the borrow; delete it after Return if
+// it isn't wanted.
+func (p *WorktreePool) BorrowBranch(branch, startPoint string) (*ScratchWorktree, error) {
+	wt, err := p.Borrow(startPoint)
+	if err != nil {
+		return nil, err
+	}
+	if err := wt.Git.CreateBranchFrom(branch, "HEAD"); err != nil {
+		wt.Return()
+		return nil, err
+	}
+	if err := wt.Git.Checkout(branch); err != nil {
+		wt.Return()
+		return nil, err
+	}
+	return wt, nil
+}
+
+// Return hands the worktree back. A pooled worktree is reset onto a
+// detached HEAD, which frees any branch it had checked out, and kept for
+// the next borrower; a throwaway one is removed.
+func (w *ScratchWorktree) Return() {
+	if w.lock == nil {
+		_ = w.pool.repo.WorktreeRemove(w.Path, true)
+		_ = os.RemoveAll(w.tmp)
+		return
+	}
+	if err := w.Git.ResetScratch("HEAD"); err != nil {
+		// Don't hand a broken checkout to the next borrower
+		w.pool.discard(w.Path)
+	}
+	_ = w.lock.Unlock()
+}
+
+// Warm fetches origin, then creates the pool's missing worktrees at ref and
+// moves idle ones to it, so the next borrowers start from a recent
+// checkout. Returns how many slots it warmed; busy slots are skipped.
+func (p *WorktreePool) Warm(ref string) (int, error) {
+	if p.size <= 0 {
+		return 0, nil
+	}
+	if err := p.repo.Fetch("origin"); err != nil {
+		return 0, fmt.Errorf("fetching origin: %w", err)
+	}
+	commit, err := p.repo.Rev(ref + "^{commit}")
+	if err != nil {
+		return 0, fmt.Errorf("resolving %s: %w", ref, err)
+	}
+	if err := os.MkdirAll(p.dir, 0755); err != nil {
+		return 0, fmt.Errorf("creating worktree pool: %w", err)
+	}
+	warmed := 0
+	for i := 0; i < p.size; i++ {
+		path := p.slotPath(i)
+		fl := flock.New(path + ".lock")
+		if locked, err := fl.TryLock(); err != nil || !locked {
+			continue
+		}
+		err := p.prepare(path, commit)
+		_ = fl.Unlock()
+		if err != nil {
+			return warmed, err
+		}
+		warmed++
+	}
+	return warmed, nil
+}
+
+// Prune removes the pool's idle worktrees, including slots beyond its
+// size left over from a larger pool. Returns how many it removed.
+func (p *WorktreePool) Prune() (int, error) {
+	entries, err := os.ReadDir(p.dir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(p.dir, entry.Name())
+		fl := flock.New(path + ".lock")
+		if locked, err := fl.TryLock(); err != nil || !locked {
+			continue
+		}
+		p.discard(path)
+		_ = fl.Unlock()
+		_ = os.Remove(path + ".lock")
+		removed++
+	}
+	return removed, nil
+}
+
+// Slots describes the pool's slots.
+func (p *WorktreePool) Slots() []WorktreeSlot {
+	slots := make([]WorktreeSlot, 0, p.size)
+	for i := 0; i < p.size; i++ {
+		s := WorktreeSlot{Path: p.slotPath(i), Exists: isWorktree(p.slotPath(i))}
+		fl := flock.New(s.Path + ".lock")
+		if _, err := os.Stat(s.Path + ".lock"); err == nil {
+			if locked, err := fl.TryLock(); err == nil && locked {
+				_ = fl.Unlock()
+			} else {
+				s.Busy = true
+			}
+		}
+		if s.Exists {
+			s.Commit, _ = git.NewGit(s.Path).Rev("HEAD")
+		}
+		slots = append(slots, s)
+	}
+	return slots
+}
+
+func (p *WorktreePool) slotPath(i int) string {
+	return filepath.Join(p.dir, fmt.Sprintf("wt-%d", i))
+}
+
+// prepare resets the worktree at path onto commit, creating it if it
+// doesn't exist or can't be reset. The caller holds the slot's lock.
+func (p *WorktreePool) prepare(path, commit string) error {
+	if isWorktree(path) {
+		if err := git.NewGit(path).ResetScratch(commit); err == nil {
+			return nil
+		}
+		p.discard(path)
+	}
+	// Forget a slot whose directory was removed behind git's back
+	_ = p.repo.WorktreePrune()
+	if err := p.repo.WorktreeAddDetached(path, commit); err != nil {
+		return fmt.Errorf("creating pooled worktree: %w", err)
+	}
+	return nil
+}
+
+func (p *WorktreePool) discard(path string) {
+	_ = p.repo.WorktreeRemove(path, true)
+	_ = os.RemoveAll(path)
+}
+
+// isWorktree reports whether path is a linked worktree checkout.
+func isWorktree(path string) bool {
+	info, err := os.Stat(filepath.Join(path, ".git"))
+	return err == nil && !info.IsDir()
+}
+
+// Worktrees returns the pool of scratch worktrees in the refinery's clone.
+func (e *Engineer) Worktrees() *WorktreePool {
+	return NewWorktreePool(e.rig.Path, e.git.WorkDir(), e.config.WorktreePool)
+}
+
+// worktrees returns the pool of scratch worktrees in g's clone, sized by
+// the rig's merge queue config.
+func (m *Manager) worktrees(g *git.Git) *WorktreePool {
+	eng := NewEngineer(m.rig)
+	if err := eng.LoadConfig(); err != nil {
+		eng.config.WorktreePool = DefaultWorktreePool
+	}
+	return NewWorktreePool(m.rig.Path, g.WorkDir(), eng.config.WorktreePool)
+}
+
+// WorktreePools returns the rig's scratch worktree pools: the refinery
+// clone's and, if it is a different clone, the mayor's.
+func (m *Manager) WorktreePools() ([]*WorktreePool, error) {
+	eng := NewEngineer(m.rig)
+	if err := eng.LoadConfig(); err != nil {
+		return nil, fmt.Errorf("loading merge queue config: %w", err)
+	}
+	pools := []*WorktreePool{eng.Worktrees()}
+	if mayor := NewWorktreePool(m.rig.Path, constants.RigMayorPath(m.rig.Path), eng.config.WorktreePool); mayor.dir != pools[0].dir {
+		pools = append(pools, mayor)
+	}
+	return pools, nil
+}
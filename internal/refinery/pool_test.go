@@ -0,0 +1,211 @@
+package refinery
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// setupPoolRepo creates a clone with main and feature branches pushed to
+// an origin, and .gitignore ignoring cache/.
+func setupPoolRepo(t *testing.T) (rigPath, clone string) {
+	t.Helper()
+	rigPath = t.TempDir()
+	origin := filepath.Join(rigPath, "origin.git")
+	clone = filepath.Join(rigPath, "refinery", "rig")
+	run := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@test.com",
+			"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@test.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(clone, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run(rigPath, "init", "--bare", "-b", "main", origin)
+	run(rigPath, "clone", origin, clone)
+	run(clone, "checkout", "-b", "main")
+	write("app.txt", "v1\n")
+	write(".gitignore", "cache/\n")
+	run(clone, "add", ".")
+	run(clone, "commit", "-m", "initial")
+	run(clone, "push", "origin", "main")
+	run(clone, "checkout", "-b", "feature")
+	write("app.txt", "v2\n")
+	run(clone, "commit", "-am", "feature")
+	run(clone, "push", "origin", "feature")
+	run(clone, "checkout", "main")
+	return rigPath, clone
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestWorktreePool_ReusesAndResets(t *testing.T) {
+	rigPath, clone := setupPoolRepo(t)
+	pool := NewWorktreePool(rigPath, clone, 1)
+	if want := filepath.Join(rigPath, ".runtime", "worktrees", "refinery"); pool.Dir() != want {
+		t.Errorf("Dir() = %q, want %q", pool.Dir(), want)
+	}
+
+	wt, err := pool.Borrow("main")
+	if err != nil {
+		t.Fatalf("Borrow: %v", err)
+	}
+	if !wt.Pooled() {
+		t.Fatal("first borrow should come from the pool")
+	}
+	if got := readFile(t, filepath.Join(wt.Path, "app.txt")); got != "v1\n" {
+		t.Errorf("app.txt = %q, want v1", got)
+	}
+	// Leave a mess: a modified file, an untracked file, and a build cache
+	if err := os.WriteFile(filepath.Join(wt.Path, "app.txt"), []byte("dirty\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(wt.Path, "stray.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(wt.Path, "cache"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(wt.Path, "cache", "obj"), []byte("warm"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The only slot is busy, so a second borrower gets a throwaway worktree
+	extra, err := pool.Borrow("main")
+	if err != nil {
+		t.Fatalf("Borrow while busy: %v", err)
+	}
+	if extra.Pooled() || extra.Path == wt.Path {
+		t.Errorf("borrow while busy got the pooled worktree %s", extra.Path)
+	}
+	extra.Return()
+	if _, err := os.Stat(extra.Path); !os.IsNotExist(err) {
+		t.Errorf("throwaway worktree %s should be removed on return", extra.Path)
+	}
+	wt.Return()
+
+	again, err := pool.Borrow("origin/feature")
+	if err != nil {
+		t.Fatalf("Borrow again: %v", err)
+	}
+	defer again.Return()
+	if again.Path != wt.Path {
+		t.Errorf("second borrow got %s, want the pooled %s", again.Path, wt.Path)
+	}
+	if got := readFile(t, filepath.Join(again.Path, "app.txt")); got != "v2\n" {
+		t.Errorf("app.txt = %q, want feature's v2", got)
+	}
+	if _, err := os.Stat(filepath.Join(again.Path, "stray.txt")); !os.IsNotExist(err) {
+		t.Error("untracked file survived the reset")
+	}
+	if got := readFile(t, filepath.Join(again.Path, "cache", "obj")); got != "warm" {
+		t.Errorf("ignored cache = %q, want it kept", got)
+	}
+}
+
+func TestWorktreePool_BorrowBranch(t *testing.T) {
+	rigPath, clone := setupPoolRepo(t)
+	pool := NewWorktreePool(rigPath, clone, 1)
+
+	wt, err := pool.BorrowBranch("revert/gt-mr1", "origin/main")
+	if err != nil {
+		t.Fatalf("BorrowBranch: %v", err)
+	}
+	if branch, _ := wt.Git.CurrentBranch(); branch != "revert/gt-mr1" {
+		t.Errorf("checked out %q, want revert/gt-mr1", branch)
+	}
+	wt.Return()
+	// Returning detaches, so the branch can be deleted
+	if err := pool.repo.DeleteBranch("revert/gt-mr1", true); err != nil {
+		t.Errorf("deleting branch after return: %v", err)
+	}
+}
+
+func TestWorktreePool_Disabled(t *testing.T) {
+	rigPath, clone := setupPoolRepo(t)
+	pool := NewWorktreePool(rigPath, clone, 0)
+
+	wt, err := pool.Borrow("main")
+	if err != nil {
+		t.Fatalf("Borrow: %v", err)
+	}
+	if wt.Pooled() {
+		t.Error("disabled pool handed out a pooled worktree")
+	}
+	wt.Return()
+	if _, err := os.Stat(pool.Dir()); !os.IsNotExist(err) {
+		t.Errorf("disabled pool created %s", pool.Dir())
+	}
+	if slots := pool.Slots(); len(slots) != 0 {
+		t.Errorf("Slots() = %v, want none", slots)
+	}
+}
+
+func TestWorktreePool_WarmPrune(t *testing.T) {
+	rigPath, clone := setupPoolRepo(t)
+	pool := NewWorktreePool(rigPath, clone, 2)
+
+	warmed, err := pool.Warm("origin/main")
+	if err != nil {
+		t.Fatalf("Warm: %v", err)
+	}
+	if warmed != 2 {
+		t.Errorf("warmed %d, want 2", warmed)
+	}
+	for _, s := range pool.Slots() {
+		if !s.Exists || s.Busy || s.Commit == "" {
+			t.Errorf("slot after warm = %+v, want idle with a commit", s)
+		}
+	}
+
+	// A slot deleted behind git's back is recreated
+	if err := os.RemoveAll(pool.slotPath(0)); err != nil {
+		t.Fatal(err)
+	}
+	wt, err := pool.Borrow("main")
+	if err != nil {
+		t.Fatalf("Borrow after slot removed: %v", err)
+	}
+	if wt.Path != pool.slotPath(0) {
+		t.Errorf("borrowed %s, want the recreated %s", wt.Path, pool.slotPath(0))
+	}
+	if slots := pool.Slots(); !slots[0].Busy || slots[1].Busy {
+		t.Errorf("Slots() = %+v, want only the first busy", slots)
+	}
+
+	// Busy slots are left alone
+	pruned, err := pool.Prune()
+	if err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	if pruned != 1 {
+		t.Errorf("pruned %d, want 1", pruned)
+	}
+	wt.Return()
+	if pruned, _ := pool.Prune(); pruned != 1 {
+		t.Errorf("second prune removed %d, want 1", pruned)
+	}
+	for _, s := range pool.Slots() {
+		if s.Exists {
+			t.Errorf("slot %s still exists after prune", s.Path)
+		}
+	}
+}
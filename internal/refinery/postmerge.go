@@ -0,0 +1,227 @@
+// Package refinery provides the merge queue processing agent.
+// This file verifies landings after they merge and rolls back failures.
+
+package refinery
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/mq"
+	"github.com/steveyegge/gastown/internal/release"
+)
+
+// Post-merge verdicts recorded in the MR's post_merge field.
+const (
+	PostMergeMonitoring = "monitoring" // Smoke check passed; health still watched
+	PostMergePassed     = "passed"
+	PostMergeFailed     = "failed"
+)
+
+// PostMergeFailedLabel marks MRs whose landing failed post-merge verification.
+const PostMergeFailedLabel = "post-merge-failed"
+
+// LandingCheck is the outcome of verifying one landing.
+type LandingCheck struct {
+	MR       string    `json:"mr"`
+	Commit   string    `json:"commit"`
+	MergedAt time.Time `json:"merged_at"`
+	Verdict  string    `json:"verdict,omitempty"` // Empty while still inside the window
+	Output   string    `json:"output,omitempty"`
+	Frozen   bool      `json:"frozen,omitempty"`
+	RevertMR string    `json:"revert_mr,omitempty"`
+}
+
+// PostMergeEnabled reports whether the rig verifies landings.
+func (e *Engineer) PostMergeEnabled() bool {
+	return e.config.PostMergeCommand != "" || e.config.PostMergeHealthURL != ""
+}
+
+func (e *Engineer) postMergeWindow() time.Duration {
+	d, err := time.ParseDuration(e.config.PostMergeWindow)
+	if err != nil || d <= 0 {
+		return 30 * time.Minute
+	}
+	return d
+}
+
+// PendingLandings returns merged MRs inside the post-merge window that
+// don't have a final verdict yet, oldest first.
+func (e *Engineer) PendingLandings(now time.Time) ([]*beads.Issue, error) {
+	mrs, err := e.beads.List(beads.ListOptions{Status: "closed", Type: "merge-request", Priority: -1})
+	if err != nil {
+		return nil, fmt.Errorf("listing merged MRs: %w", err)
+	}
+	var pending []*beads.Issue
+	for _, mr := range mrs {
+		fields := beads.ParseMRFields(mr)
+		if fields == nil || fields.MergeCommit == "" || !release.IsMerged(mr) {
+			continue
+		}
+		if fields.PostMerge == PostMergePassed || fields.PostMerge == PostMergeFailed {
+			continue
+		}
+		if now.Sub(mergedAt(mr)) > e.postMergeWindow() && fields.PostMerge == "" {
+			// Merged before verification could see it; don't judge old landings
+			continue
+		}
+		pending = append(pending, mr)
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		return mergedAt(pending[i]).Before(mergedAt(pending[j]))
+	})
+	return pending, nil
+}
+
+// mergedAt is when an MR bead was closed as merged.
+func mergedAt(mr *beads.Issue) time.Time {
+	for _, s := range []string{mr.ClosedAt, mr.UpdatedAt} {
+		if t, err := time.Parse(time.RFC3339, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// VerifyLanding runs the post-merge checks for a merged MR and records the
+// verdict. The smoke command runs once; the health URL is checked on every
+// call until the window closes. A failure flags the MR, freezes the queue,
+// and reverts the landing if configured.
+func (e *Engineer) VerifyLanding(ctx context.Context, mr *beads.Issue, now time.Time) (*LandingCheck, error) {
+	fields := beads.ParseMRFields(mr)
+	if fields == nil || fields.MergeCommit == "" {
+		return nil, fmt.Errorf("%s has no merge commit", mr.ID)
+	}
+	check := &LandingCheck{MR: mr.ID, Commit: fields.MergeCommit, MergedAt: mergedAt(mr)}
+
+	if e.config.PostMergeCommand != "" && fields.PostMerge == "" {
+		passed, output, err := e.runPostMergeCommand(ctx, mr.ID, fields.MergeCommit)
+		if err != nil {
+			return nil, err
+		}
+		if !passed {
+			check.Output = output
+			return check, e.failLanding(mr, fields, check)
+		}
+		verdict := PostMergePassed
+		if e.config.PostMergeHealthURL != "" {
+			verdict = PostMergeMonitoring
+		}
+		if err := e.recordPostMerge(mr, fields, verdict); err != nil {
+			return nil, err
+		}
+		if verdict == PostMergePassed {
+			check.Verdict = verdict
+			return check, nil
+		}
+	}
+
+	if e.config.PostMergeHealthURL != "" {
+		if healthy, detail := checkHealth(ctx, e.config.PostMergeHealthURL); !healthy {
+			check.Output = detail
+			return check, e.failLanding(mr, fields, check)
+		}
+		if now.Sub(check.MergedAt) >= e.postMergeWindow() {
+			check.Verdict = PostMergePassed
+			return check, e.recordPostMerge(mr, fields, PostMergePassed)
+		}
+	}
+	return check, nil
+}
+
+// runPostMergeCommand runs the smoke command in a pooled scratch worktree
+// at commit.
+func (e *Engineer) runPostMergeCommand(ctx context.Context, mrID, commit string) (bool, string, error) {
+	_ = e.git.Fetch("origin")
+	scratch, err := e.Worktrees().Borrow(commit)
+	if err != nil {
+		return false, "", fmt.Errorf("checking out %s: %w", shortCommit(commit), err)
+	}
+	defer scratch.Return()
+
+	// Trust boundary: PostMergeCommand comes from the rig's config.json.
+	cmd := exec.CommandContext(ctx, "sh", "-c", e.config.PostMergeCommand) //nolint:gosec // G204: from trusted rig config
+	cmd.Dir = scratch.Path
+	cmd.Env = append(os.Environ(), "GT_MERGE_COMMIT="+commit, "GT_MR="+mrID)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		return false, fmt.Sprintf("%v\n%s", err, strings.TrimSpace(output.String())), nil
+	}
+	return true, "", nil
+}
+
+// checkHealth GETs url; any 2xx is healthy.
+func checkHealth(ctx context.Context, url string) (bool, string) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false, err.Error()
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return false, err.Error()
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return false, fmt.Sprintf("health check %s returned %s", url, resp.Status)
+	}
+	return true, ""
+}
+
+func (e *Engineer) recordPostMerge(mr *beads.Issue, fields *beads.MRFields, verdict string) error {
+	fields.PostMerge = verdict
+	description := beads.SetMRFields(mr, fields)
+	if err := e.beads.Update(mr.ID, beads.UpdateOptions{Description: &description}); err != nil {
+		return fmt.Errorf("recording post-merge verdict on %s: %w", mr.ID, err)
+	}
+	mr.Description = description
+	return nil
+}
+
+// failLanding flags the MR, freezes the queue, and optionally reverts.
+func (e *Engineer) failLanding(mr *beads.Issue, fields *beads.MRFields, check *LandingCheck) error {
+	check.Verdict = PostMergeFailed
+	if err := e.recordPostMerge(mr, fields, PostMergeFailed); err != nil {
+		return err
+	}
+	if err := e.beads.Update(mr.ID, beads.UpdateOptions{AddLabels: []string{PostMergeFailedLabel}}); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to label %s: %v\n", mr.ID, err)
+	}
+
+	reason := fmt.Sprintf("post-merge verification failed for %s (%s)", mr.ID, shortCommit(fields.MergeCommit))
+	existing, err := mq.LoadFreeze(e.rig.Path)
+	if err != nil {
+		return err
+	}
+	if !existing.Active(time.Now()) {
+		if err := mq.SaveFreeze(e.rig.Path, &mq.Freeze{Reason: reason, FrozenBy: e.rig.Name + "/refinery", FrozenAt: time.Now().UTC()}); err != nil {
+			return fmt.Errorf("freezing queue: %w", err)
+		}
+	}
+	check.Frozen = true
+
+	if e.config.PostMergeAutoRevert {
+		result, err := NewManager(e.rig).RevertMR(mr.ID, RevertOptions{
+			Reason:   reason,
+			Actor:    e.rig.Name + "/refinery",
+			Priority: -1,
+			Hotfix:   true, // Must merge through the freeze above
+		})
+		if err != nil {
+			return fmt.Errorf("reverting %s: %w", mr.ID, err)
+		}
+		check.RevertMR = result.RevertMR
+	}
+	return nil
+}
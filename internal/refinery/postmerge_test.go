@@ -0,0 +1,111 @@
+package refinery
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+func TestRunPostMergeCommand(t *testing.T) {
+	repo := t.TempDir()
+	run := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@test.com",
+			"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@test.com")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	run("init", "-b", "main")
+	if err := os.WriteFile(filepath.Join(repo, "VERSION"), []byte("1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run("add", ".")
+	run("commit", "-m", "initial")
+	commit := run("rev-parse", "HEAD")
+
+	tests := []struct {
+		name    string
+		command string
+		want    bool
+		output  string
+	}{
+		{"pass", `grep -q 1 VERSION && test "$GT_MERGE_COMMIT" = ` + commit + ` && test "$GT_MR" = gt-mr1`, true, ""},
+		{"fail", "echo smoke broke; exit 1", false, "smoke broke"},
+	}
+	for _, tt := range tests {
+		cfg := DefaultMergeQueueConfig()
+		cfg.PostMergeCommand = tt.command
+		e := &Engineer{rig: &rig.Rig{Name: "test-rig", Path: repo}, config: cfg, git: git.NewGit(repo), output: io.Discard}
+		passed, output, err := e.runPostMergeCommand(context.Background(), "gt-mr1", commit)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if passed != tt.want || !strings.Contains(output, tt.output) {
+			t.Errorf("%s: runPostMergeCommand = %v %q, want %v containing %q", tt.name, passed, output, tt.want, tt.output)
+		}
+	}
+	// Both runs reuse the one pooled scratch worktree
+	if out := run("worktree", "list"); strings.Count(out, "\n") != 1 || !strings.Contains(out, filepath.Join(".runtime", "worktrees")) {
+		t.Errorf("scratch worktrees left behind:\n%s", out)
+	}
+}
+
+func TestCheckHealth(t *testing.T) {
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	if healthy, detail := checkHealth(context.Background(), srv.URL); !healthy {
+		t.Errorf("200: unhealthy: %s", detail)
+	}
+	status = http.StatusServiceUnavailable
+	if healthy, detail := checkHealth(context.Background(), srv.URL); healthy || !strings.Contains(detail, "503") {
+		t.Errorf("503: healthy=%v detail=%q", healthy, detail)
+	}
+}
+
+func TestPostMergeWindow(t *testing.T) {
+	tests := []struct {
+		window string
+		want   time.Duration
+	}{
+		{"10m", 10 * time.Minute},
+		{"", 30 * time.Minute},
+		{"bogus", 30 * time.Minute},
+	}
+	for _, tt := range tests {
+		e := &Engineer{config: &MergeQueueConfig{PostMergeWindow: tt.window}}
+		if got := e.postMergeWindow(); got != tt.want {
+			t.Errorf("postMergeWindow(%q) = %s, want %s", tt.window, got, tt.want)
+		}
+	}
+}
+
+func TestMergedAt(t *testing.T) {
+	closed := "2026-01-02T03:04:05Z"
+	if got := mergedAt(&beads.Issue{ClosedAt: closed, UpdatedAt: "2026-02-01T00:00:00Z"}); got.Format(time.RFC3339) != closed {
+		t.Errorf("mergedAt = %s, want %s", got, closed)
+	}
+	if got := mergedAt(&beads.Issue{}); !got.IsZero() {
+		t.Errorf("mergedAt with no timestamps = %s, want zero", got)
+	}
+}
@@ -0,0 +1,216 @@
+// Package refinery provides the merge queue processing agent.
+// This file reverts merged MRs through the queue.
+
+package refinery
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+// RevertLabel marks revert beads and the issues and MRs they revert.
+const RevertLabel = "reverted"
+
+// ErrMRNotMerged is returned when reverting an MR that never merged.
+var ErrMRNotMerged = errors.New("merge request has not merged")
+
+// RevertOptions configures RevertMR.
+type RevertOptions struct {
+	Reason string
+	Actor  string
+	// Commit overrides the MR's recorded merge commit.
+	Commit string
+	// Priority for the revert MR; negative means elevated above the source issue.
+	Priority int
+	Hotfix   bool
+	DryRun   bool
+}
+
+// RevertResult describes a revert pushed through the queue.
+type RevertResult struct {
+	MR             string `json:"mr"`
+	SourceIssue    string `json:"source_issue,omitempty"`
+	RevertedCommit string `json:"reverted_commit"`
+	Target         string `json:"target"`
+	Branch         string `json:"branch"`
+	RevertCommit   string `json:"revert_commit,omitempty"`
+	RevertBead     string `json:"revert_bead,omitempty"`
+	RevertMR       string `json:"revert_mr,omitempty"`
+	Priority       int    `json:"priority"`
+	DryRun         bool   `json:"dry_run,omitempty"`
+}
+
+// RevertMR reverts a merged MR: it commits the revert on a new branch cut
+// from the target, files a revert bead linked to the original issue and MR,
+// submits the branch to the queue at elevated priority, and reopens the
+// source issue.
+func (m *Manager) RevertMR(mrID string, opts RevertOptions) (*RevertResult, error) {
+	b := beads.New(m.rig.BeadsPath())
+	mr, err := b.Show(mrID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching merge request %s: %w", mrID, err)
+	}
+	fields := beads.ParseMRFields(mr)
+	if fields == nil {
+		return nil, fmt.Errorf("%s has no MR fields; is it a merge request?", mrID)
+	}
+	commit := opts.Commit
+	if commit == "" {
+		if mr.Status != "closed" || fields.MergeCommit == "" {
+			return nil, fmt.Errorf("%w: %s has no merge commit (pass one explicitly)", ErrMRNotMerged, mrID)
+		}
+		commit = fields.MergeCommit
+	}
+	target := fields.Target
+	if target == "" {
+		target = m.rig.DefaultBranch()
+	}
+
+	var source *beads.Issue
+	if fields.SourceIssue != "" {
+		source, _ = b.Show(fields.SourceIssue)
+	}
+	priority := opts.Priority
+	if priority < 0 {
+		priority = 1
+		if source != nil && source.Priority < priority {
+			priority = source.Priority
+		}
+	}
+
+	result := &RevertResult{
+		MR:          mrID,
+		SourceIssue: fields.SourceIssue,
+		Target:      target,
+		Branch:      "revert/" + mrID,
+		Priority:    priority,
+		DryRun:      opts.DryRun,
+	}
+
+	g := git.NewGit(constants.RigMayorPath(m.rig.Path))
+	if err := g.Fetch("origin"); err != nil {
+		return nil, fmt.Errorf("fetching origin: %w", err)
+	}
+	full, err := g.Rev(commit + "^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("unknown commit %s: %w", commit, err)
+	}
+	result.RevertedCommit = full
+	if onTarget, err := g.IsAncestor(full, "origin/"+target); err != nil || !onTarget {
+		return nil, fmt.Errorf("%s is not on origin/%s", shortCommit(full), target)
+	}
+	if opts.DryRun {
+		return result, nil
+	}
+
+	// File the revert bead first so the commit can reference it
+	title := "Revert " + mrID
+	if source != nil {
+		title = fmt.Sprintf("Revert %s: %s", source.ID, source.Title)
+	}
+	bead, err := b.Create(beads.CreateOptions{
+		Title:       title,
+		Type:        "task",
+		Priority:    priority,
+		Description: formatRevertDescription(result, opts.Reason),
+		Labels:      []string{RevertLabel},
+		Actor:       opts.Actor,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating revert bead: %w", err)
+	}
+	result.RevertBead = bead.ID
+
+	message := fmt.Sprintf("Revert %s (%s)\n\nThis reverts commit %s.", mrID, bead.ID, full)
+	if opts.Reason != "" {
+		message += "\n\nReason: " + opts.Reason
+	}
+	if result.RevertCommit, err = m.commitRevert(g, result.Branch, target, full, message); err != nil {
+		_ = b.CloseWithReason("revert failed: "+err.Error(), bead.ID)
+		return nil, err
+	}
+
+	labels := []string{RevertLabel}
+	if opts.Hotfix {
+		labels = append(labels, beads.HotfixLabel)
+	}
+	revertMR, err := b.Create(beads.CreateOptions{
+		Title:    "Merge: " + bead.ID,
+		Type:     "merge-request",
+		Priority: priority,
+		Description: fmt.Sprintf("branch: %s\ntarget: %s\nsource_issue: %s\nrig: %s",
+			result.Branch, target, bead.ID, m.rig.Name),
+		Labels:    labels,
+		Actor:     opts.Actor,
+		Ephemeral: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("submitting revert MR: %w", err)
+	}
+	result.RevertMR = revertMR.ID
+
+	// The original work isn't done anymore
+	_ = b.Update(mrID, beads.UpdateOptions{AddLabels: []string{RevertLabel}})
+	if source != nil {
+		open := "open"
+		note := fmt.Sprintf("Reverted in %s (%s, MR %s).", bead.ID, shortCommit(full), revertMR.ID)
+		if opts.Reason != "" {
+			note += " Reason: " + opts.Reason
+		}
+		description := beads.SetDescriptionSection(source.Description, "revert", note)
+		if err := b.Update(source.ID, beads.UpdateOptions{
+			Status:      &open,
+			Description: &description,
+			AddLabels:   []string{RevertLabel},
+		}); err != nil {
+			_, _ = fmt.Fprintf(m.output, "Warning: failed to reopen %s: %v\n", source.ID, err)
+		}
+	}
+
+	return result, nil
+}
+
+// formatRevertDescription links a revert bead to what it reverts.
+func formatRevertDescription(r *RevertResult, reason string) string {
+	lines := []string{fmt.Sprintf("Reverts %s, merged as %s.", r.MR, shortCommit(r.RevertedCommit)), ""}
+	lines = append(lines, "reverts_mr: "+r.MR)
+	if r.SourceIssue != "" {
+		lines = append(lines, "reverts_issue: "+r.SourceIssue)
+	}
+	lines = append(lines, "reverted_commit: "+r.RevertedCommit, "branch: "+r.Branch)
+	if reason != "" {
+		lines = append(lines, "reason: "+reason)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// commitRevert creates branch from origin/target in a pooled worktree,
+// commits the revert, and pushes it. Returns the revert commit.
+func (m *Manager) commitRevert(g *git.Git, branch, target, commit, message string) (string, error) {
+	scratch, err := m.worktrees(g).BorrowBranch(branch, "origin/"+target)
+	if err != nil {
+		return "", fmt.Errorf("creating revert branch %s: %w", branch, err)
+	}
+	defer func() {
+		scratch.Return()
+		_ = g.DeleteBranch(branch, true)
+	}()
+
+	wt := scratch.Git
+	if err := wt.Revert(commit, message); err != nil {
+		return "", fmt.Errorf("reverting %s onto %s: %w", shortCommit(commit), target, err)
+	}
+	sha, err := wt.Rev("HEAD")
+	if err != nil {
+		return "", err
+	}
+	if err := wt.Push("origin", branch, false); err != nil {
+		return "", fmt.Errorf("pushing %s: %w", branch, err)
+	}
+	return sha, nil
+}
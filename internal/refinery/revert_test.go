@@ -0,0 +1,75 @@
+package refinery
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+func TestCommitRevert(t *testing.T) {
+	tmp := t.TempDir()
+	origin := filepath.Join(tmp, "origin.git")
+	clone := filepath.Join(tmp, "clone")
+	run := func(dir string, args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@test.com",
+			"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@test.com")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+
+	run(tmp, "init", "--bare", "-b", "main", origin)
+	run(tmp, "clone", origin, clone)
+	run(clone, "config", "user.email", "test@test.com")
+	run(clone, "config", "user.name", "Test")
+	run(clone, "checkout", "-b", "main")
+	if err := os.WriteFile(filepath.Join(clone, "app.txt"), []byte("v1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run(clone, "add", ".")
+	run(clone, "commit", "-m", "initial")
+	if err := os.WriteFile(filepath.Join(clone, "app.txt"), []byte("v2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run(clone, "commit", "-am", "bad change")
+	bad := run(clone, "rev-parse", "HEAD")
+	run(clone, "push", "origin", "main")
+
+	m := &Manager{rig: &rig.Rig{Name: "test-rig", Path: tmp}, output: io.Discard}
+	sha, err := m.commitRevert(git.NewGit(clone), "revert/gt-mr1", "main", bad, "Revert gt-mr1")
+	if err != nil {
+		t.Fatalf("commitRevert: %v", err)
+	}
+
+	if got := run(origin, "rev-parse", "revert/gt-mr1"); got != sha {
+		t.Errorf("origin revert/gt-mr1 = %s, want %s", got, sha)
+	}
+	if got := run(origin, "show", "revert/gt-mr1:app.txt"); got != "v1" {
+		t.Errorf("app.txt on revert branch = %q, want v1", got)
+	}
+	if out := run(clone, "branch", "--list", "revert/gt-mr1"); out != "" {
+		t.Errorf("local revert branch left behind: %q", out)
+	}
+}
+
+func TestFormatRevertDescription(t *testing.T) {
+	r := &RevertResult{MR: "gt-mr1", SourceIssue: "gt-42", RevertedCommit: "0123456789abcdef", Branch: "revert/gt-mr1"}
+	got := formatRevertDescription(r, "broke login")
+	for _, want := range []string{"reverts_mr: gt-mr1", "reverts_issue: gt-42", "reverted_commit: 0123456789abcdef", "reason: broke login"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("description missing %q:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,350 @@
+// Package refinery provides the merge queue processing agent.
+// This file implements the review step of the MR lifecycle.
+
+package refinery
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/codeowners"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/mail"
+)
+
+// Review states recorded in the MR's review field. An MR with no review
+// field was never held for review and merges normally.
+const (
+	ReviewRequested        = "requested"
+	ReviewApproved         = "approved"
+	ReviewChangesRequested = "changes_requested"
+)
+
+// Verdicts a reviewer can post.
+const (
+	VerdictApprove        = "approve"
+	VerdictRequestChanges = "request_changes"
+	VerdictComment        = "comment"
+)
+
+// ReviewSection is the MR bead description section holding the latest verdict.
+const ReviewSection = "review"
+
+// reviewMaxDiff caps the diff included in a review packet by default.
+const reviewMaxDiff = 200 * 1024
+
+// ErrNoReviewPending is returned when no MR is waiting for review.
+var ErrNoReviewPending = errors.New("no merge requests awaiting review")
+
+// ReviewHolds reports whether an MR's review state keeps it out of the
+// merge queue.
+func ReviewHolds(fields *beads.MRFields) bool {
+	return fields != nil && (fields.Review == ReviewRequested || fields.Review == ReviewChangesRequested)
+}
+
+// ReviewComment is a reviewer's note, optionally anchored to a line.
+type ReviewComment struct {
+	Path string `json:"path,omitempty"`
+	Line int    `json:"line,omitempty"`
+	Body string `json:"body"`
+}
+
+// ReviewVerdict is a structured review posted back on an MR.
+type ReviewVerdict struct {
+	Verdict  string          `json:"verdict"` // approve, request_changes, or comment
+	Summary  string          `json:"summary,omitempty"`
+	Comments []ReviewComment `json:"comments,omitempty"`
+	Reviewer string          `json:"reviewer,omitempty"`
+}
+
+// Validate checks the verdict and its comments.
+func (v *ReviewVerdict) Validate() error {
+	switch v.Verdict {
+	case VerdictApprove, VerdictComment:
+	case VerdictRequestChanges:
+		if v.Summary == "" && len(v.Comments) == 0 {
+			return fmt.Errorf("request_changes needs a summary or comments")
+		}
+	default:
+		return fmt.Errorf("invalid verdict %q: want %s, %s, or %s", v.Verdict, VerdictApprove, VerdictRequestChanges, VerdictComment)
+	}
+	for i, c := range v.Comments {
+		if strings.TrimSpace(c.Body) == "" {
+			return fmt.Errorf("comment %d has no body", i+1)
+		}
+	}
+	return nil
+}
+
+// Markdown renders the verdict for the MR bead's review section.
+func (v *ReviewVerdict) Markdown() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "verdict: %s\n", v.Verdict)
+	if v.Reviewer != "" {
+		fmt.Fprintf(&sb, "reviewer: %s\n", v.Reviewer)
+	}
+	if v.Summary != "" {
+		fmt.Fprintf(&sb, "\n%s\n", strings.TrimSpace(v.Summary))
+	}
+	if len(v.Comments) > 0 {
+		sb.WriteString("\n")
+		for _, c := range v.Comments {
+			fmt.Fprintf(&sb, "- %s%s\n", c.location(), strings.TrimSpace(c.Body))
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func (c ReviewComment) location() string {
+	switch {
+	case c.Path != "" && c.Line > 0:
+		return fmt.Sprintf("%s:%d: ", c.Path, c.Line)
+	case c.Path != "":
+		return c.Path + ": "
+	}
+	return ""
+}
+
+// ReviewIssue is the source issue as included in a review packet.
+type ReviewIssue struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+}
+
+// ReviewPacket is everything a reviewer needs for one MR.
+type ReviewPacket struct {
+	MR             string           `json:"mr"`
+	Title          string           `json:"title"`
+	Branch         string           `json:"branch"`
+	Target         string           `json:"target"`
+	Worker         string           `json:"worker,omitempty"`
+	Priority       int              `json:"priority"`
+	SourceIssue    *ReviewIssue     `json:"source_issue,omitempty"`
+	Commits        []string         `json:"commits,omitempty"`
+	DiffStat       *git.DiffStat    `json:"diff_stat,omitempty"`
+	Files          []git.FileChange `json:"files,omitempty"`
+	Diff           string           `json:"diff"`
+	DiffTruncated  bool             `json:"diff_truncated,omitempty"`
+	ChangeSummary  string           `json:"change_summary,omitempty"`
+	PreviousReview string           `json:"previous_review,omitempty"`
+	PendingOwners  []string         `json:"pending_owners,omitempty"` // code owners yet to approve
+	Discussion     []CommentThread  `json:"discussion,omitempty"`
+}
+
+// ReviewQueue returns open MRs awaiting review, highest priority first and
+// oldest first within a priority.
+func (e *Engineer) ReviewQueue() ([]*beads.Issue, error) {
+	issues, err := e.beads.List(beads.ListOptions{Status: "open", Type: "merge-request", Priority: -1})
+	if err != nil {
+		return nil, fmt.Errorf("querying merge queue: %w", err)
+	}
+	var queue []*beads.Issue
+	for _, issue := range issues {
+		if issue.Status != "open" {
+			continue
+		}
+		if fields := beads.ParseMRFields(issue); fields != nil && fields.Review == ReviewRequested {
+			queue = append(queue, issue)
+		}
+	}
+	sort.SliceStable(queue, func(i, j int) bool {
+		if queue[i].Priority != queue[j].Priority {
+			return queue[i].Priority < queue[j].Priority
+		}
+		return queue[i].CreatedAt < queue[j].CreatedAt
+	})
+	return queue, nil
+}
+
+// NextReview returns the next MR awaiting review that isn't claimed by
+// another reviewer. With claim set, the MR is claimed for reviewer.
+func (e *Engineer) NextReview(reviewer string, claim bool) (*beads.Issue, error) {
+	queue, err := e.ReviewQueue()
+	if err != nil {
+		return nil, err
+	}
+	for _, mr := range queue {
+		fields := beads.ParseMRFields(mr)
+		if fields.Owners != "" {
+			// Owned MRs go to their pending owners, each of whom reviews
+			// independently, so they aren't claimed.
+			if !ownsAny(reviewer, PendingOwners(fields)) {
+				continue
+			}
+			return mr, nil
+		}
+		if fields.Reviewer != "" && fields.Reviewer != reviewer {
+			continue
+		}
+		if claim && fields.Reviewer == "" {
+			fields.Reviewer = reviewer
+			if err := e.updateMRFields(mr, fields, ""); err != nil {
+				return nil, err
+			}
+		}
+		return mr, nil
+	}
+	return nil, ErrNoReviewPending
+}
+
+// ownsAny reports whether reviewer satisfies any of owners.
+func ownsAny(reviewer string, owners []string) bool {
+	for _, o := range owners {
+		if codeowners.Satisfies(reviewer, o) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReviewPacket gathers an MR's diff and context for a reviewer. maxDiff
+// caps the diff in bytes (0 uses the default).
+func (e *Engineer) ReviewPacket(mr *beads.Issue, maxDiff int) (*ReviewPacket, error) {
+	fields := beads.ParseMRFields(mr)
+	if fields == nil || fields.Branch == "" {
+		return nil, fmt.Errorf("%s has no branch field; is it a merge request?", mr.ID)
+	}
+	target := fields.Target
+	if target == "" {
+		target = e.config.TargetBranch
+	}
+	if maxDiff <= 0 {
+		maxDiff = reviewMaxDiff
+	}
+
+	_ = e.git.Fetch("origin")
+	base, head := e.resolveRef(target), e.resolveRef(fields.Branch)
+	p := &ReviewPacket{
+		MR:             mr.ID,
+		Title:          mr.Title,
+		Branch:         fields.Branch,
+		Target:         target,
+		Worker:         fields.Worker,
+		Priority:       mr.Priority,
+		ChangeSummary:  beads.GetDescriptionSection(mr.Description, ChangeSummarySection),
+		PreviousReview: beads.GetDescriptionSection(mr.Description, ReviewSection),
+		PendingOwners:  PendingOwners(fields),
+		Discussion:     CommentThreads(ParseMRComments(mr.Description)),
+	}
+	var err error
+	if p.Diff, err = e.git.Diff(base, head); err != nil {
+		return nil, fmt.Errorf("diffing %s against %s: %w", fields.Branch, target, err)
+	}
+	if len(p.Diff) > maxDiff {
+		p.Diff, p.DiffTruncated = p.Diff[:maxDiff], true
+	}
+	p.DiffStat, _ = e.git.DiffStat(base, head)
+	p.Files, _ = e.git.ChangedFiles(base, head)
+	p.Commits, _ = e.git.CommitSubjects(base, head)
+
+	if fields.SourceIssue != "" {
+		if source, err := e.beads.Show(fields.SourceIssue); err == nil {
+			p.SourceIssue = &ReviewIssue{ID: source.ID, Title: source.Title, Description: source.Description}
+		}
+	}
+	return p, nil
+}
+
+// RequestReview holds an MR for review, clearing any earlier claim or
+// verdict state.
+func (e *Engineer) RequestReview(mrID string) error {
+	mr, err := e.beads.Show(mrID)
+	if err != nil {
+		return fmt.Errorf("fetching merge request %s: %w", mrID, err)
+	}
+	fields := beads.ParseMRFields(mr)
+	if fields == nil {
+		return fmt.Errorf("%s has no MR fields; is it a merge request?", mrID)
+	}
+	fields.Review = ReviewRequested
+	fields.Reviewer = ""
+	fields.Approvals = "" // new changes need fresh approvals
+	return e.updateMRFields(mr, fields, "")
+}
+
+// PostReview records a verdict on an MR. Approval releases the MR to the
+// merge queue; a change request holds it and mails the worker. A comment
+// leaves the review state unchanged. Returns the MR's new review state.
+func (e *Engineer) PostReview(mrID string, v *ReviewVerdict) (string, error) {
+	if err := v.Validate(); err != nil {
+		return "", err
+	}
+	mr, err := e.beads.Show(mrID)
+	if err != nil {
+		return "", fmt.Errorf("fetching merge request %s: %w", mrID, err)
+	}
+	if mr.Status == "closed" {
+		return "", fmt.Errorf("%w: %s", ErrClosedImmutable, mrID)
+	}
+	fields := beads.ParseMRFields(mr)
+	if fields == nil {
+		return "", fmt.Errorf("%s has no MR fields; is it a merge request?", mrID)
+	}
+
+	switch v.Verdict {
+	case VerdictApprove:
+		fields.Review = ReviewApproved
+		if v.Reviewer != "" && !slices.Contains(codeowners.SplitList(fields.Approvals), v.Reviewer) {
+			fields.Approvals = strings.Join(append(codeowners.SplitList(fields.Approvals), v.Reviewer), ", ")
+		}
+		// With code owners, the MR stays held until each has approved.
+		if len(PendingOwners(fields)) > 0 {
+			fields.Review = ReviewRequested
+		}
+	case VerdictRequestChanges:
+		fields.Review = ReviewChangesRequested
+	}
+	if v.Reviewer != "" {
+		fields.Reviewer = v.Reviewer
+	}
+	if err := e.updateMRFields(mr, fields, v.Markdown()); err != nil {
+		return "", err
+	}
+
+	if v.Verdict == VerdictRequestChanges && fields.Worker != "" {
+		e.notifyChangesRequested(mr, fields, v)
+	}
+	return fields.Review, nil
+}
+
+// updateMRFields writes fields (and the review section, if given) to the MR.
+func (e *Engineer) updateMRFields(mr *beads.Issue, fields *beads.MRFields, review string) error {
+	description := beads.SetMRFields(mr, fields)
+	if review != "" {
+		description = beads.SetDescriptionSection(description, ReviewSection, review)
+	}
+	if err := e.beads.Update(mr.ID, beads.UpdateOptions{Description: &description}); err != nil {
+		return fmt.Errorf("updating merge request %s: %w", mr.ID, err)
+	}
+	mr.Description = description
+	return nil
+}
+
+// notifyChangesRequested mails the review to the MR's worker.
+func (e *Engineer) notifyChangesRequested(mr *beads.Issue, fields *beads.MRFields, v *ReviewVerdict) {
+	msg := &mail.Message{
+		From:    fmt.Sprintf("%s/refinery", e.rig.Name),
+		To:      fmt.Sprintf("%s/%s", e.rig.Name, fields.Worker),
+		Subject: fmt.Sprintf("Changes requested: %s", mr.ID),
+		Body: fmt.Sprintf(`Review of your merge request requested changes.
+
+Branch: %s
+Issue: %s
+
+%s
+
+Push fixes to the same branch, then run 'gt mq submit' to request review again.`,
+			fields.Branch, fields.SourceIssue, v.Markdown()),
+		Priority:  mail.PriorityHigh,
+		Timestamp: time.Now(),
+	}
+	if err := e.router.Send(msg); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to notify %s: %v\n", fields.Worker, err)
+	}
+}
@@ -0,0 +1,74 @@
+package refinery
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+func TestReviewHolds(t *testing.T) {
+	tests := []struct {
+		review string
+		want   bool
+	}{
+		{"", false},
+		{ReviewRequested, true},
+		{ReviewChangesRequested, true},
+		{ReviewApproved, false},
+	}
+	for _, tt := range tests {
+		if got := ReviewHolds(&beads.MRFields{Review: tt.review}); got != tt.want {
+			t.Errorf("ReviewHolds(%q) = %v, want %v", tt.review, got, tt.want)
+		}
+	}
+	if ReviewHolds(nil) {
+		t.Error("ReviewHolds(nil) = true")
+	}
+}
+
+func TestReviewVerdictValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		verdict ReviewVerdict
+		wantErr bool
+	}{
+		{"approve", ReviewVerdict{Verdict: VerdictApprove}, false},
+		{"comment", ReviewVerdict{Verdict: VerdictComment, Comments: []ReviewComment{{Body: "nit"}}}, false},
+		{"changes with summary", ReviewVerdict{Verdict: VerdictRequestChanges, Summary: "needs tests"}, false},
+		{"changes without reasons", ReviewVerdict{Verdict: VerdictRequestChanges}, true},
+		{"empty comment", ReviewVerdict{Verdict: VerdictComment, Comments: []ReviewComment{{Path: "a.go"}}}, true},
+		{"unknown", ReviewVerdict{Verdict: "lgtm"}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.verdict.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestReviewVerdictMarkdown(t *testing.T) {
+	v := &ReviewVerdict{
+		Verdict:  VerdictRequestChanges,
+		Summary:  "Needs tests.",
+		Reviewer: "gastown/crew/rev",
+		Comments: []ReviewComment{
+			{Path: "auth.go", Line: 42, Body: "token is never checked"},
+			{Path: "README.md", Body: "typo"},
+			{Body: "general note"},
+		},
+	}
+	got := v.Markdown()
+	for _, want := range []string{
+		"verdict: request_changes",
+		"reviewer: gastown/crew/rev",
+		"Needs tests.",
+		"- auth.go:42: token is never checked",
+		"- README.md: typo",
+		"- general note",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Markdown missing %q:\n%s", want, got)
+		}
+	}
+}
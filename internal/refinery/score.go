@@ -74,6 +74,21 @@ type ScoreInput struct {
 	Now time.Time
 }
 
+// ScoreParts is an MR's score broken down by factor, so the queue order
+// can be explained.
+type ScoreParts struct {
+	Base         float64 `json:"base"`
+	ConvoyAge    float64 `json:"convoy_age"`
+	Priority     float64 `json:"priority"`
+	RetryPenalty float64 `json:"retry_penalty"`
+	MRAge        float64 `json:"mr_age"`
+}
+
+// Total returns the score the parts add up to.
+func (p ScoreParts) Total() float64 {
+	return p.Base + p.ConvoyAge + p.Priority - p.RetryPenalty + p.MRAge
+}
+
 // ScoreMR calculates the priority score for a merge request.
 // Higher scores mean higher priority (process first).
 //
@@ -85,19 +100,24 @@ type ScoreInput struct {
 //	      - min(RetryPenalty * retryCount, MaxRetryPenalty)  // Prevent thrashing
 //	      + MRAgeWeight * hoursOld(MR)               // FIFO tiebreaker
 func ScoreMR(input ScoreInput, config ScoreConfig) float64 {
+	return ScoreBreakdown(input, config).Total()
+}
+
+// ScoreBreakdown calculates each factor of an MR's score (see ScoreMR).
+func ScoreBreakdown(input ScoreInput, config ScoreConfig) ScoreParts {
 	now := input.Now
 	if now.IsZero() {
 		now = time.Now()
 	}
 
-	score := config.BaseScore
+	parts := ScoreParts{Base: config.BaseScore}
 
 	// Convoy age factor: prevent starvation of old convoys
 	if input.ConvoyCreatedAt != nil {
 		convoyAge := now.Sub(*input.ConvoyCreatedAt)
 		convoyHours := convoyAge.Hours()
 		if convoyHours > 0 {
-			score += config.ConvoyAgeWeight * convoyHours
+			parts.ConvoyAge = config.ConvoyAgeWeight * convoyHours
 		}
 	}
 
@@ -109,23 +129,23 @@ func ScoreMR(input ScoreInput, config ScoreConfig) float64 {
 	if priorityBonus > 4 {
 		priorityBonus = 4 // Clamp for invalid priorities < 0
 	}
-	score += config.PriorityWeight * float64(priorityBonus)
+	parts.Priority = config.PriorityWeight * float64(priorityBonus)
 
 	// Retry penalty: prevent thrashing on repeatedly failing MRs
 	retryPenalty := config.RetryPenalty * float64(input.RetryCount)
 	if retryPenalty > config.MaxRetryPenalty {
 		retryPenalty = config.MaxRetryPenalty
 	}
-	score -= retryPenalty
+	parts.RetryPenalty = retryPenalty
 
 	// MR age factor: FIFO ordering as tiebreaker
 	mrAge := now.Sub(input.MRCreatedAt)
 	mrHours := mrAge.Hours()
 	if mrHours > 0 {
-		score += config.MRAgeWeight * mrHours
+		parts.MRAge = config.MRAgeWeight * mrHours
 	}
 
-	return score
+	return parts
 }
 
 // ScoreMRWithDefaults is a convenience wrapper using default config.
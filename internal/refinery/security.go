@@ -0,0 +1,326 @@
+// Package refinery provides the merge queue processing agent.
+// This file gates merges on the findings of static analysis and security
+// scanners.
+
+package refinery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/secscan"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// SecurityScanSection is the MR bead description section holding the
+// security scan.
+const SecurityScanSection = "security-scan"
+
+// SecurityFindingLabel marks beads filed for deferred scanner findings.
+const SecurityFindingLabel = "security-finding"
+
+// securityScanMaxItems caps the findings listed on the bead.
+const securityScanMaxItems = 30
+
+// defaultFailOn is the blocking severity when the config sets none.
+const defaultFailOn = secscan.SeverityHigh
+
+// ValidateSecurityScan checks a merge_queue.security_scan config.
+func ValidateSecurityScan(cfg *config.SecurityScanConfig) error {
+	if len(cfg.Scanners) == 0 {
+		return fmt.Errorf("at least one scanner is required")
+	}
+	if err := validSeverity("fail_on", cfg.FailOn); err != nil {
+		return err
+	}
+	if err := validSeverity("file_on", cfg.FileOn); err != nil {
+		return err
+	}
+	seen := make(map[string]bool)
+	for i, s := range cfg.Scanners {
+		if s.Name == "" {
+			return fmt.Errorf("scanners[%d]: name is required", i)
+		}
+		if seen[s.Name] {
+			return fmt.Errorf("scanners[%d]: duplicate scanner %q", i, s.Name)
+		}
+		seen[s.Name] = true
+		command, format := resolveScanner(s)
+		if command == "" {
+			return fmt.Errorf("scanner %s: command is required", s.Name)
+		}
+		switch format {
+		case secscan.FormatGosec, secscan.FormatSemgrep, secscan.FormatSARIF:
+		default:
+			return fmt.Errorf("scanner %s: unknown format %q", s.Name, format)
+		}
+		if err := validSeverity("scanner "+s.Name+": fail_on", s.FailOn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validSeverity(what, s string) error {
+	if s != "" && !secscan.ValidSeverity(s) {
+		return fmt.Errorf("%s %q: want low, medium, high, or critical", what, s)
+	}
+	return nil
+}
+
+// resolveScanner fills in the command and format of a known scanner.
+func resolveScanner(s config.SecurityScanner) (command, format string) {
+	command, format = s.Command, s.Format
+	preset, known := secscan.Presets[s.Name]
+	if command == "" {
+		command = preset
+	}
+	if format == "" {
+		format = secscan.FormatSARIF
+		if known {
+			format = s.Name
+		}
+	}
+	return command, format
+}
+
+// securityFailOn returns the blocking severity for a scanner.
+func (e *Engineer) securityFailOn(scanner string) string {
+	cfg := e.config.SecurityScan
+	for _, s := range cfg.Scanners {
+		if s.Name == scanner && s.FailOn != "" {
+			return s.FailOn
+		}
+	}
+	if cfg.FailOn != "" {
+		return cfg.FailOn
+	}
+	return defaultFailOn
+}
+
+// RunScanners runs each configured scanner in the work dir and parses its
+// findings. Scanners commonly exit nonzero when they find something, so
+// only output that doesn't parse is an error.
+func (e *Engineer) RunScanners(ctx context.Context) ([]secscan.ScannerRun, []secscan.Finding, error) {
+	if e.config.SecurityScan == nil {
+		return nil, nil, fmt.Errorf("security scanning is not configured (set merge_queue.security_scan)")
+	}
+	var runs []secscan.ScannerRun
+	var all []secscan.Finding
+	for _, s := range e.config.SecurityScan.Scanners {
+		command, format := resolveScanner(s)
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Executing %s: %s\n", s.Name, command)
+		cmd, err := e.shellCommand(ctx, command)
+		if err != nil {
+			return nil, nil, err
+		}
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		runErr := cmd.Run()
+		findings, err := secscan.Parse(format, s.Name, stdout.Bytes(), e.workDir)
+		if err != nil {
+			if runErr != nil {
+				return nil, nil, fmt.Errorf("%s failed: %w: %s", s.Name, runErr, strings.TrimSpace(stderr.String()))
+			}
+			return nil, nil, fmt.Errorf("%s: %w", s.Name, err)
+		}
+		runs = append(runs, secscan.ScannerRun{Name: s.Name, Findings: len(findings)})
+		all = append(all, findings...)
+	}
+	return runs, all, nil
+}
+
+// ScanSecurity scans the current checkout and judges the findings it
+// introduces, relative to target's recorded findings, against the rig's
+// thresholds. Without recorded findings every finding is judged.
+func (e *Engineer) ScanSecurity(ctx context.Context, target string) (*secscan.Report, error) {
+	runs, head, err := e.RunScanners(ctx)
+	if err != nil {
+		return nil, err
+	}
+	base, err := e.LoadSecurityBaseline(target)
+	if err != nil {
+		return nil, err
+	}
+	return secscan.Judge(runs, base != nil, secscan.Introduced(base, head), e.securityFailOn), nil
+}
+
+// RunSecurityGate checks out an MR's branch, scans it, records the result
+// on the MR bead, and rejects the MR if a new finding is severe enough.
+// When the MR passes, the findings it lands unfixed are filed as beads.
+func (e *Engineer) RunSecurityGate(ctx context.Context, mrID, branch, target string) ProcessResult {
+	previous, _ := e.git.CurrentBranch()
+	if err := e.git.Checkout(e.resolveRef(branch)); err != nil {
+		return ProcessResult{Error: fmt.Sprintf("checking out %s for security scan: %v", branch, err)}
+	}
+	report, err := e.ScanSecurity(ctx, target)
+	if previous != "" {
+		if err := e.git.Checkout(previous); err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: restoring checkout of %s: %v\n", previous, err)
+		}
+	}
+	if err != nil {
+		return ProcessResult{Error: err.Error()}
+	}
+	if mrID != "" {
+		if err := e.RecordSecurityScan(mrID, report); err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: %v\n", err)
+		}
+	}
+	if report.Blocked {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Rejected: security findings at or above the blocking severity\n")
+		return ProcessResult{SecurityViolation: true, Error: report.Markdown(securityScanMaxItems)}
+	}
+	filed, err := e.FileDeferredFindings(mrID, report)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: %v\n", err)
+	}
+	for _, id := range filed {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Filed security-finding bead: %s\n", id)
+	}
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Security scan %s\n", report.Status())
+	return ProcessResult{Success: true}
+}
+
+// RecordSecurityScan stores a security scan on the MR bead: the status as
+// the security_scan field and the findings as the security-scan section.
+func (e *Engineer) RecordSecurityScan(mrID string, report *secscan.Report) error {
+	mrBead, err := e.beads.Show(mrID)
+	if err != nil {
+		return fmt.Errorf("fetching MR bead %s: %w", mrID, err)
+	}
+	mrFields := beads.ParseMRFields(mrBead)
+	if mrFields == nil {
+		mrFields = &beads.MRFields{}
+	}
+	mrFields.SecurityScan = report.Status()
+
+	newDesc := beads.SetMRFields(mrBead, mrFields)
+	newDesc = beads.SetDescriptionSection(newDesc, SecurityScanSection, report.Markdown(securityScanMaxItems))
+	if err := e.beads.Update(mrID, beads.UpdateOptions{Description: &newDesc}); err != nil {
+		return fmt.Errorf("updating MR %s with security scan: %w", mrID, err)
+	}
+	return nil
+}
+
+// SecurityFindingTitle returns the bead title used for a deferred finding.
+// Titles are stable so an open bead suppresses duplicate filings.
+func SecurityFindingTitle(f secscan.Finding) string {
+	return fmt.Sprintf("Security: %s/%s in %s", f.Scanner, f.Rule, f.File)
+}
+
+// securityFindingPriority maps a finding's severity to a bead priority.
+func securityFindingPriority(severity string) int {
+	switch severity {
+	case secscan.SeverityCritical, secscan.SeverityHigh:
+		return 1
+	case secscan.SeverityMedium:
+		return 2
+	}
+	return 3
+}
+
+// FileDeferredFindings files one bug bead per non-blocking finding at or
+// above the file_on severity that doesn't already have an open bead.
+// Returns the IDs of newly filed beads.
+func (e *Engineer) FileDeferredFindings(mrID string, report *secscan.Report) ([]string, error) {
+	deferred := report.Deferred(e.config.SecurityScan.FileOn)
+	if len(deferred) == 0 {
+		return nil, nil
+	}
+	existing, err := e.beads.List(beads.ListOptions{
+		Status:   "open",
+		Label:    SecurityFindingLabel,
+		Priority: -1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing security-finding beads: %w", err)
+	}
+	open := make(map[string]bool, len(existing))
+	for _, issue := range existing {
+		open[issue.Title] = true
+	}
+
+	var filed []string
+	for _, f := range deferred {
+		title := SecurityFindingTitle(f)
+		if open[title] {
+			continue
+		}
+
+		var desc strings.Builder
+		fmt.Fprintf(&desc, "scanner: %s\n", f.Scanner)
+		fmt.Fprintf(&desc, "rule: %s\n", f.Rule)
+		fmt.Fprintf(&desc, "severity: %s\n", f.Severity)
+		fmt.Fprintf(&desc, "location: %s\n", f.Location())
+		if mrID != "" {
+			fmt.Fprintf(&desc, "landed_in: %s\n", mrID)
+		}
+		if f.Message != "" {
+			fmt.Fprintf(&desc, "\n%s\n", f.Message)
+		}
+		desc.WriteString("\nThis finding was below the merge queue's blocking severity and landed unfixed.")
+
+		issue, err := e.beads.Create(beads.CreateOptions{
+			Title:       title,
+			Type:        "bug",
+			Priority:    securityFindingPriority(f.Severity),
+			Description: desc.String(),
+			Labels:      []string{SecurityFindingLabel},
+			Actor:       e.rig.Name + "/refinery",
+		})
+		if err != nil {
+			return filed, fmt.Errorf("filing security-finding bead for %s: %w", title, err)
+		}
+		open[title] = true
+		filed = append(filed, issue.ID)
+	}
+	return filed, nil
+}
+
+// securityBaselinePath returns where a target branch's findings are
+// recorded.
+func securityBaselinePath(rigPath, target string) string {
+	return filepath.Join(rigPath, ".runtime", "secscan", strings.ReplaceAll(target, "/", "_")+".json")
+}
+
+// SaveSecurityBaseline records the findings on a target branch, so MRs
+// against it are judged only on what they introduce.
+func (e *Engineer) SaveSecurityBaseline(target string, findings []secscan.Finding) error {
+	path := securityBaselinePath(e.rig.Path, target)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if findings == nil {
+		findings = []secscan.Finding{}
+	}
+	return util.AtomicWriteJSON(path, findings)
+}
+
+// LoadSecurityBaseline returns the recorded findings of a target branch,
+// or nil if none were recorded.
+func (e *Engineer) LoadSecurityBaseline(target string) ([]secscan.Finding, error) {
+	data, err := os.ReadFile(securityBaselinePath(e.rig.Path, target))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var findings []secscan.Finding
+	if err := json.Unmarshal(data, &findings); err != nil {
+		return nil, fmt.Errorf("parsing security baseline for %s: %w", target, err)
+	}
+	if findings == nil {
+		findings = []secscan.Finding{}
+	}
+	return findings, nil
+}
@@ -0,0 +1,82 @@
+package refinery
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/secscan"
+)
+
+func TestValidateSecurityScan(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.SecurityScanConfig
+		wantErr bool
+	}{
+		{"preset", config.SecurityScanConfig{Scanners: []config.SecurityScanner{{Name: "gosec"}}}, false},
+		{"custom sarif", config.SecurityScanConfig{
+			Scanners: []config.SecurityScanner{{Name: "codeql", Command: "./scan.sh", FailOn: "critical"}},
+			FailOn:   "medium", FileOn: "low",
+		}, false},
+		{"no scanners", config.SecurityScanConfig{}, true},
+		{"custom without command", config.SecurityScanConfig{Scanners: []config.SecurityScanner{{Name: "codeql"}}}, true},
+		{"bad format", config.SecurityScanConfig{Scanners: []config.SecurityScanner{{Name: "x", Command: "x", Format: "xml"}}}, true},
+		{"bad severity", config.SecurityScanConfig{Scanners: []config.SecurityScanner{{Name: "gosec"}}, FailOn: "severe"}, true},
+		{"duplicate", config.SecurityScanConfig{Scanners: []config.SecurityScanner{{Name: "gosec"}, {Name: "gosec"}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateSecurityScan(&tt.cfg); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateSecurityScan() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestScanSecurity(t *testing.T) {
+	gosec := `{"Issues":[` +
+		`{"severity":"HIGH","rule_id":"G101","details":"hardcoded credentials","file":"auth.go","line":"3"},` +
+		`{"severity":"LOW","rule_id":"G104","details":"unhandled error","file":"io.go","line":"9"}]}`
+	e := &Engineer{
+		rig: &rig.Rig{Name: "test-rig", Path: t.TempDir()},
+		config: &MergeQueueConfig{SecurityScan: &config.SecurityScanConfig{
+			Scanners: []config.SecurityScanner{{Name: "gosec", Command: "printf '%s' '" + gosec + "'"}},
+		}},
+		workDir: t.TempDir(),
+		output:  io.Discard,
+	}
+
+	report, err := e.ScanSecurity(context.Background(), "main")
+	if err != nil {
+		t.Fatalf("ScanSecurity: %v", err)
+	}
+	if !report.Blocked || report.Baseline || len(report.Findings) != 2 {
+		t.Errorf("without a baseline: %+v", report)
+	}
+
+	// The credentials finding is already on main; only the low one is new,
+	// and it doesn't block at the default threshold.
+	if err := e.SaveSecurityBaseline("main", []secscan.Finding{{Scanner: "gosec", Rule: "G101", File: "auth.go", Line: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	report, err = e.ScanSecurity(context.Background(), "main")
+	if err != nil {
+		t.Fatalf("ScanSecurity: %v", err)
+	}
+	if report.Blocked || report.Status() != "deferred" || len(report.Findings) != 1 || report.Findings[0].Rule != "G104" {
+		t.Errorf("with a baseline: %+v", report)
+	}
+
+	e.config.SecurityScan.Scanners[0].FailOn = "low"
+	if report, err := e.ScanSecurity(context.Background(), "main"); err != nil || !report.Blocked {
+		t.Errorf("per-scanner fail_on=low should block: %+v, %v", report, err)
+	}
+
+	e.config.SecurityScan.Scanners[0].Command = "echo not json; exit 1"
+	if _, err := e.ScanSecurity(context.Background(), "main"); err == nil {
+		t.Error("expected an error from a scanner that failed without output")
+	}
+}
@@ -0,0 +1,228 @@
+package refinery
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+// The refinery's own signing key lives in <rig>/refinery/.signing, next to
+// the allowed_signers file that vouches for it.
+const (
+	signingDir         = ".signing"
+	signingKeyFile     = "refinery_ed25519"
+	allowedSignersFile = "allowed_signers"
+)
+
+// SigningKeyPath returns the path of the refinery's own ssh signing key.
+func SigningKeyPath(rigPath string) string {
+	return filepath.Join(rigPath, "refinery", signingDir, signingKeyFile)
+}
+
+// AllowedSignersPath returns the allowed_signers file for the refinery's
+// own key.
+func AllowedSignersPath(rigPath string) string {
+	return filepath.Join(rigPath, "refinery", signingDir, allowedSignersFile)
+}
+
+// ValidateSigning checks a merge_queue.signing config.
+func ValidateSigning(cfg *config.SigningConfig) error {
+	switch cfg.Format {
+	case "", config.SigningFormatSSH:
+	case config.SigningFormatOpenPGP:
+		if cfg.Key == "" {
+			return fmt.Errorf("openpgp signing needs a key")
+		}
+	default:
+		return fmt.Errorf("unknown format %q (want %s or %s)", cfg.Format, config.SigningFormatSSH, config.SigningFormatOpenPGP)
+	}
+	return nil
+}
+
+// ResolveSigning fills in a rig's signing config with its defaults: ssh,
+// the refinery's own key and allowed_signers file, and a per-rig refinery
+// identity. Relative paths are taken from the rig directory.
+func ResolveSigning(rigPath, rigName string, cfg *config.SigningConfig) *git.Signing {
+	s := &git.Signing{
+		Format:         cfg.Format,
+		Key:            cfg.Key,
+		Name:           cfg.Name,
+		Email:          cfg.Email,
+		AllowedSigners: cfg.AllowedSigners,
+	}
+	if s.Format == "" {
+		s.Format = config.SigningFormatSSH
+	}
+	if s.Name == "" {
+		s.Name = rigName + " refinery"
+	}
+	if s.Email == "" {
+		s.Email = "refinery@" + rigName + ".gastown.local"
+	}
+	if s.Format == config.SigningFormatSSH {
+		if s.Key == "" {
+			s.Key = SigningKeyPath(rigPath)
+		}
+		if s.AllowedSigners == "" {
+			s.AllowedSigners = AllowedSignersPath(rigPath)
+		}
+		s.Key = rigRelative(rigPath, s.Key)
+		s.AllowedSigners = rigRelative(rigPath, s.AllowedSigners)
+	}
+	return s
+}
+
+func rigRelative(rigPath, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(rigPath, path)
+}
+
+// CreateSigningKey generates the refinery's own ssh signing key for the
+// identity in s and writes an allowed_signers file for it. An existing key
+// is kept; the allowed_signers file is rewritten either way.
+func CreateSigningKey(rigPath string, s *git.Signing) (string, error) {
+	key := SigningKeyPath(rigPath)
+	if err := os.MkdirAll(filepath.Dir(key), 0700); err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(key); os.IsNotExist(err) {
+		cmd := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", s.Email, "-f", key) //nolint:gosec // G204: fixed binary, gt-owned path
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return "", fmt.Errorf("ssh-keygen: %s", strings.TrimSpace(string(out)))
+		}
+	}
+	pub, err := os.ReadFile(key + ".pub")
+	if err != nil {
+		return "", err
+	}
+	line := fmt.Sprintf("%s namespaces=\"git\" %s\n", s.Email, strings.TrimSpace(string(pub)))
+	if err := os.WriteFile(AllowedSignersPath(rigPath), []byte(line), 0644); err != nil { //nolint:gosec // G306: public key
+		return "", err
+	}
+	return key, nil
+}
+
+// Signing returns the resolved signing config, or nil if the rig doesn't
+// sign landings.
+func (e *Engineer) Signing() *git.Signing {
+	if e.config.Signing == nil {
+		return nil
+	}
+	return ResolveSigning(e.rig.Path, e.rig.Name, e.config.Signing)
+}
+
+// SignLanding re-signs the commits on the refinery's current branch since
+// target, as the refinery, and records the signer on the MR bead. Run it
+// after tests pass and before the fast-forward to target.
+func (e *Engineer) SignLanding(mrID, target string) (*git.CommitSignature, error) {
+	s := e.Signing()
+	if s == nil {
+		return nil, fmt.Errorf("signing is not configured (set merge_queue.signing in %s/config.json)", e.rig.Name)
+	}
+	if err := e.git.Resign(target, s); err != nil {
+		return nil, fmt.Errorf("signing commits since %s: %w", target, err)
+	}
+	sigs, err := e.git.CommitSignatures(target+"..HEAD", false, s)
+	if err != nil {
+		return nil, err
+	}
+	if len(sigs) == 0 {
+		return nil, fmt.Errorf("nothing to sign: HEAD has no commits beyond %s", target)
+	}
+	if bad := CheckLandings(sigs, s); len(bad) > 0 {
+		return nil, fmt.Errorf("signed commits don't verify: %s", bad[0])
+	}
+	head := sigs[0]
+
+	if mrID != "" {
+		mrBead, err := e.beads.Show(mrID)
+		if err != nil {
+			return nil, fmt.Errorf("fetching MR bead %s: %w", mrID, err)
+		}
+		mrFields := beads.ParseMRFields(mrBead)
+		if mrFields == nil {
+			mrFields = &beads.MRFields{}
+		}
+		mrFields.Signer = fmt.Sprintf("%s <%s>", s.Name, s.Email)
+		mrFields.SigningKey = head.Key
+		newDesc := beads.SetMRFields(mrBead, mrFields)
+		if err := e.beads.Update(mrID, beads.UpdateOptions{Description: &newDesc}); err != nil {
+			return nil, fmt.Errorf("recording signer on %s: %w", mrID, err)
+		}
+	}
+	return &head, nil
+}
+
+// LandingViolation is a landed commit that wasn't made and signed by the
+// refinery.
+type LandingViolation struct {
+	Commit git.CommitSignature `json:"commit"`
+	Reason string              `json:"reason"`
+}
+
+func (v LandingViolation) String() string {
+	sha := v.Commit.SHA
+	if len(sha) > 8 {
+		sha = sha[:8]
+	}
+	return fmt.Sprintf("%s %s: %s", sha, v.Commit.Subject, v.Reason)
+}
+
+// CheckLandings returns the commits that weren't committed as the
+// refinery's identity with a good signature from its key.
+func CheckLandings(commits []git.CommitSignature, s *git.Signing) []LandingViolation {
+	var bad []LandingViolation
+	for _, c := range commits {
+		if reason := landingProblem(c, s); reason != "" {
+			bad = append(bad, LandingViolation{Commit: c, Reason: reason})
+		}
+	}
+	return bad
+}
+
+func landingProblem(c git.CommitSignature, s *git.Signing) string {
+	switch c.Status {
+	case git.SignatureGood:
+	case git.SignatureNone:
+		return "unsigned"
+	case git.SignatureBad:
+		return "bad signature"
+	case git.SignatureUnknown:
+		return "signed by a key of unknown validity"
+	default:
+		return fmt.Sprintf("signature can't be verified (status %s)", c.Status)
+	}
+	if !strings.EqualFold(c.CommitterEmail, s.Email) {
+		return fmt.Sprintf("committed by %s <%s>, not the refinery", c.CommitterName, c.CommitterEmail)
+	}
+	// ssh reports the allowed_signers principal, openpgp the key's user ID.
+	if !strings.Contains(strings.ToLower(c.Signer), strings.ToLower(s.Email)) {
+		return fmt.Sprintf("signed by %q, not the refinery", c.Signer)
+	}
+	return ""
+}
+
+// VerifyLandings checks that every commit in revRange (e.g.,
+// "v1.2..origin/main") was committed and signed by the refinery. With
+// firstParent, only the first-parent chain is checked, for rigs that land
+// with merge commits.
+func (e *Engineer) VerifyLandings(revRange string, firstParent bool) ([]git.CommitSignature, []LandingViolation, error) {
+	s := e.Signing()
+	if s == nil {
+		return nil, nil, fmt.Errorf("signing is not configured (set merge_queue.signing in %s/config.json)", e.rig.Name)
+	}
+	_ = e.git.Fetch("origin")
+	commits, err := e.git.CommitSignatures(revRange, firstParent, s)
+	if err != nil {
+		return nil, nil, err
+	}
+	return commits, CheckLandings(commits, s), nil
+}
@@ -0,0 +1,107 @@
+package refinery
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+func TestResolveSigning(t *testing.T) {
+	rigPath := "/town/gastown"
+	tests := []struct {
+		name string
+		cfg  config.SigningConfig
+		want git.Signing
+	}{
+		{
+			name: "defaults",
+			want: git.Signing{
+				Format: "ssh", Key: SigningKeyPath(rigPath), AllowedSigners: AllowedSignersPath(rigPath),
+				Name: "gastown refinery", Email: "refinery@gastown.gastown.local",
+			},
+		},
+		{
+			name: "relative ssh paths",
+			cfg:  config.SigningConfig{Key: "keys/ref", AllowedSigners: "/etc/gt/allowed", Email: "ref@example.com"},
+			want: git.Signing{
+				Format: "ssh", Key: filepath.Join(rigPath, "keys/ref"), AllowedSigners: "/etc/gt/allowed",
+				Name: "gastown refinery", Email: "ref@example.com",
+			},
+		},
+		{
+			name: "openpgp",
+			cfg:  config.SigningConfig{Format: "openpgp", Key: "ABCD1234", Name: "Refinery"},
+			want: git.Signing{Format: "openpgp", Key: "ABCD1234", Name: "Refinery", Email: "refinery@gastown.gastown.local"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			if got := ResolveSigning(rigPath, "gastown", &cfg); *got != tt.want {
+				t.Errorf("ResolveSigning() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateSigning(t *testing.T) {
+	tests := []struct {
+		cfg     config.SigningConfig
+		wantErr bool
+	}{
+		{config.SigningConfig{}, false},
+		{config.SigningConfig{Format: "ssh", Key: "k"}, false},
+		{config.SigningConfig{Format: "openpgp", Key: "ABCD"}, false},
+		{config.SigningConfig{Format: "openpgp"}, true},
+		{config.SigningConfig{Format: "x509"}, true},
+	}
+	for _, tt := range tests {
+		if err := ValidateSigning(&tt.cfg); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateSigning(%+v) = %v, wantErr %v", tt.cfg, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCheckLandings(t *testing.T) {
+	s := &git.Signing{Email: "refinery@gastown.gastown.local"}
+	good := git.CommitSignature{
+		SHA: "1111111111", Subject: "gt-1: fix", CommitterEmail: "refinery@gastown.gastown.local",
+		Status: git.SignatureGood, Signer: "refinery@gastown.gastown.local",
+	}
+	with := func(f func(c *git.CommitSignature)) git.CommitSignature {
+		c := good
+		f(&c)
+		return c
+	}
+
+	tests := []struct {
+		name   string
+		commit git.CommitSignature
+		reason string // substring; "" means the commit passes
+	}{
+		{"refinery landing", good, ""},
+		{"openpgp user ID", with(func(c *git.CommitSignature) { c.Signer = "Refinery <refinery@gastown.gastown.local>" }), ""},
+		{"unsigned", with(func(c *git.CommitSignature) { c.Status = git.SignatureNone; c.Signer = "" }), "unsigned"},
+		{"bad signature", with(func(c *git.CommitSignature) { c.Status = git.SignatureBad }), "bad signature"},
+		{"unknown key", with(func(c *git.CommitSignature) { c.Status = git.SignatureUnknown }), "unknown validity"},
+		{"pushed directly", with(func(c *git.CommitSignature) { c.CommitterEmail = "dev@example.com" }), "not the refinery"},
+		{"someone else's key", with(func(c *git.CommitSignature) { c.Signer = "dev@example.com" }), "signed by"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bad := CheckLandings([]git.CommitSignature{tt.commit}, s)
+			if tt.reason == "" {
+				if len(bad) != 0 {
+					t.Errorf("got violations %v, want none", bad)
+				}
+				return
+			}
+			if len(bad) != 1 || !strings.Contains(bad[0].Reason, tt.reason) {
+				t.Errorf("got %v, want a violation containing %q", bad, tt.reason)
+			}
+		})
+	}
+}
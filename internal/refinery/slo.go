@@ -0,0 +1,210 @@
+// Package refinery provides the merge queue processing agent.
+// This file tracks how long MRs sit in each queue state and checks that
+// time against the rig's queue SLOs.
+
+package refinery
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+// Queue states tracked for SLOs. An open MR that is blocked on another
+// issue or back with its worker for changes is in none of them.
+const (
+	QueueAwaitingTests  = "awaiting_tests"  // claimed by the refinery, tests running
+	QueueAwaitingReview = "awaiting_review" // held for a reviewer
+	QueueAwaitingMerge  = "awaiting_merge"  // ready, waiting its turn to land
+)
+
+// QueueState returns the queue state an MR is in now, or "" if none.
+func QueueState(mr *beads.Issue, fields *beads.MRFields) string {
+	switch {
+	case mr.Status == "in_progress":
+		return QueueAwaitingTests
+	case mr.Status != "open":
+		return ""
+	case fields != nil && fields.Review == ReviewRequested:
+		return QueueAwaitingReview
+	case fields != nil && fields.Review == ReviewChangesRequested:
+		return ""
+	case len(mr.BlockedBy) > 0 || mr.BlockedByCount > 0:
+		return ""
+	}
+	return QueueAwaitingMerge
+}
+
+// QueueDwell is how long an MR has been in its current queue state,
+// against that state's SLO.
+type QueueDwell struct {
+	MR       string        `json:"mr"`
+	Branch   string        `json:"branch,omitempty"`
+	State    string        `json:"state"`
+	Since    time.Time     `json:"since"`
+	Dwell    time.Duration `json:"dwell"`
+	SLO      time.Duration `json:"slo,omitempty"` // zero: no SLO for the state
+	Breached bool          `json:"breached"`
+}
+
+// Load is the dwell as a fraction of the SLO; zero without an SLO.
+func (d QueueDwell) Load() float64 {
+	if d.SLO <= 0 {
+		return 0
+	}
+	return float64(d.Dwell) / float64(d.SLO)
+}
+
+// SLOs returns the rig's queue SLOs by state. Invalid entries are
+// skipped; rig settings validation reports them.
+func (e *Engineer) SLOs() map[string]time.Duration {
+	slos := make(map[string]time.Duration, len(e.config.SLO))
+	for state, limit := range e.config.SLO {
+		if d, err := time.ParseDuration(limit); err == nil && d > 0 {
+			slos[state] = d
+		}
+	}
+	return slos
+}
+
+// ObserveQueueState brings an MR's queue tracking fields up to date: when
+// its state has changed since the last observation, the time spent in the
+// old state is added to queue_times and the new state starts now. An MR
+// that was never tracked is taken to have been in its state since it was
+// created. It reports the MR's dwell and whether fields changed.
+func ObserveQueueState(mr *beads.Issue, fields *beads.MRFields, slos map[string]time.Duration, now time.Time) (QueueDwell, bool) {
+	state := QueueState(mr, fields)
+	changed := false
+	if state != fields.QueueState {
+		since := now
+		if fields.QueueState == "" && fields.QueueTimes == "" {
+			if created, err := time.Parse(time.RFC3339, mr.CreatedAt); err == nil {
+				since = created
+			}
+		} else if prev, err := time.Parse(time.RFC3339, fields.QueueSince); err == nil && fields.QueueState != "" {
+			times := ParseQueueTimes(fields.QueueTimes)
+			times[fields.QueueState] += now.Sub(prev).Round(time.Second)
+			fields.QueueTimes = FormatQueueTimes(times)
+		}
+		fields.QueueState = state
+		fields.QueueSince = ""
+		if state != "" {
+			fields.QueueSince = since.UTC().Format(time.RFC3339)
+		}
+		fields.SLOAlerted = ""
+		changed = true
+	}
+
+	d := QueueDwell{MR: mr.ID, Branch: fields.Branch, State: state}
+	if state == "" {
+		return d, changed
+	}
+	d.Since, _ = time.Parse(time.RFC3339, fields.QueueSince)
+	d.Dwell = now.Sub(d.Since)
+	d.SLO = slos[state]
+	d.Breached = d.SLO > 0 && d.Dwell > d.SLO
+	return d, changed
+}
+
+// TrackQueueTimes observes every open MR's queue state, saving changes to
+// the MR beads, and returns the MRs that newly breached their state's SLO.
+// Each breach is reported once per stay in a state.
+func (e *Engineer) TrackQueueTimes(now time.Time) ([]QueueDwell, error) {
+	slos := e.SLOs()
+	var breaches []QueueDwell
+	for _, status := range []string{"open", "in_progress"} {
+		mrs, err := e.beads.List(beads.ListOptions{Status: status, Type: "merge-request", Priority: -1})
+		if err != nil {
+			return breaches, fmt.Errorf("listing %s MRs: %w", status, err)
+		}
+		for _, mr := range mrs {
+			fields := beads.ParseMRFields(mr)
+			if fields == nil {
+				fields = &beads.MRFields{}
+			}
+			d, changed := ObserveQueueState(mr, fields, slos, now)
+			if d.Breached && fields.SLOAlerted != d.State {
+				fields.SLOAlerted = d.State
+				changed = true
+				breaches = append(breaches, d)
+			}
+			if !changed {
+				continue
+			}
+			description := beads.SetMRFields(mr, fields)
+			if err := e.beads.Update(mr.ID, beads.UpdateOptions{Description: &description}); err != nil {
+				return breaches, fmt.Errorf("updating merge request %s: %w", mr.ID, err)
+			}
+		}
+	}
+	return breaches, nil
+}
+
+// QueueDwells returns the dwell of every open MR in a queue state, worst
+// first: breaches by how far over their SLO, then the rest by time in
+// state. Nothing is saved.
+func (e *Engineer) QueueDwells(now time.Time) ([]QueueDwell, error) {
+	slos := e.SLOs()
+	var dwells []QueueDwell
+	for _, status := range []string{"open", "in_progress"} {
+		mrs, err := e.beads.List(beads.ListOptions{Status: status, Type: "merge-request", Priority: -1})
+		if err != nil {
+			return nil, fmt.Errorf("listing %s MRs: %w", status, err)
+		}
+		for _, mr := range mrs {
+			fields := beads.ParseMRFields(mr)
+			if fields == nil {
+				fields = &beads.MRFields{}
+			}
+			if d, _ := ObserveQueueState(mr, fields, slos, now); d.State != "" {
+				dwells = append(dwells, d)
+			}
+		}
+	}
+	SortQueueDwells(dwells)
+	return dwells, nil
+}
+
+// SortQueueDwells orders dwells worst first: highest SLO load, then
+// longest in state.
+func SortQueueDwells(dwells []QueueDwell) {
+	sort.SliceStable(dwells, func(i, j int) bool {
+		if li, lj := dwells[i].Load(), dwells[j].Load(); li != lj {
+			return li > lj
+		}
+		return dwells[i].Dwell > dwells[j].Dwell
+	})
+}
+
+// ParseQueueTimes parses a queue_times field ("awaiting_review=2h0m0s,...").
+func ParseQueueTimes(s string) map[string]time.Duration {
+	times := make(map[string]time.Duration)
+	for _, part := range strings.Split(s, ",") {
+		state, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		if d, err := time.ParseDuration(value); err == nil {
+			times[state] += d
+		}
+	}
+	return times
+}
+
+// FormatQueueTimes formats queue times for the queue_times field, in
+// state order.
+func FormatQueueTimes(times map[string]time.Duration) string {
+	states := make([]string, 0, len(times))
+	for state := range times {
+		states = append(states, state)
+	}
+	sort.Strings(states)
+	parts := make([]string, 0, len(states))
+	for _, state := range states {
+		parts = append(parts, state+"="+times[state].String())
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,101 @@
+package refinery
+
+import (
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+func TestQueueState(t *testing.T) {
+	tests := []struct {
+		name   string
+		mr     *beads.Issue
+		fields *beads.MRFields
+		want   string
+	}{
+		{"claimed", &beads.Issue{Status: "in_progress"}, &beads.MRFields{}, QueueAwaitingTests},
+		{"ready", &beads.Issue{Status: "open"}, &beads.MRFields{}, QueueAwaitingMerge},
+		{"review requested", &beads.Issue{Status: "open"}, &beads.MRFields{Review: ReviewRequested}, QueueAwaitingReview},
+		{"changes requested", &beads.Issue{Status: "open"}, &beads.MRFields{Review: ReviewChangesRequested}, ""},
+		{"blocked", &beads.Issue{Status: "open", BlockedBy: []string{"gt-1"}}, &beads.MRFields{}, ""},
+		{"closed", &beads.Issue{Status: "closed"}, &beads.MRFields{}, ""},
+	}
+	for _, tt := range tests {
+		if got := QueueState(tt.mr, tt.fields); got != tt.want {
+			t.Errorf("%s: QueueState = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestObserveQueueState(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	slos := map[string]time.Duration{QueueAwaitingReview: 4 * time.Hour}
+
+	// Never tracked: the MR has been awaiting review since it was created.
+	mr := &beads.Issue{ID: "gt-mr1", Status: "open", CreatedAt: "2026-03-02T06:00:00Z"}
+	fields := &beads.MRFields{Branch: "polecat/a", Review: ReviewRequested}
+	d, changed := ObserveQueueState(mr, fields, slos, now)
+	if !changed || fields.QueueState != QueueAwaitingReview || fields.QueueSince != "2026-03-02T06:00:00Z" {
+		t.Fatalf("first observation: changed=%v fields=%+v", changed, fields)
+	}
+	if d.Dwell != 6*time.Hour || !d.Breached || d.Branch != "polecat/a" {
+		t.Errorf("dwell = %+v, want 6h breached", d)
+	}
+
+	// Same state again: nothing to save.
+	if _, changed := ObserveQueueState(mr, fields, slos, now); changed {
+		t.Error("unchanged state reported as changed")
+	}
+
+	// Approved: the review time is banked and the new state starts now.
+	fields.Review = ReviewApproved
+	fields.SLOAlerted = QueueAwaitingReview
+	d, changed = ObserveQueueState(mr, fields, slos, now)
+	if !changed || d.State != QueueAwaitingMerge || d.Dwell != 0 || d.Breached {
+		t.Errorf("after approval: changed=%v dwell=%+v", changed, d)
+	}
+	if got := ParseQueueTimes(fields.QueueTimes)[QueueAwaitingReview]; got != 6*time.Hour {
+		t.Errorf("queue_times review = %v, want 6h", got)
+	}
+	if fields.SLOAlerted != "" {
+		t.Errorf("slo_alerted = %q, want reset on state change", fields.SLOAlerted)
+	}
+
+	// Leaving the queue states clears queue_since.
+	mr.Status = "closed"
+	if d, _ := ObserveQueueState(mr, fields, slos, now.Add(time.Hour)); d.State != "" || fields.QueueSince != "" {
+		t.Errorf("closed: dwell=%+v since=%q", d, fields.QueueSince)
+	}
+	if got := ParseQueueTimes(fields.QueueTimes)[QueueAwaitingMerge]; got != time.Hour {
+		t.Errorf("queue_times merge = %v, want 1h", got)
+	}
+}
+
+func TestQueueTimesRoundTrip(t *testing.T) {
+	times := map[string]time.Duration{QueueAwaitingTests: 90 * time.Second, QueueAwaitingMerge: 2 * time.Hour}
+	s := FormatQueueTimes(times)
+	if s != "awaiting_merge=2h0m0s,awaiting_tests=1m30s" {
+		t.Errorf("FormatQueueTimes = %q", s)
+	}
+	got := ParseQueueTimes(s + ",junk,awaiting_review=bad")
+	if len(got) != 2 || got[QueueAwaitingTests] != 90*time.Second || got[QueueAwaitingMerge] != 2*time.Hour {
+		t.Errorf("ParseQueueTimes = %v", got)
+	}
+}
+
+func TestSortQueueDwells(t *testing.T) {
+	dwells := []QueueDwell{
+		{MR: "no-slo-long", Dwell: 10 * time.Hour},
+		{MR: "half", Dwell: time.Hour, SLO: 2 * time.Hour},
+		{MR: "over", Dwell: 3 * time.Hour, SLO: time.Hour},
+		{MR: "no-slo-short", Dwell: time.Minute},
+	}
+	SortQueueDwells(dwells)
+	want := []string{"over", "half", "no-slo-long", "no-slo-short"}
+	for i, d := range dwells {
+		if d.MR != want[i] {
+			t.Errorf("dwells[%d] = %s, want %s", i, d.MR, want[i])
+		}
+	}
+}
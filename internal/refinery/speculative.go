@@ -0,0 +1,308 @@
+// Package refinery provides the merge queue processing agent.
+// This file validates several MRs at once, each against the state its
+// target will be in when it lands, while landing them one at a time.
+
+package refinery
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// maxValidations caps how often one MR is validated in a batch. An MR
+// whose predictions keep being invalidated (e.g. by pushes from outside
+// the queue) lands the ordinary way instead, testing against the target as
+// it is.
+const maxValidations = 3
+
+// BatchResult is the outcome of one MR in ProcessBatch.
+type BatchResult struct {
+	MR     *MRInfo       `json:"mr"`
+	Result ProcessResult `json:"result"`
+
+	// Predicted is the speculative merge the MR was validated on: its
+	// target with the MRs ahead of it and the MR itself merged.
+	Predicted string `json:"predicted,omitempty"`
+
+	// Validations counts validation runs; more than one means an earlier
+	// landing invalidated a run.
+	Validations int `json:"validations"`
+}
+
+// speculation is one MR's predicted merge state and its validation.
+type speculation struct {
+	mr         *MRInfo
+	target     string
+	parentTree string   // Tree of the state the MR was stacked on
+	predicted  string   // The parent with the MR merged; empty if that failed
+	conflicts  []string // Files the merge conflicts on
+	result     ProcessResult
+	done       chan struct{} // Closed when validation finishes
+}
+
+// stacked reports whether MRs behind this one were stacked on its merge.
+func (s *speculation) stacked() bool {
+	return s.predicted != ""
+}
+
+// ProcessBatch processes MRs in queue order (as ListReadyMRs returns
+// them), grouped by target. Up to max_concurrent MRs per target are
+// validated in parallel, each in a pooled worktree at a speculative merge
+// of the target, the MRs ahead of it, and the MR itself. Landing stays
+// serial: each validated MR goes through the usual gates and lands in
+// order, and the next MR is stacked onto the window as one lands.
+//
+// A prediction is only trusted while the target is in the state the MR
+// was stacked on. When an MR fails or is held, or a landing leaves the
+// target somewhere else (a push from outside the queue, say), the rest of
+// the window is invalidated and validated again against the new base. An
+// MR whose merge conflicts with the MRs ahead of it fails as a conflict
+// once those have landed.
+//
+// Each result is handled (HandleMRInfoSuccess or HandleMRInfoFailure) as
+// its MR finishes, so MRs that depend on it can land later in the batch.
+func (e *Engineer) ProcessBatch(ctx context.Context, mrs []*MRInfo) []BatchResult {
+	var targets []string
+	byTarget := make(map[string][]*MRInfo)
+	for _, mr := range mrs {
+		target := mr.Target
+		if target == "" {
+			target = e.config.TargetBranch
+		}
+		if _, ok := byTarget[target]; !ok {
+			targets = append(targets, target)
+		}
+		byTarget[target] = append(byTarget[target], mr)
+	}
+
+	var results []BatchResult
+	for _, target := range targets {
+		if ctx.Err() != nil {
+			break
+		}
+		results = append(results, e.processTarget(ctx, target, byTarget[target])...)
+	}
+	return results
+}
+
+// processTarget runs the validation window over one target's MRs.
+func (e *Engineer) processTarget(ctx context.Context, target string, pending []*MRInfo) []BatchResult {
+	width := e.config.MaxConcurrent
+	if width < 1 {
+		width = 1
+	}
+	out := &syncWriter{w: e.output}
+	validations := make(map[string]int)
+	var results []BatchResult
+	var window []*speculation
+	var tip string // The commit the next MR is stacked on
+	wctx, cancel := context.WithCancel(ctx)
+
+	// restack drops the window, returning its MRs to the front of the
+	// queue, and stacks again from the target as it is now.
+	restack := func() error {
+		cancel()
+		requeue := make([]*MRInfo, 0, len(window)+len(pending))
+		for _, s := range window {
+			<-s.done
+			requeue = append(requeue, s.mr)
+		}
+		pending = append(requeue, pending...)
+		window = nil
+		wctx, cancel = context.WithCancel(ctx)
+		head, err := e.targetHead(target)
+		if err != nil {
+			return err
+		}
+		tip = head
+		return nil
+	}
+	defer func() {
+		cancel()
+		for _, s := range window {
+			<-s.done
+		}
+	}()
+
+	if err := restack(); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: can't predict merges into %s: %v (landing one at a time)\n", target, err)
+	}
+
+	for len(pending) > 0 || len(window) > 0 {
+		if ctx.Err() != nil {
+			break
+		}
+		// Keep the window full
+		for tip != "" && len(window) < width && len(pending) > 0 && validations[pending[0].ID] < maxValidations {
+			s := e.speculate(tip, target, pending[0])
+			pending = pending[1:]
+			window = append(window, s)
+			if !s.stacked() {
+				close(s.done)
+				continue
+			}
+			validations[s.mr.ID]++
+			tip = s.predicted
+			go e.validator(out).validate(wctx, s)
+		}
+
+		// Nothing predicted: land the next MR the ordinary way
+		if len(window) == 0 {
+			mr := pending[0]
+			pending = pending[1:]
+			results = append(results, e.land(ctx, mr, "", validations[mr.ID]))
+			if err := restack(); err != nil {
+				tip = ""
+			}
+			continue
+		}
+
+		s := window[0]
+		select {
+		case <-s.done:
+		case <-ctx.Done():
+			return results
+		}
+		if tree, err := e.targetTree(target); err != nil || tree != s.parentTree {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] %s moved since %s was validated; validating again\n", target, s.mr.ID)
+			if err := restack(); err != nil {
+				tip = ""
+			}
+			continue
+		}
+		window = window[1:]
+
+		var r BatchResult
+		switch {
+		case len(s.conflicts) > 0:
+			r = BatchResult{MR: s.mr, Validations: validations[s.mr.ID], Result: ProcessResult{
+				Conflict: true,
+				Error:    fmt.Sprintf("merge conflicts with %s: %s", target, strings.Join(s.conflicts, ", ")),
+			}}
+			e.HandleMRInfoFailure(s.mr, r.Result)
+		case !s.stacked():
+			// The prediction failed for another reason; test it the ordinary way
+			r = e.land(ctx, s.mr, "", validations[s.mr.ID])
+		case !s.result.Success:
+			_, _ = fmt.Fprintf(e.output, "[Engineer] %s failed validation on %s\n", s.mr.ID, shortCommit(s.predicted))
+			r = BatchResult{MR: s.mr, Result: s.result, Predicted: s.predicted, Validations: validations[s.mr.ID]}
+			e.HandleMRInfoFailure(s.mr, r.Result)
+		default:
+			r = e.land(ctx, s.mr, s.predicted, validations[s.mr.ID])
+		}
+		results = append(results, r)
+
+		// MRs stacked on one that didn't land were validated against a
+		// state the target will never be in
+		if s.stacked() && !r.Result.Success {
+			if err := restack(); err != nil {
+				tip = ""
+			}
+		}
+	}
+	return results
+}
+
+// land processes an MR and handles the result. With predicted set, its
+// suites already passed on that state and aren't run again.
+func (e *Engineer) land(ctx context.Context, mr *MRInfo, predicted string, validations int) BatchResult {
+	result := e.processMRInfo(ctx, mr, predicted)
+	if result.Success {
+		e.HandleMRInfoSuccess(mr, result)
+	} else {
+		e.HandleMRInfoFailure(mr, result)
+	}
+	return BatchResult{MR: mr, Result: result, Predicted: predicted, Validations: validations}
+}
+
+// speculate predicts the state after mr lands on tip: a merge of its
+// branch into tip, made in a pooled worktree.
+func (e *Engineer) speculate(tip, target string, mr *MRInfo) *speculation {
+	s := &speculation{mr: mr, target: target, done: make(chan struct{})}
+	var err error
+	if s.parentTree, err = e.git.Rev(tip + "^{tree}"); err != nil {
+		return s
+	}
+	scratch, err := e.Worktrees().Borrow(tip)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: predicting merge of %s: %v\n", mr.ID, err)
+		return s
+	}
+	defer scratch.Return()
+
+	if err := scratch.Git.MergeNoFF(e.resolveRef(mr.Branch), "Speculative merge of "+mr.ID); err != nil {
+		s.conflicts, _ = scratch.Git.GetConflictingFiles()
+		_ = scratch.Git.AbortMerge()
+		if len(s.conflicts) == 0 {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: predicting merge of %s: %v\n", mr.ID, err)
+		}
+		return s
+	}
+	s.predicted, _ = scratch.Git.Rev("HEAD")
+	return s
+}
+
+// validator returns a copy of the engineer for one validation, writing to
+// out. Take it before starting the validation's goroutine: the copy only
+// changes its own fields, and the engineer goes on landing MRs (which
+// changes its fields) while the copy runs.
+func (e *Engineer) validator(out io.Writer) *Engineer {
+	v := *e
+	v.output = out
+	return &v
+}
+
+// validate runs the suites covering an MR against its predicted state,
+// in a pooled worktree. Call it on a copy of the engineer (see validator).
+func (e *Engineer) validate(ctx context.Context, s *speculation) {
+	defer close(s.done)
+	s.result = ProcessResult{Success: true}
+	if !e.ConfigFor(s.target).RunTests || len(e.config.TestSuites) == 0 {
+		return
+	}
+	suites := e.SelectSuites(s.mr.Branch, s.target)
+	if len(suites) == 0 {
+		return
+	}
+	scratch, err := e.Worktrees().Borrow(s.predicted)
+	if err != nil {
+		s.result = ProcessResult{Error: fmt.Sprintf("checking out predicted state for %s: %v", s.mr.ID, err)}
+		return
+	}
+	defer scratch.Return()
+
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Validating %s on predicted %s (%s)\n", s.mr.ID, s.target, shortCommit(s.predicted))
+	e.workDir = scratch.Path
+	s.result = e.RunSuites(ctx, suites)
+}
+
+// targetHead fetches and returns the target's head commit.
+func (e *Engineer) targetHead(target string) (string, error) {
+	if err := e.git.Fetch("origin"); err != nil {
+		return "", fmt.Errorf("fetching origin: %w", err)
+	}
+	return e.git.Rev("origin/" + target + "^{commit}")
+}
+
+// targetTree fetches and returns the tree of the target's head.
+func (e *Engineer) targetTree(target string) (string, error) {
+	if err := e.git.Fetch("origin"); err != nil {
+		return "", fmt.Errorf("fetching origin: %w", err)
+	}
+	return e.git.Rev("origin/" + target + "^{tree}")
+}
+
+// syncWriter serializes writes from parallel validations.
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (w *syncWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
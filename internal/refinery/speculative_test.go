@@ -0,0 +1,117 @@
+package refinery
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/testsuite"
+)
+
+func TestSpeculateAndValidate(t *testing.T) {
+	for _, kv := range []string{"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@test.com",
+		"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@test.com"} {
+		k, v, _ := strings.Cut(kv, "=")
+		t.Setenv(k, v)
+	}
+	tmp := t.TempDir()
+	origin := filepath.Join(tmp, "origin.git")
+	clone := filepath.Join(tmp, "refinery", "rig")
+	run := func(dir string, args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(clone, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	branch := func(name, file, content string) {
+		t.Helper()
+		run(clone, "checkout", "-b", name, "main")
+		write(file, content)
+		run(clone, "add", ".")
+		run(clone, "commit", "-m", name)
+		run(clone, "push", "origin", name)
+	}
+
+	run(tmp, "init", "--bare", "-b", "main", origin)
+	run(tmp, "clone", origin, clone)
+	run(clone, "checkout", "-b", "main")
+	write("app.txt", "v1\n")
+	run(clone, "add", ".")
+	run(clone, "commit", "-m", "initial")
+	run(clone, "push", "origin", "main")
+	branch("polecat/a", "a.txt", "a\n")
+	branch("polecat/b", "b.txt", "b\n")
+	branch("polecat/c", "a.txt", "c\n") // Conflicts with a
+	run(clone, "checkout", "main")
+
+	cfg := DefaultMergeQueueConfig()
+	cfg.TestSuites = []testsuite.Suite{{Name: "both", Command: "test -f a.txt && test -f b.txt"}}
+	e := &Engineer{
+		rig:     &rig.Rig{Name: "test-rig", Path: tmp},
+		config:  cfg,
+		workDir: clone,
+		git:     git.NewGit(clone),
+		output:  io.Discard,
+	}
+
+	base, err := e.targetHead("main")
+	if err != nil {
+		t.Fatalf("targetHead: %v", err)
+	}
+	a := e.speculate(base, "main", &MRInfo{ID: "gt-mr-a", Branch: "polecat/a", Target: "main"})
+	b := e.speculate(a.predicted, "main", &MRInfo{ID: "gt-mr-b", Branch: "polecat/b", Target: "main"})
+	c := e.speculate(b.predicted, "main", &MRInfo{ID: "gt-mr-c", Branch: "polecat/c", Target: "main"})
+
+	if !a.stacked() || !b.stacked() {
+		t.Fatalf("a and b should stack cleanly: a=%+v b=%+v", a, b)
+	}
+	if tree, _ := e.targetTree("main"); a.parentTree != tree {
+		t.Errorf("a stacked on %s, want main's tree %s", a.parentTree, tree)
+	}
+	if want := run(clone, "rev-parse", a.predicted+"^{tree}"); b.parentTree != want {
+		t.Errorf("b stacked on %s, want a's predicted tree %s", b.parentTree, want)
+	}
+	if files := run(clone, "ls-tree", "--name-only", b.predicted); !strings.Contains(files, "a.txt") || !strings.Contains(files, "b.txt") {
+		t.Errorf("b's predicted state has %q, want a.txt and b.txt", files)
+	}
+	if c.stacked() || len(c.conflicts) != 1 || c.conflicts[0] != "a.txt" {
+		t.Errorf("c = predicted %q conflicts %v, want a conflict on a.txt", c.predicted, c.conflicts)
+	}
+
+	// a alone fails the suite, a+b passes; validate both in parallel
+	out := &syncWriter{w: io.Discard}
+	go e.validator(out).validate(context.Background(), a)
+	go e.validator(out).validate(context.Background(), b)
+	<-a.done
+	<-b.done
+	if a.result.Success {
+		t.Error("a validated without b.txt; the suite should fail on its predicted state")
+	}
+	if !b.result.Success {
+		t.Errorf("b failed validation on the predicted state with a: %s", b.result.Error)
+	}
+
+	// Once main moves, predictions stacked on the old main are stale
+	write("app.txt", "v2\n")
+	run(clone, "commit", "-am", "outside push")
+	run(clone, "push", "origin", "main")
+	if tree, _ := e.targetTree("main"); tree == a.parentTree {
+		t.Error("target tree unchanged after a push; invalidation would be missed")
+	}
+}
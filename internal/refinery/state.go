@@ -0,0 +1,390 @@
+// Package refinery provides the merge queue processing agent.
+// This file keeps a crash-safe record of the MRs the refinery is working
+// on, so work a crash interrupts can be finished or handed back.
+
+package refinery
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// MR processing phases, as recorded in the state journal.
+const (
+	PhaseClaimed  = "claimed"  // Claimed for processing
+	PhaseMerging  = "merging"  // PR merge requested; it may have landed
+	PhaseMerged   = "merged"   // Landed; closing the MR and issue is pending
+	PhaseDone     = "done"     // Finished; the entry is dropped
+	PhaseReleased = "released" // Handed back to the queue; the entry is dropped
+)
+
+// compactAfter is how many journal records accumulate before they are
+// folded into the snapshot.
+const compactAfter = 200
+
+// ErrProcessing is returned when reconciling while MRs are being processed.
+var ErrProcessing = errors.New("the refinery is processing MRs")
+
+// MRState is what the refinery last recorded doing with an MR.
+type MRState struct {
+	MR          string    `json:"mr"`
+	Phase       string    `json:"phase"`
+	Branch      string    `json:"branch,omitempty"`
+	Target      string    `json:"target,omitempty"`
+	PR          int       `json:"pr,omitempty"`
+	MergeCommit string    `json:"merge_commit,omitempty"`
+	Worker      string    `json:"worker,omitempty"`
+	At          time.Time `json:"at"`
+}
+
+// terminal reports whether the phase ends the refinery's work on an MR.
+func (s MRState) terminal() bool {
+	return s.Phase == PhaseDone || s.Phase == PhaseReleased
+}
+
+// StateStore is the refinery's record of in-flight MRs: a snapshot plus a
+// write-ahead journal under <rig>/.runtime/refinery. Every transition is
+// appended and synced to the journal before the refinery acts on it;
+// records carry an MR's whole state, so replaying the journal over the
+// snapshot is idempotent and a torn final record is ignored. A file lock
+// serializes writers across processes.
+type StateStore struct {
+	dir string
+}
+
+// NewStateStore returns the state store for a rig.
+func NewStateStore(rigPath string) *StateStore {
+	return &StateStore{dir: filepath.Join(rigPath, ".runtime", "refinery")}
+}
+
+func (s *StateStore) snapshotPath() string { return filepath.Join(s.dir, "state.json") }
+func (s *StateStore) journalPath() string  { return filepath.Join(s.dir, "journal.jsonl") }
+
+// lock takes the store's file lock. Caller must Unlock.
+func (s *StateStore) lock() (*flock.Flock, error) {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return nil, fmt.Errorf("creating state dir: %w", err)
+	}
+	fl := flock.New(filepath.Join(s.dir, "state.lock"))
+	if err := fl.Lock(); err != nil {
+		return nil, fmt.Errorf("locking refinery state: %w", err)
+	}
+	return fl, nil
+}
+
+// Record appends an MR's new state to the journal and syncs it to disk.
+func (s *StateStore) Record(st MRState) error {
+	if st.At.IsZero() {
+		st.At = time.Now().UTC()
+	}
+	line, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+	fl, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = fl.Unlock() }()
+
+	f, err := os.OpenFile(s.journalPath(), os.O_APPEND|os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return fmt.Errorf("opening state journal: %w", err)
+	}
+	// Start a fresh line after a torn record so this one parses
+	if fi, err := f.Stat(); err == nil && fi.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := f.ReadAt(last, fi.Size()-1); err == nil && last[0] != '\n' {
+			line = append([]byte{'\n'}, line...)
+		}
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("writing state journal: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("syncing state journal: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if n, _ := countLines(s.journalPath()); n >= compactAfter {
+		return s.compactLocked()
+	}
+	return nil
+}
+
+// Load returns the in-flight MRs, by MR ID.
+func (s *StateStore) Load() (map[string]MRState, error) {
+	fl, err := s.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = fl.Unlock() }()
+	return s.loadLocked()
+}
+
+// List returns the in-flight MRs, oldest first.
+func (s *StateStore) List() ([]MRState, error) {
+	states, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]MRState, 0, len(states))
+	for _, st := range states {
+		list = append(list, st)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].At.Equal(list[j].At) {
+			return list[i].At.Before(list[j].At)
+		}
+		return list[i].MR < list[j].MR
+	})
+	return list, nil
+}
+
+func (s *StateStore) loadLocked() (map[string]MRState, error) {
+	states := make(map[string]MRState)
+	data, err := os.ReadFile(s.snapshotPath())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading state snapshot: %w", err)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &states); err != nil {
+			return nil, fmt.Errorf("parsing state snapshot: %w", err)
+		}
+	}
+
+	f, err := os.Open(s.journalPath())
+	if os.IsNotExist(err) {
+		return states, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("reading state journal: %w", err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var st MRState
+		if err := json.Unmarshal(scanner.Bytes(), &st); err != nil || st.MR == "" {
+			// A record torn by a crash mid-write; it never took effect
+			continue
+		}
+		if st.terminal() {
+			delete(states, st.MR)
+		} else {
+			states[st.MR] = st
+		}
+	}
+	return states, scanner.Err()
+}
+
+// compactLocked folds the journal into the snapshot. A crash between the
+// two steps is harmless: the journal replays over the new snapshot to the
+// same state.
+func (s *StateStore) compactLocked() error {
+	states, err := s.loadLocked()
+	if err != nil {
+		return err
+	}
+	if err := util.AtomicWriteJSON(s.snapshotPath(), states); err != nil {
+		return fmt.Errorf("writing state snapshot: %w", err)
+	}
+	if err := os.Truncate(s.journalPath(), 0); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("truncating state journal: %w", err)
+	}
+	return nil
+}
+
+func countLines(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return bytes.Count(data, []byte{'\n'}), nil
+}
+
+// LockProcessing marks the rig's MRs as being processed until Unlock, so
+// ReconcileState leaves them alone. Returns nil if another process is
+// already processing.
+func (s *StateStore) LockProcessing() (*flock.Flock, error) {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return nil, fmt.Errorf("creating state dir: %w", err)
+	}
+	fl := flock.New(filepath.Join(s.dir, "processing.lock"))
+	locked, err := fl.TryLock()
+	if err != nil {
+		return nil, fmt.Errorf("locking refinery processing: %w", err)
+	}
+	if !locked {
+		return nil, nil
+	}
+	return fl, nil
+}
+
+// State returns the rig's refinery state store.
+func (e *Engineer) State() *StateStore {
+	return NewStateStore(e.rig.Path)
+}
+
+// recordState journals an MR transition, warning if it can't.
+func (e *Engineer) recordState(st MRState) {
+	if st.MR == "" || e.rig == nil || e.rig.Path == "" {
+		return
+	}
+	if err := e.State().Record(st); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: recording %s as %s: %v\n", st.MR, st.Phase, err)
+	}
+}
+
+// Reconciled describes what ReconcileState did with one in-flight MR.
+type Reconciled struct {
+	MR          string `json:"mr"`
+	Phase       string `json:"phase"`
+	Action      string `json:"action"`
+	MergeCommit string `json:"merge_commit,omitempty"`
+}
+
+// Reconcile actions.
+const (
+	ReconcileFinished = "finished" // It had landed; the MR and issue were closed
+	ReconcileReleased = "released" // It hadn't landed; it's back in the queue
+	ReconcileDropped  = "dropped"  // Beads already shows it finished or gone
+	ReconcileKept     = "kept"     // Its fate couldn't be determined; try again later
+)
+
+// ReconcileState settles MRs a crashed refinery left in flight, against
+// beads and the forge: an MR that landed is closed as merged, one that
+// didn't is released back to the queue, and one beads already shows as
+// finished is dropped. It refuses to run while MRs are being processed.
+func (e *Engineer) ReconcileState(ctx context.Context) ([]Reconciled, error) {
+	store := e.State()
+	fl, err := store.LockProcessing()
+	if err != nil {
+		return nil, err
+	}
+	if fl == nil {
+		return nil, ErrProcessing
+	}
+	defer func() { _ = fl.Unlock() }()
+
+	inflight, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+	var results []Reconciled
+	for _, st := range inflight {
+		if ctx.Err() != nil {
+			return results, ctx.Err()
+		}
+		r := e.reconcileMR(st)
+		results = append(results, r)
+		switch r.Action {
+		case ReconcileFinished, ReconcileDropped:
+			e.recordState(MRState{MR: st.MR, Phase: PhaseDone})
+		case ReconcileReleased:
+			e.recordState(MRState{MR: st.MR, Phase: PhaseReleased})
+		}
+	}
+	return results, nil
+}
+
+func (e *Engineer) reconcileMR(st MRState) Reconciled {
+	r := Reconciled{MR: st.MR, Phase: st.Phase}
+	issue, err := e.beads.Show(st.MR)
+	if err != nil {
+		if errors.Is(err, beads.ErrNotFound) {
+			r.Action = ReconcileDropped
+		} else {
+			r.Action = ReconcileKept
+		}
+		return r
+	}
+	if issue.Status == "closed" {
+		r.Action = ReconcileDropped
+		return r
+	}
+	fields := beads.ParseMRFields(issue)
+	if fields == nil {
+		fields = &beads.MRFields{}
+	}
+	mr := mrInfoFromIssue(issue, fields)
+
+	commit := st.MergeCommit
+	if st.Phase == PhaseMerging {
+		merged, mergeCommit, err := e.prMerged(st.PR)
+		if err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: checking PR #%d for %s: %v\n", st.PR, st.MR, err)
+			r.Action = ReconcileKept
+			return r
+		}
+		if !merged {
+			commit = ""
+		} else if commit = mergeCommit; commit == "" {
+			commit = "unknown"
+		}
+	}
+	if commit != "" {
+		e.HandleMRInfoSuccess(mr, ProcessResult{Success: true, MergeCommit: commit})
+		r.Action, r.MergeCommit = ReconcileFinished, commit
+		return r
+	}
+
+	if err := e.ReleaseMR(st.MR); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: releasing %s: %v\n", st.MR, err)
+		r.Action = ReconcileKept
+		return r
+	}
+	r.Action = ReconcileReleased
+	return r
+}
+
+// prMerged asks the forge whether a PR merged, and as what commit. A
+// missing PR number never merged.
+func (e *Engineer) prMerged(prNumber int) (bool, string, error) {
+	if prNumber == 0 {
+		return false, "", nil
+	}
+	cmd := exec.Command("gh", "pr", "view", strconv.Itoa(prNumber), "--json", "state,mergeCommit")
+	cmd.Dir = e.workDir
+	out, err := cmd.Output()
+	if err != nil {
+		return false, "", fmt.Errorf("gh pr view: %w", err)
+	}
+	return parsePRMergeState(out)
+}
+
+func parsePRMergeState(out []byte) (bool, string, error) {
+	var pr struct {
+		State       string `json:"state"`
+		MergeCommit *struct {
+			OID string `json:"oid"`
+		} `json:"mergeCommit"`
+	}
+	if err := json.Unmarshal(out, &pr); err != nil {
+		return false, "", fmt.Errorf("parsing gh output: %w", err)
+	}
+	if !strings.EqualFold(pr.State, "MERGED") {
+		return false, "", nil
+	}
+	if pr.MergeCommit == nil {
+		return true, "", nil
+	}
+	return true, pr.MergeCommit.OID, nil
+}
@@ -0,0 +1,149 @@
+package refinery
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestStateStore_RecordLoad(t *testing.T) {
+	store := NewStateStore(t.TempDir())
+
+	records := []MRState{
+		{MR: "gt-mr1", Phase: PhaseClaimed, Worker: "gastown/refinery"},
+		{MR: "gt-mr2", Phase: PhaseClaimed},
+		{MR: "gt-mr1", Phase: PhaseMerging, PR: 42},
+		{MR: "gt-mr3", Phase: PhaseClaimed},
+		{MR: "gt-mr2", Phase: PhaseDone},
+		{MR: "gt-mr3", Phase: PhaseReleased},
+	}
+	for _, st := range records {
+		if err := store.Record(st); err != nil {
+			t.Fatalf("Record(%+v): %v", st, err)
+		}
+	}
+
+	states, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(states) != 1 {
+		t.Fatalf("Load() = %v, want only gt-mr1 in flight", states)
+	}
+	st := states["gt-mr1"]
+	if st.Phase != PhaseMerging || st.PR != 42 || st.At.IsZero() {
+		t.Errorf("gt-mr1 = %+v, want merging PR #42 with a timestamp", st)
+	}
+}
+
+func TestStateStore_TornRecord(t *testing.T) {
+	store := NewStateStore(t.TempDir())
+	if err := store.Record(MRState{MR: "gt-mr1", Phase: PhaseClaimed}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A crash mid-append leaves a partial record without a newline
+	f, err := os.OpenFile(store.journalPath(), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(`{"mr":"gt-mr1","phase":"mer`); err != nil {
+		t.Fatal(err)
+	}
+	_ = f.Close()
+
+	states, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load with torn record: %v", err)
+	}
+	if states["gt-mr1"].Phase != PhaseClaimed {
+		t.Errorf("gt-mr1 = %+v, want the last whole record (claimed)", states["gt-mr1"])
+	}
+
+	// Records after the torn one still parse
+	if err := store.Record(MRState{MR: "gt-mr2", Phase: PhaseClaimed}); err != nil {
+		t.Fatal(err)
+	}
+	if states, _ := store.Load(); len(states) != 2 {
+		t.Errorf("Load() = %v, want gt-mr1 and gt-mr2", states)
+	}
+}
+
+func TestStateStore_Compaction(t *testing.T) {
+	store := NewStateStore(t.TempDir())
+	for i := 0; i < compactAfter+5; i++ {
+		st := MRState{MR: fmt.Sprintf("gt-mr%d", i%10), Phase: PhaseClaimed}
+		if i%3 == 0 {
+			st.Phase = PhaseDone
+		}
+		if err := store.Record(st); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n, _ := countLines(store.journalPath()); n >= compactAfter {
+		t.Errorf("journal has %d records after compaction, want fewer than %d", n, compactAfter)
+	}
+	if _, err := os.Stat(store.snapshotPath()); err != nil {
+		t.Errorf("no snapshot after compaction: %v", err)
+	}
+
+	// The last record for each MR wins, across snapshot and journal
+	want := make(map[string]bool)
+	for i := 0; i < compactAfter+5; i++ {
+		want[fmt.Sprintf("gt-mr%d", i%10)] = i%3 != 0
+	}
+	states, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for mr, inflight := range want {
+		if _, ok := states[mr]; ok != inflight {
+			t.Errorf("%s in flight = %v, want %v", mr, ok, inflight)
+		}
+	}
+}
+
+func TestStateStore_LockProcessing(t *testing.T) {
+	store := NewStateStore(t.TempDir())
+	fl, err := store.LockProcessing()
+	if err != nil || fl == nil {
+		t.Fatalf("LockProcessing() = %v, %v; want the lock", fl, err)
+	}
+	if again, err := store.LockProcessing(); err != nil || again != nil {
+		t.Errorf("second LockProcessing() = %v, %v; want nil while held", again, err)
+	}
+	_ = fl.Unlock()
+	again, err := store.LockProcessing()
+	if err != nil || again == nil {
+		t.Errorf("LockProcessing() after unlock = %v, %v; want the lock", again, err)
+	} else {
+		_ = again.Unlock()
+	}
+}
+
+func TestParsePRMergeState(t *testing.T) {
+	tests := []struct {
+		name       string
+		out        string
+		wantMerged bool
+		wantCommit string
+		wantErr    bool
+	}{
+		{"merged", `{"state":"MERGED","mergeCommit":{"oid":"abc123"}}`, true, "abc123", false},
+		{"merged without commit", `{"state":"MERGED","mergeCommit":null}`, true, "", false},
+		{"open", `{"state":"OPEN","mergeCommit":null}`, false, "", false},
+		{"closed", `{"state":"CLOSED"}`, false, "", false},
+		{"garbage", `not json`, false, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, commit, err := parsePRMergeState([]byte(tt.out))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if merged != tt.wantMerged || commit != tt.wantCommit {
+				t.Errorf("got (%v, %q), want (%v, %q)", merged, commit, tt.wantMerged, tt.wantCommit)
+			}
+		})
+	}
+}
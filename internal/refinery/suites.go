@@ -0,0 +1,81 @@
+// Package refinery provides the merge queue processing agent.
+// This file selects and runs the rig's test suites for an MR.
+
+package refinery
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/steveyegge/gastown/internal/testsuite"
+)
+
+// TestSuites returns the rig's configured test suites. A rig with only a
+// test_command gets it as a single "default" suite covering every path.
+func (e *Engineer) TestSuites() []testsuite.Suite {
+	if len(e.config.TestSuites) > 0 {
+		return e.config.TestSuites
+	}
+	if e.config.TestCommand != "" {
+		return []testsuite.Suite{{Name: "default", Command: e.config.TestCommand}}
+	}
+	return nil
+}
+
+// SelectSuites returns the suites covering the files an MR's branch changes
+// against its target. A rename counts as a change to both paths. If the
+// diff can't be read, every suite is selected.
+func (e *Engineer) SelectSuites(branch, target string) []testsuite.Suite {
+	changes, err := e.git.ChangedFiles(e.resolveRef(target), e.resolveRef(branch))
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: reading diff for suite selection: %v (running all suites)\n", err)
+		return e.TestSuites()
+	}
+	files := make([]string, 0, len(changes))
+	for _, c := range changes {
+		files = append(files, c.Path)
+		if c.OldPath != "" {
+			files = append(files, c.OldPath)
+		}
+	}
+	return testsuite.Select(e.TestSuites(), files)
+}
+
+// RunSuites runs suites in order, each with RunTests' retry and triage,
+// stopping at the first failing suite.
+func (e *Engineer) RunSuites(ctx context.Context, suites []testsuite.Suite) ProcessResult {
+	result := ProcessResult{Success: true}
+	for _, s := range suites {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Running test suite %s\n", s.Name)
+		result = e.runTestCommand(ctx, s.Command)
+		if !result.Success {
+			result.Error = fmt.Sprintf("suite %s: %s", s.Name, result.Error)
+			return result
+		}
+	}
+	return result
+}
+
+// RunMRSuites runs the suites an MR's diff touches against its branch, in
+// a scratch worktree borrowed from the pool so the refinery clone's
+// checkout is left alone.
+func (e *Engineer) RunMRSuites(ctx context.Context, branch, target string) ProcessResult {
+	suites := e.SelectSuites(branch, target)
+	if len(suites) == 0 {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] No test suites cover %s; skipping local tests\n", branch)
+		return ProcessResult{Success: true}
+	}
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Test suites for %s: %v\n", branch, testsuite.Names(suites))
+
+	scratch, err := e.Worktrees().Borrow(e.resolveRef(branch))
+	if err != nil {
+		return ProcessResult{Error: fmt.Sprintf("checking out %s for tests: %v", branch, err)}
+	}
+	defer scratch.Return()
+
+	// Test commands run in the work dir
+	workDir := e.workDir
+	e.workDir = scratch.Path
+	defer func() { e.workDir = workDir }()
+	return e.RunSuites(ctx, suites)
+}
@@ -0,0 +1,51 @@
+package refinery
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/testsuite"
+)
+
+func TestTestSuites_FallsBackToTestCommand(t *testing.T) {
+	e := &Engineer{config: &MergeQueueConfig{TestCommand: "make test"}}
+	got := e.TestSuites()
+	if len(got) != 1 || got[0].Name != "default" || got[0].Command != "make test" || len(got[0].Paths) != 0 {
+		t.Errorf("TestSuites = %+v", got)
+	}
+
+	e.config.TestSuites = []testsuite.Suite{{Name: "unit", Command: "go test ./..."}}
+	if got := testsuite.Names(e.TestSuites()); len(got) != 1 || got[0] != "unit" {
+		t.Errorf("configured suites should win, got %v", got)
+	}
+}
+
+func TestRunSuites_StopsAtFirstFailure(t *testing.T) {
+	dir := t.TempDir()
+	e := &Engineer{
+		config:  &MergeQueueConfig{RetryFlakyTests: 1},
+		workDir: dir,
+		output:  io.Discard,
+	}
+	result := e.RunSuites(context.Background(), []testsuite.Suite{
+		{Name: "unit", Command: "touch unit"},
+		{Name: "lint", Command: "exit 1"},
+		{Name: "e2e", Command: "touch e2e"},
+	})
+	if result.Success || !result.TestsFailed {
+		t.Fatalf("expected test failure, got %+v", result)
+	}
+	if !strings.HasPrefix(result.Error, "suite lint: ") {
+		t.Errorf("Error = %q, want it to name the suite", result.Error)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "unit")); err != nil {
+		t.Error("unit suite should have run")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "e2e")); err == nil {
+		t.Error("e2e suite should not run after lint failed")
+	}
+}
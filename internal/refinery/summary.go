@@ -0,0 +1,49 @@
+// Package refinery provides the merge queue processing agent.
+// This file attaches semantic change summaries to MR beads.
+
+package refinery
+
+import (
+	"fmt"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/semdiff"
+)
+
+// ChangeSummarySection is the MR bead description section holding the
+// change summary.
+const ChangeSummarySection = "change-summary"
+
+// changeSummaryMaxItems caps the files and symbols listed on the bead.
+const changeSummaryMaxItems = 40
+
+// SummarizeMR builds the change summary of an MR's branch against its target.
+func (e *Engineer) SummarizeMR(branch, target string) (*semdiff.Summary, error) {
+	return semdiff.Summarize(e.git, e.resolveRef(target), e.resolveRef(branch))
+}
+
+// RecordSummary stores a change summary on the MR bead: API change counts as
+// MR fields and the rendered summary as the change-summary section.
+func (e *Engineer) RecordSummary(mrID string, summary *semdiff.Summary) error {
+	if summary == nil {
+		return nil
+	}
+
+	mrBead, err := e.beads.Show(mrID)
+	if err != nil {
+		return fmt.Errorf("fetching MR bead %s: %w", mrID, err)
+	}
+	mrFields := beads.ParseMRFields(mrBead)
+	if mrFields == nil {
+		mrFields = &beads.MRFields{}
+	}
+	mrFields.APIChanges = len(summary.API())
+	mrFields.BreakingChanges = len(summary.Breaking())
+
+	newDesc := beads.SetMRFields(mrBead, mrFields)
+	newDesc = beads.SetDescriptionSection(newDesc, ChangeSummarySection, summary.Markdown(changeSummaryMaxItems))
+	if err := e.beads.Update(mrID, beads.UpdateOptions{Description: &newDesc}); err != nil {
+		return fmt.Errorf("updating MR %s with change summary: %w", mrID, err)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+// Package refinery provides the merge queue processing agent.
+// This file applies per-target policies to MRs into non-default branches.
+
+package refinery
+
+import (
+	"path"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// MatchTarget returns the policy for a target branch: the exact entry if
+// there is one, else the longest matching pattern.
+func MatchTarget(targets map[string]config.TargetPolicy, branch string) (string, config.TargetPolicy, bool) {
+	if p, ok := targets[branch]; ok {
+		return branch, p, true
+	}
+	best := ""
+	for pattern := range targets {
+		if ok, _ := path.Match(pattern, branch); !ok {
+			continue
+		}
+		if len(pattern) > len(best) || (len(pattern) == len(best) && pattern < best) {
+			best = pattern
+		}
+	}
+	if best == "" {
+		return "", config.TargetPolicy{}, false
+	}
+	return best, targets[best], true
+}
+
+// ConfigFor returns the merge queue config for MRs into target, with the
+// target's policy applied. An empty target is the rig's default branch.
+func (e *Engineer) ConfigFor(target string) *MergeQueueConfig {
+	cfg := *e.config
+	if target == "" {
+		target = cfg.TargetBranch
+	}
+	_, p, ok := MatchTarget(cfg.Targets, target)
+	if !ok {
+		return &cfg
+	}
+	if p.RequireReview != nil {
+		cfg.RequireReview = *p.RequireReview
+	}
+	if p.RunTests != nil {
+		cfg.RunTests = *p.RunTests
+	}
+	if p.PRMergeMethod != "" {
+		cfg.PRMergeMethod = p.PRMergeMethod
+	}
+	if len(p.FreezeWindows) > 0 {
+		cfg.FreezeWindows = append(append([]string(nil), cfg.FreezeWindows...), p.FreezeWindows...)
+	}
+	return &cfg
+}
+
+// TargetFreeze returns the queue freeze for MRs into target: the rig's
+// freeze, plus the target's own freeze windows.
+func (e *Engineer) TargetFreeze(target string) (*QueueFreeze, error) {
+	return e.queueFreeze(e.ConfigFor(target).FreezeWindows)
+}
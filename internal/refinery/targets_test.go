@@ -0,0 +1,58 @@
+package refinery
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func TestMatchTarget(t *testing.T) {
+	targets := map[string]config.TargetPolicy{
+		"release-*":   {PRMergeMethod: "merge"},
+		"release-1.*": {PRMergeMethod: "rebase"},
+		"release-1.2": {PRMergeMethod: "squash"},
+	}
+	tests := []struct {
+		branch, want string
+		ok           bool
+	}{
+		{"release-1.2", "release-1.2", true},
+		{"release-1.3", "release-1.*", true},
+		{"release-2.0", "release-*", true},
+		{"main", "", false},
+		{"integration/gt-epic", "", false},
+	}
+	for _, tt := range tests {
+		got, _, ok := MatchTarget(targets, tt.branch)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("MatchTarget(%q) = %q, %v; want %q, %v", tt.branch, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestConfigFor(t *testing.T) {
+	yes := true
+	cfg := DefaultMergeQueueConfig()
+	cfg.FreezeWindows = []string{"sat 00:00-sun 23:59"}
+	cfg.Targets = map[string]config.TargetPolicy{
+		"release-*": {RequireReview: &yes, PRMergeMethod: "merge", FreezeWindows: []string{"fri 16:00-mon 08:00"}},
+	}
+	e := &Engineer{config: cfg}
+
+	release := e.ConfigFor("release-1.2")
+	if !release.RequireReview || release.PRMergeMethod != "merge" {
+		t.Errorf("release config = review %v, method %q", release.RequireReview, release.PRMergeMethod)
+	}
+	if want := []string{"sat 00:00-sun 23:59", "fri 16:00-mon 08:00"}; !reflect.DeepEqual(release.FreezeWindows, want) {
+		t.Errorf("release windows = %v, want %v", release.FreezeWindows, want)
+	}
+	for _, target := range []string{"", "main"} {
+		if c := e.ConfigFor(target); c.RequireReview || c.PRMergeMethod != cfg.PRMergeMethod || len(c.FreezeWindows) != 1 {
+			t.Errorf("ConfigFor(%q) picked up the release policy: %+v", target, c)
+		}
+	}
+	if len(cfg.FreezeWindows) != 1 {
+		t.Errorf("ConfigFor modified the rig's windows: %v", cfg.FreezeWindows)
+	}
+}
@@ -0,0 +1,334 @@
+// Package refinery provides the merge queue processing agent.
+// This file contains test-failure triage: parsing test output, recording
+// structured failures on MR beads, and filing beads for flaky tests.
+
+package refinery
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/flaky"
+	"github.com/steveyegge/gastown/internal/testtriage"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// FlakyTestLabel marks beads filed for flaky tests.
+const FlakyTestLabel = "gt:flaky-test"
+
+// DefaultFlakeBeadThreshold is how many MRs a test must be flaky on before
+// a bead is filed for it, so one unlucky run doesn't file a bug.
+const DefaultFlakeBeadThreshold = 3
+
+// flakeWindow is how far back flaky runs count toward the threshold.
+const flakeWindow = 30 * 24 * time.Hour
+
+// attemptFailures extracts failures from one test attempt. For the junit
+// format the configured report file is read instead of the command output.
+// Read and parse errors are logged and yield no failures, so the run is
+// still classified (as unparsed) rather than dropped.
+func (e *Engineer) attemptFailures(output []byte) []testtriage.Failure {
+	if e.config.TestOutputFormat == testtriage.FormatJUnit {
+		if e.config.TestReport == "" {
+			_, _ = fmt.Fprintln(e.output, "[Engineer] Warning: junit test format configured without test_report")
+			return nil
+		}
+		reportPath := e.config.TestReport
+		if !filepath.IsAbs(reportPath) {
+			reportPath = filepath.Join(e.workDir, reportPath)
+		}
+		data, err := os.ReadFile(reportPath) //nolint:gosec // G304: path is from trusted rig config
+		if err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: reading test report: %v\n", err)
+			return nil
+		}
+		output = data
+	}
+
+	failures, err := e.ParseTestFailures(output)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: parsing test output: %v\n", err)
+		return nil
+	}
+	return failures
+}
+
+// ParseTestFailures parses test output (or a JUnit report) using the rig's
+// configured test_output_format and test_failure_pattern.
+func (e *Engineer) ParseTestFailures(data []byte) ([]testtriage.Failure, error) {
+	return testtriage.Parse(data, e.config.TestOutputFormat, e.config.TestFailurePattern)
+}
+
+// LoadQuarantine loads the rig's quarantine registry. Returns nil when the
+// quarantine policy is off or the registry can't be read (logged).
+func (e *Engineer) LoadQuarantine() *flaky.Registry {
+	if e.config.QuarantinePolicy == flaky.PolicyOff || e.rig == nil {
+		return nil
+	}
+	reg, err := flaky.Load(e.rig.Path)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: %v\n", err)
+		return nil
+	}
+	return reg
+}
+
+// QuarantineAttempt applies the quarantine policy to one failed attempt and
+// returns the attempt to classify plus the failures covered by the registry.
+// Under the "skip" policy quarantined failures are dropped, and an attempt
+// that failed only on quarantined tests counts as passed.
+func (e *Engineer) QuarantineAttempt(reg *flaky.Registry, failures []testtriage.Failure) (testtriage.Attempt, []testtriage.Failure) {
+	if reg == nil || len(failures) == 0 {
+		return testtriage.Attempt{Failures: failures}, nil
+	}
+
+	now := time.Now()
+	var active, quarantined []testtriage.Failure
+	for _, f := range failures {
+		if reg.Match(f.Package, f.Test, now) != nil {
+			quarantined = append(quarantined, f)
+		} else {
+			active = append(active, f)
+		}
+	}
+
+	if e.config.QuarantinePolicy == flaky.PolicySkip {
+		return testtriage.Attempt{Passed: len(active) == 0, Failures: active}, quarantined
+	}
+	return testtriage.Attempt{Failures: failures}, quarantined
+}
+
+// mergeFailures appends failures not already present (by ID).
+func mergeFailures(dst, src []testtriage.Failure) []testtriage.Failure {
+	for _, f := range src {
+		dup := false
+		for _, d := range dst {
+			if d.ID() == f.ID() {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			dst = append(dst, f)
+		}
+	}
+	return dst
+}
+
+// RecordTriage attaches a triage report to an MR bead as MR fields and, if
+// file_flake_beads is enabled, files a bug bead for each test that has been
+// flaky on flake_bead_threshold MRs.
+// Clean runs (no failures on any attempt) clear stale triage fields.
+func (e *Engineer) RecordTriage(mrID string, report *testtriage.Report) error {
+	if report == nil {
+		return nil
+	}
+
+	mrBead, err := e.beads.Show(mrID)
+	if err != nil {
+		return fmt.Errorf("fetching MR bead %s: %w", mrID, err)
+	}
+	mrFields := beads.ParseMRFields(mrBead)
+	if mrFields == nil {
+		mrFields = &beads.MRFields{}
+	}
+
+	mrFields.TestTriage = ""
+	if report.Class != testtriage.ClassNone {
+		mrFields.TestTriage = report.Class
+	}
+	mrFields.FailedTests = strings.Join(testtriage.IDs(report.Real), ",")
+	mrFields.FlakyTests = strings.Join(testtriage.IDs(report.Flaky), ",")
+
+	newDesc := beads.SetMRFields(mrBead, mrFields)
+	if err := e.beads.Update(mrID, beads.UpdateOptions{Description: &newDesc}); err != nil {
+		return fmt.Errorf("updating MR %s with triage: %w", mrID, err)
+	}
+
+	// Quarantined tests are already tracked; only file beads for new flakes
+	known := make(map[string]bool, len(report.Quarantined))
+	for _, f := range report.Quarantined {
+		known[f.ID()] = true
+	}
+	var newFlakes []testtriage.Failure
+	for _, f := range report.Flaky {
+		if !known[f.ID()] {
+			newFlakes = append(newFlakes, f)
+		}
+	}
+
+	if e.config.FileFlakeBeads && len(newFlakes) > 0 {
+		persistent, err := e.persistentFlakes(mrID, newFlakes)
+		if err != nil {
+			return err
+		}
+		filed, err := e.fileFlakeBeads(mrID, persistent)
+		if err != nil {
+			return err
+		}
+		for _, id := range filed {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Filed flaky-test bead: %s\n", id)
+		}
+	}
+	return nil
+}
+
+// FlakeBeadTitle returns the bead title used for a flaky test.
+// Titles are stable so an unfinished bead suppresses duplicate filings.
+func FlakeBeadTitle(f testtriage.Failure) string {
+	return "Flaky test: " + f.ID()
+}
+
+// persistentFlakes records that tests were flaky on an MR and returns
+// those now flaky on at least FlakeBeadThreshold MRs.
+func (e *Engineer) persistentFlakes(mrID string, flakes []testtriage.Failure) ([]testtriage.Failure, error) {
+	if e.config.FlakeBeadThreshold <= 1 || e.rig == nil {
+		return flakes, nil
+	}
+	persistent, err := NewFlakeLog(e.rig.Path).Record(mrID, flakes, e.config.FlakeBeadThreshold, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("recording flaky tests: %w", err)
+	}
+	return persistent, nil
+}
+
+// fileFlakeBeads files one bug bead per flaky test that doesn't already have
+// a bead open, hooked, or in progress. Returns the IDs of newly filed beads.
+func (e *Engineer) fileFlakeBeads(mrID string, flaky []testtriage.Failure) ([]string, error) {
+	if len(flaky) == 0 {
+		return nil, nil
+	}
+	existing, err := e.beads.ListActive(beads.ListOptions{
+		Label:    FlakyTestLabel,
+		Priority: -1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing flaky-test beads: %w", err)
+	}
+	open := make(map[string]bool, len(existing))
+	for _, issue := range existing {
+		open[issue.Title] = true
+	}
+
+	var filed []string
+	for _, f := range flaky {
+		title := FlakeBeadTitle(f)
+		if open[title] {
+			continue
+		}
+
+		var desc strings.Builder
+		fmt.Fprintf(&desc, "test: %s\n", f.Test)
+		if f.Package != "" {
+			fmt.Fprintf(&desc, "package: %s\n", f.Package)
+		}
+		fmt.Fprintf(&desc, "seen_in: %s\n", mrID)
+		if f.Message != "" {
+			fmt.Fprintf(&desc, "\nFailure output:\n%s\n", f.Message)
+		}
+		desc.WriteString("\nThis test failed on some but not all attempts during merge queue validation.")
+
+		issue, err := e.beads.Create(beads.CreateOptions{
+			Title:       title,
+			Type:        "bug",
+			Priority:    2,
+			Description: desc.String(),
+			Labels:      []string{FlakyTestLabel},
+			Actor:       e.rig.Name + "/refinery",
+		})
+		if err != nil {
+			return filed, fmt.Errorf("filing flaky-test bead for %s: %w", f.ID(), err)
+		}
+		open[title] = true
+		filed = append(filed, issue.ID)
+	}
+	return filed, nil
+}
+
+// FlakeLog records the MRs each test has been flaky on, so beads are filed
+// for tests that flake again and again rather than once. It lives under
+// <rig>/.runtime/refinery, guarded by a file lock like the state store.
+type FlakeLog struct {
+	dir string
+}
+
+// NewFlakeLog returns the flake log for a rig.
+func NewFlakeLog(rigPath string) *FlakeLog {
+	return &FlakeLog{dir: filepath.Join(rigPath, ".runtime", "refinery")}
+}
+
+func (l *FlakeLog) path() string { return filepath.Join(l.dir, "flakes.json") }
+
+// flakeSighting is a test seen flaky on an MR.
+type flakeSighting struct {
+	MR string    `json:"mr"`
+	At time.Time `json:"at"`
+}
+
+// Record notes that flakes were flaky on mrID at now, and returns those
+// flaky on at least threshold MRs within flakeWindow. Their sightings are
+// dropped, so a test's count starts over once it's reported; retries of
+// one MR count once.
+func (l *FlakeLog) Record(mrID string, flakes []testtriage.Failure, threshold int, now time.Time) ([]testtriage.Failure, error) {
+	if err := os.MkdirAll(l.dir, 0755); err != nil {
+		return nil, fmt.Errorf("creating refinery state dir: %w", err)
+	}
+	fl := flock.New(filepath.Join(l.dir, "flakes.lock"))
+	if err := fl.Lock(); err != nil {
+		return nil, fmt.Errorf("locking flake log: %w", err)
+	}
+	defer func() { _ = fl.Unlock() }()
+
+	log := make(map[string][]flakeSighting)
+	data, err := os.ReadFile(l.path())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &log); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", l.path(), err)
+		}
+	}
+
+	cutoff := now.Add(-flakeWindow)
+	for test, seen := range log {
+		recent := seen[:0]
+		for _, s := range seen {
+			if s.At.After(cutoff) {
+				recent = append(recent, s)
+			}
+		}
+		if len(recent) == 0 {
+			delete(log, test)
+		} else {
+			log[test] = recent
+		}
+	}
+
+	var persistent []testtriage.Failure
+	for _, f := range flakes {
+		id := f.ID()
+		seen := log[id]
+		if !slices.ContainsFunc(seen, func(s flakeSighting) bool { return s.MR == mrID }) {
+			seen = append(seen, flakeSighting{MR: mrID, At: now})
+		}
+		if len(seen) >= threshold {
+			persistent = append(persistent, f)
+			delete(log, id)
+			continue
+		}
+		log[id] = seen
+	}
+
+	if err := util.AtomicWriteJSON(l.path(), log); err != nil {
+		return nil, fmt.Errorf("writing flake log: %w", err)
+	}
+	return persistent, nil
+}
@@ -0,0 +1,141 @@
+package refinery
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/flaky"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/testtriage"
+)
+
+func TestRunTests_FlakeIsClassified(t *testing.T) {
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "ran-once")
+
+	// Fails on the first attempt, passes on the second.
+	script := `if [ -f ran-once ]; then exit 0; fi
+touch ran-once
+echo "--- FAIL: TestNet (0.01s)"
+echo "    net_test.go:5: connection reset"
+echo "FAIL	example.com/net	0.02s"
+exit 1`
+
+	e := &Engineer{
+		config: &MergeQueueConfig{
+			TestCommand:      script,
+			RetryFlakyTests:  2,
+			TestOutputFormat: testtriage.FormatGo,
+		},
+		workDir: dir,
+		output:  io.Discard,
+	}
+
+	result := e.RunTests(context.Background())
+	if !result.Success {
+		t.Fatalf("expected success after retry, got error %q", result.Error)
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Fatalf("test command did not run: %v", err)
+	}
+	if result.Triage == nil || result.Triage.Class != testtriage.ClassFlaky {
+		t.Fatalf("expected flaky triage, got %+v", result.Triage)
+	}
+	if got := testtriage.IDs(result.Triage.Flaky); len(got) != 1 || got[0] != "example.com/net.TestNet" {
+		t.Errorf("Flaky = %v", got)
+	}
+}
+
+func TestRunTests_RealFailure(t *testing.T) {
+	e := &Engineer{
+		config: &MergeQueueConfig{
+			TestCommand:      `echo "--- FAIL: TestMath (0.00s)"; echo "FAIL	example.com/math	0.01s"; exit 1`,
+			RetryFlakyTests:  2,
+			TestOutputFormat: testtriage.FormatGo,
+		},
+		workDir: t.TempDir(),
+		output:  io.Discard,
+	}
+
+	result := e.RunTests(context.Background())
+	if result.Success || !result.TestsFailed {
+		t.Fatalf("expected test failure, got %+v", result)
+	}
+	if result.Triage == nil || result.Triage.Class != testtriage.ClassReal {
+		t.Fatalf("expected real triage, got %+v", result.Triage)
+	}
+	if got := testtriage.IDs(result.Triage.Real); len(got) != 1 || got[0] != "example.com/math.TestMath" {
+		t.Errorf("Real = %v", got)
+	}
+}
+
+func TestRunTests_QuarantineSkip(t *testing.T) {
+	rigPath := t.TempDir()
+	reg := &flaky.Registry{}
+	reg.Add(flaky.Entry{Test: "example.com/net.TestNet"})
+	if err := flaky.Save(rigPath, reg); err != nil {
+		t.Fatal(err)
+	}
+
+	e := &Engineer{
+		rig: &rig.Rig{Name: "test-rig", Path: rigPath},
+		config: &MergeQueueConfig{
+			TestCommand:      `echo "--- FAIL: TestNet (0.01s)"; echo "FAIL	example.com/net	0.02s"; exit 1`,
+			RetryFlakyTests:  1,
+			TestOutputFormat: testtriage.FormatGo,
+			QuarantinePolicy: flaky.PolicySkip,
+		},
+		workDir: t.TempDir(),
+		output:  io.Discard,
+	}
+
+	result := e.RunTests(context.Background())
+	if !result.Success {
+		t.Fatalf("expected quarantined failure to be skipped, got error %q", result.Error)
+	}
+	if got := testtriage.IDs(result.Triage.Quarantined); len(got) != 1 || got[0] != "example.com/net.TestNet" {
+		t.Errorf("Quarantined = %v", got)
+	}
+}
+
+func TestFlakeLog_Record(t *testing.T) {
+	log := NewFlakeLog(t.TempDir())
+	net := testtriage.Failure{Package: "example.com/net", Test: "TestNet"}
+	dns := testtriage.Failure{Package: "example.com/net", Test: "TestDNS"}
+	now := time.Now()
+
+	record := func(mr string, at time.Time, flakes ...testtriage.Failure) []string {
+		t.Helper()
+		persistent, err := log.Record(mr, flakes, 3, at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return testtriage.IDs(persistent)
+	}
+
+	if got := record("gt-mr-1", now, net, dns); len(got) != 0 {
+		t.Errorf("first sighting reported %v", got)
+	}
+	// A retried MR counts once
+	if got := record("gt-mr-1", now, net); len(got) != 0 {
+		t.Errorf("same MR again reported %v", got)
+	}
+	if got := record("gt-mr-2", now, net); len(got) != 0 {
+		t.Errorf("second MR reported %v", got)
+	}
+	if got := record("gt-mr-3", now, net, dns); len(got) != 1 || got[0] != net.ID() {
+		t.Errorf("third MR reported %v, want only %s", got, net.ID())
+	}
+	// A reported test starts over
+	if got := record("gt-mr-4", now, net); len(got) != 0 {
+		t.Errorf("after reporting, got %v", got)
+	}
+	// Sightings older than the window don't count
+	if got := record("gt-mr-5", now.Add(flakeWindow+time.Hour), dns); len(got) != 0 {
+		t.Errorf("stale sightings counted: %v", got)
+	}
+}
@@ -0,0 +1,119 @@
+package release
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Changelog section titles, in display order.
+const (
+	SectionBreaking = "Breaking Changes"
+	SectionFeatures = "Features"
+	SectionFixes    = "Bug Fixes"
+	SectionDocs     = "Documentation"
+	SectionChores   = "Maintenance"
+	SectionOther    = "Other Changes"
+)
+
+var sectionOrder = []string{SectionBreaking, SectionFeatures, SectionFixes, SectionDocs, SectionChores, SectionOther}
+
+// Section is a titled group of changelog entries.
+type Section struct {
+	Title   string  `json:"title"`
+	Entries []Entry `json:"entries"`
+}
+
+// SectionFor returns the changelog section for an entry. Breaking changes
+// win; then the source issue's type; then labels such as "docs" or "chore".
+func SectionFor(e Entry) string {
+	if e.Breaking {
+		return SectionBreaking
+	}
+	switch e.IssueType {
+	case "feature":
+		return SectionFeatures
+	case "bug":
+		return SectionFixes
+	case "chore":
+		return SectionChores
+	}
+	for _, l := range e.Labels {
+		switch strings.ToLower(l) {
+		case "breaking", "breaking-change":
+			return SectionBreaking
+		case "feature", "enhancement":
+			return SectionFeatures
+		case "bug", "fix":
+			return SectionFixes
+		case "docs", "documentation":
+			return SectionDocs
+		case "chore", "refactor", "ci", "deps", "dependencies":
+			return SectionChores
+		}
+	}
+	return SectionOther
+}
+
+// Dedupe folds entries for the same source issue (or, without one, the
+// same title) into one, as happens when work is resubmitted as a new MR.
+// The first entry is kept; later MRs are listed in Also, and the entry is
+// breaking if any of them was.
+func Dedupe(entries []Entry) []Entry {
+	var out []Entry
+	index := make(map[string]int)
+	for _, e := range entries {
+		key := "issue:" + e.SourceIssue
+		if e.SourceIssue == "" {
+			key = "title:" + strings.ToLower(strings.TrimSpace(e.Title))
+		}
+		if i, ok := index[key]; ok {
+			out[i].Also = append(out[i].Also, e.MR)
+			out[i].Breaking = out[i].Breaking || e.Breaking
+			if e.MergedAt.After(out[i].MergedAt) {
+				out[i].MergedAt = e.MergedAt
+			}
+			continue
+		}
+		index[key] = len(out)
+		out = append(out, e)
+	}
+	return out
+}
+
+// Group sorts entries into sections in display order, omitting empty ones.
+// Entries keep their relative order within a section.
+func Group(entries []Entry) []Section {
+	bySection := make(map[string][]Entry)
+	for _, e := range entries {
+		s := SectionFor(e)
+		bySection[s] = append(bySection[s], e)
+	}
+	var sections []Section
+	for _, title := range sectionOrder {
+		if len(bySection[title]) > 0 {
+			sections = append(sections, Section{Title: title, Entries: bySection[title]})
+		}
+	}
+	return sections
+}
+
+// Markdown renders grouped changelog sections under a heading.
+func Markdown(heading string, sections []Section) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s\n", heading)
+	if len(sections) == 0 {
+		b.WriteString("\nNo merged changes.\n")
+		return b.String()
+	}
+	for _, s := range sections {
+		fmt.Fprintf(&b, "\n### %s\n\n", s.Title)
+		for _, e := range s.Entries {
+			ref := e.MR
+			if e.SourceIssue != "" {
+				ref = e.SourceIssue
+			}
+			fmt.Fprintf(&b, "- %s (%s)\n", e.Title, ref)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,70 @@
+package release
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSectionFor(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry Entry
+		want  string
+	}{
+		{"breaking wins", Entry{IssueType: "feature", Breaking: true}, SectionBreaking},
+		{"feature type", Entry{IssueType: "feature"}, SectionFeatures},
+		{"bug type", Entry{IssueType: "bug", Labels: []string{"docs"}}, SectionFixes},
+		{"docs label", Entry{IssueType: "task", Labels: []string{"Documentation"}}, SectionDocs},
+		{"chore label", Entry{Labels: []string{"gt:task", "deps"}}, SectionChores},
+		{"breaking label", Entry{Labels: []string{"breaking"}}, SectionBreaking},
+		{"unknown", Entry{IssueType: "task"}, SectionOther},
+	}
+	for _, tt := range tests {
+		if got := SectionFor(tt.entry); got != tt.want {
+			t.Errorf("%s: SectionFor = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDedupeAndGroup(t *testing.T) {
+	t1 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{MR: "mr-1", Title: "Add widgets", SourceIssue: "gt-1", IssueType: "feature", MergedAt: t1},
+		{MR: "mr-2", Title: "Fix crash", SourceIssue: "gt-2", IssueType: "bug", MergedAt: t1.Add(time.Hour)},
+		{MR: "mr-3", Title: "Add widgets", SourceIssue: "gt-1", IssueType: "feature", Breaking: true, MergedAt: t1.Add(2 * time.Hour)},
+		{MR: "mr-4", Title: "Tidy up", MergedAt: t1.Add(3 * time.Hour)},
+		{MR: "mr-5", Title: "tidy up ", MergedAt: t1.Add(4 * time.Hour)},
+	}
+
+	deduped := Dedupe(entries)
+	if len(deduped) != 3 {
+		t.Fatalf("Dedupe = %d entries, want 3: %+v", len(deduped), deduped)
+	}
+	first := deduped[0]
+	if first.MR != "mr-1" || len(first.Also) != 1 || first.Also[0] != "mr-3" || !first.Breaking {
+		t.Errorf("merged entry = %+v, want mr-1 with mr-3 folded in and breaking", first)
+	}
+	if !first.MergedAt.Equal(t1.Add(2 * time.Hour)) {
+		t.Errorf("merged entry MergedAt = %v, want latest", first.MergedAt)
+	}
+	if len(deduped[2].Also) != 1 {
+		t.Errorf("title-only entries not deduplicated: %+v", deduped[2])
+	}
+
+	sections := Group(deduped)
+	var titles []string
+	for _, s := range sections {
+		titles = append(titles, s.Title)
+	}
+	if got := strings.Join(titles, ","); got != "Breaking Changes,Bug Fixes,Other Changes" {
+		t.Errorf("sections = %s", got)
+	}
+
+	md := Markdown("Unreleased", sections)
+	for _, want := range []string{"## Unreleased", "### Bug Fixes\n\n- Fix crash (gt-2)", "- Tidy up (mr-4)"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown missing %q:\n%s", want, md)
+		}
+	}
+}
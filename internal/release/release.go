@@ -0,0 +1,188 @@
+// Package release computes rig releases from merge-queue history: the next
+// semantic version, the MRs merged since the last release tag, and the
+// release notes.
+package release
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+// Label marks release beads.
+const Label = "gt:release"
+
+// Bump kinds.
+const (
+	BumpMajor = "major"
+	BumpMinor = "minor"
+	BumpPatch = "patch"
+)
+
+// Version is a semantic version (major.minor.patch).
+type Version struct {
+	Major int `json:"major"`
+	Minor int `json:"minor"`
+	Patch int `json:"patch"`
+}
+
+// ParseVersion parses a version or tag such as "v1.2.3" with the given
+// prefix. Pre-release and build suffixes are not supported.
+func ParseVersion(s, prefix string) (Version, error) {
+	trimmed := strings.TrimPrefix(s, prefix)
+	parts := strings.Split(trimmed, ".")
+	if len(parts) != 3 || (prefix != "" && trimmed == s) {
+		return Version{}, fmt.Errorf("invalid version %q: want %sMAJOR.MINOR.PATCH", s, prefix)
+	}
+	var nums [3]int
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return Version{}, fmt.Errorf("invalid version %q: want %sMAJOR.MINOR.PATCH", s, prefix)
+		}
+		nums[i] = n
+	}
+	return Version{Major: nums[0], Minor: nums[1], Patch: nums[2]}, nil
+}
+
+// String returns the version without prefix, e.g. "1.2.3".
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Less reports whether v sorts before o.
+func (v Version) Less(o Version) bool {
+	if v.Major != o.Major {
+		return v.Major < o.Major
+	}
+	if v.Minor != o.Minor {
+		return v.Minor < o.Minor
+	}
+	return v.Patch < o.Patch
+}
+
+// Bump returns the next version for a bump kind.
+func (v Version) Bump(kind string) (Version, error) {
+	switch kind {
+	case BumpMajor:
+		return Version{Major: v.Major + 1}, nil
+	case BumpMinor:
+		return Version{Major: v.Major, Minor: v.Minor + 1}, nil
+	case BumpPatch:
+		return Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}, nil
+	}
+	return v, fmt.Errorf("invalid bump %q: want major, minor, or patch", kind)
+}
+
+// Latest returns the highest version among tags with the given prefix.
+// Tags that aren't versions are ignored. ok is false if none match.
+func Latest(tags []string, prefix string) (tag string, v Version, ok bool) {
+	for _, t := range tags {
+		tv, err := ParseVersion(t, prefix)
+		if err != nil {
+			continue
+		}
+		if !ok || v.Less(tv) {
+			tag, v, ok = t, tv, true
+		}
+	}
+	return tag, v, ok
+}
+
+// Entry is one merged MR included in a release.
+type Entry struct {
+	MR          string    `json:"mr"`
+	Title       string    `json:"title"`
+	SourceIssue string    `json:"source_issue,omitempty"`
+	IssueType   string    `json:"issue_type,omitempty"`
+	Branch      string    `json:"branch,omitempty"`
+	Labels      []string  `json:"labels,omitempty"`
+	MergedAt    time.Time `json:"merged_at"`
+	Breaking    bool      `json:"breaking,omitempty"`
+
+	// Also lists other MRs folded into this entry by Dedupe.
+	Also []string `json:"also,omitempty"`
+}
+
+// IsMerged reports whether a closed MR bead was merged (rather than
+// rejected or abandoned), from its close_reason field or bd close reason.
+func IsMerged(mr *beads.Issue) bool {
+	if fields := beads.ParseMRFields(mr); fields != nil && fields.CloseReason != "" {
+		return fields.CloseReason == "merged"
+	}
+	return strings.HasPrefix(strings.ToLower(mr.CloseReason), "merged")
+}
+
+// Collect builds release entries from closed MR beads merged after since.
+// lookup resolves source issues (for titles and types); it may return nil.
+// Entries are sorted by merge time.
+func Collect(mrs []*beads.Issue, since time.Time, lookup func(id string) *beads.Issue) []Entry {
+	var entries []Entry
+	for _, mr := range mrs {
+		if mr.Status != "closed" || !IsMerged(mr) {
+			continue
+		}
+		mergedAt := parseTime(mr.ClosedAt)
+		if !since.IsZero() && !mergedAt.After(since) {
+			continue
+		}
+
+		entry := Entry{MR: mr.ID, Title: strings.TrimPrefix(mr.Title, "Merge: "), MergedAt: mergedAt}
+		if fields := beads.ParseMRFields(mr); fields != nil {
+			entry.SourceIssue = fields.SourceIssue
+			entry.Branch = fields.Branch
+			entry.Breaking = fields.BreakingChanges > 0
+		}
+		if entry.SourceIssue != "" && lookup != nil {
+			if issue := lookup(entry.SourceIssue); issue != nil {
+				entry.Title = issue.Title
+				entry.IssueType = issue.Type
+				entry.Labels = issue.Labels
+			}
+		}
+		entries = append(entries, entry)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].MergedAt.Before(entries[j].MergedAt)
+	})
+	return entries
+}
+
+// InferBump picks the bump kind for a set of entries: major for breaking
+// API changes (minor while the major version is 0), minor if any feature
+// was merged, otherwise patch.
+func InferBump(current Version, entries []Entry) string {
+	kind := BumpPatch
+	for _, e := range entries {
+		if e.Breaking {
+			if current.Major == 0 {
+				return BumpMinor
+			}
+			return BumpMajor
+		}
+		if e.IssueType == "feature" {
+			kind = BumpMinor
+		}
+	}
+	return kind
+}
+
+// Notes renders release notes in Markdown: deduplicated entries grouped
+// into changelog sections.
+func Notes(tag string, entries []Entry) string {
+	return Markdown(tag, Group(Dedupe(entries)))
+}
+
+// parseTime parses a bead timestamp, returning zero time on error.
+func parseTime(s string) time.Time {
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,107 @@
+package release
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		in      string
+		prefix  string
+		want    Version
+		wantErr bool
+	}{
+		{"v1.2.3", "v", Version{1, 2, 3}, false},
+		{"1.2.3", "", Version{1, 2, 3}, false},
+		{"release-0.10.0", "release-", Version{0, 10, 0}, false},
+		{"1.2.3", "v", Version{}, true},
+		{"v1.2", "v", Version{}, true},
+		{"v1.2.x", "v", Version{}, true},
+		{"v1.2.3-rc1", "v", Version{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseVersion(tt.in, tt.prefix)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseVersion(%q, %q) error = %v, wantErr %v", tt.in, tt.prefix, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseVersion(%q, %q) = %v, want %v", tt.in, tt.prefix, got, tt.want)
+		}
+	}
+}
+
+func TestBumpAndLatest(t *testing.T) {
+	v := Version{1, 2, 3}
+	for kind, want := range map[string]Version{
+		BumpMajor: {2, 0, 0},
+		BumpMinor: {1, 3, 0},
+		BumpPatch: {1, 2, 4},
+	} {
+		got, err := v.Bump(kind)
+		if err != nil || got != want {
+			t.Errorf("Bump(%s) = %v, %v; want %v", kind, got, err, want)
+		}
+	}
+	if _, err := v.Bump("huge"); err == nil {
+		t.Error("expected error for invalid bump")
+	}
+
+	tag, latest, ok := Latest([]string{"v1.9.0", "v1.10.0", "v1.2.0", "nightly"}, "v")
+	if !ok || tag != "v1.10.0" || latest != (Version{1, 10, 0}) {
+		t.Errorf("Latest = %q %v %v, want v1.10.0", tag, latest, ok)
+	}
+	if _, _, ok := Latest([]string{"nightly"}, "v"); ok {
+		t.Error("expected no version among non-version tags")
+	}
+}
+
+func TestCollectAndInferBump(t *testing.T) {
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	mrs := []*beads.Issue{
+		{ID: "gt-mr-1", Title: "Merge: fix", Status: "closed", ClosedAt: "2026-01-03T10:00:00Z",
+			CloseReason: "Merged to main at abc123", Description: "branch: polecat/a\nsource_issue: gt-1"},
+		{ID: "gt-mr-2", Title: "Merge: feature", Status: "closed", ClosedAt: "2026-01-02T10:00:00Z",
+			Description: "branch: polecat/b\nsource_issue: gt-2\nclose_reason: merged"},
+		{ID: "gt-mr-3", Title: "Merge: old", Status: "closed", ClosedAt: "2025-12-30T10:00:00Z",
+			Description: "close_reason: merged"},
+		{ID: "gt-mr-4", Title: "Merge: rejected", Status: "closed", ClosedAt: "2026-01-04T10:00:00Z",
+			CloseReason: "Branch no longer exists"},
+		{ID: "gt-mr-5", Title: "Merge: pending", Status: "open"},
+	}
+	issues := map[string]*beads.Issue{
+		"gt-1": {ID: "gt-1", Title: "Fix crash on startup", Type: "bug"},
+		"gt-2": {ID: "gt-2", Title: "Add release command", Type: "feature"},
+	}
+
+	entries := Collect(mrs, since, func(id string) *beads.Issue { return issues[id] })
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2: %+v", len(entries), entries)
+	}
+	if entries[0].MR != "gt-mr-2" || entries[0].Title != "Add release command" || entries[0].IssueType != "feature" {
+		t.Errorf("first entry = %+v, want gt-mr-2 (sorted by merge time)", entries[0])
+	}
+
+	if got := InferBump(Version{1, 0, 0}, entries); got != BumpMinor {
+		t.Errorf("InferBump with feature = %s, want minor", got)
+	}
+	if got := InferBump(Version{1, 0, 0}, entries[1:]); got != BumpPatch {
+		t.Errorf("InferBump with bug fix = %s, want patch", got)
+	}
+	breaking := []Entry{{Breaking: true}}
+	if got := InferBump(Version{1, 0, 0}, breaking); got != BumpMajor {
+		t.Errorf("InferBump breaking = %s, want major", got)
+	}
+	if got := InferBump(Version{0, 3, 0}, breaking); got != BumpMinor {
+		t.Errorf("InferBump breaking pre-1.0 = %s, want minor", got)
+	}
+
+	notes := Notes("v1.1.0", entries)
+	if !strings.Contains(notes, "## v1.1.0") || !strings.Contains(notes, "- Fix crash on startup (gt-1)") {
+		t.Errorf("unexpected notes:\n%s", notes)
+	}
+}
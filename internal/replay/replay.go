@@ -0,0 +1,351 @@
+// Package replay records the bd and git subprocesses a gt command runs,
+// with their output, and replays them later without the original town.
+//
+// gt --record <file> (or GT_RECORD=<file>) writes a recording: a header
+// with the command line and a snapshot of the town's registry files, then
+// one line per subprocess call. gt replay <file> rebuilds a stub town from
+// the header and re-runs the command with GT_REPLAY=<file>, answering each
+// subprocess from the recording instead of running it. A user can attach a
+// recording to a bug report ("gt blocked shows the wrong count") and a
+// maintainer can reproduce it, or turn it into a test.
+//
+// Paths under the town root are stored as $TOWN so recordings replay in
+// any directory.
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// RecordEnv names the file to record to.
+	RecordEnv = "GT_RECORD"
+
+	// ReplayEnv names the recording to replay from.
+	ReplayEnv = "GT_REPLAY"
+
+	// TownVar stands in for the town root in recorded paths.
+	TownVar = "$TOWN"
+)
+
+// snapshotFiles are the town files a recording carries so replay can
+// rebuild enough of the town for workspace discovery and rig lookup.
+var snapshotFiles = []string{
+	"mayor/town.json",
+	"mayor/rigs.json",
+	".beads/routes.jsonl",
+}
+
+// Header describes the recorded command. It is the recording's first line.
+type Header struct {
+	Version   int               `json:"version"`
+	CreatedAt time.Time         `json:"created_at"`
+	GTVersion string            `json:"gt_version,omitempty"`
+	Args      []string          `json:"args"`          // gt arguments, without the program name
+	Dir       string            `json:"dir,omitempty"` // Working directory
+	Files     map[string]string `json:"files,omitempty"`
+}
+
+// Call is one recorded subprocess invocation.
+type Call struct {
+	Seq      int      `json:"seq"`
+	Name     string   `json:"name"`
+	Args     []string `json:"args"`
+	Dir      string   `json:"dir,omitempty"`
+	Stdout   string   `json:"stdout,omitempty"`
+	Stderr   string   `json:"stderr,omitempty"`
+	ExitCode int      `json:"exit_code,omitempty"`
+	Err      string   `json:"error,omitempty"` // Failure to start, e.g. not installed
+	Millis   int64    `json:"ms,omitempty"`
+}
+
+// Recording is a parsed recording file.
+type Recording struct {
+	Header Header
+	Calls  []Call
+}
+
+// ExitError reproduces a recorded non-zero exit.
+type ExitError struct {
+	Code   int
+	Stderr string
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// ExitCode mirrors exec.ExitError.
+func (e *ExitError) ExitCode() int { return e.Code }
+
+var (
+	mu       sync.Mutex
+	townRoot string
+	out      *os.File // Recording being written
+	seq      int
+	pending  map[string][]Call // Replay calls by key, in order
+)
+
+// Init starts recording or replaying according to the environment. Call
+// it once per process, after the town root is known. The environment
+// variable is cleared so gt processes started from this one don't write to
+// or read from the same recording.
+func Init(root string, args []string, gtVersion string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	townRoot = root
+
+	if path := os.Getenv(ReplayEnv); path != "" {
+		_ = os.Unsetenv(ReplayEnv)
+		rec, err := Load(path)
+		if err != nil {
+			return err
+		}
+		pending = make(map[string][]Call)
+		for _, c := range rec.Calls {
+			k := key(c.Name, c.Args)
+			pending[k] = append(pending[k], c)
+		}
+		return nil
+	}
+
+	if path := os.Getenv(RecordEnv); path != "" {
+		_ = os.Unsetenv(RecordEnv)
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600) //nolint:gosec // G304: path is chosen by the user
+		if err != nil {
+			return fmt.Errorf("opening recording: %w", err)
+		}
+		cwd, _ := os.Getwd()
+		h := Header{
+			Version:   1,
+			CreatedAt: time.Now().UTC(),
+			GTVersion: gtVersion,
+			Args:      args,
+			Dir:       normalize(cwd),
+			Files:     snapshot(root),
+		}
+		if err := json.NewEncoder(f).Encode(h); err != nil {
+			_ = f.Close()
+			return err
+		}
+		out = f
+	}
+	return nil
+}
+
+// IsRecording reports whether subprocess calls are being recorded.
+func IsRecording() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return out != nil
+}
+
+// IsReplaying reports whether subprocess calls are answered from a recording.
+func IsReplaying() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return pending != nil
+}
+
+// Run runs cmd like cmd.Run, recording the call when recording and
+// answering it from the recording when replaying. cmd.Stdout and
+// cmd.Stderr receive the output either way.
+func Run(cmd *exec.Cmd) error {
+	mu.Lock()
+	recording, replaying := out != nil, pending != nil
+	mu.Unlock()
+
+	switch {
+	case replaying:
+		return replayCall(cmd)
+	case recording:
+		return recordCall(cmd)
+	}
+	return cmd.Run()
+}
+
+func recordCall(cmd *exec.Cmd) error {
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = tee(cmd.Stdout, &stdout)
+	cmd.Stderr = tee(cmd.Stderr, &stderr)
+
+	start := time.Now()
+	err := cmd.Run()
+	c := Call{
+		Name:   filepath.Base(cmd.Path),
+		Args:   normalizeAll(cmd.Args[1:]),
+		Dir:    normalize(cmd.Dir),
+		Stdout: stdout.String(),
+		Stderr: stderr.String(),
+		Millis: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			c.ExitCode = exitErr.ExitCode()
+		} else {
+			c.Err = err.Error()
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if out != nil {
+		seq++
+		c.Seq = seq
+		_ = json.NewEncoder(out).Encode(c)
+	}
+	return err
+}
+
+func replayCall(cmd *exec.Cmd) error {
+	name := filepath.Base(cmd.Path)
+	args := normalizeAll(cmd.Args[1:])
+	k := key(name, args)
+
+	mu.Lock()
+	calls := pending[k]
+	var c Call
+	found := len(calls) > 0
+	if found {
+		c = calls[0]
+		// The last matching call answers any further repeats.
+		if len(calls) > 1 {
+			pending[k] = calls[1:]
+		}
+	}
+	mu.Unlock()
+
+	if !found {
+		return fmt.Errorf("replay: no recorded call for %s %s", name, strings.Join(args, " "))
+	}
+	if cmd.Stdout != nil {
+		_, _ = io.WriteString(cmd.Stdout, c.Stdout)
+	}
+	if cmd.Stderr != nil {
+		_, _ = io.WriteString(cmd.Stderr, c.Stderr)
+	}
+	switch {
+	case c.Err != "":
+		if strings.Contains(c.Err, exec.ErrNotFound.Error()) {
+			return &exec.Error{Name: name, Err: exec.ErrNotFound}
+		}
+		return fmt.Errorf("%s", c.Err)
+	case c.ExitCode != 0:
+		return &ExitError{Code: c.ExitCode, Stderr: c.Stderr}
+	}
+	return nil
+}
+
+// Load parses a recording file.
+func Load(path string) (*Recording, error) {
+	f, err := os.Open(path) //nolint:gosec // G304: path is chosen by the user
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var rec Recording
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for line := 0; scanner.Scan(); line++ {
+		if line == 0 {
+			if err := json.Unmarshal(scanner.Bytes(), &rec.Header); err != nil {
+				return nil, fmt.Errorf("parsing recording header: %w", err)
+			}
+			continue
+		}
+		var c Call
+		if err := json.Unmarshal(scanner.Bytes(), &c); err != nil {
+			return nil, fmt.Errorf("parsing recording line %d: %w", line+1, err)
+		}
+		rec.Calls = append(rec.Calls, c)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if rec.Header.Version == 0 {
+		return nil, fmt.Errorf("%s is not a gt recording", path)
+	}
+	return &rec, nil
+}
+
+// Stage rebuilds a stub town for rec under dir from its file snapshot and
+// returns the directory the command should run in.
+func Stage(rec *Recording, dir string) (string, error) {
+	for name, content := range rec.Header.Files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			return "", err
+		}
+	}
+	cwd := dir
+	if rel, ok := strings.CutPrefix(rec.Header.Dir, TownVar); ok {
+		cwd = filepath.Join(dir, filepath.FromSlash(rel))
+	}
+	if err := os.MkdirAll(cwd, 0755); err != nil {
+		return "", err
+	}
+	return cwd, nil
+}
+
+// snapshot reads the town's registry files, plus each rig's config.
+func snapshot(root string) map[string]string {
+	if root == "" {
+		return nil
+	}
+	files := make(map[string]string)
+	names := append([]string{}, snapshotFiles...)
+	if matches, err := filepath.Glob(filepath.Join(root, "*", "config.json")); err == nil {
+		for _, m := range matches {
+			if rel, err := filepath.Rel(root, m); err == nil {
+				names = append(names, filepath.ToSlash(rel))
+			}
+		}
+	}
+	for _, name := range names {
+		if data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(name))); err == nil {
+			files[name] = string(data)
+		}
+	}
+	return files
+}
+
+func tee(w io.Writer, buf *bytes.Buffer) io.Writer {
+	if w == nil {
+		return buf
+	}
+	return io.MultiWriter(w, buf)
+}
+
+func key(name string, args []string) string {
+	return name + "\x00" + strings.Join(args, "\x00")
+}
+
+// normalize replaces the town root in s with $TOWN.
+func normalize(s string) string {
+	if townRoot == "" || s == "" {
+		return s
+	}
+	return strings.ReplaceAll(s, townRoot, TownVar)
+}
+
+func normalizeAll(args []string) []string {
+	out := make([]string, len(args))
+	for i, a := range args {
+		out[i] = normalize(a)
+	}
+	return out
+}
@@ -0,0 +1,122 @@
+package replay
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// reset returns the package to pass-through mode.
+func reset(t *testing.T) {
+	t.Helper()
+	mu.Lock()
+	defer mu.Unlock()
+	if out != nil {
+		_ = out.Close()
+	}
+	out, pending, townRoot, seq = nil, nil, "", 0
+}
+
+func shell(dir, script string) (*exec.Cmd, *bytes.Buffer, *bytes.Buffer) {
+	cmd := exec.Command("sh", "-c", script)
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	return cmd, &stdout, &stderr
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	t.Cleanup(func() { reset(t) })
+
+	town := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(town, "mayor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(town, "mayor", "rigs.json"), []byte(`{"rigs": {}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	marker := filepath.Join(town, "ran")
+	script := "touch ran; echo found 3 blocked; echo warning >&2; exit 2"
+	path := filepath.Join(t.TempDir(), "blocked.rec")
+
+	// Record.
+	t.Setenv(RecordEnv, path)
+	if err := Init(town, []string{"blocked"}, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if os.Getenv(RecordEnv) != "" {
+		t.Error("Init left GT_RECORD set for child processes")
+	}
+	cmd, stdout, _ := shell(town, script)
+	if err := Run(cmd); err == nil {
+		t.Fatal("recorded call should fail with exit 2")
+	}
+	if stdout.String() != "found 3 blocked\n" {
+		t.Errorf("recording swallowed stdout: %q", stdout.String())
+	}
+	reset(t)
+
+	rec, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.Calls) != 1 || rec.Header.Args[0] != "blocked" || rec.Header.Files["mayor/rigs.json"] == "" {
+		t.Fatalf("recording = %+v", rec)
+	}
+	if c := rec.Calls[0]; c.Name != "sh" || c.Dir != TownVar || c.ExitCode != 2 || c.Stderr != "warning\n" {
+		t.Errorf("recorded call = %+v", c)
+	}
+
+	// Replay in a different town.
+	if err := os.Remove(marker); err != nil {
+		t.Fatal(err)
+	}
+	stub := t.TempDir()
+	dir, err := Stage(rec, stub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(stub, "mayor", "rigs.json")); err != nil {
+		t.Error("Stage didn't restore mayor/rigs.json")
+	}
+	t.Setenv(ReplayEnv, path)
+	if err := Init(stub, nil, "test"); err != nil {
+		t.Fatal(err)
+	}
+	cmd, stdout, stderr := shell(dir, script)
+	err = Run(cmd)
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 2 {
+		t.Errorf("replayed error = %v, want exit 2", err)
+	}
+	if stdout.String() != "found 3 blocked\n" || stderr.String() != "warning\n" {
+		t.Errorf("replayed output = %q, %q", stdout.String(), stderr.String())
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("replay ran the command")
+	}
+
+	cmd, _, _ = shell(dir, "echo something else")
+	if err := Run(cmd); err == nil || !strings.Contains(err.Error(), "no recorded call") {
+		t.Errorf("unrecorded call error = %v", err)
+	}
+}
+
+func TestRunPassesThrough(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	reset(t)
+	cmd, stdout, _ := shell("", "echo hi")
+	if err := Run(cmd); err != nil || stdout.String() != "hi\n" {
+		t.Errorf("Run() = %v, %q", err, stdout.String())
+	}
+}
@@ -0,0 +1,483 @@
+// Package retention enforces retention policies on the files a town
+// accumulates: agent and daemon logs, the event log, beads audit logs, and
+// agent session recordings.
+//
+// Live files are rotated once they grow past a size: their contents are
+// copied to a timestamped sibling (gzipped by default) and the live file is
+// truncated in place, so writers holding it open with O_APPEND keep working.
+// Rotated files are removed once they pass a maximum age or a count limit.
+// Recordings aren't rotated; old ones are removed and each session directory
+// is kept under a size cap.
+package retention
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/diskusage"
+)
+
+// Classes of file under retention.
+const (
+	ClassLogs       = "logs"
+	ClassEvents     = "events"
+	ClassAudit      = "audit"
+	ClassRecordings = "recordings"
+
+	// Blob store classes, pruned by blobstore.Prune.
+	ClassBackups     = "backups"
+	ClassAttachments = "attachments"
+)
+
+// Policy is a resolved config.RetentionPolicy. Zero fields are unbounded.
+type Policy struct {
+	MaxAge   time.Duration
+	MaxSize  int64
+	Keep     int
+	Compress bool
+}
+
+// Policies resolves a town's retention config, filling unset classes and
+// fields from config.DefaultRetentionConfig.
+func Policies(cfg *config.RetentionConfig) (map[string]Policy, error) {
+	defaults := config.DefaultRetentionConfig()
+	if cfg == nil {
+		cfg = defaults
+	}
+	classes := []struct {
+		name          string
+		set, fallback *config.RetentionPolicy
+	}{
+		{ClassLogs, cfg.Logs, defaults.Logs},
+		{ClassEvents, cfg.Events, defaults.Events},
+		{ClassAudit, cfg.Audit, defaults.Audit},
+		{ClassRecordings, cfg.Recordings, defaults.Recordings},
+		{ClassBackups, cfg.Backups, defaults.Backups},
+		{ClassAttachments, cfg.Attachments, defaults.Attachments},
+	}
+	policies := make(map[string]Policy, len(classes))
+	for _, c := range classes {
+		p, err := resolve(c.set, c.fallback)
+		if err != nil {
+			return nil, fmt.Errorf("retention.%s: %w", c.name, err)
+		}
+		policies[c.name] = p
+	}
+	return policies, nil
+}
+
+func resolve(set, fallback *config.RetentionPolicy) (Policy, error) {
+	if set == nil {
+		set = fallback
+	}
+	maxAge, maxSize := set.MaxAge, set.MaxSize
+	if maxAge == "" {
+		maxAge = fallback.MaxAge
+	}
+	if maxSize == "" {
+		maxSize = fallback.MaxSize
+	}
+	p := Policy{Keep: set.Keep, Compress: set.Compress == nil || *set.Compress}
+	var err error
+	if p.MaxAge, err = ParseAge(maxAge); err != nil {
+		return p, fmt.Errorf("max_age: %w", err)
+	}
+	if maxSize == "" {
+		return p, nil
+	}
+	if p.MaxSize, err = diskusage.ParseSize(maxSize); err != nil {
+		return p, fmt.Errorf("max_size: %w", err)
+	}
+	return p, nil
+}
+
+// Interval returns how often the daemon enforces retention.
+func Interval(cfg *config.RetentionConfig) time.Duration {
+	if cfg == nil {
+		cfg = config.DefaultRetentionConfig()
+	}
+	return config.ParseDurationOrDefault(cfg.Interval, time.Hour)
+}
+
+// ParseAge parses a duration that may use a "d" (days) suffix, e.g. "14d".
+// "0" or "" means unbounded.
+func ParseAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "0" {
+		return 0, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q (want e.g. 14d, 12h)", s)
+	}
+	return d, nil
+}
+
+// Target is a live file under a retention class. LockPath, if set, is the
+// flock its writers hold while appending.
+type Target struct {
+	Class    string
+	Path     string
+	LockPath string
+}
+
+// Targets returns the live files retention covers: town logs, daemon logs,
+// and the event log (unless rigsOnly), and each rig's logs and audit logs.
+func Targets(townRoot string, rigPaths []string, rigsOnly bool) []Target {
+	var targets []Target
+	addGlob := func(class, pattern string) {
+		matches, _ := filepath.Glob(pattern)
+		for _, m := range matches {
+			targets = append(targets, Target{Class: class, Path: m})
+		}
+	}
+	addFile := func(class, path, lockPath string) {
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			targets = append(targets, Target{Class: class, Path: path, LockPath: lockPath})
+		}
+	}
+
+	if !rigsOnly {
+		addGlob(ClassLogs, filepath.Join(townRoot, "logs", "*.log"))
+		addGlob(ClassLogs, filepath.Join(townRoot, "daemon", "*.log"))
+		for _, name := range []string{".events.jsonl", ".feed.jsonl"} {
+			path := filepath.Join(townRoot, name)
+			addFile(ClassEvents, path, path+".lock")
+		}
+		addFile(ClassAudit, filepath.Join(townRoot, ".beads", "audit.log"), "")
+	}
+	for _, rigPath := range rigPaths {
+		addGlob(ClassLogs, filepath.Join(rigPath, "logs", "*.log"))
+		addFile(ClassAudit, filepath.Join(rigPath, ".beads", "audit.log"), "")
+		addFile(ClassAudit, filepath.Join(rigPath, "mayor", "rig", ".beads", "audit.log"), "")
+	}
+	return targets
+}
+
+// Action is something retention did, or would do in a dry run.
+type Action struct {
+	Class string `json:"class"`
+	Op    string `json:"op"` // rotate, compress, remove
+	Path  string `json:"path"`
+	Bytes int64  `json:"bytes"` // bytes moved out of the live file, or freed
+}
+
+// rotatedTimeFormat stamps rotated files; it sorts chronologically.
+const rotatedTimeFormat = "20060102-150405"
+
+// Rotate moves a live file's contents to a timestamped sibling once it is
+// larger than the policy's MaxSize, then truncates it. Returns nil when the
+// file is within bounds.
+func Rotate(t Target, p Policy, now time.Time, dryRun bool) (*Action, error) {
+	info, err := os.Stat(t.Path)
+	if err != nil || p.MaxSize <= 0 || info.Size() <= p.MaxSize {
+		return nil, nil
+	}
+	dest := t.Path + "." + now.UTC().Format(rotatedTimeFormat)
+	if p.Compress {
+		dest += ".gz"
+	}
+	action := &Action{Class: t.Class, Op: "rotate", Path: dest, Bytes: info.Size()}
+	if dryRun {
+		return action, nil
+	}
+	if _, err := os.Stat(dest); err == nil {
+		return nil, fmt.Errorf("%s already exists", dest)
+	}
+
+	if t.LockPath != "" {
+		fl := flock.New(t.LockPath)
+		if err := fl.Lock(); err != nil {
+			return nil, fmt.Errorf("locking %s: %w", t.Path, err)
+		}
+		defer fl.Unlock() //nolint:errcheck // best-effort unlock
+	}
+	n, err := copyFile(t.Path, dest, p.Compress, info.Mode().Perm())
+	if err != nil {
+		_ = os.Remove(dest)
+		return nil, err
+	}
+	// Writers append with O_APPEND, so truncating in place is safe for them.
+	// Without a lock, lines written between the copy and here are lost.
+	if err := os.Truncate(t.Path, 0); err != nil {
+		return nil, fmt.Errorf("truncating %s: %w", t.Path, err)
+	}
+	action.Bytes = n
+	return action, nil
+}
+
+// copyFile copies src to dest, gzipping if compress, and returns the bytes read.
+func copyFile(src, dest string, compress bool, perm os.FileMode) (int64, error) {
+	in, err := os.Open(src) //nolint:gosec // G304: retention targets are town files
+	if err != nil {
+		return 0, err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_EXCL|os.O_WRONLY, perm) //nolint:gosec // G304: retention targets are town files
+	if err != nil {
+		return 0, err
+	}
+	var w io.Writer = out
+	var gz *gzip.Writer
+	if compress {
+		gz = gzip.NewWriter(out)
+		w = gz
+	}
+	n, err := io.Copy(w, in)
+	if err == nil && gz != nil {
+		err = gz.Close()
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return n, fmt.Errorf("copying %s to %s: %w", src, dest, err)
+	}
+	return n, nil
+}
+
+// Rotated returns the rotated siblings of a live file (town.log.1,
+// town.log.20260102-150405.gz), oldest first.
+func Rotated(path string) []string {
+	dir, base := filepath.Split(path)
+	entries, err := os.ReadDir(filepath.Clean(dir))
+	if err != nil {
+		return nil
+	}
+	type rotated struct {
+		path string
+		mod  time.Time
+	}
+	var found []rotated
+	for _, e := range entries {
+		suffix, ok := strings.CutPrefix(e.Name(), base+".")
+		if !ok || suffix == "" || suffix == "lock" || strings.HasSuffix(suffix, ".tmp") || !e.Type().IsRegular() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		found = append(found, rotated{filepath.Join(dir, e.Name()), info.ModTime()})
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].mod.Before(found[j].mod) })
+	paths := make([]string, len(found))
+	for i, r := range found {
+		paths[i] = r.path
+	}
+	return paths
+}
+
+// Prune removes a live file's rotated siblings that are older than MaxAge
+// or beyond the newest Keep, and compresses the rest if the policy says so.
+func Prune(t Target, p Policy, now time.Time, dryRun bool) ([]Action, error) {
+	rotated := Rotated(t.Path)
+	var actions []Action
+	var errs []string
+	for i, path := range rotated {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		expired := p.MaxAge > 0 && now.Sub(info.ModTime()) > p.MaxAge
+		excess := p.Keep > 0 && i < len(rotated)-p.Keep
+		if expired || excess {
+			if !dryRun {
+				if err := os.Remove(path); err != nil {
+					errs = append(errs, err.Error())
+					continue
+				}
+			}
+			actions = append(actions, Action{Class: t.Class, Op: "remove", Path: path, Bytes: info.Size()})
+			continue
+		}
+		if p.Compress && !strings.HasSuffix(path, ".gz") {
+			saved := info.Size()
+			if !dryRun {
+				if saved, err = compressInPlace(path, info); err != nil {
+					errs = append(errs, err.Error())
+					continue
+				}
+			}
+			actions = append(actions, Action{Class: t.Class, Op: "compress", Path: path, Bytes: saved})
+		}
+	}
+	if len(errs) > 0 {
+		return actions, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return actions, nil
+}
+
+// compressInPlace replaces path with path.gz, keeping its mtime so age
+// limits still apply, and returns the bytes saved.
+func compressInPlace(path string, info os.FileInfo) (int64, error) {
+	dest := path + ".gz"
+	if _, err := copyFile(path, dest, true, info.Mode().Perm()); err != nil {
+		_ = os.Remove(dest)
+		return 0, err
+	}
+	_ = os.Chtimes(dest, info.ModTime(), info.ModTime())
+	if err := os.Remove(path); err != nil {
+		return 0, err
+	}
+	gz, err := os.Stat(dest)
+	if err != nil {
+		return 0, nil
+	}
+	return max(info.Size()-gz.Size(), 0), nil
+}
+
+// PruneRecordings removes transcripts in dirs older than MaxAge, then the
+// oldest in each directory until it is under MaxSize. The newest transcript
+// in a directory is never removed for size, so a live session keeps its own.
+func PruneRecordings(dirs []string, p Policy, now time.Time, dryRun bool) ([]Action, error) {
+	var actions []Action
+	var errs []string
+	remove := func(path string, size int64) bool {
+		if !dryRun {
+			if err := os.Remove(path); err != nil {
+				errs = append(errs, err.Error())
+				return false
+			}
+		}
+		actions = append(actions, Action{Class: ClassRecordings, Op: "remove", Path: path, Bytes: size})
+		return true
+	}
+
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		type transcript struct {
+			path string
+			info os.FileInfo
+		}
+		var kept []transcript
+		var total int64
+		for _, e := range entries {
+			if !e.Type().IsRegular() || !diskusage.IsTranscript(e.Name()) {
+				continue
+			}
+			info, err := e.Info()
+			if err != nil {
+				continue
+			}
+			path := filepath.Join(dir, e.Name())
+			if p.MaxAge > 0 && now.Sub(info.ModTime()) > p.MaxAge && remove(path, info.Size()) {
+				continue
+			}
+			kept = append(kept, transcript{path, info})
+			total += info.Size()
+		}
+		if p.MaxSize <= 0 {
+			continue
+		}
+		sort.Slice(kept, func(i, j int) bool { return kept[i].info.ModTime().Before(kept[j].info.ModTime()) })
+		for i := 0; total > p.MaxSize && i < len(kept)-1; i++ {
+			if remove(kept[i].path, kept[i].info.Size()) {
+				total -= kept[i].info.Size()
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return actions, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return actions, nil
+}
+
+// Options scopes an Enforce run.
+type Options struct {
+	TownRoot    string
+	RigPaths    []string
+	ProjectsDir string // agent transcript root; "" skips recordings
+	RigsOnly    bool   // leave town-level files alone
+	DryRun      bool
+}
+
+// Enforce applies a town's retention policies. It keeps going past errors
+// and returns them joined with everything it did.
+func Enforce(opts Options, cfg *config.RetentionConfig) ([]Action, error) {
+	policies, err := Policies(cfg)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var actions []Action
+	var errs []string
+	for _, t := range Targets(opts.TownRoot, opts.RigPaths, opts.RigsOnly) {
+		p := policies[t.Class]
+		a, err := Rotate(t, p, now, opts.DryRun)
+		if err != nil {
+			errs = append(errs, err.Error())
+		} else if a != nil {
+			actions = append(actions, *a)
+		}
+		pruned, err := Prune(t, p, now, opts.DryRun)
+		actions = append(actions, pruned...)
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	// Transcripts are shared with every other project on the machine; only
+	// directories whose sessions all ran under a root are pruned.
+	if opts.ProjectsDir != "" {
+		roots := []string{opts.TownRoot}
+		if opts.RigsOnly {
+			roots = opts.RigPaths
+		}
+		var dirs []string
+		for _, root := range roots {
+			dirs = append(dirs, diskusage.RecordingDirs(opts.ProjectsDir, root)...)
+		}
+		pruned, err := PruneRecordings(dirs, policies[ClassRecordings], now, opts.DryRun)
+		actions = append(actions, pruned...)
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return actions, fmt.Errorf("retention: %s", strings.Join(errs, "; "))
+	}
+	return actions, nil
+}
+
+// Freed returns the bytes actions free on disk. Rotation moves bytes rather
+// than freeing them, so it isn't counted.
+func Freed(actions []Action) int64 {
+	var total int64
+	for _, a := range actions {
+		if a.Op != "rotate" {
+			total += a.Bytes
+		}
+	}
+	return total
+}
+
+// LoadConfig returns a town's retention config from its settings, or nil
+// (defaults) if it has none.
+func LoadConfig(townRoot string) *config.RetentionConfig {
+	settings, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(townRoot))
+	if err != nil {
+		return nil
+	}
+	return settings.Retention
+}
@@ -0,0 +1,251 @@
+package retention
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func writeFile(t *testing.T, path, content string, mtime time.Time) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !mtime.IsZero() {
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+		err  bool
+	}{
+		{"", 0, false},
+		{"0", 0, false},
+		{"14d", 14 * 24 * time.Hour, false},
+		{"12h", 12 * time.Hour, false},
+		{"xd", 0, true},
+		{"-1h", 0, true},
+		{"soon", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseAge(tt.in)
+		if (err != nil) != tt.err {
+			t.Errorf("ParseAge(%q) error = %v, want error %v", tt.in, err, tt.err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseAge(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPolicies(t *testing.T) {
+	off := false
+	policies, err := Policies(&config.RetentionConfig{
+		Logs: &config.RetentionPolicy{MaxSize: "1M", Keep: 3, Compress: &off},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logs := policies[ClassLogs]
+	if logs.MaxSize != 1<<20 || logs.Keep != 3 || logs.Compress || logs.MaxAge != 14*24*time.Hour {
+		t.Errorf("logs policy = %+v, want 1M, keep 3, uncompressed, default 14d", logs)
+	}
+	if events := policies[ClassEvents]; events.MaxSize != 100<<20 || !events.Compress {
+		t.Errorf("events policy = %+v, want defaults", events)
+	}
+
+	if _, err := Policies(&config.RetentionConfig{Audit: &config.RetentionPolicy{MaxAge: "forever"}}); err == nil {
+		t.Error("Policies accepted an invalid max_age")
+	}
+}
+
+func TestRotate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "town.log")
+	writeFile(t, path, strings.Repeat("line\n", 100), time.Time{})
+	now := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	target := Target{Class: ClassLogs, Path: path, LockPath: path + ".lock"}
+
+	if a, err := Rotate(target, Policy{MaxSize: 1000, Compress: true}, now, false); err != nil || a != nil {
+		t.Fatalf("Rotate under MaxSize = %+v, %v; want nothing", a, err)
+	}
+
+	a, err := Rotate(target, Policy{MaxSize: 100, Compress: true}, now, true)
+	if err != nil || a == nil || exists(a.Path) {
+		t.Fatalf("dry-run Rotate = %+v, %v; want an action and no file", a, err)
+	}
+
+	a, err = Rotate(target, Policy{MaxSize: 100, Compress: true}, now, false)
+	if err != nil || a == nil {
+		t.Fatalf("Rotate = %+v, %v", a, err)
+	}
+	if want := path + ".20260304-050607.gz"; a.Path != want || a.Bytes != 500 {
+		t.Errorf("Rotate action = %+v, want %s with 500 bytes", a, want)
+	}
+	if info, _ := os.Stat(path); info.Size() != 0 {
+		t.Errorf("live file not truncated: %d bytes", info.Size())
+	}
+	f, err := os.Open(a.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := io.ReadAll(gz); len(data) != 500 {
+		t.Errorf("rotated file holds %d bytes, want 500", len(data))
+	}
+}
+
+func TestPrune(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "daemon.log")
+	now := time.Now()
+	writeFile(t, path, "live", time.Time{})
+	writeFile(t, path+".lock", "", now.Add(-100*24*time.Hour))
+	writeFile(t, path+".20260101-000000.gz", "ancient", now.Add(-40*24*time.Hour))
+	writeFile(t, path+".20260301-000000.gz", "older", now.Add(-3*24*time.Hour))
+	writeFile(t, path+".20260302-000000.gz", "old", now.Add(-2*24*time.Hour))
+	writeFile(t, path+".1", strings.Repeat("x", 1000), now.Add(-time.Hour))
+
+	if got := Rotated(path); len(got) != 4 || got[3] != path+".1" {
+		t.Fatalf("Rotated = %v, want 4 files, newest last", got)
+	}
+
+	actions, err := Prune(Target{Class: ClassLogs, Path: path}, Policy{MaxAge: 30 * 24 * time.Hour, Keep: 2, Compress: true}, now, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ops := make(map[string]string)
+	for _, a := range actions {
+		ops[filepath.Base(a.Path)] = a.Op
+	}
+	want := map[string]string{
+		"daemon.log.20260101-000000.gz": "remove",   // past max age
+		"daemon.log.20260301-000000.gz": "remove",   // beyond keep
+		"daemon.log.1":                  "compress", // legacy rotation
+	}
+	for name, op := range want {
+		if ops[name] != op {
+			t.Errorf("%s: op %q, want %q (actions %+v)", name, ops[name], op, actions)
+		}
+	}
+	if !exists(path) || !exists(path+".lock") || !exists(path+".20260302-000000.gz") || !exists(path+".1.gz") || exists(path+".1") {
+		t.Error("Prune touched the wrong files")
+	}
+}
+
+func TestPruneRecordings(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	writeFile(t, filepath.Join(dir, "expired.jsonl"), "x", now.Add(-60*24*time.Hour))
+	writeFile(t, filepath.Join(dir, "a.jsonl"), strings.Repeat("x", 100), now.Add(-3*time.Hour))
+	writeFile(t, filepath.Join(dir, "b.jsonl"), strings.Repeat("x", 100), now.Add(-2*time.Hour))
+	writeFile(t, filepath.Join(dir, "live.jsonl"), strings.Repeat("x", 500), now)
+	writeFile(t, filepath.Join(dir, "notes.txt"), "keep", now.Add(-60*24*time.Hour))
+
+	actions, err := PruneRecordings([]string{dir}, Policy{MaxAge: 30 * 24 * time.Hour, MaxSize: 150}, now, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actions) != 3 {
+		t.Fatalf("actions = %+v, want expired, a, and b removed", actions)
+	}
+	if !exists(filepath.Join(dir, "live.jsonl")) || !exists(filepath.Join(dir, "notes.txt")) {
+		t.Error("PruneRecordings removed the live transcript or a non-transcript")
+	}
+}
+
+func TestEnforceDryRun(t *testing.T) {
+	town := t.TempDir()
+	rigPath := filepath.Join(town, "gastown")
+	events := filepath.Join(town, ".events.jsonl")
+	audit := filepath.Join(rigPath, ".beads", "audit.log")
+	writeFile(t, events, strings.Repeat("{}\n", 100), time.Time{})
+	writeFile(t, audit, strings.Repeat("{}\n", 100), time.Time{})
+	writeFile(t, filepath.Join(town, "logs", "town.log.1"), "x", time.Now().Add(-30*24*time.Hour))
+	writeFile(t, filepath.Join(town, "logs", "town.log"), "", time.Time{})
+
+	cfg := &config.RetentionConfig{
+		Events: &config.RetentionPolicy{MaxSize: "100"},
+		Audit:  &config.RetentionPolicy{MaxSize: "100"},
+	}
+	actions, err := Enforce(Options{TownRoot: town, RigPaths: []string{rigPath}, DryRun: true}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ops := make(map[string]int)
+	for _, a := range actions {
+		ops[a.Class+"/"+a.Op]++
+	}
+	if ops["events/rotate"] != 1 || ops["audit/rotate"] != 1 || ops["logs/remove"] != 1 {
+		t.Errorf("actions = %+v", actions)
+	}
+	if info, _ := os.Stat(events); info.Size() != 300 || !exists(filepath.Join(town, "logs", "town.log.1")) {
+		t.Error("dry run changed files")
+	}
+
+	actions, err = Enforce(Options{TownRoot: town, RigPaths: []string{rigPath}, RigsOnly: true, DryRun: true}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actions) != 1 || actions[0].Class != ClassAudit {
+		t.Errorf("rigs-only actions = %+v, want only the rig's audit log", actions)
+	}
+}
+
+// transcriptDir returns where an agent runtime keeps transcripts for cwd.
+func transcriptDir(projects, cwd string) string {
+	return filepath.Join(projects, strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '-'
+	}, cwd))
+}
+
+func TestEnforceRecordings_SkipsOtherProjects(t *testing.T) {
+	parent := t.TempDir()
+	town := filepath.Join(parent, "gt")
+	projects := t.TempDir()
+	old := time.Now().Add(-60 * 24 * time.Hour)
+	for _, cwd := range []string{filepath.Join(town, "mayor"), filepath.Join(parent, "gt-tools"), filepath.Join(parent, "gt.old")} {
+		writeFile(t, filepath.Join(transcriptDir(projects, cwd), "s.jsonl"), `{"cwd":"`+cwd+`"}`+"\n", old)
+	}
+
+	cfg := &config.RetentionConfig{Recordings: &config.RetentionPolicy{MaxAge: "30d"}}
+	actions, err := Enforce(Options{TownRoot: town, ProjectsDir: projects}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actions) != 1 || actions[0].Path != filepath.Join(transcriptDir(projects, filepath.Join(town, "mayor")), "s.jsonl") {
+		t.Errorf("actions = %+v, want only the town's transcript removed", actions)
+	}
+	for _, sibling := range []string{"gt-tools", "gt.old"} {
+		if !exists(filepath.Join(transcriptDir(projects, filepath.Join(parent, sibling)), "s.jsonl")) {
+			t.Errorf("Enforce removed a transcript of %s, outside the town", sibling)
+		}
+	}
+}
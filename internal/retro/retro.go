@@ -0,0 +1,265 @@
+// Package retro assembles retrospective skeletons for closed convoys,
+// milestones, and incidents: a timeline from the event log, the work and merge requests
+// involved, durations, and the failures encountered along the way.
+//
+// The skeleton collects the facts; the sections for what went well, what
+// went wrong, and action items are left for people and agents to fill in.
+package retro
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+// Kinds of retrospective subject.
+const (
+	KindConvoy    = "convoy"
+	KindMilestone = "milestone"
+	KindIncident  = "incident"
+)
+
+// failureTypes are the event types that count as failures.
+var failureTypes = map[string]bool{
+	events.TypeMergeFailed:    true,
+	events.TypeLandingFailed:  true,
+	events.TypeMRReverted:     true,
+	events.TypePRFailed:       true,
+	events.TypeSessionDeath:   true,
+	events.TypeMassDeath:      true,
+	events.TypeEscalationSent: true,
+}
+
+// Subject is the convoy, milestone, or incident under review.
+type Subject struct {
+	ID         string    `json:"id"`
+	Kind       string    `json:"kind"`
+	Title      string    `json:"title"`
+	Status     string    `json:"status"`
+	Rig        string    `json:"rig,omitempty"` // incidents only
+	Opened     time.Time `json:"opened"`
+	Closed     time.Time `json:"closed,omitempty"`
+	Resolution string    `json:"resolution,omitempty"`
+}
+
+// Item is a piece of work the subject covered: a convoy's or milestone's
+// tracked issue.
+type Item struct {
+	ID       string    `json:"id"`
+	Title    string    `json:"title"`
+	Status   string    `json:"status"`
+	Assignee string    `json:"assignee,omitempty"`
+	Created  time.Time `json:"created,omitempty"`
+	Closed   time.Time `json:"closed,omitempty"`
+}
+
+// MergeRequest is an MR filed for the subject or one of its items.
+type MergeRequest struct {
+	ID          string    `json:"id"`
+	Issue       string    `json:"issue"`
+	Branch      string    `json:"branch"`
+	Status      string    `json:"status"`
+	Merged      bool      `json:"merged"`
+	CloseReason string    `json:"close_reason,omitempty"`
+	Created     time.Time `json:"created,omitempty"`
+	Closed      time.Time `json:"closed,omitempty"`
+}
+
+// Entry is one line of the timeline.
+type Entry struct {
+	At      time.Time `json:"at"`
+	Actor   string    `json:"actor,omitempty"`
+	Type    string    `json:"type"`
+	Text    string    `json:"text"`
+	Failure bool      `json:"failure,omitempty"`
+}
+
+// Retro is an assembled retrospective.
+type Retro struct {
+	Subject       Subject        `json:"subject"`
+	Items         []Item         `json:"items,omitempty"`
+	MergeRequests []MergeRequest `json:"merge_requests,omitempty"`
+	Timeline      []Entry        `json:"timeline"`
+	GeneratedAt   time.Time      `json:"generated_at"`
+}
+
+// Build assembles a retrospective. notes are timeline entries recorded on
+// the subject itself (an incident's timeline); evts is the town's event
+// log, from which the events within the subject's window that mention it,
+// its items, or its MRs are kept. Incident retros also keep events for the
+// incident's rig. Incident open/resolve events are dropped when notes are
+// given, since the incident's own timeline already records them.
+func Build(subject Subject, items []Item, mrs []MergeRequest, notes []Entry, evts []events.Event, now time.Time) *Retro {
+	r := &Retro{Subject: subject, Items: items, MergeRequests: mrs, GeneratedAt: now.UTC()}
+
+	ids := map[string]bool{subject.ID: true}
+	for _, it := range items {
+		ids[it.ID] = true
+	}
+	for _, mr := range mrs {
+		ids[mr.ID] = true
+	}
+	end := subject.Closed
+	if end.IsZero() {
+		end = now
+	}
+
+	r.Timeline = append(r.Timeline, notes...)
+	for _, e := range evts {
+		at := e.Time()
+		if at.Before(subject.Opened) || at.After(end) {
+			continue
+		}
+		if len(notes) > 0 && (e.Type == events.TypeIncidentOpened || e.Type == events.TypeIncidentResolved) {
+			continue
+		}
+		if !e.Mentions(ids) && !(subject.Kind == KindIncident && subject.Rig != "" && e.PayloadString("rig") == subject.Rig) {
+			continue
+		}
+		r.Timeline = append(r.Timeline, Entry{
+			At:      at,
+			Actor:   e.Actor,
+			Type:    e.Type,
+			Text:    events.Describe(e),
+			Failure: failureTypes[e.Type],
+		})
+	}
+	sort.SliceStable(r.Timeline, func(i, j int) bool { return r.Timeline[i].At.Before(r.Timeline[j].At) })
+	return r
+}
+
+// Failures returns the timeline entries that are failures.
+func (r *Retro) Failures() []Entry {
+	var out []Entry
+	for _, e := range r.Timeline {
+		if e.Failure {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// Duration returns how long the subject was open, up to now if it still is.
+func (r *Retro) Duration() time.Duration {
+	end := r.Subject.Closed
+	if end.IsZero() {
+		end = r.GeneratedAt
+	}
+	return end.Sub(r.Subject.Opened)
+}
+
+// Markdown renders the retrospective skeleton.
+func (r *Retro) Markdown() string {
+	var sb strings.Builder
+	s := r.Subject
+	fmt.Fprintf(&sb, "# Retrospective: %s %s: %s\n\n", s.Kind, s.ID, s.Title)
+	fmt.Fprintf(&sb, "- Opened: %s\n", stamp(s.Opened))
+	if !s.Closed.IsZero() {
+		fmt.Fprintf(&sb, "- Closed: %s\n", stamp(s.Closed))
+	} else {
+		fmt.Fprintf(&sb, "- Status: %s (still open)\n", s.Status)
+	}
+	fmt.Fprintf(&sb, "- Duration: %s\n", FormatDuration(r.Duration()))
+	if s.Rig != "" {
+		fmt.Fprintf(&sb, "- Rig: %s\n", s.Rig)
+	}
+	if s.Resolution != "" {
+		fmt.Fprintf(&sb, "- Resolution: %s\n", s.Resolution)
+	}
+	failures := r.Failures()
+	fmt.Fprintf(&sb, "- %d item(s), %d merge request(s), %d failure(s)\n", len(r.Items), len(r.MergeRequests), len(failures))
+
+	if len(r.Items) > 0 {
+		sb.WriteString("\n## Work\n\n")
+		for _, it := range r.Items {
+			fmt.Fprintf(&sb, "- %s [%s] %s", it.ID, it.Status, it.Title)
+			if it.Assignee != "" {
+				fmt.Fprintf(&sb, " (%s)", it.Assignee)
+			}
+			if !it.Created.IsZero() && !it.Closed.IsZero() {
+				fmt.Fprintf(&sb, " — %s", FormatDuration(it.Closed.Sub(it.Created)))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	if len(r.MergeRequests) > 0 {
+		sb.WriteString("\n## Merge requests\n\n")
+		for _, mr := range r.MergeRequests {
+			fmt.Fprintf(&sb, "- %s %s for %s: ", mr.ID, mr.Branch, mr.Issue)
+			switch {
+			case mr.Merged:
+				sb.WriteString("merged")
+			case mr.Status == "closed":
+				sb.WriteString("closed")
+				if mr.CloseReason != "" {
+					fmt.Fprintf(&sb, " (%s)", mr.CloseReason)
+				}
+			default:
+				sb.WriteString(mr.Status)
+			}
+			if !mr.Created.IsZero() && !mr.Closed.IsZero() {
+				fmt.Fprintf(&sb, " after %s in the queue", FormatDuration(mr.Closed.Sub(mr.Created)))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	sb.WriteString("\n## Timeline\n\n")
+	if len(r.Timeline) == 0 {
+		sb.WriteString("_No events recorded._\n")
+	}
+	for _, e := range r.Timeline {
+		marker := ""
+		if e.Failure {
+			marker = "**✗** "
+		}
+		actor := e.Actor
+		if actor == "" {
+			actor = "unknown"
+		}
+		fmt.Fprintf(&sb, "- %s %s%s: %s\n", stamp(e.At), marker, actor, e.Text)
+	}
+
+	if len(failures) > 0 {
+		sb.WriteString("\n## Failures\n\n")
+		for _, e := range failures {
+			fmt.Fprintf(&sb, "- %s %s\n", stamp(e.At), e.Text)
+		}
+	}
+
+	sb.WriteString("\n## What went well\n\n- \n")
+	sb.WriteString("\n## What went wrong\n\n- \n")
+	sb.WriteString("\n## Action items\n\n- \n")
+	return sb.String()
+}
+
+// stamp formats a timeline timestamp.
+func stamp(t time.Time) string {
+	if t.IsZero() {
+		return "unknown"
+	}
+	return t.UTC().Format("2006-01-02 15:04Z")
+}
+
+// FormatDuration renders a duration for a retrospective: "45m", "3h20m", "2d4h".
+func FormatDuration(d time.Duration) string {
+	if d < time.Minute {
+		return "<1m"
+	}
+	d = d.Round(time.Minute)
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	mins := int(d % time.Hour / time.Minute)
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd%dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh%dm", hours, mins)
+	default:
+		return fmt.Sprintf("%dm", mins)
+	}
+}
@@ -0,0 +1,86 @@
+package retro
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+func TestBuild(t *testing.T) {
+	t0 := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) string { return t0.Add(d).Format(time.RFC3339) }
+
+	subject := Subject{ID: "hq-cv1", Kind: KindConvoy, Title: "Auth rework", Status: "closed", Opened: t0, Closed: t0.Add(26 * time.Hour)}
+	items := []Item{{ID: "gt-a", Title: "Cookies", Status: "closed", Assignee: "gastown/polecats/nux", Created: t0, Closed: t0.Add(3*time.Hour + 20*time.Minute)}}
+	mrs := []MergeRequest{{ID: "gt-mr1", Issue: "gt-a", Branch: "polecat/nux/gt-a", Status: "closed", Merged: true, Created: t0.Add(2 * time.Hour), Closed: t0.Add(3 * time.Hour)}}
+	evts := []events.Event{
+		{Timestamp: at(-time.Hour), Type: events.TypeSling, Payload: map[string]interface{}{"bead": "gt-a"}}, // before window
+		{Timestamp: at(time.Minute), Type: events.TypeSling, Actor: "mayor", Payload: map[string]interface{}{"bead": "gt-a", "target": "gastown"}},
+		{Timestamp: at(90 * time.Minute), Type: events.TypeMergeFailed, Actor: "refinery", Payload: map[string]interface{}{"mr": "gt-mr1", "reason": "tests"}},
+		{Timestamp: at(2 * time.Hour), Type: events.TypeMerged, Payload: map[string]interface{}{"mr": "gt-mr1"}},
+		{Timestamp: at(2 * time.Hour), Type: events.TypeSling, Payload: map[string]interface{}{"bead": "gt-zzz"}}, // unrelated
+		{Timestamp: at(30 * time.Hour), Type: events.TypeMerged, Payload: map[string]interface{}{"mr": "gt-mr1"}}, // after window
+	}
+
+	r := Build(subject, items, mrs, nil, evts, t0.Add(48*time.Hour))
+	if len(r.Timeline) != 3 {
+		t.Fatalf("Timeline = %+v, want 3 entries", r.Timeline)
+	}
+	if got := r.Failures(); len(got) != 1 || got[0].Text != "merge failed: gt-mr1 (tests)" {
+		t.Errorf("Failures = %+v", got)
+	}
+
+	md := r.Markdown()
+	for _, want := range []string{
+		"# Retrospective: convoy hq-cv1: Auth rework",
+		"- Duration: 1d2h",
+		"- 1 item(s), 1 merge request(s), 1 failure(s)",
+		"- gt-a [closed] Cookies (gastown/polecats/nux) — 3h20m",
+		"- gt-mr1 polecat/nux/gt-a for gt-a: merged after 1h0m in the queue",
+		"- 2026-10-01 09:01Z mayor: slung gt-a to gastown",
+		"**✗** refinery: merge failed: gt-mr1 (tests)",
+		"## Action items",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown missing %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestBuild_IncidentRigEvents(t *testing.T) {
+	t0 := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	subject := Subject{ID: "gt-inc", Kind: KindIncident, Title: "Main red", Rig: "gastown", Opened: t0}
+	notes := []Entry{{At: t0, Actor: "witness", Text: "Incident declared: Main red"}}
+	evts := []events.Event{
+		{Timestamp: t0.Format(time.RFC3339), Type: events.TypeIncidentOpened, Payload: map[string]interface{}{"incident": "gt-inc", "rig": "gastown"}},
+		{Timestamp: t0.Add(time.Minute).Format(time.RFC3339), Type: events.TypeSessionDeath, Payload: map[string]interface{}{"session": "gt-gastown-nux", "rig": "gastown"}},
+		{Timestamp: t0.Add(time.Minute).Format(time.RFC3339), Type: events.TypeSessionDeath, Payload: map[string]interface{}{"rig": "beads"}},
+	}
+
+	r := Build(subject, nil, nil, notes, evts, t0.Add(time.Hour))
+	if len(r.Timeline) != 2 || r.Timeline[1].Text != "session gt-gastown-nux died" {
+		t.Errorf("Timeline = %+v, want the note and the gastown session death", r.Timeline)
+	}
+	if !strings.Contains(r.Markdown(), "(still open)") {
+		t.Error("open incident should say so")
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{30 * time.Second, "<1m"},
+		{45 * time.Minute, "45m"},
+		{3*time.Hour + 20*time.Minute, "3h20m"},
+		{52 * time.Hour, "2d4h"},
+	}
+	for _, tt := range tests {
+		if got := FormatDuration(tt.d); got != tt.want {
+			t.Errorf("FormatDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
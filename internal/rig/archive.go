@@ -0,0 +1,284 @@
+package rig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+// ErrRigArchived is returned when an operation needs a rig that is in cold
+// storage.
+var ErrRigArchived = errors.New("rig is archived")
+
+// ArchiveDir holds an archived rig's beads snapshot, relative to the rig.
+const ArchiveDir = "archive"
+
+// archivedBeadsDir keeps tracked beads (mayor/rig/.beads) while the mayor
+// clone is gone, so a restore gets back the local database config.
+const archivedBeadsDir = "archive/mayor-beads"
+
+// checkoutPaths are the heavy parts of a rig removed by ArchiveRig:
+// the shared bare repo, the mayor and refinery clones (the first three,
+// which RestoreRig recreates), and every worker.
+var checkoutPaths = []string{".repo.git", "mayor/rig", "refinery/rig", "polecats", "crew"}
+
+// ArchiveOptions configures ArchiveRig.
+type ArchiveOptions struct {
+	Reason string
+	Force  bool // Archive even with unsaved work or a failed snapshot
+}
+
+// ArchiveResult describes what ArchiveRig did.
+type ArchiveResult struct {
+	Snapshot   string // Path of the beads export
+	IssueCount int
+	FreedBytes int64
+}
+
+// UnsavedWork reports each clone in the rig with uncommitted changes,
+// stashes, or unpushed commits, keyed by path relative to the rig.
+func UnsavedWork(rigPath string) map[string]string {
+	dirty := make(map[string]string)
+	for _, clone := range findClones(rigPath) {
+		status, err := git.NewGit(clone).CheckUncommittedWork()
+		rel, _ := filepath.Rel(rigPath, clone)
+		if err != nil {
+			dirty[rel] = err.Error()
+		} else if !status.CleanExcludingBeads() {
+			dirty[rel] = status.String()
+		}
+	}
+	return dirty
+}
+
+// ArchiveRig puts a rig in cold storage: it exports the rig's beads to
+// archive/, removes its checkouts, and marks its rigs.json entry
+// archived. Stopping agents is the caller's job, as is saving the rigs
+// config afterwards.
+func (m *Manager) ArchiveRig(name string, opts ArchiveOptions) (*ArchiveResult, error) {
+	entry, ok := m.config.Rigs[name]
+	if !ok {
+		return nil, ErrRigNotFound
+	}
+	if entry.Archived != nil {
+		return nil, ErrRigArchived
+	}
+	rigPath := filepath.Join(m.townRoot, name)
+
+	if !opts.Force {
+		if dirty := UnsavedWork(rigPath); len(dirty) > 0 {
+			var lines []string
+			for path, status := range dirty {
+				lines = append(lines, fmt.Sprintf("  %s: %s", path, status))
+			}
+			sort.Strings(lines)
+			return nil, fmt.Errorf("unsaved work would be lost:\n%s", strings.Join(lines, "\n"))
+		}
+	}
+
+	result := &ArchiveResult{}
+	now := time.Now()
+	snapshot := filepath.Join(ArchiveDir, "issues-"+now.Format("20060102-150405")+".jsonl")
+	n, err := exportIssues(rigPath, filepath.Join(rigPath, snapshot))
+	if err != nil {
+		if !opts.Force {
+			return nil, fmt.Errorf("exporting beads snapshot: %w", err)
+		}
+		snapshot = ""
+	}
+	result.IssueCount = n
+	if snapshot != "" {
+		result.Snapshot = filepath.Join(rigPath, snapshot)
+	}
+
+	// Tracked beads live inside the mayor clone; keep their local files.
+	mayorBeads := filepath.Join(rigPath, "mayor", "rig", ".beads")
+	if _, err := os.Stat(mayorBeads); err == nil {
+		kept := filepath.Join(rigPath, archivedBeadsDir)
+		if err := os.RemoveAll(kept); err != nil {
+			return nil, err
+		}
+		if err := os.Rename(mayorBeads, kept); err != nil {
+			return nil, fmt.Errorf("keeping tracked beads: %w", err)
+		}
+	}
+
+	for _, rel := range checkoutPaths {
+		path := filepath.Join(rigPath, rel)
+		result.FreedBytes += dirSize(path)
+		if err := os.RemoveAll(path); err != nil {
+			return nil, fmt.Errorf("removing %s: %w", rel, err)
+		}
+	}
+
+	entry.Archived = &config.RigArchive{ArchivedAt: now, Snapshot: snapshot, Reason: opts.Reason}
+	m.config.Rigs[name] = entry
+	return result, nil
+}
+
+// RestoreRig brings an archived rig back: it re-clones the repository
+// into .repo.git and mayor/rig, recreates the refinery worktree and the
+// worker directories, and clears the archived mark. The caller saves the
+// rigs config.
+func (m *Manager) RestoreRig(name string) (*Rig, error) {
+	entry, ok := m.config.Rigs[name]
+	if !ok {
+		return nil, ErrRigNotFound
+	}
+	if entry.Archived == nil {
+		return nil, fmt.Errorf("rig %q is not archived", name)
+	}
+	rigPath := filepath.Join(m.townRoot, name)
+
+	defaultBranch := "main"
+	if cfg, err := LoadRigConfig(rigPath); err == nil && cfg.DefaultBranch != "" {
+		defaultBranch = cfg.DefaultBranch
+	}
+
+	// A failed restore leaves the rig archived and clone-free so it can
+	// be retried.
+	success := false
+	defer func() {
+		if !success {
+			for _, rel := range checkoutPaths[:3] {
+				_ = os.RemoveAll(filepath.Join(rigPath, rel))
+			}
+		}
+	}()
+
+	fmt.Printf("  Cloning repository (this may take a moment)...\n")
+	bareRepoPath := filepath.Join(rigPath, ".repo.git")
+	if err := m.git.CloneBare(entry.GitURL, bareRepoPath); err != nil {
+		return nil, wrapCloneError(err, entry.GitURL)
+	}
+	bareGit := git.NewGitWithDir(bareRepoPath, "")
+
+	mayorRigPath := filepath.Join(rigPath, "mayor", "rig")
+	if err := os.MkdirAll(filepath.Dir(mayorRigPath), 0755); err != nil {
+		return nil, fmt.Errorf("creating mayor dir: %w", err)
+	}
+	if err := m.git.Clone(entry.GitURL, mayorRigPath); err != nil {
+		return nil, fmt.Errorf("cloning for mayor: %w", err)
+	}
+	if err := git.NewGitWithDir("", mayorRigPath).Checkout(defaultBranch); err != nil {
+		return nil, fmt.Errorf("checking out default branch for mayor: %w", err)
+	}
+	if _, err := m.createRoleCLAUDEmd(mayorRigPath, "mayor", name, ""); err != nil {
+		return nil, fmt.Errorf("creating mayor CLAUDE.md: %w", err)
+	}
+	fmt.Printf("   ✓ Restored mayor clone\n")
+
+	refineryRigPath := filepath.Join(rigPath, "refinery", "rig")
+	if err := os.MkdirAll(filepath.Dir(refineryRigPath), 0755); err != nil {
+		return nil, fmt.Errorf("creating refinery dir: %w", err)
+	}
+	if err := bareGit.WorktreeAddExisting(refineryRigPath, defaultBranch); err != nil {
+		return nil, fmt.Errorf("creating refinery worktree: %w", err)
+	}
+	if err := beads.SetupRedirect(m.townRoot, refineryRigPath); err != nil {
+		fmt.Printf("  Warning: Could not set up refinery beads redirect: %v\n", err)
+	}
+	if _, err := m.createRoleCLAUDEmd(refineryRigPath, "refinery", name, ""); err != nil {
+		return nil, fmt.Errorf("creating refinery CLAUDE.md: %w", err)
+	}
+	if err := CopyOverlay(rigPath, refineryRigPath); err != nil {
+		fmt.Printf("  Warning: Could not copy overlay files to refinery: %v\n", err)
+	}
+	fmt.Printf("   ✓ Restored refinery worktree\n")
+
+	for _, dir := range []string{"crew", "polecats"} {
+		if err := os.MkdirAll(filepath.Join(rigPath, dir), 0755); err != nil {
+			return nil, fmt.Errorf("creating %s dir: %w", dir, err)
+		}
+	}
+
+	// Put the tracked beads' local files back over the fresh clone's.
+	kept := filepath.Join(rigPath, archivedBeadsDir)
+	if _, err := os.Stat(kept); err == nil {
+		mayorBeads := filepath.Join(mayorRigPath, ".beads")
+		if err := os.RemoveAll(mayorBeads); err != nil {
+			return nil, err
+		}
+		if err := os.Rename(kept, mayorBeads); err != nil {
+			return nil, fmt.Errorf("restoring tracked beads: %w", err)
+		}
+	}
+
+	success = true
+	entry.Archived = nil
+	m.config.Rigs[name] = entry
+	return m.loadRig(name, entry)
+}
+
+// exportIssues writes every issue in the rig's beads to path as JSONL and
+// returns how many there were.
+func exportIssues(rigPath, path string) (int, error) {
+	issues, err := beads.New(rigPath).List(beads.ListOptions{Status: "all", Priority: -1})
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return 0, err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	enc := json.NewEncoder(f)
+	for _, issue := range issues {
+		if err := enc.Encode(issue); err != nil {
+			_ = f.Close()
+			return 0, err
+		}
+	}
+	return len(issues), f.Close()
+}
+
+// findClones returns the git checkouts under the rig's checkout paths:
+// the mayor and refinery clones and each crew and polecat workspace.
+func findClones(rigPath string) []string {
+	var clones []string
+	for _, rel := range checkoutPaths {
+		if rel == ".repo.git" {
+			continue // Bare; branches in it are pushed or belong to polecats
+		}
+		root := filepath.Join(rigPath, rel)
+		_ = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+			if err != nil || !d.IsDir() {
+				return nil
+			}
+			if _, err := os.Stat(filepath.Join(path, ".git")); err == nil {
+				clones = append(clones, path)
+				return filepath.SkipDir
+			}
+			if depth := strings.Count(strings.TrimPrefix(path, root), string(filepath.Separator)); depth >= 2 {
+				return filepath.SkipDir
+			}
+			return nil
+		})
+	}
+	return clones
+}
+
+// dirSize returns the total size of the regular files under path.
+func dirSize(path string) int64 {
+	var size int64
+	_ = filepath.WalkDir(path, func(_ string, d os.DirEntry, err error) error {
+		if err == nil && d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
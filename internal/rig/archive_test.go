@@ -0,0 +1,122 @@
+package rig
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+// setupArchivableRig creates a town with one rig cloned from a local
+// origin, and a fake bd whose list returns no issues.
+func setupArchivableRig(t *testing.T) (string, *config.RigsConfig, *Manager) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	for k, v := range map[string]string{
+		"GIT_AUTHOR_NAME": "t", "GIT_AUTHOR_EMAIL": "t@example.com",
+		"GIT_COMMITTER_NAME": "t", "GIT_COMMITTER_EMAIL": "t@example.com",
+	} {
+		t.Setenv(k, v)
+	}
+	binDir := writeFakeBD(t, "#!/bin/sh\necho '[]'\n", "")
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	origin := filepath.Join(t.TempDir(), "origin")
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main", origin},
+		{"-C", origin, "commit", "-q", "--allow-empty", "-m", "init"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	root, rigsConfig := setupTestTown(t)
+	rigPath := filepath.Join(root, "old")
+	g := git.NewGit(root)
+	if err := g.CloneBare(origin, filepath.Join(rigPath, ".repo.git")); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Clone(origin, filepath.Join(rigPath, "mayor", "rig")); err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{"crew", "polecats", "refinery", "witness"} {
+		if err := os.MkdirAll(filepath.Join(rigPath, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rigsConfig.Rigs["old"] = config.RigEntry{GitURL: origin}
+	return root, rigsConfig, NewManager(root, rigsConfig, g)
+}
+
+func TestArchiveAndRestoreRig(t *testing.T) {
+	root, rigsConfig, mgr := setupArchivableRig(t)
+	rigPath := filepath.Join(root, "old")
+
+	result, err := mgr.ArchiveRig("old", ArchiveOptions{Reason: "done"})
+	if err != nil {
+		t.Fatalf("ArchiveRig: %v", err)
+	}
+	if _, err := os.Stat(result.Snapshot); err != nil {
+		t.Errorf("snapshot not written: %v", err)
+	}
+	for _, rel := range checkoutPaths {
+		if _, err := os.Stat(filepath.Join(rigPath, rel)); err == nil {
+			t.Errorf("%s still exists after archive", rel)
+		}
+	}
+	archive := rigsConfig.Rigs["old"].Archived
+	if archive == nil || archive.Reason != "done" {
+		t.Fatalf("entry.Archived = %+v", archive)
+	}
+	if rigs, _ := mgr.DiscoverRigs(); len(rigs) != 0 {
+		t.Errorf("DiscoverRigs returned archived rig")
+	}
+	if r, err := mgr.GetRig("old"); err != nil || !r.Archived {
+		t.Errorf("GetRig = %+v, %v; want archived rig", r, err)
+	}
+	if _, err := mgr.ArchiveRig("old", ArchiveOptions{}); err != ErrRigArchived {
+		t.Errorf("second ArchiveRig error = %v, want ErrRigArchived", err)
+	}
+
+	r, err := mgr.RestoreRig("old")
+	if err != nil {
+		t.Fatalf("RestoreRig: %v", err)
+	}
+	if r.Archived || rigsConfig.Rigs["old"].Archived != nil {
+		t.Error("rig still archived after restore")
+	}
+	if !r.HasMayor || !r.HasRefinery {
+		t.Errorf("restored rig missing clones: mayor=%v refinery=%v", r.HasMayor, r.HasRefinery)
+	}
+}
+
+func TestArchiveRigRefusesUnsavedWork(t *testing.T) {
+	root, rigsConfig, mgr := setupArchivableRig(t)
+	scratch := filepath.Join(root, "old", "mayor", "rig", "notes.txt")
+	if err := os.WriteFile(scratch, []byte("wip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := mgr.ArchiveRig("old", ArchiveOptions{})
+	if err == nil || !strings.Contains(err.Error(), "mayor/rig") {
+		t.Fatalf("ArchiveRig error = %v, want unsaved work in mayor/rig", err)
+	}
+	if rigsConfig.Rigs["old"].Archived != nil {
+		t.Error("rig marked archived after refusal")
+	}
+	if _, err := os.Stat(scratch); err != nil {
+		t.Error("refused archive removed files")
+	}
+
+	if _, err := mgr.ArchiveRig("old", ArchiveOptions{Force: true}); err != nil {
+		t.Fatalf("ArchiveRig --force: %v", err)
+	}
+}
@@ -0,0 +1,166 @@
+package rig
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// ImportStateFile is where gt rig import records progress, relative to
+// the town root, so an interrupted import can be resumed.
+const ImportStateFile = "daemon/rig-import.json"
+
+// Import item statuses.
+const (
+	ImportPending = "pending"
+	ImportAdded   = "added"
+	ImportFailed  = "failed"
+	ImportSkipped = "skipped" // Already registered before the import
+)
+
+// ImportItem is one repository in a bulk import.
+type ImportItem struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Prefix string `json:"prefix"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	Log    string `json:"log,omitempty"` // Output of the rig add
+}
+
+// ImportState is the progress of a bulk import.
+type ImportState struct {
+	StartedAt time.Time     `json:"started_at"`
+	Template  string        `json:"template,omitempty"`
+	Items     []*ImportItem `json:"items"`
+}
+
+// Item returns the state's item for name, or nil.
+func (s *ImportState) Item(name string) *ImportItem {
+	for _, it := range s.Items {
+		if it.Name == name {
+			return it
+		}
+	}
+	return nil
+}
+
+// LoadImportState reads the import state. Returns nil, nil if there is none.
+func LoadImportState(townRoot string) (*ImportState, error) {
+	data, err := os.ReadFile(filepath.Join(townRoot, ImportStateFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var s ImportState
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ImportStateFile, err)
+	}
+	return &s, nil
+}
+
+// SaveImportState writes the import state atomically.
+func SaveImportState(townRoot string, s *ImportState) error {
+	path := filepath.Join(townRoot, ImportStateFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return util.AtomicWriteJSON(path, s)
+}
+
+// ImportName derives a rig name from a repository URL: the repository's
+// base name, lowercased, with the hyphens, dots, and spaces rig names
+// can't contain replaced by underscores.
+func ImportName(url string) string {
+	url = strings.TrimRight(strings.TrimSpace(url), "/")
+	name := url[strings.LastIndexAny(url, "/:")+1:]
+	name = strings.TrimSuffix(name, ".git")
+	return strings.ToLower(strings.NewReplacer("-", "_", ".", "_", " ", "_").Replace(name))
+}
+
+// DeriveBeadsPrefix returns the default beads prefix for a rig name.
+func DeriveBeadsPrefix(name string) string {
+	return deriveBeadsPrefix(name)
+}
+
+// PlanImport names each URL and assigns it a beads prefix unique across
+// the town and the rest of the import. Duplicate URLs and names are
+// dropped; rigs already registered are marked skipped. Items in prev, a
+// previous run of the import, keep the prefix they were given then.
+func PlanImport(urls []string, registered map[string]string, prev *ImportState) []*ImportItem {
+	usedPrefixes := make(map[string]bool)
+	for _, prefix := range registered {
+		usedPrefixes[prefix] = true
+	}
+	if prev != nil {
+		for _, it := range prev.Items {
+			usedPrefixes[it.Prefix] = true
+		}
+	}
+
+	seen := make(map[string]bool)
+	var items []*ImportItem
+	for _, url := range urls {
+		url = strings.TrimSpace(url)
+		name := ImportName(url)
+		if url == "" || name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		if prefix, ok := registered[name]; ok {
+			items = append(items, &ImportItem{Name: name, URL: url, Prefix: prefix, Status: ImportSkipped})
+			continue
+		}
+		if prev != nil {
+			if it := prev.Item(name); it != nil {
+				items = append(items, &ImportItem{Name: name, URL: url, Prefix: it.Prefix, Status: ImportPending})
+				continue
+			}
+		}
+		base := DeriveBeadsPrefix(name)
+		prefix := base
+		for n := 2; usedPrefixes[prefix]; n++ {
+			prefix = fmt.Sprintf("%s%d", base, n)
+		}
+		usedPrefixes[prefix] = true
+		items = append(items, &ImportItem{Name: name, URL: url, Prefix: prefix, Status: ImportPending})
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+	return items
+}
+
+// ApplyTemplate copies the files in the template rig's settings/ into the
+// rig's settings/, overwriting what rig add created.
+func ApplyTemplate(templatePath, rigPath string) error {
+	src := filepath.Join(templatePath, "settings")
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return fmt.Errorf("reading template settings: %w", err)
+	}
+	dst := filepath.Join(rigPath, "settings")
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if !e.Type().IsRegular() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(src, e.Name()))
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dst, e.Name()), data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
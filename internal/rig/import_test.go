@@ -0,0 +1,95 @@
+package rig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestImportName(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://github.com/acme/api.git", "api"},
+		{"https://github.com/acme/Web-App/", "web_app"},
+		{"git@github.com:acme/my.tool.git", "my_tool"},
+		{"git@host:repo.git", "repo"},
+	}
+	for _, tt := range tests {
+		if got := ImportName(tt.url); got != tt.want {
+			t.Errorf("ImportName(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestPlanImport(t *testing.T) {
+	registered := map[string]string{"api": "ap"}
+	prev := &ImportState{Items: []*ImportItem{{Name: "web", Prefix: "we9", Status: ImportFailed}}}
+	items := PlanImport([]string{
+		"https://github.com/acme/api.git",
+		"https://github.com/acme/web.git",
+		"https://github.com/acme/alpha.git",
+		"https://github.com/acme/alpine.git",
+		"https://github.com/fork/alpha.git", // Same name, dropped
+		"",
+	}, registered, prev)
+
+	got := make(map[string]*ImportItem)
+	for _, it := range items {
+		got[it.Name] = it
+	}
+	if len(items) != 4 {
+		t.Fatalf("got %d items, want 4: %+v", len(items), items)
+	}
+	if items[0].Name != "alpha" {
+		t.Errorf("items not sorted: first is %q", items[0].Name)
+	}
+	if it := got["api"]; it.Status != ImportSkipped || it.Prefix != "ap" {
+		t.Errorf("registered rig = %+v, want skipped with its prefix", it)
+	}
+	if it := got["web"]; it.Status != ImportPending || it.Prefix != "we9" {
+		t.Errorf("resumed rig = %+v, want previous prefix", it)
+	}
+	if got["alpha"].Prefix == got["alpine"].Prefix {
+		t.Errorf("alpha and alpine share prefix %q", got["alpha"].Prefix)
+	}
+	if got["alpha"].URL != "https://github.com/acme/alpha.git" {
+		t.Errorf("duplicate name replaced the first URL: %s", got["alpha"].URL)
+	}
+}
+
+func TestImportStateRoundTrip(t *testing.T) {
+	town := t.TempDir()
+	if s, err := LoadImportState(town); s != nil || err != nil {
+		t.Fatalf("LoadImportState() with no file = %v, %v", s, err)
+	}
+	want := &ImportState{Template: "api", Items: []*ImportItem{{Name: "web", Prefix: "we", Status: ImportAdded}}}
+	if err := SaveImportState(town, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadImportState(town)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Template != "api" || got.Item("web") == nil || got.Item("web").Status != ImportAdded {
+		t.Errorf("round trip = %+v", got)
+	}
+}
+
+func TestApplyTemplate(t *testing.T) {
+	tmpl, rigPath := t.TempDir(), t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tmpl, "settings"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpl, "settings", "config.json"), []byte(`{"x":1}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ApplyTemplate(tmpl, rigPath); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(rigPath, "settings", "config.json"))
+	if err != nil || string(data) != `{"x":1}` {
+		t.Errorf("copied settings = %q, %v", data, err)
+	}
+}
@@ -0,0 +1,173 @@
+package rig
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// The rig index keeps what loadRig found for each rig in
+// .runtime/rig-index.json, so discovery doesn't walk every rig on every
+// command. Loading a rig lists polecats/ and crew/ and stats each agent
+// directory, which on a network filesystem takes noticeable time; checking
+// an indexed rig only stats the directories it was loaded from.
+//
+// The whole index is dropped when rigs.json changes. An indexed rig is
+// reloaded when its registry entry differs or one of those directories
+// has a new mtime, which adding or removing anything in it sets.
+
+const rigIndexVersion = 1
+
+// rigIndexFile is the index's name under the town's .runtime directory.
+const rigIndexFile = "rig-index.json"
+
+// indexedDirs are the directories, relative to the rig, whose entries
+// loadRig reads: the rig itself (witness/), the polecat and crew lists,
+// and the parents of refinery/rig and mayor/rig.
+var indexedDirs = []string{".", "polecats", "crew", "refinery", "mayor"}
+
+// racyWindow is how recent an mtime can be and still be trusted. A
+// directory changed again within the same mtime tick wouldn't show as
+// changed, so a rig with a directory this new isn't indexed yet.
+const racyWindow = 2 * time.Second
+
+type rigIndex struct {
+	Version  int                    `json:"version"`
+	RigsJSON fileStamp              `json:"rigs_json"`
+	Rigs     map[string]*indexedRig `json:"rigs"`
+
+	path  string
+	dirty bool
+}
+
+type indexedRig struct {
+	Entry json.RawMessage `json:"entry"` // The registry entry it was loaded for
+	Dirs  []fileStamp     `json:"dirs"`  // indexedDirs, in order
+	Rig   *Rig            `json:"rig"`
+}
+
+// fileStamp identifies a version of a file or directory. A missing one has
+// a zero stamp.
+type fileStamp struct {
+	ModTime int64 `json:"mtime"` // Unix nanoseconds
+	Size    int64 `json:"size"`
+}
+
+func stampOf(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{ModTime: info.ModTime().UnixNano(), Size: info.Size()}
+}
+
+// loadRigIndex reads the town's rig index. It returns nil, which disables
+// indexing, when the town has no rigs.json to invalidate the index by.
+func loadRigIndex(townRoot string) *rigIndex {
+	rigsJSON := stampOf(constants.MayorRigsPath(townRoot))
+	if rigsJSON == (fileStamp{}) {
+		return nil
+	}
+	idx := &rigIndex{path: filepath.Join(constants.TownRuntimePath(townRoot), rigIndexFile)}
+	if data, err := os.ReadFile(idx.path); err == nil {
+		if json.Unmarshal(data, idx) != nil || idx.Version != rigIndexVersion || idx.RigsJSON != rigsJSON {
+			idx.Rigs = nil
+		}
+	}
+	if idx.Rigs == nil {
+		idx.Rigs = make(map[string]*indexedRig)
+		idx.dirty = true
+	}
+	idx.Version = rigIndexVersion
+	idx.RigsJSON = rigsJSON
+	return idx
+}
+
+// rig returns the rig from the index if it's still current, and otherwise
+// calls load and indexes what it returns. A nil index always loads.
+func (idx *rigIndex) rig(name, rigPath string, entry config.RigEntry, load func() (*Rig, error)) (*Rig, error) {
+	if idx == nil {
+		return load()
+	}
+	entryJSON, err := json.Marshal(entry)
+	if err != nil {
+		return load()
+	}
+	dirs := make([]fileStamp, len(indexedDirs))
+	for i, dir := range indexedDirs {
+		dirs[i] = stampOf(filepath.Join(rigPath, dir))
+	}
+
+	if cached, ok := idx.Rigs[name]; ok && cached.Rig != nil &&
+		bytes.Equal(cached.Entry, entryJSON) && sameStamps(cached.Dirs, dirs) {
+		return cached.Rig, nil
+	}
+
+	r, err := load()
+	if err != nil {
+		if _, ok := idx.Rigs[name]; ok {
+			delete(idx.Rigs, name)
+			idx.dirty = true
+		}
+		return nil, err
+	}
+	if racy(dirs) {
+		delete(idx.Rigs, name)
+	} else {
+		idx.Rigs[name] = &indexedRig{Entry: entryJSON, Dirs: dirs, Rig: r}
+	}
+	idx.dirty = true
+	return r, nil
+}
+
+// prune drops rigs no longer in the registry.
+func (idx *rigIndex) prune(rigs map[string]config.RigEntry) {
+	if idx == nil {
+		return
+	}
+	for name := range idx.Rigs {
+		if _, ok := rigs[name]; !ok {
+			delete(idx.Rigs, name)
+			idx.dirty = true
+		}
+	}
+}
+
+// save writes the index back if it changed. Failing to is harmless: the
+// next command walks the rigs again.
+func (idx *rigIndex) save() {
+	if idx == nil || !idx.dirty {
+		return
+	}
+	_ = util.EnsureDirAndWriteJSON(idx.path, idx)
+	idx.dirty = false
+}
+
+func sameStamps(a, b []fileStamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// racy reports whether any stamp is too recent to trust (see racyWindow).
+func racy(stamps []fileStamp) bool {
+	cutoff := time.Now().Add(-racyWindow).UnixNano()
+	for _, s := range stamps {
+		if s.ModTime > cutoff {
+			return true
+		}
+	}
+	return false
+}
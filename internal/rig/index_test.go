@@ -0,0 +1,140 @@
+package rig
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+// setupIndexedTown creates a town with rigs.json and one rig whose
+// directories are old enough to index.
+func setupIndexedTown(t *testing.T) (string, *config.RigsConfig) {
+	t.Helper()
+	root, rigsConfig := setupTestTown(t)
+	createTestRig(t, root, "gastown")
+	rigsConfig.Rigs["gastown"] = config.RigEntry{GitURL: "git@github.com:test/gastown.git"}
+
+	rigsPath := constants.MayorRigsPath(root)
+	if err := os.MkdirAll(filepath.Dir(rigsPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(rigsPath, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	age(t, rigsPath)
+	for _, dir := range indexedDirs {
+		age(t, filepath.Join(root, "gastown", dir))
+	}
+	return root, rigsConfig
+}
+
+// age sets a path's mtime an hour back.
+func age(t *testing.T, path string) {
+	t.Helper()
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// markIndexed rewrites the indexed polecats, so a discovery served from
+// the index is told apart from one that walked the rig.
+func markIndexed(t *testing.T, root string) {
+	t.Helper()
+	path := filepath.Join(constants.TownRuntimePath(root), rigIndexFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading index: %v", err)
+	}
+	var idx rigIndex
+	if err := json.Unmarshal(data, &idx); err != nil {
+		t.Fatal(err)
+	}
+	idx.Rigs["gastown"].Rig.Polecats = []string{"Indexed"}
+	data, _ = json.Marshal(&idx)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func discoverPolecats(t *testing.T, m *Manager) []string {
+	t.Helper()
+	rigs, err := m.DiscoverRigs()
+	if err != nil || len(rigs) != 1 {
+		t.Fatalf("DiscoverRigs = %v, %v", rigs, err)
+	}
+	return rigs[0].Polecats
+}
+
+func TestRigIndex(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(t *testing.T, root string, rigsConfig *config.RigsConfig)
+		indexed bool
+	}{
+		{"unchanged", func(*testing.T, string, *config.RigsConfig) {}, true},
+		{"polecat added", func(t *testing.T, root string, _ *config.RigsConfig) {
+			if err := os.Mkdir(filepath.Join(root, "gastown", "polecats", "Nux"), 0755); err != nil {
+				t.Fatal(err)
+			}
+		}, false},
+		{"entry changed", func(_ *testing.T, _ string, rigsConfig *config.RigsConfig) {
+			rigsConfig.Rigs["gastown"] = config.RigEntry{GitURL: "git@github.com:test/other.git"}
+		}, false},
+		{"rigs.json changed", func(t *testing.T, root string, _ *config.RigsConfig) {
+			now := time.Now()
+			if err := os.Chtimes(constants.MayorRigsPath(root), now, now); err != nil {
+				t.Fatal(err)
+			}
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, rigsConfig := setupIndexedTown(t)
+			m := NewManager(root, rigsConfig, git.NewGit(root))
+			discoverPolecats(t, m)
+			markIndexed(t, root)
+
+			tt.change(t, root, rigsConfig)
+			got := discoverPolecats(t, m)
+			if indexed := slices.Equal(got, []string{"Indexed"}); indexed != tt.indexed {
+				t.Errorf("polecats = %v, want from index %v", got, tt.indexed)
+			}
+		})
+	}
+}
+
+func TestRigIndexSkipsRacyRigs(t *testing.T) {
+	root, rigsConfig := setupIndexedTown(t)
+	// Just changed: another change in the same mtime tick would go unseen
+	now := time.Now()
+	if err := os.Chtimes(filepath.Join(root, "gastown", "crew"), now, now); err != nil {
+		t.Fatal(err)
+	}
+	m := NewManager(root, rigsConfig, git.NewGit(root))
+	discoverPolecats(t, m)
+
+	idx := loadRigIndex(root)
+	if _, ok := idx.Rigs["gastown"]; ok {
+		t.Error("rig with a directory changed just now was indexed")
+	}
+}
+
+func TestRigIndexNeedsRigsJSON(t *testing.T) {
+	root, rigsConfig := setupTestTown(t)
+	createTestRig(t, root, "gastown")
+	rigsConfig.Rigs["gastown"] = config.RigEntry{}
+	m := NewManager(root, rigsConfig, git.NewGit(root))
+	discoverPolecats(t, m)
+
+	if _, err := os.Stat(filepath.Join(constants.TownRuntimePath(root), rigIndexFile)); !os.IsNotExist(err) {
+		t.Errorf("index written for a town without rigs.json: %v", err)
+	}
+}
@@ -81,6 +81,7 @@ type RigConfig struct {
 	DefaultBranch string       `json:"default_branch,omitempty"` // main, master, etc.
 	CreatedAt     time.Time    `json:"created_at"`               // when rig was created
 	Beads         *BeadsConfig `json:"beads,omitempty"`
+	Subdir        string       `json:"subdir,omitempty"` // monorepo subtree this rig owns
 }
 
 // BeadsConfig represents beads configuration for the rig.
@@ -108,19 +109,27 @@ func NewManager(townRoot string, rigsConfig *config.RigsConfig, g *git.Git) *Man
 	}
 }
 
-// DiscoverRigs returns all rigs registered in the workspace.
+// DiscoverRigs returns all rigs registered in the workspace, except
+// archived ones.
 // Rigs that fail to load are logged to stderr and skipped; partial results are returned.
 func (m *Manager) DiscoverRigs() ([]*Rig, error) {
 	var rigs []*Rig
 
+	// Rigs unchanged since an earlier command come from the index
+	idx := loadRigIndex(m.townRoot)
 	for name, entry := range m.config.Rigs {
-		rig, err := m.loadRig(name, entry)
+		if entry.Archived != nil {
+			continue
+		}
+		rig, err := m.indexedRig(idx, name, entry)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: failed to load rig %q: %v\n", name, err)
 			continue
 		}
 		rigs = append(rigs, rig)
 	}
+	idx.prune(m.config.Rigs)
+	idx.save()
 
 	return rigs, nil
 }
@@ -132,7 +141,17 @@ func (m *Manager) GetRig(name string) (*Rig, error) {
 		return nil, ErrRigNotFound
 	}
 
-	return m.loadRig(name, entry)
+	idx := loadRigIndex(m.townRoot)
+	defer idx.save()
+	return m.indexedRig(idx, name, entry)
+}
+
+// indexedRig returns a rig from the index, loading it from the filesystem
+// if it isn't indexed or has changed since.
+func (m *Manager) indexedRig(idx *rigIndex, name string, entry config.RigEntry) (*Rig, error) {
+	return idx.rig(name, filepath.Join(m.townRoot, name), entry, func() (*Rig, error) {
+		return m.loadRig(name, entry)
+	})
 }
 
 // RigExists checks if a rig is registered.
@@ -160,6 +179,8 @@ func (m *Manager) loadRig(name string, entry config.RigEntry) (*Rig, error) {
 		GitURL:    entry.GitURL,
 		LocalRepo: entry.LocalRepo,
 		Config:    entry.BeadsConfig,
+		Subdir:    entry.Subdir,
+		Archived:  entry.Archived != nil,
 	}
 
 	// Scan for polecats
@@ -215,6 +236,7 @@ type AddRigOptions struct {
 	BeadsPrefix   string // Beads issue prefix (defaults to derived from name)
 	LocalRepo     string // Optional local repo for reference clones
 	DefaultBranch string // Default branch (defaults to auto-detected from remote)
+	Subdir        string // Monorepo subtree the rig owns (sub-rig); empty for the whole repo
 }
 
 func resolveLocalRepo(path, gitURL string) (string, string) {
@@ -279,6 +301,12 @@ func (m *Manager) AddRig(opts AddRigOptions) (*Rig, error) {
 		return nil, fmt.Errorf("directory already exists: %s\n\nTo adopt an existing directory, use --adopt:\n  gt rig add %s --adopt", rigPath, opts.Name)
 	}
 
+	subdir, err := NormalizeSubdir(opts.Subdir)
+	if err != nil {
+		return nil, err
+	}
+	opts.Subdir = subdir
+
 	// Track whether user explicitly provided --prefix (before deriving)
 	userProvidedPrefix := opts.BeadsPrefix != ""
 	opts.BeadsPrefix = strings.TrimSuffix(opts.BeadsPrefix, "-")
@@ -318,6 +346,7 @@ func (m *Manager) AddRig(opts AddRigOptions) (*Rig, error) {
 		Beads: &BeadsConfig{
 			Prefix: opts.BeadsPrefix,
 		},
+		Subdir: opts.Subdir,
 	}
 	if err := m.saveRigConfig(rigPath, rigConfig); err != nil {
 		return nil, fmt.Errorf("saving rig config: %w", err)
@@ -587,6 +616,7 @@ Use crew for your own workspace. Polecats are for batch work dispatch.
 		BeadsConfig: &config.BeadsConfig{
 			Prefix: opts.BeadsPrefix,
 		},
+		Subdir: opts.Subdir,
 	}
 
 	success = true
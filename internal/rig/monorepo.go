@@ -0,0 +1,128 @@
+package rig
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Monorepo sub-rigs: several rigs can share one repository, each owning the
+// subtree named by its Subdir, with its own beads, merge queue, and agents.
+// Files are routed to the rig with the longest Subdir containing them; a rig
+// without a Subdir owns whatever no sub-rig claims.
+
+// NormalizeSubdir cleans a sub-rig subtree path: slash-separated, relative
+// to the repository root, without a trailing slash. "" and "." mean the
+// whole repository.
+func NormalizeSubdir(subdir string) (string, error) {
+	subdir = strings.TrimSpace(strings.ReplaceAll(subdir, "\\", "/"))
+	if subdir == "" {
+		return "", nil
+	}
+	if strings.HasPrefix(subdir, "/") {
+		return "", fmt.Errorf("subdir %q must be relative to the repository root", subdir)
+	}
+	cleaned := path.Clean(subdir)
+	if cleaned == "." {
+		return "", nil
+	}
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("subdir %q escapes the repository", subdir)
+	}
+	return cleaned, nil
+}
+
+// SameRepo reports whether two git URLs name the same repository, ignoring
+// scheme, user, a trailing ".git", and https-vs-ssh spelling.
+func SameRepo(a, b string) bool {
+	return a != "" && repoKey(a) == repoKey(b)
+}
+
+// repoKey reduces a git URL to "host/owner/repo".
+func repoKey(url string) string {
+	u := strings.TrimSpace(url)
+	if i := strings.Index(u, "://"); i >= 0 {
+		u = u[i+3:]
+	} else if at := strings.Index(u, "@"); at >= 0 && strings.Contains(u[at:], ":") {
+		// scp-like: git@host:owner/repo
+		u = strings.Replace(u[at+1:], ":", "/", 1)
+	}
+	if at := strings.Index(u, "@"); at >= 0 && at < strings.Index(u+"/", "/") {
+		u = u[at+1:]
+	}
+	u = strings.TrimSuffix(strings.TrimSuffix(u, "/"), ".git")
+	return strings.ToLower(u)
+}
+
+// Owns reports whether file (relative to the repository root) lies in the
+// rig's subtree. A rig without a Subdir contains every file.
+func (r *Rig) Owns(file string) bool {
+	if r.Subdir == "" {
+		return true
+	}
+	file = strings.TrimPrefix(path.Clean(file), "./")
+	return file == r.Subdir || strings.HasPrefix(file, r.Subdir+"/")
+}
+
+// Siblings returns the rigs, r included, that share r's repository, sorted by
+// name. A rig whose repository no other rig shares is its own only sibling.
+func Siblings(rigs []*Rig, r *Rig) []*Rig {
+	var out []*Rig
+	for _, other := range rigs {
+		if other.Name == r.Name || SameRepo(other.GitURL, r.GitURL) {
+			out = append(out, other)
+		}
+	}
+	if len(out) == 0 {
+		out = []*Rig{r}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Owner returns the rig among siblings that owns file: the one with the
+// longest Subdir containing it, else the sibling without a Subdir. Returns
+// nil if no sibling owns the file.
+func Owner(siblings []*Rig, file string) *Rig {
+	var owner *Rig
+	for _, r := range siblings {
+		if !r.Owns(file) {
+			continue
+		}
+		if owner == nil || len(r.Subdir) > len(owner.Subdir) {
+			owner = r
+		}
+	}
+	return owner
+}
+
+// Siblings returns the registered rigs sharing r's repository, r included.
+// The rigs carry only their name, git URL, and subtree; they aren't loaded
+// from disk.
+func (m *Manager) Siblings(r *Rig) []*Rig {
+	rigs := make([]*Rig, 0, len(m.config.Rigs))
+	for name, entry := range m.config.Rigs {
+		rigs = append(rigs, &Rig{Name: name, GitURL: entry.GitURL, Subdir: entry.Subdir})
+	}
+	return Siblings(rigs, r)
+}
+
+// ForeignFile is a file in a rig's change that another rig owns.
+type ForeignFile struct {
+	File  string `json:"file"`
+	Owner string `json:"owner"`
+}
+
+// ForeignFiles returns the files that a sibling sub-rig owns rather than r.
+// Files no sub-rig claims (shared root files like go.mod when no rig owns
+// the root) are not foreign.
+func ForeignFiles(siblings []*Rig, r *Rig, files []string) []ForeignFile {
+	var out []ForeignFile
+	for _, f := range files {
+		if owner := Owner(siblings, f); owner != nil && owner.Name != r.Name {
+			out = append(out, ForeignFile{File: f, Owner: owner.Name})
+		}
+	}
+	return out
+}
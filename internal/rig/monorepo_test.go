@@ -0,0 +1,87 @@
+package rig
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeSubdir(t *testing.T) {
+	tests := []struct {
+		in, want string
+		wantErr  bool
+	}{
+		{"", "", false},
+		{".", "", false},
+		{"services/api/", "services/api", false},
+		{"./services//api", "services/api", false},
+		{"/abs", "", true},
+		{"../outside", "", true},
+		{"a/../../b", "", true},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeSubdir(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("NormalizeSubdir(%q) = %q, %v; want %q, err=%v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestSameRepo(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"https://github.com/acme/mono.git", "git@github.com:acme/mono.git", true},
+		{"https://github.com/acme/mono", "ssh://git@github.com/Acme/mono.git", true},
+		{"https://github.com/acme/mono", "https://github.com/acme/other", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		if got := SameRepo(tt.a, tt.b); got != tt.want {
+			t.Errorf("SameRepo(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestOwnerAndForeignFiles(t *testing.T) {
+	const url = "https://github.com/acme/mono"
+	root := &Rig{Name: "mono", GitURL: url}
+	api := &Rig{Name: "api", GitURL: url + ".git", Subdir: "services/api"}
+	apiV2 := &Rig{Name: "apiv2", GitURL: url, Subdir: "services/api/v2"}
+	web := &Rig{Name: "web", GitURL: url, Subdir: "web"}
+	other := &Rig{Name: "other", GitURL: "https://github.com/acme/other"}
+
+	siblings := Siblings([]*Rig{web, other, api, root, apiV2}, api)
+	var names []string
+	for _, s := range siblings {
+		names = append(names, s.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"api", "apiv2", "mono", "web"}) {
+		t.Fatalf("Siblings = %v", names)
+	}
+
+	for file, want := range map[string]string{
+		"services/api/main.go":     "api",
+		"services/api/v2/h.go":     "apiv2",
+		"services/apix/main.go":    "mono",
+		"web/src/app.ts":           "web",
+		"go.mod":                   "mono",
+		"./services/api/README.md": "api",
+	} {
+		if got := Owner(siblings, file); got == nil || got.Name != want {
+			t.Errorf("Owner(%q) = %v, want %s", file, got, want)
+		}
+	}
+
+	got := ForeignFiles(siblings, api, []string{"services/api/a.go", "web/x.ts", "services/api/v2/b.go", "go.mod"})
+	want := []ForeignFile{{File: "web/x.ts", Owner: "web"}, {File: "services/api/v2/b.go", Owner: "apiv2"}, {File: "go.mod", Owner: "mono"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ForeignFiles = %+v, want %+v", got, want)
+	}
+
+	// Without a root rig, shared files belong to no one and aren't foreign.
+	subOnly := Siblings([]*Rig{api, web}, api)
+	if got := ForeignFiles(subOnly, api, []string{"go.mod"}); len(got) != 0 {
+		t.Errorf("unclaimed files should not be foreign, got %+v", got)
+	}
+}
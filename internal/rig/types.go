@@ -19,6 +19,10 @@ type Rig struct {
 	// LocalRepo is an optional local repository used for reference clones.
 	LocalRepo string `json:"local_repo,omitempty"`
 
+	// Subdir is the monorepo subtree this rig owns, when it is one of
+	// several rigs sharing a repository. Empty means the whole repository.
+	Subdir string `json:"subdir,omitempty"`
+
 	// Config is the rig-level configuration.
 	Config *config.BeadsConfig `json:"config,omitempty"`
 
@@ -37,6 +41,9 @@ type Rig struct {
 
 	// HasMayor indicates if the rig has a mayor clone.
 	HasMayor bool `json:"has_mayor"`
+
+	// Archived indicates the rig is in cold storage (no checkouts).
+	Archived bool `json:"archived,omitempty"`
 }
 
 // AgentDirs are the standard agent directories in a rig.
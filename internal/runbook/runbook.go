@@ -0,0 +1,338 @@
+// Package runbook runs named sequences of gt and shell commands defined in
+// town settings: the operational glue that would otherwise live in
+// untracked shell scripts.
+//
+// A runbook's steps run in order from the town root. Parameters are
+// substituted into commands as {{name}} (shell-quoted) and exported as
+// GT_PARAM_<NAME>. Each run is logged, step by step, to
+// <town>/logs/runbook-<name>.log, and a failing step stops the run unless
+// it sets continue_on_error.
+package runbook
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/retention"
+)
+
+// DefaultStepTimeout bounds a step without its own timeout.
+const DefaultStepTimeout = 30 * time.Minute
+
+// validName matches a runbook name; names become log file names.
+var validName = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._-]*$`)
+
+// placeholder matches a {{name}} parameter reference.
+var placeholder = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_-]*)\s*\}\}`)
+
+// Load returns the runbooks defined in the town's settings.
+func Load(townRoot string) (map[string]*config.RunbookConfig, error) {
+	settings, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(townRoot))
+	if err != nil {
+		return nil, err
+	}
+	return settings.Runbooks, nil
+}
+
+// Names returns the runbook names, sorted.
+func Names(runbooks map[string]*config.RunbookConfig) []string {
+	names := make([]string, 0, len(runbooks))
+	for name := range runbooks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate checks that a runbook's steps and parameters are well formed.
+func Validate(name string, rb *config.RunbookConfig) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("invalid runbook name %q (letters, digits, '.', '_', '-')", name)
+	}
+	if rb == nil || len(rb.Steps) == 0 {
+		return fmt.Errorf("runbook %q has no steps", name)
+	}
+	declared := make(map[string]bool, len(rb.Params))
+	for _, p := range rb.Params {
+		if p.Name == "" {
+			return fmt.Errorf("runbook %q: parameter without a name", name)
+		}
+		if declared[p.Name] {
+			return fmt.Errorf("runbook %q: parameter %q declared twice", name, p.Name)
+		}
+		declared[p.Name] = true
+	}
+	for i, s := range rb.Steps {
+		if (s.Run == "") == (s.GT == "") {
+			return fmt.Errorf("runbook %q step %d: set exactly one of run and gt", name, i+1)
+		}
+		if s.Timeout != "" {
+			if _, err := time.ParseDuration(s.Timeout); err != nil {
+				return fmt.Errorf("runbook %q step %d: invalid timeout %q", name, i+1, s.Timeout)
+			}
+		}
+		for _, m := range placeholder.FindAllStringSubmatch(s.Run+" "+s.GT, -1) {
+			if !declared[m[1]] {
+				return fmt.Errorf("runbook %q step %d: {{%s}} is not a declared parameter", name, i+1, m[1])
+			}
+		}
+	}
+	if _, err := retention.ParseAge(rb.Every); err != nil {
+		return fmt.Errorf("runbook %q: invalid every %q", name, rb.Every)
+	}
+	return nil
+}
+
+// Interval returns how often the daemon runs rb, or 0 if it isn't
+// scheduled. Days may be written as "1d".
+func Interval(rb *config.RunbookConfig) time.Duration {
+	if rb == nil {
+		return 0
+	}
+	d, _ := retention.ParseAge(rb.Every)
+	return d
+}
+
+// Params resolves the parameters for a run: given values, then defaults.
+// Unknown and missing required parameters are errors.
+func Params(name string, rb *config.RunbookConfig, given map[string]string) (map[string]string, error) {
+	out := make(map[string]string, len(rb.Params))
+	known := make(map[string]bool, len(rb.Params))
+	for _, p := range rb.Params {
+		known[p.Name] = true
+		if v, ok := given[p.Name]; ok {
+			out[p.Name] = v
+		} else if p.Required {
+			return nil, fmt.Errorf("runbook %q needs --param %s=<value>", name, p.Name)
+		} else {
+			out[p.Name] = p.Default
+		}
+	}
+	for k := range given {
+		if !known[k] {
+			return nil, fmt.Errorf("runbook %q has no parameter %q", name, k)
+		}
+	}
+	return out, nil
+}
+
+// ParseParams parses "key=value" arguments.
+func ParseParams(args []string) (map[string]string, error) {
+	out := make(map[string]string, len(args))
+	for _, a := range args {
+		k, v, ok := strings.Cut(a, "=")
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid parameter %q (want key=value)", a)
+		}
+		out[k] = v
+	}
+	return out, nil
+}
+
+// Command returns the shell command for a step with params substituted.
+// gt is the gt binary for gt steps.
+func Command(step config.RunbookStep, params map[string]string, gt string) string {
+	expand := func(s string) string {
+		return placeholder.ReplaceAllStringFunc(s, func(m string) string {
+			return shellQuote(params[placeholder.FindStringSubmatch(m)[1]])
+		})
+	}
+	if step.GT != "" {
+		return shellQuote(gt) + " " + expand(step.GT)
+	}
+	return expand(step.Run)
+}
+
+// StepName labels a step: its name, or its command.
+func StepName(step config.RunbookStep) string {
+	switch {
+	case step.Name != "":
+		return step.Name
+	case step.GT != "":
+		return "gt " + step.GT
+	default:
+		return step.Run
+	}
+}
+
+// shellQuote single-quotes s for sh.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// StepResult is the outcome of one step.
+type StepResult struct {
+	Name     string        `json:"name"`
+	Command  string        `json:"command"`
+	ExitCode int           `json:"exit_code"`
+	Duration time.Duration `json:"duration_ns"`
+	Error    string        `json:"error,omitempty"`
+	Skipped  bool          `json:"skipped,omitempty"`
+}
+
+// OK reports whether the step ran and succeeded.
+func (s StepResult) OK() bool {
+	return !s.Skipped && s.Error == ""
+}
+
+// Result is the outcome of a run.
+type Result struct {
+	Runbook string            `json:"runbook"`
+	Params  map[string]string `json:"params,omitempty"`
+	Started time.Time         `json:"started"`
+	Steps   []StepResult      `json:"steps"`
+	OK      bool              `json:"ok"`
+	LogPath string            `json:"log_path,omitempty"`
+}
+
+// Runner runs runbooks in a town.
+type Runner struct {
+	TownRoot string
+
+	// GT is the gt binary for gt steps. Default: this executable.
+	GT string
+
+	// Output receives step headers and command output as the run goes.
+	Output io.Writer
+
+	// DryRun prints the commands without running them.
+	DryRun bool
+}
+
+// LogPath is where runs of a runbook are logged.
+func LogPath(townRoot, name string) string {
+	return filepath.Join(townRoot, "logs", "runbook-"+name+".log")
+}
+
+// Run runs a runbook with resolved params (see Params). The returned error
+// is for failures to run at all; step failures are in the result.
+func (r *Runner) Run(ctx context.Context, name string, rb *config.RunbookConfig, params map[string]string) (*Result, error) {
+	if err := Validate(name, rb); err != nil {
+		return nil, err
+	}
+	gt := r.GT
+	if gt == "" {
+		exe, err := os.Executable()
+		if err != nil {
+			return nil, fmt.Errorf("locating gt: %w", err)
+		}
+		gt = exe
+	}
+	out := r.Output
+	if out == nil {
+		out = io.Discard
+	}
+
+	res := &Result{Runbook: name, Params: params, Started: time.Now(), OK: true}
+	if !r.DryRun {
+		res.LogPath = LogPath(r.TownRoot, name)
+		if err := os.MkdirAll(filepath.Dir(res.LogPath), 0755); err != nil {
+			return nil, err
+		}
+		logFile, err := os.OpenFile(res.LogPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644) //nolint:gosec // G302: town log
+		if err != nil {
+			return nil, err
+		}
+		defer logFile.Close()
+		out = io.MultiWriter(out, logFile)
+		_, _ = fmt.Fprintf(logFile, "=== %s runbook %s %s\n", res.Started.Format(time.RFC3339), name, formatParams(params))
+	}
+
+	env := append(os.Environ(), "GT_TOWN_ROOT="+r.TownRoot, "GT_RUNBOOK="+name)
+	for k, v := range params {
+		env = append(env, "GT_PARAM_"+envName(k)+"="+v)
+	}
+
+	stopped := false
+	for i, step := range rb.Steps {
+		sr := StepResult{Name: StepName(step), Command: Command(step, params, gt)}
+		if stopped {
+			sr.Skipped = true
+			res.Steps = append(res.Steps, sr)
+			continue
+		}
+		_, _ = fmt.Fprintf(out, "--- [%d/%d] %s\n", i+1, len(rb.Steps), sr.Name)
+		if r.DryRun {
+			_, _ = fmt.Fprintf(out, "%s\n", sr.Command)
+			res.Steps = append(res.Steps, sr)
+			continue
+		}
+
+		timeout := DefaultStepTimeout
+		if step.Timeout != "" {
+			timeout, _ = time.ParseDuration(step.Timeout)
+		}
+		stepCtx, cancel := context.WithTimeout(ctx, timeout)
+		cmd := exec.CommandContext(stepCtx, "sh", "-c", sr.Command) //nolint:gosec // G204: runbooks come from trusted town settings
+		cmd.Dir = r.TownRoot
+		cmd.Env = env
+		cmd.Stdout = out
+		cmd.Stderr = out
+		start := time.Now()
+		err := cmd.Run()
+		sr.Duration = time.Since(start)
+		if err != nil {
+			sr.ExitCode = -1
+			if exitErr, ok := err.(*exec.ExitError); ok {
+				sr.ExitCode = exitErr.ExitCode()
+			}
+			sr.Error = err.Error()
+			if stepCtx.Err() == context.DeadlineExceeded {
+				sr.Error = fmt.Sprintf("timed out after %s", timeout)
+			}
+		}
+		cancel()
+
+		if sr.OK() {
+			_, _ = fmt.Fprintf(out, "--- ok (%s)\n", sr.Duration.Round(time.Millisecond))
+		} else {
+			_, _ = fmt.Fprintf(out, "--- failed: %s\n", sr.Error)
+			res.OK = false
+			if !step.ContinueOnError {
+				stopped = true
+			}
+		}
+		res.Steps = append(res.Steps, sr)
+		if ctx.Err() != nil {
+			stopped = true
+		}
+	}
+	return res, nil
+}
+
+// FailedStep returns the first failed step's name, or "".
+func (r *Result) FailedStep() string {
+	for _, s := range r.Steps {
+		if !s.Skipped && !s.OK() {
+			return s.Name
+		}
+	}
+	return ""
+}
+
+func formatParams(params map[string]string) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + "=" + params[k]
+	}
+	return strings.Join(parts, " ")
+}
+
+// envName turns a parameter name into an environment variable suffix.
+func envName(name string) string {
+	return strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
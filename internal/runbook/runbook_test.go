@@ -0,0 +1,154 @@
+package runbook
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rbName  string
+		rb      *config.RunbookConfig
+		wantErr string
+	}{
+		{"ok", "deploy", &config.RunbookConfig{
+			Params: []config.RunbookParam{{Name: "rig"}},
+			Steps:  []config.RunbookStep{{GT: "rig park {{rig}}"}, {Run: "echo done", Timeout: "1m"}},
+			Every:  "1d",
+		}, ""},
+		{"no steps", "deploy", &config.RunbookConfig{}, "no steps"},
+		{"bad name", "../x", &config.RunbookConfig{Steps: []config.RunbookStep{{Run: "true"}}}, "invalid runbook name"},
+		{"both run and gt", "deploy", &config.RunbookConfig{Steps: []config.RunbookStep{{Run: "true", GT: "status"}}}, "exactly one"},
+		{"neither run nor gt", "deploy", &config.RunbookConfig{Steps: []config.RunbookStep{{Name: "x"}}}, "exactly one"},
+		{"undeclared param", "deploy", &config.RunbookConfig{Steps: []config.RunbookStep{{Run: "echo {{rig}}"}}}, "not a declared parameter"},
+		{"duplicate param", "deploy", &config.RunbookConfig{
+			Params: []config.RunbookParam{{Name: "rig"}, {Name: "rig"}},
+			Steps:  []config.RunbookStep{{Run: "true"}},
+		}, "declared twice"},
+		{"bad timeout", "deploy", &config.RunbookConfig{Steps: []config.RunbookStep{{Run: "true", Timeout: "soon"}}}, "invalid timeout"},
+		{"bad every", "deploy", &config.RunbookConfig{Steps: []config.RunbookStep{{Run: "true"}}, Every: "daily"}, "invalid every"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.rbName, tt.rb)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParams(t *testing.T) {
+	rb := &config.RunbookConfig{Params: []config.RunbookParam{
+		{Name: "rig", Required: true},
+		{Name: "branch", Default: "main"},
+	}}
+	got, err := Params("deploy", rb, map[string]string{"rig": "gastown"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["rig"] != "gastown" || got["branch"] != "main" {
+		t.Errorf("Params = %v", got)
+	}
+	if _, err := Params("deploy", rb, nil); err == nil || !strings.Contains(err.Error(), "--param rig=") {
+		t.Errorf("missing required param: error = %v", err)
+	}
+	if _, err := Params("deploy", rb, map[string]string{"rig": "x", "typo": "y"}); err == nil {
+		t.Error("unknown param: expected error")
+	}
+}
+
+func TestCommand(t *testing.T) {
+	params := map[string]string{"msg": "it's $HOME; rm -rf /"}
+	if got, want := Command(config.RunbookStep{Run: "echo {{msg}}"}, params, "gt"), `echo 'it'\''s $HOME; rm -rf /'`; got != want {
+		t.Errorf("Command = %s, want %s", got, want)
+	}
+	if got, want := Command(config.RunbookStep{GT: "mail send mayor/ -s {{ msg }}"}, params, "/usr/bin/gt"), `'/usr/bin/gt' mail send mayor/ -s 'it'\''s $HOME; rm -rf /'`; got != want {
+		t.Errorf("Command = %s, want %s", got, want)
+	}
+}
+
+func TestRun(t *testing.T) {
+	town := t.TempDir()
+	rb := &config.RunbookConfig{
+		Params: []config.RunbookParam{{Name: "who"}},
+		Steps: []config.RunbookStep{
+			{Name: "greet", Run: "echo hello {{who}} $GT_PARAM_WHO"},
+			{Run: "exit 3", ContinueOnError: true},
+			{GT: "status --who {{who}}"},
+			{Name: "fails", Run: "echo boom >&2; exit 1"},
+			{Name: "never", Run: "touch never"},
+		},
+	}
+	fakeGT := t.TempDir() + "/gt"
+	if err := os.WriteFile(fakeGT, []byte("#!/bin/sh\necho gt \"$@\"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	runner := &Runner{TownRoot: town, GT: fakeGT, Output: &out}
+	res, err := runner.Run(context.Background(), "demo", rb, map[string]string{"who": "world"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.OK || res.FailedStep() != "exit 3" {
+		t.Errorf("OK = %v, FailedStep = %q", res.OK, res.FailedStep())
+	}
+	want := []struct {
+		ok, skipped bool
+		exit        int
+	}{{true, false, 0}, {false, false, 3}, {true, false, 0}, {false, false, 1}, {false, true, 0}}
+	for i, w := range want {
+		s := res.Steps[i]
+		if s.OK() != w.ok || s.Skipped != w.skipped || s.ExitCode != w.exit {
+			t.Errorf("step %d (%s) = ok %v skipped %v exit %d, want %+v", i+1, s.Name, s.OK(), s.Skipped, s.ExitCode, w)
+		}
+	}
+	if _, err := os.Stat(town + "/never"); err == nil {
+		t.Error("step after a failure ran")
+	}
+
+	for _, want := range []string{"hello world world", "gt status --who world", "boom", "[4/5] fails"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+	log, err := os.ReadFile(LogPath(town, "demo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(log), "runbook demo who=world") || !strings.Contains(string(log), "hello world") {
+		t.Errorf("log:\n%s", log)
+	}
+}
+
+func TestRun_DryRun(t *testing.T) {
+	town := t.TempDir()
+	rb := &config.RunbookConfig{Steps: []config.RunbookStep{{Run: "touch ran"}}}
+	var out bytes.Buffer
+	res, err := (&Runner{TownRoot: town, GT: "gt", Output: &out, DryRun: true}).Run(context.Background(), "demo", rb, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.OK || !strings.Contains(out.String(), "touch ran") {
+		t.Errorf("dry run: ok %v, output %q", res.OK, out.String())
+	}
+	if _, err := os.Stat(town + "/ran"); err == nil {
+		t.Error("dry run ran the step")
+	}
+	if _, err := os.Stat(LogPath(town, "demo")); err == nil {
+		t.Error("dry run wrote a log")
+	}
+}
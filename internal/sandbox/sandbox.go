@@ -0,0 +1,351 @@
+// Package sandbox runs a town against a throwaway overlay copy of its state,
+// so orchestration logic, formulas, and policies can be exercised end to end
+// without touching real data.
+//
+// With GT_SANDBOX=1 (or gt --sandbox), workspace discovery resolves to the
+// overlay instead of the real town. The overlay holds copies of the town's
+// config, registries, and beads (including .dolt-data, served on its own
+// port); rig clones whose origin is a local bare mirror, so pushes land in
+// the overlay; and agents run on a separate tmux server.
+package sandbox
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+const (
+	// EnvVar enables sandbox mode.
+	EnvVar = "GT_SANDBOX"
+
+	// DirEnvVar overrides where the overlay is created.
+	DirEnvVar = "GT_SANDBOX_DIR"
+
+	// MarkerFile identifies an overlay town and records where it came from.
+	MarkerFile = ".gt-sandbox.json"
+
+	// TmuxSocket is the tmux server (tmux -L) sandboxed agents run on.
+	TmuxSocket = "gt-sandbox"
+
+	// DoltPort is the port the overlay's Dolt server listens on, clear of
+	// the real town's server on 3307.
+	DoltPort = 3407
+
+	// remotesDir holds the bare mirrors that stand in for each rig's origin.
+	remotesDir = ".sandbox-remotes"
+)
+
+// skipTopLevel lists town-root entries that aren't copied into the overlay:
+// runtime state that belongs to the real town's processes, and history the
+// sandbox starts without.
+var skipTopLevel = map[string]bool{
+	".git":          true,
+	"daemon":        true,
+	"logs":          true,
+	".events.jsonl": true,
+	".feed.jsonl":   true,
+	".runtime":      true,
+	remotesDir:      true,
+	MarkerFile:      true,
+}
+
+// Info is the overlay's marker file.
+type Info struct {
+	Source    string    `json:"source"` // Real town root
+	CreatedAt time.Time `json:"created_at"`
+	Rigs      []string  `json:"rigs,omitempty"`
+}
+
+// Enabled reports whether sandbox mode is on.
+func Enabled() bool {
+	switch strings.ToLower(os.Getenv(EnvVar)) {
+	case "1", "true", "yes", "on":
+		return true
+	}
+	return false
+}
+
+// Dir returns where townRoot's overlay lives: $GT_SANDBOX_DIR, or a
+// per-town directory under the system temp dir.
+func Dir(townRoot string) string {
+	if dir := os.Getenv(DirEnvVar); dir != "" {
+		return dir
+	}
+	sum := sha256.Sum256([]byte(townRoot))
+	return filepath.Join(os.TempDir(), fmt.Sprintf("gt-sandbox-%s-%s", filepath.Base(townRoot), hex.EncodeToString(sum[:4])))
+}
+
+// IsOverlay reports whether dir is a sandbox overlay.
+func IsOverlay(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, MarkerFile))
+	return err == nil
+}
+
+// ReadInfo loads an overlay's marker file.
+func ReadInfo(overlay string) (*Info, error) {
+	data, err := os.ReadFile(filepath.Join(overlay, MarkerFile))
+	if err != nil {
+		return nil, err
+	}
+	var info Info
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", MarkerFile, err)
+	}
+	return &info, nil
+}
+
+// Source returns the real town behind root: root itself unless it is an
+// overlay.
+func Source(root string) string {
+	if !IsOverlay(root) {
+		return root
+	}
+	if info, err := ReadInfo(root); err == nil && info.Source != "" {
+		return info.Source
+	}
+	return root
+}
+
+// Ensure returns townRoot's overlay, creating it on first use. The overlay
+// is built beside its final path and renamed into place, so concurrent
+// callers never see a half-built town.
+func Ensure(townRoot string) (string, error) {
+	overlay := Dir(townRoot)
+	if IsOverlay(overlay) {
+		return overlay, nil
+	}
+
+	building := fmt.Sprintf("%s.building-%d", overlay, os.Getpid())
+	_ = os.RemoveAll(building)
+	if err := build(townRoot, building, overlay); err != nil {
+		_ = os.RemoveAll(building)
+		return "", fmt.Errorf("creating sandbox: %w", err)
+	}
+	if err := os.Rename(building, overlay); err != nil {
+		_ = os.RemoveAll(building)
+		if IsOverlay(overlay) {
+			return overlay, nil // Another process won the race
+		}
+		return "", fmt.Errorf("creating sandbox: %w", err)
+	}
+	return overlay, nil
+}
+
+// Reset discards townRoot's overlay. The next sandboxed command starts
+// from a fresh copy of the real town.
+func Reset(townRoot string) error {
+	return os.RemoveAll(Dir(townRoot))
+}
+
+// build copies townRoot's state into overlay, which will be renamed to
+// final once complete.
+func build(townRoot, overlay, final string) error {
+	var rigNames []string
+	if rigs, err := config.LoadRigsConfig(filepath.Join(townRoot, "mayor", "rigs.json")); err == nil {
+		for name := range rigs.Rigs {
+			rigNames = append(rigNames, name)
+		}
+	}
+	sort.Strings(rigNames)
+	isRig := make(map[string]bool, len(rigNames))
+	for _, name := range rigNames {
+		isRig[name] = true
+	}
+
+	entries, err := os.ReadDir(townRoot)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if skipTopLevel[e.Name()] || isRig[e.Name()] {
+			continue
+		}
+		if err := copyTree(filepath.Join(townRoot, e.Name()), filepath.Join(overlay, e.Name())); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(overlay, "daemon"), 0755); err != nil {
+		return err
+	}
+
+	for _, name := range rigNames {
+		mirror := filepath.Join(remotesDir, name+".git")
+		if err := buildRig(filepath.Join(townRoot, name), filepath.Join(overlay, name), filepath.Join(overlay, mirror), filepath.Join(final, mirror)); err != nil {
+			return fmt.Errorf("rig %s: %w", name, err)
+		}
+	}
+
+	if err := redirectDolt(overlay); err != nil {
+		return err
+	}
+
+	return util.AtomicWriteJSON(filepath.Join(overlay, MarkerFile), &Info{
+		Source:    townRoot,
+		CreatedAt: time.Now().UTC(),
+		Rigs:      rigNames,
+	})
+}
+
+// buildRig copies a rig's config and beads and re-clones its repos against
+// a local bare mirror, whose final path is origin. Worker directories
+// (polecats, crew, witness, refinery) aren't copied; the sandbox creates
+// its own.
+func buildRig(rigPath, dst, mirror, origin string) error {
+	for _, name := range []string{"config.json", "settings", ".beads"} {
+		if err := copyTree(filepath.Join(rigPath, name), filepath.Join(dst, name)); err != nil {
+			return err
+		}
+	}
+
+	base := filepath.Join(rigPath, ".repo.git")
+	if _, err := os.Stat(base); err != nil {
+		base = filepath.Join(rigPath, "mayor", "rig")
+		if _, err := os.Stat(base); err != nil {
+			return nil // No repo yet
+		}
+	}
+	if err := git("", "clone", "--quiet", "--bare", base, mirror); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(filepath.Join(rigPath, ".repo.git")); err == nil {
+		bare := filepath.Join(dst, ".repo.git")
+		if err := git("", "clone", "--quiet", "--bare", mirror, bare); err != nil {
+			return err
+		}
+		if err := git("", "--git-dir", bare, "config", "remote.origin.fetch", "+refs/heads/*:refs/remotes/origin/*"); err != nil {
+			return err
+		}
+		if err := git("", "--git-dir", bare, "remote", "set-url", "origin", origin); err != nil {
+			return err
+		}
+	}
+
+	mayorRig := filepath.Join(rigPath, "mayor", "rig")
+	if _, err := os.Stat(mayorRig); err == nil {
+		clone := filepath.Join(dst, "mayor", "rig")
+		if err := git("", "clone", "--quiet", mirror, clone); err != nil {
+			return err
+		}
+		if err := git(clone, "remote", "set-url", "origin", origin); err != nil {
+			return err
+		}
+		// Rig beads are often untracked or ahead of the last commit.
+		if err := copyTree(filepath.Join(mayorRig, ".beads"), filepath.Join(clone, ".beads")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// redirectDolt points every server-mode beads database in the overlay at
+// the overlay's own Dolt server.
+func redirectDolt(overlay string) error {
+	return filepath.WalkDir(overlay, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			switch d.Name() {
+			case ".git", ".dolt-data", remotesDir, ".repo.git":
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() != "metadata.json" || filepath.Base(filepath.Dir(path)) != ".beads" {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		meta := make(map[string]interface{})
+		if json.Unmarshal(data, &meta) != nil || meta["dolt_mode"] != "server" {
+			return nil
+		}
+		meta["dolt_server_port"] = DoltPort
+		out, err := json.MarshalIndent(meta, "", "  ")
+		if err != nil {
+			return err
+		}
+		return util.AtomicWriteFile(path, append(out, '\n'), 0600)
+	})
+}
+
+// copyTree copies src to dst, preserving modes. A missing src is not an
+// error; sockets and other special files are skipped.
+func copyTree(src, dst string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	switch {
+	case info.IsDir():
+		if err := os.MkdirAll(dst, info.Mode().Perm()); err != nil {
+			return err
+		}
+		entries, err := os.ReadDir(src)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if err := copyTree(filepath.Join(src, e.Name()), filepath.Join(dst, e.Name())); err != nil {
+				return err
+			}
+		}
+		return nil
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(target, dst)
+	case info.Mode().IsRegular():
+		return copyFile(src, dst, info.Mode().Perm())
+	}
+	return nil
+}
+
+func copyFile(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src) //nolint:gosec // G304: path is within the town
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm) //nolint:gosec // G304: path is within the overlay
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func git(dir string, args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(out)))
+	}
+	return nil
+}
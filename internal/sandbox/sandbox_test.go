@@ -0,0 +1,146 @@
+package sandbox
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEnabled(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"", false},
+		{"0", false},
+		{"false", false},
+		{"1", true},
+		{"true", true},
+		{"YES", true},
+	}
+	for _, tt := range tests {
+		t.Setenv(EnvVar, tt.value)
+		if got := Enabled(); got != tt.want {
+			t.Errorf("Enabled() with %s=%q = %v, want %v", EnvVar, tt.value, got, tt.want)
+		}
+	}
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@t", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@t")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEnsure(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	town := t.TempDir()
+	overlayDir := filepath.Join(t.TempDir(), "sandbox")
+	t.Setenv(DirEnvVar, overlayDir)
+
+	// A real remote the sandbox must never push to.
+	origin := filepath.Join(t.TempDir(), "origin.git")
+	runGit(t, "", "init", "--quiet", "--bare", origin)
+
+	writeFile(t, filepath.Join(town, "mayor", "town.json"), `{"name": "test"}`)
+	writeFile(t, filepath.Join(town, "mayor", "rigs.json"), `{"version": 1, "rigs": {"gastown": {}}}`)
+	writeFile(t, filepath.Join(town, ".beads", "metadata.json"), `{"dolt_mode": "server", "dolt_database": "hq"}`)
+	writeFile(t, filepath.Join(town, ".events.jsonl"), "{}\n")
+	writeFile(t, filepath.Join(town, "daemon", "daemon.pid"), "123")
+	writeFile(t, filepath.Join(town, "gastown", "config.json"), `{"name": "gastown"}`)
+	writeFile(t, filepath.Join(town, "gastown", "polecats", "Toast", "README"), "worker")
+
+	mayorRig := filepath.Join(town, "gastown", "mayor", "rig")
+	writeFile(t, filepath.Join(mayorRig, "README"), "hello")
+	runGit(t, mayorRig, "init", "--quiet", "-b", "main")
+	runGit(t, mayorRig, "add", ".")
+	runGit(t, mayorRig, "commit", "--quiet", "-m", "init")
+	runGit(t, mayorRig, "remote", "add", "origin", origin)
+	writeFile(t, filepath.Join(mayorRig, ".beads", "metadata.json"), `{"dolt_mode": "server", "dolt_database": "gastown"}`)
+
+	overlay, err := Ensure(town)
+	if err != nil {
+		t.Fatalf("Ensure() = %v", err)
+	}
+	if overlay != overlayDir || !IsOverlay(overlay) {
+		t.Fatalf("Ensure() = %q, want overlay at %q", overlay, overlayDir)
+	}
+	if got := Source(overlay); got != town {
+		t.Errorf("Source(overlay) = %q, want %q", got, town)
+	}
+
+	for _, path := range []string{"mayor/town.json", "gastown/config.json", "gastown/mayor/rig/README", "daemon"} {
+		if _, err := os.Stat(filepath.Join(overlay, path)); err != nil {
+			t.Errorf("overlay is missing %s", path)
+		}
+	}
+	for _, path := range []string{".events.jsonl", "daemon/daemon.pid", "gastown/polecats"} {
+		if _, err := os.Stat(filepath.Join(overlay, path)); err == nil {
+			t.Errorf("overlay has %s, which should not be copied", path)
+		}
+	}
+
+	for _, path := range []string{".beads/metadata.json", "gastown/mayor/rig/.beads/metadata.json"} {
+		data, err := os.ReadFile(filepath.Join(overlay, path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var meta map[string]interface{}
+		if err := json.Unmarshal(data, &meta); err != nil {
+			t.Fatal(err)
+		}
+		if meta["dolt_server_port"] != float64(DoltPort) {
+			t.Errorf("%s dolt_server_port = %v, want %d", path, meta["dolt_server_port"], DoltPort)
+		}
+	}
+
+	// A push from the overlay lands in the overlay's mirror, not origin.
+	clone := filepath.Join(overlay, "gastown", "mayor", "rig")
+	writeFile(t, filepath.Join(clone, "CHANGE"), "sandboxed")
+	runGit(t, clone, "add", "CHANGE")
+	runGit(t, clone, "commit", "--quiet", "-m", "sandboxed change")
+	runGit(t, clone, "push", "--quiet", "origin", "HEAD:refs/heads/sandboxed")
+	if remote := runGit(t, clone, "remote", "get-url", "origin"); !strings.HasPrefix(remote, overlay) {
+		t.Errorf("overlay origin = %q, want a mirror inside %q", remote, overlay)
+	}
+	if refs := runGit(t, "", "--git-dir", origin, "for-each-ref"); refs != "" {
+		t.Errorf("real origin received refs: %s", refs)
+	}
+
+	// The overlay is reused until reset.
+	writeFile(t, filepath.Join(overlay, "mayor", "scratch"), "kept")
+	if again, err := Ensure(town); err != nil || again != overlay {
+		t.Fatalf("second Ensure() = %q, %v", again, err)
+	}
+	if _, err := os.Stat(filepath.Join(overlay, "mayor", "scratch")); err != nil {
+		t.Error("second Ensure() rebuilt the overlay")
+	}
+	if err := Reset(town); err != nil {
+		t.Fatal(err)
+	}
+	if IsOverlay(overlay) {
+		t.Error("overlay still present after Reset")
+	}
+}
@@ -0,0 +1,381 @@
+// Package sbom reads software bills of materials and checks the licenses of
+// the components in them against a policy.
+//
+// CycloneDX and SPDX JSON documents are supported, which covers the output
+// of the common scanners (syft, trivy, cdxgen). The merge queue diffs the
+// SBOM of an MR's branch against its target's so only dependencies the MR
+// adds or relicenses are judged.
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Component is a dependency listed in an SBOM.
+type Component struct {
+	Name     string   `json:"name"`
+	Version  string   `json:"version,omitempty"`
+	PURL     string   `json:"purl,omitempty"`
+	Licenses []string `json:"licenses,omitempty"` // SPDX IDs or expressions
+}
+
+// key identifies a component across versions.
+func (c Component) key() string {
+	if c.PURL != "" {
+		// pkg:golang/github.com/foo/bar@v1.2.3 -> pkg:golang/github.com/foo/bar
+		p, _, _ := strings.Cut(c.PURL, "@")
+		p, _, _ = strings.Cut(p, "?")
+		return p
+	}
+	return c.Name
+}
+
+// cycloneDX is the subset of a CycloneDX JSON document gt reads.
+type cycloneDX struct {
+	BOMFormat  string `json:"bomFormat"`
+	Components []struct {
+		Name     string `json:"name"`
+		Group    string `json:"group"`
+		Version  string `json:"version"`
+		PURL     string `json:"purl"`
+		Licenses []struct {
+			License *struct {
+				ID   string `json:"id"`
+				Name string `json:"name"`
+			} `json:"license"`
+			Expression string `json:"expression"`
+		} `json:"licenses"`
+	} `json:"components"`
+}
+
+// spdx is the subset of an SPDX JSON document gt reads.
+type spdx struct {
+	SPDXVersion string `json:"spdxVersion"`
+	Packages    []struct {
+		Name             string `json:"name"`
+		Version          string `json:"versionInfo"`
+		LicenseConcluded string `json:"licenseConcluded"`
+		LicenseDeclared  string `json:"licenseDeclared"`
+		ExternalRefs     []struct {
+			Type    string `json:"referenceType"`
+			Locator string `json:"referenceLocator"`
+		} `json:"externalRefs"`
+	} `json:"packages"`
+}
+
+// Parse reads a CycloneDX or SPDX JSON document.
+func Parse(data []byte) ([]Component, error) {
+	var probe struct {
+		BOMFormat   string `json:"bomFormat"`
+		SPDXVersion string `json:"spdxVersion"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("parsing SBOM: %w", err)
+	}
+	switch {
+	case probe.BOMFormat == "CycloneDX":
+		return parseCycloneDX(data)
+	case probe.SPDXVersion != "":
+		return parseSPDX(data)
+	}
+	return nil, fmt.Errorf("unrecognized SBOM: want CycloneDX or SPDX JSON")
+}
+
+func parseCycloneDX(data []byte) ([]Component, error) {
+	var doc cycloneDX
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing CycloneDX SBOM: %w", err)
+	}
+	comps := make([]Component, 0, len(doc.Components))
+	for _, c := range doc.Components {
+		comp := Component{Name: c.Name, Version: c.Version, PURL: c.PURL}
+		if c.Group != "" {
+			comp.Name = c.Group + "/" + c.Name
+		}
+		for _, l := range c.Licenses {
+			switch {
+			case l.Expression != "":
+				comp.Licenses = append(comp.Licenses, l.Expression)
+			case l.License != nil && l.License.ID != "":
+				comp.Licenses = append(comp.Licenses, l.License.ID)
+			case l.License != nil && l.License.Name != "":
+				comp.Licenses = append(comp.Licenses, l.License.Name)
+			}
+		}
+		comps = append(comps, comp)
+	}
+	return comps, nil
+}
+
+func parseSPDX(data []byte) ([]Component, error) {
+	var doc spdx
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing SPDX SBOM: %w", err)
+	}
+	comps := make([]Component, 0, len(doc.Packages))
+	for _, p := range doc.Packages {
+		comp := Component{Name: p.Name, Version: p.Version}
+		for _, ref := range p.ExternalRefs {
+			if ref.Type == "purl" {
+				comp.PURL = ref.Locator
+			}
+		}
+		// The concluded license is the scanner's verdict; fall back to
+		// what the package declares.
+		for _, l := range []string{p.LicenseConcluded, p.LicenseDeclared} {
+			if l != "" && l != "NOASSERTION" && l != "NONE" {
+				comp.Licenses = []string{l}
+				break
+			}
+		}
+		comps = append(comps, comp)
+	}
+	return comps, nil
+}
+
+// Changed returns the components of head that base doesn't have, or whose
+// licenses differ from base's, sorted by name. A nil base treats every
+// component as new.
+func Changed(base, head []Component) []Component {
+	known := make(map[string]string, len(base))
+	for _, c := range base {
+		known[c.key()] = licenseKey(c.Licenses)
+	}
+	var changed []Component
+	seen := make(map[string]bool)
+	for _, c := range head {
+		k := c.key()
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		if lic, ok := known[k]; ok && lic == licenseKey(c.Licenses) {
+			continue
+		}
+		changed = append(changed, c)
+	}
+	sort.Slice(changed, func(i, j int) bool { return changed[i].Name < changed[j].Name })
+	return changed
+}
+
+func licenseKey(licenses []string) string {
+	sorted := append([]string(nil), licenses...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, "|")
+}
+
+// What a policy does with a component that has no license information.
+const (
+	UnknownWarn  = "warn" // report it; don't block (default)
+	UnknownBlock = "block"
+	UnknownAllow = "allow"
+)
+
+// Policy says which licenses may be merged. Entries are SPDX IDs and may
+// use globs ("BSD-*"); matching ignores case.
+type Policy struct {
+	Allow   []string // if set, only these licenses are allowed
+	Deny    []string // never allowed, even if also in Allow
+	Unknown string   // UnknownWarn, UnknownBlock, or UnknownAllow
+}
+
+// Verdicts for a component.
+const (
+	VerdictAllowed = "allowed"
+	VerdictDenied  = "denied"
+	VerdictUnknown = "unknown" // no license information
+)
+
+// Finding is the verdict on one component.
+type Finding struct {
+	Component
+	Verdict string `json:"verdict"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// Check judges one component. Every license listed for it must be
+// satisfied; an SPDX expression is satisfied when one of its OR
+// alternatives has every AND term allowed.
+func (p Policy) Check(c Component) Finding {
+	if len(c.Licenses) == 0 {
+		return Finding{Component: c, Verdict: VerdictUnknown, Reason: "no license information"}
+	}
+	for _, expr := range c.Licenses {
+		if !p.expressionAllowed(expr) {
+			return Finding{Component: c, Verdict: VerdictDenied, Reason: fmt.Sprintf("license %s not allowed", expr)}
+		}
+	}
+	return Finding{Component: c, Verdict: VerdictAllowed}
+}
+
+func (p Policy) expressionAllowed(expr string) bool {
+	tokens := strings.Fields(strings.NewReplacer("(", " ( ", ")", " ) ").Replace(expr))
+	e := &exprEval{tokens: tokens, allowed: p.licenseAllowed}
+	ok := e.or()
+	// An expression that doesn't parse is never allowed.
+	return ok && !e.malformed && e.pos == len(tokens)
+}
+
+// exprEval evaluates an SPDX license expression, where AND binds tighter
+// than OR, as it parses it.
+type exprEval struct {
+	tokens    []string
+	pos       int
+	malformed bool
+	allowed   func(id string) bool
+}
+
+func (e *exprEval) peek(word string) bool {
+	return e.pos < len(e.tokens) && strings.EqualFold(e.tokens[e.pos], word)
+}
+
+func (e *exprEval) or() bool {
+	ok := e.and()
+	for e.peek("OR") {
+		e.pos++
+		// Evaluate both sides so the whole expression is consumed.
+		right := e.and()
+		ok = ok || right
+	}
+	return ok
+}
+
+func (e *exprEval) and() bool {
+	ok := e.atom()
+	for e.peek("AND") {
+		e.pos++
+		right := e.atom()
+		ok = ok && right
+	}
+	return ok
+}
+
+func (e *exprEval) atom() bool {
+	if e.pos >= len(e.tokens) || e.peek(")") {
+		e.malformed = true
+		return false
+	}
+	if e.peek("(") {
+		e.pos++
+		ok := e.or()
+		if !e.peek(")") {
+			e.malformed = true
+			return false
+		}
+		e.pos++
+		return ok
+	}
+	id := e.tokens[e.pos]
+	e.pos++
+	// "GPL-2.0 WITH Classpath-exception-2.0" is judged by its license.
+	if e.peek("WITH") {
+		e.pos += 2
+	}
+	return e.allowed(id)
+}
+
+func (p Policy) licenseAllowed(id string) bool {
+	if id == "" || matchAny(id, p.Deny) {
+		return false
+	}
+	return len(p.Allow) == 0 || matchAny(id, p.Allow)
+}
+
+func matchAny(id string, patterns []string) bool {
+	id = strings.ToLower(id)
+	for _, pat := range patterns {
+		if ok, _ := path.Match(strings.ToLower(pat), id); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Report is the result of checking an MR's dependencies.
+type Report struct {
+	Scanned  int       `json:"scanned"`  // components in the branch's SBOM
+	Baseline bool      `json:"baseline"` // whether a target SBOM was diffed against
+	Findings []Finding `json:"findings"` // one per new or relicensed component
+	Blocked  bool      `json:"blocked"`
+}
+
+// Evaluate checks the components head adds or relicenses relative to base
+// (every component when base is nil) against the policy.
+func Evaluate(base, head []Component, p Policy) *Report {
+	r := &Report{Scanned: len(head), Baseline: base != nil, Findings: []Finding{}}
+	for _, c := range Changed(base, head) {
+		f := p.Check(c)
+		switch {
+		case f.Verdict == VerdictDenied:
+			r.Blocked = true
+		case f.Verdict == VerdictUnknown && p.Unknown == UnknownBlock:
+			r.Blocked = true
+		case f.Verdict == VerdictUnknown && p.Unknown == UnknownAllow:
+			continue
+		}
+		r.Findings = append(r.Findings, f)
+	}
+	return r
+}
+
+// Count returns how many findings have the verdict.
+func (r *Report) Count(verdict string) int {
+	n := 0
+	for _, f := range r.Findings {
+		if f.Verdict == verdict {
+			n++
+		}
+	}
+	return n
+}
+
+// Status summarizes the report for the MR bead: "blocked", "warned" (some
+// components lack license information), or "passed".
+func (r *Report) Status() string {
+	switch {
+	case r.Blocked:
+		return "blocked"
+	case r.Count(VerdictUnknown) > 0:
+		return "warned"
+	}
+	return "passed"
+}
+
+// Markdown renders the report for the MR bead, listing at most max
+// findings, problems first.
+func (r *Report) Markdown(max int) string {
+	var sb strings.Builder
+	scope := "new or relicensed"
+	if !r.Baseline {
+		scope = "all (no target baseline)"
+	}
+	fmt.Fprintf(&sb, "License scan: %s — %d components, %d %s checked\n", r.Status(), r.Scanned, len(r.Findings), scope)
+
+	findings := append([]Finding(nil), r.Findings...)
+	rank := map[string]int{VerdictDenied: 0, VerdictUnknown: 1, VerdictAllowed: 2}
+	sort.SliceStable(findings, func(i, j int) bool { return rank[findings[i].Verdict] < rank[findings[j].Verdict] })
+	for i, f := range findings {
+		if i == max {
+			fmt.Fprintf(&sb, "- ... and %d more\n", len(findings)-max)
+			break
+		}
+		name := f.Name
+		if f.Version != "" {
+			name += "@" + f.Version
+		}
+		lic := strings.Join(f.Licenses, ", ")
+		if lic == "" {
+			lic = "?"
+		}
+		fmt.Fprintf(&sb, "- %s %s (%s)", f.Verdict, name, lic)
+		if f.Verdict != VerdictAllowed && f.Reason != "" {
+			fmt.Fprintf(&sb, ": %s", f.Reason)
+		}
+		sb.WriteString("\n")
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -0,0 +1,166 @@
+package sbom
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const cycloneDXDoc = `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.5",
+  "components": [
+    {"name": "cobra", "group": "github.com/spf13", "version": "v1.8.0",
+     "purl": "pkg:golang/github.com/spf13/cobra@v1.8.0",
+     "licenses": [{"license": {"id": "Apache-2.0"}}]},
+    {"name": "left-pad", "version": "1.3.0", "purl": "pkg:npm/left-pad@1.3.0",
+     "licenses": [{"expression": "MIT OR GPL-3.0-only"}]},
+    {"name": "mystery", "version": "0.1.0"}
+  ]
+}`
+
+const spdxDoc = `{
+  "spdxVersion": "SPDX-2.3",
+  "packages": [
+    {"name": "cobra", "versionInfo": "v1.8.0", "licenseConcluded": "NOASSERTION", "licenseDeclared": "Apache-2.0",
+     "externalRefs": [{"referenceType": "purl", "referenceLocator": "pkg:golang/github.com/spf13/cobra@v1.8.0"}]},
+    {"name": "readline", "versionInfo": "8.2", "licenseConcluded": "GPL-3.0-or-later"},
+    {"name": "blob", "versionInfo": "1", "licenseConcluded": "NOASSERTION"}
+  ]
+}`
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want []Component
+	}{
+		{"cyclonedx", cycloneDXDoc, []Component{
+			{Name: "github.com/spf13/cobra", Version: "v1.8.0", PURL: "pkg:golang/github.com/spf13/cobra@v1.8.0", Licenses: []string{"Apache-2.0"}},
+			{Name: "left-pad", Version: "1.3.0", PURL: "pkg:npm/left-pad@1.3.0", Licenses: []string{"MIT OR GPL-3.0-only"}},
+			{Name: "mystery", Version: "0.1.0"},
+		}},
+		{"spdx", spdxDoc, []Component{
+			{Name: "cobra", Version: "v1.8.0", PURL: "pkg:golang/github.com/spf13/cobra@v1.8.0", Licenses: []string{"Apache-2.0"}},
+			{Name: "readline", Version: "8.2", Licenses: []string{"GPL-3.0-or-later"}},
+			{Name: "blob", Version: "1"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.doc))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Parse([]byte(`{"packages": []}`)); err == nil {
+		t.Error("Parse accepted a document that is neither CycloneDX nor SPDX")
+	}
+}
+
+func TestChanged(t *testing.T) {
+	base := []Component{
+		{Name: "a", Version: "1.0", PURL: "pkg:golang/a@1.0", Licenses: []string{"MIT"}},
+		{Name: "b", Version: "1.0", PURL: "pkg:golang/b@1.0", Licenses: []string{"MIT"}},
+	}
+	head := []Component{
+		{Name: "a", Version: "1.1", PURL: "pkg:golang/a@1.1", Licenses: []string{"MIT"}},      // bumped, same license
+		{Name: "b", Version: "2.0", PURL: "pkg:golang/b@2.0", Licenses: []string{"BUSL-1.1"}}, // relicensed
+		{Name: "c", Version: "1.0", PURL: "pkg:golang/c@1.0", Licenses: []string{"MIT"}},      // new
+	}
+	var names []string
+	for _, c := range Changed(base, head) {
+		names = append(names, c.Name)
+	}
+	if want := []string{"b", "c"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Changed() = %v, want %v", names, want)
+	}
+	if got := Changed(nil, head); len(got) != 3 {
+		t.Errorf("Changed(nil) returned %d components, want all 3", len(got))
+	}
+}
+
+func TestPolicyCheck(t *testing.T) {
+	policy := Policy{
+		Allow: []string{"MIT", "Apache-2.0", "BSD-*", "GPL-2.0-only"},
+		Deny:  []string{"BSD-4-Clause"},
+	}
+	tests := []struct {
+		licenses []string
+		want     string
+	}{
+		{[]string{"MIT"}, VerdictAllowed},
+		{[]string{"mit"}, VerdictAllowed},
+		{[]string{"BSD-3-Clause"}, VerdictAllowed},
+		{[]string{"BSD-4-Clause"}, VerdictDenied},
+		{[]string{"GPL-3.0-only"}, VerdictDenied},
+		{[]string{"MIT OR GPL-3.0-only"}, VerdictAllowed},
+		{[]string{"MIT AND GPL-3.0-only"}, VerdictDenied},
+		{[]string{"(MIT OR Apache-2.0) AND BSD-2-Clause"}, VerdictAllowed},
+		{[]string{"(MIT OR Apache-2.0) AND AGPL-3.0"}, VerdictDenied},
+		{[]string{"MIT OR Apache-2.0 AND AGPL-3.0"}, VerdictAllowed}, // AND binds tighter
+		{[]string{"GPL-2.0-only WITH Classpath-exception-2.0"}, VerdictAllowed},
+		{[]string{"MIT", "GPL-3.0-only"}, VerdictDenied},
+		{[]string{"MIT OR"}, VerdictDenied},
+		{nil, VerdictUnknown},
+	}
+	for _, tt := range tests {
+		got := policy.Check(Component{Name: "x", Licenses: tt.licenses})
+		if got.Verdict != tt.want {
+			t.Errorf("Check(%q) = %s (%s), want %s", tt.licenses, got.Verdict, got.Reason, tt.want)
+		}
+	}
+
+	// With no allow list, anything not denied passes.
+	open := Policy{Deny: []string{"AGPL-*"}}
+	if got := open.Check(Component{Licenses: []string{"WTFPL"}}); got.Verdict != VerdictAllowed {
+		t.Errorf("deny-only policy: WTFPL = %s", got.Verdict)
+	}
+	if got := open.Check(Component{Licenses: []string{"AGPL-3.0-only"}}); got.Verdict != VerdictDenied {
+		t.Errorf("deny-only policy: AGPL-3.0-only = %s", got.Verdict)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	base := []Component{{Name: "old", Licenses: []string{"GPL-3.0-only"}}}
+	head := []Component{
+		{Name: "old", Licenses: []string{"GPL-3.0-only"}}, // already on the target: not judged
+		{Name: "new-mit", Licenses: []string{"MIT"}},
+		{Name: "new-unknown"},
+	}
+	policy := Policy{Allow: []string{"MIT"}}
+
+	tests := []struct {
+		unknown    string
+		findings   int
+		wantStatus string
+	}{
+		{UnknownWarn, 2, "warned"},
+		{UnknownBlock, 2, "blocked"},
+		{UnknownAllow, 1, "passed"},
+	}
+	for _, tt := range tests {
+		policy.Unknown = tt.unknown
+		r := Evaluate(base, head, policy)
+		if len(r.Findings) != tt.findings || r.Status() != tt.wantStatus || r.Scanned != 3 || !r.Baseline {
+			t.Errorf("unknown=%s: %d findings, status %s, %+v", tt.unknown, len(r.Findings), r.Status(), r)
+		}
+	}
+
+	r := Evaluate(nil, head, Policy{Allow: []string{"MIT"}})
+	if !r.Blocked || r.Count(VerdictDenied) != 1 {
+		t.Errorf("without a baseline every component is judged: %+v", r)
+	}
+	md := r.Markdown(10)
+	if !strings.HasPrefix(md, "License scan: blocked") || !strings.Contains(md, "no target baseline") {
+		t.Errorf("Markdown() = %q", md)
+	}
+	if i, j := strings.Index(md, "denied old"), strings.Index(md, "allowed new-mit"); i < 0 || j < 0 || i > j {
+		t.Errorf("Markdown() should list denials first:\n%s", md)
+	}
+}
@@ -0,0 +1,280 @@
+// Package scorecard computes per-agent performance metrics from history:
+// issues completed, how often their merge requests are accepted, how many
+// review rounds those take, how often merged work is reverted, and what
+// each completed issue cost. Scorecards can be rolled up by role or model
+// to compare how well each handles the work it's given.
+package scorecard
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Groupings for Rollup.
+const (
+	ByAgent = "agent"
+	ByRole  = "role"
+	ByModel = "model"
+)
+
+// Completion is an issue an agent finished (a done event).
+type Completion struct {
+	Agent string
+	Issue string
+}
+
+// MergeRequest is an MR an agent submitted and what came of it.
+type MergeRequest struct {
+	ID       string
+	Agent    string
+	Merged   bool
+	Rejected bool // closed without merging, other than superseded
+	Reviews  int  // review verdicts posted on it
+	Reverted bool
+}
+
+// Cost is one session's cost, attributed to the agent that ran it.
+type Cost struct {
+	Agent string
+	Role  string
+	Model string
+	USD   float64
+}
+
+// Card is one agent's (or one role's or model's) scorecard.
+type Card struct {
+	Name      string   `json:"name"`
+	Role      string   `json:"role,omitempty"`
+	Models    []string `json:"models,omitempty"`
+	Agents    int      `json:"agents,omitempty"` // rollups only
+	Completed int      `json:"completed"`
+	MRs       int      `json:"merge_requests"`
+	Merged    int      `json:"merged"`
+	Rejected  int      `json:"rejected"`
+	Reviewed  int      `json:"reviewed"` // MRs with at least one review
+	Reviews   int      `json:"reviews"`
+	Reverted  int      `json:"reverted"`
+	CostUSD   float64  `json:"cost_usd"`
+
+	// Derived from the counts above; zero when undefined.
+	AcceptanceRate   float64 `json:"acceptance_rate"`
+	ReviewIterations float64 `json:"avg_review_iterations"`
+	RevertRate       float64 `json:"revert_rate"`
+	CostPerIssue     float64 `json:"cost_per_issue_usd"`
+
+	modelCost map[string]float64
+}
+
+// Build computes a scorecard per agent, sorted by issues completed, most
+// first. An agent's role is taken from its address, or else from its
+// cost records.
+func Build(completions []Completion, mrs []MergeRequest, costs []Cost) []*Card {
+	cards := make(map[string]*Card)
+	card := func(agent string) *Card {
+		c, ok := cards[agent]
+		if !ok {
+			c = &Card{Name: agent, Role: RoleOf(agent), modelCost: make(map[string]float64)}
+			cards[agent] = c
+		}
+		return c
+	}
+
+	seen := make(map[Completion]bool)
+	for _, c := range completions {
+		if c.Agent == "" || seen[c] {
+			continue
+		}
+		seen[c] = true
+		card(c.Agent).Completed++
+	}
+	for _, mr := range mrs {
+		if mr.Agent == "" {
+			continue
+		}
+		c := card(mr.Agent)
+		c.MRs++
+		if mr.Merged {
+			c.Merged++
+			if mr.Reverted {
+				c.Reverted++
+			}
+		} else if mr.Rejected {
+			c.Rejected++
+		}
+		if mr.Reviews > 0 {
+			c.Reviewed++
+			c.Reviews += mr.Reviews
+		}
+	}
+	for _, cost := range costs {
+		if cost.Agent == "" {
+			continue
+		}
+		c := card(cost.Agent)
+		c.CostUSD += cost.USD
+		if cost.Model != "" {
+			c.modelCost[cost.Model] += cost.USD
+		}
+		if c.Role == "" {
+			c.Role = cost.Role
+		}
+	}
+
+	out := make([]*Card, 0, len(cards))
+	for _, c := range cards {
+		c.finish()
+		out = append(out, c)
+	}
+	sortCards(out)
+	return out
+}
+
+// Rollup combines agent scorecards by role or model. An agent counts
+// toward the model it spent the most on, or "unknown" without cost
+// records. by == ByAgent returns cards unchanged.
+func Rollup(cards []*Card, by string) []*Card {
+	if by == ByAgent {
+		return cards
+	}
+	groups := make(map[string]*Card)
+	for _, c := range cards {
+		key := c.Role
+		if by == ByModel {
+			key = c.primaryModel()
+		}
+		if key == "" {
+			key = "unknown"
+		}
+		g, ok := groups[key]
+		if !ok {
+			g = &Card{Name: key, modelCost: make(map[string]float64)}
+			groups[key] = g
+		}
+		g.Agents++
+		g.Completed += c.Completed
+		g.MRs += c.MRs
+		g.Merged += c.Merged
+		g.Rejected += c.Rejected
+		g.Reviewed += c.Reviewed
+		g.Reviews += c.Reviews
+		g.Reverted += c.Reverted
+		g.CostUSD += c.CostUSD
+		for m, usd := range c.modelCost {
+			g.modelCost[m] += usd
+		}
+	}
+	out := make([]*Card, 0, len(groups))
+	for _, g := range groups {
+		g.finish()
+		out = append(out, g)
+	}
+	sortCards(out)
+	return out
+}
+
+// RoleOf returns the role in an agent address: "polecat" for
+// gastown/polecats/furiosa, "refinery" for gastown/refinery, "mayor" for
+// mayor. Returns "" for addresses it doesn't recognize.
+func RoleOf(agent string) string {
+	parts := strings.Split(strings.TrimSuffix(agent, "/"), "/")
+	switch {
+	case len(parts) == 3 && parts[1] == "polecats":
+		return "polecat"
+	case len(parts) == 3 && parts[1] == "crew":
+		return "crew"
+	case len(parts) == 2 && (parts[1] == "witness" || parts[1] == "refinery"):
+		return parts[1]
+	case len(parts) == 1 && (parts[0] == "mayor" || parts[0] == "deacon"):
+		return parts[0]
+	}
+	return ""
+}
+
+// finish fills in the models and derived rates.
+func (c *Card) finish() {
+	c.Models = c.Models[:0]
+	for m := range c.modelCost {
+		c.Models = append(c.Models, m)
+	}
+	sort.Strings(c.Models)
+	if len(c.Models) == 0 {
+		c.Models = nil
+	}
+	c.AcceptanceRate = ratio(c.Merged, c.Merged+c.Rejected)
+	c.ReviewIterations = ratio(c.Reviews, c.Reviewed)
+	c.RevertRate = ratio(c.Reverted, c.Merged)
+	if c.Completed > 0 {
+		c.CostPerIssue = c.CostUSD / float64(c.Completed)
+	}
+}
+
+// primaryModel returns the model the card spent the most on.
+func (c *Card) primaryModel() string {
+	best := ""
+	for _, m := range c.Models {
+		if best == "" || c.modelCost[m] > c.modelCost[best] {
+			best = m
+		}
+	}
+	return best
+}
+
+func ratio(n, d int) float64 {
+	if d == 0 {
+		return 0
+	}
+	return float64(n) / float64(d)
+}
+
+// sortCards orders cards by issues completed, then merged MRs, then name.
+func sortCards(cards []*Card) {
+	sort.Slice(cards, func(i, j int) bool {
+		a, b := cards[i], cards[j]
+		if a.Completed != b.Completed {
+			return a.Completed > b.Completed
+		}
+		if a.Merged != b.Merged {
+			return a.Merged > b.Merged
+		}
+		return a.Name < b.Name
+	})
+}
+
+// Markdown renders scorecards as a Markdown table, for reports.
+func Markdown(cards []*Card, by string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "| %s | Completed | MRs | Accepted | Review rounds | Reverted | Cost/issue |\n", strings.ToUpper(by[:1])+by[1:])
+	sb.WriteString("|---|---:|---:|---:|---:|---:|---:|\n")
+	for _, c := range cards {
+		fmt.Fprintf(&sb, "| %s | %d | %d | %s | %s | %s | %s |\n",
+			c.Name, c.Completed, c.MRs, Percent(c.AcceptanceRate, c.Merged+c.Rejected),
+			Rounds(c.ReviewIterations, c.Reviewed), Percent(c.RevertRate, c.Merged), Dollars(c.CostPerIssue, c.Completed))
+	}
+	return sb.String()
+}
+
+// Percent formats a rate, or "-" when it has no basis (n == 0).
+func Percent(rate float64, n int) string {
+	if n == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%%", rate*100)
+}
+
+// Rounds formats average review iterations, or "-" when nothing was
+// reviewed.
+func Rounds(avg float64, reviewed int) string {
+	if reviewed == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f", avg)
+}
+
+// Dollars formats a cost per issue, or "-" when no issues were completed.
+func Dollars(usd float64, completed int) string {
+	if completed == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("$%.2f", usd)
+}
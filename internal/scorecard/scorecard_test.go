@@ -0,0 +1,78 @@
+package scorecard
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuild(t *testing.T) {
+	completions := []Completion{
+		{Agent: "gastown/polecats/furiosa", Issue: "gt-1"},
+		{Agent: "gastown/polecats/furiosa", Issue: "gt-1"}, // done twice, counts once
+		{Agent: "gastown/polecats/furiosa", Issue: "gt-2"},
+		{Agent: "gastown/crew/max", Issue: "gt-3"},
+	}
+	mrs := []MergeRequest{
+		{ID: "mr1", Agent: "gastown/polecats/furiosa", Merged: true, Reviews: 1},
+		{ID: "mr2", Agent: "gastown/polecats/furiosa", Merged: true, Reviews: 3, Reverted: true},
+		{ID: "mr3", Agent: "gastown/polecats/furiosa", Rejected: true},
+		{ID: "mr4", Agent: "gastown/polecats/furiosa"}, // still open
+		{ID: "mr5", Agent: "gastown/crew/max", Merged: true},
+	}
+	costs := []Cost{
+		{Agent: "gastown/polecats/furiosa", Role: "polecat", Model: "opus", USD: 3},
+		{Agent: "gastown/polecats/furiosa", Role: "polecat", Model: "sonnet", USD: 1},
+		{Agent: "gastown/crew/max", Role: "crew", Model: "sonnet", USD: 2},
+	}
+
+	cards := Build(completions, mrs, costs)
+	if len(cards) != 2 || cards[0].Name != "gastown/polecats/furiosa" {
+		t.Fatalf("cards = %+v", cards)
+	}
+	f := cards[0]
+	if f.Role != "polecat" || f.Completed != 2 || f.MRs != 4 || f.Merged != 2 || f.Rejected != 1 {
+		t.Errorf("counts = %+v", f)
+	}
+	if f.AcceptanceRate < 0.66 || f.AcceptanceRate > 0.67 {
+		t.Errorf("AcceptanceRate = %v, want 2/3", f.AcceptanceRate)
+	}
+	if f.ReviewIterations != 2 || f.RevertRate != 0.5 || f.CostPerIssue != 2 {
+		t.Errorf("ReviewIterations = %v, RevertRate = %v, CostPerIssue = %v", f.ReviewIterations, f.RevertRate, f.CostPerIssue)
+	}
+	if strings.Join(f.Models, ",") != "opus,sonnet" {
+		t.Errorf("Models = %v", f.Models)
+	}
+
+	byModel := Rollup(cards, ByModel)
+	if len(byModel) != 2 || byModel[0].Name != "opus" || byModel[0].Agents != 1 || byModel[1].Name != "sonnet" {
+		t.Errorf("by model = %+v", byModel)
+	}
+	byRole := Rollup(cards, ByRole)
+	if len(byRole) != 2 || byRole[0].Name != "polecat" || byRole[1].Name != "crew" {
+		t.Errorf("by role = %+v", byRole)
+	}
+
+	md := Markdown(cards, ByAgent)
+	for _, want := range []string{"| Agent | Completed", "| gastown/polecats/furiosa | 2 | 4 | 67% | 2.0 | 50% | $2.00 |", "| gastown/crew/max | 1 | 1 | 100% | - | 0% | $2.00 |"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown missing %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestRoleOf(t *testing.T) {
+	tests := map[string]string{
+		"gastown/polecats/furiosa": "polecat",
+		"gastown/crew/max":         "crew",
+		"gastown/refinery":         "refinery",
+		"gastown/witness":          "witness",
+		"mayor":                    "mayor",
+		"deacon/":                  "deacon",
+		"somebody":                 "",
+	}
+	for agent, want := range tests {
+		if got := RoleOf(agent); got != want {
+			t.Errorf("RoleOf(%q) = %q, want %q", agent, got, want)
+		}
+	}
+}
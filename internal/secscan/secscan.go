@@ -0,0 +1,386 @@
+// Package secscan reads the output of static analysis and security scanners
+// and decides which findings block a merge.
+//
+// gosec and semgrep JSON are read natively; any other scanner can be used
+// if it writes SARIF. The merge queue diffs an MR's findings against its
+// target's recorded findings so only what the MR introduces is judged.
+package secscan
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Output formats a scanner can write.
+const (
+	FormatGosec   = "gosec"
+	FormatSemgrep = "semgrep"
+	FormatSARIF   = "sarif"
+)
+
+// Severities, lowest first.
+const (
+	SeverityLow      = "low"
+	SeverityMedium   = "medium"
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
+)
+
+var severityRank = map[string]int{
+	SeverityLow:      1,
+	SeverityMedium:   2,
+	SeverityHigh:     3,
+	SeverityCritical: 4,
+}
+
+// ValidSeverity reports whether s is a known severity.
+func ValidSeverity(s string) bool {
+	return severityRank[s] > 0
+}
+
+// AtLeast reports whether severity meets threshold. An empty threshold is
+// never met.
+func AtLeast(severity, threshold string) bool {
+	return threshold != "" && severityRank[severity] >= severityRank[threshold]
+}
+
+// Presets are the default commands for the scanners gt knows by name.
+var Presets = map[string]string{
+	FormatGosec:   "gosec -fmt=json -quiet ./...",
+	FormatSemgrep: "semgrep scan --config auto --json --quiet",
+}
+
+// Finding is one problem reported by a scanner.
+type Finding struct {
+	Scanner  string `json:"scanner"`
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+// key identifies a finding across commits. Lines move as code is edited,
+// so they aren't part of it.
+func (f Finding) key() string {
+	return f.Scanner + "|" + f.Rule + "|" + f.File
+}
+
+// Location renders the finding's position as "file:line".
+func (f Finding) Location() string {
+	if f.Line > 0 {
+		return fmt.Sprintf("%s:%d", f.File, f.Line)
+	}
+	return f.File
+}
+
+// Parse reads a scanner's output in the given format. Paths under root are
+// made relative to it, so findings compare across checkouts.
+func Parse(format, scanner string, data []byte, root string) ([]Finding, error) {
+	var findings []Finding
+	var err error
+	switch format {
+	case FormatGosec:
+		findings, err = parseGosec(data)
+	case FormatSemgrep:
+		findings, err = parseSemgrep(data)
+	case FormatSARIF:
+		findings, err = parseSARIF(data)
+	default:
+		return nil, fmt.Errorf("unknown format %q: want %s, %s, or %s", format, FormatGosec, FormatSemgrep, FormatSARIF)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range findings {
+		findings[i].Scanner = scanner
+		findings[i].File = relativePath(root, findings[i].File)
+	}
+	return findings, nil
+}
+
+func relativePath(root, path string) string {
+	path = strings.TrimPrefix(path, "file://")
+	if root != "" && filepath.IsAbs(path) {
+		if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") {
+			path = rel
+		}
+	}
+	return filepath.ToSlash(path)
+}
+
+func parseGosec(data []byte) ([]Finding, error) {
+	var doc struct {
+		Issues *[]struct {
+			Severity string `json:"severity"`
+			RuleID   string `json:"rule_id"`
+			Details  string `json:"details"`
+			File     string `json:"file"`
+			Line     string `json:"line"` // "12" or "12-14"
+		} `json:"Issues"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing gosec output: %w", err)
+	}
+	if doc.Issues == nil {
+		return nil, fmt.Errorf("parsing gosec output: no Issues list (run gosec with -fmt=json)")
+	}
+	findings := make([]Finding, 0, len(*doc.Issues))
+	for _, is := range *doc.Issues {
+		first, _, _ := strings.Cut(is.Line, "-")
+		line, _ := strconv.Atoi(first)
+		findings = append(findings, Finding{
+			Rule:     is.RuleID,
+			Severity: normalizeSeverity(is.Severity),
+			File:     is.File,
+			Line:     line,
+			Message:  is.Details,
+		})
+	}
+	return findings, nil
+}
+
+func parseSemgrep(data []byte) ([]Finding, error) {
+	var doc struct {
+		Results *[]struct {
+			CheckID string `json:"check_id"`
+			Path    string `json:"path"`
+			Start   struct {
+				Line int `json:"line"`
+			} `json:"start"`
+			Extra struct {
+				Message  string `json:"message"`
+				Severity string `json:"severity"`
+			} `json:"extra"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing semgrep output: %w", err)
+	}
+	if doc.Results == nil {
+		return nil, fmt.Errorf("parsing semgrep output: no results list (run semgrep with --json)")
+	}
+	findings := make([]Finding, 0, len(*doc.Results))
+	for _, r := range *doc.Results {
+		findings = append(findings, Finding{
+			Rule:     r.CheckID,
+			Severity: normalizeSeverity(r.Extra.Severity),
+			File:     r.Path,
+			Line:     r.Start.Line,
+			Message:  r.Extra.Message,
+		})
+	}
+	return findings, nil
+}
+
+type sarifRule struct {
+	ID         string `json:"id"`
+	Properties struct {
+		SecuritySeverity string `json:"security-severity"`
+	} `json:"properties"`
+	DefaultConfiguration struct {
+		Level string `json:"level"`
+	} `json:"defaultConfiguration"`
+}
+
+func parseSARIF(data []byte) ([]Finding, error) {
+	var doc struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []sarifRule `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID  string `json:"ruleId"`
+				Level   string `json:"level"`
+				Message struct {
+					Text string `json:"text"`
+				} `json:"message"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+						Region struct {
+							StartLine int `json:"startLine"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing SARIF: %w", err)
+	}
+	if doc.Version == "" {
+		return nil, fmt.Errorf("parsing SARIF: no version (is this a SARIF log?)")
+	}
+	var findings []Finding
+	for _, run := range doc.Runs {
+		rules := make(map[string]sarifRule, len(run.Tool.Driver.Rules))
+		for _, r := range run.Tool.Driver.Rules {
+			rules[r.ID] = r
+		}
+		for _, res := range run.Results {
+			rule := rules[res.RuleID]
+			f := Finding{Rule: res.RuleID, Message: res.Message.Text}
+			if len(res.Locations) > 0 {
+				loc := res.Locations[0].PhysicalLocation
+				f.File = loc.ArtifactLocation.URI
+				f.Line = loc.Region.StartLine
+			}
+			f.Severity = sarifSeverity(rule, res.Level)
+			findings = append(findings, f)
+		}
+	}
+	return findings, nil
+}
+
+// sarifSeverity prefers the rule's CVSS-style security-severity score, as
+// GitHub code scanning does, then the result's level.
+func sarifSeverity(rule sarifRule, level string) string {
+	if score, err := strconv.ParseFloat(rule.Properties.SecuritySeverity, 64); err == nil {
+		switch {
+		case score >= 9:
+			return SeverityCritical
+		case score >= 7:
+			return SeverityHigh
+		case score >= 4:
+			return SeverityMedium
+		}
+		return SeverityLow
+	}
+	if level == "" {
+		level = rule.DefaultConfiguration.Level
+	}
+	return normalizeSeverity(level)
+}
+
+// normalizeSeverity maps the scanners' vocabularies onto gt's severities.
+// Anything unrecognized is medium.
+func normalizeSeverity(s string) string {
+	switch strings.ToLower(s) {
+	case "critical":
+		return SeverityCritical
+	case "high", "error":
+		return SeverityHigh
+	case "low", "info", "note", "none":
+		return SeverityLow
+	}
+	return SeverityMedium
+}
+
+// Introduced returns the findings of head that base doesn't account for.
+// Findings are matched by scanner, rule, and file; a file with more
+// findings of a rule than base had contributes the extras.
+func Introduced(base, head []Finding) []Finding {
+	known := make(map[string]int, len(base))
+	for _, f := range base {
+		known[f.key()]++
+	}
+	var introduced []Finding
+	for _, f := range head {
+		k := f.key()
+		if known[k] > 0 {
+			known[k]--
+			continue
+		}
+		introduced = append(introduced, f)
+	}
+	return introduced
+}
+
+// Result is one finding judged against the thresholds.
+type Result struct {
+	Finding
+	Blocking bool `json:"blocking"`
+}
+
+// ScannerRun summarizes one scanner's run.
+type ScannerRun struct {
+	Name     string `json:"name"`
+	Findings int    `json:"findings"` // total, including ones already on the target
+}
+
+// Report is the result of scanning an MR.
+type Report struct {
+	Scanners []ScannerRun `json:"scanners"`
+	Baseline bool         `json:"baseline"` // whether target findings were diffed against
+	Findings []Result     `json:"findings"` // introduced by the MR, most severe first
+	Blocked  bool         `json:"blocked"`
+}
+
+// Judge builds a report from the findings an MR introduced, blocking on
+// any at or above failOn. failOn may differ per scanner.
+func Judge(runs []ScannerRun, baseline bool, introduced []Finding, failOn func(scanner string) string) *Report {
+	r := &Report{Scanners: runs, Baseline: baseline, Findings: []Result{}}
+	if r.Scanners == nil {
+		r.Scanners = []ScannerRun{}
+	}
+	for _, f := range introduced {
+		res := Result{Finding: f, Blocking: AtLeast(f.Severity, failOn(f.Scanner))}
+		r.Blocked = r.Blocked || res.Blocking
+		r.Findings = append(r.Findings, res)
+	}
+	sort.SliceStable(r.Findings, func(i, j int) bool {
+		return severityRank[r.Findings[i].Severity] > severityRank[r.Findings[j].Severity]
+	})
+	return r
+}
+
+// Deferred returns the non-blocking findings at or above threshold: the
+// ones that land unfixed and should be tracked.
+func (r *Report) Deferred(threshold string) []Finding {
+	var deferred []Finding
+	for _, res := range r.Findings {
+		if !res.Blocking && AtLeast(res.Severity, threshold) {
+			deferred = append(deferred, res.Finding)
+		}
+	}
+	return deferred
+}
+
+// Status summarizes the report for the MR bead: "blocked", "deferred" (the
+// MR adds findings below the blocking threshold), or "passed".
+func (r *Report) Status() string {
+	switch {
+	case r.Blocked:
+		return "blocked"
+	case len(r.Findings) > 0:
+		return "deferred"
+	}
+	return "passed"
+}
+
+// Markdown renders the report for the MR bead, listing at most max
+// findings.
+func (r *Report) Markdown(max int) string {
+	var sb strings.Builder
+	names := make([]string, 0, len(r.Scanners))
+	for _, s := range r.Scanners {
+		names = append(names, s.Name)
+	}
+	scope := "new"
+	if !r.Baseline {
+		scope = "total (no target baseline)"
+	}
+	fmt.Fprintf(&sb, "Security scan: %s — %d %s findings from %s\n", r.Status(), len(r.Findings), scope, strings.Join(names, ", "))
+	for i, f := range r.Findings {
+		if i == max {
+			fmt.Fprintf(&sb, "- ... and %d more\n", len(r.Findings)-max)
+			break
+		}
+		mark := ""
+		if f.Blocking {
+			mark = " (blocking)"
+		}
+		fmt.Fprintf(&sb, "- %s %s/%s at %s%s: %s\n", f.Severity, f.Scanner, f.Rule, f.Location(), mark, f.Message)
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
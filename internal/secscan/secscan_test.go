@@ -0,0 +1,145 @@
+package secscan
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const gosecOutput = `{
+  "Golang errors": {},
+  "Issues": [
+    {"severity": "HIGH", "confidence": "HIGH", "rule_id": "G101",
+     "details": "Potential hardcoded credentials", "file": "/work/rig/internal/auth/auth.go", "line": "12"},
+    {"severity": "MEDIUM", "confidence": "HIGH", "rule_id": "G304",
+     "details": "Potential file inclusion via variable", "file": "/work/rig/cmd/read.go", "line": "40-42"}
+  ],
+  "Stats": {"files": 2}
+}`
+
+const semgrepOutput = `{
+  "results": [
+    {"check_id": "python.lang.security.eval", "path": "app/run.py", "start": {"line": 7},
+     "extra": {"message": "eval of user input", "severity": "ERROR"}},
+    {"check_id": "python.lang.best-practice.open", "path": "app/io.py", "start": {"line": 3},
+     "extra": {"message": "file not closed", "severity": "INFO"}}
+  ],
+  "errors": []
+}`
+
+const sarifOutput = `{
+  "version": "2.1.0",
+  "runs": [{
+    "tool": {"driver": {"name": "custom", "rules": [
+      {"id": "SQLI", "properties": {"security-severity": "9.1"}},
+      {"id": "WEAKRAND", "defaultConfiguration": {"level": "note"}}
+    ]}},
+    "results": [
+      {"ruleId": "SQLI", "level": "warning", "message": {"text": "query built from input"},
+       "locations": [{"physicalLocation": {"artifactLocation": {"uri": "db/query.go"}, "region": {"startLine": 20}}}]},
+      {"ruleId": "WEAKRAND", "message": {"text": "math/rand for a token"},
+       "locations": [{"physicalLocation": {"artifactLocation": {"uri": "file:///work/rig/token.go"}, "region": {"startLine": 5}}}]},
+      {"ruleId": "OTHER", "level": "error", "message": {"text": "something"}}
+    ]
+  }]
+}`
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		format string
+		doc    string
+		want   []Finding
+	}{
+		{FormatGosec, gosecOutput, []Finding{
+			{Scanner: "s", Rule: "G101", Severity: "high", File: "internal/auth/auth.go", Line: 12, Message: "Potential hardcoded credentials"},
+			{Scanner: "s", Rule: "G304", Severity: "medium", File: "cmd/read.go", Line: 40, Message: "Potential file inclusion via variable"},
+		}},
+		{FormatSemgrep, semgrepOutput, []Finding{
+			{Scanner: "s", Rule: "python.lang.security.eval", Severity: "high", File: "app/run.py", Line: 7, Message: "eval of user input"},
+			{Scanner: "s", Rule: "python.lang.best-practice.open", Severity: "low", File: "app/io.py", Line: 3, Message: "file not closed"},
+		}},
+		{FormatSARIF, sarifOutput, []Finding{
+			{Scanner: "s", Rule: "SQLI", Severity: "critical", File: "db/query.go", Line: 20, Message: "query built from input"},
+			{Scanner: "s", Rule: "WEAKRAND", Severity: "low", File: "token.go", Line: 5, Message: "math/rand for a token"},
+			{Scanner: "s", Rule: "OTHER", Severity: "high", Message: "something"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got, err := Parse(tt.format, "s", []byte(tt.doc), "/work/rig")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+
+	for _, format := range []string{FormatGosec, FormatSemgrep, FormatSARIF} {
+		if _, err := Parse(format, "s", []byte(`{"unrelated": true}`), ""); err == nil {
+			t.Errorf("%s: accepted output without findings list", format)
+		}
+	}
+	if _, err := Parse("checkstyle", "s", []byte(`{}`), ""); err == nil {
+		t.Error("accepted an unknown format")
+	}
+}
+
+func TestIntroduced(t *testing.T) {
+	f := func(rule, file string, line int) Finding {
+		return Finding{Scanner: "gosec", Rule: rule, File: file, Line: line}
+	}
+	base := []Finding{f("G101", "a.go", 10), f("G304", "b.go", 5)}
+	head := []Finding{
+		f("G101", "a.go", 14), // moved down: not new
+		f("G101", "a.go", 30), // a second one in the same file: new
+		f("G304", "c.go", 5),  // same rule, new file: new
+	}
+	got := Introduced(base, head)
+	want := []Finding{head[1], head[2]}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Introduced() = %+v, want %+v", got, want)
+	}
+	if got := Introduced(nil, head); len(got) != 3 {
+		t.Errorf("Introduced(nil) = %d findings, want all 3", len(got))
+	}
+}
+
+func TestJudge(t *testing.T) {
+	introduced := []Finding{
+		{Scanner: "semgrep", Rule: "r1", Severity: SeverityMedium, File: "a.py"},
+		{Scanner: "gosec", Rule: "G101", Severity: SeverityHigh, File: "a.go"},
+		{Scanner: "gosec", Rule: "G104", Severity: SeverityLow, File: "b.go"},
+	}
+	failOn := map[string]string{"gosec": SeverityHigh, "semgrep": SeverityCritical}
+	runs := []ScannerRun{{Name: "gosec", Findings: 9}, {Name: "semgrep", Findings: 1}}
+
+	r := Judge(runs, true, introduced, func(s string) string { return failOn[s] })
+	if !r.Blocked || r.Status() != "blocked" {
+		t.Fatalf("expected blocked, got %+v", r)
+	}
+	if r.Findings[0].Rule != "G101" || !r.Findings[0].Blocking || r.Findings[1].Blocking {
+		t.Errorf("findings not ordered most severe first or misjudged: %+v", r.Findings)
+	}
+	if got := r.Deferred(SeverityMedium); len(got) != 1 || got[0].Rule != "r1" {
+		t.Errorf("Deferred(medium) = %+v, want only r1", got)
+	}
+	if got := r.Deferred(""); got != nil {
+		t.Errorf("Deferred(\"\") = %+v, want none", got)
+	}
+
+	md := r.Markdown(2)
+	if !strings.HasPrefix(md, "Security scan: blocked — 3 new findings from gosec, semgrep") ||
+		!strings.Contains(md, "high gosec/G101 at a.go (blocking)") || !strings.Contains(md, "... and 1 more") {
+		t.Errorf("Markdown() =\n%s", md)
+	}
+
+	failOn["gosec"] = SeverityCritical
+	if r := Judge(runs, true, introduced, func(s string) string { return failOn[s] }); r.Status() != "deferred" {
+		t.Errorf("status = %s, want deferred", r.Status())
+	}
+	if r := Judge(runs, true, nil, func(string) string { return SeverityLow }); r.Status() != "passed" {
+		t.Errorf("status = %s, want passed", r.Status())
+	}
+}
@@ -0,0 +1,470 @@
+// Package semdiff produces a structured summary of a branch's changes: the
+// files it touches, the top-level symbols it adds, removes, or modifies, and
+// the changes it makes to a Go package's public API.
+//
+// Go files are compared declaration by declaration using go/parser on the
+// base and branch versions of each file, so no build or module download is
+// needed. A symbol is part of a package's API when it is exported (methods
+// also need an exported receiver) and lives outside test files, main
+// packages, and testdata. Exported symbols of internal/ packages count too:
+// they are API to the rest of the module.
+package semdiff
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+// Change kinds.
+const (
+	Added    = "added"
+	Removed  = "removed"
+	Modified = "modified"
+)
+
+// Source reads diffs and file contents from a repository.
+// *git.Git implements it.
+type Source interface {
+	ChangedFiles(base, branch string) ([]git.FileChange, error)
+	MergeBase(a, b string) (string, error)
+	ShowFile(ref, path string) ([]byte, error)
+}
+
+// SymbolChange is a top-level declaration touched by the diff.
+type SymbolChange struct {
+	File    string `json:"file"`
+	Package string `json:"package"` // Directory of the file
+	Name    string `json:"name"`    // Foo, T, or T.Method
+	Kind    string `json:"kind"`    // func, method, type, var, const
+	Change  string `json:"change"`  // added, removed, modified
+	API     bool   `json:"api"`     // Part of the package's public API
+
+	// Breaking is set for API changes that can break callers: removals,
+	// function signature changes, and struct or interface changes other
+	// than added struct fields.
+	Breaking bool `json:"breaking,omitempty"`
+
+	// Signature is the declaration without body or doc comment (the old
+	// declaration for removals). OldSignature is set when a modification
+	// changed the signature.
+	Signature    string `json:"signature,omitempty"`
+	OldSignature string `json:"old_signature,omitempty"`
+}
+
+// Summary is the structured change summary of a branch against a base.
+type Summary struct {
+	Base    string           `json:"base"`
+	Branch  string           `json:"branch"`
+	Files   []git.FileChange `json:"files"`
+	Symbols []SymbolChange   `json:"symbols,omitempty"`
+	Errors  []string         `json:"errors,omitempty"` // Files that could not be parsed
+}
+
+// API returns the public API changes: exported symbols that were added,
+// removed, or whose signature changed. Body-only edits are not API changes.
+func (s *Summary) API() []SymbolChange {
+	var api []SymbolChange
+	for _, sym := range s.Symbols {
+		if sym.API && (sym.Change != Modified || sym.OldSignature != "") {
+			api = append(api, sym)
+		}
+	}
+	return api
+}
+
+// Breaking returns the API changes that can break callers.
+func (s *Summary) Breaking() []SymbolChange {
+	var breaking []SymbolChange
+	for _, sym := range s.API() {
+		if sym.Breaking {
+			breaking = append(breaking, sym)
+		}
+	}
+	return breaking
+}
+
+// Summarize builds the change summary of branch against base. Go files are
+// compared at the merge base so changes on base aren't attributed to branch.
+func Summarize(src Source, base, branch string) (*Summary, error) {
+	files, err := src.ChangedFiles(base, branch)
+	if err != nil {
+		return nil, fmt.Errorf("listing changed files: %w", err)
+	}
+	mergeBase, err := src.MergeBase(base, branch)
+	if err != nil {
+		return nil, fmt.Errorf("finding merge base: %w", err)
+	}
+
+	summary := &Summary{Base: base, Branch: branch, Files: files}
+	for _, f := range files {
+		if !strings.HasSuffix(f.Path, ".go") {
+			continue
+		}
+
+		var oldSrc, newSrc []byte
+		if f.Status != "A" && f.Status != "C" {
+			oldPath := f.Path
+			if f.OldPath != "" {
+				oldPath = f.OldPath
+			}
+			if oldSrc, err = src.ShowFile(mergeBase, oldPath); err != nil {
+				summary.Errors = append(summary.Errors, fmt.Sprintf("%s: reading base: %v", f.Path, err))
+				continue
+			}
+		}
+		if f.Status != "D" {
+			if newSrc, err = src.ShowFile(branch, f.Path); err != nil {
+				summary.Errors = append(summary.Errors, fmt.Sprintf("%s: reading branch: %v", f.Path, err))
+				continue
+			}
+		}
+
+		changes, err := DiffGo(f.Path, oldSrc, newSrc)
+		if err != nil {
+			summary.Errors = append(summary.Errors, err.Error())
+			continue
+		}
+		summary.Symbols = append(summary.Symbols, changes...)
+	}
+	return summary, nil
+}
+
+// decl is one top-level declaration of a Go file.
+type decl struct {
+	name      string
+	kind      string
+	exported  bool
+	signature string
+	full      string
+
+	// members maps a struct's exported fields or an interface's methods to
+	// their types. Nil for other declarations.
+	members map[string]string
+	iface   bool
+}
+
+// DiffGo compares two versions of a Go file and returns the top-level
+// declarations that were added, removed, or modified. A nil version means
+// the file did not exist.
+func DiffGo(filename string, oldSrc, newSrc []byte) ([]SymbolChange, error) {
+	oldDecls, oldPkg, err := parseDecls(filename, oldSrc)
+	if err != nil {
+		return nil, err
+	}
+	newDecls, newPkg, err := parseDecls(filename, newSrc)
+	if err != nil {
+		return nil, err
+	}
+
+	pkgName := newPkg
+	if pkgName == "" {
+		pkgName = oldPkg
+	}
+	public := isPublicFile(filename, pkgName)
+	dir := path.Dir(filename)
+
+	names := make(map[string]bool)
+	for name := range oldDecls {
+		names[name] = true
+	}
+	for name := range newDecls {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	var changes []SymbolChange
+	for _, name := range sorted {
+		o, n := oldDecls[name], newDecls[name]
+		change := SymbolChange{File: filename, Package: dir, Name: name}
+		switch {
+		case o == nil:
+			change.Change, change.Kind, change.Signature = Added, n.kind, n.signature
+			change.API = public && n.exported
+		case n == nil:
+			change.Change, change.Kind, change.Signature = Removed, o.kind, o.signature
+			change.API = public && o.exported
+			change.Breaking = change.API
+		case squash(o.full) != squash(n.full):
+			change.Change, change.Kind, change.Signature = Modified, n.kind, n.signature
+			change.API = public && (o.exported || n.exported)
+			if squash(o.signature) != squash(n.signature) {
+				change.OldSignature = o.signature
+				change.Breaking = change.API && breaksCallers(o, n)
+			}
+		default:
+			continue
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// parseDecls parses a Go file and indexes its top-level declarations by name.
+// Returns an empty index for nil source.
+func parseDecls(filename string, src []byte) (map[string]*decl, string, error) {
+	decls := make(map[string]*decl)
+	if src == nil {
+		return decls, "", nil
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, "", fmt.Errorf("parsing %s: %w", filename, err)
+	}
+
+	for _, d := range file.Decls {
+		switch d := d.(type) {
+		case *ast.FuncDecl:
+			sig := *d
+			sig.Doc, sig.Body = nil, nil
+			full := *d
+			full.Doc = nil
+
+			name, kind, exported := d.Name.Name, "func", d.Name.IsExported()
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				recv := receiverName(d.Recv.List[0].Type)
+				name, kind = recv+"."+name, "method"
+				exported = exported && ast.IsExported(recv)
+			}
+			decls[name] = &decl{
+				name:      name,
+				kind:      kind,
+				exported:  exported,
+				signature: render(fset, &sig),
+				full:      render(fset, &full),
+			}
+
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					text := "type " + render(fset, s)
+					td := &decl{
+						name:      s.Name.Name,
+						kind:      "type",
+						exported:  s.Name.IsExported(),
+						signature: text,
+						full:      text,
+					}
+					switch t := s.Type.(type) {
+					case *ast.StructType:
+						td.members = fieldTypes(fset, t.Fields, true)
+					case *ast.InterfaceType:
+						td.members, td.iface = fieldTypes(fset, t.Methods, false), true
+					}
+					decls[s.Name.Name] = td
+				case *ast.ValueSpec:
+					kind := "var"
+					if d.Tok == token.CONST {
+						kind = "const"
+					}
+					text := kind + " " + render(fset, s)
+					for _, n := range s.Names {
+						if n.Name == "_" {
+							continue
+						}
+						decls[n.Name] = &decl{
+							name:      n.Name,
+							kind:      kind,
+							exported:  n.IsExported(),
+							signature: text,
+							full:      text,
+						}
+					}
+				}
+			}
+		}
+	}
+	return decls, file.Name.Name, nil
+}
+
+// fieldTypes maps the named fields (or interface methods) of a field list to
+// their rendered types. Embedded fields are keyed by their type. With
+// exportedOnly, unexported names are skipped.
+func fieldTypes(fset *token.FileSet, list *ast.FieldList, exportedOnly bool) map[string]string {
+	members := make(map[string]string)
+	if list == nil {
+		return members
+	}
+	for _, f := range list.List {
+		typ := squash(render(fset, f.Type))
+		if len(f.Names) == 0 {
+			members[typ] = typ
+			continue
+		}
+		for _, n := range f.Names {
+			if !exportedOnly || n.IsExported() {
+				members[n.Name] = typ
+			}
+		}
+	}
+	return members
+}
+
+// breaksCallers reports whether a signature change can break existing
+// callers. Struct types may gain fields; interfaces may not gain methods
+// (existing implementations would stop satisfying them). Changed values of
+// vars and consts keep callers compiling.
+func breaksCallers(o, n *decl) bool {
+	switch {
+	case o.kind == "var" || o.kind == "const":
+		return squash(valueType(o.signature)) != squash(valueType(n.signature))
+	case o.members == nil || n.members == nil || o.iface != n.iface:
+		return true
+	}
+	for name, typ := range o.members {
+		if n.members[name] != typ {
+			return true
+		}
+	}
+	return o.iface && len(n.members) > len(o.members)
+}
+
+// valueType returns the declared type of a var or const signature such as
+// "var X int = 5" (empty when the type is inferred).
+func valueType(sig string) string {
+	fields := strings.Fields(strings.SplitN(sig, "=", 2)[0])
+	if len(fields) < 3 {
+		return ""
+	}
+	return strings.Join(fields[2:], " ")
+}
+
+// receiverName returns the base type name of a method receiver.
+func receiverName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(t.X)
+	case *ast.IndexExpr:
+		return receiverName(t.X)
+	case *ast.IndexListExpr:
+		return receiverName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+// render prints an AST node in gofmt form (without comments).
+func render(fset *token.FileSet, node ast.Node) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, node); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
+// squash removes whitespace so formatting-only edits (which the printer
+// preserves as line breaks) don't register as changes.
+func squash(s string) string {
+	return strings.Join(strings.Fields(s), "")
+}
+
+// isPublicFile reports whether a file's exported symbols are importable API.
+func isPublicFile(filename, pkgName string) bool {
+	if strings.HasSuffix(filename, "_test.go") || pkgName == "main" {
+		return false
+	}
+	for _, part := range strings.Split(path.Dir(filename), "/") {
+		if part == "testdata" {
+			return false
+		}
+	}
+	return true
+}
+
+// Markdown renders the summary for an MR bead or review. At most maxItems
+// files and symbols are listed (0 means no limit); API changes are always
+// listed in full.
+func (s *Summary) Markdown(maxItems int) string {
+	var b strings.Builder
+
+	api := s.API()
+	fmt.Fprintf(&b, "%d files, %d symbols, %d API changes", len(s.Files), len(s.Symbols), len(api))
+	if breaking := len(s.Breaking()); breaking > 0 {
+		fmt.Fprintf(&b, " (%d breaking)", breaking)
+	}
+	b.WriteString("\n")
+
+	if len(api) > 0 {
+		b.WriteString("\nAPI changes:\n")
+		for _, sym := range api {
+			marker := changeMarker(sym.Change)
+			if sym.Breaking {
+				marker += "!"
+			}
+			fmt.Fprintf(&b, "- %s %s `%s`\n", marker, sym.Package, firstLine(sym.Signature))
+			switch {
+			case sym.OldSignature == "":
+			case firstLine(sym.OldSignature) == firstLine(sym.Signature):
+				b.WriteString("  definition changed\n")
+			default:
+				fmt.Fprintf(&b, "  was `%s`\n", firstLine(sym.OldSignature))
+			}
+		}
+	}
+
+	if len(s.Symbols) > 0 {
+		b.WriteString("\nSymbols:\n")
+		for i, sym := range s.Symbols {
+			if maxItems > 0 && i == maxItems {
+				fmt.Fprintf(&b, "- ... and %d more\n", len(s.Symbols)-i)
+				break
+			}
+			fmt.Fprintf(&b, "- %s %s %s.%s\n", changeMarker(sym.Change), sym.Kind, path.Base(sym.Package), sym.Name)
+		}
+	}
+
+	if len(s.Files) > 0 {
+		b.WriteString("\nFiles:\n")
+		for i, f := range s.Files {
+			if maxItems > 0 && i == maxItems {
+				fmt.Fprintf(&b, "- ... and %d more\n", len(s.Files)-i)
+				break
+			}
+			if f.OldPath != "" {
+				fmt.Fprintf(&b, "- %s %s -> %s\n", f.Status, f.OldPath, f.Path)
+			} else {
+				fmt.Fprintf(&b, "- %s %s\n", f.Status, f.Path)
+			}
+		}
+	}
+
+	for _, e := range s.Errors {
+		fmt.Fprintf(&b, "\nNot analyzed: %s\n", e)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// changeMarker returns a diff-style marker for a change kind.
+func changeMarker(change string) string {
+	switch change {
+	case Added:
+		return "+"
+	case Removed:
+		return "-"
+	}
+	return "~"
+}
+
+// firstLine returns the first line of a declaration, marking truncation.
+func firstLine(s string) string {
+	if i := strings.Index(s, "\n"); i >= 0 {
+		return s[:i] + " ..."
+	}
+	return s
+}
@@ -0,0 +1,222 @@
+package semdiff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+const oldFile = `package store
+
+// Store holds items.
+type Store struct{ items map[string]string }
+
+func New() *Store { return &Store{} }
+
+func (s *Store) Get(key string) string { return s.items[key] }
+
+func (s *Store) Delete(key string) { delete(s.items, key) }
+
+func helper() {}
+
+const Version = "1"
+`
+
+const newFile = `package store
+
+// Store holds items.
+type Store struct{ items map[string]string }
+
+func New() *Store {
+	return &Store{items: map[string]string{}}
+}
+
+func (s *Store) Get(key string) (string, bool) {
+	v, ok := s.items[key]
+	return v, ok
+}
+
+func (s *Store) Put(key, value string) { s.items[key] = value }
+
+func helper() { println("changed") }
+
+const Version = "1"
+`
+
+func TestDiffGo(t *testing.T) {
+	changes, err := DiffGo("pkg/store/store.go", []byte(oldFile), []byte(newFile))
+	if err != nil {
+		t.Fatalf("DiffGo: %v", err)
+	}
+
+	got := make(map[string]SymbolChange)
+	for _, c := range changes {
+		got[c.Name] = c
+	}
+
+	tests := []struct {
+		name      string
+		change    string
+		kind      string
+		api       bool
+		sigChange bool
+	}{
+		{"New", Modified, "func", true, false},
+		{"Store.Get", Modified, "method", true, true},
+		{"Store.Put", Added, "method", true, false},
+		{"Store.Delete", Removed, "method", true, false},
+		{"helper", Modified, "func", false, false},
+	}
+	for _, tt := range tests {
+		c, ok := got[tt.name]
+		if !ok {
+			t.Errorf("%s: not reported", tt.name)
+			continue
+		}
+		if c.Change != tt.change || c.Kind != tt.kind || c.API != tt.api {
+			t.Errorf("%s: got change=%s kind=%s api=%v, want %s %s %v", tt.name, c.Change, c.Kind, c.API, tt.change, tt.kind, tt.api)
+		}
+		if (c.OldSignature != "") != tt.sigChange {
+			t.Errorf("%s: OldSignature = %q, want signature change %v", tt.name, c.OldSignature, tt.sigChange)
+		}
+	}
+
+	for _, unchanged := range []string{"Store", "Version"} {
+		if _, ok := got[unchanged]; ok {
+			t.Errorf("%s: reported but unchanged", unchanged)
+		}
+	}
+}
+
+func TestDiffGo_FormattingOnlyIsUnchanged(t *testing.T) {
+	reformatted := strings.ReplaceAll(oldFile, "func helper() {}", "func helper() {\n}")
+	changes, err := DiffGo("store.go", []byte(oldFile), []byte(reformatted))
+	if err != nil {
+		t.Fatalf("DiffGo: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("expected no changes for formatting-only edit, got %+v", changes)
+	}
+}
+
+func TestIsPublicFile(t *testing.T) {
+	tests := []struct {
+		file, pkg string
+		want      bool
+	}{
+		{"pkg/store/store.go", "store", true},
+		{"internal/store/store.go", "store", true},
+		{"pkg/store/store_test.go", "store", false},
+		{"cmd/tool/main.go", "main", false},
+		{"pkg/store/testdata/fixture.go", "fixture", false},
+	}
+	for _, tt := range tests {
+		if got := isPublicFile(tt.file, tt.pkg); got != tt.want {
+			t.Errorf("isPublicFile(%q, %q) = %v, want %v", tt.file, tt.pkg, got, tt.want)
+		}
+	}
+}
+
+// fakeSource serves files from maps keyed by ref.
+type fakeSource struct {
+	files   []git.FileChange
+	content map[string]map[string]string
+}
+
+func (f *fakeSource) ChangedFiles(base, branch string) ([]git.FileChange, error) {
+	return f.files, nil
+}
+
+func (f *fakeSource) MergeBase(a, b string) (string, error) {
+	return "base-sha", nil
+}
+
+func (f *fakeSource) ShowFile(ref, path string) ([]byte, error) {
+	content, ok := f.content[ref][path]
+	if !ok {
+		return nil, fmt.Errorf("%s:%s not found", ref, path)
+	}
+	return []byte(content), nil
+}
+
+func TestSummarize(t *testing.T) {
+	src := &fakeSource{
+		files: []git.FileChange{
+			{Status: "M", Path: "pkg/store/store.go"},
+			{Status: "A", Path: "pkg/store/extra.go"},
+			{Status: "M", Path: "README.md"},
+			{Status: "M", Path: "pkg/broken.go"},
+		},
+		content: map[string]map[string]string{
+			"base-sha": {
+				"pkg/store/store.go": oldFile,
+				"pkg/broken.go":      "package broken\n",
+			},
+			"feature": {
+				"pkg/store/store.go": newFile,
+				"pkg/store/extra.go": "package store\n\nfunc Extra() {}\n",
+				"pkg/broken.go":      "package broken\nfunc {",
+			},
+		},
+	}
+
+	summary, err := Summarize(src, "main", "feature")
+	if err != nil {
+		t.Fatalf("Summarize: %v", err)
+	}
+	if len(summary.Files) != 4 {
+		t.Errorf("Files = %d, want 4", len(summary.Files))
+	}
+	if len(summary.Errors) != 1 || !strings.Contains(summary.Errors[0], "pkg/broken.go") {
+		t.Errorf("Errors = %v, want one for pkg/broken.go", summary.Errors)
+	}
+
+	// Store.Get signature, Store.Put, Store.Delete, Extra
+	if api := summary.API(); len(api) != 4 {
+		t.Errorf("API() = %d changes, want 4: %+v", len(api), api)
+	}
+	// Store.Get signature change and Store.Delete removal
+	if breaking := summary.Breaking(); len(breaking) != 2 {
+		t.Errorf("Breaking() = %d changes, want 2: %+v", len(breaking), breaking)
+	}
+
+	md := summary.Markdown(0)
+	for _, want := range []string{"4 files", "2 breaking", "- + pkg/store `func Extra()`", "was `func (s *Store) Get(key string) string`"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown missing %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestDiffGo_BreakingTypeChanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		breaking bool
+	}{
+		{"struct field added", "type T struct{ A int }", "type T struct{ A int; B string }", false},
+		{"struct field removed", "type T struct{ A int; B string }", "type T struct{ A int }", true},
+		{"struct field retyped", "type T struct{ A int }", "type T struct{ A int64 }", true},
+		{"unexported field removed", "type T struct{ A int; b int }", "type T struct{ A int }", false},
+		{"interface method added", "type T interface{ A() }", "type T interface{ A(); B() }", true},
+		{"const value changed", `const T = "1"`, `const T = "2"`, false},
+		{"var type changed", "var T int", "var T string", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, err := DiffGo("pkg/t.go", []byte("package p\n"+tt.old+"\n"), []byte("package p\n"+tt.new+"\n"))
+			if err != nil {
+				t.Fatalf("DiffGo: %v", err)
+			}
+			if len(changes) != 1 {
+				t.Fatalf("got %d changes, want 1: %+v", len(changes), changes)
+			}
+			if changes[0].Breaking != tt.breaking {
+				t.Errorf("Breaking = %v, want %v", changes[0].Breaking, tt.breaking)
+			}
+		})
+	}
+}
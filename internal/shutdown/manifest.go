@@ -0,0 +1,95 @@
+// Package shutdown records what a town was doing when it went down with
+// gt down --checkpoint, so gt up --resume can bring the same agents back on
+// the same issues.
+package shutdown
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// ManifestFile is the resume manifest's path within the town.
+const ManifestFile = "daemon/resume.json"
+
+// Agent is a worker session that was stopped and should be resumed.
+type Agent struct {
+	Role    string `json:"role"` // polecat or crew
+	Rig     string `json:"rig"`
+	Name    string `json:"name"`
+	Session string `json:"session"`
+	WorkDir string `json:"work_dir,omitempty"`
+	Issue   string `json:"issue,omitempty"` // hooked when the town went down
+	Branch  string `json:"branch,omitempty"`
+	Commit  string `json:"commit,omitempty"`
+}
+
+// Address returns the agent's mail address (e.g. gastown/polecats/Toast).
+func (a Agent) Address() string {
+	if a.Role == "crew" {
+		return a.Rig + "/crew/" + a.Name
+	}
+	return a.Rig + "/polecats/" + a.Name
+}
+
+// Manifest is the state gt down --checkpoint leaves for gt up --resume.
+type Manifest struct {
+	CreatedAt time.Time `json:"created_at"`
+	CreatedBy string    `json:"created_by,omitempty"`
+
+	// Daemon records whether the daemon was running; its scheduled work
+	// (patrols, plugin gates, retention) comes back with it.
+	Daemon bool `json:"daemon"`
+
+	// Rigs lists the rigs whose witness and refinery were stopped.
+	Rigs []string `json:"rigs,omitempty"`
+
+	// Agents lists the worker sessions that were checkpointed and stopped.
+	Agents []Agent `json:"agents,omitempty"`
+
+	// PendingSpawns is how many queued spawn triggers were flushed; the
+	// polecats they belonged to are in Agents.
+	PendingSpawns int `json:"pending_spawns,omitempty"`
+}
+
+// Path returns the resume manifest path for a town.
+func Path(townRoot string) string {
+	return filepath.Join(townRoot, ManifestFile)
+}
+
+// Write saves the manifest atomically.
+func Write(townRoot string, m *Manifest) error {
+	path := Path(townRoot)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating daemon dir: %w", err)
+	}
+	return util.AtomicWriteJSON(path, m)
+}
+
+// Read loads the manifest. Returns nil, nil if the town has none.
+func Read(townRoot string) (*Manifest, error) {
+	data, err := os.ReadFile(Path(townRoot))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ManifestFile, err)
+	}
+	return &m, nil
+}
+
+// Remove deletes the manifest once it has been resumed.
+func Remove(townRoot string) error {
+	if err := os.Remove(Path(townRoot)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
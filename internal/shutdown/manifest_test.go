@@ -0,0 +1,50 @@
+package shutdown
+
+import (
+	"testing"
+	"time"
+)
+
+func TestManifestRoundTrip(t *testing.T) {
+	town := t.TempDir()
+
+	if m, err := Read(town); err != nil || m != nil {
+		t.Fatalf("Read() on a fresh town = %v, %v; want nil, nil", m, err)
+	}
+
+	want := &Manifest{
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+		Daemon:    true,
+		Rigs:      []string{"gastown"},
+		Agents: []Agent{
+			{Role: "polecat", Rig: "gastown", Name: "Toast", Session: "gt-gastown-Toast", Issue: "gt-abc"},
+			{Role: "crew", Rig: "gastown", Name: "max", Session: "gt-gastown-crew-max"},
+		},
+	}
+	if err := Write(town, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Read(town)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.CreatedAt.Equal(want.CreatedAt) || !got.Daemon || len(got.Agents) != 2 || got.Agents[0].Issue != "gt-abc" {
+		t.Errorf("Read() = %+v, want %+v", got, want)
+	}
+	if addr := got.Agents[0].Address(); addr != "gastown/polecats/Toast" {
+		t.Errorf("polecat address = %q", addr)
+	}
+	if addr := got.Agents[1].Address(); addr != "gastown/crew/max" {
+		t.Errorf("crew address = %q", addr)
+	}
+
+	if err := Remove(town); err != nil {
+		t.Fatal(err)
+	}
+	if m, _ := Read(town); m != nil {
+		t.Error("manifest still present after Remove")
+	}
+	if err := Remove(town); err != nil {
+		t.Errorf("Remove() of a missing manifest = %v, want nil", err)
+	}
+}
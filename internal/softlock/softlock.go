@@ -0,0 +1,222 @@
+// Package softlock tracks file-level soft locks: the paths an agent
+// declares it will change when it claims an issue.
+//
+// Locks are advisory. A claim overlapping another's locked paths either
+// takes its lock anyway with a warning, or waits in line for the paths,
+// per the rig's file_locks policy. Locks are released when the issue's MR
+// merges, or expire with the claim.
+package softlock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// Policies for claims that overlap locked paths.
+const (
+	PolicyWarn  = "warn"
+	PolicyQueue = "queue"
+)
+
+// DefaultTTL is how long locks last when the rig doesn't set a TTL.
+const DefaultTTL = 24 * time.Hour
+
+// Lock is the set of paths one issue's claim has locked.
+type Lock struct {
+	Issue     string    `json:"issue"`
+	Holder    string    `json:"holder"`
+	Paths     []string  `json:"paths"`
+	ClaimedAt time.Time `json:"claimed_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// Queued locks wait for overlapping locks ahead of them to be released
+	// and don't hold their paths yet.
+	Queued bool `json:"queued,omitempty"`
+}
+
+// Conflict is a lock overlapping a claim, with the claimed paths it
+// overlaps.
+type Conflict struct {
+	Lock  Lock     `json:"lock"`
+	Paths []string `json:"paths"`
+}
+
+// Settings returns a rig's lock policy and TTL, with defaults applied.
+func Settings(cfg *config.FileLocksConfig) (policy string, ttl time.Duration, err error) {
+	policy, ttl = PolicyWarn, DefaultTTL
+	if cfg == nil {
+		return policy, ttl, nil
+	}
+	switch cfg.Policy {
+	case "", PolicyWarn:
+	case PolicyQueue:
+		policy = PolicyQueue
+	default:
+		return "", 0, fmt.Errorf("file_locks.policy %q: want %q or %q", cfg.Policy, PolicyWarn, PolicyQueue)
+	}
+	if cfg.TTL != "" {
+		if ttl, err = time.ParseDuration(cfg.TTL); err != nil || ttl <= 0 {
+			return "", 0, fmt.Errorf("file_locks.ttl %q: want a positive duration like \"8h\"", cfg.TTL)
+		}
+	}
+	return policy, ttl, nil
+}
+
+// Clean normalizes declared paths: slash-separated, relative to the repo
+// root, without trailing slashes, sorted and deduplicated. "." locks the
+// whole repo.
+func Clean(paths []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var out []string
+	for _, p := range paths {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		c := path.Clean(filepath.ToSlash(p))
+		if path.IsAbs(c) || c == ".." || strings.HasPrefix(c, "../") {
+			return nil, fmt.Errorf("path %q is outside the repo", p)
+		}
+		if !seen[c] {
+			seen[c] = true
+			out = append(out, c)
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// Overlap reports whether two cleaned paths overlap: they're the same, or
+// one is a directory containing the other.
+func Overlap(a, b string) bool {
+	return a == b || a == "." || b == "." || strings.HasPrefix(b, a+"/") || strings.HasPrefix(a, b+"/")
+}
+
+// Conflicts returns the locks in locks that a claim for l must respect:
+// other issues' held locks, and their queued locks ahead of l in line,
+// that overlap l's paths.
+func Conflicts(locks []Lock, l Lock) []Conflict {
+	var conflicts []Conflict
+	for _, other := range locks {
+		if other.Issue == l.Issue || (other.Queued && !other.ClaimedAt.Before(l.ClaimedAt)) {
+			continue
+		}
+		var shared []string
+		for _, p := range l.Paths {
+			for _, q := range other.Paths {
+				if Overlap(p, q) {
+					shared = append(shared, p)
+					break
+				}
+			}
+		}
+		if len(shared) > 0 {
+			conflicts = append(conflicts, Conflict{Lock: other, Paths: shared})
+		}
+	}
+	return conflicts
+}
+
+// RegistryPath returns the soft lock registry for a rig.
+func RegistryPath(rigPath string) string {
+	return filepath.Join(rigPath, ".runtime", "file-locks.json")
+}
+
+// Load returns a rig's unexpired locks, in the order they were claimed.
+func Load(rigPath string, now time.Time) ([]Lock, error) {
+	data, err := os.ReadFile(RegistryPath(rigPath)) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading file locks: %w", err)
+	}
+	var locks []Lock
+	if err := json.Unmarshal(data, &locks); err != nil {
+		return nil, fmt.Errorf("parsing file locks: %w", err)
+	}
+	live := locks[:0]
+	for _, l := range locks {
+		if now.Before(l.ExpiresAt) {
+			live = append(live, l)
+		}
+	}
+	sort.SliceStable(live, func(i, j int) bool { return live[i].ClaimedAt.Before(live[j].ClaimedAt) })
+	return live, nil
+}
+
+// update applies fn to a rig's unexpired locks under the registry's file
+// lock and saves the result.
+func update(rigPath string, now time.Time, fn func([]Lock) []Lock) error {
+	lockDir := filepath.Join(rigPath, ".runtime", "locks")
+	if err := os.MkdirAll(lockDir, 0755); err != nil {
+		return fmt.Errorf("creating lock dir: %w", err)
+	}
+	fl := flock.New(filepath.Join(lockDir, "file-locks.lock"))
+	if err := fl.Lock(); err != nil {
+		return fmt.Errorf("acquiring file lock registry: %w", err)
+	}
+	defer func() { _ = fl.Unlock() }()
+
+	locks, err := Load(rigPath, now)
+	if err != nil {
+		return err
+	}
+	locks = fn(locks)
+	if locks == nil {
+		locks = []Lock{}
+	}
+	return util.AtomicWriteJSON(RegistryPath(rigPath), locks)
+}
+
+// Claim locks l's paths for its issue, replacing any locks the issue held.
+// It returns the conflicting locks. Under PolicyQueue, a conflicting claim
+// is queued rather than held, and queued reports so. Re-claiming refreshes
+// the locks' expiry and keeps the issue's place in line.
+func Claim(rigPath string, l Lock, policy string, ttl time.Duration, now time.Time) (conflicts []Conflict, queued bool, err error) {
+	l.ClaimedAt, l.ExpiresAt, l.Queued = now, now.Add(ttl), false
+	err = update(rigPath, now, func(locks []Lock) []Lock {
+		others := locks[:0]
+		for _, other := range locks {
+			if other.Issue == l.Issue {
+				l.ClaimedAt = other.ClaimedAt
+				continue
+			}
+			others = append(others, other)
+		}
+		conflicts = Conflicts(others, l)
+		l.Queued = policy == PolicyQueue && len(conflicts) > 0
+		return append(others, l)
+	})
+	return conflicts, l.Queued, err
+}
+
+// Release drops an issue's locks. It reports whether the issue held any.
+func Release(rigPath, issue string, now time.Time) (bool, error) {
+	if _, err := os.Stat(RegistryPath(rigPath)); errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	released := false
+	err := update(rigPath, now, func(locks []Lock) []Lock {
+		kept := locks[:0]
+		for _, l := range locks {
+			if l.Issue == issue {
+				released = true
+				continue
+			}
+			kept = append(kept, l)
+		}
+		return kept
+	})
+	return released, err
+}
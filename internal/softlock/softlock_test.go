@@ -0,0 +1,125 @@
+package softlock
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func TestSettings(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        *config.FileLocksConfig
+		wantPolicy string
+		wantTTL    time.Duration
+		wantErr    bool
+	}{
+		{"nil", nil, PolicyWarn, DefaultTTL, false},
+		{"queue", &config.FileLocksConfig{Policy: "queue", TTL: "8h"}, PolicyQueue, 8 * time.Hour, false},
+		{"bad policy", &config.FileLocksConfig{Policy: "block"}, "", 0, true},
+		{"bad ttl", &config.FileLocksConfig{TTL: "soon"}, "", 0, true},
+		{"negative ttl", &config.FileLocksConfig{TTL: "-1h"}, "", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, ttl, err := Settings(tt.cfg)
+			if (err != nil) != tt.wantErr || policy != tt.wantPolicy || ttl != tt.wantTTL {
+				t.Errorf("Settings() = %q, %v, %v; want %q, %v, err %v", policy, ttl, err, tt.wantPolicy, tt.wantTTL, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClean(t *testing.T) {
+	got, err := Clean([]string{"internal/auth/", "./README.md", "internal/auth", " ", "docs/../cmd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"README.md", "cmd", "internal/auth"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Clean() = %v, want %v", got, want)
+	}
+	for _, bad := range []string{"/etc/passwd", "../other"} {
+		if _, err := Clean([]string{bad}); err == nil {
+			t.Errorf("Clean(%q) should fail", bad)
+		}
+	}
+}
+
+func TestOverlap(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"internal/auth", "internal/auth", true},
+		{"internal/auth", "internal/auth/login.go", true},
+		{"internal/auth/login.go", "internal", true},
+		{"internal/auth", "internal/authz", false},
+		{"README.md", "docs/README.md", false},
+		{".", "anything.go", true},
+	}
+	for _, tt := range tests {
+		if got := Overlap(tt.a, tt.b); got != tt.want {
+			t.Errorf("Overlap(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestClaimAndRelease(t *testing.T) {
+	rigPath := t.TempDir()
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	claim := func(issue, holder, policy string, at time.Time, paths ...string) ([]Conflict, bool) {
+		t.Helper()
+		conflicts, queued, err := Claim(rigPath, Lock{Issue: issue, Holder: holder, Paths: paths}, policy, time.Hour, at)
+		if err != nil {
+			t.Fatalf("Claim(%s): %v", issue, err)
+		}
+		return conflicts, queued
+	}
+
+	if c, q := claim("gt-1", "gastown/polecats/nux", PolicyQueue, now, "internal/auth"); c != nil || q {
+		t.Fatalf("first claim: conflicts %v, queued %v", c, q)
+	}
+	// Warn policy: takes the lock despite the overlap.
+	c, q := claim("gt-2", "gastown/crew/joe", PolicyWarn, now.Add(time.Minute), "internal/auth/login.go", "README.md")
+	if q || len(c) != 1 || c[0].Lock.Issue != "gt-1" || !reflect.DeepEqual(c[0].Paths, []string{"internal/auth/login.go"}) {
+		t.Fatalf("warn claim: conflicts %+v, queued %v", c, q)
+	}
+	// Queue policy: waits behind both.
+	c, q = claim("gt-3", "gastown/polecats/toast", PolicyQueue, now.Add(2*time.Minute), "internal")
+	if !q || len(c) != 2 {
+		t.Fatalf("queued claim: conflicts %+v, queued %v", c, q)
+	}
+	// A later claim waits behind the queued one too.
+	if c, q := claim("gt-4", "gastown/polecats/ace", PolicyQueue, now.Add(3*time.Minute), "internal/mail"); !q || len(c) != 1 || c[0].Lock.Issue != "gt-3" {
+		t.Fatalf("claim behind queue: conflicts %+v, queued %v", c, q)
+	}
+
+	for _, issue := range []string{"gt-1", "gt-2"} {
+		if ok, err := Release(rigPath, issue, now.Add(4*time.Minute)); err != nil || !ok {
+			t.Fatalf("Release(%s) = %v, %v", issue, ok, err)
+		}
+	}
+	// Re-claiming once the paths are free takes the lock, keeping gt-3's
+	// place ahead of gt-4.
+	if c, q := claim("gt-3", "gastown/polecats/toast", PolicyQueue, now.Add(5*time.Minute), "internal"); q || c != nil {
+		t.Fatalf("re-claim: conflicts %+v, queued %v", c, q)
+	}
+	locks, err := Load(rigPath, now.Add(5*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(locks) != 2 || locks[0].Issue != "gt-3" || locks[0].Queued || !locks[1].Queued {
+		t.Fatalf("Load() = %+v", locks)
+	}
+
+	// Locks expire with the claim.
+	if locks, _ := Load(rigPath, now.Add(2*time.Hour)); len(locks) != 0 {
+		t.Errorf("expired locks still loaded: %+v", locks)
+	}
+	if ok, err := Release(t.TempDir(), "gt-1", now); err != nil || ok {
+		t.Errorf("Release with no registry = %v, %v", ok, err)
+	}
+}
@@ -0,0 +1,143 @@
+package style
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// spinnerFrames are drawn in turn while work is in flight.
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+const (
+	progressBarWidth = 20
+	progressTick     = 100 * time.Millisecond
+)
+
+// Progress draws a single self-updating status line for work spread over
+// several items (usually rigs): a spinner, a done/total bar, and the items
+// still running. It redraws in place with a carriage return, so it must
+// only be pointed at a terminal.
+//
+// All methods are safe for concurrent use and are no-ops on a nil
+// *Progress, so callers can pass nil when progress is suppressed.
+type Progress struct {
+	w     io.Writer
+	label string
+	total int // 0 when the amount of work isn't known up front
+
+	mu      sync.Mutex
+	done    int
+	active  []string
+	frame   int
+	stopped bool
+	quit    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewProgress starts drawing a progress line to w. total is the number of
+// items expected; pass 0 for a plain spinner.
+func NewProgress(w io.Writer, label string, total int) *Progress {
+	p := &Progress{w: w, label: label, total: total, quit: make(chan struct{})}
+	p.wg.Add(1)
+	go p.loop()
+	return p
+}
+
+// Start marks item as in flight.
+func (p *Progress) Start(item string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.active = append(p.active, item)
+}
+
+// Done marks item as finished.
+func (p *Progress) Done(item string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, a := range p.active {
+		if a == item {
+			p.active = append(p.active[:i], p.active[i+1:]...)
+			break
+		}
+	}
+	p.done++
+}
+
+// Stop erases the progress line. Call it before printing results.
+func (p *Progress) Stop() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	if p.stopped {
+		p.mu.Unlock()
+		return
+	}
+	p.stopped = true
+	p.mu.Unlock()
+
+	close(p.quit)
+	p.wg.Wait()
+	fmt.Fprint(p.w, "\r\033[K")
+}
+
+func (p *Progress) loop() {
+	defer p.wg.Done()
+	ticker := time.NewTicker(progressTick)
+	defer ticker.Stop()
+	for {
+		p.mu.Lock()
+		line := p.render()
+		p.frame++
+		p.mu.Unlock()
+		fmt.Fprint(p.w, "\r\033[K"+line)
+
+		select {
+		case <-p.quit:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// render builds the current line. Callers hold p.mu.
+func (p *Progress) render() string {
+	var b strings.Builder
+	b.WriteString(Info.Render(spinnerFrames[p.frame%len(spinnerFrames)]))
+	b.WriteString(" ")
+	b.WriteString(p.label)
+	if p.total > 0 {
+		filled := p.done * progressBarWidth / p.total
+		if filled > progressBarWidth {
+			filled = progressBarWidth
+		}
+		fmt.Fprintf(&b, " %s%s %d/%d",
+			Success.Render(strings.Repeat("━", filled)),
+			Dim.Render(strings.Repeat("─", progressBarWidth-filled)),
+			p.done, p.total)
+	} else if p.done > 0 {
+		fmt.Fprintf(&b, " %d", p.done)
+	}
+	if len(p.active) > 0 {
+		b.WriteString(" ")
+		b.WriteString(Dim.Render(activeSummary(p.active, 3)))
+	}
+	return b.String()
+}
+
+// activeSummary lists up to max in-flight items, then a count of the rest.
+func activeSummary(active []string, max int) string {
+	if len(active) <= max {
+		return strings.Join(active, ", ")
+	}
+	return fmt.Sprintf("%s +%d more", strings.Join(active[:max], ", "), len(active)-max)
+}
@@ -0,0 +1,57 @@
+package style
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestActiveSummary(t *testing.T) {
+	tests := []struct {
+		active []string
+		want   string
+	}{
+		{[]string{"a"}, "a"},
+		{[]string{"a", "b", "c"}, "a, b, c"},
+		{[]string{"a", "b", "c", "d", "e"}, "a, b, c +2 more"},
+	}
+	for _, tt := range tests {
+		if got := activeSummary(tt.active, 3); got != tt.want {
+			t.Errorf("activeSummary(%v) = %q, want %q", tt.active, got, tt.want)
+		}
+	}
+}
+
+func TestProgressRender(t *testing.T) {
+	p := &Progress{label: "Checking", total: 4}
+	p.Start("gastown")
+	p.Start("beads")
+	p.Done("beads")
+
+	line := p.render()
+	for _, want := range []string{"Checking", "1/4", "gastown"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("render() = %q, missing %q", line, want)
+		}
+	}
+	if strings.Contains(line, "beads") {
+		t.Errorf("render() = %q, still lists finished item", line)
+	}
+}
+
+func TestProgressStopClearsLine(t *testing.T) {
+	var buf bytes.Buffer
+	p := NewProgress(&buf, "Working", 0)
+	p.Stop()
+	p.Stop() // second Stop is a no-op
+	if !strings.HasSuffix(buf.String(), "\r\033[K") {
+		t.Errorf("output %q doesn't end by clearing the line", buf.String())
+	}
+}
+
+func TestNilProgress(t *testing.T) {
+	var p *Progress
+	p.Start("x")
+	p.Done("x")
+	p.Stop()
+}
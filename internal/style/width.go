@@ -0,0 +1,85 @@
+package style
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"golang.org/x/term"
+)
+
+// EnvTitleWidth overrides TownSettings.CLITitleWidth.
+const EnvTitleWidth = "GT_TITLE_WIDTH"
+
+const (
+	// DefaultTitleWidth is how wide titles get when output isn't a terminal.
+	DefaultTitleWidth = 60
+
+	// MinTitleWidth keeps a title readable on a narrow terminal: the line
+	// wraps rather than cutting the title shorter than this.
+	MinTitleWidth = 20
+)
+
+// titleWidth is set once during startup by InitTitleWidth. Zero fits
+// titles to the terminal; negative never truncates them.
+var titleWidth int
+
+// InitTitleWidth sets how FitTitle truncates issue titles. configured is
+// TownSettings.CLITitleWidth; GT_TITLE_WIDTH, when it's a number, takes
+// precedence.
+func InitTitleWidth(configured int) {
+	titleWidth = configured
+	if n, err := strconv.Atoi(os.Getenv(EnvTitleWidth)); err == nil {
+		titleWidth = n
+	}
+}
+
+// TerminalWidth returns stdout's width in cells, or 0 when stdout isn't a
+// terminal.
+func TerminalWidth() int {
+	fd := int(os.Stdout.Fd())
+	if !term.IsTerminal(fd) {
+		return 0
+	}
+	width, _, err := term.GetSize(fd)
+	if err != nil || width <= 0 {
+		return 0
+	}
+	return width
+}
+
+// Width returns how many terminal cells s takes up, ignoring ANSI styling
+// and counting wide (CJK, emoji) characters as two.
+func Width(s string) int {
+	return lipgloss.Width(s)
+}
+
+// Truncate shortens s to at most width terminal cells, ending it with
+// "..." when anything was cut. It cuts between whole characters, never
+// inside a multibyte or wide one, and keeps ANSI styling intact.
+func Truncate(s string, width int) string {
+	const tail = "..."
+	if width <= len(tail) {
+		return ansi.Truncate(s, width, "")
+	}
+	return ansi.Truncate(s, width, tail)
+}
+
+// FitTitle truncates an issue title for a line whose other text takes up
+// used cells. The title gets the configured width (see InitTitleWidth) or,
+// by default, what's left of the terminal line, but never less than
+// MinTitleWidth. When stdout isn't a terminal it gets DefaultTitleWidth.
+func FitTitle(title string, used int) string {
+	width := titleWidth
+	if width < 0 {
+		return title
+	}
+	if width == 0 {
+		width = DefaultTitleWidth
+		if tw := TerminalWidth(); tw > 0 {
+			width = max(tw-used, MinTitleWidth)
+		}
+	}
+	return Truncate(title, width)
+}
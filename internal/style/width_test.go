@@ -0,0 +1,71 @@
+package style
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name  string
+		s     string
+		width int
+		want  string
+	}{
+		{"fits", "Fix login", 20, "Fix login"},
+		{"exact", "Fix login", 9, "Fix login"},
+		{"ascii", "Fix the login redirect loop", 12, "Fix the l..."},
+		{"tiny width", "Fix login", 3, "Fix"},
+		// Wide characters take two cells; none is split in half
+		{"cjk", "修复登录重定向循环", 10, "修复登..."},
+		{"cjk odd width", "修复登录重定向循环", 9, "修复登..."},
+		{"emoji", "🚀🚀🚀🚀 launch", 8, "🚀🚀..."},
+		{"accents", "Résumé des tâches à faire", 10, "Résumé ..."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Truncate(tt.s, tt.width)
+			if got != tt.want {
+				t.Errorf("Truncate(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+			}
+			if w := Width(got); w > tt.width {
+				t.Errorf("Truncate(%q, %d) is %d cells wide", tt.s, tt.width, w)
+			}
+		})
+	}
+}
+
+func TestTruncateKeepsStyling(t *testing.T) {
+	styled := "\x1b[1mFix the login redirect loop\x1b[0m"
+	got := Truncate(styled, 12)
+	if Width(got) != 12 || !strings.HasPrefix(got, "\x1b[1m") {
+		t.Errorf("Truncate(styled, 12) = %q (%d cells)", got, Width(got))
+	}
+}
+
+func TestFitTitle(t *testing.T) {
+	// Tests don't run on a terminal, so zero falls back to DefaultTitleWidth
+	long := strings.Repeat("x", 100)
+	tests := []struct {
+		name       string
+		configured int
+		env        string
+		want       int
+	}{
+		{"default", 0, "", DefaultTitleWidth},
+		{"configured", 30, "", 30},
+		{"never truncate", -1, "", 100},
+		{"env overrides", 30, "40", 40},
+		{"bad env ignored", 30, "wide", 30},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvTitleWidth, tt.env)
+			InitTitleWidth(tt.configured)
+			t.Cleanup(func() { InitTitleWidth(0) })
+			if got := Width(FitTitle(long, 10)); got != tt.want {
+				t.Errorf("FitTitle is %d cells wide, want %d", got, tt.want)
+			}
+		})
+	}
+}
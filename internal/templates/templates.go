@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"text/template"
 
@@ -43,6 +44,7 @@ var templateFS embed.FS
 type Templates struct {
 	roleTemplates    *template.Template
 	messageTemplates *template.Template
+	roleSources      map[string]string // role -> override file, for overridden roles
 }
 
 // RoleData contains information for rendering role contexts.
@@ -59,6 +61,9 @@ type RoleData struct {
 	IssuePrefix    string   // beads issue prefix
 	MayorSession   string   // e.g., "gt-ai-mayor" - dynamic mayor session name
 	DeaconSession  string   // e.g., "gt-ai-deacon" - dynamic deacon session name
+	Issue          string   // assigned issue ID, if any
+	IssueTitle     string   // assigned issue title
+	IssueContext   string   // rendered issue context bundle (gt context)
 }
 
 // SpawnData contains information for spawn assignment messages.
@@ -125,6 +130,81 @@ func New() (*Templates, error) {
 	return t, nil
 }
 
+// RoleOverrideDir returns the town directory holding role template overrides.
+// Town-wide overrides live at <dir>/<role>.md.tmpl and per-rig overrides at
+// <dir>/<rig>/<role>.md.tmpl, so every machine sharing the town renders the
+// same prompts.
+func RoleOverrideDir(townRoot string) string {
+	return filepath.Join(townRoot, "settings", "roles")
+}
+
+// RoleOverridePaths returns the override files consulted for a role, most
+// specific first. The first one that exists wins; if none exist, the
+// embedded template is used.
+func RoleOverridePaths(townRoot, rigName, role string) []string {
+	dir := RoleOverrideDir(townRoot)
+	var paths []string
+	if rigName != "" {
+		paths = append(paths, filepath.Join(dir, rigName, role+".md.tmpl"))
+	}
+	return append(paths, filepath.Join(dir, role+".md.tmpl"))
+}
+
+// LoadOverrides replaces embedded role templates with the town's overrides,
+// applying rigName's overrides over the town-wide ones. rigName may be empty
+// for town-level roles. Overrides may also define roles with no embedded
+// template.
+func (t *Templates) LoadOverrides(townRoot, rigName string) error {
+	dirs := []string{RoleOverrideDir(townRoot)}
+	if rigName != "" {
+		dirs = append(dirs, filepath.Join(dirs[0], rigName))
+	}
+
+	// Least specific first, so rig overrides replace town-wide ones
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return fmt.Errorf("reading role overrides: %w", err)
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() || !strings.HasSuffix(name, ".md.tmpl") {
+				continue
+			}
+			path := filepath.Join(dir, name)
+			content, err := os.ReadFile(path) //nolint:gosec // G304: path is within the town settings
+			if err != nil {
+				return fmt.Errorf("reading %s: %w", path, err)
+			}
+			if _, err := t.roleTemplates.New(name).Parse(string(content)); err != nil {
+				return fmt.Errorf("parsing %s: %w", path, err)
+			}
+			if t.roleSources == nil {
+				t.roleSources = make(map[string]string)
+			}
+			t.roleSources[strings.TrimSuffix(name, ".md.tmpl")] = path
+		}
+	}
+	return nil
+}
+
+// RoleSource returns the override file a role's template was loaded from,
+// or "embedded" if it wasn't overridden.
+func (t *Templates) RoleSource(role string) string {
+	if path, ok := t.roleSources[role]; ok {
+		return path
+	}
+	return "embedded"
+}
+
+// HasRole reports whether a template exists for role, embedded or overridden.
+func (t *Templates) HasRole(role string) bool {
+	return t.roleTemplates.Lookup(role+".md.tmpl") != nil
+}
+
 // RenderRole renders a role context template.
 func (t *Templates) RenderRole(role string, data RoleData) (string, error) {
 	templateName := role + ".md.tmpl"
@@ -151,7 +231,7 @@ func (t *Templates) RenderMessage(name string, data interface{}) (string, error)
 
 // RoleNames returns the list of available role templates.
 func (t *Templates) RoleNames() []string {
-	return []string{"mayor", "witness", "refinery", "polecat", "crew", "deacon"}
+	return []string{"mayor", "witness", "refinery", "polecat", "crew", "deacon", "reviewer"}
 }
 
 // MessageNames returns the list of available message templates.
@@ -1,6 +1,8 @@
 package templates
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -235,7 +237,7 @@ func TestRoleNames(t *testing.T) {
 	}
 
 	names := tmpl.RoleNames()
-	expected := []string{"mayor", "witness", "refinery", "polecat", "crew", "deacon"}
+	expected := []string{"mayor", "witness", "refinery", "polecat", "crew", "deacon", "reviewer"}
 
 	if len(names) != len(expected) {
 		t.Errorf("RoleNames() = %v, want %v", names, expected)
@@ -295,3 +297,98 @@ func TestGetAllRoleTemplates_ContentValidity(t *testing.T) {
 		}
 	}
 }
+
+func TestRenderRole_Reviewer(t *testing.T) {
+	tmpl, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	data := RoleData{
+		Role:         "reviewer",
+		RigName:      "myrig",
+		WorkDir:      "/test/town/myrig",
+		Issue:        "gt-abc",
+		IssueTitle:   "Fix the login flow",
+		IssueContext: "## Description\n\nTokens expire early.",
+	}
+
+	output, err := tmpl.RenderRole("reviewer", data)
+	if err != nil {
+		t.Fatalf("RenderRole() error = %v", err)
+	}
+	for _, want := range []string{"Reviewer Context", "mq next-review myrig", "gt-abc", "Tokens expire early."} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output missing %q", want)
+		}
+	}
+}
+
+func TestLoadOverrides(t *testing.T) {
+	townRoot := t.TempDir()
+	dir := RoleOverrideDir(townRoot)
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(dir, "witness.md.tmpl"), "town witness for {{ .RigName }}")
+	write(filepath.Join(dir, "polecat.md.tmpl"), "town polecat")
+	write(filepath.Join(dir, "myrig", "polecat.md.tmpl"), "rig polecat {{ .Polecat }}")
+	write(filepath.Join(dir, "auditor.md.tmpl"), "auditor")
+
+	tests := []struct {
+		rig, role, want, source string
+	}{
+		{"myrig", "witness", "town witness for myrig", filepath.Join(dir, "witness.md.tmpl")},
+		{"myrig", "polecat", "rig polecat toast", filepath.Join(dir, "myrig", "polecat.md.tmpl")},
+		{"otherrig", "polecat", "town polecat", filepath.Join(dir, "polecat.md.tmpl")},
+		{"myrig", "auditor", "auditor", filepath.Join(dir, "auditor.md.tmpl")},
+		{"myrig", "refinery", "Refinery Context", "embedded"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rig+"/"+tt.role, func(t *testing.T) {
+			tmpl, err := New()
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if err := tmpl.LoadOverrides(townRoot, tt.rig); err != nil {
+				t.Fatalf("LoadOverrides() error = %v", err)
+			}
+			output, err := tmpl.RenderRole(tt.role, RoleData{RigName: tt.rig, Polecat: "toast", DefaultBranch: "main"})
+			if err != nil {
+				t.Fatalf("RenderRole() error = %v", err)
+			}
+			if !strings.Contains(output, tt.want) {
+				t.Errorf("RenderRole(%s) = %q, want it to contain %q", tt.role, output, tt.want)
+			}
+			if got := tmpl.RoleSource(tt.role); got != tt.source {
+				t.Errorf("RoleSource(%s) = %q, want %q", tt.role, got, tt.source)
+			}
+		})
+	}
+}
+
+func TestLoadOverrides_ParseError(t *testing.T) {
+	townRoot := t.TempDir()
+	dir := RoleOverrideDir(townRoot)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "mayor.md.tmpl"), []byte("{{ .Broken "), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := tmpl.LoadOverrides(townRoot, ""); err == nil {
+		t.Error("LoadOverrides() should fail on a malformed template")
+	}
+}
@@ -0,0 +1,144 @@
+// Package testsuite selects which of a rig's named test suites an MR needs.
+//
+// A rig declares suites (unit, integration, lint, ...) in its merge_queue
+// config, each with a command and the path globs it covers. The refinery
+// runs only the suites whose paths an MR's diff touches, and 'gt test' lets
+// agents run the same selection locally.
+package testsuite
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Suite is one named test suite.
+type Suite struct {
+	// Name identifies the suite (e.g. "unit", "integration", "lint").
+	Name string `json:"name"`
+
+	// Command is run with sh -c from the repository root.
+	Command string `json:"command"`
+
+	// Paths are the globs the suite covers, relative to the repository
+	// root. "**" matches any number of directories, and a trailing "/"
+	// matches everything under a directory. A suite without paths runs
+	// for every change.
+	Paths []string `json:"paths,omitempty"`
+}
+
+// Covers reports whether a change to file should run the suite.
+func (s Suite) Covers(file string) bool {
+	if len(s.Paths) == 0 {
+		return true
+	}
+	for _, p := range s.Paths {
+		if Match(p, file) {
+			return true
+		}
+	}
+	return false
+}
+
+// Select returns the suites, in order, that cover at least one of files.
+// A nil files slice means the change is unknown, and selects every suite.
+func Select(suites []Suite, files []string) []Suite {
+	if files == nil {
+		return suites
+	}
+	var selected []Suite
+	for _, s := range suites {
+		for _, f := range files {
+			if s.Covers(f) {
+				selected = append(selected, s)
+				break
+			}
+		}
+	}
+	return selected
+}
+
+// Find returns the named suites, in the order given.
+func Find(suites []Suite, names []string) ([]Suite, error) {
+	var found []Suite
+	for _, name := range names {
+		ok := false
+		for _, s := range suites {
+			if s.Name == name {
+				found = append(found, s)
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return nil, fmt.Errorf("unknown test suite %q (have: %s)", name, strings.Join(Names(suites), ", "))
+		}
+	}
+	return found, nil
+}
+
+// Names returns the suites' names.
+func Names(suites []Suite) []string {
+	names := make([]string, len(suites))
+	for i, s := range suites {
+		names[i] = s.Name
+	}
+	return names
+}
+
+// Validate checks that suites are named uniquely and have commands and
+// well-formed globs.
+func Validate(suites []Suite) error {
+	seen := make(map[string]bool)
+	for i, s := range suites {
+		if s.Name == "" {
+			return fmt.Errorf("test suite %d has no name", i+1)
+		}
+		if seen[s.Name] {
+			return fmt.Errorf("duplicate test suite %q", s.Name)
+		}
+		seen[s.Name] = true
+		if strings.TrimSpace(s.Command) == "" {
+			return fmt.Errorf("test suite %q has no command", s.Name)
+		}
+		for _, p := range s.Paths {
+			if _, err := path.Match(p, p); err != nil {
+				return fmt.Errorf("test suite %q: bad path %q: %w", s.Name, p, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Match reports whether file matches the glob pattern. Patterns use
+// path.Match syntax per segment, plus "**" for any number of segments; a
+// pattern ending in "/" matches everything under that directory.
+func Match(pattern, file string) bool {
+	pattern = strings.TrimPrefix(pattern, "./")
+	file = strings.TrimPrefix(file, "./")
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(file, "/"))
+}
+
+func matchSegments(pattern, file []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(file); i++ {
+				if matchSegments(pattern[1:], file[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(file) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], file[0]); err != nil || !ok {
+			return false
+		}
+		pattern, file = pattern[1:], file[1:]
+	}
+	return len(file) == 0
+}
@@ -0,0 +1,87 @@
+package testsuite
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern, file string
+		want          bool
+	}{
+		{"internal/**", "internal/cmd/root.go", true},
+		{"internal/", "internal/cmd/root.go", true},
+		{"internal/", "internalx/a.go", false},
+		{"**/*.go", "main.go", true},
+		{"**/*.go", "a/b/c.go", true},
+		{"**/*.go", "a/b/c.md", false},
+		{"docs/*.md", "docs/a.md", true},
+		{"docs/*.md", "docs/sub/a.md", false},
+		{"web/**/*.ts", "web/src/app.ts", true},
+		{"web/**/*.ts", "web/app.ts", true},
+		{"go.mod", "go.mod", true},
+		{"./go.mod", "go.mod", true},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.file); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestSelect(t *testing.T) {
+	suites := []Suite{
+		{Name: "unit", Command: "go test ./...", Paths: []string{"**/*.go", "go.mod"}},
+		{Name: "web", Command: "npm test", Paths: []string{"web/"}},
+		{Name: "lint", Command: "make lint"},
+	}
+	tests := []struct {
+		name  string
+		files []string
+		want  []string
+	}{
+		{"go change", []string{"internal/a.go"}, []string{"unit", "lint"}},
+		{"web change", []string{"web/src/app.ts"}, []string{"web", "lint"}},
+		{"both", []string{"web/x.ts", "go.mod"}, []string{"unit", "web", "lint"}},
+		{"docs only", []string{"README.md"}, []string{"lint"}},
+		{"no files", []string{}, nil},
+		{"unknown diff", nil, []string{"unit", "web", "lint"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Names(Select(suites, tt.files))
+			if len(got) == 0 {
+				got = nil
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Select = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindAndValidate(t *testing.T) {
+	suites := []Suite{{Name: "unit", Command: "go test"}, {Name: "lint", Command: "make lint"}}
+	got, err := Find(suites, []string{"lint", "unit"})
+	if err != nil || !reflect.DeepEqual(Names(got), []string{"lint", "unit"}) {
+		t.Errorf("Find = %v, %v", Names(got), err)
+	}
+	if _, err := Find(suites, []string{"e2e"}); err == nil {
+		t.Error("Find(unknown) should error")
+	}
+
+	if err := Validate(suites); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+	for _, bad := range [][]Suite{
+		{{Command: "x"}},
+		{{Name: "a", Command: "x"}, {Name: "a", Command: "y"}},
+		{{Name: "a"}},
+		{{Name: "a", Command: "x", Paths: []string{"src/[a-"}}},
+	} {
+		if err := Validate(bad); err == nil {
+			t.Errorf("Validate(%v) should error", bad)
+		}
+	}
+}
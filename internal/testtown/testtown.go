@@ -0,0 +1,445 @@
+// Package testtown generates synthetic towns for hermetic tests: rigs with
+// registry and config entries, beads fixtures (work issues, agent beads,
+// merge requests), and git repos with fake history and merge-request
+// branches. The same Options always produce the same town, down to commit
+// hashes, so tests and plugin authors can assert against it without
+// cloning real repos.
+//
+// Beads are written as JSONL in bd's export format (.beads/issues.jsonl) and
+// returned in memory; Import loads them into bd when it is available.
+package testtown
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// Epoch is the fixed time generated history starts from.
+var Epoch = time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+
+// IssuesFile is the beads fixture each beads directory gets.
+const IssuesFile = "issues.jsonl"
+
+var (
+	rigNames     = []string{"alpha", "bravo", "charlie", "delta", "echo", "foxtrot", "golf", "hotel"}
+	polecatNames = []string{"Toast", "Nux", "Slit", "Furiosa", "Capable", "Dag", "Ace", "Morsov"}
+	verbs        = []string{"Fix", "Add", "Refactor", "Document", "Speed up", "Remove", "Test", "Rename"}
+	subjects     = []string{"config loader", "retry logic", "status output", "merge queue", "session cleanup", "mail routing", "CLI flags", "error messages"}
+	types        = []string{"task", "bug", "feature"}
+	statuses     = []string{"open", "open", "in_progress", "hooked", "closed"}
+)
+
+// Options controls the generated town. Zero values take the defaults.
+type Options struct {
+	Name           string // Town name (default "testtown")
+	Rigs           int    // Number of rigs (default 2, at most 8)
+	IssuesPerRig   int    // Work issues per rig (default 10)
+	CommitsPerRig  int    // Commits on main per rig (default 5)
+	MergeRequests  int    // Open merge requests per rig (default 3, negative for none)
+	PolecatsPerRig int    // Polecats per rig (default 2, at most 8)
+	Seed           int64  // Random seed (default 1)
+}
+
+func (o Options) withDefaults() Options {
+	if o.Name == "" {
+		o.Name = "testtown"
+	}
+	if o.Rigs <= 0 {
+		o.Rigs = 2
+	}
+	o.Rigs = min(o.Rigs, len(rigNames))
+	if o.IssuesPerRig <= 0 {
+		o.IssuesPerRig = 10
+	}
+	if o.CommitsPerRig <= 0 {
+		o.CommitsPerRig = 5
+	}
+	if o.MergeRequests < 0 {
+		o.MergeRequests = 0
+	} else if o.MergeRequests == 0 {
+		o.MergeRequests = 3
+	}
+	if o.PolecatsPerRig <= 0 {
+		o.PolecatsPerRig = 2
+	}
+	o.PolecatsPerRig = min(o.PolecatsPerRig, len(polecatNames))
+	if o.Seed == 0 {
+		o.Seed = 1
+	}
+	return o
+}
+
+// Town is a generated town.
+type Town struct {
+	Root   string
+	Name   string
+	Rigs   []*Rig
+	Agents []*beads.Issue // Town-level agent beads (hq-)
+}
+
+// Rig is a generated rig.
+type Rig struct {
+	Name     string
+	Prefix   string // Beads prefix, without the hyphen
+	Path     string
+	RepoPath string // mayor/rig clone
+	Polecats []string
+
+	Issues        []*beads.Issue // Work issues
+	Agents        []*beads.Issue // Witness, refinery, and polecat agent beads
+	MergeRequests []*beads.Issue // Open MRs, each with a branch in RepoPath
+	Commits       []string       // Commit hashes on main, oldest first
+}
+
+// Rig returns the named rig, or nil.
+func (t *Town) Rig(name string) *Rig {
+	for _, r := range t.Rigs {
+		if r.Name == name {
+			return r
+		}
+	}
+	return nil
+}
+
+// Generate creates a town at root, which must be empty or not exist.
+func Generate(root string, opts Options) (*Town, error) {
+	opts = opts.withDefaults()
+	if entries, err := os.ReadDir(root); err == nil && len(entries) > 0 {
+		return nil, fmt.Errorf("%s is not empty", root)
+	}
+	g := &generator{rng: rand.New(rand.NewSource(opts.Seed)), opts: opts} //nolint:gosec // G404: fixtures, not security
+
+	town := &Town{Root: root, Name: opts.Name}
+	if err := config.SaveTownConfig(filepath.Join(root, "mayor", "town.json"), &config.TownConfig{
+		Type:      "town",
+		Version:   config.CurrentTownVersion,
+		Name:      opts.Name,
+		CreatedAt: Epoch,
+	}); err != nil {
+		return nil, err
+	}
+
+	rigs := &config.RigsConfig{Version: config.CurrentRigsVersion, Rigs: make(map[string]config.RigEntry)}
+	routes := []beads.Route{{Prefix: "hq-", Path: "."}}
+	for i := 0; i < opts.Rigs; i++ {
+		r, err := g.rig(root, rigNames[i])
+		if err != nil {
+			return nil, fmt.Errorf("rig %s: %w", rigNames[i], err)
+		}
+		town.Rigs = append(town.Rigs, r)
+		rigs.Rigs[r.Name] = config.RigEntry{
+			GitURL:      "https://example.com/" + r.Name + ".git",
+			AddedAt:     Epoch,
+			BeadsConfig: &config.BeadsConfig{Repo: "local", Prefix: r.Prefix},
+		}
+		routes = append(routes, beads.Route{Prefix: r.Prefix + "-", Path: r.Name + "/mayor/rig"})
+	}
+	if err := config.SaveRigsConfig(filepath.Join(root, "mayor", "rigs.json"), rigs); err != nil {
+		return nil, err
+	}
+
+	townBeads := filepath.Join(root, ".beads")
+	for _, role := range []string{"mayor", "deacon"} {
+		town.Agents = append(town.Agents, g.agent("hq-"+role, role, "", ""))
+	}
+	if err := writeBeads(townBeads, "hq", town.Agents); err != nil {
+		return nil, err
+	}
+	if err := beads.WriteRoutes(townBeads, routes); err != nil {
+		return nil, err
+	}
+	return town, nil
+}
+
+// New generates a town in a temp dir, failing the test on error.
+func New(t testing.TB, opts Options) *Town {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	town, err := Generate(t.TempDir(), opts)
+	if err != nil {
+		t.Fatalf("generating test town: %v", err)
+	}
+	return town
+}
+
+// Import loads the town's beads fixtures into bd, initializing each
+// beads directory. Requires bd.
+func (t *Town) Import() error {
+	dirs := []struct{ dir, prefix string }{{filepath.Join(t.Root, ".beads"), "hq"}}
+	for _, r := range t.Rigs {
+		dirs = append(dirs, struct{ dir, prefix string }{filepath.Join(r.RepoPath, ".beads"), r.Prefix})
+	}
+	for _, d := range dirs {
+		for _, args := range [][]string{
+			{"init", "--prefix", d.prefix, "--quiet"},
+			{"import", "-i", filepath.Join(d.dir, IssuesFile)},
+		} {
+			cmd := exec.Command("bd", append([]string{"--no-daemon"}, args...)...) //nolint:gosec // G204: bd is a trusted internal tool
+			cmd.Dir = filepath.Dir(d.dir)
+			cmd.Env = append(os.Environ(), "BEADS_DIR="+d.dir)
+			if out, err := cmd.CombinedOutput(); err != nil {
+				return fmt.Errorf("bd %s in %s: %s", args[0], d.dir, strings.TrimSpace(string(out)))
+			}
+		}
+	}
+	return nil
+}
+
+type generator struct {
+	rng   *rand.Rand
+	opts  Options
+	clock int // Minutes since Epoch, advanced for every generated object
+}
+
+// tick returns the next timestamp in the town's history.
+func (g *generator) tick() time.Time {
+	g.clock += 1 + g.rng.Intn(90)
+	return Epoch.Add(time.Duration(g.clock) * time.Minute)
+}
+
+func (g *generator) pick(list []string) string {
+	return list[g.rng.Intn(len(list))]
+}
+
+func (g *generator) rig(root, name string) (*Rig, error) {
+	r := &Rig{
+		Name:     name,
+		Prefix:   name[:2],
+		Path:     filepath.Join(root, name),
+		RepoPath: filepath.Join(root, name, "mayor", "rig"),
+		Polecats: polecatNames[:g.opts.PolecatsPerRig],
+	}
+	if err := config.SaveRigConfig(filepath.Join(r.Path, "config.json"), &config.RigConfig{
+		Type:      "rig",
+		Version:   config.CurrentRigConfigVersion,
+		Name:      name,
+		GitURL:    "https://example.com/" + name + ".git",
+		CreatedAt: Epoch,
+		Beads:     &config.BeadsConfig{Repo: "local", Prefix: r.Prefix},
+	}); err != nil {
+		return nil, err
+	}
+	for _, dir := range []string{"polecats", "crew", "witness", "refinery"} {
+		if err := os.MkdirAll(filepath.Join(r.Path, dir), 0755); err != nil {
+			return nil, err
+		}
+	}
+
+	// Work issues, with hooked and in-progress work assigned to polecats.
+	for i := 0; i < g.opts.IssuesPerRig; i++ {
+		at := g.tick().Format(time.RFC3339)
+		issue := &beads.Issue{
+			ID:        fmt.Sprintf("%s-%03d", r.Prefix, i+1),
+			Title:     g.pick(verbs) + " " + g.pick(subjects),
+			Status:    g.pick(statuses),
+			Priority:  g.rng.Intn(5),
+			Type:      g.pick(types),
+			CreatedAt: at,
+			UpdatedAt: at,
+		}
+		if issue.Status == "hooked" || issue.Status == "in_progress" {
+			issue.Assignee = name + "/polecats/" + g.pick(r.Polecats)
+		}
+		if issue.Status == "closed" {
+			issue.ClosedAt = at
+			issue.CloseReason = "done"
+		}
+		r.Issues = append(r.Issues, issue)
+	}
+
+	// Agent beads: a polecat is working if it has hooked work.
+	r.Agents = append(r.Agents,
+		g.agent(beads.WitnessBeadIDWithPrefix(r.Prefix, name), "witness", name, ""),
+		g.agent(beads.RefineryBeadIDWithPrefix(r.Prefix, name), "refinery", name, ""))
+	for _, p := range r.Polecats {
+		a := g.agent(beads.AgentBeadIDWithPrefix(r.Prefix, name, "polecat", p), "polecat", name, p)
+		a.AgentState = "idle"
+		for _, issue := range r.Issues {
+			if issue.Status == "hooked" && issue.Assignee == name+"/polecats/"+p {
+				a.AgentState, a.HookBead = "working", issue.ID
+				break
+			}
+		}
+		r.Agents = append(r.Agents, a)
+	}
+
+	if err := g.repo(r); err != nil {
+		return nil, err
+	}
+
+	all := append(append(append([]*beads.Issue{}, r.Issues...), r.Agents...), r.MergeRequests...)
+	if err := writeBeads(filepath.Join(r.RepoPath, ".beads"), r.Prefix, all); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (g *generator) agent(id, role, rig, name string) *beads.Issue {
+	at := g.tick().Format(time.RFC3339)
+	title := role
+	if rig != "" {
+		title = rig + " " + role
+	}
+	if name != "" {
+		title += " " + name
+	}
+	return &beads.Issue{
+		ID:         id,
+		Title:      title,
+		Status:     "open",
+		Priority:   2,
+		Type:       "agent",
+		Labels:     []string{"gt:agent"},
+		AgentState: "running",
+		CreatedAt:  at,
+		UpdatedAt:  at,
+	}
+}
+
+// repo creates the rig's git history on main and a branch per merge
+// request, each with one commit on top of main.
+func (g *generator) repo(r *Rig) error {
+	if err := os.MkdirAll(r.RepoPath, 0755); err != nil {
+		return err
+	}
+	if err := g.git(r.RepoPath, time.Time{}, "init", "--quiet", "--initial-branch=main"); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(r.RepoPath, ".gitignore"), []byte(".beads/\n"), 0644); err != nil {
+		return err
+	}
+	for i := 0; i < g.opts.CommitsPerRig; i++ {
+		subject := g.pick(verbs) + " " + g.pick(subjects)
+		file := filepath.Join(r.RepoPath, "src", fmt.Sprintf("file%d.go", i%4))
+		if err := appendLine(file, fmt.Sprintf("// %d: %s", i, subject)); err != nil {
+			return err
+		}
+		hash, err := g.commit(r.RepoPath, subject)
+		if err != nil {
+			return err
+		}
+		r.Commits = append(r.Commits, hash)
+	}
+
+	// Merge requests draw on open work, falling back to new issues.
+	var candidates []*beads.Issue
+	for _, issue := range r.Issues {
+		if issue.Status != "closed" {
+			candidates = append(candidates, issue)
+		}
+	}
+	for i := 0; i < g.opts.MergeRequests; i++ {
+		worker := r.Polecats[i%len(r.Polecats)]
+		source := fmt.Sprintf("%s-%03d", r.Prefix, g.opts.IssuesPerRig+i+1)
+		title := g.pick(verbs) + " " + g.pick(subjects)
+		if i < len(candidates) {
+			source, title = candidates[i].ID, candidates[i].Title
+		}
+		branch := fmt.Sprintf("polecat/%s/%s", worker, source)
+		if err := g.git(r.RepoPath, time.Time{}, "checkout", "--quiet", "-b", branch, "main"); err != nil {
+			return err
+		}
+		if err := appendLine(filepath.Join(r.RepoPath, "src", strings.ToLower(worker)+".go"), "// "+title); err != nil {
+			return err
+		}
+		if _, err := g.commit(r.RepoPath, title+" ("+source+")"); err != nil {
+			return err
+		}
+
+		at := g.tick().Format(time.RFC3339)
+		r.MergeRequests = append(r.MergeRequests, &beads.Issue{
+			ID:       fmt.Sprintf("%s-mr%d", r.Prefix, i+1),
+			Title:    "Merge: " + source,
+			Status:   "open",
+			Priority: 2,
+			Type:     "merge-request",
+			Labels:   []string{"gt:merge-request"},
+			Description: beads.FormatMRFields(&beads.MRFields{
+				Branch:      branch,
+				Target:      "main",
+				SourceIssue: source,
+				Worker:      r.Name + "/polecats/" + worker,
+				Rig:         r.Name,
+			}),
+			CreatedAt: at,
+			UpdatedAt: at,
+		})
+	}
+	return g.git(r.RepoPath, time.Time{}, "checkout", "--quiet", "main")
+}
+
+func (g *generator) commit(dir, subject string) (string, error) {
+	if err := g.git(dir, time.Time{}, "add", "-A"); err != nil {
+		return "", err
+	}
+	if err := g.git(dir, g.tick(), "commit", "--quiet", "-m", subject); err != nil {
+		return "", err
+	}
+	cmd := exec.Command("git", "rev-parse", "HEAD")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// git runs git with a fixed identity and, when at is set, fixed commit
+// dates, so history is reproducible.
+func (g *generator) git(dir string, at time.Time, args ...string) error {
+	args = append([]string{"-c", "user.name=Test Town", "-c", "user.email=testtown@example.com", "-c", "commit.gpgsign=false"}, args...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = os.Environ()
+	if !at.IsZero() {
+		date := at.Format(time.RFC3339)
+		cmd.Env = append(cmd.Env, "GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git %s: %s", strings.Join(args[6:], " "), strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func appendLine(path, line string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644) //nolint:gosec // G304: path is within the generated town
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(f, line); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeBeads writes a beads directory's config and issues fixture.
+func writeBeads(dir, prefix string, issues []*beads.Issue) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("issue-prefix: "+prefix+"\n"), 0644); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, issue := range issues {
+		if err := enc.Encode(issue); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(filepath.Join(dir, IssuesFile), buf.Bytes(), 0644)
+}
@@ -0,0 +1,98 @@
+package testtown
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+func TestGenerate(t *testing.T) {
+	town := New(t, Options{Rigs: 3, IssuesPerRig: 8, CommitsPerRig: 4, MergeRequests: 2})
+
+	if root, err := workspace.Find(filepath.Join(town.Root, "bravo", "mayor", "rig")); err != nil || root != town.Root {
+		t.Errorf("workspace.Find() = %q, %v; want %q", root, err, town.Root)
+	}
+	rigs, err := config.LoadRigsConfig(filepath.Join(town.Root, "mayor", "rigs.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rigs.Rigs) != 3 || len(town.Rigs) != 3 {
+		t.Fatalf("got %d registered, %d generated rigs; want 3", len(rigs.Rigs), len(town.Rigs))
+	}
+	routes, err := beads.LoadRoutes(filepath.Join(town.Root, ".beads"))
+	if err != nil || len(routes) != 4 {
+		t.Errorf("routes = %v, %v; want hq plus one per rig", routes, err)
+	}
+
+	r := town.Rig("alpha")
+	if r == nil {
+		t.Fatal("no rig alpha")
+	}
+	if len(r.Issues) != 8 || len(r.Commits) != 4 || len(r.MergeRequests) != 2 {
+		t.Errorf("alpha has %d issues, %d commits, %d MRs; want 8, 4, 2", len(r.Issues), len(r.Commits), len(r.MergeRequests))
+	}
+
+	data, err := os.ReadFile(filepath.Join(r.RepoPath, ".beads", IssuesFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Count(string(data), "\n"), len(r.Issues)+len(r.Agents)+len(r.MergeRequests); got != want {
+		t.Errorf("%s has %d lines, want %d", IssuesFile, got, want)
+	}
+
+	for _, mr := range r.MergeRequests {
+		fields := beads.ParseMRFields(mr)
+		if fields == nil || fields.Target != "main" || fields.Rig != "alpha" {
+			t.Fatalf("MR %s fields = %+v", mr.ID, fields)
+		}
+		cmd := exec.Command("git", "rev-list", "--count", "main.."+fields.Branch)
+		cmd.Dir = r.RepoPath
+		if out, err := cmd.Output(); err != nil || strings.TrimSpace(string(out)) != "1" {
+			t.Errorf("branch %s is %q commits ahead of main (%v), want 1", fields.Branch, out, err)
+		}
+	}
+
+	for _, a := range r.Agents {
+		if a.HookBead == "" {
+			continue
+		}
+		if a.AgentState != "working" {
+			t.Errorf("%s has hooked work but agent_state %q", a.ID, a.AgentState)
+		}
+		if _, _, _, ok := beads.ParseAgentBeadID(a.ID); !ok {
+			t.Errorf("agent bead ID %q doesn't parse", a.ID)
+		}
+	}
+}
+
+func TestGenerateDeterministic(t *testing.T) {
+	a := New(t, Options{Seed: 42})
+	b := New(t, Options{Seed: 42})
+	c := New(t, Options{Seed: 7})
+
+	if a.Rigs[0].Commits[len(a.Rigs[0].Commits)-1] != b.Rigs[0].Commits[len(b.Rigs[0].Commits)-1] {
+		t.Error("same seed produced different git history")
+	}
+	if a.Rigs[0].Issues[0].Title != b.Rigs[0].Issues[0].Title {
+		t.Error("same seed produced different issues")
+	}
+	if a.Rigs[0].Commits[0] == c.Rigs[0].Commits[0] {
+		t.Error("different seeds produced the same git history")
+	}
+}
+
+func TestGenerateRefusesNonEmptyDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "keep"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Generate(dir, Options{}); err == nil {
+		t.Error("Generate() into a non-empty directory succeeded")
+	}
+}
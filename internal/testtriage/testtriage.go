@@ -0,0 +1,357 @@
+// Package testtriage turns raw test-run output into structured failures and
+// classifies them as real regressions or flakes.
+//
+// Output is parsed in one of three formats:
+//   - go:    `go test` output (--- FAIL lines, attributed to the FAIL package line)
+//   - junit: JUnit XML reports (testsuite/testcase/failure)
+//   - regex: a configurable pattern with named groups test, package, message
+//
+// Classification works across retry attempts: a test that failed on every
+// attempt is a real failure, one that failed on some attempts but not others
+// is a flake.
+package testtriage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Output formats.
+const (
+	FormatGo    = "go"
+	FormatJUnit = "junit"
+	FormatRegex = "regex"
+)
+
+// Classes assigned to a triaged run.
+const (
+	ClassReal  = "real"
+	ClassFlaky = "flaky"
+	ClassNone  = "none"
+)
+
+// maxMessageLines caps the failure message captured per test.
+const maxMessageLines = 5
+
+// Failure is a single failed test.
+type Failure struct {
+	Package string `json:"package,omitempty"`
+	Test    string `json:"test"`
+	Message string `json:"message,omitempty"`
+}
+
+// ID returns a stable identifier for the failure (package.Test or Test).
+func (f Failure) ID() string {
+	if f.Package == "" {
+		return f.Test
+	}
+	return f.Package + "." + f.Test
+}
+
+// Parse extracts failures from test output in the given format.
+// An empty format defaults to go. For the regex format, pattern must be a
+// regular expression with at least a named "test" group.
+func Parse(output []byte, format, pattern string) ([]Failure, error) {
+	switch format {
+	case "", FormatGo:
+		return ParseGoTest(output), nil
+	case FormatJUnit:
+		return ParseJUnit(output)
+	case FormatRegex:
+		return ParseRegex(output, pattern)
+	default:
+		return nil, fmt.Errorf("unknown test output format %q (want go, junit, or regex)", format)
+	}
+}
+
+var (
+	goFailRe    = regexp.MustCompile(`^\s*--- FAIL: (\S+)`)
+	goPkgFailRe = regexp.MustCompile(`^FAIL\s+(\S+)\s`)
+)
+
+// ParseGoTest parses `go test` output. go test prints --- FAIL lines for a
+// package before the package's own "FAIL <pkg> <time>" line, so failures are
+// attributed to the next package line seen.
+func ParseGoTest(output []byte) []Failure {
+	var (
+		failures []Failure
+		pending  []int // indexes of failures awaiting a package
+		current  = -1  // failure currently collecting message lines
+		msgLines int
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if m := goFailRe.FindStringSubmatch(line); m != nil {
+			failures = append(failures, Failure{Test: m[1]})
+			current = len(failures) - 1
+			pending = append(pending, current)
+			msgLines = 0
+			continue
+		}
+
+		if m := goPkgFailRe.FindStringSubmatch(line); m != nil {
+			for _, i := range pending {
+				failures[i].Package = m[1]
+			}
+			pending = nil
+			current = -1
+			continue
+		}
+
+		// Indented lines after --- FAIL are the test's log output.
+		if current >= 0 && strings.HasPrefix(line, "    ") && msgLines < maxMessageLines {
+			text := strings.TrimSpace(line)
+			if text == "" {
+				continue
+			}
+			if failures[current].Message != "" {
+				failures[current].Message += "\n"
+			}
+			failures[current].Message += text
+			msgLines++
+			continue
+		}
+
+		if !strings.HasPrefix(line, " ") {
+			current = -1
+		}
+	}
+
+	return dropParentFailures(failures)
+}
+
+// dropParentFailures removes a failing test when one of its subtests also
+// failed, so TestFoo/bar is reported instead of both TestFoo and TestFoo/bar.
+func dropParentFailures(failures []Failure) []Failure {
+	parents := make(map[string]bool)
+	for _, f := range failures {
+		if i := strings.LastIndex(f.Test, "/"); i > 0 {
+			parents[f.Package+"."+f.Test[:i]] = true
+		}
+	}
+	if len(parents) == 0 {
+		return failures
+	}
+	kept := failures[:0]
+	for _, f := range failures {
+		if !parents[f.Package+"."+f.Test] {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// junitSuites covers both <testsuites> and bare <testsuite> roots.
+type junitSuites struct {
+	Suites []junitSuite `xml:"testsuite"`
+	junitSuite
+}
+
+type junitSuite struct {
+	Name   string       `xml:"name,attr"`
+	Suites []junitSuite `xml:"testsuite"`
+	Cases  []struct {
+		Name      string `xml:"name,attr"`
+		Classname string `xml:"classname,attr"`
+		Failure   *struct {
+			Message string `xml:"message,attr"`
+			Text    string `xml:",chardata"`
+		} `xml:"failure"`
+		Error *struct {
+			Message string `xml:"message,attr"`
+			Text    string `xml:",chardata"`
+		} `xml:"error"`
+	} `xml:"testcase"`
+}
+
+// ParseJUnit parses a JUnit XML report.
+func ParseJUnit(data []byte) ([]Failure, error) {
+	var root junitSuites
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("parsing junit report: %w", err)
+	}
+
+	var failures []Failure
+	var walk func(s junitSuite)
+	walk = func(s junitSuite) {
+		for _, c := range s.Cases {
+			var msg, text string
+			switch {
+			case c.Failure != nil:
+				msg, text = c.Failure.Message, c.Failure.Text
+			case c.Error != nil:
+				msg, text = c.Error.Message, c.Error.Text
+			default:
+				continue
+			}
+			if msg == "" {
+				msg = firstLines(strings.TrimSpace(text), maxMessageLines)
+			}
+			pkg := c.Classname
+			if pkg == "" {
+				pkg = s.Name
+			}
+			failures = append(failures, Failure{Package: pkg, Test: c.Name, Message: msg})
+		}
+		for _, child := range s.Suites {
+			walk(child)
+		}
+	}
+	walk(root.junitSuite)
+	for _, s := range root.Suites {
+		walk(s)
+	}
+	return failures, nil
+}
+
+// ParseRegex extracts failures using a pattern with named groups. The "test"
+// group is required; "package" and "message" are optional.
+func ParseRegex(output []byte, pattern string) ([]Failure, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("regex format requires a failure pattern")
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid failure pattern: %w", err)
+	}
+	testIdx := re.SubexpIndex("test")
+	if testIdx < 0 {
+		return nil, fmt.Errorf("failure pattern must have a named group (?P<test>...)")
+	}
+	pkgIdx := re.SubexpIndex("package")
+	msgIdx := re.SubexpIndex("message")
+
+	var failures []Failure
+	for _, m := range re.FindAllSubmatch(output, -1) {
+		f := Failure{Test: string(m[testIdx])}
+		if pkgIdx >= 0 {
+			f.Package = string(m[pkgIdx])
+		}
+		if msgIdx >= 0 {
+			f.Message = strings.TrimSpace(string(m[msgIdx]))
+		}
+		if f.Test != "" {
+			failures = append(failures, f)
+		}
+	}
+	return failures, nil
+}
+
+// Attempt is the outcome of one test run.
+type Attempt struct {
+	Passed   bool      `json:"passed"`
+	Failures []Failure `json:"failures,omitempty"`
+}
+
+// Report is the triage result across all attempts of a test run.
+type Report struct {
+	Attempts int       `json:"attempts"`
+	Passed   bool      `json:"passed"` // Final attempt passed
+	Class    string    `json:"class"`  // real, flaky, or none
+	Real     []Failure `json:"real,omitempty"`
+	Flaky    []Failure `json:"flaky,omitempty"`
+
+	// Unparsed is set when a run failed but no individual failures could be
+	// extracted (build errors, unsupported output, wrong format).
+	Unparsed bool `json:"unparsed,omitempty"`
+
+	// Quarantined lists failures of known-flaky tests. Callers fill this in;
+	// Classify does not consult any quarantine registry.
+	Quarantined []Failure `json:"quarantined,omitempty"`
+}
+
+// Classify triages a sequence of attempts. A test that failed in every
+// attempt is real; a test that failed in some attempts is flaky. If the final
+// attempt passed, everything seen failing earlier is flaky.
+func Classify(attempts []Attempt) *Report {
+	r := &Report{Attempts: len(attempts), Class: ClassNone}
+	if len(attempts) == 0 {
+		return r
+	}
+	r.Passed = attempts[len(attempts)-1].Passed
+
+	counts := make(map[string]int)
+	first := make(map[string]Failure)
+	var order []string
+	failedRuns := 0
+	for _, a := range attempts {
+		if a.Passed {
+			continue
+		}
+		failedRuns++
+		seen := make(map[string]bool)
+		for _, f := range a.Failures {
+			id := f.ID()
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			if _, ok := first[id]; !ok {
+				first[id] = f
+				order = append(order, id)
+			}
+			counts[id]++
+		}
+		if len(a.Failures) == 0 && !r.Passed {
+			r.Unparsed = true
+		}
+	}
+
+	sort.Strings(order)
+	for _, id := range order {
+		if !r.Passed && counts[id] == len(attempts) {
+			r.Real = append(r.Real, first[id])
+		} else {
+			r.Flaky = append(r.Flaky, first[id])
+		}
+	}
+
+	switch {
+	case len(r.Real) > 0 || (r.Unparsed && !r.Passed):
+		r.Class = ClassReal
+	case len(r.Flaky) > 0 || failedRuns > 0:
+		r.Class = ClassFlaky
+	}
+	return r
+}
+
+// IDs returns the identifiers of the given failures.
+func IDs(failures []Failure) []string {
+	ids := make([]string, len(failures))
+	for i, f := range failures {
+		ids[i] = f.ID()
+	}
+	return ids
+}
+
+// Summary returns a one-line description of the report.
+func (r *Report) Summary() string {
+	switch r.Class {
+	case ClassReal:
+		if r.Unparsed && len(r.Real) == 0 {
+			return fmt.Sprintf("tests failed after %d attempt(s); no individual failures parsed", r.Attempts)
+		}
+		return fmt.Sprintf("%d real failure(s), %d flaky after %d attempt(s)", len(r.Real), len(r.Flaky), r.Attempts)
+	case ClassFlaky:
+		return fmt.Sprintf("passed after %d attempt(s); %d flaky test(s)", r.Attempts, len(r.Flaky))
+	default:
+		return "all tests passed"
+	}
+}
+
+func firstLines(s string, n int) string {
+	lines := strings.SplitN(s, "\n", n+1)
+	if len(lines) > n {
+		lines = lines[:n]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,149 @@
+package testtriage
+
+import (
+	"testing"
+)
+
+func TestParseGoTest(t *testing.T) {
+	output := []byte(`=== RUN   TestAdd
+--- PASS: TestAdd (0.00s)
+=== RUN   TestDiv
+    math_test.go:12: expected 2, got 3
+--- FAIL: TestDiv (0.00s)
+=== RUN   TestTable
+=== RUN   TestTable/zero
+    math_test.go:30: division by zero
+--- FAIL: TestTable (0.00s)
+    --- FAIL: TestTable/zero (0.00s)
+FAIL
+FAIL	example.com/math	0.012s
+--- FAIL: TestFetch (1.00s)
+    net_test.go:8: timeout
+FAIL	example.com/net	1.020s
+ok  	example.com/other	0.003s
+`)
+
+	failures := ParseGoTest(output)
+	want := []string{"example.com/math.TestDiv", "example.com/math.TestTable/zero", "example.com/net.TestFetch"}
+	if got := IDs(failures); !equalStrings(got, want) {
+		t.Fatalf("IDs = %v, want %v", got, want)
+	}
+	if failures[2].Message != "net_test.go:8: timeout" {
+		t.Errorf("message = %q", failures[2].Message)
+	}
+}
+
+func TestParseJUnit(t *testing.T) {
+	data := []byte(`<?xml version="1.0"?>
+<testsuites>
+  <testsuite name="pkg/a">
+    <testcase name="TestOK" classname="pkg/a"/>
+    <testcase name="TestBad" classname="pkg/a"><failure message="boom">stack</failure></testcase>
+  </testsuite>
+  <testsuite name="pkg/b">
+    <testcase name="TestErr"><error>panic: nil map
+goroutine 1</error></testcase>
+  </testsuite>
+</testsuites>`)
+
+	failures, err := ParseJUnit(data)
+	if err != nil {
+		t.Fatalf("ParseJUnit: %v", err)
+	}
+	if got, want := IDs(failures), []string{"pkg/a.TestBad", "pkg/b.TestErr"}; !equalStrings(got, want) {
+		t.Fatalf("IDs = %v, want %v", got, want)
+	}
+	if failures[0].Message != "boom" {
+		t.Errorf("failure message = %q, want boom", failures[0].Message)
+	}
+
+	single, err := ParseJUnit([]byte(`<testsuite name="s"><testcase name="T"><failure/></testcase></testsuite>`))
+	if err != nil {
+		t.Fatalf("ParseJUnit single suite: %v", err)
+	}
+	if len(single) != 1 || single[0].ID() != "s.T" {
+		t.Errorf("single suite failures = %+v", single)
+	}
+}
+
+func TestParseRegex(t *testing.T) {
+	output := []byte("FAILED tests/test_api.py::test_login - AssertionError\nPASSED tests/test_api.py::test_ok\n")
+	failures, err := ParseRegex(output, `FAILED (?P<package>\S+)::(?P<test>\S+) - (?P<message>.*)`)
+	if err != nil {
+		t.Fatalf("ParseRegex: %v", err)
+	}
+	if len(failures) != 1 || failures[0].ID() != "tests/test_api.py.test_login" || failures[0].Message != "AssertionError" {
+		t.Errorf("failures = %+v", failures)
+	}
+
+	if _, err := ParseRegex(output, `FAILED (\S+)`); err == nil {
+		t.Error("expected error for pattern without test group")
+	}
+	if _, err := Parse(output, "tap", ""); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestClassify(t *testing.T) {
+	a := Failure{Package: "p", Test: "TestA"}
+	b := Failure{Package: "p", Test: "TestB"}
+
+	tests := []struct {
+		name      string
+		attempts  []Attempt
+		wantClass string
+		wantReal  []string
+		wantFlaky []string
+	}{
+		{
+			name:      "clean pass",
+			attempts:  []Attempt{{Passed: true}},
+			wantClass: ClassNone,
+		},
+		{
+			name:      "flake passes on retry",
+			attempts:  []Attempt{{Failures: []Failure{a}}, {Passed: true}},
+			wantClass: ClassFlaky,
+			wantFlaky: []string{"p.TestA"},
+		},
+		{
+			name:      "real failure plus flake",
+			attempts:  []Attempt{{Failures: []Failure{a, b}}, {Failures: []Failure{a}}},
+			wantClass: ClassReal,
+			wantReal:  []string{"p.TestA"},
+			wantFlaky: []string{"p.TestB"},
+		},
+		{
+			name:      "unparsed failure is real",
+			attempts:  []Attempt{{}, {}},
+			wantClass: ClassReal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Classify(tt.attempts)
+			if r.Class != tt.wantClass {
+				t.Errorf("Class = %q, want %q", r.Class, tt.wantClass)
+			}
+			if got := IDs(r.Real); !equalStrings(got, tt.wantReal) {
+				t.Errorf("Real = %v, want %v", got, tt.wantReal)
+			}
+			if got := IDs(r.Flaky); !equalStrings(got, tt.wantFlaky) {
+				t.Errorf("Flaky = %v, want %v", got, tt.wantFlaky)
+			}
+		})
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,120 @@
+// Package timefmt renders timestamps consistently across gt's output.
+//
+// Timestamps are stored in UTC. Human output shows them in local time (or
+// UTC with --utc): list views use relative ages like "3h ago", detail views
+// use Exact. JSON output keeps the stored RFC 3339 values untouched.
+package timefmt
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// EnvUTC, when set to a non-empty value, renders times in UTC (same as --utc).
+const EnvUTC = "GT_UTC"
+
+// ExactLayout is the layout for exact times in human output.
+const ExactLayout = "2006-01-02 15:04"
+
+var useUTC atomic.Bool
+
+// SetUTC selects UTC (true) or the local time zone (false) for display.
+func SetUTC(utc bool) { useUTC.Store(utc) }
+
+// Location returns the time zone human output is rendered in.
+func Location() *time.Location {
+	if useUTC.Load() {
+		return time.UTC
+	}
+	return time.Local
+}
+
+// layouts are the timestamp formats found in beads, logs, and state files,
+// most common first. Layouts without a zone are taken as UTC.
+var layouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// Parse reads a stored timestamp in any of the formats gt and bd write.
+func Parse(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized timestamp %q", s)
+}
+
+// Exact renders t in the display time zone, to the minute. The zone
+// abbreviation is appended when showing UTC so it isn't mistaken for local.
+func Exact(t time.Time) string {
+	return exactLayout(t, ExactLayout)
+}
+
+// ExactSeconds is Exact with seconds, for logs.
+func ExactSeconds(t time.Time) string {
+	return exactLayout(t, ExactLayout+":05")
+}
+
+func exactLayout(t time.Time, layout string) string {
+	if t.IsZero() {
+		return ""
+	}
+	if useUTC.Load() {
+		layout += " MST"
+	}
+	return t.In(Location()).Format(layout)
+}
+
+// Ago renders how long ago t was, e.g. "just now", "5m ago", "3h ago",
+// "2d ago". Times in the future render as "in 5m".
+func Ago(t time.Time) string {
+	return AgoFrom(t, time.Now())
+}
+
+// AgoFrom is Ago relative to now.
+func AgoFrom(t, now time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	d := now.Sub(t)
+	if d < 0 {
+		return "in " + Age(-d)
+	}
+	if d < time.Minute {
+		return "just now"
+	}
+	return Age(d) + " ago"
+}
+
+// Age renders a duration compactly at its largest unit: "45s", "5m", "3h",
+// "2d". Use it for AGE columns where "ago" is implied.
+func Age(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd", int(d.Hours()/24))
+}
+
+// AgoString parses a stored timestamp and renders it with Ago. It returns
+// "" when the timestamp can't be parsed.
+func AgoString(s string) string {
+	t, err := Parse(s)
+	if err != nil {
+		return ""
+	}
+	return Ago(t)
+}
@@ -0,0 +1,63 @@
+package timefmt
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAgoFrom(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		ago  time.Duration
+		want string
+	}{
+		{30 * time.Second, "just now"},
+		{5 * time.Minute, "5m ago"},
+		{3*time.Hour + 59*time.Minute, "3h ago"},
+		{50 * time.Hour, "2d ago"},
+		{-10 * time.Minute, "in 10m"},
+	}
+	for _, tt := range tests {
+		if got := AgoFrom(now.Add(-tt.ago), now); got != tt.want {
+			t.Errorf("AgoFrom(-%v) = %q, want %q", tt.ago, got, tt.want)
+		}
+	}
+	if got := AgoFrom(time.Time{}, now); got != "" {
+		t.Errorf("AgoFrom(zero) = %q, want empty", got)
+	}
+}
+
+func TestParse(t *testing.T) {
+	want := time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC)
+	for _, s := range []string{
+		"2026-03-01T12:30:00Z",
+		"2026-03-01T13:30:00+01:00",
+		"2026-03-01T12:30:00.000000Z",
+		"2026-03-01T12:30:00",
+		"2026-03-01 12:30:00",
+		" 2026-03-01 12:30 ",
+	} {
+		got, err := Parse(s)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("Parse(%q) = %v, %v; want %v", s, got, err, want)
+		}
+	}
+	if _, err := Parse("yesterday"); err == nil {
+		t.Error("Parse(yesterday): expected error")
+	}
+}
+
+func TestExactUTC(t *testing.T) {
+	defer SetUTC(false)
+	SetUTC(true)
+	ts := time.Date(2026, 3, 1, 13, 30, 0, 0, time.FixedZone("CET", 3600))
+	if got := Exact(ts); got != "2026-03-01 12:30 UTC" {
+		t.Errorf("Exact = %q", got)
+	}
+	if got := ExactSeconds(ts); got != "2026-03-01 12:30:00 UTC" {
+		t.Errorf("ExactSeconds = %q", got)
+	}
+	if got := Exact(time.Time{}); got != "" {
+		t.Errorf("Exact(zero) = %q", got)
+	}
+}
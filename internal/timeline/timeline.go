@@ -0,0 +1,169 @@
+// Package timeline merges everything recorded about an issue into one
+// chronological view: the issue's own history, its comments, the merge
+// requests filed for it, agent activity from the event log, and the
+// commits that reference it.
+package timeline
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+// Sources of timeline entries.
+const (
+	SourceBead    = "bead"    // the issue itself: created, closed
+	SourceComment = "comment" // comments on the issue
+	SourceMR      = "mr"      // merge requests and merge queue events
+	SourceAgent   = "agent"   // slings, hooks, and agent sessions
+	SourceEvent   = "event"   // other events that mention the issue
+	SourceGit     = "git"     // commits that reference the issue
+)
+
+// eventSources assigns event types to a source; the rest are SourceEvent.
+var eventSources = map[string]string{
+	events.TypeSling:         SourceAgent,
+	events.TypeHook:          SourceAgent,
+	events.TypeUnhook:        SourceAgent,
+	events.TypeDone:          SourceAgent,
+	events.TypeSessionDeath:  SourceAgent,
+	events.TypeMergeStarted:  SourceMR,
+	events.TypeMerged:        SourceMR,
+	events.TypeMergeFailed:   SourceMR,
+	events.TypeMergeSkipped:  SourceMR,
+	events.TypeLandingFailed: SourceMR,
+	events.TypeMRReverted:    SourceMR,
+	events.TypeMRBackported:  SourceMR,
+	events.TypePRFailed:      SourceMR,
+}
+
+// Issue is the issue the timeline is for.
+type Issue struct {
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	Status      string    `json:"status"`
+	Assignee    string    `json:"assignee,omitempty"`
+	CreatedBy   string    `json:"created_by,omitempty"`
+	CloseReason string    `json:"close_reason,omitempty"`
+	Created     time.Time `json:"created,omitempty"`
+	Closed      time.Time `json:"closed,omitempty"`
+}
+
+// Comment is a comment on the issue.
+type Comment struct {
+	At     time.Time
+	Author string
+	Text   string
+}
+
+// MergeRequest is an MR filed for the issue.
+type MergeRequest struct {
+	ID          string
+	Branch      string
+	Status      string
+	MergeCommit string
+	CloseReason string
+	Created     time.Time
+	Closed      time.Time
+}
+
+// Commit is a commit whose message references the issue.
+type Commit struct {
+	SHA     string
+	Subject string
+	Author  string
+	At      time.Time
+}
+
+// Entry is one line of the timeline. Ref is the bead ID or commit SHA the
+// entry is about, when that isn't the issue itself.
+type Entry struct {
+	At     time.Time `json:"at"`
+	Source string    `json:"source"`
+	Type   string    `json:"type"`
+	Actor  string    `json:"actor,omitempty"`
+	Text   string    `json:"text"`
+	Ref    string    `json:"ref,omitempty"`
+}
+
+// Timeline is an issue's merged history.
+type Timeline struct {
+	Issue   Issue   `json:"issue"`
+	Entries []Entry `json:"entries"`
+}
+
+// Build merges the issue's history into a timeline, oldest first. evts is
+// the town's event log; the events that mention the issue or one of its
+// MRs are kept. An MR's close is taken from its bead only when the event
+// log has no merge event for it, so a merge isn't listed twice.
+func Build(issue Issue, comments []Comment, mrs []MergeRequest, commits []Commit, evts []events.Event) *Timeline {
+	t := &Timeline{Issue: issue, Entries: []Entry{}}
+	add := func(e Entry) {
+		if !e.At.IsZero() {
+			t.Entries = append(t.Entries, e)
+		}
+	}
+
+	add(Entry{At: issue.Created, Source: SourceBead, Type: "created", Actor: issue.CreatedBy, Text: "created: " + issue.Title})
+	add(Entry{At: issue.Closed, Source: SourceBead, Type: "closed", Text: withDetail("closed", issue.CloseReason)})
+	for _, c := range comments {
+		add(Entry{At: c.At, Source: SourceComment, Type: "comment", Actor: c.Author, Text: c.Text})
+	}
+
+	ids := map[string]bool{issue.ID: true}
+	for _, mr := range mrs {
+		ids[mr.ID] = true
+	}
+	mergeLogged := make(map[string]bool)
+	for _, e := range evts {
+		if !e.Mentions(ids) {
+			continue
+		}
+		source, ok := eventSources[e.Type]
+		if !ok {
+			source = SourceEvent
+		}
+		ref := e.PayloadString("mr")
+		if e.Type == events.TypeMerged {
+			mergeLogged[ref] = true
+		}
+		add(Entry{At: e.Time(), Source: source, Type: e.Type, Actor: e.Actor, Text: events.Describe(e), Ref: ref})
+	}
+
+	for _, mr := range mrs {
+		add(Entry{At: mr.Created, Source: SourceMR, Type: "mr_filed", Text: withDetail("merge request "+mr.ID+" filed", mr.Branch), Ref: mr.ID})
+		if mr.Status != "closed" || mergeLogged[mr.ID] {
+			continue
+		}
+		text := withDetail("merge request "+mr.ID+" closed", mr.CloseReason)
+		if mr.MergeCommit != "" {
+			text = fmt.Sprintf("merged %s (%s)", mr.ID, short(mr.MergeCommit))
+		}
+		add(Entry{At: mr.Closed, Source: SourceMR, Type: "mr_closed", Text: text, Ref: mr.ID})
+	}
+
+	for _, c := range commits {
+		add(Entry{At: c.At, Source: SourceGit, Type: "commit", Actor: c.Author, Text: short(c.SHA) + " " + c.Subject, Ref: c.SHA})
+	}
+
+	sort.SliceStable(t.Entries, func(i, j int) bool { return t.Entries[i].At.Before(t.Entries[j].At) })
+	return t
+}
+
+// short abbreviates a commit SHA.
+func short(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
+
+// withDetail appends detail to text when there is one.
+func withDetail(text, detail string) string {
+	if detail == "" {
+		return text
+	}
+	return text + " (" + detail + ")"
+}
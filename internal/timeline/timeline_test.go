@@ -0,0 +1,59 @@
+package timeline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+func TestBuild(t *testing.T) {
+	t0 := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) string { return t0.Add(d).Format(time.RFC3339) }
+
+	issue := Issue{ID: "gt-a", Title: "Cookies", Status: "closed", CreatedBy: "mayor", Created: t0, Closed: t0.Add(5 * time.Hour), CloseReason: "done"}
+	comments := []Comment{{At: t0.Add(30 * time.Minute), Author: "nux", Text: "starting on the cookie jar"}}
+	mrs := []MergeRequest{
+		{ID: "gt-mr1", Branch: "polecat/nux/gt-a", Status: "closed", MergeCommit: "abcdef1234567", Created: t0.Add(2 * time.Hour), Closed: t0.Add(3 * time.Hour)},
+		{ID: "gt-mr2", Branch: "polecat/nux/gt-a-2", Status: "closed", CloseReason: "superseded", Created: t0.Add(time.Hour), Closed: t0.Add(4 * time.Hour)},
+	}
+	commits := []Commit{{SHA: "abcdef1234567", Subject: "Add cookie jar (gt-a)", Author: "nux", At: t0.Add(90 * time.Minute)}}
+	evts := []events.Event{
+		{Timestamp: at(time.Minute), Type: events.TypeSling, Actor: "mayor", Payload: map[string]interface{}{"bead": "gt-a", "target": "gastown/nux"}},
+		{Timestamp: at(150 * time.Minute), Type: events.TypeMergeFailed, Actor: "refinery", Payload: map[string]interface{}{"mr": "gt-mr1", "reason": "tests"}},
+		{Timestamp: at(3 * time.Hour), Type: events.TypeMerged, Actor: "refinery", Payload: map[string]interface{}{"mr": "gt-mr1", "branch": "polecat/nux/gt-a"}},
+		{Timestamp: at(3 * time.Hour), Type: events.TypeSling, Payload: map[string]interface{}{"bead": "gt-zzz"}}, // unrelated
+	}
+
+	got := Build(issue, comments, mrs, commits, evts).Entries
+	want := []struct{ source, typ, text string }{
+		{SourceBead, "created", "created: Cookies"},
+		{SourceAgent, events.TypeSling, "slung gt-a to gastown/nux"},
+		{SourceComment, "comment", "starting on the cookie jar"},
+		{SourceMR, "mr_filed", "merge request gt-mr2 filed (polecat/nux/gt-a-2)"},
+		{SourceGit, "commit", "abcdef12 Add cookie jar (gt-a)"},
+		{SourceMR, "mr_filed", "merge request gt-mr1 filed (polecat/nux/gt-a)"},
+		{SourceMR, events.TypeMergeFailed, "merge failed: gt-mr1 (tests)"},
+		{SourceMR, events.TypeMerged, "merged gt-mr1 (polecat/nux/gt-a)"}, // no duplicate from the MR bead
+		{SourceMR, "mr_closed", "merge request gt-mr2 closed (superseded)"},
+		{SourceBead, "closed", "closed (done)"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d entries, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		if got[i].Source != w.source || got[i].Type != w.typ || got[i].Text != w.text {
+			t.Errorf("entry %d = %s/%s %q, want %s/%s %q", i, got[i].Source, got[i].Type, got[i].Text, w.source, w.typ, w.text)
+		}
+	}
+}
+
+func TestBuild_MergedWithoutEvent(t *testing.T) {
+	t0 := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	mrs := []MergeRequest{{ID: "gt-mr1", Status: "closed", MergeCommit: "abcdef1234567", Created: t0, Closed: t0.Add(time.Hour)}}
+
+	got := Build(Issue{ID: "gt-a"}, nil, mrs, nil, nil).Entries
+	if len(got) != 2 || got[1].Text != "merged gt-mr1 (abcdef12)" || got[1].Ref != "gt-mr1" {
+		t.Errorf("Entries = %+v", got)
+	}
+}
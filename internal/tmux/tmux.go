@@ -16,6 +16,7 @@ import (
 
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/sandbox"
 )
 
 // sessionNudgeLocks serializes nudges to the same session.
@@ -55,6 +56,10 @@ func NewTmux() *Tmux {
 func (t *Tmux) run(args ...string) (string, error) {
 	// Prepend -u flag for UTF-8 mode (PATCH-004)
 	allArgs := append([]string{"-u"}, args...)
+	// Sandboxed agents run on their own tmux server.
+	if sandbox.Enabled() {
+		allArgs = append([]string{"-L", sandbox.TmuxSocket}, allArgs...)
+	}
 	cmd := exec.Command("tmux", allArgs...)
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
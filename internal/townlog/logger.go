@@ -102,7 +102,7 @@ func (l *Logger) LogEvent(event Event) error {
 // Log is a convenience method that creates an Event and logs it.
 func (l *Logger) Log(eventType EventType, agent, context string) error {
 	return l.LogEvent(Event{
-		Timestamp: time.Now(),
+		Timestamp: time.Now().UTC(),
 		Type:      eventType,
 		Agent:     agent,
 		Context:   context,
@@ -111,8 +111,9 @@ func (l *Logger) Log(eventType EventType, agent, context string) error {
 
 // formatLogLine formats an event as a human-readable log line.
 // Format: 2025-12-26 15:30:45 [spawn] gastown/crew/max spawned for gt-xyz
+// Timestamps are written in UTC, which is how parseLogLine reads them back.
 func formatLogLine(e Event) string {
-	ts := e.Timestamp.Format("2006-01-02 15:04:05")
+	ts := e.Timestamp.UTC().Format("2006-01-02 15:04:05")
 
 	var detail string
 	switch e.Type {
@@ -0,0 +1,116 @@
+package townsync
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// MergeLines merges an append-only log both sides added to: local's lines,
+// then the lines remote added since base. Lines are compared whole, so a
+// line both sides added is kept once.
+func MergeLines(base, local, remote []byte) []byte {
+	seen := make(map[string]bool)
+	for _, line := range splitLines(base) {
+		seen[line] = true
+	}
+	var out bytes.Buffer
+	for _, line := range splitLines(local) {
+		seen[line] = true
+		out.WriteString(line)
+		out.WriteByte('\n')
+	}
+	for _, line := range splitLines(remote) {
+		if !seen[line] {
+			seen[line] = true
+			out.WriteString(line)
+			out.WriteByte('\n')
+		}
+	}
+	return out.Bytes()
+}
+
+// MergeRecords merges JSONL files keyed by each record's "id" (beads
+// issues). A record changed (or added, or removed) on one side takes that
+// side's version. A record both sides changed differently is a conflict:
+// prefer picks the winner, or with PreferNone local's version is kept and
+// the record's ID is returned among the conflicts. Lines without an ID are
+// merged like MergeLines.
+func MergeRecords(base, local, remote []byte, prefer Side) ([]byte, []string) {
+	b, l, r := parseRecords(base), parseRecords(local), parseRecords(remote)
+
+	var conflicts []string
+	var out bytes.Buffer
+	write := func(line string) {
+		out.WriteString(line)
+		out.WriteByte('\n')
+	}
+	resolve := func(key string) (string, bool) {
+		bl, bok := b.lines[key]
+		ll, lok := l.lines[key]
+		rl, rok := r.lines[key]
+		switch {
+		case lok == rok && ll == rl, lok == bok && ll == bl:
+			return rl, rok
+		case rok == bok && rl == bl:
+			return ll, lok
+		}
+		if l.ids[key] || r.ids[key] {
+			conflicts = append(conflicts, key)
+		}
+		if prefer == PreferRemote {
+			return rl, rok
+		}
+		return ll, lok
+	}
+
+	done := make(map[string]bool)
+	for _, order := range [][]string{l.order, r.order, b.order} {
+		for _, key := range order {
+			if done[key] {
+				continue
+			}
+			done[key] = true
+			if line, ok := resolve(key); ok {
+				write(line)
+			}
+		}
+	}
+	return out.Bytes(), conflicts
+}
+
+// records is a parsed JSONL file. Lines with an "id" are keyed by it;
+// others by their text.
+type records struct {
+	order []string
+	lines map[string]string
+	ids   map[string]bool
+}
+
+func parseRecords(data []byte) records {
+	rs := records{lines: make(map[string]string), ids: make(map[string]bool)}
+	for _, line := range splitLines(data) {
+		key := line
+		var rec struct {
+			ID string `json:"id"`
+		}
+		if json.Unmarshal([]byte(line), &rec) == nil && rec.ID != "" {
+			key = rec.ID
+			rs.ids[key] = true
+		}
+		if _, dup := rs.lines[key]; !dup {
+			rs.order = append(rs.order, key)
+		}
+		rs.lines[key] = line
+	}
+	return rs
+}
+
+func splitLines(data []byte) []string {
+	var lines []string
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) > 0 {
+			lines = append(lines, string(line))
+		}
+	}
+	return lines
+}
@@ -0,0 +1,139 @@
+package townsync
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// SSH is a town on another machine, reached by running 'gt sync serve'
+// there over ssh. The remote machine needs gt on its PATH.
+type SSH struct {
+	// Host is the ssh destination, e.g. "user@office".
+	Host string
+	// Town is the town root on the remote machine.
+	Town string
+	// Command is the ssh client and its options. Default: ["ssh"].
+	Command []string
+	// GT is the gt binary on the remote machine. Default: "gt".
+	GT string
+}
+
+// ParsePeer parses "host" or "host:/path/to/town". Without a path, the
+// remote town is assumed to be at defaultTown.
+func ParsePeer(spec, defaultTown string) (*SSH, error) {
+	host, town, ok := strings.Cut(spec, ":")
+	if host == "" {
+		return nil, fmt.Errorf("invalid peer %q (want host or host:/path/to/town)", spec)
+	}
+	if !ok || town == "" {
+		town = defaultTown
+	}
+	return &SSH{Host: host, Town: town}, nil
+}
+
+// serveRequest and serveResponse are the 'gt sync serve' wire format, one
+// JSON document each way.
+type serveRequest struct {
+	Op     string   `json:"op"` // "files" or "apply"
+	Write  Files    `json:"write,omitempty"`
+	Remove []string `json:"remove,omitempty"`
+}
+
+type serveResponse struct {
+	Files Files  `json:"files,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// Files fetches the remote town's synced files.
+func (s *SSH) Files(ctx context.Context) (Files, error) {
+	resp, err := s.call(ctx, serveRequest{Op: "files"})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Files == nil {
+		resp.Files = make(Files)
+	}
+	return resp.Files, nil
+}
+
+// Apply writes and removes files in the remote town.
+func (s *SSH) Apply(ctx context.Context, write Files, remove []string) error {
+	_, err := s.call(ctx, serveRequest{Op: "apply", Write: write, Remove: remove})
+	return err
+}
+
+func (s *SSH) String() string {
+	return s.Host + ":" + s.Town
+}
+
+// call runs one request through 'gt sync serve' on the remote machine.
+func (s *SSH) call(ctx context.Context, req serveRequest) (*serveResponse, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	ssh := s.Command
+	if len(ssh) == 0 {
+		ssh = []string{"ssh"}
+	}
+	gt := s.GT
+	if gt == "" {
+		gt = "gt"
+	}
+	// ssh hands the remote command to a shell, so quote the town path.
+	remote := fmt.Sprintf("%s sync serve --town %s", gt, config.ShellQuote(s.Town))
+	args := append(append([]string{}, ssh[1:]...), s.Host, remote)
+
+	cmd := exec.CommandContext(ctx, ssh[0], args...) //nolint:gosec // G204: the user names the ssh command and host
+	cmd.Stdin = bytes.NewReader(body)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	runErr := cmd.Run()
+
+	var resp serveResponse
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if runErr != nil && msg != "" {
+			return nil, fmt.Errorf("%s: %s", s.Host, msg)
+		}
+		if runErr != nil {
+			return nil, fmt.Errorf("%s: %w", s.Host, runErr)
+		}
+		return nil, fmt.Errorf("%s: malformed response from gt sync serve: %w", s.Host, err)
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("%s: %s", s.Host, resp.Error)
+	}
+	return &resp, nil
+}
+
+// Serve answers one request from a peer on r, writing the response to w.
+// It is the remote half of SSH. Errors are reported in the response.
+func Serve(ctx context.Context, town Peer, r io.Reader, w io.Writer) error {
+	var req serveRequest
+	var resp serveResponse
+	if err := json.NewDecoder(r).Decode(&req); err != nil {
+		resp.Error = "decoding request: " + err.Error()
+	} else {
+		switch req.Op {
+		case "files":
+			resp.Files, err = town.Files(ctx)
+		case "apply":
+			err = town.Apply(ctx, req.Write, req.Remove)
+		default:
+			err = fmt.Errorf("unknown op %q", req.Op)
+		}
+		if err != nil {
+			resp.Error = err.Error()
+		}
+	}
+	return json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,111 @@
+package townsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// unsafeName matches characters not allowed in a peer's base directory.
+var unsafeName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// BaseDir is where the merge base for syncs with peer is kept.
+func BaseDir(townRoot, peer string) string {
+	return filepath.Join(townRoot, ".runtime", "sync", unsafeName.ReplaceAllString(peer, "_"))
+}
+
+// LoadBase reads the merge base in dir. A missing dir is an empty base:
+// the first sync with a peer.
+func LoadBase(dir string) (Files, error) {
+	files := make(Files)
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(p) //nolint:gosec // G304: p is under the base dir
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = data
+		return nil
+	})
+	return files, err
+}
+
+// SaveBase replaces the merge base in dir with files. The new base is
+// written beside the old one and swapped in, so an interrupted save leaves
+// the old base intact.
+func SaveBase(dir string, files Files) error {
+	tmp := dir + ".new"
+	if err := os.RemoveAll(tmp); err != nil {
+		return err
+	}
+	for name, data := range files {
+		p := filepath.Join(tmp, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(p, data, 0644); err != nil { //nolint:gosec // G306: mirrors town files
+			return err
+		}
+	}
+	if err := os.MkdirAll(tmp, 0755); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	return os.Rename(tmp, dir)
+}
+
+// Options configures a Sync.
+type Options struct {
+	// BaseDir holds the merge base for this pair of towns (see BaseDir).
+	BaseDir string
+	Prefer  Side
+	DryRun  bool
+}
+
+// Sync plans a sync between local and remote and, unless it's a dry run,
+// applies it and records the new merge base. Conflicting files are left
+// alone on both sides and reported in the plan; everything else is synced.
+func Sync(ctx context.Context, local, remote Peer, opts Options) (*Plan, error) {
+	base, err := LoadBase(opts.BaseDir)
+	if err != nil {
+		return nil, fmt.Errorf("reading sync base: %w", err)
+	}
+	localFiles, err := local.Files(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", local, err)
+	}
+	remoteFiles, err := remote.Files(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", remote, err)
+	}
+
+	plan := Compute(base, localFiles, remoteFiles, opts.Prefer)
+	if opts.DryRun {
+		return plan, nil
+	}
+	if err := Apply(ctx, local, remote, plan); err != nil {
+		return plan, err
+	}
+	if err := SaveBase(opts.BaseDir, plan.base); err != nil {
+		return plan, fmt.Errorf("recording sync base: %w", err)
+	}
+	return plan, nil
+}
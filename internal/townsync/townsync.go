@@ -0,0 +1,361 @@
+// Package townsync replicates town-level state between two machines: the
+// town and rig registry, town configuration, town beads, and the event
+// log. Rig clones aren't synced; they have git.
+//
+// Sync is a three-way merge per file against the merge base recorded at
+// the last successful sync with the same peer. A file changed on one side
+// is copied to the other. A file changed on both sides is merged when its
+// kind allows (the event log is append-only; beads merge issue by issue)
+// and is otherwise a conflict, left alone on both sides until resolved
+// with a preferred side.
+package townsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// Kind is how a file merges when both sides changed it.
+type Kind int
+
+const (
+	// Whole files can't be merged; divergent changes conflict.
+	Whole Kind = iota
+	// Lines files are append-only logs; both sides' new lines are kept.
+	Lines
+	// Records files are JSONL keyed by "id"; records merge one by one.
+	Records
+)
+
+// Spec selects town-relative files to sync.
+type Spec struct {
+	Glob string
+	Kind Kind
+}
+
+// Specs are the files a sync covers.
+var Specs = []Spec{
+	{"mayor/*.json", Whole},
+	{"settings/*.json", Whole},
+	{".beads/config.yaml", Whole},
+	{".beads/routes.jsonl", Whole},
+	{".beads/issues.jsonl", Records},
+	{".events.jsonl", Lines},
+}
+
+// issuesFile is the town beads JSONL export.
+const issuesFile = ".beads/issues.jsonl"
+
+// KindOf returns how a synced file merges.
+func KindOf(name string) Kind {
+	for _, s := range Specs {
+		if ok, _ := path.Match(s.Glob, name); ok {
+			return s.Kind
+		}
+	}
+	return Whole
+}
+
+// Files maps town-relative slash paths to contents.
+type Files map[string][]byte
+
+// Peer is one side of a sync.
+type Peer interface {
+	// Files returns the contents of every synced file the peer has.
+	Files(ctx context.Context) (Files, error)
+	// Apply writes and removes synced files.
+	Apply(ctx context.Context, write Files, remove []string) error
+	// String names the peer for messages.
+	String() string
+}
+
+// Dir is a town on the local filesystem.
+type Dir struct {
+	Root string
+	// Beads flushes the town beads database to issues.jsonl before reading
+	// and imports it after writing. Off in tests, where bd isn't available.
+	Beads bool
+}
+
+// Files reads the synced files under the town root.
+func (d *Dir) Files(ctx context.Context) (Files, error) {
+	if d.Beads {
+		_ = beads.New(d.Root).FlushJSONL() // best effort: an unflushed export is just older
+	}
+	return ReadFiles(d.Root)
+}
+
+// ReadFiles reads the synced files under root.
+func ReadFiles(root string) (Files, error) {
+	files := make(Files)
+	for _, s := range Specs {
+		matches, err := filepath.Glob(filepath.Join(root, filepath.FromSlash(s.Glob)))
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range matches {
+			info, err := os.Stat(m)
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			data, err := os.ReadFile(m) //nolint:gosec // G304: m matches a sync spec under root
+			if err != nil {
+				return nil, err
+			}
+			rel, err := filepath.Rel(root, m)
+			if err != nil {
+				return nil, err
+			}
+			files[filepath.ToSlash(rel)] = data
+		}
+	}
+	return files, nil
+}
+
+// Apply writes files atomically and removes others. Only paths matching
+// a sync spec are accepted, so a peer can't write elsewhere in the town.
+func (d *Dir) Apply(ctx context.Context, write Files, remove []string) error {
+	for name, data := range write {
+		p, err := d.path(name)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			return err
+		}
+		if err := util.AtomicWriteFile(p, data, 0644); err != nil {
+			return fmt.Errorf("writing %s: %w", name, err)
+		}
+	}
+	for _, name := range remove {
+		p, err := d.path(name)
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	if _, ok := write[issuesFile]; ok && d.Beads {
+		if err := beads.New(d.Root).ImportJSONL(); err != nil {
+			return fmt.Errorf("importing synced beads: %w", err)
+		}
+	}
+	return nil
+}
+
+func (d *Dir) path(name string) (string, error) {
+	if !synced(name) {
+		return "", fmt.Errorf("%q is not a synced town file", name)
+	}
+	return filepath.Join(d.Root, filepath.FromSlash(name)), nil
+}
+
+// synced reports whether name is a clean relative path matching a spec.
+func synced(name string) bool {
+	if path.Clean(name) != name || strings.HasPrefix(name, "../") || path.IsAbs(name) {
+		return false
+	}
+	for _, s := range Specs {
+		if ok, _ := path.Match(s.Glob, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Dir) String() string {
+	return d.Root
+}
+
+// Action is what a sync does with one file.
+type Action string
+
+const (
+	Pull     Action = "pull"     // copy the peer's version here
+	Push     Action = "push"     // copy our version to the peer
+	Merge    Action = "merge"    // write a merge of both to both sides
+	Conflict Action = "conflict" // both changed; nothing written
+)
+
+// Change is a planned action on one file.
+type Change struct {
+	Path   string `json:"path"`
+	Action Action `json:"action"`
+	// Deleted is set when a pull or push removes the file.
+	Deleted bool `json:"deleted,omitempty"`
+	// Detail explains merges and conflicts.
+	Detail string `json:"detail,omitempty"`
+}
+
+// Plan is the outcome of comparing both sides with their merge base.
+type Plan struct {
+	Changes []Change `json:"changes"`
+
+	localWrite, remoteWrite   Files
+	localRemove, remoteRemove []string
+	// base is the merge base to record once the plan is applied; files in
+	// conflict keep their old base.
+	base Files
+}
+
+// Conflicts returns the plan's conflicting changes.
+func (p *Plan) Conflicts() []Change {
+	var out []Change
+	for _, c := range p.Changes {
+		if c.Action == Conflict {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// Side names the side that wins conflicts: "local", "remote", or "" to
+// leave conflicts unresolved.
+type Side string
+
+const (
+	PreferNone   Side = ""
+	PreferLocal  Side = "local"
+	PreferRemote Side = "remote"
+)
+
+// Compute plans a sync from the merge base and both sides' files.
+func Compute(base, local, remote Files, prefer Side) *Plan {
+	p := &Plan{
+		localWrite:  make(Files),
+		remoteWrite: make(Files),
+		base:        make(Files),
+	}
+	names := make(map[string]bool)
+	for _, files := range []Files{base, local, remote} {
+		for name := range files {
+			names[name] = true
+		}
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	for _, name := range sorted {
+		b, bok := base[name]
+		l, lok := local[name]
+		r, rok := remote[name]
+		switch {
+		case same(l, lok, r, rok):
+			p.keep(name, l, lok)
+		case same(l, lok, b, bok):
+			p.pull(name, r, rok)
+		case same(r, rok, b, bok):
+			p.push(name, l, lok)
+		default:
+			p.diverged(name, b, l, r, bok, lok, rok, prefer)
+		}
+	}
+	return p
+}
+
+func same(a []byte, aok bool, b []byte, bok bool) bool {
+	return aok == bok && string(a) == string(b)
+}
+
+func (p *Plan) keep(name string, data []byte, ok bool) {
+	if ok {
+		p.base[name] = data
+	}
+}
+
+func (p *Plan) pull(name string, data []byte, ok bool) {
+	if ok {
+		p.localWrite[name] = data
+	} else {
+		p.localRemove = append(p.localRemove, name)
+	}
+	p.keep(name, data, ok)
+	p.Changes = append(p.Changes, Change{Path: name, Action: Pull, Deleted: !ok})
+}
+
+func (p *Plan) push(name string, data []byte, ok bool) {
+	if ok {
+		p.remoteWrite[name] = data
+	} else {
+		p.remoteRemove = append(p.remoteRemove, name)
+	}
+	p.keep(name, data, ok)
+	p.Changes = append(p.Changes, Change{Path: name, Action: Push, Deleted: !ok})
+}
+
+// diverged handles a file both sides changed.
+func (p *Plan) diverged(name string, b, l, r []byte, bok, lok, rok bool, prefer Side) {
+	if lok && rok {
+		var merged []byte
+		var detail string
+		switch KindOf(name) {
+		case Lines:
+			merged = MergeLines(b, l, r)
+			detail = "kept new lines from both sides"
+		case Records:
+			var conflicts []string
+			merged, conflicts = MergeRecords(b, l, r, prefer)
+			if len(conflicts) > 0 && prefer == PreferNone {
+				p.Changes = append(p.Changes, Change{Path: name, Action: Conflict,
+					Detail: "changed on both sides: " + strings.Join(conflicts, ", ")})
+				p.keep(name, b, bok)
+				return
+			}
+			detail = "merged record by record"
+			if len(conflicts) > 0 {
+				detail += fmt.Sprintf("; %s side won for %s", prefer, strings.Join(conflicts, ", "))
+			}
+		}
+		if merged != nil {
+			p.localWrite[name] = merged
+			p.remoteWrite[name] = merged
+			p.base[name] = merged
+			p.Changes = append(p.Changes, Change{Path: name, Action: Merge, Detail: detail})
+			return
+		}
+	}
+
+	switch prefer {
+	case PreferLocal:
+		p.push(name, l, lok)
+	case PreferRemote:
+		p.pull(name, r, rok)
+	default:
+		detail := "changed on both sides"
+		if !lok || !rok {
+			detail = "changed on one side, deleted on the other"
+		}
+		p.Changes = append(p.Changes, Change{Path: name, Action: Conflict, Detail: detail})
+		p.keep(name, b, bok)
+	}
+}
+
+// Apply carries out a plan on both sides. The remote is written first, so
+// a failure there leaves this machine untouched.
+func Apply(ctx context.Context, local, remote Peer, p *Plan) error {
+	if len(p.remoteWrite) > 0 || len(p.remoteRemove) > 0 {
+		if err := remote.Apply(ctx, p.remoteWrite, p.remoteRemove); err != nil {
+			return fmt.Errorf("updating %s: %w", remote, err)
+		}
+	}
+	if len(p.localWrite) > 0 || len(p.localRemove) > 0 {
+		if err := local.Apply(ctx, p.localWrite, p.localRemove); err != nil {
+			return fmt.Errorf("updating %s: %w", local, err)
+		}
+	}
+	return nil
+}
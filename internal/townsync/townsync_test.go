@@ -0,0 +1,327 @@
+package townsync
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompute(t *testing.T) {
+	base := Files{
+		"settings/config.json": []byte("v1"),
+		"mayor/rigs.json":      []byte("rigs"),
+		"mayor/town.json":      []byte("town"),
+		".events.jsonl":        []byte("a\n"),
+	}
+	tests := []struct {
+		name          string
+		local, remote Files
+		prefer        Side
+		want          map[string]Action // path -> action; absent means unchanged
+	}{
+		{
+			name:   "in sync",
+			local:  base,
+			remote: base,
+			want:   map[string]Action{},
+		},
+		{
+			name:   "changed on one side",
+			local:  with(base, "settings/config.json", "v2"),
+			remote: with(base, "mayor/rigs.json", "rigs2"),
+			want:   map[string]Action{"settings/config.json": Push, "mayor/rigs.json": Pull},
+		},
+		{
+			name:   "same change on both sides",
+			local:  with(base, "settings/config.json", "v2"),
+			remote: with(base, "settings/config.json", "v2"),
+			want:   map[string]Action{},
+		},
+		{
+			name:   "conflict",
+			local:  with(base, "settings/config.json", "v2"),
+			remote: with(base, "settings/config.json", "v3"),
+			want:   map[string]Action{"settings/config.json": Conflict},
+		},
+		{
+			name:   "conflict settled by preference",
+			local:  with(base, "settings/config.json", "v2"),
+			remote: with(base, "settings/config.json", "v3"),
+			prefer: PreferRemote,
+			want:   map[string]Action{"settings/config.json": Pull},
+		},
+		{
+			name:   "deleted on one side",
+			local:  without(base, "mayor/rigs.json"),
+			remote: base,
+			want:   map[string]Action{"mayor/rigs.json": Push},
+		},
+		{
+			name:   "deleted on one side, changed on the other",
+			local:  without(base, "mayor/rigs.json"),
+			remote: with(base, "mayor/rigs.json", "rigs2"),
+			want:   map[string]Action{"mayor/rigs.json": Conflict},
+		},
+		{
+			name:   "event log appended on both sides",
+			local:  with(base, ".events.jsonl", "a\nb\n"),
+			remote: with(base, ".events.jsonl", "a\nc\n"),
+			want:   map[string]Action{".events.jsonl": Merge},
+		},
+		{
+			name:   "new on both sides without a base",
+			local:  with(base, "settings/agents.json", "x"),
+			remote: with(base, "settings/agents.json", "y"),
+			want:   map[string]Action{"settings/agents.json": Conflict},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := Compute(base, tt.local, tt.remote, tt.prefer)
+			got := make(map[string]Action)
+			for _, c := range plan.Changes {
+				got[c.Path] = c.Action
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("changes = %+v, want %v", plan.Changes, tt.want)
+			}
+			for name, action := range tt.want {
+				if got[name] != action {
+					t.Errorf("%s: %s, want %s", name, got[name], action)
+				}
+			}
+		})
+	}
+}
+
+func with(files Files, name, content string) Files {
+	out := make(Files, len(files)+1)
+	for k, v := range files {
+		out[k] = v
+	}
+	out[name] = []byte(content)
+	return out
+}
+
+func without(files Files, name string) Files {
+	out := make(Files, len(files))
+	for k, v := range files {
+		if k != name {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func TestMergeLines(t *testing.T) {
+	got := MergeLines([]byte("a\nb\n"), []byte("a\nb\nc\nd\n"), []byte("a\nb\ne\nd\n"))
+	if want := "a\nb\nc\nd\ne\n"; string(got) != want {
+		t.Errorf("MergeLines = %q, want %q", got, want)
+	}
+}
+
+func TestMergeRecords(t *testing.T) {
+	base := []byte(`{"id":"hq-1","title":"one"}
+{"id":"hq-2","title":"two"}
+{"id":"hq-3","title":"three"}
+`)
+	local := []byte(`{"id":"hq-1","title":"one, edited here"}
+{"id":"hq-2","title":"two"}
+{"id":"hq-3","title":"three, here"}
+{"id":"hq-4","title":"new here"}
+`)
+	remote := []byte(`{"id":"hq-1","title":"one"}
+{"id":"hq-3","title":"three, there"}
+{"id":"hq-5","title":"new there"}
+`)
+
+	tests := []struct {
+		prefer    Side
+		wantThree string
+	}{
+		{PreferNone, "three, here"},
+		{PreferLocal, "three, here"},
+		{PreferRemote, "three, there"},
+	}
+	for _, tt := range tests {
+		merged, conflicts := MergeRecords(base, local, remote, tt.prefer)
+		if strings.Join(conflicts, ",") != "hq-3" {
+			t.Errorf("prefer %q: conflicts = %v, want [hq-3]", tt.prefer, conflicts)
+		}
+		s := string(merged)
+		for _, want := range []string{"one, edited here", tt.wantThree, "new here", "new there"} {
+			if !strings.Contains(s, want) {
+				t.Errorf("prefer %q: merged is missing %q:\n%s", tt.prefer, want, s)
+			}
+		}
+		if strings.Contains(s, `"two"`) {
+			t.Errorf("prefer %q: hq-2 was deleted remotely but kept:\n%s", tt.prefer, s)
+		}
+	}
+}
+
+func writeTown(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func readTown(t *testing.T, root, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(name)))
+	if err != nil {
+		return "<missing>"
+	}
+	return string(data)
+}
+
+func TestSync(t *testing.T) {
+	home, office := t.TempDir(), t.TempDir()
+	writeTown(t, home, map[string]string{
+		"mayor/town.json":      `{"name":"gt"}`,
+		"mayor/rigs.json":      `{"rigs":{}}`,
+		"settings/config.json": `{}`,
+		".events.jsonl":        "e1\n",
+		"gastown/config.json":  "rig config is not synced",
+	})
+	local, remote := &Dir{Root: home}, &Dir{Root: office}
+	ctx := context.Background()
+	opts := Options{BaseDir: BaseDir(home, "office:/srv/gt")}
+
+	// First sync copies everything to the empty peer.
+	plan, err := Sync(ctx, local, remote, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Changes) != 4 || len(plan.Conflicts()) != 0 {
+		t.Fatalf("first sync changes = %+v", plan.Changes)
+	}
+	if got := readTown(t, office, "mayor/rigs.json"); got != `{"rigs":{}}` {
+		t.Errorf("office rigs.json = %s", got)
+	}
+	if got := readTown(t, office, "gastown/config.json"); got != "<missing>" {
+		t.Errorf("unsynced file was copied: %s", got)
+	}
+
+	// Both sides move on: a one-sided change, appends, and a conflict.
+	writeTown(t, office, map[string]string{"mayor/rigs.json": `{"rigs":{"gastown":{}}}`, ".events.jsonl": "e1\ne3\n", "settings/config.json": `{"theme":"dark"}`})
+	writeTown(t, home, map[string]string{".events.jsonl": "e1\ne2\n", "settings/config.json": `{"theme":"light"}`})
+
+	dry, err := Sync(ctx, local, remote, Options{BaseDir: opts.BaseDir, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := readTown(t, home, "mayor/rigs.json"); got != `{"rigs":{}}` {
+		t.Errorf("dry run changed home: %s", got)
+	}
+
+	plan, err = Sync(ctx, local, remote, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Changes) != len(dry.Changes) {
+		t.Errorf("dry run planned %d changes, sync made %d", len(dry.Changes), len(plan.Changes))
+	}
+	if c := plan.Conflicts(); len(c) != 1 || c[0].Path != "settings/config.json" {
+		t.Errorf("conflicts = %+v, want settings/config.json", c)
+	}
+	if got := readTown(t, home, "mayor/rigs.json"); got != `{"rigs":{"gastown":{}}}` {
+		t.Errorf("home rigs.json = %s", got)
+	}
+	for _, root := range []string{home, office} {
+		if got := readTown(t, root, ".events.jsonl"); got != "e1\ne2\ne3\n" {
+			t.Errorf("%s event log = %q", root, got)
+		}
+	}
+	if readTown(t, home, "settings/config.json") != `{"theme":"light"}` || readTown(t, office, "settings/config.json") != `{"theme":"dark"}` {
+		t.Error("conflicting file was changed")
+	}
+
+	// The conflict persists until settled, then the towns agree.
+	opts.Prefer = PreferLocal
+	if _, err := Sync(ctx, local, remote, opts); err != nil {
+		t.Fatal(err)
+	}
+	if got := readTown(t, office, "settings/config.json"); got != `{"theme":"light"}` {
+		t.Errorf("office config after --prefer local = %s", got)
+	}
+	plan, err = Sync(ctx, local, remote, Options{BaseDir: opts.BaseDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Changes) != 0 {
+		t.Errorf("towns still differ: %+v", plan.Changes)
+	}
+}
+
+func TestDirApply_RejectsUnsyncedPaths(t *testing.T) {
+	d := &Dir{Root: t.TempDir()}
+	for _, name := range []string{"../evil.json", "/etc/passwd", "gastown/config.json", "mayor/../settings/x.json"} {
+		if err := d.Apply(context.Background(), Files{name: []byte("x")}, nil); err == nil {
+			t.Errorf("Apply accepted %q", name)
+		}
+	}
+}
+
+func TestServe(t *testing.T) {
+	root := t.TempDir()
+	writeTown(t, root, map[string]string{"mayor/town.json": "town"})
+	d := &Dir{Root: root}
+
+	var out bytes.Buffer
+	if err := Serve(context.Background(), d, strings.NewReader(`{"op":"apply","write":{"settings/config.json":"e30="}}`), &out); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "error") {
+		t.Fatalf("apply response = %s", out.String())
+	}
+	if got := readTown(t, root, "settings/config.json"); got != "{}" {
+		t.Errorf("applied file = %q", got)
+	}
+
+	out.Reset()
+	if err := Serve(context.Background(), d, strings.NewReader(`{"op":"files"}`), &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), `"mayor/town.json":"dG93bg=="`) {
+		t.Errorf("files response = %s", out.String())
+	}
+
+	out.Reset()
+	_ = Serve(context.Background(), d, strings.NewReader(`{"op":"apply","write":{"../x":""}}`), &out)
+	if !strings.Contains(out.String(), `"error"`) {
+		t.Errorf("escaping write not reported: %s", out.String())
+	}
+}
+
+func TestParsePeer(t *testing.T) {
+	tests := []struct {
+		spec, host, town string
+	}{
+		{"office", "office", "/home/me/gt"},
+		{"me@office:/srv/gt", "me@office", "/srv/gt"},
+		{"office:", "office", "/home/me/gt"},
+	}
+	for _, tt := range tests {
+		p, err := ParsePeer(tt.spec, "/home/me/gt")
+		if err != nil {
+			t.Fatalf("ParsePeer(%q): %v", tt.spec, err)
+		}
+		if p.Host != tt.host || p.Town != tt.town {
+			t.Errorf("ParsePeer(%q) = %s:%s, want %s:%s", tt.spec, p.Host, p.Town, tt.host, tt.town)
+		}
+	}
+	if _, err := ParsePeer(":/srv/gt", "/x"); err == nil {
+		t.Error("ParsePeer without a host: expected error")
+	}
+}
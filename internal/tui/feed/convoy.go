@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 )
 
 // convoyIDPattern validates convoy IDs.
@@ -337,11 +338,8 @@ func renderConvoyLine(c Convoy, landed bool) string {
 	id := ConvoyIDStyle.Render(c.ID)
 
 	// Truncate title if too long
-	title := c.Title
-	if len(title) > 20 {
-		title = title[:17] + "..."
-	}
-	title = ConvoyNameStyle.Render(title)
+	// Truncate title if too long, without splitting a wide character
+	title := ConvoyNameStyle.Render(ansi.Truncate(c.Title, 20, "..."))
 
 	if landed {
 		// Show checkmark and time since landing
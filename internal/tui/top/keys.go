@@ -0,0 +1,73 @@
+package top
+
+import "github.com/charmbracelet/bubbles/key"
+
+// KeyMap defines the key bindings for the top TUI.
+type KeyMap struct {
+	Up      key.Binding
+	Down    key.Binding
+	Sort    key.Binding
+	Reverse key.Binding
+	Kill    key.Binding
+	Nudge   key.Binding
+	Refresh key.Binding
+	Help    key.Binding
+	Quit    key.Binding
+}
+
+// DefaultKeyMap returns the default key bindings.
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Up: key.NewBinding(
+			key.WithKeys("up", "k"),
+			key.WithHelp("↑/k", "up"),
+		),
+		Down: key.NewBinding(
+			key.WithKeys("down", "j"),
+			key.WithHelp("↓/j", "down"),
+		),
+		Sort: key.NewBinding(
+			key.WithKeys("s", "tab"),
+			key.WithHelp("s", "next sort column"),
+		),
+		Reverse: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "reverse sort"),
+		),
+		Kill: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "kill session"),
+		),
+		Nudge: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n", "nudge agent"),
+		),
+		Refresh: key.NewBinding(
+			key.WithKeys("ctrl+r", "R"),
+			key.WithHelp("R", "refresh now"),
+		),
+		Help: key.NewBinding(
+			key.WithKeys("?"),
+			key.WithHelp("?", "help"),
+		),
+		Quit: key.NewBinding(
+			key.WithKeys("q", "esc", "ctrl+c"),
+			key.WithHelp("q", "quit"),
+		),
+	}
+}
+
+// ShortHelp returns keybindings to show in the help view.
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Up, k.Down, k.Sort, k.Kill, k.Nudge, k.Quit, k.Help}
+}
+
+// FullHelp returns keybindings for the expanded help view.
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Up, k.Down},
+		{k.Sort, k.Reverse, k.Refresh},
+		{k.Kill, k.Nudge},
+		{k.Help, k.Quit},
+	}
+}
@@ -0,0 +1,374 @@
+// Package top implements gt top, a live view of running agents that pairs
+// each session's process resource use with its Gas Town identity.
+package top
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Agent is one running agent session.
+type Agent struct {
+	Session  string    `json:"session"`
+	Address  string    `json:"address"`
+	Role     string    `json:"role"`
+	Rig      string    `json:"rig,omitempty"`
+	CPU      float64   `json:"cpu_percent"`
+	RSS      int64     `json:"rss_bytes"`
+	Procs    int       `json:"procs"`
+	Tokens   int       `json:"tokens"`
+	Rate     float64   `json:"tokens_per_min"` // since the previous sample
+	Cost     float64   `json:"cost_usd"`
+	Issue    string    `json:"issue,omitempty"`
+	Title    string    `json:"issue_title,omitempty"`
+	Activity time.Time `json:"last_activity,omitempty"`
+}
+
+// Sort columns, in the order the sort key cycles through them.
+const (
+	SortCPU      = "cpu"
+	SortMemory   = "mem"
+	SortTokens   = "tokens"
+	SortCost     = "cost"
+	SortActivity = "activity"
+	SortName     = "name"
+)
+
+// SortColumns lists sort columns in cycle order.
+var SortColumns = []string{SortCPU, SortMemory, SortTokens, SortCost, SortActivity, SortName}
+
+// Sort orders agents by column, largest (or most recent) first; names sort
+// alphabetically. Ties fall back to the session name.
+func Sort(agents []Agent, column string, reverse bool) {
+	less := func(a, b Agent) bool {
+		switch column {
+		case SortMemory:
+			if a.RSS != b.RSS {
+				return a.RSS > b.RSS
+			}
+		case SortTokens:
+			if a.Tokens != b.Tokens {
+				return a.Tokens > b.Tokens
+			}
+		case SortCost:
+			if a.Cost != b.Cost {
+				return a.Cost > b.Cost
+			}
+		case SortActivity:
+			if !a.Activity.Equal(b.Activity) {
+				return a.Activity.After(b.Activity)
+			}
+		case SortCPU:
+			if a.CPU != b.CPU {
+				return a.CPU > b.CPU
+			}
+		}
+		return a.Session < b.Session
+	}
+	sort.SliceStable(agents, func(i, j int) bool {
+		if reverse {
+			return less(agents[j], agents[i])
+		}
+		return less(agents[i], agents[j])
+	})
+}
+
+// WithRates fills each agent's token rate from the previous sample,
+// elapsed ago. Agents new since then get no rate.
+func WithRates(agents, prev []Agent, elapsed time.Duration) []Agent {
+	if elapsed <= 0 {
+		return agents
+	}
+	before := make(map[string]int, len(prev))
+	for _, a := range prev {
+		before[a.Session] = a.Tokens
+	}
+	for i := range agents {
+		if tokens, ok := before[agents[i].Session]; ok && agents[i].Tokens >= tokens {
+			agents[i].Rate = float64(agents[i].Tokens-tokens) / elapsed.Minutes()
+		}
+	}
+	return agents
+}
+
+// Sampler collects the running agents.
+type Sampler func() ([]Agent, error)
+
+// Actions are what the user can do to the selected agent.
+type Actions struct {
+	Kill  func(session string) error
+	Nudge func(session, message string) error
+}
+
+// mode is what keystrokes currently drive.
+type mode int
+
+const (
+	modeNormal mode = iota
+	modeConfirmKill
+	modeNudge
+)
+
+// Model is the bubbletea model for the top TUI.
+type Model struct {
+	sample   Sampler
+	actions  Actions
+	interval time.Duration
+
+	agents    []Agent
+	sampledAt time.Time
+	cursor    int
+	selected  string // session under the cursor, kept across refreshes
+	sortBy    string
+	reverse   bool
+	err       error
+	status    string
+
+	// UI state
+	mode     mode
+	input    []rune // nudge message being typed
+	keys     KeyMap
+	help     help.Model
+	showHelp bool
+	width    int
+	height   int
+}
+
+// New creates a new top TUI model that samples every interval.
+func New(sample Sampler, actions Actions, interval time.Duration, sortBy string) Model {
+	if sortBy == "" {
+		sortBy = SortCPU
+	}
+	return Model{
+		sample:   sample,
+		actions:  actions,
+		interval: interval,
+		sortBy:   sortBy,
+		keys:     DefaultKeyMap(),
+		help:     help.New(),
+	}
+}
+
+// sampleMsg is the result of a sample. Only scheduled samples schedule the
+// next one, so refreshing by hand doesn't start a second tick loop.
+type sampleMsg struct {
+	agents    []Agent
+	at        time.Time
+	err       error
+	scheduled bool
+}
+
+// tickMsg asks for the next sample.
+type tickMsg struct{}
+
+// actionMsg reports the outcome of a kill or nudge.
+type actionMsg struct {
+	status string
+	err    error
+}
+
+// Init initializes the model.
+func (m Model) Init() tea.Cmd {
+	return m.fetch
+}
+
+// fetch samples the agents on schedule.
+func (m Model) fetch() tea.Msg {
+	msg := m.refresh().(sampleMsg)
+	msg.scheduled = true
+	return msg
+}
+
+// refresh samples the agents immediately.
+func (m Model) refresh() tea.Msg {
+	agents, err := m.sample()
+	return sampleMsg{agents: agents, at: time.Now(), err: err}
+}
+
+func (m Model) tick() tea.Cmd {
+	return tea.Tick(m.interval, func(time.Time) tea.Msg { return tickMsg{} })
+}
+
+// Update handles messages.
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.help.Width = msg.Width
+		return m, nil
+
+	case tickMsg:
+		return m, m.fetch
+
+	case sampleMsg:
+		m.err = msg.err
+		if msg.err == nil {
+			m.agents = WithRates(msg.agents, m.agents, msg.at.Sub(m.sampledAt))
+			m.sampledAt = msg.at
+			m.resort()
+		}
+		if !msg.scheduled {
+			return m, nil
+		}
+		return m, m.tick()
+
+	case actionMsg:
+		m.status = msg.status
+		if msg.err != nil {
+			m.status = "Error: " + msg.err.Error()
+		}
+		return m, m.refresh
+
+	case tea.KeyMsg:
+		switch m.mode {
+		case modeConfirmKill:
+			return m.updateConfirmKill(msg)
+		case modeNudge:
+			return m.updateNudge(msg)
+		}
+		return m.updateNormal(msg)
+	}
+
+	return m, nil
+}
+
+func (m Model) updateNormal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Quit):
+		return m, tea.Quit
+
+	case key.Matches(msg, m.keys.Help):
+		m.showHelp = !m.showHelp
+
+	case key.Matches(msg, m.keys.Up):
+		if m.cursor > 0 {
+			m.cursor--
+		}
+		m.rememberSelection()
+
+	case key.Matches(msg, m.keys.Down):
+		if m.cursor < len(m.agents)-1 {
+			m.cursor++
+		}
+		m.rememberSelection()
+
+	case key.Matches(msg, m.keys.Sort):
+		for i, c := range SortColumns {
+			if c == m.sortBy {
+				m.sortBy = SortColumns[(i+1)%len(SortColumns)]
+				break
+			}
+		}
+		m.resort()
+
+	case key.Matches(msg, m.keys.Reverse):
+		m.reverse = !m.reverse
+		m.resort()
+
+	case key.Matches(msg, m.keys.Refresh):
+		return m, m.refresh
+
+	case key.Matches(msg, m.keys.Kill):
+		if a := m.current(); a != nil && m.actions.Kill != nil {
+			m.mode = modeConfirmKill
+			m.status = ""
+		}
+
+	case key.Matches(msg, m.keys.Nudge):
+		if a := m.current(); a != nil && m.actions.Nudge != nil {
+			m.mode = modeNudge
+			m.status = ""
+			m.input = nil
+		}
+	}
+	return m, nil
+}
+
+func (m Model) updateConfirmKill(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.mode = modeNormal
+	a := m.current()
+	if a == nil || (msg.String() != "y" && msg.String() != "Y") {
+		m.status = "Kill cancelled"
+		return m, nil
+	}
+	session, kill := a.Session, m.actions.Kill
+	return m, func() tea.Msg {
+		if err := kill(session); err != nil {
+			return actionMsg{err: err}
+		}
+		return actionMsg{status: "Killed " + session}
+	}
+}
+
+func (m Model) updateNudge(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC:
+		m.mode = modeNormal
+		m.status = "Nudge cancelled"
+		return m, nil
+	case tea.KeyBackspace:
+		if len(m.input) > 0 {
+			m.input = m.input[:len(m.input)-1]
+		}
+		return m, nil
+	case tea.KeyRunes, tea.KeySpace:
+		m.input = append(m.input, msg.Runes...)
+		return m, nil
+	case tea.KeyEnter:
+		m.mode = modeNormal
+		message := strings.TrimSpace(string(m.input))
+		a := m.current()
+		if a == nil || message == "" {
+			return m, nil
+		}
+		session, nudge := a.Session, m.actions.Nudge
+		return m, func() tea.Msg {
+			if err := nudge(session, message); err != nil {
+				return actionMsg{err: err}
+			}
+			return actionMsg{status: fmt.Sprintf("Nudged %s", session)}
+		}
+	}
+	return m, nil
+}
+
+// current returns the agent under the cursor.
+func (m Model) current() *Agent {
+	if m.cursor < 0 || m.cursor >= len(m.agents) {
+		return nil
+	}
+	return &m.agents[m.cursor]
+}
+
+func (m *Model) rememberSelection() {
+	if a := m.current(); a != nil {
+		m.selected = a.Session
+	}
+}
+
+// resort sorts the agents and keeps the cursor on the selected session.
+func (m *Model) resort() {
+	Sort(m.agents, m.sortBy, m.reverse)
+	for i, a := range m.agents {
+		if a.Session == m.selected {
+			m.cursor = i
+			return
+		}
+	}
+	if m.cursor >= len(m.agents) {
+		m.cursor = max(len(m.agents)-1, 0)
+	}
+	m.rememberSelection()
+}
+
+// View renders the model.
+func (m Model) View() string {
+	return m.renderView()
+}
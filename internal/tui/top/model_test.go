@@ -0,0 +1,116 @@
+package top
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func sessions(agents []Agent) string {
+	names := make([]string, len(agents))
+	for i, a := range agents {
+		names[i] = a.Session
+	}
+	return strings.Join(names, ",")
+}
+
+func TestSort(t *testing.T) {
+	now := time.Now()
+	agents := []Agent{
+		{Session: "b", CPU: 10, RSS: 300, Tokens: 5, Activity: now.Add(-time.Hour)},
+		{Session: "a", CPU: 90, RSS: 100, Tokens: 50, Activity: now},
+		{Session: "c", CPU: 10, RSS: 200, Tokens: 500, Activity: now.Add(-time.Minute)},
+	}
+	tests := []struct {
+		column  string
+		reverse bool
+		want    string
+	}{
+		{SortCPU, false, "a,b,c"},
+		{SortMemory, false, "b,c,a"},
+		{SortTokens, false, "c,a,b"},
+		{SortActivity, false, "a,c,b"},
+		{SortName, false, "a,b,c"},
+		{SortName, true, "c,b,a"},
+	}
+	for _, tt := range tests {
+		Sort(agents, tt.column, tt.reverse)
+		if got := sessions(agents); got != tt.want {
+			t.Errorf("Sort(%s, reverse=%v) = %s, want %s", tt.column, tt.reverse, got, tt.want)
+		}
+	}
+}
+
+func TestWithRates(t *testing.T) {
+	prev := []Agent{{Session: "a", Tokens: 1000}, {Session: "b", Tokens: 500}}
+	cur := []Agent{{Session: "a", Tokens: 1600}, {Session: "b", Tokens: 100}, {Session: "new", Tokens: 9000}}
+	got := WithRates(cur, prev, 30*time.Second)
+	if got[0].Rate != 1200 {
+		t.Errorf("rate for a = %v, want 1200/min", got[0].Rate)
+	}
+	if got[1].Rate != 0 || got[2].Rate != 0 {
+		t.Errorf("rates for a restarted and a new session = %v, %v; want 0", got[1].Rate, got[2].Rate)
+	}
+}
+
+func press(s string) tea.KeyMsg {
+	switch s {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestModelActions(t *testing.T) {
+	var killed, nudged, message string
+	actions := Actions{
+		Kill:  func(s string) error { killed = s; return nil },
+		Nudge: func(s, msg string) error { nudged, message = s, msg; return nil },
+	}
+	sample := func() ([]Agent, error) {
+		return []Agent{{Session: "gt-x-a", CPU: 1}, {Session: "gt-x-b", CPU: 2}}, nil
+	}
+	var m tea.Model = New(sample, actions, time.Second, SortCPU)
+	m, _ = m.Update(m.(Model).refresh())
+
+	// Sorted by CPU, so the cursor starts on b; move to a
+	m, _ = m.Update(press("j"))
+	m, _ = m.Update(press("x"))
+	m, _ = m.Update(press("n")) // anything but y cancels
+	if killed != "" {
+		t.Fatalf("kill ran without confirmation")
+	}
+	m, _ = m.Update(press("x"))
+	var cmd tea.Cmd
+	m, cmd = m.Update(press("y"))
+	if cmd == nil {
+		t.Fatal("confirmed kill returned no command")
+	}
+	cmd()
+	if killed != "gt-x-a" {
+		t.Errorf("killed %q, want gt-x-a", killed)
+	}
+
+	m, _ = m.Update(press("n"))
+	for _, r := range "go on" {
+		m, _ = m.Update(press(string(r)))
+	}
+	_, cmd = m.Update(press("enter"))
+	cmd()
+	if nudged != "gt-x-a" || message != "go on" {
+		t.Errorf("nudged %q with %q, want gt-x-a with \"go on\"", nudged, message)
+	}
+}
+
+func TestModelSampleError(t *testing.T) {
+	var m tea.Model = New(func() ([]Agent, error) { return nil, errors.New("tmux gone") }, Actions{}, time.Second, "")
+	m, _ = m.Update(m.(Model).refresh())
+	if view := m.View(); !strings.Contains(view, "tmux gone") {
+		t.Errorf("view doesn't show the sample error:\n%s", view)
+	}
+}
@@ -0,0 +1,189 @@
+package top
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Styles for the top TUI
+var (
+	titleStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("12"))
+
+	headerStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("8"))
+
+	selectedStyle = lipgloss.NewStyle().
+			Background(lipgloss.Color("236")).
+			Foreground(lipgloss.Color("15"))
+
+	hotStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("11")) // yellow
+
+	helpStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("8"))
+
+	statusStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("10")) // green
+
+	errorStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("9")) // red
+)
+
+// hotCPU is the CPU percentage above which a row is highlighted.
+const hotCPU = 80.0
+
+// columns of the agent table; the issue column takes the remaining width.
+const tableHeader = "%-28s %-9s %6s %8s %8s %7s %7s %6s  %s"
+
+// renderView renders the entire view.
+func (m Model) renderView() string {
+	var b strings.Builder
+
+	order := m.sortBy
+	if m.reverse {
+		order += " (reversed)"
+	}
+	b.WriteString(titleStyle.Render("Gas Town top"))
+	b.WriteString(helpStyle.Render(fmt.Sprintf("  %d agent(s) · sorted by %s · every %s", len(m.agents), order, m.interval)))
+	b.WriteString("\n\n")
+
+	if m.err != nil {
+		b.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", m.err)))
+		b.WriteString("\n\n")
+	}
+
+	if len(m.agents) == 0 && m.err == nil {
+		if m.sampledAt.IsZero() {
+			b.WriteString("Sampling agents...\n")
+		} else {
+			b.WriteString("No agents running.\n")
+		}
+	} else {
+		b.WriteString(Table(m.agents, m.cursor, m.width, time.Now()))
+	}
+
+	b.WriteString("\n")
+	switch m.mode {
+	case modeConfirmKill:
+		if a := m.current(); a != nil {
+			b.WriteString(errorStyle.Render(fmt.Sprintf("Kill %s and all its processes? [y/N] ", a.Session)))
+		}
+	case modeNudge:
+		if a := m.current(); a != nil {
+			b.WriteString(fmt.Sprintf("Nudge %s: %s█", a.Session, string(m.input)))
+		}
+	default:
+		if m.status != "" {
+			b.WriteString(statusStyle.Render(m.status))
+			b.WriteString("\n")
+		}
+		if m.showHelp {
+			b.WriteString(m.help.View(m.keys))
+		} else {
+			b.WriteString(helpStyle.Render("j/k:select  s:sort  r:reverse  x:kill  n:nudge  R:refresh  q:quit  ?:help"))
+		}
+	}
+
+	return b.String()
+}
+
+// Table renders agents as a table, highlighting the row at cursor (-1 for
+// none). width bounds the issue column; 0 means unbounded.
+func Table(agents []Agent, cursor, width int, now time.Time) string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render(fmt.Sprintf(tableHeader,
+		"AGENT", "ROLE", "CPU%", "MEM", "TOKENS", "TOK/MIN", "COST", "IDLE", "ISSUE")))
+	b.WriteString("\n")
+	for i, a := range agents {
+		line := fmt.Sprintf(tableHeader,
+			truncate(a.Address, 28),
+			a.Role,
+			fmt.Sprintf("%.1f", a.CPU),
+			formatBytes(a.RSS),
+			formatCount(a.Tokens),
+			formatCount(int(a.Rate)),
+			fmt.Sprintf("$%.2f", a.Cost),
+			formatIdle(a.Activity, now),
+			"")
+		issue := a.Issue
+		if a.Title != "" {
+			issue += " " + a.Title
+		}
+		if width > 0 {
+			issue = truncate(issue, max(width-utf8.RuneCountInString(line)-1, 10))
+		}
+		line += issue
+
+		switch {
+		case i == cursor:
+			b.WriteString(selectedStyle.Render(line))
+		case a.CPU >= hotCPU:
+			b.WriteString(hotStyle.Render(line))
+		default:
+			b.WriteString(line)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// formatBytes renders a byte count compactly (e.g. 412M).
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.0f%c", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// formatCount renders a count compactly (e.g. 1.2M).
+func formatCount(n int) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 10_000:
+		return fmt.Sprintf("%.0fk", float64(n)/1_000)
+	}
+	return fmt.Sprintf("%d", n)
+}
+
+// formatIdle renders how long ago the agent's session last saw activity.
+func formatIdle(t, now time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	d := now.Sub(t)
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(max(d.Seconds(), 0)))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd", int(d.Hours()/24))
+}
+
+// truncate shortens a string to the given rune length, preserving UTF-8.
+func truncate(s string, maxLen int) string {
+	if utf8.RuneCountInString(s) <= maxLen {
+		return s
+	}
+	runes := []rune(s)
+	if maxLen <= 3 {
+		return "..."
+	}
+	return string(runes[:maxLen-3]) + "..."
+}
@@ -0,0 +1,180 @@
+package ui
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// EnvASCII, when set to a non-empty value other than "0", turns on ASCII
+// output mode (same as --ascii).
+const EnvASCII = "GT_ASCII"
+
+// asciiMode is set once during startup by InitASCII.
+var asciiMode bool
+
+// InitASCII enables ASCII output mode when configured (TownSettings.CLIASCII
+// or --ascii) or when GT_ASCII is set.
+func InitASCII(configured bool) {
+	env := os.Getenv(EnvASCII)
+	asciiMode = configured || (env != "" && env != "0")
+}
+
+// IsASCII reports whether human output should be plain ASCII.
+func IsASCII() bool {
+	return asciiMode
+}
+
+// asciiReplacements maps the symbols gt prints to plain ASCII markers.
+var asciiReplacements = map[rune]string{
+	// Status marks
+	'✓': "[ok]", '✔': "[ok]", '✅': "[ok]",
+	'✗': "[x]", '✘': "[x]", '✖': "[x]", '❌': "[x]", '×': "x",
+	'⚠': "[!]", '🚨': "[!]", '🚫': "[blocked]", '🛑': "[stop]",
+	'⏳': "[..]", '⧖': "[..]", '⏸': "[paused]", '❄': "[frozen]",
+	'○': "o", '◌': "o", '●': "*", '•': "*", '·': ".", '★': "*", '✻': "*",
+	'▶': ">", '▸': ">", '❯': ">", '▼': "v",
+	// Arrows and punctuation
+	'→': "->", '←': "<-", '↔': "<->", '↑': "^", '↓': "v", '↺': "~",
+	'—': "--", '–': "-", '…': "...", '±': "+/-", '≤': "<=", '≥': ">=", '∥': "||",
+	// Box drawing and bars
+	'─': "-", '━': "=", '═': "=", '│': "|", '┃': "|", '║': "|",
+	'┌': "+", '┐': "+", '└': "+", '┘': "+", '├': "+", '┤': "+", '┬': "+", '┴': "+", '┼': "+",
+	'╭': "+", '╮': "+", '╰': "+", '╯': "+", '╔': "+", '╗': "+", '╚': "+", '╝': "+", '╠': "+", '╣': "+",
+	'█': "#", '░': ".",
+}
+
+// ToASCII replaces symbols, emoji, and box drawing in s with plain ASCII.
+// Letters (including accented ones) are left alone; they're content, not
+// decoration. Emoji without a replacement are dropped along with the space
+// that separated them from the text.
+func ToASCII(s string) string {
+	var b strings.Builder
+	t := asciiTransliterator{}
+	for _, r := range s {
+		t.write(&b, r)
+	}
+	return b.String()
+}
+
+// asciiTransliterator converts a rune stream, remembering whether the last
+// rune was a dropped emoji so the space after it can be dropped too.
+type asciiTransliterator struct {
+	dropped bool
+}
+
+func (t *asciiTransliterator) write(w io.StringWriter, r rune) {
+	dropped := t.dropped
+	t.dropped = false
+	if r < 0x80 {
+		if dropped && r == ' ' {
+			return
+		}
+		_, _ = w.WriteString(string(r))
+		return
+	}
+	if repl, ok := asciiReplacements[r]; ok {
+		_, _ = w.WriteString(repl)
+		return
+	}
+	switch {
+	case r == 0xFE0F || r == 0x200D: // Variation selector, zero-width joiner
+		t.dropped = dropped
+	case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsSpace(r):
+		_, _ = w.WriteString(string(r))
+	case unicode.In(r, unicode.So, unicode.Sk) || r >= 0x1F000 || (r >= 0x2190 && r <= 0x2BFF):
+		t.dropped = true
+	default:
+		_, _ = w.WriteString("?")
+	}
+}
+
+// asciiFilter holds the state of StartASCIIFilter.
+var asciiFilter struct {
+	mu     sync.Mutex
+	stdout *os.File // The real stdout, while filtering
+	stderr *os.File
+	pipes  []*os.File // Write ends handed to the process as stdout/stderr
+	wg     sync.WaitGroup
+}
+
+// StartASCIIFilter routes os.Stdout and os.Stderr through ToASCII until
+// StopASCIIFilter is called. Output that bypasses the os.Stdout variable,
+// like a child process given the real descriptor, is not filtered.
+func StartASCIIFilter() error {
+	f := &asciiFilter
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.stdout != nil {
+		return nil
+	}
+	outR, outW, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	errR, errW, err := os.Pipe()
+	if err != nil {
+		_ = outR.Close()
+		_ = outW.Close()
+		return err
+	}
+	f.stdout, f.stderr = os.Stdout, os.Stderr
+	f.pipes = []*os.File{outW, errW}
+	f.wg.Add(2)
+	go copyASCII(f.stdout, outR, &f.wg)
+	go copyASCII(f.stderr, errR, &f.wg)
+	os.Stdout, os.Stderr = outW, errW
+	return nil
+}
+
+// StopASCIIFilter flushes filtered output and restores the real stdout and
+// stderr. Call it before exiting, and before exec'ing another program that
+// should own the terminal.
+func StopASCIIFilter() {
+	f := &asciiFilter
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.stdout == nil {
+		return
+	}
+	os.Stdout, os.Stderr = f.stdout, f.stderr
+	for _, p := range f.pipes {
+		_ = p.Close()
+	}
+	f.wg.Wait()
+	f.stdout, f.stderr, f.pipes = nil, nil, nil
+}
+
+// realStdout returns the process's real stdout, even while filtering.
+func realStdout() *os.File {
+	asciiFilter.mu.Lock()
+	defer asciiFilter.mu.Unlock()
+	if asciiFilter.stdout != nil {
+		return asciiFilter.stdout
+	}
+	return os.Stdout
+}
+
+// copyASCII copies r to w through ToASCII, flushing whenever the input is
+// drained so interactive output isn't held back.
+func copyASCII(w io.Writer, r *os.File, wg *sync.WaitGroup) {
+	defer wg.Done()
+	defer r.Close()
+	in := bufio.NewReader(r)
+	out := bufio.NewWriter(w)
+	t := asciiTransliterator{}
+	for {
+		ch, _, err := in.ReadRune()
+		if err != nil {
+			_ = out.Flush()
+			return
+		}
+		t.write(out, ch)
+		if in.Buffered() == 0 {
+			_ = out.Flush()
+		}
+	}
+}
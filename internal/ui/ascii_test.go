@@ -0,0 +1,58 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestToASCII(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"plain text", "plain text"},
+		{"✓ Merged gt-1 → main", "[ok] Merged gt-1 -> main"},
+		{"🚫 Blocked work across town:", "[blocked] Blocked work across town:"},
+		{"📋 Ready work across town:", "Ready work across town:"},
+		{"⚠️  queue frozen", "[!]  queue frozen"},
+		{"── gastown ──", "-- gastown --"},
+		{"┌─┐", "+-+"},
+		{"café Zürich", "café Zürich"},
+		{"\x1b[1mbold\x1b[0m", "\x1b[1mbold\x1b[0m"},
+	}
+	for _, tt := range tests {
+		if got := ToASCII(tt.in); got != tt.want {
+			t.Errorf("ToASCII(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestASCIIFilter(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	origStdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = origStdout }()
+
+	if err := StartASCIIFilter(); err != nil {
+		t.Fatal(err)
+	}
+	if realStdout() != w {
+		t.Error("realStdout should see through the filter")
+	}
+	fmt.Println("✓ done")
+	StopASCIIFilter()
+	if os.Stdout != w {
+		t.Error("StopASCIIFilter didn't restore stdout")
+	}
+	_ = w.Close()
+
+	out, _ := io.ReadAll(r)
+	if string(out) != "[ok] done\n" {
+		t.Errorf("filtered output = %q", out)
+	}
+}
@@ -93,8 +93,9 @@ func detectDarkBackground(mode ThemeMode) bool {
 }
 
 // IsTerminal returns true if stdout is connected to a terminal (TTY).
+// It looks through the ASCII output filter to the real stdout.
 func IsTerminal() bool {
-	return term.IsTerminal(int(os.Stdout.Fd()))
+	return term.IsTerminal(int(realStdout().Fd()))
 }
 
 // ShouldUseColor determines if ANSI color codes should be used.
@@ -126,6 +127,9 @@ func ShouldUseEmoji() bool {
 	if _, exists := os.LookupEnv("GT_NO_EMOJI"); exists {
 		return false
 	}
+	if IsASCII() {
+		return false
+	}
 
 	// default: use emoji only if stdout is a TTY
 	return IsTerminal()
@@ -0,0 +1,255 @@
+// Package view runs saved queries ("views") over beads across rigs. Views
+// are defined in town settings (see config.ViewConfig).
+package view
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/customfield"
+	"github.com/steveyegge/gastown/internal/query"
+)
+
+// Kinds of beads a view covers.
+const (
+	KindIssues = "issues"
+	KindMRs    = "mrs"
+	KindAll    = "all"
+)
+
+// Computed statuses, answered by 'bd ready' and 'bd blocked' rather than
+// 'bd list --status'.
+const (
+	StatusReady   = "ready"
+	StatusBlocked = "blocked"
+)
+
+// Sort orders.
+const (
+	SortPriority = "priority"
+	SortCreated  = "created"
+	SortUpdated  = "updated"
+)
+
+// TownSource names the town beads among a view's rigs.
+const TownSource = "town"
+
+// mrLabel marks merge request beads.
+const mrLabel = "gt:merge-request"
+
+// validName matches a view name.
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Load returns the views defined in the town's settings.
+func Load(townRoot string) (map[string]*config.ViewConfig, error) {
+	settings, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(townRoot))
+	if err != nil {
+		return nil, err
+	}
+	return settings.Views, nil
+}
+
+// Names returns the view names, sorted.
+func Names(views map[string]*config.ViewConfig) []string {
+	names := make([]string, 0, len(views))
+	for name := range views {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate checks a view's definition.
+func Validate(name string, v *config.ViewConfig) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("invalid view name %q (letters, digits, '.', '_', '-')", name)
+	}
+	if v == nil {
+		return fmt.Errorf("view %q: no definition", name)
+	}
+	switch v.Kind {
+	case "", KindIssues, KindMRs, KindAll:
+	default:
+		return fmt.Errorf("view %q: unknown kind %q (want issues, mrs, or all)", name, v.Kind)
+	}
+	switch v.Status {
+	case "", "open", "in_progress", "closed", "all", StatusReady, StatusBlocked:
+	default:
+		return fmt.Errorf("view %q: unknown status %q", name, v.Status)
+	}
+	switch v.Sort {
+	case "", SortPriority, SortCreated, SortUpdated:
+	default:
+		return fmt.Errorf("view %q: unknown sort %q (want priority, created, or updated)", name, v.Sort)
+	}
+	if v.MaxPriority != nil && (*v.MaxPriority < 0 || *v.MaxPriority > 4) {
+		return fmt.Errorf("view %q: max_priority must be 0-4", name)
+	}
+	if v.Limit < 0 {
+		return fmt.Errorf("view %q: limit can't be negative", name)
+	}
+	if _, err := Query(v, ""); err != nil {
+		return fmt.Errorf("view %q: %w", name, err)
+	}
+	return nil
+}
+
+// Query parses the view's query, narrowed by an extra one (e.g. from the
+// command line). It is nil when neither is set.
+func Query(v *config.ViewConfig, extra string) (*query.Query, error) {
+	src := v.Query
+	switch {
+	case src != "" && extra != "":
+		src = "(" + src + ") and (" + extra + ")"
+	case src == "":
+		src = extra
+	}
+	if src == "" {
+		return nil, nil
+	}
+	return query.Parse(src)
+}
+
+// Status returns the view's status filter, defaulting to open.
+func Status(v *config.ViewConfig) string {
+	if v.Status == "" {
+		return "open"
+	}
+	return v.Status
+}
+
+// Covers reports whether a view includes a source: the town or a rig.
+func Covers(v *config.ViewConfig, source string) bool {
+	if len(v.Rigs) == 0 {
+		return true
+	}
+	for _, r := range v.Rigs {
+		if r == source {
+			return true
+		}
+	}
+	return false
+}
+
+// Match reports whether an issue passes the view's filters. The status
+// filter is left to the query that fetched the issue.
+func Match(v *config.ViewConfig, issue *beads.Issue) bool {
+	isMR := beads.HasLabel(issue, mrLabel) || issue.Type == "merge-request"
+	switch v.Kind {
+	case "", KindIssues:
+		if isMR {
+			return false
+		}
+	case KindMRs:
+		if !isMR {
+			return false
+		}
+	}
+	if v.Type != "" && issue.Type != v.Type && !beads.HasLabel(issue, "gt:"+v.Type) {
+		return false
+	}
+	for _, l := range v.Labels {
+		if !beads.HasLabel(issue, l) {
+			return false
+		}
+	}
+	switch v.Assignee {
+	case "":
+	case "none":
+		if issue.Assignee != "" {
+			return false
+		}
+	default:
+		if issue.Assignee != v.Assignee {
+			return false
+		}
+	}
+	if v.MaxPriority != nil && issue.Priority > *v.MaxPriority {
+		return false
+	}
+	if len(v.Fields) > 0 {
+		filters := make([]customfield.Filter, 0, len(v.Fields))
+		for name, value := range v.Fields {
+			filters = append(filters, customfield.Filter{Name: name, Value: value})
+		}
+		if !customfield.Match(beads.CustomFields(issue.Labels), filters) {
+			return false
+		}
+	}
+	return true
+}
+
+// Row is one bead in a view's results.
+type Row struct {
+	Source string `json:"source"` // "town" or rig name
+	*beads.Issue
+}
+
+// Order sorts rows by the view's sort order, then by ID, and applies its
+// limit.
+func Order(v *config.ViewConfig, rows []Row) []Row {
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		switch v.Sort {
+		case SortCreated:
+			if a.CreatedAt != b.CreatedAt {
+				return a.CreatedAt > b.CreatedAt
+			}
+		case SortUpdated:
+			if a.UpdatedAt != b.UpdatedAt {
+				return a.UpdatedAt > b.UpdatedAt
+			}
+		default:
+			if a.Priority != b.Priority {
+				return a.Priority < b.Priority
+			}
+		}
+		return a.ID < b.ID
+	})
+	if v.Limit > 0 && len(rows) > v.Limit {
+		rows = rows[:v.Limit]
+	}
+	return rows
+}
+
+// Describe summarizes a view's filters in one line, for listings.
+func Describe(v *config.ViewConfig) string {
+	parts := []string{Status(v)}
+	if v.Kind != "" && v.Kind != KindIssues {
+		parts = append(parts, "kind="+v.Kind)
+	}
+	if v.Type != "" {
+		parts = append(parts, "type="+v.Type)
+	}
+	if v.MaxPriority != nil {
+		parts = append(parts, fmt.Sprintf("P0-P%d", *v.MaxPriority))
+	}
+	for _, l := range v.Labels {
+		parts = append(parts, "label="+l)
+	}
+	if v.Assignee != "" {
+		parts = append(parts, "assignee="+v.Assignee)
+	}
+	names := make([]string, 0, len(v.Fields))
+	for name := range v.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		parts = append(parts, name+"="+v.Fields[name])
+	}
+	if v.Query != "" {
+		parts = append(parts, "where "+v.Query)
+	}
+	if len(v.Rigs) > 0 {
+		parts = append(parts, "rigs="+strings.Join(v.Rigs, ","))
+	}
+	if v.Limit > 0 {
+		parts = append(parts, fmt.Sprintf("limit=%d", v.Limit))
+	}
+	return strings.Join(parts, " ")
+}
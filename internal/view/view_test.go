@@ -0,0 +1,136 @@
+package view
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func intPtr(n int) *int { return &n }
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		view    *config.ViewConfig
+		wantErr bool
+	}{
+		{"p0-open", &config.ViewConfig{MaxPriority: intPtr(0)}, false},
+		{"mrs", &config.ViewConfig{Kind: KindMRs, Status: StatusReady, Sort: SortUpdated}, false},
+		{"bad name", &config.ViewConfig{}, true},
+		{"nil", nil, true},
+		{"kind", &config.ViewConfig{Kind: "epics"}, true},
+		{"status", &config.ViewConfig{Status: "stale"}, true},
+		{"sort", &config.ViewConfig{Sort: "title"}, true},
+		{"priority", &config.ViewConfig{MaxPriority: intPtr(5)}, true},
+		{"limit", &config.ViewConfig{Limit: -1}, true},
+	}
+	for _, tt := range tests {
+		if err := Validate(tt.name, tt.view); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%q) err = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	bug := &beads.Issue{ID: "gt-1", Type: "bug", Priority: 1, Assignee: "gastown/polecats/Toast",
+		Labels: []string{"gt:bug", "area:ui", "field:customer=acme"}}
+	mr := &beads.Issue{ID: "gt-2", Type: "merge-request", Priority: 2, Labels: []string{"gt:merge-request"}}
+
+	tests := []struct {
+		name  string
+		view  config.ViewConfig
+		issue *beads.Issue
+		want  bool
+	}{
+		{"default kind skips MRs", config.ViewConfig{}, mr, false},
+		{"mrs kind", config.ViewConfig{Kind: KindMRs}, mr, true},
+		{"mrs kind skips issues", config.ViewConfig{Kind: KindMRs}, bug, false},
+		{"all kind", config.ViewConfig{Kind: KindAll}, mr, true},
+		{"type", config.ViewConfig{Type: "bug"}, bug, true},
+		{"wrong type", config.ViewConfig{Type: "feature"}, bug, false},
+		{"labels", config.ViewConfig{Labels: []string{"area:ui", "gt:bug"}}, bug, true},
+		{"missing label", config.ViewConfig{Labels: []string{"area:api"}}, bug, false},
+		{"assignee", config.ViewConfig{Assignee: "gastown/polecats/Toast"}, bug, true},
+		{"unassigned", config.ViewConfig{Assignee: "none"}, bug, false},
+		{"priority", config.ViewConfig{MaxPriority: intPtr(1)}, bug, true},
+		{"priority too low", config.ViewConfig{MaxPriority: intPtr(0)}, bug, false},
+		{"field", config.ViewConfig{Fields: map[string]string{"customer": "ACME"}}, bug, true},
+		{"wrong field", config.ViewConfig{Fields: map[string]string{"customer": "globex"}}, bug, false},
+		{"rig filter left to caller", config.ViewConfig{Rigs: []string{"beads"}}, bug, true},
+	}
+	for _, tt := range tests {
+		if got := Match(&tt.view, tt.issue); got != tt.want {
+			t.Errorf("%s: Match = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCovers(t *testing.T) {
+	v := &config.ViewConfig{Rigs: []string{TownSource, "gastown"}}
+	if !Covers(v, TownSource) || !Covers(v, "gastown") || Covers(v, "beads") {
+		t.Errorf("Covers wrong for rigs %v", v.Rigs)
+	}
+	if !Covers(&config.ViewConfig{}, "beads") {
+		t.Error("a view without rigs should cover every rig")
+	}
+}
+
+func TestOrder(t *testing.T) {
+	rows := func() []Row {
+		return []Row{
+			{Source: "a", Issue: &beads.Issue{ID: "gt-3", Priority: 2, CreatedAt: "2026-03-01T00:00:00Z", UpdatedAt: "2026-03-09T00:00:00Z"}},
+			{Source: "a", Issue: &beads.Issue{ID: "gt-2", Priority: 1, CreatedAt: "2026-03-03T00:00:00Z", UpdatedAt: "2026-03-04T00:00:00Z"}},
+			{Source: "b", Issue: &beads.Issue{ID: "bd-1", Priority: 2, CreatedAt: "2026-03-02T00:00:00Z", UpdatedAt: "2026-03-05T00:00:00Z"}},
+		}
+	}
+	ids := func(rows []Row) string {
+		var out []string
+		for _, r := range rows {
+			out = append(out, r.ID)
+		}
+		return strings.Join(out, " ")
+	}
+	tests := []struct {
+		view config.ViewConfig
+		want string
+	}{
+		{config.ViewConfig{}, "gt-2 bd-1 gt-3"},
+		{config.ViewConfig{Sort: SortCreated}, "gt-2 bd-1 gt-3"},
+		{config.ViewConfig{Sort: SortUpdated}, "gt-3 bd-1 gt-2"},
+		{config.ViewConfig{Limit: 2}, "gt-2 bd-1"},
+	}
+	for _, tt := range tests {
+		if got := ids(Order(&tt.view, rows())); got != tt.want {
+			t.Errorf("Order(sort=%q limit=%d) = %s, want %s", tt.view.Sort, tt.view.Limit, got, tt.want)
+		}
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	v := &config.ViewConfig{Type: "bug", MaxPriority: intPtr(1), Fields: map[string]string{"customer": "acme"}, Rigs: []string{"gastown"}, Limit: 20}
+	want := "open type=bug P0-P1 customer=acme rigs=gastown limit=20"
+	if got := Describe(v); got != want {
+		t.Errorf("Describe = %q, want %q", got, want)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	v := &config.ViewConfig{Query: "label:infra or label:security"}
+	q, err := Query(v, "priority<=1")
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	// The extra query narrows the whole view query, not just its last term.
+	want := "(label:infra or label:security) and (priority<=1)"
+	if q.String() != want {
+		t.Errorf("combined query = %q, want %q", q.String(), want)
+	}
+	if q, err := Query(&config.ViewConfig{}, ""); q != nil || err != nil {
+		t.Errorf("no query = %v, %v; want nil, nil", q, err)
+	}
+	if err := Validate("broken", &config.ViewConfig{Query: "priority <="}); err == nil {
+		t.Error("Validate accepted an invalid query")
+	}
+}
@@ -0,0 +1,526 @@
+// Package watch evaluates conditions over gt's JSON output.
+//
+// A condition is a small expression over the decoded JSON document:
+//
+//	summary.total == 0
+//	status == "merged" || status == "failed"
+//	len(issues) > 0 && issues[0].priority <= 1
+//	!ready
+//
+// Paths are dotted field names with optional [n] indexes (a leading "."
+// is allowed). A path that doesn't exist evaluates to null. Supported
+// operators are == != < <= > >= && || ! and parentheses; literals are
+// numbers, 'single' or "double" quoted strings, true, false, and null.
+// len(path) is the length of an array, object, or string.
+package watch
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Expr is a parsed condition.
+type Expr struct {
+	src  string
+	root node
+}
+
+// Parse compiles a condition.
+func Parse(src string) (*Expr, error) {
+	p := &parser{src: src}
+	if err := p.lex(); err != nil {
+		return nil, err
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.toks) {
+		return nil, fmt.Errorf("unexpected %q at offset %d", p.toks[p.pos].text, p.toks[p.pos].off)
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+// String returns the source of the condition.
+func (e *Expr) String() string { return e.src }
+
+// Eval evaluates the condition against a decoded JSON document (as
+// produced by encoding/json into an interface{}) and reports whether it
+// holds.
+func (e *Expr) Eval(doc interface{}) (bool, error) {
+	v, err := e.root.eval(doc)
+	if err != nil {
+		return false, err
+	}
+	return truthy(v), nil
+}
+
+type tokKind int
+
+const (
+	tokIdent tokKind = iota
+	tokNumber
+	tokString
+	tokOp
+)
+
+type token struct {
+	kind tokKind
+	text string
+	off  int
+}
+
+type parser struct {
+	src  string
+	toks []token
+	pos  int
+}
+
+var operators = []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "!", "(", ")", "[", "]", "."}
+
+func (p *parser) lex() error {
+	s := p.src
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"' || c == '\'':
+			j := i + 1
+			var b strings.Builder
+			for ; j < len(s) && rune(s[j]) != c; j++ {
+				if s[j] == '\\' && j+1 < len(s) {
+					j++
+				}
+				b.WriteByte(s[j])
+			}
+			if j >= len(s) {
+				return fmt.Errorf("unterminated string at offset %d", i)
+			}
+			p.toks = append(p.toks, token{tokString, b.String(), i})
+			i = j + 1
+		case isDigit(s[i]) || c == '-' && i+1 < len(s) && isDigit(s[i+1]):
+			j := i + 1
+			for j < len(s) && (isDigit(s[j]) || s[j] == '.' && j+1 < len(s) && isDigit(s[j+1])) {
+				j++
+			}
+			p.toks = append(p.toks, token{tokNumber, s[i:j], i})
+			i = j
+		case c == '_' || unicode.IsLetter(c):
+			j := i + 1
+			for j < len(s) && (s[j] == '_' || s[j] == '-' || unicode.IsLetter(rune(s[j])) || unicode.IsDigit(rune(s[j]))) {
+				j++
+			}
+			p.toks = append(p.toks, token{tokIdent, s[i:j], i})
+			i = j
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(s[i:], op) {
+					p.toks = append(p.toks, token{tokOp, op, i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return fmt.Errorf("unexpected %q at offset %d", c, i)
+			}
+		}
+	}
+	return nil
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+func (p *parser) peek() *token {
+	if p.pos < len(p.toks) {
+		return &p.toks[p.pos]
+	}
+	return nil
+}
+
+// accept consumes the next token if it is the operator op.
+func (p *parser) accept(op string) bool {
+	if t := p.peek(); t != nil && t.kind == tokOp && t.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(op string) error {
+	if p.accept(op) {
+		return nil
+	}
+	if t := p.peek(); t != nil {
+		return fmt.Errorf("expected %q at offset %d, got %q", op, t.off, t.text)
+	}
+	return fmt.Errorf("expected %q at end of expression", op)
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logicNode{and: false, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = logicNode{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.accept("!") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand}, nil
+	}
+	return p.parseComparison()
+}
+
+var comparisons = map[string]bool{"==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t != nil && t.kind == tokOp && comparisons[t.text] {
+		p.pos++
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return compareNode{op: t.text, left: left, right: right}, nil
+	}
+	return left, nil
+}
+
+func (p *parser) parseOperand() (node, error) {
+	t := p.peek()
+	if t == nil {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	switch t.kind {
+	case tokNumber:
+		p.pos++
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad number %q at offset %d", t.text, t.off)
+		}
+		return literalNode{n}, nil
+	case tokString:
+		p.pos++
+		return literalNode{t.text}, nil
+	case tokIdent:
+		switch t.text {
+		case "true":
+			p.pos++
+			return literalNode{true}, nil
+		case "false":
+			p.pos++
+			return literalNode{false}, nil
+		case "null":
+			p.pos++
+			return literalNode{nil}, nil
+		case "len":
+			if p.pos+1 < len(p.toks) && p.toks[p.pos+1].text == "(" {
+				p.pos += 2
+				arg, err := p.parseOr()
+				if err != nil {
+					return nil, err
+				}
+				if err := p.expect(")"); err != nil {
+					return nil, err
+				}
+				return lenNode{arg}, nil
+			}
+		}
+		return p.parsePath()
+	case tokOp:
+		switch t.text {
+		case "(":
+			p.pos++
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return inner, nil
+		case ".", "[":
+			return p.parsePath()
+		}
+	}
+	return nil, fmt.Errorf("unexpected %q at offset %d", t.text, t.off)
+}
+
+// parsePath parses a.b[0].c; a leading "." or "[" starts at the document
+// root, so "." alone is the whole document.
+func (p *parser) parsePath() (node, error) {
+	var path pathNode
+	if t := p.peek(); t.kind == tokIdent {
+		path = append(path, t.text)
+		p.pos++
+	} else if p.accept(".") {
+		if t := p.peek(); t != nil && t.kind == tokIdent {
+			path = append(path, t.text)
+			p.pos++
+		}
+	}
+	for {
+		switch {
+		case p.accept("."):
+			t := p.peek()
+			if t == nil || (t.kind != tokIdent && t.kind != tokNumber) {
+				return nil, fmt.Errorf("expected field name after '.'")
+			}
+			path = append(path, t.text)
+			p.pos++
+		case p.accept("["):
+			t := p.peek()
+			if t == nil || (t.kind != tokNumber && t.kind != tokString) {
+				return nil, fmt.Errorf("expected index or quoted key after '['")
+			}
+			p.pos++
+			if t.kind == tokNumber {
+				idx, err := strconv.Atoi(t.text)
+				if err != nil {
+					return nil, fmt.Errorf("bad index %q at offset %d", t.text, t.off)
+				}
+				path = append(path, idx)
+			} else {
+				path = append(path, t.text)
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+		default:
+			return path, nil
+		}
+	}
+}
+
+type node interface {
+	eval(doc interface{}) (interface{}, error)
+}
+
+type literalNode struct{ v interface{} }
+
+func (n literalNode) eval(interface{}) (interface{}, error) { return n.v, nil }
+
+// pathNode is a sequence of object keys (string) and array indexes (int).
+// A "." followed by a number (items.0) is treated as an index on arrays.
+type pathNode []interface{}
+
+func (n pathNode) eval(doc interface{}) (interface{}, error) {
+	cur := doc
+	for _, step := range n {
+		switch v := cur.(type) {
+		case map[string]interface{}:
+			key, ok := step.(string)
+			if !ok {
+				key = strconv.Itoa(step.(int))
+			}
+			cur = v[key]
+		case []interface{}:
+			idx, ok := step.(int)
+			if !ok {
+				i, err := strconv.Atoi(step.(string))
+				if err != nil {
+					return nil, nil
+				}
+				idx = i
+			}
+			if idx < 0 {
+				idx += len(v)
+			}
+			if idx < 0 || idx >= len(v) {
+				return nil, nil
+			}
+			cur = v[idx]
+		default:
+			return nil, nil
+		}
+	}
+	return cur, nil
+}
+
+type lenNode struct{ arg node }
+
+func (n lenNode) eval(doc interface{}) (interface{}, error) {
+	v, err := n.arg.eval(doc)
+	if err != nil {
+		return nil, err
+	}
+	switch v := v.(type) {
+	case nil:
+		return float64(0), nil
+	case []interface{}:
+		return float64(len(v)), nil
+	case map[string]interface{}:
+		return float64(len(v)), nil
+	case string:
+		return float64(len(v)), nil
+	}
+	return nil, fmt.Errorf("len() of %s", typeName(v))
+}
+
+type notNode struct{ operand node }
+
+func (n notNode) eval(doc interface{}) (interface{}, error) {
+	v, err := n.operand.eval(doc)
+	if err != nil {
+		return nil, err
+	}
+	return !truthy(v), nil
+}
+
+type logicNode struct {
+	and         bool
+	left, right node
+}
+
+func (n logicNode) eval(doc interface{}) (interface{}, error) {
+	l, err := n.left.eval(doc)
+	if err != nil {
+		return nil, err
+	}
+	if truthy(l) != n.and {
+		return truthy(l), nil // short-circuit
+	}
+	r, err := n.right.eval(doc)
+	if err != nil {
+		return nil, err
+	}
+	return truthy(r), nil
+}
+
+type compareNode struct {
+	op          string
+	left, right node
+}
+
+func (n compareNode) eval(doc interface{}) (interface{}, error) {
+	l, err := n.left.eval(doc)
+	if err != nil {
+		return nil, err
+	}
+	r, err := n.right.eval(doc)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "==":
+		return equal(l, r), nil
+	case "!=":
+		return !equal(l, r), nil
+	}
+
+	var cmp int
+	switch lv := l.(type) {
+	case float64:
+		rv, ok := r.(float64)
+		if !ok {
+			return false, nil // Ordering across types never holds
+		}
+		switch {
+		case lv < rv:
+			cmp = -1
+		case lv > rv:
+			cmp = 1
+		}
+	case string:
+		rv, ok := r.(string)
+		if !ok {
+			return false, nil
+		}
+		cmp = strings.Compare(lv, rv)
+	default:
+		return false, nil
+	}
+	switch n.op {
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	default: // ">="
+		return cmp >= 0, nil
+	}
+}
+
+// equal compares JSON scalars; arrays and objects are never equal to
+// anything (compare their len() or fields instead).
+func equal(a, b interface{}) bool {
+	switch av := a.(type) {
+	case nil:
+		return b == nil
+	case float64:
+		bv, ok := b.(float64)
+		return ok && av == bv
+	case string:
+		bv, ok := b.(string)
+		return ok && av == bv
+	case bool:
+		bv, ok := b.(bool)
+		return ok && av == bv
+	}
+	return false
+}
+
+// truthy follows JSON-ish truthiness: null, false, 0, "", and empty
+// arrays and objects are false.
+func truthy(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		return v != ""
+	case []interface{}:
+		return len(v) > 0
+	case map[string]interface{}:
+		return len(v) > 0
+	}
+	return true
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	}
+	return fmt.Sprintf("%T", v)
+}
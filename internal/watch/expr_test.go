@@ -0,0 +1,104 @@
+package watch
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const testDoc = `{
+	"summary": {"total": 0, "by_rig": {"gastown": 2}},
+	"status": "merged",
+	"ready": false,
+	"title": "",
+	"issues": [
+		{"id": "gt-1", "priority": 1, "labels": ["bug"]},
+		{"id": "gt-2", "priority": 3}
+	],
+	"owner": null
+}`
+
+func TestEval(t *testing.T) {
+	var doc interface{}
+	if err := json.Unmarshal([]byte(testDoc), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"summary.total == 0", true},
+		{"summary.total==0", true},
+		{"summary.total != 0", false},
+		{"summary.by_rig.gastown >= 2", true},
+		{"summary.by_rig.beads == null", true},
+		{"status == 'merged'", true},
+		{`status == "merged" || status == "failed"`, true},
+		{`status == "failed"`, false},
+		{"ready", false},
+		{"!ready", true},
+		{"title", false},
+		{"owner == null", true},
+		{"missing.deeply.nested == null", true},
+		{"issues", true},
+		{"len(issues) == 2", true},
+		{"len(issues) > 0 && issues[0].priority <= 1", true},
+		{"issues[1].priority < 2", false},
+		{"issues.0.id == 'gt-1'", true},
+		{"issues[-1].id == 'gt-2'", true},
+		{"issues[5].id == null", true},
+		{"len(issues[0].labels) == 1", true},
+		{"len(missing) == 0", true},
+		{".summary.total == 0", true},
+		{"!(ready || status == 'merged')", false},
+		{"status > 'a'", true},
+		{"status > 1", false},
+		{"summary.total == '0'", false},
+		{"-1 < summary.total", true},
+		{"len(.) == 6", true},
+		{".issues[0].id == 'gt-1'", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			e, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			got, err := e.Eval(doc)
+			if err != nil {
+				t.Fatalf("Eval: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Eval(%q) = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"summary.total ==",
+		"(ready",
+		"status == 'merged",
+		"issues[",
+		"issues[0",
+		"a.",
+		"ready ready",
+		"a # b",
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", expr)
+		}
+	}
+}
+
+func TestEvalLenOfNumber(t *testing.T) {
+	e, err := Parse("len(n) > 0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Eval(map[string]interface{}{"n": 3.0}); err == nil {
+		t.Error("len() of a number succeeded, want error")
+	}
+}
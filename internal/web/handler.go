@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/pkg/gastown"
 )
 
 //go:embed static
@@ -39,6 +40,7 @@ type ConvoyHandler struct {
 	fetcher      ConvoyFetcher
 	template     *template.Template
 	fetchTimeout time.Duration
+	readOnly     bool // observer mode: no controls, no mail
 }
 
 // NewConvoyHandler creates a new convoy handler with the given fetcher and fetch timeout.
@@ -232,6 +234,10 @@ func (h *ConvoyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		Activity:    activity,
 		Summary:     summary,
 		Expand:      expandPanel,
+		ReadOnly:    h.readOnly,
+	}
+	if h.readOnly {
+		data.Mail = nil
 	}
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
@@ -323,6 +329,12 @@ func enrichIssuesWithAssignees(issues []IssueRow, hooks []HookRow) []IssueRow {
 // NewDashboardMux creates an HTTP handler that serves both the dashboard and API.
 // webCfg may be nil, in which case defaults are used.
 func NewDashboardMux(fetcher ConvoyFetcher, webCfg *config.WebTimeoutsConfig) (http.Handler, error) {
+	return newDashboardMux(fetcher, webCfg, false)
+}
+
+// newDashboardMux builds the dashboard; readOnly renders it without
+// controls or mail, for observers (see NewObserverMux).
+func newDashboardMux(fetcher ConvoyFetcher, webCfg *config.WebTimeoutsConfig, readOnly bool) (http.Handler, error) {
 	if webCfg == nil {
 		webCfg = config.DefaultWebTimeoutsConfig()
 	}
@@ -332,6 +344,7 @@ func NewDashboardMux(fetcher ConvoyFetcher, webCfg *config.WebTimeoutsConfig) (h
 	if err != nil {
 		return nil, err
 	}
+	convoyHandler.readOnly = readOnly
 
 	defaultRunTimeout := config.ParseDurationOrDefault(webCfg.DefaultRunTimeout, 30*time.Second)
 	maxRunTimeout := config.ParseDurationOrDefault(webCfg.MaxRunTimeout, 60*time.Second)
@@ -346,6 +359,11 @@ func NewDashboardMux(fetcher ConvoyFetcher, webCfg *config.WebTimeoutsConfig) (h
 
 	mux := http.NewServeMux()
 	mux.Handle("/api/", apiHandler)
+	// The typed v1 API needs the town; without one only the dashboard's
+	// command endpoints are served.
+	if town, err := gastown.Find(apiHandler.workDir); err == nil {
+		mux.Handle("/api/v1/", newServiceHandler(TownService(town), readOnly))
+	}
 	mux.Handle("/static/", http.StripPrefix("/static/", staticHandler))
 	mux.Handle("/", convoyHandler)
 
@@ -0,0 +1,217 @@
+package web
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// Observer mode serves the dashboard read-only to holders of a viewer
+// token: stakeholders can watch the town but not run commands, send mail,
+// or create issues.
+
+// viewerCookie carries the viewer token after the first visit, so the
+// dashboard's own refreshes and asset loads don't need it in the URL.
+const viewerCookie = "gt_viewer"
+
+// observerAPIPaths are the API endpoints observers may call. Everything
+// else under /api/ either changes the town or reads mail.
+var observerAPIPaths = map[string]bool{
+	"/api/crew":        true,
+	"/api/ready":       true,
+	"/api/issues/show": true,
+	"/api/pr/show":     true,
+}
+
+// observerV1Routes matches the v1 API reads observers may make: work
+// queries, the merge queue, agent status and events. The v1 handler
+// itself drops mail from what it serves them.
+var observerV1Routes = func() *http.ServeMux {
+	mux := http.NewServeMux()
+	routes := []string{
+		"GET /api/v1/rigs",
+		"GET /api/v1/rigs/{rig}/mq",
+		"GET /api/v1/rigs/{rig}/mq/{mr...}",
+		"GET /api/v1/rigs/{rig}/agents",
+		"GET /api/v1/events",
+	}
+	for _, scope := range []string{"/api/v1/town", "/api/v1/rigs/{rig}"} {
+		routes = append(routes,
+			"GET "+scope+"/issues",
+			"GET "+scope+"/issues/{id}",
+			"GET "+scope+"/ready",
+			"GET "+scope+"/blocked",
+		)
+	}
+	for _, route := range routes {
+		mux.Handle(route, http.NotFoundHandler())
+	}
+	return mux
+}()
+
+// observerMayRead reports whether observers may make API request r.
+func observerMayRead(r *http.Request) bool {
+	if strings.HasPrefix(r.URL.Path, "/api/v1/") {
+		_, pattern := observerV1Routes.Handler(r)
+		return pattern != ""
+	}
+	return observerAPIPaths[r.URL.Path]
+}
+
+// ViewerTokenPath is where a town's viewer token is kept.
+func ViewerTokenPath(townRoot string) string {
+	return filepath.Join(townRoot, ".runtime", "web", "viewer-token")
+}
+
+// LoadOrCreateViewerToken returns the town's viewer token, creating one on
+// first use.
+func LoadOrCreateViewerToken(townRoot string) (string, error) {
+	data, err := os.ReadFile(ViewerTokenPath(townRoot))
+	if err == nil && len(strings.TrimSpace(string(data))) > 0 {
+		return strings.TrimSpace(string(data)), nil
+	}
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+	return RotateViewerToken(townRoot)
+}
+
+// RotateViewerToken replaces the town's viewer token, revoking every link
+// shared with the old one.
+func RotateViewerToken(townRoot string) (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(buf)
+	path := ViewerTokenPath(townRoot)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// ObserverState records a running observer server so 'gt share status'
+// can build links to it.
+type ObserverState struct {
+	URL       string    `json:"url"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// ObserverStatePath is where the running observer server is recorded.
+func ObserverStatePath(townRoot string) string {
+	return filepath.Join(townRoot, ".runtime", "web", "observer.json")
+}
+
+// SaveObserverState records a running observer server.
+func SaveObserverState(townRoot string, state ObserverState) error {
+	path := ObserverStatePath(townRoot)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// LoadObserverState returns the recorded observer server, or nil if none
+// was started.
+func LoadObserverState(townRoot string) (*ObserverState, error) {
+	data, err := os.ReadFile(ObserverStatePath(townRoot))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var state ObserverState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ObserverStatePath(townRoot), err)
+	}
+	return &state, nil
+}
+
+// ShareURL returns a link to base that grants read-only access.
+func ShareURL(base, token string) string {
+	return strings.TrimRight(base, "/") + "/?token=" + token
+}
+
+// NewObserverMux creates a read-only dashboard for viewer-token holders.
+// The token is looked up on every request, so rotating it takes effect
+// without restarting the server.
+func NewObserverMux(fetcher ConvoyFetcher, webCfg *config.WebTimeoutsConfig, token func() (string, error)) (http.Handler, error) {
+	mux, err := newDashboardMux(fetcher, webCfg, true)
+	if err != nil {
+		return nil, err
+	}
+	return requireViewer(token, readOnly(mux)), nil
+}
+
+// requireViewer admits requests carrying the viewer token in the "token"
+// query parameter, the viewer cookie, or an Authorization bearer header.
+// A valid query token is moved into the cookie.
+func requireViewer(token func() (string, error), next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		want, err := token()
+		if err != nil || want == "" {
+			http.Error(w, "Observer access is not configured", http.StatusServiceUnavailable)
+			return
+		}
+		valid := func(got string) bool {
+			return got != "" && subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+		}
+
+		if q := r.URL.Query().Get("token"); valid(q) {
+			http.SetCookie(w, &http.Cookie{
+				Name:     viewerCookie,
+				Value:    q,
+				Path:     "/",
+				HttpOnly: true,
+				SameSite: http.SameSiteLaxMode,
+			})
+			next.ServeHTTP(w, r)
+			return
+		}
+		if c, err := r.Cookie(viewerCookie); err == nil && valid(c.Value) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && valid(bearer) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		http.Error(w, "A valid viewer token is required (ask for a 'gt share status' link)", http.StatusUnauthorized)
+	})
+}
+
+// readOnly rejects anything but reads of the dashboard, its assets, and
+// the observer API endpoints.
+func readOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "Read-only view", http.StatusForbidden)
+			return
+		}
+		if strings.HasPrefix(r.URL.Path, "/api/") && !observerMayRead(r) {
+			http.Error(w, "Read-only view", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
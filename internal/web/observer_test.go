@@ -0,0 +1,251 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/pkg/gastown"
+)
+
+func newTestObserver(t *testing.T, token string) http.Handler {
+	t.Helper()
+	mock := &MockConvoyFetcher{
+		Convoys: []ConvoyRow{{ID: "hq-cv-abc", Title: "Visible convoy", Status: "open"}},
+		Mail:    []MailRow{{ID: "hq-msg-1", Subject: "Private subject"}},
+	}
+	h, err := NewObserverMux(mock, nil, func() (string, error) { return token, nil })
+	if err != nil {
+		t.Fatalf("NewObserverMux() error = %v", err)
+	}
+	return h
+}
+
+func TestObserver_RequiresToken(t *testing.T) {
+	h := newTestObserver(t, "sekrit")
+	tests := []struct {
+		name   string
+		target string
+		header string
+		cookie string
+		want   int
+	}{
+		{"no token", "/", "", "", http.StatusUnauthorized},
+		{"wrong token", "/?token=nope", "", "", http.StatusUnauthorized},
+		{"query token", "/?token=sekrit", "", "", http.StatusOK},
+		{"cookie", "/", "", "sekrit", http.StatusOK},
+		{"bearer", "/", "Bearer sekrit", "", http.StatusOK},
+		{"static needs token", "/static/dashboard.css", "", "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.target, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: viewerCookie, Value: tt.cookie})
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestObserver_QueryTokenSetsCookie(t *testing.T) {
+	h := newTestObserver(t, "sekrit")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/?token=sekrit", nil))
+
+	var found *http.Cookie
+	for _, c := range w.Result().Cookies() {
+		if c.Name == viewerCookie {
+			found = c
+		}
+	}
+	if found == nil || found.Value != "sekrit" || !found.HttpOnly {
+		t.Errorf("viewer cookie = %+v, want HttpOnly cookie with the token", found)
+	}
+}
+
+func TestObserver_ReadOnly(t *testing.T) {
+	h := newTestObserver(t, "sekrit")
+	tests := []struct {
+		method, target string
+		want           int
+	}{
+		{"POST", "/api/run", http.StatusForbidden},
+		{"POST", "/api/mail/send", http.StatusForbidden},
+		{"POST", "/api/issues/create", http.StatusForbidden},
+		{"GET", "/api/mail/inbox", http.StatusForbidden},
+		{"GET", "/api/options", http.StatusForbidden},
+		{"GET", "/api/commands", http.StatusForbidden},
+		{"POST", "/", http.StatusForbidden},
+		{"POST", "/api/v1/town/issues", http.StatusForbidden},
+		{"POST", "/api/v1/rigs/gastown/agents/witness/stop", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.target, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(`{"command":"polecat nuke x"}`))
+			req.Header.Set("Authorization", "Bearer sekrit")
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+// mailTown serves the v1 API for a town whose only issues are a task and
+// a mail message.
+type mailTown struct{ ServiceTown }
+
+func (mailTown) Rigs() ([]*gastown.Rig, error) { return nil, nil }
+
+func (mailTown) Issues(rig string) (gastown.IssueStore, error) {
+	return mailStore{issues: []gastown.Issue{
+		{ID: "hq-1", Title: "Visible work", Status: "open"},
+		{ID: "hq-msg-1", Title: "Private subject", Description: "Private body", Status: "open", Labels: []string{"gt:message"}},
+	}}, nil
+}
+
+func (mailTown) FollowEvents(ctx context.Context, fn func(gastown.Event) error) error {
+	for _, e := range []gastown.Event{
+		{Type: events.TypeMail, Payload: map[string]any{"subject": "Private subject"}},
+		{Type: events.TypeSling, Payload: map[string]any{"bead": "hq-1"}},
+	} {
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type mailStore struct {
+	gastown.IssueStore
+	issues []gastown.Issue
+}
+
+func (s mailStore) List(ctx context.Context, filter gastown.IssueFilter) ([]gastown.Issue, error) {
+	return s.issues, nil
+}
+
+func (s mailStore) Ready(ctx context.Context) ([]gastown.Issue, error) { return s.issues, nil }
+
+func (s mailStore) Get(ctx context.Context, id string) (*gastown.Issue, error) {
+	for i := range s.issues {
+		if s.issues[i].ID == id {
+			return &s.issues[i], nil
+		}
+	}
+	return nil, gastown.ErrNotFound
+}
+
+func TestObserver_V1(t *testing.T) {
+	h := readOnly(newServiceHandler(mailTown{}, true))
+	tests := []struct {
+		target string
+		want   int
+	}{
+		{"/api/v1/rigs", http.StatusOK},
+		{"/api/v1/town/issues", http.StatusOK},
+		{"/api/v1/town/issues?label=gt:message", http.StatusOK},
+		{"/api/v1/town/ready", http.StatusOK},
+		{"/api/v1/town/issues/hq-1", http.StatusOK},
+		{"/api/v1/town/issues/hq-msg-1", http.StatusNotFound},
+		{"/api/v1/events", http.StatusOK},
+		{"/api/v1/town/issues/hq-1/close", http.StatusForbidden},
+		{"/api/v1/rigs/gastown/agents/witness/start", http.StatusForbidden},
+		{"/api/v1/unknown", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest("GET", tt.target, nil))
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+			if strings.Contains(w.Body.String(), "Private") {
+				t.Errorf("observer sees mail: %s", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestServiceHandler_ServesMailToOwner(t *testing.T) {
+	h := NewServiceHandler(mailTown{})
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/town/issues/hq-msg-1", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Private body") {
+		t.Errorf("GET mail = %d %q, want 200 with the body", w.Code, w.Body.String())
+	}
+}
+
+func TestObserver_HidesControlsAndMail(t *testing.T) {
+	h := newTestObserver(t, "sekrit")
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer sekrit")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	body := w.Body.String()
+	if !strings.Contains(body, "Visible convoy") {
+		t.Error("observer page is missing convoys")
+	}
+	if !strings.Contains(body, `class="read-only"`) {
+		t.Error("observer page is not marked read-only")
+	}
+	if strings.Contains(body, "Private subject") {
+		t.Error("observer page shows mail")
+	}
+}
+
+func TestObserver_UnconfiguredToken(t *testing.T) {
+	h := newTestObserver(t, "")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/?token=", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestViewerToken(t *testing.T) {
+	town := t.TempDir()
+	first, err := LoadOrCreateViewerToken(town)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := LoadOrCreateViewerToken(town)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == "" || again != first {
+		t.Errorf("token not stable: %q then %q", first, again)
+	}
+	info, err := os.Stat(ViewerTokenPath(town))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("token file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	rotated, err := RotateViewerToken(town)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotated == first {
+		t.Error("RotateViewerToken kept the old token")
+	}
+	if got, _ := LoadOrCreateViewerToken(town); got != rotated {
+		t.Errorf("token after rotate = %q, want %q", got, rotated)
+	}
+}
@@ -0,0 +1,457 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/pkg/gastown"
+)
+
+// The v1 API serves the pkg/gastown operations as typed JSON over HTTP,
+// for orchestrators that would otherwise exec gt and scrape its output.
+// pkg/client is its Go client. Unlike the /api/ endpoints the dashboard
+// uses, which run gt commands and return their text, every v1 route
+// returns the pkg/gastown types and reports failures as an ErrorBody.
+//
+//	GET  /api/v1/rigs
+//	GET  /api/v1/{scope}/issues                 ?status=&label=&assignee=&parent=&priority=
+//	POST /api/v1/{scope}/issues                 gastown.NewIssue
+//	GET  /api/v1/{scope}/issues/{id}
+//	POST /api/v1/{scope}/issues/{id}/close      {"reason": ...}
+//	GET  /api/v1/{scope}/ready
+//	GET  /api/v1/{scope}/blocked
+//	GET  /api/v1/rigs/{rig}/mq
+//	GET  /api/v1/rigs/{rig}/mq/{id-or-branch...}
+//	POST /api/v1/rigs/{rig}/mq/reject           {"mr": ..., "reason": ...}
+//	GET  /api/v1/rigs/{rig}/agents
+//	POST /api/v1/rigs/{rig}/agents/{role}/start
+//	POST /api/v1/rigs/{rig}/agents/{role}/stop
+//	GET  /api/v1/events                         newline-delimited gastown.Event
+//
+// {scope} is "town" for the town-level issues or "rigs/{rig}" for a rig's.
+
+// Error codes in ErrorBody.Code, one per pkg/gastown sentinel error.
+const (
+	CodeNotFound       = "not_found"
+	CodeAlreadyRunning = "already_running"
+	CodeNotRunning     = "not_running"
+	CodeBadRequest     = "bad_request"
+	CodeInternal       = "internal"
+)
+
+// ErrorBody is the response body of a failed v1 request.
+type ErrorBody struct {
+	Error string `json:"error"`
+	Code  string `json:"code"`
+}
+
+// ServiceTown is the town the v1 API serves. TownService adapts a
+// *gastown.Town; tests substitute fakes.
+type ServiceTown interface {
+	Rigs() ([]*gastown.Rig, error)
+	// Issues returns the named rig's issue store, or the town's for "".
+	Issues(rig string) (gastown.IssueStore, error)
+	MergeQueue(rig string) (gastown.MergeQueue, error)
+	Agents(rig string) (gastown.Agents, error)
+	FollowEvents(ctx context.Context, fn func(gastown.Event) error) error
+}
+
+// TownService serves an open town.
+func TownService(town *gastown.Town) ServiceTown {
+	return townService{town: town}
+}
+
+type townService struct {
+	town *gastown.Town
+}
+
+func (s townService) Rigs() ([]*gastown.Rig, error) {
+	return s.town.Rigs()
+}
+
+func (s townService) Issues(rig string) (gastown.IssueStore, error) {
+	if rig == "" {
+		return s.town.Issues(), nil
+	}
+	r, err := s.town.Rig(rig)
+	if err != nil {
+		return nil, err
+	}
+	return r.Issues(), nil
+}
+
+func (s townService) MergeQueue(rig string) (gastown.MergeQueue, error) {
+	r, err := s.town.Rig(rig)
+	if err != nil {
+		return nil, err
+	}
+	return r.MergeQueue(), nil
+}
+
+func (s townService) Agents(rig string) (gastown.Agents, error) {
+	r, err := s.town.Rig(rig)
+	if err != nil {
+		return nil, err
+	}
+	return r.Agents(), nil
+}
+
+func (s townService) FollowEvents(ctx context.Context, fn func(gastown.Event) error) error {
+	return s.town.FollowEvents(ctx, fn)
+}
+
+// errBadRequest marks errors in the request itself rather than the town.
+var errBadRequest = errors.New("bad request")
+
+// NewServiceHandler returns the v1 API over town, routed at /api/v1/.
+func NewServiceHandler(town ServiceTown) http.Handler {
+	return newServiceHandler(town, false)
+}
+
+// newServiceHandler builds the v1 API; readOnly hides mail, for observers
+// (see NewObserverMux).
+func newServiceHandler(town ServiceTown, readOnly bool) http.Handler {
+	s := &serviceHandler{town: town, readOnly: readOnly}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/rigs", s.rigs)
+	for _, scope := range []string{"/api/v1/town", "/api/v1/rigs/{rig}"} {
+		mux.HandleFunc("GET "+scope+"/issues", s.listIssues)
+		mux.HandleFunc("POST "+scope+"/issues", s.createIssue)
+		mux.HandleFunc("GET "+scope+"/issues/{id}", s.getIssue)
+		mux.HandleFunc("POST "+scope+"/issues/{id}/close", s.closeIssue)
+		mux.HandleFunc("GET "+scope+"/ready", s.ready)
+		mux.HandleFunc("GET "+scope+"/blocked", s.blocked)
+	}
+	mux.HandleFunc("GET /api/v1/rigs/{rig}/mq", s.listMergeRequests)
+	mux.HandleFunc("GET /api/v1/rigs/{rig}/mq/{mr...}", s.getMergeRequest)
+	mux.HandleFunc("POST /api/v1/rigs/{rig}/mq/reject", s.rejectMergeRequest)
+	mux.HandleFunc("GET /api/v1/rigs/{rig}/agents", s.listAgents)
+	mux.HandleFunc("POST /api/v1/rigs/{rig}/agents/{role}/start", s.startAgent)
+	mux.HandleFunc("POST /api/v1/rigs/{rig}/agents/{role}/stop", s.stopAgent)
+	mux.HandleFunc("GET /api/v1/events", s.events)
+	mux.HandleFunc("/api/v1/", func(w http.ResponseWriter, r *http.Request) {
+		sendServiceError(w, fmt.Errorf("no route for %s %s: %w", r.Method, r.URL.Path, gastown.ErrNotFound))
+	})
+	return mux
+}
+
+type serviceHandler struct {
+	town     ServiceTown
+	readOnly bool // observer mode: no mail
+}
+
+// mailLabel marks the beads that carry mail.
+const mailLabel = "gt:message"
+
+// isMail reports whether issue is a mail message.
+func isMail(issue *gastown.Issue) bool {
+	return slices.Contains(issue.Labels, mailLabel)
+}
+
+// visible drops mail from issues when serving observers.
+func (s *serviceHandler) visible(issues []gastown.Issue) []gastown.Issue {
+	if !s.readOnly {
+		return issues
+	}
+	var shown []gastown.Issue
+	for i := range issues {
+		if !isMail(&issues[i]) {
+			shown = append(shown, issues[i])
+		}
+	}
+	return shown
+}
+
+func (s *serviceHandler) rigs(w http.ResponseWriter, r *http.Request) {
+	rigs, err := s.town.Rigs()
+	if err != nil {
+		sendServiceError(w, err)
+		return
+	}
+	sendServiceJSON(w, http.StatusOK, nonNil(rigs))
+}
+
+func (s *serviceHandler) listIssues(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := gastown.IssueFilter{
+		Status:   q.Get("status"),
+		Label:    q.Get("label"),
+		Assignee: q.Get("assignee"),
+		Parent:   q.Get("parent"),
+	}
+	if p := q.Get("priority"); p != "" {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			sendServiceError(w, fmt.Errorf("priority %q: %w", p, errBadRequest))
+			return
+		}
+		filter.Priority = &n
+	}
+	store, err := s.town.Issues(r.PathValue("rig"))
+	if err != nil {
+		sendServiceError(w, err)
+		return
+	}
+	issues, err := store.List(r.Context(), filter)
+	if err != nil {
+		sendServiceError(w, err)
+		return
+	}
+	sendServiceJSON(w, http.StatusOK, nonNil(s.visible(issues)))
+}
+
+func (s *serviceHandler) getIssue(w http.ResponseWriter, r *http.Request) {
+	store, err := s.town.Issues(r.PathValue("rig"))
+	if err != nil {
+		sendServiceError(w, err)
+		return
+	}
+	issue, err := store.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		sendServiceError(w, err)
+		return
+	}
+	if s.readOnly && isMail(issue) {
+		sendServiceError(w, fmt.Errorf("issue %s: %w", r.PathValue("id"), gastown.ErrNotFound))
+		return
+	}
+	sendServiceJSON(w, http.StatusOK, issue)
+}
+
+func (s *serviceHandler) ready(w http.ResponseWriter, r *http.Request) {
+	s.issueQuery(w, r, gastown.IssueStore.Ready)
+}
+
+func (s *serviceHandler) blocked(w http.ResponseWriter, r *http.Request) {
+	s.issueQuery(w, r, gastown.IssueStore.Blocked)
+}
+
+func (s *serviceHandler) issueQuery(w http.ResponseWriter, r *http.Request, query func(gastown.IssueStore, context.Context) ([]gastown.Issue, error)) {
+	store, err := s.town.Issues(r.PathValue("rig"))
+	if err != nil {
+		sendServiceError(w, err)
+		return
+	}
+	issues, err := query(store, r.Context())
+	if err != nil {
+		sendServiceError(w, err)
+		return
+	}
+	sendServiceJSON(w, http.StatusOK, nonNil(s.visible(issues)))
+}
+
+func (s *serviceHandler) createIssue(w http.ResponseWriter, r *http.Request) {
+	var req gastown.NewIssue
+	if err := decodeServiceBody(r, &req); err != nil {
+		sendServiceError(w, err)
+		return
+	}
+	if req.Title == "" {
+		sendServiceError(w, fmt.Errorf("title is required: %w", errBadRequest))
+		return
+	}
+	store, err := s.town.Issues(r.PathValue("rig"))
+	if err != nil {
+		sendServiceError(w, err)
+		return
+	}
+	issue, err := store.Create(r.Context(), req)
+	if err != nil {
+		sendServiceError(w, err)
+		return
+	}
+	sendServiceJSON(w, http.StatusCreated, issue)
+}
+
+// CloseRequest is the body of POST .../issues/{id}/close.
+type CloseRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+func (s *serviceHandler) closeIssue(w http.ResponseWriter, r *http.Request) {
+	var req CloseRequest
+	if err := decodeServiceBody(r, &req); err != nil {
+		sendServiceError(w, err)
+		return
+	}
+	store, err := s.town.Issues(r.PathValue("rig"))
+	if err != nil {
+		sendServiceError(w, err)
+		return
+	}
+	if err := store.Close(r.Context(), r.PathValue("id"), req.Reason); err != nil {
+		sendServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *serviceHandler) listMergeRequests(w http.ResponseWriter, r *http.Request) {
+	queue, err := s.town.MergeQueue(r.PathValue("rig"))
+	if err != nil {
+		sendServiceError(w, err)
+		return
+	}
+	mrs, err := queue.List(r.Context())
+	if err != nil {
+		sendServiceError(w, err)
+		return
+	}
+	sendServiceJSON(w, http.StatusOK, nonNil(mrs))
+}
+
+func (s *serviceHandler) getMergeRequest(w http.ResponseWriter, r *http.Request) {
+	queue, err := s.town.MergeQueue(r.PathValue("rig"))
+	if err != nil {
+		sendServiceError(w, err)
+		return
+	}
+	mr, err := queue.Get(r.Context(), r.PathValue("mr"))
+	if err != nil {
+		sendServiceError(w, err)
+		return
+	}
+	sendServiceJSON(w, http.StatusOK, mr)
+}
+
+// RejectRequest is the body of POST /api/v1/rigs/{rig}/mq/reject. MR is
+// a merge request ID or its branch; branches contain slashes, so it
+// travels in the body rather than the path.
+type RejectRequest struct {
+	MR     string `json:"mr"`
+	Reason string `json:"reason"`
+}
+
+func (s *serviceHandler) rejectMergeRequest(w http.ResponseWriter, r *http.Request) {
+	var req RejectRequest
+	if err := decodeServiceBody(r, &req); err != nil {
+		sendServiceError(w, err)
+		return
+	}
+	if req.MR == "" {
+		sendServiceError(w, fmt.Errorf("mr is required: %w", errBadRequest))
+		return
+	}
+	queue, err := s.town.MergeQueue(r.PathValue("rig"))
+	if err != nil {
+		sendServiceError(w, err)
+		return
+	}
+	mr, err := queue.Reject(r.Context(), req.MR, req.Reason)
+	if err != nil {
+		sendServiceError(w, err)
+		return
+	}
+	sendServiceJSON(w, http.StatusOK, mr)
+}
+
+func (s *serviceHandler) listAgents(w http.ResponseWriter, r *http.Request) {
+	agents, err := s.town.Agents(r.PathValue("rig"))
+	if err != nil {
+		sendServiceError(w, err)
+		return
+	}
+	list, err := agents.List(r.Context())
+	if err != nil {
+		sendServiceError(w, err)
+		return
+	}
+	sendServiceJSON(w, http.StatusOK, nonNil(list))
+}
+
+func (s *serviceHandler) startAgent(w http.ResponseWriter, r *http.Request) {
+	s.agentAction(w, r, gastown.Agents.Start)
+}
+
+func (s *serviceHandler) stopAgent(w http.ResponseWriter, r *http.Request) {
+	s.agentAction(w, r, gastown.Agents.Stop)
+}
+
+func (s *serviceHandler) agentAction(w http.ResponseWriter, r *http.Request, action func(gastown.Agents, context.Context, string) error) {
+	agents, err := s.town.Agents(r.PathValue("rig"))
+	if err != nil {
+		sendServiceError(w, err)
+		return
+	}
+	if err := action(agents, r.Context(), r.PathValue("role")); err != nil {
+		sendServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// events streams the town's events, one JSON object per line, until the
+// client goes away. Only events after the request are sent, and observers
+// don't see mail events.
+func (s *serviceHandler) events(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+	// The server's write timeout is for ordinary responses; a follow
+	// stream stays open as long as the client wants it.
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	_ = rc.Flush()
+
+	// The status line is already sent, so a failed stream just ends and
+	// the client sees it close.
+	enc := json.NewEncoder(w)
+	_ = s.town.FollowEvents(r.Context(), func(e gastown.Event) error {
+		if s.readOnly && e.Type == events.TypeMail {
+			return nil
+		}
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+		return rc.Flush()
+	})
+}
+
+// decodeServiceBody decodes an optional JSON request body into v.
+func decodeServiceBody(r *http.Request, v any) error {
+	r.Body = http.MaxBytesReader(nil, r.Body, 1<<20)
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid request body: %v: %w", err, errBadRequest)
+	}
+	return nil
+}
+
+func sendServiceJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// sendServiceError reports err with the status and code of the
+// pkg/gastown sentinel it wraps.
+func sendServiceError(w http.ResponseWriter, err error) {
+	status, code := http.StatusInternalServerError, CodeInternal
+	switch {
+	case errors.Is(err, gastown.ErrNotFound):
+		status, code = http.StatusNotFound, CodeNotFound
+	case errors.Is(err, gastown.ErrAlreadyRunning):
+		status, code = http.StatusConflict, CodeAlreadyRunning
+	case errors.Is(err, gastown.ErrNotRunning):
+		status, code = http.StatusConflict, CodeNotRunning
+	case errors.Is(err, errBadRequest):
+		status, code = http.StatusBadRequest, CodeBadRequest
+	}
+	sendServiceJSON(w, status, ErrorBody{Error: err.Error(), Code: code})
+}
+
+// nonNil makes empty lists encode as [] rather than null.
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
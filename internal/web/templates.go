@@ -31,6 +31,7 @@ type ConvoyData struct {
 	Activity    []ActivityRow
 	Summary     *DashboardSummary
 	Expand      string // Panel to show fullscreen (from ?expand=name)
+	ReadOnly    bool   // Observer mode: hide commands and mail
 }
 
 // RigRow represents a registered rig in the dashboard.
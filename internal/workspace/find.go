@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/sandbox"
 )
 
 // ErrNotFound indicates no workspace was found.
@@ -30,7 +31,16 @@ const (
 // It prefers mayor/town.json over mayor/ directory as workspace marker.
 // When in a worktree path (polecats/ or crew/), continues to outermost workspace.
 // Does not resolve symlinks to stay consistent with os.Getwd().
+// In sandbox mode (GT_SANDBOX=1), returns the town's sandbox overlay instead.
 func Find(startDir string) (string, error) {
+	root, err := find(startDir)
+	if err != nil || root == "" || !sandbox.Enabled() || sandbox.IsOverlay(root) {
+		return root, err
+	}
+	return sandbox.Ensure(root)
+}
+
+func find(startDir string) (string, error) {
 	absDir, err := filepath.Abs(startDir)
 	if err != nil {
 		return "", fmt.Errorf("resolving path: %w", err)
@@ -110,6 +120,24 @@ func FindFromCwdOrError() (string, error) {
 	return FindOrError(cwd)
 }
 
+// FindSourceFromCwd returns the real town for the current directory,
+// ignoring sandbox mode: the town itself rather than its overlay, and the
+// town an overlay was copied from when run inside one.
+func FindSourceFromCwd() (string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("getting current directory: %w", err)
+	}
+	root, err := find(cwd)
+	if err != nil {
+		return "", err
+	}
+	if root == "" {
+		return "", ErrNotFound
+	}
+	return sandbox.Source(root), nil
+}
+
 // FindFromCwdWithFallback is like FindFromCwdOrError but returns (townRoot, cwd, error).
 // If getcwd fails, returns (townRoot, "", nil) using GT_TOWN_ROOT fallback.
 // This is useful for commands like `gt done` that need to continue even if the
@@ -0,0 +1,332 @@
+// Package client is the Go client for the typed v1 API that gt serve (and
+// gt dashboard) expose under /api/v1/. It implements the pkg/gastown
+// interfaces over HTTP, so code written against a local town works
+// against a remote one:
+//
+//	c := client.New("http://localhost:8080")
+//	ready, err := c.Issues("gastown").Ready(ctx)
+//	queue, err := c.MergeQueue("gastown").List(ctx)
+//	err = c.Agents("gastown").Start(ctx, gastown.RoleRefinery)
+//
+// Errors wrap the pkg/gastown sentinels where the server reported one, so
+// errors.Is(err, gastown.ErrNotFound) works as it does locally. Observer
+// servers (gt dashboard --observer) need WithToken and answer reads only.
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/steveyegge/gastown/pkg/gastown"
+)
+
+// Client talks to one gt server.
+type Client struct {
+	base  string
+	http  *http.Client
+	token string
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client requests go through. The default is
+// http.DefaultClient.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.http = hc }
+}
+
+// WithToken sends token as a bearer token, for observer servers.
+func WithToken(token string) Option {
+	return func(c *Client) { c.token = token }
+}
+
+// New returns a client for the server at baseURL, e.g.
+// "http://localhost:8080".
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{base: strings.TrimRight(baseURL, "/"), http: http.DefaultClient}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Rigs returns the town's active rigs, sorted by name. Their Issues,
+// MergeQueue, and Agents methods only work on a local town; pass the
+// rig's name to the Client's methods instead.
+func (c *Client) Rigs(ctx context.Context) ([]*gastown.Rig, error) {
+	var rigs []*gastown.Rig
+	err := c.do(ctx, http.MethodGet, "/rigs", nil, &rigs)
+	return rigs, err
+}
+
+// Issues returns the named rig's issue store, or the town-level store for
+// rig "".
+func (c *Client) Issues(rig string) gastown.IssueStore {
+	scope := "/town"
+	if rig != "" {
+		scope = "/rigs/" + url.PathEscape(rig)
+	}
+	return &issueStore{c: c, scope: scope}
+}
+
+// MergeQueue returns the named rig's merge queue.
+func (c *Client) MergeQueue(rig string) gastown.MergeQueue {
+	return &mergeQueue{c: c, path: "/rigs/" + url.PathEscape(rig) + "/mq"}
+}
+
+// Agents returns the named rig's agents.
+func (c *Client) Agents(rig string) gastown.Agents {
+	return &agents{c: c, path: "/rigs/" + url.PathEscape(rig) + "/agents"}
+}
+
+// FollowEvents calls fn with each event the town logs from now on, in
+// order, like gastown.Town.FollowEvents. It returns nil when ctx is done,
+// fn's error if fn returns one, and an error if the stream breaks.
+func (c *Client) FollowEvents(ctx context.Context, fn func(gastown.Event) error) error {
+	resp, err := c.send(ctx, http.MethodGet, "/events", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	for scanner.Scan() {
+		var e gastown.Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return fmt.Errorf("decoding event: %w", err)
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading events: %w", err)
+	}
+	return errors.New("event stream closed by server")
+}
+
+// do sends a request with body (if non-nil) as JSON and decodes the
+// response into out (if non-nil).
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	resp, err := c.send(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%s %s: decoding response: %w", method, path, err)
+	}
+	return nil
+}
+
+// send sends a request and returns the response if it succeeded, or the
+// server's error.
+func (c *Client) send(ctx context.Context, method, path string, body any) (*http.Response, error) {
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		r = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.base+"/api/v1"+path, r)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, responseError(method, path, resp)
+	}
+	return resp, nil
+}
+
+// responseError turns a failed response into an error wrapping the
+// pkg/gastown sentinel named by its code.
+func responseError(method, path string, resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	var body struct {
+		Error string `json:"error"`
+		Code  string `json:"code"`
+	}
+	if json.Unmarshal(data, &body) != nil || body.Error == "" {
+		// Not a v1 error: a proxy, an observer refusal, or no v1 API
+		body.Error = strings.TrimSpace(string(data))
+		if body.Error == "" {
+			body.Error = resp.Status
+		}
+	}
+	var sentinel error
+	switch body.Code {
+	case "not_found":
+		sentinel = gastown.ErrNotFound
+	case "already_running":
+		sentinel = gastown.ErrAlreadyRunning
+	case "not_running":
+		sentinel = gastown.ErrNotRunning
+	}
+	if sentinel != nil {
+		return fmt.Errorf("%s %s: %s: %w", method, path, body.Error, sentinel)
+	}
+	return fmt.Errorf("%s %s: %s (%d)", method, path, body.Error, resp.StatusCode)
+}
+
+// issueStore is the IssueStore of a remote rig or town.
+type issueStore struct {
+	c     *Client
+	scope string
+}
+
+var _ gastown.IssueStore = (*issueStore)(nil)
+
+func (s *issueStore) List(ctx context.Context, filter gastown.IssueFilter) ([]gastown.Issue, error) {
+	q := url.Values{}
+	for key, v := range map[string]string{
+		"status":   filter.Status,
+		"label":    filter.Label,
+		"assignee": filter.Assignee,
+		"parent":   filter.Parent,
+	} {
+		if v != "" {
+			q.Set(key, v)
+		}
+	}
+	if filter.Priority != nil {
+		q.Set("priority", strconv.Itoa(*filter.Priority))
+	}
+	path := s.scope + "/issues"
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+	var issues []gastown.Issue
+	err := s.c.do(ctx, http.MethodGet, path, nil, &issues)
+	return issues, err
+}
+
+func (s *issueStore) Get(ctx context.Context, id string) (*gastown.Issue, error) {
+	var issue gastown.Issue
+	if err := s.c.do(ctx, http.MethodGet, s.scope+"/issues/"+url.PathEscape(id), nil, &issue); err != nil {
+		return nil, err
+	}
+	return &issue, nil
+}
+
+func (s *issueStore) Ready(ctx context.Context) ([]gastown.Issue, error) {
+	var issues []gastown.Issue
+	err := s.c.do(ctx, http.MethodGet, s.scope+"/ready", nil, &issues)
+	return issues, err
+}
+
+func (s *issueStore) Blocked(ctx context.Context) ([]gastown.Issue, error) {
+	var issues []gastown.Issue
+	err := s.c.do(ctx, http.MethodGet, s.scope+"/blocked", nil, &issues)
+	return issues, err
+}
+
+func (s *issueStore) Create(ctx context.Context, issue gastown.NewIssue) (*gastown.Issue, error) {
+	var created gastown.Issue
+	if err := s.c.do(ctx, http.MethodPost, s.scope+"/issues", issue, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (s *issueStore) Close(ctx context.Context, id, reason string) error {
+	body := struct {
+		Reason string `json:"reason,omitempty"`
+	}{reason}
+	return s.c.do(ctx, http.MethodPost, s.scope+"/issues/"+url.PathEscape(id)+"/close", body, nil)
+}
+
+// mergeQueue is the MergeQueue of a remote rig.
+type mergeQueue struct {
+	c    *Client
+	path string
+}
+
+var _ gastown.MergeQueue = (*mergeQueue)(nil)
+
+func (q *mergeQueue) List(ctx context.Context) ([]gastown.MergeRequest, error) {
+	var mrs []gastown.MergeRequest
+	err := q.c.do(ctx, http.MethodGet, q.path, nil, &mrs)
+	return mrs, err
+}
+
+func (q *mergeQueue) Get(ctx context.Context, idOrBranch string) (*gastown.MergeRequest, error) {
+	// Branches keep their slashes: the route takes the rest of the path
+	var mr gastown.MergeRequest
+	if err := q.c.do(ctx, http.MethodGet, q.path+"/"+escapeSegments(idOrBranch), nil, &mr); err != nil {
+		return nil, err
+	}
+	return &mr, nil
+}
+
+func (q *mergeQueue) Reject(ctx context.Context, idOrBranch, reason string) (*gastown.MergeRequest, error) {
+	body := struct {
+		MR     string `json:"mr"`
+		Reason string `json:"reason"`
+	}{idOrBranch, reason}
+	var mr gastown.MergeRequest
+	if err := q.c.do(ctx, http.MethodPost, q.path+"/reject", body, &mr); err != nil {
+		return nil, err
+	}
+	return &mr, nil
+}
+
+// agents is the Agents of a remote rig.
+type agents struct {
+	c    *Client
+	path string
+}
+
+var _ gastown.Agents = (*agents)(nil)
+
+func (a *agents) List(ctx context.Context) ([]gastown.Agent, error) {
+	var list []gastown.Agent
+	err := a.c.do(ctx, http.MethodGet, a.path, nil, &list)
+	return list, err
+}
+
+func (a *agents) Start(ctx context.Context, role string) error {
+	return a.c.do(ctx, http.MethodPost, a.path+"/"+url.PathEscape(role)+"/start", nil, nil)
+}
+
+func (a *agents) Stop(ctx context.Context, role string) error {
+	return a.c.do(ctx, http.MethodPost, a.path+"/"+url.PathEscape(role)+"/stop", nil, nil)
+}
+
+// escapeSegments escapes each slash-separated segment of p.
+func escapeSegments(p string) string {
+	parts := strings.Split(p, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,349 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/web"
+	"github.com/steveyegge/gastown/pkg/gastown"
+)
+
+// fakeTown is an in-memory town with one rig, "gastown".
+type fakeTown struct {
+	issues map[string]*fakeIssues // by rig; "" is the town
+	queue  *fakeQueue
+	agents *fakeAgents
+	events chan gastown.Event
+}
+
+func newFakeTown() *fakeTown {
+	return &fakeTown{
+		issues: map[string]*fakeIssues{
+			"":        {issues: []gastown.Issue{{ID: "hq-1", Title: "Town work", Status: "open"}}},
+			"gastown": {issues: []gastown.Issue{{ID: "gt-1", Title: "Fix it", Status: "open", Priority: 1, Labels: []string{"bug"}}}},
+		},
+		queue:  &fakeQueue{mrs: []gastown.MergeRequest{{ID: "gt-mr-1", Branch: "polecat/Nux/gt-1", Status: "open"}}},
+		agents: &fakeAgents{running: map[string]bool{gastown.RoleWitness: true}},
+		events: make(chan gastown.Event),
+	}
+}
+
+func (t *fakeTown) Rigs() ([]*gastown.Rig, error) {
+	return []*gastown.Rig{{Name: "gastown", Prefix: "gt"}}, nil
+}
+
+func (t *fakeTown) Issues(rig string) (gastown.IssueStore, error) {
+	if s, ok := t.issues[rig]; ok {
+		return s, nil
+	}
+	return nil, fmt.Errorf("rig %q: %w", rig, gastown.ErrNotFound)
+}
+
+func (t *fakeTown) MergeQueue(rig string) (gastown.MergeQueue, error) {
+	if rig != "gastown" {
+		return nil, fmt.Errorf("rig %q: %w", rig, gastown.ErrNotFound)
+	}
+	return t.queue, nil
+}
+
+func (t *fakeTown) Agents(rig string) (gastown.Agents, error) {
+	if rig != "gastown" {
+		return nil, fmt.Errorf("rig %q: %w", rig, gastown.ErrNotFound)
+	}
+	return t.agents, nil
+}
+
+func (t *fakeTown) FollowEvents(ctx context.Context, fn func(gastown.Event) error) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e := <-t.events:
+			if err := fn(e); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+type fakeIssues struct {
+	issues []gastown.Issue
+	filter gastown.IssueFilter
+}
+
+func (s *fakeIssues) List(ctx context.Context, filter gastown.IssueFilter) ([]gastown.Issue, error) {
+	s.filter = filter
+	return s.issues, nil
+}
+
+func (s *fakeIssues) Get(ctx context.Context, id string) (*gastown.Issue, error) {
+	for i := range s.issues {
+		if s.issues[i].ID == id {
+			return &s.issues[i], nil
+		}
+	}
+	return nil, fmt.Errorf("issue %s: %w", id, gastown.ErrNotFound)
+}
+
+func (s *fakeIssues) Ready(ctx context.Context) ([]gastown.Issue, error) { return s.issues, nil }
+
+func (s *fakeIssues) Blocked(ctx context.Context) ([]gastown.Issue, error) { return nil, nil }
+
+func (s *fakeIssues) Create(ctx context.Context, issue gastown.NewIssue) (*gastown.Issue, error) {
+	created := gastown.Issue{ID: fmt.Sprintf("gt-%d", len(s.issues)+1), Title: issue.Title, Status: "open", Priority: issue.Priority, Labels: issue.Labels}
+	s.issues = append(s.issues, created)
+	return &created, nil
+}
+
+func (s *fakeIssues) Close(ctx context.Context, id, reason string) error {
+	issue, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	issue.Status, issue.CloseReason = "closed", reason
+	return nil
+}
+
+type fakeQueue struct {
+	mrs []gastown.MergeRequest
+}
+
+func (q *fakeQueue) List(ctx context.Context) ([]gastown.MergeRequest, error) { return q.mrs, nil }
+
+func (q *fakeQueue) Get(ctx context.Context, idOrBranch string) (*gastown.MergeRequest, error) {
+	for i := range q.mrs {
+		if q.mrs[i].ID == idOrBranch || q.mrs[i].Branch == idOrBranch {
+			return &q.mrs[i], nil
+		}
+	}
+	return nil, fmt.Errorf("merge request %s: %w", idOrBranch, gastown.ErrNotFound)
+}
+
+func (q *fakeQueue) Reject(ctx context.Context, idOrBranch, reason string) (*gastown.MergeRequest, error) {
+	mr, err := q.Get(ctx, idOrBranch)
+	if err != nil {
+		return nil, err
+	}
+	mr.Status, mr.CloseReason = "closed", reason
+	return mr, nil
+}
+
+type fakeAgents struct {
+	running map[string]bool
+}
+
+func (a *fakeAgents) List(ctx context.Context) ([]gastown.Agent, error) {
+	var list []gastown.Agent
+	for _, role := range []string{gastown.RoleWitness, gastown.RoleRefinery} {
+		list = append(list, gastown.Agent{Name: role, Role: role, Session: "gt-gastown-" + role, Running: a.running[role]})
+	}
+	return list, nil
+}
+
+func (a *fakeAgents) Start(ctx context.Context, role string) error {
+	if a.running[role] {
+		return fmt.Errorf("%s: %w", role, gastown.ErrAlreadyRunning)
+	}
+	a.running[role] = true
+	return nil
+}
+
+func (a *fakeAgents) Stop(ctx context.Context, role string) error {
+	if !a.running[role] {
+		return fmt.Errorf("%s: %w", role, gastown.ErrNotRunning)
+	}
+	a.running[role] = false
+	return nil
+}
+
+func newTestClient(t *testing.T) (*Client, *fakeTown) {
+	t.Helper()
+	town := newFakeTown()
+	srv := httptest.NewServer(web.NewServiceHandler(town))
+	t.Cleanup(srv.Close)
+	return New(srv.URL + "/"), town
+}
+
+func TestRigs(t *testing.T) {
+	c, _ := newTestClient(t)
+	rigs, err := c.Rigs(context.Background())
+	if err != nil {
+		t.Fatalf("Rigs() error = %v", err)
+	}
+	if len(rigs) != 1 || rigs[0].Name != "gastown" || rigs[0].Prefix != "gt" {
+		t.Errorf("Rigs() = %+v, want the gastown rig", rigs)
+	}
+}
+
+func TestIssues(t *testing.T) {
+	ctx := context.Background()
+	c, town := newTestClient(t)
+	store := c.Issues("gastown")
+
+	p := 1
+	issues, err := store.List(ctx, gastown.IssueFilter{Status: "all", Label: "bug", Priority: &p})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if !reflect.DeepEqual(issues, town.issues["gastown"].issues) {
+		t.Errorf("List() = %+v, want %+v", issues, town.issues["gastown"].issues)
+	}
+	got := town.issues["gastown"].filter
+	if got.Status != "all" || got.Label != "bug" || got.Priority == nil || *got.Priority != 1 {
+		t.Errorf("server saw filter %+v, want status all, label bug, priority 1", got)
+	}
+
+	created, err := store.Create(ctx, gastown.NewIssue{Title: "New work", Priority: 2, Labels: []string{"x"}})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if created.ID != "gt-2" || created.Title != "New work" || created.Priority != 2 {
+		t.Errorf("Create() = %+v, want gt-2 'New work' P2", created)
+	}
+
+	if err := store.Close(ctx, "gt-2", "done"); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	issue, err := store.Get(ctx, "gt-2")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if issue.Status != "closed" || issue.CloseReason != "done" {
+		t.Errorf("Get() after Close = %+v, want closed with reason done", issue)
+	}
+
+	if _, err := store.Get(ctx, "gt-99"); !errors.Is(err, gastown.ErrNotFound) {
+		t.Errorf("Get(gt-99) error = %v, want ErrNotFound", err)
+	}
+	if _, err := c.Issues("missing").Ready(ctx); !errors.Is(err, gastown.ErrNotFound) {
+		t.Errorf("Ready() on a missing rig error = %v, want ErrNotFound", err)
+	}
+	blocked, err := store.Blocked(ctx)
+	if err != nil || len(blocked) != 0 {
+		t.Errorf("Blocked() = %v, %v, want none", blocked, err)
+	}
+	if _, err := store.Create(ctx, gastown.NewIssue{}); err == nil || errors.Is(err, gastown.ErrNotFound) {
+		t.Errorf("Create() without a title error = %v, want a bad request", err)
+	}
+}
+
+func TestTownIssues(t *testing.T) {
+	c, _ := newTestClient(t)
+	ready, err := c.Issues("").Ready(context.Background())
+	if err != nil {
+		t.Fatalf("Ready() error = %v", err)
+	}
+	if len(ready) != 1 || ready[0].ID != "hq-1" {
+		t.Errorf("Ready() = %+v, want hq-1", ready)
+	}
+}
+
+func TestMergeQueue(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestClient(t)
+	queue := c.MergeQueue("gastown")
+
+	mrs, err := queue.List(ctx)
+	if err != nil || len(mrs) != 1 {
+		t.Fatalf("List() = %v, %v, want one MR", mrs, err)
+	}
+	mr, err := queue.Get(ctx, "polecat/Nux/gt-1")
+	if err != nil {
+		t.Fatalf("Get(branch) error = %v", err)
+	}
+	if mr.ID != "gt-mr-1" {
+		t.Errorf("Get(branch) = %+v, want gt-mr-1", mr)
+	}
+	mr, err = queue.Reject(ctx, "polecat/Nux/gt-1", "conflicts")
+	if err != nil {
+		t.Fatalf("Reject() error = %v", err)
+	}
+	if mr.Status != "closed" || mr.CloseReason != "conflicts" {
+		t.Errorf("Reject() = %+v, want closed for conflicts", mr)
+	}
+	if _, err := queue.Get(ctx, "gt-mr-9"); !errors.Is(err, gastown.ErrNotFound) {
+		t.Errorf("Get(gt-mr-9) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestAgents(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestClient(t)
+	agents := c.Agents("gastown")
+
+	if err := agents.Start(ctx, gastown.RoleWitness); !errors.Is(err, gastown.ErrAlreadyRunning) {
+		t.Errorf("Start(witness) error = %v, want ErrAlreadyRunning", err)
+	}
+	if err := agents.Stop(ctx, gastown.RoleRefinery); !errors.Is(err, gastown.ErrNotRunning) {
+		t.Errorf("Stop(refinery) error = %v, want ErrNotRunning", err)
+	}
+	if err := agents.Start(ctx, gastown.RoleRefinery); err != nil {
+		t.Fatalf("Start(refinery) error = %v", err)
+	}
+	list, err := agents.List(ctx)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 2 || !list[0].Running || !list[1].Running {
+		t.Errorf("List() = %+v, want the witness and refinery running", list)
+	}
+}
+
+func TestFollowEvents(t *testing.T) {
+	c, town := newTestClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	sent := []gastown.Event{
+		{Time: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), Type: "sling", Actor: "mayor", Payload: map[string]any{"bead": "gt-1"}},
+		{Time: time.Date(2026, 1, 2, 3, 4, 6, 0, time.UTC), Type: "merged", Actor: "gastown/refinery"},
+	}
+	go func() {
+		for _, e := range sent {
+			select {
+			case town.events <- e:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var got []gastown.Event
+	done := errors.New("done")
+	err := c.FollowEvents(ctx, func(e gastown.Event) error {
+		got = append(got, e)
+		if len(got) == len(sent) {
+			return done
+		}
+		return nil
+	})
+	if !errors.Is(err, done) {
+		t.Fatalf("FollowEvents() error = %v, want fn's error", err)
+	}
+	if !reflect.DeepEqual(got, sent) {
+		t.Errorf("FollowEvents() got %+v, want %+v", got, sent)
+	}
+}
+
+func TestWithToken(t *testing.T) {
+	var auth string
+	h := web.NewServiceHandler(newFakeTown())
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		h.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	if _, err := New(srv.URL, WithToken("sekrit")).Rigs(context.Background()); err != nil {
+		t.Fatalf("Rigs() error = %v", err)
+	}
+	if auth != "Bearer sekrit" {
+		t.Errorf("Authorization = %q, want Bearer sekrit", auth)
+	}
+}
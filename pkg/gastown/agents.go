@@ -0,0 +1,125 @@
+package gastown
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/session"
+	"github.com/steveyegge/gastown/internal/tmux"
+	"github.com/steveyegge/gastown/internal/witness"
+)
+
+// Agent roles.
+const (
+	RoleWitness  = "witness"
+	RoleRefinery = "refinery"
+	RolePolecat  = "polecat"
+	RoleCrew     = "crew"
+)
+
+// Agent is one of a rig's agents and whether its session is up.
+type Agent struct {
+	Name    string `json:"name"` // The role for the witness and refinery
+	Role    string `json:"role"` // witness, refinery, polecat, or crew
+	Session string `json:"session"`
+	Running bool   `json:"running"`
+}
+
+// Agents reports on a rig's agents and starts and stops its patrol agents.
+// Polecats are started by slinging work to them and crew by the people
+// who own them, so only the witness and refinery (by role) can be started
+// and stopped here.
+//
+// Contexts are checked before each operation; an operation already running
+// is not interrupted.
+type Agents interface {
+	// List returns the witness, refinery, polecats, and crew, in that order.
+	List(ctx context.Context) ([]Agent, error)
+	// Start starts a patrol agent; ErrAlreadyRunning if it's up.
+	Start(ctx context.Context, role string) error
+	// Stop stops a patrol agent; ErrNotRunning if it's down.
+	Stop(ctx context.Context, role string) error
+}
+
+// sessionAgents is the Agents backed by the rig's tmux sessions.
+type sessionAgents struct {
+	rig      *rig.Rig
+	witness  *witness.Manager
+	refinery *refinery.Manager
+}
+
+var _ Agents = (*sessionAgents)(nil)
+
+func newSessionAgents(r *rig.Rig) *sessionAgents {
+	mgr := refinery.NewManager(r)
+	mgr.SetOutput(io.Discard)
+	return &sessionAgents{rig: r, witness: witness.NewManager(r), refinery: mgr}
+}
+
+func (a *sessionAgents) List(ctx context.Context) ([]Agent, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	agents := []Agent{
+		{Name: RoleWitness, Role: RoleWitness, Session: session.WitnessSessionName(a.rig.Name)},
+		{Name: RoleRefinery, Role: RoleRefinery, Session: session.RefinerySessionName(a.rig.Name)},
+	}
+	for _, name := range a.rig.Polecats {
+		agents = append(agents, Agent{Name: name, Role: RolePolecat, Session: session.PolecatSessionName(a.rig.Name, name)})
+	}
+	for _, name := range a.rig.Crew {
+		agents = append(agents, Agent{Name: name, Role: RoleCrew, Session: session.CrewSessionName(a.rig.Name, name)})
+	}
+
+	t := tmux.NewTmux()
+	for i := range agents {
+		running, err := t.HasSession(agents[i].Session)
+		if err != nil {
+			return nil, err
+		}
+		agents[i].Running = running
+	}
+	return agents, nil
+}
+
+func (a *sessionAgents) Start(ctx context.Context, role string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	var err error
+	switch role {
+	case RoleWitness:
+		err = a.witness.Start(false, "", nil)
+	case RoleRefinery:
+		err = a.refinery.Start(false, "")
+	default:
+		return fmt.Errorf("%s agents can't be started here", role)
+	}
+	if errors.Is(err, witness.ErrAlreadyRunning) || errors.Is(err, refinery.ErrAlreadyRunning) {
+		return fmt.Errorf("%s: %w", role, ErrAlreadyRunning)
+	}
+	return err
+}
+
+func (a *sessionAgents) Stop(ctx context.Context, role string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	var err error
+	switch role {
+	case RoleWitness:
+		err = a.witness.Stop()
+	case RoleRefinery:
+		err = a.refinery.Stop()
+	default:
+		return fmt.Errorf("%s agents can't be stopped here", role)
+	}
+	if errors.Is(err, witness.ErrNotRunning) || errors.Is(err, refinery.ErrNotRunning) {
+		return fmt.Errorf("%s: %w", role, ErrNotRunning)
+	}
+	return err
+}
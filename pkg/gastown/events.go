@@ -0,0 +1,92 @@
+package gastown
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+// Event is an entry in the town's event log: work slung, an MR merged, an
+// agent started, and so on.
+type Event struct {
+	Time    time.Time      `json:"time"`
+	Type    string         `json:"type"`
+	Actor   string         `json:"actor"`
+	Source  string         `json:"source,omitempty"`
+	Payload map[string]any `json:"payload,omitempty"`
+}
+
+// eventPoll is how often FollowEvents looks for new events.
+var eventPoll = time.Second
+
+// FollowEvents calls fn with each event logged from now on, in order. It
+// returns nil when ctx is done, or fn's error if fn returns one.
+func (t *Town) FollowEvents(ctx context.Context, fn func(Event) error) error {
+	path := filepath.Join(t.root, events.EventsFile)
+	var offset int64
+	if info, err := os.Stat(path); err == nil {
+		offset = info.Size()
+	}
+
+	ticker := time.NewTicker(eventPoll)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		var err error
+		if offset, err = readEvents(path, offset, fn); err != nil {
+			return err
+		}
+	}
+}
+
+// readEvents passes fn the whole lines of the log after offset, returning
+// the offset after the last one. A log shorter than offset was replaced
+// and is read from the start.
+func readEvents(path string, offset int64, fn func(Event) error) (int64, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return offset, err
+	}
+	defer f.Close()
+	if info, err := f.Stat(); err != nil {
+		return offset, err
+	} else if info.Size() < offset {
+		offset = 0
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return offset, err
+	}
+
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err != nil {
+			// A partial line is still being written; it's read next time
+			return offset, nil
+		}
+		offset += int64(len(line))
+		var e events.Event
+		if json.Unmarshal(bytes.TrimSpace(line), &e) != nil {
+			continue
+		}
+		if err := fn(Event{Time: e.Time(), Type: e.Type, Actor: e.Actor, Source: e.Source, Payload: e.Payload}); err != nil {
+			return offset, err
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package gastown
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+func TestFollowEvents(t *testing.T) {
+	saved := eventPoll
+	eventPoll = 10 * time.Millisecond
+	defer func() { eventPoll = saved }()
+
+	town, err := Open(setupTown(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(town.Root(), events.EventsFile)
+	appendLog := func(s string) {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if _, err := f.WriteString(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Logged before following, so not followed
+	appendLog(`{"ts":"2026-03-20T11:00:00Z","type":"sling","actor":"mayor"}` + "\n")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	got := make(chan Event, 3)
+	done := make(chan error, 1)
+	go func() {
+		done <- town.FollowEvents(ctx, func(e Event) error {
+			got <- e
+			return nil
+		})
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	appendLog(`{"ts":"2026-03-20T12:00:00Z","type":"merged","actor":"alpha/refinery","payload":{"mr":"al-1"}}` + "\n")
+	appendLog("not json\n")
+	// Written in two parts: the first isn't a whole line yet
+	appendLog(`{"ts":"2026-03-20T12:01:00Z","type":"do`)
+	time.Sleep(50 * time.Millisecond)
+	appendLog(`ne","actor":"alpha/polecats/Nux"}` + "\n")
+
+	for _, want := range []string{"merged", "done"} {
+		select {
+		case e := <-got:
+			if e.Type != want {
+				t.Errorf("event type = %q, want %q", e.Type, want)
+			}
+			if want == "merged" && (e.Payload["mr"] != "al-1" || !e.Time.Equal(time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC))) {
+				t.Errorf("merged event = %+v", e)
+			}
+		case <-ctx.Done():
+			t.Fatalf("timed out waiting for %s", want)
+		}
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("FollowEvents = %v, want nil after cancel", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("extra event: %+v", <-got)
+	}
+}
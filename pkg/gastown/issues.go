@@ -0,0 +1,201 @@
+package gastown
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+// Issue is a unit of tracked work.
+type Issue struct {
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Description string   `json:"description,omitempty"`
+	Status      string   `json:"status"`   // open, in_progress, blocked, closed
+	Priority    int      `json:"priority"` // 0 (urgent) to 4 (backlog)
+	Type        string   `json:"type"`     // task, bug, feature, epic, ...
+	Assignee    string   `json:"assignee,omitempty"`
+	Parent      string   `json:"parent,omitempty"`
+	Labels      []string `json:"labels,omitempty"`
+	DependsOn   []string `json:"depends_on,omitempty"`
+	BlockedBy   []string `json:"blocked_by,omitempty"`
+	CreatedAt   string   `json:"created_at,omitempty"` // RFC 3339
+	UpdatedAt   string   `json:"updated_at,omitempty"`
+	ClosedAt    string   `json:"closed_at,omitempty"`
+	CloseReason string   `json:"close_reason,omitempty"`
+}
+
+// IssueFilter selects issues for IssueStore.List. Zero values match
+// everything, except Status, which defaults to open issues.
+type IssueFilter struct {
+	Status   string // open (default), closed, or all
+	Label    string
+	Assignee string
+	Parent   string
+	Priority *int
+}
+
+// NewIssue describes an issue to create.
+type NewIssue struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description,omitempty"`
+	Type        string   `json:"type,omitempty"` // Defaults to task
+	Priority    int      `json:"priority"`
+	Parent      string   `json:"parent,omitempty"`
+	Labels      []string `json:"labels,omitempty"`
+	Actor       string   `json:"actor,omitempty"` // Recorded as the creator
+}
+
+// IssueStore reads and updates issues in one beads database (a rig's, or
+// the town's).
+//
+// Contexts are checked before each operation; an operation already running
+// is not interrupted.
+type IssueStore interface {
+	List(ctx context.Context, filter IssueFilter) ([]Issue, error)
+	Get(ctx context.Context, id string) (*Issue, error)
+	Ready(ctx context.Context) ([]Issue, error)
+	Blocked(ctx context.Context) ([]Issue, error)
+	Create(ctx context.Context, issue NewIssue) (*Issue, error)
+	Close(ctx context.Context, id, reason string) error
+}
+
+// beadsStore is the IssueStore backed by bd.
+type beadsStore struct {
+	bd *beads.Beads
+}
+
+var _ IssueStore = (*beadsStore)(nil)
+
+func newBeadsStore(dir string) *beadsStore {
+	return &beadsStore{bd: beads.New(dir)}
+}
+
+func (s *beadsStore) List(ctx context.Context, filter IssueFilter) ([]Issue, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	opts := beads.ListOptions{
+		Status:   filter.Status,
+		Label:    filter.Label,
+		Assignee: filter.Assignee,
+		Parent:   filter.Parent,
+		Priority: -1,
+	}
+	if opts.Status == "" {
+		opts.Status = "open"
+	}
+	if filter.Priority != nil {
+		opts.Priority = *filter.Priority
+	}
+	issues, err := s.bd.List(opts)
+	if err != nil {
+		return nil, err
+	}
+	return toIssues(issues), nil
+}
+
+func (s *beadsStore) Get(ctx context.Context, id string) (*Issue, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	issue, err := s.bd.Show(id)
+	if err != nil {
+		if errors.Is(err, beads.ErrNotFound) {
+			return nil, fmt.Errorf("issue %s: %w", id, ErrNotFound)
+		}
+		return nil, err
+	}
+	out := toIssue(issue)
+	return &out, nil
+}
+
+func (s *beadsStore) Ready(ctx context.Context) ([]Issue, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	issues, err := s.bd.Ready()
+	if err != nil {
+		return nil, err
+	}
+	return toIssues(issues), nil
+}
+
+func (s *beadsStore) Blocked(ctx context.Context) ([]Issue, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	issues, err := s.bd.Blocked()
+	if err != nil {
+		return nil, err
+	}
+	return toIssues(issues), nil
+}
+
+func (s *beadsStore) Create(ctx context.Context, issue NewIssue) (*Issue, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(issue.Title) == "" {
+		return nil, fmt.Errorf("issue title is required")
+	}
+	typ := issue.Type
+	if typ == "" {
+		typ = "task"
+	}
+	created, err := s.bd.Create(beads.CreateOptions{
+		Title:       issue.Title,
+		Description: issue.Description,
+		Type:        typ,
+		Priority:    issue.Priority,
+		Parent:      issue.Parent,
+		Labels:      issue.Labels,
+		Actor:       issue.Actor,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := toIssue(created)
+	return &out, nil
+}
+
+func (s *beadsStore) Close(ctx context.Context, id, reason string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if reason == "" {
+		return s.bd.Close(id)
+	}
+	return s.bd.CloseWithReason(reason, id)
+}
+
+func toIssue(i *beads.Issue) Issue {
+	return Issue{
+		ID:          i.ID,
+		Title:       i.Title,
+		Description: i.Description,
+		Status:      i.Status,
+		Priority:    i.Priority,
+		Type:        i.Type,
+		Assignee:    i.Assignee,
+		Parent:      i.Parent,
+		Labels:      append([]string(nil), i.Labels...),
+		DependsOn:   append([]string(nil), i.DependsOn...),
+		BlockedBy:   append([]string(nil), i.BlockedBy...),
+		CreatedAt:   i.CreatedAt,
+		UpdatedAt:   i.UpdatedAt,
+		ClosedAt:    i.ClosedAt,
+		CloseReason: i.CloseReason,
+	}
+}
+
+func toIssues(issues []*beads.Issue) []Issue {
+	out := make([]Issue, 0, len(issues))
+	for _, i := range issues {
+		out = append(out, toIssue(i))
+	}
+	return out
+}
@@ -0,0 +1,119 @@
+package gastown
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// MergeRequest is a branch waiting in, or processed by, a rig's merge queue.
+type MergeRequest struct {
+	ID          string    `json:"id"`
+	Branch      string    `json:"branch"`
+	Target      string    `json:"target"`                 // Branch it merges into
+	Issue       string    `json:"issue,omitempty"`        // Issue the work is for
+	Worker      string    `json:"worker,omitempty"`       // Polecat that did the work, if any
+	Status      string    `json:"status"`                 // open, in_progress, closed
+	CloseReason string    `json:"close_reason,omitempty"` // merged, rejected, conflict, superseded (when closed)
+	Position    int       `json:"position,omitempty"`     // 1-based place in the queue (0 when not queued)
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// MergeQueue reads and acts on a rig's merge queue. Merging itself is the
+// refinery's job; callers submit and reject.
+//
+// Contexts are checked before each operation; an operation already running
+// is not interrupted.
+type MergeQueue interface {
+	// List returns the open MRs in the order the refinery will take them.
+	List(ctx context.Context) ([]MergeRequest, error)
+	// Get finds an open MR by ID or branch.
+	Get(ctx context.Context, idOrBranch string) (*MergeRequest, error)
+	// Reject closes an open MR without merging it.
+	Reject(ctx context.Context, idOrBranch, reason string) (*MergeRequest, error)
+}
+
+// refineryQueue is the MergeQueue backed by the refinery's beads queue.
+type refineryQueue struct {
+	rig *rig.Rig
+	mgr *refinery.Manager
+}
+
+var _ MergeQueue = (*refineryQueue)(nil)
+
+func newRefineryQueue(r *rig.Rig) *refineryQueue {
+	mgr := refinery.NewManager(r)
+	mgr.SetOutput(io.Discard) // Library callers get errors, not console output
+	return &refineryQueue{rig: r, mgr: mgr}
+}
+
+func (q *refineryQueue) List(ctx context.Context) ([]MergeRequest, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if q.rig.Archived {
+		return nil, nil
+	}
+	items, err := q.mgr.Queue()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]MergeRequest, 0, len(items))
+	for _, item := range items {
+		mr := toMergeRequest(item.MR)
+		mr.Position = item.Position
+		out = append(out, mr)
+	}
+	return out, nil
+}
+
+func (q *refineryQueue) Get(ctx context.Context, idOrBranch string) (*MergeRequest, error) {
+	list, err := q.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i, mr := range list {
+		if mr.ID == idOrBranch || mr.Branch == idOrBranch || mr.Branch == constants.BranchPolecatPrefix+idOrBranch {
+			return &list[i], nil
+		}
+	}
+	return nil, fmt.Errorf("merge request %s: %w", idOrBranch, ErrNotFound)
+}
+
+func (q *refineryQueue) Reject(ctx context.Context, idOrBranch, reason string) (*MergeRequest, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	mr, err := q.mgr.RejectMR(idOrBranch, reason, true)
+	if err != nil {
+		return nil, mrError(idOrBranch, err)
+	}
+	out := toMergeRequest(mr)
+	return &out, nil
+}
+
+func mrError(idOrBranch string, err error) error {
+	if errors.Is(err, refinery.ErrMRNotFound) {
+		return fmt.Errorf("merge request %s: %w", idOrBranch, ErrNotFound)
+	}
+	return err
+}
+
+func toMergeRequest(mr *refinery.MergeRequest) MergeRequest {
+	return MergeRequest{
+		ID:          mr.ID,
+		Branch:      mr.Branch,
+		Target:      mr.TargetBranch,
+		Issue:       mr.IssueID,
+		Worker:      mr.Worker,
+		Status:      string(mr.Status),
+		CloseReason: string(mr.CloseReason),
+		CreatedAt:   mr.CreatedAt,
+	}
+}
@@ -0,0 +1,165 @@
+// Package gastown is the public Go API for embedding Gas Town operations
+// in other tools without exec'ing the gt binary.
+//
+// Open a town, then reach its rigs, their issues, merge queues, and
+// agents:
+//
+//	town, err := gastown.Find(".")
+//	if err != nil { ... }
+//	r, err := town.Rig("gastown")
+//	if err != nil { ... }
+//	ready, err := r.Issues().Ready(ctx)
+//	queue, err := r.MergeQueue().List(ctx)
+//	agents, err := r.Agents().List(ctx)
+//
+// Issues, merge queues, and agents are interfaces so callers can
+// substitute fakes in tests, or the remote implementations in pkg/client.
+// The implementations returned here run bd and tmux under the hood; that
+// is an implementation detail and may change. The types in this package
+// are stable; anything under internal/ is not.
+package gastown
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Errors returned by the API. Compare with errors.Is.
+var (
+	ErrNotTown        = errors.New("not a Gas Town workspace")
+	ErrNotFound       = errors.New("not found")
+	ErrAlreadyRunning = errors.New("already running")
+	ErrNotRunning     = errors.New("not running")
+)
+
+// Town is an open Gas Town workspace.
+type Town struct {
+	root string
+	rigs *config.RigsConfig
+	mgr  *rig.Manager
+}
+
+// Open opens the town rooted at root.
+func Open(root string) (*Town, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := workspace.IsWorkspace(abs)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", abs, ErrNotTown)
+	}
+	rigsConfig, err := config.LoadRigsConfig(filepath.Join(abs, "mayor", "rigs.json"))
+	if err != nil {
+		if !errors.Is(err, config.ErrNotFound) {
+			return nil, fmt.Errorf("loading rigs: %w", err)
+		}
+		rigsConfig = &config.RigsConfig{Version: 1, Rigs: map[string]config.RigEntry{}}
+	}
+	return &Town{
+		root: abs,
+		rigs: rigsConfig,
+		mgr:  rig.NewManager(abs, rigsConfig, git.NewGit(abs)),
+	}, nil
+}
+
+// Find opens the town containing dir, searching upward from it.
+func Find(dir string) (*Town, error) {
+	root, err := workspace.Find(dir)
+	if err != nil {
+		return nil, err
+	}
+	if root == "" {
+		return nil, fmt.Errorf("%s: %w", dir, ErrNotTown)
+	}
+	return Open(root)
+}
+
+// Root returns the town's root directory.
+func (t *Town) Root() string { return t.root }
+
+// Rigs returns the town's active rigs, sorted by name. Archived rigs are
+// left out; use Rig to reach one by name.
+func (t *Town) Rigs() ([]*Rig, error) {
+	found, err := t.mgr.DiscoverRigs()
+	if err != nil {
+		return nil, err
+	}
+	rigs := make([]*Rig, 0, len(found))
+	for _, r := range found {
+		rigs = append(rigs, t.newRig(r))
+	}
+	sort.Slice(rigs, func(i, j int) bool { return rigs[i].Name < rigs[j].Name })
+	return rigs, nil
+}
+
+// Rig returns the named rig, archived or not.
+func (t *Town) Rig(name string) (*Rig, error) {
+	r, err := t.mgr.GetRig(name)
+	if errors.Is(err, rig.ErrRigNotFound) {
+		return nil, fmt.Errorf("rig %q: %w", name, ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return t.newRig(r), nil
+}
+
+// Issues returns the town-level issue store (hq-* convoys, escalations,
+// and other cross-rig work).
+func (t *Town) Issues() IssueStore {
+	return newBeadsStore(t.root)
+}
+
+// Rig is one project in the town.
+type Rig struct {
+	Name     string   `json:"name"`
+	Path     string   `json:"path"`
+	GitURL   string   `json:"git_url"`
+	Prefix   string   `json:"prefix,omitempty"`   // Issue ID prefix, e.g. "gt"
+	Groups   []string `json:"groups,omitempty"`   // Rig groups it belongs to
+	Archived bool     `json:"archived,omitempty"` // In cold storage: no checkouts, merge queue closed
+
+	rig *rig.Rig
+}
+
+func (t *Town) newRig(r *rig.Rig) *Rig {
+	entry := t.rigs.Rigs[r.Name]
+	out := &Rig{
+		Name:     r.Name,
+		Path:     r.Path,
+		GitURL:   r.GitURL,
+		Groups:   append([]string(nil), entry.Groups...),
+		Archived: r.Archived,
+		rig:      r,
+	}
+	if r.Config != nil {
+		out.Prefix = r.Config.Prefix
+	}
+	return out
+}
+
+// Issues returns the rig's issue store.
+func (r *Rig) Issues() IssueStore {
+	return newBeadsStore(r.rig.BeadsPath())
+}
+
+// MergeQueue returns the rig's merge queue.
+func (r *Rig) MergeQueue() MergeQueue {
+	return newRefineryQueue(r.rig)
+}
+
+// Agents returns the rig's agents.
+func (r *Rig) Agents() Agents {
+	return newSessionAgents(r.rig)
+}
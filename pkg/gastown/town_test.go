@@ -0,0 +1,119 @@
+package gastown
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// setupTown writes a town with an active rig "alpha" (in group "infra")
+// and an archived rig "old".
+func setupTown(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	for _, dir := range []string{"mayor", "alpha/witness", "old"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rigs := config.RigsConfig{
+		Version: 1,
+		Rigs: map[string]config.RigEntry{
+			"alpha": {
+				GitURL:      "https://example.com/alpha.git",
+				BeadsConfig: &config.BeadsConfig{Prefix: "al"},
+				Groups:      []string{"infra"},
+			},
+			"old": {
+				GitURL:   "https://example.com/old.git",
+				Archived: &config.RigArchive{ArchivedAt: time.Now()},
+			},
+		},
+	}
+	data, err := json.Marshal(rigs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "mayor", "rigs.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func TestOpenNotTown(t *testing.T) {
+	if _, err := Open(t.TempDir()); !errors.Is(err, ErrNotTown) {
+		t.Errorf("Open = %v, want ErrNotTown", err)
+	}
+}
+
+func TestTownRigs(t *testing.T) {
+	root := setupTown(t)
+	town, err := Find(filepath.Join(root, "alpha", "witness"))
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	if got, _ := filepath.EvalSymlinks(town.Root()); got != mustEval(t, root) {
+		t.Errorf("Root = %s, want %s", town.Root(), root)
+	}
+
+	rigs, err := town.Rigs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rigs) != 1 || rigs[0].Name != "alpha" {
+		t.Fatalf("Rigs = %+v, want only alpha", rigs)
+	}
+	alpha := rigs[0]
+	if alpha.Prefix != "al" || len(alpha.Groups) != 1 || alpha.Groups[0] != "infra" || alpha.Archived {
+		t.Errorf("alpha = %+v", alpha)
+	}
+
+	old, err := town.Rig("old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !old.Archived {
+		t.Error("old.Archived = false")
+	}
+	if mrs, err := old.MergeQueue().List(context.Background()); err != nil || len(mrs) != 0 {
+		t.Errorf("archived MergeQueue().List = %v, %v; want empty", mrs, err)
+	}
+
+	if _, err := town.Rig("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Rig(missing) = %v, want ErrNotFound", err)
+	}
+}
+
+func TestCanceledContext(t *testing.T) {
+	town, err := Open(setupTown(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := town.Rig("alpha")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := r.Issues().Ready(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Issues().Ready = %v, want context.Canceled", err)
+	}
+	if _, err := r.MergeQueue().List(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("MergeQueue().List = %v, want context.Canceled", err)
+	}
+}
+
+func mustEval(t *testing.T, path string) string {
+	t.Helper()
+	p, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}